@@ -1,18 +1,30 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"runtime/debug"
 
 	"fyne.io/fyne/v2/app"
 	grottoApp "github.com/shhac/grotto/internal/app"
+	"github.com/shhac/grotto/internal/cli"
 	"github.com/shhac/grotto/internal/ui"
+	"github.com/shhac/grotto/internal/ui/settings"
 )
 
 func main() {
+	// Subcommands run headless; the app is only started without one
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		code := cli.Run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	versionFlag := flag.Bool("version", false, "print version and exit")
 	flag.Parse()
 
@@ -59,6 +71,10 @@ func runApp() (err error) {
 	// Load theme preference
 	ui.LoadThemePreference(fyneApp)
 
+	// Load saved log level and file (GROTTO_DEBUG still forces debug)
+	cfg.Log = settings.LoadLogSettings(fyneApp.Preferences())
+	cfg.Audit, cfg.AuditPayloads = settings.LoadAuditSettings(fyneApp.Preferences())
+
 	// Create and wire the application
 	grottoApp, err := grottoApp.New(fyneApp, cfg)
 	if err != nil {
@@ -1,11 +1,13 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"sync"
 
 	"fyne.io/fyne/v2"
+	"github.com/shhac/grotto/internal/controller"
 	"github.com/shhac/grotto/internal/grpc"
 	"github.com/shhac/grotto/internal/logging"
 	"github.com/shhac/grotto/internal/model"
@@ -19,8 +21,12 @@ type App struct {
 	window           fyne.Window
 	config           *Config
 	logger           *slog.Logger
+	logOutput        *logging.DynamicHandler
+	logs             *logging.RingHandler
 	connManager      *grpc.ConnectionManager
 	storage          storage.Repository
+	schemaCache      *storage.DescriptorCache
+	auditLog         *storage.AuditLog
 	state            *model.ApplicationState
 	mu               sync.RWMutex
 	reflectionClient *grpc.ReflectionClient
@@ -30,14 +36,29 @@ type App struct {
 // New creates a new App instance with the given configuration.
 // This performs all dependency injection and wiring.
 func New(fyneApp fyne.App, cfg *Config) (*App, error) {
-	// Initialize logger
-	logger, err := logging.InitLogger("grotto", cfg.Debug)
+	// Initialize logger. The log file accepts every level; the dynamic
+	// handler applies the one chosen in Preferences.
+	logFile, err := logging.OpenLogFile("grotto", slog.LevelDebug, cfg.Debug)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
+	logSettings := cfg.Log
+	if cfg.Debug {
+		logSettings.Level = slog.LevelDebug
+	}
+	logOutput := logging.NewDynamicHandler(logFile, logSettings.Level)
+	fileErr := logOutput.SetFile(logSettings.FilePath)
+
+	// Keep recent records, debug included, for the in-app log viewer
+	logs := logging.NewRingHandler(logging.DefaultRingSize, slog.LevelDebug)
+	logger := slog.New(logging.Tee(logOutput, logs))
+	if fileErr != nil {
+		logger.Warn("failed to open saved log file", slog.Any("error", fileErr))
+	}
 
 	logger.Info("initializing Grotto application",
 		slog.Bool("debug", cfg.Debug),
+		slog.String("log_level", logging.LevelName(logSettings.Level)),
 		slog.String("storage_path", cfg.StoragePath),
 	)
 
@@ -51,9 +72,11 @@ func New(fyneApp fyne.App, cfg *Config) (*App, error) {
 	}
 
 	repo := storage.NewJSONRepository(storagePath, logger)
+	schemaCache := storage.NewDescriptorCache(storagePath, logger)
 
 	// Initialize connection manager
 	connManager := grpc.NewConnectionManager(logger)
+	auditLog := storage.NewAuditLog(storagePath, storage.DefaultAuditMaxSize, storage.DefaultAuditKeep)
 
 	// Initialize application state
 	state := model.NewApplicationState()
@@ -65,14 +88,20 @@ func New(fyneApp fyne.App, cfg *Config) (*App, error) {
 
 	logger.Info("application initialized successfully")
 
-	return &App{
+	a := &App{
 		fyneApp:     fyneApp,
 		config:      cfg,
 		logger:      logger,
+		logOutput:   logOutput,
+		logs:        logs,
 		connManager: connManager,
 		storage:     repo,
+		schemaCache: schemaCache,
+		auditLog:    auditLog,
 		state:       state,
-	}, nil
+	}
+	a.SetAudit(cfg.Audit, cfg.AuditPayloads)
+	return a, nil
 }
 
 // Run starts the application and displays the main window.
@@ -98,6 +127,50 @@ func (a *App) Logger() *slog.Logger {
 	return a.logger
 }
 
+// Logs returns the recent log records kept for the in-app log viewer.
+func (a *App) Logs() *logging.RingHandler {
+	return a.logs
+}
+
+// LogSettings returns the current log level and extra log file.
+func (a *App) LogSettings() logging.Settings {
+	return a.logOutput.Settings()
+}
+
+// SetLogSettings changes the log level and extra log file of every logger
+// in the app at once. If the file cannot be opened the level still changes
+// and the previous file is kept.
+func (a *App) SetLogSettings(settings logging.Settings) error {
+	if err := a.logOutput.Apply(settings); err != nil {
+		return err
+	}
+	a.logger.Info("log settings changed",
+		slog.String("level", logging.LevelName(settings.Level)),
+		slog.String("file", settings.FilePath),
+	)
+	return nil
+}
+
+// SetAudit turns the audit file on or off for connections made from now
+// on. With payloads, the messages of each call are recorded too.
+func (a *App) SetAudit(enabled, payloads bool) {
+	settings := grpc.AuditSettings{Payloads: payloads}
+	if enabled {
+		settings.Writer = a.auditLog
+	}
+	a.connManager.SetAudit(settings)
+	a.logger.Info("audit settings changed",
+		slog.Bool("enabled", enabled),
+		slog.Bool("payloads", payloads),
+		slog.String("file", a.auditLog.Path()),
+	)
+}
+
+// AuditPath returns the path of the current audit file.
+func (a *App) AuditPath() string {
+	return a.auditLog.Path()
+}
+
 // Storage returns the storage repository.
 func (a *App) Storage() storage.Repository {
 	return a.storage
@@ -122,12 +195,32 @@ func (a *App) Invoker() *grpc.Invoker {
 	return a.invoker
 }
 
+// MethodResolver returns the reflection client for the request
+// controller, or nil if not connected.
+func (a *App) MethodResolver() controller.MethodResolver {
+	if rc := a.ReflectionClient(); rc != nil {
+		return rc
+	}
+	return nil
+}
+
+// MethodInvoker returns the invoker for the request controller, or nil if
+// not connected.
+func (a *App) MethodInvoker() controller.Invoker {
+	return controller.FromGRPC(a.Invoker())
+}
+
 // InitializeReflectionClient creates a new reflection client and invoker for the current connection.
-// This should be called after a successful connection is established.
-func (a *App) InitializeReflectionClient() error {
+// This should be called after a successful connection is established. The
+// client reuses descriptors cached on disk for the server while its service
+// list is unchanged. Nothing is replaced if ctx is already done.
+func (a *App) InitializeReflectionClient(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	conn := a.connManager.Conn()
+	conn := a.connManager.Channel()
 	if conn == nil {
 		return fmt.Errorf("no active connection")
 	}
@@ -139,12 +232,70 @@ func (a *App) InitializeReflectionClient() error {
 
 	// Create new reflection client and invoker
 	a.reflectionClient = grpc.NewReflectionClient(conn, a.logger)
+	a.reflectionClient.SetSchemaCache(a.schemaCache, a.connManager.Address())
 	a.invoker = grpc.NewInvoker(conn, a.logger)
 
 	a.logger.Info("reflection client and invoker initialized")
 	return nil
 }
 
+// InitializeDescriptorSetClient creates a descriptor source backed by a
+// FileDescriptorSet file, plus an invoker, for the current connection.
+// Use this instead of InitializeReflectionClient when the server has
+// reflection disabled. Nothing is replaced if ctx is already done.
+func (a *App) InitializeDescriptorSetClient(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	conn := a.connManager.Channel()
+	if conn == nil {
+		return fmt.Errorf("no active connection")
+	}
+
+	refClient, err := grpc.NewReflectionClientFromDescriptorSet(conn, path, a.logger)
+	if err != nil {
+		return err
+	}
+
+	if a.reflectionClient != nil {
+		a.reflectionClient.Close()
+	}
+	a.reflectionClient = refClient
+	a.invoker = grpc.NewInvoker(conn, a.logger)
+
+	a.logger.Info("descriptor set client and invoker initialized", slog.String("path", path))
+	return nil
+}
+
+// InitializeProtoSourceClient creates a descriptor source by compiling the
+// .proto files under the given import paths, plus an invoker, for the
+// current connection. Compile errors are returned as a
+// *grpc.ProtoCompileError. Cancelling ctx stops the compile.
+func (a *App) InitializeProtoSourceClient(ctx context.Context, importPaths []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	conn := a.connManager.Channel()
+	if conn == nil {
+		return fmt.Errorf("no active connection")
+	}
+
+	refClient, err := grpc.NewReflectionClientFromProtoSources(ctx, conn, importPaths, a.logger)
+	if err != nil {
+		return err
+	}
+
+	if a.reflectionClient != nil {
+		a.reflectionClient.Close()
+	}
+	a.reflectionClient = refClient
+	a.invoker = grpc.NewInvoker(conn, a.logger)
+
+	a.logger.Info("proto source client and invoker initialized", slog.Any("import_paths", importPaths))
+	return nil
+}
+
 // CleanupReflectionClient closes and clears the reflection client and invoker
 func (a *App) CleanupReflectionClient() {
 	a.mu.Lock()
@@ -1,8 +1,11 @@
 package app
 
 import (
+	"log/slog"
 	"os"
 	"strconv"
+
+	"github.com/shhac/grotto/internal/logging"
 )
 
 // Config holds application-wide configuration.
@@ -12,6 +15,15 @@ type Config struct {
 
 	// StoragePath is the directory where workspaces and settings are stored
 	StoragePath string
+
+	// Log is the saved log level and extra log file. Debug overrides the
+	// level at startup.
+	Log logging.Settings
+
+	// Audit records every call to the audit file, with its messages too if
+	// AuditPayloads is set
+	Audit         bool
+	AuditPayloads bool
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -19,6 +31,7 @@ func DefaultConfig() *Config {
 	return &Config{
 		Debug:       false,
 		StoragePath: "", // Will use DefaultStoragePath() from storage package
+		Log:         logging.Settings{Level: slog.LevelInfo},
 	}
 }
 
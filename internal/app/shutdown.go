@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// shutdownStep is one named step of closing the app.
+type shutdownStep struct {
+	name string
+	run  func() error
+}
+
+// Shutdown closes the app down in order: cancelStreams cancels the calls
+// and streams in progress, then the reflection client is closed, the
+// connection closed, and flush waits for pending history and autosave
+// writes. A step still running after timeout is left to finish on its own
+// and the next one starts; the error names the steps that did.
+func (a *App) Shutdown(cancelStreams, flush func(), timeout time.Duration) error {
+	a.logger.Info("shutting down")
+	return runShutdown(a.logger, timeout, []shutdownStep{
+		{"cancel streams", noError(cancelStreams)},
+		{"close reflection client", noError(a.CleanupReflectionClient)},
+		{"disconnect", a.connManager.Disconnect},
+		{"flush writes", noError(flush)},
+	})
+}
+
+// runShutdown runs steps one after another, skipping those without a func
+// and waiting at most timeout for each.
+func runShutdown(logger *slog.Logger, timeout time.Duration, steps []shutdownStep) error {
+	var errs []error
+	for _, step := range steps {
+		if step.run == nil {
+			continue
+		}
+		done := make(chan error, 1)
+		go func() { done <- step.run() }()
+
+		timer := time.NewTimer(timeout)
+		select {
+		case err := <-done:
+			if err != nil {
+				logger.Warn("shutdown step failed", slog.String("step", step.name), slog.Any("error", err))
+				errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			}
+		case <-timer.C:
+			logger.Warn("shutdown step timed out", slog.String("step", step.name), slog.Duration("timeout", timeout))
+			errs = append(errs, fmt.Errorf("%s: timed out after %s", step.name, timeout))
+		}
+		timer.Stop()
+	}
+	return errors.Join(errs...)
+}
+
+// noError adapts f to a step func, or returns nil when f is nil.
+func noError(f func()) func() error {
+	if f == nil {
+		return nil
+	}
+	return func() error {
+		f()
+		return nil
+	}
+}
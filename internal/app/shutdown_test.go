@@ -0,0 +1,80 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestShutdown_Order(t *testing.T) {
+	logger := testLogger()
+	a := &App{logger: logger, connManager: grpc.NewConnectionManager(logger)}
+
+	var calls []string
+	err := a.Shutdown(
+		func() { calls = append(calls, "cancel") },
+		func() { calls = append(calls, "flush") },
+		time.Second,
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cancel", "flush"}, calls)
+	assert.Nil(t, a.ReflectionClient())
+	assert.Equal(t, grpc.StateDisconnected, a.connManager.State())
+}
+
+func TestRunShutdown_StepsInOrder(t *testing.T) {
+	var calls []string
+	step := func(name string, err error) shutdownStep {
+		return shutdownStep{name, func() error {
+			calls = append(calls, name)
+			return err
+		}}
+	}
+
+	err := runShutdown(testLogger(), time.Second, []shutdownStep{
+		step("cancel", nil),
+		{"skipped", nil},
+		step("disconnect", errors.New("refused")),
+		step("flush", nil),
+	})
+
+	// A failing step does not stop the ones after it
+	assert.Equal(t, []string{"cancel", "disconnect", "flush"}, calls)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disconnect: refused")
+}
+
+func TestRunShutdown_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	flushed := false
+
+	start := time.Now()
+	err := runShutdown(testLogger(), 50*time.Millisecond, []shutdownStep{
+		{"disconnect", func() error {
+			<-release
+			return nil
+		}},
+		{"flush", func() error {
+			flushed = true
+			return nil
+		}},
+	})
+
+	// The hung step is abandoned after the timeout and the next one still runs
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, flushed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disconnect: timed out")
+}
@@ -0,0 +1,343 @@
+// Package checklist runs workspace startup checklists: an ordered list of
+// connection, method, and metadata checks executed before a session.
+package checklist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMethodTimeout bounds method checks that don't set their own timeout
+const DefaultMethodTimeout = 10 * time.Second
+
+// Status is the outcome of a single checklist item
+type Status int
+
+const (
+	StatusPass Status = iota
+	StatusFail
+	StatusSkip
+)
+
+// String returns a human-readable representation of the status
+func (s Status) String() string {
+	switch s {
+	case StatusPass:
+		return "Pass"
+	case StatusFail:
+		return "Fail"
+	case StatusSkip:
+		return "Skip"
+	default:
+		return "Unknown"
+	}
+}
+
+// Fix identifies where the user should go to fix a failed item
+type Fix int
+
+const (
+	FixNone       Fix = iota
+	FixConnection     // Edit the item's connection profile
+	FixMetadata       // Edit the request metadata
+	FixItem           // Edit the checklist item itself
+)
+
+// Result is the outcome of running one checklist item
+type Result struct {
+	Index    int // Position of the item in the checklist
+	Item     domain.ChecklistItem
+	Status   Status
+	Message  string
+	Duration time.Duration
+	Fix      Fix
+}
+
+// Summary aggregates the results of a checklist run
+type Summary struct {
+	Results []Result
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// OK reports whether every item passed or was skipped
+func (s Summary) OK() bool {
+	return s.Failed == 0
+}
+
+// String returns a one-line summary suitable for a banner
+func (s Summary) String() string {
+	return fmt.Sprintf("%d passed, %d failed, %d skipped", s.Passed, s.Failed, s.Skipped)
+}
+
+// Env provides the connection facilities checklist items run against
+type Env interface {
+	// Connect establishes a connection and verifies it is reachable
+	Connect(ctx context.Context, conn domain.Connection) (Session, error)
+}
+
+// Session is a live connection created by Env.Connect
+type Session interface {
+	// InvokeUnary calls a unary method and returns its gRPC status error (nil for OK)
+	InvokeUnary(ctx context.Context, method, body string, md map[string]string) error
+	// Close releases the connection
+	Close()
+}
+
+// Runner executes checklists sequentially
+type Runner struct {
+	env    Env
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewRunner creates a checklist runner over the given environment
+func NewRunner(env Env, logger *slog.Logger) *Runner {
+	return &Runner{
+		env:    env,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run executes items in order. Method items use the session opened by the
+// most recent connect item and are skipped when there is none or it failed.
+// md is the request metadata checked by metadata items and sent with method
+// calls. onResult, if non-nil, is called after each item completes.
+// Cancelling ctx skips the remaining items.
+func (r *Runner) Run(ctx context.Context, items []domain.ChecklistItem, md map[string]string, onResult func(Result)) Summary {
+	var summary Summary
+	var session Session
+	sessionErr := "no connect item before this check"
+
+	defer func() {
+		if session != nil {
+			session.Close()
+		}
+	}()
+
+	for i, item := range items {
+		var res Result
+		start := r.now()
+
+		switch {
+		case ctx.Err() != nil:
+			res = Result{Status: StatusSkip, Message: "checklist cancelled"}
+		case item.Kind == domain.ChecklistConnect:
+			if session != nil {
+				session.Close()
+				session = nil
+			}
+			var s Session
+			res, s = r.runConnect(ctx, item)
+			if s != nil {
+				session = s
+			} else {
+				sessionErr = "connection " + connectionName(item.Connection) + " failed"
+			}
+		case item.Kind == domain.ChecklistMethod:
+			if session == nil {
+				res = Result{Status: StatusSkip, Message: "skipped: " + sessionErr}
+				break
+			}
+			res = r.runMethod(ctx, session, item, md)
+		case item.Kind == domain.ChecklistMetadata:
+			res = r.runMetadata(item, md)
+		default:
+			res = Result{Status: StatusFail, Message: fmt.Sprintf("unknown item kind %q", item.Kind), Fix: FixItem}
+		}
+
+		res.Index = i
+		res.Item = item
+		res.Duration = r.now().Sub(start)
+
+		switch res.Status {
+		case StatusPass:
+			summary.Passed++
+		case StatusFail:
+			summary.Failed++
+		case StatusSkip:
+			summary.Skipped++
+		}
+		summary.Results = append(summary.Results, res)
+
+		r.logger.Debug("checklist item finished",
+			slog.Int("index", i),
+			slog.String("kind", string(item.Kind)),
+			slog.String("status", res.Status.String()),
+			slog.String("message", res.Message),
+		)
+
+		if onResult != nil {
+			onResult(res)
+		}
+	}
+
+	r.logger.Info("checklist finished",
+		slog.Int("passed", summary.Passed),
+		slog.Int("failed", summary.Failed),
+		slog.Int("skipped", summary.Skipped),
+	)
+	return summary
+}
+
+// runConnect opens a session for a connect item
+func (r *Runner) runConnect(ctx context.Context, item domain.ChecklistItem) (Result, Session) {
+	if item.Connection == nil || item.Connection.Address == "" {
+		return Result{Status: StatusFail, Message: "no connection profile configured", Fix: FixConnection}, nil
+	}
+
+	s, err := r.env.Connect(ctx, *item.Connection)
+	if err != nil {
+		return Result{Status: StatusFail, Message: err.Error(), Fix: FixConnection}, nil
+	}
+	return Result{Status: StatusPass, Message: "connected to " + item.Connection.Address}, s
+}
+
+// runMethod invokes a method item and compares the status code
+func (r *Runner) runMethod(ctx context.Context, s Session, item domain.ChecklistItem, md map[string]string) Result {
+	if item.Method == "" {
+		return Result{Status: StatusFail, Message: "no method configured", Fix: FixItem}
+	}
+
+	want, err := ExpectedCode(item.ExpectCode)
+	if err != nil {
+		return Result{Status: StatusFail, Message: err.Error(), Fix: FixItem}
+	}
+
+	timeout := item.Timeout
+	if timeout <= 0 {
+		timeout = DefaultMethodTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body := item.Body
+	if strings.TrimSpace(body) == "" {
+		body = "{}"
+	}
+
+	start := r.now()
+	callErr := s.InvokeUnary(callCtx, item.Method, body, md)
+	elapsed := r.now().Sub(start)
+	got := status.Code(callErr)
+
+	if got == want {
+		return Result{Status: StatusPass, Message: fmt.Sprintf("%s in %s", got, elapsed.Round(time.Millisecond))}
+	}
+	if got == codes.DeadlineExceeded && callCtx.Err() != nil && ctx.Err() == nil {
+		return Result{Status: StatusFail, Message: fmt.Sprintf("no response within %s", timeout), Fix: FixItem}
+	}
+
+	msg := fmt.Sprintf("expected %s, got %s", want, got)
+	if callErr != nil {
+		if detail := status.Convert(callErr).Message(); detail != "" {
+			msg += ": " + detail
+		}
+	}
+	fix := FixItem
+	if got == codes.Unauthenticated || got == codes.PermissionDenied {
+		fix = FixMetadata
+	}
+	return Result{Status: StatusFail, Message: msg, Fix: fix}
+}
+
+// runMetadata checks that a metadata key is present and, optionally, that
+// its JWT has not expired
+func (r *Runner) runMetadata(item domain.ChecklistItem, md map[string]string) Result {
+	key := strings.ToLower(strings.TrimSpace(item.MetadataKey))
+	if key == "" {
+		return Result{Status: StatusFail, Message: "no metadata key configured", Fix: FixItem}
+	}
+
+	var value string
+	var found bool
+	for k, v := range md {
+		if strings.ToLower(k) == key {
+			value, found = v, true
+			break
+		}
+	}
+	if !found || strings.TrimSpace(value) == "" {
+		return Result{Status: StatusFail, Message: "metadata " + key + " is not set", Fix: FixMetadata}
+	}
+	if !item.CheckJWTExpiry {
+		return Result{Status: StatusPass, Message: "metadata " + key + " is set"}
+	}
+
+	exp, ok, err := JWTExpiry(value)
+	if err != nil {
+		return Result{Status: StatusFail, Message: "metadata " + key + ": " + err.Error(), Fix: FixMetadata}
+	}
+	if !ok {
+		return Result{Status: StatusPass, Message: "token has no exp claim"}
+	}
+
+	now := r.now()
+	if !exp.After(now) {
+		return Result{
+			Status:  StatusFail,
+			Message: fmt.Sprintf("token expired %s ago", now.Sub(exp).Round(time.Second)),
+			Fix:     FixMetadata,
+		}
+	}
+	return Result{Status: StatusPass, Message: fmt.Sprintf("token valid for %s", exp.Sub(now).Round(time.Second))}
+}
+
+// ExpectedCode returns the status code a method item expects: OK when
+// none is set, or the code named as grpc.ParseCode accepts it.
+func ExpectedCode(name string) (codes.Code, error) {
+	if strings.TrimSpace(name) == "" {
+		return codes.OK, nil
+	}
+	return grpc.ParseCode(name)
+}
+
+// connectionName returns a display name for a connection profile
+func connectionName(conn *domain.Connection) string {
+	if conn == nil {
+		return "(none)"
+	}
+	if conn.Name != "" {
+		return conn.Name
+	}
+	return conn.Address
+}
+
+// Describe returns a short human-readable description of an item
+func Describe(item domain.ChecklistItem) string {
+	if item.Label != "" {
+		return item.Label
+	}
+	switch item.Kind {
+	case domain.ChecklistConnect:
+		return "Connect to " + connectionName(item.Connection)
+	case domain.ChecklistMethod:
+		expect := item.ExpectCode
+		if expect == "" {
+			expect = "OK"
+		}
+		desc := item.Method + " returns " + expect
+		if item.Timeout > 0 {
+			desc += " within " + item.Timeout.String()
+		}
+		return desc
+	case domain.ChecklistMetadata:
+		desc := "Metadata " + item.MetadataKey + " present"
+		if item.CheckJWTExpiry {
+			desc += " and token not expired"
+		}
+		return desc
+	default:
+		return string(item.Kind)
+	}
+}
@@ -0,0 +1,319 @@
+package checklist
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testLogger = logging.NewNopLogger()
+
+// fakeEnv connects to any address listed in sessions and fails otherwise.
+type fakeEnv struct {
+	sessions map[string]*fakeSession
+	dialed   []string
+}
+
+func (e *fakeEnv) Connect(_ context.Context, conn domain.Connection) (Session, error) {
+	e.dialed = append(e.dialed, conn.Address)
+	s, ok := e.sessions[conn.Address]
+	if !ok {
+		return nil, errors.New("server unreachable")
+	}
+	return s, nil
+}
+
+// fakeSession returns canned errors per method and records calls.
+type fakeSession struct {
+	errs   map[string]error
+	delay  time.Duration
+	calls  []string
+	gotMD  map[string]string
+	closed bool
+}
+
+func (s *fakeSession) InvokeUnary(ctx context.Context, method, _ string, md map[string]string) error {
+	s.calls = append(s.calls, method)
+	s.gotMD = md
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return s.errs[method]
+}
+
+func (s *fakeSession) Close() { s.closed = true }
+
+func connectItem(addr string) domain.ChecklistItem {
+	return domain.ChecklistItem{Kind: domain.ChecklistConnect, Connection: &domain.Connection{Address: addr}}
+}
+
+func methodItem(method, expect string) domain.ChecklistItem {
+	return domain.ChecklistItem{Kind: domain.ChecklistMethod, Method: method, ExpectCode: expect}
+}
+
+// makeJWT builds an unsigned JWT with the given claims JSON.
+func makeJWT(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(claims)) + ".sig"
+}
+
+func TestRun_ConnectAndMethods(t *testing.T) {
+	healthy := &fakeSession{errs: map[string]error{
+		"pkg.Svc/Missing": status.Error(codes.NotFound, "no such thing"),
+		"pkg.Svc/Secret":  status.Error(codes.Unauthenticated, "bad token"),
+	}}
+	env := &fakeEnv{sessions: map[string]*fakeSession{"a:1": healthy}}
+
+	items := []domain.ChecklistItem{
+		connectItem("a:1"),
+		methodItem("pkg.Svc/Health", ""),
+		methodItem("pkg.Svc/Missing", "NOT_FOUND"),
+		methodItem("pkg.Svc/Health", "not_found"),
+		methodItem("pkg.Svc/Secret", "OK"),
+		connectItem("down:1"),
+		methodItem("pkg.Svc/Health", ""),
+	}
+
+	var streamed []Result
+	summary := NewRunner(env, testLogger).Run(context.Background(), items, map[string]string{"x": "y"}, func(r Result) {
+		streamed = append(streamed, r)
+	})
+
+	require.Len(t, summary.Results, len(items))
+	assert.Equal(t, summary.Results, streamed)
+
+	want := []struct {
+		status Status
+		fix    Fix
+	}{
+		{StatusPass, FixNone},
+		{StatusPass, FixNone},
+		{StatusPass, FixNone},
+		{StatusFail, FixItem},
+		{StatusFail, FixMetadata},
+		{StatusFail, FixConnection},
+		{StatusSkip, FixNone},
+	}
+	for i, w := range want {
+		assert.Equal(t, i, summary.Results[i].Index)
+		assert.Equal(t, w.status, summary.Results[i].Status, "item %d: %s", i, summary.Results[i].Message)
+		assert.Equal(t, w.fix, summary.Results[i].Fix, "item %d", i)
+	}
+
+	assert.Contains(t, summary.Results[3].Message, "expected NotFound, got OK")
+	assert.Contains(t, summary.Results[4].Message, "bad token")
+	assert.Contains(t, summary.Results[6].Message, "down:1 failed")
+	assert.Equal(t, 3, summary.Passed)
+	assert.Equal(t, 3, summary.Failed)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.False(t, summary.OK())
+	assert.Equal(t, "3 passed, 3 failed, 1 skipped", summary.String())
+
+	assert.True(t, healthy.closed, "session should be closed when the next connect item runs")
+	assert.Equal(t, map[string]string{"x": "y"}, healthy.gotMD)
+	assert.Equal(t, []string{"a:1", "down:1"}, env.dialed)
+}
+
+func TestRun_MethodWithoutConnectIsSkipped(t *testing.T) {
+	summary := NewRunner(&fakeEnv{}, testLogger).Run(context.Background(),
+		[]domain.ChecklistItem{methodItem("pkg.Svc/Health", "")}, nil, nil)
+
+	require.Len(t, summary.Results, 1)
+	assert.Equal(t, StatusSkip, summary.Results[0].Status)
+	assert.True(t, summary.OK())
+}
+
+func TestRun_MethodTimeout(t *testing.T) {
+	slow := &fakeSession{delay: time.Second}
+	env := &fakeEnv{sessions: map[string]*fakeSession{"a:1": slow}}
+	item := methodItem("pkg.Svc/Slow", "")
+	item.Timeout = 20 * time.Millisecond
+
+	summary := NewRunner(env, testLogger).Run(context.Background(),
+		[]domain.ChecklistItem{connectItem("a:1"), item}, nil, nil)
+
+	require.Len(t, summary.Results, 2)
+	assert.Equal(t, StatusFail, summary.Results[1].Status)
+	assert.Contains(t, summary.Results[1].Message, "no response within 20ms")
+	assert.True(t, slow.closed, "session should be closed when the run ends")
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	env := &fakeEnv{sessions: map[string]*fakeSession{"a:1": {}}}
+	summary := NewRunner(env, testLogger).Run(ctx,
+		[]domain.ChecklistItem{connectItem("a:1"), methodItem("pkg.Svc/Health", "")}, nil, nil)
+
+	assert.Equal(t, 2, summary.Skipped)
+	assert.Empty(t, env.dialed)
+}
+
+func TestRun_InvalidItems(t *testing.T) {
+	env := &fakeEnv{sessions: map[string]*fakeSession{"a:1": {}}}
+	items := []domain.ChecklistItem{
+		{Kind: domain.ChecklistConnect},
+		connectItem("a:1"),
+		methodItem("", ""),
+		methodItem("pkg.Svc/Health", "SOMETIMES"),
+		{Kind: domain.ChecklistMetadata},
+		{Kind: "bogus"},
+	}
+
+	summary := NewRunner(env, testLogger).Run(context.Background(), items, nil, nil)
+
+	wantFix := []Fix{FixConnection, FixNone, FixItem, FixItem, FixItem, FixItem}
+	for i, fix := range wantFix {
+		assert.Equal(t, fix, summary.Results[i].Fix, "item %d: %s", i, summary.Results[i].Message)
+	}
+	assert.Equal(t, 1, summary.Passed)
+	assert.Equal(t, 5, summary.Failed)
+}
+
+func TestRun_Metadata(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	valid := "Bearer " + makeJWT(`{"sub":"me","exp":1700003600}`)
+	expired := makeJWT(`{"exp":1699999000}`)
+	noExp := makeJWT(`{"sub":"me"}`)
+
+	tests := []struct {
+		name       string
+		item       domain.ChecklistItem
+		md         map[string]string
+		wantStatus Status
+		wantMsg    string
+	}{
+		{
+			name:       "present",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "x-team"},
+			md:         map[string]string{"x-team": "oncall"},
+			wantStatus: StatusPass,
+		},
+		{
+			name:       "key match is case-insensitive",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "X-Team"},
+			md:         map[string]string{"x-team": "oncall"},
+			wantStatus: StatusPass,
+		},
+		{
+			name:       "missing",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "authorization"},
+			md:         map[string]string{"x-team": "oncall"},
+			wantStatus: StatusFail,
+			wantMsg:    "is not set",
+		},
+		{
+			name:       "empty value",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "authorization"},
+			md:         map[string]string{"authorization": " "},
+			wantStatus: StatusFail,
+			wantMsg:    "is not set",
+		},
+		{
+			name:       "valid bearer JWT",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "authorization", CheckJWTExpiry: true},
+			md:         map[string]string{"authorization": valid},
+			wantStatus: StatusPass,
+			wantMsg:    "valid for 1h0m0s",
+		},
+		{
+			name:       "expired JWT",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "authorization", CheckJWTExpiry: true},
+			md:         map[string]string{"authorization": expired},
+			wantStatus: StatusFail,
+			wantMsg:    "expired 16m40s ago",
+		},
+		{
+			name:       "JWT without exp",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "authorization", CheckJWTExpiry: true},
+			md:         map[string]string{"authorization": noExp},
+			wantStatus: StatusPass,
+			wantMsg:    "no exp claim",
+		},
+		{
+			name:       "not a JWT",
+			item:       domain.ChecklistItem{Kind: domain.ChecklistMetadata, MetadataKey: "authorization", CheckJWTExpiry: true},
+			md:         map[string]string{"authorization": "Bearer opaque-token"},
+			wantStatus: StatusFail,
+			wantMsg:    "not a JWT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(&fakeEnv{}, testLogger)
+			r.now = func() time.Time { return now }
+
+			summary := r.Run(context.Background(), []domain.ChecklistItem{tt.item}, tt.md, nil)
+			require.Len(t, summary.Results, 1)
+			res := summary.Results[0]
+			assert.Equal(t, tt.wantStatus, res.Status, res.Message)
+			assert.Contains(t, res.Message, tt.wantMsg)
+			if tt.wantStatus == StatusFail {
+				assert.Equal(t, FixMetadata, res.Fix)
+			}
+		})
+	}
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp, ok, err := JWTExpiry(makeJWT(`{"exp":1700000000.5}`))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1700000000), exp.Unix())
+
+	_, _, err = JWTExpiry("a.!!!.c")
+	assert.Error(t, err)
+
+	_, _, err = JWTExpiry(makeJWT(`{"exp":"soon"}`))
+	assert.Error(t, err)
+
+	_, _, err = JWTExpiry(makeJWT(`not json`))
+	assert.Error(t, err)
+}
+
+func TestExpectedCode(t *testing.T) {
+	code, err := ExpectedCode("")
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, code)
+
+	code, err = ExpectedCode("NotFound")
+	require.NoError(t, err)
+	assert.Equal(t, codes.NotFound, code)
+
+	for _, name := range []string{"CANCELLED", "cancelled"} {
+		code, err = ExpectedCode(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, codes.Canceled, code, name)
+	}
+
+	_, err = ExpectedCode("teapot")
+	assert.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "Connect to prod", Describe(domain.ChecklistItem{
+		Kind: domain.ChecklistConnect, Connection: &domain.Connection{Name: "prod", Address: "prod:443"},
+	}))
+	assert.Equal(t, "pkg.Svc/Health returns OK within 2s", Describe(domain.ChecklistItem{
+		Kind: domain.ChecklistMethod, Method: "pkg.Svc/Health", Timeout: 2 * time.Second,
+	}))
+	assert.Equal(t, "Metadata authorization present and token not expired", Describe(domain.ChecklistItem{
+		Kind: domain.ChecklistMetadata, MetadataKey: "authorization", CheckJWTExpiry: true,
+	}))
+	assert.Equal(t, "custom", Describe(domain.ChecklistItem{Kind: domain.ChecklistMethod, Label: "custom"}))
+}
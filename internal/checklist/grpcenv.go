@@ -0,0 +1,104 @@
+package checklist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/pkg/grottocore"
+)
+
+// defaultConnectTimeout bounds connect items whose profile has no timeout
+const defaultConnectTimeout = 10 * time.Second
+
+// GRPCEnv runs checklist items over real connections. Each connect item
+// gets its own ConnectionManager, so a checklist never disturbs the
+// connection the user is working with.
+type GRPCEnv struct {
+	logger *slog.Logger
+}
+
+// NewGRPCEnv creates an environment backed by the grpc package
+func NewGRPCEnv(logger *slog.Logger) *GRPCEnv {
+	return &GRPCEnv{logger: logger}
+}
+
+// Connect implements Env. Native gRPC connections are dialed eagerly and
+// must reach READY; gRPC-Web has no handshake, so it is verified by the
+// first method call instead.
+func (e *GRPCEnv) Connect(ctx context.Context, conn domain.Connection) (Session, error) {
+	timeout := conn.Timeout
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cm := grpc.NewConnectionManager(e.logger)
+	if err := cm.Connect(ctx, conn); err != nil {
+		return nil, err
+	}
+	if err := cm.WaitReady(ctx); err != nil {
+		_ = cm.Disconnect()
+		return nil, err
+	}
+
+	channel := cm.Channel()
+	reflection, err := grpc.NewSchemaSource(ctx, channel, conn, e.logger)
+	if err != nil {
+		_ = cm.Disconnect()
+		return nil, err
+	}
+
+	return &grpcSession{
+		cm:         cm,
+		reflection: reflection,
+		invoker:    grpc.NewInvoker(channel, e.logger),
+	}, nil
+}
+
+// grpcSession is a Session over a dedicated connection
+type grpcSession struct {
+	cm         *grpc.ConnectionManager
+	reflection *grpc.ReflectionClient
+	invoker    *grpc.Invoker
+}
+
+// InvokeUnary implements Session
+func (s *grpcSession) InvokeUnary(ctx context.Context, method, body string, md map[string]string) error {
+	serviceName, methodName, err := SplitMethod(method)
+	if err != nil {
+		return err
+	}
+
+	methodDesc, err := s.reflection.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		return err
+	}
+	if methodDesc.IsStreamingClient() || methodDesc.IsStreamingServer() {
+		return fmt.Errorf("%s is a streaming method; only unary methods can be checked", method)
+	}
+
+	grpcMD, err := grpc.BuildMetadata(md)
+	if err != nil {
+		return err
+	}
+
+	_, _, _, err = s.invoker.InvokeUnary(ctx, methodDesc, body, grpcMD)
+	return err
+}
+
+// Close implements Session
+func (s *grpcSession) Close() {
+	s.reflection.Close()
+	_ = s.cm.Disconnect()
+}
+
+// SplitMethod splits "pkg.Service/Method" (with an optional leading slash)
+// into service and method names.
+func SplitMethod(fullName string) (service, method string, err error) {
+	return grottocore.SplitMethod(fullName)
+}
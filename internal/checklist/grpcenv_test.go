@@ -0,0 +1,56 @@
+package checklist
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCEnv_Run(t *testing.T) {
+	addr := grpctest.StartServer(t, grpctest.WithTestService()).Addr
+
+	// Grab a free port and release it so the connect item targets nothing.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	items := []domain.ChecklistItem{
+		{Kind: domain.ChecklistConnect, Connection: &domain.Connection{Address: addr}},
+		{Kind: domain.ChecklistMethod, Method: "grpctest.TestService/UnaryEcho", Body: `{"item":{"id":"1"}}`, Timeout: 2 * time.Second},
+		{Kind: domain.ChecklistMethod, Method: "grpctest.TestService/UnaryEcho", Body: `{"bogus":1}`},
+		{Kind: domain.ChecklistMethod, Method: "grpctest.TestService/StreamItems"},
+		{Kind: domain.ChecklistMethod, Method: "grpctest.TestService/Nope", ExpectCode: "UNIMPLEMENTED"},
+		{Kind: domain.ChecklistConnect, Connection: &domain.Connection{Address: deadAddr, Timeout: 2 * time.Second}},
+	}
+
+	summary := NewRunner(NewGRPCEnv(testLogger), testLogger).Run(context.Background(), items, nil, nil)
+	require.Len(t, summary.Results, len(items))
+
+	want := []Status{StatusPass, StatusPass, StatusFail, StatusFail, StatusFail, StatusFail}
+	for i, w := range want {
+		assert.Equal(t, w, summary.Results[i].Status, "item %d: %s", i, summary.Results[i].Message)
+	}
+	assert.Contains(t, summary.Results[2].Message, "invalid request JSON")
+	assert.Contains(t, summary.Results[3].Message, "streaming method")
+	assert.Contains(t, summary.Results[4].Message, "not found")
+	assert.Equal(t, FixConnection, summary.Results[5].Fix)
+}
+
+func TestSplitMethod(t *testing.T) {
+	svc, method, err := SplitMethod("/pkg.Svc/Health")
+	require.NoError(t, err)
+	assert.Equal(t, "pkg.Svc", svc)
+	assert.Equal(t, "Health", method)
+
+	for _, bad := range []string{"", "pkg.Svc", "pkg.Svc/", "/Health"} {
+		_, _, err := SplitMethod(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,46 @@
+package checklist
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JWTExpiry extracts the exp claim from a JWT, optionally prefixed with an
+// auth scheme ("Bearer <token>"). The signature is not verified. ok is false
+// when the token is well-formed but has no exp claim.
+func JWTExpiry(value string) (exp time.Time, ok bool, err error) {
+	token := strings.TrimSpace(value)
+	if scheme, rest, found := strings.Cut(token, " "); found && !strings.Contains(scheme, ".") {
+		token = strings.TrimSpace(rest)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false, errors.New("value is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, false, nil
+	}
+
+	secs, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid exp claim: %w", err)
+	}
+	return time.Unix(int64(secs), 0), true, nil
+}
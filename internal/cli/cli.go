@@ -0,0 +1,391 @@
+// Package cli runs Grotto's subcommands: headless calls and listings made
+// through grottocore, the same connection, reflection and invocation code
+// as the app, for scripts.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/pkg/grottocore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// exitUsage is the exit code for bad arguments (EX_USAGE). Failed calls
+// exit with their status code, 1 to 16, so the two never collide.
+const exitUsage = 64
+
+// defaultTimeout bounds connecting and resolving the method when --timeout
+// is not set. The call itself is not bounded by default.
+const defaultTimeout = 10 * time.Second
+
+// commands are the subcommands by name. Each runs with the flags after its
+// name and returns the exit code.
+var commands = map[string]func(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int{
+	"call": callCommand,
+	"list": listCommand,
+}
+
+// IsCommand reports whether name is a subcommand, so the app is not started.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run runs the subcommand args[0] with the rest of args as its flags,
+// writing results to stdout and problems to stderr, and returns the exit
+// code. stdin is read when the request data is "@-".
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		fmt.Fprintln(stderr, "usage: grotto call|list --addr host:port [flags]")
+		return exitUsage
+	}
+	return commands[args[0]](ctx, args[1:], stdin, stdout, stderr)
+}
+
+// callCommand runs the call subcommand.
+func callCommand(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseCallFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "grotto call:", err)
+		return exitUsage
+	}
+	if opts.data, err = readData(opts.data, stdin); err != nil {
+		fmt.Fprintln(stderr, "grotto call:", err)
+		return exitUsage
+	}
+
+	if err := call(ctx, opts, stdout, newLogger(stderr, opts.verbose)); err != nil {
+		fmt.Fprintln(stderr, "grotto call:", err)
+		return exitCode(err)
+	}
+	return 0
+}
+
+// newLogger logs errors to stderr, or everything with -v.
+func newLogger(stderr io.Writer, verbose bool) *slog.Logger {
+	level := slog.LevelError
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// exitCode maps a failure to the process exit code: its status code, or
+// Unknown for errors without one.
+func exitCode(err error) int {
+	code := status.Code(err)
+	if code == codes.OK {
+		code = codes.Unknown
+	}
+	return int(code)
+}
+
+// serverOptions are the flags every subcommand takes to reach a server and
+// resolve its schema.
+type serverOptions struct {
+	addr       string
+	plaintext  bool
+	insecure   bool
+	web        bool
+	protoset   string
+	importPath stringsFlag
+	timeout    time.Duration
+	verbose    bool
+}
+
+// callOptions are the flags of the call subcommand.
+type callOptions struct {
+	serverOptions
+	method  string
+	data    string
+	headers headerFlags
+}
+
+// headerFlags collects repeated --header k=v flags.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string { return "" }
+
+func (h headerFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !ok || key == "" {
+		return fmt.Errorf("header %q must be in the form key=value", s)
+	}
+	h[key] = value
+	return nil
+}
+
+// stringsFlag collects a repeated string flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func parseCallFlags(args []string, stderr io.Writer) (callOptions, error) {
+	opts := callOptions{headers: headerFlags{}}
+	fs := flag.NewFlagSet("grotto call", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&opts.method, "method", "", "method to call, package.Service/Method (required)")
+	fs.StringVar(&opts.data, "data", "{}", "request JSON; streams take an array or a sequence of messages; @file reads a file, @- stdin")
+	fs.Var(opts.headers, "header", "request metadata as key=value (repeatable)")
+	fs.DurationVar(&opts.timeout, "timeout", 0, "deadline for the whole call (default none)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: grotto call --addr host:port --method pkg.Service/Method [flags]")
+		fmt.Fprintln(fs.Output(), "\nPrints the response as JSON, or one line of JSON per message for server streams.")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if opts.method == "" {
+		return opts, errors.New("--addr and --method are required")
+	}
+	return opts, opts.validate()
+}
+
+// register adds the server flags, all but --timeout, whose meaning and
+// default are the subcommand's own.
+func (o *serverOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.addr, "addr", "", "server address, host:port or unix:///path (required)")
+	fs.BoolVar(&o.plaintext, "plaintext", false, "connect without TLS")
+	fs.BoolVar(&o.insecure, "insecure", false, "skip TLS certificate verification")
+	fs.BoolVar(&o.web, "web", false, "connect over gRPC-Web")
+	fs.StringVar(&o.protoset, "protoset", "", "FileDescriptorSet to use instead of server reflection")
+	fs.Var(&o.importPath, "import-path", ".proto source directory to compile instead of using server reflection (repeatable)")
+	fs.BoolVar(&o.verbose, "v", false, "log debug output to stderr")
+}
+
+func (o serverOptions) validate() error {
+	if o.addr == "" {
+		return errors.New("--addr is required")
+	}
+	if o.protoset != "" && len(o.importPath) > 0 {
+		return errors.New("--protoset and --import-path cannot be combined")
+	}
+	return nil
+}
+
+// readData returns the request data, reading it from a file for "@path"
+// or from stdin for "@-".
+func readData(data string, stdin io.Reader) (string, error) {
+	path, ok := strings.CutPrefix(data, "@")
+	if !ok {
+		return data, nil
+	}
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read request data: %w", err)
+	}
+	return string(b), nil
+}
+
+// connect connects to the server the flags name and sets up its schema
+// source: the descriptor set, the .proto sources or server reflection.
+func (o serverOptions) connect(ctx context.Context, logger *slog.Logger) (*grottocore.Client, error) {
+	return grottocore.Connect(ctx, o.addr, grottocore.Options{
+		Plaintext:          o.plaintext,
+		InsecureSkipVerify: o.insecure,
+		Web:                o.web,
+		DescriptorSetFile:  o.protoset,
+		ProtoImportPaths:   o.importPath,
+		Logger:             logger,
+	})
+}
+
+// call connects, resolves the method and makes the call, writing the
+// response messages to stdout.
+func call(ctx context.Context, opts callOptions, stdout io.Writer, logger *slog.Logger) error {
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	if _, _, err := grottocore.SplitMethod(opts.method); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	md, err := grpc.BuildMetadata(opts.headers)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	setupCtx := ctx
+	if opts.timeout <= 0 {
+		var cancel context.CancelFunc
+		setupCtx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+	client, err := opts.connect(setupCtx, logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	methodDesc, err := client.Method(opts.method)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case methodDesc.IsStreamingClient():
+		return callClientStream(ctx, client, opts.method, methodDesc.IsStreamingServer(), opts.data, md, stdout)
+	case methodDesc.IsStreamingServer():
+		return callServerStream(ctx, client, opts.method, opts.data, md, stdout)
+	}
+	resp, _, _, err := client.InvokeUnary(ctx, opts.method, opts.data, md)
+	if err != nil {
+		return err
+	}
+	return writeIndented(stdout, resp)
+}
+
+// callServerStream writes each message as a line of JSON (NDJSON).
+func callServerStream(ctx context.Context, client *grottocore.Client, method, data string, md metadata.MD, stdout io.Writer) error {
+	stream, err := client.InvokeServerStream(ctx, method, data, md)
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(stdout, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// callClientStream sends every message in data, then writes the response:
+// as indented JSON for a client stream, as NDJSON for a bidi stream.
+func callClientStream(ctx context.Context, client *grottocore.Client, method string, bidi bool, data string, md metadata.MD, stdout io.Writer) error {
+	msgs, err := splitMessages(data)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if !bidi {
+		stream, err := client.InvokeClientStream(ctx, method, md)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := stream.Send(msg); err != nil && err != io.EOF {
+				return err
+			}
+		}
+		resp, err := stream.CloseAndReceive()
+		if err != nil {
+			return err
+		}
+		return writeIndented(stdout, resp)
+	}
+
+	stream, err := client.InvokeBidiStream(ctx, method, md)
+	if err != nil {
+		return err
+	}
+	// Send everything first; the server's replies are buffered meanwhile
+	sendErr := make(chan error, 1)
+	go func() {
+		for _, msg := range msgs {
+			if err := stream.Send(msg); err != nil {
+				if err == io.EOF {
+					// The stream ended; Recv reports why
+					err = nil
+				}
+				sendErr <- err
+				return
+			}
+		}
+		sendErr <- stream.CloseSend()
+	}()
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(stdout, msg); err != nil {
+			return err
+		}
+	}
+	return <-sendErr
+}
+
+// splitMessages splits stream request data, a JSON array of messages or a
+// sequence of JSON values, into one JSON text per message.
+func splitMessages(data string) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	var msgs []string
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid request data: %w", err)
+		}
+		var elems []json.RawMessage
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err := json.Unmarshal(raw, &elems); err != nil {
+				return nil, fmt.Errorf("invalid request data: %w", err)
+			}
+		} else {
+			elems = []json.RawMessage{raw}
+		}
+		for _, elem := range elems {
+			msgs = append(msgs, string(elem))
+		}
+	}
+	return msgs, nil
+}
+
+// writeIndented writes a JSON response indented, followed by a newline.
+func writeIndented(w io.Writer, resp string) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(resp), "", "  "); err != nil {
+		buf.Reset()
+		buf.WriteString(resp)
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
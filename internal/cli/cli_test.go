@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+// runCall runs `grotto call` against addr over plaintext with the given
+// extra flags, returning the exit code, stdout and stderr.
+func runCall(t *testing.T, addr string, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"call", "--addr", addr, "--plaintext"}, args...)
+	code := Run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCall_Unary(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+
+	code, out, errOut := runCall(t, srv.Addr, "",
+		"--method", "grpctest.TestService/UnaryEcho",
+		"--data", `{"item": {"id": "1", "number": "9007199254740993"}}`,
+	)
+	require.Equal(t, 0, code, errOut)
+	assert.JSONEq(t, `{"item": {"id": "1", "number": "9007199254740993"}, "ok": true}`, out)
+	assert.True(t, strings.HasPrefix(out, "{\n  "), "indented: %q", out)
+}
+
+func TestCall_ServerStreamNDJSON(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+
+	code, out, errOut := runCall(t, srv.Addr, "",
+		"--method", "/grpctest.TestService/StreamItems",
+		"--data", `{"item": {"id": "s"}}`,
+	)
+	require.Equal(t, 0, code, errOut)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.JSONEq(t, `{"item": {"id": "s"}, "ok": true}`, line)
+	}
+}
+
+func TestCall_ClientStream(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+
+	// An array, a sequence of values, or both, from stdin
+	code, out, errOut := runCall(t, srv.Addr, `[{"item": {"id": "a"}}, {"item": {"id": "b"}}] {"item": {"id": "c"}}`,
+		"--method", "grpctest.TestService/CollectItems",
+		"--data", "@-",
+	)
+	require.Equal(t, 0, code, errOut)
+	assert.JSONEq(t, `{"items": [{"id": "a"}, {"id": "b"}, {"id": "c"}], "count": 3}`, out)
+}
+
+func TestCall_BidiStream(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+	data := filepath.Join(t.TempDir(), "msgs.json")
+	require.NoError(t, os.WriteFile(data, []byte(`[{"item": {"id": "1"}}, {"item": {"id": "2"}}]`), 0o600))
+
+	code, out, errOut := runCall(t, srv.Addr, "",
+		"--method", "grpctest.TestService/BidiEcho",
+		"--data", "@"+data,
+	)
+	require.Equal(t, 0, code, errOut)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"item": {"id": "1"}, "ok": true}`, lines[0])
+	assert.JSONEq(t, `{"item": {"id": "2"}, "ok": true}`, lines[1])
+}
+
+func TestCall_HeaderFlags(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+
+	code, _, errOut := runCall(t, srv.Addr, "",
+		"--method", "grpctest.TestService/UnaryEcho",
+		"--header", "x-tenant=acme=1",
+		"--header", "x-token-bin=AAEC",
+	)
+	assert.Equal(t, 0, code, errOut)
+
+	code, _, errOut = runCall(t, srv.Addr, "",
+		"--method", "grpctest.TestService/UnaryEcho",
+		"--header", "x-bad",
+	)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, errOut, `header "x-bad" must be in the form key=value`)
+}
+
+func TestCall_Protoset(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithReflection(false))
+	set := &descriptorpb.FileDescriptorSet{}
+	imports := pb.File_grpc_test_proto.Imports()
+	for i := range imports.Len() {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(imports.Get(i).FileDescriptor))
+	}
+	set.File = append(set.File, protodesc.ToFileDescriptorProto(pb.File_grpc_test_proto))
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "test.protoset")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	code, out, errOut := runCall(t, srv.Addr, "",
+		"--method", "grpctest.TestService/UnaryEcho",
+		"--protoset", path,
+		"--data", `{"item": {"id": "p"}}`,
+	)
+	require.Equal(t, 0, code, errOut)
+	assert.JSONEq(t, `{"item": {"id": "p"}, "ok": true}`, out)
+}
+
+func TestCall_ExitCodes(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(),
+		grpctest.WithStatus("/grpctest.TestService/UnaryEcho", codes.PermissionDenied))
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"server status", []string{"--method", "grpctest.TestService/UnaryEcho"}, int(codes.PermissionDenied)},
+		{"server stream status", []string{"--method", "grpctest.TestService/StreamItems", "--data", `{"item": 1}`}, int(codes.InvalidArgument)},
+		{"unknown method", []string{"--method", "grpctest.TestService/Nope"}, int(codes.NotFound)},
+		{"malformed method", []string{"--method", "UnaryEcho"}, int(codes.InvalidArgument)},
+		{"missing method", nil, exitUsage},
+		{"unknown flag", []string{"--method", "grpctest.TestService/UnaryEcho", "--bogus"}, exitUsage},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, out, errOut := runCall(t, srv.Addr, "", tc.args...)
+			assert.Equal(t, tc.code, code, errOut)
+			assert.Empty(t, out)
+			assert.NotEmpty(t, errOut)
+		})
+	}
+}
+
+func TestRun_NotACommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitUsage, Run(context.Background(), []string{"serve"}, nil, &stdout, &stderr))
+	assert.True(t, IsCommand("call"))
+	assert.True(t, IsCommand("list"))
+	assert.False(t, IsCommand("-version"))
+}
+
+func TestSplitMessages(t *testing.T) {
+	msgs, err := splitMessages("{\"a\": 1}\n[{\"b\": 2}, {\"c\": [3]}]\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"a": 1}`, `{"b": 2}`, `{"c": [3]}`}, msgs)
+
+	_, err = splitMessages(`{"a": `)
+	assert.Error(t, err)
+}
+
+func TestCall_Unreachable(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+	addr := srv.Addr
+	srv.Close()
+
+	code, out, errOut := runCall(t, addr, "", "--method", "grpctest.TestService/UnaryEcho")
+	assert.Equal(t, int(codes.Unavailable), code, errOut)
+	assert.Empty(t, out)
+}
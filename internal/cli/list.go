@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/shhac/grotto/pkg/grottocore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// listOptions are the flags of the list subcommand.
+type listOptions struct {
+	serverOptions
+	describe string
+}
+
+// listedService is a service as the list subcommand prints it. Error is
+// set, and Methods may be empty, when it could not be resolved.
+type listedService struct {
+	Name       string         `json:"name"`
+	File       string         `json:"file,omitempty"`
+	Deprecated bool           `json:"deprecated,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Methods    []listedMethod `json:"methods"`
+}
+
+// listedMethod is a method of a listedService.
+type listedMethod struct {
+	Name            string `json:"name"`
+	Input           string `json:"input"`
+	Output          string `json:"output"`
+	ClientStreaming bool   `json:"clientStreaming"`
+	ServerStreaming bool   `json:"serverStreaming"`
+	Deprecated      bool   `json:"deprecated,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// describedMessage is a message schema as list --describe prints it.
+type describedMessage struct {
+	Name   string           `json:"name"`
+	File   string           `json:"file,omitempty"`
+	Fields []describedField `json:"fields"`
+}
+
+// describedField is a field of a describedMessage. Type is the scalar kind
+// or the full name of the message or enum; a map's is "map<K, V>".
+type describedField struct {
+	Name       string   `json:"name"`
+	JSONName   string   `json:"jsonName"`
+	Number     int32    `json:"number"`
+	Type       string   `json:"type"`
+	Repeated   bool     `json:"repeated,omitempty"`
+	Optional   bool     `json:"optional,omitempty"`
+	Oneof      string   `json:"oneof,omitempty"`
+	Values     []string `json:"values,omitempty"` // Enum value names
+	Unresolved bool     `json:"unresolved,omitempty"`
+}
+
+// listCommand runs the list subcommand.
+func listCommand(ctx context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseListFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "grotto list:", err)
+		return exitUsage
+	}
+
+	if err := list(ctx, opts, stdout, newLogger(stderr, opts.verbose)); err != nil {
+		fmt.Fprintln(stderr, "grotto list:", err)
+		return exitCode(err)
+	}
+	return 0
+}
+
+func parseListFlags(args []string, stderr io.Writer) (listOptions, error) {
+	var opts listOptions
+	fs := flag.NewFlagSet("grotto list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&opts.describe, "describe", "", "print the schema of a message, package.Message, instead of the services")
+	fs.DurationVar(&opts.timeout, "timeout", defaultTimeout, "deadline for the whole listing")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: grotto list --addr host:port [--describe pkg.Message] [flags]")
+		fmt.Fprintln(fs.Output(), "\nPrints the services and methods the server exposes as JSON, with any")
+		fmt.Fprintln(fs.Output(), "resolution error on the service or method it belongs to.")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	return opts, opts.validate()
+}
+
+// list connects and lists the services as the app's sidebar would, or
+// describes one message, writing indented JSON to stdout. Services that
+// fail to resolve are listed with their error rather than failing the
+// listing.
+func list(ctx context.Context, opts listOptions, stdout io.Writer, logger *slog.Logger) error {
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	client, err := opts.connect(ctx, logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	services, err := client.ListServices(ctx)
+	if err != nil {
+		return err
+	}
+
+	var out any
+	if opts.describe == "" {
+		out = listServices(services)
+	} else {
+		d, err := client.Describe(opts.describe)
+		if err != nil {
+			return err
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return status.Errorf(codes.NotFound, "%s is not a message", d.FullName())
+		}
+		out = describeMessage(md)
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false) // Keep map<K, V> readable
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// listServices converts services for printing, sorted by name as
+// grottocore lists them, so listings compare cleanly.
+func listServices(services []grottocore.Service) []listedService {
+	listed := make([]listedService, 0, len(services))
+	for _, svc := range services {
+		ls := listedService{
+			Name:       svc.FullName,
+			File:       svc.File,
+			Deprecated: svc.Deprecated,
+			Error:      svc.Error,
+			Methods:    make([]listedMethod, 0, len(svc.Methods)),
+		}
+		for _, m := range svc.Methods {
+			ls.Methods = append(ls.Methods, listedMethod{
+				Name:            m.Name,
+				Input:           m.InputType,
+				Output:          m.OutputType,
+				ClientStreaming: m.ClientStreaming,
+				ServerStreaming: m.ServerStreaming,
+				Deprecated:      m.Deprecated,
+				Error:           m.Error,
+			})
+		}
+		listed = append(listed, ls)
+	}
+	return listed
+}
+
+// describeMessage converts a message's fields, in declaration order, for
+// printing. Nested messages are named, not expanded; describe them in turn.
+func describeMessage(md protoreflect.MessageDescriptor) describedMessage {
+	dm := describedMessage{
+		Name:   string(md.FullName()),
+		Fields: []describedField{},
+	}
+	if fd := md.ParentFile(); fd != nil {
+		dm.File = fd.Path()
+	}
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		df := describedField{
+			Name:     string(fd.Name()),
+			JSONName: fd.JSONName(),
+			Number:   int32(fd.Number()),
+			Type:     fieldType(fd),
+			Repeated: fd.IsList(),
+			Optional: fd.HasOptionalKeyword(),
+		}
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			df.Oneof = string(oneof.Name())
+		}
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		switch {
+		case fd.Message() != nil:
+			df.Unresolved = fd.Message().IsPlaceholder()
+		case fd.Enum() != nil:
+			df.Unresolved = fd.Enum().IsPlaceholder()
+			values := fd.Enum().Values()
+			for j := range values.Len() {
+				df.Values = append(df.Values, string(values.Get(j).Name()))
+			}
+		}
+		dm.Fields = append(dm.Fields, df)
+	}
+	return dm
+}
+
+// fieldType names a field's type: its scalar kind, the full name of its
+// message or enum, or map<K, V>.
+func fieldType(fd protoreflect.FieldDescriptor) string {
+	if fd.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", fieldType(fd.MapKey()), fieldType(fd.MapValue()))
+	}
+	switch {
+	case fd.Message() != nil:
+		return string(fd.Message().FullName())
+	case fd.Enum() != nil:
+		return string(fd.Enum().FullName())
+	}
+	return fd.Kind().String()
+}
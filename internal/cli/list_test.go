@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// runList runs `grotto list` against addr over plaintext with the given
+// extra flags, returning the exit code, stdout and stderr.
+func runList(t *testing.T, addr string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"list", "--addr", addr, "--plaintext"}, args...)
+	code := Run(context.Background(), args, nil, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// assertGolden compares got with testdata/name, or rewrites the file with
+// -update.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run go test ./internal/cli -update to create it")
+	assert.Equal(t, string(want), got)
+}
+
+// brokenFile has a service with one method naming a type no file defines.
+func brokenFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("broken.proto"),
+		Package: proto.String("custom.broken.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Ping")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("BrokenService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Ping"),
+						InputType:  proto.String(".custom.broken.v1.Ping"),
+						OutputType: proto.String(".custom.broken.v1.Ping"),
+					},
+					{
+						Name:            proto.String("Watch"),
+						InputType:       proto.String(".custom.missing.v1.WatchRequest"),
+						OutputType:      proto.String(".custom.broken.v1.Ping"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			},
+		},
+	}
+}
+
+// clashFile declares a message and a service with the same name, so the
+// file cannot be built even leniently and only the service survives, its
+// types unresolved.
+func clashFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("clash.proto"),
+		Package: proto.String("custom.clash.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Ping")},
+			{Name: proto.String("ClashService")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("ClashService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Ping"),
+						InputType:  proto.String(".custom.clash.v1.Ping"),
+						OutputType: proto.String(".custom.clash.v1.Ping"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func startNonCanonicalServer(t *testing.T) *grpctest.Server {
+	t.Helper()
+	files := append(grpctest.NonCanonicalFiles(), brokenFile(), clashFile())
+	return grpctest.StartServer(t, grpctest.WithReflectionFiles(files...))
+}
+
+func TestList_NonCanonical(t *testing.T) {
+	srv := startNonCanonicalServer(t)
+
+	code, out, errOut := runList(t, srv.Addr)
+	require.Equal(t, 0, code, errOut)
+	assertGolden(t, "list_noncanonical.golden", out)
+}
+
+func TestList_Describe(t *testing.T) {
+	srv := startNonCanonicalServer(t)
+
+	for _, name := range []string{"custom.event.v1.Event", "custom.event.v1.EventsByOrg", "custom.common.KeyValues"} {
+		t.Run(name, func(t *testing.T) {
+			code, out, errOut := runList(t, srv.Addr, "--describe", name)
+			require.Equal(t, 0, code, errOut)
+			assertGolden(t, "describe_"+name+".golden", out)
+		})
+	}
+
+	code, out, errOut := runList(t, srv.Addr, "--describe", "custom.event.v1.Nope")
+	assert.Equal(t, int(codes.NotFound), code, errOut)
+	assert.Empty(t, out)
+}
+
+func TestList_Errors(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithReflection(false))
+
+	code, out, errOut := runList(t, srv.Addr)
+	assert.Equal(t, int(codes.Unimplemented), code, errOut)
+	assert.Empty(t, out)
+
+	code, _, errOut = runList(t, srv.Addr, "--protoset", "a", "--import-path", "b")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, errOut, "cannot be combined")
+}
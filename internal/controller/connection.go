@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConnectionController tracks the connection attempt in progress, so it
+// can be cancelled from the UI or when the window closes.
+type ConnectionController struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc // Cancels the last attempt begun
+}
+
+// NewConnectionController returns a controller with no attempt begun.
+func NewConnectionController() *ConnectionController {
+	return &ConnectionController{}
+}
+
+// Begin starts a connection attempt bounded by timeout, returning its
+// context and a func the attempt calls when it is over. An attempt still
+// running is left to finish; Cancel only reaches the latest.
+func (c *ConnectionController) Begin(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+	return ctx, cancel
+}
+
+// Cancel cancels the last attempt begun, reporting whether there was one
+// since the last Cancel. The attempt sees its context cancelled and cleans
+// up after itself.
+func (c *ConnectionController) Cancel() bool {
+	c.mu.Lock()
+	cancel := c.cancel
+	c.cancel = nil
+	c.mu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	return true
+}
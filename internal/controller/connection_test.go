@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionController_Cancel(t *testing.T) {
+	c := NewConnectionController()
+	assert.False(t, c.Cancel(), "no attempt begun")
+
+	first, done := c.Begin(time.Minute)
+	defer done()
+	second, done := c.Begin(time.Minute)
+	defer done()
+
+	// Only the latest attempt is cancelled, and only once
+	assert.True(t, c.Cancel())
+	assert.ErrorIs(t, second.Err(), context.Canceled)
+	assert.NoError(t, first.Err())
+	assert.False(t, c.Cancel())
+
+	ctx, done := c.Begin(time.Nanosecond)
+	defer done()
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
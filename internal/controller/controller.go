@@ -0,0 +1,76 @@
+// Package controller runs calls for the window without touching widgets:
+// the window hands it the call to make and shows the events and results it
+// reports. Connections and methods are reached through the interfaces here,
+// so the controllers can be tested with fakes in place of a server.
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	// ErrNoResolver is returned when there is no connection to look
+	// methods up on.
+	ErrNoResolver = errors.New("reflection client not initialized")
+	// ErrNoInvoker is returned when there is no connection to call on.
+	ErrNoInvoker = errors.New("invoker not initialized")
+)
+
+// MethodResolver finds method descriptors by service and method name, as
+// grpc.ReflectionClient does.
+type MethodResolver interface {
+	GetMethodDescriptor(serviceName, methodName string) (protoreflect.MethodDescriptor, error)
+}
+
+// Invoker calls methods with JSON messages, as grpc.Invoker does; FromGRPC
+// adapts one.
+type Invoker interface {
+	InvokeUnary(ctx context.Context, methodDesc protoreflect.MethodDescriptor, jsonRequest string, md metadata.MD) (string, metadata.MD, metadata.MD, error)
+	InvokeServerStream(ctx context.Context, methodDesc protoreflect.MethodDescriptor, jsonRequest string, md metadata.MD) (<-chan string, <-chan error, <-chan metadata.MD, <-chan metadata.MD)
+	InvokeClientStream(ctx context.Context, methodDesc protoreflect.MethodDescriptor, md metadata.MD) (ClientStream, error)
+}
+
+// ClientStream is an open client streaming call, as grpc.ClientStreamHandle
+// is.
+type ClientStream interface {
+	Method() protoreflect.MethodDescriptor
+	Send(jsonRequest string) error
+	CloseAndReceive() (string, error)
+	Header() (metadata.MD, error)
+	Trailers() metadata.MD
+	SentSize() int
+}
+
+// Session is the connection calls go through. Both return nil while there
+// is no connection.
+type Session interface {
+	MethodResolver() MethodResolver
+	MethodInvoker() Invoker
+}
+
+// grpcInvoker returns a grpc.Invoker's client stream handles as
+// ClientStreams.
+type grpcInvoker struct {
+	*grpc.Invoker
+}
+
+func (i grpcInvoker) InvokeClientStream(ctx context.Context, methodDesc protoreflect.MethodDescriptor, md metadata.MD) (ClientStream, error) {
+	handle, err := i.Invoker.InvokeClientStream(ctx, methodDesc, md)
+	if err != nil {
+		return nil, err
+	}
+	return handle, nil
+}
+
+// FromGRPC returns invoker as an Invoker, or nil when invoker is nil.
+func FromGRPC(invoker *grpc.Invoker) Invoker {
+	if invoker == nil {
+		return nil
+	}
+	return grpcInvoker{invoker}
+}
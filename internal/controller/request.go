@@ -0,0 +1,540 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrNoClientStream is returned when finishing a client stream that was
+// cancelled, or never opened.
+var ErrNoClientStream = errors.New("client stream was cancelled")
+
+// CallError is a call that failed once made: the server or the transport
+// rejected it, as opposed to it never being made. Err is the invoker's
+// error, usually carrying a gRPC status.
+type CallError struct {
+	Err error
+}
+
+func (e *CallError) Error() string { return e.Err.Error() }
+func (e *CallError) Unwrap() error { return e.Err }
+
+// ResolveError is a method that could not be looked up.
+type ResolveError struct {
+	Service, Method string
+	Err             error
+}
+
+func (e *ResolveError) Error() string { return e.Err.Error() }
+func (e *ResolveError) Unwrap() error { return e.Err }
+
+// Call is a request to make.
+type Call struct {
+	Service  string
+	Method   string
+	Desc     protoreflect.MethodDescriptor // Resolved method; client streams look it up when nil
+	Body     string                        // JSON request; the message to send, for client streams
+	Metadata map[string]string             // Request metadata, -bin values base64 encoded
+}
+
+// Name returns the method as history records it, "service/method".
+func (c Call) Name() string {
+	return c.Service + "/" + c.Method
+}
+
+// Result is how a unary call or a client stream ended.
+type Result struct {
+	Response string // JSON response as the server sent it; empty on error
+	Headers  metadata.MD
+	Trailers metadata.MD
+	Duration time.Duration
+	Timing   *grpc.TimingRecorder
+	Err      error
+
+	// Unary calls only: the attempts made under the connection's retry
+	// policy
+	Attempts grpc.CallAttempts
+
+	// Client streams only: the method called and the encoded size of the
+	// messages sent
+	Method   protoreflect.MethodDescriptor
+	SentSize int
+}
+
+// StreamEvents receives a server stream's events. They are called from the
+// goroutine reading the stream, in order.
+type StreamEvents struct {
+	OnHeaders func(headers metadata.MD) // Once, possibly long before the first message
+	OnMessage func(json string)
+	OnDone    func(result StreamResult)
+}
+
+// StreamResult is how a server stream ended.
+type StreamResult struct {
+	Headers  metadata.MD
+	Trailers metadata.MD
+	Messages int
+	Duration time.Duration
+	Timing   *grpc.TimingRecorder
+	Err      error // nil when the server finished the stream
+}
+
+// Operation names the kind of call Cancel stopped.
+type Operation int
+
+const (
+	OpNone Operation = iota
+	OpServerStream
+	OpClientStream
+	OpUnary
+)
+
+// clientStream is the open client stream and what ends it.
+type clientStream struct {
+	stream ClientStream
+	timing *grpc.TimingRecorder
+	cancel context.CancelFunc
+}
+
+// RequestController makes unary, server streaming and client streaming
+// calls on the session's connection, one of each at a time. Its methods
+// may be called from any goroutine.
+type RequestController struct {
+	session Session
+	logger  *slog.Logger
+
+	mu           sync.Mutex
+	unaryCancel  context.CancelFunc
+	serverCancel context.CancelFunc
+	serverDone   <-chan struct{} // Closed once the server stream ends
+	client       *clientStream   // nil when no client stream is open
+
+	// Server stream goroutines, until they have reported their result
+	streams sync.WaitGroup
+}
+
+// NewRequestController returns a controller calling through session.
+func NewRequestController(session Session, logger *slog.Logger) *RequestController {
+	return &RequestController{session: session, logger: logger}
+}
+
+// Resolve looks a method up on the connection, returning a *ResolveError
+// when it cannot be found.
+func (c *RequestController) Resolve(service, method string) (protoreflect.MethodDescriptor, error) {
+	resolver := c.session.MethodResolver()
+	if resolver == nil {
+		return nil, ErrNoResolver
+	}
+	desc, err := resolver.GetMethodDescriptor(service, method)
+	if err != nil {
+		c.logger.Error("failed to get method descriptor", slog.Any("error", err))
+		return nil, &ResolveError{Service: service, Method: method, Err: err}
+	}
+	return desc, nil
+}
+
+// Unary makes a unary call, cancelled after timeout or by Cancel, and
+// blocks until it ends. An error means the call was never made; a call
+// that failed reports it in Result.Err.
+func (c *RequestController) Unary(call Call, timeout time.Duration) (Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c.mu.Lock()
+	c.unaryCancel = cancel
+	c.mu.Unlock()
+
+	c.logger.Debug("sending unary request",
+		slog.String("service", call.Service),
+		slog.String("method", call.Method),
+	)
+	startTime := time.Now()
+
+	// Convert metadata map to grpc metadata, decoding -bin values
+	md, err := grpc.BuildMetadata(call.Metadata)
+	if err != nil {
+		return Result{}, err
+	}
+	invoker := c.session.MethodInvoker()
+	if invoker == nil {
+		return Result{}, ErrNoInvoker
+	}
+
+	ctx, timing := grpc.WithCallTiming(ctx)
+	ctx, attempts := grpc.WithCallAttempts(ctx)
+	respJSON, respHeaders, respTrailers, err := invoker.InvokeUnary(ctx, call.Desc, call.Body, md)
+	result := Result{
+		Response: respJSON,
+		Headers:  respHeaders,
+		Trailers: respTrailers,
+		Duration: time.Since(startTime),
+		Timing:   timing,
+		Attempts: attempts.Attempts(),
+	}
+	if err != nil {
+		c.logger.Error("RPC invocation failed", slog.Any("error", err))
+		result.Err = err
+		return result, nil
+	}
+
+	c.logger.Info("RPC completed successfully",
+		slog.String("method", call.Method),
+		slog.Duration("duration", result.Duration),
+		slog.Int("attempts", result.Attempts.Made),
+	)
+	return result, nil
+}
+
+// StartServerStream starts a server streaming call, ending any still open,
+// and reads it in a goroutine, reporting to events until it ends or is
+// stopped. An error means the call was never made.
+func (c *RequestController) StartServerStream(call Call, events StreamEvents) error {
+	c.StopServerStream()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.serverCancel = cancel
+	c.serverDone = ctx.Done()
+	c.mu.Unlock()
+
+	c.logger.Debug("sending server stream request",
+		slog.String("service", call.Service),
+		slog.String("method", call.Method),
+	)
+
+	// Convert metadata map to grpc metadata, decoding -bin values
+	md, err := grpc.BuildMetadata(call.Metadata)
+	if err != nil {
+		cancel()
+		return err
+	}
+	invoker := c.session.MethodInvoker()
+	if invoker == nil {
+		cancel()
+		return ErrNoInvoker
+	}
+
+	startTime := time.Now()
+	ctx, timing := grpc.WithCallTiming(ctx)
+	msgChan, errChan, headerChan, trailerChan := invoker.InvokeServerStream(ctx, call.Desc, call.Body, md)
+
+	c.streams.Go(func() {
+		defer cancel() // ensure context is cleaned up on all exit paths
+		messageCount := 0
+		var headers metadata.MD
+		showHeaders := func(hdr metadata.MD) {
+			headers = hdr
+			events.OnHeaders(hdr)
+		}
+
+		for {
+			select {
+			case jsonMsg, ok := <-msgChan:
+				if !ok {
+					// The error is sent before the channels close
+					msgChan = nil
+					continue
+				}
+
+				// Headers are sent before the first message; report them
+				// first when both are waiting
+				if headers == nil {
+					select {
+					case hdr, ok := <-headerChan:
+						if ok {
+							showHeaders(hdr)
+						}
+					default:
+					}
+				}
+
+				messageCount++
+				events.OnMessage(jsonMsg)
+
+			case err, ok := <-errChan:
+				if !ok {
+					return
+				}
+
+				// Messages the server sent before ending the stream may
+				// still be waiting
+				if msgChan != nil {
+					for jsonMsg := range msgChan {
+						messageCount++
+						events.OnMessage(jsonMsg)
+					}
+				}
+				result := StreamResult{
+					Headers:  headers,
+					Messages: messageCount,
+					Duration: time.Since(startTime),
+					Timing:   timing,
+				}
+
+				// Trailers are sent before the error by the invoker
+				select {
+				case result.Trailers = <-trailerChan:
+				default:
+				}
+
+				if err == io.EOF {
+					c.logger.Info("server stream completed successfully",
+						slog.String("method", call.Method),
+						slog.Int("message_count", messageCount),
+						slog.Duration("duration", result.Duration),
+					)
+				} else {
+					c.logger.Error("server stream error",
+						slog.String("method", call.Method),
+						slog.Int("message_count", messageCount),
+						slog.Any("error", err),
+					)
+					result.Err = err
+				}
+				events.OnDone(result)
+				return
+
+			case hdr, ok := <-headerChan:
+				if !ok {
+					headerChan = nil // Stop selecting the closed channel
+					continue
+				}
+				showHeaders(hdr)
+			}
+		}
+	})
+	return nil
+}
+
+// StopServerStream cancels the open server stream, reporting whether there
+// was one. Its events end with OnDone.
+func (c *RequestController) StopServerStream() bool {
+	c.mu.Lock()
+	cancel := c.serverCancel
+	c.serverCancel = nil
+	c.mu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	return true
+}
+
+// ClientStreamOpen reports whether a client stream is open.
+func (c *RequestController) ClientStreamOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client != nil
+}
+
+// SendClientStream sends call.Body on the open client stream, opening one
+// for call's method first when none is open. A stream that fails to send
+// is closed, so the next send opens a new one. Failures of the call are
+// returned as a *CallError and failed lookups as a *ResolveError.
+func (c *RequestController) SendClientStream(call Call) error {
+	c.mu.Lock()
+	cs := c.client
+	c.mu.Unlock()
+	if cs == nil {
+		var err error
+		if cs, err = c.openClientStream(call); err != nil {
+			return err
+		}
+	}
+
+	if err := cs.stream.Send(call.Body); err != nil {
+		c.logger.Error("failed to send client stream message", slog.Any("error", err))
+		c.mu.Lock()
+		if c.client == cs {
+			c.client = nil
+		}
+		c.mu.Unlock()
+		cs.cancel()
+		return &CallError{Err: err}
+	}
+
+	c.logger.Debug("client stream message sent",
+		slog.String("method", call.Method),
+	)
+	return nil
+}
+
+// openClientStream opens a client stream for call's method, looking it up
+// unless call.Desc is set, and makes it the open one.
+func (c *RequestController) openClientStream(call Call) (*clientStream, error) {
+	desc := call.Desc
+	if desc == nil {
+		var err error
+		if desc, err = c.Resolve(call.Service, call.Method); err != nil {
+			return nil, err
+		}
+	}
+	if !desc.IsStreamingClient() {
+		return nil, fmt.Errorf("method %s is not a client streaming RPC", call.Method)
+	}
+
+	// Convert metadata map to grpc metadata, decoding -bin values
+	md, err := grpc.BuildMetadata(call.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	invoker := c.session.MethodInvoker()
+	if invoker == nil {
+		return nil, ErrNoInvoker
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, timing := grpc.WithCallTiming(ctx)
+	stream, err := invoker.InvokeClientStream(ctx, desc, md)
+	if err != nil {
+		cancel()
+		c.logger.Error("failed to start client stream", slog.Any("error", err))
+		return nil, &CallError{Err: err}
+	}
+
+	cs := &clientStream{stream: stream, timing: timing, cancel: cancel}
+	c.mu.Lock()
+	c.client = cs
+	c.mu.Unlock()
+	c.logger.Info("client stream started",
+		slog.String("service", call.Service),
+		slog.String("method", call.Method),
+	)
+	return cs, nil
+}
+
+// FinishClientStream closes the open client stream and blocks until the
+// server responds. It returns ErrNoClientStream when no stream is open; a
+// call that failed reports it in Result.Err.
+func (c *RequestController) FinishClientStream() (Result, error) {
+	c.mu.Lock()
+	cs := c.client
+	c.mu.Unlock()
+	if cs == nil {
+		return Result{}, ErrNoClientStream
+	}
+
+	startTime := time.Now()
+	respJSON, err := cs.stream.CloseAndReceive()
+
+	// Headers and trailers are available once the stream ends
+	headers, _ := cs.stream.Header()
+	result := Result{
+		Response: respJSON,
+		Headers:  headers,
+		Trailers: cs.stream.Trailers(),
+		Duration: time.Since(startTime),
+		Timing:   cs.timing,
+		Method:   cs.stream.Method(),
+		SentSize: cs.stream.SentSize(),
+	}
+
+	c.mu.Lock()
+	if c.client == cs {
+		c.client = nil
+	}
+	c.mu.Unlock()
+	cs.cancel()
+
+	if err != nil {
+		c.logger.Error("client stream failed", slog.Any("error", err))
+		result.Err = err
+		return result, nil
+	}
+	c.logger.Info("client stream completed successfully",
+		slog.String("method", string(result.Method.Name())),
+		slog.Duration("duration", result.Duration),
+	)
+	return result, nil
+}
+
+// Cancel cancels one call, in order of preference the server stream, the
+// client stream or the unary call, and reports which.
+func (c *RequestController) Cancel() Operation {
+	c.mu.Lock()
+	serverCancel := c.serverCancel
+	client := c.client
+	unaryCancel := c.unaryCancel
+	switch {
+	case serverCancel != nil:
+		c.serverCancel = nil
+		c.mu.Unlock()
+		serverCancel()
+		return OpServerStream
+
+	case client != nil:
+		c.client = nil
+		c.mu.Unlock()
+		// CloseAndReceive blocks, so run in goroutine
+		go func() {
+			client.stream.CloseAndReceive()
+			client.cancel()
+		}()
+		return OpClientStream
+
+	case unaryCancel != nil:
+		c.unaryCancel = nil
+		c.mu.Unlock()
+		unaryCancel()
+		return OpUnary
+	}
+	c.mu.Unlock()
+	return OpNone
+}
+
+// CancelAll cancels every call in progress.
+// Cancel funcs are called outside the lock to avoid potential deadlocks.
+func (c *RequestController) CancelAll() {
+	c.mu.Lock()
+	unaryCancel := c.unaryCancel
+	c.unaryCancel = nil
+	serverCancel := c.serverCancel
+	c.serverCancel = nil
+	client := c.client
+	c.client = nil
+	c.mu.Unlock()
+
+	if unaryCancel != nil {
+		unaryCancel()
+	}
+	if serverCancel != nil {
+		serverCancel()
+	}
+	if client != nil {
+		client.cancel()
+		// CloseAndReceive blocks, so run in goroutine
+		go client.stream.CloseAndReceive()
+	}
+}
+
+// Wait blocks until every server stream has ended and its OnDone has
+// returned, e.g. after CancelAll.
+func (c *RequestController) Wait() {
+	c.streams.Wait()
+}
+
+// StreamOpen reports whether a server or client stream is open.
+func (c *RequestController) StreamOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return true
+	}
+	if c.serverDone == nil {
+		return false
+	}
+	select {
+	case <-c.serverDone:
+		return false
+	default:
+		return true
+	}
+}
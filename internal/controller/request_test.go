@@ -0,0 +1,412 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func testMethod(name string) protoreflect.MethodDescriptor {
+	return pb.File_grpc_test_proto.Services().ByName("TestService").Methods().ByName(protoreflect.Name(name))
+}
+
+// fakeSession hands out a fake connection; nil fields act disconnected.
+type fakeSession struct {
+	resolver *fakeResolver
+	invoker  *fakeInvoker
+}
+
+func (s *fakeSession) MethodResolver() MethodResolver {
+	if s.resolver == nil {
+		return nil
+	}
+	return s.resolver
+}
+
+func (s *fakeSession) MethodInvoker() Invoker {
+	if s.invoker == nil {
+		return nil
+	}
+	return s.invoker
+}
+
+// fakeResolver resolves TestService's methods.
+type fakeResolver struct{}
+
+func (fakeResolver) GetMethodDescriptor(serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
+	if serviceName != "grpctest.TestService" {
+		return nil, status.Errorf(codes.NotFound, "service %s not found", serviceName)
+	}
+	return testMethod(methodName), nil
+}
+
+// fakeInvoker answers calls as its funcs say and records what was sent.
+type fakeInvoker struct {
+	unary        func(ctx context.Context, jsonRequest string) (string, error)
+	serverStream func(ctx context.Context, msgs chan<- string) error // Ends the stream with the error it returns
+	clientStream *fakeClientStream
+	openErr      error
+
+	mu       sync.Mutex
+	metadata []metadata.MD
+}
+
+func (i *fakeInvoker) record(md metadata.MD) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.metadata = append(i.metadata, md)
+}
+
+func (i *fakeInvoker) InvokeUnary(ctx context.Context, _ protoreflect.MethodDescriptor, jsonRequest string, md metadata.MD) (string, metadata.MD, metadata.MD, error) {
+	i.record(md)
+	resp, err := i.unary(ctx, jsonRequest)
+	return resp, metadata.Pairs("x-request-id", "r-1"), metadata.Pairs("x-trailer", "t"), err
+}
+
+func (i *fakeInvoker) InvokeServerStream(ctx context.Context, _ protoreflect.MethodDescriptor, _ string, md metadata.MD) (<-chan string, <-chan error, <-chan metadata.MD, <-chan metadata.MD) {
+	i.record(md)
+	msgChan := make(chan string)
+	errChan := make(chan error, 1)
+	headerChan := make(chan metadata.MD, 1)
+	trailerChan := make(chan metadata.MD, 1)
+	go func() {
+		defer close(msgChan)
+		defer close(errChan)
+		defer close(headerChan)
+		defer close(trailerChan)
+		headerChan <- metadata.Pairs("x-stream", "s-1")
+		err := i.serverStream(ctx, msgChan)
+		trailerChan <- metadata.Pairs("x-count", "done")
+		errChan <- err
+	}()
+	return msgChan, errChan, headerChan, trailerChan
+}
+
+func (i *fakeInvoker) InvokeClientStream(_ context.Context, methodDesc protoreflect.MethodDescriptor, md metadata.MD) (ClientStream, error) {
+	i.record(md)
+	if i.openErr != nil {
+		return nil, i.openErr
+	}
+	i.clientStream.method = methodDesc
+	return i.clientStream, nil
+}
+
+// fakeClientStream collects what is sent and answers with the count.
+type fakeClientStream struct {
+	method  protoreflect.MethodDescriptor
+	sendErr error
+
+	mu     sync.Mutex
+	sent   []string
+	closed int
+}
+
+func (s *fakeClientStream) Method() protoreflect.MethodDescriptor { return s.method }
+func (s *fakeClientStream) Header() (metadata.MD, error)          { return metadata.Pairs("x-h", "1"), nil }
+func (s *fakeClientStream) Trailers() metadata.MD                 { return metadata.Pairs("x-t", "1") }
+
+func (s *fakeClientStream) Send(jsonRequest string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	s.sent = append(s.sent, jsonRequest)
+	return nil
+}
+
+func (s *fakeClientStream) CloseAndReceive() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed++
+	return fmt.Sprintf(`{"count": %d}`, len(s.sent)), nil
+}
+
+func (s *fakeClientStream) SentSize() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return 10 * len(s.sent)
+}
+
+func (s *fakeClientStream) closeCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func TestRequestController_Resolve(t *testing.T) {
+	c := NewRequestController(&fakeSession{}, testLogger)
+	_, err := c.Resolve("grpctest.TestService", "UnaryEcho")
+	assert.ErrorIs(t, err, ErrNoResolver)
+
+	c = NewRequestController(&fakeSession{resolver: &fakeResolver{}}, testLogger)
+	desc, err := c.Resolve("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.Name("UnaryEcho"), desc.Name())
+
+	_, err = c.Resolve("other.Service", "UnaryEcho")
+	var resolveErr *ResolveError
+	require.ErrorAs(t, err, &resolveErr)
+	assert.Equal(t, codes.NotFound, status.Code(resolveErr.Err))
+}
+
+func TestRequestController_Unary(t *testing.T) {
+	invoker := &fakeInvoker{unary: func(_ context.Context, req string) (string, error) {
+		return req, nil
+	}}
+	c := NewRequestController(&fakeSession{invoker: invoker}, testLogger)
+	call := Call{
+		Service:  "grpctest.TestService",
+		Method:   "UnaryEcho",
+		Desc:     testMethod("UnaryEcho"),
+		Body:     `{"item": {"id": "1"}}`,
+		Metadata: map[string]string{"x-tenant": "acme"},
+	}
+
+	result, err := c.Unary(call, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+	assert.Equal(t, call.Body, result.Response)
+	assert.Equal(t, []string{"r-1"}, result.Headers.Get("x-request-id"))
+	assert.Equal(t, []string{"t"}, result.Trailers.Get("x-trailer"))
+	assert.NotNil(t, result.Timing)
+	assert.Equal(t, []string{"acme"}, invoker.metadata[0].Get("x-tenant"))
+	assert.Equal(t, "grpctest.TestService/UnaryEcho", call.Name())
+
+	// A call that fails keeps what the server sent with the failure
+	invoker.unary = func(context.Context, string) (string, error) {
+		return "", status.Error(codes.PermissionDenied, "no")
+	}
+	result, err = c.Unary(call, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(result.Err))
+	assert.Equal(t, []string{"r-1"}, result.Headers.Get("x-request-id"))
+
+	// Calls that cannot be made are errors of their own
+	call.Metadata = map[string]string{"x-data-bin": "not base64!"}
+	_, err = c.Unary(call, time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewRequestController(&fakeSession{}, testLogger).Unary(Call{Desc: testMethod("UnaryEcho")}, time.Minute)
+	assert.ErrorIs(t, err, ErrNoInvoker)
+}
+
+func TestRequestController_UnaryCancel(t *testing.T) {
+	started := make(chan struct{})
+	invoker := &fakeInvoker{unary: func(ctx context.Context, _ string) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", status.FromContextError(ctx.Err()).Err()
+	}}
+	c := NewRequestController(&fakeSession{invoker: invoker}, testLogger)
+
+	done := make(chan Result)
+	go func() {
+		result, _ := c.Unary(Call{Desc: testMethod("UnaryEcho"), Body: "{}"}, time.Minute)
+		done <- result
+	}()
+	<-started
+	assert.False(t, c.StreamOpen(), "unary calls are not streams")
+	assert.Equal(t, OpUnary, c.Cancel())
+	assert.Equal(t, codes.Canceled, status.Code((<-done).Err))
+	assert.Equal(t, OpNone, c.Cancel())
+}
+
+// streamRecorder collects a server stream's events.
+type streamRecorder struct {
+	mu       sync.Mutex
+	events   []string
+	messages []string
+	done     chan StreamResult
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{done: make(chan StreamResult, 1)}
+}
+
+func (r *streamRecorder) handlers() StreamEvents {
+	return StreamEvents{
+		OnHeaders: func(md metadata.MD) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, "headers")
+		},
+		OnMessage: func(json string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, "message")
+			r.messages = append(r.messages, json)
+		},
+		OnDone: func(result StreamResult) {
+			r.done <- result
+		},
+	}
+}
+
+func TestRequestController_ServerStream(t *testing.T) {
+	invoker := &fakeInvoker{serverStream: func(ctx context.Context, msgs chan<- string) error {
+		for _, msg := range []string{`{"n": 1}`, `{"n": 2}`} {
+			msgs <- msg
+		}
+		return io.EOF
+	}}
+	c := NewRequestController(&fakeSession{invoker: invoker}, testLogger)
+	rec := newStreamRecorder()
+
+	require.NoError(t, c.StartServerStream(Call{Desc: testMethod("StreamItems"), Body: "{}"}, rec.handlers()))
+	result := <-rec.done
+	require.NoError(t, result.Err, "the server finishing the stream is no error")
+	assert.Equal(t, 2, result.Messages)
+	assert.Equal(t, []string{"headers", "message", "message"}, rec.events, "headers come first")
+	assert.Equal(t, []string{`{"n": 1}`, `{"n": 2}`}, rec.messages)
+	assert.Equal(t, []string{"s-1"}, result.Headers.Get("x-stream"))
+	assert.Equal(t, []string{"done"}, result.Trailers.Get("x-count"))
+	assert.Eventually(t, func() bool { return !c.StreamOpen() }, time.Second, 10*time.Millisecond)
+
+	invoker.serverStream = func(context.Context, chan<- string) error {
+		return status.Error(codes.Unavailable, "gone")
+	}
+	rec = newStreamRecorder()
+	require.NoError(t, c.StartServerStream(Call{Desc: testMethod("StreamItems"), Body: "{}"}, rec.handlers()))
+	assert.Equal(t, codes.Unavailable, status.Code((<-rec.done).Err))
+
+	// A stream that cannot be started is not left open
+	err := c.StartServerStream(Call{Desc: testMethod("StreamItems"), Metadata: map[string]string{"x-bin": "!"}}, rec.handlers())
+	assert.Error(t, err)
+	assert.False(t, c.StreamOpen())
+}
+
+func TestRequestController_ServerStreamStop(t *testing.T) {
+	invoker := &fakeInvoker{serverStream: func(ctx context.Context, msgs chan<- string) error {
+		msgs <- `{}`
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	c := NewRequestController(&fakeSession{invoker: invoker}, testLogger)
+	first := newStreamRecorder()
+	require.NoError(t, c.StartServerStream(Call{Desc: testMethod("StreamItems")}, first.handlers()))
+	assert.True(t, c.StreamOpen())
+
+	// Starting another stream ends the first
+	second := newStreamRecorder()
+	require.NoError(t, c.StartServerStream(Call{Desc: testMethod("StreamItems")}, second.handlers()))
+	assert.ErrorIs(t, (<-first.done).Err, context.Canceled)
+	assert.True(t, c.StreamOpen())
+
+	assert.Equal(t, OpServerStream, c.Cancel())
+	result := <-second.done
+	assert.ErrorIs(t, result.Err, context.Canceled)
+	assert.Equal(t, 1, result.Messages)
+	assert.Eventually(t, func() bool { return !c.StreamOpen() }, time.Second, 10*time.Millisecond)
+	assert.False(t, c.StopServerStream(), "nothing left to stop")
+}
+
+func TestRequestController_WaitAfterCancelAll(t *testing.T) {
+	invoker := &fakeInvoker{serverStream: func(ctx context.Context, msgs chan<- string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	c := NewRequestController(&fakeSession{invoker: invoker}, testLogger)
+	var reported atomic.Bool
+	events := newStreamRecorder().handlers()
+	events.OnDone = func(StreamResult) {
+		time.Sleep(10 * time.Millisecond)
+		reported.Store(true)
+	}
+	require.NoError(t, c.StartServerStream(Call{Desc: testMethod("StreamItems")}, events))
+
+	c.CancelAll()
+	c.Wait()
+	assert.True(t, reported.Load(), "Wait returns only once OnDone has")
+}
+
+func TestRequestController_ClientStream(t *testing.T) {
+	stream := &fakeClientStream{}
+	invoker := &fakeInvoker{clientStream: stream}
+	c := NewRequestController(&fakeSession{resolver: &fakeResolver{}, invoker: invoker}, testLogger)
+	call := Call{
+		Service:  "grpctest.TestService",
+		Method:   "CollectItems",
+		Metadata: map[string]string{"x-tenant": "acme"},
+	}
+
+	_, err := c.FinishClientStream()
+	assert.ErrorIs(t, err, ErrNoClientStream)
+
+	// The first send opens the stream; the rest reuse it
+	for _, body := range []string{`{"id": "1"}`, `{"id": "2"}`} {
+		call.Body = body
+		require.NoError(t, c.SendClientStream(call))
+	}
+	assert.True(t, c.ClientStreamOpen())
+	assert.True(t, c.StreamOpen())
+	assert.Len(t, invoker.metadata, 1)
+	assert.Equal(t, []string{`{"id": "1"}`, `{"id": "2"}`}, stream.sent)
+
+	result, err := c.FinishClientStream()
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+	assert.JSONEq(t, `{"count": 2}`, result.Response)
+	assert.Equal(t, 20, result.SentSize)
+	assert.Equal(t, protoreflect.Name("CollectItems"), result.Method.Name())
+	assert.Equal(t, []string{"1"}, result.Trailers.Get("x-t"))
+	assert.False(t, c.ClientStreamOpen())
+}
+
+func TestRequestController_ClientStreamErrors(t *testing.T) {
+	stream := &fakeClientStream{}
+	invoker := &fakeInvoker{clientStream: stream}
+	c := NewRequestController(&fakeSession{resolver: &fakeResolver{}, invoker: invoker}, testLogger)
+	call := Call{Service: "grpctest.TestService", Method: "CollectItems", Body: "{}"}
+
+	// Methods that are not client streams are refused before any call
+	err := c.SendClientStream(Call{Service: call.Service, Method: "UnaryEcho", Body: "{}"})
+	require.Error(t, err)
+	var callErr *CallError
+	assert.False(t, errors.As(err, &callErr))
+	var resolveErr *ResolveError
+	assert.ErrorAs(t, c.SendClientStream(Call{Service: "other.Service", Method: "CollectItems"}), &resolveErr)
+	assert.Empty(t, invoker.metadata)
+
+	invoker.openErr = status.Error(codes.Unavailable, "down")
+	require.ErrorAs(t, c.SendClientStream(call), &callErr)
+	assert.Equal(t, codes.Unavailable, status.Code(callErr.Err))
+	assert.False(t, c.ClientStreamOpen())
+
+	// A stream failing to send is closed, so the next send opens another
+	invoker.openErr = nil
+	stream.sendErr = status.Error(codes.Internal, "broken")
+	require.ErrorAs(t, c.SendClientStream(call), &callErr)
+	assert.False(t, c.ClientStreamOpen())
+	stream.sendErr = nil
+	require.NoError(t, c.SendClientStream(call))
+	assert.Len(t, invoker.metadata, 3)
+
+	// Cancelling closes the stream in the background
+	assert.Equal(t, OpClientStream, c.Cancel())
+	assert.False(t, c.ClientStreamOpen())
+	assert.Eventually(t, func() bool { return stream.closeCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, c.SendClientStream(call))
+	c.CancelAll()
+	assert.False(t, c.StreamOpen())
+	assert.Eventually(t, func() bool { return stream.closeCount() == 2 }, time.Second, 10*time.Millisecond)
+	_, err = c.FinishClientStream()
+	assert.ErrorIs(t, err, ErrNoClientStream)
+}
@@ -0,0 +1,239 @@
+// Package diff computes line-based differences between two texts, with a
+// JSON mode that canonicalizes both sides first so reordered object keys do
+// not show up as changes.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Op says whether a line is common to both texts or only in one of them.
+type Op int
+
+const (
+	Equal  Op = iota // in both texts
+	Delete           // only in the old text
+	Insert           // only in the new text
+)
+
+// Line is one line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Hunk is a run of changes with surrounding context, as in a unified diff.
+// Starts are 1-based line numbers in the old and new texts.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Header returns the hunk's unified diff header, e.g. "@@ -3,4 +3,5 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// maxTableCells bounds the LCS table. Larger middles (after trimming the
+// common prefix and suffix) are reported as wholly replaced.
+const maxTableCells = 1 << 22
+
+// Lines diffs two texts split into lines, returning every line of both in
+// order: common lines as Equal, the rest as Delete or Insert. Deletions come
+// before insertions within a change.
+func Lines(a, b []string) []Line {
+	// Common prefix and suffix need no table
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	out := make([]Line, 0, len(a)+len(b)-pre-suf)
+	for _, s := range a[:pre] {
+		out = append(out, Line{Equal, s})
+	}
+	out = append(out, middle(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, s := range a[len(a)-suf:] {
+		out = append(out, Line{Equal, s})
+	}
+	return out
+}
+
+// middle diffs the part between the common prefix and suffix using a
+// longest common subsequence table.
+func middle(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 || (n+1)*(m+1) > maxTableCells {
+		return replace(a, b)
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	w := m + 1
+	lcs := make([]int32, (n+1)*w)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+
+	out := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, Line{Equal, a[i]})
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			out = append(out, Line{Delete, a[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, b[j]})
+			j++
+		}
+	}
+	return append(out, replace(a[i:], b[j:])...)
+}
+
+// replace reports all of a as deleted and all of b as inserted.
+func replace(a, b []string) []Line {
+	out := make([]Line, 0, len(a)+len(b))
+	for _, s := range a {
+		out = append(out, Line{Delete, s})
+	}
+	for _, s := range b {
+		out = append(out, Line{Insert, s})
+	}
+	return out
+}
+
+// Text diffs two texts line by line.
+func Text(a, b string) []Line {
+	return Lines(splitLines(a), splitLines(b))
+}
+
+// JSON diffs two JSON documents after canonicalizing them (see
+// Canonicalize), so only real differences in content show up.
+func JSON(a, b string) []Line {
+	return Text(Canonicalize(a), Canonicalize(b))
+}
+
+// Canonicalize re-indents a JSON document with object keys sorted and
+// numbers kept as written. Text that is not valid JSON is returned as is.
+func Canonicalize(s string) string {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return s
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Changed reports whether lines contain any insertion or deletion.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Hunks groups the changes in lines into hunks with up to context unchanged
+// lines on either side. Changes closer than 2*context lines share a hunk.
+func Hunks(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	oldLine, newLine := 1, 1 // next line number in each text
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			i++
+			oldLine++
+			newLine++
+			continue
+		}
+
+		// Start a hunk with leading context
+		lead := 0
+		for lead < context && i-lead-1 >= 0 && lines[i-lead-1].Op == Equal {
+			lead++
+		}
+		h := Hunk{OldStart: oldLine - lead, NewStart: newLine - lead}
+		h.Lines = append(h.Lines, lines[i-lead:i]...)
+		h.OldLines, h.NewLines = lead, lead
+
+		// Extend through changes and short runs of context between them
+		for i < len(lines) {
+			if lines[i].Op != Equal {
+				h.Lines = append(h.Lines, lines[i])
+				if lines[i].Op == Delete {
+					h.OldLines++
+					oldLine++
+				} else {
+					h.NewLines++
+					newLine++
+				}
+				i++
+				continue
+			}
+			run := 0
+			for i+run < len(lines) && lines[i+run].Op == Equal {
+				run++
+			}
+			if i+run == len(lines) || run > 2*context {
+				run = min(run, context)
+				h.Lines = append(h.Lines, lines[i:i+run]...)
+				h.OldLines += run
+				h.NewLines += run
+				oldLine += run
+				newLine += run
+				i += run
+				break
+			}
+			h.Lines = append(h.Lines, lines[i:i+run]...)
+			h.OldLines += run
+			h.NewLines += run
+			oldLine += run
+			newLine += run
+			i += run
+		}
+
+		// An empty side starts at the line before, as in unified diffs
+		if h.OldLines == 0 {
+			h.OldStart--
+		}
+		if h.NewLines == 0 {
+			h.NewStart--
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+// splitLines splits s into lines; "" has none.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// render formats lines as in a unified diff body.
+func render(lines []Line) string {
+	var b strings.Builder
+	for _, l := range lines {
+		switch l.Op {
+		case Equal:
+			b.WriteString(" ")
+		case Delete:
+			b.WriteString("-")
+		case Insert:
+			b.WriteString("+")
+		}
+		b.WriteString(l.Text + "\n")
+	}
+	return b.String()
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"identical", "a\nb", "a\nb", " a\n b\n"},
+		{"both empty", "", "", ""},
+		{"from empty", "", "a\nb", "+a\n+b\n"},
+		{"to empty", "a\nb", "", "-a\n-b\n"},
+		{"changed middle", "a\nb\nc", "a\nx\nc", " a\n-b\n+x\n c\n"},
+		{"insert", "a\nc", "a\nb\nc", " a\n+b\n c\n"},
+		{"delete", "a\nb\nc", "a\nc", " a\n-b\n c\n"},
+		{"interleaved", "a\nb\nc\nd", "b\nx\nd\ne", "-a\n b\n-c\n+x\n d\n+e\n"},
+		{"trailing newline ignored", "a\n", "a", " a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, render(Text(tt.a, tt.b)))
+		})
+	}
+}
+
+func TestLines_LargeMiddleReplaced(t *testing.T) {
+	// Too big for the LCS table: the differing middle is replaced wholesale
+	n := 3000
+	a := make([]string, n)
+	b := make([]string, n)
+	for i := range a {
+		a[i] = "a" + strings.Repeat("x", i%7)
+		b[i] = "b" + strings.Repeat("x", i%7)
+	}
+	a = append([]string{"head"}, append(a, "tail")...)
+	b = append([]string{"head"}, append(b, "tail")...)
+
+	lines := Lines(a, b)
+	require.Len(t, lines, 2*n+2)
+	assert.Equal(t, Line{Equal, "head"}, lines[0])
+	assert.Equal(t, Delete, lines[1].Op)
+	assert.Equal(t, Insert, lines[n+1].Op)
+	assert.Equal(t, Line{Equal, "tail"}, lines[len(lines)-1])
+}
+
+func TestCanonicalize(t *testing.T) {
+	got := Canonicalize(`{"b": 1, "a": {"d": [1, 2.50], "c": "<x>"}}`)
+	assert.Equal(t, "{\n  \"a\": {\n    \"c\": \"<x>\",\n    \"d\": [\n      1,\n      2.50\n    ]\n  },\n  \"b\": 1\n}", got)
+
+	// Not JSON, or trailing garbage: unchanged
+	assert.Equal(t, "not json", Canonicalize("not json"))
+	assert.Equal(t, `{} {}`, Canonicalize(`{} {}`))
+}
+
+func TestJSON_IgnoresKeyOrder(t *testing.T) {
+	lines := JSON(`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`)
+	assert.False(t, Changed(lines))
+
+	lines = JSON(`{"a": 1, "b": 2}`, `{"b": 3, "a": 1}`)
+	assert.True(t, Changed(lines))
+	assert.Equal(t, " {\n   \"a\": 1,\n-  \"b\": 2\n+  \"b\": 3\n }\n", render(lines))
+}
+
+func TestHunks(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, Hunks(Text(old, old), 3))
+	})
+
+	t.Run("separate hunks", func(t *testing.T) {
+		changed := strings.Replace(strings.Replace(old, "2\n", "two\n", 1), "11\n", "eleven\n", 1)
+		hunks := Hunks(Text(old, changed), 1)
+		require.Len(t, hunks, 2)
+		assert.Equal(t, "@@ -1,3 +1,3 @@", hunks[0].Header())
+		assert.Equal(t, " 1\n-2\n+two\n 3\n", render(hunks[0].Lines))
+		assert.Equal(t, "@@ -10,3 +10,3 @@", hunks[1].Header())
+		assert.Equal(t, " 10\n-11\n+eleven\n 12\n", render(hunks[1].Lines))
+	})
+
+	t.Run("nearby changes merge", func(t *testing.T) {
+		changed := strings.Replace(strings.Replace(old, "2\n", "two\n", 1), "5\n", "five\n", 1)
+		hunks := Hunks(Text(old, changed), 1)
+		require.Len(t, hunks, 1)
+		assert.Equal(t, "@@ -1,6 +1,6 @@", hunks[0].Header())
+	})
+
+	t.Run("pure insertion", func(t *testing.T) {
+		hunks := Hunks(Text("a\nb", "a\nb\nc"), 0)
+		require.Len(t, hunks, 1)
+		assert.Equal(t, "@@ -2,0 +3,1 @@", hunks[0].Header())
+	})
+}
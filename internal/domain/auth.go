@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"encoding/base64"
+	"maps"
+	"strings"
+	"time"
+)
+
+// AuthorizationHeader is the metadata key auth presets set
+const AuthorizationHeader = "authorization"
+
+// RedactedValue replaces credentials in history entries
+const RedactedValue = "[redacted]"
+
+// AuthType selects how a call's authorization header is composed
+type AuthType string
+
+const (
+	// AuthNone sends no authorization header (the default)
+	AuthNone AuthType = ""
+	// AuthBearer sends "Bearer <token>"
+	AuthBearer AuthType = "bearer"
+	// AuthBasic sends "Basic <base64(username:password)>"
+	AuthBasic AuthType = "basic"
+	// AuthCommand sends "Bearer <token>" with the token printed by a
+	// command, rerun once the token is older than the TTL
+	AuthCommand AuthType = "command"
+)
+
+// String returns a human-readable name for the auth type
+func (t AuthType) String() string {
+	switch t {
+	case AuthBearer:
+		return "Bearer token"
+	case AuthBasic:
+		return "Basic"
+	case AuthCommand:
+		return "Token command"
+	default:
+		return "None"
+	}
+}
+
+// Auth holds the credentials an authorization header is composed from
+type Auth struct {
+	Type     AuthType `json:"Type,omitempty"`
+	Token    string   `json:"Token,omitempty"`    // Bearer token, with or without the "Bearer " prefix
+	Username string   `json:"Username,omitempty"` // Basic auth
+	Password string   `json:"Password,omitempty"` // Basic auth
+
+	// Command prints a bearer token on stdout, e.g.
+	// "gcloud auth print-identity-token"
+	Command string `json:"Command,omitempty"`
+	// TTL is how long a command's token is reused (0 for the default)
+	TTL time.Duration `json:"TTL,omitempty"`
+}
+
+// Header returns the authorization header value, or "" when there is
+// nothing to send. Token commands are run by the caller, which passes
+// the token on as a bearer Auth.
+func (a Auth) Header() string {
+	switch a.Type {
+	case AuthBearer:
+		token := strings.TrimSpace(a.Token)
+		if scheme, rest, ok := strings.Cut(token, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(rest)
+		}
+		if token == "" {
+			return ""
+		}
+		return "Bearer " + token
+	case AuthBasic:
+		if a.Username == "" && a.Password == "" {
+			return ""
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password))
+	default:
+		return ""
+	}
+}
+
+// Apply returns a copy of metadata with the authorization header set from
+// a, replacing any authorization entry in another case. Metadata is
+// returned unchanged when a has nothing to send.
+func (a Auth) Apply(metadata map[string]string) map[string]string {
+	header := a.Header()
+	if header == "" {
+		return metadata
+	}
+	out := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		if !strings.EqualFold(k, AuthorizationHeader) {
+			out[k] = v
+		}
+	}
+	out[AuthorizationHeader] = header
+	return out
+}
+
+// Redacted returns a with its secrets replaced by RedactedValue
+func (a Auth) Redacted() Auth {
+	if a.Token != "" {
+		a.Token = RedactedValue
+	}
+	if a.Password != "" {
+		a.Password = RedactedValue
+	}
+	return a
+}
+
+// IsRedacted reports whether a's secrets were replaced by Redacted
+func (a Auth) IsRedacted() bool {
+	return a.Token == RedactedValue || a.Password == RedactedValue
+}
+
+// RedactMetadata returns a copy of metadata with authorization values
+// replaced by RedactedValue, keeping the scheme (e.g. "Bearer [redacted]").
+func RedactMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	out := maps.Clone(metadata)
+	for k, v := range out {
+		if !strings.EqualFold(k, AuthorizationHeader) {
+			continue
+		}
+		if scheme, _, ok := strings.Cut(strings.TrimSpace(v), " "); ok {
+			out[k] = scheme + " " + RedactedValue
+		} else {
+			out[k] = RedactedValue
+		}
+	}
+	return out
+}
+
+// WithoutRedacted returns a copy of metadata without the authorization
+// entries RedactMetadata replaced, so a replayed call uses the current
+// credentials instead of the placeholder.
+func WithoutRedacted(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	out := maps.Clone(metadata)
+	for k, v := range out {
+		if strings.EqualFold(k, AuthorizationHeader) && strings.HasSuffix(v, RedactedValue) {
+			delete(out, k)
+		}
+	}
+	return out
+}
+
+// RedactCredentials returns e with the authorization header of its request
+// metadata and the secrets of its connection's auth redacted, and its
+// connection's other secrets removed as by Connection.WithoutSecrets.
+func (e HistoryEntry) RedactCredentials() HistoryEntry {
+	e.Metadata.Request = RedactMetadata(e.Metadata.Request)
+	auth := e.Connection.Auth.Redacted()
+	e.Connection = e.Connection.WithoutSecrets()
+	e.Connection.Auth = auth
+	return e
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuth_Header(t *testing.T) {
+	tests := []struct {
+		name string
+		auth Auth
+		want string
+	}{
+		{"none", Auth{Token: "ignored"}, ""},
+		{"bearer", Auth{Type: AuthBearer, Token: "abc.def"}, "Bearer abc.def"},
+		{"bearer prefix kept once", Auth{Type: AuthBearer, Token: "Bearer abc"}, "Bearer abc"},
+		{"bearer prefix any case", Auth{Type: AuthBearer, Token: "  bearer   abc "}, "Bearer abc"},
+		{"empty bearer", Auth{Type: AuthBearer, Token: "  "}, ""},
+		{"basic", Auth{Type: AuthBasic, Username: "Aladdin", Password: "open sesame"}, "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ=="},
+		{"basic password only", Auth{Type: AuthBasic, Password: "p"}, "Basic OnA="},
+		{"empty basic", Auth{Type: AuthBasic}, ""},
+		{"command run by caller", Auth{Type: AuthCommand, Command: "echo tok"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.auth.Header())
+		})
+	}
+}
+
+func TestAuth_Apply(t *testing.T) {
+	md := map[string]string{"x-trace": "1", "Authorization": "Bearer manual"}
+
+	got := Auth{Type: AuthBearer, Token: "preset"}.Apply(md)
+	assert.Equal(t, map[string]string{"x-trace": "1", "authorization": "Bearer preset"}, got)
+	assert.Equal(t, "Bearer manual", md["Authorization"], "input left untouched")
+
+	assert.Equal(t, md, Auth{}.Apply(md), "no auth keeps the metadata as-is")
+	assert.Equal(t, map[string]string{"authorization": "Bearer t"}, Auth{Type: AuthBearer, Token: "t"}.Apply(nil))
+}
+
+func TestRedactMetadata(t *testing.T) {
+	md := map[string]string{"authorization": "Bearer secret", "x-trace": "1"}
+	redacted := RedactMetadata(md)
+	assert.Equal(t, map[string]string{"authorization": "Bearer [redacted]", "x-trace": "1"}, redacted)
+	assert.Equal(t, "Bearer secret", md["authorization"], "input left untouched")
+
+	assert.Equal(t, "[redacted]", RedactMetadata(map[string]string{"Authorization": "opaque"})["Authorization"])
+	assert.Nil(t, RedactMetadata(nil))
+
+	assert.Equal(t, map[string]string{"x-trace": "1"}, WithoutRedacted(redacted))
+	assert.Equal(t, md, WithoutRedacted(md), "real credentials are kept")
+}
+
+func TestHistoryEntry_RedactCredentials(t *testing.T) {
+	entry := HistoryEntry{
+		Connection: Connection{Address: "api:443", Auth: Auth{Type: AuthBasic, Username: "u", Password: "p"}},
+		Metadata: Metadata{
+			Request:  map[string]string{"authorization": "Basic dTpw"},
+			Response: map[string]string{"authorization": "kept"},
+		},
+	}
+
+	got := entry.RedactCredentials()
+	assert.Equal(t, Auth{Type: AuthBasic, Username: "u", Password: RedactedValue}, got.Connection.Auth)
+	assert.True(t, got.Connection.Auth.IsRedacted())
+	assert.Equal(t, "Basic [redacted]", got.Metadata.Request["authorization"])
+	assert.Equal(t, "kept", got.Metadata.Response["authorization"], "only request credentials are redacted")
+
+	assert.Equal(t, "Basic dTpw", entry.Metadata.Request["authorization"], "original entry untouched")
+	assert.False(t, entry.Connection.Auth.IsRedacted())
+	assert.False(t, Auth{}.Redacted().IsRedacted())
+}
+
+func TestHistoryEntry_RedactCredentials_Connection(t *testing.T) {
+	entry := HistoryEntry{Connection: Connection{
+		Address:         "api:443",
+		DefaultMetadata: map[string]string{"Authorization": "Bearer d", "x-tenant": "acme"},
+		Proxy:           ProxySettings{Type: ProxyHTTP, Host: "proxy", Port: 3128, Username: "pu", Password: "pp"},
+		TLS:             TLSSettings{Enabled: true, ClientCertFile: "client.pem", ClientKeyFile: "client.key"},
+	}}
+
+	got := entry.RedactCredentials().Connection
+	assert.Equal(t, map[string]string{"x-tenant": "acme"}, got.DefaultMetadata)
+	assert.Empty(t, got.Proxy.Password)
+	assert.Equal(t, "pu", got.Proxy.Username)
+	assert.Empty(t, got.TLS.ClientKeyFile)
+	assert.Equal(t, "client.pem", got.TLS.ClientCertFile)
+
+	assert.Equal(t, "Bearer d", entry.Connection.DefaultMetadata["Authorization"], "original entry untouched")
+	assert.Equal(t, "pp", entry.Connection.Proxy.Password)
+}
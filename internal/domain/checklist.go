@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// ChecklistItemKind identifies what a checklist item verifies
+type ChecklistItemKind string
+
+const (
+	// ChecklistConnect verifies a connection profile is reachable
+	ChecklistConnect ChecklistItemKind = "connect"
+	// ChecklistMethod verifies a unary method returns the expected status
+	ChecklistMethod ChecklistItemKind = "method"
+	// ChecklistMetadata verifies a request metadata entry is present (and,
+	// for JWT bearer tokens, not expired)
+	ChecklistMetadata ChecklistItemKind = "metadata"
+)
+
+// String returns a human-readable name for the item kind
+func (k ChecklistItemKind) String() string {
+	switch k {
+	case ChecklistConnect:
+		return "Connect"
+	case ChecklistMethod:
+		return "Method"
+	case ChecklistMetadata:
+		return "Metadata"
+	default:
+		return string(k)
+	}
+}
+
+// ChecklistItem is one step of a workspace startup checklist.
+// Which fields are used depends on Kind.
+type ChecklistItem struct {
+	Kind  ChecklistItemKind `json:"Kind"`
+	Label string            `json:"Label,omitempty"` // Optional display label
+
+	// Connect: the connection profile to reach. Method items run against
+	// the connection established by the most recent connect item.
+	Connection *Connection `json:"Connection,omitempty"`
+
+	// Method: full method name ("pkg.Service/Method"), JSON body, expected
+	// status code name (empty means OK), and the deadline for the call.
+	Method     string        `json:"Method,omitempty"`
+	Body       string        `json:"Body,omitempty"`
+	ExpectCode string        `json:"ExpectCode,omitempty"`
+	Timeout    time.Duration `json:"Timeout,omitempty"`
+
+	// Metadata: header key that must be present, optionally a JWT whose
+	// exp claim must be in the future.
+	MetadataKey    string `json:"MetadataKey,omitempty"`
+	CheckJWTExpiry bool   `json:"CheckJWTExpiry,omitempty"`
+}
@@ -1,6 +1,39 @@
 package domain
 
-import "time"
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+// Transport selects the wire protocol used to reach a server
+type Transport string
+
+const (
+	// TransportGRPC is native gRPC over HTTP/2 (the default)
+	TransportGRPC Transport = ""
+	// TransportGRPCWeb is gRPC-Web with binary framing over HTTP/1.1
+	TransportGRPCWeb Transport = "grpc-web"
+	// TransportGRPCWebText is gRPC-Web with base64-encoded framing (grpc-web-text)
+	TransportGRPCWebText Transport = "grpc-web-text"
+)
+
+// IsWeb reports whether the transport is one of the gRPC-Web variants
+func (t Transport) IsWeb() bool {
+	return t == TransportGRPCWeb || t == TransportGRPCWebText
+}
+
+// String returns a human-readable name for the transport
+func (t Transport) String() string {
+	switch t {
+	case TransportGRPCWeb:
+		return "gRPC-Web"
+	case TransportGRPCWebText:
+		return "gRPC-Web (text)"
+	default:
+		return "gRPC"
+	}
+}
 
 // Connection holds gRPC connection settings
 type Connection struct {
@@ -8,15 +41,192 @@ type Connection struct {
 	Address string        `json:"Address"`
 	Timeout time.Duration `json:"Timeout"`
 
+	// Transport selects native gRPC or gRPC-Web (empty means native gRPC)
+	Transport Transport `json:"Transport,omitempty"`
+
+	// DescriptorSetFile is a binary FileDescriptorSet used instead of server
+	// reflection to discover services (empty means use reflection)
+	DescriptorSetFile string `json:"DescriptorSetFile,omitempty"`
+
+	// ProtoImportPaths are directories of .proto sources compiled in-process
+	// instead of using server reflection. Every file under them is compiled;
+	// imports resolve against the paths in order.
+	ProtoImportPaths []string `json:"ProtoImportPaths,omitempty"`
+
+	// KeepAlive redials with backoff when the connection drops, instead
+	// of waiting for the next request
+	KeepAlive bool `json:"KeepAlive,omitempty"`
+
+	// MaxRecvMsgSize and MaxSendMsgSize limit message sizes in bytes
+	// (0 keeps gRPC's defaults: 4 MB received, unlimited sent). Native
+	// gRPC only.
+	MaxRecvMsgSize int `json:"MaxRecvMsgSize,omitempty"`
+	MaxSendMsgSize int `json:"MaxSendMsgSize,omitempty"`
+
+	// Authority replaces the address as the :authority of calls, for
+	// gateways that route by it (empty sends the address). Native gRPC only.
+	Authority string `json:"Authority,omitempty"`
+
+	// UserAgent is sent as the user-agent of calls, ahead of gRPC's own
+	// (empty sends gRPC's alone). Native gRPC only.
+	UserAgent string `json:"UserAgent,omitempty"`
+
+	// Compression names the compressor for requests, e.g. "gzip" (empty
+	// means uncompressed). Native gRPC only.
+	Compression string `json:"Compression,omitempty"`
+
+	// KeepaliveParams sets the HTTP/2 pings that keep an idle connection
+	// open through NATs and load balancers (the zero value sends none).
+	// Native gRPC only.
+	KeepaliveParams KeepaliveParams `json:"KeepaliveParams,omitzero"`
+
+	// Retry retries unary calls that fail with a transient status (the
+	// zero value makes a single attempt)
+	Retry RetryPolicy `json:"Retry,omitzero"`
+
+	// Auth is the default authorization for calls on this connection
+	Auth Auth `json:"Auth,omitzero"`
+
+	// DefaultMetadata is sent with every call on this connection, e.g. a
+	// tenant id or API key. A call's own metadata replaces an entry with
+	// the same key; -bin values are base64 like request metadata.
+	DefaultMetadata map[string]string `json:"DefaultMetadata,omitempty"`
+
+	// Proxy routes the connection through an HTTP CONNECT or SOCKS5 proxy
+	Proxy ProxySettings `json:"Proxy,omitzero"`
+
 	// TLS configuration
 	TLS TLSSettings `json:"TLS"`
 }
 
+// DisplayName returns "Name (address)" for a named connection, or just
+// the address.
+func (c Connection) DisplayName() string {
+	if c.Name == "" {
+		return c.Address
+	}
+	return c.Name + " (" + c.Address + ")"
+}
+
+// WithoutSecrets returns c without its auth token and password, proxy
+// password, client key path, or authorization default metadata, for sharing
+func (c Connection) WithoutSecrets() Connection {
+	c.DefaultMetadata = withoutAuthorization(c.DefaultMetadata)
+	c.Auth.Token = ""
+	c.Auth.Password = ""
+	c.Proxy.Password = ""
+	c.TLS.ClientKeyFile = ""
+	return c
+}
+
+// MinKeepaliveTime is the shortest ping interval gRPC allows; shorter
+// intervals are raised to it. Servers commonly reject anything shorter
+// still with GOAWAY too_many_pings.
+const MinKeepaliveTime = 10 * time.Second
+
+// KeepaliveParams holds a connection's client keepalive pings
+type KeepaliveParams struct {
+	// Time is how long the connection may go without activity before
+	// a ping is sent (0 sends no pings)
+	Time time.Duration `json:"Time,omitempty"`
+	// Timeout is how long to wait for a ping's ack before closing the
+	// connection (0 keeps gRPC's default of 20s)
+	Timeout time.Duration `json:"Timeout,omitempty"`
+	// PermitWithoutStream pings even when no call is in progress
+	PermitWithoutStream bool `json:"PermitWithoutStream,omitempty"`
+}
+
+// Enabled reports whether keepalive pings are configured
+func (k KeepaliveParams) Enabled() bool {
+	return k.Time > 0
+}
+
+// DefaultRetryableCodes are the status codes retried when a retry policy
+// names none
+var DefaultRetryableCodes = []string{"UNAVAILABLE"}
+
+// RetryPolicy holds how unary calls on a connection are retried. Streaming
+// calls are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the most attempts made at a call, the first included
+	// (0 or 1 makes a single attempt)
+	MaxAttempts int `json:"MaxAttempts,omitempty"`
+	// InitialBackoff is the wait before the first retry, doubled for each
+	// retry after it
+	InitialBackoff time.Duration `json:"InitialBackoff,omitempty"`
+	// MaxBackoff caps the wait between attempts (0 leaves it uncapped)
+	MaxBackoff time.Duration `json:"MaxBackoff,omitempty"`
+	// RetryableCodes names the status codes that are retried, as in gRPC
+	// service configs, e.g. "UNAVAILABLE" (empty means DefaultRetryableCodes)
+	RetryableCodes []string `json:"RetryableCodes,omitempty"`
+}
+
+// Enabled reports whether calls may be attempted more than once
+func (r RetryPolicy) Enabled() bool {
+	return r.MaxAttempts > 1
+}
+
+// Codes returns the status codes retried, falling back to
+// DefaultRetryableCodes
+func (r RetryPolicy) Codes() []string {
+	if len(r.RetryableCodes) == 0 {
+		return DefaultRetryableCodes
+	}
+	return r.RetryableCodes
+}
+
+// ProxyType selects the kind of proxy a connection goes through
+type ProxyType string
+
+const (
+	// ProxyNone connects directly (the default)
+	ProxyNone ProxyType = ""
+	// ProxyHTTP tunnels through an HTTP proxy with the CONNECT method
+	ProxyHTTP ProxyType = "http"
+	// ProxySOCKS5 tunnels through a SOCKS5 proxy
+	ProxySOCKS5 ProxyType = "socks5"
+)
+
+// String returns a human-readable name for the proxy type
+func (t ProxyType) String() string {
+	switch t {
+	case ProxyHTTP:
+		return "HTTP CONNECT"
+	case ProxySOCKS5:
+		return "SOCKS5"
+	default:
+		return "None"
+	}
+}
+
+// ProxySettings holds the proxy a connection is dialed through
+type ProxySettings struct {
+	Type     ProxyType `json:"Type,omitempty"`
+	Host     string    `json:"Host,omitempty"`
+	Port     int       `json:"Port,omitempty"`
+	Username string    `json:"Username,omitempty"` // Optional proxy credentials
+	Password string    `json:"Password,omitempty"`
+}
+
+// Enabled reports whether a proxy is configured
+func (p ProxySettings) Enabled() bool {
+	return p.Type != ProxyNone
+}
+
+// Address returns the proxy's host:port
+func (p ProxySettings) Address() string {
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
 // TLSSettings holds detailed TLS configuration
 type TLSSettings struct {
 	Enabled        bool   `json:"Enabled"`
 	SkipVerify     bool   `json:"SkipVerify"`     // Skip TLS certificate verification (insecure)
-	CertFile       string `json:"CertFile"`       // Path to CA certificate
+	CertFile       string `json:"CertFile"`       // Path to CA certificate bundle (PEM), trusted instead of the system roots
 	ClientCertFile string `json:"ClientCertFile"` // Path to client certificate (mTLS)
 	ClientKeyFile  string `json:"ClientKeyFile"`  // Path to client key (mTLS)
+
+	// ServerNameOverride is the name sent for SNI and checked against the
+	// server's certificate, when it differs from the address's host
+	ServerNameOverride string `json:"ServerNameOverride,omitempty"`
 }
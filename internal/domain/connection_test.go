@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnection_DisplayName(t *testing.T) {
+	assert.Equal(t, "localhost:50051", Connection{Address: "localhost:50051"}.DisplayName())
+	assert.Equal(t, "staging (staging:443)", Connection{Name: "staging", Address: "staging:443"}.DisplayName())
+}
+
+func TestConnection_NameJSON(t *testing.T) {
+	// Connections saved before they could be named have no Name field
+	var old Connection
+	require.NoError(t, json.Unmarshal([]byte(`{"Address": "api:443", "Timeout": 0, "TLS": {}}`), &old))
+	assert.Empty(t, old.Name)
+	assert.Equal(t, "api:443", old.DisplayName())
+
+	data, err := json.Marshal(Connection{Address: "api:443"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"Name"`, "unnamed connections are written as before")
+
+	data, err = json.Marshal(Connection{Name: "prod", Address: "api:443"})
+	require.NoError(t, err)
+	var named Connection
+	require.NoError(t, json.Unmarshal(data, &named))
+	assert.Equal(t, "prod", named.Name)
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // HistoryEntry represents a record of a gRPC request/response for replay
 type HistoryEntry struct {
@@ -12,6 +15,7 @@ type HistoryEntry struct {
 	Response     string        `json:"response"`                // JSON response body (for reference)
 	Duration     time.Duration `json:"duration"`                // Request duration
 	Status       string        `json:"status"`                  // "success" or "error"
+	Code         string        `json:"code,omitempty"`          // gRPC status code name (e.g. "OK", "Unavailable")
 	Error        string        `json:"error"`                   // Error message if failed
 	Metadata     Metadata      `json:"metadata"`                // Request metadata/headers
 	StreamType   string        `json:"stream_type,omitempty"`   // "unary", "server_stream", "client_stream", "bidi_stream"
@@ -20,6 +24,56 @@ type HistoryEntry struct {
 
 // Metadata represents request/response metadata
 type Metadata struct {
-	Request  map[string]string `json:"request"`  // Request headers
-	Response map[string]string `json:"response"` // Response headers
+	Request  map[string]string `json:"request"`            // Request headers
+	Response map[string]string `json:"response"`           // Response headers
+	Trailers map[string]string `json:"trailers,omitempty"` // Response trailers
+}
+
+// StatusCode returns the gRPC status code name for the entry. Entries
+// recorded before codes were stored derive it from the error message
+// ("rpc error: code = Unavailable desc = ..."), falling back to "Unknown"
+// for errors and "OK" otherwise.
+func (e HistoryEntry) StatusCode() string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return codeFromError(e.Status, e.Error)
+}
+
+// codeFromError derives a status code name from a stored status and error.
+func codeFromError(status, errMsg string) string {
+	if _, rest, ok := strings.Cut(errMsg, "code = "); ok {
+		if code, _, _ := strings.Cut(rest, " "); code != "" {
+			return code
+		}
+	}
+	if errMsg != "" || strings.EqualFold(status, "error") {
+		return "Unknown"
+	}
+	return "OK"
+}
+
+// StatusBucket counts calls by gRPC status code within one time bucket
+type StatusBucket struct {
+	Start  time.Time      // Inclusive
+	End    time.Time      // Exclusive
+	Counts map[string]int // Status code name -> number of calls
+}
+
+// Total returns the number of calls in the bucket
+func (b StatusBucket) Total() int {
+	total := 0
+	for _, n := range b.Counts {
+		total += n
+	}
+	return total
+}
+
+// HistoryStatsQuery selects and buckets history entries by time and status code
+type HistoryStatsQuery struct {
+	Method   string         // Full method name ("pkg.Service/Method"); empty matches all
+	Since    time.Time      // Inclusive start of the window
+	Until    time.Time      // Exclusive end of the window
+	Bucket   time.Duration  // Bucket width; multiples of 24h align to midnight
+	Location *time.Location // Zone for bucket boundaries; nil means UTC
 }
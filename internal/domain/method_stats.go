@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// MaxLatencySamples is how many recent call latencies a method's
+// statistics keep for its percentiles
+const MaxLatencySamples = 50
+
+// MethodStats are the invocation statistics of one method on one server
+type MethodStats struct {
+	Address  string      `json:"address"`
+	Method   string      `json:"method"`    // Full method name ("pkg.Service/Method")
+	Calls    int         `json:"calls"`     // Every call ever recorded
+	LastCode string      `json:"last_code"` // gRPC status code name of the last call
+	LastCall time.Time   `json:"last_call"`
+	Latency  LatencyRing `json:"latency"` // The last MaxLatencySamples latencies
+}
+
+// Record counts a call that ended with code after latency.
+func (s *MethodStats) Record(code string, latency time.Duration, at time.Time) {
+	s.Calls++
+	s.LastCode = code
+	s.LastCall = at
+	s.Latency.Add(latency)
+}
+
+// Summary returns the call count, last status and latency percentiles.
+func (s MethodStats) Summary() MethodStatsSummary {
+	samples := s.Latency.Values()
+	return MethodStatsSummary{
+		Calls:    s.Calls,
+		LastCode: s.LastCode,
+		LastCall: s.LastCall,
+		P50:      Percentile(samples, 50),
+		P95:      Percentile(samples, 95),
+		Samples:  len(samples),
+	}
+}
+
+// LatencyRing is a ring buffer of the last MaxLatencySamples latencies
+type LatencyRing struct {
+	Samples []time.Duration `json:"samples"`
+	Next    int             `json:"next"` // Index the next sample overwrites once full
+}
+
+// Add stores a latency, overwriting the oldest once the ring is full.
+func (r *LatencyRing) Add(d time.Duration) {
+	if len(r.Samples) < MaxLatencySamples {
+		r.Samples = append(r.Samples, d)
+		return
+	}
+	// Rings loaded from disk may be over size or point past the end
+	if len(r.Samples) > MaxLatencySamples || r.Next < 0 || r.Next >= MaxLatencySamples {
+		r.Samples = r.Values()[len(r.Samples)-MaxLatencySamples:]
+		r.Next = 0
+	}
+	r.Samples[r.Next] = d
+	r.Next = (r.Next + 1) % MaxLatencySamples
+}
+
+// Values returns the latencies oldest first.
+func (r LatencyRing) Values() []time.Duration {
+	next := r.Next
+	if next < 0 || next >= len(r.Samples) {
+		next = 0
+	}
+	return slices.Concat(r.Samples[next:], r.Samples[:next])
+}
+
+// Percentile returns the p-th percentile (0-100) of samples by the
+// nearest-rank method, or 0 for no samples.
+func Percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank-1, 0), len(sorted)-1)]
+}
+
+// MethodStatsSummary is what the UI shows of a method's statistics
+type MethodStatsSummary struct {
+	Calls    int
+	LastCode string
+	LastCall time.Time
+	P50      time.Duration
+	P95      time.Duration
+	Samples  int // Latencies the percentiles cover
+}
+
+// Annotation is the short form shown beside a method, e.g.
+// "12 calls · 45ms" with the median latency.
+func (s MethodStatsSummary) Annotation() string {
+	if s.Samples == 0 {
+		return s.callCount()
+	}
+	return s.callCount() + " · " + FormatLatency(s.P50)
+}
+
+// Details describes the statistics in full, e.g. "12 calls, last OK at
+// 15:04:05 · p50 45ms, p95 120ms over the last 12".
+func (s MethodStatsSummary) Details() string {
+	text := s.callCount()
+	if s.LastCode != "" {
+		text += ", last " + s.LastCode
+		if !s.LastCall.IsZero() {
+			text += " at " + s.LastCall.In(time.Local).Format("15:04:05")
+		}
+	}
+	if s.Samples > 0 {
+		text += fmt.Sprintf(" · p50 %s, p95 %s over the last %d", FormatLatency(s.P50), FormatLatency(s.P95), s.Samples)
+	}
+	return text
+}
+
+func (s MethodStatsSummary) callCount() string {
+	if s.Calls == 1 {
+		return "1 call"
+	}
+	return fmt.Sprintf("%d calls", s.Calls)
+}
+
+// FormatLatency formats a call latency in whole milliseconds, or "<1ms".
+func FormatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	ms := func(values ...int) []time.Duration {
+		d := make([]time.Duration, len(values))
+		for i, v := range values {
+			d[i] = time.Duration(v) * time.Millisecond
+		}
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		p       float64
+		want    time.Duration
+	}{
+		{"no samples", nil, 50, 0},
+		{"one sample", ms(7), 95, 7 * time.Millisecond},
+		{"median of even count", ms(40, 10, 30, 20), 50, 20 * time.Millisecond},
+		{"median of odd count", ms(5, 1, 3), 50, 3 * time.Millisecond},
+		{"p95 of 20", ms(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 200), 95, 19 * time.Millisecond},
+		{"p100 is the max", ms(3, 9, 1), 100, 9 * time.Millisecond},
+		{"p0 is the min", ms(3, 9, 1), 0, time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Percentile(tt.samples, tt.p))
+		})
+	}
+
+	samples := ms(3, 1, 2)
+	Percentile(samples, 50)
+	assert.Equal(t, ms(3, 1, 2), samples, "samples are not reordered")
+}
+
+func TestLatencyRing(t *testing.T) {
+	var r LatencyRing
+	for i := range MaxLatencySamples {
+		r.Add(time.Duration(i))
+	}
+	assert.Len(t, r.Samples, MaxLatencySamples)
+	assert.Equal(t, time.Duration(0), r.Values()[0])
+
+	// Past capacity the oldest samples are overwritten
+	r.Add(100)
+	r.Add(101)
+	values := r.Values()
+	assert.Len(t, values, MaxLatencySamples)
+	assert.Equal(t, time.Duration(2), values[0])
+	assert.Equal(t, []time.Duration{100, 101}, values[MaxLatencySamples-2:])
+
+	// A ring from an older file with more samples is trimmed to the newest
+	big := LatencyRing{Samples: make([]time.Duration, MaxLatencySamples+10)}
+	for i := range big.Samples {
+		big.Samples[i] = time.Duration(i)
+	}
+	big.Add(999)
+	values = big.Values()
+	assert.Len(t, values, MaxLatencySamples)
+	assert.Equal(t, time.Duration(11), values[0])
+	assert.Equal(t, time.Duration(999), values[MaxLatencySamples-1])
+}
+
+func TestMethodStats_Summary(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var s MethodStats
+	assert.Equal(t, "0 calls", s.Summary().Annotation())
+
+	s.Record("OK", 40*time.Millisecond, at)
+	assert.Equal(t, "1 call · 40ms", s.Summary().Annotation())
+
+	s.Record("OK", 50*time.Millisecond, at)
+	s.Record("Unavailable", 500*time.Microsecond, at.Add(time.Second))
+	summary := s.Summary()
+	assert.Equal(t, 3, summary.Calls)
+	assert.Equal(t, "Unavailable", summary.LastCode)
+	assert.Equal(t, at.Add(time.Second), summary.LastCall)
+	assert.Equal(t, 40*time.Millisecond, summary.P50)
+	assert.Equal(t, 50*time.Millisecond, summary.P95)
+	assert.Equal(t, "3 calls · 40ms", summary.Annotation())
+	assert.Contains(t, summary.Details(), "3 calls, last Unavailable at ")
+	assert.Contains(t, summary.Details(), "p50 40ms, p95 50ms over the last 3")
+
+	// Calls keep counting once samples are capped
+	for range MaxLatencySamples {
+		s.Record("OK", time.Millisecond, at)
+	}
+	summary = s.Summary()
+	assert.Equal(t, 3+MaxLatencySamples, summary.Calls)
+	assert.Equal(t, MaxLatencySamples, summary.Samples)
+	assert.Equal(t, time.Millisecond, summary.P95)
+}
+
+func TestFormatLatency(t *testing.T) {
+	assert.Equal(t, "<1ms", FormatLatency(300*time.Microsecond))
+	assert.Equal(t, "45ms", FormatLatency(45*time.Millisecond+600*time.Microsecond))
+	assert.Equal(t, "2500ms", FormatLatency(2500*time.Millisecond))
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"maps"
+	"strings"
+	"time"
+)
 
 // Request represents a gRPC request
 type Request struct {
@@ -9,6 +13,55 @@ type Request struct {
 	Metadata map[string]string `json:"Metadata"`
 }
 
+// WithoutSecrets returns r without authorization entries in its metadata
+func (r Request) WithoutSecrets() Request {
+	r.Metadata = withoutAuthorization(r.Metadata)
+	return r
+}
+
+// withoutAuthorization returns a copy of metadata without authorization
+// entries in any case
+func withoutAuthorization(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	metadata = maps.Clone(metadata)
+	for k := range metadata {
+		if strings.EqualFold(k, AuthorizationHeader) {
+			delete(metadata, k)
+		}
+	}
+	return metadata
+}
+
+// MergeMetadata returns defaults with metadata on top: every entry of
+// metadata is kept, and a default is kept only if metadata has no key
+// equal to it ignoring case. Neither map is modified.
+func MergeMetadata(defaults, metadata map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return metadata
+	}
+	merged := make(map[string]string, len(defaults)+len(metadata))
+	for k, v := range defaults {
+		if !HasMetadataKey(metadata, k) {
+			merged[k] = v
+		}
+	}
+	maps.Copy(merged, metadata)
+	return merged
+}
+
+// HasMetadataKey reports whether metadata has key, ignoring case as gRPC
+// does.
+func HasMetadataKey(metadata map[string]string, key string) bool {
+	for k := range metadata {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
 // Response represents a gRPC response
 type Response struct {
 	Body     string            `json:"Body"` // JSON
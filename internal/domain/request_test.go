@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeMetadata(t *testing.T) {
+	defaults := map[string]string{"X-Tenant": "acme", "x-api-key": "default", "x-trace-bin": "AAE="}
+	metadata := map[string]string{"X-API-KEY": "call", "x-trace-bin": "AAH/", "x-request": "1"}
+
+	merged := MergeMetadata(defaults, metadata)
+	assert.Equal(t, map[string]string{
+		"X-Tenant":    "acme",
+		"X-API-KEY":   "call",
+		"x-trace-bin": "AAH/",
+		"x-request":   "1",
+	}, merged)
+	assert.Len(t, defaults, 3, "defaults untouched")
+	assert.Len(t, metadata, 3, "metadata untouched")
+
+	assert.Equal(t, metadata, MergeMetadata(nil, metadata))
+	assert.Equal(t, defaults, MergeMetadata(defaults, nil))
+}
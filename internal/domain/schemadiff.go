@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaDiff describes how a server's services changed between two listings
+type SchemaDiff struct {
+	AddedServices   []string // Full service names
+	RemovedServices []string
+	AddedMethods    []string // Full method names, in services present in both
+	RemovedMethods  []string
+	ChangedMethods  []MethodChange
+}
+
+// MethodChange is a method whose input or output type, or streaming,
+// differs between two listings
+type MethodChange struct {
+	Old Method
+	New Method
+}
+
+// DiffServices compares two service listings, old first. Methods of added
+// or removed services are not listed separately.
+func DiffServices(old, updated []Service) SchemaDiff {
+	var diff SchemaDiff
+	oldByName := servicesByName(old)
+	newByName := servicesByName(updated)
+
+	for _, svc := range old {
+		if _, ok := newByName[svc.FullName]; !ok {
+			diff.RemovedServices = append(diff.RemovedServices, svc.FullName)
+		}
+	}
+	for _, svc := range updated {
+		prev, ok := oldByName[svc.FullName]
+		if !ok {
+			diff.AddedServices = append(diff.AddedServices, svc.FullName)
+			continue
+		}
+
+		prevMethods := methodsByName(prev.Methods)
+		newMethods := methodsByName(svc.Methods)
+		for _, m := range prev.Methods {
+			if _, ok := newMethods[m.Name]; !ok {
+				diff.RemovedMethods = append(diff.RemovedMethods, m.FullName)
+			}
+		}
+		for _, m := range svc.Methods {
+			p, ok := prevMethods[m.Name]
+			switch {
+			case !ok:
+				diff.AddedMethods = append(diff.AddedMethods, m.FullName)
+			case p.InputType != m.InputType || p.OutputType != m.OutputType || p.MethodType() != m.MethodType():
+				diff.ChangedMethods = append(diff.ChangedMethods, MethodChange{Old: p, New: m})
+			}
+		}
+	}
+	return diff
+}
+
+// IsEmpty reports whether the listings were the same
+func (d SchemaDiff) IsEmpty() bool {
+	return len(d.AddedServices) == 0 && len(d.RemovedServices) == 0 &&
+		len(d.AddedMethods) == 0 && len(d.RemovedMethods) == 0 && len(d.ChangedMethods) == 0
+}
+
+// Summary describes the changes in a few words, e.g. "2 methods updated,
+// 1 service added"
+func (d SchemaDiff) Summary() string {
+	var parts []string
+	add := func(n int, noun, verb string) {
+		if n == 0 {
+			return
+		}
+		if n != 1 {
+			noun += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s %s", n, noun, verb))
+	}
+	add(len(d.ChangedMethods), "method", "updated")
+	add(len(d.AddedMethods), "method", "added")
+	add(len(d.RemovedMethods), "method", "removed")
+	add(len(d.AddedServices), "service", "added")
+	add(len(d.RemovedServices), "service", "removed")
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SelectionState is what becomes of a selected method when the services
+// are replaced by a newer listing
+type SelectionState int
+
+const (
+	// SelectionKept means the method is still there, unchanged
+	SelectionKept SelectionState = iota
+	// SelectionChanged means the method is still there with new types, so
+	// its request form must be rebuilt
+	SelectionChanged
+	// SelectionRemoved means the method or its service is gone
+	SelectionRemoved
+)
+
+// Selection reports what becomes of the method named method of the service
+// with full name service. An empty selection is always kept.
+func (d SchemaDiff) Selection(service, method string) SelectionState {
+	if service == "" || method == "" {
+		return SelectionKept
+	}
+	for _, name := range d.RemovedServices {
+		if name == service {
+			return SelectionRemoved
+		}
+	}
+	fullName := service + "." + method
+	for _, name := range d.RemovedMethods {
+		if name == fullName {
+			return SelectionRemoved
+		}
+	}
+	for _, change := range d.ChangedMethods {
+		if change.New.FullName == fullName {
+			return SelectionChanged
+		}
+	}
+	return SelectionKept
+}
+
+// servicesByName indexes services by full name
+func servicesByName(services []Service) map[string]Service {
+	m := make(map[string]Service, len(services))
+	for _, svc := range services {
+		m[svc.FullName] = svc
+	}
+	return m
+}
+
+// methodsByName indexes methods by short name
+func methodsByName(methods []Method) map[string]Method {
+	m := make(map[string]Method, len(methods))
+	for _, method := range methods {
+		m[method.Name] = method
+	}
+	return m
+}
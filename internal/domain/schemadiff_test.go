@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// method returns a unary method of service svc
+func method(svc, name, in, out string) Method {
+	return Method{Name: name, FullName: svc + "." + name, InputType: in, OutputType: out}
+}
+
+func TestDiffServices(t *testing.T) {
+	get := method("users.UserService", "GetUser", "users.GetUserRequest", "users.User")
+	list := method("users.UserService", "ListUsers", "users.ListUsersRequest", "users.ListUsersResponse")
+	del := method("users.UserService", "DeleteUser", "users.DeleteUserRequest", "google.protobuf.Empty")
+	old := []Service{
+		{Name: "UserService", FullName: "users.UserService", Methods: []Method{get, list, del}},
+		{Name: "Legacy", FullName: "legacy.Legacy"},
+	}
+
+	getV2 := get
+	getV2.OutputType = "users.UserV2"
+	listStream := list
+	listStream.IsServerStream = true
+	create := method("users.UserService", "CreateUser", "users.CreateUserRequest", "users.User")
+	updated := []Service{
+		{Name: "UserService", FullName: "users.UserService", Methods: []Method{getV2, listStream, create}},
+		{Name: "Orders", FullName: "orders.Orders", Methods: []Method{method("orders.Orders", "Get", "a", "b")}},
+	}
+
+	diff := DiffServices(old, updated)
+	assert.Equal(t, []string{"orders.Orders"}, diff.AddedServices)
+	assert.Equal(t, []string{"legacy.Legacy"}, diff.RemovedServices)
+	assert.Equal(t, []string{"users.UserService.CreateUser"}, diff.AddedMethods)
+	assert.Equal(t, []string{"users.UserService.DeleteUser"}, diff.RemovedMethods)
+	assert.Equal(t, []MethodChange{{Old: get, New: getV2}, {Old: list, New: listStream}}, diff.ChangedMethods)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, "2 methods updated, 1 method added, 1 method removed, 1 service added, 1 service removed", diff.Summary())
+
+	same := DiffServices(old, old)
+	assert.True(t, same.IsEmpty())
+	assert.Equal(t, "no changes", same.Summary())
+	assert.True(t, DiffServices(nil, nil).IsEmpty())
+}
+
+func TestDiffServices_ReorderedIsEmpty(t *testing.T) {
+	a := Service{FullName: "a.A", Methods: []Method{method("a.A", "X", "i", "o"), method("a.A", "Y", "i", "o")}}
+	b := Service{FullName: "b.B"}
+	reordered := Service{FullName: "a.A", Methods: []Method{a.Methods[1], a.Methods[0]}}
+	assert.True(t, DiffServices([]Service{a, b}, []Service{b, reordered}).IsEmpty())
+}
+
+func TestSchemaDiff_Selection(t *testing.T) {
+	get := method("users.UserService", "GetUser", "users.GetUserRequest", "users.User")
+	getV2 := get
+	getV2.InputType = "users.GetUserRequestV2"
+	diff := SchemaDiff{
+		RemovedServices: []string{"legacy.Legacy"},
+		RemovedMethods:  []string{"users.UserService.DeleteUser"},
+		ChangedMethods:  []MethodChange{{Old: get, New: getV2}},
+	}
+
+	tests := []struct {
+		name            string
+		service, method string
+		want            SelectionState
+	}{
+		{"untouched method", "users.UserService", "ListUsers", SelectionKept},
+		{"no selection", "", "", SelectionKept},
+		{"service only", "users.UserService", "", SelectionKept},
+		{"changed types", "users.UserService", "GetUser", SelectionChanged},
+		{"removed method", "users.UserService", "DeleteUser", SelectionRemoved},
+		{"removed service", "legacy.Legacy", "Anything", SelectionRemoved},
+		{"same name in another service", "admin.UserService", "GetUser", SelectionKept},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diff.Selection(tt.service, tt.method))
+		})
+	}
+}
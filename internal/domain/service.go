@@ -4,8 +4,11 @@ package domain
 type Service struct {
 	Name     string
 	FullName string // Fully qualified name
+	File     string // Path of the .proto file defining it, e.g. "example/v1/user.proto"
 	Methods  []Method
 	Error    string // non-empty when descriptor resolution failed
+
+	Deprecated bool // Marked deprecated in the service options
 }
 
 // Method represents a gRPC method
@@ -16,6 +19,8 @@ type Method struct {
 	OutputType     string
 	IsClientStream bool
 	IsServerStream bool
+	Deprecated     bool   // Marked deprecated itself or through its service
+	Error          string // non-empty when its input or output type could not be resolved
 }
 
 // MethodType returns the RPC type (Unary, ServerStream, ClientStream, or BidiStream)
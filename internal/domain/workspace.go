@@ -1,11 +1,23 @@
 package domain
 
+import (
+	"slices"
+	"time"
+)
+
 // Workspace holds saved connections and requests
 type Workspace struct {
 	Name        string         `json:"Name"`
+	SavedAt     time.Time      `json:"SavedAt,omitzero"` // When last saved, explicitly or by autosave
 	Connections []Connection   `json:"Connections,omitempty"`
 	Requests    []SavedRequest `json:"Requests,omitempty"`
 
+	// Named requests from the saved requests library, for every method
+	Library []SavedRequest `json:"Library,omitempty"`
+
+	// Startup checklist run before a session
+	Checklist []ChecklistItem `json:"Checklist,omitempty"`
+
 	// Current UI state
 	CurrentConnection *Connection `json:"CurrentConnection,omitempty"` // Active connection settings
 	CurrentRequest    *Request    `json:"CurrentRequest,omitempty"`    // Current request being edited
@@ -13,8 +25,116 @@ type Workspace struct {
 	SelectedMethod    string      `json:"SelectedMethod"`              // Currently selected method
 }
 
-// SavedRequest represents a named request for reuse
+// SavedRequest represents a named request for reuse. In the saved requests
+// library, Request.Method holds the full method name ("pkg.Service/Method")
+// and Name is unique per method.
 type SavedRequest struct {
 	Name    string  `json:"Name"`
 	Request Request `json:"Request"`
 }
+
+// WithoutSecrets returns a copy of w fit to share: auth tokens and
+// passwords, proxy passwords, client key paths, and authorization headers
+// are removed from every connection and request it holds.
+func (w Workspace) WithoutSecrets() Workspace {
+	w.Connections = mapSlice(w.Connections, Connection.WithoutSecrets)
+	w.Requests = mapSlice(w.Requests, SavedRequest.WithoutSecrets)
+	w.Library = mapSlice(w.Library, SavedRequest.WithoutSecrets)
+	w.Checklist = mapSlice(w.Checklist, func(item ChecklistItem) ChecklistItem {
+		if item.Connection != nil {
+			conn := item.Connection.WithoutSecrets()
+			item.Connection = &conn
+		}
+		return item
+	})
+	if w.CurrentConnection != nil {
+		conn := w.CurrentConnection.WithoutSecrets()
+		w.CurrentConnection = &conn
+	}
+	if w.CurrentRequest != nil {
+		req := w.CurrentRequest.WithoutSecrets()
+		w.CurrentRequest = &req
+	}
+	return w
+}
+
+// WithoutSecrets returns s with the authorization header removed from its
+// request metadata
+func (s SavedRequest) WithoutSecrets() SavedRequest {
+	s.Request = s.Request.WithoutSecrets()
+	return s
+}
+
+// Merge adds the connections, requests, and library entries of other to w,
+// replacing those with the same name (for library entries, the same method
+// and name), and appends other's checklist. The names replaced are returned
+// so they can be confirmed first. The current connection, request, and
+// selection are taken from other when it has them.
+func (w Workspace) Merge(other Workspace) (Workspace, []string) {
+	var replaced []string
+
+	w.Connections = append([]Connection(nil), w.Connections...)
+	for _, conn := range other.Connections {
+		i := slices.IndexFunc(w.Connections, func(c Connection) bool { return c.Name == conn.Name && c.Address == conn.Address })
+		if i < 0 {
+			w.Connections = append(w.Connections, conn)
+			continue
+		}
+		name := conn.Name
+		if name == "" {
+			name = conn.Address
+		}
+		replaced = append(replaced, "connection "+name)
+		w.Connections[i] = conn
+	}
+
+	w.Requests = append([]SavedRequest(nil), w.Requests...)
+	for _, req := range other.Requests {
+		i := slices.IndexFunc(w.Requests, func(r SavedRequest) bool { return r.Name == req.Name })
+		if i < 0 {
+			w.Requests = append(w.Requests, req)
+			continue
+		}
+		replaced = append(replaced, "request "+req.Name)
+		w.Requests[i] = req
+	}
+
+	w.Library = append([]SavedRequest(nil), w.Library...)
+	for _, req := range other.Library {
+		i := slices.IndexFunc(w.Library, func(r SavedRequest) bool {
+			return r.Name == req.Name && r.Request.Method == req.Request.Method
+		})
+		if i < 0 {
+			w.Library = append(w.Library, req)
+			continue
+		}
+		replaced = append(replaced, "saved request "+req.Request.Method+" "+req.Name)
+		w.Library[i] = req
+	}
+
+	w.Checklist = append(append([]ChecklistItem(nil), w.Checklist...), other.Checklist...)
+
+	if other.CurrentConnection != nil {
+		w.CurrentConnection = other.CurrentConnection
+	}
+	if other.CurrentRequest != nil {
+		w.CurrentRequest = other.CurrentRequest
+	}
+	if other.SelectedService != "" {
+		w.SelectedService, w.SelectedMethod = other.SelectedService, other.SelectedMethod
+	}
+	return w, replaced
+}
+
+// mapSlice returns a new slice holding fn applied to each element of s,
+// or s itself when it is empty.
+func mapSlice[T any](s []T, fn func(T) T) []T {
+	if len(s) == 0 {
+		return s
+	}
+	out := make([]T, len(s))
+	for i, v := range s {
+		out[i] = fn(v)
+	}
+	return out
+}
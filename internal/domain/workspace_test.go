@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspace_Merge(t *testing.T) {
+	current := Workspace{
+		Name: "mine",
+		Connections: []Connection{
+			{Name: "local", Address: "localhost:50051"},
+			{Name: "staging", Address: "staging:443"},
+		},
+		Requests:        []SavedRequest{{Name: "a.S/Get", Request: Request{Body: `{"old":true}`}}},
+		Library:         []SavedRequest{{Name: "happy", Request: Request{Method: "a.S/Get"}}},
+		SelectedService: "a.S",
+		SelectedMethod:  "Get",
+	}
+	imported := Workspace{
+		Name: "theirs",
+		Connections: []Connection{
+			{Name: "staging", Address: "staging:443", Timeout: 5},
+			{Name: "staging", Address: "staging-eu:443"},
+		},
+		Requests: []SavedRequest{
+			{Name: "a.S/Get", Request: Request{Body: `{"new":true}`}},
+			{Name: "a.S/List"},
+		},
+		Library: []SavedRequest{
+			{Name: "happy", Request: Request{Method: "a.S/List"}},
+			{Name: "happy", Request: Request{Method: "a.S/Get", Body: "{}"}},
+		},
+	}
+
+	merged, replaced := current.Merge(imported)
+	assert.Equal(t, "mine", merged.Name)
+	assert.Equal(t, []Connection{
+		{Name: "local", Address: "localhost:50051"},
+		{Name: "staging", Address: "staging:443", Timeout: 5},
+		{Name: "staging", Address: "staging-eu:443"},
+	}, merged.Connections)
+	assert.Equal(t, `{"new":true}`, merged.Requests[0].Request.Body)
+	assert.Len(t, merged.Requests, 2)
+	assert.Len(t, merged.Library, 2)
+	assert.Equal(t, "{}", merged.Library[0].Request.Body)
+	assert.Equal(t, []string{"connection staging", "request a.S/Get", "saved request a.S/Get happy"}, replaced)
+	assert.Equal(t, "Get", merged.SelectedMethod, "selection kept when the import has none")
+
+	assert.Len(t, current.Connections, 2, "original workspace untouched")
+	assert.Equal(t, `{"old":true}`, current.Requests[0].Request.Body)
+}
+
+func TestWorkspace_WithoutSecrets(t *testing.T) {
+	conn := Connection{
+		Address:         "api:443",
+		Auth:            Auth{Type: AuthBearer, Token: "t"},
+		Proxy:           ProxySettings{Type: ProxyHTTP, Username: "u", Password: "p"},
+		TLS:             TLSSettings{Enabled: true, ClientCertFile: "c.pem", ClientKeyFile: "c.key"},
+		DefaultMetadata: map[string]string{"Authorization": "Bearer t", "x-tenant": "acme"},
+	}
+	req := Request{Method: "a.S/Get", Metadata: map[string]string{"AUTHORIZATION": "Bearer t", "x-id": "1"}}
+	ws := Workspace{
+		Connections:       []Connection{conn},
+		Requests:          []SavedRequest{{Name: "a.S/Get", Request: req}},
+		Checklist:         []ChecklistItem{{Kind: ChecklistConnect, Connection: &conn}},
+		CurrentConnection: &conn,
+		CurrentRequest:    &req,
+	}
+
+	got := ws.WithoutSecrets()
+	want := Connection{
+		Address:         "api:443",
+		Auth:            Auth{Type: AuthBearer},
+		Proxy:           ProxySettings{Type: ProxyHTTP, Username: "u"},
+		TLS:             TLSSettings{Enabled: true, ClientCertFile: "c.pem"},
+		DefaultMetadata: map[string]string{"x-tenant": "acme"},
+	}
+	assert.Equal(t, want, got.Connections[0])
+	assert.Equal(t, want, *got.Checklist[0].Connection)
+	assert.Equal(t, want, *got.CurrentConnection)
+	assert.Equal(t, map[string]string{"x-id": "1"}, got.Requests[0].Request.Metadata)
+	assert.Equal(t, map[string]string{"x-id": "1"}, got.CurrentRequest.Metadata)
+
+	assert.Equal(t, "t", ws.Connections[0].Auth.Token, "original workspace untouched")
+	assert.Equal(t, "p", ws.CurrentConnection.Proxy.Password)
+	assert.Len(t, ws.CurrentRequest.Metadata, 2)
+	assert.Len(t, ws.CurrentConnection.DefaultMetadata, 2)
+}
@@ -101,6 +101,9 @@ func ClassifyError(err error) *UIError {
 			Actions: []ErrorAction{{Label: "Retry"}, {Label: "Edit Connection"}},
 		}
 
+	case errors.Is(err, ErrProxyFailed):
+		return proxyFailedError(err, err.Error())
+
 	case errors.Is(err, ErrReflectionUnavailable):
 		return &UIError{
 			Err:      err,
@@ -158,3 +161,21 @@ func ClassifyError(err error) *UIError {
 		Details:  err.Error(),
 	}
 }
+
+// proxyFailedError describes a connection that failed at the proxy rather
+// than at the server.
+func proxyFailedError(err error, details string) *UIError {
+	return &UIError{
+		Err:      err,
+		Severity: SeverityError,
+		Title:    "Proxy Connection Failed",
+		Message:  "The proxy could not connect you to the server. The server itself was not reached.",
+		Recovery: []string{
+			"Check the proxy host, port, and type",
+			"Check the proxy username and password",
+			"Check that the proxy can reach the server address",
+		},
+		Actions: []ErrorAction{{Label: "Retry"}, {Label: "Edit Connection"}},
+		Details: details,
+	}
+}
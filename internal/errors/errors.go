@@ -5,6 +5,7 @@ import "errors"
 // Sentinel errors for common failure modes.
 var (
 	ErrConnectionFailed      = errors.New("connection failed")
+	ErrProxyFailed           = errors.New("proxy failed")
 	ErrReflectionUnavailable = errors.New("reflection not available")
 	ErrInvalidDescriptor     = errors.New("invalid descriptor")
 	ErrUserCancelled         = errors.New("user cancelled operation")
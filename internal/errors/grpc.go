@@ -22,7 +22,12 @@ func ClassifyGRPCError(err error) *UIError {
 		// Not a gRPC error, fall back to standard classification
 		return ClassifyError(err)
 	}
+	return ClassifyStatus(err, st)
+}
 
+// ClassifyStatus converts err, a failed call, into a UIError by its status
+// st, for callers that already hold the status.
+func ClassifyStatus(err error, st *status.Status) *UIError {
 	// Build details string with gRPC code and message
 	details := fmt.Sprintf("gRPC: %s - %s", st.Code(), st.Message())
 
@@ -33,6 +38,25 @@ func ClassifyGRPCError(err error) *UIError {
 
 	switch st.Code() {
 	case codes.Unavailable:
+		// Dial errors reach us flattened into the status message
+		if strings.Contains(st.Message(), ErrProxyFailed.Error()) {
+			return proxyFailedError(err, details)
+		}
+		if strings.Contains(st.Message(), "too_many_pings") {
+			return &UIError{
+				Err:      err,
+				Severity: SeverityError,
+				Title:    "Keepalive Pings Rejected",
+				Message:  "The server closed the connection because it received keepalive pings more often than its policy allows.",
+				Recovery: []string{
+					"Raise the ping interval under Connection Settings > Keepalive",
+					"Turn off pinging without active calls",
+					"Turn keepalive off",
+				},
+				Actions: []ErrorAction{{Label: "Retry"}, {Label: "Edit Connection"}},
+				Details: details,
+			}
+		}
 		return &UIError{
 			Err:      err,
 			Severity: SeverityError,
@@ -0,0 +1,150 @@
+// Package export renders requests in formats used outside Grotto, and
+// reads them back.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// heredocDelimiter ends the stdin block of streaming commands. Compact JSON
+// cannot produce a line consisting of just this word.
+const heredocDelimiter = "EOF"
+
+// GrpcurlRequest is a call to reproduce with grpcurl.
+type GrpcurlRequest struct {
+	Address  string
+	TLS      domain.TLSSettings
+	Protoset string // FileDescriptorSet used instead of reflection, if any
+
+	// Authority and UserAgent override the :authority and user-agent of
+	// the call ("" keeps grpcurl's)
+	Authority string
+	UserAgent string
+
+	Method   string // "pkg.Service/Method"
+	Metadata map[string]string
+
+	// Body is the request JSON of a unary or server-streaming call ("" sends
+	// an empty message).
+	Body string
+
+	// Streaming marks client and bidi streaming calls, whose Messages are
+	// fed to grpcurl on stdin instead of Body.
+	Streaming bool
+	Messages  []string
+}
+
+// GrpcurlCommand returns the grpcurl command line equivalent to r. JSON is
+// compacted onto one line and every argument that needs it is quoted for
+// POSIX shells. Streaming requests read their messages from a heredoc.
+func GrpcurlCommand(r GrpcurlRequest) string {
+	args := []string{"grpcurl"}
+
+	if r.TLS.Enabled {
+		if r.TLS.SkipVerify {
+			args = append(args, "-insecure")
+		}
+		if r.TLS.CertFile != "" {
+			args = append(args, "-cacert", ShellQuote(r.TLS.CertFile))
+		}
+		if r.TLS.ClientCertFile != "" {
+			args = append(args, "-cert", ShellQuote(r.TLS.ClientCertFile))
+		}
+		if r.TLS.ClientKeyFile != "" {
+			args = append(args, "-key", ShellQuote(r.TLS.ClientKeyFile))
+		}
+		if r.TLS.ServerNameOverride != "" {
+			args = append(args, "-servername", ShellQuote(r.TLS.ServerNameOverride))
+		}
+	} else {
+		args = append(args, "-plaintext")
+	}
+
+	if r.Authority != "" {
+		args = append(args, "-authority", ShellQuote(r.Authority))
+	}
+	if r.UserAgent != "" {
+		args = append(args, "-user-agent", ShellQuote(r.UserAgent))
+	}
+	if r.Protoset != "" {
+		args = append(args, "-protoset", ShellQuote(r.Protoset))
+	}
+
+	keys := make([]string, 0, len(r.Metadata))
+	for k := range r.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-H", ShellQuote(k+": "+r.Metadata[k]))
+	}
+
+	var stdin []string
+	switch {
+	case r.Streaming:
+		args = append(args, "-d", "@")
+		for _, msg := range r.Messages {
+			if msg = compactJSON(msg); msg != "" {
+				stdin = append(stdin, msg)
+			}
+		}
+	case strings.TrimSpace(r.Body) != "":
+		args = append(args, "-d", ShellQuote(compactJSON(r.Body)))
+	}
+
+	if path, ok := unixSocketAddress(r.Address); ok {
+		args = append(args, "-unix", ShellQuote(path), ShellQuote(r.Method))
+	} else {
+		args = append(args, ShellQuote(r.Address), ShellQuote(r.Method))
+	}
+	cmd := strings.Join(args, " ")
+
+	if r.Streaming {
+		cmd += " <<'" + heredocDelimiter + "'\n"
+		for _, msg := range stdin {
+			cmd += msg + "\n"
+		}
+		cmd += heredocDelimiter
+	}
+	return cmd
+}
+
+// ShellQuote quotes s for a POSIX shell, leaving it bare when it contains
+// only characters the shell treats literally.
+func ShellQuote(s string) string {
+	if s != "" && strings.Trim(s, safeShellChars) == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// safeShellChars never need quoting.
+const safeShellChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-"
+
+// compactJSON removes insignificant whitespace from s, or returns it trimmed
+// when it is not valid JSON.
+func compactJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		return strings.TrimSpace(s)
+	}
+	return buf.String()
+}
+
+// unixSocketAddress returns the socket grpcurl's -unix flag expects for a
+// unix: or unix-abstract: target: a file path, or @name for an abstract
+// socket.
+func unixSocketAddress(address string) (string, bool) {
+	if name, ok := strings.CutPrefix(address, "unix-abstract:"); ok {
+		return "@" + name, true
+	}
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		return path, true
+	}
+	return strings.CutPrefix(address, "unix:")
+}
@@ -0,0 +1,577 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// ParseGrpcurlCommand reads a grpcurl command line, as pasted from a
+// terminal or a chat, into the request it makes. It understands POSIX
+// shell quoting, line continuations, and a body fed on stdin by a heredoc,
+// a here-string or echo. Parts of the command that cannot be carried over
+// into Grotto are returned as problems rather than dropped silently; the
+// error is for commands that are not a grpcurl call at all.
+//
+// The body of -d is split into its JSON messages: several messages, or
+// any read from stdin ("-d @"), make a streaming request, as
+// GrpcurlCommand writes them.
+func ParseGrpcurlCommand(cmd string) (GrpcurlRequest, []string, error) {
+	tokens, err := splitShell(cmd)
+	if err != nil {
+		return GrpcurlRequest{}, nil, err
+	}
+
+	args, stdin, problems, err := grpcurlArgs(tokens)
+	if err != nil {
+		return GrpcurlRequest{}, nil, err
+	}
+
+	p := grpcurlParser{
+		// Without -plaintext grpcurl uses TLS, verified against the system roots
+		req:      GrpcurlRequest{TLS: domain.TLSSettings{Enabled: true}},
+		problems: problems,
+	}
+	positional := p.parseFlags(args)
+	if err := p.setTarget(positional); err != nil {
+		return GrpcurlRequest{}, nil, err
+	}
+	p.setBody(stdin)
+	return p.req, p.problems, nil
+}
+
+// grpcurlParser collects the request and problems while reading arguments.
+type grpcurlParser struct {
+	req      GrpcurlRequest
+	problems []string
+
+	data     string // -d as given
+	hasData  bool
+	unix     bool
+	headers  map[string]bool // Header names seen, to report repeats
+	protoset bool
+}
+
+// grpcurlFlags lists grpcurl's flags: whether each takes a value, and how
+// it is imported. Flags not listed are reported as unknown.
+var grpcurlFlags = map[string]struct {
+	value  bool
+	ignore bool   // Only affects grpcurl's output, so nothing is lost
+	reason string // Why the flag cannot be imported, if it cannot
+}{
+	"plaintext":            {},
+	"insecure":             {},
+	"unix":                 {},
+	"cacert":               {value: true},
+	"cert":                 {value: true},
+	"key":                  {value: true},
+	"H":                    {value: true},
+	"rpc-header":           {value: true},
+	"d":                    {value: true},
+	"protoset":             {value: true},
+	"format":               {value: true},
+	"v":                    {ignore: true},
+	"vv":                   {ignore: true},
+	"emit-defaults":        {ignore: true},
+	"format-error":         {ignore: true},
+	"msg-template":         {ignore: true},
+	"allow-unknown-fields": {ignore: true},
+	"use-reflection":       {ignore: true},
+	"reflect-header":       {value: true, reason: "reflection-only headers are not supported"},
+	"proto":                {value: true, reason: "add the .proto directory under Connection Settings instead"},
+	"import-path":          {value: true, reason: "add the .proto directory under Connection Settings instead"},
+	"authority":            {value: true},
+	"servername":           {value: true},
+	"connect-timeout":      {value: true, reason: "set the timeout under Connection Settings instead"},
+	"max-time":             {value: true, reason: "set the timeout under Connection Settings instead"},
+	"keepalive-time":       {value: true, reason: "set keepalive under Connection Settings instead"},
+	"max-msg-sz":           {value: true, reason: "set message size limits under Connection Settings instead"},
+	"user-agent":           {value: true},
+	"expand-headers":       {reason: "${NAME} in headers is not expanded"},
+	"alts":                 {reason: "ALTS is not supported"},
+}
+
+// parseFlags applies the flags in args and returns the other arguments.
+// Like grpcurl's own flag parsing, -flag value, -flag=value and --flag
+// are all accepted; unlike it, flags may also follow the address.
+func (p *grpcurlParser) parseFlags(args []string) []string {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		name, value, hasValue := strings.Cut(name, "=")
+		flag, known := grpcurlFlags[name]
+		switch {
+		case !known:
+			p.problem("unknown flag %s was ignored", arg)
+			continue
+		case flag.value && !hasValue:
+			if i+1 >= len(args) {
+				p.problem("-%s has no value", name)
+				continue
+			}
+			i++
+			value = args[i]
+		}
+
+		switch {
+		case flag.ignore:
+		case flag.reason != "":
+			p.problem("%s was ignored: %s", strings.TrimSpace("-"+name+" "+value), flag.reason)
+		case flag.value:
+			p.applyValue(name, value)
+		default:
+			p.applyBool(name, !hasValue || value == "true" || value == "1")
+		}
+	}
+	return positional
+}
+
+// applyBool applies a boolean flag.
+func (p *grpcurlParser) applyBool(name string, on bool) {
+	switch name {
+	case "plaintext":
+		p.req.TLS.Enabled = !on
+	case "insecure":
+		p.req.TLS.SkipVerify = on
+	case "unix":
+		p.unix = on
+	}
+}
+
+// applyValue applies a flag taking a value.
+func (p *grpcurlParser) applyValue(name, value string) {
+	switch name {
+	case "cacert":
+		p.req.TLS.CertFile = value
+	case "cert":
+		p.req.TLS.ClientCertFile = value
+	case "key":
+		p.req.TLS.ClientKeyFile = value
+	case "servername":
+		p.req.TLS.ServerNameOverride = value
+	case "authority":
+		p.req.Authority = value
+	case "user-agent":
+		p.req.UserAgent = value
+	case "protoset":
+		if p.protoset {
+			p.problem("-protoset %s was ignored: only one descriptor set can be used", value)
+			return
+		}
+		p.protoset = true
+		p.req.Protoset = value
+	case "H", "rpc-header":
+		p.addHeader(value)
+	case "d":
+		p.data, p.hasData = value, true
+	case "format":
+		if value != "json" {
+			p.problem("-format %s was ignored: only JSON bodies can be imported", value)
+		}
+	}
+}
+
+// addHeader adds a "name: value" header to the metadata.
+func (p *grpcurlParser) addHeader(header string) {
+	key, value, ok := strings.Cut(header, ":")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !ok || key == "" {
+		p.problem("header %q was ignored: expected name: value", header)
+		return
+	}
+	if p.req.Metadata == nil {
+		p.req.Metadata = make(map[string]string)
+		p.headers = make(map[string]bool)
+	}
+	if p.headers[strings.ToLower(key)] {
+		p.problem("header %s is given more than once: only the last value was kept", key)
+	}
+	p.headers[strings.ToLower(key)] = true
+	p.req.Metadata[key] = value
+}
+
+// setTarget reads the address and method from the positional arguments.
+func (p *grpcurlParser) setTarget(positional []string) error {
+	if len(positional) == 0 {
+		return errors.New("the command has no server address")
+	}
+	address := positional[0]
+	if p.unix {
+		if name, ok := strings.CutPrefix(address, "@"); ok {
+			address = "unix-abstract:" + name
+		} else {
+			address = "unix:" + address
+		}
+	}
+	p.req.Address = address
+
+	if len(positional) < 2 {
+		p.problem("the command names no method")
+		return nil
+	}
+	method := positional[1]
+	switch method {
+	case "list", "describe":
+		return fmt.Errorf("grpcurl %s does not call a method, so there is nothing to import", method)
+	}
+	// grpcurl accepts both pkg.Service/Method and pkg.Service.Method
+	if !strings.Contains(method, "/") {
+		if i := strings.LastIndex(method, "."); i >= 0 {
+			method = method[:i] + "/" + method[i+1:]
+		}
+	}
+	if svc, name, ok := strings.Cut(method, "/"); !ok || svc == "" || name == "" {
+		p.problem("%q is not a method name (package.Service/Method)", positional[1])
+	}
+	p.req.Method = method
+
+	for _, extra := range positional[2:] {
+		p.problem("argument %q was ignored", extra)
+	}
+	return nil
+}
+
+// setBody sets the body from -d, reading it from stdin for -d @.
+func (p *grpcurlParser) setBody(stdin *string) {
+	if !p.hasData {
+		if stdin != nil {
+			p.problem("input on stdin was ignored: the command has no -d @")
+		}
+		return
+	}
+
+	data := p.data
+	fromStdin := data == "@" || data == "@-"
+	if fromStdin {
+		if stdin == nil {
+			p.req.Streaming = true
+			p.problem("the body is read from stdin, which is not part of the command")
+			return
+		}
+		data = *stdin
+	}
+
+	msgs := splitJSONMessages(data)
+	if fromStdin || len(msgs) > 1 {
+		p.req.Streaming = true
+		p.req.Messages = msgs
+		return
+	}
+	p.req.Body = strings.TrimSpace(data)
+}
+
+func (p *grpcurlParser) problem(format string, args ...any) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+// splitJSONMessages splits a stream of JSON values, as grpcurl reads them,
+// into one string per value. Text that is not such a stream is returned
+// whole, for the request editor to point out what is wrong with it.
+func splitJSONMessages(data string) []string {
+	dec := json.NewDecoder(strings.NewReader(data))
+	var msgs []string
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			return msgs
+		}
+		if err != nil {
+			if data = strings.TrimSpace(data); data == "" {
+				return nil
+			}
+			return []string{data}
+		}
+		msgs = append(msgs, string(bytes.TrimSpace(raw)))
+	}
+}
+
+// grpcurlArgs finds the grpcurl command among the shell tokens and returns
+// its arguments, the text it reads on stdin (nil when none is given), and
+// problems with the rest of the command line.
+func grpcurlArgs(tokens []shellToken) (args []string, stdin *string, problems []string, err error) {
+	commands := splitCommands(tokens)
+
+	found := -1
+	for i, c := range commands {
+		if len(c.words) > 0 && isGrpcurl(c.words[0].text) {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		// Arguments pasted without the command name
+		if len(commands) == 0 || len(commands[0].words) == 0 || !strings.HasPrefix(commands[0].words[0].text, "-") {
+			return nil, nil, nil, errors.New("not a grpcurl command")
+		}
+		found = 0
+	} else {
+		commands[found].words = commands[found].words[1:]
+	}
+
+	for i, c := range commands {
+		switch {
+		case i == found || len(c.words) == 0:
+		case i == found-1 && commands[found].piped:
+			if c.words[0].text != "echo" {
+				problems = append(problems, fmt.Sprintf("input piped from %q was ignored", c.String()))
+				continue
+			}
+			var words []string
+			for _, w := range c.words[1:] {
+				if w.text != "-n" && w.text != "-e" {
+					words = append(words, w.text)
+				}
+			}
+			text := strings.Join(words, " ")
+			stdin = &text
+		case i == found+1 && c.piped:
+			// Output piped elsewhere, e.g. into jq, does not change the call
+		default:
+			problems = append(problems, fmt.Sprintf("%q is not part of the grpcurl command and was ignored", c.String()))
+		}
+	}
+
+	words := commands[found].words
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if !w.op {
+			args = append(args, w.text)
+			continue
+		}
+		var target string
+		if i+1 < len(words) && !words[i+1].op {
+			i++
+			target = words[i].text
+		}
+		switch w.text {
+		case "<<", "<<<":
+			stdin = &target
+		case "<":
+			problems = append(problems, fmt.Sprintf("input read from %s was ignored", target))
+		}
+		// Output redirections do not change the call
+	}
+	return args, stdin, problems, nil
+}
+
+// isGrpcurl reports whether a command name runs grpcurl, given as a path
+// or not.
+func isGrpcurl(name string) bool {
+	base := path.Base(strings.ReplaceAll(name, `\`, "/"))
+	return base == "grpcurl" || base == "grpcurl.exe"
+}
+
+// shellCommand is one simple command of a command line: its words and
+// redirections, and whether its input is piped from the command before.
+type shellCommand struct {
+	words []shellToken
+	piped bool
+}
+
+func (c shellCommand) String() string {
+	parts := make([]string, len(c.words))
+	for i, w := range c.words {
+		parts[i] = w.text
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitCommands splits tokens into commands at pipes, separators and
+// newlines. A leading "$" prompt is dropped.
+func splitCommands(tokens []shellToken) []shellCommand {
+	var (
+		commands []shellCommand
+		cur      shellCommand
+	)
+	for _, t := range tokens {
+		if t.op && isSeparator(t.text) {
+			commands = append(commands, cur)
+			cur = shellCommand{piped: t.text == "|"}
+			continue
+		}
+		if len(cur.words) == 0 && !t.op && t.text == "$" {
+			continue
+		}
+		cur.words = append(cur.words, t)
+	}
+	return append(commands, cur)
+}
+
+func isSeparator(op string) bool {
+	switch op {
+	case "|", "||", "&&", ";", "&", "\n":
+		return true
+	}
+	return false
+}
+
+// shellToken is a word of a command line after quote removal, or an
+// operator. A heredoc's operator is followed by a word holding its body.
+type shellToken struct {
+	text string
+	op   bool
+}
+
+// shellOperators are the operators recognized, longest first so that
+// each is matched whole.
+var shellOperators = []string{"<<<", "<<-", "<<", ">>", ">&", "||", "&&", "|", "&", ";", "<", ">"}
+
+// splitShell splits a command line into words and operators the way a
+// POSIX shell does, without expanding anything. Heredoc bodies are read
+// from the lines following the one they are started on.
+func splitShell(s string) ([]shellToken, error) {
+	var (
+		tokens   []shellToken
+		word     strings.Builder
+		inWord   bool
+		heredocs []pendingHeredoc
+		delim    *pendingHeredoc // Heredoc waiting for its delimiter word
+	)
+	s = strings.ReplaceAll(s, "\r\n", "\n") // Pasted from Windows
+	flush := func() {
+		if !inWord {
+			return
+		}
+		tokens = append(tokens, shellToken{text: word.String()})
+		word.Reset()
+		inWord = false
+		if delim != nil {
+			delim.index = len(tokens) - 1
+			delim.delimiter = tokens[delim.index].text
+			heredocs = append(heredocs, *delim)
+			delim = nil
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(s) && s[i+1] != '\n' {
+				word.WriteByte(s[i+1])
+				inWord = true
+			}
+			i += 2 // A backslash-newline joins the lines
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			inWord = true
+			i += end + 2
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) && strings.IndexByte("$`\"\\\n", s[j+1]) >= 0 {
+					j++
+					if s[j] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+			i = j + 1
+		case c == '#' && !inWord:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '\n':
+			flush()
+			tokens = append(tokens, shellToken{text: "\n", op: true})
+			i++
+			for _, h := range heredocs {
+				tokens[h.index].text, i = h.read(s, i)
+			}
+			heredocs = nil
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+			i++
+		case strings.IndexByte("|&;<>", c) >= 0:
+			// A number before a redirection is a file descriptor
+			if (c == '<' || c == '>') && inWord && strings.Trim(word.String(), "0123456789") == "" {
+				word.Reset()
+				inWord = false
+			}
+			flush()
+			op := ""
+			for _, o := range shellOperators {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			i += len(op)
+			if op == "<<" || op == "<<-" {
+				delim = &pendingHeredoc{stripTabs: op == "<<-"}
+				op = "<<"
+			}
+			tokens = append(tokens, shellToken{text: op, op: true})
+		default:
+			word.WriteByte(c)
+			inWord = true
+			i++
+		}
+	}
+	flush()
+
+	// A heredoc cut off before its body is empty
+	for _, h := range heredocs {
+		tokens[h.index].text = ""
+	}
+	return tokens, nil
+}
+
+// pendingHeredoc is a heredoc whose body starts on the next line.
+type pendingHeredoc struct {
+	index     int // Of the delimiter word, replaced by the body
+	delimiter string
+	stripTabs bool // <<- strips leading tabs
+}
+
+// read returns the body of the heredoc starting at s[i] and the index
+// after its delimiter line. A missing delimiter ends it at the end of s.
+func (h pendingHeredoc) read(s string, i int) (string, int) {
+	var body strings.Builder
+	for i < len(s) {
+		end := strings.IndexByte(s[i:], '\n')
+		next := len(s)
+		if end >= 0 {
+			next = i + end + 1
+			end += i
+		} else {
+			end = len(s)
+		}
+		line := s[i:end]
+		if h.stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+		i = next
+		if strings.TrimRight(line, " \t\r") == h.delimiter {
+			break
+		}
+		body.WriteString(line + "\n")
+	}
+	return body.String(), i
+}
@@ -0,0 +1,309 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitShell(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string // Operators shown in angle brackets
+	}{
+		{"plain words", "grpcurl -plaintext  localhost:1", []string{"grpcurl", "-plaintext", "localhost:1"}},
+		{"single quotes keep everything", `-d '{"a": "$x \n"}'`, []string{"-d", `{"a": "$x \n"}`}},
+		{"double quotes", `-H "authorization: Bearer abc"`, []string{"-H", "authorization: Bearer abc"}},
+		{"escapes in double quotes", `"{\"a\": \"b\\c\", \"d\": \"\$x\"}"`, []string{`{"a": "b\c", "d": "$x"}`}},
+		{"other backslashes in double quotes kept", `"a\nb"`, []string{`a\nb`}},
+		{"escaped quote in single quotes", `'it'\''s'`, []string{"it's"}},
+		{"backslash outside quotes", `a\ b \"c\"`, []string{"a b", `"c"`}},
+		{"adjacent quoted parts join", `'a'"b"c`, []string{"abc"}},
+		{"empty quotes are a word", `-d ''`, []string{"-d", ""}},
+		{"line continuation", "grpcurl \\\n  -plaintext \\\n  host:1", []string{"grpcurl", "-plaintext", "host:1"}},
+		{"continuation in double quotes", "\"a\\\nb\"", []string{"ab"}},
+		{"newline inside quotes kept", "'a\nb'", []string{"a\nb"}},
+		{"windows line endings", "grpcurl \\\r\n  host:1", []string{"grpcurl", "host:1"}},
+		{"comment", "grpcurl host:1 # try this", []string{"grpcurl", "host:1"}},
+		{"hash inside word", "a#b", []string{"a#b"}},
+		{"operators", "echo x|grpcurl a;b&&c", []string{"echo", "x", "<|>", "grpcurl", "a", "<;>", "b", "<&&>", "c"}},
+		{"fd redirection", "grpcurl a 2>&1 >out", []string{"grpcurl", "a", "<>&>", "1", "<>>", "out"}},
+		{"here-string", `grpcurl a <<< '{"x":1}'`, []string{"grpcurl", "a", "<<<<>", `{"x":1}`}},
+		{
+			"heredoc",
+			"grpcurl -d @ a <<'EOF'\n{\"x\": 1}\n{\"x\": 2}\nEOF\n",
+			[]string{"grpcurl", "-d", "@", "a", "<<<>", "{\"x\": 1}\n{\"x\": 2}\n", "<\n>"},
+		},
+		{
+			"heredoc with tabs stripped",
+			"grpcurl a <<-END\n\t{}\n\tEND",
+			[]string{"grpcurl", "a", "<<<>", "{}\n", "<\n>"},
+		},
+		{
+			"heredoc without delimiter line",
+			"grpcurl a <<EOF\n{}",
+			[]string{"grpcurl", "a", "<<<>", "{}\n", "<\n>"},
+		},
+		{"heredoc cut off", "grpcurl a <<EOF", []string{"grpcurl", "a", "<<<>", ""}},
+		{"utf-8", `-d '{"name":"café"}' caf\é`, []string{"-d", `{"name":"café"}`, "café"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := splitShell(tt.in)
+			require.NoError(t, err)
+			var got []string
+			for _, tok := range tokens {
+				if tok.op {
+					got = append(got, "<"+tok.text+">")
+				} else {
+					got = append(got, tok.text)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitShell_Unterminated(t *testing.T) {
+	_, err := splitShell(`grpcurl -d '{"a":1}`)
+	assert.ErrorContains(t, err, "single quote")
+	_, err = splitShell(`grpcurl -H "x: y`)
+	assert.ErrorContains(t, err, "double quote")
+}
+
+func TestParseGrpcurlCommand(t *testing.T) {
+	const method = "grpctest.TestService/UnaryEcho"
+	plaintext := domain.TLSSettings{}
+
+	tests := []struct {
+		name     string
+		cmd      string
+		want     GrpcurlRequest
+		problems []string
+	}{
+		{
+			name: "minimal",
+			cmd:  "grpcurl -plaintext localhost:50051 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "localhost:50051", Method: method, TLS: plaintext},
+		},
+		{
+			name: "TLS by default",
+			cmd:  "grpcurl api.example.com:443 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "api.example.com:443", Method: method, TLS: domain.TLSSettings{Enabled: true}},
+		},
+		{
+			name: "TLS files and insecure",
+			cmd:  "grpcurl -insecure -cacert '/certs/my ca.pem' --cert=/c.pem -key /c.key -servername api.internal api:443 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "api:443", Method: method, TLS: domain.TLSSettings{
+				Enabled: true, SkipVerify: true, CertFile: "/certs/my ca.pem", ClientCertFile: "/c.pem", ClientKeyFile: "/c.key",
+				ServerNameOverride: "api.internal",
+			}},
+		},
+		{
+			name: "plaintext=false",
+			cmd:  "grpcurl -plaintext=false api:443 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "api:443", Method: method, TLS: domain.TLSSettings{Enabled: true}},
+		},
+		{
+			name: "metadata and body",
+			cmd: `grpcurl -plaintext -H 'authorization: Bearer it'\''s' -H "x-trace:abc" ` +
+				`-d '{"item": {"id": "1"}}' localhost:1 grpctest.TestService/UnaryEcho`,
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext,
+				Metadata: map[string]string{"authorization": "Bearer it's", "x-trace": "abc"},
+				Body:     `{"item": {"id": "1"}}`,
+			},
+		},
+		{
+			name: "dotted method and flags after the address",
+			cmd:  "$ grpcurl localhost:1 grpctest.TestService.UnaryEcho -plaintext",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext},
+		},
+		{
+			name: "command given as a path, continued over lines",
+			cmd:  "/usr/local/bin/grpcurl \\\n  -plaintext \\\n  -d '{}' \\\n  localhost:1 \\\n  grpctest.TestService/UnaryEcho\n",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext, Body: "{}"},
+		},
+		{
+			name: "arguments without the command name",
+			cmd:  "-plaintext localhost:1 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext},
+		},
+		{
+			name: "several messages inline",
+			cmd:  `grpcurl -plaintext -d '{"id":"1"} {"id":"2"}' localhost:1 grpctest.TestService/CollectItems`,
+			want: GrpcurlRequest{Address: "localhost:1", Method: "grpctest.TestService/CollectItems", TLS: plaintext,
+				Streaming: true, Messages: []string{`{"id":"1"}`, `{"id":"2"}`}},
+		},
+		{
+			name: "heredoc",
+			cmd:  "grpcurl -plaintext -d @ localhost:1 grpctest.TestService/CollectItems <<EOF\n{\"id\": \"1\"}\n{\n  \"id\": \"2\"\n}\nEOF",
+			want: GrpcurlRequest{Address: "localhost:1", Method: "grpctest.TestService/CollectItems", TLS: plaintext,
+				Streaming: true, Messages: []string{`{"id": "1"}`, "{\n  \"id\": \"2\"\n}"}},
+		},
+		{
+			name: "echo piped",
+			cmd:  `echo '{"id":"1"}' | grpcurl -plaintext -d @ localhost:1 grpctest.TestService/UnaryEcho | jq .`,
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext,
+				Streaming: true, Messages: []string{`{"id":"1"}`}},
+		},
+		{
+			name: "curl-style stdin",
+			cmd:  `grpcurl -plaintext -d @- localhost:1 grpctest.TestService/UnaryEcho <<< '{}'`,
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext,
+				Streaming: true, Messages: []string{`{}`}},
+		},
+		{
+			name: "invalid JSON kept for the editor",
+			cmd:  `grpcurl -plaintext -d '{oops}' localhost:1 grpctest.TestService/UnaryEcho`,
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext, Body: "{oops}"},
+		},
+		{
+			name: "unix socket",
+			cmd:  "grpcurl -plaintext -unix '/tmp/my app.sock' grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "unix:/tmp/my app.sock", Method: method, TLS: plaintext},
+		},
+		{
+			name: "abstract unix socket",
+			cmd:  "grpcurl -plaintext -unix @grotto grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "unix-abstract:grotto", Method: method, TLS: plaintext},
+		},
+		{
+			name: "protoset",
+			cmd:  "grpcurl -plaintext -protoset api.pb -protoset more.pb localhost:1 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext, Protoset: "api.pb"},
+			problems: []string{
+				"-protoset more.pb was ignored: only one descriptor set can be used",
+			},
+		},
+		{
+			name: "output flags ignored quietly",
+			cmd:  "grpcurl -v -emit-defaults -format json -plaintext localhost:1 grpctest.TestService/UnaryEcho > out.json 2>&1",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext},
+		},
+		{
+			name: "unsupported and unknown flags reported",
+			cmd:  "grpcurl -plaintext -max-time 5 -keepalive-time=x -bogus -format text localhost:1 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext},
+			problems: []string{
+				"-max-time 5 was ignored: set the timeout under Connection Settings instead",
+				"-keepalive-time x was ignored: set keepalive under Connection Settings instead",
+				"unknown flag -bogus was ignored",
+				"-format text was ignored: only JSON bodies can be imported",
+			},
+		},
+		{
+			name: "authority and user-agent",
+			cmd:  "grpcurl -plaintext -authority tenant-a.example.com -user-agent 'grotto infra' 10.0.0.1:443 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "10.0.0.1:443", Method: method, TLS: plaintext,
+				Authority: "tenant-a.example.com", UserAgent: "grotto infra"},
+		},
+		{
+			name: "bad and repeated headers",
+			cmd:  "grpcurl -plaintext -H novalue -H 'x-a: 1' -H 'X-A: 2' localhost:1 grpctest.TestService/UnaryEcho",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext,
+				Metadata: map[string]string{"x-a": "1", "X-A": "2"}},
+			problems: []string{
+				`header "novalue" was ignored: expected name: value`,
+				"header X-A is given more than once: only the last value was kept",
+			},
+		},
+		{
+			name: "stdin missing",
+			cmd:  "grpcurl -plaintext -d @ localhost:1 grpctest.TestService/CollectItems",
+			want: GrpcurlRequest{Address: "localhost:1", Method: "grpctest.TestService/CollectItems", TLS: plaintext, Streaming: true},
+			problems: []string{
+				"the body is read from stdin, which is not part of the command",
+			},
+		},
+		{
+			name: "stdin from elsewhere",
+			cmd:  "cat body.json | grpcurl -plaintext -d @ localhost:1 grpctest.TestService/UnaryEcho < other.json",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext, Streaming: true},
+			problems: []string{
+				`input piped from "cat body.json" was ignored`,
+				"input read from other.json was ignored",
+				"the body is read from stdin, which is not part of the command",
+			},
+		},
+		{
+			name: "other commands and arguments",
+			cmd:  "export TOKEN=abc && grpcurl -plaintext localhost:1 grpctest.TestService/UnaryEcho extra",
+			want: GrpcurlRequest{Address: "localhost:1", Method: method, TLS: plaintext},
+			problems: []string{
+				`"export TOKEN=abc" is not part of the grpcurl command and was ignored`,
+				`argument "extra" was ignored`,
+			},
+		},
+		{
+			name:     "no method",
+			cmd:      "grpcurl -plaintext localhost:1",
+			want:     GrpcurlRequest{Address: "localhost:1", TLS: plaintext},
+			problems: []string{"the command names no method"},
+		},
+		{
+			name:     "not a method name",
+			cmd:      "grpcurl -plaintext localhost:1 Echo",
+			want:     GrpcurlRequest{Address: "localhost:1", Method: "Echo", TLS: plaintext},
+			problems: []string{`"Echo" is not a method name (package.Service/Method)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, problems, err := ParseGrpcurlCommand(tt.cmd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.problems, problems)
+		})
+	}
+}
+
+func TestParseGrpcurlCommand_Errors(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"", "not a grpcurl command"},
+		{"curl https://example.com", "not a grpcurl command"},
+		{"grpcurl -plaintext", "no server address"},
+		{"grpcurl -plaintext localhost:1 list", "does not call a method"},
+		{"grpcurl -plaintext localhost:1 describe", "does not call a method"},
+		{`grpcurl -d '{"a":1} localhost:1 s/M`, "unterminated single quote"},
+	}
+	for _, tt := range tests {
+		_, _, err := ParseGrpcurlCommand(tt.cmd)
+		assert.ErrorContains(t, err, tt.want, "ParseGrpcurlCommand(%q)", tt.cmd)
+	}
+}
+
+// Commands copied from Grotto import back as the request they were
+// copied from.
+func TestParseGrpcurlCommand_RoundTrip(t *testing.T) {
+	requests := []GrpcurlRequest{
+		{Address: "localhost:50051", Method: "grpctest.TestService/UnaryEcho", Body: `{"name":"O'Brien","text":"a\nb"}`},
+		{
+			Address: "api.example.com:443", Method: "pkg.Svc/Call",
+			TLS: domain.TLSSettings{Enabled: true, SkipVerify: true, CertFile: "/certs/my ca.pem", ServerNameOverride: "api.internal"},
+			Metadata: map[string]string{
+				"authorization": "Bearer it's-secret",
+				"x-trace":       "$HOME *",
+			},
+		},
+		{Address: "unix:/tmp/my app.sock", Method: "pkg.Svc/Call", Protoset: "/tmp/api.pb"},
+		{
+			Address: "localhost:50051", Method: "grpctest.TestService/CollectItems",
+			Streaming: true, Messages: []string{`{"id":"1"}`, `{"id":"it's 2"}`},
+		},
+	}
+	for _, req := range requests {
+		cmd := GrpcurlCommand(req)
+		got, problems, err := ParseGrpcurlCommand(cmd)
+		require.NoError(t, err, cmd)
+		assert.Empty(t, problems, cmd)
+		assert.Equal(t, req, got, cmd)
+	}
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrpcurlCommand(t *testing.T) {
+	const (
+		addr   = "localhost:50051"
+		method = "grpctest.TestService/UnaryEcho"
+	)
+
+	tests := []struct {
+		name string
+		req  GrpcurlRequest
+		want string
+	}{
+		{
+			name: "empty body",
+			req:  GrpcurlRequest{Address: addr, Method: method},
+			want: `grpcurl -plaintext localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "whitespace body",
+			req:  GrpcurlRequest{Address: addr, Method: method, Body: "  \n"},
+			want: `grpcurl -plaintext localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "pretty JSON compacted",
+			req:  GrpcurlRequest{Address: addr, Method: method, Body: "{\n  \"id\": \"a b\",\n  \"n\": 1\n}"},
+			want: `grpcurl -plaintext -d '{"id":"a b","n":1}' localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "single quote in body",
+			req:  GrpcurlRequest{Address: addr, Method: method, Body: `{"name": "O'Brien"}`},
+			want: `grpcurl -plaintext -d '{"name":"O'\''Brien"}' localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "escaped newline in JSON string stays escaped",
+			req:  GrpcurlRequest{Address: addr, Method: method, Body: `{"text": "line1\nline2"}`},
+			want: `grpcurl -plaintext -d '{"text":"line1\nline2"}' localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "invalid JSON kept verbatim",
+			req:  GrpcurlRequest{Address: addr, Method: method, Body: "{oops\n}"},
+			want: "grpcurl -plaintext -d '{oops\n}' localhost:50051 grpctest.TestService/UnaryEcho",
+		},
+		{
+			name: "metadata sorted and quoted",
+			req: GrpcurlRequest{Address: addr, Method: method, Metadata: map[string]string{
+				"x-trace":       "abc",
+				"authorization": "Bearer it's-secret",
+			}},
+			want: `grpcurl -plaintext -H 'authorization: Bearer it'\''s-secret' -H 'x-trace: abc' localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "TLS flags",
+			req: GrpcurlRequest{Address: "api.example.com:443", Method: method, TLS: domain.TLSSettings{
+				Enabled:        true,
+				SkipVerify:     true,
+				CertFile:       "/certs/my ca.pem",
+				ClientCertFile: "/certs/client.pem",
+				ClientKeyFile:  "/certs/client.key",
+
+				ServerNameOverride: "api.internal",
+			}},
+			want: `grpcurl -insecure -cacert '/certs/my ca.pem' -cert /certs/client.pem -key /certs/client.key -servername api.internal api.example.com:443 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "TLS with system roots",
+			req:  GrpcurlRequest{Address: "api.example.com:443", Method: method, TLS: domain.TLSSettings{Enabled: true}},
+			want: `grpcurl api.example.com:443 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "authority and user-agent",
+			req:  GrpcurlRequest{Address: "10.0.0.1:443", Method: method, Authority: "tenant-a.example.com", UserAgent: "grotto infra"},
+			want: `grpcurl -plaintext -authority tenant-a.example.com -user-agent 'grotto infra' 10.0.0.1:443 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "protoset",
+			req:  GrpcurlRequest{Address: addr, Method: method, Protoset: "/tmp/api.pb"},
+			want: `grpcurl -plaintext -protoset /tmp/api.pb localhost:50051 grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "unix socket",
+			req:  GrpcurlRequest{Address: "unix:///tmp/my app.sock", Method: method},
+			want: `grpcurl -plaintext -unix '/tmp/my app.sock' grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "abstract unix socket",
+			req:  GrpcurlRequest{Address: "unix-abstract:grotto", Method: method},
+			want: `grpcurl -plaintext -unix @grotto grpctest.TestService/UnaryEcho`,
+		},
+		{
+			name: "streaming heredoc",
+			req: GrpcurlRequest{Address: addr, Method: "grpctest.TestService/CollectItems", Streaming: true, Messages: []string{
+				"{\n  \"id\": \"1\"\n}",
+				"",
+				`{"id": "it's 2"}`,
+			}},
+			want: "grpcurl -plaintext -d @ localhost:50051 grpctest.TestService/CollectItems <<'EOF'\n" +
+				"{\"id\":\"1\"}\n" +
+				"{\"id\":\"it's 2\"}\n" +
+				"EOF",
+		},
+		{
+			name: "streaming without messages",
+			req:  GrpcurlRequest{Address: addr, Method: "grpctest.TestService/CollectItems", Streaming: true, Body: `{"ignored":true}`},
+			want: "grpcurl -plaintext -d @ localhost:50051 grpctest.TestService/CollectItems <<'EOF'\nEOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GrpcurlCommand(tt.req))
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "''"},
+		{"localhost:50051", "localhost:50051"},
+		{"a b", "'a b'"},
+		{"it's", `'it'\''s'`},
+		{"''", `''\'''\'''`},
+		{"line1\nline2", "'line1\nline2'"},
+		{"$HOME", "'$HOME'"},
+		{"a*b", "'a*b'"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ShellQuote(tt.in), "ShellQuote(%q)", tt.in)
+	}
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Directions of a StreamMessage.
+const (
+	DirectionRecv = "recv"
+	DirectionSend = "send"
+)
+
+// StreamMessage is one message of a streaming call.
+type StreamMessage struct {
+	Direction string // DirectionRecv or DirectionSend
+	Time      time.Time
+	JSON      string
+}
+
+// ndjsonLine is the shape of one exported line.
+type ndjsonLine struct {
+	Direction string          `json:"direction"`
+	TS        string          `json:"ts"`
+	Msg       json.RawMessage `json:"msg"`
+}
+
+// WriteNDJSON writes msgs to w as newline-delimited JSON, one
+// {"direction":"recv","ts":"...","msg":{...}} object per line, with the
+// timestamp in RFC 3339 format. Each message is compacted onto its line; a
+// message that isn't valid JSON is written as a JSON string. Lines are
+// written as they are encoded rather than built up in memory.
+func WriteNDJSON(w io.Writer, msgs []StreamMessage) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	var buf bytes.Buffer
+	for i, m := range msgs {
+		buf.Reset()
+		if err := json.Compact(&buf, []byte(m.JSON)); err != nil {
+			buf.Reset()
+			quoted, _ := json.Marshal(m.JSON)
+			buf.Write(quoted)
+		}
+		line := ndjsonLine{
+			Direction: m.Direction,
+			TS:        m.Time.Format(time.RFC3339Nano),
+			Msg:       buf.Bytes(),
+		}
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("write message %d: %w", i+1, err)
+		}
+	}
+	return bw.Flush()
+}
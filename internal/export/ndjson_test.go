@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 30, 0, 123000000, time.UTC)
+	msgs := []StreamMessage{
+		{Direction: DirectionSend, Time: ts, JSON: "{\n  \"id\": \"a\"\n}"},
+		{Direction: DirectionRecv, Time: ts.Add(time.Second), JSON: `{"text": "line1\nline2", "html": "<b>"}`},
+		{Direction: DirectionRecv, Time: ts.Add(2 * time.Second), JSON: "not\njson"},
+	}
+
+	var out strings.Builder
+	require.NoError(t, WriteNDJSON(&out, msgs))
+
+	want := `{"direction":"send","ts":"2026-03-01T12:30:00.123Z","msg":{"id":"a"}}
+{"direction":"recv","ts":"2026-03-01T12:30:01.123Z","msg":{"text":"line1\nline2","html":"<b>"}}
+{"direction":"recv","ts":"2026-03-01T12:30:02.123Z","msg":"not\njson"}
+`
+	assert.Equal(t, want, out.String())
+
+	// Every line is one JSON object, even with newlines inside messages
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	lines := 0
+	for scanner.Scan() {
+		var line struct {
+			Direction string          `json:"direction"`
+			TS        time.Time       `json:"ts"`
+			Msg       json.RawMessage `json:"msg"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), "line %d", lines+1)
+		assert.Equal(t, msgs[lines].Direction, line.Direction)
+		assert.True(t, msgs[lines].Time.Equal(line.TS))
+		lines++
+	}
+	assert.Equal(t, len(msgs), lines)
+}
+
+func TestWriteNDJSON_Empty(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, WriteNDJSON(&out, nil))
+	assert.Empty(t, out.String())
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteNDJSON_WriteError(t *testing.T) {
+	msgs := make([]StreamMessage, 1000)
+	for i := range msgs {
+		msgs[i] = StreamMessage{Direction: DirectionRecv, JSON: `{"padding": "` + strings.Repeat("x", 100) + `"}`}
+	}
+	assert.ErrorContains(t, WriteNDJSON(failingWriter{}, msgs), "disk full")
+}
@@ -0,0 +1,277 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxAuditPayloads bounds the messages captured in each direction of one
+// call, so a long stream cannot make a record without limit. Counts and
+// sizes keep adding up past it.
+const maxAuditPayloads = 100
+
+// AuditRecord is one line of the audit trail: a call made on a connection,
+// written once the call has ended.
+type AuditRecord struct {
+	Time       time.Time `json:"ts"` // When the call started
+	Server     string    `json:"server"`
+	Method     string    `json:"method"` // "/pkg.Service/Method"
+	Kind       string    `json:"kind"`   // "unary", "server_stream", "client_stream" or "bidi_stream"
+	Code       string    `json:"code"`   // Status code name, "OK" on success
+	Error      string    `json:"error,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+
+	RequestMessages  int `json:"request_messages"`
+	RequestBytes     int `json:"request_bytes"` // Encoded, before compression
+	ResponseMessages int `json:"response_messages"`
+	ResponseBytes    int `json:"response_bytes"`
+
+	// Requests and Responses are the messages in protobuf wire format,
+	// base64-encoded, when payload capture is on
+	Requests          []string `json:"requests,omitempty"`
+	Responses         []string `json:"responses,omitempty"`
+	PayloadsTruncated bool     `json:"payloads_truncated,omitempty"`
+}
+
+// line formats r as a line of JSON.
+func (r AuditRecord) line() ([]byte, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// AuditSettings turns on the audit trail for connections made after it is
+// set on a ConnectionManager.
+type AuditSettings struct {
+	// Writer receives one JSON line per call; nil turns auditing off. It
+	// must be safe for concurrent use.
+	Writer io.Writer
+	// Payloads also records the messages sent and received
+	Payloads bool
+}
+
+// Enabled reports whether calls are audited.
+func (s AuditSettings) Enabled() bool {
+	return s.Writer != nil
+}
+
+// auditor writes an AuditRecord for every call through its client
+// interceptors.
+type auditor struct {
+	settings AuditSettings
+	server   string
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func newAuditor(settings AuditSettings, server string, logger *slog.Logger) *auditor {
+	return &auditor{settings: settings, server: server, logger: logger, now: time.Now}
+}
+
+// dialOptions returns the dial options installing the interceptors.
+func (a *auditor) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(a.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(a.streamInterceptor),
+	}
+}
+
+// unaryInterceptor audits a unary call.
+func (a *auditor) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	call := a.begin(method, "unary")
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	call.sent(req)
+	if err == nil {
+		call.received(reply)
+	}
+	call.finish(err)
+	return err
+}
+
+// streamInterceptor audits a streaming call. Its status is only known once
+// the stream ends: when a receive fails or reaches the end, when the
+// single response of a client stream arrives, or when its context is
+// cancelled, whichever comes first.
+func (a *auditor) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	call := a.begin(method, streamKind(desc))
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		call.finish(err)
+		return nil, err
+	}
+	s := &auditStream{ClientStream: cs, call: call, serverStreams: desc.ServerStreams}
+	streamCtx := cs.Context()
+	s.stop = context.AfterFunc(streamCtx, func() {
+		call.finish(status.FromContextError(streamCtx.Err()).Err())
+	})
+	return s, nil
+}
+
+// streamKind names a stream's shape as AuditRecord.Kind does.
+func streamKind(desc *grpc.StreamDesc) string {
+	switch {
+	case desc.ClientStreams && desc.ServerStreams:
+		return "bidi_stream"
+	case desc.ClientStreams:
+		return "client_stream"
+	case desc.ServerStreams:
+		return "server_stream"
+	}
+	return "unary"
+}
+
+// begin starts the record of a call.
+func (a *auditor) begin(method, kind string) *auditCall {
+	return &auditCall{
+		a: a,
+		rec: AuditRecord{
+			Time:   a.now(),
+			Server: a.server,
+			Method: method,
+			Kind:   kind,
+		},
+	}
+}
+
+// write appends rec to the audit trail. A failure is logged, not returned:
+// it must not fail the call.
+func (a *auditor) write(rec AuditRecord) {
+	line, err := rec.line()
+	if err == nil {
+		_, err = a.settings.Writer.Write(line)
+	}
+	if err != nil {
+		a.logger.Warn("failed to write audit record",
+			slog.String("method", rec.Method),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// auditCall is the record of one call in progress. Streams may send and
+// receive on different goroutines.
+type auditCall struct {
+	a    *auditor
+	mu   sync.Mutex
+	rec  AuditRecord
+	done bool
+}
+
+// sent counts a request message.
+func (c *auditCall) sent(m any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rec.RequestMessages++
+	c.rec.RequestBytes += messageSize(m)
+	c.rec.Requests = c.capture(c.rec.Requests, m)
+}
+
+// received counts a response message.
+func (c *auditCall) received(m any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rec.ResponseMessages++
+	c.rec.ResponseBytes += messageSize(m)
+	c.rec.Responses = c.capture(c.rec.Responses, m)
+}
+
+// capture adds m to payloads when payload capture is on and there is room.
+// The caller must hold c.mu.
+func (c *auditCall) capture(payloads []string, m any) []string {
+	if !c.a.settings.Payloads {
+		return payloads
+	}
+	if len(payloads) >= maxAuditPayloads {
+		c.rec.PayloadsTruncated = true
+		return payloads
+	}
+	data, err := marshalMessage(m)
+	if err != nil {
+		return payloads
+	}
+	return append(payloads, base64.StdEncoding.EncodeToString(data))
+}
+
+// finish writes the record with the call's final status. Only the first
+// call has an effect.
+func (c *auditCall) finish(err error) {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		return
+	}
+	c.done = true
+	st := status.Convert(err)
+	c.rec.Code = st.Code().String()
+	c.rec.Error = st.Message()
+	c.rec.DurationMS = float64(c.a.now().Sub(c.rec.Time).Microseconds()) / 1000
+	rec := c.rec
+	c.mu.Unlock()
+
+	c.a.write(rec)
+}
+
+// messageSize returns the encoded size of a *rawFrame or proto.Message.
+func messageSize(m any) int {
+	switch m := m.(type) {
+	case *rawFrame:
+		return len(*m)
+	case proto.Message:
+		return proto.Size(m)
+	}
+	return 0
+}
+
+// auditStream counts a stream's messages and finishes its record when the
+// stream ends.
+type auditStream struct {
+	grpc.ClientStream
+	call          *auditCall
+	serverStreams bool
+	stop          func() bool // Stops watching the context
+}
+
+// SendMsg counts m once it is sent. A failed send ends nothing: the
+// stream's status comes from RecvMsg.
+func (s *auditStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.call.sent(m)
+	}
+	return err
+}
+
+// RecvMsg counts a received message, or finishes the record when the
+// stream has ended.
+func (s *auditStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.call.received(m)
+		if !s.serverStreams {
+			// The single response arrives with the call's status
+			s.end(nil)
+		}
+	case err == io.EOF:
+		s.end(nil)
+	default:
+		s.end(err)
+	}
+	return err
+}
+
+func (s *auditStream) end(err error) {
+	s.stop()
+	s.call.finish(err)
+}
@@ -0,0 +1,307 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/shhac/grotto/testdata/grpcweb"
+)
+
+// auditBuffer collects audit lines from concurrent calls.
+type auditBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *auditBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records parses the lines written so far.
+func (b *auditBuffer) records(t *testing.T) []AuditRecord {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var recs []AuditRecord
+	for line := range strings.Lines(b.buf.String()) {
+		var rec AuditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec), "line %q", line)
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// waitForRecord waits for the single record of a call that ends in the
+// background.
+func (b *auditBuffer) waitForRecord(t *testing.T) AuditRecord {
+	t.Helper()
+	require.Eventually(t, func() bool { return len(b.records(t)) > 0 }, 5*time.Second, 5*time.Millisecond)
+	recs := b.records(t)
+	require.Len(t, recs, 1)
+	return recs[0]
+}
+
+// newAuditedServer starts a TestService whose client connection audits into
+// the returned buffer.
+func newAuditedServer(t *testing.T, payloads bool, opts ...grpctest.Option) (*Invoker, *auditBuffer) {
+	t.Helper()
+	buf := &auditBuffer{}
+	a := newAuditor(AuditSettings{Writer: buf, Payloads: payloads}, "test-server", testLogger)
+	opts = append([]grpctest.Option{grpctest.WithTestService(), grpctest.WithDialOptions(a.dialOptions()...)}, opts...)
+	srv := grpctest.StartServer(t, opts...)
+	return NewInvoker(srv.Conn, testLogger), buf
+}
+
+func itemRequestSize(id string) int {
+	return proto.Size(&pb.ItemRequest{Item: &pb.Item{Id: id}})
+}
+
+func itemResponseSize(id string) int {
+	return proto.Size(&pb.ItemResponse{Item: &pb.Item{Id: id}, Ok: true})
+}
+
+func TestAuditRecord_Line(t *testing.T) {
+	rec := AuditRecord{
+		Time:             time.Date(2024, 6, 15, 8, 0, 0, 500_000_000, time.UTC),
+		Server:           "localhost:50051",
+		Method:           "/pkg.Service/Get",
+		Kind:             "unary",
+		Code:             "NotFound",
+		Error:            "no such thing",
+		DurationMS:       12.5,
+		RequestMessages:  1,
+		RequestBytes:     7,
+		ResponseMessages: 0,
+	}
+	line, err := rec.line()
+	require.NoError(t, err)
+	assert.Equal(t, `{"ts":"2024-06-15T08:00:00.5Z","server":"localhost:50051","method":"/pkg.Service/Get","kind":"unary",`+
+		`"code":"NotFound","error":"no such thing","duration_ms":12.5,`+
+		`"request_messages":1,"request_bytes":7,"response_messages":0,"response_bytes":0}`+"\n", string(line))
+
+	// Payloads appear only when captured
+	rec = AuditRecord{Code: "OK", Requests: []string{"CgA="}, PayloadsTruncated: true}
+	line, err = rec.line()
+	require.NoError(t, err)
+	assert.Contains(t, string(line), `"requests":["CgA="],"payloads_truncated":true}`)
+	assert.NotContains(t, string(line), `"error"`)
+	assert.NotContains(t, string(line), `"responses"`)
+}
+
+func TestAudit_Unary(t *testing.T) {
+	inv, buf := newAuditedServer(t, false)
+	_, _, _, err := inv.InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{"item":{"id":"a"}}`, nil)
+	require.NoError(t, err)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, "test-server", rec.Server)
+	assert.Equal(t, "/grpctest.TestService/UnaryEcho", rec.Method)
+	assert.Equal(t, "unary", rec.Kind)
+	assert.Equal(t, "OK", rec.Code)
+	assert.Empty(t, rec.Error)
+	assert.Equal(t, 1, rec.RequestMessages)
+	assert.Equal(t, itemRequestSize("a"), rec.RequestBytes)
+	assert.Equal(t, 1, rec.ResponseMessages)
+	assert.Equal(t, itemResponseSize("a"), rec.ResponseBytes)
+	assert.GreaterOrEqual(t, rec.DurationMS, 0.0)
+	assert.WithinDuration(t, time.Now(), rec.Time, time.Minute)
+	assert.Nil(t, rec.Requests, "payloads are off by default")
+	assert.Nil(t, rec.Responses)
+}
+
+func TestAudit_UnaryError(t *testing.T) {
+	inv, buf := newAuditedServer(t, false, grpctest.WithStatus("grpctest.TestService/UnaryEcho", codes.PermissionDenied))
+	_, _, _, err := inv.InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	require.Error(t, err)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, "PermissionDenied", rec.Code)
+	assert.NotEmpty(t, rec.Error)
+	assert.Equal(t, 0, rec.ResponseMessages)
+}
+
+func TestAudit_Payloads(t *testing.T) {
+	inv, buf := newAuditedServer(t, true)
+	_, _, _, err := inv.InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{"item":{"id":"p"}}`, nil)
+	require.NoError(t, err)
+
+	rec := buf.waitForRecord(t)
+	require.Len(t, rec.Requests, 1)
+	require.Len(t, rec.Responses, 1)
+	data, err := base64.StdEncoding.DecodeString(rec.Requests[0])
+	require.NoError(t, err)
+	var req pb.ItemRequest
+	require.NoError(t, proto.Unmarshal(data, &req))
+	assert.Equal(t, "p", req.GetItem().GetId())
+	assert.False(t, rec.PayloadsTruncated)
+}
+
+func TestAudit_PayloadsTruncated(t *testing.T) {
+	inv, buf := newAuditedServer(t, true)
+	handle, err := inv.InvokeClientStream(context.Background(), testMethod(t, "CollectItems"), nil)
+	require.NoError(t, err)
+	for range maxAuditPayloads + 2 {
+		require.NoError(t, handle.Send(`{"item":{"id":"x"}}`))
+	}
+	_, err = handle.CloseAndReceive()
+	require.NoError(t, err)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, maxAuditPayloads+2, rec.RequestMessages, "counts go on past the cap")
+	assert.Equal(t, (maxAuditPayloads+2)*itemRequestSize("x"), rec.RequestBytes)
+	assert.Len(t, rec.Requests, maxAuditPayloads)
+	assert.True(t, rec.PayloadsTruncated)
+}
+
+func TestAudit_ServerStream(t *testing.T) {
+	inv, buf := newAuditedServer(t, false)
+	msgs, errs, _, _ := inv.InvokeServerStream(context.Background(), testMethod(t, "StreamItems"), `{"item":{"id":"s"}}`, nil)
+	for range msgs {
+	}
+	require.Equal(t, io.EOF, <-errs)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, "server_stream", rec.Kind)
+	assert.Equal(t, "OK", rec.Code)
+	assert.Equal(t, 1, rec.RequestMessages)
+	assert.Equal(t, 3, rec.ResponseMessages)
+	assert.Equal(t, 3*itemResponseSize("s"), rec.ResponseBytes)
+}
+
+func TestAudit_ClientStream(t *testing.T) {
+	inv, buf := newAuditedServer(t, false)
+	handle, err := inv.InvokeClientStream(context.Background(), testMethod(t, "CollectItems"), nil)
+	require.NoError(t, err)
+	require.NoError(t, handle.Send(`{"item":{"id":"1"}}`))
+	require.NoError(t, handle.Send(`{"item":{"id":"2"}}`))
+	_, err = handle.CloseAndReceive()
+	require.NoError(t, err)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, "client_stream", rec.Kind)
+	assert.Equal(t, "OK", rec.Code)
+	assert.Equal(t, 2, rec.RequestMessages)
+	assert.Equal(t, 2*itemRequestSize("1"), rec.RequestBytes)
+	assert.Equal(t, 1, rec.ResponseMessages)
+}
+
+func TestAudit_Bidi(t *testing.T) {
+	inv, buf := newAuditedServer(t, false)
+	handle, err := inv.InvokeBidiStream(context.Background(), testMethod(t, "BidiEcho"), nil)
+	require.NoError(t, err)
+	for _, id := range []string{"b1", "b2"} {
+		require.NoError(t, handle.Send(`{"item":{"id":"`+id+`"}}`))
+		_, err := handle.Recv()
+		require.NoError(t, err)
+	}
+	assert.Empty(t, buf.records(t), "nothing is written while the stream is open")
+
+	require.NoError(t, handle.CloseSend())
+	_, err = handle.Recv()
+	require.Equal(t, io.EOF, err)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, "bidi_stream", rec.Kind)
+	assert.Equal(t, "OK", rec.Code)
+	assert.Equal(t, 2, rec.RequestMessages)
+	assert.Equal(t, 2, rec.ResponseMessages)
+}
+
+func TestAudit_StreamCancelled(t *testing.T) {
+	inv, buf := newAuditedServer(t, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	handle, err := inv.InvokeBidiStream(ctx, testMethod(t, "BidiEcho"), nil)
+	require.NoError(t, err)
+	require.NoError(t, handle.Send(`{"item":{"id":"c"}}`))
+	_, err = handle.Recv()
+	require.NoError(t, err)
+
+	// Nobody receives again: the record comes from the cancellation alone
+	cancel()
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, "Canceled", rec.Code)
+	assert.Equal(t, 1, rec.RequestMessages)
+	assert.Equal(t, 1, rec.ResponseMessages)
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAudit_WriteFailureDoesNotFailCall(t *testing.T) {
+	a := newAuditor(AuditSettings{Writer: failingWriter{}}, "test-server", testLogger)
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithDialOptions(a.dialOptions()...))
+	_, _, _, err := NewInvoker(srv.Conn, testLogger).InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	assert.NoError(t, err)
+}
+
+func TestAudit_WebConn(t *testing.T) {
+	srv := httptest.NewServer(grpcweb.NewHandler(testConn))
+	t.Cleanup(srv.Close)
+	conn, err := NewWebConn(srv.URL, false, nil, testLogger)
+	require.NoError(t, err)
+	buf := &auditBuffer{}
+	a := newAuditor(AuditSettings{Writer: buf}, srv.URL, testLogger)
+	conn.setInterceptors(a.unaryInterceptor, a.streamInterceptor)
+	inv := NewInvoker(conn, testLogger)
+
+	msgs, errs, _, _ := inv.InvokeServerStream(context.Background(), testMethod(t, "StreamItems"), `{"item":{"id":"w"}}`, nil)
+	for range msgs {
+	}
+	require.Equal(t, io.EOF, <-errs)
+
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, srv.URL, rec.Server)
+	assert.Equal(t, "server_stream", rec.Kind)
+	assert.Equal(t, "OK", rec.Code)
+	assert.Equal(t, 3, rec.ResponseMessages)
+}
+
+func TestConnectionManager_Audit(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+	buf := &auditBuffer{}
+	m := NewConnectionManager(testLogger)
+	t.Cleanup(func() { _ = m.Disconnect() })
+
+	// Off by default
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: srv.Addr}))
+	_, _, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	require.NoError(t, err)
+	assert.Empty(t, buf.records(t))
+
+	// On from the next connection
+	m.SetAudit(AuditSettings{Writer: buf})
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: srv.Addr}))
+	_, _, _, err = NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	require.NoError(t, err)
+	rec := buf.waitForRecord(t)
+	assert.Equal(t, srv.Addr, rec.Server)
+	assert.Equal(t, "/grpctest.TestService/UnaryEcho", rec.Method)
+
+	// And off again
+	m.SetAudit(AuditSettings{})
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: srv.Addr}))
+	_, _, _, err = NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	require.NoError(t, err)
+	assert.Len(t, buf.records(t), 1)
+}
@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc"
+)
+
+// ValidateAuthority accepts an empty authority (use the address) or a host
+// with an optional port, as sent in :authority. URLs are rejected, since
+// gRPC would send the scheme and path along verbatim.
+func ValidateAuthority(authority string) error {
+	switch {
+	case authority == "":
+		return nil
+	case strings.Contains(authority, "://"):
+		return fmt.Errorf("authority %q has a scheme; give the host and port only", authority)
+	case strings.ContainsAny(authority, "/ \t"):
+		return fmt.Errorf("authority %q must be a host and optional port", authority)
+	}
+	return nil
+}
+
+// authorityOptions returns the dial options overriding the :authority and
+// user-agent of cfg's calls, or nil when it keeps both. gRPC appends its
+// own version to the user-agent, and checks the server's TLS certificate
+// against the authority rather than the address.
+func authorityOptions(cfg domain.Connection) []grpc.DialOption {
+	var opts []grpc.DialOption
+	if cfg.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(cfg.Authority))
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, grpc.WithUserAgent(cfg.UserAgent))
+	}
+	return opts
+}
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValidateAuthority(t *testing.T) {
+	for _, ok := range []string{"", "tenant-a.example.com", "tenant-a.example.com:443", "10.0.0.1:8080", "[::1]:50051"} {
+		assert.NoError(t, ValidateAuthority(ok), ok)
+	}
+	for _, bad := range []string{"https://tenant-a.example.com", "dns:///tenant-a", "tenant-a.example.com/api", "tenant a"} {
+		assert.Error(t, ValidateAuthority(bad), bad)
+	}
+}
+
+func TestAuthorityOptions(t *testing.T) {
+	assert.Nil(t, authorityOptions(domain.Connection{}))
+	assert.Len(t, authorityOptions(domain.Connection{Authority: "tenant-a.example.com"}), 1)
+	assert.Len(t, authorityOptions(domain.Connection{Authority: "tenant-a.example.com", UserAgent: "grotto-infra"}), 2)
+}
+
+// echoCallHeaders answers every call with the :authority and user-agent
+// the server saw, as response headers.
+var echoCallHeaders = grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	_ = grpc.SetHeader(ctx, metadata.MD{
+		"seen-authority":  md.Get(":authority"),
+		"seen-user-agent": md.Get("user-agent"),
+	})
+	return handler(ctx, req)
+})
+
+func TestConnect_AuthorityAndUserAgent(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithServerOptions(echoCallHeaders))
+	md := testMethod(t, "UnaryEcho")
+
+	call := func(cfg domain.Connection) metadata.MD {
+		m := NewConnectionManager(testLogger)
+		require.NoError(t, m.Connect(context.Background(), cfg))
+		defer func() { _ = m.Disconnect() }()
+		_, headers, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), md, `{}`, nil)
+		require.NoError(t, err)
+		return headers
+	}
+
+	headers := call(domain.Connection{Address: srv.Addr})
+	assert.Equal(t, []string{srv.Addr}, headers.Get("seen-authority"), "the address by default")
+
+	// Dialing the IP while the gateway sees the tenant's name
+	headers = call(domain.Connection{Address: srv.Addr, Authority: "tenant-a.example.com", UserAgent: "grotto-infra/1.0"})
+	assert.Equal(t, []string{"tenant-a.example.com"}, headers.Get("seen-authority"))
+	require.Len(t, headers.Get("seen-user-agent"), 1)
+	assert.True(t, strings.HasPrefix(headers.Get("seen-user-agent")[0], "grotto-infra/1.0 "), headers.Get("seen-user-agent")[0])
+}
+
+func TestConnect_RejectsAuthorityWithScheme(t *testing.T) {
+	m := NewConnectionManager(testLogger)
+	err := m.Connect(context.Background(), domain.Connection{Address: "localhost:50051", Authority: "https://tenant-a.example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scheme")
+	assert.Equal(t, StateError, m.State())
+}
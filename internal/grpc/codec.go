@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// rawFrame is an encoded protobuf message, moved over the wire as is.
+type rawFrame []byte
+
+// rawCodec is a passthrough codec. The invoker encodes and decodes messages
+// itself, with dynamicpb against the method's own descriptors, so calls work
+// for any descriptor the reflection client resolved (including leniently
+// built ones) and never decode into an unrelated generated type that
+// happens to share the name. Named "proto" to keep the standard content type.
+type rawCodec struct{}
+
+// rawCodecOption makes a call use rawCodec.
+var rawCodecOption = grpc.ForceCodec(rawCodec{})
+
+// Marshal returns the frame's bytes.
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	f, ok := v.(*rawFrame)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: cannot marshal %T", v)
+	}
+	return *f, nil
+}
+
+// Unmarshal copies data into the frame.
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	f, ok := v.(*rawFrame)
+	if !ok {
+		return fmt.Errorf("raw codec: cannot unmarshal into %T", v)
+	}
+	*f = append((*f)[:0], data...)
+	return nil
+}
+
+// Name returns the codec's content subtype.
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// encodeJSON parses a JSON message of type desc and encodes it to a frame.
+// Any values are packed with the types known to types, which may be nil.
+func encodeJSON(desc protoreflect.MessageDescriptor, jsonMsg string, types *protoconv.TypeResolver) (rawFrame, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := types.Unmarshal(protojson.UnmarshalOptions{}, []byte(jsonMsg), msg); err != nil {
+		return nil, fmt.Errorf("invalid request JSON: %w", err)
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return data, nil
+}
+
+// CheckJSON returns the error sending jsonMsg as a desc message would fail
+// with, or nil if it encodes.
+func CheckJSON(desc protoreflect.MessageDescriptor, jsonMsg string, types *protoconv.TypeResolver) error {
+	_, err := encodeJSON(desc, jsonMsg, types)
+	return err
+}
+
+// decodeJSON decodes a frame as a message of type desc and formats it as
+// JSON, expanding the Any values and extensions whose types are known to
+// types.
+func decodeJSON(desc protoreflect.MessageDescriptor, frame rawFrame, types *protoconv.TypeResolver) (string, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(frame, msg); err != nil {
+		return "", fmt.Errorf("decode %s: %w", desc.FullName(), err)
+	}
+	data, err := types.Marshal(protojson.MarshalOptions{}, msg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// methodPath returns the HTTP/2 path of a method, "/pkg.Service/Method".
+func methodPath(desc protoreflect.MethodDescriptor) string {
+	return "/" + string(desc.Parent().FullName()) + "/" + string(desc.Name())
+}
+
+// streamDesc describes a method's streaming shape for NewStream.
+func streamDesc(desc protoreflect.MethodDescriptor) *grpc.StreamDesc {
+	return &grpc.StreamDesc{
+		StreamName:    string(desc.Name()),
+		ServerStreams: desc.IsStreamingServer(),
+		ClientStreams: desc.IsStreamingClient(),
+	}
+}
+
+// marshalMessage encodes a *rawFrame or proto.Message, for transports such
+// as WebConn that do not go through grpc-go's codecs.
+func marshalMessage(m any) ([]byte, error) {
+	switch m := m.(type) {
+	case *rawFrame:
+		return *m, nil
+	case proto.Message:
+		return proto.Marshal(m)
+	}
+	return nil, fmt.Errorf("cannot marshal %T", m)
+}
+
+// unmarshalMessage is the decoding counterpart of marshalMessage.
+func unmarshalMessage(data []byte, m any) error {
+	switch m := m.(type) {
+	case *rawFrame:
+		*m = append((*m)[:0], data...)
+		return nil
+	case proto.Message:
+		return proto.Unmarshal(data, m)
+	}
+	return fmt.Errorf("cannot unmarshal into %T", m)
+}
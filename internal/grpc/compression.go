@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+// EncodingHeader is the response header naming the compression the server
+// used. grpc-go strips it from received metadata; the invoker puts it back
+// (when the connection has an encodingStats handler) so it can be shown.
+const EncodingHeader = "grpc-encoding"
+
+// SetCompressor sets the compressor used for requests on every invocation
+// path, e.g. "gzip" ("" sends uncompressed). Set it before invoking.
+func (i *Invoker) SetCompressor(name string) {
+	i.compressor = name
+}
+
+// encodingRecorder receives the response compression of one call.
+type encodingRecorder struct {
+	mu       sync.Mutex
+	encoding string
+}
+
+type encodingRecorderKey struct{}
+
+// withEncodingRecorder returns a context whose calls report their response
+// compression to the returned recorder.
+func withEncodingRecorder(ctx context.Context) (context.Context, *encodingRecorder) {
+	rec := &encodingRecorder{}
+	return context.WithValue(ctx, encodingRecorderKey{}, rec), rec
+}
+
+// addTo returns md with the recorded encoding as EncodingHeader, leaving md
+// untouched when the response was not compressed.
+func (r *encodingRecorder) addTo(md metadata.MD) metadata.MD {
+	r.mu.Lock()
+	enc := r.encoding
+	r.mu.Unlock()
+	if enc == "" || enc == "identity" {
+		return md
+	}
+	md = md.Copy()
+	if md == nil {
+		md = metadata.MD{}
+	}
+	md.Set(EncodingHeader, enc)
+	return md
+}
+
+// encodingStats is a stats handler that reports each call's response
+// compression to the encodingRecorder in its context.
+type encodingStats struct{}
+
+func (encodingStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (encodingStats) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	h, ok := s.(*stats.InHeader)
+	if !ok || !h.Client {
+		return
+	}
+	if rec, ok := ctx.Value(encodingRecorderKey{}).(*encodingRecorder); ok {
+		rec.mu.Lock()
+		rec.encoding = h.Compression
+		rec.mu.Unlock()
+	}
+}
+
+func (encodingStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (encodingStats) HandleConn(context.Context, stats.ConnStats) {}
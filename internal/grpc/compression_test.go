@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newCompressionServer starts a TestService that rejects uncompressed
+// requests, with a client that records response compression.
+func newCompressionServer(t *testing.T) *grpctest.Server {
+	return grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithRequiredCompression("gzip"),
+		grpctest.WithDialOptions(grpc.WithStatsHandler(encodingStats{})),
+	)
+}
+
+func TestInvoker_Compression(t *testing.T) {
+	srv := newCompressionServer(t)
+	inv := NewInvoker(srv.Conn, testLogger)
+	ctx := context.Background()
+	req := `{"item":{"id":"z"}}`
+
+	_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "uncompressed request rejected")
+
+	inv.SetCompressor("gzip")
+
+	t.Run("unary", func(t *testing.T) {
+		_, headers, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gzip"}, headers.Get(EncodingHeader), "server answers in kind")
+	})
+
+	t.Run("server stream", func(t *testing.T) {
+		msgs, errs, _, _ := inv.InvokeServerStream(ctx, testMethod(t, "StreamItems"), req, nil)
+		for range msgs {
+		}
+		assert.Equal(t, io.EOF, <-errs)
+	})
+
+	t.Run("client stream", func(t *testing.T) {
+		handle, err := inv.InvokeClientStream(ctx, testMethod(t, "CollectItems"), nil)
+		require.NoError(t, err)
+		require.NoError(t, handle.Send(req))
+		_, err = handle.CloseAndReceive()
+		require.NoError(t, err)
+		headers, err := handle.Header()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gzip"}, headers.Get(EncodingHeader))
+	})
+
+	t.Run("bidi", func(t *testing.T) {
+		handle, err := inv.InvokeBidiStream(ctx, testMethod(t, "BidiEcho"), nil)
+		require.NoError(t, err)
+		require.NoError(t, handle.Send(req))
+		_, err = handle.Recv()
+		require.NoError(t, err)
+		require.NoError(t, handle.CloseSend())
+	})
+}
+
+func TestInvoker_NoCompression(t *testing.T) {
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithDialOptions(grpc.WithStatsHandler(encodingStats{})),
+	)
+	inv := NewInvoker(srv.Conn, testLogger)
+	inv.SetCompressor("")
+
+	_, headers, _, err := inv.InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	require.NoError(t, err)
+	assert.Empty(t, headers.Get(EncodingHeader))
+}
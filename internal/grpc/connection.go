@@ -3,18 +3,18 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/shhac/grotto/internal/domain"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/keepalive"
 )
 
 // ConnectionState represents the current state of the gRPC connection
@@ -45,42 +45,82 @@ func (s ConnectionState) String() string {
 
 // ConnectionManager manages the lifecycle of a gRPC client connection
 type ConnectionManager struct {
-	conn    *grpc.ClientConn
-	state   ConnectionState
-	address string
-	logger  *slog.Logger
-	mu      sync.RWMutex
+	conn      *grpc.ClientConn
+	webConn   *WebConn // set instead of conn when using a gRPC-Web transport
+	transport domain.Transport
+	state     ConnectionState
+	address   string
+	certs     *certRecorder // TLS handshakes of the connection; nil for plaintext
+	logger    *slog.Logger
+	mu        sync.RWMutex
 
 	// Callbacks for state changes
 	onStateChange func(state ConnectionState, message string)
+	onLinkChange  func(LinkStatus)
+
+	// Link watching and auto-reconnect (see connwatch.go)
+	autoReconnect bool
+	watchCancel   context.CancelFunc
+	watchWake     chan struct{}
+	reconnectBase time.Duration
+	reconnectMax  time.Duration
+
+	// Audit trail for new connections (see audit.go)
+	audit AuditSettings
 }
 
 // NewConnectionManager creates a new connection manager
 func NewConnectionManager(logger *slog.Logger) *ConnectionManager {
 	return &ConnectionManager{
-		state:  StateDisconnected,
-		logger: logger,
+		state:         StateDisconnected,
+		logger:        logger,
+		reconnectBase: defaultReconnectBase,
+		reconnectMax:  defaultReconnectMax,
 	}
 }
 
-// Connect establishes a gRPC connection with the provided configuration
+// Connect establishes a gRPC connection with the provided configuration.
+// If ctx is done before the connection is in place, it returns ctx's error
+// and leaves the manager disconnected.
 func (m *ConnectionManager) Connect(ctx context.Context, cfg domain.Connection) error {
 	m.updateState(StateConnecting, "Connecting to "+cfg.Address)
 
-	// Configure keepalive parameters to avoid ENHANCE_YOUR_CALM errors
-	kaParams := keepalive.ClientParameters{
-		Time:                30 * time.Second, // Ping every 30s (reduced frequency)
-		Timeout:             20 * time.Second, // Wait 20s for ping ack
-		PermitWithoutStream: true,             // Keep alive even when idle
+	if IsUnixTarget(cfg.Address) {
+		if err := m.checkUnixTarget(cfg); err != nil {
+			m.updateState(StateError, "Failed to connect: "+err.Error())
+			return err
+		}
+	}
+
+	if err := validateProxy(cfg.Proxy); err != nil {
+		m.updateState(StateError, "Failed to configure proxy: "+err.Error())
+		return err
+	}
+
+	if err := ValidateAuthority(cfg.Authority); err != nil {
+		m.updateState(StateError, "Invalid authority: "+err.Error())
+		return err
+	}
+
+	if cfg.Transport.IsWeb() {
+		return m.connectWeb(cfg)
 	}
 
 	// Build dial options
 	opts := []grpc.DialOption{
-		grpc.WithKeepaliveParams(kaParams),
+		grpc.WithStatsHandler(encodingStats{}),
+		grpc.WithStatsHandler(timingStats{}),
+	}
+	opts = append(opts, messageSizeOptions(cfg)...)
+	opts = append(opts, keepaliveOptions(cfg)...)
+	opts = append(opts, authorityOptions(cfg)...)
+	if a := m.auditor(cfg.Address); a != nil {
+		opts = append(opts, a.dialOptions()...)
 	}
 
 	// Configure TLS/credentials
 	var creds credentials.TransportCredentials
+	var certs *certRecorder
 	if cfg.TLS.Enabled {
 		// Build TLS configuration
 		tlsConfig, err := m.buildTLSConfig(cfg.TLS)
@@ -93,6 +133,7 @@ func (m *ConnectionManager) Connect(ctx context.Context, cfg domain.Connection)
 			return err
 		}
 
+		certs = recordTo(tlsConfig)
 		creds = credentials.NewTLS(tlsConfig)
 		opts = append(opts, grpc.WithTransportCredentials(creds))
 
@@ -112,8 +153,18 @@ func (m *ConnectionManager) Connect(ctx context.Context, cfg domain.Connection)
 		defer cancel()
 	}
 
+	target := cfg.Address
+	if cfg.Proxy.Enabled() {
+		dial, err := m.buildProxyDialer(cfg)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.WithContextDialer(dial))
+		target = proxiedTarget(cfg.Address)
+	}
+
 	// Create the connection (not deprecated NewClient, not Dial)
-	conn, err := grpc.NewClient(cfg.Address, opts...)
+	conn, err := grpc.NewClient(target, opts...)
 	if err != nil {
 		m.logger.Error("failed to create gRPC client",
 			slog.String("address", cfg.Address),
@@ -122,22 +173,28 @@ func (m *ConnectionManager) Connect(ctx context.Context, cfg domain.Connection)
 		m.updateState(StateError, "Failed to connect: "+err.Error())
 		return err
 	}
+	if err := ctx.Err(); err != nil {
+		// Cancelled while being set up
+		_ = conn.Close()
+		m.updateState(StateDisconnected, "Connection cancelled")
+		return err
+	}
 
 	// Update state with new connection
 	m.mu.Lock()
-	// Close old connection if it exists
-	if m.conn != nil {
-		oldConn := m.conn
-		go func() {
-			if err := oldConn.Close(); err != nil {
-				m.logger.Warn("failed to close old connection", slog.Any("error", err))
-			}
-		}()
-	}
+	m.closeOldLocked()
 	m.conn = conn
+	m.transport = cfg.Transport
 	m.address = cfg.Address
+	m.certs = certs
+	m.autoReconnect = cfg.KeepAlive
+	m.startWatchLocked(conn)
 	m.mu.Unlock()
 
+	// Leave IDLE right away so the link state reflects the server rather
+	// than waiting for the first RPC.
+	conn.Connect()
+
 	m.logger.Info("gRPC connection established",
 		slog.String("address", cfg.Address),
 		slog.Bool("tls", cfg.TLS.Enabled),
@@ -147,9 +204,165 @@ func (m *ConnectionManager) Connect(ctx context.Context, cfg domain.Connection)
 	return nil
 }
 
+// checkUnixTarget fails early for unix socket targets that can't work: over
+// gRPC-Web or a proxy, or with a socket file that doesn't exist. grpc.NewClient would
+// accept them and only fail on the first call.
+func (m *ConnectionManager) checkUnixTarget(cfg domain.Connection) error {
+	if cfg.Transport.IsWeb() {
+		return fmt.Errorf("unix sockets are not supported over %s", cfg.Transport)
+	}
+	if cfg.Proxy.Enabled() {
+		return errors.New("unix sockets can't be reached through a proxy")
+	}
+	if strings.HasPrefix(cfg.Address, unixScheme) {
+		if err := checkUnixSocket(cfg.Address); err != nil {
+			m.logger.Error("unix socket check failed",
+				slog.String("address", cfg.Address),
+				slog.Any("error", err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// buildProxyDialer returns the dialer for cfg's proxy, setting the error
+// state if it can't be built.
+func (m *ConnectionManager) buildProxyDialer(cfg domain.Connection) (dialFunc, error) {
+	dial, err := proxyDialer(cfg.Proxy)
+	if err != nil {
+		m.logger.Error("failed to configure proxy",
+			slog.String("address", cfg.Address),
+			slog.String("proxy", cfg.Proxy.Address()),
+			slog.Any("error", err),
+		)
+		m.updateState(StateError, "Failed to configure proxy: "+err.Error())
+		return nil, err
+	}
+	m.logger.Info("dialing through proxy",
+		slog.String("address", cfg.Address),
+		slog.String("proxy_type", string(cfg.Proxy.Type)),
+		slog.String("proxy", cfg.Proxy.Address()),
+	)
+	return dial, nil
+}
+
+// connectWeb sets up a gRPC-Web connection. No network round trip happens
+// here: like grpc.NewClient, failures surface on the first call.
+func (m *ConnectionManager) connectWeb(cfg domain.Connection) error {
+	var tlsConfig *tls.Config
+	var certs *certRecorder
+	if cfg.TLS.Enabled {
+		var err error
+		tlsConfig, err = m.buildTLSConfig(cfg.TLS)
+		if err != nil {
+			m.logger.Error("failed to build TLS config",
+				slog.String("address", cfg.Address),
+				slog.Any("error", err),
+			)
+			m.updateState(StateError, "Failed to configure TLS: "+err.Error())
+			return err
+		}
+		certs = recordTo(tlsConfig)
+	}
+
+	webConn, err := NewWebConn(cfg.Address, cfg.Transport == domain.TransportGRPCWebText, tlsConfig, m.logger)
+	if err != nil {
+		m.logger.Error("failed to create gRPC-Web client",
+			slog.String("address", cfg.Address),
+			slog.Any("error", err),
+		)
+		m.updateState(StateError, "Failed to connect: "+err.Error())
+		return err
+	}
+
+	if cfg.Proxy.Enabled() {
+		dial, err := m.buildProxyDialer(cfg)
+		if err != nil {
+			return err
+		}
+		webConn.setDialer(dial)
+	}
+	if a := m.auditor(cfg.Address); a != nil {
+		webConn.setInterceptors(a.unaryInterceptor, a.streamInterceptor)
+	}
+
+	m.mu.Lock()
+	m.closeOldLocked()
+	m.webConn = webConn
+	m.transport = cfg.Transport
+	m.address = cfg.Address
+	m.certs = certs
+	m.mu.Unlock()
+
+	m.logger.Info("gRPC-Web connection configured",
+		slog.String("address", cfg.Address),
+		slog.String("transport", string(cfg.Transport)),
+		slog.Bool("tls", cfg.TLS.Enabled),
+	)
+	m.updateState(StateConnected, "Connected to "+cfg.Address+" via "+cfg.Transport.String())
+
+	return nil
+}
+
+// SetAudit sets the audit trail for connections made from now on; the
+// current connection keeps the settings it was made with.
+func (m *ConnectionManager) SetAudit(settings AuditSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audit = settings
+}
+
+// auditor returns the auditor for a new connection to server, or nil when
+// auditing is off.
+func (m *ConnectionManager) auditor(server string) *auditor {
+	m.mu.RLock()
+	settings := m.audit
+	m.mu.RUnlock()
+	if !settings.Enabled() {
+		return nil
+	}
+	return newAuditor(settings, server, m.logger)
+}
+
+// closeOldLocked closes any existing connection in the background.
+// The caller must hold m.mu.
+func (m *ConnectionManager) closeOldLocked() {
+	m.stopWatchLocked()
+	if m.conn != nil {
+		oldConn := m.conn
+		go func() {
+			if err := oldConn.Close(); err != nil {
+				m.logger.Warn("failed to close old connection", slog.Any("error", err))
+			}
+		}()
+		m.conn = nil
+	}
+	if m.webConn != nil {
+		_ = m.webConn.Close()
+		m.webConn = nil
+	}
+}
+
 // Disconnect closes the gRPC connection
 func (m *ConnectionManager) Disconnect() error {
 	m.mu.Lock()
+	m.certs = nil
+
+	if m.webConn != nil {
+		addr := m.address
+		_ = m.webConn.Close()
+		m.webConn = nil
+		m.transport = domain.TransportGRPC
+		m.address = ""
+		m.logger.Info("gRPC-Web connection closed", slog.String("address", addr))
+		cb := m.updateStateLocked(StateDisconnected, "Disconnected")
+		m.mu.Unlock()
+		if cb != nil {
+			cb(StateDisconnected, "Disconnected")
+		}
+		return nil
+	}
 
 	if m.conn == nil {
 		cb := m.updateStateLocked(StateDisconnected, "Already disconnected")
@@ -161,6 +374,7 @@ func (m *ConnectionManager) Disconnect() error {
 	}
 
 	addr := m.address
+	m.stopWatchLocked()
 	err := m.conn.Close()
 	if err != nil {
 		m.logger.Error("failed to close connection",
@@ -188,14 +402,61 @@ func (m *ConnectionManager) Disconnect() error {
 	return nil
 }
 
-// Conn returns the current gRPC client connection
-// Returns nil if not connected
+// Conn returns the current native gRPC client connection
+// Returns nil if not connected or when using a gRPC-Web transport
 func (m *ConnectionManager) Conn() *grpc.ClientConn {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.conn
 }
 
+// WaitReady dials the native connection and blocks until it is READY,
+// fails, or ctx expires. gRPC-Web has no handshake, so it returns at once
+// for a gRPC-Web connection; its first call is what reaches the server.
+func (m *ConnectionManager) WaitReady(ctx context.Context) error {
+	cc := m.Conn()
+	if cc == nil {
+		return nil
+	}
+	cc.Connect()
+	for {
+		state := cc.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure:
+			return errors.New("server unreachable")
+		case connectivity.Shutdown:
+			return errors.New("connection shut down")
+		}
+		if !cc.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("timed out connecting: %w", ctx.Err())
+		}
+	}
+}
+
+// Channel returns the active connection as a grpc.ClientConnInterface,
+// which is either the native connection or the gRPC-Web connection.
+// Returns nil if not connected.
+func (m *ConnectionManager) Channel() grpc.ClientConnInterface {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.webConn != nil {
+		return m.webConn
+	}
+	if m.conn != nil {
+		return m.conn
+	}
+	return nil
+}
+
+// Transport returns the transport of the current connection
+func (m *ConnectionManager) Transport() domain.Transport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.transport
+}
+
 // State returns the current connection state
 func (m *ConnectionManager) State() ConnectionState {
 	m.mu.RLock()
@@ -250,22 +511,21 @@ func (m *ConnectionManager) updateStateLocked(state ConnectionState, message str
 func (m *ConnectionManager) buildTLSConfig(settings domain.TLSSettings) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: settings.SkipVerify,
+		ServerName:         settings.ServerNameOverride,
 	}
 
 	// Load CA certificate if provided
 	if settings.CertFile != "" {
-		caCert, err := os.ReadFile(settings.CertFile)
+		caCertPool, count, err := loadCertPool(settings.CertFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
-		}
-
-		caCertPool := x509.NewCertPool()
-		if !caCertPool.AppendCertsFromPEM(caCert) {
-			return nil, fmt.Errorf("failed to append CA certificate")
+			return nil, err
 		}
 		tlsConfig.RootCAs = caCertPool
 
-		m.logger.Debug("loaded CA certificate", slog.String("file", settings.CertFile))
+		m.logger.Debug("loaded CA certificates",
+			slog.String("file", settings.CertFile),
+			slog.Int("count", count),
+		)
 	}
 
 	// Load client certificate and key for mTLS if provided
@@ -0,0 +1,205 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Reconnect backoff bounds used when auto-reconnect is enabled
+const (
+	defaultReconnectBase = 500 * time.Millisecond
+	defaultReconnectMax  = 30 * time.Second
+)
+
+// LinkStatus describes the transport-level state of a native gRPC
+// connection, as opposed to ConnectionState, which tracks whether the user
+// is connected at all. A connected session can lose its link when the
+// server restarts; LinkStatus reports that as it happens.
+type LinkStatus struct {
+	State connectivity.State
+	// Since is when the link entered State. For Ready it is the uptime start.
+	Since time.Time
+	// Reconnected is set on a Ready that follows an earlier Ready on the
+	// same connection, i.e. the link was lost and came back.
+	Reconnected bool
+	// Attempt counts auto-reconnect attempts since the link was last ready.
+	Attempt int
+}
+
+// SetLinkCallback registers a function called on every link state
+// transition of native connections. It runs on the watcher goroutine.
+func (m *ConnectionManager) SetLinkCallback(fn func(LinkStatus)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onLinkChange = fn
+}
+
+// SetAutoReconnect enables or disables redialing with exponential backoff
+// when the link drops. It takes effect on the current connection too.
+func (m *ConnectionManager) SetAutoReconnect(enabled bool) {
+	m.mu.Lock()
+	m.autoReconnect = enabled
+	wake := m.watchWake
+	m.mu.Unlock()
+
+	// Nudge the watcher so a change takes effect without waiting for the
+	// next state transition.
+	if wake != nil {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// AutoReconnect reports whether auto-reconnect is enabled
+func (m *ConnectionManager) AutoReconnect() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.autoReconnect
+}
+
+// startWatchLocked starts watching conn's state until the connection is
+// replaced or closed. The caller must hold m.mu.
+func (m *ConnectionManager) startWatchLocked(conn *grpc.ClientConn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watchCancel = cancel
+	m.watchWake = make(chan struct{}, 1)
+	go m.watch(ctx, conn, m.watchWake)
+}
+
+// stopWatchLocked stops the current watcher, if any. The caller must hold m.mu.
+func (m *ConnectionManager) stopWatchLocked() {
+	if m.watchCancel != nil {
+		m.watchCancel()
+		m.watchCancel = nil
+		m.watchWake = nil
+	}
+}
+
+// watch reports link transitions and, when auto-reconnect is on, asks an
+// idle or failing connection to reconnect after an exponential backoff.
+// gRPC does not redial a lost connection by itself until the next RPC.
+func (m *ConnectionManager) watch(ctx context.Context, conn *grpc.ClientConn, wake <-chan struct{}) {
+	var (
+		backoff  time.Duration
+		attempt  int
+		wasReady bool
+		redial   <-chan time.Time
+		timer    *time.Timer
+	)
+	stopTimer := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, redial = nil, nil
+		}
+	}
+	defer stopTimer()
+
+	changes := make(chan connectivity.State)
+	state := conn.GetState()
+	m.reportLink(LinkStatus{State: state, Since: time.Now()})
+
+	// moveTo records and reports a transition to next, if it is one
+	moveTo := func(next connectivity.State) {
+		if next == state {
+			return
+		}
+		state = next
+		m.reportLink(LinkStatus{
+			State:       state,
+			Since:       time.Now(),
+			Reconnected: state == connectivity.Ready && wasReady,
+			Attempt:     attempt,
+		})
+	}
+
+	for {
+		if state == connectivity.Shutdown {
+			return
+		}
+		if state == connectivity.Ready {
+			backoff, attempt = 0, 0
+			wasReady = true
+		}
+
+		// Schedule a redial for a link that is down and not already trying
+		down := state == connectivity.Idle || state == connectivity.TransientFailure
+		if down && m.AutoReconnect() {
+			if redial == nil {
+				backoff = m.nextBackoff(backoff)
+				timer = time.NewTimer(backoff)
+				redial = timer.C
+			}
+		} else {
+			stopTimer()
+		}
+
+		// Wait for the next transition, redial, or wake-up
+		waitCtx, waitCancel := context.WithCancel(ctx)
+		go func(from connectivity.State) {
+			if conn.WaitForStateChange(waitCtx, from) {
+				select {
+				case changes <- conn.GetState():
+				case <-waitCtx.Done():
+				}
+			}
+		}(state)
+
+		select {
+		case <-ctx.Done():
+			waitCancel()
+			return
+		case next := <-changes:
+			waitCancel()
+			moveTo(next)
+		case <-redial:
+			waitCancel()
+			timer, redial = nil, nil
+			attempt++
+			m.logger.Info("reconnecting",
+				slog.String("address", conn.Target()),
+				slog.Int("attempt", attempt),
+				slog.Duration("backoff", backoff),
+			)
+			conn.Connect()
+			moveTo(conn.GetState())
+		case <-wake:
+			waitCancel()
+			moveTo(conn.GetState())
+		}
+	}
+}
+
+// nextBackoff doubles the previous backoff, starting at the base delay and
+// capped at the maximum.
+func (m *ConnectionManager) nextBackoff(prev time.Duration) time.Duration {
+	if prev <= 0 {
+		return m.reconnectBase
+	}
+	next := prev * 2
+	if next > m.reconnectMax {
+		next = m.reconnectMax
+	}
+	return next
+}
+
+// reportLink logs a link transition and invokes the link callback.
+func (m *ConnectionManager) reportLink(status LinkStatus) {
+	m.mu.RLock()
+	callback := m.onLinkChange
+	m.mu.RUnlock()
+
+	m.logger.Debug("connection link state changed",
+		slog.String("state", status.State.String()),
+		slog.Bool("reconnected", status.Reconnected),
+	)
+
+	if callback != nil {
+		callback(status)
+	}
+}
@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/connectivity"
+)
+
+// linkRecorder collects link transitions reported by a ConnectionManager.
+type linkRecorder chan LinkStatus
+
+// waitFor returns the first reported status matching want, failing the
+// test if none arrives within timeout.
+func (r linkRecorder) waitFor(t *testing.T, timeout time.Duration, want func(LinkStatus) bool) LinkStatus {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case status := <-r:
+			if want(status) {
+				return status
+			}
+		case <-deadline:
+			t.Fatalf("no matching link status within %v", timeout)
+			return LinkStatus{}
+		}
+	}
+}
+
+// newWatchedManager returns a manager with short reconnect backoff whose
+// link transitions are recorded.
+func newWatchedManager(t *testing.T) (*ConnectionManager, linkRecorder) {
+	t.Helper()
+	m := NewConnectionManager(testLogger)
+	m.reconnectBase = 20 * time.Millisecond
+	m.reconnectMax = 100 * time.Millisecond
+	rec := make(linkRecorder, 64)
+	m.SetLinkCallback(func(s LinkStatus) { rec <- s })
+	t.Cleanup(func() { _ = m.Disconnect() })
+	return m, rec
+}
+
+func isState(state connectivity.State) func(LinkStatus) bool {
+	return func(s LinkStatus) bool { return s.State == state }
+}
+
+func TestConnectionManager_ReconnectsAfterServerRestart(t *testing.T) {
+	first := grpctest.StartServer(t, grpctest.WithTestService())
+	addr := first.Addr
+
+	m, rec := newWatchedManager(t)
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: addr, KeepAlive: true}))
+
+	ready := rec.waitFor(t, 5*time.Second, isState(connectivity.Ready))
+	assert.False(t, ready.Reconnected)
+	assert.False(t, ready.Since.IsZero())
+
+	// Server goes away: the link leaves Ready without any RPC being made.
+	first.Stop()
+	lost := rec.waitFor(t, 5*time.Second, func(s LinkStatus) bool { return s.State != connectivity.Ready })
+	assert.Contains(t, []connectivity.State{connectivity.Idle, connectivity.Connecting, connectivity.TransientFailure}, lost.State)
+
+	// Restart on the same address; auto-reconnect brings the link back.
+	grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithListenAddress(addr))
+	back := rec.waitFor(t, 5*time.Second, isState(connectivity.Ready))
+	assert.True(t, back.Reconnected)
+	assert.Equal(t, StateConnected, m.State())
+}
+
+func TestConnectionManager_NoReconnectWithoutKeepAlive(t *testing.T) {
+	first := grpctest.StartServer(t, grpctest.WithTestService())
+	addr := first.Addr
+
+	m, rec := newWatchedManager(t)
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: addr}))
+	rec.waitFor(t, 5*time.Second, isState(connectivity.Ready))
+
+	first.Stop()
+	rec.waitFor(t, 5*time.Second, isState(connectivity.Idle))
+
+	grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithListenAddress(addr))
+	select {
+	case s := <-rec:
+		t.Fatalf("unexpected link change to %s without keep-alive", s.State)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Enabling keep-alive on the live connection redials right away.
+	m.SetAutoReconnect(true)
+	back := rec.waitFor(t, 5*time.Second, isState(connectivity.Ready))
+	assert.True(t, back.Reconnected)
+}
+
+func TestConnectionManager_DisconnectStopsWatching(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+
+	m, rec := newWatchedManager(t)
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: srv.Addr, KeepAlive: true}))
+	rec.waitFor(t, 5*time.Second, isState(connectivity.Ready))
+
+	require.NoError(t, m.Disconnect())
+	select {
+	case s := <-rec:
+		t.Fatalf("unexpected link change to %s after disconnect", s.State)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConnectionManager_NextBackoff(t *testing.T) {
+	m := NewConnectionManager(testLogger)
+	var got []time.Duration
+	var d time.Duration
+	for i := 0; i < 8; i++ {
+		d = m.nextBackoff(d)
+		got = append(got, d)
+	}
+	assert.Equal(t, []time.Duration{
+		500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second,
+		8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second,
+	}, got)
+}
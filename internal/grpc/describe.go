@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FindDescriptor returns the service, method, message or enum with the
+// given full name, a leading dot allowed. Services and types already
+// loaded are looked in first. Over server reflection the server is then
+// asked for the file declaring name, and a service whose files do not
+// build as served is resolved leniently, as ListServices would.
+func (r *ReflectionClient) FindDescriptor(name string) (protoreflect.Descriptor, error) {
+	fullName := protoreflect.FullName(strings.TrimPrefix(strings.TrimSpace(name), "."))
+	if !fullName.IsValid() {
+		return nil, fmt.Errorf("%q is not a full name such as package.Message", name)
+	}
+
+	// A service or a method, by the service's own name or its parent's
+	for _, serviceName := range []protoreflect.FullName{fullName, fullName.Parent()} {
+		if sd, ok := r.loadedService(serviceName); ok {
+			if d := serviceMember(sd, fullName); d != nil {
+				return d, nil
+			}
+		}
+	}
+	if t, ok := r.knownTypes()[fullName]; ok {
+		return t.Descriptor, nil
+	}
+	if r.client == nil {
+		return nil, fmt.Errorf("%s not found in %s", fullName, r.localSource())
+	}
+
+	_, err := r.client.FileContainingSymbol(fullName)
+	if err == nil {
+		d, findErr := r.client.AsResolver().FindDescriptorByName(fullName)
+		if findErr == nil {
+			if sd, ok := d.(protoreflect.ServiceDescriptor); ok {
+				r.cacheService(string(sd.FullName()), sd)
+			}
+			return d, nil
+		}
+		err = findErr
+	}
+	for _, serviceName := range []protoreflect.FullName{fullName, fullName.Parent()} {
+		ctx, cancel := r.lookupContext()
+		sd, lenientErr := r.lenientResolve(ctx, string(serviceName))
+		cancel()
+		if lenientErr != nil {
+			continue
+		}
+		r.cacheService(string(serviceName), sd)
+		if d := serviceMember(sd, fullName); d != nil {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not found: %w", fullName, err)
+}
+
+// loadedService returns a service ListServices or GetMethodDescriptor
+// loaded, or one the local descriptor source declares.
+func (r *ReflectionClient) loadedService(name protoreflect.FullName) (protoreflect.ServiceDescriptor, bool) {
+	if sd, ok := r.cachedService(string(name)); ok {
+		return sd, true
+	}
+	if r.client != nil {
+		return nil, false
+	}
+	for _, sd := range r.listLocalServices() {
+		if sd.FullName() == name {
+			return sd, true
+		}
+	}
+	return nil, false
+}
+
+// serviceMember returns sd itself or its method with the given full name,
+// or nil if name is neither.
+func serviceMember(sd protoreflect.ServiceDescriptor, name protoreflect.FullName) protoreflect.Descriptor {
+	if sd.FullName() == name {
+		return sd
+	}
+	if md := sd.Methods().ByName(name.Name()); md != nil && md.FullName() == name {
+		return md
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestFindDescriptor(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...))
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+
+	// Before any listing: the method's service only builds leniently
+	d, err := client.FindDescriptor(".custom.event.v1.EventService.GetEvent")
+	require.NoError(t, err)
+	md, ok := d.(protoreflect.MethodDescriptor)
+	require.True(t, ok, "%T", d)
+	assert.Equal(t, protoreflect.FullName("custom.event.v1.Event"), md.Output().FullName())
+
+	d, err = client.FindDescriptor("custom.event.v1.EventService")
+	require.NoError(t, err)
+	assert.Implements(t, (*protoreflect.ServiceDescriptor)(nil), d)
+
+	// Types of the files lenient resolution built
+	d, err = client.FindDescriptor("custom.event.v1.Event")
+	require.NoError(t, err)
+	assert.Same(t, md.Output(), d, "the descriptor the method uses")
+
+	_, err = client.FindDescriptor("custom.event.v1.Missing")
+	assert.Error(t, err)
+	_, err = client.FindDescriptor("not a name")
+	assert.ErrorContains(t, err, "not a full name")
+}
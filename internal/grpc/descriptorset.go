@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// LoadDescriptorSet reads a binary FileDescriptorSet, as written by
+// protoc --descriptor_set_out (ideally with --include_imports).
+func LoadDescriptorSet(path string) ([]*descriptorpb.FileDescriptorProto, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &fds); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set %s: %w", filepath.Base(path), err)
+	}
+	if len(fds.GetFile()) == 0 {
+		return nil, fmt.Errorf("descriptor set %s contains no files", filepath.Base(path))
+	}
+	return fds.GetFile(), nil
+}
+
+// NewReflectionClientFromDescriptorSet creates a ReflectionClient that
+// serves ListServices and GetMethodDescriptor from a FileDescriptorSet file
+// instead of the server reflection stream. The descriptors get the same
+// fix-ups as lenient reflection (map entry names, missing imports, reserved
+// ranges). conn is kept for symmetry with NewReflectionClient; no
+// reflection calls are made on it.
+func NewReflectionClientFromDescriptorSet(conn grpc.ClientConnInterface, path string, logger *slog.Logger) (*ReflectionClient, error) {
+	fdProtos, err := LoadDescriptorSet(path)
+	if err != nil {
+		return nil, err
+	}
+
+	setFiles, built, err := localFileDescriptors(fdProtos, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build descriptors from %s: %w", filepath.Base(path), err)
+	}
+
+	logger.Info("loaded descriptor set",
+		slog.String("path", path),
+		slog.Int("files", len(fdProtos)),
+		slog.Int("built", built),
+	)
+
+	return &ReflectionClient{
+		conn:          conn,
+		logger:        logger,
+		serviceCache:  make(map[string]protoreflect.ServiceDescriptor),
+		descriptorSet: path,
+		localFiles:    setFiles,
+	}, nil
+}
+
+// DescriptorSetPath returns the FileDescriptorSet this client was loaded
+// from, or "" when it uses server reflection.
+func (r *ReflectionClient) DescriptorSetPath() string {
+	return r.descriptorSet
+}
+
+// localSource names where a client without a reflection stream got its
+// descriptors, for logs and errors.
+func (r *ReflectionClient) localSource() string {
+	switch {
+	case len(r.protoRoots) > 0:
+		return "proto sources"
+	case r.descriptorSet == "":
+		return "cached descriptors"
+	}
+	return "descriptor set"
+}
+
+// listLocalServices lists the services declared in the descriptor set or
+// compiled proto files.
+func (r *ReflectionClient) listLocalServices() []protoreflect.ServiceDescriptor {
+	var services []protoreflect.ServiceDescriptor
+	seen := make(map[protoreflect.FullName]bool)
+	for _, fd := range r.localFiles {
+		for i := range fd.Services().Len() {
+			sd := fd.Services().Get(i)
+			if seen[sd.FullName()] || isReflectionService(string(sd.FullName())) {
+				continue
+			}
+			seen[sd.FullName()] = true
+			services = append(services, sd)
+		}
+	}
+	return services
+}
+
+// localFileDescriptors builds fdProtos with the lenient fix-ups and returns
+// the descriptor of each file that could be built, in order, along with how
+// many were built locally. A file that fails but declares services is kept
+// with just those, so their resolvable methods can still be called.
+func localFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *slog.Logger) ([]protoreflect.FileDescriptor, int, error) {
+	// A set made entirely of files already in the global registry builds
+	// nothing locally; that's fine as long as the lookups below succeed.
+	trace := newTraceCollector(logger)
+	files, buildErr := buildFileDescriptors(fdProtos, trace)
+	if buildErr != nil {
+		files = new(protoregistry.Files)
+	}
+
+	// Files that were already in the global registry were skipped by
+	// buildFileDescriptors, so those are looked up there instead.
+	var fds []protoreflect.FileDescriptor
+	for _, fdp := range fdProtos {
+		fd, err := files.FindFileByPath(fdp.GetName())
+		if err != nil {
+			fd, err = protoregistry.GlobalFiles.FindFileByPath(fdp.GetName())
+		}
+		if err != nil && len(fdp.GetService()) > 0 {
+			// Keep the methods that don't depend on what failed
+			fd, err = buildServicesOnly(fdp, files, trace)
+		}
+		if err != nil {
+			logger.Warn("descriptor file could not be built",
+				slog.String("file", fdp.GetName()),
+			)
+			continue
+		}
+		fds = append(fds, fd)
+	}
+	if len(fds) == 0 {
+		if buildErr == nil {
+			buildErr = fmt.Errorf("no files could be built")
+		}
+		return nil, 0, buildErr
+	}
+	return fds, files.NumFiles(), nil
+}
@@ -0,0 +1,189 @@
+package grpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// writeDescriptorSet marshals files into a FileDescriptorSet on disk.
+func writeDescriptorSet(t *testing.T, files ...*descriptorpb.FileDescriptorProto) string {
+	t.Helper()
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: files})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "descriptors.pb")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+func TestDescriptorSet_ListServicesWithFixups(t *testing.T) {
+	// Missing the timestamp import: the same fix-up as lenient reflection
+	// must inject it so the file builds.
+	path := writeDescriptorSet(t, makeServiceFDP(nil))
+
+	rc, err := NewReflectionClientFromDescriptorSet(nil, path, discardLogger)
+	require.NoError(t, err)
+	defer rc.Close()
+	assert.Equal(t, path, rc.DescriptorSetPath())
+
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "test.noncanonical.v1.NonCanonicalService", services[0].FullName)
+	require.Len(t, services[0].Methods, 1)
+	assert.Equal(t, "GetItem", services[0].Methods[0].Name)
+	assert.Equal(t, "test.noncanonical.v1.Item", services[0].Methods[0].OutputType)
+
+	md, err := rc.GetMethodDescriptor("test.noncanonical.v1.NonCanonicalService", "GetItem")
+	require.NoError(t, err)
+	assert.Equal(t, "google.protobuf.Timestamp", string(md.Output().Fields().ByName("created_at").Message().FullName()))
+
+	_, err = rc.GetMethodDescriptor("test.noncanonical.v1.Missing", "GetItem")
+	assert.ErrorContains(t, err, "not found in descriptor set")
+}
+
+func TestDescriptorSet_InvokeWithoutReflection(t *testing.T) {
+	// grpc_test.proto is already in the global registry (the pb package is
+	// linked into this test binary), exercising the global fallback.
+	path := writeDescriptorSet(t,
+		protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		protodesc.ToFileDescriptorProto(pb.File_grpc_test_proto),
+	)
+
+	rc, err := NewReflectionClientFromDescriptorSet(testConn, path, discardLogger)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "grpctest.TestService", services[0].FullName)
+	assert.Len(t, services[0].Methods, 4)
+
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+
+	resp, _, _, err := NewInvoker(testConn, testLogger).InvokeUnary(
+		context.Background(), md, `{"item":{"id":"from-file"}}`, nil)
+	require.NoError(t, err)
+	assert.Contains(t, resp, "from-file")
+}
+
+func TestLoadDescriptorSet_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadDescriptorSet(filepath.Join(dir, "missing.pb"))
+	assert.ErrorContains(t, err, "failed to read descriptor set")
+
+	garbage := filepath.Join(dir, "garbage.pb")
+	require.NoError(t, os.WriteFile(garbage, []byte{0xff, 0xff, 0xff}, 0o644))
+	_, err = LoadDescriptorSet(garbage)
+	assert.ErrorContains(t, err, "failed to parse descriptor set")
+
+	_, err = LoadDescriptorSet(writeDescriptorSet(t))
+	assert.ErrorContains(t, err, "contains no files")
+}
+
+// makePartialFDPs returns a file of message types and a service file using
+// them. The service's Missing method takes a type declared nowhere, and
+// Stuck one from the service file itself, which also holds a message
+// protodesc rejects when broken is set.
+func makePartialFDPs(broken bool) []*descriptorpb.FileDescriptorProto {
+	strType := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	idField := func(name string) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: strPtr(name), Number: int32Ptr(1), Type: &strType, Label: &label}
+	}
+
+	local := &descriptorpb.DescriptorProto{Name: strPtr("LocalRequest"), Field: []*descriptorpb.FieldDescriptorProto{idField("id")}}
+	if broken {
+		// Two fields with the same number fail validation even leniently
+		local.Field = append(local.Field, idField("other_id"))
+	}
+
+	types := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("partial_types.proto"),
+		Syntax:  strPtr("proto3"),
+		Package: strPtr("test.partial.v1"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: strPtr("Request"), Field: []*descriptorpb.FieldDescriptorProto{idField("id")}},
+			{Name: strPtr("Response"), Field: []*descriptorpb.FieldDescriptorProto{idField("id")}},
+		},
+	}
+	service := &descriptorpb.FileDescriptorProto{
+		Name:        strPtr("partial_service.proto"),
+		Syntax:      strPtr("proto3"),
+		Package:     strPtr("test.partial.v1"),
+		Dependency:  []string{"partial_types.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{local},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: strPtr("PartialService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: strPtr("Good"), InputType: strPtr(".test.partial.v1.Request"), OutputType: strPtr(".test.partial.v1.Response")},
+				{Name: strPtr("Missing"), InputType: strPtr(".missing.v1.Type"), OutputType: strPtr(".test.partial.v1.Response")},
+				{Name: strPtr("Stuck"), InputType: strPtr(".test.partial.v1.LocalRequest"), OutputType: strPtr(".test.partial.v1.Response")},
+			},
+		}},
+	}
+	return []*descriptorpb.FileDescriptorProto{types, service}
+}
+
+func TestDescriptorSet_PartiallyResolvedService(t *testing.T) {
+	const serviceName = "test.partial.v1.PartialService"
+
+	tests := []struct {
+		name     string
+		broken   bool
+		stuckErr string // Error of the Stuck method; "" when it resolves
+	}{
+		{name: "file builds with a placeholder", broken: false},
+		{name: "file stuck on a message", broken: true, stuckErr: "input type test.partial.v1.LocalRequest could not be resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeDescriptorSet(t, makePartialFDPs(tt.broken)...)
+			rc, err := NewReflectionClientFromDescriptorSet(nil, path, discardLogger)
+			require.NoError(t, err)
+			defer rc.Close()
+
+			services, err := rc.ListServices(context.Background())
+			require.NoError(t, err)
+			require.Len(t, services, 1)
+			service := services[0]
+			assert.Equal(t, serviceName, service.FullName)
+			assert.Empty(t, service.Error)
+			require.Len(t, service.Methods, 3)
+
+			errs := map[string]string{}
+			for _, m := range service.Methods {
+				errs[m.Name] = m.Error
+			}
+			assert.Empty(t, errs["Good"])
+			assert.Equal(t, "input type missing.v1.Type could not be resolved", errs["Missing"])
+			assert.Equal(t, tt.stuckErr, errs["Stuck"])
+
+			md, err := rc.GetMethodDescriptor(serviceName, "Good")
+			require.NoError(t, err)
+			assert.NotNil(t, md.Input().Fields().ByName("id"))
+
+			_, err = rc.GetMethodDescriptor(serviceName, "Missing")
+			assert.ErrorContains(t, err, "cannot be called: input type missing.v1.Type could not be resolved")
+
+			_, err = rc.GetMethodDescriptor(serviceName, "Stuck")
+			if tt.stuckErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.stuckErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// startExtensionServer serves ext.v1.RecordService, whose Record is extended
+// in a file the service's file does not import, and returns a reflection
+// client that has listed it.
+func startExtensionServer(t *testing.T) (*grpctest.Server, *ReflectionClient) {
+	t.Helper()
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionRegistry(grpctest.ExtensionFiles()...),
+		grpctest.WithExtensionService(),
+	)
+	client := NewReflectionClient(srv.Conn, testLogger)
+	t.Cleanup(client.Close)
+	_, err := client.ListServices(context.Background())
+	require.NoError(t, err)
+	return srv, client
+}
+
+func TestReflectionClient_TypeResolverFetchesExtensions(t *testing.T) {
+	_, client := startExtensionServer(t)
+	types := client.TypeResolver()
+
+	xt, err := types.FindExtensionByName("ext.v1.color")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FieldNumber(100), xt.TypeDescriptor().Number())
+
+	xt, err = types.FindExtensionByNumber("ext.v1.Record", 101)
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("ext.v1.Audit.audit"), xt.TypeDescriptor().FullName())
+
+	mt, err := types.FindMessageByName("ext.v1.Audit")
+	require.NoError(t, err, "messages of the extension files are known too")
+	assert.Equal(t, protoreflect.FullName("ext.v1.Audit"), mt.Descriptor().FullName())
+}
+
+func TestInvokeUnary_Extensions(t *testing.T) {
+	srv, client := startExtensionServer(t)
+	md, err := client.GetMethodDescriptor("ext.v1.RecordService", "Echo")
+	require.NoError(t, err)
+
+	invoker := NewInvoker(srv.Conn, testLogger)
+	invoker.SetTypeResolver(client.TypeResolver())
+
+	req := `{"id": "r1", "[ext.v1.color]": "blue", "[ext.v1.Audit.audit]": {"by": "ops"}}`
+	resp, _, _, err := invoker.InvokeUnary(context.Background(), md, req, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, req, resp)
+
+	// Without the extensions, the request cannot be written
+	_, _, _, err = NewInvoker(srv.Conn, testLogger).InvokeUnary(context.Background(), md, req, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,617 @@
+package grpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// grpcWebHeaderLen is the size of the length-prefixed frame header:
+	// one flag byte followed by a 4-byte big-endian payload length.
+	grpcWebHeaderLen = 5
+	// grpcWebTrailerFlag marks a frame whose payload is the trailer block.
+	grpcWebTrailerFlag = 0x80
+	// grpcWebCompressedFlag marks a frame whose payload is compressed.
+	grpcWebCompressedFlag = 0x01
+	// grpcWebMaxFrameSize guards against absurd lengths from a broken proxy.
+	grpcWebMaxFrameSize = 64 * 1024 * 1024
+)
+
+// Compile-time interface checks.
+var (
+	_ grpc.ClientConnInterface = (*WebConn)(nil)
+	_ grpc.ClientStream        = (*webClientStream)(nil)
+)
+
+// WebConn is a grpc.ClientConnInterface that speaks gRPC-Web over HTTP/1.1.
+// It lets the Invoker call servers that are
+// only reachable through a gRPC-Web proxy such as Envoy's grpc_web filter.
+//
+// Only unary and server-streaming calls are supported; gRPC-Web has no
+// client-streaming or bidirectional streaming.
+type WebConn struct {
+	baseURL *url.URL
+	text    bool // grpc-web-text: base64-encode request and response bodies
+	client  *http.Client
+	logger  *slog.Logger
+
+	// Client interceptors, as grpc.WithUnaryInterceptor and
+	// grpc.WithStreamInterceptor would install; nil for none
+	unary  grpc.UnaryClientInterceptor
+	stream grpc.StreamClientInterceptor
+}
+
+// NewWebConn creates a gRPC-Web connection to the given target.
+// The target may be a bare host:port (the scheme is then chosen from tlsConfig)
+// or a full http:// or https:// URL, optionally with a path prefix.
+// When text is true the grpc-web-text (base64) wire format is used.
+func NewWebConn(target string, text bool, tlsConfig *tls.Config, logger *slog.Logger) (*WebConn, error) {
+	raw := target
+	if !strings.Contains(raw, "://") {
+		scheme := "http"
+		if tlsConfig != nil {
+			scheme = "https"
+		}
+		raw = scheme + "://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC-Web address %q: %w", target, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported gRPC-Web scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid gRPC-Web address %q: missing host", target)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &WebConn{
+		baseURL: u,
+		text:    text,
+		client:  &http.Client{Transport: transport},
+		logger:  logger,
+	}, nil
+}
+
+// setDialer makes the client open its connections with dial, e.g. through a
+// proxy, instead of the environment's HTTP proxy settings.
+func (c *WebConn) setDialer(dial dialFunc) {
+	transport := c.client.Transport.(*http.Transport)
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dial(ctx, addr)
+	}
+}
+
+// setInterceptors makes every call go through unary or stream. Their
+// *grpc.ClientConn argument is nil.
+func (c *WebConn) setInterceptors(unary grpc.UnaryClientInterceptor, stream grpc.StreamClientInterceptor) {
+	c.unary = unary
+	c.stream = stream
+}
+
+// Close releases idle HTTP connections held by the client.
+func (c *WebConn) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// Invoke performs a unary RPC over gRPC-Web.
+func (c *WebConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if c.unary != nil {
+		return c.unary(ctx, method, args, reply, nil, c.invoke, opts...)
+	}
+	return c.invoke(ctx, method, args, reply, nil, opts...)
+}
+
+// invoke is Invoke without interceptors, shaped as a grpc.UnaryInvoker.
+func (c *WebConn) invoke(ctx context.Context, method string, args, reply any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+	s := &webClientStream{conn: c, ctx: ctx, method: method}
+	defer s.applyCallOptions(opts)
+
+	if err := s.SendMsg(args); err != nil {
+		return err
+	}
+	_ = s.CloseSend()
+	if err := s.RecvMsg(reply); err != nil {
+		if err == io.EOF {
+			return status.Error(codes.Internal, "grpc-web: server returned no response message")
+		}
+		return err
+	}
+
+	// Drain to the trailer frame so a non-OK status after the message is reported.
+	var extra []byte
+	switch err := s.recvFrame(&extra); {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	default:
+		return status.Error(codes.Internal, "grpc-web: server returned more than one response message for a unary call")
+	}
+}
+
+// NewStream starts a streaming RPC over gRPC-Web. Only server streaming is
+// supported: the single request message is sent when CloseSend is called.
+func (c *WebConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if c.stream != nil {
+		return c.stream(ctx, desc, nil, method, c.newStream, opts...)
+	}
+	return c.newStream(ctx, desc, nil, method, opts...)
+}
+
+// newStream is NewStream without interceptors, shaped as a grpc.Streamer.
+func (c *WebConn) newStream(ctx context.Context, desc *grpc.StreamDesc, _ *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if desc.ClientStreams {
+		return nil, status.Error(codes.Unimplemented, "gRPC-Web does not support client or bidirectional streaming")
+	}
+	s := &webClientStream{conn: c, ctx: ctx, method: method, opts: opts}
+	return s, nil
+}
+
+// webClientStream is a single gRPC-Web call. The request is buffered by
+// SendMsg and posted on CloseSend; responses are decoded frame by frame.
+type webClientStream struct {
+	conn   *WebConn
+	ctx    context.Context
+	method string
+	opts   []grpc.CallOption // applied when the stream finishes
+
+	request []byte
+	sent    bool
+
+	body     io.ReadCloser
+	reader   *bufio.Reader
+	header   metadata.MD
+	trailer  metadata.MD
+	finalErr error // sticky error (io.EOF on clean completion)
+}
+
+// SendMsg buffers the request message. gRPC-Web carries exactly one request.
+func (s *webClientStream) SendMsg(m any) error {
+	if s.sent || s.request != nil {
+		return status.Error(codes.Internal, "grpc-web: only one request message may be sent")
+	}
+	payload, err := marshalMessage(m)
+	if err != nil {
+		return status.Errorf(codes.Internal, "grpc-web: failed to marshal request: %v", err)
+	}
+	s.request = payload
+	return nil
+}
+
+// CloseSend posts the buffered request and reads the response headers.
+// As with native gRPC, call failures surface from Header and RecvMsg rather
+// than from CloseSend.
+func (s *webClientStream) CloseSend() error {
+	if s.sent {
+		return nil
+	}
+	s.sent = true
+	_ = s.start()
+	return nil
+}
+
+// Header returns the response headers, starting the call if needed.
+func (s *webClientStream) Header() (metadata.MD, error) {
+	_ = s.CloseSend()
+	if s.header == nil && s.finalErr != nil && s.finalErr != io.EOF {
+		return nil, s.finalErr
+	}
+	return s.header, nil
+}
+
+// Trailer returns the trailers parsed from the response body (or from the
+// HTTP headers for trailers-only responses). Valid once RecvMsg returns an error.
+func (s *webClientStream) Trailer() metadata.MD {
+	return s.trailer
+}
+
+// Context returns the call context.
+func (s *webClientStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg decodes the next response message into m.
+func (s *webClientStream) RecvMsg(m any) error {
+	_ = s.CloseSend()
+
+	var payload []byte
+	if err := s.recvFrame(&payload); err != nil {
+		return err
+	}
+	if err := unmarshalMessage(payload, m); err != nil {
+		return s.fail(status.Errorf(codes.Internal, "grpc-web: failed to unmarshal response: %v", err))
+	}
+	return nil
+}
+
+// start sends the HTTP request and processes the response status line and headers.
+func (s *webClientStream) start() error {
+	c := s.conn
+
+	body := make([]byte, grpcWebHeaderLen+len(s.request))
+	binary.BigEndian.PutUint32(body[1:grpcWebHeaderLen], uint32(len(s.request)))
+	copy(body[grpcWebHeaderLen:], s.request)
+
+	contentType := "application/grpc-web+proto"
+	if c.text {
+		contentType = "application/grpc-web-text"
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+
+	endpoint := *c.baseURL
+	endpoint.Path = c.baseURL.Path + s.method
+
+	httpReq, err := http.NewRequestWithContext(s.ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return s.fail(status.Errorf(codes.Internal, "grpc-web: failed to build request: %v", err))
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Accept", contentType)
+	httpReq.Header.Set("X-Grpc-Web", "1")
+	httpReq.Header.Set("X-User-Agent", "grotto-grpc-web")
+	if deadline, ok := s.ctx.Deadline(); ok {
+		httpReq.Header.Set("Grpc-Timeout", encodeGRPCTimeout(time.Until(deadline)))
+	}
+	if md, ok := metadata.FromOutgoingContext(s.ctx); ok {
+		for key, values := range md {
+			for _, v := range values {
+				if strings.HasSuffix(key, "-bin") {
+					v = base64.StdEncoding.EncodeToString([]byte(v))
+				}
+				httpReq.Header.Add(key, v)
+			}
+		}
+	}
+
+	c.logger.Debug("sending gRPC-Web request",
+		slog.String("url", endpoint.String()),
+		slog.Bool("text", c.text),
+		slog.Int("request_bytes", len(s.request)),
+	)
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		if ctxErr := s.ctx.Err(); ctxErr != nil {
+			return s.fail(status.FromContextError(ctxErr).Err())
+		}
+		return s.fail(status.Errorf(codes.Unavailable, "grpc-web: %v", err))
+	}
+
+	s.header = headerToMetadata(httpResp.Header)
+
+	// Trailers-only responses put grpc-status in the HTTP headers.
+	if httpResp.Header.Get("Grpc-Status") != "" {
+		httpResp.Body.Close()
+		s.trailer = s.header
+		s.header = metadata.MD{}
+		return s.fail(takeStatus(s.trailer))
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		httpResp.Body.Close()
+		return s.fail(status.Errorf(httpStatusToCode(httpResp.StatusCode),
+			"grpc-web: unexpected HTTP status %s", httpResp.Status))
+	}
+
+	s.body = httpResp.Body
+	var r io.Reader = httpResp.Body
+	if c.text || strings.HasPrefix(httpResp.Header.Get("Content-Type"), "application/grpc-web-text") {
+		r = &base64ChunkReader{src: bufio.NewReader(httpResp.Body)}
+	}
+	s.reader = bufio.NewReader(r)
+	return nil
+}
+
+// recvFrame reads the next data frame into payload. When the trailer frame
+// is reached it records the trailers and returns io.EOF on OK status or the
+// corresponding status error otherwise.
+func (s *webClientStream) recvFrame(payload *[]byte) error {
+	if s.finalErr != nil {
+		return s.finalErr
+	}
+	if s.reader == nil {
+		return s.fail(status.Error(codes.Internal, "grpc-web: stream not started"))
+	}
+
+	for {
+		var hdr [grpcWebHeaderLen]byte
+		if _, err := io.ReadFull(s.reader, hdr[:]); err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return s.fail(status.FromContextError(ctxErr).Err())
+			}
+			if err == io.EOF {
+				return s.fail(status.Error(codes.Internal, "grpc-web: response ended without trailers"))
+			}
+			return s.fail(status.Errorf(codes.Internal, "grpc-web: failed to read frame: %v", err))
+		}
+
+		flags := hdr[0]
+		length := binary.BigEndian.Uint32(hdr[1:])
+		if length > grpcWebMaxFrameSize {
+			return s.fail(status.Errorf(codes.ResourceExhausted, "grpc-web: frame of %d bytes exceeds limit", length))
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(s.reader, data); err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return s.fail(status.FromContextError(ctxErr).Err())
+			}
+			return s.fail(status.Errorf(codes.Internal, "grpc-web: truncated frame: %v", err))
+		}
+
+		if flags&grpcWebCompressedFlag != 0 {
+			return s.fail(status.Error(codes.Unimplemented, "grpc-web: compressed responses are not supported"))
+		}
+
+		if flags&grpcWebTrailerFlag != 0 {
+			s.trailer = parseTrailerBlock(data)
+			return s.fail(takeStatus(s.trailer))
+		}
+
+		*payload = data
+		return nil
+	}
+}
+
+// fail records a terminal error, closes the response body, and returns err.
+func (s *webClientStream) fail(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	if s.finalErr == nil {
+		s.finalErr = err
+	}
+	if s.body != nil {
+		s.body.Close()
+		s.body = nil
+	}
+	if s.trailer == nil {
+		s.trailer = metadata.MD{}
+	}
+	s.applyCallOptions(s.opts)
+	return s.finalErr
+}
+
+// applyCallOptions copies headers/trailers into grpc.Header/grpc.Trailer targets.
+func (s *webClientStream) applyCallOptions(opts []grpc.CallOption) {
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			if o.HeaderAddr != nil {
+				*o.HeaderAddr = s.header
+			}
+		case grpc.TrailerCallOption:
+			if o.TrailerAddr != nil {
+				*o.TrailerAddr = s.trailer
+			}
+		}
+	}
+}
+
+// parseTrailerBlock parses an HTTP/1-style header block ("key: value\r\n"),
+// decoding base64 values of binary (-bin) trailers.
+func parseTrailerBlock(data []byte) metadata.MD {
+	md := metadata.MD{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if strings.HasSuffix(key, "-bin") {
+			if decoded, err := decodeBinaryHeader(value); err == nil {
+				value = string(decoded)
+			}
+		}
+		md.Append(key, value)
+	}
+	return md
+}
+
+// takeStatus converts the grpc-status, grpc-message, and grpc-status-details-bin
+// trailers into an error (nil for OK) and removes them from md, matching the
+// trailers a native gRPC call exposes.
+func takeStatus(md metadata.MD) error {
+	err := statusFromTrailer(md)
+	delete(md, "grpc-status")
+	delete(md, "grpc-message")
+	delete(md, "grpc-status-details-bin")
+	return err
+}
+
+// statusFromTrailer converts grpc-status/grpc-message trailers into an error.
+// Rich error details are decoded from grpc-status-details-bin when present.
+// Returns nil for OK.
+func statusFromTrailer(md metadata.MD) error {
+	codeStr := firstValue(md, "grpc-status")
+	if codeStr == "" {
+		return status.Error(codes.Internal, "grpc-web: missing grpc-status in trailers")
+	}
+	code, err := strconv.Atoi(codeStr)
+	if err != nil {
+		return status.Errorf(codes.Internal, "grpc-web: invalid grpc-status %q", codeStr)
+	}
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+	if details := firstValue(md, "grpc-status-details-bin"); details != "" {
+		var st spb.Status
+		if err := proto.Unmarshal([]byte(details), &st); err == nil && st.GetCode() == int32(code) {
+			return status.FromProto(&st).Err()
+		}
+	}
+	msg := firstValue(md, "grpc-message")
+	if decoded, err := url.PathUnescape(msg); err == nil {
+		msg = decoded
+	}
+	return status.Error(codes.Code(code), msg)
+}
+
+// headerToMetadata converts HTTP response headers into lower-cased gRPC metadata,
+// decoding base64 values of binary (-bin) headers.
+func headerToMetadata(h http.Header) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range h {
+		key = strings.ToLower(key)
+		for _, v := range values {
+			if strings.HasSuffix(key, "-bin") {
+				if decoded, err := decodeBinaryHeader(v); err == nil {
+					v = string(decoded)
+				}
+			}
+			md.Append(key, v)
+		}
+	}
+	return md
+}
+
+// decodeBinaryHeader decodes a -bin header value, which may omit padding.
+func decodeBinaryHeader(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
+
+// firstValue returns the first value for key in md, or "".
+func firstValue(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// encodeGRPCTimeout formats a duration as a grpc-timeout header value.
+func encodeGRPCTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "1n"
+	}
+	// Timeout values are limited to 8 digits.
+	const maxValue = 99999999
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"n", time.Nanosecond},
+		{"u", time.Microsecond},
+		{"m", time.Millisecond},
+		{"S", time.Second},
+		{"M", time.Minute},
+		{"H", time.Hour},
+	}
+	for _, u := range units {
+		if v := d / u.unit; v <= maxValue {
+			// Round up so the server never sees a shorter deadline.
+			if d%u.unit != 0 {
+				v++
+			}
+			return strconv.FormatInt(int64(v), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(maxValue, 10) + "H"
+}
+
+// httpStatusToCode maps a non-200 HTTP status to a gRPC code, following the
+// gRPC HTTP-to-gRPC status mapping.
+func httpStatusToCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// base64ChunkReader decodes a grpc-web-text body. Servers may flush each
+// frame as an independently padded base64 chunk, so the stream is decoded in
+// 4-character quanta rather than with a single base64 decoder.
+type base64ChunkReader struct {
+	src *bufio.Reader
+	out []byte
+	err error
+}
+
+func (r *base64ChunkReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		var quantum [4]byte
+		n := 0
+		for n < 4 {
+			b, err := r.src.ReadByte()
+			if err != nil {
+				if n != 0 && errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF
+				}
+				r.err = err
+				break
+			}
+			if b == '\r' || b == '\n' || b == ' ' || b == '\t' {
+				continue
+			}
+			quantum[n] = b
+			n++
+		}
+		if n < 4 {
+			if r.err == nil {
+				r.err = io.ErrUnexpectedEOF
+			}
+			continue
+		}
+		decoded := make([]byte, 3)
+		dn, err := base64.StdEncoding.Decode(decoded, quantum[:])
+		if err != nil {
+			r.err = fmt.Errorf("invalid grpc-web-text body: %w", err)
+			continue
+		}
+		r.out = decoded[:dn]
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
@@ -0,0 +1,237 @@
+package grpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/shhac/grotto/testdata/grpcweb"
+)
+
+// newWebInvoker starts an in-process gRPC-Web bridge in front of the shared
+// test server and returns an Invoker that talks to it over gRPC-Web.
+func newWebInvoker(t *testing.T, text bool) (*Invoker, *WebConn) {
+	t.Helper()
+	srv := httptest.NewServer(grpcweb.NewHandler(testConn))
+	t.Cleanup(srv.Close)
+
+	conn, err := NewWebConn(srv.URL, text, nil, testLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewInvoker(conn, testLogger), conn
+}
+
+// testMethod looks up a TestService method from the compiled descriptor.
+func testMethod(t *testing.T, name string) protoreflect.MethodDescriptor {
+	t.Helper()
+	svc := pb.File_grpc_test_proto.Services().ByName("TestService")
+	require.NotNil(t, svc)
+	md := svc.Methods().ByName(protoreflect.Name(name))
+	require.NotNil(t, md, "method %s not found", name)
+	return md
+}
+
+func TestWebInvokeUnary(t *testing.T) {
+	for _, text := range []bool{false, true} {
+		name := "binary"
+		if text {
+			name = "text"
+		}
+		t.Run(name, func(t *testing.T) {
+			inv, _ := newWebInvoker(t, text)
+
+			resp, headers, trailers, err := inv.InvokeUnary(
+				context.Background(),
+				testMethod(t, "UnaryEcho"),
+				`{"item": {"id": "web-1", "name": "over grpc-web"}}`,
+				metadata.Pairs("x-test", "1"),
+			)
+			require.NoError(t, err)
+
+			var result map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(resp), &result))
+			assert.Equal(t, true, result["ok"])
+			item := result["item"].(map[string]interface{})
+			assert.Equal(t, "web-1", item["id"])
+
+			assert.NotNil(t, headers)
+			assert.Empty(t, trailers.Get("grpc-status"), "status trailers should not leak into metadata")
+		})
+	}
+}
+
+func TestWebInvokeServerStream(t *testing.T) {
+	for _, text := range []bool{false, true} {
+		name := "binary"
+		if text {
+			name = "text"
+		}
+		t.Run(name, func(t *testing.T) {
+			inv, _ := newWebInvoker(t, text)
+
+			msgChan, errChan, headerChan, trailerChan := inv.InvokeServerStream(
+				context.Background(),
+				testMethod(t, "StreamItems"),
+				`{"item": {"id": "s1"}}`,
+				nil,
+			)
+
+			var messages []string
+			for msg := range msgChan {
+				messages = append(messages, msg)
+			}
+			assert.Len(t, messages, 3)
+
+			err := <-errChan
+			assert.Equal(t, io.EOF, err)
+			_, ok := <-headerChan
+			assert.True(t, ok, "expected headers")
+			_, ok = <-trailerChan
+			assert.True(t, ok, "expected trailers")
+		})
+	}
+}
+
+func TestWebInvoke_StatusError(t *testing.T) {
+	_, conn := newWebInvoker(t, false)
+
+	var resp pb.ItemResponse
+	err := conn.Invoke(context.Background(), "/grpctest.TestService/NoSuchMethod", &pb.ItemRequest{}, &resp)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestWebInvoke_ClientStreamingUnsupported(t *testing.T) {
+	inv, _ := newWebInvoker(t, false)
+
+	_, err := inv.InvokeClientStream(context.Background(), testMethod(t, "CollectItems"), nil)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestWebInvoke_TrailersOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Header().Set("Grpc-Status", "16")
+		w.Header().Set("Grpc-Message", "token%20expired")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	conn, err := NewWebConn(srv.URL, false, nil, testLogger)
+	require.NoError(t, err)
+
+	var resp pb.ItemResponse
+	err = conn.Invoke(context.Background(), "/grpctest.TestService/UnaryEcho", &pb.ItemRequest{}, &resp)
+	require.Error(t, err)
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	assert.Equal(t, "token expired", st.Message())
+}
+
+func TestWebInvoke_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	conn, err := NewWebConn(srv.URL, false, nil, testLogger)
+	require.NoError(t, err)
+
+	var resp pb.ItemResponse
+	err = conn.Invoke(context.Background(), "/grpctest.TestService/UnaryEcho", &pb.ItemRequest{}, &resp)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestNewWebConn_Address(t *testing.T) {
+	tests := []struct {
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{"localhost:8080", "http://localhost:8080", false},
+		{"http://proxy.local/api/", "http://proxy.local/api", false},
+		{"https://proxy.local", "https://proxy.local", false},
+		{"ftp://proxy.local", "", true},
+		{"http://", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			conn, err := NewWebConn(tt.target, false, nil, testLogger)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, conn.baseURL.String())
+		})
+	}
+}
+
+func TestEncodeGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "1n"},
+		{500 * time.Nanosecond, "500n"},
+		{30 * time.Second, "30000000u"},
+		{1500 * time.Millisecond, "1500000u"},
+		{10 * time.Minute, "600000m"},
+		{48 * time.Hour, "172800S"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, encodeGRPCTimeout(tt.d))
+		})
+	}
+}
+
+func TestBase64ChunkReader(t *testing.T) {
+	// Two independently padded chunks, as emitted by streaming proxies.
+	first := base64.StdEncoding.EncodeToString([]byte("hello"))
+	second := base64.StdEncoding.EncodeToString([]byte(", world!"))
+	r := &base64ChunkReader{src: bufioReader(first + "\r\n" + second)}
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world!", string(got))
+
+	bad := &base64ChunkReader{src: bufioReader("not*base64")}
+	_, err = io.ReadAll(bad)
+	assert.Error(t, err)
+}
+
+func TestParseTrailerBlock(t *testing.T) {
+	details := base64.RawStdEncoding.EncodeToString([]byte{0x01, 0x02})
+	md := parseTrailerBlock([]byte("grpc-status: 0\r\nX-Custom: a\r\nx-custom: b\r\ntrace-bin: " + details + "\r\n"))
+
+	assert.Equal(t, []string{"0"}, md.Get("grpc-status"))
+	assert.Equal(t, []string{"a", "b"}, md.Get("x-custom"))
+	assert.Equal(t, []string{"\x01\x02"}, md.Get("trace-bin"))
+	assert.NoError(t, takeStatus(md))
+	assert.Empty(t, md.Get("grpc-status"))
+}
+
+func bufioReader(s string) *bufio.Reader {
+	return bufio.NewReader(bytes.NewBufferString(s))
+}
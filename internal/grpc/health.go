@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HealthStatus is a serving status reported by grpc.health.v1.Health, or
+// HealthUnavailable when the server does not implement it.
+type HealthStatus string
+
+// Health statuses, named as in HealthCheckResponse.ServingStatus
+const (
+	HealthUnknown        HealthStatus = "UNKNOWN"
+	HealthServing        HealthStatus = "SERVING"
+	HealthNotServing     HealthStatus = "NOT_SERVING"
+	HealthServiceUnknown HealthStatus = "SERVICE_UNKNOWN"
+	HealthUnavailable    HealthStatus = "n/a"
+)
+
+// DefaultHealthInterval is how often HealthMonitor checks by default.
+const DefaultHealthInterval = 10 * time.Second
+
+// maxHealthTimeout bounds a single Check call so a slow server cannot hold
+// up the next poll.
+const maxHealthTimeout = 5 * time.Second
+
+// healthCheckMethod returns the descriptor of grpc.health.v1.Health/Check.
+// Only the descriptor is used: the call itself goes through the dynamic
+// invoker like any other request.
+func healthCheckMethod() protoreflect.MethodDescriptor {
+	return healthpb.File_grpc_health_v1_health_proto.Services().ByName("Health").Methods().ByName("Check")
+}
+
+// CheckHealth calls grpc.health.v1.Health/Check for service ("" asks about
+// the server as a whole). A server without the health service reports
+// HealthUnavailable and no error. Checks are not retried: the next poll is
+// the retry.
+func (i *Invoker) CheckHealth(ctx context.Context, service string) (HealthStatus, error) {
+	req, err := json.Marshal(map[string]string{"service": service})
+	if err != nil {
+		return HealthUnknown, err
+	}
+
+	resp, _, _, err := i.invokeUnary(ctx, healthCheckMethod(), string(req), nil)
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return HealthUnavailable, nil
+		}
+		return HealthUnknown, err
+	}
+
+	var out struct {
+		Status HealthStatus `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(resp), &out); err != nil {
+		return HealthUnknown, fmt.Errorf("failed to parse health response: %w", err)
+	}
+	if out.Status == "" {
+		// protojson omits the zero value
+		return HealthUnknown, nil
+	}
+	return out.Status, nil
+}
+
+// HealthResult is the outcome of one health check.
+type HealthResult struct {
+	Status    HealthStatus
+	Err       error // set when the check itself failed
+	CheckedAt time.Time
+}
+
+// HealthMonitor polls a server's overall health in the background.
+type HealthMonitor struct {
+	invoker  *Invoker
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHealthMonitor creates a monitor that checks every interval
+// (DefaultHealthInterval when interval is not positive).
+func NewHealthMonitor(invoker *Invoker, interval time.Duration, logger *slog.Logger) *HealthMonitor {
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+	return &HealthMonitor{
+		invoker:  invoker,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start checks health now and then every interval, passing each result to
+// fn on the monitor's goroutine. Polling stops early if the server turns
+// out not to implement the health service. Start replaces any earlier run.
+func (m *HealthMonitor) Start(fn func(HealthResult)) {
+	m.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.mu.Lock()
+	m.cancel = cancel
+	m.done = done
+	m.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			result := m.check(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			fn(result)
+			if result.Status == HealthUnavailable {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for an in-flight check to finish. No results
+// are delivered after Stop returns.
+func (m *HealthMonitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// check runs a single bounded health check.
+func (m *HealthMonitor) check(ctx context.Context) HealthResult {
+	timeout := min(m.interval, maxHealthTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	st, err := m.invoker.CheckHealth(ctx, "")
+	if err != nil {
+		m.logger.Debug("health check failed", slog.Any("error", err))
+	}
+	return HealthResult{Status: st, Err: err, CheckedAt: time.Now()}
+}
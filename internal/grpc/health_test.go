@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCheckHealth(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithHealth())
+	invoker := NewInvoker(srv.Conn, testLogger)
+
+	st, err := invoker.CheckHealth(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, HealthServing, st)
+
+	srv.Health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	st, err = invoker.CheckHealth(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, HealthNotServing, st)
+
+	// The standard server answers NotFound for services it has not heard of
+	_, err = invoker.CheckHealth(context.Background(), "no.Such")
+	assert.Error(t, err)
+
+	srv.Health.SetServingStatus("svc", healthpb.HealthCheckResponse_UNKNOWN)
+	st, err = invoker.CheckHealth(context.Background(), "svc")
+	require.NoError(t, err)
+	assert.Equal(t, HealthUnknown, st, "zero value omitted from the JSON")
+}
+
+func TestCheckHealth_NotImplemented(t *testing.T) {
+	// The shared test server has no health service
+	st, err := NewInvoker(testConn, testLogger).CheckHealth(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, HealthUnavailable, st)
+}
+
+func TestHealthMonitor(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithHealth())
+	monitor := NewHealthMonitor(NewInvoker(srv.Conn, testLogger), 20*time.Millisecond, testLogger)
+
+	results := make(chan HealthResult, 100)
+	monitor.Start(func(r HealthResult) { results <- r })
+	defer monitor.Stop()
+
+	first := <-results
+	assert.Equal(t, HealthServing, first.Status)
+	assert.NoError(t, first.Err)
+	assert.False(t, first.CheckedAt.IsZero())
+
+	srv.Health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	require.Eventually(t, func() bool {
+		for {
+			select {
+			case r := <-results:
+				if r.Status == HealthNotServing {
+					return true
+				}
+			default:
+				return false
+			}
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Nothing arrives once stopped
+	monitor.Stop()
+	for len(results) > 0 {
+		<-results
+	}
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, results)
+}
+
+func TestHealthMonitor_StopsWithoutHealthService(t *testing.T) {
+	monitor := NewHealthMonitor(NewInvoker(testConn, testLogger), 10*time.Millisecond, testLogger)
+
+	results := make(chan HealthResult, 100)
+	monitor.Start(func(r HealthResult) { results <- r })
+	defer monitor.Stop()
+
+	assert.Equal(t, HealthUnavailable, (<-results).Status)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, results, "no further polls")
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/shhac/grotto/internal/testutil/grpctest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -199,8 +203,127 @@ func TestResolveService_NotFound(t *testing.T) {
 	require.Error(t, err)
 }
 
+// Run with -race: connecting lists services in a goroutine while the UI
+// looks methods up.
+func TestReflectionClient_ConcurrentUse(t *testing.T) {
+	rc := NewReflectionClient(testConn, testLogger)
+	defer rc.Close()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 10 {
+				_, err := rc.ListServices(context.Background())
+				assert.NoError(t, err)
+			}
+		})
+		wg.Go(func() {
+			for range 20 {
+				md, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+				if assert.NoError(t, err) {
+					assert.Equal(t, protoreflect.Name("UnaryEcho"), md.Name())
+				}
+				rc.FromSchemaCache()
+				rc.FileDescriptorProtos()
+			}
+		})
+	}
+	wg.Go(func() {
+		for range 10 {
+			assert.NoError(t, rc.InvalidateSchemaCache())
+		}
+	})
+	wg.Wait()
+}
+
+func TestReflectionClient_CloseDuringLenientResolve(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...))
+	rc := NewReflectionClient(srv.Conn, testLogger)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			// Results are undefined once closed; it must just not crash
+			_, _ = rc.ListServices(context.Background())
+			_, _ = rc.GetMethodDescriptor("custom.event.v1.EventService", "GetEvent")
+		})
+	}
+	wg.Go(rc.Close)
+	wg.Wait()
+}
+
+func TestListServices_CancelStalledServer(t *testing.T) {
+	// The server holds every reflection response for an hour
+	srv := grpctest.StartServer(t, grpctest.WithReflectionLatency(time.Hour))
+	rc := NewReflectionClient(srv.Conn, testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := rc.ListServices(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second, "cancel must not wait for the server")
+
+	// Closing must not wait on the stalled stream either
+	closed := make(chan struct{})
+	go func() {
+		rc.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on the stalled reflection stream")
+	}
+}
+
+func TestGetMethodDescriptor_CloseStalledServer(t *testing.T) {
+	// The server holds every reflection response for an hour, so the
+	// lookup falls back to lenient resolution, which must stall no longer
+	srv := grpctest.StartServer(t, grpctest.WithReflectionLatency(time.Hour))
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	time.AfterFunc(100*time.Millisecond, rc.Close)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := rc.GetMethodDescriptor("grotto.test.TestService", "Echo")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetMethodDescriptor blocked on the stalled reflection stream after Close")
+	}
+}
+
+func TestListServices_ReportsProgress(t *testing.T) {
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...),
+		grpctest.WithHealth(),
+	)
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	defer rc.Close()
+
+	var calls [][2]int
+	rc.SetOnProgress(func(resolved, total int) {
+		calls = append(calls, [2]int{resolved, total})
+	})
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+
+	require.NotEmpty(t, calls)
+	total := len(services)
+	assert.Equal(t, [2]int{0, total}, calls[0], "starts with nothing resolved")
+	assert.Equal(t, [2]int{total, total}, calls[len(calls)-1], "ends with everything resolved")
+	for i := 1; i < len(calls); i++ {
+		assert.GreaterOrEqual(t, calls[i][0], calls[i-1][0], "progress never goes backwards")
+	}
+}
+
 // ---------------------------------------------------------------------------
-// RPC Invocation Tests (grpcdynamic via Invoker)
+// RPC Invocation Tests (dynamicpb via Invoker)
 // ---------------------------------------------------------------------------
 
 func TestInvokeUnary(t *testing.T) {
@@ -255,6 +378,49 @@ func TestInvokeUnary_InvalidJSON(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid request JSON")
 }
 
+func TestInvokeUnary_NonCanonicalServer(t *testing.T) {
+	// Descriptors here only resolve through the lenient build path
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...),
+		grpctest.WithEventService(),
+	)
+	inv := NewInvoker(srv.Conn, testLogger)
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	defer rc.Close()
+
+	_, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+
+	md, err := rc.GetMethodDescriptor("custom.event.v1.EventService", "GetEvent")
+	require.NoError(t, err)
+
+	resp, _, _, err := inv.InvokeUnary(context.Background(), md, `{"id": "42"}`, nil)
+	require.NoError(t, err)
+
+	// createdAt is a google.protobuf.Timestamp from the server's own
+	// google_protobuf.proto barrel file, still formatted as a WKT
+	var event map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resp), &event))
+	assert.Equal(t, "event 42", event["name"])
+	assert.Equal(t, time.Unix(grpctest.EventTime, 0).UTC().Format(time.RFC3339), event["createdAt"])
+
+	md, err = rc.GetMethodDescriptor("custom.event.v1.EventService", "GetEvents")
+	require.NoError(t, err)
+
+	resp, _, _, err = inv.InvokeUnary(context.Background(), md, `{"id": "7"}`, nil)
+	require.NoError(t, err)
+
+	var result struct {
+		Events []struct {
+			Name string `json:"name"`
+		} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resp), &result))
+	require.Len(t, result.Events, 2)
+	assert.Equal(t, "7-1", result.Events[0].Name)
+	assert.Equal(t, "7-2", result.Events[1].Name)
+}
+
 func TestInvokeServerStream(t *testing.T) {
 	inv := NewInvoker(testConn, testLogger)
 	rc := NewReflectionClient(testConn, testLogger)
@@ -306,6 +472,74 @@ func TestInvokeServerStream_Cancel(t *testing.T) {
 	assert.NotEqual(t, io.EOF, streamErr)
 }
 
+// startEarlyHeaderServer starts a server whose streams send a session-id
+// header at once but their first message only after delay.
+func startEarlyHeaderServer(t *testing.T, delay time.Duration) (*Invoker, *ReflectionClient) {
+	t.Helper()
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithStreamHeaders(metadata.Pairs("session-id", "s-42"), delay),
+	)
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	t.Cleanup(rc.Close)
+	return NewInvoker(srv.Conn, testLogger), rc
+}
+
+func TestInvokeServerStream_HeadersBeforeFirstMessage(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	inv, rc := startEarlyHeaderServer(t, delay)
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "StreamItems")
+	require.NoError(t, err)
+
+	start := time.Now()
+	msgChan, errChan, headerChan, _ := inv.InvokeServerStream(context.Background(), md, `{}`, nil)
+
+	select {
+	case hdr := <-headerChan:
+		assert.Equal(t, []string{"s-42"}, hdr.Get("session-id"))
+		assert.Less(t, time.Since(start), delay, "headers must not wait for the first message")
+	case msg := <-msgChan:
+		t.Fatalf("message %s arrived before the headers", msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no headers")
+	}
+
+	<-msgChan
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+	for range msgChan {
+	}
+	assert.Equal(t, io.EOF, <-errChan)
+}
+
+func TestBidiStreamHandle_HeadersBeforeFirstMessage(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	inv, rc := startEarlyHeaderServer(t, delay)
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "BidiEcho")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	handle, err := inv.InvokeBidiStream(ctx, md, nil)
+	require.NoError(t, err)
+
+	// Headers arrive before anything is sent, let alone received
+	select {
+	case hdr := <-handle.Headers():
+		assert.Equal(t, []string{"s-42"}, hdr.Get("session-id"))
+		assert.Less(t, time.Since(start), delay)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no headers")
+	}
+	_, open := <-handle.Headers()
+	assert.False(t, open, "headers are delivered once")
+
+	require.NoError(t, handle.Send(`{"item":{"id":"a"}}`))
+	_, err = handle.Recv()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+}
+
 func TestInvokeClientStream(t *testing.T) {
 	inv := NewInvoker(testConn, testLogger)
 	rc := NewReflectionClient(testConn, testLogger)
@@ -322,6 +556,8 @@ func TestInvokeClientStream(t *testing.T) {
 		req := `{"item":{"id":"item-` + strings.Repeat("x", i+1) + `"}}`
 		require.NoError(t, handle.Send(req))
 	}
+	// Each ItemRequest is 4 bytes of tags and lengths plus the id
+	assert.Equal(t, 3*4+6+7+8, handle.SentSize())
 
 	resp, err := handle.CloseAndReceive()
 	require.NoError(t, err)
@@ -425,6 +661,86 @@ func TestInvokeBidiStream_CloseSendThenDrain(t *testing.T) {
 	assert.Equal(t, 2, received)
 }
 
+// newTrailerInvoker starts a server that echoes x-request-id back as a
+// header and trailer, and returns an invoker, a reflection client, and an
+// outgoing context carrying the request ID.
+func newTrailerInvoker(t *testing.T) (*Invoker, *ReflectionClient, context.Context) {
+	t.Helper()
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithEchoMetadata("x-request-id"))
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	t.Cleanup(rc.Close)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-42")
+	return NewInvoker(srv.Conn, testLogger), rc, ctx
+}
+
+func TestInvokeUnary_Trailers(t *testing.T) {
+	inv, rc, ctx := newTrailerInvoker(t)
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+
+	_, headers, trailers, err := inv.InvokeUnary(ctx, md, `{}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-42"}, headers.Get("x-request-id"))
+	assert.Equal(t, []string{"req-42"}, trailers.Get("x-request-id"))
+}
+
+func TestInvokeUnary_TrailersOnError(t *testing.T) {
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithEchoMetadata("x-request-id"),
+		grpctest.WithStatus("grpctest.TestService/UnaryEcho", codes.Unavailable),
+	)
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	defer rc.Close()
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-42")
+	_, _, trailers, err := NewInvoker(srv.Conn, testLogger).InvokeUnary(ctx, md, `{}`, nil)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, []string{"req-42"}, trailers.Get("x-request-id"))
+}
+
+func TestInvokeServerStream_Trailers(t *testing.T) {
+	inv, rc, ctx := newTrailerInvoker(t)
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "StreamItems")
+	require.NoError(t, err)
+
+	msgChan, errChan, _, trailerChan := inv.InvokeServerStream(ctx, md, `{}`, nil)
+	for range msgChan {
+	}
+	assert.Equal(t, io.EOF, <-errChan)
+	assert.Equal(t, []string{"req-42"}, (<-trailerChan).Get("x-request-id"))
+}
+
+func TestClientStreamHandle_Trailers(t *testing.T) {
+	inv, rc, ctx := newTrailerInvoker(t)
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "CollectItems")
+	require.NoError(t, err)
+
+	handle, err := inv.InvokeClientStream(ctx, md, nil)
+	require.NoError(t, err)
+	require.NoError(t, handle.Send(`{"item":{"id":"a"}}`))
+	_, err = handle.CloseAndReceive()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"req-42"}, handle.Trailers().Get("x-request-id"))
+}
+
+func TestBidiStreamHandle_Trailers(t *testing.T) {
+	inv, rc, ctx := newTrailerInvoker(t)
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "BidiEcho")
+	require.NoError(t, err)
+
+	handle, err := inv.InvokeBidiStream(ctx, md, nil)
+	require.NoError(t, err)
+	require.NoError(t, handle.CloseSend())
+	_, err = handle.Recv()
+	require.Equal(t, io.EOF, err)
+
+	assert.Equal(t, []string{"req-42"}, handle.Trailers().Get("x-request-id"))
+}
+
 // ---------------------------------------------------------------------------
 // JSON Round-Trip Tests
 // ---------------------------------------------------------------------------
@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CallPhase names the step of a call an InvocationError happened in.
+type CallPhase string
+
+const (
+	PhaseMarshal CallPhase = "marshal" // Encoding the request or decoding a response
+	PhaseStart   CallPhase = "start"   // Opening the call
+	PhaseSend    CallPhase = "send"    // Sending a request message
+	PhaseRecv    CallPhase = "recv"    // Receiving a response message, or a unary call's status
+	PhaseClose   CallPhase = "close"   // Closing the send side, or a client stream's response
+)
+
+// InvocationError is a failed call made through an Invoker or one of its
+// stream handles. It carries the call's status, so status.FromError and
+// status.Code see through it, and unwraps to the error it was made from.
+type InvocationError struct {
+	Method  string // Full method name, "pkg.Service.Method"
+	Phase   CallPhase
+	Code    codes.Code
+	Message string
+	Details []any // Status details, such as errdetails.BadRequest
+	Err     error
+
+	status *status.Status
+}
+
+// newInvocationError types err, a failure of method in phase. A nil err, an
+// io.EOF (the normal end of a stream) and an already typed err are returned
+// as they are. Errors without a status are given one: context errors their
+// code, other errors Unknown.
+func newInvocationError(method string, phase CallPhase, err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	var invErr *InvocationError
+	if errors.As(err, &invErr) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err)
+	}
+	return invocationError(method, phase, st, err)
+}
+
+// marshalError types err, a failure to encode a request or decode a
+// response, as a PhaseMarshal error with the given code: InvalidArgument
+// when the request is at fault, Internal when the response is, as grpc-go
+// reports responses it cannot decode.
+func marshalError(method string, code codes.Code, err error) error {
+	return invocationError(method, PhaseMarshal, status.New(code, err.Error()), err)
+}
+
+func invocationError(method string, phase CallPhase, st *status.Status, err error) *InvocationError {
+	return &InvocationError{
+		Method:  method,
+		Phase:   phase,
+		Code:    st.Code(),
+		Message: st.Message(),
+		Details: st.Details(),
+		Err:     err,
+		status:  st,
+	}
+}
+
+// Error returns the message of the error it was made from.
+func (e *InvocationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error it was made from.
+func (e *InvocationError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus returns the call's status, details included.
+func (e *InvocationError) GRPCStatus() *status.Status {
+	if e.status == nil {
+		// Made as a literal rather than from a failed call
+		return status.New(e.Code, e.Message)
+	}
+	return e.status
+}
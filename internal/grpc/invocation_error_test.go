@@ -0,0 +1,209 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+// failingService fails every method with its own status: unary calls with
+// InvalidArgument and a BadRequest detail, server streams with Unavailable
+// after one message, client streams with FailedPrecondition once the client
+// closes, and bidi streams with DataLoss after echoing one message.
+type failingService struct {
+	pb.UnimplementedTestServiceServer
+}
+
+func (failingService) UnaryEcho(context.Context, *pb.ItemRequest) (*pb.ItemResponse, error) {
+	st, err := status.New(codes.InvalidArgument, "id is required").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "item.id", Description: "must not be empty"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, st.Err()
+}
+
+func (failingService) StreamItems(req *pb.ItemRequest, stream pb.TestService_StreamItemsServer) error {
+	if err := stream.Send(&pb.ItemResponse{Item: req.GetItem(), Ok: true}); err != nil {
+		return err
+	}
+	return status.Error(codes.Unavailable, "server going away")
+}
+
+func (failingService) CollectItems(stream pb.TestService_CollectItemsServer) error {
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			return status.Error(codes.FailedPrecondition, "batch is closed")
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (failingService) BidiEcho(stream pb.TestService_BidiEchoServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.ItemResponse{Item: req.GetItem(), Ok: true}); err != nil {
+		return err
+	}
+	return status.Error(codes.DataLoss, "lost the rest")
+}
+
+func startFailingServer(t *testing.T, opts ...grpctest.Option) *Invoker {
+	t.Helper()
+	opts = append([]grpctest.Option{grpctest.WithService(func(s *grpc.Server) {
+		pb.RegisterTestServiceServer(s, failingService{})
+	})}, opts...)
+	srv := grpctest.StartServer(t, opts...)
+	return NewInvoker(srv.Conn, testLogger)
+}
+
+// requireInvocationError checks err is an *InvocationError of the given
+// phase and code, and returns it.
+func requireInvocationError(t *testing.T, err error, method string, phase CallPhase, code codes.Code) *InvocationError {
+	t.Helper()
+	var invErr *InvocationError
+	require.ErrorAs(t, err, &invErr)
+	assert.Equal(t, "grpctest.TestService."+method, invErr.Method)
+	assert.Equal(t, phase, invErr.Phase)
+	assert.Equal(t, code, invErr.Code)
+	// Callers that only look at the status still see it
+	assert.Equal(t, code, status.Code(err))
+	return invErr
+}
+
+func TestInvocationError_Marshal(t *testing.T) {
+	invoker := startFailingServer(t)
+
+	_, _, _, err := invoker.InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{"item": {"count": "many"}}`, nil)
+	invErr := requireInvocationError(t, err, "UnaryEcho", PhaseMarshal, codes.InvalidArgument)
+	assert.Equal(t, invErr.Err.Error(), invErr.Message)
+
+	handle, err := invoker.InvokeBidiStream(context.Background(), testMethod(t, "BidiEcho"), nil)
+	require.NoError(t, err)
+	defer handle.CloseSend()
+	requireInvocationError(t, handle.Send(`{"nope": true}`), "BidiEcho", PhaseMarshal, codes.InvalidArgument)
+}
+
+func TestInvocationError_MarshalResponse(t *testing.T) {
+	// A server answering UnaryEcho with bytes that are not an ItemResponse
+	srv := grpctest.StartServer(t, grpctest.WithService(func(s *grpc.Server) {
+		s.RegisterService(&grpc.ServiceDesc{
+			ServiceName: "grpctest.TestService",
+			HandlerType: (*any)(nil),
+			Methods: []grpc.MethodDesc{{
+				MethodName: "UnaryEcho",
+				Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					if err := dec(&pb.ItemRequest{}); err != nil {
+						return nil, err
+					}
+					return wrapperspb.Bytes([]byte{0xff}), nil
+				},
+			}},
+		}, struct{}{})
+	}))
+
+	_, _, _, err := NewInvoker(srv.Conn, testLogger).InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+	invErr := requireInvocationError(t, err, "UnaryEcho", PhaseMarshal, codes.Internal)
+	assert.True(t, strings.HasPrefix(invErr.Error(), "failed to format response"), invErr.Error())
+}
+
+func TestInvocationError_Start(t *testing.T) {
+	// Nothing listens where the server was
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = NewInvoker(conn, testLogger).InvokeBidiStream(context.Background(), testMethod(t, "BidiEcho"), nil)
+	requireInvocationError(t, err, "BidiEcho", PhaseStart, codes.Unavailable)
+}
+
+func TestInvocationError_Send(t *testing.T) {
+	invoker := startFailingServer(t, grpctest.WithDialOptions(grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(16))))
+
+	handle, err := invoker.InvokeClientStream(context.Background(), testMethod(t, "CollectItems"), nil)
+	require.NoError(t, err)
+	err = handle.Send(`{"item": {"name": "` + strings.Repeat("x", 64) + `"}}`)
+	requireInvocationError(t, err, "CollectItems", PhaseSend, codes.ResourceExhausted)
+}
+
+func TestInvocationError_Recv(t *testing.T) {
+	invoker := startFailingServer(t)
+
+	t.Run("unary", func(t *testing.T) {
+		_, _, _, err := invoker.InvokeUnary(context.Background(), testMethod(t, "UnaryEcho"), `{}`, nil)
+		invErr := requireInvocationError(t, err, "UnaryEcho", PhaseRecv, codes.InvalidArgument)
+		assert.Equal(t, "id is required", invErr.Message)
+		require.Len(t, invErr.Details, 1)
+		violations := invErr.Details[0].(*errdetails.BadRequest).GetFieldViolations()
+		assert.Equal(t, "item.id", violations[0].GetField())
+	})
+
+	t.Run("server stream", func(t *testing.T) {
+		msgs, errs, _, _ := invoker.InvokeServerStream(context.Background(), testMethod(t, "StreamItems"), `{"item": {"id": "1"}}`, nil)
+		for range msgs {
+		}
+		invErr := requireInvocationError(t, <-errs, "StreamItems", PhaseRecv, codes.Unavailable)
+		assert.Equal(t, "server going away", invErr.Message)
+	})
+
+	t.Run("bidi", func(t *testing.T) {
+		handle, err := invoker.InvokeBidiStream(context.Background(), testMethod(t, "BidiEcho"), nil)
+		require.NoError(t, err)
+		require.NoError(t, handle.Send(`{"item": {"id": "1"}}`))
+		_, err = handle.Recv()
+		require.NoError(t, err)
+		_, err = handle.Recv()
+		requireInvocationError(t, err, "BidiEcho", PhaseRecv, codes.DataLoss)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		handle, err := invoker.InvokeBidiStream(ctx, testMethod(t, "BidiEcho"), nil)
+		require.NoError(t, err)
+		cancel()
+		_, err = handle.Recv()
+		requireInvocationError(t, err, "BidiEcho", PhaseRecv, codes.Canceled)
+	})
+}
+
+func TestInvocationError_Close(t *testing.T) {
+	invoker := startFailingServer(t)
+
+	handle, err := invoker.InvokeClientStream(context.Background(), testMethod(t, "CollectItems"), nil)
+	require.NoError(t, err)
+	require.NoError(t, handle.Send(`{"item": {"id": "1"}}`))
+	_, err = handle.CloseAndReceive()
+	invErr := requireInvocationError(t, err, "CollectItems", PhaseClose, codes.FailedPrecondition)
+	assert.Equal(t, "batch is closed", invErr.Message)
+}
+
+func TestInvocationError_EOFPassesThrough(t *testing.T) {
+	assert.NoError(t, newInvocationError("m", PhaseRecv, nil))
+	assert.Equal(t, io.EOF, newInvocationError("m", PhaseRecv, io.EOF))
+
+	// Already typed errors keep their phase
+	first := newInvocationError("m", PhaseSend, status.Error(codes.Aborted, "x"))
+	assert.Same(t, first, newInvocationError("m", PhaseRecv, first))
+}
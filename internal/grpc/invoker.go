@@ -6,13 +6,13 @@ import (
 	"io"
 	"log/slog"
 	"strconv"
+	"sync/atomic"
 
-	"github.com/jhump/protoreflect/v2/grpcdynamic"
+	"github.com/shhac/grotto/internal/protoconv"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/reflect/protoreflect"
-	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 const maxLogBodyLen = 512
@@ -26,22 +26,61 @@ func truncateForLog(s string) string {
 }
 
 // Invoker handles dynamic gRPC invocations using reflection-based message types.
-// It supports unary and streaming RPC patterns without requiring generated code.
+// It supports unary and streaming RPC patterns without requiring generated code:
+// messages are built with dynamicpb from the method's descriptors and sent
+// pre-encoded through rawCodec. Calls and stream handles fail with an
+// *InvocationError, except for io.EOF at the end of a stream.
 type Invoker struct {
-	conn   *grpc.ClientConn
-	logger *slog.Logger
-	stub   *grpcdynamic.Stub
+	conn       grpc.ClientConnInterface
+	logger     *slog.Logger
+	compressor string // request compression, "" for none
+	types      *protoconv.TypeResolver
+	defaults   metadata.MD // sent with every call unless the call sets the key
+	retry      retryPolicy // unary calls only; the zero value makes one attempt
 }
 
 // NewInvoker creates a new dynamic gRPC invoker for the given connection.
-func NewInvoker(conn *grpc.ClientConn, logger *slog.Logger) *Invoker {
+// The connection may be a native *grpc.ClientConn or a gRPC-Web *WebConn.
+func NewInvoker(conn grpc.ClientConnInterface, logger *slog.Logger) *Invoker {
 	return &Invoker{
 		conn:   conn,
 		logger: logger,
-		stub:   grpcdynamic.NewStub(conn),
 	}
 }
 
+// SetTypeResolver sets the types used to pack and expand google.protobuf.Any
+// values in requests and responses. Set it before invoking.
+func (i *Invoker) SetTypeResolver(types *protoconv.TypeResolver) {
+	i.types = types
+}
+
+// TypeResolver returns the types set with SetTypeResolver, or nil.
+func (i *Invoker) TypeResolver() *protoconv.TypeResolver {
+	return i.types
+}
+
+// callOptions returns opts plus the passthrough codec and the configured
+// compressor, if any.
+func (i *Invoker) callOptions(opts ...grpc.CallOption) []grpc.CallOption {
+	opts = append(opts, rawCodecOption)
+	if i.compressor != "" {
+		opts = append(opts, grpc.UseCompressor(i.compressor))
+	}
+	return opts
+}
+
+// newStream starts a stream for methodDesc. Calling the returned cancel
+// releases the stream's context once the call is over.
+func (i *Invoker) newStream(ctx context.Context, methodDesc protoreflect.MethodDescriptor) (grpc.ClientStream, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := i.conn.NewStream(ctx, streamDesc(methodDesc), methodPath(methodDesc), i.callOptions()...)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return stream, cancel, nil
+}
+
 // InvokeUnary calls a unary RPC method dynamically.
 //
 // Parameters:
@@ -54,11 +93,25 @@ func NewInvoker(conn *grpc.ClientConn, logger *slog.Logger) *Invoker {
 //   - responseHeaders: gRPC metadata (headers) received from the server
 //   - responseTrailers: gRPC metadata (trailers) received from the server
 //   - err: Error if invocation fails or JSON marshaling fails
+//
+// Calls failing with a retryable status are retried as SetRetryPolicy
+// says, and the result is the last attempt's; WithCallAttempts reports how
+// many were made.
 func (i *Invoker) InvokeUnary(
 	ctx context.Context,
 	methodDesc protoreflect.MethodDescriptor,
 	jsonRequest string,
 	md metadata.MD,
+) (jsonResponse string, responseHeaders metadata.MD, responseTrailers metadata.MD, err error) {
+	return i.invokeUnaryWithRetry(ctx, methodDesc, jsonRequest, md)
+}
+
+// invokeUnary makes a single attempt at a unary call.
+func (i *Invoker) invokeUnary(
+	ctx context.Context,
+	methodDesc protoreflect.MethodDescriptor,
+	jsonRequest string,
+	md metadata.MD,
 ) (jsonResponse string, responseHeaders metadata.MD, responseTrailers metadata.MD, err error) {
 	methodName := string(methodDesc.FullName())
 	i.logger.Debug("invoking unary RPC",
@@ -66,16 +119,14 @@ func (i *Invoker) InvokeUnary(
 		slog.String("request", truncateForLog(jsonRequest)),
 	)
 
-	// Create dynamic request message from method descriptor
-	reqMsg := dynamicpb.NewMessage(methodDesc.Input())
-
-	// Unmarshal JSON into dynamic message
-	if err := protojson.Unmarshal([]byte(jsonRequest), reqMsg); err != nil {
-		i.logger.Error("failed to unmarshal request JSON",
+	// Encode the JSON request against the method's input descriptor
+	reqFrame, err := encodeJSON(methodDesc.Input(), jsonRequest, i.types)
+	if err != nil {
+		i.logger.Error("failed to encode request JSON",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", nil, nil, fmt.Errorf("invalid request JSON: %w", err)
+		return "", nil, nil, marshalError(methodName, codes.InvalidArgument, err)
 	}
 
 	// Prepare call options to capture response headers and trailers
@@ -86,37 +137,38 @@ func (i *Invoker) InvokeUnary(
 		grpc.Trailer(&respTrailers),
 	}
 
-	// Add request metadata if provided
-	if len(md) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, md)
-	}
+	// Add request metadata, over the connection's defaults
+	ctx = i.outgoingContext(ctx, md)
+	ctx, enc := withEncodingRecorder(ctx)
 
-	// Invoke the RPC using dynamic stub
-	respMsg, err := i.stub.InvokeRpc(ctx, methodDesc, reqMsg, callOpts...)
+	// Invoke the RPC with pre-encoded frames
+	var respFrame rawFrame
+	err = i.conn.Invoke(ctx, methodPath(methodDesc), &reqFrame, &respFrame, i.callOptions(callOpts...)...)
+	respHeaders = enc.addTo(respHeaders)
 	if err != nil {
 		i.logger.Error("RPC invocation failed",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", respHeaders, respTrailers, err
+		return "", respHeaders, respTrailers, newInvocationError(methodName, PhaseRecv, err)
 	}
 
-	// Marshal response to JSON
-	jsonBytes, err := protojson.Marshal(respMsg)
+	// Decode response and format as JSON
+	jsonResponse, err = decodeJSON(methodDesc.Output(), respFrame, i.types)
 	if err != nil {
-		i.logger.Error("failed to marshal response to JSON",
+		i.logger.Error("failed to format response as JSON",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", respHeaders, respTrailers, fmt.Errorf("failed to format response: %w", err)
+		return "", respHeaders, respTrailers, marshalError(methodName, codes.Internal, fmt.Errorf("failed to format response: %w", err))
 	}
 
 	i.logger.Debug("unary RPC completed",
 		slog.String("method", methodName),
-		slog.String("response", truncateForLog(string(jsonBytes))),
+		slog.String("response", truncateForLog(jsonResponse)),
 	)
 
-	return string(jsonBytes), respHeaders, respTrailers, nil
+	return jsonResponse, respHeaders, respTrailers, nil
 }
 
 // InvokeServerStream calls a server streaming RPC method dynamically.
@@ -129,7 +181,9 @@ func (i *Invoker) InvokeUnary(
 // Returns:
 //   - msgChan: Channel that receives JSON-formatted response messages
 //   - errChan: Channel that receives errors (including io.EOF when stream completes)
-//   - headerChan: Channel that receives response headers (sent once before messages)
+//   - headerChan: Channel that receives response headers once, as soon as the
+//     server sends them; a long-lived stream may send them well before its
+//     first message
 //   - trailerChan: Channel that receives response trailers (sent once after stream ends)
 //
 // The caller should read from both channels until errChan receives io.EOF (normal completion)
@@ -157,26 +211,33 @@ func (i *Invoker) InvokeServerStream(
 		defer close(headerChan)
 		defer close(trailerChan)
 
-		// Create dynamic request message
-		reqMsg := dynamicpb.NewMessage(methodDesc.Input())
-
-		// Unmarshal JSON into dynamic message
-		if err := protojson.Unmarshal([]byte(jsonRequest), reqMsg); err != nil {
-			i.logger.Error("failed to unmarshal request JSON",
+		// Encode the JSON request against the method's input descriptor
+		reqFrame, err := encodeJSON(methodDesc.Input(), jsonRequest, i.types)
+		if err != nil {
+			i.logger.Error("failed to encode request JSON",
 				slog.String("method", methodName),
 				slog.Any("error", err),
 			)
-			errChan <- fmt.Errorf("invalid request JSON: %w", err)
+			errChan <- marshalError(methodName, codes.InvalidArgument, err)
 			return
 		}
 
-		// Add request metadata if provided
-		if len(md) > 0 {
-			ctx = metadata.NewOutgoingContext(ctx, md)
-		}
+		// Add request metadata, over the connection's defaults
+		ctx = i.outgoingContext(ctx, md)
 
-		// Invoke the server streaming RPC
-		stream, err := i.stub.InvokeRpcServerStream(ctx, methodDesc, reqMsg)
+		// Start the server streaming RPC and send the single request
+		stream, cancel, err := i.newStream(ctx, methodDesc)
+		if err != nil {
+			err = newInvocationError(methodName, PhaseStart, err)
+		} else {
+			defer cancel()
+			if err = stream.SendMsg(&reqFrame); err == nil || err == io.EOF {
+				// io.EOF from SendMsg means the stream failed; RecvMsg reports why
+				err = newInvocationError(methodName, PhaseClose, stream.CloseSend())
+			} else {
+				err = newInvocationError(methodName, PhaseSend, err)
+			}
+		}
 		if err != nil {
 			i.logger.Error("failed to start server stream",
 				slog.String("method", methodName),
@@ -186,7 +247,8 @@ func (i *Invoker) InvokeServerStream(
 			return
 		}
 
-		// Capture response headers (available once stream is established)
+		// Deliver response headers as soon as the server sends them, before
+		// waiting for the first message
 		if hdr, err := stream.Header(); err == nil {
 			headerChan <- hdr
 		}
@@ -201,7 +263,8 @@ func (i *Invoker) InvokeServerStream(
 		// Receive messages from stream
 		messageCount := 0
 		for {
-			respMsg, err := stream.RecvMsg()
+			var respFrame rawFrame
+			err := stream.RecvMsg(&respFrame)
 			if err == io.EOF {
 				i.logger.Debug("server stream completed",
 					slog.String("method", methodName),
@@ -216,18 +279,18 @@ func (i *Invoker) InvokeServerStream(
 					slog.Int("message_count", messageCount),
 					slog.Any("error", err),
 				)
-				sendTrailersAndError(err)
+				sendTrailersAndError(newInvocationError(methodName, PhaseRecv, err))
 				return
 			}
 
-			// Marshal message to JSON
-			jsonBytes, err := protojson.Marshal(respMsg)
+			// Decode message and format as JSON
+			jsonMsg, err := decodeJSON(methodDesc.Output(), respFrame, i.types)
 			if err != nil {
-				i.logger.Error("failed to marshal stream message to JSON",
+				i.logger.Error("failed to format stream message as JSON",
 					slog.String("method", methodName),
 					slog.Any("error", err),
 				)
-				sendTrailersAndError(fmt.Errorf("failed to format stream message: %w", err))
+				sendTrailersAndError(marshalError(methodName, codes.Internal, fmt.Errorf("failed to format stream message: %w", err)))
 				return
 			}
 
@@ -239,13 +302,13 @@ func (i *Invoker) InvokeServerStream(
 
 			// Send JSON message to channel
 			select {
-			case msgChan <- string(jsonBytes):
+			case msgChan <- jsonMsg:
 			case <-ctx.Done():
 				i.logger.Info("server stream cancelled by context",
 					slog.String("method", methodName),
 					slog.Int("message_count", messageCount),
 				)
-				sendTrailersAndError(ctx.Err())
+				sendTrailersAndError(newInvocationError(methodName, PhaseRecv, ctx.Err()))
 				return
 			}
 		}
@@ -257,18 +320,36 @@ func (i *Invoker) InvokeServerStream(
 // ClientStreamHandle represents an active client streaming RPC session.
 // It provides methods to send messages and close the stream to receive the final response.
 type ClientStreamHandle struct {
-	stream     *grpcdynamic.ClientStream
+	stream     grpc.ClientStream
+	cancel     context.CancelFunc // releases the stream's context
 	methodDesc protoreflect.MethodDescriptor
+	types      *protoconv.TypeResolver
 	logger     *slog.Logger
+	encoding   *encodingRecorder
+
+	sentBytes atomic.Int64 // encoded size of the messages sent so far
 }
 
-// Header returns the response headers from the server.
+// Method returns the descriptor of the method being called.
+func (h *ClientStreamHandle) Method() protoreflect.MethodDescriptor {
+	return h.methodDesc
+}
+
+// SentSize returns the total encoded size of the messages sent so far.
+func (h *ClientStreamHandle) SentSize() int {
+	return int(h.sentBytes.Load())
+}
+
+// Header returns the response headers from the server. Once the response
+// has been received they include EncodingHeader if it was compressed.
 func (h *ClientStreamHandle) Header() (metadata.MD, error) {
-	return h.stream.Header()
+	md, err := h.stream.Header()
+	return h.encoding.addTo(md), err
 }
 
-// Trailer returns the response trailers from the server (available after stream ends).
-func (h *ClientStreamHandle) Trailer() metadata.MD {
+// Trailers returns the response trailers from the server. They are only
+// available once the stream has finished; before that the result is empty.
+func (h *ClientStreamHandle) Trailers() metadata.MD {
 	return h.stream.Trailer()
 }
 
@@ -281,26 +362,25 @@ func (h *ClientStreamHandle) Send(jsonRequest string) error {
 		slog.String("request", truncateForLog(jsonRequest)),
 	)
 
-	// Create dynamic request message
-	reqMsg := dynamicpb.NewMessage(h.methodDesc.Input())
-
-	// Unmarshal JSON into dynamic message
-	if err := protojson.Unmarshal([]byte(jsonRequest), reqMsg); err != nil {
-		h.logger.Error("failed to unmarshal request JSON",
+	// Encode the JSON message against the method's input descriptor
+	reqFrame, err := encodeJSON(h.methodDesc.Input(), jsonRequest, h.types)
+	if err != nil {
+		h.logger.Error("failed to encode request JSON",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return fmt.Errorf("invalid request JSON: %w", err)
+		return marshalError(methodName, codes.InvalidArgument, err)
 	}
 
 	// Send message on stream
-	if err := h.stream.SendMsg(reqMsg); err != nil {
+	if err := h.stream.SendMsg(&reqFrame); err != nil {
 		h.logger.Error("failed to send client stream message",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return err
+		return newInvocationError(methodName, PhaseSend, err)
 	}
+	h.sentBytes.Add(int64(len(reqFrame)))
 
 	h.logger.Debug("client stream message sent",
 		slog.String("method", methodName),
@@ -317,32 +397,38 @@ func (h *ClientStreamHandle) CloseAndReceive() (string, error) {
 		slog.String("method", methodName),
 	)
 
-	// Close send side and receive final response
-	respMsg, err := h.stream.CloseAndReceive()
+	// Close send side and receive final response. For client streaming
+	// calls RecvMsg also checks that the server sent exactly one message.
+	defer h.cancel()
+	var respFrame rawFrame
+	err := h.stream.CloseSend()
+	if err == nil {
+		err = h.stream.RecvMsg(&respFrame)
+	}
 	if err != nil {
 		h.logger.Error("failed to close and receive client stream response",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", err
+		return "", newInvocationError(methodName, PhaseClose, err)
 	}
 
-	// Marshal response to JSON
-	jsonBytes, err := protojson.Marshal(respMsg)
+	// Decode response and format as JSON
+	jsonResponse, err := decodeJSON(h.methodDesc.Output(), respFrame, h.types)
 	if err != nil {
-		h.logger.Error("failed to marshal response to JSON",
+		h.logger.Error("failed to format response as JSON",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", fmt.Errorf("failed to format response: %w", err)
+		return "", marshalError(methodName, codes.Internal, fmt.Errorf("failed to format response: %w", err))
 	}
 
 	h.logger.Debug("client stream completed",
 		slog.String("method", methodName),
-		slog.String("response", truncateForLog(string(jsonBytes))),
+		slog.String("response", truncateForLog(jsonResponse)),
 	)
 
-	return string(jsonBytes), nil
+	return jsonResponse, nil
 }
 
 // InvokeClientStream starts a client streaming RPC and returns a handle for sending messages.
@@ -376,19 +462,19 @@ func (i *Invoker) InvokeClientStream(
 		slog.String("method", methodName),
 	)
 
-	// Add request metadata if provided
-	if len(md) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, md)
-	}
+	// Add request metadata, over the connection's defaults
+	ctx = i.outgoingContext(ctx, md)
+
+	ctx, enc := withEncodingRecorder(ctx)
 
-	// Invoke the client streaming RPC
-	stream, err := i.stub.InvokeRpcClientStream(ctx, methodDesc)
+	// Start the client streaming RPC
+	stream, cancel, err := i.newStream(ctx, methodDesc)
 	if err != nil {
 		i.logger.Error("failed to start client stream",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return nil, err
+		return nil, newInvocationError(methodName, PhaseStart, err)
 	}
 
 	i.logger.Debug("client stream started",
@@ -397,17 +483,30 @@ func (i *Invoker) InvokeClientStream(
 
 	return &ClientStreamHandle{
 		stream:     stream,
+		cancel:     cancel,
 		methodDesc: methodDesc,
+		types:      i.types,
 		logger:     i.logger,
+		encoding:   enc,
 	}, nil
 }
 
 // BidiStreamHandle represents an active bidirectional streaming RPC session.
 // It provides methods to send messages, receive messages, and close the send side.
 type BidiStreamHandle struct {
-	stream     *grpcdynamic.BidiStream
+	stream     grpc.ClientStream
+	cancel     context.CancelFunc // releases the stream's context
 	methodDesc protoreflect.MethodDescriptor
+	types      *protoconv.TypeResolver
 	logger     *slog.Logger
+	headers    chan metadata.MD // receives the response headers, then closes
+}
+
+// Headers returns a channel that receives the response headers once, as
+// soon as the server sends them, which may be well before its first
+// message. It is closed afterwards, or when the stream ends without any.
+func (h *BidiStreamHandle) Headers() <-chan metadata.MD {
+	return h.headers
 }
 
 // Header returns the response headers from the server.
@@ -415,8 +514,9 @@ func (h *BidiStreamHandle) Header() (metadata.MD, error) {
 	return h.stream.Header()
 }
 
-// Trailer returns the response trailers from the server (available after stream ends).
-func (h *BidiStreamHandle) Trailer() metadata.MD {
+// Trailers returns the response trailers from the server. They are only
+// available once the stream has finished; before that the result is empty.
+func (h *BidiStreamHandle) Trailers() metadata.MD {
 	return h.stream.Trailer()
 }
 
@@ -429,25 +529,23 @@ func (h *BidiStreamHandle) Send(jsonRequest string) error {
 		slog.String("request", truncateForLog(jsonRequest)),
 	)
 
-	// Create dynamic request message
-	reqMsg := dynamicpb.NewMessage(h.methodDesc.Input())
-
-	// Unmarshal JSON into dynamic message
-	if err := protojson.Unmarshal([]byte(jsonRequest), reqMsg); err != nil {
-		h.logger.Error("failed to unmarshal request JSON",
+	// Encode the JSON message against the method's input descriptor
+	reqFrame, err := encodeJSON(h.methodDesc.Input(), jsonRequest, h.types)
+	if err != nil {
+		h.logger.Error("failed to encode request JSON",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return fmt.Errorf("invalid request JSON: %w", err)
+		return marshalError(methodName, codes.InvalidArgument, err)
 	}
 
 	// Send message on stream
-	if err := h.stream.SendMsg(reqMsg); err != nil {
+	if err := h.stream.SendMsg(&reqFrame); err != nil {
 		h.logger.Error("failed to send bidi stream message",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return err
+		return newInvocationError(methodName, PhaseSend, err)
 	}
 
 	h.logger.Debug("bidi stream message sent",
@@ -462,11 +560,13 @@ func (h *BidiStreamHandle) Send(jsonRequest string) error {
 func (h *BidiStreamHandle) Recv() (string, error) {
 	methodName := string(h.methodDesc.FullName())
 
-	respMsg, err := h.stream.RecvMsg()
+	var respFrame rawFrame
+	err := h.stream.RecvMsg(&respFrame)
 	if err == io.EOF {
 		h.logger.Debug("bidi stream receive completed",
 			slog.String("method", methodName),
 		)
+		h.cancel()
 		return "", io.EOF
 	}
 	if err != nil {
@@ -474,24 +574,25 @@ func (h *BidiStreamHandle) Recv() (string, error) {
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", err
+		h.cancel()
+		return "", newInvocationError(methodName, PhaseRecv, err)
 	}
 
-	// Marshal message to JSON
-	jsonBytes, err := protojson.Marshal(respMsg)
+	// Decode message and format as JSON
+	jsonMsg, err := decodeJSON(h.methodDesc.Output(), respFrame, h.types)
 	if err != nil {
-		h.logger.Error("failed to marshal bidi stream message to JSON",
+		h.logger.Error("failed to format bidi stream message as JSON",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return "", fmt.Errorf("failed to format stream message: %w", err)
+		return "", marshalError(methodName, codes.Internal, fmt.Errorf("failed to format stream message: %w", err))
 	}
 
 	h.logger.Debug("received bidi stream message",
 		slog.String("method", methodName),
 	)
 
-	return string(jsonBytes), nil
+	return jsonMsg, nil
 }
 
 // CloseSend closes the send side of the bidirectional stream.
@@ -507,7 +608,7 @@ func (h *BidiStreamHandle) CloseSend() error {
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return err
+		return newInvocationError(methodName, PhaseClose, err)
 	}
 
 	h.logger.Debug("bidi stream send side closed",
@@ -558,28 +659,38 @@ func (i *Invoker) InvokeBidiStream(
 		slog.String("method", methodName),
 	)
 
-	// Add request metadata if provided
-	if len(md) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, md)
-	}
+	// Add request metadata, over the connection's defaults
+	ctx = i.outgoingContext(ctx, md)
 
-	// Invoke the bidirectional streaming RPC
-	stream, err := i.stub.InvokeRpcBidiStream(ctx, methodDesc)
+	// Start the bidirectional streaming RPC
+	stream, cancel, err := i.newStream(ctx, methodDesc)
 	if err != nil {
 		i.logger.Error("failed to start bidi stream",
 			slog.String("method", methodName),
 			slog.Any("error", err),
 		)
-		return nil, err
+		return nil, newInvocationError(methodName, PhaseStart, err)
 	}
 
 	i.logger.Debug("bidi stream started",
 		slog.String("method", methodName),
 	)
 
+	// Wait for the response headers alongside sending and receiving
+	headers := make(chan metadata.MD, 1)
+	go func() {
+		defer close(headers)
+		if hdr, err := stream.Header(); err == nil && hdr != nil {
+			headers <- hdr
+		}
+	}()
+
 	return &BidiStreamHandle{
 		stream:     stream,
+		cancel:     cancel,
 		methodDesc: methodDesc,
+		types:      i.types,
 		logger:     i.logger,
+		headers:    headers,
 	}, nil
 }
@@ -0,0 +1,23 @@
+package grpc
+
+import (
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// keepaliveOptions returns the dial option sending the keepalive pings of
+// cfg, or nil when it sets none. Pings are off by default: a server whose
+// enforcement policy allows fewer pings answers with GOAWAY too_many_pings
+// and fails the calls in progress.
+func keepaliveOptions(cfg domain.Connection) []grpc.DialOption {
+	ka := cfg.KeepaliveParams
+	if !ka.Enabled() {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                ka.Time,
+		Timeout:             ka.Timeout,
+		PermitWithoutStream: ka.PermitWithoutStream,
+	})}
+}
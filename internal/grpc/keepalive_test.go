@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	apperrors "github.com/shhac/grotto/internal/errors"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeepaliveOptions(t *testing.T) {
+	assert.Nil(t, keepaliveOptions(domain.Connection{}), "no pings by default")
+	assert.Nil(t, keepaliveOptions(domain.Connection{KeepaliveParams: domain.KeepaliveParams{PermitWithoutStream: true}}))
+	assert.Len(t, keepaliveOptions(domain.Connection{KeepaliveParams: domain.KeepaliveParams{Time: time.Minute}}), 1)
+}
+
+// TestConnect_KeepaliveEnforcement holds a call open long enough for
+// several pings against a server allowing one every 15s. gRPC will not
+// ping more often than every 10s and servers allow two bad pings, so the
+// rejection takes about 40s.
+func TestConnect_KeepaliveEnforcement(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for keepalive pings")
+	}
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithLatency(45*time.Second),
+		grpctest.WithServerOptions(grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime: 15 * time.Second,
+		})),
+	)
+	md := testMethod(t, "UnaryEcho")
+
+	invoke := func(ka domain.KeepaliveParams) <-chan error {
+		m := NewConnectionManager(testLogger)
+		require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: srv.Addr, KeepaliveParams: ka}))
+		done := make(chan error, 1)
+		go func() {
+			defer func() { _ = m.Disconnect() }()
+			_, _, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), md, `{}`, nil)
+			done <- err
+		}()
+		return done
+	}
+
+	// Both calls run at once to share the wait
+	accepted := invoke(domain.KeepaliveParams{Time: 20 * time.Second})
+	rejected := invoke(domain.KeepaliveParams{Time: domain.MinKeepaliveTime})
+
+	err := <-rejected
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "too_many_pings")
+	assert.Equal(t, "Keepalive Pings Rejected", apperrors.ClassifyGRPCError(err).Title)
+
+	assert.NoError(t, <-accepted)
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// binaryHeaderSuffix marks metadata keys whose values are binary. gRPC
+// base64-encodes these on the wire; in metadata.MD they hold raw bytes.
+const binaryHeaderSuffix = "-bin"
+
+// IsBinaryHeader reports whether key names a binary metadata header.
+func IsBinaryHeader(key string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(key)), binaryHeaderSuffix)
+}
+
+// DecodeBinaryHeader decodes a binary header value entered as standard
+// base64, padded or unpadded (raw).
+func DecodeBinaryHeader(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if data, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return data, nil
+	}
+	data, err := base64.RawStdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("binary header values must be base64")
+	}
+	return data, nil
+}
+
+// BuildMetadata converts header name/value pairs from the UI into outgoing
+// metadata. Values of -bin headers are decoded from base64 so gRPC sends the
+// original bytes rather than re-encoding the text.
+func BuildMetadata(headers map[string]string) (metadata.MD, error) {
+	md := metadata.MD{}
+	for key, value := range headers {
+		if IsBinaryHeader(key) {
+			data, err := DecodeBinaryHeader(value)
+			if err != nil {
+				return nil, fmt.Errorf("header %q: %w", key, err)
+			}
+			value = string(data)
+		}
+		md.Append(key, value)
+	}
+	return md, nil
+}
+
+// FormatMetadataValue returns a received metadata value for display,
+// base64-encoding the raw bytes of -bin headers.
+func FormatMetadataValue(key, value string) string {
+	if IsBinaryHeader(key) {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
+
+// SetDefaultMetadata sets metadata sent with every call on every invocation
+// path, e.g. a tenant id or API key set on the connection. A call's own
+// metadata replaces a default with the same key. Set it before invoking.
+func (i *Invoker) SetDefaultMetadata(md metadata.MD) {
+	i.defaults = md
+}
+
+// DefaultMetadata returns the metadata set with SetDefaultMetadata.
+func (i *Invoker) DefaultMetadata() metadata.MD {
+	return i.defaults
+}
+
+// outgoingContext returns ctx carrying md merged over the default metadata.
+func (i *Invoker) outgoingContext(ctx context.Context, md metadata.MD) context.Context {
+	if merged := MergeMetadata(i.defaults, md); len(merged) > 0 {
+		return metadata.NewOutgoingContext(ctx, merged)
+	}
+	return ctx
+}
+
+// MergeMetadata returns defaults with md on top: every value in md is kept,
+// and a default is kept only if md has no value for its key. Keys are
+// compared case-insensitively, as gRPC does. Neither argument is modified.
+func MergeMetadata(defaults, md metadata.MD) metadata.MD {
+	if len(defaults) == 0 {
+		return md
+	}
+	merged := metadata.MD{}
+	for key, values := range defaults {
+		merged.Append(key, values...)
+	}
+	for key, values := range md {
+		merged.Set(key, values...)
+	}
+	return merged
+}
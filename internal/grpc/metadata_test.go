@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBinaryHeader(t *testing.T) {
+	assert.True(t, IsBinaryHeader("x-trace-bin"))
+	assert.True(t, IsBinaryHeader("X-Trace-BIN"))
+	assert.False(t, IsBinaryHeader("x-trace"))
+	assert.False(t, IsBinaryHeader("binary"))
+}
+
+func TestDecodeBinaryHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []byte
+		wantErr bool
+	}{
+		{"padded", "AAH/", []byte{0x00, 0x01, 0xff}, false},
+		{"padded with equals", "AAE=", []byte{0x00, 0x01}, false},
+		{"raw unpadded", "AAE", []byte{0x00, 0x01}, false},
+		{"surrounding whitespace", " AAE= ", []byte{0x00, 0x01}, false},
+		{"empty", "", []byte{}, false},
+		{"not base64", "hello world!", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeBinaryHeader(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildMetadata(t *testing.T) {
+	md, err := BuildMetadata(map[string]string{
+		"Authorization": "Bearer abc",
+		"x-trace-bin":   "AAH/",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer abc"}, md.Get("authorization"))
+	assert.Equal(t, []string{"\x00\x01\xff"}, md.Get("x-trace-bin"))
+
+	_, err = BuildMetadata(map[string]string{"x-trace-bin": "not base64!"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"x-trace-bin"`)
+}
+
+func TestFormatMetadataValue(t *testing.T) {
+	assert.Equal(t, "AAH/", FormatMetadataValue("x-trace-bin", "\x00\x01\xff"))
+	assert.Equal(t, "text", FormatMetadataValue("x-trace", "text"))
+}
+
+func TestInvokeUnary_BinaryMetadataEcho(t *testing.T) {
+	inv := NewInvoker(testConn, testLogger)
+	rc := NewReflectionClient(testConn, testLogger)
+	defer rc.Close()
+
+	method, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+
+	md, err := BuildMetadata(map[string]string{"x-trace-bin": "AAH/gA"})
+	require.NoError(t, err)
+
+	_, headers, trailers, err := inv.InvokeUnary(context.Background(), method, `{}`, md)
+	require.NoError(t, err)
+
+	// The server received the decoded bytes, not the base64 text
+	require.Equal(t, []string{"\x00\x01\xff\x80"}, headers.Get("x-trace-bin"))
+	require.Equal(t, []string{"\x00\x01\xff\x80"}, trailers.Get("x-trace-bin"))
+	assert.Equal(t, "AAH/gA==", FormatMetadataValue("x-trace-bin", headers.Get("x-trace-bin")[0]))
+}
+
+func TestInvokeServerStream_BinaryMetadataEcho(t *testing.T) {
+	inv := NewInvoker(testConn, testLogger)
+	rc := NewReflectionClient(testConn, testLogger)
+	defer rc.Close()
+
+	method, err := rc.GetMethodDescriptor("grpctest.TestService", "StreamItems")
+	require.NoError(t, err)
+
+	md, err := BuildMetadata(map[string]string{"x-trace-bin": "3q2+7w=="})
+	require.NoError(t, err)
+
+	msgChan, errChan, headerChan, trailerChan := inv.InvokeServerStream(context.Background(), method, `{}`, md)
+	for range msgChan {
+	}
+	assert.Equal(t, io.EOF, <-errChan)
+
+	headers := <-headerChan
+	trailers := <-trailerChan
+	assert.Equal(t, []string{"\xde\xad\xbe\xef"}, headers.Get("x-trace-bin"))
+	assert.Equal(t, []string{"\xde\xad\xbe\xef"}, trailers.Get("x-trace-bin"))
+}
+
+func TestMergeMetadata(t *testing.T) {
+	defaults, err := BuildMetadata(map[string]string{
+		"X-Tenant":    "acme",
+		"x-api-key":   "default-key",
+		"x-trace-bin": "AAE=",
+	})
+	require.NoError(t, err)
+	md, err := BuildMetadata(map[string]string{
+		"X-API-Key":   "call-key",
+		"x-trace-bin": "AAH/",
+		"x-request":   "1",
+	})
+	require.NoError(t, err)
+
+	merged := MergeMetadata(defaults, md)
+	assert.Equal(t, []string{"acme"}, merged.Get("x-tenant"), "defaults fill keys the call does not set")
+	assert.Equal(t, []string{"call-key"}, merged.Get("x-api-key"), "the call wins whatever the case")
+	assert.Equal(t, []string{"\x00\x01\xff"}, merged.Get("x-trace-bin"), "-bin values stay decoded and the call wins")
+	assert.Equal(t, []string{"1"}, merged.Get("x-request"))
+	assert.Len(t, merged, 4)
+
+	// Neither input is modified
+	assert.Equal(t, []string{"default-key"}, defaults.Get("x-api-key"))
+	assert.Len(t, md, 3)
+
+	assert.Equal(t, md, MergeMetadata(nil, md))
+	assert.Equal(t, []string{"\x00\x01"}, MergeMetadata(defaults, nil).Get("x-trace-bin"))
+}
+
+func TestInvoker_DefaultMetadataEcho(t *testing.T) {
+	inv := NewInvoker(testConn, testLogger)
+	rc := NewReflectionClient(testConn, testLogger)
+	defer rc.Close()
+
+	// The test server echoes -bin headers only
+	defaults, err := BuildMetadata(map[string]string{"x-tenant-bin": "YWNtZQ==", "x-trace-bin": "AAE="})
+	require.NoError(t, err)
+	inv.SetDefaultMetadata(defaults)
+
+	unary, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+	md, err := BuildMetadata(map[string]string{"x-trace-bin": "AAH/"})
+	require.NoError(t, err)
+
+	_, headers, _, err := inv.InvokeUnary(context.Background(), unary, `{}`, md)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme"}, headers.Get("x-tenant-bin"))
+	assert.Equal(t, []string{"\x00\x01\xff"}, headers.Get("x-trace-bin"))
+
+	// Streaming paths get the defaults too, even without call metadata
+	stream, err := rc.GetMethodDescriptor("grpctest.TestService", "StreamItems")
+	require.NoError(t, err)
+	msgChan, errChan, headerChan, _ := inv.InvokeServerStream(context.Background(), stream, `{}`, nil)
+	for range msgChan {
+	}
+	assert.Equal(t, io.EOF, <-errChan)
+	headers = <-headerChan
+	assert.Equal(t, []string{"acme"}, headers.Get("x-tenant-bin"))
+	assert.Equal(t, []string{"\x00\x01"}, headers.Get("x-trace-bin"))
+}
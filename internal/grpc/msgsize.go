@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DefaultMaxRecvMsgSize is grpc-go's limit on received messages, used when
+// a connection does not set its own. Sent messages are unlimited by default.
+const DefaultMaxRecvMsgSize = 4 << 20
+
+// messageSizeOptions returns the dial option raising or lowering the
+// message size limits of cfg, or nil when both are left at the defaults.
+func messageSizeOptions(cfg domain.Connection) []grpc.DialOption {
+	var callOpts []grpc.CallOption
+	if cfg.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if len(callOpts) == 0 {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(callOpts...)}
+}
+
+// EncodedSize returns the length of the protobuf encoding of jsonMsg, a
+// message of type desc in JSON form. This is the size counted against the
+// message size limits, before compression and framing. Any values are
+// packed with the types known to types, which may be nil.
+func EncodedSize(desc protoreflect.MessageDescriptor, jsonMsg string, types *protoconv.TypeResolver) (int, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := types.Unmarshal(protojson.UnmarshalOptions{}, []byte(jsonMsg), msg); err != nil {
+		return 0, fmt.Errorf("invalid message JSON: %w", err)
+	}
+	return proto.Size(msg), nil
+}
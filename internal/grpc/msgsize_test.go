@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEncodedSize(t *testing.T) {
+	md := testMethod(t, "UnaryEcho")
+
+	size, err := EncodedSize(md.Input(), `{"item":{"id":"a","name":"hello"}}`, nil)
+	require.NoError(t, err)
+	want := proto.Size(&pb.ItemRequest{Item: &pb.Item{Id: "a", Name: "hello"}})
+	assert.Equal(t, want, size)
+
+	size, err = EncodedSize(md.Input(), `{}`, nil)
+	require.NoError(t, err)
+	assert.Zero(t, size)
+
+	_, err = EncodedSize(md.Input(), `{"nope":1}`, nil)
+	assert.Error(t, err)
+}
+
+func TestConnect_MaxMessageSize(t *testing.T) {
+	const limit = 16 << 20
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithServerOptions(
+		grpc.MaxRecvMsgSize(limit),
+		grpc.MaxSendMsgSize(limit),
+	))
+	md := testMethod(t, "UnaryEcho")
+
+	// Over the 4 MB default, so the echoed response is too big to receive
+	req := `{"item":{"name":"` + strings.Repeat("x", 5<<20) + `"}}`
+
+	invoke := func(cfg domain.Connection) error {
+		m := NewConnectionManager(testLogger)
+		require.NoError(t, m.Connect(context.Background(), cfg))
+		defer func() { _ = m.Disconnect() }()
+		_, _, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), md, req, nil)
+		return err
+	}
+
+	err := invoke(domain.Connection{Address: srv.Addr})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	assert.NoError(t, invoke(domain.Connection{Address: srv.Addr, MaxRecvMsgSize: limit}))
+
+	err = invoke(domain.Connection{Address: srv.Addr, MaxRecvMsgSize: limit, MaxSendMsgSize: 1 << 20})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
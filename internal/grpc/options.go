@@ -0,0 +1,172 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// UnknownOption is an option whose extension type could not be resolved,
+// shown as it was found on the wire.
+type UnknownOption struct {
+	Number   protowire.Number `json:"number"`
+	WireType string           `json:"wireType"`
+	Value    interface{}      `json:"value"` // Integer for varint and fixed types, base64 otherwise
+}
+
+// DescriptorOptions returns the options set on d as a JSON-ready map keyed
+// by option name, or nil when none are set. Custom options such as
+// google.api.http appear as "[google.api.http]" when types (or the global
+// registry, if types is nil) can resolve their extension; the rest are
+// listed under "unknownFields" by number, wire type and raw value.
+func DescriptorOptions(d protoreflect.Descriptor, types protoregistry.ExtensionTypeResolver) (map[string]interface{}, error) {
+	opts := d.Options()
+	if opts == nil || !opts.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	if types == nil {
+		types = protoregistry.GlobalTypes
+	}
+
+	// Descriptors keep extensions unknown to the registry they were built
+	// with as raw bytes, so decode them again with types.
+	raw, err := proto.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("marshal options: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	resolved := opts.ProtoReflect().Type().New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(raw, resolved); err != nil {
+		return nil, fmt.Errorf("unmarshal options: %w", err)
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("marshal options as JSON: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out map[string]interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode options JSON: %w", err)
+	}
+
+	if unknown := unknownOptions(resolved.ProtoReflect().GetUnknown()); len(unknown) > 0 {
+		out["unknownFields"] = unknown
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// unknownOptions splits raw wire bytes into fields.
+func unknownOptions(b []byte) []UnknownOption {
+	var fields []UnknownOption
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fields
+		}
+		b = b[n:]
+		opt := UnknownOption{Number: num}
+		switch typ {
+		case protowire.VarintType:
+			v, m := protowire.ConsumeVarint(b)
+			opt.WireType, opt.Value, n = "varint", v, m
+		case protowire.Fixed32Type:
+			v, m := protowire.ConsumeFixed32(b)
+			opt.WireType, opt.Value, n = "fixed32", v, m
+		case protowire.Fixed64Type:
+			v, m := protowire.ConsumeFixed64(b)
+			opt.WireType, opt.Value, n = "fixed64", v, m
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(b)
+			opt.WireType, opt.Value, n = "bytes", base64.StdEncoding.EncodeToString(v), m
+		case protowire.StartGroupType:
+			v, m := protowire.ConsumeGroup(num, b)
+			opt.WireType, opt.Value, n = "group", base64.StdEncoding.EncodeToString(v), m
+		default:
+			n = -1
+		}
+		if n < 0 {
+			return fields
+		}
+		b = b[n:]
+		fields = append(fields, opt)
+	}
+	return fields
+}
+
+// MethodOptions gathers the options of a method, its service, and its
+// request and response messages and their fields, leaving out those with
+// none set. The result is keyed "service", "method" and "messages"; each
+// message has "options" and "fields" keyed by field name.
+func MethodOptions(md protoreflect.MethodDescriptor, types protoregistry.ExtensionTypeResolver) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	for key, d := range map[string]protoreflect.Descriptor{"service": md.Parent(), "method": md} {
+		opts, err := DescriptorOptions(d, types)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.FullName(), err)
+		}
+		if opts != nil {
+			out[key] = opts
+		}
+	}
+
+	messages := make(map[string]interface{})
+	for _, msg := range []protoreflect.MessageDescriptor{md.Input(), md.Output()} {
+		entry, err := messageOptions(msg, types)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			messages[string(msg.FullName())] = entry
+		}
+	}
+	if len(messages) > 0 {
+		out["messages"] = messages
+	}
+	return out, nil
+}
+
+// messageOptions returns the options of msg and its fields, or nil when
+// none are set.
+func messageOptions(msg protoreflect.MessageDescriptor, types protoregistry.ExtensionTypeResolver) (map[string]interface{}, error) {
+	entry := make(map[string]interface{})
+	opts, err := DescriptorOptions(msg, types)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", msg.FullName(), err)
+	}
+	if opts != nil {
+		entry["options"] = opts
+	}
+
+	fields := make(map[string]interface{})
+	for i := range msg.Fields().Len() {
+		fd := msg.Fields().Get(i)
+		opts, err := DescriptorOptions(fd, types)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fd.FullName(), err)
+		}
+		if opts != nil {
+			fields[string(fd.Name())] = opts
+		}
+	}
+	if len(fields) > 0 {
+		entry["fields"] = fields
+	}
+	if len(entry) == 0 {
+		return nil, nil
+	}
+	return entry, nil
+}
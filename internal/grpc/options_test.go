@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// optionsFiles compiles testdata/options, which annotates its methods with
+// google.api.http and its messages and fields with custom options.
+func optionsFiles(t *testing.T) []protoreflect.FileDescriptor {
+	t.Helper()
+	files, err := CompileProtoSources(context.Background(), []string{"../../testdata/options"})
+	require.NoError(t, err)
+	return files
+}
+
+func optionsService(t *testing.T, files []protoreflect.FileDescriptor) protoreflect.ServiceDescriptor {
+	t.Helper()
+	for _, fd := range files {
+		if sd := fd.Services().ByName("ItemService"); sd != nil {
+			return sd
+		}
+	}
+	t.Fatal("ItemService not found")
+	return nil
+}
+
+// optionsJSON renders v as compact JSON for comparison.
+func optionsJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestDescriptorOptions_Resolved(t *testing.T) {
+	files := optionsFiles(t)
+	types := protoconv.NewTypeResolver(files, nil)
+	methods := optionsService(t, files).Methods()
+
+	got, err := DescriptorOptions(methods.ByName("GetItem"), types)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"[google.api.http]": {"get": "/v1/items/{id}"}}`, optionsJSON(t, got))
+
+	got, err = DescriptorOptions(methods.ByName("UpdateItem"), types)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"idempotency_level": "IDEMPOTENT",
+		"[google.api.http]": {
+			"patch": "/v1/items/{id}",
+			"body": "*",
+			"additional_bindings": [{"put": "/v1/items/{id}", "body": "*"}]
+		}
+	}`, optionsJSON(t, got))
+
+	item := methods.ByName("GetItem").Output()
+	got, err = DescriptorOptions(item, types)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"[options.v1.table]": "items"}`, optionsJSON(t, got))
+
+	got, err = DescriptorOptions(item.Fields().ByName("id"), types)
+	require.NoError(t, err)
+	assert.Nil(t, got, "no options set")
+}
+
+func TestDescriptorOptions_Unresolved(t *testing.T) {
+	files := optionsFiles(t)
+	methods := optionsService(t, files).Methods()
+
+	// Without the files' extensions, custom options stay raw
+	got, err := DescriptorOptions(methods.ByName("GetItem"), new(protoregistry.Types))
+	require.NoError(t, err)
+	require.Contains(t, got, "unknownFields")
+	unknown := got["unknownFields"].([]UnknownOption)
+	require.Len(t, unknown, 1)
+	assert.EqualValues(t, 72295728, unknown[0].Number)
+	assert.Equal(t, "bytes", unknown[0].WireType)
+	assert.Equal(t, "Eg4vdjEvaXRlbXMve2lkfQ==", unknown[0].Value) // HttpRule{get: "/v1/items/{id}"}
+
+	got, err = DescriptorOptions(methods.ByName("GetItem").Output().Fields().ByName("owner_email"), new(protoregistry.Types))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"unknownFields": [{"number": 50002, "wireType": "varint", "value": 1}]}`, optionsJSON(t, got))
+}
+
+func TestMethodOptions(t *testing.T) {
+	files := optionsFiles(t)
+	types := protoconv.NewTypeResolver(files, nil)
+	md := optionsService(t, files).Methods().ByName("GetItem")
+
+	got, err := MethodOptions(md, types)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": {"[google.api.http]": {"get": "/v1/items/{id}"}},
+		"messages": {
+			"options.v1.Item": {
+				"options": {"[options.v1.table]": "items"},
+				"fields": {
+					"owner_email": {"[options.v1.sensitive]": true},
+					"legacy_code": {"deprecated": true}
+				}
+			}
+		}
+	}`, optionsJSON(t, got))
+}
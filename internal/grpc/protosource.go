@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/reporter"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtoSourceError is one problem found while compiling .proto sources.
+type ProtoSourceError struct {
+	File    string // path relative to its import root
+	Line    int    // 1-based; 0 when the position is unknown
+	Column  int    // 1-based; 0 when the position is unknown
+	Message string
+}
+
+// String formats the error as file:line:column: message.
+func (e ProtoSourceError) String() string {
+	if e.Line == 0 {
+		return e.File + ": " + e.Message
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ProtoCompileError reports every error found while compiling .proto
+// sources, in the order the compiler found them.
+type ProtoCompileError struct {
+	Errors []ProtoSourceError
+}
+
+// Error implements error, showing the first problem and how many follow.
+func (e *ProtoCompileError) Error() string {
+	if len(e.Errors) == 0 {
+		return "failed to compile protos"
+	}
+	msg := e.Errors[0].String()
+	if more := len(e.Errors) - 1; more > 0 {
+		msg += fmt.Sprintf(" (and %d more)", more)
+	}
+	return msg
+}
+
+// FindProtoFiles lists the .proto files under each import root, relative to
+// that root with forward slashes, as they would be named in an import.
+// Hidden directories (.git and the like) are skipped, and a file found
+// under more than one root is listed once.
+func FindProtoFiles(roots []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(path) != ".proto" {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if !seen[rel] {
+				seen[rel] = true
+				files = append(files, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .proto files found in %s", strings.Join(roots, ", "))
+	}
+	return files, nil
+}
+
+// CompileProtoSources compiles every .proto file under the given import
+// roots, as protoc would with one -I per root. Imports are resolved against
+// the roots in order, falling back to the well-known types bundled with
+// protobuf. Compile errors are returned together as a *ProtoCompileError.
+func CompileProtoSources(ctx context.Context, roots []string) ([]protoreflect.FileDescriptor, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("no import paths given")
+	}
+	paths, err := FindProtoFiles(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep going after the first error so they can all be reported at once
+	var problems []ProtoSourceError
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: roots}),
+		Reporter: reporter.NewReporter(func(err reporter.ErrorWithPos) error {
+			problems = append(problems, protoSourceError(err))
+			return nil
+		}, nil),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	compiled, err := compiler.Compile(ctx, paths...)
+	// Unresolvable imports stop compilation without going to the reporter
+	var posErr reporter.ErrorWithPos
+	if len(problems) == 0 && errors.As(err, &posErr) {
+		problems = append(problems, protoSourceError(posErr))
+	}
+	if len(problems) > 0 {
+		return nil, &ProtoCompileError{Errors: problems}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile protos: %w", err)
+	}
+
+	files := make([]protoreflect.FileDescriptor, len(compiled))
+	for i, fd := range compiled {
+		files[i] = fd
+	}
+	return files, nil
+}
+
+// protoSourceError converts a positioned compiler error.
+func protoSourceError(err reporter.ErrorWithPos) ProtoSourceError {
+	pos := err.GetPosition()
+	msg := err.Unwrap().Error()
+	if errors.Is(err, fs.ErrNotExist) {
+		msg = "imported file not found in any import path"
+	}
+	return ProtoSourceError{
+		File:    pos.Filename,
+		Line:    pos.Line,
+		Column:  pos.Col,
+		Message: msg,
+	}
+}
+
+// NewReflectionClientFromProtoSources creates a ReflectionClient that
+// serves ListServices and GetMethodDescriptor from .proto sources compiled
+// in-process, for servers with reflection disabled. Every .proto file under
+// the import roots is compiled. conn is kept for symmetry with
+// NewReflectionClient; no reflection calls are made on it. Cancelling ctx
+// stops the compile.
+func NewReflectionClientFromProtoSources(ctx context.Context, conn grpc.ClientConnInterface, roots []string, logger *slog.Logger) (*ReflectionClient, error) {
+	files, err := CompileProtoSources(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("compiled proto sources",
+		slog.Any("import_paths", roots),
+		slog.Int("files", len(files)),
+	)
+
+	return &ReflectionClient{
+		conn:         conn,
+		logger:       logger,
+		serviceCache: make(map[string]protoreflect.ServiceDescriptor),
+		protoRoots:   roots,
+		localFiles:   files,
+	}, nil
+}
+
+// ProtoImportPaths returns the import roots this client compiled its
+// descriptors from, or nil when it uses another source.
+func (r *ReflectionClient) ProtoImportPaths() []string {
+	return r.protoRoots
+}
@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProtos writes name -> source files under a new temp directory and
+// returns it.
+func writeProtos(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	}
+	return root
+}
+
+func TestProtoSources_InvokeLikeReflection(t *testing.T) {
+	rc, err := NewReflectionClientFromProtoSources(context.Background(), testConn, []string{"../../testdata/protos"}, discardLogger)
+	require.NoError(t, err)
+	defer rc.Close()
+	assert.Equal(t, []string{"../../testdata/protos"}, rc.ProtoImportPaths())
+	assert.Empty(t, rc.DescriptorSetPath())
+
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+	var names []string
+	for _, svc := range services {
+		names = append(names, svc.FullName)
+	}
+	assert.ElementsMatch(t, []string{"grpctest.TestService", "kitchensink.KitchenSink"}, names)
+
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+	assert.NotSame(t, pb.File_grpc_test_proto, md.ParentFile(), "compiled from source, not the linked-in registry")
+	assert.Equal(t, "google.protobuf.Timestamp", string(md.Input().Fields().ByName("item").Message().Fields().ByName("created_at").Message().FullName()))
+
+	resp, _, _, err := NewInvoker(testConn, testLogger).InvokeUnary(
+		context.Background(), md, `{"item":{"id":"from-source","createdAt":"2024-01-01T00:00:00Z"}}`, nil)
+	require.NoError(t, err)
+	assert.Contains(t, resp, "from-source")
+
+	_, err = rc.GetMethodDescriptor("grpctest.Missing", "UnaryEcho")
+	assert.ErrorContains(t, err, "not found in proto sources")
+}
+
+func TestCompileProtoSources_ImportRoots(t *testing.T) {
+	common := writeProtos(t, map[string]string{
+		"acme/common/v1/common.proto": `syntax = "proto3";
+package acme.common.v1;
+message Ref { string id = 1; }
+`,
+	})
+	services := writeProtos(t, map[string]string{
+		"acme/svc/v1/svc.proto": `syntax = "proto3";
+package acme.svc.v1;
+import "acme/common/v1/common.proto";
+import "google/protobuf/empty.proto";
+service Lookup { rpc Get(acme.common.v1.Ref) returns (google.protobuf.Empty); }
+`,
+		".git/ignored.proto": `not a proto`,
+	})
+
+	files, err := CompileProtoSources(context.Background(), []string{services, common})
+	require.NoError(t, err)
+	var paths []string
+	for _, fd := range files {
+		paths = append(paths, fd.Path())
+	}
+	assert.ElementsMatch(t, []string{"acme/svc/v1/svc.proto", "acme/common/v1/common.proto"}, paths)
+
+	// Without the second root the import cannot be found
+	_, err = CompileProtoSources(context.Background(), []string{services})
+	var compileErr *ProtoCompileError
+	require.ErrorAs(t, err, &compileErr)
+	require.NotEmpty(t, compileErr.Errors)
+	assert.Equal(t, "acme/svc/v1/svc.proto", compileErr.Errors[0].File)
+	assert.Equal(t, 3, compileErr.Errors[0].Line)
+}
+
+func TestCompileProtoSources_ReportsEveryError(t *testing.T) {
+	root := writeProtos(t, map[string]string{
+		"a.proto": `syntax = "proto3";
+message A {
+  string name = 1
+}
+`,
+		"b.proto": `syntax = "proto3";
+message B {
+  Missing m = 1;
+}
+`,
+	})
+
+	_, err := CompileProtoSources(context.Background(), []string{root})
+	var compileErr *ProtoCompileError
+	require.ErrorAs(t, err, &compileErr)
+	require.Len(t, compileErr.Errors, 2)
+
+	byFile := make(map[string]ProtoSourceError)
+	for _, e := range compileErr.Errors {
+		byFile[e.File] = e
+	}
+	assert.Equal(t, 4, byFile["a.proto"].Line)
+	assert.Equal(t, 3, byFile["b.proto"].Line)
+	assert.Equal(t, 3, byFile["b.proto"].Column)
+	assert.Contains(t, byFile["b.proto"].String(), "b.proto:3:3: ")
+	assert.Contains(t, compileErr.Error(), "(and 1 more)")
+}
+
+func TestCompileProtoSources_NoFiles(t *testing.T) {
+	_, err := CompileProtoSources(context.Background(), []string{t.TempDir()})
+	assert.ErrorContains(t, err, "no .proto files found")
+
+	_, err = CompileProtoSources(context.Background(), nil)
+	assert.Error(t, err)
+}
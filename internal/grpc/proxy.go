@@ -0,0 +1,157 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	apperrors "github.com/shhac/grotto/internal/errors"
+	"golang.org/x/net/proxy"
+)
+
+// defaultTargetPort is used for proxied targets given without a port, as
+// gRPC's DNS resolver does.
+const defaultTargetPort = "443"
+
+// dialFunc dials a host:port, as grpc.WithContextDialer and
+// http.Transport.DialContext expect.
+type dialFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// validateProxy checks that proxy settings are complete before dialing.
+func validateProxy(p domain.ProxySettings) error {
+	switch p.Type {
+	case domain.ProxyNone, domain.ProxyHTTP, domain.ProxySOCKS5:
+	default:
+		return fmt.Errorf("unknown proxy type %q", p.Type)
+	}
+	if !p.Enabled() {
+		return nil
+	}
+	if strings.TrimSpace(p.Host) == "" {
+		return errors.New("proxy host is required")
+	}
+	if p.Port < 1 || p.Port > 65535 {
+		return fmt.Errorf("proxy port must be from 1 to 65535, got %d", p.Port)
+	}
+	if p.Password != "" && p.Username == "" {
+		return errors.New("proxy password needs a username")
+	}
+	return nil
+}
+
+// proxyDialer returns a dialer that tunnels every connection through the
+// configured proxy. Its errors wrap apperrors.ErrProxyFailed so the proxy
+// failing (unreachable, rejecting the credentials, or unable to reach the
+// server) is told apart from the server itself failing.
+func proxyDialer(p domain.ProxySettings) (dialFunc, error) {
+	proxyAddr := p.Address()
+
+	var dial dialFunc
+	switch p.Type {
+	case domain.ProxySOCKS5:
+		var auth *proxy.Auth
+		if p.Username != "" {
+			auth = &proxy.Auth{User: p.Username, Password: p.Password}
+		}
+		d, err := proxy.SOCKS5("tcp", proxyAddr, auth, nil)
+		if err != nil {
+			return nil, err
+		}
+		dial = func(ctx context.Context, addr string) (net.Conn, error) {
+			return d.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+		}
+	case domain.ProxyHTTP:
+		dial = func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialHTTPConnect(ctx, p, addr)
+		}
+	default:
+		return nil, fmt.Errorf("unknown proxy type %q", p.Type)
+	}
+
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, defaultTargetPort)
+		}
+		conn, err := dial(ctx, addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s proxy %s could not reach %s: %v", apperrors.ErrProxyFailed, p.Type, proxyAddr, addr, err)
+		}
+		return conn, nil
+	}, nil
+}
+
+// proxiedTarget makes grpc.NewClient hand the target to the proxy dialer
+// unresolved, so the proxy looks up the server name. Behind a proxy the
+// name often only resolves on the proxy's side.
+func proxiedTarget(address string) string {
+	if strings.Contains(address, ":///") {
+		return address
+	}
+	return "passthrough:///" + address
+}
+
+// dialHTTPConnect opens a tunnel to addr through an HTTP proxy with the
+// CONNECT method.
+func dialHTTPConnect(ctx context.Context, p domain.ProxySettings, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.Address())
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if p.Username != "" {
+		creds := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + p.Password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sending CONNECT: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("reading CONNECT response: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy answered CONNECT with %s", resp.Status)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	if br.Buffered() > 0 {
+		// The server spoke before we did; keep what was read past the response
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose first reads come from a bufio.Reader
+// that already consumed part of the stream.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,229 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	apperrors "github.com/shhac/grotto/internal/errors"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shhac/grotto/testdata/grpcweb"
+	"github.com/shhac/grotto/testdata/socks5"
+)
+
+// startSOCKS5 starts an in-process SOCKS5 proxy that requires username and
+// password when username is set.
+func startSOCKS5(t *testing.T, username, password string) *socks5.Server {
+	t.Helper()
+	proxy, err := socks5.Start(username, password)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = proxy.Close() })
+	return proxy
+}
+
+// connectVia returns a manager connected with cfg.
+func connectVia(t *testing.T, cfg domain.Connection) *ConnectionManager {
+	t.Helper()
+	m := NewConnectionManager(testLogger)
+	t.Cleanup(func() { _ = m.Disconnect() })
+	require.NoError(t, m.Connect(context.Background(), cfg))
+	return m
+}
+
+// echoVia makes a UnaryEcho call over the manager's channel.
+func echoVia(t *testing.T, m *ConnectionManager) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, _, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(ctx, testMethod(t, "UnaryEcho"), `{"item":{"id":"proxied"}}`, nil)
+	return resp, err
+}
+
+// closedAddr returns a local address nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+	return addr
+}
+
+// requireProxyFailure checks that err is reported as a proxy failure rather
+// than the server being down.
+func requireProxyFailure(t *testing.T, err error, contains string) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), apperrors.ErrProxyFailed.Error())
+	assert.Contains(t, err.Error(), contains)
+	assert.Equal(t, "Proxy Connection Failed", apperrors.ClassifyGRPCError(err).Title)
+}
+
+func socksSettings(p *socks5.Server, username, password string) domain.ProxySettings {
+	return domain.ProxySettings{Type: domain.ProxySOCKS5, Host: "127.0.0.1", Port: p.Port(), Username: username, Password: password}
+}
+
+func TestProxy_SOCKS5(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+
+	t.Run("no auth", func(t *testing.T) {
+		proxy := startSOCKS5(t, "", "")
+		m := connectVia(t, domain.Connection{Address: srv.Addr, Proxy: socksSettings(proxy, "", "")})
+		resp, err := echoVia(t, m)
+		require.NoError(t, err)
+		assert.Contains(t, resp, "proxied")
+		assert.Equal(t, 1, proxy.Connects())
+	})
+
+	t.Run("auth success", func(t *testing.T) {
+		proxy := startSOCKS5(t, "alice", "s3cret")
+		m := connectVia(t, domain.Connection{Address: srv.Addr, Proxy: socksSettings(proxy, "alice", "s3cret")})
+		resp, err := echoVia(t, m)
+		require.NoError(t, err)
+		assert.Contains(t, resp, "proxied")
+		assert.Equal(t, 1, proxy.Connects())
+	})
+
+	t.Run("auth failure", func(t *testing.T) {
+		proxy := startSOCKS5(t, "alice", "s3cret")
+		m := connectVia(t, domain.Connection{Address: srv.Addr, Proxy: socksSettings(proxy, "alice", "wrong")})
+		_, err := echoVia(t, m)
+		requireProxyFailure(t, err, "authentication failed")
+		assert.Zero(t, proxy.Connects())
+	})
+
+	t.Run("unreachable target", func(t *testing.T) {
+		proxy := startSOCKS5(t, "", "")
+		m := connectVia(t, domain.Connection{Address: closedAddr(t), Proxy: socksSettings(proxy, "", "")})
+		_, err := echoVia(t, m)
+		requireProxyFailure(t, err, "host unreachable")
+	})
+
+	t.Run("unreachable proxy", func(t *testing.T) {
+		_, port, _ := net.SplitHostPort(closedAddr(t))
+		n, _ := strconv.Atoi(port)
+		proxy := domain.ProxySettings{Type: domain.ProxySOCKS5, Host: "127.0.0.1", Port: n}
+		m := connectVia(t, domain.Connection{Address: srv.Addr, Proxy: proxy})
+		_, err := echoVia(t, m)
+		requireProxyFailure(t, err, "connection refused")
+	})
+}
+
+func TestProxy_SOCKS5_GRPCWeb(t *testing.T) {
+	bridge := httptest.NewServer(grpcweb.NewHandler(testConn))
+	t.Cleanup(bridge.Close)
+	proxy := startSOCKS5(t, "", "")
+
+	m := connectVia(t, domain.Connection{Address: bridge.URL, Transport: domain.TransportGRPCWeb, Proxy: socksSettings(proxy, "", "")})
+	resp, err := echoVia(t, m)
+	require.NoError(t, err)
+	assert.Contains(t, resp, "proxied")
+	assert.Equal(t, 1, proxy.Connects())
+}
+
+// connectProxy is an HTTP proxy that only tunnels CONNECT requests carrying
+// the expected basic credentials.
+func connectProxy(t *testing.T, username, password string) *httptest.Server {
+	t.Helper()
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Proxy-Authorization") != want {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+			return
+		}
+		go func() {
+			_, _ = io.Copy(upstream, buf)
+			_ = upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProxy_HTTPConnect(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+	proxy := connectProxy(t, "bob", "hunter2")
+	_, port, _ := net.SplitHostPort(proxy.Listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	settings := func(password string) domain.ProxySettings {
+		return domain.ProxySettings{Type: domain.ProxyHTTP, Host: "127.0.0.1", Port: n, Username: "bob", Password: password}
+	}
+
+	m := connectVia(t, domain.Connection{Address: srv.Addr, Proxy: settings("hunter2")})
+	resp, err := echoVia(t, m)
+	require.NoError(t, err)
+	assert.Contains(t, resp, "proxied")
+
+	m = connectVia(t, domain.Connection{Address: srv.Addr, Proxy: settings("wrong")})
+	_, err = echoVia(t, m)
+	requireProxyFailure(t, err, "407")
+}
+
+func TestValidateProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   domain.ProxySettings
+		wantErr string
+	}{
+		{"none", domain.ProxySettings{}, ""},
+		{"socks5", domain.ProxySettings{Type: domain.ProxySOCKS5, Host: "proxy", Port: 1080}, ""},
+		{"http with auth", domain.ProxySettings{Type: domain.ProxyHTTP, Host: "proxy", Port: 3128, Username: "u", Password: "p"}, ""},
+		{"unknown type", domain.ProxySettings{Type: "socks4", Host: "proxy", Port: 1080}, "unknown proxy type"},
+		{"no host", domain.ProxySettings{Type: domain.ProxySOCKS5, Port: 1080}, "host is required"},
+		{"no port", domain.ProxySettings{Type: domain.ProxySOCKS5, Host: "proxy"}, "port must be"},
+		{"password only", domain.ProxySettings{Type: domain.ProxyHTTP, Host: "proxy", Port: 3128, Password: "p"}, "needs a username"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProxy(tt.proxy)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConnect_ProxyRejectsUnixSocket(t *testing.T) {
+	m := NewConnectionManager(testLogger)
+	err := m.Connect(context.Background(), domain.Connection{
+		Address: "unix-abstract:grotto",
+		Proxy:   domain.ProxySettings{Type: domain.ProxySOCKS5, Host: "proxy", Port: 1080},
+	})
+	assert.ErrorContains(t, err, "through a proxy")
+	assert.Equal(t, StateError, m.State())
+}
@@ -4,32 +4,83 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/jhump/protoreflect/v2/grpcreflect"
 	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/protoconv"
 	"google.golang.org/grpc"
 	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
-// ReflectionClient wraps gRPC server reflection functionality
+// lenientLookupTimeout bounds a single method or symbol lookup that falls
+// back to lenient reflection.
+const lenientLookupTimeout = 30 * time.Second
+
+// ReflectionClient wraps gRPC server reflection functionality.
+// A client created by NewReflectionClientFromDescriptorSet or
+// NewReflectionClientFromProtoSources resolves everything from local
+// descriptors and has no reflection stream.
 type ReflectionClient struct {
-	conn         *grpc.ClientConn
-	client       *grpcreflect.Client
-	logger       *slog.Logger
+	conn   grpc.ClientConnInterface
+	client *grpcreflect.Client // nil when loaded from local descriptors
+	logger *slog.Logger
+
+	// stop cancels ctx, the context the reflection stream runs under, which
+	// is what unblocks a call the server never answers. Lookups made without
+	// a context of their own run under ctx too, so Close ends them.
+	ctx  context.Context
+	stop context.CancelFunc
+
+	// onProgress is told how many services ListServices has resolved
+	onProgress func(resolved, total int)
+
+	// mu guards serviceCache, reflectionMethod and fromSchemaCache, which
+	// ListServices writes from the connect goroutine while UI handlers look
+	// methods up
+	mu           sync.RWMutex
 	serviceCache map[string]protoreflect.ServiceDescriptor
+
+	// reflectionMethod is the raw reflection stream method known to work
+	// for lenient resolution, "" until the first successful stream
+	reflectionMethod string
+
+	// lenient holds the files lenient resolution fetched and built
+	lenient *lenientFiles
+
+	// listed holds the services the last listing was given; searched and
+	// searchFiles hold the names SearchTypes asked the server for and the
+	// files it got back (guarded by mu)
+	listed      []protoreflect.FullName
+	searched    map[protoreflect.FullName]bool
+	searchFiles []protoreflect.FileDescriptor
+
+	// Descriptors cached across sessions for the server at schemaAddress;
+	// fromSchemaCache is set when the last listing came from the cache
+	schemaCache     SchemaCache
+	schemaAddress   string
+	fromSchemaCache bool
+
+	// Local descriptor source: a FileDescriptorSet path or the import
+	// roots of compiled .proto sources (both empty means server reflection)
+	descriptorSet string
+	protoRoots    []string
+	localFiles    []protoreflect.FileDescriptor
 }
 
 // NewReflectionClient creates a new reflection client for the given connection
-func NewReflectionClient(conn *grpc.ClientConn, logger *slog.Logger) *ReflectionClient {
+func NewReflectionClient(conn grpc.ClientConnInterface, logger *slog.Logger) *ReflectionClient {
 	// Use NewClientAuto which takes the connection directly
-	refClient := grpcreflect.NewClientAuto(context.Background(), conn,
+	ctx, stop := context.WithCancel(context.Background())
+	refClient := grpcreflect.NewClientAuto(ctx, conn,
 		grpcreflect.WithAllowMissingFileDescriptors(),
 		grpcreflect.WithFallbackResolvers(protoregistry.GlobalFiles, protoregistry.GlobalTypes),
 	)
@@ -38,94 +89,136 @@ func NewReflectionClient(conn *grpc.ClientConn, logger *slog.Logger) *Reflection
 		conn:         conn,
 		client:       refClient,
 		logger:       logger,
+		ctx:          ctx,
+		stop:         stop,
 		serviceCache: make(map[string]protoreflect.ServiceDescriptor),
+		lenient:      newLenientFiles(),
+		searched:     make(map[protoreflect.FullName]bool),
 	}
 }
 
-// ListServices discovers all services available on the server
+// SetOnProgress sets a callback ListServices calls, from its own goroutine,
+// as services are resolved: once with none resolved when the service names
+// are known, then as each is done. Set it before listing.
+func (r *ReflectionClient) SetOnProgress(fn func(resolved, total int)) {
+	r.onProgress = fn
+}
+
+// progress reports ListServices progress, if anyone is listening.
+func (r *ReflectionClient) progress(resolved, total int) {
+	if r.onProgress != nil {
+		r.onProgress(resolved, total)
+	}
+}
+
+// ListServices discovers all services available on the server. Cancelling
+// ctx abandons the listing and returns ctx's error; over reflection it also
+// closes the client, since a call the server never answers can only be
+// unblocked by tearing down its stream.
 func (r *ReflectionClient) ListServices(ctx context.Context) ([]domain.Service, error) {
+	return r.ListServicesFunc(ctx, nil)
+}
+
+// ListServicesFunc lists services as ListServices does, and also calls fn,
+// from its own goroutine, with each service as soon as it has resolved or
+// failed to, so a slow listing can be shown as it goes. Services come in
+// the order they finish, not by name. fn may already have been called for
+// some services when the listing fails or is abandoned. A nil fn is
+// allowed.
+func (r *ReflectionClient) ListServicesFunc(ctx context.Context, fn func(domain.Service)) ([]domain.Service, error) {
+	found := func(svc domain.Service) {
+		if fn != nil {
+			fn(svc)
+		}
+	}
+
+	if r.client == nil {
+		var services []domain.Service
+		for _, sd := range r.listLocalServices() {
+			r.cacheService(string(sd.FullName()), sd)
+			svc := r.convertService(sd)
+			found(svc)
+			services = append(services, svc)
+		}
+		r.logger.Info("discovered services from "+r.localSource(),
+			slog.String("path", r.descriptorSet),
+			slog.Any("import_paths", r.protoRoots),
+			slog.Int("service_count", len(services)),
+		)
+		return services, nil
+	}
+
 	r.logger.Debug("listing services via reflection")
 
+	abandon := context.AfterFunc(ctx, r.Close)
+	defer abandon()
+
 	serviceNames, err := r.client.ListServices()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		r.logger.Error("failed to list services", slog.Any("error", err))
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
 
-	resolver := r.client.AsResolver()
+	var schemaHash string
+	r.mu.Lock()
+	r.fromSchemaCache = false
+	r.mu.Unlock()
+	if r.schemaCache != nil {
+		schemaHash = servicesHash(serviceNames)
+		if services, ok := r.listCachedServices(serviceNames, schemaHash); ok {
+			for _, svc := range services {
+				found(svc)
+			}
+			r.progress(len(services), len(services))
+			return services, nil
+		}
+	}
 
-	var services []domain.Service
+	var wanted []protoreflect.FullName
 	for _, serviceName := range serviceNames {
 		// Skip reflection service itself
-		if serviceName == "grpc.reflection.v1alpha.ServerReflection" ||
-			serviceName == "grpc.reflection.v1.ServerReflection" {
-			continue
+		if !isReflectionService(string(serviceName)) {
+			wanted = append(wanted, serviceName)
 		}
-
-		// Load the file containing this service (populates the resolver cache)
-		_, err := r.client.FileContainingSymbol(serviceName)
-		if err != nil {
-			r.logger.Warn("standard resolution failed, trying lenient resolve",
-				slog.String("service", string(serviceName)),
-				slog.Any("error", err),
-			)
-
-			// Try lenient resolution with AllowUnresolvable
-			sd, lenientErr := r.lenientResolve(ctx, string(serviceName))
-			if lenientErr != nil {
-				r.logger.Warn("lenient resolution also failed",
-					slog.String("service", string(serviceName)),
-					slog.Any("error", lenientErr),
-				)
-				services = append(services, domain.Service{
-					Name:     string(serviceName.Name()),
-					FullName: string(serviceName),
-					Error:    fmt.Sprintf("%s\n\nLenient: %s", err.Error(), lenientErr.Error()),
-				})
-				continue
-			}
-
-			r.serviceCache[string(serviceName)] = sd
-			service := r.convertService(sd)
-			services = append(services, service)
-			r.logger.Info("lenient resolution succeeded",
-				slog.String("service", string(serviceName)),
-				slog.Int("methods", len(service.Methods)),
-			)
-			continue
+	}
+	r.mu.Lock()
+	r.listed = wanted
+	r.mu.Unlock()
+
+	// Fetch shared files once, concurrently; whatever doesn't build cleanly
+	// goes through the client and the lenient fallback below
+	r.progress(0, len(wanted))
+	prefetched := r.prefetchServices(ctx, wanted)
+	r.progress(len(prefetched), len(wanted))
+
+	// Services are returned in the server's order, but reported as they
+	// finish: the prefetched ones at once, then each of the rest
+	services := make([]domain.Service, len(wanted))
+	for i, serviceName := range wanted {
+		if sd, ok := prefetched[serviceName]; ok {
+			r.cacheService(string(serviceName), sd)
+			services[i] = r.convertService(sd)
+			found(services[i])
 		}
-
-		// Resolve the service descriptor
-		desc, err := resolver.FindDescriptorByName(serviceName)
-		if err != nil {
-			r.logger.Warn("failed to resolve service",
-				slog.String("service", string(serviceName)),
-				slog.Any("error", err),
-			)
-			services = append(services, domain.Service{
-				Name:     string(serviceName.Name()),
-				FullName: string(serviceName),
-				Error:    err.Error(),
-			})
+	}
+	resolved := len(prefetched)
+	for i, serviceName := range wanted {
+		if _, ok := prefetched[serviceName]; ok {
 			continue
 		}
-
-		serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
-		if !ok {
-			r.logger.Warn("descriptor is not a service",
-				slog.String("service", string(serviceName)),
-			)
-			services = append(services, domain.Service{
-				Name:     string(serviceName.Name()),
-				FullName: string(serviceName),
-				Error:    "descriptor is not a service",
-			})
-			continue
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
-		r.serviceCache[string(serviceName)] = serviceDesc
-		service := r.convertService(serviceDesc)
-		services = append(services, service)
+		services[i] = r.resolveService(ctx, serviceName)
+		found(services[i])
+		resolved++
+		r.progress(resolved, len(wanted))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// Log summary with error count
@@ -146,188 +239,401 @@ func (r *ReflectionClient) ListServices(ctx context.Context) ([]domain.Service,
 		slog.Int("service_count", len(services)),
 	)
 
+	if r.schemaCache != nil {
+		r.storeSchema(schemaHash, services)
+	}
+
 	return services, nil
 }
 
+// resolveService loads one service through the reflection client, falling
+// back to lenient resolution. Failures are reported on the returned service.
+func (r *ReflectionClient) resolveService(ctx context.Context, serviceName protoreflect.FullName) domain.Service {
+	// Load the file containing this service (populates the resolver cache)
+	_, err := r.client.FileContainingSymbol(serviceName)
+	if err != nil {
+		r.logger.Warn("standard resolution failed, trying lenient resolve",
+			slog.String("service", string(serviceName)),
+			slog.Any("error", err),
+		)
+
+		// Try lenient resolution with AllowUnresolvable
+		sd, lenientErr := r.lenientResolve(ctx, string(serviceName))
+		if lenientErr != nil {
+			r.logger.Warn("lenient resolution also failed",
+				slog.String("service", string(serviceName)),
+				slog.Any("error", lenientErr),
+			)
+			return domain.Service{
+				Name:     string(serviceName.Name()),
+				FullName: string(serviceName),
+				Error:    fmt.Sprintf("%s\n\nLenient: %s", err.Error(), lenientErr.Error()),
+			}
+		}
+
+		r.cacheService(string(serviceName), sd)
+		service := r.convertService(sd)
+		r.logger.Info("lenient resolution succeeded",
+			slog.String("service", string(serviceName)),
+			slog.Int("methods", len(service.Methods)),
+		)
+		return service
+	}
+
+	// Resolve the service descriptor
+	desc, err := r.client.AsResolver().FindDescriptorByName(serviceName)
+	if err != nil {
+		r.logger.Warn("failed to resolve service",
+			slog.String("service", string(serviceName)),
+			slog.Any("error", err),
+		)
+		return domain.Service{
+			Name:     string(serviceName.Name()),
+			FullName: string(serviceName),
+			Error:    err.Error(),
+		}
+	}
+
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		r.logger.Warn("descriptor is not a service",
+			slog.String("service", string(serviceName)),
+		)
+		return domain.Service{
+			Name:     string(serviceName.Name()),
+			FullName: string(serviceName),
+			Error:    "descriptor is not a service",
+		}
+	}
+
+	r.cacheService(string(serviceName), serviceDesc)
+	return r.convertService(serviceDesc)
+}
+
 // GetMethodDescriptor returns the descriptor for a specific method
 func (r *ReflectionClient) GetMethodDescriptor(serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
-	serviceDesc, ok := r.serviceCache[serviceName]
+	serviceDesc, ok := r.cachedService(serviceName)
+	if !ok && r.client == nil {
+		for _, sd := range r.listLocalServices() {
+			if string(sd.FullName()) == serviceName {
+				serviceDesc, ok = sd, true
+				r.cacheService(serviceName, sd)
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("service %s not found in %s", serviceName, r.localSource())
+		}
+	}
 	if !ok {
 		// Load the file and resolve the service descriptor
 		resolver := r.client.AsResolver()
 		_, err := r.client.FileContainingSymbol(protoreflect.FullName(serviceName))
 		if err != nil {
-			return nil, fmt.Errorf("failed to load service %s: %w", serviceName, err)
-		}
-		d, err := resolver.FindDescriptorByName(protoreflect.FullName(serviceName))
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve service %s: %w", serviceName, err)
-		}
-		sd, ok := d.(protoreflect.ServiceDescriptor)
-		if !ok {
-			return nil, fmt.Errorf("descriptor for %s is not a service", serviceName)
+			// A partially resolvable service still has callable methods
+			ctx, cancel := r.lookupContext()
+			sd, lenientErr := r.lenientResolve(ctx, serviceName)
+			cancel()
+			if lenientErr != nil {
+				return nil, fmt.Errorf("failed to load service %s: %w", serviceName, err)
+			}
+			serviceDesc = sd
+		} else {
+			d, err := resolver.FindDescriptorByName(protoreflect.FullName(serviceName))
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve service %s: %w", serviceName, err)
+			}
+			sd, ok := d.(protoreflect.ServiceDescriptor)
+			if !ok {
+				return nil, fmt.Errorf("descriptor for %s is not a service", serviceName)
+			}
+			serviceDesc = sd
 		}
-		serviceDesc = sd
-		r.serviceCache[serviceName] = serviceDesc
+		r.cacheService(serviceName, serviceDesc)
 	}
 
 	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(methodName))
 	if methodDesc == nil {
 		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
 	}
+	if problem := methodError(methodDesc); problem != "" {
+		return nil, fmt.Errorf("method %s cannot be called: %s", methodDesc.FullName(), problem)
+	}
 
 	return methodDesc, nil
 }
 
-// Close closes the reflection client
+// methodError describes why md cannot be called, or returns "" if it can.
+// An input or output type that could not be resolved is left as a
+// placeholder, which has no fields to build or decode a message with.
+func methodError(md protoreflect.MethodDescriptor) string {
+	var problems []string
+	if md.Input().IsPlaceholder() {
+		problems = append(problems, fmt.Sprintf("input type %s could not be resolved", md.Input().FullName()))
+	}
+	if md.Output().IsPlaceholder() {
+		problems = append(problems, fmt.Sprintf("output type %s could not be resolved", md.Output().FullName()))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Close closes the reflection client, unblocking any call waiting on the
+// server. It may be called more than once.
 func (r *ReflectionClient) Close() {
-	r.client.Reset()
-	r.serviceCache = nil
+	if r.stop != nil {
+		r.stop()
+	}
+	if r.client != nil {
+		r.client.Reset()
+	}
+	// Cleared rather than dropped, so a listing still in flight can finish
+	r.mu.Lock()
+	clear(r.serviceCache)
+	clear(r.searched)
+	r.listed, r.searchFiles = nil, nil
+	r.mu.Unlock()
+	if r.lenient != nil {
+		r.lenient.clear()
+	}
+}
+
+// cachedService returns the resolved descriptor for a service, if any.
+func (r *ReflectionClient) cachedService(name string) (protoreflect.ServiceDescriptor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sd, ok := r.serviceCache[name]
+	return sd, ok
+}
+
+// cacheService records a resolved service descriptor.
+func (r *ReflectionClient) cacheService(name string, sd protoreflect.ServiceDescriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.serviceCache[name] = sd
+}
+
+// isReflectionService reports whether name is a server reflection service,
+// which is hidden from the service list.
+func isReflectionService(name string) bool {
+	return name == "grpc.reflection.v1alpha.ServerReflection" ||
+		name == "grpc.reflection.v1.ServerReflection"
+}
+
+// lookupContext returns the context for a lenient lookup made on behalf of
+// a caller without one: it ends when the client is closed, and after
+// lenientLookupTimeout so a stalled stream cannot block the caller forever.
+func (r *ReflectionClient) lookupContext() (context.Context, context.CancelFunc) {
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, lenientLookupTimeout)
 }
 
 // lenientResolve uses the raw reflection protocol with protodesc.AllowUnresolvable
 // to build service descriptors even when some type dependencies can't be resolved.
+// It speaks reflection v1, falling back to v1alpha for older servers. Files
+// are fetched and built once per session (see lenientFiles), so services
+// sharing a file resolve from the same descriptors.
 func (r *ReflectionClient) lenientResolve(ctx context.Context, serviceName string) (protoreflect.ServiceDescriptor, error) {
-	refClient := reflectionpb.NewServerReflectionClient(r.conn)
-	stream, err := refClient.ServerReflectionInfo(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	if sd, ok := r.lenient.service(serviceName); ok {
+		return sd, nil
 	}
-	defer stream.CloseSend()
+	return r.lenientResolveInto(ctx, r.lenient, serviceName, newTraceCollector(r.logger))
+}
 
-	// Request file containing the service symbol
-	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
-		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
-			FileContainingSymbol: serviceName,
-		},
-	}); err != nil {
-		return nil, fmt.Errorf("failed to send reflection request: %w", err)
-	}
-
-	resp, err := stream.Recv()
-	if err != nil {
-		return nil, fmt.Errorf("failed to receive reflection response: %w", err)
+// Diagnose resolves serviceName again over lenient reflection, from scratch
+// and apart from the session's files, and reports which files the server
+// returned, the fix-ups they needed and why any could not be built. Only
+// server reflection can be diagnosed.
+func (r *ReflectionClient) Diagnose(ctx context.Context, serviceName string) (ResolutionReport, error) {
+	if r.client == nil {
+		return ResolutionReport{}, fmt.Errorf("services from %s are not resolved over reflection", r.localSource())
 	}
+	trace := newTraceCollector(r.logger)
+	_, err := r.lenientResolveInto(ctx, newLenientFiles(), serviceName, trace)
+	return trace.Report(serviceName, err), nil
+}
 
-	fdResp := resp.GetFileDescriptorResponse()
-	if fdResp == nil {
-		if errResp := resp.GetErrorResponse(); errResp != nil {
-			return nil, fmt.Errorf("reflection error: %s", errResp.GetErrorMessage())
+// lenientResolveInto fetches the files serviceName needs into files and
+// resolves it from them, recording what happens in trace.
+func (r *ReflectionClient) lenientResolveInto(ctx context.Context, files *lenientFiles, serviceName string, trace *TraceCollector) (protoreflect.ServiceDescriptor, error) {
+	// The stream is opened by the first request, over v1 or v1alpha
+	var stream reflectionStream
+	defer func() {
+		if stream != nil {
+			_ = stream.CloseSend()
 		}
-		return nil, fmt.Errorf("unexpected reflection response type")
-	}
-
-	// Parse all returned file descriptor protos
-	var fdProtos []*descriptorpb.FileDescriptorProto
-	seen := map[string]bool{}
-	for _, raw := range fdResp.GetFileDescriptorProto() {
-		fd := &descriptorpb.FileDescriptorProto{}
-		if err := proto.Unmarshal(raw, fd); err != nil {
-			r.logger.Warn("failed to unmarshal file descriptor in lenient resolve", slog.Any("error", err))
-			continue
+	}()
+	fetch := func(req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+		if stream == nil {
+			s, resp, err := r.openReflection(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			stream = s
+			return resp, nil
 		}
-		if !seen[fd.GetName()] {
-			fdProtos = append(fdProtos, fd)
-			seen[fd.GetName()] = true
+		if err := stream.Send(req); err != nil {
+			return nil, err
 		}
+		return stream.Recv()
 	}
-
-	for _, fd := range fdProtos {
-		r.logger.Debug("lenient resolve: received file descriptor",
-			slog.String("file", fd.GetName()),
-			slog.String("package", fd.GetPackage()),
-			slog.Any("deps", fd.GetDependency()),
-		)
-	}
-
-	// Fetch missing dependencies not available locally
-	needed := map[string]bool{}
-	for _, fd := range fdProtos {
-		for _, dep := range fd.GetDependency() {
-			if !seen[dep] {
-				if _, err := protoregistry.GlobalFiles.FindFileByPath(dep); err != nil {
-					needed[dep] = true
-				}
+	receive := func(resp *reflectionpb.FileDescriptorResponse, source string) {
+		for _, raw := range resp.GetFileDescriptorProto() {
+			fd, err := files.add(raw)
+			if err != nil {
+				r.logger.Warn("failed to unmarshal file descriptor in lenient resolve", slog.Any("error", err))
+				continue
+			}
+			if fd != nil {
+				trace.received(fd, source)
 			}
 		}
 	}
 
-	for dep := range needed {
-		if err := stream.Send(&reflectionpb.ServerReflectionRequest{
-			MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{
-				FileByFilename: dep,
+	// Request the file containing the service, unless it arrived with
+	// another service's files
+	if !files.declares(serviceName) {
+		resp, err := fetch(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: serviceName,
 			},
-		}); err != nil {
-			r.logger.Debug("failed to request dependency file",
-				slog.String("dep", dep), slog.Any("error", err))
-			continue
-		}
-		depResp, err := stream.Recv()
+		})
 		if err != nil {
-			r.logger.Debug("failed to receive dependency file",
-				slog.String("dep", dep), slog.Any("error", err))
-			continue
+			return nil, err
 		}
-		if depFdResp := depResp.GetFileDescriptorResponse(); depFdResp != nil {
-			for _, raw := range depFdResp.GetFileDescriptorProto() {
-				fd := &descriptorpb.FileDescriptorProto{}
-				if err := proto.Unmarshal(raw, fd); err == nil && !seen[fd.GetName()] {
-					fdProtos = append(fdProtos, fd)
-					seen[fd.GetName()] = true
-				}
+		fdResp := resp.GetFileDescriptorResponse()
+		if fdResp == nil {
+			if errResp := resp.GetErrorResponse(); errResp != nil {
+				return nil, fmt.Errorf("reflection error: %s", errResp.GetErrorMessage())
 			}
+			return nil, fmt.Errorf("unexpected reflection response type")
 		}
+		receive(fdResp, "file containing "+serviceName)
 	}
 
-	localFiles, err := buildFileDescriptors(fdProtos, r.logger)
-	if err != nil {
-		return nil, err
-	}
-
-	// Search the built registry for the target service
-	var serviceDesc protoreflect.ServiceDescriptor
-	localFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
-		for i := range fd.Services().Len() {
-			sd := fd.Services().Get(i)
-			if string(sd.FullName()) == serviceName {
-				serviceDesc = sd
-				return false
+	// Fetch missing dependencies not available locally
+	requested := map[string]bool{}
+	for {
+		var needed []string
+		for _, dep := range files.missing() {
+			if !requested[dep] {
+				needed = append(needed, dep)
 			}
 		}
-		return true
-	})
+		if len(needed) == 0 {
+			break
+		}
+		for _, dep := range needed {
+			requested[dep] = true
+			depResp, err := fetch(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{
+					FileByFilename: dep,
+				},
+			})
+			if err != nil {
+				r.logger.Debug("failed to fetch dependency file",
+					slog.String("dep", dep), slog.Any("error", err))
+				continue
+			}
+			receive(depResp.GetFileDescriptorResponse(), "file by name "+dep)
+		}
+	}
+
+	return files.resolve(serviceName, trace)
+}
 
-	if serviceDesc == nil {
-		return nil, fmt.Errorf("service %s not found after lenient parsing", serviceName)
+// declaresService reports whether fdp declares the service fullName.
+func declaresService(fdp *descriptorpb.FileDescriptorProto, fullName string) bool {
+	for _, sdp := range fdp.GetService() {
+		name := sdp.GetName()
+		if fdp.GetPackage() != "" {
+			name = fdp.GetPackage() + "." + name
+		}
+		if name == fullName {
+			return true
+		}
 	}
+	return false
+}
 
-	return serviceDesc, nil
+// buildServicesOnly builds a stand-in for a file that could not be built,
+// keeping only its services. Method types declared in the file itself, or
+// in others that failed, become placeholders, so those methods are reported
+// as broken while the rest of the service can still be called.
+func buildServicesOnly(fdp *descriptorpb.FileDescriptorProto, files *protoregistry.Files, trace *TraceCollector) (protoreflect.FileDescriptor, error) {
+	stub := &descriptorpb.FileDescriptorProto{
+		Name:       fdp.Name,
+		Package:    fdp.Package,
+		Dependency: slices.Clone(fdp.GetDependency()),
+		Service:    fdp.GetService(),
+		Syntax:     fdp.Syntax,
+		Edition:    fdp.Edition,
+	}
+	opts := protodesc.FileOptions{AllowUnresolvable: true}
+	resolver := &combinedResolver{local: files, global: protoregistry.GlobalFiles}
+	fd, err := opts.New(stub, resolver)
+	if err != nil && fixMissingImports(stub, resolver, trace) {
+		fd, err = opts.New(stub, resolver)
+	}
+	if err != nil {
+		trace.failed(fdp.GetName(), err)
+		return nil, err
+	}
+	trace.logger.Warn("built services only for a file with unresolvable messages",
+		slog.String("file", fdp.GetName()),
+		slog.Int("services", fd.Services().Len()),
+	)
+	trace.fixed(fdp.GetName(), fmt.Sprintf("built its %d service(s) alone, leaving out its messages", fd.Services().Len()))
+	return fd, nil
 }
 
 // buildFileDescriptors iteratively builds protoreflect FileDescriptors from raw
 // FileDescriptorProtos using lenient options. It handles dependency ordering and
-// fixes missing imports on failure. Returns the registry of successfully built files.
-func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *slog.Logger) (*protoregistry.Files, error) {
-	opts := protodesc.FileOptions{AllowUnresolvable: true}
+// fixes missing imports on failure, recording what it did in trace. Returns
+// the registry of successfully built files.
+func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, trace *TraceCollector) (*protoregistry.Files, error) {
 	localFiles := new(protoregistry.Files)
-	resolver := &combinedResolver{local: localFiles, global: protoregistry.GlobalFiles}
+	buildInto(&combinedResolver{local: localFiles, global: protoregistry.GlobalFiles}, fdProtos, trace)
+	if localFiles.NumFiles() == 0 {
+		return nil, fmt.Errorf("no files could be built from %d protos", len(fdProtos))
+	}
+	return localFiles, nil
+}
+
+// buildInto builds fdProtos as buildFileDescriptors does, registering them
+// in resolver.local, which may already hold files they import.
+func buildInto(resolver *combinedResolver, fdProtos []*descriptorpb.FileDescriptorProto, trace *TraceCollector) {
+	opts := protodesc.FileOptions{AllowUnresolvable: true}
+	localFiles := resolver.local
 
 	// Pre-fix malformed descriptors before building
 	for _, fd := range fdProtos {
-		if fixMapEntryNames(fd) {
-			logger.Debug("fixed malformed map entry names",
-				slog.String("file", fd.GetName()),
-			)
-		}
+		trace.declared(fd)
+		fixMapEntryNames(fd, trace)
 		if fixReservedRanges(fd) {
-			logger.Debug("fixed malformed reserved ranges",
-				slog.String("file", fd.GetName()),
-			)
+			trace.fixed(fd.GetName(), "made inclusive reserved ranges end-exclusive")
+		}
+		if fixGroupFields(fd) {
+			trace.fixed(fd.GetName(), "renamed group fields after their messages")
 		}
 	}
 
-	remaining := make([]*descriptorpb.FileDescriptorProto, len(fdProtos))
-	copy(remaining, fdProtos)
+	// Types declared in other files of the set can't be seen by the
+	// resolver until those files are built, and a file built first would
+	// get placeholders for them. Import them up front and build each
+	// file's dependencies before it.
+	declared := declaredTypes(fdProtos)
+	for _, fd := range fdProtos {
+		before := len(fd.GetDependency())
+		if fixImportsFromSet(fd, declared) {
+			for _, dep := range fd.GetDependency()[before:] {
+				trace.fixed(fd.GetName(), fmt.Sprintf("added missing import %s, which declares types it uses", dep))
+			}
+		}
+	}
+	remaining := orderByDependency(fdProtos)
 
 	iteration := 0
 	for len(remaining) > 0 {
@@ -342,6 +648,8 @@ func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *
 				continue
 			}
 			if _, err := protoregistry.GlobalFiles.FindFileByPath(fd.GetName()); err == nil {
+				trace.fixed(fd.GetName(), "used the copy already registered under this path")
+				trace.built(fd.GetName())
 				progress = true
 				continue
 			}
@@ -349,14 +657,10 @@ func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *
 			parsed, err := opts.New(fd, resolver)
 			if err != nil {
 				firstErr := err
-				if fixMissingImports(fd, resolver, logger) {
-					logger.Debug("fixMissingImports: injected imports",
-						slog.String("file", fd.GetName()),
-						slog.Any("deps", fd.GetDependency()),
-					)
+				if fixMissingImports(fd, resolver, trace) {
 					parsed, err = opts.New(fd, resolver)
 					if err != nil {
-						logger.Debug("build still failed after import fix",
+						trace.logger.Debug("build still failed after import fix",
 							slog.String("file", fd.GetName()),
 							slog.String("first_error", firstErr.Error()),
 							slog.String("retry_error", err.Error()),
@@ -365,18 +669,21 @@ func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *
 				}
 			}
 			if err != nil {
+				trace.failed(fd.GetName(), err)
 				next = append(next, fd)
 				continue
 			}
 			progress = true
 			if regErr := localFiles.RegisterFile(parsed); regErr != nil {
-				logger.Debug("failed to register lenient file",
+				trace.logger.Debug("failed to register lenient file",
 					slog.String("file", fd.GetName()),
 					slog.Any("error", regErr),
 				)
+				trace.failed(fd.GetName(), regErr)
 				continue
 			}
-			logger.Debug("successfully built file",
+			trace.built(fd.GetName())
+			trace.logger.Debug("successfully built file",
 				slog.String("file", fd.GetName()),
 				slog.Int("iteration", iteration),
 			)
@@ -386,7 +693,10 @@ func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *
 		if !progress {
 			for _, fd := range remaining {
 				_, lastErr := opts.New(fd, resolver)
-				logger.Warn("file stuck after all retries",
+				if lastErr != nil {
+					trace.failed(fd.GetName(), lastErr)
+				}
+				trace.logger.Warn("file stuck after all retries",
 					slog.String("file", fd.GetName()),
 					slog.Any("deps", fd.GetDependency()),
 					slog.Any("error", lastErr),
@@ -396,12 +706,6 @@ func buildFileDescriptors(fdProtos []*descriptorpb.FileDescriptorProto, logger *
 			break
 		}
 	}
-
-	if localFiles.NumFiles() == 0 {
-		return nil, fmt.Errorf("no files could be built from %d protos", len(fdProtos))
-	}
-
-	return localFiles, nil
 }
 
 // combinedResolver merges local (server-provided) files with the global registry.
@@ -437,14 +741,15 @@ func (r *combinedResolver) FindDescriptorByName(name protoreflect.FullName) (pro
 // Type references may be fully-qualified (".pkg.Type") or relative ("Type",
 // "sub.Type"). Relative refs are resolved using proto scoping rules: the file's
 // package is progressively stripped to find a matching fully-qualified name.
-func fixMissingImports(fd *descriptorpb.FileDescriptorProto, r protodesc.Resolver, logger *slog.Logger) bool {
+// Each import added is recorded in trace.
+func fixMissingImports(fd *descriptorpb.FileDescriptorProto, r protodesc.Resolver, trace *TraceCollector) bool {
 	existing := make(map[string]bool, len(fd.GetDependency()))
 	for _, d := range fd.GetDependency() {
 		existing[d] = true
 	}
 
 	refs := collectTypeRefs(fd)
-	logger.Debug("fixMissingImports: collected type refs",
+	trace.logger.Debug("fixMissingImports: collected type refs",
 		slog.String("file", fd.GetName()),
 		slog.Int("ref_count", len(refs)),
 		slog.Any("refs", refs),
@@ -463,11 +768,7 @@ func fixMissingImports(fd *descriptorpb.FileDescriptorProto, r protodesc.Resolve
 		}
 		filePath := d.ParentFile().Path()
 		if !existing[filePath] {
-			logger.Debug("fixMissingImports: adding import",
-				slog.String("file", fd.GetName()),
-				slog.String("type", name),
-				slog.String("resolved_from", string(filePath)),
-			)
+			trace.fixed(fd.GetName(), fmt.Sprintf("added missing import %s for %s", filePath, name))
 			fd.Dependency = append(fd.Dependency, filePath)
 			existing[filePath] = true
 			added = true
@@ -481,24 +782,125 @@ func fixMissingImports(fd *descriptorpb.FileDescriptorProto, r protodesc.Resolve
 // it tries: acme.svc.v1.types.Money, acme.svc.types.Money, acme.types.Money,
 // types.Money — returning the first match.
 func resolveTypeRef(name, pkg string, r protodesc.Resolver) protoreflect.Descriptor {
-	// Try as-is first (handles already-qualified names like "google.protobuf.Timestamp")
-	if d, err := r.FindDescriptorByName(protoreflect.FullName(name)); err == nil {
-		return d
-	}
-	// Try with package prefix scoping: prepend progressively shorter package prefixes
-	for pkg != "" {
-		candidate := pkg + "." + name
+	for _, candidate := range typeRefCandidates(name, pkg) {
 		if d, err := r.FindDescriptorByName(protoreflect.FullName(candidate)); err == nil {
 			return d
 		}
+	}
+	return nil
+}
+
+// typeRefCandidates lists the full names a type reference may mean, in the
+// order resolveTypeRef tries them: as-is first (handles already-qualified
+// names like "google.protobuf.Timestamp"), then prefixed with progressively
+// shorter package prefixes.
+func typeRefCandidates(name, pkg string) []string {
+	candidates := []string{name}
+	for pkg != "" {
+		candidates = append(candidates, pkg+"."+name)
 		// Strip the last package segment
-		if i := strings.LastIndex(pkg, "."); i >= 0 {
-			pkg = pkg[:i]
-		} else {
+		i := strings.LastIndex(pkg, ".")
+		if i < 0 {
 			break
 		}
+		pkg = pkg[:i]
 	}
-	return nil
+	return candidates
+}
+
+// declaredTypes maps the full name of every message and enum declared in
+// fdProtos, at any nesting level, to the path of the file declaring it.
+func declaredTypes(fdProtos []*descriptorpb.FileDescriptorProto) map[string]string {
+	declared := make(map[string]string)
+	var addMessage func(msg *descriptorpb.DescriptorProto, scope, path string)
+	addMessage = func(msg *descriptorpb.DescriptorProto, scope, path string) {
+		name := scope + msg.GetName()
+		declared[name] = path
+		for _, e := range msg.GetEnumType() {
+			declared[name+"."+e.GetName()] = path
+		}
+		for _, nested := range msg.GetNestedType() {
+			addMessage(nested, name+".", path)
+		}
+	}
+	for _, fd := range fdProtos {
+		scope := ""
+		if pkg := fd.GetPackage(); pkg != "" {
+			scope = pkg + "."
+		}
+		for _, msg := range fd.GetMessageType() {
+			addMessage(msg, scope, fd.GetName())
+		}
+		for _, e := range fd.GetEnumType() {
+			declared[scope+e.GetName()] = fd.GetName()
+		}
+	}
+	return declared
+}
+
+// fixImportsFromSet adds imports for type references that resolve to
+// another file of the same set (see declaredTypes). Names defined in the
+// global registry are left to fixMissingImports, so canonical well-known
+// types win. Returns true if any imports were added.
+func fixImportsFromSet(fd *descriptorpb.FileDescriptorProto, declared map[string]string) bool {
+	existing := make(map[string]bool, len(fd.GetDependency()))
+	for _, d := range fd.GetDependency() {
+		existing[d] = true
+	}
+
+	added := false
+	for _, ref := range collectTypeRefs(fd) {
+		name := strings.TrimPrefix(ref, ".")
+		if name == "" {
+			continue
+		}
+		for _, candidate := range typeRefCandidates(name, fd.GetPackage()) {
+			if _, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(candidate)); err == nil {
+				break
+			}
+			path, ok := declared[candidate]
+			if !ok {
+				continue
+			}
+			if path != fd.GetName() && !existing[path] {
+				fd.Dependency = append(fd.Dependency, path)
+				existing[path] = true
+				added = true
+			}
+			break
+		}
+	}
+	return added
+}
+
+// orderByDependency returns fdProtos with every file after the files of the
+// set it imports, otherwise keeping the given order. Import cycles are
+// broken at the first file revisited.
+func orderByDependency(fdProtos []*descriptorpb.FileDescriptorProto) []*descriptorpb.FileDescriptorProto {
+	byPath := make(map[string]*descriptorpb.FileDescriptorProto, len(fdProtos))
+	for _, fd := range fdProtos {
+		byPath[fd.GetName()] = fd
+	}
+
+	ordered := make([]*descriptorpb.FileDescriptorProto, 0, len(fdProtos))
+	visited := make(map[*descriptorpb.FileDescriptorProto]bool, len(fdProtos))
+	var visit func(fd *descriptorpb.FileDescriptorProto)
+	visit = func(fd *descriptorpb.FileDescriptorProto) {
+		if visited[fd] {
+			return
+		}
+		visited[fd] = true
+		for _, dep := range fd.GetDependency() {
+			if d, ok := byPath[dep]; ok {
+				visit(d)
+			}
+		}
+		ordered = append(ordered, fd)
+	}
+	for _, fd := range fdProtos {
+		visit(fd)
+	}
+	return ordered
 }
 
 // collectTypeRefs collects all type name references from a FileDescriptorProto,
@@ -554,7 +956,8 @@ func collectFieldTypeRef(field *descriptorpb.FieldDescriptorProto, refs *[]strin
 // with names that don't match protobuf's expected convention of CamelCase(field_name)+"Entry".
 // For example, a field "competitions" might have entry "CompetitionEntry" instead of
 // "CompetitionsEntry". protodesc rejects these with "incorrect implicit map entry name".
-func fixMapEntryNames(fd *descriptorpb.FileDescriptorProto) bool {
+// Each rename is recorded in trace.
+func fixMapEntryNames(fd *descriptorpb.FileDescriptorProto, trace *TraceCollector) bool {
 	pkg := fd.GetPackage()
 	fixed := false
 	for _, msg := range fd.GetMessageType() {
@@ -563,14 +966,21 @@ func fixMapEntryNames(fd *descriptorpb.FileDescriptorProto) bool {
 			fqn += "."
 		}
 		fqn += msg.GetName()
-		if fixMapEntriesInMessage(msg, fqn) {
+		if fixMapEntriesInMessage(msg, fqn, fd.GetName(), trace) {
 			fixed = true
 		}
 	}
 	return fixed
 }
 
-func fixMapEntriesInMessage(msg *descriptorpb.DescriptorProto, fqn string) bool {
+// mapEntryRename is a map field whose entry is to be renamed.
+type mapEntryRename struct {
+	field *descriptorpb.FieldDescriptorProto
+	entry *descriptorpb.DescriptorProto
+	name  string
+}
+
+func fixMapEntriesInMessage(msg *descriptorpb.DescriptorProto, fqn, file string, trace *TraceCollector) bool {
 	fixed := false
 
 	// Recurse into nested types (non-map-entry messages can also have map fields)
@@ -579,57 +989,211 @@ func fixMapEntriesInMessage(msg *descriptorpb.DescriptorProto, fqn string) bool
 			continue
 		}
 		nestedFQN := fqn + "." + nested.GetName()
-		if fixMapEntriesInMessage(nested, nestedFQN) {
+		if fixMapEntriesInMessage(nested, nestedFQN, file, trace) {
 			fixed = true
 		}
 	}
 
-	// For each field, check if it references a map entry with wrong name.
-	// TypeNames may be fully-qualified (".pkg.Msg.Entry") or relative ("Entry",
-	// "Msg.Entry") depending on the server's proto tooling.
+	// Associate each field with the map entry it references before renaming
+	// anything, so one rename can't redirect another field's match.
+	// TypeNames may be fully-qualified (".pkg.Msg.Entry") or relative
+	// ("Entry", "Msg.Entry") depending on the server's proto tooling.
+	var renames []mapEntryRename
+	users := make(map[*descriptorpb.DescriptorProto]int)
 	for _, field := range msg.GetField() {
-		typeName := field.GetTypeName()
-		if typeName == "" {
+		if field.GetTypeName() == "" || field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+			// Groups also name a nested type, but never a map entry
+			continue
+		}
+		entry, ok := mapEntryForField(msg, fqn, field.GetTypeName())
+		if !ok {
+			trace.logger.Debug("skipped map field matching several entries",
+				slog.String("message", fqn),
+				slog.String("field", field.GetName()),
+				slog.String("type", field.GetTypeName()),
+			)
+			continue
+		}
+		if entry == nil {
 			continue
 		}
+		users[entry]++
+		renames = append(renames, mapEntryRename{field: field, entry: entry, name: mapEntryName(field.GetName())})
+	}
 
-		// Find the map entry nested type this field references
-		for _, nested := range msg.GetNestedType() {
-			if !nested.GetOptions().GetMapEntry() {
-				continue
-			}
-			entryName := nested.GetName()
-			absRef := "." + fqn + "." + entryName
+	// An entry shared by two fields can't be named after both of them
+	renames = slices.DeleteFunc(renames, func(r mapEntryRename) bool {
+		if users[r.entry] > 1 {
+			trace.logger.Debug("skipped map entry referenced by several fields",
+				slog.String("message", fqn),
+				slog.String("entry", r.entry.GetName()),
+				slog.String("field", r.field.GetName()),
+			)
+			return true
+		}
+		return r.entry.GetName() == r.name
+	})
+	if len(renames) == 0 {
+		return fixed
+	}
+
+	// Names the renamed entries may not take. Their own current names are
+	// free, so two entries with swapped names are simply exchanged.
+	taken := make(map[string]bool)
+	for _, nested := range msg.GetNestedType() {
+		taken[nested.GetName()] = true
+	}
+	for _, enum := range msg.GetEnumType() {
+		taken[enum.GetName()] = true
+	}
+	for _, field := range msg.GetField() {
+		taken[field.GetName()] = true
+	}
+	for _, r := range renames {
+		delete(taken, r.entry.GetName())
+	}
+
+	for _, r := range renames {
+		name := r.name
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s%d", r.name, n)
+		}
+		if name != r.name {
+			trace.logger.Debug("map entry name already in use, disambiguated",
+				slog.String("message", fqn),
+				slog.String("field", r.field.GetName()),
+				slog.String("entry", name),
+			)
+		}
+		taken[name] = true
+
+		// Update the field's TypeName, preserving relative/absolute form
+		oldName := r.entry.GetName()
+		absRef := "." + fqn + "." + oldName
+		correctRef := strings.TrimSuffix(r.field.GetTypeName(), oldName) + name
+		if r.field.GetTypeName() == absRef {
+			correctRef = "." + fqn + "." + name
+		}
+		trace.fixed(file, fmt.Sprintf("renamed map entry %s.%s to %s for field %s", fqn, oldName, name, r.field.GetName()))
+		r.field.TypeName = &correctRef
+		r.entry.Name = &name
+		fixed = true
+	}
+	return fixed
+}
+
+// mapEntryForField finds the map entry nested in msg (whose full name is
+// fqn) that typeName refers to: the one it names exactly, else the only one
+// whose full name ends with it. It returns nil if none matches, and false if
+// several match equally well.
+func mapEntryForField(msg *descriptorpb.DescriptorProto, fqn, typeName string) (*descriptorpb.DescriptorProto, bool) {
+	var exact, suffix []*descriptorpb.DescriptorProto
+	for _, nested := range msg.GetNestedType() {
+		if !nested.GetOptions().GetMapEntry() {
+			continue
+		}
+		entryName := nested.GetName()
+		absRef := "." + fqn + "." + entryName
+		switch {
+		case typeName == absRef || typeName == entryName:
+			exact = append(exact, nested)
+		case strings.HasSuffix(absRef, "."+typeName):
+			suffix = append(suffix, nested)
+		}
+	}
+	for _, matches := range [][]*descriptorpb.DescriptorProto{exact, suffix} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], true
+		}
+		return nil, false
+	}
+	return nil, true
+}
+
+// fixGroupFields fixes proto2 group fields that protoc would never emit but
+// some legacy servers do. A group field must be named after its nested
+// message, lowercased ("result" for group Result); servers that reuse the
+// message name verbatim make the field collide with the message itself.
+// Relative group TypeNames are made absolute so they cannot be mistaken
+// for package-level types. Returns true if anything was changed.
+func fixGroupFields(fd *descriptorpb.FileDescriptorProto) bool {
+	fixed := false
+	for _, msg := range fd.GetMessageType() {
+		if fixGroupFieldsInMessage(msg, qualify(fd.GetPackage(), msg.GetName())) {
+			fixed = true
+		}
+	}
+	return fixed
+}
+
+func fixGroupFieldsInMessage(msg *descriptorpb.DescriptorProto, fqn string) bool {
+	fixed := false
+	for _, nested := range msg.GetNestedType() {
+		if fixGroupFieldsInMessage(nested, fqn+"."+nested.GetName()) {
+			fixed = true
+		}
+	}
 
-			// Match both absolute and relative TypeName forms
-			if typeName != absRef && typeName != entryName &&
-				!strings.HasSuffix(absRef, "."+typeName) {
+	for _, field := range msg.GetField() {
+		if field.GetType() != descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+			continue
+		}
+		typeName := field.GetTypeName()
+		for _, nested := range msg.GetNestedType() {
+			groupName := nested.GetName()
+			absRef := "." + fqn + "." + groupName
+			if typeName != absRef && typeName != groupName && !strings.HasSuffix(absRef, "."+typeName) {
 				continue
 			}
 
-			// This field references this map entry. Check the name.
-			expectedName := mapEntryName(field.GetName())
-			if entryName == expectedName {
-				break // already correct
+			if typeName != absRef {
+				field.TypeName = &absRef
+				fixed = true
 			}
-			// Fix the entry name
-			nested.Name = &expectedName
-			// Update field's TypeName, preserving relative/absolute form
-			if typeName == absRef {
-				correctRef := "." + fqn + "." + expectedName
-				field.TypeName = &correctRef
-			} else {
-				// Replace the last path component (the entry name) with the corrected one
-				correctRef := strings.TrimSuffix(typeName, entryName) + expectedName
-				field.TypeName = &correctRef
+			if want := strings.ToLower(groupName); field.GetName() != want {
+				// A JSON name derived from the old field name goes with it
+				if field.JsonName != nil && field.GetJsonName() == jsonCamelCase(field.GetName()) {
+					field.JsonName = nil
+				}
+				field.Name = &want
+				fixed = true
 			}
-			fixed = true
 			break
 		}
 	}
 	return fixed
 }
 
+// qualify joins a package and a name into a full name.
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+// jsonCamelCase returns the JSON name protoc derives for a field name:
+// underscores dropped and the letter after each one uppercased.
+func jsonCamelCase(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
 // fixReservedRanges fixes invalid reserved ranges in all messages of a FileDescriptorProto.
 // Some servers produce ranges where end <= start (e.g., start=2, end=2), which is invalid
 // because protobuf reserved ranges are end-exclusive: [start, end). We fix these by
@@ -688,7 +1252,10 @@ func (r *ReflectionClient) convertService(sd protoreflect.ServiceDescriptor) dom
 	service := domain.Service{
 		Name:     string(sd.Name()),
 		FullName: string(sd.FullName()),
+		File:     sd.ParentFile().Path(),
 		Methods:  make([]domain.Method, 0, methods.Len()),
+
+		Deprecated: protoconv.IsDeprecated(sd),
 	}
 
 	for i := range methods.Len() {
@@ -700,6 +1267,8 @@ func (r *ReflectionClient) convertService(sd protoreflect.ServiceDescriptor) dom
 			OutputType:     string(md.Output().FullName()),
 			IsClientStream: md.IsStreamingClient(),
 			IsServerStream: md.IsStreamingServer(),
+			Deprecated:     service.Deprecated || protoconv.IsDeprecated(md),
+			Error:          methodError(md),
 		}
 		service.Methods = append(service.Methods, method)
 	}
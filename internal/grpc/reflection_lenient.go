@@ -0,0 +1,202 @@
+package grpc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// lenientFiles holds the files lenient resolution has received and built
+// over a ReflectionClient's session. It is shared by every service the
+// client resolves, so a file many services live in is fetched and built
+// once, and those services share its descriptors.
+type lenientFiles struct {
+	mu sync.Mutex
+
+	// received holds every file the server sent, by name
+	received map[string]lenientFile
+
+	// pending is set when files arrived after the last build
+	pending bool
+
+	// resolver.local holds the files built so far
+	resolver *combinedResolver
+
+	// stubs holds services-only builds of files that could not be built,
+	// by file name
+	stubs map[string]protoreflect.FileDescriptor
+
+	// builds counts the times received files were built
+	builds int
+}
+
+// lenientFile is a file as received, with the hash of its encoding.
+type lenientFile struct {
+	fdp  *descriptorpb.FileDescriptorProto
+	hash [sha256.Size]byte
+}
+
+func newLenientFiles() *lenientFiles {
+	l := &lenientFiles{}
+	l.reset()
+	return l
+}
+
+// clear forgets everything received and built.
+func (l *lenientFiles) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reset()
+}
+
+func (l *lenientFiles) reset() {
+	l.received = make(map[string]lenientFile)
+	l.dropBuilt()
+	l.pending = false
+}
+
+// dropBuilt discards the built files, leaving the received ones to be
+// built again.
+func (l *lenientFiles) dropBuilt() {
+	l.resolver = &combinedResolver{local: new(protoregistry.Files), global: protoregistry.GlobalFiles}
+	l.stubs = make(map[string]protoreflect.FileDescriptor)
+	l.pending = len(l.received) > 0
+}
+
+// add records a file received from the server and returns it, or nil when
+// the same file was already received. A file whose content changed after
+// it was built drops everything built, since other files were built
+// against it.
+func (l *lenientFiles) add(raw []byte) (*descriptorpb.FileDescriptorProto, error) {
+	fdp := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(raw, fdp); err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(raw)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if prev, ok := l.received[fdp.GetName()]; ok {
+		if prev.hash == hash {
+			return nil, nil
+		}
+		if _, err := l.resolver.local.FindFileByPath(fdp.GetName()); err == nil {
+			l.dropBuilt()
+		}
+	}
+	l.received[fdp.GetName()] = lenientFile{fdp: fdp, hash: hash}
+	l.pending = true
+	return fdp, nil
+}
+
+// declares reports whether a received file declares the service fullName.
+func (l *lenientFiles) declares(fullName string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, f := range l.received {
+		if declaresService(f.fdp, fullName) {
+			return true
+		}
+	}
+	return false
+}
+
+// missing returns the imports of received files that were neither
+// received nor are known to the global registry, sorted.
+func (l *lenientFiles) missing() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	needed := make(map[string]bool)
+	for _, f := range l.received {
+		for _, dep := range f.fdp.GetDependency() {
+			if _, ok := l.received[dep]; ok {
+				continue
+			}
+			if _, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+				continue
+			}
+			needed[dep] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(needed))
+}
+
+// service returns the service fullName if it has been built.
+func (l *lenientFiles) service(fullName string) (protoreflect.ServiceDescriptor, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lookup(fullName)
+}
+
+func (l *lenientFiles) lookup(fullName string) (protoreflect.ServiceDescriptor, bool) {
+	if d, err := l.resolver.local.FindDescriptorByName(protoreflect.FullName(fullName)); err == nil {
+		if sd, ok := d.(protoreflect.ServiceDescriptor); ok {
+			return sd, true
+		}
+	}
+	name := protoreflect.FullName(fullName).Name()
+	for _, fd := range l.stubs {
+		if sd := fd.Services().ByName(name); sd != nil && string(sd.FullName()) == fullName {
+			return sd, true
+		}
+	}
+	return nil, false
+}
+
+// resolve builds the files received since the last build and returns the
+// service fullName from them. When the file declaring it cannot be built,
+// its services are built on their own (see buildServicesOnly).
+func (l *lenientFiles) resolve(fullName string, trace *TraceCollector) (protoreflect.ServiceDescriptor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending {
+		var unbuilt []*descriptorpb.FileDescriptorProto
+		for _, name := range slices.Sorted(maps.Keys(l.received)) {
+			if _, err := l.resolver.local.FindFileByPath(name); err != nil {
+				unbuilt = append(unbuilt, l.received[name].fdp)
+			}
+		}
+		buildInto(l.resolver, unbuilt, trace)
+		l.pending = false
+		l.builds++
+	}
+	if sd, ok := l.lookup(fullName); ok {
+		return sd, nil
+	}
+
+	// The file declaring the service may be stuck on one of its messages;
+	// its services can still be built without them.
+	for _, name := range slices.Sorted(maps.Keys(l.received)) {
+		fdp := l.received[name].fdp
+		if !declaresService(fdp, fullName) {
+			continue
+		}
+		fd, err := buildServicesOnly(fdp, l.resolver.local, trace)
+		if err != nil {
+			trace.logger.Debug("services-only build failed",
+				slog.String("file", fdp.GetName()),
+				slog.Any("error", err),
+			)
+			break
+		}
+		l.stubs[name] = fd
+		if sd, ok := l.lookup(fullName); ok {
+			return sd, nil
+		}
+		break
+	}
+
+	if l.resolver.local.NumFiles() == 0 {
+		return nil, fmt.Errorf("no files could be built from %d protos", len(l.received))
+	}
+	return nil, fmt.Errorf("service %s not found after lenient parsing", fullName)
+}
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestLenientResolve_ServicesShareFile(t *testing.T) {
+	// A second service in event_service.proto, which only builds leniently
+	files := grpctest.NonCanonicalFiles()
+	files[0].Service = append(files[0].Service, &descriptorpb.ServiceDescriptorProto{
+		Name: proto.String("AuditService"),
+		Method: []*descriptorpb.MethodDescriptorProto{{
+			Name:       proto.String("GetEvent"),
+			InputType:  proto.String(".custom.event.v1.GetEventRequest"),
+			OutputType: proto.String(".custom.event.v1.Event"),
+		}},
+	})
+	srv := grpctest.StartServer(t, grpctest.WithReflectionFiles(files...))
+
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	defer rc.Close()
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+	for _, svc := range services {
+		assert.Empty(t, svc.Error, svc.FullName)
+	}
+	assert.Equal(t, 1, rc.lenient.builds, "the shared file is built once")
+
+	events, err := rc.GetMethodDescriptor("custom.event.v1.EventService", "GetEvent")
+	require.NoError(t, err)
+	audit, err := rc.GetMethodDescriptor("custom.event.v1.AuditService", "GetEvent")
+	require.NoError(t, err)
+	assert.Same(t, events.ParentFile(), audit.ParentFile())
+	assert.Same(t, events.Output(), audit.Output(), "message types are the same descriptors")
+	assert.Equal(t, 1, rc.lenient.builds)
+}
+
+func TestLenientFiles_Add(t *testing.T) {
+	raw := func(fdp *descriptorpb.FileDescriptorProto) []byte {
+		b, err := proto.Marshal(fdp)
+		require.NoError(t, err)
+		return b
+	}
+	fdp := makeServiceFDP([]string{"google/protobuf/timestamp.proto"})
+	files := newLenientFiles()
+
+	got, err := files.add(raw(fdp))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	got, err = files.add(raw(fdp))
+	require.NoError(t, err)
+	assert.Nil(t, got, "the same file is only received once")
+
+	_, err = files.resolve("test.noncanonical.v1.NonCanonicalService", newTraceCollector(testLogger))
+	require.NoError(t, err)
+	_, err = files.resolve("test.noncanonical.v1.NonCanonicalService", newTraceCollector(testLogger))
+	require.NoError(t, err)
+	assert.Equal(t, 1, files.builds, "nothing new to build")
+
+	// A changed file replaces the built one
+	changed := proto.Clone(fdp).(*descriptorpb.FileDescriptorProto)
+	changed.Service[0].Method = append(changed.Service[0].Method, &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("GetItemAgain"),
+		InputType:  proto.String(".test.noncanonical.v1.GetItemRequest"),
+		OutputType: proto.String(".test.noncanonical.v1.Item"),
+	})
+	got, err = files.add(raw(changed))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	sd, err := files.resolve("test.noncanonical.v1.NonCanonicalService", newTraceCollector(testLogger))
+	require.NoError(t, err)
+	assert.Equal(t, 2, files.builds)
+	assert.Equal(t, 2, sd.Methods().Len())
+
+	_, err = files.add([]byte("not a descriptor"))
+	assert.Error(t, err)
+}
+
+func TestReflectionClient_Diagnose(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...))
+	rc := NewReflectionClient(srv.Conn, testLogger)
+	defer rc.Close()
+
+	report, err := rc.Diagnose(context.Background(), "custom.event.v1.EventService")
+	require.NoError(t, err)
+	assert.Empty(t, report.Error)
+	require.Len(t, report.Files, 4)
+	svc := report.Files[0]
+	assert.Equal(t, "event_service.proto", svc.Name)
+	assert.Equal(t, "file containing custom.event.v1.EventService", svc.Source)
+	assert.Empty(t, svc.Dependencies)
+	assert.Contains(t, svc.Fixes, "renamed map entry custom.event.v1.EventsByOrg.EventsByOrg to EventsByOrgEntry for field events_by_org")
+	assert.Contains(t, svc.Fixes, "added missing import common.proto, which declares types it uses")
+	for _, ft := range report.Files {
+		assert.True(t, ft.Built, ft.Name)
+	}
+	assert.Zero(t, rc.lenient.builds, "the session's files are left alone")
+
+	// A symbol the server does not know is reported, not returned
+	report, err = rc.Diagnose(context.Background(), "custom.event.v1.Missing")
+	require.NoError(t, err)
+	assert.NotEmpty(t, report.Error)
+	assert.Empty(t, report.Files)
+
+	local, err := NewReflectionClientFromDescriptors(nil, grpctest.NonCanonicalFiles(), testLogger)
+	require.NoError(t, err)
+	_, err = local.Diagnose(context.Background(), "custom.event.v1.EventService")
+	assert.Error(t, err, "only reflection can be diagnosed")
+}
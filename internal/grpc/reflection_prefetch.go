@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Prefetch tuning. A server answers the requests on one reflection stream
+// in order, so requests are pipelined in batches to save round trips and
+// spread over a few streams so the server can work on several at once.
+const (
+	prefetchStreams   = 4
+	prefetchBatchSize = 32
+)
+
+// prefetchServices fetches the files declaring serviceNames, and the files
+// they import, over a small pool of reflection streams. Each file is
+// requested at most once, however many services share it. It returns the
+// services that build cleanly from the fetched files; the caller resolves
+// the rest one at a time, which is what reports their errors.
+func (r *ReflectionClient) prefetchServices(ctx context.Context, serviceNames []protoreflect.FullName) map[protoreflect.FullName]protoreflect.ServiceDescriptor {
+	if len(serviceNames) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streams, err := r.openPrefetchStreams(ctx, min(prefetchStreams, len(serviceNames)))
+	if err != nil {
+		r.logger.Debug("reflection prefetch unavailable, resolving services one at a time",
+			slog.Any("error", err))
+		return nil
+	}
+	defer func() {
+		for _, s := range streams {
+			_ = s.CloseSend()
+		}
+	}()
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	requested := make(map[string]bool)
+	reqs := make([]*reflectionpb.ServerReflectionRequest, len(serviceNames))
+	for i, name := range serviceNames {
+		reqs[i] = &reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: string(name),
+			},
+		}
+	}
+
+	for len(reqs) > 0 {
+		for _, resp := range fetchPipelined(streams, reqs) {
+			for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+				fd := &descriptorpb.FileDescriptorProto{}
+				if err := proto.Unmarshal(raw, fd); err != nil {
+					continue
+				}
+				if _, ok := files[fd.GetName()]; !ok {
+					files[fd.GetName()] = fd
+				}
+			}
+		}
+
+		// Request the imports no response has carried yet
+		reqs = reqs[:0]
+		for _, fd := range files {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := files[dep]; ok || requested[dep] {
+					continue
+				}
+				if _, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+					continue
+				}
+				requested[dep] = true
+				reqs = append(reqs, &reflectionpb.ServerReflectionRequest{
+					MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{
+						FileByFilename: dep,
+					},
+				})
+			}
+		}
+	}
+
+	built := buildStrict(files, r.logger)
+	found := make(map[protoreflect.FullName]protoreflect.ServiceDescriptor, len(serviceNames))
+	for _, name := range serviceNames {
+		if d, err := built.FindDescriptorByName(name); err == nil {
+			if sd, ok := d.(protoreflect.ServiceDescriptor); ok {
+				found[name] = sd
+			}
+		}
+	}
+
+	r.logger.Debug("prefetched reflection files",
+		slog.Int("streams", len(streams)),
+		slog.Int("files", len(files)),
+		slog.Int("services", len(found)),
+		slog.Int("remaining", len(serviceNames)-len(found)),
+	)
+	return found
+}
+
+// openPrefetchStreams opens up to n reflection streams. The first settles
+// which reflection version the server speaks; failing to open later ones
+// just leaves a smaller pool.
+func (r *ReflectionClient) openPrefetchStreams(ctx context.Context, n int) ([]reflectionStream, error) {
+	first, _, err := r.openReflection(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	streams := []reflectionStream{first}
+	for len(streams) < n {
+		s, err := newReflectionStream(ctx, r.conn, r.knownReflectionMethod())
+		if err != nil {
+			break
+		}
+		streams = append(streams, s)
+	}
+	return streams, nil
+}
+
+// fetchPipelined sends reqs spread over streams, a batch at a time per
+// stream, and returns the responses in request order. A request whose
+// stream fails has a nil response.
+func fetchPipelined(streams []reflectionStream, reqs []*reflectionpb.ServerReflectionRequest) []*reflectionpb.ServerReflectionResponse {
+	resps := make([]*reflectionpb.ServerReflectionResponse, len(reqs))
+	var wg sync.WaitGroup
+	for s, stream := range streams {
+		var mine []int
+		for i := s; i < len(reqs); i += len(streams) {
+			mine = append(mine, i)
+		}
+		wg.Go(func() {
+			for batch := range slices.Chunk(mine, prefetchBatchSize) {
+				for _, i := range batch {
+					if err := stream.Send(reqs[i]); err != nil {
+						return
+					}
+				}
+				for _, i := range batch {
+					resp, err := stream.Recv()
+					if err != nil {
+						return
+					}
+					resps[i] = resp
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return resps
+}
+
+// buildStrict builds every file it can from files without the lenient
+// fixes, in dependency order. Files that fail to build are left out, along
+// with the files importing them.
+func buildStrict(files map[string]*descriptorpb.FileDescriptorProto, logger *slog.Logger) *protoregistry.Files {
+	fdProtos := slices.SortedFunc(maps.Values(files), func(a, b *descriptorpb.FileDescriptorProto) int {
+		return cmp.Compare(a.GetName(), b.GetName())
+	})
+	built := new(protoregistry.Files)
+	resolver := &combinedResolver{local: built, global: protoregistry.GlobalFiles}
+	for _, fdp := range orderByDependency(fdProtos) {
+		fd, err := protodesc.NewFile(fdp, resolver)
+		if err == nil {
+			err = built.RegisterFile(fd)
+		}
+		if err != nil {
+			logger.Debug("prefetched file needs individual resolution",
+				slog.String("file", fdp.GetName()), slog.Any("error", err))
+		}
+	}
+	return built
+}
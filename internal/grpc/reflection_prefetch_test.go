@@ -0,0 +1,202 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// listOneByOne resolves services the way ListServices did before
+// prefetching: one reflection round trip after another on a single stream.
+func listOneByOne(t testing.TB, r *ReflectionClient) []domain.Service {
+	names, err := r.client.ListServices()
+	require.NoError(t, err)
+	var services []domain.Service
+	for _, name := range names {
+		if !isReflectionService(string(name)) {
+			services = append(services, r.resolveService(context.Background(), name))
+		}
+	}
+	return services
+}
+
+func TestListServices_ManyServicesPrefetched(t *testing.T) {
+	const (
+		count   = 200
+		latency = 2 * time.Millisecond
+	)
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionRegistry(grpctest.ManyServiceFiles(count)...),
+		grpctest.WithReflectionLatency(latency),
+	)
+
+	sequential := NewReflectionClient(srv.Conn, testLogger)
+	defer sequential.Close()
+	start := time.Now()
+	want := listOneByOne(t, sequential)
+	oneByOne := time.Since(start)
+
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+	start = time.Now()
+	got, err := client.ListServices(context.Background())
+	prefetched := time.Since(start)
+	require.NoError(t, err)
+
+	require.Len(t, got, count)
+	assert.Equal(t, want, got, "prefetching must not change the listing")
+	for _, svc := range got {
+		assert.Empty(t, svc.Error, svc.FullName)
+	}
+
+	md, err := client.GetMethodDescriptor("many.v1.Service7", "Get")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("many.v1.Response"), md.Output().FullName())
+
+	t.Logf("%d services: %v one by one, %v prefetched", count, oneByOne, prefetched)
+	assert.Less(t, prefetched, oneByOne/2, "prefetching should be several times faster")
+}
+
+func TestListServices_PrefetchFallsBackForMalformedFiles(t *testing.T) {
+	// Non-canonical files fail the strict prefetch build, so the services
+	// go through lenient resolution exactly as before
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...),
+		grpctest.WithHealth(),
+	)
+
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+	got, err := client.ListServices(context.Background())
+	require.NoError(t, err)
+
+	sequential := NewReflectionClient(srv.Conn, testLogger)
+	defer sequential.Close()
+	assert.Equal(t, listOneByOne(t, sequential), got)
+}
+
+// BenchmarkListServices compares listing a large server with and without
+// prefetching. Run with -bench ListServices.
+func BenchmarkListServices(b *testing.B) {
+	for _, count := range []int{50, 300} {
+		srv := grpctest.StartServer(b,
+			grpctest.WithReflectionRegistry(grpctest.ManyServiceFiles(count)...),
+			grpctest.WithReflectionLatency(time.Millisecond),
+		)
+		b.Run(fmt.Sprintf("prefetched/%d", count), func(b *testing.B) {
+			for b.Loop() {
+				r := NewReflectionClient(srv.Conn, testLogger)
+				if _, err := r.ListServices(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				r.Close()
+			}
+		})
+		b.Run(fmt.Sprintf("one-by-one/%d", count), func(b *testing.B) {
+			for b.Loop() {
+				r := NewReflectionClient(srv.Conn, testLogger)
+				listOneByOne(b, r)
+				r.Close()
+			}
+		})
+	}
+}
+
+// unresolvableFile declares a service whose method names a type no file
+// declares, so it only resolves leniently, with its own round trips.
+func unresolvableFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("partial/v1/partial.proto"),
+		Package: proto.String("partial.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Ping")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("PartialService"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Watch"),
+				InputType:  proto.String(".partial.missing.v1.WatchRequest"),
+				OutputType: proto.String(".partial.v1.Ping"),
+			}},
+		}},
+	}
+}
+
+func TestListServicesFunc_ReportsAsResolved(t *testing.T) {
+	// Neither service survives the strict prefetch build, so each is
+	// resolved after the other over a slow link
+	const latency = 20 * time.Millisecond
+	files := append(grpctest.NonCanonicalFiles(), unresolvableFile())
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionFiles(files...),
+		grpctest.WithReflectionLatency(latency),
+	)
+
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+	var events []string
+	client.SetOnProgress(func(resolved, total int) {
+		events = append(events, fmt.Sprintf("%d/%d", resolved, total))
+	})
+	var reported []domain.Service
+	var firstReport time.Time
+	got, err := client.ListServicesFunc(context.Background(), func(svc domain.Service) {
+		if firstReport.IsZero() {
+			firstReport = time.Now()
+		}
+		reported = append(reported, svc)
+		events = append(events, svc.FullName)
+	})
+	done := time.Now()
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, got, reported, "every service is reported once, as returned")
+	assert.Equal(t, []string{
+		"0/2", "0/2",
+		got[0].FullName, "1/2",
+		got[1].FullName, "2/2",
+	}, events, "each service is reported as soon as it is resolved")
+	assert.GreaterOrEqual(t, done.Sub(firstReport), latency, "the first service is reported while the next is still resolving")
+
+	methods := make(map[string]string)
+	for _, svc := range reported {
+		for _, m := range svc.Methods {
+			methods[m.FullName] = m.Error
+		}
+	}
+	assert.NotEmpty(t, methods["partial.v1.PartialService.Watch"], "failures are reported too")
+	assert.Empty(t, methods["custom.event.v1.EventService.GetEvent"])
+}
+
+func TestListServicesFunc_Prefetched(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionRegistry(grpctest.ManyServiceFiles(5)...))
+
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+	var reported []string
+	got, err := client.ListServicesFunc(context.Background(), func(svc domain.Service) {
+		reported = append(reported, svc.FullName)
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 5)
+	var names []string
+	for _, svc := range got {
+		names = append(names, svc.FullName)
+	}
+	assert.Equal(t, names, reported)
+
+	// The slice API lists the same services without reporting them
+	again, err := client.ListServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, got, again)
+}
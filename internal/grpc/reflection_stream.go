@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Server reflection stream methods. v1alpha is what servers built before
+// v1 register; its messages are wire-compatible with v1's, so both are
+// driven through the v1 message types.
+const (
+	reflectionV1Method      = "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"
+	reflectionV1AlphaMethod = "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"
+)
+
+// reflectionStream is a raw server reflection stream, independent of the
+// reflection service version behind it.
+type reflectionStream interface {
+	Send(*reflectionpb.ServerReflectionRequest) error
+	Recv() (*reflectionpb.ServerReflectionResponse, error)
+	CloseSend() error
+}
+
+// newReflectionStream opens a reflection stream on the given method.
+func newReflectionStream(ctx context.Context, conn grpc.ClientConnInterface, method string) (reflectionStream, error) {
+	desc := &grpc.StreamDesc{
+		StreamName:    "ServerReflectionInfo",
+		ServerStreams: true,
+		ClientStreams: true,
+	}
+	cs, err := conn.NewStream(ctx, desc, method)
+	if err != nil {
+		return nil, err
+	}
+	return &grpc.GenericClientStream[reflectionpb.ServerReflectionRequest, reflectionpb.ServerReflectionResponse]{ClientStream: cs}, nil
+}
+
+// openReflection opens a reflection stream and sends req, returning the
+// stream and its first response. It uses v1 unless the server answers
+// Unimplemented, then retries over v1alpha. The version that worked is
+// remembered for later streams.
+func (r *ReflectionClient) openReflection(ctx context.Context, req *reflectionpb.ServerReflectionRequest) (reflectionStream, *reflectionpb.ServerReflectionResponse, error) {
+	methods := []string{reflectionV1Method, reflectionV1AlphaMethod}
+	if known := r.knownReflectionMethod(); known != "" {
+		methods = []string{known}
+	}
+
+	var lastErr error
+	for _, method := range methods {
+		stream, resp, err := roundTrip(ctx, r.conn, method, req)
+		if err == nil {
+			r.mu.Lock()
+			r.reflectionMethod = method
+			r.mu.Unlock()
+			return stream, resp, nil
+		}
+		lastErr = err
+		if status.Code(err) != codes.Unimplemented {
+			break
+		}
+		r.logger.Debug("reflection service unimplemented, trying next version",
+			slog.String("method", method))
+	}
+	return nil, nil, lastErr
+}
+
+// roundTrip opens a stream on method, sends req and receives the response.
+func roundTrip(ctx context.Context, conn grpc.ClientConnInterface, method string, req *reflectionpb.ServerReflectionRequest) (reflectionStream, *reflectionpb.ServerReflectionResponse, error) {
+	stream, err := newReflectionStream(ctx, conn, method)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	}
+	if err := stream.Send(req); err != nil {
+		_ = stream.CloseSend()
+		return nil, nil, fmt.Errorf("failed to send reflection request: %w", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		_ = stream.CloseSend()
+		return nil, nil, fmt.Errorf("failed to receive reflection response: %w", err)
+	}
+	return stream, resp, nil
+}
+
+// knownReflectionMethod returns the reflection stream method that last
+// worked, or "" before the first successful stream.
+func (r *ReflectionClient) knownReflectionMethod() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.reflectionMethod
+}
@@ -2,19 +2,15 @@ package grpc
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"log/slog"
-	"net"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/shhac/grotto/internal/domain"
-	googlegrpc "google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -43,7 +39,7 @@ func TestFixMissingImports_AddsResolvableImport(t *testing.T) {
 		Dependency: []string{},
 	}
 
-	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardLogger)
+	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardTrace())
 	if !added {
 		t.Fatal("expected fixMissingImports to return true")
 	}
@@ -80,7 +76,7 @@ func TestFixMissingImports_NoDuplicates(t *testing.T) {
 		},
 	}
 
-	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardLogger)
+	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardTrace())
 	if added {
 		t.Error("expected fixMissingImports to return false when import already exists")
 	}
@@ -113,7 +109,7 @@ func TestFixMissingImports_ReturnsFalseWhenNothingNeeded(t *testing.T) {
 		Dependency: []string{},
 	}
 
-	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardLogger)
+	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardTrace())
 	if added {
 		t.Error("expected fixMissingImports to return false for scalar-only fields")
 	}
@@ -143,7 +139,7 @@ func TestFixMissingImports_HandlesServiceMethods(t *testing.T) {
 		Dependency: []string{},
 	}
 
-	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardLogger)
+	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardTrace())
 	if !added {
 		t.Fatal("expected fixMissingImports to return true")
 	}
@@ -186,7 +182,7 @@ func TestFixMissingImports_HandlesNestedMessages(t *testing.T) {
 		Dependency: []string{},
 	}
 
-	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardLogger)
+	added := fixMissingImports(fd, protoregistry.GlobalFiles, discardTrace())
 	if !added {
 		t.Fatal("expected fixMissingImports to return true")
 	}
@@ -206,6 +202,11 @@ func TestFixMissingImports_HandlesNestedMessages(t *testing.T) {
 
 var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
 
+// discardTrace returns a trace collector that logs nowhere.
+func discardTrace() *TraceCollector {
+	return newTraceCollector(discardLogger)
+}
+
 // makeServiceFDP creates a FileDescriptorProto mimicking a server's service file.
 // The service has one method: GetItem(GetItemRequest) returns (Item).
 // Item.created_at is of type .google.protobuf.Timestamp.
@@ -293,7 +294,7 @@ func TestBuildFileDescriptors_CanonicalImport(t *testing.T) {
 	// Should work without fixMissingImports since GlobalFiles has it.
 	svcFDP := makeServiceFDP([]string{"google/protobuf/timestamp.proto"})
 
-	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardLogger)
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardTrace())
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
 	}
@@ -314,7 +315,7 @@ func TestBuildFileDescriptors_NonCanonicalWKTProvided(t *testing.T) {
 	wktFDP := makeNonCanonicalTimestampFDP()
 	svcFDP := makeServiceFDP([]string{"google_protobuf.proto"})
 
-	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP, wktFDP}, discardLogger)
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP, wktFDP}, discardTrace())
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
 	}
@@ -333,7 +334,7 @@ func TestBuildFileDescriptors_MissingImportEntirely(t *testing.T) {
 	// fixMissingImports should add google/protobuf/timestamp.proto from GlobalFiles.
 	svcFDP := makeServiceFDP(nil)
 
-	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardLogger)
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardTrace())
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
 	}
@@ -349,7 +350,7 @@ func TestBuildFileDescriptors_NonCanonicalWKTNotProvided(t *testing.T) {
 	// is NOT provided. fixMissingImports should add canonical import from GlobalFiles.
 	svcFDP := makeServiceFDP([]string{"google_protobuf.proto"})
 
-	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardLogger)
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardTrace())
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
 	}
@@ -400,7 +401,7 @@ func TestBuildFileDescriptors_MultipleWKTs(t *testing.T) {
 		},
 	}
 
-	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardLogger)
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP}, discardTrace())
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
 	}
@@ -519,7 +520,7 @@ func TestBuildFileDescriptors_FourFilesWithCrossRefs(t *testing.T) {
 	t.Run("ServiceFirst", func(t *testing.T) {
 		files, err := buildFileDescriptors(
 			[]*descriptorpb.FileDescriptorProto{svcFDP, wktFDP, commonFDP, typeFDP},
-			discardLogger,
+			discardTrace(),
 		)
 		if err != nil {
 			t.Fatalf("buildFileDescriptors failed: %v", err)
@@ -534,7 +535,7 @@ func TestBuildFileDescriptors_FourFilesWithCrossRefs(t *testing.T) {
 	t.Run("BarrelFirst", func(t *testing.T) {
 		files, err := buildFileDescriptors(
 			[]*descriptorpb.FileDescriptorProto{wktFDP, typeFDP, commonFDP, svcFDP},
-			discardLogger,
+			discardTrace(),
 		)
 		if err != nil {
 			t.Fatalf("buildFileDescriptors failed: %v", err)
@@ -601,7 +602,7 @@ func TestBuildFileDescriptors_DependencyOrdering(t *testing.T) {
 	}
 
 	// Provide in wrong order: service before common
-	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP, commonFDP}, discardLogger)
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP, commonFDP}, discardTrace())
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
 	}
@@ -610,6 +611,332 @@ func TestBuildFileDescriptors_DependencyOrdering(t *testing.T) {
 	if sd == nil {
 		t.Fatal("expected to find ListService")
 	}
+	pagination := sd.Methods().Get(0).Input().Fields().ByName("pagination").Message()
+	if pagination.IsPlaceholder() {
+		t.Error("expected Pagination to resolve from common.proto, got a placeholder")
+	}
+}
+
+// enumFileFDP returns a file in package test.types declaring a top-level
+// Color enum and a Palette message with a nested Shade enum.
+func enumFileFDP() *descriptorpb.FileDescriptorProto {
+	values := []*descriptorpb.EnumValueDescriptorProto{
+		{Name: strPtr("UNSPECIFIED"), Number: int32Ptr(0)},
+		{Name: strPtr("RED"), Number: int32Ptr(1)},
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:     strPtr("types.proto"),
+		Syntax:   strPtr("proto3"),
+		Package:  strPtr("test.types"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{Name: strPtr("Color"), Value: values}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:     strPtr("Palette"),
+				EnumType: []*descriptorpb.EnumDescriptorProto{{Name: strPtr("Shade"), Value: values}},
+			},
+		},
+	}
+}
+
+func TestBuildFileDescriptors_EnumFromUnimportedFile(t *testing.T) {
+	// File A's only cross-file references are enums in file B, which A does
+	// not import and which comes after A in the set.
+	enumType := descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+
+	itemFDP := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("item.proto"),
+		Syntax:  strPtr("proto3"),
+		Package: strPtr("test"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: strPtr("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: strPtr("color"), Number: int32Ptr(1), Type: &enumType, Label: &label, TypeName: strPtr(".test.types.Color")},
+					// Relative reference to a nested enum, resolved by package scoping
+					{Name: strPtr("shade"), Number: int32Ptr(2), Type: &enumType, Label: &label, TypeName: strPtr("types.Palette.Shade")},
+				},
+			},
+		},
+	}
+
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{itemFDP, enumFileFDP()}, discardTrace())
+	if err != nil {
+		t.Fatalf("buildFileDescriptors failed: %v", err)
+	}
+
+	if deps := itemFDP.GetDependency(); len(deps) != 1 || deps[0] != "types.proto" {
+		t.Errorf("expected types.proto to be imported, got %v", deps)
+	}
+
+	d, err := files.FindDescriptorByName("test.Item")
+	if err != nil {
+		t.Fatalf("expected to find test.Item: %v", err)
+	}
+	fields := d.(protoreflect.MessageDescriptor).Fields()
+	for _, name := range []protoreflect.Name{"color", "shade"} {
+		enum := fields.ByName(name).Enum()
+		if enum.IsPlaceholder() {
+			t.Errorf("%s: expected a resolved enum, got placeholder %s", name, enum.FullName())
+			continue
+		}
+		if enum.Values().Len() != 2 {
+			t.Errorf("%s: expected 2 enum values, got %d", name, enum.Values().Len())
+		}
+	}
+}
+
+func TestCollectTypeRefs_EnumsAtEveryLevel(t *testing.T) {
+	enumType := descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	groupType := descriptorpb.FieldDescriptorProto_TYPE_GROUP
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("nested.proto"),
+		Syntax:  strPtr("proto2"),
+		Package: strPtr("test"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: strPtr("Outer"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: strPtr("top"), Number: int32Ptr(1), Type: &enumType, Label: &label, TypeName: strPtr(".test.types.Color")},
+					{Name: strPtr("group"), Number: int32Ptr(2), Type: &groupType, Label: &label, TypeName: strPtr(".test.Outer.Group")},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: strPtr("Group"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							// Oneof member inside a group
+							{Name: strPtr("choice"), Number: int32Ptr(3), Type: &enumType, Label: &label, TypeName: strPtr(".test.types.Palette.Shade"), OneofIndex: int32Ptr(0)},
+						},
+						OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: strPtr("pick")}},
+						NestedType: []*descriptorpb.DescriptorProto{
+							{
+								Name: strPtr("Deep"),
+								// Extension declared inside a nested message
+								Extension: []*descriptorpb.FieldDescriptorProto{
+									{Name: strPtr("deep_ext"), Number: int32Ptr(100), Type: &enumType, Label: &label, TypeName: strPtr(".test.ext.Level"), Extendee: strPtr(".test.ext.Target")},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			{Name: strPtr("file_ext"), Number: int32Ptr(101), Type: &enumType, Label: &label, TypeName: strPtr(".test.ext.Mode"), Extendee: strPtr(".google.protobuf.MethodOptions")},
+		},
+	}
+
+	refs := make(map[string]bool)
+	for _, r := range collectTypeRefs(fd) {
+		refs[r] = true
+	}
+	for _, want := range []string{
+		".test.types.Color",
+		".test.Outer.Group",
+		".test.types.Palette.Shade",
+		".test.ext.Level",
+		".test.ext.Target",
+		".test.ext.Mode",
+		".google.protobuf.MethodOptions",
+	} {
+		if !refs[want] {
+			t.Errorf("expected ref %s, got %v", want, refs)
+		}
+	}
+}
+
+func TestOrderByDependency(t *testing.T) {
+	a := &descriptorpb.FileDescriptorProto{Name: strPtr("a.proto"), Dependency: []string{"b.proto", "google/protobuf/timestamp.proto"}}
+	b := &descriptorpb.FileDescriptorProto{Name: strPtr("b.proto"), Dependency: []string{"c.proto"}}
+	c := &descriptorpb.FileDescriptorProto{Name: strPtr("c.proto"), Dependency: []string{"a.proto"}} // cycle
+	d := &descriptorpb.FileDescriptorProto{Name: strPtr("d.proto")}
+
+	var got []string
+	for _, fd := range orderByDependency([]*descriptorpb.FileDescriptorProto{d, a, b, c}) {
+		got = append(got, fd.GetName())
+	}
+	want := []string{"d.proto", "c.proto", "b.proto", "a.proto"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("orderByDependency = %v, want %v", got, want)
+	}
+}
+
+// legacyProto2FDPs returns a proto2 service file, with no "syntax" set,
+// whose request has a required field and a message from a second,
+// un-imported file, and whose response has a repeated group named the way
+// some legacy servers emit it (field named after the group message).
+func legacyProto2FDPs() []*descriptorpb.FileDescriptorProto {
+	strType := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	msgType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	groupType := descriptorpb.FieldDescriptorProto_TYPE_GROUP
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	required := descriptorpb.FieldDescriptorProto_LABEL_REQUIRED
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	commonFDP := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("legacy/common.proto"),
+		Package: strPtr("legacy.common"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: strPtr("Owner"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: strPtr("name"), Number: int32Ptr(1), Type: &strType, Label: &required},
+				},
+			},
+		},
+	}
+
+	searchFDP := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("legacy/search.proto"),
+		Package: strPtr("legacy.search"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: strPtr("SearchRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: strPtr("query"), Number: int32Ptr(1), Type: &strType, Label: &required},
+					{Name: strPtr("owner"), Number: int32Ptr(2), Type: &msgType, Label: &optional, TypeName: strPtr(".legacy.common.Owner")},
+				},
+			},
+			{
+				Name: strPtr("SearchResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: strPtr("Result"), JsonName: strPtr("Result"), Number: int32Ptr(1), Type: &groupType, Label: &repeated, TypeName: strPtr("Result")},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: strPtr("Result"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{Name: strPtr("url"), Number: int32Ptr(2), Type: &strType, Label: &required},
+							{Name: strPtr("title"), Number: int32Ptr(3), Type: &strType, Label: &optional},
+						},
+					},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: strPtr("SearchService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       strPtr("Search"),
+						InputType:  strPtr(".legacy.search.SearchRequest"),
+						OutputType: strPtr(".legacy.search.SearchResponse"),
+					},
+				},
+			},
+		},
+	}
+	return []*descriptorpb.FileDescriptorProto{searchFDP, commonFDP}
+}
+
+func TestBuildFileDescriptors_Proto2GroupsAndRequired(t *testing.T) {
+	rc, err := NewReflectionClientFromDescriptors(nil, legacyProto2FDPs(), discardLogger)
+	if err != nil {
+		t.Fatalf("NewReflectionClientFromDescriptors failed: %v", err)
+	}
+	defer rc.Close()
+
+	services, err := rc.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 1 || services[0].Error != "" {
+		t.Fatalf("expected SearchService to build, got %+v", services)
+	}
+
+	md, err := rc.GetMethodDescriptor("legacy.search.SearchService", "Search")
+	if err != nil {
+		t.Fatalf("GetMethodDescriptor failed: %v", err)
+	}
+
+	in := md.Input()
+	if in.Syntax() != protoreflect.Proto2 {
+		t.Errorf("expected proto2 input, got %v", in.Syntax())
+	}
+	if f := in.Fields().ByName("query"); f == nil || f.Cardinality() != protoreflect.Required {
+		t.Errorf("expected required query field, got %v", f)
+	}
+	if owner := in.Fields().ByName("owner").Message(); owner.IsPlaceholder() || owner.Fields().ByName("name") == nil {
+		t.Errorf("expected Owner to resolve from legacy/common.proto, got %v", owner.FullName())
+	}
+
+	result := md.Output().Fields().ByName("result")
+	if result == nil {
+		t.Fatal("expected group field to be renamed to result")
+	}
+	if result.Kind() != protoreflect.GroupKind || !result.IsList() {
+		t.Errorf("expected repeated group, got %v list=%v", result.Kind(), result.IsList())
+	}
+	if result.Message().Fields().ByName("url").Cardinality() != protoreflect.Required {
+		t.Error("expected required url inside the group")
+	}
+
+	// The descriptors encode and decode messages
+	frame, err := encodeJSON(in, `{"query":"grotto","owner":{"name":"ann"}}`, nil)
+	if err != nil {
+		t.Fatalf("encodeJSON failed: %v", err)
+	}
+	if _, err := decodeJSON(in, frame, nil); err != nil {
+		t.Fatalf("decodeJSON failed: %v", err)
+	}
+	frame, err = encodeJSON(md.Output(), `{"result":[{"url":"https://a","title":"A"}]}`, nil)
+	if err != nil {
+		t.Fatalf("encodeJSON of group failed: %v", err)
+	}
+	got, err := decodeJSON(md.Output(), frame, nil)
+	if err != nil {
+		t.Fatalf("decodeJSON of group failed: %v", err)
+	}
+	if !strings.Contains(got, "https://a") {
+		t.Errorf("group did not round-trip: %s", got)
+	}
+}
+
+func TestFixGroupFields(t *testing.T) {
+	fdps := legacyProto2FDPs()
+	search := fdps[0]
+	if !fixGroupFields(search) {
+		t.Fatal("expected the group field to be fixed")
+	}
+	field := search.GetMessageType()[1].GetField()[0]
+	if field.GetName() != "result" {
+		t.Errorf("field name = %q, want result", field.GetName())
+	}
+	if field.JsonName != nil {
+		t.Errorf("expected derived JSON name to be cleared, got %q", field.GetJsonName())
+	}
+	if field.GetTypeName() != ".legacy.search.SearchResponse.Result" {
+		t.Errorf("type name = %q, want absolute", field.GetTypeName())
+	}
+	if fixGroupFields(search) {
+		t.Error("expected a second pass to change nothing")
+	}
+
+	// Map entry fixing leaves the group's nested type alone
+	if fixMapEntryNames(search, discardTrace()) {
+		t.Error("fixMapEntryNames changed a file without maps")
+	}
+	if got := search.GetMessageType()[1].GetNestedType()[0].GetName(); got != "Result" {
+		t.Errorf("group message renamed to %q", got)
+	}
+}
+
+func TestJSONCamelCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"result":        "result",
+		"Result":        "Result",
+		"created_at":    "createdAt",
+		"a__b":          "aB",
+		"trailing_":     "trailing",
+		"x_1_y":         "x1Y",
+		"already_Camel": "alreadyCamel",
+	} {
+		if got := jsonCamelCase(in); got != want {
+			t.Errorf("jsonCamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
 }
 
 // --- fixMapEntryNames unit tests ---
@@ -649,7 +976,7 @@ func TestFixMapEntryNames_FixesIncorrectName(t *testing.T) {
 		},
 	}
 
-	fixed := fixMapEntryNames(fd)
+	fixed := fixMapEntryNames(fd, discardTrace())
 	if !fixed {
 		t.Fatal("expected fixMapEntryNames to return true")
 	}
@@ -700,7 +1027,7 @@ func TestFixMapEntryNames_LeavesCorrectNameAlone(t *testing.T) {
 		},
 	}
 
-	fixed := fixMapEntryNames(fd)
+	fixed := fixMapEntryNames(fd, discardTrace())
 	if fixed {
 		t.Error("expected fixMapEntryNames to return false for correct map entry")
 	}
@@ -741,7 +1068,7 @@ func TestFixMapEntryNames_SnakeCaseField(t *testing.T) {
 		},
 	}
 
-	fixed := fixMapEntryNames(fd)
+	fixed := fixMapEntryNames(fd, discardTrace())
 	if !fixed {
 		t.Fatal("expected fixMapEntryNames to return true")
 	}
@@ -811,7 +1138,7 @@ func TestFixMapEntryNames_RelativeTypeNames(t *testing.T) {
 			},
 		}
 
-		fixed := fixMapEntryNames(fd)
+		fixed := fixMapEntryNames(fd, discardTrace())
 		if !fixed {
 			t.Fatal("expected fixMapEntryNames to return true")
 		}
@@ -858,7 +1185,7 @@ func TestFixMapEntryNames_RelativeTypeNames(t *testing.T) {
 			},
 		}
 
-		fixed := fixMapEntryNames(fd)
+		fixed := fixMapEntryNames(fd, discardTrace())
 		if !fixed {
 			t.Fatal("expected fixMapEntryNames to return true")
 		}
@@ -942,7 +1269,7 @@ func TestFixMapEntryNames_MixedTypeNames(t *testing.T) {
 		},
 	}
 
-	fixed := fixMapEntryNames(fd)
+	fixed := fixMapEntryNames(fd, discardTrace())
 	if !fixed {
 		t.Fatal("expected fixMapEntryNames to return true")
 	}
@@ -974,6 +1301,126 @@ func TestFixMapEntryNames_MixedTypeNames(t *testing.T) {
 	}
 }
 
+// mapEntryFDP returns a file with one message, Msg, holding fields that
+// reference the given nested map entries (all string to string).
+func mapEntryFDP(fields map[string]string, entries ...string) *descriptorpb.FileDescriptorProto {
+	strType := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	msgType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	labelOpt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	labelRep := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	msg := &descriptorpb.DescriptorProto{Name: strPtr("Msg")}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+			Name: strPtr(name), Number: int32Ptr(int32(i + 1)), Type: &msgType, Label: &labelRep,
+			TypeName: strPtr(fields[name]),
+		})
+	}
+	for _, entry := range entries {
+		msg.NestedType = append(msg.NestedType, &descriptorpb.DescriptorProto{
+			Name:    strPtr(entry),
+			Options: &descriptorpb.MessageOptions{MapEntry: boolPtr(true)},
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: strPtr("key"), Number: int32Ptr(1), Type: &strType, Label: &labelOpt},
+				{Name: strPtr("value"), Number: int32Ptr(2), Type: &strType, Label: &labelOpt},
+			},
+		})
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:        strPtr("maps.proto"),
+		Syntax:      strPtr("proto3"),
+		Package:     strPtr("test.pkg"),
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+	}
+}
+
+// fieldTypes maps each of Msg's fields to its TypeName.
+func fieldTypes(fd *descriptorpb.FileDescriptorProto) map[string]string {
+	types := map[string]string{}
+	for _, f := range fd.GetMessageType()[0].GetField() {
+		types[f.GetName()] = f.GetTypeName()
+	}
+	return types
+}
+
+func TestFixMapEntryNames_CollidingNames(t *testing.T) {
+	// Each field references the entry its neighbour should be named after:
+	// renaming one at a time would give two entries the same name.
+	fd := mapEntryFDP(map[string]string{
+		"item":  ".test.pkg.Msg.ItemsEntry",
+		"items": "ItemEntry",
+	}, "ItemsEntry", "ItemEntry")
+
+	if !fixMapEntryNames(fd, discardTrace()) {
+		t.Fatal("expected fixMapEntryNames to return true")
+	}
+	nested := fd.GetMessageType()[0].GetNestedType()
+	if nested[0].GetName() != "ItemEntry" || nested[1].GetName() != "ItemsEntry" {
+		t.Errorf("entries = %s, %s; want them exchanged", nested[0].GetName(), nested[1].GetName())
+	}
+	want := map[string]string{"item": ".test.pkg.Msg.ItemEntry", "items": "ItemsEntry"}
+	if got := fieldTypes(fd); !reflect.DeepEqual(got, want) {
+		t.Errorf("field types = %v, want %v", got, want)
+	}
+
+	files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{fd}, discardTrace())
+	if err != nil {
+		t.Fatalf("fixed file did not build: %v", err)
+	}
+	d, err := files.FindDescriptorByName("test.pkg.Msg")
+	if err != nil {
+		t.Fatalf("Msg not found: %v", err)
+	}
+	if !d.(protoreflect.MessageDescriptor).Fields().ByName("items").IsMap() {
+		t.Error("items is not a map after the fix")
+	}
+}
+
+func TestFixMapEntryNames_NameTakenByMessage(t *testing.T) {
+	fd := mapEntryFDP(map[string]string{"labels": "Label"}, "Label")
+	// A plain nested message already has the name the entry should get
+	msg := fd.GetMessageType()[0]
+	msg.NestedType = append(msg.NestedType, &descriptorpb.DescriptorProto{Name: strPtr("LabelsEntry")})
+
+	if !fixMapEntryNames(fd, discardTrace()) {
+		t.Fatal("expected fixMapEntryNames to return true")
+	}
+	if got := msg.GetNestedType()[0].GetName(); got != "LabelsEntry2" {
+		t.Errorf("entry name = %q, want the disambiguated LabelsEntry2", got)
+	}
+	if got := msg.GetField()[0].GetTypeName(); got != "LabelsEntry2" {
+		t.Errorf("field type = %q, want LabelsEntry2", got)
+	}
+}
+
+func TestFixMapEntryNames_EntrySharedByTwoFields(t *testing.T) {
+	fd := mapEntryFDP(map[string]string{
+		"tags":   ".test.pkg.Msg.Tag",
+		"labels": "Tag",
+		"scores": "Msg.Score",
+	}, "Tag", "Score")
+
+	if !fixMapEntryNames(fd, discardTrace()) {
+		t.Fatal("expected the unshared entry to be fixed")
+	}
+	nested := fd.GetMessageType()[0].GetNestedType()
+	if got := nested[0].GetName(); got != "Tag" {
+		t.Errorf("shared entry renamed to %q; it can't match both fields", got)
+	}
+	if got := nested[1].GetName(); got != "ScoresEntry" {
+		t.Errorf("unshared entry = %q, want ScoresEntry", got)
+	}
+	want := map[string]string{"tags": ".test.pkg.Msg.Tag", "labels": "Tag", "scores": "Msg.ScoresEntry"}
+	if got := fieldTypes(fd); !reflect.DeepEqual(got, want) {
+		t.Errorf("field types = %v, want %v", got, want)
+	}
+}
+
 // --- fixReservedRanges unit tests ---
 
 func TestFixReservedRanges_FixesInvalidRange(t *testing.T) {
@@ -1102,9 +1549,10 @@ func TestResolveTypeRef(t *testing.T) {
 
 // --- Full scenario test: map entries + missing imports + non-canonical WKT ---
 
-func TestBuildFileDescriptors_FullNonCanonicalScenario(t *testing.T) {
-	// Tests all three fix-ups together: non-canonical WKT barrel file,
-	// malformed map entries, empty dependencies, cross-file type refs.
+// fullNonCanonicalFDPs returns the files of the full scenario: a service
+// file with no declared imports and a malformed map entry, using types from
+// a non-canonical WKT barrel file and a custom types file.
+func fullNonCanonicalFDPs() (svcFDP, wktFDP, typesFDP *descriptorpb.FileDescriptorProto) {
 	msgType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
 	strType := descriptorpb.FieldDescriptorProto_TYPE_STRING
 	int32Type := descriptorpb.FieldDescriptorProto_TYPE_INT32
@@ -1113,7 +1561,7 @@ func TestBuildFileDescriptors_FullNonCanonicalScenario(t *testing.T) {
 	labelRep := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
 
 	// Non-canonical barrel file
-	wktFDP := &descriptorpb.FileDescriptorProto{
+	wktFDP = &descriptorpb.FileDescriptorProto{
 		Name:    strPtr("google_protobuf.proto"),
 		Syntax:  strPtr("proto3"),
 		Package: strPtr("google.protobuf"),
@@ -1129,7 +1577,7 @@ func TestBuildFileDescriptors_FullNonCanonicalScenario(t *testing.T) {
 	}
 
 	// Custom types file
-	typesFDP := &descriptorpb.FileDescriptorProto{
+	typesFDP = &descriptorpb.FileDescriptorProto{
 		Name:    strPtr("custom_types.proto"),
 		Syntax:  strPtr("proto3"),
 		Package: strPtr("custom.types"),
@@ -1149,7 +1597,7 @@ func TestBuildFileDescriptors_FullNonCanonicalScenario(t *testing.T) {
 	// 2. Uses Timestamp from non-canonical barrel
 	// 3. Uses Money from custom_types.proto
 	// 4. Has a MALFORMED map entry (ItemEntry instead of ItemsEntry)
-	svcFDP := &descriptorpb.FileDescriptorProto{
+	svcFDP = &descriptorpb.FileDescriptorProto{
 		Name:       strPtr("noncanonical_svc.proto"),
 		Syntax:     strPtr("proto3"),
 		Package:    strPtr("test.noncanonical.v1"),
@@ -1206,9 +1654,16 @@ func TestBuildFileDescriptors_FullNonCanonicalScenario(t *testing.T) {
 		},
 	}
 
+	return svcFDP, wktFDP, typesFDP
+}
+
+func TestBuildFileDescriptors_FullNonCanonicalScenario(t *testing.T) {
+	// Tests all three fix-ups together: non-canonical WKT barrel file,
+	// malformed map entries, empty dependencies, cross-file type refs.
+	svcFDP, wktFDP, typesFDP := fullNonCanonicalFDPs()
 	files, err := buildFileDescriptors(
 		[]*descriptorpb.FileDescriptorProto{svcFDP, wktFDP, typesFDP},
-		discardLogger,
+		discardTrace(),
 	)
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
@@ -1322,7 +1777,7 @@ func TestBuildFileDescriptors_RelativeTypeNames(t *testing.T) {
 
 	files, err := buildFileDescriptors(
 		[]*descriptorpb.FileDescriptorProto{svcFDP, typesFDP},
-		discardLogger,
+		discardTrace(),
 	)
 	if err != nil {
 		t.Fatalf("buildFileDescriptors failed: %v", err)
@@ -1337,112 +1792,176 @@ func TestBuildFileDescriptors_RelativeTypeNames(t *testing.T) {
 	}
 }
 
-// --- Integration tests against testdata/noncanonical server ---
+// --- Integration tests against an in-process non-canonical server ---
 
 func TestIntegration_NonCanonicalServer(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in short mode")
-	}
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...),
+		grpctest.WithHealth(),
+	)
 
-	// Resolve paths relative to the test file's package directory
-	serverDir, err := filepath.Abs("../../testdata/noncanonical")
+	reflClient := NewReflectionClient(srv.Conn, testLogger)
+	defer reflClient.Close()
+
+	// ListServices should discover services and resolve them via lenientResolve
+	services, err := reflClient.ListServices(context.Background())
 	if err != nil {
-		t.Fatalf("failed to resolve server dir: %v", err)
+		t.Fatalf("ListServices failed: %v", err)
 	}
-	serverBin := filepath.Join(serverDir, "noncanonical")
 
-	buildCmd := exec.Command("go", "build", "-o", serverBin, ".")
-	buildCmd.Dir = serverDir
-	if out, err := buildCmd.CombinedOutput(); err != nil {
-		t.Fatalf("failed to build noncanonical server: %v\n%s", err, out)
+	// Find our target service
+	var eventSvc *domain.Service
+	for i := range services {
+		if services[i].FullName == "custom.event.v1.EventService" {
+			eventSvc = &services[i]
+			break
+		}
 	}
-	defer os.Remove(serverBin)
 
-	// Find a free port
-	lis, err := net.Listen("tcp", "localhost:0")
-	if err != nil {
-		t.Fatalf("failed to find free port: %v", err)
+	if eventSvc == nil {
+		t.Fatal("expected to find custom.event.v1.EventService in services")
 	}
-	port := lis.Addr().(*net.TCPAddr).Port
-	lis.Close()
 
-	addr := fmt.Sprintf("localhost:%d", port)
-
-	// Start the server
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, serverBin, "-addr", addr)
-	cmd.Dir = serverDir
-	if err := cmd.Start(); err != nil {
-		t.Fatalf("failed to start noncanonical server: %v", err)
+	if eventSvc.Error != "" {
+		t.Errorf("expected no error for EventService, got:\n%s", eventSvc.Error)
 	}
-	defer func() {
-		cancel()
-		cmd.Wait()
-	}()
 
-	// Wait for the server to accept connections
-	var conn *googlegrpc.ClientConn
-	deadline := time.Now().Add(5 * time.Second)
-	for time.Now().Before(deadline) {
-		time.Sleep(50 * time.Millisecond)
-		conn, err = googlegrpc.NewClient(addr, googlegrpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			continue
-		}
-		// Verify the connection is actually usable
-		_, connCancel := context.WithTimeout(ctx, 200*time.Millisecond)
-		conn.Connect()
-		state := conn.GetState()
-		connCancel()
-		if state == 2 { // connectivity.Ready
-			break
-		}
-		conn.Close()
-		conn = nil
-	}
-	if conn == nil {
-		// Just create the connection; ListServices will surface the actual error
-		conn, _ = googlegrpc.NewClient(addr, googlegrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if len(eventSvc.Methods) != 2 {
+		t.Errorf("expected 2 methods (GetEvent, GetEvents), got %d", len(eventSvc.Methods))
 	}
-	defer conn.Close()
+}
 
-	// Give server a moment to be fully ready
-	time.Sleep(500 * time.Millisecond)
+func TestIntegration_NonCanonicalServer_V1Alpha(t *testing.T) {
+	// Older servers only register reflection v1alpha
+	srv := grpctest.StartServer(t,
+		grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...),
+		grpctest.WithReflectionV1Alpha(),
+	)
 
-	// Create a reflection client with a verbose logger for debugging
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
-	reflClient := NewReflectionClient(conn, logger)
+	reflClient := NewReflectionClient(srv.Conn, testLogger)
+	defer reflClient.Close()
 
-	// ListServices should discover services and resolve them via lenientResolve
-	services, err := reflClient.ListServices(ctx)
+	services, err := reflClient.ListServices(context.Background())
 	if err != nil {
 		t.Fatalf("ListServices failed: %v", err)
 	}
 
-	// Find our target service
 	var eventSvc *domain.Service
 	for i := range services {
 		if services[i].FullName == "custom.event.v1.EventService" {
 			eventSvc = &services[i]
-			break
 		}
 	}
-
 	if eventSvc == nil {
 		t.Fatal("expected to find custom.event.v1.EventService in services")
 	}
-
 	if eventSvc.Error != "" {
-		t.Errorf("expected no error for EventService, got:\n%s", eventSvc.Error)
+		t.Errorf("expected lenient resolution over v1alpha, got:\n%s", eventSvc.Error)
 	}
-
 	if len(eventSvc.Methods) != 2 {
 		t.Errorf("expected 2 methods (GetEvent, GetEvents), got %d", len(eventSvc.Methods))
 	}
+	if reflClient.reflectionMethod != reflectionV1AlphaMethod {
+		t.Errorf("reflectionMethod = %q, want %q", reflClient.reflectionMethod, reflectionV1AlphaMethod)
+	}
+}
+
+func TestConvertService_Deprecated(t *testing.T) {
+	fdp := makeServiceFDP([]string{"google/protobuf/timestamp.proto"})
+	svc := fdp.Service[0]
+	svc.Method = append(svc.Method, &descriptorpb.MethodDescriptorProto{
+		Name:       strPtr("GetItemLegacy"),
+		InputType:  strPtr(".test.noncanonical.v1.GetItemRequest"),
+		OutputType: strPtr(".test.noncanonical.v1.Item"),
+		Options:    &descriptorpb.MethodOptions{Deprecated: boolPtr(true)},
+	})
+
+	convert := func() domain.Service {
+		t.Helper()
+		files, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{fdp}, discardTrace())
+		if err != nil {
+			t.Fatalf("buildFileDescriptors failed: %v", err)
+		}
+		sd := findService(files, "test.noncanonical.v1.NonCanonicalService")
+		if sd == nil {
+			t.Fatal("expected to find NonCanonicalService")
+		}
+		return (&ReflectionClient{}).convertService(sd)
+	}
+
+	got := convert()
+	if got.File != "noncanonical_service.proto" {
+		t.Errorf("File = %q, want the defining file's path", got.File)
+	}
+	if got.Deprecated {
+		t.Error("service should not be deprecated")
+	}
+	if got.Methods[0].Deprecated {
+		t.Error("GetItem should not be deprecated")
+	}
+	if !got.Methods[1].Deprecated {
+		t.Error("GetItemLegacy should be deprecated")
+	}
+
+	// A deprecated service deprecates all of its methods
+	svc.Options = &descriptorpb.ServiceOptions{Deprecated: boolPtr(true)}
+	got = convert()
+	if !got.Deprecated {
+		t.Error("service should be deprecated")
+	}
+	for _, m := range got.Methods {
+		if !m.Deprecated {
+			t.Errorf("%s should be deprecated with its service", m.Name)
+		}
+	}
 }
 
 func boolPtr(b bool) *bool    { return &b }
 func strPtr(s string) *string { return &s }
 func int32Ptr(i int32) *int32 { return &i }
+
+func TestIntegration_PartiallyResolvedService(t *testing.T) {
+	// The service file is stuck on one of its messages; the methods using
+	// other files' types must still be listed and callable.
+	srv := grpctest.StartServer(t, grpctest.WithReflectionFiles(makePartialFDPs(true)...))
+
+	reflClient := NewReflectionClient(srv.Conn, testLogger)
+	defer reflClient.Close()
+
+	services, err := reflClient.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	var svc *domain.Service
+	for i := range services {
+		if services[i].FullName == "test.partial.v1.PartialService" {
+			svc = &services[i]
+		}
+	}
+	if svc == nil {
+		t.Fatal("expected to find test.partial.v1.PartialService in services")
+	}
+	if svc.Error != "" {
+		t.Fatalf("expected a partially resolved service, got error:\n%s", svc.Error)
+	}
+	broken := map[string]bool{}
+	for _, m := range svc.Methods {
+		broken[m.Name] = m.Error != ""
+	}
+	want := map[string]bool{"Good": false, "Missing": true, "Stuck": true}
+	for name, wantBroken := range want {
+		if broken[name] != wantBroken {
+			t.Errorf("method %s broken = %v, want %v", name, broken[name], wantBroken)
+		}
+	}
+
+	// A fresh client has nothing cached and resolves the service itself
+	fresh := NewReflectionClient(srv.Conn, testLogger)
+	defer fresh.Close()
+	if _, err := fresh.GetMethodDescriptor(svc.FullName, "Good"); err != nil {
+		t.Errorf("GetMethodDescriptor(Good) failed: %v", err)
+	}
+	if _, err := fresh.GetMethodDescriptor(svc.FullName, "Stuck"); err == nil {
+		t.Error("GetMethodDescriptor(Stuck) succeeded, want an error")
+	}
+}
@@ -0,0 +1,173 @@
+package grpc
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// TraceCollector records what lenient resolution did with each file: where
+// it came from, the fix-ups applied to it, and why it could not be built.
+// Everything recorded is also logged at debug level. It is safe for
+// concurrent use.
+type TraceCollector struct {
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	files map[string]*FileTrace
+	order []string // File names, in the order first seen
+}
+
+// newTraceCollector creates a collector that logs to logger.
+func newTraceCollector(logger *slog.Logger) *TraceCollector {
+	return &TraceCollector{logger: logger, files: make(map[string]*FileTrace)}
+}
+
+// ResolutionReport is the trace of resolving one service over lenient
+// reflection, for telling why a server's descriptors do not build.
+type ResolutionReport struct {
+	Service string
+	Files   []FileTrace // In the order they were received or built
+	Error   string      // Why the service could not be resolved, "" if it was
+}
+
+// FileTrace is what lenient resolution did with one file.
+type FileTrace struct {
+	Name         string
+	Package      string
+	Source       string   // The reflection request that returned it, if any
+	Dependencies []string // Imports as the server declared them
+	Fixes        []string // Fix-ups applied, in order
+	Built        bool
+	Error        string // Last build error, when it could not be built
+}
+
+// file returns the trace of the file name, starting one if needed. The
+// caller holds t.mu.
+func (t *TraceCollector) file(name string) *FileTrace {
+	ft, ok := t.files[name]
+	if !ok {
+		ft = &FileTrace{Name: name}
+		t.files[name] = ft
+		t.order = append(t.order, name)
+	}
+	return ft
+}
+
+// received records fd as returned by the reflection request source, before
+// any fix-up.
+func (t *TraceCollector) received(fd *descriptorpb.FileDescriptorProto, source string) {
+	t.logger.Debug("lenient resolve: received file descriptor",
+		slog.String("file", fd.GetName()),
+		slog.String("package", fd.GetPackage()),
+		slog.String("source", source),
+		slog.Any("deps", fd.GetDependency()),
+	)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ft := t.file(fd.GetName())
+	ft.Package = fd.GetPackage()
+	ft.Source = source
+	ft.Dependencies = slices.Clone(fd.GetDependency())
+}
+
+// declared records fd's package and imports before it is first built,
+// unless fd was already recorded as received or in an earlier build.
+func (t *TraceCollector) declared(fd *descriptorpb.FileDescriptorProto) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.files[fd.GetName()]; ok {
+		return
+	}
+	ft := t.file(fd.GetName())
+	ft.Package = fd.GetPackage()
+	ft.Dependencies = slices.Clone(fd.GetDependency())
+}
+
+// fixed records a fix-up applied to file.
+func (t *TraceCollector) fixed(file, fix string) {
+	t.logger.Debug("applied descriptor fix-up",
+		slog.String("file", file),
+		slog.String("fix", fix),
+	)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ft := t.file(file)
+	ft.Fixes = append(ft.Fixes, fix)
+}
+
+// built records that file was built.
+func (t *TraceCollector) built(file string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ft := t.file(file)
+	ft.Built = true
+	ft.Error = ""
+}
+
+// failed records the latest error building file.
+func (t *TraceCollector) failed(file string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file(file).Error = err.Error()
+}
+
+// Report returns what was recorded as the report of resolving service,
+// which failed with err when it is not nil.
+func (t *TraceCollector) Report(service string, err error) ResolutionReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := ResolutionReport{Service: service}
+	for _, name := range t.order {
+		ft := *t.files[name]
+		ft.Dependencies = slices.Clone(ft.Dependencies)
+		ft.Fixes = slices.Clone(ft.Fixes)
+		report.Files = append(report.Files, ft)
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
+
+// String formats the report as plain text for bug reports.
+func (r ResolutionReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Service: %s\n", r.Service)
+	if r.Error != "" {
+		fmt.Fprintf(&b, "Result: failed: %s\n", r.Error)
+	} else {
+		b.WriteString("Result: resolved\n")
+	}
+	fmt.Fprintf(&b, "Files: %d\n", len(r.Files))
+	for _, ft := range r.Files {
+		fmt.Fprintf(&b, "\n%s\n", ft.Name)
+		if ft.Package != "" {
+			fmt.Fprintf(&b, "  Package: %s\n", ft.Package)
+		}
+		if ft.Source != "" {
+			fmt.Fprintf(&b, "  Returned for: %s\n", ft.Source)
+		}
+		if len(ft.Dependencies) > 0 {
+			fmt.Fprintf(&b, "  Declared imports: %s\n", strings.Join(ft.Dependencies, ", "))
+		} else {
+			b.WriteString("  Declared imports: none\n")
+		}
+		for _, fix := range ft.Fixes {
+			fmt.Fprintf(&b, "  Fix-up: %s\n", fix)
+		}
+		switch {
+		case ft.Built:
+			b.WriteString("  Built: yes\n")
+		case ft.Error != "":
+			fmt.Fprintf(&b, "  Built: no: %s\n", ft.Error)
+		default:
+			b.WriteString("  Built: no\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestBuildFileDescriptors_FullNonCanonicalTrace(t *testing.T) {
+	svcFDP, wktFDP, typesFDP := fullNonCanonicalFDPs()
+	// A file no fix-up can save: it declares the same message twice
+	brokenFDP := &descriptorpb.FileDescriptorProto{
+		Name:        strPtr("broken.proto"),
+		Syntax:      strPtr("proto3"),
+		Package:     strPtr("test.broken"),
+		Dependency:  []string{"noncanonical_svc.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{Name: strPtr("Dup")}, {Name: strPtr("Dup")}},
+	}
+	trace := discardTrace()
+	_, err := buildFileDescriptors([]*descriptorpb.FileDescriptorProto{svcFDP, wktFDP, typesFDP, brokenFDP}, trace)
+	require.NoError(t, err)
+
+	report := trace.Report("test.noncanonical.v1.NonCanonicalService", nil)
+	byName := make(map[string]FileTrace)
+	for _, ft := range report.Files {
+		byName[ft.Name] = ft
+	}
+	require.Len(t, byName, 4)
+
+	svc := byName["noncanonical_svc.proto"]
+	assert.Equal(t, "test.noncanonical.v1", svc.Package)
+	assert.Empty(t, svc.Dependencies, "imports as declared, before fix-ups")
+	assert.Equal(t, []string{
+		"renamed map entry test.noncanonical.v1.ListItemsResponse.ItemEntry to ItemsEntry for field items",
+		"added missing import custom_types.proto, which declares types it uses",
+		"added missing import google/protobuf/timestamp.proto for google.protobuf.Timestamp",
+	}, svc.Fixes)
+	assert.True(t, svc.Built)
+	assert.Empty(t, svc.Error)
+
+	for _, name := range []string{"google_protobuf.proto", "custom_types.proto"} {
+		assert.True(t, byName[name].Built, name)
+		assert.Empty(t, byName[name].Fixes, name)
+	}
+
+	broken := byName["broken.proto"]
+	assert.False(t, broken.Built)
+	assert.Equal(t, []string{"noncanonical_svc.proto"}, broken.Dependencies)
+	assert.Contains(t, broken.Error, "test.broken.Dup")
+
+	text := report.String()
+	assert.Contains(t, text, "Service: test.noncanonical.v1.NonCanonicalService\nResult: resolved\n")
+	assert.Contains(t, text, "  Fix-up: renamed map entry")
+	assert.Contains(t, text, "broken.proto\n  Package: test.broken\n  Declared imports: noncanonical_svc.proto\n  Built: no: ")
+}
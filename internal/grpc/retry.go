@@ -0,0 +1,194 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultRetryBackoff is the wait before the first retry when a retry
+// policy sets none.
+const DefaultRetryBackoff = 200 * time.Millisecond
+
+// retryPolicy is a domain.RetryPolicy ready for use.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	codes          map[codes.Code]bool
+}
+
+// SetRetryPolicy sets how InvokeUnary retries failed calls (the zero value
+// makes a single attempt). Unknown code names are logged and ignored. Set
+// it before invoking.
+func (i *Invoker) SetRetryPolicy(p domain.RetryPolicy) {
+	policy := retryPolicy{
+		maxAttempts:    max(p.MaxAttempts, 1),
+		initialBackoff: p.InitialBackoff,
+		maxBackoff:     p.MaxBackoff,
+		codes:          make(map[codes.Code]bool),
+	}
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = DefaultRetryBackoff
+	}
+	for _, name := range p.Codes() {
+		code, err := ParseCode(name)
+		if err != nil {
+			i.logger.Warn("ignoring retryable code", slog.String("code", name), slog.Any("error", err))
+			continue
+		}
+		policy.codes[code] = true
+	}
+	i.retry = policy
+}
+
+// ParseCode parses a status code by name or number. Names are matched
+// case-insensitively with underscores optional, so "UNAVAILABLE" as gRPC
+// service configs write it, "Unavailable" as grpc-go prints it, and "14"
+// are the same code. Service configs spell code 1 "CANCELLED"; grpc-go
+// prints "Canceled", and both are accepted.
+func ParseCode(name string) (codes.Code, error) {
+	trimmed := strings.TrimSpace(name)
+	if n, err := strconv.Atoi(trimmed); err == nil {
+		if n < int(codes.OK) || n > int(codes.Unauthenticated) {
+			return codes.Unknown, fmt.Errorf("unknown status code %q", name)
+		}
+		return codes.Code(n), nil
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(trimmed)))); err == nil {
+		return code, nil
+	}
+	norm := strings.ToLower(strings.ReplaceAll(trimmed, "_", ""))
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if norm != "" && strings.ToLower(c.String()) == norm {
+			return c, nil
+		}
+	}
+	return codes.Unknown, fmt.Errorf("unknown status code %q", name)
+}
+
+// retryable reports whether a call that failed with err may be tried
+// again. Requests that could not be encoded and responses that could not
+// be decoded fail the same way every time.
+func (p retryPolicy) retryable(err error) bool {
+	var invErr *InvocationError
+	if !errors.As(err, &invErr) || invErr.Phase == PhaseMarshal {
+		return false
+	}
+	return p.codes[invErr.Code]
+}
+
+// backoff returns the wait before the given retry, counting from 1:
+// the initial backoff doubled for each retry before it, up to the maximum.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	d := p.initialBackoff
+	for range retry - 1 {
+		d *= 2
+		if p.maxBackoff > 0 && d >= p.maxBackoff {
+			break
+		}
+	}
+	if p.maxBackoff > 0 {
+		d = min(d, p.maxBackoff)
+	}
+	return d
+}
+
+// CallAttempts is how many attempts a unary call took.
+type CallAttempts struct {
+	Made int // Attempts made, the first included
+	Max  int // Attempts the retry policy allowed
+}
+
+// Retried reports whether the call was attempted more than once.
+func (a CallAttempts) Retried() bool {
+	return a.Made > 1
+}
+
+// AttemptRecorder collects the CallAttempts of the unary call made with
+// its context.
+type AttemptRecorder struct {
+	mu       sync.Mutex
+	attempts CallAttempts
+}
+
+type attemptRecorderKey struct{}
+
+// WithCallAttempts returns a context whose unary call reports its attempts
+// to the returned recorder.
+func WithCallAttempts(ctx context.Context) (context.Context, *AttemptRecorder) {
+	rec := &AttemptRecorder{}
+	return context.WithValue(ctx, attemptRecorderKey{}, rec), rec
+}
+
+// Attempts returns the attempts made so far.
+func (r *AttemptRecorder) Attempts() CallAttempts {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts
+}
+
+// recordAttempt notes the start of an attempt, if ctx has a recorder.
+func recordAttempt(ctx context.Context, made, maxAttempts int) {
+	if rec, ok := ctx.Value(attemptRecorderKey{}).(*AttemptRecorder); ok {
+		rec.mu.Lock()
+		rec.attempts = CallAttempts{Made: made, Max: maxAttempts}
+		rec.mu.Unlock()
+	}
+}
+
+// invokeUnaryWithRetry makes the attempts of a unary call the retry
+// policy allows, returning the last attempt's result. Retries stop early
+// when ctx is done, or when its deadline would pass before the next
+// attempt starts.
+func (i *Invoker) invokeUnaryWithRetry(
+	ctx context.Context,
+	methodDesc protoreflect.MethodDescriptor,
+	jsonRequest string,
+	md metadata.MD,
+) (string, metadata.MD, metadata.MD, error) {
+	policy := i.retry
+	maxAttempts := max(policy.maxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		recordAttempt(ctx, attempt, maxAttempts)
+		resp, headers, trailers, err := i.invokeUnary(ctx, methodDesc, jsonRequest, md)
+		if err == nil || attempt == maxAttempts || !policy.retryable(err) {
+			return resp, headers, trailers, err
+		}
+
+		wait := policy.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			i.logger.Warn("not retrying: deadline before next attempt",
+				slog.String("method", string(methodDesc.FullName())),
+				slog.Int("attempt", attempt),
+				slog.Int("max_attempts", maxAttempts),
+			)
+			return resp, headers, trailers, err
+		}
+		i.logger.Warn("unary RPC attempt failed, retrying",
+			slog.String("method", string(methodDesc.FullName())),
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", maxAttempts),
+			slog.Duration("backoff", wait),
+			slog.Any("error", err),
+		)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, headers, trailers, err
+		case <-timer.C:
+		}
+	}
+}
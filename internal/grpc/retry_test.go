@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newFlakyInvoker returns an invoker whose UnaryEcho and StreamItems fail
+// with code the first failures times they are called.
+func newFlakyInvoker(t *testing.T, code codes.Code, failures int, policy domain.RetryPolicy) *Invoker {
+	t.Helper()
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithFlakyStatus("grpctest.TestService/UnaryEcho", code, failures),
+	)
+	inv := NewInvoker(srv.Conn, testLogger)
+	inv.SetRetryPolicy(policy)
+	return inv
+}
+
+func TestInvokeUnary_Retry(t *testing.T) {
+	req := `{"item":{"id":"r"}}`
+	fast := domain.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		inv := newFlakyInvoker(t, codes.Unavailable, 2, fast)
+		ctx, attempts := WithCallAttempts(context.Background())
+		resp, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		require.NoError(t, err)
+		assert.Contains(t, resp, `"r"`)
+		assert.Equal(t, CallAttempts{Made: 3, Max: 3}, attempts.Attempts())
+		assert.True(t, attempts.Attempts().Retried())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		inv := newFlakyInvoker(t, codes.Unavailable, 5, fast)
+		ctx, attempts := WithCallAttempts(context.Background())
+		_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, CallAttempts{Made: 3, Max: 3}, attempts.Attempts())
+	})
+
+	t.Run("other codes are not retried", func(t *testing.T) {
+		inv := newFlakyInvoker(t, codes.PermissionDenied, 1, fast)
+		ctx, attempts := WithCallAttempts(context.Background())
+		_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, 1, attempts.Attempts().Made)
+	})
+
+	t.Run("configured codes", func(t *testing.T) {
+		policy := fast
+		policy.RetryableCodes = []string{"RESOURCE_EXHAUSTED", "not-a-code"}
+		inv := newFlakyInvoker(t, codes.ResourceExhausted, 1, policy)
+		ctx, attempts := WithCallAttempts(context.Background())
+		_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts.Attempts().Made)
+	})
+
+	t.Run("no policy makes one attempt", func(t *testing.T) {
+		inv := newFlakyInvoker(t, codes.Unavailable, 1, domain.RetryPolicy{})
+		ctx, attempts := WithCallAttempts(context.Background())
+		_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, CallAttempts{Made: 1, Max: 1}, attempts.Attempts())
+	})
+
+	t.Run("deadline stops retries", func(t *testing.T) {
+		slow := domain.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+		inv := newFlakyInvoker(t, codes.Unavailable, 5, slow)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ctx, attempts := WithCallAttempts(ctx)
+		start := time.Now()
+		_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		assert.Equal(t, codes.Unavailable, status.Code(err), "the last attempt's error, not the deadline")
+		assert.Equal(t, 1, attempts.Attempts().Made)
+		assert.Less(t, time.Since(start), time.Second, "gave up without waiting")
+	})
+
+	t.Run("cancel during backoff", func(t *testing.T) {
+		slow := domain.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+		inv := newFlakyInvoker(t, codes.Unavailable, 5, slow)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), req, nil)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestInvokeServerStream_NotRetried(t *testing.T) {
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithStatus("grpctest.TestService/StreamItems", codes.Unavailable),
+	)
+	inv := NewInvoker(srv.Conn, testLogger)
+	inv.SetRetryPolicy(domain.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	msgs, errs, _, _ := inv.InvokeServerStream(context.Background(), testMethod(t, "StreamItems"), `{}`, nil)
+	for range msgs {
+	}
+	err := <-errs
+	assert.NotEqual(t, io.EOF, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := retryPolicy{initialBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	var got []time.Duration
+	for retry := 1; retry <= 6; retry++ {
+		got = append(got, p.backoff(retry))
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	}, got)
+
+	p.maxBackoff = 0
+	assert.Equal(t, 3200*time.Millisecond, p.backoff(6), "uncapped")
+}
+
+func TestParseCode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    codes.Code
+		wantErr bool
+	}{
+		{"UNAVAILABLE", codes.Unavailable, false},
+		{"Unavailable", codes.Unavailable, false},
+		{"unavailable", codes.Unavailable, false},
+		{"OK", codes.OK, false},
+		{"CANCELLED", codes.Canceled, false},
+		{"cancelled", codes.Canceled, false},
+		{"Canceled", codes.Canceled, false},
+		{"NOT_FOUND", codes.NotFound, false},
+		{"NotFound", codes.NotFound, false},
+		{" unauthenticated ", codes.Unauthenticated, false},
+		{"deadline_exceeded", codes.DeadlineExceeded, false},
+		{"8", codes.ResourceExhausted, false},
+		{"99", codes.Unknown, true},
+		{"", codes.Unknown, true},
+		{"teapot", codes.Unknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseCode(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package grpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// SchemaCache persists the descriptors fetched over reflection between
+// sessions. Entries are keyed by server address and a hash of the server's
+// service list, so a server that adds or removes services is re-fetched.
+type SchemaCache interface {
+	// Load returns the files cached for address under servicesHash, or nil
+	// when there is no such entry.
+	Load(address, servicesHash string) ([]*descriptorpb.FileDescriptorProto, error)
+	// Save replaces the entry for address.
+	Save(address, servicesHash string, files []*descriptorpb.FileDescriptorProto) error
+	// Invalidate drops the entry for address, if any.
+	Invalidate(address string) error
+}
+
+// NewReflectionClientFromDescriptors creates a ReflectionClient that serves
+// ListServices and GetMethodDescriptor from already-fetched descriptors, such
+// as a schema cache entry, with the same fix-ups as a descriptor set. No
+// reflection calls are made on conn.
+func NewReflectionClientFromDescriptors(conn grpc.ClientConnInterface, fdProtos []*descriptorpb.FileDescriptorProto, logger *slog.Logger) (*ReflectionClient, error) {
+	files, _, err := localFileDescriptors(fdProtos, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cached descriptors: %w", err)
+	}
+	return &ReflectionClient{
+		conn:         conn,
+		logger:       logger,
+		serviceCache: make(map[string]protoreflect.ServiceDescriptor),
+		localFiles:   files,
+	}, nil
+}
+
+// SetSchemaCache makes ListServices reuse the descriptors cached for address
+// while the server lists the same services as when they were cached, and
+// cache freshly resolved ones otherwise. It has no effect on clients loaded
+// from local descriptors.
+func (r *ReflectionClient) SetSchemaCache(cache SchemaCache, address string) {
+	r.schemaCache = cache
+	r.schemaAddress = address
+}
+
+// InvalidateSchemaCache drops the cached descriptors for this client's
+// server and forgets resolved services and fetched files, so the next
+// ListServices resolves everything over reflection again.
+func (r *ReflectionClient) InvalidateSchemaCache() error {
+	r.mu.Lock()
+	r.serviceCache = make(map[string]protoreflect.ServiceDescriptor)
+	r.fromSchemaCache = false
+	r.mu.Unlock()
+	if r.lenient != nil {
+		r.lenient.clear()
+	}
+	if r.schemaCache == nil {
+		return nil
+	}
+	if err := r.schemaCache.Invalidate(r.schemaAddress); err != nil {
+		return fmt.Errorf("failed to invalidate schema cache: %w", err)
+	}
+	return nil
+}
+
+// FromSchemaCache reports whether the last ListServices was served from the
+// schema cache rather than resolved over reflection.
+func (r *ReflectionClient) FromSchemaCache() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.fromSchemaCache
+}
+
+// FileDescriptorProtos returns the files declaring the resolved services and
+// everything they import, dependencies first. Placeholders left by lenient
+// resolution are omitted.
+func (r *ReflectionClient) FileDescriptorProtos() []*descriptorpb.FileDescriptorProto {
+	r.mu.RLock()
+	services := maps.Clone(r.serviceCache)
+	r.mu.RUnlock()
+	names := slices.Sorted(maps.Keys(services))
+
+	var out []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var visit func(fd protoreflect.FileDescriptor)
+	visit = func(fd protoreflect.FileDescriptor) {
+		if fd == nil || fd.IsPlaceholder() || seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := range imports.Len() {
+			visit(imports.Get(i).FileDescriptor)
+		}
+		out = append(out, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, name := range names {
+		visit(services[name].ParentFile())
+	}
+	return out
+}
+
+// servicesHash fingerprints a ListServices response, ignoring order.
+func servicesHash(names []protoreflect.FullName) string {
+	sorted := make([]string, len(names))
+	for i, n := range names {
+		sorted[i] = string(n)
+	}
+	slices.Sort(sorted)
+
+	h := sha256.New()
+	for _, n := range sorted {
+		h.Write([]byte(n))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// listCachedServices serves ListServices from the schema cache when it holds
+// an entry for hash that declares every listed service. It returns false on
+// a miss or an unusable entry, leaving the client to resolve over reflection.
+func (r *ReflectionClient) listCachedServices(names []protoreflect.FullName, hash string) ([]domain.Service, bool) {
+	fdProtos, err := r.schemaCache.Load(r.schemaAddress, hash)
+	if err != nil {
+		r.logger.Warn("failed to load schema cache",
+			slog.String("address", r.schemaAddress),
+			slog.Any("error", err),
+		)
+		return nil, false
+	}
+	if len(fdProtos) == 0 {
+		return nil, false
+	}
+
+	files, _, err := localFileDescriptors(fdProtos, r.logger)
+	if err != nil {
+		r.logger.Warn("cached schema could not be built",
+			slog.String("address", r.schemaAddress),
+			slog.Any("error", err),
+		)
+		return nil, false
+	}
+
+	byName := make(map[protoreflect.FullName]protoreflect.ServiceDescriptor)
+	for _, fd := range files {
+		for i := range fd.Services().Len() {
+			sd := fd.Services().Get(i)
+			byName[sd.FullName()] = sd
+		}
+	}
+
+	var services []domain.Service
+	resolved := make(map[string]protoreflect.ServiceDescriptor)
+	for _, name := range names {
+		if isReflectionService(string(name)) {
+			continue
+		}
+		sd, ok := byName[name]
+		if !ok {
+			r.logger.Info("schema cache is missing a service, re-fetching",
+				slog.String("address", r.schemaAddress),
+				slog.String("service", string(name)),
+			)
+			return nil, false
+		}
+		resolved[string(name)] = sd
+		services = append(services, r.convertService(sd))
+	}
+
+	r.mu.Lock()
+	r.serviceCache = resolved
+	r.fromSchemaCache = true
+	r.mu.Unlock()
+	r.logger.Info("discovered services from schema cache",
+		slog.String("address", r.schemaAddress),
+		slog.Int("service_count", len(services)),
+	)
+	return services, true
+}
+
+// storeSchema caches the descriptors resolved by ListServices under hash.
+// Listings with failed services or methods are not cached, so they are
+// retried next session.
+func (r *ReflectionClient) storeSchema(hash string, services []domain.Service) {
+	for _, s := range services {
+		if s.Error != "" {
+			return
+		}
+		for _, m := range s.Methods {
+			if m.Error != "" {
+				return
+			}
+		}
+	}
+	if err := r.schemaCache.Save(r.schemaAddress, hash, r.FileDescriptorProtos()); err != nil {
+		r.logger.Warn("failed to save schema cache",
+			slog.String("address", r.schemaAddress),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// memSchemaCache is an in-memory SchemaCache holding one entry per address.
+type memSchemaCache struct {
+	entries map[string]memSchemaEntry
+	saves   int
+}
+
+type memSchemaEntry struct {
+	hash  string
+	files []*descriptorpb.FileDescriptorProto
+}
+
+func newMemSchemaCache() *memSchemaCache {
+	return &memSchemaCache{entries: make(map[string]memSchemaEntry)}
+}
+
+func (c *memSchemaCache) Load(address, servicesHash string) ([]*descriptorpb.FileDescriptorProto, error) {
+	e, ok := c.entries[address]
+	if !ok || e.hash != servicesHash {
+		return nil, nil
+	}
+	return e.files, nil
+}
+
+func (c *memSchemaCache) Save(address, servicesHash string, files []*descriptorpb.FileDescriptorProto) error {
+	c.saves++
+	c.entries[address] = memSchemaEntry{hash: servicesHash, files: files}
+	return nil
+}
+
+func (c *memSchemaCache) Invalidate(address string) error {
+	delete(c.entries, address)
+	return nil
+}
+
+// cachedClient returns a reflection client on testConn backed by cache.
+func cachedClient(t *testing.T, cache SchemaCache) *ReflectionClient {
+	t.Helper()
+	rc := NewReflectionClient(testConn, testLogger)
+	rc.SetSchemaCache(cache, "test-server")
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func TestSchemaCache_StoresAndReuses(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemSchemaCache()
+
+	first := cachedClient(t, cache)
+	live, err := first.ListServices(ctx)
+	require.NoError(t, err)
+	assert.False(t, first.FromSchemaCache())
+	require.Contains(t, cache.entries, "test-server")
+
+	var paths []string
+	for _, f := range cache.entries["test-server"].files {
+		paths = append(paths, f.GetName())
+	}
+	assert.Contains(t, paths, "grpc_test.proto")
+
+	// A new session against the same service list is served from the cache
+	second := cachedClient(t, cache)
+	cached, err := second.ListServices(ctx)
+	require.NoError(t, err)
+	assert.True(t, second.FromSchemaCache())
+	assert.Equal(t, live, cached)
+	assert.Equal(t, 1, cache.saves, "a cache hit is not written back")
+
+	md, err := second.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+	resp, _, _, err := NewInvoker(testConn, testLogger).InvokeUnary(ctx, md, `{"item":{"id":"cached"}}`, nil)
+	require.NoError(t, err)
+	assert.Contains(t, resp, "cached")
+}
+
+func TestSchemaCache_ServiceListChangeRefetches(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemSchemaCache()
+	stale := &descriptorpb.FileDescriptorProto{Name: strPtr("stale.proto")}
+	require.NoError(t, cache.Save("test-server", "previous-services", []*descriptorpb.FileDescriptorProto{stale}))
+
+	rc := cachedClient(t, cache)
+	services, err := rc.ListServices(ctx)
+	require.NoError(t, err)
+	assert.False(t, rc.FromSchemaCache())
+	require.Len(t, services, 1)
+	assert.Equal(t, "grpctest.TestService", services[0].FullName)
+
+	entry := cache.entries["test-server"]
+	assert.NotEqual(t, "previous-services", entry.hash)
+	assert.NotContains(t, entry.files, stale)
+}
+
+func TestSchemaCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemSchemaCache()
+
+	rc := cachedClient(t, cache)
+	_, err := rc.ListServices(ctx)
+	require.NoError(t, err)
+	_, err = rc.ListServices(ctx)
+	require.NoError(t, err)
+	require.True(t, rc.FromSchemaCache())
+
+	require.NoError(t, rc.InvalidateSchemaCache())
+	assert.NotContains(t, cache.entries, "test-server")
+	assert.False(t, rc.FromSchemaCache())
+
+	// The next listing goes back to reflection and re-populates the cache
+	_, err = rc.ListServices(ctx)
+	require.NoError(t, err)
+	assert.False(t, rc.FromSchemaCache())
+	assert.Contains(t, cache.entries, "test-server")
+}
+
+func TestSchemaCache_UnusableEntryFallsBackToReflection(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemSchemaCache()
+	_, err := cachedClient(t, cache).ListServices(ctx)
+	require.NoError(t, err)
+
+	// Same service list, but the entry doesn't declare the service
+	empty := &descriptorpb.FileDescriptorProto{Name: strPtr("empty.proto"), Package: strPtr("other")}
+	hash := cache.entries["test-server"].hash
+	require.NoError(t, cache.Save("test-server", hash, []*descriptorpb.FileDescriptorProto{empty}))
+
+	rc := cachedClient(t, cache)
+	services, err := rc.ListServices(ctx)
+	require.NoError(t, err)
+	assert.False(t, rc.FromSchemaCache())
+	require.Len(t, services, 1)
+	assert.Empty(t, services[0].Error)
+}
+
+func TestServicesHash_IgnoresOrder(t *testing.T) {
+	a := servicesHash([]protoreflect.FullName{"a.A", "b.B"})
+	assert.Equal(t, a, servicesHash([]protoreflect.FullName{"b.B", "a.A"}))
+	assert.NotEqual(t, a, servicesHash([]protoreflect.FullName{"a.A"}))
+	assert.NotEqual(t, a, servicesHash([]protoreflect.FullName{"a.Ab.B"}))
+}
+
+func TestNewReflectionClientFromDescriptors(t *testing.T) {
+	rc, err := NewReflectionClientFromDescriptors(nil, []*descriptorpb.FileDescriptorProto{makeServiceFDP(nil)}, discardLogger)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "test.noncanonical.v1.NonCanonicalService", services[0].FullName)
+
+	_, err = rc.GetMethodDescriptor("test.noncanonical.v1.Missing", "GetItem")
+	assert.ErrorContains(t, err, "not found in cached descriptors")
+
+	_, err = NewReflectionClientFromDescriptors(nil, nil, discardLogger)
+	assert.Error(t, err)
+}
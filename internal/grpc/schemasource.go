@@ -0,0 +1,22 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc"
+)
+
+// NewSchemaSource creates the ReflectionClient a connection profile asks
+// for: one reading its descriptor set, one compiling its .proto sources,
+// or, when it names neither, one using server reflection over conn.
+func NewSchemaSource(ctx context.Context, conn grpc.ClientConnInterface, cfg domain.Connection, logger *slog.Logger) (*ReflectionClient, error) {
+	switch {
+	case cfg.DescriptorSetFile != "":
+		return NewReflectionClientFromDescriptorSet(conn, cfg.DescriptorSetFile, logger)
+	case len(cfg.ProtoImportPaths) > 0:
+		return NewReflectionClientFromProtoSources(ctx, conn, cfg.ProtoImportPaths, logger)
+	}
+	return NewReflectionClient(conn, logger), nil
+}
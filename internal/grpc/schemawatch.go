@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc"
+)
+
+// DefaultSchemaCheckInterval is how often SchemaWatcher lists services by
+// default.
+const DefaultSchemaCheckInterval = 5 * time.Minute
+
+// maxSchemaCheckTimeout bounds a single listing.
+const maxSchemaCheckTimeout = 30 * time.Second
+
+// SchemaWatcher lists a server's services over reflection in the
+// background, so a deploy that changes them can be noticed without
+// reconnecting. Each listing uses a fresh reflection client, bypassing the
+// schema cache and the descriptors the session already resolved.
+type SchemaWatcher struct {
+	conn     grpc.ClientConnInterface
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSchemaWatcher creates a watcher that lists services every interval
+// (DefaultSchemaCheckInterval when interval is not positive).
+func NewSchemaWatcher(conn grpc.ClientConnInterface, interval time.Duration, logger *slog.Logger) *SchemaWatcher {
+	if interval <= 0 {
+		interval = DefaultSchemaCheckInterval
+	}
+	return &SchemaWatcher{
+		conn:     conn,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start lists services every interval, starting one interval from now, and
+// passes each listing to fn on the watcher's goroutine. Failed listings are
+// logged and skipped. Start replaces any earlier run.
+func (w *SchemaWatcher) Start(fn func([]domain.Service)) {
+	w.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	w.mu.Lock()
+	w.cancel = cancel
+	w.done = done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			services, err := w.List(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				w.logger.Debug("schema check failed", slog.Any("error", err))
+				continue
+			}
+			fn(services)
+		}
+	}()
+}
+
+// Stop ends polling and waits for an in-flight listing to finish. No
+// listings are delivered after Stop returns.
+func (w *SchemaWatcher) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// List lists the server's services once, as the watcher does on each tick.
+func (w *SchemaWatcher) List(ctx context.Context) ([]domain.Service, error) {
+	ctx, cancel := context.WithTimeout(ctx, maxSchemaCheckTimeout)
+	defer cancel()
+
+	rc := NewReflectionClient(w.conn, w.logger)
+	defer rc.Close()
+	return rc.ListServices(ctx)
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaWatcher(t *testing.T) {
+	watcher := NewSchemaWatcher(testConn, 20*time.Millisecond, testLogger)
+
+	listings := make(chan []domain.Service, 100)
+	watcher.Start(func(services []domain.Service) { listings <- services })
+
+	var services []domain.Service
+	select {
+	case services = <-listings:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no listing delivered")
+	}
+	watcher.Stop()
+
+	var names []string
+	for _, svc := range services {
+		names = append(names, svc.FullName)
+	}
+	assert.Contains(t, names, "grpctest.TestService")
+
+	// Nothing arrives once stopped
+	for len(listings) > 0 {
+		<-listings
+	}
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, listings)
+}
+
+func TestSchemaWatcher_ListMatchesSession(t *testing.T) {
+	rc := NewReflectionClient(testConn, testLogger)
+	defer rc.Close()
+	session, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+
+	fresh, err := NewSchemaWatcher(testConn, 0, testLogger).List(context.Background())
+	require.NoError(t, err)
+	assert.True(t, domain.DiffServices(session, fresh).IsEmpty())
+}
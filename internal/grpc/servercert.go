@@ -0,0 +1,186 @@
+package grpc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertExpiryWarning is how close to expiring a certificate is flagged.
+const CertExpiryWarning = 14 * 24 * time.Hour
+
+// certTimeLayout is how certificate validity dates are shown.
+const certTimeLayout = "2006-01-02 15:04:05 MST"
+
+// CertInfo describes one certificate of a server's chain.
+type CertInfo struct {
+	Subject     string
+	Issuer      string
+	SANs        []string // DNS names, IP addresses, URIs and emails
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Fingerprint string // SHA-256 of the certificate, colon-separated hex
+	IsCA        bool
+
+	Expired     bool
+	ExpiresSoon bool   // Within CertExpiryWarning, and not yet expired
+	Expiry      string // When it expires or expired, relative to when it was described
+}
+
+// CertField is a labelled line of a certificate's details.
+type CertField struct {
+	Name  string
+	Value string
+}
+
+// Fields returns the certificate's details as labelled lines, for showing.
+func (c CertInfo) Fields() []CertField {
+	sans := strings.Join(c.SANs, ", ")
+	if sans == "" {
+		sans = "none"
+	}
+	return []CertField{
+		{"Subject", c.Subject},
+		{"Issuer", c.Issuer},
+		{"SANs", sans},
+		{"Not before", c.NotBefore.Format(certTimeLayout)},
+		{"Not after", c.NotAfter.Format(certTimeLayout) + " (" + c.Expiry + ")"},
+		{"SHA-256", c.Fingerprint},
+	}
+}
+
+// ServerCertificates is what a TLS connection's server presented.
+type ServerCertificates struct {
+	Version     string // TLS version, e.g. "TLS 1.3"
+	CipherSuite string
+	ServerName  string     // Name sent for SNI; empty when dialing an IP without an override
+	Verified    bool       // The chain was verified; false when verification was skipped
+	Chain       []CertInfo // Leaf first
+}
+
+// Warn reports whether any certificate of the chain has expired or is
+// about to.
+func (s ServerCertificates) Warn() bool {
+	for _, c := range s.Chain {
+		if c.Expired || c.ExpiresSoon {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeServerCertificates describes the certificates the server
+// presented in a TLS handshake, with their expiry relative to now.
+func DescribeServerCertificates(state tls.ConnectionState, now time.Time) ServerCertificates {
+	s := ServerCertificates{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+		Verified:    len(state.VerifiedChains) > 0,
+	}
+	for _, cert := range state.PeerCertificates {
+		s.Chain = append(s.Chain, describeCert(cert, now))
+	}
+	return s
+}
+
+// describeCert describes cert, with its expiry relative to now.
+func describeCert(cert *x509.Certificate, now time.Time) CertInfo {
+	info := CertInfo{
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		SANs:        append([]string(nil), cert.DNSNames...),
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		Fingerprint: fingerprint(cert.Raw),
+		IsCA:        cert.IsCA,
+	}
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		info.SANs = append(info.SANs, uri.String())
+	}
+	info.SANs = append(info.SANs, cert.EmailAddresses...)
+
+	left := cert.NotAfter.Sub(now)
+	switch {
+	case left < 0:
+		info.Expired = true
+		info.Expiry = "expired " + formatCertDuration(-left) + " ago"
+	case left < CertExpiryWarning:
+		info.ExpiresSoon = true
+		info.Expiry = "expires in " + formatCertDuration(left)
+	default:
+		info.Expiry = "expires in " + formatCertDuration(left)
+	}
+	return info
+}
+
+// formatCertDuration renders a time to or since expiry in days, or hours
+// under two days.
+func formatCertDuration(d time.Duration) string {
+	if d < 48*time.Hour {
+		hours := int(d.Hours())
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
+
+// fingerprint returns the SHA-256 of der as colon-separated hex.
+func fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	hexSum := strings.ToUpper(hex.EncodeToString(sum[:]))
+	pairs := make([]string, 0, len(sum))
+	for i := 0; i < len(hexSum); i += 2 {
+		pairs = append(pairs, hexSum[i:i+2])
+	}
+	return strings.Join(pairs, ":")
+}
+
+// certRecorder keeps the state of a connection's last TLS handshake. It
+// is installed as the TLS config's VerifyConnection, which runs for
+// gRPC and gRPC-Web alike, and even when verification is skipped.
+type certRecorder struct {
+	mu    sync.Mutex
+	state *tls.ConnectionState
+}
+
+// recordTo makes config report its handshakes to a new recorder.
+func recordTo(config *tls.Config) *certRecorder {
+	r := &certRecorder{}
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		r.mu.Lock()
+		r.state = &state
+		r.mu.Unlock()
+		return nil
+	}
+	return r
+}
+
+// ServerCertificates describes the certificates the server presented in
+// the current connection's last TLS handshake. It reports false for
+// plaintext connections and before the first handshake completes.
+func (m *ConnectionManager) ServerCertificates() (ServerCertificates, bool) {
+	m.mu.RLock()
+	r := m.certs
+	m.mu.RUnlock()
+	if r == nil {
+		return ServerCertificates{}, false
+	}
+	r.mu.Lock()
+	state := r.state
+	r.mu.Unlock()
+	if state == nil {
+		return ServerCertificates{}, false
+	}
+	return DescribeServerCertificates(*state, time.Now()), true
+}
@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert creates a certificate valid from notBefore to notAfter, signed
+// by parent's key, or self-signed when parent is nil.
+func testCert(t *testing.T, tmpl *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func TestDescribeServerCertificates(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ca, caKey := testCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Dev Root CA", Organization: []string{"Grotto"}},
+		NotBefore:             now.AddDate(-1, 0, 0),
+		NotAfter:              now.AddDate(5, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/dev/sa/api")
+	leaf, _ := testCert(t, &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "api.dev.internal"},
+		NotBefore:      now.AddDate(0, -3, 0),
+		NotAfter:       now.Add(5*24*time.Hour + time.Hour),
+		DNSNames:       []string{"api.dev.internal", "*.api.dev.internal"},
+		IPAddresses:    []net.IP{net.IPv4(10, 0, 0, 7)},
+		URIs:           []*url.URL{spiffe},
+		EmailAddresses: []string{"ops@example.com"},
+	}, ca, caKey)
+
+	certs := DescribeServerCertificates(tls.ConnectionState{
+		Version:          tls.VersionTLS13,
+		CipherSuite:      tls.TLS_AES_128_GCM_SHA256,
+		ServerName:       "api.dev.internal",
+		PeerCertificates: []*x509.Certificate{leaf, ca},
+	}, now)
+	assert.Equal(t, "TLS 1.3", certs.Version)
+	assert.Equal(t, "TLS_AES_128_GCM_SHA256", certs.CipherSuite)
+	assert.Equal(t, "api.dev.internal", certs.ServerName)
+	assert.False(t, certs.Verified, "no verified chains")
+	require.Len(t, certs.Chain, 2)
+
+	got := certs.Chain[0]
+	assert.Equal(t, "CN=api.dev.internal", got.Subject)
+	assert.Equal(t, "CN=Dev Root CA,O=Grotto", got.Issuer)
+	assert.Equal(t, []string{"api.dev.internal", "*.api.dev.internal", "10.0.0.7", "spiffe://cluster.local/ns/dev/sa/api", "ops@example.com"}, got.SANs)
+	assert.Len(t, strings.Split(got.Fingerprint, ":"), 32)
+	assert.Equal(t, strings.ToUpper(got.Fingerprint), got.Fingerprint)
+	assert.True(t, got.ExpiresSoon)
+	assert.False(t, got.Expired)
+	assert.Equal(t, "expires in 5 days", got.Expiry)
+	assert.True(t, certs.Warn())
+
+	root := certs.Chain[1]
+	assert.True(t, root.IsCA)
+	assert.False(t, root.ExpiresSoon)
+	assert.Empty(t, root.SANs)
+
+	fields := root.Fields()
+	assert.Equal(t, CertField{"SANs", "none"}, fields[2])
+	assert.Equal(t, CertField{"Not after", "2031-03-01 12:00:00 UTC (expires in 1826 days)"}, fields[4])
+
+	// Past its expiry, and hours before it
+	expired := DescribeServerCertificates(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, now.AddDate(0, 0, 8))
+	assert.True(t, expired.Chain[0].Expired)
+	assert.False(t, expired.Chain[0].ExpiresSoon)
+	assert.Equal(t, "expired 2 days ago", expired.Chain[0].Expiry)
+	soon := DescribeServerCertificates(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, leaf.NotAfter.Add(-3*time.Hour))
+	assert.Equal(t, "expires in 3 hours", soon.Chain[0].Expiry)
+
+	assert.False(t, DescribeServerCertificates(tls.ConnectionState{PeerCertificates: []*x509.Certificate{ca}}, now).Warn())
+}
+
+func TestConnect_ServerCertificates(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithTLS())
+	caFile := writeFile(t, "ca.pem", srv.CertPEM)
+	md := testMethod(t, "UnaryEcho")
+
+	connect := func(cfg domain.Connection) *ConnectionManager {
+		m := NewConnectionManager(testLogger)
+		require.NoError(t, m.Connect(context.Background(), cfg))
+		t.Cleanup(func() { _ = m.Disconnect() })
+		_, _, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), md, `{}`, nil)
+		require.NoError(t, err)
+		return m
+	}
+
+	m := connect(domain.Connection{Address: srv.Addr, TLS: domain.TLSSettings{Enabled: true, CertFile: caFile, ServerNameOverride: "localhost"}})
+	certs, ok := m.ServerCertificates()
+	require.True(t, ok)
+	assert.True(t, certs.Verified)
+	assert.Equal(t, "localhost", certs.ServerName)
+	require.Len(t, certs.Chain, 1)
+	assert.Equal(t, "CN=grotto test server", certs.Chain[0].Subject)
+	assert.Contains(t, certs.Chain[0].SANs, "127.0.0.1")
+	assert.True(t, certs.Chain[0].ExpiresSoon, "the test certificate lasts a day")
+
+	require.NoError(t, m.Disconnect())
+	_, ok = m.ServerCertificates()
+	assert.False(t, ok, "forgotten on disconnect")
+
+	m = connect(domain.Connection{Address: srv.Addr, TLS: domain.TLSSettings{Enabled: true, SkipVerify: true}})
+	certs, ok = m.ServerCertificates()
+	require.True(t, ok, "recorded when verification is skipped too")
+	assert.False(t, certs.Verified)
+
+	plain := grpctest.StartServer(t, grpctest.WithTestService())
+	m = connect(domain.Connection{Address: plain.Addr})
+	_, ok = m.ServerCertificates()
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Target schemes for unix domain sockets, as understood by grpc-go's
+// built-in resolvers.
+const (
+	unixScheme         = "unix:"
+	unixAbstractScheme = "unix-abstract:"
+)
+
+// IsUnixTarget reports whether address names a unix domain socket:
+// unix:relative/path, unix:///absolute/path or unix-abstract:name.
+func IsUnixTarget(address string) bool {
+	return strings.HasPrefix(address, unixScheme) || strings.HasPrefix(address, unixAbstractScheme)
+}
+
+// UnixSocketPath returns the file path of a unix: target, e.g.
+// /var/run/app.sock for unix:///var/run/app.sock. It returns false for
+// abstract sockets and other targets.
+func UnixSocketPath(address string) (string, bool) {
+	rest, ok := strings.CutPrefix(address, unixScheme)
+	if !ok {
+		return "", false
+	}
+	if after, ok := strings.CutPrefix(rest, "//"); ok {
+		// unix://absolute_path: the authority must be empty
+		if !strings.HasPrefix(after, "/") {
+			return "", false
+		}
+		rest = after
+	}
+	return rest, rest != ""
+}
+
+// ValidateAddress checks that address is something Connect can dial: a host
+// or host:port (IPv6 hosts in brackets), a target URI such as
+// dns:///host:port, or a unix socket. Unix socket files must exist.
+func ValidateAddress(address string) error {
+	switch {
+	case strings.TrimSpace(address) == "":
+		return errors.New("enter a server address")
+	case strings.ContainsAny(address, " \t\n"):
+		return fmt.Errorf("address %q contains spaces", address)
+	case strings.HasPrefix(address, unixAbstractScheme):
+		if address == unixAbstractScheme {
+			return errors.New("unix-abstract: needs a socket name")
+		}
+		return nil
+	case strings.HasPrefix(address, unixScheme):
+		return checkUnixSocket(address)
+	case strings.Contains(address, "://"):
+		// Other target URIs (dns:///, passthrough:///, http:// for gRPC-Web)
+		// are left to the resolver
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// A bare host uses the default port, unless it looks like a
+		// mangled host:port
+		if strings.Count(address, ":") == 1 {
+			return fmt.Errorf("address %q: %w", address, err)
+		}
+		return nil
+	}
+	if host == "" && port == "" {
+		return fmt.Errorf("address %q has no host or port", address)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("address %q: port must be a number from 0 to 65535", address)
+	}
+	return nil
+}
+
+// checkUnixSocket returns a friendly error when a unix: target is malformed
+// or its socket file is missing, instead of the resolver's connection error
+// on the first call.
+func checkUnixSocket(address string) error {
+	path, ok := UnixSocketPath(address)
+	if !ok {
+		return fmt.Errorf("unix socket address %q must be unix:relative/path or unix:///absolute/path", address)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("unix socket %s does not exist (is the server running?)", path)
+		}
+		return fmt.Errorf("unix socket %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a unix socket", path)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	tests := []struct {
+		address string
+		path    string
+		ok      bool
+	}{
+		{"unix:///var/run/app.sock", "/var/run/app.sock", true},
+		{"unix:/var/run/app.sock", "/var/run/app.sock", true},
+		{"unix:app.sock", "app.sock", true},
+		{"unix://host/app.sock", "", false},
+		{"unix:", "", false},
+		{"unix-abstract:grotto", "", false},
+		{"localhost:50051", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			path, ok := UnixSocketPath(tt.address)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	dir := t.TempDir()
+	plainFile := filepath.Join(dir, "plain")
+	require.NoError(t, os.WriteFile(plainFile, nil, 0o600))
+	srv := grpctest.StartServer(t, grpctest.WithUnixSocket(filepath.Join(dir, "ok.sock")))
+
+	tests := []struct {
+		address string
+		wantErr string
+	}{
+		{"localhost:50051", ""},
+		{"127.0.0.1:0", ""},
+		{"[::1]:50051", ""},
+		{"localhost", ""},
+		{"dns:///api.example.com:443", ""},
+		{"http://localhost:8080", ""},
+		{"unix-abstract:grotto", ""},
+		{srv.Addr, ""},
+		{"", "enter a server address"},
+		{"local host:1", "contains spaces"},
+		{"localhost:http", "port must be a number"},
+		{"localhost:70000", "port must be a number"},
+		{"unix-abstract:", "needs a socket name"},
+		{"unix://host/app.sock", "must be unix:relative/path"},
+		{"unix://" + filepath.Join(dir, "missing.sock"), "does not exist"},
+		{"unix://" + plainFile, "is not a unix socket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := ValidateAddress(tt.address)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConnectionManager_UnixSocket(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(),
+		grpctest.WithUnixSocket(filepath.Join(t.TempDir(), "grotto.sock")))
+
+	m := NewConnectionManager(testLogger)
+	t.Cleanup(func() { _ = m.Disconnect() })
+	require.NoError(t, m.Connect(context.Background(), domain.Connection{Address: srv.Addr}))
+	assert.Equal(t, StateConnected, m.State())
+
+	rc := NewReflectionClient(m.Conn(), testLogger)
+	defer rc.Close()
+	services, err := rc.ListServices(context.Background())
+	require.NoError(t, err)
+	var names []string
+	for _, svc := range services {
+		names = append(names, svc.FullName)
+	}
+	assert.Contains(t, names, "grpctest.TestService")
+
+	md, err := rc.GetMethodDescriptor("grpctest.TestService", "UnaryEcho")
+	require.NoError(t, err)
+	resp, _, _, err := NewInvoker(m.Conn(), testLogger).InvokeUnary(context.Background(), md, `{"item":{"id":"sock"}}`, nil)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resp), &result))
+	assert.Equal(t, true, result["ok"])
+}
+
+func TestConnectionManager_UnixSocketErrors(t *testing.T) {
+	m := NewConnectionManager(testLogger)
+	t.Cleanup(func() { _ = m.Disconnect() })
+
+	missing := "unix://" + filepath.Join(t.TempDir(), "missing.sock")
+	err := m.Connect(context.Background(), domain.Connection{Address: missing})
+	assert.ErrorContains(t, err, "does not exist")
+	assert.Equal(t, StateError, m.State())
+
+	err = m.Connect(context.Background(), domain.Connection{Address: "unix-abstract:grotto", Transport: domain.TransportGRPCWeb})
+	assert.ErrorContains(t, err, "not supported")
+}
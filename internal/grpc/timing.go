@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/stats"
+)
+
+// maxTimedMessages bounds the per-message timings kept for one call, so a
+// long stream cannot grow them without limit. Counts and sizes keep
+// adding up past it.
+const maxTimedMessages = 1000
+
+// CallTiming is where the time of one call went, as reported by the
+// connection's stats handler. Durations are measured from Start; a zero
+// FirstHeader or FirstMessage means none was received.
+type CallTiming struct {
+	Start        time.Time
+	FirstHeader  time.Duration // Response headers received
+	FirstMessage time.Duration // First response message received
+	Total        time.Duration // Call finished; zero while in progress
+
+	SentMessages     int
+	ReceivedMessages int
+	SentBytes        int // On the wire, after compression
+	ReceivedBytes    int
+
+	// Messages holds the first maxTimedMessages messages in either
+	// direction, in order
+	Messages []MessageTiming
+}
+
+// MessageTiming is when one message of a call was sent or received.
+type MessageTiming struct {
+	At    time.Duration // Since the call started
+	Sent  bool          // Sent by the client, else received
+	Bytes int           // On the wire
+}
+
+// Done reports whether the call has finished.
+func (t CallTiming) Done() bool {
+	return t.Total > 0
+}
+
+// TimingRecorder collects the CallTiming of the call made with its context.
+type TimingRecorder struct {
+	mu     sync.Mutex
+	timing CallTiming
+	seen   bool             // Begin has been handled
+	now    func() time.Time // Time of events that carry none
+}
+
+type timingRecorderKey struct{}
+
+// WithCallTiming returns a context whose call reports its timing to the
+// returned recorder. Only connections dialed by ConnectionManager have the
+// stats handler that feeds it; on others the timing stays empty.
+func WithCallTiming(ctx context.Context) (context.Context, *TimingRecorder) {
+	rec := &TimingRecorder{now: time.Now}
+	return context.WithValue(ctx, timingRecorderKey{}, rec), rec
+}
+
+// Timing returns a copy of the timing recorded so far, and false when
+// nothing was recorded.
+func (r *TimingRecorder) Timing() (CallTiming, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.timing
+	t.Messages = append([]MessageTiming(nil), t.Messages...)
+	return t, r.seen
+}
+
+// handle folds one stats event into the timing.
+func (r *TimingRecorder) handle(s stats.RPCStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := &r.timing
+	switch s := s.(type) {
+	case *stats.Begin:
+		// A retried call starts over; its timing is the last attempt's
+		*t = CallTiming{Start: s.BeginTime}
+		r.seen = true
+	case *stats.InHeader:
+		if t.FirstHeader == 0 {
+			t.FirstHeader = r.since(r.now())
+		}
+	case *stats.OutPayload:
+		t.SentMessages++
+		t.SentBytes += s.WireLength
+		r.addMessage(MessageTiming{At: r.since(s.SentTime), Sent: true, Bytes: s.WireLength})
+	case *stats.InPayload:
+		at := r.since(s.RecvTime)
+		if t.ReceivedMessages == 0 {
+			t.FirstMessage = at
+		}
+		t.ReceivedMessages++
+		t.ReceivedBytes += s.WireLength
+		r.addMessage(MessageTiming{At: at, Bytes: s.WireLength})
+	case *stats.End:
+		t.Total = s.EndTime.Sub(s.BeginTime)
+	}
+}
+
+// since returns how long after the call started at was, never less than
+// a nanosecond so that a recorded moment is told apart from none.
+func (r *TimingRecorder) since(at time.Time) time.Duration {
+	return max(at.Sub(r.timing.Start), time.Nanosecond)
+}
+
+// addMessage records a message timing while there is room.
+func (r *TimingRecorder) addMessage(m MessageTiming) {
+	if len(r.timing.Messages) < maxTimedMessages {
+		r.timing.Messages = append(r.timing.Messages, m)
+	}
+}
+
+// timingStats is a stats handler that reports each call's events to the
+// TimingRecorder in its context.
+type timingStats struct{}
+
+func (timingStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (timingStats) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if !s.IsClient() {
+		return
+	}
+	if rec, ok := ctx.Value(timingRecorderKey{}).(*TimingRecorder); ok {
+		rec.handle(s)
+	}
+}
+
+func (timingStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (timingStats) HandleConn(context.Context, stats.ConnStats) {}
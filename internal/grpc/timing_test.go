@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+func TestTimingRecorder_Unary(t *testing.T) {
+	ctx, rec := WithCallTiming(context.Background())
+	start := time.Date(2024, 6, 15, 8, 0, 0, 0, time.UTC)
+	rec.now = func() time.Time { return start.Add(40 * time.Millisecond) }
+
+	_, ok := rec.Timing()
+	assert.False(t, ok, "nothing recorded yet")
+
+	h := timingStats{}
+	h.HandleRPC(ctx, &stats.Begin{Client: true, BeginTime: start})
+	h.HandleRPC(ctx, &stats.OutHeader{Client: true})
+	h.HandleRPC(ctx, &stats.OutPayload{Client: true, WireLength: 25, SentTime: start.Add(time.Millisecond)})
+	h.HandleRPC(ctx, &stats.InHeader{Client: true})
+	h.HandleRPC(ctx, &stats.InPayload{Client: true, WireLength: 130, RecvTime: start.Add(50 * time.Millisecond)})
+	h.HandleRPC(ctx, &stats.InTrailer{Client: true})
+
+	timing, ok := rec.Timing()
+	require.True(t, ok)
+	assert.False(t, timing.Done())
+
+	h.HandleRPC(ctx, &stats.End{Client: true, BeginTime: start, EndTime: start.Add(52 * time.Millisecond)})
+	timing, _ = rec.Timing()
+	assert.Equal(t, CallTiming{
+		Start:            start,
+		FirstHeader:      40 * time.Millisecond,
+		FirstMessage:     50 * time.Millisecond,
+		Total:            52 * time.Millisecond,
+		SentMessages:     1,
+		ReceivedMessages: 1,
+		SentBytes:        25,
+		ReceivedBytes:    130,
+		Messages: []MessageTiming{
+			{At: time.Millisecond, Sent: true, Bytes: 25},
+			{At: 50 * time.Millisecond, Bytes: 130},
+		},
+	}, timing)
+	assert.True(t, timing.Done())
+}
+
+func TestTimingRecorder_Stream(t *testing.T) {
+	ctx, rec := WithCallTiming(context.Background())
+	start := time.Now()
+	h := timingStats{}
+	h.HandleRPC(ctx, &stats.Begin{Client: true, BeginTime: start})
+	for i := range maxTimedMessages + 5 {
+		h.HandleRPC(ctx, &stats.InPayload{Client: true, WireLength: 10, RecvTime: start.Add(time.Duration(i+1) * time.Millisecond)})
+	}
+
+	timing, _ := rec.Timing()
+	assert.Equal(t, maxTimedMessages+5, timing.ReceivedMessages)
+	assert.Equal(t, 10*(maxTimedMessages+5), timing.ReceivedBytes)
+	assert.Len(t, timing.Messages, maxTimedMessages, "per-message timings are capped")
+	assert.Equal(t, time.Millisecond, timing.FirstMessage)
+	assert.Equal(t, 3*time.Millisecond, timing.Messages[2].At)
+	assert.Zero(t, timing.FirstHeader)
+
+	// Server-side events and calls without a recorder are ignored
+	h.HandleRPC(ctx, &stats.InPayload{Client: false, WireLength: 99})
+	h.HandleRPC(context.Background(), &stats.InPayload{Client: true, WireLength: 99})
+	again, _ := rec.Timing()
+	assert.Equal(t, timing.ReceivedBytes, again.ReceivedBytes)
+
+	// Copies are independent of the recorder
+	timing.Messages[0].Bytes = -1
+	again, _ = rec.Timing()
+	assert.Equal(t, 10, again.Messages[0].Bytes)
+}
+
+func TestTimingStats_Call(t *testing.T) {
+	srv := grpctest.StartServer(t,
+		grpctest.WithTestService(),
+		grpctest.WithDialOptions(grpc.WithStatsHandler(timingStats{})),
+	)
+	inv := NewInvoker(srv.Conn, testLogger)
+
+	ctx, rec := WithCallTiming(context.Background())
+	_, _, _, err := inv.InvokeUnary(ctx, testMethod(t, "UnaryEcho"), `{"item":{"id":"z"}}`, nil)
+	require.NoError(t, err)
+
+	timing, ok := rec.Timing()
+	require.True(t, ok)
+	assert.True(t, timing.Done())
+	assert.Equal(t, 1, timing.SentMessages)
+	assert.Equal(t, 1, timing.ReceivedMessages)
+	assert.Positive(t, timing.ReceivedBytes)
+	assert.Positive(t, timing.FirstHeader)
+	assert.LessOrEqual(t, timing.FirstMessage, timing.Total)
+	assert.Len(t, timing.Messages, 2)
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// CertError is a CA bundle that could not be used: unreadable, holding no
+// certificates, or with a PEM block that is not a valid certificate.
+type CertError struct {
+	Path  string
+	Block int // Index of the bad PEM block, from 0; -1 for the whole file
+	Err   error
+}
+
+func (e *CertError) Error() string {
+	if e.Block < 0 {
+		return fmt.Sprintf("CA certificate %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("CA certificate %s: PEM block %d: %v", e.Path, e.Block, e.Err)
+}
+
+func (e *CertError) Unwrap() error { return e.Err }
+
+// loadCertPool reads the PEM certificates in the bundle at path into a
+// pool, returning how many there were. Blocks of other types, such as a
+// key saved alongside, are skipped; a certificate block that does not
+// parse fails the whole bundle, so a bad file is not half trusted.
+func loadCertPool(path string) (*x509.CertPool, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, &CertError{Path: path, Block: -1, Err: err}
+	}
+
+	pool := x509.NewCertPool()
+	count := 0
+	for index := 0; ; index++ {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, 0, &CertError{Path: path, Block: index, Err: err}
+		}
+		pool.AddCert(cert)
+		count++
+	}
+	if count == 0 {
+		return nil, 0, &CertError{Path: path, Block: -1, Err: fmt.Errorf("no PEM certificates found")}
+	}
+	return pool, count, nil
+}
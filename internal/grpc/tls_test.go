@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestLoadCertPool(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTLS())
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+	bad := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not DER")})
+
+	// Other blocks kept with the certificates are skipped
+	_, count, err := loadCertPool(writeFile(t, "bundle.pem", append(append(key, srv.CertPEM...), srv.CertPEM...)))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	path := writeFile(t, "bad.pem", append(append(srv.CertPEM, key...), bad...))
+	_, _, err = loadCertPool(path)
+	var certErr *CertError
+	require.ErrorAs(t, err, &certErr)
+	assert.Equal(t, 2, certErr.Block)
+	assert.Contains(t, err.Error(), path+": PEM block 2: x509:")
+
+	_, _, err = loadCertPool(writeFile(t, "empty.pem", []byte("not PEM at all")))
+	require.ErrorAs(t, err, &certErr)
+	assert.Equal(t, -1, certErr.Block)
+	assert.Contains(t, err.Error(), "no PEM certificates found")
+
+	_, _, err = loadCertPool(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestConnect_SelfSignedTLS(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithTLS())
+	caFile := writeFile(t, "ca.pem", srv.CertPEM)
+	md := testMethod(t, "UnaryEcho")
+
+	call := func(settings domain.TLSSettings) error {
+		m := NewConnectionManager(testLogger)
+		if err := m.Connect(context.Background(), domain.Connection{Address: srv.Addr, TLS: settings}); err != nil {
+			return err
+		}
+		defer func() { _ = m.Disconnect() }()
+		_, _, _, err := NewInvoker(m.Channel(), testLogger).InvokeUnary(context.Background(), md, `{}`, nil)
+		return err
+	}
+
+	// The system roots do not trust a self-signed certificate
+	err := call(domain.TLSSettings{Enabled: true})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "certificate")
+
+	assert.NoError(t, call(domain.TLSSettings{Enabled: true, CertFile: caFile}), "trusted through the CA bundle")
+	assert.NoError(t, call(domain.TLSSettings{Enabled: true, SkipVerify: true}), "not verified at all")
+
+	// The certificate is checked against the override instead of the address
+	assert.NoError(t, call(domain.TLSSettings{Enabled: true, CertFile: caFile, ServerNameOverride: "localhost"}))
+	err = call(domain.TLSSettings{Enabled: true, CertFile: caFile, ServerNameOverride: "api.example.com"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "api.example.com")
+
+	// A bad bundle fails the connect, naming the file
+	err = call(domain.TLSSettings{Enabled: true, CertFile: writeFile(t, "bad.pem", []byte("junk"))})
+	var certErr *CertError
+	assert.ErrorAs(t, err, &certErr)
+}
@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TypeResolver returns the message types google.protobuf.Any values can be
+// packed with or expanded to, and the extensions that can be set: those in
+// the files of the resolved services and of any local descriptor source.
+// Over server reflection, the files declaring extensions of their messages
+// are fetched too, and types outside those files are asked for by name
+// when an Any first names them. Call it after ListServices, off the UI
+// thread.
+func (r *ReflectionClient) TypeResolver() *protoconv.TypeResolver {
+	r.mu.RLock()
+	services := maps.Clone(r.serviceCache)
+	r.mu.RUnlock()
+
+	var files []protoreflect.FileDescriptor
+	seen := make(map[string]bool)
+	known := make(map[extensionKey]bool)
+	var visit func(fd protoreflect.FileDescriptor)
+	visit = func(fd protoreflect.FileDescriptor) {
+		if fd == nil || fd.IsPlaceholder() || seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		files = append(files, fd)
+		rangeExtensions(fd, func(xd protoreflect.ExtensionDescriptor) {
+			known[extensionKey{xd.ContainingMessage().FullName(), xd.Number()}] = true
+		})
+		imports := fd.Imports()
+		for i := range imports.Len() {
+			visit(imports.Get(i).FileDescriptor)
+		}
+	}
+	for _, fd := range r.localFiles {
+		visit(fd)
+	}
+	for _, sd := range services {
+		visit(sd.ParentFile())
+	}
+
+	if r.client == nil {
+		return protoconv.NewTypeResolver(files, nil)
+	}
+	// Files found here may declare extendable messages of their own
+	for i := 0; i < len(files); i++ {
+		for _, fd := range r.extensionFiles(files[i], known) {
+			visit(fd)
+		}
+	}
+	return protoconv.NewTypeResolver(files, r.lookupMessage)
+}
+
+// extensionKey identifies an extension by the message it extends.
+type extensionKey struct {
+	message protoreflect.FullName
+	number  protoreflect.FieldNumber
+}
+
+// extensionFiles asks the server for the extensions of each extendable
+// message in fd and returns the files declaring those not in known. Types
+// the server knows no extensions of, or servers that do not answer
+// extension requests, are skipped.
+func (r *ReflectionClient) extensionFiles(fd protoreflect.FileDescriptor, known map[extensionKey]bool) []protoreflect.FileDescriptor {
+	var found []protoreflect.FileDescriptor
+	rangeMessages(fd.Messages(), func(md protoreflect.MessageDescriptor) {
+		// Options messages are extended by custom options, which are not
+		// needed to call anything
+		if md.ExtensionRanges().Len() == 0 || md.ParentFile().Package() == "google.protobuf" {
+			return
+		}
+		numbers, err := r.client.AllExtensionNumbersForType(md.FullName())
+		if err != nil {
+			r.logger.Debug("could not list extensions",
+				slog.String("message", string(md.FullName())),
+				slog.Any("error", err),
+			)
+			return
+		}
+		for _, n := range numbers {
+			key := extensionKey{md.FullName(), n}
+			if known[key] {
+				continue
+			}
+			xfd, err := r.client.FileContainingExtension(md.FullName(), n)
+			if err != nil {
+				r.logger.Debug("could not resolve extension",
+					slog.String("message", string(md.FullName())),
+					slog.Int("number", int(n)),
+					slog.Any("error", err),
+				)
+				continue
+			}
+			known[key] = true
+			found = append(found, xfd)
+		}
+	})
+	return found
+}
+
+// rangeMessages calls fn for each message in msgs and those nested in them.
+func rangeMessages(msgs protoreflect.MessageDescriptors, fn func(protoreflect.MessageDescriptor)) {
+	for i := range msgs.Len() {
+		fn(msgs.Get(i))
+		rangeMessages(msgs.Get(i).Messages(), fn)
+	}
+}
+
+// rangeExtensions calls fn for each extension fd declares, at the top
+// level or nested in a message.
+func rangeExtensions(fd protoreflect.FileDescriptor, fn func(protoreflect.ExtensionDescriptor)) {
+	for i := range fd.Extensions().Len() {
+		fn(fd.Extensions().Get(i))
+	}
+	rangeMessages(fd.Messages(), func(md protoreflect.MessageDescriptor) {
+		for i := range md.Extensions().Len() {
+			fn(md.Extensions().Get(i))
+		}
+	})
+}
+
+// lookupMessage asks the server for the file declaring a message.
+func (r *ReflectionClient) lookupMessage(name protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	if _, err := r.client.FileContainingSymbol(name); err != nil {
+		return nil, err
+	}
+	d, err := r.client.AsResolver().FindDescriptorByName(name)
+	if err != nil {
+		return nil, err
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message", name)
+	}
+	return md, nil
+}
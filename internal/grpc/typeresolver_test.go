@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestReflectionClient_TypeResolver(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionRegistry(grpctest.ManyServiceFiles(2)...))
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+	_, err := client.ListServices(context.Background())
+	require.NoError(t, err)
+
+	types := client.TypeResolver()
+	assert.Contains(t, types.MessageNames(), protoreflect.FullName("many.v1.Request"))
+
+	mt, err := types.FindMessageByURL("type.googleapis.com/many.v1.Response")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("many.v1.Response"), mt.Descriptor().FullName())
+
+	_, err = types.FindMessageByName("many.v1.Missing")
+	assert.Error(t, err, "unknown names are asked for and not found")
+}
@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/jhump/protoreflect/v2/grpcreflect"
+	"github.com/jhump/protoreflect/v2/protoprint"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// TypeKind tells messages from enums in SearchTypes results.
+type TypeKind string
+
+const (
+	TypeMessage TypeKind = "message"
+	TypeEnum    TypeKind = "enum"
+)
+
+// TypeInfo is a message or enum found by SearchTypes.
+type TypeInfo struct {
+	FullName   string
+	Kind       TypeKind
+	File       string // Path of the file declaring it
+	Descriptor protoreflect.Descriptor
+}
+
+// Schema returns the type's definition as .proto source.
+func (t TypeInfo) Schema() (string, error) {
+	printer := &protoprint.Printer{Compact: true}
+	return printer.PrintProtoToString(t.Descriptor)
+}
+
+// SearchTypes returns the messages and enums whose full name contains
+// query, ignoring case, from the files of the resolved services and their
+// imports, the files lenient resolution built, any local descriptor source
+// and the types built into Grotto. A type URL searches by the name in it.
+// Types whose name starts with query, in full, after the package or on
+// its own, come first; each group is sorted by name.
+//
+// Over server reflection, the files of services listed but not loaded are
+// asked for first, and so is query itself when it is a full type name no
+// known file declares, so types no service uses can be found too. Each
+// name is asked for once per session. Failed requests are returned joined,
+// along with whatever was found. Call it after ListServices, off the UI
+// thread.
+func (r *ReflectionClient) SearchTypes(query string) ([]TypeInfo, error) {
+	query = strings.TrimSpace(query)
+	if i := strings.LastIndexByte(query, '/'); i >= 0 {
+		query = query[i+1:]
+	}
+
+	var errs []error
+	if err := r.fetchForSearch(r.unloadedServices()); err != nil {
+		errs = append(errs, err)
+	}
+	types := r.knownTypes()
+	if name := protoreflect.FullName(query); strings.Contains(query, ".") && name.IsValid() {
+		if _, ok := types[name]; !ok {
+			if err := r.fetchForSearch([]protoreflect.FullName{name}); err != nil {
+				errs = append(errs, err)
+			}
+			types = r.knownTypes()
+		}
+	}
+	return rankTypes(types, query), errors.Join(errs...)
+}
+
+// unloadedServices returns the services ListServices was given whose
+// descriptors were never loaded, such as those that failed to resolve.
+func (r *ReflectionClient) unloadedServices() []protoreflect.FullName {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []protoreflect.FullName
+	for _, name := range r.listed {
+		if _, ok := r.serviceCache[string(name)]; !ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fetchForSearch asks the server for the files declaring names not asked
+// for before, keeping them for knownTypes. Names the server does not know
+// are skipped; other failures are returned joined.
+func (r *ReflectionClient) fetchForSearch(names []protoreflect.FullName) error {
+	if r.client == nil {
+		return nil
+	}
+	var errs []error
+	for _, name := range names {
+		r.mu.Lock()
+		asked := r.searched[name]
+		r.searched[name] = true
+		r.mu.Unlock()
+		if asked {
+			continue
+		}
+
+		fd, err := r.client.FileContainingSymbol(name)
+		if err != nil {
+			r.logger.Debug("type search could not fetch symbol",
+				slog.String("symbol", string(name)),
+				slog.Any("error", err),
+			)
+			if !grpcreflect.IsElementNotFoundError(err) {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			continue
+		}
+		r.mu.Lock()
+		r.searchFiles = append(r.searchFiles, fd)
+		r.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// knownTypes returns every message and enum SearchTypes looks through, by
+// full name. Where two files declare the same name, the one the services
+// use wins over the global registry.
+func (r *ReflectionClient) knownTypes() map[protoreflect.FullName]TypeInfo {
+	r.mu.RLock()
+	roots := slices.Clone(r.localFiles)
+	for _, sd := range r.serviceCache {
+		roots = append(roots, sd.ParentFile())
+	}
+	roots = append(roots, r.searchFiles...)
+	r.mu.RUnlock()
+	if r.lenient != nil {
+		roots = append(roots, r.lenient.builtFiles()...)
+	}
+
+	types := make(map[protoreflect.FullName]TypeInfo)
+	seen := make(map[string]bool)
+	var visit func(fd protoreflect.FileDescriptor)
+	visit = func(fd protoreflect.FileDescriptor) {
+		if fd == nil || fd.IsPlaceholder() || seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		addFileTypes(types, fd)
+		imports := fd.Imports()
+		for i := range imports.Len() {
+			visit(imports.Get(i).FileDescriptor)
+		}
+	}
+	for _, fd := range roots {
+		visit(fd)
+	}
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		visit(fd)
+		return true
+	})
+	return types
+}
+
+// addFileTypes adds the messages and enums fd declares, nested ones
+// included, that types does not hold yet. Map entries are left out.
+func addFileTypes(types map[protoreflect.FullName]TypeInfo, fd protoreflect.FileDescriptor) {
+	add := func(d protoreflect.Descriptor, kind TypeKind) {
+		if _, ok := types[d.FullName()]; !ok {
+			types[d.FullName()] = TypeInfo{FullName: string(d.FullName()), Kind: kind, File: fd.Path(), Descriptor: d}
+		}
+	}
+	addEnums := func(enums protoreflect.EnumDescriptors) {
+		for i := range enums.Len() {
+			add(enums.Get(i), TypeEnum)
+		}
+	}
+	addEnums(fd.Enums())
+	rangeMessages(fd.Messages(), func(md protoreflect.MessageDescriptor) {
+		if md.IsMapEntry() {
+			return
+		}
+		add(md, TypeMessage)
+		addEnums(md.Enums())
+	})
+}
+
+// rankTypes returns the types whose full name contains query, ignoring
+// case: those whose name starts with it, in full, after the package or on
+// its own, first, then the rest, each sorted by name.
+func rankTypes(types map[protoreflect.FullName]TypeInfo, query string) []TypeInfo {
+	query = strings.ToLower(query)
+	rank := func(t TypeInfo) int {
+		name := strings.ToLower(t.FullName)
+		short := strings.TrimPrefix(name, strings.ToLower(string(t.Descriptor.ParentFile().Package()))+".")
+		switch {
+		case strings.HasPrefix(name, query), strings.HasPrefix(short, query),
+			strings.HasPrefix(strings.ToLower(string(t.Descriptor.Name())), query):
+			return 0
+		case strings.Contains(name, query):
+			return 1
+		}
+		return -1
+	}
+
+	var found []TypeInfo
+	for _, t := range types {
+		if rank(t) >= 0 {
+			found = append(found, t)
+		}
+	}
+	slices.SortFunc(found, func(a, b TypeInfo) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), strings.Compare(a.FullName, b.FullName))
+	})
+	return found
+}
+
+// builtFiles returns the files built so far.
+func (l *lenientFiles) builtFiles() []protoreflect.FileDescriptor {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var files []protoreflect.FileDescriptor
+	l.resolver.local.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		files = append(files, fd)
+		return true
+	})
+	return files
+}
@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// widgetFile describes:
+//
+//	message Widget { enum Color { COLOR_UNSPECIFIED = 0; } }
+//	message BlueWidget {}
+//	message Order { message Widget {} map<string, string> labels = 1; }
+//	enum WidgetKind { WIDGET_KIND_UNSPECIFIED = 0; }
+func widgetFile() *descriptorpb.FileDescriptorProto {
+	enum := func(name, zero string) *descriptorpb.EnumDescriptorProto {
+		return &descriptorpb.EnumDescriptorProto{
+			Name:  proto.String(name),
+			Value: []*descriptorpb.EnumValueDescriptorProto{{Name: proto.String(zero), Number: proto.Int32(0)}},
+		}
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("searchtest/widget.proto"),
+		Package: proto.String("searchtest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Widget"), EnumType: []*descriptorpb.EnumDescriptorProto{enum("Color", "COLOR_UNSPECIFIED")}},
+			{Name: proto.String("BlueWidget")},
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name: proto.String("labels"), JsonName: proto.String("labels"), Number: proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					TypeName: proto.String(".searchtest.Order.LabelsEntry"),
+				}},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("Widget")},
+					{
+						Name:    proto.String("LabelsEntry"),
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{Name: proto.String("key"), JsonName: proto.String("key"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+							{Name: proto.String("value"), JsonName: proto.String("value"), Number: proto.Int32(2), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+						},
+					},
+				},
+			},
+		},
+		EnumType: []*descriptorpb.EnumDescriptorProto{enum("WidgetKind", "WIDGET_KIND_UNSPECIFIED")},
+	}
+}
+
+func typeNames(types []TypeInfo) []string {
+	var names []string
+	for _, t := range types {
+		names = append(names, t.FullName)
+	}
+	return names
+}
+
+func TestSearchTypes_Ranking(t *testing.T) {
+	client, err := NewReflectionClientFromDescriptors(nil, []*descriptorpb.FileDescriptorProto{widgetFile()}, testLogger)
+	require.NoError(t, err)
+
+	types, err := client.SearchTypes("widget")
+	require.NoError(t, err)
+	// Names starting with the query come first, then those containing it
+	assert.Equal(t, []string{
+		"searchtest.Order.Widget",
+		"searchtest.Widget",
+		"searchtest.Widget.Color",
+		"searchtest.WidgetKind",
+		"searchtest.BlueWidget",
+	}, typeNames(types))
+	assert.Equal(t, TypeEnum, types[3].Kind)
+	assert.Equal(t, "searchtest/widget.proto", types[3].File)
+
+	types, err = client.SearchTypes("labels")
+	require.NoError(t, err)
+	assert.Empty(t, types, "map entries are left out")
+
+	types, err = client.SearchTypes("type.googleapis.com/searchtest.BlueWidget")
+	require.NoError(t, err)
+	require.NotEmpty(t, types)
+	assert.Equal(t, TypeMessage, types[0].Kind)
+	schema, err := types[0].Schema()
+	require.NoError(t, err)
+	assert.Contains(t, schema, "message BlueWidget")
+
+	// Types built into Grotto are found too
+	types, err = client.SearchTypes("google.protobuf.Timestamp")
+	require.NoError(t, err)
+	require.NotEmpty(t, types)
+	assert.Equal(t, "google.protobuf.Timestamp", types[0].FullName)
+	assert.Equal(t, "google/protobuf/timestamp.proto", types[0].File)
+}
+
+// loneFile declares a message no service uses.
+func loneFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("lone/v1/audit.proto"),
+		Package:     proto.String("lone.v1"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("AuditEvent")}},
+	}
+}
+
+func TestSearchTypes_FetchesUnloaded(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionRegistry(append(grpctest.ManyServiceFiles(1), loneFile())...))
+	client := NewReflectionClient(srv.Conn, testLogger)
+	defer client.Close()
+
+	// As if the listing stopped before loading the service
+	client.listed = []protoreflect.FullName{"many.v1.Service0"}
+	types, err := client.SearchTypes("many.v1.re")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"many.v1.Request", "many.v1.Response"}, typeNames(types))
+
+	// A type no service uses is asked for by its full name only
+	types, err = client.SearchTypes("AuditEvent")
+	require.NoError(t, err)
+	assert.Empty(t, types)
+
+	types, err = client.SearchTypes("lone.v1.AuditEvent")
+	require.NoError(t, err)
+	require.Len(t, types, 1)
+	assert.Equal(t, "lone/v1/audit.proto", types[0].File)
+
+	types, err = client.SearchTypes("AuditEvent")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lone.v1.AuditEvent"}, typeNames(types), "fetched files are kept")
+
+	types, err = client.SearchTypes("lone.v1.Missing")
+	assert.NoError(t, err, "names the server does not know are not errors")
+	assert.Empty(t, types)
+}
@@ -0,0 +1,365 @@
+// Package jsonpath picks fragments out of a JSON document with simple path
+// expressions: member names joined by dots, array indices and wildcards in
+// brackets, as in items[*].id, metadata.labels.env or $["odd.key"][0].
+// It is a small subset of JSONPath, without filters, slices or recursive
+// descent.
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// stepKind is what a path step selects.
+type stepKind int
+
+const (
+	stepMember   stepKind = iota // an object member by name
+	stepIndex                    // an array element by index
+	stepWildcard                 // every member or element
+)
+
+// step is one step of a path.
+type step struct {
+	kind  stepKind
+	name  string // stepMember
+	index int    // stepIndex; negative counts from the end
+}
+
+// Path is a parsed path expression. The zero Path selects the whole
+// document.
+type Path struct {
+	steps []step
+}
+
+// Parse parses a path expression. A leading "$" is optional, and so is the
+// dot before the first member name.
+//
+//	path  = ["$"] [name | "*"] { "." name | ".*" | "[" index "]" | "[*]" | "[" quoted "]" }
+//
+// Names run up to the next ".", "[" or "]"; members whose names contain
+// those are written quoted in brackets. Negative indices count from the end.
+func Parse(expr string) (Path, error) {
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return Path{}, fmt.Errorf("empty path")
+	}
+	rooted := strings.HasPrefix(s, "$")
+	s = strings.TrimPrefix(s, "$")
+
+	var p Path
+	i := 0
+	first := !rooted
+	for i < len(s) {
+		switch {
+		case s[i] == '.':
+			i++
+			name, n := scanName(s[i:])
+			if n == 0 {
+				return Path{}, fmt.Errorf("missing name after \".\" at %d", i)
+			}
+			p.steps = append(p.steps, nameStep(name))
+			i += n
+		case s[i] == '[':
+			st, n, err := scanBracket(s[i:])
+			if err != nil {
+				return Path{}, fmt.Errorf("%w at %d", err, i)
+			}
+			p.steps = append(p.steps, st)
+			i += n
+		case first:
+			name, n := scanName(s)
+			if n == 0 {
+				return Path{}, fmt.Errorf("unexpected %q at 0", s[0])
+			}
+			p.steps = append(p.steps, nameStep(name))
+			i += n
+		default:
+			return Path{}, fmt.Errorf("unexpected %q at %d", s[i], i)
+		}
+		first = false
+	}
+	return p, nil
+}
+
+// nameStep returns the step for a bare name: a wildcard for "*".
+func nameStep(name string) step {
+	if name == "*" {
+		return step{kind: stepWildcard}
+	}
+	return step{kind: stepMember, name: name}
+}
+
+// scanName returns the name at the start of s and its length.
+func scanName(s string) (string, int) {
+	n := strings.IndexAny(s, ".[]")
+	if n < 0 {
+		n = len(s)
+	}
+	return strings.TrimSpace(s[:n]), n
+}
+
+// scanBracket parses the bracketed step at the start of s and returns it
+// with its length.
+func scanBracket(s string) (step, int, error) {
+	end := strings.IndexByte(s, ']')
+	if q := strings.TrimSpace(s[1:]); strings.HasPrefix(q, `"`) || strings.HasPrefix(q, "'") {
+		return scanQuoted(s)
+	}
+	if end < 0 {
+		return step{}, 0, fmt.Errorf("unterminated \"[\"")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	if inner == "*" {
+		return step{kind: stepWildcard}, end + 1, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return step{}, 0, fmt.Errorf("invalid index %q", inner)
+	}
+	return step{kind: stepIndex, index: index}, end + 1, nil
+}
+
+// scanQuoted parses a quoted member name in brackets, ["name"] or
+// ['name'], at the start of s. Double-quoted names are JSON strings.
+func scanQuoted(s string) (step, int, error) {
+	i := 1
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	quote := s[i]
+	j := i + 1
+	for j < len(s) && s[j] != quote {
+		if s[j] == '\\' && quote == '"' {
+			j++
+		}
+		j++
+	}
+	if j >= len(s) {
+		return step{}, 0, fmt.Errorf("unterminated quoted name")
+	}
+	name := s[i+1 : j]
+	if quote == '"' {
+		if err := json.Unmarshal([]byte(s[i:j+1]), &name); err != nil {
+			return step{}, 0, fmt.Errorf("invalid quoted name %s", s[i:j+1])
+		}
+	}
+	k := j + 1
+	for k < len(s) && s[k] == ' ' {
+		k++
+	}
+	if k >= len(s) || s[k] != ']' {
+		return step{}, 0, fmt.Errorf("unterminated \"[\"")
+	}
+	return step{kind: stepMember, name: name}, k + 1, nil
+}
+
+// Definite reports whether the path selects at most one value, having no
+// wildcards.
+func (p Path) Definite() bool {
+	for _, st := range p.steps {
+		if st.kind == stepWildcard {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the path in canonical form, e.g. $.items[*].id.
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, st := range p.steps {
+		switch st.kind {
+		case stepMember:
+			b.WriteString(MemberPath(st.name))
+		case stepIndex:
+			b.WriteString("[" + strconv.Itoa(st.index) + "]")
+		case stepWildcard:
+			b.WriteString("[*]")
+		}
+	}
+	return b.String()
+}
+
+// Match is a value a path selected, with the definite path to it.
+type Match struct {
+	Path  string          // e.g. $.items[3].id
+	Value json.RawMessage // as written in the document
+}
+
+// Select returns the values p selects from the JSON document data, in
+// document order. Steps that do not apply, such as a member of an array or
+// an index past the end, select nothing. It fails only when data is not
+// valid JSON.
+func (p Path) Select(data []byte) ([]Match, error) {
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("not valid JSON")
+	}
+	var matches []Match
+	var walk func(value json.RawMessage, path string, steps []step) error
+	walk = func(value json.RawMessage, path string, steps []step) error {
+		if len(steps) == 0 {
+			matches = append(matches, Match{Path: path, Value: value})
+			return nil
+		}
+		st, rest := steps[0], steps[1:]
+		switch firstByte(value) {
+		case '{':
+			members, err := objectMembers(value)
+			if err != nil {
+				return err
+			}
+			for _, m := range members {
+				if st.kind == stepWildcard || (st.kind == stepMember && st.name == m.name) {
+					if err := walk(m.value, path+MemberPath(m.name), rest); err != nil {
+						return err
+					}
+				}
+			}
+		case '[':
+			var elems []json.RawMessage
+			if err := json.Unmarshal(value, &elems); err != nil {
+				return err
+			}
+			switch st.kind {
+			case stepWildcard:
+				for i, e := range elems {
+					if err := walk(e, path+"["+strconv.Itoa(i)+"]", rest); err != nil {
+						return err
+					}
+				}
+			case stepIndex:
+				i := st.index
+				if i < 0 {
+					i += len(elems)
+				}
+				if i >= 0 && i < len(elems) {
+					return walk(elems[i], path+"["+strconv.Itoa(i)+"]", rest)
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(bytes.TrimSpace(data), "$", p.steps); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// Format renders matches as indented JSON: a definite path's single match
+// as the value alone, otherwise an object from each match's path to its
+// value. No matches render as "".
+func (p Path) Format(matches []Match) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	if p.Definite() && len(matches) == 1 {
+		return indent(matches[0].Value, "")
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, m := range matches {
+		b.WriteString("  " + quoteJSON(m.Path) + ": " + indent(m.Value, "  "))
+		if i < len(matches)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Filter selects p from the JSON document text and formats the matches,
+// returning how many there were.
+func (p Path) Filter(text string) (string, int, error) {
+	matches, err := p.Select([]byte(text))
+	if err != nil {
+		return "", 0, err
+	}
+	return p.Format(matches), len(matches), nil
+}
+
+// member is an object member, in document order.
+type member struct {
+	name  string
+	value json.RawMessage
+}
+
+// objectMembers returns the members of the JSON object data in the order
+// they are written.
+func objectMembers(data json.RawMessage) ([]member, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	var members []member
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, member{name: name, value: value})
+	}
+	return members, nil
+}
+
+// firstByte returns the first non-space byte of data, or 0.
+func firstByte(data []byte) byte {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return 0
+	}
+	return data[0]
+}
+
+// indent indents a JSON value two spaces per level, with every line after
+// the first prefixed by prefix.
+func indent(value json.RawMessage, prefix string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, value, prefix, "  "); err != nil {
+		return string(value)
+	}
+	return buf.String()
+}
+
+// MemberPath returns the path step for object member name: .name for plain
+// identifiers, ["name"] otherwise.
+func MemberPath(name string) string {
+	if isIdentifier(name) {
+		return "." + name
+	}
+	return "[" + quoteJSON(name) + "]"
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteJSON returns s as a JSON string literal, leaving <, > and & as-is.
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
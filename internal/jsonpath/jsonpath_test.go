@@ -0,0 +1,127 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const doc = `{
+  "items": [
+    {"id": "a", "count": 1, "tags": ["x", "y"]},
+    {"id": "b", "count": 2},
+    {"count": 3}
+  ],
+  "metadata": {"labels": {"env": "prod", "team": "core"}, "name.with.dots": true},
+  "@type": "type.googleapis.com/pkg.List",
+  "big": 9007199254740993
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // canonical form
+	}{
+		{"items", "$.items"},
+		{"$.items", "$.items"},
+		{"$", "$"},
+		{"items[*].id", "$.items[*].id"},
+		{"items[0]", "$.items[0]"},
+		{"items[-1]", "$.items[-1]"},
+		{"items.*", "$.items[*]"},
+		{"*", "$[*]"},
+		{"metadata.labels.env", "$.metadata.labels.env"},
+		{`metadata["name.with.dots"]`, `$.metadata["name.with.dots"]`},
+		{`metadata['name.with.dots']`, `$.metadata["name.with.dots"]`},
+		{"@type", `$["@type"]`},
+		{"[2]", "$[2]"},
+		{" items [ 1 ] ", "$.items[1]"},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, p.String(), tt.expr)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for expr, want := range map[string]string{
+		"":          "empty path",
+		"items.":    `missing name after "." at 6`,
+		"items[":    `unterminated "["`,
+		"items[x]":  `invalid index "x"`,
+		`items["a`:  "unterminated quoted name",
+		`items["a"`: `unterminated "["`,
+		"items]":    `unexpected ']' at 5`,
+		"$items":    `unexpected 'i' at 0`,
+	} {
+		_, err := Parse(expr)
+		assert.ErrorContains(t, err, want, expr)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		expr      string
+		wantPaths []string
+		wantVals  []string
+	}{
+		{"items[*].id", []string{"$.items[0].id", "$.items[1].id"}, []string{`"a"`, `"b"`}},
+		{"items[1].count", []string{"$.items[1].count"}, []string{`2`}},
+		{"items[-1].count", []string{"$.items[2].count"}, []string{`3`}},
+		{"items[5]", nil, nil},
+		{"items.id", nil, nil},
+		{"metadata.labels.*", []string{"$.metadata.labels.env", "$.metadata.labels.team"}, []string{`"prod"`, `"core"`}},
+		{`metadata["name.with.dots"]`, []string{`$.metadata["name.with.dots"]`}, []string{`true`}},
+		{"@type", []string{`$["@type"]`}, []string{`"type.googleapis.com/pkg.List"`}},
+		{"items[*].tags[*]", []string{"$.items[0].tags[0]", "$.items[0].tags[1]"}, []string{`"x"`, `"y"`}},
+		{"big", []string{"$.big"}, []string{"9007199254740993"}},
+		{"missing.deeper", nil, nil},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.expr)
+		require.NoError(t, err, tt.expr)
+		matches, err := p.Select([]byte(doc))
+		require.NoError(t, err, tt.expr)
+		var paths, vals []string
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+			vals = append(vals, string(m.Value))
+		}
+		assert.Equal(t, tt.wantPaths, paths, tt.expr)
+		assert.Equal(t, tt.wantVals, vals, tt.expr)
+	}
+
+	_, err := Path{}.Select([]byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	p, err := Parse("metadata.labels")
+	require.NoError(t, err)
+	out, n, err := p.Filter(doc)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "{\n  \"env\": \"prod\",\n  \"team\": \"core\"\n}", out, "a definite path shows the value alone, keys in document order")
+
+	p, err = Parse("items[*].id")
+	require.NoError(t, err)
+	out, n, err = p.Filter(doc)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "{\n  \"$.items[0].id\": \"a\",\n  \"$.items[1].id\": \"b\"\n}", out)
+
+	p, err = Parse("items[*].tags")
+	require.NoError(t, err)
+	out, _, err = p.Filter(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"$.items[0].tags\": [\n    \"x\",\n    \"y\"\n  ]\n}", out, "nested values are indented under their path")
+
+	p, err = Parse("nothing")
+	require.NoError(t, err)
+	out, n, err = p.Filter(doc)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Empty(t, out)
+}
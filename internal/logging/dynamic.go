@@ -0,0 +1,192 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Settings are the logging options a user can change while the app runs.
+type Settings struct {
+	Level    slog.Level
+	FilePath string // Extra JSON log file written alongside the default one; empty for none
+}
+
+// ParseLevel reads a level name ("debug", "info", "warn", "error"),
+// ignoring case. Unknown names are slog.LevelInfo.
+func ParseLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// LevelName is the lower-case name of level, as read by ParseLevel.
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// dynamicState is shared by a DynamicHandler and the handlers derived from
+// it with WithAttrs and WithGroup, so a change applies to every logger.
+type dynamicState struct {
+	level slog.LevelVar
+
+	mu   sync.RWMutex
+	path string
+	file *os.File
+	sink slog.Handler // Writes to file; nil when there is none
+}
+
+// handlerOp is a WithAttrs or WithGroup call, replayed on the extra file
+// handler since it may be replaced after a logger was derived.
+type handlerOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+// DynamicHandler is a slog.Handler whose level and extra log file can be
+// changed at runtime. Records at or above the level go to the base handler
+// and, when one is set, to the extra file. It is safe for concurrent use.
+type DynamicHandler struct {
+	state *dynamicState
+	base  slog.Handler
+	ops   []handlerOp
+}
+
+// NewDynamicHandler wraps base, which should accept every level the user can
+// choose, gating it at level.
+func NewDynamicHandler(base slog.Handler, level slog.Level) *DynamicHandler {
+	h := &DynamicHandler{state: &dynamicState{}, base: base}
+	h.state.level.Set(level)
+	return h
+}
+
+// Level returns the current level.
+func (h *DynamicHandler) Level() slog.Level {
+	return h.state.level.Level()
+}
+
+// SetLevel changes the level of every logger sharing this handler.
+func (h *DynamicHandler) SetLevel(level slog.Level) {
+	h.state.level.Set(level)
+}
+
+// FilePath returns the extra log file's path, or "" if there is none.
+func (h *DynamicHandler) FilePath() string {
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+	return h.state.path
+}
+
+// SetFile starts writing JSON records to path as well, appending to it and
+// creating its directory if needed. The previous extra file, if any, is
+// closed. An empty path stops writing to a file. On error the previous file
+// is kept.
+func (h *DynamicHandler) SetFile(path string) error {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if path == s.path {
+		return nil
+	}
+
+	var (
+		file *os.File
+		sink slog.Handler
+	)
+	if path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file %s: %w", path, err)
+		}
+		file = f
+		// The dynamic level does the gating
+		sink = slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.path, s.file, s.sink = path, file, sink
+	return nil
+}
+
+// Apply sets both the level and the extra log file.
+func (h *DynamicHandler) Apply(settings Settings) error {
+	h.SetLevel(settings.Level)
+	return h.SetFile(settings.FilePath)
+}
+
+// Settings returns the current level and extra log file.
+func (h *DynamicHandler) Settings() Settings {
+	return Settings{Level: h.Level(), FilePath: h.FilePath()}
+}
+
+// Close closes the extra log file, if any.
+func (h *DynamicHandler) Close() error {
+	return h.SetFile("")
+}
+
+// Enabled implements slog.Handler.
+func (h *DynamicHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.state.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *DynamicHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if h.base.Enabled(ctx, r.Level) {
+		err = h.base.Handle(ctx, r.Clone())
+	}
+
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+	if h.state.sink == nil {
+		return err
+	}
+	sink := h.state.sink
+	for _, op := range h.ops {
+		if op.group != "" {
+			sink = sink.WithGroup(op.group)
+		} else {
+			sink = sink.WithAttrs(op.attrs)
+		}
+	}
+	if fileErr := sink.Handle(ctx, r); err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *DynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &DynamicHandler{
+		state: h.state,
+		base:  h.base.WithAttrs(attrs),
+		ops:   append(h.ops[:len(h.ops):len(h.ops)], handlerOp{attrs: attrs}),
+	}
+}
+
+// WithGroup implements slog.Handler.
+func (h *DynamicHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &DynamicHandler{
+		state: h.state,
+		base:  h.base.WithGroup(name),
+		ops:   append(h.ops[:len(h.ops):len(h.ops)], handlerOp{group: name}),
+	}
+}
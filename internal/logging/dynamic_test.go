@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDynamicHandler_SetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	h := NewDynamicHandler(base, slog.LevelInfo)
+	// A logger derived before the change must follow it too
+	logger := slog.New(h).With(slog.String("component", "reflection"))
+
+	logger.Debug("resolving file")
+	if buf.Len() != 0 {
+		t.Fatalf("debug record written at info level: %q", buf.String())
+	}
+
+	h.SetLevel(slog.LevelDebug)
+	logger.Debug("resolving file")
+	if !strings.Contains(buf.String(), "msg=\"resolving file\" component=reflection") {
+		t.Errorf("debug record missing after switching to debug: %q", buf.String())
+	}
+
+	buf.Reset()
+	h.SetLevel(slog.LevelError)
+	logger.Warn("lenient resolution")
+	if buf.Len() != 0 {
+		t.Errorf("warn record written at error level: %q", buf.String())
+	}
+	if h.Level() != slog.LevelError {
+		t.Errorf("Level() = %v, want ERROR", h.Level())
+	}
+}
+
+// readJSONLines decodes a file of JSON records.
+func readJSONLines(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad JSON line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestDynamicHandler_SetFile(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	h := NewDynamicHandler(base, slog.LevelInfo)
+	defer h.Close()
+	logger := slog.New(h).WithGroup("svc").With(slog.String("name", "pkg.Broken"))
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "nested", "first.log")
+	if err := h.SetFile(first); err != nil {
+		t.Fatalf("SetFile: %v", err)
+	}
+	logger.Info("written to both")
+	logger.Debug("below the level")
+
+	second := filepath.Join(dir, "second.log")
+	if err := h.Apply(Settings{Level: slog.LevelDebug, FilePath: second}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	logger.Debug("only in second")
+
+	if err := h.SetFile(filepath.Join(dir, "missing", "\x00bad")); err == nil {
+		t.Error("SetFile with an invalid path succeeded")
+	}
+	if h.FilePath() != second {
+		t.Errorf("FilePath() = %q after a failed SetFile, want the previous %q", h.FilePath(), second)
+	}
+
+	if err := h.SetFile(""); err != nil {
+		t.Fatalf("SetFile(\"\"): %v", err)
+	}
+	logger.Info("file closed")
+
+	firstRecords := readJSONLines(t, first)
+	if len(firstRecords) != 1 || firstRecords[0]["msg"] != "written to both" {
+		t.Fatalf("first file = %v, want the one info record", firstRecords)
+	}
+	svc, _ := firstRecords[0]["svc"].(map[string]any)
+	if svc["name"] != "pkg.Broken" {
+		t.Errorf("first file record lost its group and attrs: %v", firstRecords[0])
+	}
+
+	secondRecords := readJSONLines(t, second)
+	if len(secondRecords) != 1 || secondRecords[0]["msg"] != "only in second" {
+		t.Errorf("second file = %v, want the one debug record", secondRecords)
+	}
+
+	// The base handler gets every record at or above the level in effect
+	for _, msg := range []string{"written to both", "only in second", "file closed"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Errorf("base handler missing %q: %q", msg, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "below the level") {
+		t.Errorf("base handler got a record below the level: %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		" warn ":  slog.LevelWarn,
+		"error":   slog.LevelError,
+		"":        slog.LevelInfo,
+		"verbose": slog.LevelInfo,
+	}
+	for name, want := range tests {
+		if got := ParseLevel(name); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", name, got, want)
+		}
+	}
+	for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		if got := ParseLevel(LevelName(level)); got != level {
+			t.Errorf("ParseLevel(LevelName(%v)) = %v", level, got)
+		}
+	}
+}
@@ -24,6 +24,21 @@ const (
 // When debug is true, the logger uses DEBUG level and includes source locations.
 // Otherwise, it uses INFO level without source information.
 func InitLogger(appName string, debug bool) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	handler, err := OpenLogFile(appName, level, debug)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(handler), nil
+}
+
+// OpenLogFile opens the platform-specific log file used by InitLogger,
+// rotating it if needed, and returns a JSON handler writing records at or
+// above level to it. addSource includes source locations.
+func OpenLogFile(appName string, level slog.Leveler, addSource bool) (slog.Handler, error) {
 	logPath, err := getLogFilePath(appName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get log file path: %w", err)
@@ -46,18 +61,10 @@ func InitLogger(appName string, debug bool) (*slog.Logger, error) {
 		return nil, fmt.Errorf("failed to open log file %s: %w", logPath, err)
 	}
 
-	// Configure log level and options
-	level := slog.LevelInfo
-	if debug {
-		level = slog.LevelDebug
-	}
-
-	handler := slog.NewJSONHandler(logFile, &slog.HandlerOptions{
+	return slog.NewJSONHandler(logFile, &slog.HandlerOptions{
 		Level:     level,
-		AddSource: debug,
-	})
-
-	return slog.New(handler), nil
+		AddSource: addSource,
+	}), nil
 }
 
 // rotateIfNeeded checks the log file size and rotates if it exceeds maxLogSize.
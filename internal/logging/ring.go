@@ -0,0 +1,234 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRingSize is how many records the in-app log keeps.
+const DefaultRingSize = 5000
+
+// Entry is a log record as kept by a RingHandler, with its attributes
+// already formatted.
+type Entry struct {
+	Seq     uint64 // Position among all records handled, from 1
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   string // key=value pairs separated by spaces; groups as dotted keys
+}
+
+// String formats the entry as a single line, e.g.
+// "15:04:05.000 INFO  connected address=localhost:50051".
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Time.Format("15:04:05.000"))
+	b.WriteByte(' ')
+	level := e.Level.String()
+	b.WriteString(level)
+	b.WriteString(strings.Repeat(" ", max(1, 6-len(level))))
+	b.WriteString(e.Message)
+	if e.Attrs != "" {
+		b.WriteByte(' ')
+		b.WriteString(e.Attrs)
+	}
+	return b.String()
+}
+
+// ring is the bounded store shared by a RingHandler and the handlers
+// derived from it with WithAttrs and WithGroup.
+type ring struct {
+	mu      sync.Mutex
+	entries []Entry // Circular once full; next is the oldest
+	next    int
+	size    int
+	seq     uint64 // Records ever added
+}
+
+func (r *ring) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	e.Seq = r.seq
+	if len(r.entries) < r.size {
+		r.entries = append(r.entries, e)
+	} else {
+		r.entries[r.next] = e
+		r.next = (r.next + 1) % r.size
+	}
+}
+
+// RingHandler is a slog.Handler that keeps the most recent records in
+// memory for the in-app log viewer. It is safe for concurrent use.
+type RingHandler struct {
+	buf   *ring
+	level slog.Leveler
+	attrs string // Formatted attributes added with WithAttrs
+	group string // Dotted prefix from WithGroup, e.g. "request."
+}
+
+// NewRingHandler creates a handler keeping the last size records at or
+// above level.
+func NewRingHandler(size int, level slog.Leveler) *RingHandler {
+	if size < 1 {
+		size = 1
+	}
+	return &RingHandler{buf: &ring{size: size}, level: level}
+}
+
+// Enabled implements slog.Handler.
+func (h *RingHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *RingHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&b, h.group, a)
+		return true
+	})
+	h.buf.add(Entry{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   strings.TrimPrefix(b.String(), " "),
+	})
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *RingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		appendAttr(&b, h.group, a)
+	}
+	h2 := *h
+	h2.attrs = b.String()
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *RingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// appendAttr writes " key=value" for a, flattening groups into dotted keys.
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(b, prefix, ga)
+		}
+		return
+	}
+	b.WriteByte(' ')
+	b.WriteString(prefix + a.Key)
+	b.WriteByte('=')
+	value := a.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(value)
+}
+
+// Entries returns the kept records, oldest first.
+func (h *RingHandler) Entries() []Entry {
+	h.buf.mu.Lock()
+	defer h.buf.mu.Unlock()
+	out := make([]Entry, 0, len(h.buf.entries))
+	out = append(out, h.buf.entries[h.buf.next:]...)
+	return append(out, h.buf.entries[:h.buf.next]...)
+}
+
+// Seq returns how many records have been added, so a viewer can tell
+// whether anything changed since it last looked.
+func (h *RingHandler) Seq() uint64 {
+	h.buf.mu.Lock()
+	defer h.buf.mu.Unlock()
+	return h.buf.seq
+}
+
+// FilterEntries returns the entries at or above level whose message or
+// attributes contain text, ignoring case.
+func FilterEntries(entries []Entry, level slog.Level, text string) []Entry {
+	text = strings.ToLower(text)
+	var out []Entry
+	for _, e := range entries {
+		if e.Level < level {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(e.Message+" "+e.Attrs), text) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// teeHandler sends each record to every handler that accepts its level.
+type teeHandler []slog.Handler
+
+// Tee returns a handler writing records to all of handlers.
+func Tee(handlers ...slog.Handler) slog.Handler {
+	return teeHandler(handlers)
+}
+
+// Enabled implements slog.Handler.
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler.
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range t {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// WithAttrs implements slog.Handler.
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+// WithGroup implements slog.Handler.
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRingHandler_Bounded(t *testing.T) {
+	h := NewRingHandler(3, slog.LevelDebug)
+	logger := slog.New(h)
+	for i := range 5 {
+		logger.Info(fmt.Sprintf("message %d", i))
+	}
+
+	entries := h.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("kept %d entries, want 3", len(entries))
+	}
+	for i, e := range entries {
+		if want := fmt.Sprintf("message %d", i+2); e.Message != want {
+			t.Errorf("entries[%d] = %q, want %q (oldest first)", i, e.Message, want)
+		}
+		if want := uint64(i + 3); e.Seq != want {
+			t.Errorf("entries[%d].Seq = %d, want %d", i, e.Seq, want)
+		}
+	}
+	if h.Seq() != 5 {
+		t.Errorf("Seq() = %d, want 5", h.Seq())
+	}
+}
+
+func TestRingHandler_ConcurrentWrites(t *testing.T) {
+	h := NewRingHandler(100, slog.LevelDebug)
+	logger := slog.New(h)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				logger.Info("write", slog.Int("goroutine", g), slog.Int("i", i))
+				_ = h.Entries()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(h.Entries()); got != 100 {
+		t.Errorf("kept %d entries, want 100", got)
+	}
+	if h.Seq() != 400 {
+		t.Errorf("Seq() = %d, want 400", h.Seq())
+	}
+}
+
+func TestRingHandler_LevelAndAttrs(t *testing.T) {
+	h := NewRingHandler(10, slog.LevelInfo)
+	logger := slog.New(h).With(slog.String("component", "reflection")).WithGroup("svc")
+
+	logger.Debug("skipped")
+	logger.Warn("lenient resolution",
+		slog.String("name", "pkg.Broken"),
+		slog.Group("fix", slog.Int("imports", 2)),
+		slog.String("reason", "missing import"),
+	)
+
+	entries := h.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("kept %d entries, want 1 (debug is below the level)", len(entries))
+	}
+	want := `component=reflection svc.name=pkg.Broken svc.fix.imports=2 svc.reason="missing import"`
+	if entries[0].Attrs != want {
+		t.Errorf("Attrs = %q, want %q", entries[0].Attrs, want)
+	}
+
+	entries[0].Time = time.Date(2024, 6, 15, 8, 30, 5, 123e6, time.UTC)
+	if got, want := entries[0].String(), "08:30:05.123 WARN  lenient resolution "+want; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFilterEntries(t *testing.T) {
+	entries := []Entry{
+		{Level: slog.LevelDebug, Message: "dialing", Attrs: "address=localhost:50051"},
+		{Level: slog.LevelInfo, Message: "connected", Attrs: "address=localhost:50051"},
+		{Level: slog.LevelWarn, Message: "service resolution failed", Attrs: "service=pkg.Broken"},
+		{Level: slog.LevelError, Message: "RPC invocation failed"},
+	}
+
+	messages := func(es []Entry) string {
+		var out []string
+		for _, e := range es {
+			out = append(out, e.Message)
+		}
+		return strings.Join(out, ", ")
+	}
+
+	tests := []struct {
+		level slog.Level
+		text  string
+		want  string
+	}{
+		{slog.LevelDebug, "", "dialing, connected, service resolution failed, RPC invocation failed"},
+		{slog.LevelWarn, "", "service resolution failed, RPC invocation failed"},
+		{slog.LevelDebug, "LOCALHOST", "dialing, connected"},
+		{slog.LevelInfo, "localhost", "connected"},
+		{slog.LevelDebug, "pkg.broken", "service resolution failed"},
+		{slog.LevelError, "connected", ""},
+	}
+	for _, tt := range tests {
+		if got := messages(FilterEntries(entries, tt.level, tt.text)); got != tt.want {
+			t.Errorf("FilterEntries(%v, %q) = %q, want %q", tt.level, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTee(t *testing.T) {
+	var buf bytes.Buffer
+	text := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})
+	ring := NewRingHandler(10, slog.LevelDebug)
+	logger := slog.New(Tee(text, ring)).With(slog.String("k", "v"))
+
+	logger.Debug("debug only in ring")
+	logger.Warn("both")
+
+	if got := len(ring.Entries()); got != 2 {
+		t.Errorf("ring kept %d entries, want 2", got)
+	}
+	if strings.Contains(buf.String(), "debug only") || !strings.Contains(buf.String(), "msg=both k=v") {
+		t.Errorf("text handler got %q", buf.String())
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import "strings"
+
+// BodyCarry chooses what happens to a request body written for one message
+// type when a method taking another is selected.
+type BodyCarry string
+
+const (
+	// BodyCarryClear replaces the body with the new method's template (the default)
+	BodyCarryClear BodyCarry = "clear"
+	// BodyCarryKeep keeps the body as written
+	BodyCarryKeep BodyCarry = "keep"
+	// BodyCarryConvert keeps the fields that fit the new message type
+	BodyCarryConvert BodyCarry = "convert"
+)
+
+// ParseBodyCarry returns the BodyCarry named s, or BodyCarryClear when s
+// names none.
+func ParseBodyCarry(s string) BodyCarry {
+	switch c := BodyCarry(s); c {
+	case BodyCarryKeep, BodyCarryConvert:
+		return c
+	default:
+		return BodyCarryClear
+	}
+}
+
+// BodyAction is what to do with the request body when a method is selected.
+type BodyAction int
+
+const (
+	// BodyUnchanged leaves the body, which is empty or already fits
+	BodyUnchanged BodyAction = iota
+	// BodyRestore replaces the body with the one last used for the method
+	BodyRestore
+	// BodyClear empties the body, so the method's template fills it
+	BodyClear
+	// BodyKeep leaves a body written for another message type
+	BodyKeep
+	// BodyConvert carries the body's fields over to the new message type
+	BodyConvert
+)
+
+// BodySwitch describes the request body as another method is selected.
+type BodySwitch struct {
+	Body      string // Current body
+	BodyType  string // Full name of the message Body was written for ("" if unknown)
+	InputType string // Full name of the selected method's input message
+	Template  bool   // Body is a template nobody has edited
+	Cached    bool   // A body was kept for the method when it was last left
+}
+
+// Decide returns what carry makes of the switch. A body kept for the method
+// always wins, and bodies with nothing of the user's in them are cleared
+// whatever carry says. A body of unknown type cannot be converted, so it is
+// kept instead.
+func (s BodySwitch) Decide(carry BodyCarry) BodyAction {
+	switch {
+	case s.Cached:
+		return BodyRestore
+	case strings.TrimSpace(s.Body) == "" || s.BodyType == s.InputType:
+		return BodyUnchanged
+	case s.Template:
+		return BodyClear
+	}
+
+	switch carry {
+	case BodyCarryKeep:
+		return BodyKeep
+	case BodyCarryConvert:
+		if s.BodyType == "" {
+			return BodyKeep
+		}
+		return BodyConvert
+	default:
+		return BodyClear
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import "testing"
+
+func TestBodySwitch_Decide(t *testing.T) {
+	edited := BodySwitch{Body: `{"id": 1}`, BodyType: "pkg.GetRequest", InputType: "pkg.ListRequest"}
+
+	tests := []struct {
+		name  string
+		sw    BodySwitch
+		carry BodyCarry
+		want  BodyAction
+	}{
+		{"cached body wins", BodySwitch{Body: `{"id": 1}`, BodyType: "pkg.GetRequest", InputType: "pkg.GetRequest", Cached: true}, BodyCarryKeep, BodyRestore},
+		{"empty body", BodySwitch{Body: " \n", BodyType: "pkg.GetRequest", InputType: "pkg.ListRequest"}, BodyCarryClear, BodyUnchanged},
+		{"same input type", BodySwitch{Body: `{"id": 1}`, BodyType: "pkg.GetRequest", InputType: "pkg.GetRequest"}, BodyCarryClear, BodyUnchanged},
+		{"untouched template", BodySwitch{Body: `{"id": ""}`, BodyType: "pkg.GetRequest", InputType: "pkg.ListRequest", Template: true}, BodyCarryKeep, BodyClear},
+		{"clear", edited, BodyCarryClear, BodyClear},
+		{"keep", edited, BodyCarryKeep, BodyKeep},
+		{"convert", edited, BodyCarryConvert, BodyConvert},
+		{"unset preference clears", edited, "", BodyClear},
+		{"unknown type is kept rather than converted", BodySwitch{Body: `{"id": 1}`, InputType: "pkg.ListRequest"}, BodyCarryConvert, BodyKeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sw.Decide(tt.carry); got != tt.want {
+				t.Errorf("Decide(%q) = %v, want %v", tt.carry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBodyCarry(t *testing.T) {
+	for in, want := range map[string]BodyCarry{
+		"keep":    BodyCarryKeep,
+		"convert": BodyCarryConvert,
+		"clear":   BodyCarryClear,
+		"":        BodyCarryClear,
+		"bogus":   BodyCarryClear,
+	} {
+		if got := ParseBodyCarry(in); got != want {
+			t.Errorf("ParseBodyCarry(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package model
 
-import "fyne.io/fyne/v2/data/binding"
+import (
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+)
 
 // ApplicationState represents the centralized application state with Fyne data bindings.
 // All UI components bind to these values for reactive updates.
@@ -36,9 +40,10 @@ func NewApplicationState() *ApplicationState {
 
 // RequestState represents the state of the request panel.
 type RequestState struct {
-	Mode     binding.String     // "text" or "form"
-	TextData binding.String     // JSON representation
-	Metadata binding.StringList // Request metadata headers
+	Mode      binding.String     // "text" or "form"
+	TextData  binding.String     // JSON representation
+	InputType binding.String     // Full name of the message TextData was written for ("" if unknown)
+	Metadata  binding.StringList // Request metadata headers
 }
 
 // NewRequestState creates a new RequestState with initialized bindings.
@@ -47,9 +52,10 @@ func NewRequestState() *RequestState {
 	_ = mode.Set("form") // Default to form mode
 
 	return &RequestState{
-		Mode:     mode,
-		TextData: binding.NewString(),
-		Metadata: binding.NewStringList(),
+		Mode:      mode,
+		TextData:  binding.NewString(),
+		InputType: binding.NewString(),
+		Metadata:  binding.NewStringList(),
 	}
 }
 
@@ -78,9 +84,15 @@ func NewResponseState() *ResponseState {
 
 // ConnectionUIState represents the UI state for connection status display.
 // States: "disconnected", "connecting", "connected", "error"
+//
+// While connected, Link tracks the transport underneath: "idle",
+// "connecting", "ready", "transient-failure" or "shutdown", or "" when the
+// transport does not report it (gRPC-Web).
 type ConnectionUIState struct {
-	State   binding.String // Connection state
-	Message binding.String // Status message
+	State     binding.String          // Connection state
+	Message   binding.String          // Status message
+	Link      binding.String          // Transport link state
+	LinkSince binding.Item[time.Time] // When Link was entered
 }
 
 // NewConnectionUIState creates a new ConnectionUIState with initialized bindings.
@@ -89,7 +101,9 @@ func NewConnectionUIState() *ConnectionUIState {
 	_ = state.Set("disconnected") // Default to disconnected
 
 	return &ConnectionUIState{
-		State:   state,
-		Message: binding.NewString(),
+		State:     state,
+		Message:   binding.NewString(),
+		Link:      binding.NewString(),
+		LinkSince: binding.NewItem(func(a, b time.Time) bool { return a.Equal(b) }),
 	}
 }
@@ -0,0 +1,420 @@
+package protoconv
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// AnyFullName is the full name of google.protobuf.Any.
+const AnyFullName protoreflect.FullName = "google.protobuf.Any"
+
+// AnyTypeURLPrefix is the prefix of the type URLs written into Any values.
+const AnyTypeURLPrefix = "type.googleapis.com/"
+
+// Field numbers of google.protobuf.Any.
+const (
+	anyTypeURLField protoreflect.FieldNumber = 1
+	anyValueField   protoreflect.FieldNumber = 2
+)
+
+// LookupFunc finds a message type the TypeResolver does not know yet, such
+// as one the server only references from Any values.
+type LookupFunc func(protoreflect.FullName) (protoreflect.MessageDescriptor, error)
+
+// TypeResolver finds the message types named by Any type URLs: first among
+// the server's files, then among the types compiled into the binary, then
+// through an optional lookup. Types a lookup finds, or fails to find, are
+// remembered. A nil *TypeResolver knows only the compiled-in types.
+type TypeResolver struct {
+	local  *protoregistry.Types
+	lookup LookupFunc
+
+	mu      sync.RWMutex
+	lookups map[protoreflect.FullName]protoreflect.MessageType // nil for a miss
+}
+
+// NewTypeResolver returns a resolver for the messages declared in files,
+// falling back to lookup (which may be nil) for any others.
+func NewTypeResolver(files []protoreflect.FileDescriptor, lookup LookupFunc) *TypeResolver {
+	r := &TypeResolver{
+		local:   new(protoregistry.Types),
+		lookup:  lookup,
+		lookups: make(map[protoreflect.FullName]protoreflect.MessageType),
+	}
+	for _, fd := range files {
+		registerMessages(r.local, fd.Messages())
+		registerExtensions(r.local, fd.Extensions())
+	}
+	return r
+}
+
+// registerMessages adds msgs, the messages nested in them and the
+// extensions they declare to types. Names already registered, by an
+// earlier file, are skipped.
+func registerMessages(types *protoregistry.Types, msgs protoreflect.MessageDescriptors) {
+	for i := range msgs.Len() {
+		md := msgs.Get(i)
+		if !md.IsMapEntry() {
+			_ = types.RegisterMessage(dynamicpb.NewMessageType(md))
+		}
+		registerMessages(types, md.Messages())
+		registerExtensions(types, md.Extensions())
+	}
+}
+
+// registerExtensions adds exts to types, skipping any already registered.
+func registerExtensions(types *protoregistry.Types, exts protoreflect.ExtensionDescriptors) {
+	for i := range exts.Len() {
+		_ = types.RegisterExtension(dynamicpb.NewExtensionType(exts.Get(i)))
+	}
+}
+
+// FindMessageByName implements protoregistry.MessageTypeResolver.
+func (r *TypeResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if r == nil {
+		return protoregistry.GlobalTypes.FindMessageByName(name)
+	}
+	if mt, err := r.local.FindMessageByName(name); err == nil {
+		return mt, nil
+	}
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(name); err == nil {
+		return mt, nil
+	}
+	return r.lookupMessage(name)
+}
+
+// FindMessageByURL implements protoregistry.MessageTypeResolver. Only the
+// part of the URL after the last slash is significant.
+func (r *TypeResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	return r.FindMessageByName(typeURLName(url))
+}
+
+// FindExtensionByName implements protoregistry.ExtensionTypeResolver.
+func (r *TypeResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if r != nil {
+		if xt, err := r.local.FindExtensionByName(field); err == nil {
+			return xt, nil
+		}
+	}
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+// FindExtensionByNumber implements protoregistry.ExtensionTypeResolver.
+func (r *TypeResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if r != nil {
+		if xt, err := r.local.FindExtensionByNumber(message, field); err == nil {
+			return xt, nil
+		}
+	}
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+// lookupMessage asks the lookup for a type, once per name.
+func (r *TypeResolver) lookupMessage(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if r.lookup == nil {
+		return nil, protoregistry.NotFound
+	}
+	r.mu.RLock()
+	mt, seen := r.lookups[name]
+	r.mu.RUnlock()
+	if !seen {
+		if md, err := r.lookup(name); err == nil && md != nil {
+			mt = dynamicpb.NewMessageType(md)
+		}
+		r.mu.Lock()
+		r.lookups[name] = mt
+		r.mu.Unlock()
+	}
+	if mt == nil {
+		return nil, protoregistry.NotFound
+	}
+	return mt, nil
+}
+
+// MessageNames lists the message types an Any can be filled with: the
+// server's own messages, then the compiled-in ones, each sorted by name.
+func (r *TypeResolver) MessageNames() []protoreflect.FullName {
+	var local []protoreflect.FullName
+	seen := make(map[protoreflect.FullName]bool)
+	if r != nil {
+		r.local.RangeMessages(func(mt protoreflect.MessageType) bool {
+			name := mt.Descriptor().FullName()
+			local = append(local, name)
+			seen[name] = true
+			return true
+		})
+	}
+	var global []protoreflect.FullName
+	protoregistry.GlobalTypes.RangeMessages(func(mt protoreflect.MessageType) bool {
+		md := mt.Descriptor()
+		if !seen[md.FullName()] && !md.IsMapEntry() {
+			global = append(global, md.FullName())
+		}
+		return true
+	})
+	slices.Sort(local)
+	slices.Sort(global)
+	return append(local, global...)
+}
+
+// TypeURL returns the type URL an Any holding a name message carries.
+func TypeURL(name protoreflect.FullName) string {
+	return AnyTypeURLPrefix + string(name)
+}
+
+// typeURLName returns the message name a type URL refers to.
+func typeURLName(url string) protoreflect.FullName {
+	if i := strings.LastIndexByte(url, '/'); i >= 0 {
+		url = url[i+1:]
+	}
+	return protoreflect.FullName(url)
+}
+
+// Marshal formats msg as JSON with opts, expanding every Any whose type
+// r resolves into the payload's own fields next to its "@type". An Any of
+// a type r cannot resolve is written as {"@type": url, "value": base64},
+// which Unmarshal reads back, instead of failing the whole message.
+func (r *TypeResolver) Marshal(opts protojson.MarshalOptions, msg proto.Message) ([]byte, error) {
+	res := &anyResolver{types: r}
+	if hasAny(msg.ProtoReflect().Descriptor(), nil) {
+		msg = proto.Clone(msg)
+		if err := res.wrapUnresolved(msg.ProtoReflect()); err != nil {
+			return nil, err
+		}
+	}
+	opts.Resolver = res
+	return opts.Marshal(msg)
+}
+
+// Unmarshal parses JSON written by Marshal, or by protojson, into
+// msg with opts. Any values of an unresolvable type may be given as
+// {"@type": url, "value": base64}, carrying their encoded payload as is.
+func (r *TypeResolver) Unmarshal(opts protojson.UnmarshalOptions, data []byte, msg proto.Message) error {
+	res := &anyResolver{types: r, placeholders: true}
+	opts.Resolver = res
+	if err := opts.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	if len(res.wrappers) == 0 {
+		return nil
+	}
+	return res.unwrapUnresolved(msg.ProtoReflect())
+}
+
+// stableMarshal re-encodes Any payloads with their fields in a fixed order,
+// so a round trip gives back the bytes it started from. Dynamic messages
+// otherwise encode their fields in random order.
+var stableMarshal = proto.MarshalOptions{Deterministic: true}
+
+// anyResolver resolves Any types for one Marshal or Unmarshal call,
+// standing a wrapper message with a single bytes field named "value" in for
+// each type the TypeResolver cannot find.
+type anyResolver struct {
+	types        *TypeResolver
+	placeholders bool // create wrappers on demand, for unmarshalling
+	wrappers     map[protoreflect.FullName]protoreflect.MessageType
+}
+
+func (a *anyResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if mt, ok := a.wrappers[name]; ok {
+		return mt, nil
+	}
+	mt, err := a.types.FindMessageByName(name)
+	if err == nil || !a.placeholders {
+		return mt, err
+	}
+	return a.wrapper(name)
+}
+
+func (a *anyResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	return a.FindMessageByName(typeURLName(url))
+}
+
+func (a *anyResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	return a.types.FindExtensionByName(field)
+}
+
+func (a *anyResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	return a.types.FindExtensionByNumber(message, field)
+}
+
+// wrapper returns the wrapper message standing in for name.
+func (a *anyResolver) wrapper(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if mt, ok := a.wrappers[name]; ok {
+		return mt, nil
+	}
+	if !name.IsValid() {
+		return nil, fmt.Errorf("invalid Any type name %q", name)
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String("grotto/unresolved/" + string(name) + ".proto"),
+		Syntax: proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String(string(name.Name())),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("value"),
+				JsonName: proto.String("value"),
+				Number:   proto.Int32(1),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			}},
+		}},
+	}
+	if pkg := name.Parent(); pkg != "" {
+		fdp.Package = proto.String(string(pkg))
+	}
+	fd, err := protodesc.NewFile(fdp, new(protoregistry.Files))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Any type name %q: %w", name, err)
+	}
+	mt := dynamicpb.NewMessageType(fd.Messages().Get(0))
+	if a.wrappers == nil {
+		a.wrappers = make(map[protoreflect.FullName]protoreflect.MessageType)
+	}
+	a.wrappers[name] = mt
+	return mt, nil
+}
+
+// wrapUnresolved rewrites, in place, the value of every Any in m whose type
+// cannot be resolved into the encoding of its wrapper message, so protojson
+// can format it. Resolvable payloads are decoded and searched too.
+func (a *anyResolver) wrapUnresolved(m protoreflect.Message) error {
+	return rangeAnys(m, func(anyMsg protoreflect.Message) error {
+		url := anyMsg.Get(anyMsg.Descriptor().Fields().ByNumber(anyTypeURLField)).String()
+		valueFD := anyMsg.Descriptor().Fields().ByNumber(anyValueField)
+		value := anyMsg.Get(valueFD).Bytes()
+
+		if mt, err := a.types.FindMessageByURL(url); err == nil {
+			payload := mt.New()
+			if err := proto.Unmarshal(value, payload.Interface()); err != nil || !hasAny(payload.Descriptor(), nil) {
+				// protojson reports payloads that do not decode
+				return nil
+			}
+			if err := a.wrapUnresolved(payload); err != nil {
+				return err
+			}
+			data, err := stableMarshal.Marshal(payload.Interface())
+			if err != nil {
+				return err
+			}
+			anyMsg.Set(valueFD, protoreflect.ValueOfBytes(data))
+			return nil
+		}
+
+		wt, err := a.wrapper(typeURLName(url))
+		if err != nil {
+			return err
+		}
+		w := wt.New()
+		w.Set(wt.Descriptor().Fields().ByNumber(1), protoreflect.ValueOfBytes(value))
+		data, err := proto.Marshal(w.Interface())
+		if err != nil {
+			return err
+		}
+		anyMsg.Set(valueFD, protoreflect.ValueOfBytes(data))
+		return nil
+	})
+}
+
+// unwrapUnresolved undoes wrapUnresolved after protojson has packed wrapper
+// messages into Any values.
+func (a *anyResolver) unwrapUnresolved(m protoreflect.Message) error {
+	return rangeAnys(m, func(anyMsg protoreflect.Message) error {
+		url := anyMsg.Get(anyMsg.Descriptor().Fields().ByNumber(anyTypeURLField)).String()
+		valueFD := anyMsg.Descriptor().Fields().ByNumber(anyValueField)
+		value := anyMsg.Get(valueFD).Bytes()
+
+		mt, err := a.FindMessageByURL(url)
+		if err != nil {
+			return err
+		}
+		payload := mt.New()
+		if err := proto.Unmarshal(value, payload.Interface()); err != nil {
+			return err
+		}
+		if wt, ok := a.wrappers[typeURLName(url)]; ok && wt == mt {
+			anyMsg.Set(valueFD, payload.Get(wt.Descriptor().Fields().ByNumber(1)))
+			return nil
+		}
+		if !hasAny(payload.Descriptor(), nil) {
+			return nil
+		}
+		if err := a.unwrapUnresolved(payload); err != nil {
+			return err
+		}
+		data, err := stableMarshal.Marshal(payload.Interface())
+		if err != nil {
+			return err
+		}
+		anyMsg.Set(valueFD, protoreflect.ValueOfBytes(data))
+		return nil
+	})
+}
+
+// rangeAnys calls fn with every Any set in m, looking through nested
+// messages, lists, and map values but not into the Anys themselves.
+func rangeAnys(m protoreflect.Message, fn func(protoreflect.Message) error) error {
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		md := fd.Message()
+		if fd.IsMap() {
+			md = fd.MapValue().Message()
+		}
+		if md == nil || !hasAny(md, nil) {
+			return true
+		}
+		visit := func(msg protoreflect.Message) error {
+			if msg.Descriptor().FullName() == AnyFullName {
+				return fn(msg)
+			}
+			return rangeAnys(msg, fn)
+		}
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = visit(list.Get(i).Message())
+			}
+		case fd.IsMap():
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				err = visit(mv.Message())
+				return err == nil
+			})
+		default:
+			err = visit(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
+
+// hasAny reports whether a message of type md can contain an Any.
+func hasAny(md protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) bool {
+	if md.FullName() == AnyFullName {
+		return true
+	}
+	if visiting[md.FullName()] {
+		return false
+	}
+	if visiting == nil {
+		visiting = make(map[protoreflect.FullName]bool)
+	}
+	visiting[md.FullName()] = true
+	fields := md.Fields()
+	for i := range fields.Len() {
+		if sub := fields.Get(i).Message(); sub != nil && hasAny(sub, visiting) {
+			return true
+		}
+	}
+	return false
+}
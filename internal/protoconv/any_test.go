@@ -0,0 +1,165 @@
+package protoconv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// customFile declares custom.v1.Envelope, holding an Any, and
+// custom.v1.Widget, a type only the server knows, with an Any of its own.
+func customFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("custom/v1/custom.proto"),
+		Package:    proto.String("custom.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/any.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Envelope"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("payload"), JsonName: proto.String("payload"), Number: proto.Int32(1), Type: msg, Label: opt, TypeName: proto.String(".google.protobuf.Any")},
+				},
+			},
+			{
+				Name: proto.String("Widget"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Type: str, Label: opt},
+					{Name: proto.String("detail"), JsonName: proto.String("detail"), Number: proto.Int32(2), Type: msg, Label: opt, TypeName: proto.String(".google.protobuf.Any")},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	return fd
+}
+
+// packed returns an Any holding m.
+func packed(t *testing.T, m proto.Message) *anypb.Any {
+	t.Helper()
+	data, err := stableMarshal.Marshal(m)
+	require.NoError(t, err)
+	return &anypb.Any{TypeUrl: TypeURL(m.ProtoReflect().Descriptor().FullName()), Value: data}
+}
+
+// envelope returns a custom.v1.Envelope holding payload.
+func envelope(fd protoreflect.FileDescriptor, payload *anypb.Any) *dynamicpb.Message {
+	env := dynamicpb.NewMessage(fd.Messages().ByName("Envelope"))
+	env.Set(env.Descriptor().Fields().ByName("payload"), protoreflect.ValueOfMessage(payload.ProtoReflect()))
+	return env
+}
+
+func TestTypeResolver_PackUnpackLocalType(t *testing.T) {
+	fd := customFile(t)
+	types := NewTypeResolver([]protoreflect.FileDescriptor{fd}, nil)
+
+	widget := dynamicpb.NewMessage(fd.Messages().ByName("Widget"))
+	widget.Set(widget.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString("sprocket"))
+	env := envelope(fd, packed(t, widget))
+
+	data, err := types.Marshal(protojson.MarshalOptions{}, env)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"payload":{"@type":"type.googleapis.com/custom.v1.Widget","name":"sprocket"}}`, string(data))
+
+	got := dynamicpb.NewMessage(env.Descriptor())
+	require.NoError(t, types.Unmarshal(protojson.UnmarshalOptions{}, data, got))
+	assert.True(t, proto.Equal(env, got), "round trip changed the message")
+
+	// protojson alone cannot format a type it has never heard of
+	_, err = protojson.Marshal(env)
+	assert.Error(t, err)
+}
+
+func TestTypeResolver_UnresolvableTypeFallsBackToBytes(t *testing.T) {
+	fd := customFile(t)
+	types := NewTypeResolver([]protoreflect.FileDescriptor{fd}, nil)
+
+	unknown := &anypb.Any{TypeUrl: "type.googleapis.com/missing.v1.Thing", Value: []byte{0x08, 0x2a}}
+	env := envelope(fd, unknown)
+
+	data, err := types.Marshal(protojson.MarshalOptions{}, env)
+	require.NoError(t, err)
+	var out struct {
+		Payload map[string]string `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, map[string]string{
+		"@type": "type.googleapis.com/missing.v1.Thing",
+		"value": base64.StdEncoding.EncodeToString(unknown.Value),
+	}, out.Payload)
+
+	got := dynamicpb.NewMessage(env.Descriptor())
+	require.NoError(t, types.Unmarshal(protojson.UnmarshalOptions{}, data, got))
+	assert.True(t, proto.Equal(env, got), "round trip changed the message")
+	assert.True(t, proto.Equal(unknown, got.Get(got.Descriptor().Fields().ByName("payload")).Message().Interface()))
+}
+
+func TestTypeResolver_UnresolvableInsideResolvable(t *testing.T) {
+	fd := customFile(t)
+	types := NewTypeResolver([]protoreflect.FileDescriptor{fd}, nil)
+
+	widget := dynamicpb.NewMessage(fd.Messages().ByName("Widget"))
+	widget.Set(widget.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString("outer"))
+	widget.Set(widget.Descriptor().Fields().ByName("detail"), protoreflect.ValueOfMessage(
+		(&anypb.Any{TypeUrl: "example.com/missing.v1.Thing", Value: []byte{0x01}}).ProtoReflect()))
+	env := envelope(fd, packed(t, widget))
+
+	data, err := types.Marshal(protojson.MarshalOptions{}, env)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"payload":{
+		"@type":"type.googleapis.com/custom.v1.Widget",
+		"name":"outer",
+		"detail":{"@type":"example.com/missing.v1.Thing","value":"AQ=="}
+	}}`, string(data))
+
+	got := dynamicpb.NewMessage(env.Descriptor())
+	require.NoError(t, types.Unmarshal(protojson.UnmarshalOptions{}, data, got))
+	assert.True(t, proto.Equal(env, got), "round trip changed the message")
+}
+
+func TestTypeResolver_LookupRemembered(t *testing.T) {
+	fd := customFile(t)
+	calls := 0
+	types := NewTypeResolver(nil, func(name protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+		calls++
+		if md := fd.Messages().ByName(name.Name()); md != nil && md.FullName() == name {
+			return md, nil
+		}
+		return nil, protoregistry.NotFound
+	})
+
+	for range 2 {
+		mt, err := types.FindMessageByURL("type.googleapis.com/custom.v1.Widget")
+		require.NoError(t, err)
+		assert.Equal(t, protoreflect.FullName("custom.v1.Widget"), mt.Descriptor().FullName())
+		_, err = types.FindMessageByName("custom.v1.Missing")
+		assert.ErrorIs(t, err, protoregistry.NotFound)
+	}
+	assert.Equal(t, 2, calls, "each name is looked up once")
+}
+
+func TestTypeResolver_MessageNames(t *testing.T) {
+	types := NewTypeResolver([]protoreflect.FileDescriptor{customFile(t)}, nil)
+	names := types.MessageNames()
+	require.GreaterOrEqual(t, len(names), 3)
+	assert.Equal(t, []protoreflect.FullName{"custom.v1.Envelope", "custom.v1.Widget"}, names[:2])
+	assert.Contains(t, names, protoreflect.FullName("google.protobuf.Any"))
+
+	var nilTypes *TypeResolver
+	assert.NotContains(t, nilTypes.MessageNames(), protoreflect.FullName("custom.v1.Widget"))
+}
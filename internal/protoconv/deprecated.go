@@ -0,0 +1,26 @@
+package protoconv
+
+import "google.golang.org/protobuf/reflect/protoreflect"
+
+// IsDeprecated reports whether d is marked with the deprecated option.
+// Every descriptor kind (file, message, field, enum, enum value, service,
+// method) carries the option, so any descriptor may be passed.
+func IsDeprecated(d protoreflect.Descriptor) bool {
+	opts, ok := d.Options().(interface{ GetDeprecated() bool })
+	return ok && opts.GetDeprecated()
+}
+
+// IsFieldDeprecated reports whether a field is deprecated itself or holds a
+// message type that is deprecated. For maps the value type is checked.
+func IsFieldDeprecated(fd protoreflect.FieldDescriptor) bool {
+	if IsDeprecated(fd) {
+		return true
+	}
+	if fd.IsMap() {
+		fd = fd.MapValue()
+	}
+	if md := fd.Message(); md != nil {
+		return IsDeprecated(md)
+	}
+	return false
+}
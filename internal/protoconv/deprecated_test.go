@@ -0,0 +1,55 @@
+package protoconv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const deprecatedProto = `
+syntax = "proto3";
+package deprecated.v1;
+
+message Legacy { option deprecated = true; string id = 1; }
+message Current { string id = 1; }
+
+message Holder {
+  string name = 1;
+  string old_name = 2 [deprecated = true];
+  Legacy legacy = 3;
+  repeated Legacy legacies = 4;
+  map<string, Legacy> by_key = 5;
+  map<string, Current> current = 6;
+  Current current_one = 7;
+}
+`
+
+func TestIsFieldDeprecated(t *testing.T) {
+	compiler := protocompile.Compiler{
+		Resolver: &protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{"deprecated.proto": deprecatedProto}),
+		},
+	}
+	files, err := compiler.Compile(context.Background(), "deprecated.proto")
+	require.NoError(t, err)
+	msgs := files[0].Messages()
+	assert.True(t, IsDeprecated(msgs.ByName("Legacy")))
+	assert.False(t, IsDeprecated(msgs.ByName("Current")))
+
+	fields := msgs.ByName("Holder").Fields()
+	for name, want := range map[string]bool{
+		"name":        false,
+		"old_name":    true,
+		"legacy":      true,
+		"legacies":    true,
+		"by_key":      true,
+		"current":     false,
+		"current_one": false,
+	} {
+		assert.Equal(t, want, IsFieldDeprecated(fields.ByName(protoreflect.Name(name))), name)
+	}
+}
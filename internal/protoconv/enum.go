@@ -0,0 +1,71 @@
+package protoconv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shhac/grotto/internal/jsonpath"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// UnknownEnumPaths returns the JSON paths, e.g. $.items[2].state, of the
+// enum values in jsonMsg, a desc message, that their enums do not declare.
+// protojson writes those as bare numbers, which read like any other
+// number. Paths are sorted.
+func UnknownEnumPaths(desc protoreflect.MessageDescriptor, jsonMsg string, types *TypeResolver) ([]string, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := types.Unmarshal(protojson.UnmarshalOptions{}, []byte(jsonMsg), msg); err != nil {
+		return nil, fmt.Errorf("invalid message JSON: %w", err)
+	}
+	var paths []string
+	appendUnknownEnums(&paths, "$", msg)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// appendUnknownEnums appends the paths of msg's undeclared enum values,
+// msg being at path.
+func appendUnknownEnums(paths *[]string, path string, msg protoreflect.Message) {
+	if strings.HasPrefix(string(msg.Descriptor().FullName()), "google.protobuf.") {
+		// Well-known types have JSON forms of their own
+		return
+	}
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() {
+			return true
+		}
+		fieldPath := path + jsonpath.MemberPath(fd.JSONName())
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := range list.Len() {
+				appendUnknownEnum(paths, fieldPath+"["+strconv.Itoa(i)+"]", fd, list.Get(i))
+			}
+		case fd.IsMap():
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				appendUnknownEnum(paths, fieldPath+jsonpath.MemberPath(k.String()), fd.MapValue(), v)
+				return true
+			})
+		default:
+			appendUnknownEnum(paths, fieldPath, fd, v)
+		}
+		return true
+	})
+}
+
+// appendUnknownEnum appends path if v, a single value of fd, is an
+// undeclared enum value, or the paths of those inside v if it is a message.
+func appendUnknownEnum(paths *[]string, path string, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if fd.Enum().Values().ByNumber(v.Enum()) == nil {
+			*paths = append(*paths, path)
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		appendUnknownEnums(paths, path, v.Message())
+	}
+}
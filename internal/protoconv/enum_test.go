@@ -0,0 +1,91 @@
+package protoconv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const enumProto = `
+syntax = "proto3";
+package enums.v1;
+
+import "google/protobuf/struct.proto";
+
+enum Status { STATUS_UNSPECIFIED = 0; STATUS_OK = 1; STATUS_FAILED = 2; }
+
+message Item { Status status = 1; }
+
+message Report {
+  Status status = 1;
+  repeated Status history = 2;
+  map<string, Status> by_region = 3;
+  repeated Item items = 4;
+  google.protobuf.Value extra = 5;
+}
+`
+
+func enumReport(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{"enums.proto": enumProto}),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), "enums.proto")
+	require.NoError(t, err)
+	return files[0].Messages().ByName("Report")
+}
+
+func TestUnknownEnumPaths(t *testing.T) {
+	desc := enumReport(t)
+
+	paths, err := UnknownEnumPaths(desc, `{
+		"status": 999,
+		"history": ["STATUS_OK", 7, 2],
+		"byRegion": {"eu-west": 5, "us": "STATUS_FAILED"},
+		"items": [{"status": 1}, {"status": 42}],
+		"extra": null
+	}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`$.byRegion["eu-west"]`,
+		"$.history[1]",
+		"$.items[1].status",
+		"$.status",
+	}, paths)
+
+	paths, err = UnknownEnumPaths(desc, `{"status": "STATUS_OK"}`, nil)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	_, err = UnknownEnumPaths(desc, `{"status": "STATUS_BOGUS"}`, nil)
+	assert.Error(t, err)
+}
+
+func TestUnknownEnumPaths_FromServer(t *testing.T) {
+	// A server with a newer Status sends a number this descriptor lacks;
+	// decoding keeps it and protojson writes it as a number
+	desc := enumReport(t)
+	sent := dynamicpb.NewMessage(desc)
+	sent.Set(desc.Fields().ByName("status"), protoreflect.ValueOfEnum(3))
+	wire, err := proto.Marshal(sent)
+	require.NoError(t, err)
+
+	received := dynamicpb.NewMessage(desc)
+	require.NoError(t, proto.Unmarshal(wire, received))
+	text, err := protojson.Marshal(received)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status": 3}`, string(text))
+
+	paths, err := UnknownEnumPaths(desc, string(text), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"$.status"}, paths)
+}
@@ -0,0 +1,90 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// MaskPath is a path a FieldMask can name, found among the fields set in a
+// message.
+type MaskPath struct {
+	Path  string // snake_case proto names, e.g. "address.city"
+	Depth int    // Number of messages above the field
+	Leaf  bool   // The path cannot go deeper: scalars, maps, lists, well-known types
+}
+
+// MaskPaths lists the paths a FieldMask over md could name for the fields
+// set in the JSON message text: every field, followed by the fields set
+// inside it when it is a message. Map and repeated fields are listed but
+// not entered, since mask paths cannot select map keys or list elements;
+// nor are well-known types, whose JSON form is not their fields. Fields
+// are visited in declaration order, and unknown or null ones are skipped.
+func MaskPaths(text string, md protoreflect.MessageDescriptor) ([]MaskPath, error) {
+	var paths []MaskPath
+	var walk func(raw json.RawMessage, md protoreflect.MessageDescriptor, prefix string, depth int) error
+	walk = func(raw json.RawMessage, md protoreflect.MessageDescriptor, prefix string, depth int) error {
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &members); err != nil {
+			if prefix == "" {
+				return fmt.Errorf("%s must be a JSON object", md.Name())
+			}
+			return fmt.Errorf("%s must be a JSON object", strings.TrimSuffix(prefix, "."))
+		}
+		fields := md.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			value, ok := members[fd.JSONName()]
+			if !ok {
+				value, ok = members[string(fd.Name())]
+			}
+			if !ok || string(value) == "null" {
+				continue
+			}
+			path := prefix + string(fd.Name())
+			entered := fd.Message() != nil && !fd.IsList() && !fd.IsMap() &&
+				fd.Message().ParentFile().Package() != "google.protobuf"
+			paths = append(paths, MaskPath{Path: path, Depth: depth, Leaf: !entered})
+			if entered {
+				if err := walk(value, fd.Message(), path+".", depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(json.RawMessage(text), md, "", 0); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// FieldMaskJSON returns the protojson string form of a FieldMask with the
+// given snake_case paths: sorted, without duplicates or paths under another
+// one given, in lowerCamelCase and joined by commas.
+func FieldMaskJSON(paths []string) (string, error) {
+	mask := &fieldmaskpb.FieldMask{Paths: paths}
+	mask.Normalize()
+	s, err := marshalString(mask)
+	if err != nil {
+		return "", fmt.Errorf("field mask: %w", err)
+	}
+	return s, nil
+}
+
+// FieldMaskFields returns the top-level FieldMask fields of md, such as an
+// Update method's update_mask.
+func FieldMaskFields(md protoreflect.MessageDescriptor) []protoreflect.FieldDescriptor {
+	var found []protoreflect.FieldDescriptor
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !fd.IsList() && fd.Message() != nil && fd.Message().FullName() == FieldMaskName {
+			found = append(found, fd)
+		}
+	}
+	return found
+}
@@ -0,0 +1,178 @@
+package protoconv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const maskProto = `
+syntax = "proto3";
+package mask.v1;
+
+import "google/protobuf/field_mask.proto";
+import "google/protobuf/timestamp.proto";
+
+message Address {
+  string city = 1;
+  Geo geo = 2;
+}
+
+message Geo {
+  double lat = 1;
+  double lng = 2;
+}
+
+message User {
+  string id = 1;
+  string display_name = 2;
+  Address address = 3;
+  map<string, Address> addresses = 4;
+  repeated Address previous = 5;
+  google.protobuf.Timestamp created_at = 6;
+  oneof contact {
+    string email = 7;
+    string phone = 8;
+  }
+}
+
+message UpdateUserRequest {
+  User user = 1;
+  google.protobuf.FieldMask update_mask = 2;
+  repeated google.protobuf.FieldMask masks = 3;
+}
+`
+
+// maskMessages compiles maskProto and returns its User and
+// UpdateUserRequest messages.
+func maskMessages(t *testing.T) (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{"mask.proto": maskProto}),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), "mask.proto")
+	require.NoError(t, err)
+	msgs := files[0].Messages()
+	return msgs.ByName("User"), msgs.ByName("UpdateUserRequest")
+}
+
+func TestMaskPaths(t *testing.T) {
+	user, _ := maskMessages(t)
+
+	tests := []struct {
+		name    string
+		text    string
+		want    []MaskPath
+		wantErr string
+	}{
+		{
+			name: "scalars",
+			text: `{"id": "u1", "displayName": "Ada"}`,
+			want: []MaskPath{{Path: "id", Leaf: true}, {Path: "display_name", Leaf: true}},
+		},
+		{
+			name: "nested messages are entered",
+			text: `{"address": {"city": "London", "geo": {"lat": 51.5}}}`,
+			want: []MaskPath{
+				{Path: "address"},
+				{Path: "address.city", Depth: 1, Leaf: true},
+				{Path: "address.geo", Depth: 1},
+				{Path: "address.geo.lat", Depth: 2, Leaf: true},
+			},
+		},
+		{
+			name: "maps stop at the map field",
+			text: `{"addresses": {"home": {"city": "Paris"}}}`,
+			want: []MaskPath{{Path: "addresses", Leaf: true}},
+		},
+		{
+			name: "repeated fields stop at the list field",
+			text: `{"previous": [{"city": "Rome"}, {"city": "Oslo"}]}`,
+			want: []MaskPath{{Path: "previous", Leaf: true}},
+		},
+		{
+			name: "well-known types are not entered",
+			text: `{"createdAt": "2024-06-15T08:00:00Z"}`,
+			want: []MaskPath{{Path: "created_at", Leaf: true}},
+		},
+		{
+			name: "oneof members by their own names",
+			text: `{"email": "ada@example.com"}`,
+			want: []MaskPath{{Path: "email", Leaf: true}},
+		},
+		{
+			name: "proto names accepted",
+			text: `{"display_name": "Ada", "created_at": "2024-06-15T08:00:00Z"}`,
+			want: []MaskPath{{Path: "display_name", Leaf: true}, {Path: "created_at", Leaf: true}},
+		},
+		{
+			name: "declaration order, not document order",
+			text: `{"displayName": "Ada", "id": "u1"}`,
+			want: []MaskPath{{Path: "id", Leaf: true}, {Path: "display_name", Leaf: true}},
+		},
+		{
+			name: "unknown and null fields skipped",
+			text: `{"nickname": "A", "address": null, "id": "u1"}`,
+			want: []MaskPath{{Path: "id", Leaf: true}},
+		},
+		{
+			name: "empty message",
+			text: `{}`,
+			want: nil,
+		},
+		{
+			name:    "not an object",
+			text:    `[]`,
+			wantErr: "User must be a JSON object",
+		},
+		{
+			name:    "nested message not an object",
+			text:    `{"address": {"geo": 5}}`,
+			wantErr: "address.geo must be a JSON object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MaskPaths(tt.text, user)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFieldMaskJSON(t *testing.T) {
+	got, err := FieldMaskJSON([]string{"display_name", "address.city", "address.geo.lat", "display_name"})
+	require.NoError(t, err)
+	assert.Equal(t, "address.city,address.geo.lat,displayName", got)
+
+	got, err = FieldMaskJSON([]string{"address.city", "address", "address.geo"})
+	require.NoError(t, err)
+	assert.Equal(t, "address", got, "paths under another are dropped")
+
+	got, err = FieldMaskJSON(nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = FieldMaskJSON([]string{"displayName"})
+	assert.Error(t, err, "paths are snake_case")
+}
+
+func TestFieldMaskFields(t *testing.T) {
+	user, update := maskMessages(t)
+
+	fields := FieldMaskFields(update)
+	require.Len(t, fields, 1, "repeated masks are left out")
+	assert.Equal(t, protoreflect.Name("update_mask"), fields[0].Name())
+	assert.Empty(t, FieldMaskFields(user))
+}
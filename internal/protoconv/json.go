@@ -0,0 +1,224 @@
+package protoconv
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// JSONRewrite rewrites one value of a JSON request: the value of a
+// singular field, an element of a repeated field, or a map value, held by
+// fd at path. It returns the value to write back and whether it changed.
+type JSONRewrite func(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool)
+
+// RewriteJSON decodes a JSON request once and passes every value it holds
+// for md's fields to each rewrite in turn, following the descriptor through
+// nested messages, repeated fields, and maps. Keys are visited in sorted
+// order and paths look like "items[1].ttl" or "labels[env]". Well-known
+// types have their own JSON mappings, so they are handed to the rewrites
+// but not walked into. It returns the re-encoded JSON, or jsonStr itself
+// when nothing changed or it does not parse, so the caller's normal
+// validation can report it.
+func RewriteJSON(jsonStr string, md protoreflect.MessageDescriptor, rewrites ...JSONRewrite) string {
+	if md == nil || len(rewrites) == 0 || strings.TrimSpace(jsonStr) == "" {
+		return jsonStr
+	}
+
+	dec := json.NewDecoder(strings.NewReader(jsonStr))
+	dec.UseNumber()
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return jsonStr
+	}
+
+	w := jsonWalker{rewrites: rewrites}
+	if !w.message(root, md, "") {
+		return jsonStr
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(root); err != nil {
+		return jsonStr
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// jsonWalker walks a decoded JSON request for RewriteJSON.
+type jsonWalker struct {
+	rewrites []JSONRewrite
+}
+
+// message walks obj (a decoded JSON object) against md, rewriting values
+// in place. Returns true if anything changed.
+func (w jsonWalker) message(obj interface{}, md protoreflect.MessageDescriptor, prefix string) bool {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	changed := false
+	for _, key := range sortedKeys(m) {
+		val := m[key]
+		fd := md.Fields().ByJSONName(key)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(key))
+		}
+		if fd == nil {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch {
+		case fd.IsList():
+			items, ok := val.([]interface{})
+			if !ok {
+				continue
+			}
+			for i, item := range items {
+				if newItem, c := w.element(item, fd, path+"["+strconv.Itoa(i)+"]"); c {
+					items[i] = newItem
+					changed = true
+				}
+			}
+		case fd.IsMap():
+			entries, ok := val.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, k := range sortedKeys(entries) {
+				if newVal, c := w.element(entries[k], fd.MapValue(), path+"["+k+"]"); c {
+					entries[k] = newVal
+					changed = true
+				}
+			}
+		default:
+			if newVal, c := w.element(val, fd, path); c {
+				m[key] = newVal
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// element passes a single (non-list, non-map) value to the rewrites, then
+// walks into it if it is a message other than a well-known type.
+func (w jsonWalker) element(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool) {
+	changed := false
+	for _, rewrite := range w.rewrites {
+		if newVal, c := rewrite(val, fd, path); c {
+			val = newVal
+			changed = true
+		}
+	}
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		if md := fd.Message(); !isWellKnownType(md.FullName()) && w.message(val, md, path) {
+			changed = true
+		}
+	}
+	return val, changed
+}
+
+// sortedKeys returns m's keys in order, which keeps paths and errors in a
+// stable order across runs.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isWellKnownType reports whether name is a google.protobuf.* type.
+func isWellKnownType(name protoreflect.FullName) bool {
+	return strings.HasPrefix(string(name), "google.protobuf.")
+}
+
+// ValidateJSON checks every Timestamp, Duration, and FieldMask value in a
+// JSON request against its accepted syntax, following the message
+// descriptor through nested messages, repeated fields, and maps. JSON that
+// does not parse yields no errors so the caller's normal validation can
+// report it.
+func ValidateJSON(jsonStr string, md protoreflect.MessageDescriptor) []FieldError {
+	_, errs := NormalizeJSON(jsonStr, md)
+	return errs
+}
+
+// NormalizeJSON rewrites human-friendly Duration values ("5m", "1h30m") into
+// the protojson "Ns" form and returns the (possibly unchanged) JSON along
+// with field-level errors for values that cannot be converted.
+func NormalizeJSON(jsonStr string, md protoreflect.MessageDescriptor) (string, []FieldError) {
+	var errs []FieldError
+	jsonStr = RewriteJSON(jsonStr, md, NormalizeWellKnown(&errs))
+	return jsonStr, errs
+}
+
+// NormalizeWellKnown returns a rewrite for RewriteJSON that checks
+// Timestamp, Duration, and FieldMask values against their accepted syntax,
+// adding a FieldError to errs (if not nil) for each that fails, and
+// converts human-friendly durations to the protojson "Ns" form.
+func NormalizeWellKnown(errs *[]FieldError) JSONRewrite {
+	report := func(path, msg string) {
+		if errs != nil {
+			*errs = append(*errs, FieldError{Path: path, Message: msg})
+		}
+	}
+	return func(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool) {
+		if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+			return val, false
+		}
+		name := fd.Message().FullName()
+		if val == nil || !IsSupported(name) {
+			return val, false
+		}
+
+		s, ok := val.(string)
+		if !ok {
+			report(path, hintFor(name))
+			return val, false
+		}
+
+		switch name {
+		case TimestampName:
+			if err := ValidateTimestamp(s); err != nil {
+				report(path, err.Error())
+			}
+		case DurationName:
+			normalized, err := NormalizeDuration(s)
+			if err != nil {
+				report(path, err.Error())
+				return val, false
+			}
+			if normalized != s {
+				return normalized, true
+			}
+		case FieldMaskName:
+			if err := ValidateFieldMaskJSON(s); err != nil {
+				report(path, err.Error())
+			}
+		}
+		return val, false
+	}
+}
+
+// hintFor returns the accepted-syntax hint for a supported well-known type.
+func hintFor(name protoreflect.FullName) string {
+	switch name {
+	case TimestampName:
+		return TimestampHint
+	case DurationName:
+		return DurationHint
+	default:
+		return FieldMaskJSONHint
+	}
+}
@@ -0,0 +1,118 @@
+package protoconv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+func TestNormalizeJSON(t *testing.T) {
+	itemMD := (&pb.Item{}).ProtoReflect().Descriptor()
+	listMD := (&pb.ItemList{}).ProtoReflect().Descriptor()
+	requestMD := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	tests := []struct {
+		name     string
+		md       protoreflect.MessageDescriptor
+		input    string
+		want     string
+		wantErrs []string
+	}{
+		{
+			name:  "valid values unchanged",
+			md:    itemMD,
+			input: `{"createdAt":"2024-06-15T08:00:00Z","ttl":"30s"}`,
+			want:  `{"createdAt":"2024-06-15T08:00:00Z","ttl":"30s"}`,
+		},
+		{
+			name:  "human duration converted",
+			md:    itemMD,
+			input: `{"ttl":"1h30m","name":"x"}`,
+			want:  `{"name":"x","ttl":"5400s"}`,
+		},
+		{
+			name:     "bad timestamp reported with path",
+			md:       requestMD,
+			input:    `{"item":{"created_at":"yesterday"}}`,
+			want:     `{"item":{"created_at":"yesterday"}}`,
+			wantErrs: []string{"item.created_at: " + TimestampHint},
+		},
+		{
+			name:     "repeated messages indexed",
+			md:       listMD,
+			input:    `{"items":[{"ttl":"5m"},{"ttl":"forever"}]}`,
+			want:     `{"items":[{"ttl":"300s"},{"ttl":"forever"}]}`,
+			wantErrs: []string{"items[1].ttl: " + DurationHint},
+		},
+		{
+			name:     "non-string value",
+			md:       itemMD,
+			input:    `{"createdAt":{"seconds":1}}`,
+			want:     `{"createdAt":{"seconds":1}}`,
+			wantErrs: []string{"createdAt: " + TimestampHint},
+		},
+		{
+			name:  "null ignored",
+			md:    itemMD,
+			input: `{"ttl":null}`,
+			want:  `{"ttl":null}`,
+		},
+		{
+			name:  "invalid json returned as-is",
+			md:    itemMD,
+			input: `{"ttl":`,
+			want:  `{"ttl":`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := NormalizeJSON(tt.input, tt.md)
+			assert.Equal(t, tt.want, got)
+			var msgs []string
+			for _, e := range errs {
+				msgs = append(msgs, e.Error())
+			}
+			assert.Equal(t, tt.wantErrs, msgs)
+		})
+	}
+}
+
+func TestRewriteJSON(t *testing.T) {
+	listMD := (&pb.ItemList{}).ProtoReflect().Descriptor()
+
+	// Every rewrite sees each value once, in key order, and sees what the
+	// rewrites before it wrote
+	var seen []string
+	record := func(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool) {
+		seen = append(seen, path)
+		return val, false
+	}
+	upper := func(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool) {
+		if s, ok := val.(string); ok && fd.Name() == "name" {
+			return strings.ToUpper(s), true
+		}
+		return val, false
+	}
+	var names []interface{}
+	after := func(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool) {
+		if fd.Name() == "name" {
+			names = append(names, val)
+		}
+		return val, false
+	}
+
+	got := RewriteJSON(`{"items":[{"name":"a","ttl":"5m","labels":{"b":"2","a":"1"}},{"createdAt":"2024-06-15T08:00:00Z"}]}`,
+		listMD, record, upper, after)
+	assert.Equal(t, `{"items":[{"labels":{"a":"1","b":"2"},"name":"A","ttl":"5m"},{"createdAt":"2024-06-15T08:00:00Z"}]}`, got)
+	assert.Equal(t, []string{"items[0]", "items[0].labels[a]", "items[0].labels[b]", "items[0].name", "items[0].ttl", "items[1]", "items[1].createdAt"}, seen,
+		"well-known types are passed whole, not walked into")
+	assert.Equal(t, []interface{}{"A"}, names)
+
+	// Nothing changed returns the text as written
+	input := `{"items": [{"name": "a"}]}`
+	assert.Equal(t, input, RewriteJSON(input, listMD, record))
+}
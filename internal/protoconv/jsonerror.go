@@ -0,0 +1,328 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// protojsonPosition matches the "(line L:C)" protojson puts in its errors.
+var protojsonPosition = regexp.MustCompile(`\s*\(line (\d+):(\d+)\):?\s*`)
+
+// SplitJSONError splits a protojson error into its message and the line
+// and column it reports, or zeros when it has no position.
+// "proto: (line 3:5): unknown field \"nmae\"" becomes
+// ("unknown field \"nmae\"", 3, 5).
+func SplitJSONError(errText string) (string, int, int) {
+	// protobuf randomly separates its "proto:" prefix with a space or a
+	// non-breaking space, to discourage matching on error text
+	msg := strings.TrimLeft(strings.TrimPrefix(errText, "proto:"), " \u00a0")
+	m := protojsonPosition.FindStringSubmatchIndex(msg)
+	if m == nil {
+		return msg, 0, 0
+	}
+	line, _ := strconv.Atoi(msg[m[2]:m[3]])
+	col, _ := strconv.Atoi(msg[m[4]:m[5]])
+
+	before, after := msg[:m[0]], msg[m[1]:]
+	switch {
+	case before == "":
+		msg = after
+	case after == "":
+		msg = before
+	default:
+		msg = before + ": " + after
+	}
+	return msg, line, col
+}
+
+// Messages of the protojson errors ExplainJSONError rewrites
+var (
+	unknownFieldError  = regexp.MustCompile(`^unknown field (.+)$`)
+	invalidValueError  = regexp.MustCompile(`^invalid value for (\S+) field \S+: (.*)$`)
+	invalidKeyError    = regexp.MustCompile(`^invalid value for (\S+) key: (.*)$`)
+	duplicateError     = regexp.MustCompile(`^duplicate (field|map key) (.+)$`)
+	oneofSetError      = regexp.MustCompile(`^error parsing .+, oneof (\S+) is already set$`)
+	unexpectedTokenErr = regexp.MustCompile(`^syntax error: unexpected token (.*)$`)
+	unresolvedAnyError = regexp.MustCompile(`^unable to resolve (.+?): `)
+)
+
+// ExplainJSONError rewrites the text of a protojson error from parsing
+// jsonStr as an md message into a FieldError naming the offending field
+// path and, where md describes it, the type that field expects. protojson
+// only reports a line and column, which are traced back to a path through
+// jsonStr. Errors it does not recognize keep their message, without the
+// position.
+func ExplainJSONError(errText, jsonStr string, md protoreflect.MessageDescriptor) FieldError {
+	msg, line, col := SplitJSONError(errText)
+	var loc jsonLocation
+	if line > 0 {
+		loc = locateJSONPath(md, jsonPathAt(jsonStr, lineColumnOffset(jsonStr, line, col)))
+	}
+	fe := FieldError{Path: loc.path}
+
+	switch {
+	case unknownFieldError.MatchString(msg):
+		fe.Unknown = true
+		fe.Message = "unknown field"
+		if loc.owner != nil {
+			fe.Message = "not a field of " + string(loc.owner.FullName())
+		}
+	case invalidValueError.MatchString(msg):
+		m := invalidValueError.FindStringSubmatch(msg)
+		expected := m[1]
+		if loc.field != nil {
+			expected = describeJSONType(loc.field, loc.element)
+		}
+		fe.Message = fmt.Sprintf("expected %s, got %s", expected, m[2])
+	case invalidKeyError.MatchString(msg):
+		m := invalidKeyError.FindStringSubmatch(msg)
+		fe.Message = fmt.Sprintf("map key %s is not a valid %s", m[2], m[1])
+	case duplicateError.MatchString(msg):
+		fe.Message = "set more than once"
+	case oneofSetError.MatchString(msg):
+		name := protoreflect.FullName(oneofSetError.FindStringSubmatch(msg)[1]).Name()
+		fe.Message = fmt.Sprintf("only one field of oneof %s can be set", name)
+	case unexpectedTokenErr.MatchString(msg):
+		got := unexpectedTokenErr.FindStringSubmatch(msg)[1]
+		if loc.field != nil {
+			fe.Message = fmt.Sprintf("expected %s, got %s", describeJSONType(loc.field, loc.element), got)
+		} else {
+			fe.Message = "unexpected " + got
+		}
+	case unresolvedAnyError.MatchString(msg):
+		url := unresolvedAnyError.FindStringSubmatch(msg)[1]
+		fe.Message = "unknown Any type " + url
+	default:
+		fe.Message = msg
+	}
+	return fe
+}
+
+// UnknownFields returns the top-level keys of jsonStr that name no field of
+// md, by JSON or proto name, in sorted order. Extension keys such as
+// "[pkg.ext_field]" are left for protojson to resolve. JSON that is not an
+// object has none.
+func UnknownFields(jsonStr string, md protoreflect.MessageDescriptor) []string {
+	if md == nil {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(jsonStr), &obj); err != nil {
+		return nil
+	}
+	var unknown []string
+	for key := range obj {
+		if isExtensionKey(key) {
+			continue
+		}
+		if lookupJSONField(md, key) == nil {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// isExtensionKey reports whether a JSON key names an extension, as
+// protojson writes them: the extension's full name in brackets.
+func isExtensionKey(key string) bool {
+	return len(key) > 2 && strings.HasPrefix(key, "[") && strings.HasSuffix(key, "]")
+}
+
+// describeJSONType names the JSON value fd expects, or that one of its
+// elements expects when element is set: "int32", "list of string",
+// "map of string to int64", "message grpctest.Item".
+func describeJSONType(fd protoreflect.FieldDescriptor, element bool) string {
+	switch {
+	case element:
+		return describeJSONKind(fd)
+	case fd.IsMap():
+		return fmt.Sprintf("map of %s to %s", describeJSONKind(fd.MapKey()), describeJSONKind(fd.MapValue()))
+	case fd.IsList():
+		return "list of " + describeJSONKind(fd)
+	default:
+		return describeJSONKind(fd)
+	}
+}
+
+// describeJSONKind names the type of a single value of fd.
+func describeJSONKind(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "message " + string(fd.Message().FullName())
+	case protoreflect.EnumKind:
+		return "enum " + string(fd.Enum().FullName())
+	default:
+		return fd.Kind().String()
+	}
+}
+
+// lookupJSONField finds the field of md a JSON key names, accepting the
+// proto name as protojson does.
+func lookupJSONField(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByJSONName(key); fd != nil {
+		return fd
+	}
+	return md.Fields().ByName(protoreflect.Name(key))
+}
+
+// jsonStep is one step into a JSON document: an object key, or an array
+// index when key is unset.
+type jsonStep struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// jsonLocation is a JSON path resolved against a message descriptor.
+type jsonLocation struct {
+	path    string                         // e.g. "item.tags[2]" or "item.labels[env]"
+	field   protoreflect.FieldDescriptor   // Field the path ends at, if known
+	element bool                           // Path ends at a list element or map value of field
+	owner   protoreflect.MessageDescriptor // Message the last key was looked up in
+}
+
+// locateJSONPath follows steps through md, formatting the path the way
+// FieldError paths read and noting the field it ends at. Steps past what
+// md describes, such as inside a Struct, are kept in the path only.
+func locateJSONPath(md protoreflect.MessageDescriptor, steps []jsonStep) jsonLocation {
+	var loc jsonLocation
+	var b strings.Builder
+	msg := md
+	var fd protoreflect.FieldDescriptor
+	for _, step := range steps {
+		switch {
+		case fd != nil && fd.IsMap() && !loc.element && step.isKey:
+			b.WriteString("[" + step.key + "]")
+			fd, loc.element = fd.MapValue(), true
+		case fd != nil && fd.IsList() && !loc.element && !step.isKey:
+			b.WriteString("[" + strconv.Itoa(step.index) + "]")
+			loc.element = true
+		case step.isKey:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(step.key)
+			if fd != nil {
+				msg = fd.Message()
+			}
+			loc.owner = msg
+			fd, loc.element = nil, false
+			if msg != nil {
+				fd = lookupJSONField(msg, step.key)
+			}
+			msg = nil
+		default:
+			b.WriteString("[" + strconv.Itoa(step.index) + "]")
+			fd, loc.element, loc.owner = nil, false, nil
+		}
+	}
+	loc.path = b.String()
+	loc.field = fd
+	if fd == nil {
+		loc.element = false
+	}
+	return loc
+}
+
+// lineColumnOffset converts a protojson 1-based line and rune column to a
+// byte offset into text.
+func lineColumnOffset(text string, line, col int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		offset += i + 1
+	}
+	for ; col > 1 && offset < len(text); col-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// jsonFrame tracks an open object or array while walking JSON tokens.
+type jsonFrame struct {
+	step    jsonStep
+	array   bool
+	wantKey bool
+}
+
+// jsonPathAt returns the path to the token of text starting at or spanning
+// offset: a key's own path, or the path of the value it belongs to. It
+// walks as far as text parses.
+func jsonPathAt(text string, offset int) []jsonStep {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var stack []jsonFrame
+	path := func() []jsonStep {
+		steps := make([]jsonStep, 0, len(stack))
+		for _, f := range stack {
+			if f.array && f.step.index < 0 {
+				continue
+			}
+			if !f.array && !f.step.isKey {
+				continue
+			}
+			steps = append(steps, f.step)
+		}
+		return steps
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return path()
+		}
+		end := int(dec.InputOffset())
+
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if offset < end {
+				return path()
+			}
+			continue
+		}
+
+		if n := len(stack); n > 0 {
+			top := &stack[n-1]
+			switch {
+			case top.array:
+				top.step.index++
+			case top.wantKey:
+				top.step = jsonStep{key: tok.(string), isKey: true}
+				top.wantKey = false
+				if offset < end {
+					return path()
+				}
+				continue
+			default:
+				top.wantKey = true
+			}
+		}
+		if offset < end {
+			return path()
+		}
+
+		if isDelim {
+			switch delim {
+			case '{':
+				stack = append(stack, jsonFrame{wantKey: true})
+			case '[':
+				stack = append(stack, jsonFrame{array: true, step: jsonStep{index: -1}})
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package protoconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+func TestSplitJSONError(t *testing.T) {
+	tests := []struct {
+		err      string
+		wantMsg  string
+		wantLine int
+		wantCol  int
+	}{
+		{`proto: (line 3:5): unknown field "nmae"`, `unknown field "nmae"`, 3, 5},
+		{`proto: syntax error (line 1:9): unexpected token }`, `syntax error: unexpected token }`, 1, 9},
+		{`proto: invalid value for int64 field count: "many"`, `invalid value for int64 field count: "many"`, 0, 0},
+		{"proto: (line 2:1): unknown field \"x\"", `unknown field "x"`, 2, 1},
+	}
+	for _, tt := range tests {
+		msg, line, col := SplitJSONError(tt.err)
+		assert.Equal(t, tt.wantMsg, msg, tt.err)
+		assert.Equal(t, tt.wantLine, line, tt.err)
+		assert.Equal(t, tt.wantCol, col, tt.err)
+	}
+}
+
+func TestExplainJSONError(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	tests := []struct {
+		name        string
+		json        string
+		err         string
+		wantPath    string
+		wantMsg     string
+		wantUnknown bool
+	}{
+		{
+			name:        "unknown nested field",
+			json:        `{"item":{"nmae":"x"}}`,
+			err:         `proto: (line 1:10): unknown field "nmae"`,
+			wantPath:    "item.nmae",
+			wantMsg:     "not a field of grpctest.Item",
+			wantUnknown: true,
+		},
+		{
+			name:     "wrong scalar type",
+			json:     "{\n  \"item\": {\n    \"count\": \"many\"\n  }\n}",
+			err:      `proto: (line 3:14): invalid value for int32 field count: "many"`,
+			wantPath: "item.count",
+			wantMsg:  `expected int32, got "many"`,
+		},
+		{
+			name:     "wrong enum value",
+			json:     `{"item":{"color":"PURPLE"}}`,
+			err:      "proto: (line 1:18): invalid value for enum field color: \"PURPLE\"",
+			wantPath: "item.color",
+			wantMsg:  `expected enum grpctest.Color, got "PURPLE"`,
+		},
+		{
+			name:     "list element",
+			json:     `{"item":{"tags":["a",2]}}`,
+			err:      `proto: (line 1:22): invalid value for string field tags: 2`,
+			wantPath: "item.tags[1]",
+			wantMsg:  "expected string, got 2",
+		},
+		{
+			name:     "list given a scalar",
+			json:     `{"item":{"tags":"a"}}`,
+			err:      `proto: syntax error (line 1:17): unexpected token "a"`,
+			wantPath: "item.tags",
+			wantMsg:  `expected list of string, got "a"`,
+		},
+		{
+			name:     "message given a scalar",
+			json:     `{"item":7}`,
+			err:      `proto: syntax error (line 1:9): unexpected token 7`,
+			wantPath: "item",
+			wantMsg:  "expected message grpctest.Item, got 7",
+		},
+		{
+			name:     "map value",
+			json:     `{"item":{"labels":{"env":1}}}`,
+			err:      `proto: (line 1:26): invalid value for string field value: 1`,
+			wantPath: "item.labels[env]",
+			wantMsg:  "expected string, got 1",
+		},
+		{
+			name:     "duplicate field",
+			json:     `{"item":{"name":"a","name":"b"}}`,
+			err:      `proto: (line 1:21): duplicate field "name"`,
+			wantPath: "item.name",
+			wantMsg:  "set more than once",
+		},
+		{
+			name:     "oneof set twice",
+			json:     `{"item":{"text":"a","number":"1"}}`,
+			err:      `proto: (line 1:21): error parsing "number", oneof grpctest.Item.payload is already set`,
+			wantPath: "item.number",
+			wantMsg:  "only one field of oneof payload can be set",
+		},
+		{
+			name:    "no position",
+			json:    `{}`,
+			err:     `proto: unexpected EOF`,
+			wantMsg: "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExplainJSONError(tt.err, tt.json, md)
+			assert.Equal(t, tt.wantPath, got.Path)
+			assert.Equal(t, tt.wantMsg, got.Message)
+			assert.Equal(t, tt.wantUnknown, got.Unknown)
+		})
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	md := (&pb.Item{}).ProtoReflect().Descriptor()
+
+	assert.Equal(t, []string{"bogus", "nmae"}, UnknownFields(`{"nmae":"x","created_at":"","createdAt":"","bogus":{},"name":"y"}`, md))
+	assert.Nil(t, UnknownFields(`{"name":"y"}`, md))
+	assert.Nil(t, UnknownFields(`{"name":"y","[pkg.v1.color]":"blue"}`, md), "extensions are left to protojson")
+	assert.Nil(t, UnknownFields(`[1]`, md))
+	assert.Nil(t, UnknownFields(`{"nmae":"x"}`, nil))
+}
@@ -0,0 +1,223 @@
+package protoconv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MapMessageJSON carries jsonStr, a message of type from in JSON form,
+// over to a message of type to, as MapMessage does. Messages of the same
+// type are returned unchanged.
+func MapMessageJSON(jsonStr string, from, to protoreflect.MessageDescriptor) (string, []FieldError, error) {
+	if from.FullName() == to.FullName() {
+		return jsonStr, nil, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(jsonStr))
+	dec.UseNumber()
+	var src map[string]interface{}
+	if err := dec.Decode(&src); err != nil {
+		return "", nil, fmt.Errorf("invalid %s JSON: %w", from.FullName(), err)
+	}
+
+	dst, dropped := MapMessage(src, from, to)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dst); err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), dropped, nil
+}
+
+// MapMessage carries src, a decoded JSON message of type from, over to a
+// message of type to on a best-effort basis. A field is kept when to has a
+// field of the same proto name with the same cardinality and a compatible
+// kind: the same message or enum type, or scalars of the same family
+// (signed, unsigned, floating point, string, bytes, bool). Messages of
+// different types are mapped field by field, and enum values are kept when
+// the target enum has a value of the same name. Everything else is dropped
+// and reported by its path in src.
+func MapMessage(src map[string]interface{}, from, to protoreflect.MessageDescriptor) (map[string]interface{}, []FieldError) {
+	var dropped []FieldError
+	dst := mapMessage(src, from, to, "", &dropped)
+	return dst, dropped
+}
+
+// mapMessage maps one message object, collecting dropped fields in dropped.
+func mapMessage(src map[string]interface{}, from, to protoreflect.MessageDescriptor, prefix string, dropped *[]FieldError) map[string]interface{} {
+	// Sorted keys keep the dropped fields in a stable order
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dst := make(map[string]interface{}, len(src))
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		drop := func(format string, args ...interface{}) {
+			*dropped = append(*dropped, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+		}
+
+		srcFD := lookupJSONField(from, key)
+		if srcFD == nil {
+			drop("not a field of %s", from.FullName())
+			continue
+		}
+		dstFD := to.Fields().ByName(srcFD.Name())
+		if dstFD == nil {
+			drop("%s has no field %s", to.FullName(), srcFD.Name())
+			continue
+		}
+		if srcFD.IsList() != dstFD.IsList() || srcFD.IsMap() != dstFD.IsMap() {
+			drop("%v", mismatch(srcFD, dstFD))
+			continue
+		}
+
+		var val interface{}
+		var err error
+		switch {
+		case srcFD.IsMap():
+			val, err = mapEntries(src[key], srcFD, dstFD, path, dropped)
+		case srcFD.IsList():
+			val, err = mapElements(src[key], srcFD, dstFD, path, dropped)
+		default:
+			val, err = mapValue(src[key], srcFD, dstFD, path, dropped)
+		}
+		if err != nil {
+			drop("%v", err)
+			continue
+		}
+		dst[dstFD.JSONName()] = val
+	}
+	return dst
+}
+
+// mapElements maps the elements of a repeated field.
+func mapElements(val interface{}, srcFD, dstFD protoreflect.FieldDescriptor, path string, dropped *[]FieldError) (interface{}, error) {
+	items, ok := val.([]interface{})
+	if !ok {
+		return val, nil
+	}
+	out := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		mapped, err := mapValue(item, srcFD, dstFD, fmt.Sprintf("%s[%d]", path, i), dropped)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, mapped)
+	}
+	return out, nil
+}
+
+// mapEntries maps the values of a map field.
+func mapEntries(val interface{}, srcFD, dstFD protoreflect.FieldDescriptor, path string, dropped *[]FieldError) (interface{}, error) {
+	if !kindsCompatible(srcFD.MapKey(), dstFD.MapKey()) {
+		return nil, mismatch(srcFD, dstFD)
+	}
+	entries, ok := val.(map[string]interface{})
+	if !ok {
+		return val, nil
+	}
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(entries))
+	for _, k := range keys {
+		mapped, err := mapValue(entries[k], srcFD.MapValue(), dstFD.MapValue(), path+"["+k+"]", dropped)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = mapped
+	}
+	return out, nil
+}
+
+// mapValue maps a single (non-list, non-map) value of srcFD to dstFD.
+func mapValue(val interface{}, srcFD, dstFD protoreflect.FieldDescriptor, path string, dropped *[]FieldError) (interface{}, error) {
+	if !kindsCompatible(srcFD, dstFD) {
+		return nil, mismatch(srcFD, dstFD)
+	}
+	switch srcFD.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		obj, ok := val.(map[string]interface{})
+		if !ok || srcFD.Message().FullName() == dstFD.Message().FullName() {
+			return val, nil
+		}
+		return mapMessage(obj, srcFD.Message(), dstFD.Message(), path, dropped), nil
+	case protoreflect.EnumKind:
+		name, ok := val.(string)
+		if !ok || srcFD.Enum().FullName() == dstFD.Enum().FullName() {
+			return val, nil
+		}
+		if dstFD.Enum().Values().ByName(protoreflect.Name(name)) == nil {
+			return nil, fmt.Errorf("%s has no value %s", dstFD.Enum().FullName(), name)
+		}
+		return val, nil
+	default:
+		return val, nil
+	}
+}
+
+// mismatch is the reason a field of srcFD's type is dropped from dstFD.
+func mismatch(srcFD, dstFD protoreflect.FieldDescriptor) error {
+	return fmt.Errorf("%s does not fit %s", describeJSONType(srcFD, false), describeJSONType(dstFD, false))
+}
+
+// kindsCompatible reports whether a value of src's kind can be written to
+// dst as is. Messages are compatible with any message, except well-known
+// types, which only fit themselves.
+func kindsCompatible(src, dst protoreflect.FieldDescriptor) bool {
+	sk, dk := src.Kind(), dst.Kind()
+	switch {
+	case isMessageKind(sk) || isMessageKind(dk):
+		if !isMessageKind(sk) || !isMessageKind(dk) {
+			return false
+		}
+		sn, dn := src.Message().FullName(), dst.Message().FullName()
+		return sn == dn || (!isWellKnown(sn) && !isWellKnown(dn))
+	case sk == protoreflect.EnumKind || dk == protoreflect.EnumKind:
+		return sk == dk
+	default:
+		return kindFamily(sk) == kindFamily(dk)
+	}
+}
+
+// isMessageKind reports whether k holds a message.
+func isMessageKind(k protoreflect.Kind) bool {
+	return k == protoreflect.MessageKind || k == protoreflect.GroupKind
+}
+
+// isWellKnown reports whether name is a google.protobuf type, whose JSON
+// forms are special and do not map field by field.
+func isWellKnown(name protoreflect.FullName) bool {
+	return name.Parent() == "google.protobuf"
+}
+
+// kindFamily groups scalar kinds whose JSON values are interchangeable.
+func kindFamily(k protoreflect.Kind) string {
+	switch k {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "signed"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "unsigned"
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "float"
+	default:
+		return k.String()
+	}
+}
@@ -0,0 +1,208 @@
+package protoconv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// mappingProto declares a Get response and an Update request that share
+// some fields, rename others, and change the types of a few.
+const mappingProto = `
+syntax = "proto3";
+package mapping.v1;
+
+import "google/protobuf/timestamp.proto";
+
+message Owner { string name = 1; int32 age = 2; string email = 3; }
+message Person { string name = 1; int64 age = 2; bytes email = 3; }
+
+message Item {
+  enum Color { COLOR_UNSPECIFIED = 0; RED = 1; BLUE = 2; }
+
+  string id = 1;
+  string title = 2;
+  int32 count = 3;
+  repeated string tags = 4;
+  Owner owner = 5;
+  map<string, int32> scores = 6;
+  Color color = 7;
+  repeated Owner owners = 8;
+  google.protobuf.Timestamp created_at = 9;
+  double price = 10;
+  bool active = 11;
+  repeated Color colors = 12;
+  string note = 13;
+  map<string, Owner> by_role = 14;
+}
+
+message UpdateItemRequest {
+  enum Color { COLOR_UNSPECIFIED = 0; RED = 1; GREEN = 2; }
+
+  string id = 1;
+  string name = 2;
+  string count = 3;
+  repeated string tags = 4;
+  Person owner = 5;
+  map<string, int64> scores = 6;
+  Color color = 7;
+  repeated Person owners = 8;
+  google.protobuf.Timestamp created_at = 9;
+  float price = 10;
+  repeated bool active = 11;
+  repeated Color colors = 12;
+  google.protobuf.Timestamp note = 13;
+  map<string, Person> by_role = 14;
+}
+`
+
+// mappingMessages compiles mappingProto and returns its Item and
+// UpdateItemRequest messages.
+func mappingMessages(t *testing.T) (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{"mapping.proto": mappingProto}),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), "mapping.proto")
+	require.NoError(t, err)
+	msgs := files[0].Messages()
+	return msgs.ByName("Item"), msgs.ByName("UpdateItemRequest")
+}
+
+func TestMapMessage(t *testing.T) {
+	item, update := mappingMessages(t)
+
+	tests := []struct {
+		name        string
+		src         map[string]interface{}
+		want        map[string]interface{}
+		wantDropped []string
+	}{
+		{
+			name: "matching scalars kept",
+			src:  map[string]interface{}{"id": "a1", "tags": []interface{}{"x", "y"}, "createdAt": "2024-06-15T08:00:00Z"},
+			want: map[string]interface{}{"id": "a1", "tags": []interface{}{"x", "y"}, "createdAt": "2024-06-15T08:00:00Z"},
+		},
+		{
+			name:        "renamed field dropped",
+			src:         map[string]interface{}{"id": "a1", "title": "widget"},
+			want:        map[string]interface{}{"id": "a1"},
+			wantDropped: []string{"title: mapping.v1.UpdateItemRequest has no field title"},
+		},
+		{
+			name: "proto names accepted",
+			src:  map[string]interface{}{"created_at": "2024-06-15T08:00:00Z"},
+			want: map[string]interface{}{"createdAt": "2024-06-15T08:00:00Z"},
+		},
+		{
+			name: "type mismatches dropped",
+			src: map[string]interface{}{
+				"count":  float64(3),
+				"active": true,
+				"note":   "hello",
+			},
+			want: map[string]interface{}{},
+			wantDropped: []string{
+				"active: bool does not fit list of bool",
+				"count: int32 does not fit string",
+				"note: string does not fit message google.protobuf.Timestamp",
+			},
+		},
+		{
+			name: "compatible scalar families kept",
+			src:  map[string]interface{}{"price": 1.5, "scores": map[string]interface{}{"a": float64(1)}},
+			want: map[string]interface{}{"price": 1.5, "scores": map[string]interface{}{"a": float64(1)}},
+		},
+		{
+			name: "nested message mapped field by field",
+			src:  map[string]interface{}{"owner": map[string]interface{}{"name": "ann", "age": float64(40), "email": "a@b"}},
+			want: map[string]interface{}{"owner": map[string]interface{}{"name": "ann", "age": float64(40)}},
+			wantDropped: []string{
+				"owner.email: string does not fit bytes",
+			},
+		},
+		{
+			name: "repeated messages mapped per element",
+			src: map[string]interface{}{"owners": []interface{}{
+				map[string]interface{}{"name": "ann", "email": "a@b"},
+				map[string]interface{}{"name": "bob"},
+			}},
+			want: map[string]interface{}{"owners": []interface{}{
+				map[string]interface{}{"name": "ann"},
+				map[string]interface{}{"name": "bob"},
+			}},
+			wantDropped: []string{"owners[0].email: string does not fit bytes"},
+		},
+		{
+			name: "map values mapped per entry",
+			src: map[string]interface{}{"byRole": map[string]interface{}{
+				"lead": map[string]interface{}{"name": "ann", "email": "a@b"},
+			}},
+			want: map[string]interface{}{"byRole": map[string]interface{}{
+				"lead": map[string]interface{}{"name": "ann"},
+			}},
+			wantDropped: []string{"byRole[lead].email: string does not fit bytes"},
+		},
+		{
+			name:        "enum values matched by name",
+			src:         map[string]interface{}{"color": "RED", "colors": []interface{}{"RED", "BLUE"}},
+			want:        map[string]interface{}{"color": "RED"},
+			wantDropped: []string{"colors: mapping.v1.UpdateItemRequest.Color has no value BLUE"},
+		},
+		{
+			name:        "enum value missing from target",
+			src:         map[string]interface{}{"color": "BLUE"},
+			want:        map[string]interface{}{},
+			wantDropped: []string{"color: mapping.v1.UpdateItemRequest.Color has no value BLUE"},
+		},
+		{
+			name:        "unknown source field dropped",
+			src:         map[string]interface{}{"bogus": 1},
+			want:        map[string]interface{}{},
+			wantDropped: []string{"bogus: not a field of mapping.v1.Item"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := MapMessage(tt.src, item, update)
+			assert.Equal(t, tt.want, got)
+			var gotDropped []string
+			for _, d := range dropped {
+				gotDropped = append(gotDropped, d.Error())
+			}
+			assert.Equal(t, tt.wantDropped, gotDropped)
+		})
+	}
+}
+
+func TestMapMessageJSON(t *testing.T) {
+	item, update := mappingMessages(t)
+
+	// The same type is copied verbatim
+	same := `{"id": "a1",   "title": "widget"}`
+	got, dropped, err := MapMessageJSON(same, item, item)
+	require.NoError(t, err)
+	assert.Equal(t, same, got)
+	assert.Empty(t, dropped)
+
+	got, dropped, err = MapMessageJSON(`{"id":"a1","title":"widget","count":12345678901}`, item, update)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"a1"}`, got)
+	require.Len(t, dropped, 2)
+	assert.Equal(t, "count", dropped[0].Path)
+	assert.Equal(t, "title", dropped[1].Path)
+
+	// Numbers keep their exact text
+	got, _, err = MapMessageJSON(`{"scores":{"a":12345678901234567890}}`, item, update)
+	require.NoError(t, err)
+	assert.Contains(t, got, "12345678901234567890")
+
+	_, _, err = MapMessageJSON(`[1]`, item, update)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+// Package protoconv validates and normalizes the protojson string forms of
+// well-known types (Timestamp, Duration, FieldMask), producing field-level
+// errors that name the accepted syntax.
+package protoconv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Well-known type names handled by this package
+const (
+	TimestampName protoreflect.FullName = "google.protobuf.Timestamp"
+	DurationName  protoreflect.FullName = "google.protobuf.Duration"
+	FieldMaskName protoreflect.FullName = "google.protobuf.FieldMask"
+)
+
+// Accepted-syntax hints used in error messages and placeholders
+const (
+	TimestampHint     = "timestamps must be RFC3339, e.g. 2024-06-15T08:00:00Z"
+	DurationHint      = "durations must be seconds with an s suffix, e.g. 1.5s (5m, 1h30m, and 250ms are converted)"
+	FieldMaskHint     = "field masks are comma-separated field paths, e.g. user.display_name,user.email"
+	FieldMaskJSONHint = "field masks in JSON are comma-separated lowerCamelCase paths, e.g. user.displayName,user.email"
+)
+
+// FieldError is a validation failure for a specific field path
+type FieldError struct {
+	Path    string // Dotted field path, e.g. "item.created_at" or "tags[2]"
+	Message string
+	Unknown bool // Path names a field the message does not have
+}
+
+// Error implements error
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// IsSupported reports whether name is a well-known type with a string form
+// handled by this package
+func IsSupported(name protoreflect.FullName) bool {
+	return name == TimestampName || name == DurationName || name == FieldMaskName
+}
+
+// ValidateTimestamp checks s is an RFC3339 timestamp within the range
+// protobuf allows (years 0001-9999). Empty is valid (unset).
+func ValidateTimestamp(s string) error {
+	_, err := NormalizeTimestamp(s)
+	return err
+}
+
+// NormalizeTimestamp returns the canonical protojson form of an RFC3339
+// timestamp (UTC, "Z" suffix). Empty input returns "".
+func NormalizeTimestamp(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+
+	var ts timestamppb.Timestamp
+	if err := protojson.Unmarshal([]byte(strconv.Quote(s)), &ts); err != nil {
+		if _, parseErr := time.Parse(time.RFC3339Nano, s); parseErr == nil {
+			return "", errors.New("timestamp out of range: years must be 0001-9999")
+		}
+		return "", errors.New(TimestampHint)
+	}
+	return marshalString(&ts)
+}
+
+// ValidateDuration checks s is a protojson duration ("1.5s") or a Go
+// duration ("5m", "1h30m", "250ms"). Empty is valid (unset).
+func ValidateDuration(s string) error {
+	_, err := NormalizeDuration(s)
+	return err
+}
+
+// NormalizeDuration converts a protojson duration ("90s", "0.25s") or a
+// Go duration ("1m30s", "250ms") into the canonical protojson form
+// ("90s", "0.250s"). Bare numbers are rejected since the unit is ambiguous.
+// Empty input returns "".
+func NormalizeDuration(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+
+	// Native protojson form first: it covers the full ±10000 year range
+	var dur durationpb.Duration
+	if err := protojson.Unmarshal([]byte(strconv.Quote(s)), &dur); err == nil {
+		return marshalString(&dur)
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return "", fmt.Errorf("duration %q needs a unit — try %ss", s, s)
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", errors.New(DurationHint)
+	}
+	return marshalString(durationpb.New(d))
+}
+
+// ValidateFieldMask checks s is a comma- or newline-separated list of
+// dotted field paths, as typed into a form. Empty is valid.
+func ValidateFieldMask(s string) error {
+	_, err := ParseFieldMask(s)
+	return err
+}
+
+// ParseFieldMask splits a comma- or newline-separated field mask into
+// snake_case paths, converting lowerCamelCase segments ("displayName")
+// to the proto field names protojson expects in FieldMask.paths.
+func ParseFieldMask(s string) ([]string, error) {
+	var paths []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		path := strings.TrimSpace(part)
+		if path == "" {
+			continue
+		}
+		if !validFieldPath(path) {
+			return nil, fmt.Errorf("invalid path %q: %s", path, FieldMaskHint)
+		}
+		paths = append(paths, snakeCase(path))
+	}
+
+	// protojson rejects paths whose camelCase form does not map back
+	if _, err := protojson.Marshal(&fieldmaskpb.FieldMask{Paths: paths}); err != nil {
+		return nil, errors.New(FieldMaskHint)
+	}
+	return paths, nil
+}
+
+// ValidateFieldMaskJSON checks s is a field mask in its protojson string
+// form: comma-separated lowerCamelCase paths.
+func ValidateFieldMaskJSON(s string) error {
+	var mask fieldmaskpb.FieldMask
+	if err := protojson.Unmarshal([]byte(strconv.Quote(s)), &mask); err != nil {
+		return errors.New(FieldMaskJSONHint)
+	}
+	return nil
+}
+
+// validFieldPath reports whether path is a dot-separated list of identifiers.
+func validFieldPath(path string) bool {
+	for _, seg := range strings.Split(path, ".") {
+		if seg == "" {
+			return false
+		}
+		for i, r := range seg {
+			isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+			if !isLetter && (i == 0 || r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// snakeCase converts lowerCamelCase to snake_case ("displayName" to
+// "display_name"); snake_case input is returned unchanged.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// marshalString marshals a well-known type and strips the JSON quotes.
+func marshalString(m proto.Message) (string, error) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Unquote(string(b))
+}
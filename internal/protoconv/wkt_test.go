@@ -0,0 +1,137 @@
+package protoconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"protojson seconds", "90s", "90s", false},
+		{"protojson fractional", "1.5s", "1.500s", false},
+		{"protojson negative", "-2s", "-2s", false},
+		{"minutes", "5m", "300s", false},
+		{"hours and minutes", "1h30m", "5400s", false},
+		{"milliseconds", "250ms", "0.250s", false},
+		{"microseconds", "1500us", "0.001500s", false},
+		{"surrounding whitespace", "  10s ", "10s", false},
+		{"beyond time.Duration range", "315576000000s", "315576000000s", false},
+		{"bare number", "30", "", true},
+		{"unknown unit", "5 minutes", "", true},
+		{"garbage", "soon", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeDuration_BareNumberSuggestsSeconds(t *testing.T) {
+	_, err := NormalizeDuration("30")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "30s")
+}
+
+func TestNormalizeTimestamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"empty", "", "", ""},
+		{"utc", "2024-06-15T08:00:00Z", "2024-06-15T08:00:00Z", ""},
+		{"offset converted to utc", "2024-06-15T10:00:00+02:00", "2024-06-15T08:00:00Z", ""},
+		{"fractional seconds", "2024-06-15T08:00:00.5Z", "2024-06-15T08:00:00.500Z", ""},
+		{"date only", "2024-06-15", "", TimestampHint},
+		{"missing zone", "2024-06-15T08:00:00", "", TimestampHint},
+		{"space separator", "2024-06-15 08:00:00Z", "", TimestampHint},
+		{"unix seconds", "1718438400", "", TimestampHint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTimestamp(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseFieldMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single", "name", []string{"name"}, false},
+		{"comma separated", "name, user.display_name", []string{"name", "user.display_name"}, false},
+		{"newline separated", "name\nuser.email\n", []string{"name", "user.email"}, false},
+		{"camel case converted", "user.displayName", []string{"user.display_name"}, false},
+		{"empty segment", "user..email", nil, true},
+		{"leading digit", "1name", nil, true},
+		{"spaces inside path", "user name", nil, true},
+		{"wildcard", "user.*", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFieldMask(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateFieldMaskJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"camel case paths", "user.displayName,user.email", false},
+		{"snake case rejected", "user.display_name", true},
+		{"invalid path", "user..email", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFieldMaskJSON(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, FieldMaskJSONHint, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFieldError_Error(t *testing.T) {
+	assert.Equal(t, "created_at: "+TimestampHint, FieldError{Path: "created_at", Message: TimestampHint}.Error())
+	assert.Equal(t, "bad", FieldError{Message: "bad"}.Error())
+}
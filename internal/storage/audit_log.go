@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	auditDir  = "audit"
+	auditFile = "audit.jsonl"
+
+	// DefaultAuditMaxSize is the size an audit file may reach before it is
+	// rotated (10 MB).
+	DefaultAuditMaxSize = 10 * 1024 * 1024
+	// DefaultAuditKeep is the number of rotated audit files kept.
+	DefaultAuditKeep = 5
+)
+
+// AuditLog is the audit trail of calls, one JSON record per line in
+// <base>/audit/audit.jsonl. When a write would take the file past its
+// maximum size, the file is rotated first: audit.jsonl becomes
+// audit.jsonl.1, .1 becomes .2 and so on, and files past the number kept
+// are deleted. It is safe for concurrent use; each Write lands whole.
+type AuditLog struct {
+	path    string
+	maxSize int64
+	keep    int
+
+	mu   sync.Mutex
+	file *os.File // nil until the first write
+	size int64
+}
+
+// NewAuditLog creates the audit log under basePath, the same storage
+// directory as NewJSONRepository. Nothing is created until the first write.
+func NewAuditLog(basePath string, maxSize int64, keep int) *AuditLog {
+	return &AuditLog{
+		path:    filepath.Join(basePath, auditDir, auditFile),
+		maxSize: maxSize,
+		keep:    keep,
+	}
+}
+
+// Path returns the path of the current audit file.
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Write appends p, which should be whole lines, to the audit file.
+func (l *AuditLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return 0, err
+		}
+	}
+	if l.size > 0 && l.size+int64(len(p)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// Close closes the audit file. A later Write opens it again.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// open opens the audit file for appending. The caller must hold l.mu.
+func (l *AuditLog) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), dirPermission); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermission)
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts the rotated files along, deleting the oldest, and starts
+// a new audit file. The caller must hold l.mu.
+func (l *AuditLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("close audit file: %w", err)
+	}
+	l.file = nil
+
+	_ = os.Remove(l.rotated(l.keep))
+	for i := l.keep - 1; i >= 1; i-- {
+		_ = os.Rename(l.rotated(i), l.rotated(i+1))
+	}
+	if l.keep > 0 {
+		if err := os.Rename(l.path, l.rotated(1)); err != nil {
+			return fmt.Errorf("rotate audit file: %w", err)
+		}
+	} else if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("rotate audit file: %w", err)
+	}
+	return l.open()
+}
+
+// rotated returns the path of the n-th rotated file, 1 being the newest.
+func (l *AuditLog) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func readAuditFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) failed: %v", filepath.Base(path), err)
+	}
+	return string(data)
+}
+
+func TestAuditLog_AppendsAcrossSessions(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir, 1024, 2)
+	if _, err := os.Stat(log.Path()); !os.IsNotExist(err) {
+		t.Fatalf("audit file exists before the first write: %v", err)
+	}
+
+	if _, err := log.Write([]byte("{\"n\":1}\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// A later session appends to the same file
+	log = NewAuditLog(dir, 1024, 2)
+	if _, err := log.Write([]byte("{\"n\":2}\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	log.Close()
+
+	if got := readAuditFile(t, filepath.Join(dir, auditDir, auditFile)); got != "{\"n\":1}\n{\"n\":2}\n" {
+		t.Errorf("audit file = %q", got)
+	}
+	info, err := os.Stat(log.Path())
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != filePermission {
+		t.Errorf("permissions = %o, want %o", perm, filePermission)
+	}
+}
+
+func TestAuditLog_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir, 20, 2)
+	defer log.Close()
+
+	// Each line is 10 bytes, so every file holds two
+	for i := range 7 {
+		if _, err := log.Write([]byte(fmt.Sprintf("line %04d\n", i))); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+
+	want := map[string]string{
+		auditFile:        "line 0006\n",
+		auditFile + ".1": "line 0004\nline 0005\n",
+		auditFile + ".2": "line 0002\nline 0003\n",
+	}
+	for name, content := range want {
+		if got := readAuditFile(t, filepath.Join(dir, auditDir, name)); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, auditDir, auditFile+".3")); !os.IsNotExist(err) {
+		t.Errorf("a third rotated file was kept: %v", err)
+	}
+}
+
+func TestAuditLog_OversizedWrite(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir, 8, 1)
+	defer log.Close()
+
+	// A record larger than the limit still lands whole, in a file of its own
+	long := strings.Repeat("x", 20) + "\n"
+	for _, line := range []string{"a\n", long, "b\n"} {
+		if _, err := log.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if got := readAuditFile(t, log.Path()); got != "b\n" {
+		t.Errorf("current file = %q", got)
+	}
+	if got := readAuditFile(t, log.Path()+".1"); got != long {
+		t.Errorf("rotated file = %q", got)
+	}
+}
+
+func TestAuditLog_ConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir, 1<<20, 1)
+	defer log.Close()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			if _, err := log.Write([]byte(fmt.Sprintf("{\"n\":%d}\n", i))); err != nil {
+				t.Errorf("Write failed: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(readAuditFile(t, log.Path()), "\n"), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("got %d lines, want 50", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{\"n\":") || !strings.HasSuffix(line, "}") {
+			t.Errorf("interleaved line %q", line)
+		}
+	}
+}
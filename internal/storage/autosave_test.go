@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+)
+
+func TestAutosave_SaveLoadClear(t *testing.T) {
+	repos := map[string]func(t *testing.T) Repository{
+		"json": func(t *testing.T) Repository {
+			return NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+		},
+		"memory": func(t *testing.T) Repository {
+			return NewMemoryRepository()
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			got, err := repo.LoadAutosave()
+			if err != nil || got != nil {
+				t.Fatalf("LoadAutosave() on empty = %v, %v; want nil, nil", got, err)
+			}
+
+			savedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+			ws := domain.Workspace{Name: "dev", SavedAt: savedAt, SelectedService: "pkg.Svc", SelectedMethod: "Get"}
+			if err := repo.SaveAutosave(ws); err != nil {
+				t.Fatalf("SaveAutosave failed: %v", err)
+			}
+			got, err = repo.LoadAutosave()
+			if err != nil || got == nil {
+				t.Fatalf("LoadAutosave() = %v, %v", got, err)
+			}
+			if got.Name != "dev" || !got.SavedAt.Equal(savedAt) || got.SelectedMethod != "Get" {
+				t.Errorf("LoadAutosave() = %+v, want %+v", *got, ws)
+			}
+
+			// The autosave is not one of the named workspaces
+			names, _ := repo.ListWorkspaces()
+			if len(names) != 0 {
+				t.Errorf("ListWorkspaces() = %v, want none", names)
+			}
+
+			if err := repo.ClearAutosave(); err != nil {
+				t.Fatalf("ClearAutosave failed: %v", err)
+			}
+			if got, _ := repo.LoadAutosave(); got != nil {
+				t.Errorf("LoadAutosave() after clear = %+v, want nil", *got)
+			}
+			if err := repo.ClearAutosave(); err != nil {
+				t.Errorf("ClearAutosave() twice failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestAutosave_CorruptFileSetAside(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewJSONRepository(dir, logging.NewNopLogger())
+	if err := os.WriteFile(filepath.Join(dir, autosaveFile), []byte("{not json"), filePermission); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.LoadAutosave()
+	if err != nil || got != nil {
+		t.Fatalf("LoadAutosave() = %v, %v; want nil, nil", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, autosaveFile)); !os.IsNotExist(err) {
+		t.Errorf("corrupt autosave was not set aside: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const descriptorsDir = "descriptors"
+
+// DescriptorCache stores the FileDescriptorSet fetched from each server over
+// reflection, one file per server address under <base>/descriptors. The
+// file name carries a hash of the server's service list, so an entry only
+// matches while the server lists the same services.
+type DescriptorCache struct {
+	dir    string
+	logger *slog.Logger
+}
+
+// NewDescriptorCache creates a descriptor cache under basePath, the same
+// storage directory as NewJSONRepository.
+func NewDescriptorCache(basePath string, logger *slog.Logger) *DescriptorCache {
+	return &DescriptorCache{
+		dir:    filepath.Join(basePath, descriptorsDir),
+		logger: logger,
+	}
+}
+
+// Load returns the files cached for address under servicesHash, or nil when
+// there is no entry or it was cached for a different service list. A
+// corrupt entry is set aside and treated as a miss.
+func (c *DescriptorCache) Load(address, servicesHash string) ([]*descriptorpb.FileDescriptorProto, error) {
+	path := c.path(address, servicesHash)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read descriptor cache: %w", err)
+	}
+
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &fds); err != nil {
+		setAsideCorruptFile(path, err, c.logger)
+		return nil, nil
+	}
+	if len(fds.GetFile()) == 0 {
+		setAsideCorruptFile(path, errors.New("descriptor cache contains no files"), c.logger)
+		return nil, nil
+	}
+	return fds.GetFile(), nil
+}
+
+// Save writes files as the entry for address, replacing any entry cached
+// for a different service list.
+func (c *DescriptorCache) Save(address, servicesHash string, files []*descriptorpb.FileDescriptorProto) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no descriptors to cache for %s", address)
+	}
+	if err := os.MkdirAll(c.dir, dirPermission); err != nil {
+		return fmt.Errorf("create descriptor cache directory: %w", err)
+	}
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: files})
+	if err != nil {
+		return fmt.Errorf("marshal descriptor cache: %w", err)
+	}
+
+	path := c.path(address, servicesHash)
+	if err := atomicWriteFile(path, data, filePermission); err != nil {
+		return fmt.Errorf("write descriptor cache: %w", err)
+	}
+	return c.removeEntries(address, path)
+}
+
+// Invalidate removes the entry for address. It succeeds when there is none.
+func (c *DescriptorCache) Invalidate(address string) error {
+	return c.removeEntries(address, "")
+}
+
+// removeEntries removes every cache file for address except keep.
+func (c *DescriptorCache) removeEntries(address, keep string) error {
+	matches, err := filepath.Glob(filepath.Join(c.dir, fileKey(address)+"-*.pb"))
+	if err != nil {
+		return fmt.Errorf("list descriptor cache: %w", err)
+	}
+	for _, m := range matches {
+		if m == keep {
+			continue
+		}
+		if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove descriptor cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// path returns the cache file for address and servicesHash, named
+// <address key>-<hash key>.pb.
+func (c *DescriptorCache) path(address, servicesHash string) string {
+	return filepath.Join(c.dir, fileKey(address)+"-"+fileKey(servicesHash)+".pb")
+}
+
+// fileKey turns s, which may hold characters that are not valid in file
+// names (an address has ":" and maybe "/"), into a fixed-length name part.
+func fileKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
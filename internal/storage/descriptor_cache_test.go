@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shhac/grotto/internal/logging"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func testDescriptorFiles(names ...string) []*descriptorpb.FileDescriptorProto {
+	files := make([]*descriptorpb.FileDescriptorProto, len(names))
+	for i, name := range names {
+		files[i] = &descriptorpb.FileDescriptorProto{Name: proto.String(name), Package: proto.String("test")}
+	}
+	return files
+}
+
+func cacheFileNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(dir, descriptorsDir))
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestDescriptorCache_WriteRead(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewDescriptorCache(dir, logging.NewNopLogger())
+
+	files, err := cache.Load("localhost:50051", "hash-1")
+	if err != nil || files != nil {
+		t.Fatalf("Load on empty cache = %v, %v; want nil, nil", files, err)
+	}
+
+	if err := cache.Save("localhost:50051", "hash-1", testDescriptorFiles("a.proto", "b.proto")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A new cache on the same directory, as in a later session
+	files, err = NewDescriptorCache(dir, logging.NewNopLogger()).Load("localhost:50051", "hash-1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(files) != 2 || files[0].GetName() != "a.proto" || files[1].GetName() != "b.proto" {
+		t.Errorf("Load = %v, want a.proto and b.proto", files)
+	}
+
+	// Another service list or another server misses
+	if files, _ := cache.Load("localhost:50051", "hash-2"); files != nil {
+		t.Errorf("Load with a different hash = %v, want nil", files)
+	}
+	if files, _ := cache.Load("localhost:50052", "hash-1"); files != nil {
+		t.Errorf("Load for a different address = %v, want nil", files)
+	}
+
+	info, err := os.Stat(cache.path("localhost:50051", "hash-1"))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != filePermission {
+		t.Errorf("permissions = %o, want %o", perm, filePermission)
+	}
+}
+
+func TestDescriptorCache_SaveReplacesOtherHash(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewDescriptorCache(dir, logging.NewNopLogger())
+
+	if err := cache.Save("localhost:50051", "hash-1", testDescriptorFiles("old.proto")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := cache.Save("other:443", "hash-1", testDescriptorFiles("other.proto")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := cache.Save("localhost:50051", "hash-2", testDescriptorFiles("new.proto")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if files, _ := cache.Load("localhost:50051", "hash-1"); files != nil {
+		t.Errorf("stale entry still loads: %v", files)
+	}
+	if files, _ := cache.Load("localhost:50051", "hash-2"); len(files) != 1 {
+		t.Errorf("Load = %v, want new.proto", files)
+	}
+	if names := cacheFileNames(t, dir); len(names) != 2 {
+		t.Errorf("cache files = %v, want one per address", names)
+	}
+}
+
+func TestDescriptorCache_Invalidate(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewDescriptorCache(dir, logging.NewNopLogger())
+
+	// Nothing cached yet
+	if err := cache.Invalidate("localhost:50051"); err != nil {
+		t.Fatalf("Invalidate on empty cache failed: %v", err)
+	}
+
+	if err := cache.Save("localhost:50051", "hash-1", testDescriptorFiles("a.proto")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := cache.Save("other:443", "hash-1", testDescriptorFiles("b.proto")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := cache.Invalidate("localhost:50051"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if files, _ := cache.Load("localhost:50051", "hash-1"); files != nil {
+		t.Errorf("Load after Invalidate = %v, want nil", files)
+	}
+	if files, _ := cache.Load("other:443", "hash-1"); len(files) != 1 {
+		t.Errorf("other server's entry was dropped: %v", files)
+	}
+}
+
+func TestDescriptorCache_CorruptFileRecovery(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not a descriptor set", []byte("{not protobuf")},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cache := NewDescriptorCache(dir, logging.NewNopLogger())
+			if err := os.MkdirAll(filepath.Join(dir, descriptorsDir), dirPermission); err != nil {
+				t.Fatalf("MkdirAll failed: %v", err)
+			}
+			path := cache.path("localhost:50051", "hash-1")
+			if err := os.WriteFile(path, tt.data, filePermission); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+
+			files, err := cache.Load("localhost:50051", "hash-1")
+			if err != nil || files != nil {
+				t.Fatalf("Load = %v, %v; want a miss", files, err)
+			}
+
+			// The corrupt file is set aside for inspection
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("corrupt cache file still in place")
+			}
+			names := cacheFileNames(t, dir)
+			if len(names) != 1 || !strings.Contains(names[0], ".corrupt.") {
+				t.Errorf("cache files = %v, want one .corrupt backup", names)
+			}
+
+			// And the entry can be written again
+			if err := cache.Save("localhost:50051", "hash-1", testDescriptorFiles("a.proto")); err != nil {
+				t.Fatalf("Save after corruption failed: %v", err)
+			}
+			if files, _ := cache.Load("localhost:50051", "hash-1"); len(files) != 1 {
+				t.Errorf("Load after re-save = %v, want a.proto", files)
+			}
+		})
+	}
+}
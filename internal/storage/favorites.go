@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+)
+
+// setFavorites sets the pinned methods of address in favorites, removing
+// the address when none are left
+func setFavorites(favorites map[string][]string, address string, methods []string) error {
+	if address == "" {
+		return errors.New("favorites need an address")
+	}
+	if len(methods) == 0 {
+		delete(favorites, address)
+		return nil
+	}
+	favorites[address] = slices.Clone(methods)
+	return nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shhac/grotto/internal/logging"
+)
+
+func TestFavorites_RoundTrip(t *testing.T) {
+	repos := map[string]func(t *testing.T) Repository{
+		"json": func(t *testing.T) Repository {
+			return NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+		},
+		"memory": func(t *testing.T) Repository {
+			return NewMemoryRepository()
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			got, err := repo.GetFavorites("localhost:50051")
+			if err != nil {
+				t.Fatalf("GetFavorites failed: %v", err)
+			}
+			if len(got) != 0 {
+				t.Errorf("GetFavorites() = %v before any were set, want none", got)
+			}
+
+			pinned := []string{getUser, createUser}
+			if err := repo.SetFavorites("localhost:50051", pinned); err != nil {
+				t.Fatalf("SetFavorites failed: %v", err)
+			}
+			if err := repo.SetFavorites("prod:443", []string{createUser}); err != nil {
+				t.Fatalf("SetFavorites failed: %v", err)
+			}
+			pinned[0] = "mutated" // The repository keeps its own copy
+
+			got, err = repo.GetFavorites("localhost:50051")
+			if err != nil {
+				t.Fatalf("GetFavorites failed: %v", err)
+			}
+			if want := []string{getUser, createUser}; !reflect.DeepEqual(got, want) {
+				t.Errorf("GetFavorites() = %v, want %v in the order pinned", got, want)
+			}
+
+			// Setting none forgets the address, leaving the others
+			if err := repo.SetFavorites("localhost:50051", nil); err != nil {
+				t.Fatalf("SetFavorites(nil) failed: %v", err)
+			}
+			got, _ = repo.GetFavorites("localhost:50051")
+			if len(got) != 0 {
+				t.Errorf("GetFavorites() = %v after clearing, want none", got)
+			}
+			got, _ = repo.GetFavorites("prod:443")
+			if want := []string{createUser}; !reflect.DeepEqual(got, want) {
+				t.Errorf("GetFavorites(other address) = %v, want %v", got, want)
+			}
+
+			if err := repo.SetFavorites("", []string{getUser}); err == nil {
+				t.Error("SetFavorites without an address succeeded")
+			}
+		})
+	}
+}
+
+func TestFavorites_PersistAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewJSONRepository(dir, logging.NewNopLogger()).SetFavorites("localhost:50051", []string{getUser}); err != nil {
+		t.Fatalf("SetFavorites failed: %v", err)
+	}
+
+	got, err := NewJSONRepository(dir, logging.NewNopLogger()).GetFavorites("localhost:50051")
+	if err != nil {
+		t.Fatalf("GetFavorites failed: %v", err)
+	}
+	if want := []string{getUser}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFavorites() = %v, want %v", got, want)
+	}
+}
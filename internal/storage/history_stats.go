@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// maxStatusBuckets bounds the number of buckets one query may produce
+const maxStatusBuckets = 1000
+
+// historySummary is the subset of a history entry needed for aggregation.
+// Decoding history into it skips the request and response payloads.
+type historySummary struct {
+	Timestamp time.Time `json:"timestamp"`
+	Method    string    `json:"method"`
+	Status    string    `json:"status"`
+	Code      string    `json:"code,omitempty"`
+	Error     string    `json:"error"`
+}
+
+// summarize reduces a full history entry to its aggregation fields.
+func summarize(entry domain.HistoryEntry) historySummary {
+	return historySummary{
+		Timestamp: entry.Timestamp,
+		Method:    entry.Method,
+		Status:    entry.Status,
+		Code:      entry.Code,
+		Error:     entry.Error,
+	}
+}
+
+// aggregateStatusBuckets counts summaries by time bucket and status code.
+// Every bucket overlapping [Since, Until) is returned in order, including
+// empty ones; the first bucket may start before Since since boundaries are
+// aligned to the bucket width in the query's time zone.
+func aggregateStatusBuckets(summaries []historySummary, q domain.HistoryStatsQuery) ([]domain.StatusBucket, error) {
+	if q.Bucket < time.Second {
+		return nil, errors.New("bucket width must be at least one second")
+	}
+	if !q.Until.After(q.Since) {
+		return nil, errors.New("until must be after since")
+	}
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var buckets []domain.StatusBucket
+	for start := bucketStart(q.Since, q.Bucket, loc); start.Before(q.Until); {
+		if len(buckets) == maxStatusBuckets {
+			return nil, errors.New("too many buckets: use a wider bucket or shorter window")
+		}
+		end := nextBucketStart(start, q.Bucket, loc)
+		buckets = append(buckets, domain.StatusBucket{Start: start, End: end, Counts: map[string]int{}})
+		start = end
+	}
+
+	for _, s := range summaries {
+		if s.Timestamp.Before(q.Since) || !s.Timestamp.Before(q.Until) {
+			continue
+		}
+		if q.Method != "" && s.Method != q.Method {
+			continue
+		}
+		i := sort.Search(len(buckets), func(i int) bool { return buckets[i].End.After(s.Timestamp) })
+		if i == len(buckets) {
+			continue
+		}
+		entry := domain.HistoryEntry{Status: s.Status, Code: s.Code, Error: s.Error}
+		buckets[i].Counts[entry.StatusCode()]++
+	}
+	return buckets, nil
+}
+
+// bucketStart returns the start of the bucket containing t. Widths that are
+// whole days align to local midnight (so DST days are 23 or 25 hours);
+// shorter widths align to the local wall clock, so hourly buckets start on
+// the hour even in zones with half-hour offsets.
+func bucketStart(t time.Time, width time.Duration, loc *time.Location) time.Time {
+	t = t.In(loc)
+	if width%(24*time.Hour) == 0 {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+
+	_, offset := t.Zone()
+	wall := t.Unix() + int64(offset)
+	w := int64(width / time.Second)
+	wall -= ((wall % w) + w) % w
+	return time.Unix(wall-int64(offset), 0).In(loc)
+}
+
+// nextBucketStart returns the start of the bucket after the one at start.
+func nextBucketStart(start time.Time, width time.Duration, loc *time.Location) time.Time {
+	if width%(24*time.Hour) == 0 {
+		return start.AddDate(0, 0, int(width/(24*time.Hour)))
+	}
+	next := bucketStart(start.Add(width), width, loc)
+	if !next.After(start) {
+		// An offset change larger than the width: fall back to absolute time
+		next = start.Add(width)
+	}
+	return next
+}
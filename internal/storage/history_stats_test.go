@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+)
+
+const getEvents = "events.v1.EventService/GetEvents"
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func seedEntry(ts time.Time, method, code string) domain.HistoryEntry {
+	entry := domain.HistoryEntry{
+		ID:        ts.Format(time.RFC3339Nano) + method,
+		Timestamp: ts,
+		Method:    method,
+		Status:    "success",
+		Code:      code,
+		Request:   `{"payload":"large request body"}`,
+		Response:  `{"payload":"large response body"}`,
+	}
+	if code != "OK" {
+		entry.Status = "error"
+	}
+	return entry
+}
+
+// bucketCounts flattens buckets to their counts for comparison.
+func bucketCounts(buckets []domain.StatusBucket) []map[string]int {
+	out := make([]map[string]int, len(buckets))
+	for i, b := range buckets {
+		out[i] = b.Counts
+	}
+	return out
+}
+
+func TestHistoryStatusBuckets_BucketBoundaries(t *testing.T) {
+	base := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	entries := []domain.HistoryEntry{
+		seedEntry(base.Add(-time.Second), getEvents, "OK"),                           // before window
+		seedEntry(base, getEvents, "OK"),                                             // first instant of bucket 0
+		seedEntry(base.Add(5*time.Minute-time.Nanosecond), getEvents, "Unavailable"), // last instant of bucket 0
+		seedEntry(base.Add(5*time.Minute), getEvents, "Unavailable"),                 // first instant of bucket 1
+		seedEntry(base.Add(7*time.Minute), "other.Service/Call", "Internal"),         // other method
+		seedEntry(base.Add(14*time.Minute), getEvents, "DeadlineExceeded"),
+		seedEntry(base.Add(15*time.Minute), getEvents, "OK"), // at Until: excluded
+	}
+
+	repos := map[string]Repository{
+		"memory": NewMemoryRepository(),
+		"json":   NewJSONRepository(t.TempDir(), logging.NewNopLogger()),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			for _, e := range entries {
+				if err := repo.AddHistoryEntry(e); err != nil {
+					t.Fatalf("AddHistoryEntry: %v", err)
+				}
+			}
+
+			buckets, err := repo.HistoryStatusBuckets(domain.HistoryStatsQuery{
+				Method: getEvents,
+				Since:  base,
+				Until:  base.Add(15 * time.Minute),
+				Bucket: 5 * time.Minute,
+			})
+			if err != nil {
+				t.Fatalf("HistoryStatusBuckets: %v", err)
+			}
+
+			want := []map[string]int{
+				{"OK": 1, "Unavailable": 1},
+				{"Unavailable": 1},
+				{"DeadlineExceeded": 1},
+			}
+			if got := bucketCounts(buckets); !reflect.DeepEqual(got, want) {
+				t.Errorf("counts = %v, want %v", got, want)
+			}
+			for i, b := range buckets {
+				wantStart := base.Add(time.Duration(i) * 5 * time.Minute)
+				if !b.Start.Equal(wantStart) || !b.End.Equal(wantStart.Add(5*time.Minute)) {
+					t.Errorf("bucket %d = [%v, %v), want start %v", i, b.Start, b.End, wantStart)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryStatusBuckets_AllMethodsAndLegacyEntries(t *testing.T) {
+	base := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	repo := NewMemoryRepository()
+	legacy := []domain.HistoryEntry{
+		{Timestamp: base, Method: getEvents, Status: "success"},
+		{Timestamp: base, Method: getEvents, Status: "error", Error: "rpc error: code = NotFound desc = no such event"},
+		{Timestamp: base, Method: "other.Service/Call", Status: "error", Error: "connection refused"},
+		{Timestamp: base, Method: "other.Service/Stream", Status: "ERROR"},
+	}
+	for _, e := range legacy {
+		_ = repo.AddHistoryEntry(e)
+	}
+
+	buckets, err := repo.HistoryStatusBuckets(domain.HistoryStatsQuery{
+		Since:  base,
+		Until:  base.Add(time.Hour),
+		Bucket: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("HistoryStatusBuckets: %v", err)
+	}
+	want := []map[string]int{{"OK": 1, "NotFound": 1, "Unknown": 2}}
+	if got := bucketCounts(buckets); !reflect.DeepEqual(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+	if total := buckets[0].Total(); total != 4 {
+		t.Errorf("Total() = %d, want 4", total)
+	}
+}
+
+func TestHistoryStatusBuckets_EmptyBucketsAndAlignment(t *testing.T) {
+	since := time.Date(2024, 6, 15, 10, 7, 30, 0, time.UTC)
+	buckets, err := NewMemoryRepository().HistoryStatusBuckets(domain.HistoryStatsQuery{
+		Since:  since,
+		Until:  since.Add(time.Hour),
+		Bucket: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("HistoryStatusBuckets: %v", err)
+	}
+	// 10:05 through 11:05: the first bucket starts before Since
+	if len(buckets) != 13 {
+		t.Fatalf("got %d buckets, want 13", len(buckets))
+	}
+	if want := time.Date(2024, 6, 15, 10, 5, 0, 0, time.UTC); !buckets[0].Start.Equal(want) {
+		t.Errorf("first bucket starts %v, want %v", buckets[0].Start, want)
+	}
+	for i, b := range buckets {
+		if b.Total() != 0 {
+			t.Errorf("bucket %d has %d calls, want 0", i, b.Total())
+		}
+	}
+}
+
+func TestHistoryStatusBuckets_HalfHourOffsetZone(t *testing.T) {
+	kolkata := mustLoadLocation(t, "Asia/Kolkata") // UTC+05:30
+	since := time.Date(2024, 6, 15, 9, 0, 0, 0, kolkata)
+	repo := NewMemoryRepository()
+	_ = repo.AddHistoryEntry(seedEntry(time.Date(2024, 6, 15, 9, 59, 59, 0, kolkata), getEvents, "OK"))
+	_ = repo.AddHistoryEntry(seedEntry(time.Date(2024, 6, 15, 10, 0, 0, 0, kolkata), getEvents, "Internal"))
+
+	buckets, err := repo.HistoryStatusBuckets(domain.HistoryStatsQuery{
+		Since:    since,
+		Until:    since.Add(2 * time.Hour),
+		Bucket:   time.Hour,
+		Location: kolkata,
+	})
+	if err != nil {
+		t.Fatalf("HistoryStatusBuckets: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("got %d buckets, want 2", len(buckets))
+	}
+	// Buckets follow the local hour, not the UTC hour (which falls on :30 local)
+	if h, m, _ := buckets[1].Start.In(kolkata).Clock(); h != 10 || m != 0 {
+		t.Errorf("second bucket starts %02d:%02d local, want 10:00", h, m)
+	}
+	want := []map[string]int{{"OK": 1}, {"Internal": 1}}
+	if got := bucketCounts(buckets); !reflect.DeepEqual(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryStatusBuckets_DayBucketsAcrossDST(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	// DST ends 2024-11-03 at 02:00 local: that day is 25 hours long
+	since := time.Date(2024, 11, 2, 0, 0, 0, 0, ny)
+	until := time.Date(2024, 11, 5, 0, 0, 0, 0, ny)
+	repo := NewMemoryRepository()
+	_ = repo.AddHistoryEntry(seedEntry(time.Date(2024, 11, 2, 23, 59, 0, 0, ny), getEvents, "OK"))
+	_ = repo.AddHistoryEntry(seedEntry(time.Date(2024, 11, 3, 23, 30, 0, 0, ny), getEvents, "Unavailable"))
+	_ = repo.AddHistoryEntry(seedEntry(time.Date(2024, 11, 4, 0, 0, 0, 0, ny), getEvents, "OK"))
+
+	buckets, err := repo.HistoryStatusBuckets(domain.HistoryStatsQuery{
+		Since:    since,
+		Until:    until,
+		Bucket:   24 * time.Hour,
+		Location: ny,
+	})
+	if err != nil {
+		t.Fatalf("HistoryStatusBuckets: %v", err)
+	}
+	if len(buckets) != 3 {
+		t.Fatalf("got %d buckets, want 3", len(buckets))
+	}
+	wantHours := []float64{24, 25, 24}
+	for i, b := range buckets {
+		if h, m, _ := b.Start.In(ny).Clock(); h != 0 || m != 0 {
+			t.Errorf("bucket %d starts %02d:%02d local, want midnight", i, h, m)
+		}
+		if got := b.End.Sub(b.Start).Hours(); got != wantHours[i] {
+			t.Errorf("bucket %d spans %vh, want %vh", i, got, wantHours[i])
+		}
+	}
+	want := []map[string]int{{"OK": 1}, {"Unavailable": 1}, {"OK": 1}}
+	if got := bucketCounts(buckets); !reflect.DeepEqual(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryStatusBuckets_InvalidQuery(t *testing.T) {
+	repo := NewMemoryRepository()
+	now := time.Now()
+	queries := map[string]domain.HistoryStatsQuery{
+		"zero bucket":      {Since: now.Add(-time.Hour), Until: now},
+		"reversed window":  {Since: now, Until: now.Add(-time.Hour), Bucket: time.Minute},
+		"too many buckets": {Since: now.Add(-24 * time.Hour), Until: now, Bucket: time.Second},
+	}
+	for name, q := range queries {
+		t.Run(name, func(t *testing.T) {
+			if _, err := repo.HistoryStatusBuckets(q); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
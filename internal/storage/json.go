@@ -16,7 +16,11 @@ const (
 	workspacesDir  = "workspaces"
 	recentFile     = "recent.json"
 	historyFile    = "history.json"
-	maxRecent      = 10
+	requestsFile   = "requests.json"
+	autosaveFile   = "autosave.json"
+	statsFile      = "stats.json"
+	favoritesFile  = "favorites.json"
+	maxRecent      = 15
 	maxHistory     = 100
 	filePermission = 0600
 	dirPermission  = 0700
@@ -203,6 +207,71 @@ func (r *JSONRepository) DeleteWorkspace(name string) error {
 	return nil
 }
 
+// SaveAutosave replaces the autosaved workspace
+func (r *JSONRepository) SaveAutosave(workspace domain.Workspace) error {
+	if err := r.ensureBaseDir(); err != nil {
+		return fmt.Errorf("ensure base directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal autosave: %w", err)
+	}
+
+	wrapped, err := wrapVersioned(data)
+	if err != nil {
+		return fmt.Errorf("wrap autosave version: %w", err)
+	}
+
+	path := r.autosavePath()
+	if err := atomicWriteFile(path, wrapped, filePermission); err != nil {
+		return fmt.Errorf("write autosave file: %w", err)
+	}
+
+	r.logger.Debug("saved autosave",
+		slog.String("workspace", workspace.Name),
+		slog.String("path", path))
+
+	return nil
+}
+
+// LoadAutosave returns the autosaved workspace, or nil if there is none.
+// A corrupt autosave is set aside and treated as missing.
+func (r *JSONRepository) LoadAutosave() (*domain.Workspace, error) {
+	path := r.autosavePath()
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read autosave file: %w", err)
+	}
+
+	_, data, err := unwrapVersioned(fileData)
+	if err != nil {
+		r.handleCorruptFile(path, err)
+		return nil, nil
+	}
+
+	var workspace domain.Workspace
+	if err := json.Unmarshal(data, &workspace); err != nil {
+		r.handleCorruptFile(path, err)
+		return nil, nil
+	}
+
+	return &workspace, nil
+}
+
+// ClearAutosave removes the autosaved workspace
+func (r *JSONRepository) ClearAutosave() error {
+	if err := os.Remove(r.autosavePath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete autosave file: %w", err)
+	}
+
+	r.logger.Debug("cleared autosave")
+	return nil
+}
+
 // SaveRecentConnection adds a connection to recent list
 func (r *JSONRepository) SaveRecentConnection(conn domain.Connection) error {
 	if err := r.ensureBaseDir(); err != nil {
@@ -353,20 +422,30 @@ func (r *JSONRepository) verifyPathInWorkspacesDir(path string) error {
 	return nil
 }
 
+func (r *JSONRepository) autosavePath() string {
+	return filepath.Join(r.basePath, autosaveFile)
+}
+
 func (r *JSONRepository) recentPath() string {
 	return filepath.Join(r.basePath, recentFile)
 }
 
 // handleCorruptFile renames a corrupt file to <path>.corrupt.<timestamp> and logs a warning.
 func (r *JSONRepository) handleCorruptFile(path string, err error) {
+	setAsideCorruptFile(path, err, r.logger)
+}
+
+// setAsideCorruptFile renames a corrupt file to <path>.corrupt.<timestamp>
+// so the next write starts fresh while the original is kept for inspection.
+func setAsideCorruptFile(path string, err error, logger *slog.Logger) {
 	backupPath := fmt.Sprintf("%s.corrupt.%d", path, time.Now().Unix())
 	if renameErr := os.Rename(path, backupPath); renameErr != nil {
-		r.logger.Error("failed to rename corrupt file",
+		logger.Error("failed to rename corrupt file",
 			slog.String("path", path),
 			slog.Any("error", renameErr))
 		return
 	}
-	r.logger.Warn("recovered from corrupt file",
+	logger.Warn("recovered from corrupt file",
 		slog.String("path", path),
 		slog.String("backup", backupPath),
 		slog.Any("original_error", err))
@@ -421,11 +500,12 @@ func (r *JSONRepository) removeDuplicate(recent []domain.Connection, conn domain
 	return removeDuplicateConnection(recent, conn)
 }
 
-// removeDuplicateConnection removes a connection from the list by matching address and TLS.
+// removeDuplicateConnection removes earlier entries for the connection's
+// address, so each address is listed once with the settings last used.
 func removeDuplicateConnection(recent []domain.Connection, conn domain.Connection) []domain.Connection {
 	var filtered []domain.Connection
 	for _, r := range recent {
-		if r.Address != conn.Address || r.TLS.Enabled != conn.TLS.Enabled {
+		if r.Address != conn.Address {
 			filtered = append(filtered, r)
 		}
 	}
@@ -514,6 +594,39 @@ func (r *JSONRepository) ClearHistory() error {
 	return nil
 }
 
+// HistoryStatusBuckets counts history entries by time bucket and status
+// code, decoding only the timestamp, method, and status of each entry
+func (r *JSONRepository) HistoryStatusBuckets(query domain.HistoryStatsQuery) ([]domain.StatusBucket, error) {
+	summaries, err := r.loadHistorySummaries()
+	if err != nil {
+		return nil, fmt.Errorf("load history: %w", err)
+	}
+	return aggregateStatusBuckets(summaries, query)
+}
+
+// loadHistorySummaries loads the aggregation fields of every history entry.
+// A corrupt file yields no entries; loadHistoryList handles recovery.
+func (r *JSONRepository) loadHistorySummaries() ([]historySummary, error) {
+	fileData, err := os.ReadFile(r.historyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history file: %w", err)
+	}
+
+	_, data, err := unwrapVersioned(fileData)
+	if err != nil {
+		return nil, nil
+	}
+
+	var summaries []historySummary
+	if err := json.Unmarshal(data, &summaries); err != nil {
+		return nil, nil
+	}
+	return summaries, nil
+}
+
 // historyPath returns the path to the history file
 func (r *JSONRepository) historyPath() string {
 	return filepath.Join(r.basePath, historyFile)
@@ -565,3 +678,297 @@ func (r *JSONRepository) saveHistoryList(history []domain.HistoryEntry) error {
 
 	return nil
 }
+
+// SaveRequest adds a request to the saved requests library, replacing any
+// request with the same method and name
+func (r *JSONRepository) SaveRequest(req domain.SavedRequest) error {
+	if err := validateSavedRequest(req); err != nil {
+		return err
+	}
+
+	if err := r.ensureBaseDir(); err != nil {
+		return fmt.Errorf("ensure base directory: %w", err)
+	}
+
+	saved, err := r.loadSavedRequestList()
+	if err != nil {
+		return fmt.Errorf("load saved requests: %w", err)
+	}
+
+	if err := r.saveSavedRequestList(upsertSavedRequest(saved, req)); err != nil {
+		return fmt.Errorf("save saved requests: %w", err)
+	}
+
+	r.logger.Debug("saved request",
+		slog.String("method", req.Request.Method),
+		slog.String("name", req.Name))
+
+	return nil
+}
+
+// GetSavedRequests returns the saved requests for a method sorted by name,
+// or every saved request when method is empty
+func (r *JSONRepository) GetSavedRequests(method string) ([]domain.SavedRequest, error) {
+	saved, err := r.loadSavedRequestList()
+	if err != nil {
+		return nil, fmt.Errorf("load saved requests: %w", err)
+	}
+
+	return filterSavedRequests(saved, method), nil
+}
+
+// DeleteSavedRequest removes a request from the saved requests library
+func (r *JSONRepository) DeleteSavedRequest(method, name string) error {
+	saved, err := r.loadSavedRequestList()
+	if err != nil {
+		return fmt.Errorf("load saved requests: %w", err)
+	}
+
+	saved, found := removeSavedRequest(saved, method, name)
+	if !found {
+		return nil // Not found — idempotent
+	}
+
+	if err := r.saveSavedRequestList(saved); err != nil {
+		return fmt.Errorf("save saved requests: %w", err)
+	}
+
+	r.logger.Debug("deleted saved request",
+		slog.String("method", method),
+		slog.String("name", name))
+
+	return nil
+}
+
+// requestsPath returns the path to the saved requests file
+func (r *JSONRepository) requestsPath() string {
+	return filepath.Join(r.basePath, requestsFile)
+}
+
+// loadSavedRequestList loads the saved requests library from disk
+func (r *JSONRepository) loadSavedRequestList() ([]domain.SavedRequest, error) {
+	path := r.requestsPath()
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist yet, return empty list
+			return []domain.SavedRequest{}, nil
+		}
+		return nil, fmt.Errorf("read saved requests file: %w", err)
+	}
+
+	_, data, err := unwrapVersioned(fileData)
+	if err != nil {
+		r.handleCorruptFile(path, err)
+		return []domain.SavedRequest{}, nil
+	}
+
+	var saved []domain.SavedRequest
+	if err := json.Unmarshal(data, &saved); err != nil {
+		r.handleCorruptFile(path, err)
+		return []domain.SavedRequest{}, nil
+	}
+
+	return saved, nil
+}
+
+// saveSavedRequestList saves the saved requests library to disk
+func (r *JSONRepository) saveSavedRequestList(saved []domain.SavedRequest) error {
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal saved requests: %w", err)
+	}
+
+	wrapped, err := wrapVersioned(data)
+	if err != nil {
+		return fmt.Errorf("wrap saved requests version: %w", err)
+	}
+
+	path := r.requestsPath()
+	if err := atomicWriteFile(path, wrapped, filePermission); err != nil {
+		return fmt.Errorf("write saved requests file: %w", err)
+	}
+
+	return nil
+}
+
+// RecordMethodCall counts the call a history entry records in its method's
+// statistics
+func (r *JSONRepository) RecordMethodCall(entry domain.HistoryEntry) error {
+	if err := r.ensureBaseDir(); err != nil {
+		return fmt.Errorf("ensure base directory: %w", err)
+	}
+
+	stats, err := r.loadMethodStatsList()
+	if err != nil {
+		return fmt.Errorf("load method stats: %w", err)
+	}
+
+	stats, err = recordMethodCall(stats, entry)
+	if err != nil {
+		return err
+	}
+
+	if err := r.saveMethodStatsList(stats); err != nil {
+		return fmt.Errorf("save method stats: %w", err)
+	}
+
+	r.logger.Debug("recorded method call",
+		slog.String("address", entry.Connection.Address),
+		slog.String("method", entry.Method))
+
+	return nil
+}
+
+// GetMethodStats returns the statistics of every method called on address
+func (r *JSONRepository) GetMethodStats(address string) ([]domain.MethodStats, error) {
+	stats, err := r.loadMethodStatsList()
+	if err != nil {
+		return nil, fmt.Errorf("load method stats: %w", err)
+	}
+
+	return methodStatsFor(stats, address), nil
+}
+
+// statsPath returns the path to the method statistics file
+func (r *JSONRepository) statsPath() string {
+	return filepath.Join(r.basePath, statsFile)
+}
+
+// loadMethodStatsList loads the method statistics from disk
+func (r *JSONRepository) loadMethodStatsList() ([]domain.MethodStats, error) {
+	path := r.statsPath()
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist yet, return empty list
+			return []domain.MethodStats{}, nil
+		}
+		return nil, fmt.Errorf("read method stats file: %w", err)
+	}
+
+	_, data, err := unwrapVersioned(fileData)
+	if err != nil {
+		r.handleCorruptFile(path, err)
+		return []domain.MethodStats{}, nil
+	}
+
+	var stats []domain.MethodStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		r.handleCorruptFile(path, err)
+		return []domain.MethodStats{}, nil
+	}
+
+	return stats, nil
+}
+
+// saveMethodStatsList saves the method statistics to disk
+func (r *JSONRepository) saveMethodStatsList(stats []domain.MethodStats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal method stats: %w", err)
+	}
+
+	wrapped, err := wrapVersioned(data)
+	if err != nil {
+		return fmt.Errorf("wrap method stats version: %w", err)
+	}
+
+	path := r.statsPath()
+	if err := atomicWriteFile(path, wrapped, filePermission); err != nil {
+		return fmt.Errorf("write method stats file: %w", err)
+	}
+
+	return nil
+}
+
+// SetFavorites sets the methods pinned on address
+func (r *JSONRepository) SetFavorites(address string, methods []string) error {
+	if err := r.ensureBaseDir(); err != nil {
+		return fmt.Errorf("ensure base directory: %w", err)
+	}
+
+	favorites, err := r.loadFavorites()
+	if err != nil {
+		return fmt.Errorf("load favorites: %w", err)
+	}
+
+	if err := setFavorites(favorites, address, methods); err != nil {
+		return err
+	}
+
+	if err := r.saveFavorites(favorites); err != nil {
+		return fmt.Errorf("save favorites: %w", err)
+	}
+
+	r.logger.Debug("saved favorites",
+		slog.String("address", address),
+		slog.Int("count", len(methods)))
+
+	return nil
+}
+
+// GetFavorites returns the methods pinned on address
+func (r *JSONRepository) GetFavorites(address string) ([]string, error) {
+	favorites, err := r.loadFavorites()
+	if err != nil {
+		return nil, fmt.Errorf("load favorites: %w", err)
+	}
+
+	return favorites[address], nil
+}
+
+// favoritesPath returns the path to the favorites file
+func (r *JSONRepository) favoritesPath() string {
+	return filepath.Join(r.basePath, favoritesFile)
+}
+
+// loadFavorites loads the pinned methods of every address from disk
+func (r *JSONRepository) loadFavorites() (map[string][]string, error) {
+	path := r.favoritesPath()
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist yet, return empty map
+			return map[string][]string{}, nil
+		}
+		return nil, fmt.Errorf("read favorites file: %w", err)
+	}
+
+	_, data, err := unwrapVersioned(fileData)
+	if err != nil {
+		r.handleCorruptFile(path, err)
+		return map[string][]string{}, nil
+	}
+
+	var favorites map[string][]string
+	if err := json.Unmarshal(data, &favorites); err != nil {
+		r.handleCorruptFile(path, err)
+		return map[string][]string{}, nil
+	}
+	if favorites == nil {
+		favorites = map[string][]string{} // Saved as null
+	}
+
+	return favorites, nil
+}
+
+// saveFavorites saves the pinned methods of every address to disk
+func (r *JSONRepository) saveFavorites(favorites map[string][]string) error {
+	data, err := json.MarshalIndent(favorites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal favorites: %w", err)
+	}
+
+	wrapped, err := wrapVersioned(data)
+	if err != nil {
+		return fmt.Errorf("wrap favorites version: %w", err)
+	}
+
+	path := r.favoritesPath()
+	if err := atomicWriteFile(path, wrapped, filePermission); err != nil {
+		return fmt.Errorf("write favorites file: %w", err)
+	}
+
+	return nil
+}
@@ -3,7 +3,9 @@ package storage
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/shhac/grotto/internal/domain"
 	"github.com/shhac/grotto/internal/logging"
@@ -153,6 +155,22 @@ func TestSaveAndLoadWorkspace_RoundTrip(t *testing.T) {
 	ws := domain.Workspace{
 		Name:            "test-workspace",
 		SelectedService: "my.Service",
+		Connections: []domain.Connection{{
+			Address: "staging.internal:443",
+			Proxy:   domain.ProxySettings{Type: domain.ProxySOCKS5, Host: "proxy", Port: 1080, Username: "u", Password: "p"},
+			Auth:    domain.Auth{Type: domain.AuthBearer, Token: "t"},
+			Retry: domain.RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     2 * time.Second,
+				RetryableCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+			},
+		}},
+		Checklist: []domain.ChecklistItem{
+			{Kind: domain.ChecklistConnect, Connection: &domain.Connection{Name: "prod", Address: "prod:443"}},
+			{Kind: domain.ChecklistMethod, Method: "pkg.Svc/Health", Timeout: 2 * time.Second},
+			{Kind: domain.ChecklistMetadata, MetadataKey: "authorization", CheckJWTExpiry: true},
+		},
 	}
 
 	if err := repo.SaveWorkspace(ws); err != nil {
@@ -170,4 +188,121 @@ func TestSaveAndLoadWorkspace_RoundTrip(t *testing.T) {
 	if loaded.SelectedService != ws.SelectedService {
 		t.Errorf("SelectedService = %q, want %q", loaded.SelectedService, ws.SelectedService)
 	}
+	if !reflect.DeepEqual(loaded.Checklist, ws.Checklist) {
+		t.Errorf("Checklist = %+v, want %+v", loaded.Checklist, ws.Checklist)
+	}
+	if !reflect.DeepEqual(loaded.Connections, ws.Connections) {
+		t.Errorf("Connections = %+v, want %+v", loaded.Connections, ws.Connections)
+	}
+}
+
+// TestLoadWorkspace_UnnamedConnections loads workspaces written before
+// connections could be named, with and without the version envelope.
+func TestLoadWorkspace_UnnamedConnections(t *testing.T) {
+	workspace := `{
+		"Name": "old",
+		"Connections": [{"Address": "api:443", "Timeout": 0, "TLS": {"Enabled": true}}],
+		"CurrentConnection": {"Address": "api:443", "Timeout": 0, "TLS": {"Enabled": true}},
+		"SelectedService": "pkg.Svc",
+		"SelectedMethod": "Call"
+	}`
+	files := map[string]string{
+		"unversioned": workspace,
+		"version 1":   `{"version": 1, "data": ` + workspace + `}`,
+	}
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo := NewJSONRepository(dir, logging.NewNopLogger())
+			if err := os.MkdirAll(filepath.Join(dir, workspacesDir), dirPermission); err != nil {
+				t.Fatalf("MkdirAll failed: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, workspacesDir, "old.json"), []byte(data), filePermission); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+
+			loaded, err := repo.LoadWorkspace("old")
+			if err != nil {
+				t.Fatalf("LoadWorkspace failed: %v", err)
+			}
+			want := domain.Connection{Address: "api:443", TLS: domain.TLSSettings{Enabled: true}}
+			if !reflect.DeepEqual(loaded.Connections, []domain.Connection{want}) {
+				t.Errorf("Connections = %+v, want %+v", loaded.Connections, want)
+			}
+			if loaded.CurrentConnection == nil || !reflect.DeepEqual(*loaded.CurrentConnection, want) {
+				t.Errorf("CurrentConnection = %+v, want %+v", loaded.CurrentConnection, want)
+			}
+			if got := loaded.CurrentConnection.DisplayName(); got != "api:443" {
+				t.Errorf("DisplayName = %q, want the address", got)
+			}
+
+			// Naming the connection and saving again keeps the name
+			loaded.CurrentConnection.Name = "api"
+			if err := repo.SaveWorkspace(*loaded); err != nil {
+				t.Fatalf("SaveWorkspace failed: %v", err)
+			}
+			reloaded, err := repo.LoadWorkspace("old")
+			if err != nil {
+				t.Fatalf("LoadWorkspace failed: %v", err)
+			}
+			if got := reloaded.CurrentConnection.DisplayName(); got != "api (api:443)" {
+				t.Errorf("DisplayName = %q, want %q", got, "api (api:443)")
+			}
+		})
+	}
+}
+
+// TestGetHistory_UnnamedConnections reads history written before
+// connections could be named.
+func TestGetHistory_UnnamedConnections(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewJSONRepository(dir, logging.NewNopLogger())
+	data := `{"version": 1, "data": [{"id": "1", "method": "pkg.Svc/Call", "connection": {"Address": "api:443", "Timeout": 0, "TLS": {}}, "status": "success"}]}`
+	if err := os.WriteFile(filepath.Join(dir, historyFile), []byte(data), filePermission); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	history, err := repo.GetHistory(0)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Connection.Name != "" || history[0].Connection.DisplayName() != "api:443" {
+		t.Errorf("history = %+v, want one unnamed entry for api:443", history)
+	}
+}
+
+func TestUnixSocketAddresses_RoundTrip(t *testing.T) {
+	repo := NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+
+	conn := domain.Connection{Name: "local", Address: "unix:///var/run/app.sock"}
+	abstract := domain.Connection{Address: "unix-abstract:grotto"}
+	ws := domain.Workspace{
+		Name:              "sockets",
+		Connections:       []domain.Connection{conn, abstract},
+		CurrentConnection: &conn,
+	}
+	if err := repo.SaveWorkspace(ws); err != nil {
+		t.Fatalf("SaveWorkspace failed: %v", err)
+	}
+	loaded, err := repo.LoadWorkspace("sockets")
+	if err != nil {
+		t.Fatalf("LoadWorkspace failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Connections, ws.Connections) {
+		t.Errorf("Connections = %+v, want %+v", loaded.Connections, ws.Connections)
+	}
+	if loaded.CurrentConnection == nil || loaded.CurrentConnection.Address != conn.Address {
+		t.Errorf("CurrentConnection = %+v, want address %q", loaded.CurrentConnection, conn.Address)
+	}
+
+	if err := repo.AddHistoryEntry(domain.HistoryEntry{ID: "1", Method: "pkg.Svc/Call", Connection: conn}); err != nil {
+		t.Fatalf("AddHistoryEntry failed: %v", err)
+	}
+	history, err := repo.GetHistory(0)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Connection.Address != conn.Address {
+		t.Errorf("history = %+v, want one entry for %q", history, conn.Address)
+	}
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/shhac/grotto/internal/domain"
@@ -10,8 +11,12 @@ import (
 // MemoryRepository implements Repository using in-memory storage for tests
 type MemoryRepository struct {
 	workspaces map[string]domain.Workspace
+	autosave   *domain.Workspace
 	recent     []domain.Connection
 	history    []domain.HistoryEntry
+	requests   []domain.SavedRequest
+	stats      []domain.MethodStats
+	favorites  map[string][]string
 	mu         sync.RWMutex
 }
 
@@ -21,6 +26,8 @@ func NewMemoryRepository() *MemoryRepository {
 		workspaces: make(map[string]domain.Workspace),
 		recent:     []domain.Connection{},
 		history:    []domain.HistoryEntry{},
+		requests:   []domain.SavedRequest{},
+		favorites:  make(map[string][]string),
 	}
 }
 
@@ -72,6 +79,36 @@ func (m *MemoryRepository) DeleteWorkspace(name string) error {
 	return nil
 }
 
+// SaveAutosave replaces the autosaved workspace
+func (m *MemoryRepository) SaveAutosave(workspace domain.Workspace) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.autosave = &workspace
+	return nil
+}
+
+// LoadAutosave returns the autosaved workspace, or nil if there is none
+func (m *MemoryRepository) LoadAutosave() (*domain.Workspace, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.autosave == nil {
+		return nil, nil
+	}
+	workspace := *m.autosave
+	return &workspace, nil
+}
+
+// ClearAutosave removes the autosaved workspace
+func (m *MemoryRepository) ClearAutosave() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.autosave = nil
+	return nil
+}
+
 // SaveRecentConnection adds a connection to recent list
 func (m *MemoryRepository) SaveRecentConnection(conn domain.Connection) error {
 	m.mu.Lock()
@@ -173,3 +210,89 @@ func (m *MemoryRepository) DeleteHistoryEntry(id string) error {
 	}
 	return fmt.Errorf("history entry %q not found", id)
 }
+
+// HistoryStatusBuckets counts history entries by time bucket and status code
+func (m *MemoryRepository) HistoryStatusBuckets(query domain.HistoryStatsQuery) ([]domain.StatusBucket, error) {
+	m.mu.RLock()
+	summaries := make([]historySummary, len(m.history))
+	for i, entry := range m.history {
+		summaries[i] = summarize(entry)
+	}
+	m.mu.RUnlock()
+
+	return aggregateStatusBuckets(summaries, query)
+}
+
+// SaveRequest adds a request to the saved requests library, replacing any
+// request with the same method and name
+func (m *MemoryRepository) SaveRequest(req domain.SavedRequest) error {
+	if err := validateSavedRequest(req); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = upsertSavedRequest(m.requests, req)
+	return nil
+}
+
+// GetSavedRequests returns the saved requests for a method sorted by name,
+// or every saved request when method is empty
+func (m *MemoryRepository) GetSavedRequests(method string) ([]domain.SavedRequest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return filterSavedRequests(m.requests, method), nil
+}
+
+// DeleteSavedRequest removes a request from the saved requests library
+func (m *MemoryRepository) DeleteSavedRequest(method, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests, found := removeSavedRequest(m.requests, method, name)
+	if !found {
+		return fmt.Errorf("saved request %q not found for %s", name, method)
+	}
+	m.requests = requests
+	return nil
+}
+
+// RecordMethodCall counts the call a history entry records in its method's
+// statistics
+func (m *MemoryRepository) RecordMethodCall(entry domain.HistoryEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, err := recordMethodCall(m.stats, entry)
+	if err != nil {
+		return err
+	}
+	m.stats = stats
+	return nil
+}
+
+// GetMethodStats returns the statistics of every method called on address
+func (m *MemoryRepository) GetMethodStats(address string) ([]domain.MethodStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return methodStatsFor(m.stats, address), nil
+}
+
+// SetFavorites sets the methods pinned on address
+func (m *MemoryRepository) SetFavorites(address string, methods []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return setFavorites(m.favorites, address, methods)
+}
+
+// GetFavorites returns the methods pinned on address
+func (m *MemoryRepository) GetFavorites(address string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.favorites[address]), nil
+}
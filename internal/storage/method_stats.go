@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// maxMethodStats caps the methods whose statistics are kept; the ones
+// called longest ago are dropped first
+const maxMethodStats = 500
+
+// recordMethodCall counts the call entry records in the statistics of its
+// server and method, adding them when there are none
+func recordMethodCall(list []domain.MethodStats, entry domain.HistoryEntry) ([]domain.MethodStats, error) {
+	address := entry.Connection.Address
+	if address == "" || entry.Method == "" {
+		return nil, errors.New("method call needs an address and method")
+	}
+
+	i := slices.IndexFunc(list, func(s domain.MethodStats) bool {
+		return s.Address == address && s.Method == entry.Method
+	})
+	if i < 0 {
+		list = append(list, domain.MethodStats{Address: address, Method: entry.Method})
+		i = len(list) - 1
+	}
+	list[i].Record(entry.StatusCode(), entry.Duration, entry.Timestamp)
+
+	if len(list) > maxMethodStats {
+		slices.SortStableFunc(list, func(a, b domain.MethodStats) int {
+			return b.LastCall.Compare(a.LastCall)
+		})
+		list = list[:maxMethodStats]
+	}
+	return list, nil
+}
+
+// methodStatsFor returns copies of the statistics of every method called
+// on address
+func methodStatsFor(list []domain.MethodStats, address string) []domain.MethodStats {
+	stats := []domain.MethodStats{}
+	for _, s := range list {
+		if s.Address == address {
+			s.Latency.Samples = slices.Clone(s.Latency.Samples)
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+)
+
+func callEntry(address, method, code string, duration time.Duration, at time.Time) domain.HistoryEntry {
+	return domain.HistoryEntry{
+		Timestamp:  at,
+		Connection: domain.Connection{Address: address},
+		Method:     method,
+		Duration:   duration,
+		Status:     "success",
+		Code:       code,
+	}
+}
+
+func TestMethodStats_RoundTrip(t *testing.T) {
+	repos := map[string]func(t *testing.T) Repository{
+		"json": func(t *testing.T) Repository {
+			return NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+		},
+		"memory": func(t *testing.T) Repository {
+			return NewMemoryRepository()
+		},
+	}
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			for i, entry := range []domain.HistoryEntry{
+				callEntry("localhost:50051", getUser, "OK", 10*time.Millisecond, at),
+				callEntry("localhost:50051", getUser, "NotFound", 30*time.Millisecond, at.Add(time.Second)),
+				callEntry("localhost:50051", createUser, "OK", 5*time.Millisecond, at),
+				callEntry("staging:443", getUser, "OK", time.Second, at),
+			} {
+				if err := repo.RecordMethodCall(entry); err != nil {
+					t.Fatalf("RecordMethodCall(%d) failed: %v", i, err)
+				}
+			}
+
+			stats, err := repo.GetMethodStats("localhost:50051")
+			if err != nil {
+				t.Fatalf("GetMethodStats failed: %v", err)
+			}
+			if len(stats) != 2 {
+				t.Fatalf("GetMethodStats returned %d methods, want 2", len(stats))
+			}
+			get := stats[0].Summary()
+			if stats[0].Method != getUser || get.Calls != 2 || get.LastCode != "NotFound" || get.P95 != 30*time.Millisecond {
+				t.Errorf("GetUser stats = %+v (%+v)", stats[0], get)
+			}
+
+			// Each server keeps its own statistics
+			stats, err = repo.GetMethodStats("staging:443")
+			if err != nil {
+				t.Fatalf("GetMethodStats failed: %v", err)
+			}
+			if len(stats) != 1 || stats[0].Calls != 1 {
+				t.Errorf("staging stats = %+v, want one call", stats)
+			}
+
+			// Entries without an address are not counted
+			if err := repo.RecordMethodCall(callEntry("", getUser, "OK", 0, at)); err == nil {
+				t.Error("RecordMethodCall without an address succeeded")
+			}
+		})
+	}
+}
+
+func TestRecordMethodCall_Caps(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var list []domain.MethodStats
+	var err error
+	for i := range maxMethodStats + 5 {
+		method := fmt.Sprintf("pkg.Service/Method%d", i)
+		list, err = recordMethodCall(list, callEntry("localhost:50051", method, "OK", time.Millisecond, at.Add(time.Duration(i)*time.Second)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(list) != maxMethodStats {
+		t.Fatalf("kept %d methods, want %d", len(list), maxMethodStats)
+	}
+	for _, s := range list {
+		if s.Method == "pkg.Service/Method0" {
+			t.Error("the method called longest ago was kept")
+		}
+	}
+
+	// Samples are capped per method while calls keep counting
+	list = nil
+	for range domain.MaxLatencySamples * 2 {
+		list, _ = recordMethodCall(list, callEntry("localhost:50051", getUser, "OK", time.Millisecond, at))
+	}
+	if got := len(list[0].Latency.Samples); got != domain.MaxLatencySamples {
+		t.Errorf("kept %d samples, want %d", got, domain.MaxLatencySamples)
+	}
+	if list[0].Calls != domain.MaxLatencySamples*2 {
+		t.Errorf("counted %d calls, want %d", list[0].Calls, domain.MaxLatencySamples*2)
+	}
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+)
+
+// addresses returns the addresses of conns in order.
+func addresses(conns []domain.Connection) []string {
+	out := make([]string, len(conns))
+	for i, c := range conns {
+		out[i] = c.Address
+	}
+	return out
+}
+
+func TestRecentConnections_MostRecentFirst(t *testing.T) {
+	repos := map[string]func(t *testing.T) Repository{
+		"json": func(t *testing.T) Repository {
+			return NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+		},
+		"memory": func(t *testing.T) Repository {
+			return NewMemoryRepository()
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			for _, addr := range []string{"a:1", "b:2", "c:3"} {
+				if err := repo.SaveRecentConnection(domain.Connection{Address: addr}); err != nil {
+					t.Fatalf("SaveRecentConnection(%q) failed: %v", addr, err)
+				}
+			}
+			got, err := repo.GetRecentConnections()
+			if err != nil {
+				t.Fatalf("GetRecentConnections failed: %v", err)
+			}
+			if want := []string{"c:3", "b:2", "a:1"}; !reflect.DeepEqual(addresses(got), want) {
+				t.Errorf("order = %v, want %v", addresses(got), want)
+			}
+
+			// Reconnecting moves an address to the front, once, with the
+			// settings used last
+			tls := domain.Connection{Address: "a:1", TLS: domain.TLSSettings{Enabled: true, CertFile: "/etc/ca.pem"}}
+			if err := repo.SaveRecentConnection(tls); err != nil {
+				t.Fatalf("SaveRecentConnection(again) failed: %v", err)
+			}
+			got, _ = repo.GetRecentConnections()
+			if want := []string{"a:1", "c:3", "b:2"}; !reflect.DeepEqual(addresses(got), want) {
+				t.Errorf("after reconnect = %v, want %v", addresses(got), want)
+			}
+			if got[0].TLS != tls.TLS {
+				t.Errorf("TLS = %+v, want %+v", got[0].TLS, tls.TLS)
+			}
+
+			// The oldest fall off past the cap
+			for i := range maxRecent {
+				if err := repo.SaveRecentConnection(domain.Connection{Address: fmt.Sprintf("host%d:1", i)}); err != nil {
+					t.Fatalf("SaveRecentConnection failed: %v", err)
+				}
+			}
+			got, _ = repo.GetRecentConnections()
+			if len(got) != maxRecent {
+				t.Fatalf("len = %d, want %d", len(got), maxRecent)
+			}
+			if want := fmt.Sprintf("host%d:1", maxRecent-1); got[0].Address != want {
+				t.Errorf("newest = %q, want %q", got[0].Address, want)
+			}
+
+			if err := repo.ClearRecentConnections(); err != nil {
+				t.Fatalf("ClearRecentConnections failed: %v", err)
+			}
+			got, _ = repo.GetRecentConnections()
+			if len(got) != 0 {
+				t.Errorf("after clear = %v, want none", addresses(got))
+			}
+		})
+	}
+}
+
+func TestRecentConnections_PersistAcrossRepositories(t *testing.T) {
+	dir := t.TempDir()
+	conn := domain.Connection{
+		Address:   "api.example.com:443",
+		Transport: domain.TransportGRPC,
+		TLS: domain.TLSSettings{
+			Enabled:        true,
+			CertFile:       "/etc/ssl/internal-ca.pem",
+			ClientCertFile: "/etc/ssl/client.pem",
+			ClientKeyFile:  "/etc/ssl/client.key",
+		},
+		KeepAlive:   true,
+		Compression: "gzip",
+		KeepaliveParams: domain.KeepaliveParams{
+			Time:                time.Minute,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		},
+	}
+
+	if err := NewJSONRepository(dir, logging.NewNopLogger()).SaveRecentConnection(conn); err != nil {
+		t.Fatalf("SaveRecentConnection failed: %v", err)
+	}
+
+	got, err := NewJSONRepository(dir, logging.NewNopLogger()).GetRecentConnections()
+	if err != nil {
+		t.Fatalf("GetRecentConnections failed: %v", err)
+	}
+	if want := []domain.Connection{conn}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRecentConnections = %+v, want %+v", got, want)
+	}
+}
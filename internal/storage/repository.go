@@ -10,6 +10,12 @@ type Repository interface {
 	ListWorkspaces() ([]string, error)
 	DeleteWorkspace(name string) error
 
+	// Autosave slot, a workspace kept apart from the named ones so it is
+	// never listed. LoadAutosave returns nil when there is none.
+	SaveAutosave(workspace domain.Workspace) error
+	LoadAutosave() (*domain.Workspace, error)
+	ClearAutosave() error
+
 	// Recent connections
 	SaveRecentConnection(conn domain.Connection) error
 	GetRecentConnections() ([]domain.Connection, error)
@@ -20,4 +26,27 @@ type Repository interface {
 	GetHistory(limit int) ([]domain.HistoryEntry, error)
 	DeleteHistoryEntry(id string) error
 	ClearHistory() error
+
+	// Saved requests library, keyed by full method name
+	// ("pkg.Service/Method"). Saving replaces a request with the same
+	// method and name; an empty method lists every saved request.
+	SaveRequest(req domain.SavedRequest) error
+	GetSavedRequests(method string) ([]domain.SavedRequest, error)
+	DeleteSavedRequest(method, name string) error
+
+	// HistoryStatusBuckets counts history entries by time bucket and
+	// gRPC status code without loading request or response payloads
+	HistoryStatusBuckets(query domain.HistoryStatsQuery) ([]domain.StatusBucket, error)
+
+	// Per-method invocation statistics, keyed by server address and full
+	// method name. RecordMethodCall counts the call a history entry
+	// records; GetMethodStats lists every method called on address.
+	RecordMethodCall(entry domain.HistoryEntry) error
+	GetMethodStats(address string) ([]domain.MethodStats, error)
+
+	// Methods pinned in the service browser, keyed by server address and
+	// listed by full method name in the order pinned. Setting none
+	// forgets the address.
+	SetFavorites(address string, methods []string) error
+	GetFavorites(address string) ([]string, error)
 }
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// validateSavedRequest checks that a saved request has a method and name
+func validateSavedRequest(req domain.SavedRequest) error {
+	if req.Request.Method == "" {
+		return errors.New("saved request method cannot be empty")
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("saved request name cannot be empty")
+	}
+	return nil
+}
+
+// upsertSavedRequest replaces the request with the same method and name, or
+// appends req when there is none
+func upsertSavedRequest(list []domain.SavedRequest, req domain.SavedRequest) []domain.SavedRequest {
+	for i, saved := range list {
+		if saved.Request.Method == req.Request.Method && saved.Name == req.Name {
+			list[i] = req
+			return list
+		}
+	}
+	return append(list, req)
+}
+
+// removeSavedRequest removes the request with the given method and name,
+// reporting whether it was found
+func removeSavedRequest(list []domain.SavedRequest, method, name string) ([]domain.SavedRequest, bool) {
+	for i, saved := range list {
+		if saved.Request.Method == method && saved.Name == name {
+			return append(list[:i], list[i+1:]...), true
+		}
+	}
+	return list, false
+}
+
+// filterSavedRequests returns a copy of the requests for method ("" for all),
+// sorted by method then name
+func filterSavedRequests(list []domain.SavedRequest, method string) []domain.SavedRequest {
+	filtered := []domain.SavedRequest{}
+	for _, saved := range list {
+		if method == "" || saved.Request.Method == method {
+			filtered = append(filtered, saved)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if a.Request.Method != b.Request.Method {
+			return a.Request.Method < b.Request.Method
+		}
+		return a.Name < b.Name
+	})
+	return filtered
+}
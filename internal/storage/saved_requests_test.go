@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+)
+
+const (
+	createUser = "users.UserService/CreateUser"
+	getUser    = "users.UserService/GetUser"
+)
+
+func savedRequest(method, name, body string) domain.SavedRequest {
+	return domain.SavedRequest{
+		Name: name,
+		Request: domain.Request{
+			Method:   method,
+			Body:     body,
+			Metadata: map[string]string{"authorization": "Bearer " + name},
+		},
+	}
+}
+
+func TestSavedRequests_RoundTrip(t *testing.T) {
+	repos := map[string]func(t *testing.T) Repository{
+		"json": func(t *testing.T) Repository {
+			return NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+		},
+		"memory": func(t *testing.T) Repository {
+			return NewMemoryRepository()
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			happy := savedRequest(createUser, "happy path", `{"email": "a@example.com"}`)
+			missing := savedRequest(createUser, "missing email", `{}`)
+			other := savedRequest(getUser, "happy path", `{"id": "1"}`)
+			for _, req := range []domain.SavedRequest{missing, happy, other} {
+				if err := repo.SaveRequest(req); err != nil {
+					t.Fatalf("SaveRequest(%q) failed: %v", req.Name, err)
+				}
+			}
+
+			got, err := repo.GetSavedRequests(createUser)
+			if err != nil {
+				t.Fatalf("GetSavedRequests failed: %v", err)
+			}
+			if want := []domain.SavedRequest{happy, missing}; !reflect.DeepEqual(got, want) {
+				t.Errorf("GetSavedRequests(%q) = %+v, want %+v", createUser, got, want)
+			}
+
+			// Same name under another method is a separate entry
+			all, err := repo.GetSavedRequests("")
+			if err != nil {
+				t.Fatalf("GetSavedRequests(all) failed: %v", err)
+			}
+			if want := []domain.SavedRequest{happy, missing, other}; !reflect.DeepEqual(all, want) {
+				t.Errorf("GetSavedRequests(all) = %+v, want %+v", all, want)
+			}
+
+			// Saving an existing name overwrites it
+			updated := savedRequest(createUser, "happy path", `{"email": "b@example.com"}`)
+			if err := repo.SaveRequest(updated); err != nil {
+				t.Fatalf("SaveRequest(overwrite) failed: %v", err)
+			}
+			got, _ = repo.GetSavedRequests(createUser)
+			if want := []domain.SavedRequest{updated, missing}; !reflect.DeepEqual(got, want) {
+				t.Errorf("after overwrite = %+v, want %+v", got, want)
+			}
+
+			if err := repo.DeleteSavedRequest(createUser, "missing email"); err != nil {
+				t.Fatalf("DeleteSavedRequest failed: %v", err)
+			}
+			got, _ = repo.GetSavedRequests(createUser)
+			if want := []domain.SavedRequest{updated}; !reflect.DeepEqual(got, want) {
+				t.Errorf("after delete = %+v, want %+v", got, want)
+			}
+
+			none, _ := repo.GetSavedRequests("users.UserService/DeleteUser")
+			if len(none) != 0 {
+				t.Errorf("unknown method returned %+v", none)
+			}
+		})
+	}
+}
+
+func TestSavedRequests_PersistAcrossRepositories(t *testing.T) {
+	dir := t.TempDir()
+	req := savedRequest(createUser, "happy path", `{"email": "a@example.com"}`)
+
+	if err := NewJSONRepository(dir, logging.NewNopLogger()).SaveRequest(req); err != nil {
+		t.Fatalf("SaveRequest failed: %v", err)
+	}
+
+	got, err := NewJSONRepository(dir, logging.NewNopLogger()).GetSavedRequests(createUser)
+	if err != nil {
+		t.Fatalf("GetSavedRequests failed: %v", err)
+	}
+	if want := []domain.SavedRequest{req}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSavedRequests = %+v, want %+v", got, want)
+	}
+}
+
+func TestSavedRequests_Invalid(t *testing.T) {
+	repo := NewJSONRepository(t.TempDir(), logging.NewNopLogger())
+
+	if err := repo.SaveRequest(savedRequest("", "happy path", "{}")); err == nil {
+		t.Error("expected error for empty method")
+	}
+	if err := repo.SaveRequest(savedRequest(createUser, "  ", "{}")); err == nil {
+		t.Error("expected error for blank name")
+	}
+}
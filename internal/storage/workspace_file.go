@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+const (
+	// workspaceFileKind marks a JSON file as an exported workspace
+	workspaceFileKind = "grotto-workspace"
+
+	// workspaceFileVersion is the schema version of exported workspaces.
+	// Bump it when a change would be misread by older versions; fields
+	// added without bumping it are ignored by them.
+	workspaceFileVersion = 1
+)
+
+// workspaceFile is the shareable form of a workspace written by
+// ExportWorkspace.
+type workspaceFile struct {
+	Kind      string           `json:"kind"`
+	Version   int              `json:"version"`
+	Secrets   bool             `json:"secrets,omitempty"` // Whether credentials were kept
+	Workspace domain.Workspace `json:"workspace"`
+}
+
+// ExportWorkspace encodes workspace as a single shareable JSON file. Unless
+// includeSecrets is set, credentials are stripped first (see
+// domain.Workspace.WithoutSecrets).
+func ExportWorkspace(workspace domain.Workspace, includeSecrets bool) ([]byte, error) {
+	if !includeSecrets {
+		workspace = workspace.WithoutSecrets()
+	}
+	data, err := json.MarshalIndent(workspaceFile{
+		Kind:      workspaceFileKind,
+		Version:   workspaceFileVersion,
+		Secrets:   includeSecrets,
+		Workspace: workspace,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal workspace: %w", err)
+	}
+	return data, nil
+}
+
+// ImportWorkspace decodes a file written by ExportWorkspace. Files from a
+// newer schema version are rejected; unknown fields are ignored.
+func ImportWorkspace(data []byte) (*domain.Workspace, error) {
+	var file workspaceFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("not a workspace file: %w", err)
+	}
+	if file.Kind != workspaceFileKind {
+		return nil, errors.New("not a workspace file: missing \"kind\": \"" + workspaceFileKind + "\"")
+	}
+	if file.Version < 1 || file.Version > workspaceFileVersion {
+		return nil, fmt.Errorf("unsupported workspace file version %d (this version of Grotto reads up to %d)", file.Version, workspaceFileVersion)
+	}
+	if err := validateWorkspaceName(file.Workspace.Name); err != nil {
+		return nil, fmt.Errorf("invalid workspace name: %w", err)
+	}
+	return &file.Workspace, nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// sharedWorkspace returns a workspace with credentials in every place
+// they can be kept.
+func sharedWorkspace() domain.Workspace {
+	conn := domain.Connection{
+		Name:    "staging",
+		Address: "staging:443",
+		Timeout: 30 * time.Second,
+		Auth:    domain.Auth{Type: domain.AuthBasic, Username: "ann", Password: "hunter2"},
+		Proxy:   domain.ProxySettings{Type: domain.ProxyHTTP, Host: "proxy", Port: 3128, Username: "p", Password: "proxypass"},
+		TLS:     domain.TLSSettings{Enabled: true, CertFile: "/ca.pem", ClientCertFile: "/client.pem", ClientKeyFile: "/client.key"},
+	}
+	req := domain.Request{
+		Method:   "users.UserService/GetUser",
+		Body:     `{"id": "1"}`,
+		Metadata: map[string]string{"Authorization": "Bearer abc", "x-trace": "on"},
+	}
+	return domain.Workspace{
+		Name:              "team",
+		Connections:       []domain.Connection{conn},
+		Requests:          []domain.SavedRequest{{Name: req.Method, Request: req}},
+		Library:           []domain.SavedRequest{{Name: "happy path", Request: req}},
+		Checklist:         []domain.ChecklistItem{{Kind: domain.ChecklistConnect, Connection: &conn}},
+		CurrentConnection: &conn,
+		CurrentRequest:    &req,
+		SelectedService:   "users.UserService",
+		SelectedMethod:    "GetUser",
+	}
+}
+
+func TestWorkspaceFile_RoundTripWithSecrets(t *testing.T) {
+	ws := sharedWorkspace()
+	data, err := ExportWorkspace(ws, true)
+	if err != nil {
+		t.Fatalf("ExportWorkspace failed: %v", err)
+	}
+	got, err := ImportWorkspace(data)
+	if err != nil {
+		t.Fatalf("ImportWorkspace failed: %v", err)
+	}
+	if !reflect.DeepEqual(*got, ws) {
+		t.Errorf("round trip = %+v, want %+v", *got, ws)
+	}
+}
+
+func TestWorkspaceFile_StripsSecrets(t *testing.T) {
+	ws := sharedWorkspace()
+	data, err := ExportWorkspace(ws, false)
+	if err != nil {
+		t.Fatalf("ExportWorkspace failed: %v", err)
+	}
+	for _, secret := range []string{"hunter2", "proxypass", "/client.key", "Bearer abc"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("export contains %q", secret)
+		}
+	}
+	if ws.CurrentConnection.Auth.Password != "hunter2" || ws.CurrentRequest.Metadata["Authorization"] == "" {
+		t.Error("export modified the workspace it was given")
+	}
+
+	got, err := ImportWorkspace(data)
+	if err != nil {
+		t.Fatalf("ImportWorkspace failed: %v", err)
+	}
+	if want := ws.WithoutSecrets(); !reflect.DeepEqual(*got, want) {
+		t.Errorf("round trip = %+v, want %+v", *got, want)
+	}
+	conn := got.Connections[0]
+	if conn.Auth.Username != "ann" || conn.TLS.ClientCertFile != "/client.pem" || conn.Proxy.Username != "p" {
+		t.Errorf("non-secret settings lost: %+v", conn)
+	}
+	if md := got.Library[0].Request.Metadata; !reflect.DeepEqual(md, map[string]string{"x-trace": "on"}) {
+		t.Errorf("library metadata = %v, want only x-trace", md)
+	}
+}
+
+func TestImportWorkspace_IgnoresUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"kind": "grotto-workspace",
+		"version": 1,
+		"exportedBy": "grotto 9.0",
+		"workspace": {
+			"Name": "future",
+			"Connections": [{"Address": "a:1", "Colour": "teal"}],
+			"Variables": {"host": "a"},
+			"SelectedService": "s",
+			"SelectedMethod": "m"
+		}
+	}`)
+	got, err := ImportWorkspace(data)
+	if err != nil {
+		t.Fatalf("ImportWorkspace failed: %v", err)
+	}
+	if got.Name != "future" || len(got.Connections) != 1 || got.Connections[0].Address != "a:1" || got.SelectedMethod != "m" {
+		t.Errorf("import = %+v", *got)
+	}
+}
+
+func TestImportWorkspace_Rejects(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":       `nope`,
+		"no kind":        `{"version": 1, "workspace": {"Name": "x"}}`,
+		"newer version":  `{"kind": "grotto-workspace", "version": 2, "workspace": {"Name": "x"}}`,
+		"no version":     `{"kind": "grotto-workspace", "workspace": {"Name": "x"}}`,
+		"unsafe name":    `{"kind": "grotto-workspace", "version": 1, "workspace": {"Name": "../x"}}`,
+		"missing name":   `{"kind": "grotto-workspace", "version": 1, "workspace": {}}`,
+		"wrong document": `{"version": 1, "data": {"Name": "x"}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ImportWorkspace([]byte(data)); err == nil {
+				t.Error("ImportWorkspace succeeded, want error")
+			}
+		})
+	}
+}
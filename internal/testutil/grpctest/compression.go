@@ -0,0 +1,57 @@
+package grpctest
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// WithRequiredCompression rejects TestService calls whose requests are not
+// compressed with name (e.g. "gzip") as Unimplemented, the way some
+// proxies do. Reflection and health stay available uncompressed.
+func WithRequiredCompression(name string) Option {
+	return func(c *config) {
+		c.compression = name
+		c.serverOpts = append(c.serverOpts, grpc.StatsHandler(requestEncodingStats{}))
+	}
+}
+
+type requestEncodingKey struct{}
+
+// requestEncodingStats records each call's request compression in its
+// context, where the interceptors can check it.
+type requestEncodingStats struct{}
+
+func (requestEncodingStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, requestEncodingKey{}, new(string))
+}
+
+func (requestEncodingStats) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if h, ok := s.(*stats.InHeader); ok {
+		if enc, ok := ctx.Value(requestEncodingKey{}).(*string); ok {
+			*enc = h.Compression
+		}
+	}
+}
+
+func (requestEncodingStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (requestEncodingStats) HandleConn(context.Context, stats.ConnStats) {}
+
+// checkCompression enforces WithRequiredCompression.
+func (c *config) checkCompression(ctx context.Context, method string) error {
+	if c.compression == "" || !strings.HasPrefix(method, "/grpctest.TestService/") {
+		return nil
+	}
+	if enc, _ := ctx.Value(requestEncodingKey{}).(*string); enc == nil || *enc != c.compression {
+		return status.Errorf(codes.Unimplemented, "grpctest: %s requires %s compression", method, c.compression)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package grpctest
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// EventTime is the created_at (and date) of every event served by
+// WithEventService, in Unix seconds.
+const EventTime = 1700000000
+
+// WithEventService implements custom.event.v1.EventService from
+// NonCanonicalFiles, so the malformed descriptors can be invoked end to end.
+// Pair it with WithReflectionFiles(NonCanonicalFiles()...).
+//
+// GetEvent returns an Event named "event <id>" costing 1250 EUR, created
+// at EventTime; GetEvents returns the events "<id>-1" and "<id>-2". No
+// generated code exists for these types, so messages are encoded by hand.
+func WithEventService() Option {
+	return WithService(func(s *grpc.Server) {
+		s.RegisterService(&eventServiceDesc, nil)
+	})
+}
+
+var eventServiceDesc = grpc.ServiceDesc{
+	ServiceName: "custom.event.v1.EventService",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GetEvent", Handler: eventHandler("GetEvent", func(id string) []byte {
+			return eventBytes("event " + id)
+		})},
+		{MethodName: "GetEvents", Handler: eventHandler("GetEvents", func(id string) []byte {
+			var b []byte
+			for _, name := range []string{id + "-1", id + "-2"} {
+				b = protowire.AppendTag(b, 1, protowire.BytesType)
+				b = protowire.AppendBytes(b, eventBytes(name))
+			}
+			return b
+		})},
+	},
+}
+
+// eventHandler adapts respond, which encodes the response for a
+// GetEventRequest id, to a unary method handler. Messages travel as the
+// unknown fields of an Empty, which the proto codec carries verbatim.
+func eventHandler(method string, respond func(id string) []byte) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := &emptypb.Empty{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		handler := func(_ context.Context, req any) (any, error) {
+			resp := &emptypb.Empty{}
+			resp.ProtoReflect().SetUnknown(respond(eventRequestID(req.(*emptypb.Empty))))
+			return resp, nil
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/custom.event.v1.EventService/" + method}
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+// eventRequestID reads field 1 (id) of a GetEventRequest.
+func eventRequestID(req *emptypb.Empty) string {
+	b := []byte(req.ProtoReflect().GetUnknown())
+	var id string
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			break
+		}
+		b = b[n:]
+		if num == 1 && typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				break
+			}
+			id = string(v)
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			break
+		}
+		b = b[m:]
+	}
+	return id
+}
+
+// eventBytes encodes an Event with the given name.
+func eventBytes(name string) []byte {
+	var timestamp []byte // google.protobuf.Timestamp{seconds: EventTime}
+	timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, EventTime)
+
+	var money []byte // custom.types.Money{amount: 1250, currency: "EUR"}
+	money = protowire.AppendTag(money, 1, protowire.VarintType)
+	money = protowire.AppendVarint(money, 1250)
+	money = protowire.AppendTag(money, 2, protowire.BytesType)
+	money = protowire.AppendString(money, "EUR")
+
+	var date []byte // custom.common.DateValue{value: timestamp}
+	date = protowire.AppendTag(date, 1, protowire.BytesType)
+	date = protowire.AppendBytes(date, timestamp)
+
+	var event []byte
+	event = protowire.AppendTag(event, 1, protowire.BytesType)
+	event = protowire.AppendString(event, name)
+	event = protowire.AppendTag(event, 2, protowire.BytesType)
+	event = protowire.AppendBytes(event, timestamp)
+	event = protowire.AppendTag(event, 3, protowire.BytesType)
+	event = protowire.AppendBytes(event, money)
+	event = protowire.AppendTag(event, 4, protowire.BytesType)
+	event = protowire.AppendBytes(event, date)
+	return event
+}
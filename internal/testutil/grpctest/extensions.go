@@ -0,0 +1,125 @@
+package grpctest
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ExtensionFiles returns proto2 descriptors with extensions, matching
+// testdata/extensions, for use with WithReflectionRegistry and
+// WithExtensionService. ext.v1.RecordService.Echo takes and returns an
+// ext.v1.Record, which is extended with [ext.v1.color] (string, 100) and
+// [ext.v1.Audit.audit] (message, 101) in a file that record.proto does not
+// import, so a client only finds them by asking for Record's extensions.
+func ExtensionFiles() []*descriptorpb.FileDescriptorProto {
+	typeBool := descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	return []*descriptorpb.FileDescriptorProto{
+		{
+			Name:    proto.String("ext/v1/record.proto"),
+			Package: proto.String("ext.v1"),
+			Syntax:  proto.String("proto2"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Record"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), JsonName: proto.String("id"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+					{Name: proto.String("active"), JsonName: proto.String("active"), Number: proto.Int32(2), Type: &typeBool, Label: &labelOptional},
+				},
+				ExtensionRange: []*descriptorpb.DescriptorProto_ExtensionRange{
+					{Start: proto.Int32(100), End: proto.Int32(200)},
+				},
+			}},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("RecordService"),
+				Method: []*descriptorpb.MethodDescriptorProto{{
+					Name:       proto.String("Echo"),
+					InputType:  proto.String(".ext.v1.Record"),
+					OutputType: proto.String(".ext.v1.Record"),
+				}},
+			}},
+		},
+		{
+			Name:       proto.String("ext/v1/record_ext.proto"),
+			Package:    proto.String("ext.v1"),
+			Syntax:     proto.String("proto2"),
+			Dependency: []string{"ext/v1/record.proto"},
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Audit"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("by"), JsonName: proto.String("by"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+				},
+				Extension: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("audit"), JsonName: proto.String("audit"), Number: proto.Int32(101), Type: &typeMessage, TypeName: proto.String(".ext.v1.Audit"), Label: &labelOptional, Extendee: proto.String(".ext.v1.Record")},
+				},
+			}},
+			Extension: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("color"), JsonName: proto.String("color"), Number: proto.Int32(100), Type: &typeString, Label: &labelOptional, Extendee: proto.String(".ext.v1.Record")},
+			},
+		},
+	}
+}
+
+// WithExtensionService handles ext.v1.RecordService from ExtensionFiles,
+// whose Echo returns the request as sent, extensions included. Pair it
+// with WithReflectionRegistry(ExtensionFiles()...).
+func WithExtensionService() Option {
+	return WithService(func(s *grpc.Server) {
+		s.RegisterService(&recordServiceDesc, nil)
+	})
+}
+
+var recordServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ext.v1.RecordService",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Echo", Handler: echoRecord},
+	},
+}
+
+// echoRecord returns the request unchanged. The record travels as the
+// unknown fields of an Empty, which the proto codec carries verbatim.
+func echoRecord(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := &emptypb.Empty{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(_ context.Context, req any) (any, error) {
+		return req, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/ext.v1.RecordService/Echo"}
+	return interceptor(ctx, req, info, handler)
+}
+
+// registryExtensions returns the extensions declared in files, for the
+// reflection service to answer extension requests from.
+func registryExtensions(files *protoregistry.Files) *protoregistry.Types {
+	types := new(protoregistry.Types)
+	var register func(exts protoreflect.ExtensionDescriptors)
+	register = func(exts protoreflect.ExtensionDescriptors) {
+		for i := range exts.Len() {
+			_ = types.RegisterExtension(dynamicpb.NewExtensionType(exts.Get(i)))
+		}
+	}
+	var visit func(msgs protoreflect.MessageDescriptors)
+	visit = func(msgs protoreflect.MessageDescriptors) {
+		for i := range msgs.Len() {
+			register(msgs.Get(i).Extensions())
+			visit(msgs.Get(i).Messages())
+		}
+	}
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		register(fd.Extensions())
+		visit(fd.Messages())
+		return true
+	})
+	return types
+}
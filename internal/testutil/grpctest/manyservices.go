@@ -0,0 +1,88 @@
+package grpctest
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ManyServiceFiles returns well-formed descriptors for a large server, for
+// use with WithReflectionRegistry: n files each declaring one service
+// (many.v1.Service0 to many.v1.Service<n-1>) whose methods take and return
+// messages from a shared many/v1/common.proto, which in turn imports
+// google/protobuf/timestamp.proto. Most real services share their
+// dependencies like this.
+func ManyServiceFiles(n int) []*descriptorpb.FileDescriptorProto {
+	files := []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		manyCommonFile(),
+	}
+	for i := range n {
+		name := fmt.Sprintf("Service%d", i)
+		files = append(files, &descriptorpb.FileDescriptorProto{
+			Name:       proto.String(fmt.Sprintf("many/v1/service%d.proto", i)),
+			Package:    proto.String("many.v1"),
+			Syntax:     proto.String("proto3"),
+			Dependency: []string{"many/v1/common.proto"},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String(name),
+				Method: []*descriptorpb.MethodDescriptorProto{{
+					Name:       proto.String("Get"),
+					InputType:  proto.String(".many.v1.Request"),
+					OutputType: proto.String(".many.v1.Response"),
+				}},
+			}},
+		})
+	}
+	return files
+}
+
+// manyCommonFile declares the request and response shared by every
+// service in ManyServiceFiles.
+func manyCommonFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("many/v1/common.proto"),
+		Package:    proto.String("many.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Request"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional, JsonName: proto.String("id")},
+				},
+			},
+			{
+				Name: proto.String("Response"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional, JsonName: proto.String("id")},
+					{Name: proto.String("updated_at"), Number: proto.Int32(2), Type: &typeMessage, Label: &labelOptional, TypeName: proto.String(".google.protobuf.Timestamp"), JsonName: proto.String("updatedAt")},
+				},
+			},
+		},
+	}
+}
+
+// registryServices lists the services declared in a registry, standing in
+// for a *grpc.Server as the reflection service's source of service names.
+type registryServices struct {
+	files *protoregistry.Files
+}
+
+// GetServiceInfo implements reflection.ServiceInfoProvider.
+func (s registryServices) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := make(map[string]grpc.ServiceInfo)
+	s.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := range fd.Services().Len() {
+			info[string(fd.Services().Get(i).FullName())] = grpc.ServiceInfo{Metadata: fd.Path()}
+		}
+		return true
+	})
+	return info
+}
@@ -0,0 +1,240 @@
+package grpctest
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// NonCanonicalFiles returns descriptors reproducing real-world servers with
+// non-canonical protobuf naming, for use with WithReflectionFiles. They
+// serve custom.event.v1.EventService (GetEvent, GetEvents) and contain:
+//
+//   - a WKT barrel file "google_protobuf.proto" defining Timestamp and
+//     Duration in place of the canonical google/protobuf/*.proto files
+//   - an event_service.proto with an empty dependency list despite
+//     referencing types from the other three files
+//   - map entry messages missing the "Entry" suffix (KeyValues, EventsByOrg)
+//   - cross-file type references by short, relative names
+func NonCanonicalFiles() []*descriptorpb.FileDescriptorProto {
+	return []*descriptorpb.FileDescriptorProto{
+		nonCanonicalEventFile(),
+		nonCanonicalWKTFile(),
+		nonCanonicalTypesFile(),
+		nonCanonicalCommonFile(),
+	}
+}
+
+var (
+	typeInt32     = descriptorpb.FieldDescriptorProto_TYPE_INT32
+	typeInt64     = descriptorpb.FieldDescriptorProto_TYPE_INT64
+	typeString    = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	typeMessage   = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	labelOptional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	labelRepeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+)
+
+// nonCanonicalWKTFile creates a non-canonical WKT barrel file.
+// Instead of the standard google/protobuf/timestamp.proto etc., the server
+// provides a single file "google_protobuf.proto" that defines both Timestamp
+// and Duration in the google.protobuf package.
+func nonCanonicalWKTFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("google_protobuf.proto"),
+		Package: proto.String("google.protobuf"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Timestamp"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("seconds"), Number: proto.Int32(1), Type: &typeInt64, Label: &labelOptional},
+					{Name: proto.String("nanos"), Number: proto.Int32(2), Type: &typeInt32, Label: &labelOptional},
+				},
+			},
+			{
+				Name: proto.String("Duration"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("seconds"), Number: proto.Int32(1), Type: &typeInt64, Label: &labelOptional},
+					{Name: proto.String("nanos"), Number: proto.Int32(2), Type: &typeInt32, Label: &labelOptional},
+				},
+			},
+		},
+	}
+}
+
+// nonCanonicalTypesFile creates a file with standalone message types.
+func nonCanonicalTypesFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("custom_types.proto"),
+		Package: proto.String("custom.types"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Money"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("amount"), Number: proto.Int32(1), Type: &typeInt64, Label: &labelOptional},
+					{Name: proto.String("currency"), Number: proto.Int32(2), Type: &typeString, Label: &labelOptional},
+				},
+			},
+			{
+				Name: proto.String("Image"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("url"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+					{Name: proto.String("width"), Number: proto.Int32(2), Type: &typeInt32, Label: &labelOptional},
+					{Name: proto.String("height"), Number: proto.Int32(3), Type: &typeInt32, Label: &labelOptional},
+				},
+			},
+		},
+	}
+}
+
+// nonCanonicalCommonFile creates a file with a non-canonical map entry name.
+// The map field "key_values" generates entry "KeyValues" (no "Entry" suffix),
+// reproducing a real-world server pattern.
+func nonCanonicalCommonFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("common.proto"),
+		Package:    proto.String("custom.common"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("DateValue"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("value"),
+						Number:   proto.Int32(1),
+						Type:     &typeMessage,
+						TypeName: proto.String("google.protobuf.Timestamp"), // WKT - no leading dot
+						Label:    &labelOptional,
+					},
+				},
+			},
+			{
+				Name: proto.String("KeyValues"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("key_values"),
+						Number:   proto.Int32(1),
+						Type:     &typeMessage,
+						TypeName: proto.String("KeyValues"), // relative, no "Entry" suffix
+						Label:    &labelRepeated,
+					},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name:    proto.String("KeyValues"), // no "Entry" suffix
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{Name: proto.String("key"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+							{Name: proto.String("value"), Number: proto.Int32(2), Type: &typeString, Label: &labelOptional},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// nonCanonicalEventFile creates the service file with EMPTY dependency array
+// despite referencing types from google_protobuf.proto, custom_types.proto,
+// and common.proto. It also has a non-canonical map entry name for events_by_org.
+func nonCanonicalEventFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("event_service.proto"),
+		Package:    proto.String("custom.event.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{}, // EMPTY - missing all imports!
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Event"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("name"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+					{
+						Name:     proto.String("created_at"),
+						Number:   proto.Int32(2),
+						Type:     &typeMessage,
+						TypeName: proto.String("google.protobuf.Timestamp"), // WKT - no leading dot
+						Label:    &labelOptional,
+					},
+					{
+						Name:     proto.String("price"),
+						Number:   proto.Int32(3),
+						Type:     &typeMessage,
+						TypeName: proto.String("types.Money"), // cross-file - short prefix
+						Label:    &labelOptional,
+					},
+					{
+						Name:     proto.String("date"),
+						Number:   proto.Int32(4),
+						Type:     &typeMessage,
+						TypeName: proto.String("common.DateValue"), // cross-file - short prefix
+						Label:    &labelOptional,
+					},
+				},
+			},
+			{
+				Name: proto.String("GetEventRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+				},
+			},
+			{
+				Name: proto.String("GetEventsResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("events"),
+						Number:   proto.Int32(1),
+						Type:     &typeMessage,
+						TypeName: proto.String("Event"), // same-file - bare name
+						Label:    &labelRepeated,
+					},
+				},
+			},
+			{
+				Name: proto.String("EventsByOrg"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("events_by_org"),
+						Number:   proto.Int32(1),
+						Type:     &typeMessage,
+						TypeName: proto.String("EventsByOrg"), // relative, no "Entry" suffix
+						Label:    &labelRepeated,
+					},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name:    proto.String("EventsByOrg"), // no "Entry" suffix
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{Name: proto.String("key"), Number: proto.Int32(1), Type: &typeString, Label: &labelOptional},
+							{
+								Name:     proto.String("value"),
+								Number:   proto.Int32(2),
+								Type:     &typeMessage,
+								TypeName: proto.String("Event"), // same-file - bare name
+								Label:    &labelOptional,
+							},
+						},
+					},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("EventService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("GetEvent"),
+						InputType:  proto.String(".custom.event.v1.GetEventRequest"),
+						OutputType: proto.String(".custom.event.v1.Event"),
+					},
+					{
+						Name:       proto.String("GetEvents"),
+						InputType:  proto.String(".custom.event.v1.GetEventRequest"),
+						OutputType: proto.String(".custom.event.v1.GetEventsResponse"),
+					},
+				},
+			},
+		},
+	}
+}
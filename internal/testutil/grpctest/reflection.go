@@ -0,0 +1,206 @@
+package grpctest
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// rawReflectionServer answers reflection requests from hand-supplied
+// FileDescriptorProtos without building them, so it can serve descriptors
+// that protodesc would reject.
+//
+// Like the servers it imitates, it answers a symbol lookup with the
+// declaring file followed by every other supplied file, since the
+// malformed files cannot be trusted to declare their dependencies.
+// Standalone files (such as the health service's) are answered alone.
+type rawReflectionServer struct {
+	reflectionpb.UnimplementedServerReflectionServer
+	services   []string
+	byName     map[string][]byte
+	bySymbol   map[string]string
+	order      []string
+	standalone map[string]bool
+}
+
+// v1alphaReflectionDesc is the v1 reflection service under its v1alpha
+// name. The messages are wire-compatible, so rawReflectionServer serves
+// both unchanged.
+var v1alphaReflectionDesc = func() grpc.ServiceDesc {
+	desc := reflectionpb.ServerReflection_ServiceDesc
+	desc.ServiceName = "grpc.reflection.v1alpha.ServerReflection"
+	return desc
+}()
+
+// newRawReflectionServer indexes files, then standalone, by name and by the
+// symbols they declare.
+func newRawReflectionServer(files []*descriptorpb.FileDescriptorProto, standalone ...*descriptorpb.FileDescriptorProto) (*rawReflectionServer, error) {
+	s := &rawReflectionServer{
+		byName:     make(map[string][]byte),
+		bySymbol:   make(map[string]string),
+		standalone: make(map[string]bool),
+	}
+	add := func(fdp *descriptorpb.FileDescriptorProto, alone bool) error {
+		data, err := proto.Marshal(fdp)
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", fdp.GetName(), err)
+		}
+		s.byName[fdp.GetName()] = data
+		s.order = append(s.order, fdp.GetName())
+		s.standalone[fdp.GetName()] = alone
+		for _, sym := range fileSymbols(fdp) {
+			s.bySymbol[sym] = fdp.GetName()
+		}
+		for _, svc := range fdp.GetService() {
+			s.services = append(s.services, qualify(fdp.GetPackage(), svc.GetName()))
+		}
+		return nil
+	}
+	for _, fdp := range files {
+		if err := add(fdp, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, fdp := range standalone {
+		if err := add(fdp, true); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// ServerReflectionInfo implements grpc_reflection_v1.ServerReflectionServer.
+func (s *rawReflectionServer) ServerReflectionInfo(stream grpc.BidiStreamingServer[reflectionpb.ServerReflectionRequest, reflectionpb.ServerReflectionResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp := &reflectionpb.ServerReflectionResponse{OriginalRequest: req}
+		switch req.MessageRequest.(type) {
+		case *reflectionpb.ServerReflectionRequest_ListServices:
+			list := &reflectionpb.ListServiceResponse{}
+			for _, name := range s.services {
+				list.Service = append(list.Service, &reflectionpb.ServiceResponse{Name: name})
+			}
+			resp.MessageResponse = &reflectionpb.ServerReflectionResponse_ListServicesResponse{ListServicesResponse: list}
+
+		case *reflectionpb.ServerReflectionRequest_FileContainingSymbol:
+			symbol := req.GetFileContainingSymbol()
+			if name, ok := s.bySymbol[symbol]; ok {
+				resp.MessageResponse = s.filesResponse(s.related(name))
+			} else {
+				resp.MessageResponse = errorResponse(codes.NotFound, "symbol not found: "+symbol)
+			}
+
+		case *reflectionpb.ServerReflectionRequest_FileByFilename:
+			filename := req.GetFileByFilename()
+			if _, ok := s.byName[filename]; ok {
+				resp.MessageResponse = s.filesResponse([]string{filename})
+			} else {
+				resp.MessageResponse = errorResponse(codes.NotFound, "file not found: "+filename)
+			}
+
+		default:
+			resp.MessageResponse = errorResponse(codes.Unimplemented, "request type not supported")
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// related returns name followed by the other non-standalone files.
+func (s *rawReflectionServer) related(name string) []string {
+	names := []string{name}
+	if s.standalone[name] {
+		return names
+	}
+	for _, other := range s.order {
+		if other != name && !s.standalone[other] {
+			names = append(names, other)
+		}
+	}
+	return names
+}
+
+// filesResponse wraps the named files in a reflection response.
+func (s *rawReflectionServer) filesResponse(names []string) *reflectionpb.ServerReflectionResponse_FileDescriptorResponse {
+	files := make([][]byte, 0, len(names))
+	for _, name := range names {
+		files = append(files, s.byName[name])
+	}
+	return &reflectionpb.ServerReflectionResponse_FileDescriptorResponse{
+		FileDescriptorResponse: &reflectionpb.FileDescriptorResponse{FileDescriptorProto: files},
+	}
+}
+
+// errorResponse builds a reflection error response.
+func errorResponse(code codes.Code, msg string) *reflectionpb.ServerReflectionResponse_ErrorResponse {
+	return &reflectionpb.ServerReflectionResponse_ErrorResponse{
+		ErrorResponse: &reflectionpb.ErrorResponse{
+			ErrorCode:    int32(code),
+			ErrorMessage: msg,
+		},
+	}
+}
+
+// fileSymbols lists the fully-qualified names declared in fdp: messages
+// (including nested ones), enums, services and methods.
+func fileSymbols(fdp *descriptorpb.FileDescriptorProto) []string {
+	pkg := fdp.GetPackage()
+	var syms []string
+	var addMessages func(prefix string, msgs []*descriptorpb.DescriptorProto)
+	addMessages = func(prefix string, msgs []*descriptorpb.DescriptorProto) {
+		for _, msg := range msgs {
+			name := qualify(prefix, msg.GetName())
+			syms = append(syms, name)
+			for _, enum := range msg.GetEnumType() {
+				syms = append(syms, qualify(name, enum.GetName()))
+			}
+			addMessages(name, msg.GetNestedType())
+		}
+	}
+	addMessages(pkg, fdp.GetMessageType())
+	for _, enum := range fdp.GetEnumType() {
+		syms = append(syms, qualify(pkg, enum.GetName()))
+	}
+	for _, svc := range fdp.GetService() {
+		name := qualify(pkg, svc.GetName())
+		syms = append(syms, name)
+		for _, method := range svc.GetMethod() {
+			syms = append(syms, qualify(name, method.GetName()))
+		}
+	}
+	return syms
+}
+
+// qualify joins a package or parent name and a simple name.
+func qualify(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// healthFile returns the compiled health service file for serving next to
+// raw reflection files.
+func healthFile() (*descriptorpb.FileDescriptorProto, error) {
+	fd, err := protoregistry.GlobalFiles.FindFileByPath("grpc/health/v1/health.proto")
+	if err != nil {
+		return nil, fmt.Errorf("find health proto: %w", err)
+	}
+	return protodesc.ToFileDescriptorProto(fd), nil
+}
@@ -0,0 +1,481 @@
+// Package grpctest starts in-process gRPC servers for integration tests.
+//
+// StartServer listens on a random loopback port, serves whatever the options
+// register, and returns a ready client connection; both are torn down via
+// t.Cleanup. Options compose, so a test asks for exactly the server it needs:
+//
+//	srv := grpctest.StartServer(t,
+//		grpctest.WithTestService(),
+//		grpctest.WithTLS(),
+//		grpctest.WithStatus("grpctest.TestService/UnaryEcho", codes.Unavailable),
+//	)
+//	client := pb.NewTestServiceClient(srv.Conn)
+//
+// The option set is documented by example in server_test.go.
+package grpctest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Server is a running in-process gRPC server and a client connected to it.
+type Server struct {
+	// Addr is the host:port the server listens on, or its unix:///path
+	// target with WithUnixSocket.
+	Addr string
+	// Conn is a client connection to Addr, using the server's TLS
+	// certificate when WithTLS is set.
+	Conn *grpc.ClientConn
+	// GRPC is the underlying server, for registering extra services
+	// before the first call.
+	GRPC *grpc.Server
+	// Health is the health server registered by WithHealth, or nil.
+	Health *health.Server
+	// CertPEM is the PEM-encoded self-signed certificate served by
+	// WithTLS, or nil. It doubles as the CA certificate.
+	CertPEM []byte
+
+	lis net.Listener
+}
+
+// Option configures a server started by Start or StartServer.
+type Option func(*config)
+
+// config collects the options before the server is built.
+type config struct {
+	testService     bool
+	health          bool
+	reflection      bool
+	reflectionFiles []*descriptorpb.FileDescriptorProto
+	registryFiles   []*descriptorpb.FileDescriptorProto
+	reflectionDelay time.Duration
+	v1alphaOnly     bool
+	tls             bool
+	latency         time.Duration
+	statuses        map[string]codes.Code
+	flaky           map[string]*flakyStatus
+	echoSuffix      *string
+	streamHeaders   metadata.MD
+	streamDelay     time.Duration
+	register        []func(*grpc.Server)
+	addr            string
+	unixSocket      string
+	serverOpts      []grpc.ServerOption
+	dialOpts        []grpc.DialOption
+	compression     string
+}
+
+// WithTestService registers the grpctest.TestService echo service, whose
+// Item message covers every common proto3 field kind.
+func WithTestService() Option {
+	return func(c *config) { c.testService = true }
+}
+
+// WithHealth registers the standard health service, reporting SERVING for
+// the server as a whole. Use Server.Health to change statuses.
+func WithHealth() Option {
+	return func(c *config) { c.health = true }
+}
+
+// WithReflection turns the standard reflection service on or off. It is on
+// by default.
+func WithReflection(enabled bool) Option {
+	return func(c *config) { c.reflection = enabled }
+}
+
+// WithReflectionFiles serves files verbatim through a reflection handler
+// instead of the standard one. The files are never validated, so malformed
+// descriptors (missing imports, odd map entry names) reach the client
+// exactly as a misbehaving server would send them. With WithHealth, the
+// health service's file is served alongside.
+func WithReflectionFiles(files ...*descriptorpb.FileDescriptorProto) Option {
+	return func(c *config) { c.reflectionFiles = append(c.reflectionFiles, files...) }
+}
+
+// WithReflectionRegistry serves well-formed files through the standard
+// reflection service, listing their services as if they were registered.
+// Unlike WithReflectionFiles, answers carry only a file's transitive
+// imports, once per stream, as real servers do, and extension requests are
+// answered from the extensions the files declare. Calls to those services
+// are not handled.
+func WithReflectionRegistry(files ...*descriptorpb.FileDescriptorProto) Option {
+	return func(c *config) { c.registryFiles = append(c.registryFiles, files...) }
+}
+
+// WithReflectionLatency delays every reflection response by d, like a
+// server slow to look descriptors up. Requests on one stream are answered
+// in order, so the delays add up per stream.
+func WithReflectionLatency(d time.Duration) Option {
+	return func(c *config) { c.reflectionDelay = d }
+}
+
+// WithReflectionV1Alpha serves reflection only as the older
+// grpc.reflection.v1alpha.ServerReflection service, as servers built before
+// v1 existed do. It applies to WithReflectionFiles as well.
+func WithReflectionV1Alpha() Option {
+	return func(c *config) { c.v1alphaOnly = true }
+}
+
+// WithTLS serves over TLS with a freshly generated self-signed certificate
+// for localhost and 127.0.0.1. Server.Conn trusts it; other clients can use
+// Server.CertPEM as their CA.
+func WithTLS() Option {
+	return func(c *config) { c.tls = true }
+}
+
+// WithLatency delays every call by d before it reaches the handler, or
+// until the call's context is done.
+func WithLatency(d time.Duration) Option {
+	return func(c *config) { c.latency = d }
+}
+
+// WithStatus makes method fail with code instead of reaching its handler.
+// Method is "pkg.Service/Method", with or without a leading slash.
+func WithStatus(method string, code codes.Code) Option {
+	return func(c *config) {
+		if c.statuses == nil {
+			c.statuses = make(map[string]codes.Code)
+		}
+		c.statuses["/"+strings.TrimPrefix(method, "/")] = code
+	}
+}
+
+// WithFlakyStatus makes the first failures unary calls of method fail
+// with code, and later ones reach the handler, like a backend behind a
+// load balancer that is still coming up. Method is "pkg.Service/Method",
+// with or without a leading slash.
+func WithFlakyStatus(method string, code codes.Code, failures int) Option {
+	return func(c *config) {
+		if c.flaky == nil {
+			c.flaky = make(map[string]*flakyStatus)
+		}
+		f := &flakyStatus{code: code}
+		f.remaining.Store(int32(failures))
+		c.flaky["/"+strings.TrimPrefix(method, "/")] = f
+	}
+}
+
+// flakyStatus counts down the calls a WithFlakyStatus method still fails.
+type flakyStatus struct {
+	code      codes.Code
+	remaining atomic.Int32
+}
+
+// fail reports whether this call should still fail.
+func (f *flakyStatus) fail() bool {
+	return f.remaining.Add(-1) >= 0
+}
+
+// WithEchoMetadata sends incoming metadata whose key ends in suffix back as
+// both response headers and trailers. An empty suffix echoes everything.
+func WithEchoMetadata(suffix string) Option {
+	return func(c *config) { c.echoSuffix = &suffix }
+}
+
+// WithStreamHeaders sends md as response headers as soon as a stream
+// starts, then holds back each message by delay, like a server that
+// announces a session long before it has anything to send. Reflection
+// streams are left alone.
+func WithStreamHeaders(md metadata.MD, delay time.Duration) Option {
+	return func(c *config) {
+		c.streamHeaders = md
+		c.streamDelay = delay
+	}
+}
+
+// WithService calls register with the server before it starts serving, for
+// services the harness does not know about.
+func WithService(register func(*grpc.Server)) Option {
+	return func(c *config) { c.register = append(c.register, register) }
+}
+
+// WithListenAddress listens on addr instead of a random loopback port,
+// typically to restart a server where a previous one was stopped.
+func WithListenAddress(addr string) Option {
+	return func(c *config) { c.addr = addr }
+}
+
+// WithUnixSocket listens on a unix domain socket at path instead of TCP.
+// Server.Addr is then the unix:///path target.
+func WithUnixSocket(path string) Option {
+	return func(c *config) { c.unixSocket = path }
+}
+
+// WithServerOptions passes extra options to grpc.NewServer.
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(c *config) { c.serverOpts = append(c.serverOpts, opts...) }
+}
+
+// WithDialOptions passes extra options to grpc.NewClient for Server.Conn.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) { c.dialOpts = append(c.dialOpts, opts...) }
+}
+
+// StartServer starts a server configured by opts and stops it when the test
+// ends. It fails the test if the server cannot start.
+func StartServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+	srv, err := Start(opts...)
+	if err != nil {
+		t.Fatalf("grpctest: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// Start starts a server configured by opts. Callers must Close it; tests
+// should prefer StartServer, which does so automatically. Start exists for
+// TestMain, where no *testing.T is available.
+func Start(opts ...Option) (*Server, error) {
+	cfg := &config{reflection: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	srv := &Server{}
+	serverOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(cfg.unaryInterceptor),
+		grpc.ChainStreamInterceptor(cfg.streamInterceptor),
+	}, cfg.serverOpts...)
+	creds := insecure.NewCredentials()
+	if cfg.tls {
+		cert, certPEM, err := selfSignedCert()
+		if err != nil {
+			return nil, fmt.Errorf("generate certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(certPEM)
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+		})))
+		creds = credentials.NewTLS(&tls.Config{RootCAs: pool})
+		srv.CertPEM = certPEM
+	}
+	srv.GRPC = grpc.NewServer(serverOpts...)
+
+	if cfg.testService {
+		pb.RegisterTestServiceServer(srv.GRPC, &testService{})
+	}
+	if cfg.health {
+		srv.Health = health.NewServer()
+		healthpb.RegisterHealthServer(srv.GRPC, srv.Health)
+		srv.Health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	}
+	for _, register := range cfg.register {
+		register(srv.GRPC)
+	}
+	switch {
+	case len(cfg.reflectionFiles) > 0:
+		var standalone []*descriptorpb.FileDescriptorProto
+		if cfg.health {
+			fdp, err := healthFile()
+			if err != nil {
+				return nil, err
+			}
+			standalone = append(standalone, fdp)
+		}
+		handler, err := newRawReflectionServer(cfg.reflectionFiles, standalone...)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.v1alphaOnly {
+			srv.GRPC.RegisterService(&v1alphaReflectionDesc, handler)
+		} else {
+			reflectionpb.RegisterServerReflectionServer(srv.GRPC, handler)
+		}
+	case len(cfg.registryFiles) > 0:
+		files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: cfg.registryFiles})
+		if err != nil {
+			return nil, fmt.Errorf("build registry files: %w", err)
+		}
+		opts := reflection.ServerOptions{
+			Services:           registryServices{files},
+			DescriptorResolver: files,
+			ExtensionResolver:  registryExtensions(files),
+		}
+		if cfg.v1alphaOnly {
+			reflectionv1alphapb.RegisterServerReflectionServer(srv.GRPC, reflection.NewServer(opts))
+		} else {
+			reflectionpb.RegisterServerReflectionServer(srv.GRPC, reflection.NewServerV1(opts))
+		}
+	case cfg.reflection && cfg.v1alphaOnly:
+		reflectionv1alphapb.RegisterServerReflectionServer(srv.GRPC,
+			reflection.NewServer(reflection.ServerOptions{Services: srv.GRPC}))
+	case cfg.reflection:
+		reflection.Register(srv.GRPC)
+	}
+
+	network, addr := "tcp", cfg.addr
+	if cfg.unixSocket != "" {
+		network, addr = "unix", cfg.unixSocket
+	} else if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	lis, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	srv.lis = lis
+	srv.Addr = lis.Addr().String()
+	if network == "unix" {
+		srv.Addr = "unix://" + addr
+	}
+	go func() { _ = srv.GRPC.Serve(lis) }()
+
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, cfg.dialOpts...)
+	target := srv.Addr
+	if cfg.tls && network == "tcp" {
+		// Dial by name so the certificate's DNS SAN is what gets verified.
+		target = net.JoinHostPort("localhost", strconv.Itoa(lis.Addr().(*net.TCPAddr).Port))
+	}
+	srv.Conn, err = grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		srv.GRPC.Stop()
+		return nil, fmt.Errorf("dial %s: %w", target, err)
+	}
+	return srv, nil
+}
+
+// Stop stops serving and releases the address, leaving Conn open, as if
+// the server process went away. Start another server WithListenAddress(Addr)
+// to bring it back.
+func (s *Server) Stop() {
+	s.GRPC.Stop()
+	// Stop only closes listeners Serve has picked up; close ours in case
+	// the serving goroutine has not started yet.
+	_ = s.lis.Close()
+}
+
+// Close closes the client connection and stops the server.
+func (s *Server) Close() {
+	_ = s.Conn.Close()
+	s.Stop()
+}
+
+// unaryInterceptor applies required compression, latency, metadata echo and forced statuses.
+func (c *config) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := c.checkCompression(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	if err := c.delay(ctx); err != nil {
+		return nil, err
+	}
+	if md := c.echoed(ctx); len(md) > 0 {
+		_ = grpc.SetHeader(ctx, md)
+		_ = grpc.SetTrailer(ctx, md)
+	}
+	if code, ok := c.statuses[info.FullMethod]; ok {
+		return nil, forcedStatus(info.FullMethod, code)
+	}
+	if f, ok := c.flaky[info.FullMethod]; ok && f.fail() {
+		return nil, forcedStatus(info.FullMethod, f.code)
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor is the streaming counterpart of unaryInterceptor.
+func (c *config) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := c.checkCompression(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	if err := c.delay(ss.Context()); err != nil {
+		return err
+	}
+	if md := c.echoed(ss.Context()); len(md) > 0 {
+		_ = ss.SetHeader(md)
+		ss.SetTrailer(md)
+	}
+	if code, ok := c.statuses[info.FullMethod]; ok {
+		return forcedStatus(info.FullMethod, code)
+	}
+	reflectionStream := strings.HasSuffix(info.FullMethod, "/ServerReflectionInfo")
+	if c.reflectionDelay > 0 && reflectionStream {
+		ss = &delayedSendStream{ServerStream: ss, delay: c.reflectionDelay}
+	}
+	if c.streamHeaders != nil && !reflectionStream {
+		if err := ss.SendHeader(c.streamHeaders); err != nil {
+			return err
+		}
+		if c.streamDelay > 0 {
+			ss = &delayedSendStream{ServerStream: ss, delay: c.streamDelay}
+		}
+	}
+	return handler(srv, ss)
+}
+
+// delayedSendStream waits before sending each message.
+type delayedSendStream struct {
+	grpc.ServerStream
+	delay time.Duration
+}
+
+// SendMsg sends m once the delay has passed or the stream is done.
+func (s *delayedSendStream) SendMsg(m any) error {
+	timer := time.NewTimer(s.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-s.Context().Done():
+		return s.Context().Err()
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// delay waits out the configured latency.
+func (c *config) delay(ctx context.Context) error {
+	if c.latency <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(c.latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// echoed returns the incoming metadata selected by WithEchoMetadata.
+func (c *config) echoed(ctx context.Context) metadata.MD {
+	if c.echoSuffix == nil {
+		return nil
+	}
+	in, _ := metadata.FromIncomingContext(ctx)
+	out := metadata.MD{}
+	for key, values := range in {
+		if strings.HasSuffix(key, *c.echoSuffix) {
+			out[key] = values
+		}
+	}
+	return out
+}
+
+// forcedStatus is the error returned for methods configured by WithStatus.
+func forcedStatus(method string, code codes.Code) error {
+	return status.Errorf(code, "grpctest: %s forced to %s", method, code)
+}
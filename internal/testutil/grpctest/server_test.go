@@ -0,0 +1,339 @@
+package grpctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflect sends a single reflection request over conn.
+func reflect(t *testing.T, conn *grpc.ClientConn, req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	t.Helper()
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.CloseSend() }()
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
+
+// listServices returns the service names reported by reflection.
+func listServices(t *testing.T, conn *grpc.ClientConn) []string {
+	t.Helper()
+	resp, err := reflect(t, conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.GetName())
+	}
+	return names
+}
+
+// fileNames decodes the file names in a FileDescriptorResponse.
+func fileNames(t *testing.T, resp *reflectionpb.ServerReflectionResponse) []string {
+	t.Helper()
+	var names []string
+	for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		require.NoError(t, proto.Unmarshal(data, fdp))
+		names = append(names, fdp.GetName())
+	}
+	return names
+}
+
+func TestStartServer_TestService(t *testing.T) {
+	srv := StartServer(t, WithTestService())
+
+	resp, err := pb.NewTestServiceClient(srv.Conn).UnaryEcho(context.Background(),
+		&pb.ItemRequest{Item: &pb.Item{Id: "1"}})
+	require.NoError(t, err)
+	assert.True(t, resp.GetOk())
+	assert.Equal(t, "1", resp.GetItem().GetId())
+
+	// Reflection is on by default.
+	assert.Contains(t, listServices(t, srv.Conn), "grpctest.TestService")
+}
+
+func TestStartServer_ReflectionOff(t *testing.T) {
+	srv := StartServer(t, WithTestService(), WithReflection(false))
+
+	_, err := reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestStartServer_Health(t *testing.T) {
+	srv := StartServer(t, WithHealth())
+	client := healthpb.NewHealthClient(srv.Conn)
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	srv.Health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
+
+func TestStartServer_TLS(t *testing.T) {
+	srv := StartServer(t, WithTestService(), WithTLS())
+	require.NotEmpty(t, srv.CertPEM)
+
+	_, err := pb.NewTestServiceClient(srv.Conn).UnaryEcho(context.Background(), &pb.ItemRequest{})
+	require.NoError(t, err, "Conn trusts the generated certificate")
+
+	plain, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer plain.Close()
+	_, err = pb.NewTestServiceClient(plain).UnaryEcho(context.Background(), &pb.ItemRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "plaintext clients are refused")
+}
+
+func TestStartServer_Latency(t *testing.T) {
+	const latency = 50 * time.Millisecond
+	srv := StartServer(t, WithTestService(), WithLatency(latency))
+	client := pb.NewTestServiceClient(srv.Conn)
+
+	start := time.Now()
+	_, err := client.UnaryEcho(context.Background(), &pb.ItemRequest{})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), latency)
+
+	ctx, cancel := context.WithTimeout(context.Background(), latency/5)
+	defer cancel()
+	_, err = client.UnaryEcho(ctx, &pb.ItemRequest{})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestStartServer_Status(t *testing.T) {
+	srv := StartServer(t,
+		WithTestService(),
+		WithStatus("grpctest.TestService/UnaryEcho", codes.Unavailable),
+		WithStatus("/grpctest.TestService/StreamItems", codes.PermissionDenied),
+	)
+	client := pb.NewTestServiceClient(srv.Conn)
+
+	_, err := client.UnaryEcho(context.Background(), &pb.ItemRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	stream, err := client.StreamItems(context.Background(), &pb.ItemRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Other methods are unaffected.
+	bidi, err := client.BidiEcho(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, bidi.Send(&pb.ItemRequest{}))
+	_, err = bidi.Recv()
+	assert.NoError(t, err)
+}
+
+func TestStartServer_FlakyStatus(t *testing.T) {
+	srv := StartServer(t,
+		WithTestService(),
+		WithFlakyStatus("grpctest.TestService/UnaryEcho", codes.Unavailable, 2),
+	)
+	client := pb.NewTestServiceClient(srv.Conn)
+
+	for range 2 {
+		_, err := client.UnaryEcho(context.Background(), &pb.ItemRequest{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+	_, err := client.UnaryEcho(context.Background(), &pb.ItemRequest{})
+	assert.NoError(t, err, "succeeds once the failures are used up")
+}
+
+func TestStartServer_EchoMetadata(t *testing.T) {
+	srv := StartServer(t, WithTestService(), WithEchoMetadata("-bin"))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"trace-bin", "\x00\x01", "x-plain", "ignored")
+	var header, trailer metadata.MD
+	_, err := pb.NewTestServiceClient(srv.Conn).UnaryEcho(ctx, &pb.ItemRequest{},
+		grpc.Header(&header), grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"\x00\x01"}, header.Get("trace-bin"))
+	assert.Equal(t, []string{"\x00\x01"}, trailer.Get("trace-bin"))
+	assert.Empty(t, header.Get("x-plain"))
+}
+
+func TestStartServer_StreamHeaders(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	srv := StartServer(t, WithTestService(), WithStreamHeaders(metadata.Pairs("session-id", "s-1"), delay))
+
+	start := time.Now()
+	stream, err := pb.NewTestServiceClient(srv.Conn).StreamItems(context.Background(), &pb.ItemRequest{})
+	require.NoError(t, err)
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s-1"}, header.Get("session-id"))
+	assert.Less(t, time.Since(start), delay, "headers come before the delay")
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+}
+
+func TestStartServer_RequiredCompression(t *testing.T) {
+	srv := StartServer(t, WithTestService(), WithRequiredCompression("gzip"))
+	client := pb.NewTestServiceClient(srv.Conn)
+
+	_, err := client.UnaryEcho(context.Background(), &pb.ItemRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = client.UnaryEcho(context.Background(), &pb.ItemRequest{}, grpc.UseCompressor("gzip"))
+	assert.NoError(t, err)
+}
+
+func TestStartServer_ReflectionFiles(t *testing.T) {
+	srv := StartServer(t,
+		WithReflectionFiles(NonCanonicalFiles()...),
+		WithHealth(),
+	)
+
+	assert.ElementsMatch(t,
+		[]string{"custom.event.v1.EventService", "grpc.health.v1.Health"},
+		listServices(t, srv.Conn))
+
+	// A symbol brings its file first, then every other malformed file.
+	resp, err := reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "custom.event.v1.EventService",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]string{"event_service.proto", "google_protobuf.proto", "custom_types.proto", "common.proto"},
+		fileNames(t, resp))
+
+	// The health file is served on its own.
+	resp, err = reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "grpc.health.v1.Health",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"grpc/health/v1/health.proto"}, fileNames(t, resp))
+
+	resp, err = reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: "common.proto"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common.proto"}, fileNames(t, resp))
+
+	resp, err = reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "no.Such"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.NotFound), resp.GetErrorResponse().GetErrorCode())
+}
+
+func TestStartServer_ReflectionRegistry(t *testing.T) {
+	const latency = 20 * time.Millisecond
+	srv := StartServer(t,
+		WithReflectionRegistry(ManyServiceFiles(3)...),
+		WithReflectionLatency(latency),
+	)
+
+	start := time.Now()
+	assert.ElementsMatch(t,
+		[]string{"many.v1.Service0", "many.v1.Service1", "many.v1.Service2"},
+		listServices(t, srv.Conn))
+	assert.GreaterOrEqual(t, time.Since(start), latency)
+
+	// A symbol brings its file and its transitive imports.
+	resp, err := reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "many.v1.Service1",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]string{"many/v1/service1.proto", "many/v1/common.proto", "google/protobuf/timestamp.proto"},
+		fileNames(t, resp))
+}
+
+func TestStartServer_ReflectionV1Alpha(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"standard": {WithTestService(), WithReflectionV1Alpha()},
+		"files":    {WithReflectionFiles(NonCanonicalFiles()...), WithReflectionV1Alpha()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := StartServer(t, opts...)
+
+			_, err := reflect(t, srv.Conn, &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+			})
+			assert.Equal(t, codes.Unimplemented, status.Code(err), "v1 is not served")
+
+			resp, err := reflectV1Alpha(t, srv.Conn, &reflectionv1alphapb.ServerReflectionRequest{
+				MessageRequest: &reflectionv1alphapb.ServerReflectionRequest_ListServices{},
+			})
+			require.NoError(t, err)
+			assert.NotEmpty(t, resp.GetListServicesResponse().GetService())
+		})
+	}
+}
+
+// reflectV1Alpha sends a single v1alpha reflection request over conn.
+func reflectV1Alpha(t *testing.T, conn *grpc.ClientConn, req *reflectionv1alphapb.ServerReflectionRequest) (*reflectionv1alphapb.ServerReflectionResponse, error) {
+	t.Helper()
+	stream, err := reflectionv1alphapb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.CloseSend() }()
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
+
+func TestStartServer_CustomService(t *testing.T) {
+	hs := health.NewServer()
+	srv := StartServer(t, WithService(func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, hs)
+	}))
+	hs.SetServingStatus("custom", healthpb.HealthCheckResponse_SERVING)
+
+	resp, err := healthpb.NewHealthClient(srv.Conn).Check(context.Background(),
+		&healthpb.HealthCheckRequest{Service: "custom"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestStartServer_ListenAddress(t *testing.T) {
+	first := StartServer(t, WithTestService())
+	first.Stop()
+
+	// A restarted server takes over the stopped one's address.
+	second := StartServer(t, WithTestService(), WithListenAddress(first.Addr))
+	assert.Equal(t, first.Addr, second.Addr)
+
+	_, err := pb.NewTestServiceClient(second.Conn).UnaryEcho(context.Background(), &pb.ItemRequest{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,73 @@
+package grpctest
+
+import (
+	"context"
+	"io"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+// testService is a trivial echo-back implementation of TestService.
+type testService struct {
+	pb.UnimplementedTestServiceServer
+}
+
+// UnaryEcho echoes the request item back with ok=true.
+func (s *testService) UnaryEcho(_ context.Context, req *pb.ItemRequest) (*pb.ItemResponse, error) {
+	return &pb.ItemResponse{
+		Item: req.GetItem(),
+		Ok:   true,
+	}, nil
+}
+
+// StreamItems sends the request item back 3 times.
+func (s *testService) StreamItems(req *pb.ItemRequest, stream pb.TestService_StreamItemsServer) error {
+	for i := 0; i < 3; i++ {
+		resp := &pb.ItemResponse{
+			Item: req.GetItem(),
+			Ok:   true,
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CollectItems collects all sent items and returns an aggregated list.
+func (s *testService) CollectItems(stream pb.TestService_CollectItemsServer) error {
+	var items []*pb.Item
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&pb.ItemList{
+				Items: items,
+				Count: int32(len(items)),
+			})
+		}
+		if err != nil {
+			return err
+		}
+		items = append(items, req.GetItem())
+	}
+}
+
+// BidiEcho echoes each request immediately as a response.
+func (s *testService) BidiEcho(stream pb.TestService_BidiEchoServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp := &pb.ItemResponse{
+			Item: req.GetItem(),
+			Ok:   true,
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package tokencmd runs external commands that print bearer tokens, such as
+// `gcloud auth print-identity-token`, and caches their output.
+package tokencmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single run of a token command.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultTTL is how long a token is reused when no TTL is configured.
+const DefaultTTL = 5 * time.Minute
+
+// waitDelay is how long to wait for a killed command's output pipes to
+// close. Grandchildren of the shell can hold them open after it exits.
+const waitDelay = time.Second
+
+// CommandError is a token command that failed to run, exited non-zero,
+// timed out or printed no token.
+type CommandError struct {
+	Command string
+	Stderr  string // trimmed standard error output
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("token command %q failed: %v", e.Command, e.Err)
+	if e.Stderr != "" {
+		msg += "\n\n" + e.Stderr
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// cached is a token and when its command ran.
+type cached struct {
+	token   string
+	fetched time.Time
+}
+
+// Runner runs token commands and caches each command's token for a TTL.
+// It is safe for concurrent use; concurrent calls for the same command may
+// each run it.
+type Runner struct {
+	// Timeout bounds each run (DefaultTimeout when zero).
+	Timeout time.Duration
+
+	now func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// NewRunner creates a runner with an empty cache.
+func NewRunner() *Runner {
+	return &Runner{now: time.Now, cache: make(map[string]cached)}
+}
+
+// Token returns the token printed by command, running it again when the
+// cached token is older than ttl (DefaultTTL when zero or negative).
+func (r *Runner) Token(ctx context.Context, command string, ttl time.Duration) (string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", errors.New("no token command configured")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	r.mu.Lock()
+	c, ok := r.cache[command]
+	r.mu.Unlock()
+	if ok && r.now().Sub(c.fetched) < ttl {
+		return c.token, nil
+	}
+
+	token, err := r.run(ctx, command)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.cache[command] = cached{token: token, fetched: r.now()}
+	r.mu.Unlock()
+	return token, nil
+}
+
+// Invalidate forgets every cached token, e.g. after the server rejects one.
+func (r *Runner) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.cache)
+}
+
+// run runs command through the shell and returns its trimmed output.
+func (r *Runner) run(ctx context.Context, command string) (string, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.WaitDelay = waitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		// Report why the command was killed rather than "signal: killed"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %v", timeout)
+		} else if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return "", &CommandError{Command: command, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", &CommandError{Command: command, Stderr: strings.TrimSpace(stderr.String()), Err: errors.New("it printed no token")}
+	}
+	return token, nil
+}
+
+// shellCommand runs command through the platform shell, so pipes and
+// quoting work as typed.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
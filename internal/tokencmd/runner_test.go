@@ -0,0 +1,105 @@
+package tokencmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeToken returns the command line running the fake token script in
+// the given mode.
+func fakeToken(t *testing.T, args ...string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake token command is a POSIX shell script")
+	}
+	script, err := filepath.Abs(filepath.Join("..", "..", "testdata", "tokencmd", "fake_token.sh"))
+	require.NoError(t, err)
+	cmd := "sh '" + script + "'"
+	for _, a := range args {
+		cmd += " '" + a + "'"
+	}
+	return cmd
+}
+
+func TestRunner_Token(t *testing.T) {
+	r := NewRunner()
+	token, err := r.Token(context.Background(), fakeToken(t, "ok", "abc.def"), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "abc.def", token, "trailing newline trimmed")
+}
+
+func TestRunner_CachesForTTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRunner()
+	r.now = func() time.Time { return now }
+	cmd := fakeToken(t, "count", filepath.Join(t.TempDir(), "runs"))
+
+	token, err := r.Token(context.Background(), cmd, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	now = now.Add(14 * time.Minute)
+	token, err = r.Token(context.Background(), cmd, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token, "still fresh")
+
+	now = now.Add(time.Minute)
+	token, err = r.Token(context.Background(), cmd, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token, "expired at the TTL")
+
+	r.Invalidate()
+	token, err = r.Token(context.Background(), cmd, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "token-3", token)
+}
+
+func TestRunner_NonZeroExit(t *testing.T) {
+	r := NewRunner()
+	cmd := fakeToken(t, "fail")
+	_, err := r.Token(context.Background(), cmd, time.Minute)
+
+	var cmdErr *CommandError
+	require.ErrorAs(t, err, &cmdErr)
+	assert.Equal(t, "ERROR: not logged in, run the login command first", cmdErr.Stderr)
+	assert.Contains(t, err.Error(), "exit status 3")
+	assert.Contains(t, err.Error(), "not logged in")
+
+	// Failures aren't cached
+	_, err = r.Token(context.Background(), cmd, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	r := NewRunner()
+	r.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := r.Token(context.Background(), fakeToken(t, "slow"), time.Minute)
+	var cmdErr *CommandError
+	require.ErrorAs(t, err, &cmdErr)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second, "killed rather than waited for")
+}
+
+func TestRunner_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewRunner().Token(ctx, fakeToken(t, "ok"), time.Minute)
+	assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+}
+
+func TestRunner_EmptyOutput(t *testing.T) {
+	_, err := NewRunner().Token(context.Background(), fakeToken(t, "empty"), time.Minute)
+	assert.ErrorContains(t, err, "printed no token")
+
+	_, err = NewRunner().Token(context.Background(), "  ", time.Minute)
+	assert.ErrorContains(t, err, "no token command")
+}
@@ -29,12 +29,14 @@ func ShowAboutDialog(parent fyne.Window) {
 // ShowShortcutDialog displays a reference of all keyboard shortcuts.
 func ShowShortcutDialog(parent fyne.Window) {
 	shortcuts := []struct{ action, key string }{
-		{"Send Request", "\u2318 Return"},
+		{"Send Request", "\u2318 Return / Ctrl Return"},
 		{"Save Workspace", "\u2318 S"},
 		{"Load Workspace", "\u2318 O"},
 		{"Focus Address Bar", "\u2318 K"},
 		{"Focus Service Browser", "\u2318 B"},
-		{"Filter Services", "\u2318 P"},
+		{"Filter Services", "\u2318 P / Ctrl K"},
+		{"Select Method", "\u2191 \u2193 Return"},
+		{"Find Type", "Ctrl T"},
 		{"Expand All Services", "\u2318 \u21e7 E"},
 		{"Collapse All Services", "\u2318 \u21e7 W"},
 		{"Clear Response", "\u2318 L"},
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/settings"
+)
+
+// windowTitle is the main window's title before any workspace marks.
+const windowTitle = "Grotto - gRPC Client"
+
+// workspaceCheckInterval is how often the workspace is captured to notice
+// unsaved changes. Autosaves happen on the next check once the configured
+// autosave interval has passed.
+const workspaceCheckInterval = 2 * time.Second
+
+// workspaceHash fingerprints a captured workspace for change detection.
+// The name and save time are left out, as are the orders of the
+// per-method requests and the library, which carry no meaning.
+func workspaceHash(ws domain.Workspace) string {
+	ws.Name = ""
+	ws.SavedAt = time.Time{}
+	ws.Requests = slices.SortedFunc(slices.Values(ws.Requests), func(a, b domain.SavedRequest) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	ws.Library = slices.SortedFunc(slices.Values(ws.Library), func(a, b domain.SavedRequest) int {
+		return cmp.Or(cmp.Compare(a.Request.Method, b.Request.Method), cmp.Compare(a.Name, b.Name))
+	})
+	data, err := json.Marshal(ws)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// workspaceTracker tells whether the workspace has unsaved changes, and
+// whether it changed since the last autosave, by comparing hashes of
+// captured snapshots rather than hooking every binding.
+type workspaceTracker struct {
+	baseline  string // Workspace as last loaded or explicitly saved
+	autosaved string // Workspace as last autosaved
+}
+
+// Reset makes ws the state that unsaved changes are measured against.
+func (t *workspaceTracker) Reset(ws domain.Workspace) {
+	t.baseline = workspaceHash(ws)
+	t.autosaved = t.baseline
+}
+
+// Check compares ws with the baseline and the last autosave, returning its
+// hash for MarkAutosaved.
+func (t *workspaceTracker) Check(ws domain.Workspace) (hash string, dirty, changed bool) {
+	hash = workspaceHash(ws)
+	return hash, hash != t.baseline, hash != t.autosaved
+}
+
+// MarkAutosaved records that the workspace with hash was autosaved.
+func (t *workspaceTracker) MarkAutosaved(hash string) {
+	t.autosaved = hash
+}
+
+// shouldOfferRestore decides at startup whether the autosave is worth
+// restoring: it must hold something other than saved, the named workspace
+// it was taken from (nil when there is none), and be the newer of the two.
+func shouldOfferRestore(autosave, saved *domain.Workspace) bool {
+	if autosave == nil {
+		return false
+	}
+	if saved == nil {
+		return true
+	}
+	if workspaceHash(*autosave) == workspaceHash(*saved) {
+		return false
+	}
+	return autosave.SavedAt.After(saved.SavedAt)
+}
+
+// workspaceTitle is the window title for the named workspace, marked with
+// an asterisk when it has unsaved changes.
+func workspaceTitle(name string, dirty bool) string {
+	title := windowTitle
+	if name != "" {
+		title += " — " + name
+	}
+	if dirty {
+		title += "*"
+	}
+	return title
+}
+
+// startWorkspaceTracking takes the current state as unchanged and starts
+// checking it in the background, keeping the title's unsaved mark up to
+// date and autosaving at the configured interval (0 turns autosave off).
+// Must be called on the main thread.
+func (w *MainWindow) startWorkspaceTracking() {
+	w.tracker.Reset(w.captureWorkspaceState())
+	w.lastAutosave = time.Now()
+
+	stop := make(chan struct{})
+	w.trackingStop = stop
+	go func() {
+		ticker := time.NewTicker(workspaceCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fyne.Do(func() { w.checkWorkspace(false) })
+			}
+		}
+	}()
+}
+
+// stopWorkspaceTracking stops background checks, autosaving any change
+// not yet kept. Must be called on the main thread.
+func (w *MainWindow) stopWorkspaceTracking() {
+	if w.trackingStop == nil {
+		return
+	}
+	close(w.trackingStop)
+	w.trackingStop = nil
+	w.checkWorkspace(true)
+}
+
+// checkWorkspace captures the workspace, marks the title when it has
+// unsaved changes, and autosaves it when it changed since the last
+// autosave and the interval has passed, or now when force is set.
+func (w *MainWindow) checkWorkspace(force bool) {
+	ws := w.captureWorkspaceState()
+	hash, dirty, changed := w.tracker.Check(ws)
+	w.window.SetTitle(workspaceTitle(w.workspaceName, dirty))
+
+	seconds := w.fyneApp.Preferences().IntWithFallback(settings.PrefAutosaveInterval, settings.DefaultAutosaveInterval)
+	if !changed || seconds <= 0 {
+		return
+	}
+	if !force && time.Since(w.lastAutosave) < time.Duration(seconds)*time.Second {
+		return
+	}
+
+	ws.Name = w.workspaceName
+	ws.SavedAt = time.Now()
+	if err := w.app.Storage().SaveAutosave(ws); err != nil {
+		w.logger.Warn("failed to autosave workspace", slog.Any("error", err))
+		return
+	}
+	w.tracker.MarkAutosaved(hash)
+	w.lastAutosave = ws.SavedAt
+	w.logger.Debug("autosaved workspace", slog.String("workspace", ws.Name))
+}
+
+// markWorkspaceSaved makes ws, just loaded or saved, the state unsaved
+// changes are measured against.
+func (w *MainWindow) markWorkspaceSaved(ws domain.Workspace) {
+	w.workspaceName = ws.Name
+	w.tracker.Reset(ws)
+	w.window.SetTitle(workspaceTitle(w.workspaceName, false))
+}
+
+// offerAutosaveRestore offers to restore the autosave left by an earlier
+// session when it is newer than the workspace it was taken from. Declining
+// discards it.
+func (w *MainWindow) offerAutosaveRestore() {
+	repo := w.app.Storage()
+	autosave, err := repo.LoadAutosave()
+	if err != nil {
+		w.logger.Warn("failed to load autosave", slog.Any("error", err))
+		return
+	}
+
+	var saved *domain.Workspace
+	if autosave != nil && autosave.Name != "" {
+		saved, _ = repo.LoadWorkspace(autosave.Name)
+	}
+	if !shouldOfferRestore(autosave, saved) {
+		return
+	}
+
+	message := "Unsaved changes from " + autosave.SavedAt.Local().Format("Jan 2 15:04") + " were kept"
+	if autosave.Name != "" {
+		message += " for workspace '" + autosave.Name + "'"
+	}
+	message += ".\n\nRestore them?"
+	dialog.ShowConfirm("Restore Unsaved Changes", message, func(restore bool) {
+		if !restore {
+			if err := repo.ClearAutosave(); err != nil {
+				w.logger.Warn("failed to discard autosave", slog.Any("error", err))
+			}
+			return
+		}
+
+		w.applyWorkspaceState(*autosave)
+		w.workspacePanel.SetWorkspaceName(autosave.Name)
+		w.workspaceName = autosave.Name
+		if saved != nil {
+			w.tracker.Reset(*saved)
+		}
+		w.tracker.MarkAutosaved(workspaceHash(*autosave))
+		w.window.SetTitle(workspaceTitle(w.workspaceName, true))
+	}, w.window)
+}
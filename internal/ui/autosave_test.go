@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleWorkspace() domain.Workspace {
+	return domain.Workspace{
+		Name: "dev",
+		Requests: []domain.SavedRequest{
+			{Name: "pkg.A/Get", Request: domain.Request{Method: "pkg.A/Get", Body: `{"id": 1}`}},
+			{Name: "pkg.B/List", Request: domain.Request{Method: "pkg.B/List", Body: `{}`}},
+		},
+		Library: []domain.SavedRequest{
+			{Name: "first", Request: domain.Request{Method: "pkg.A/Get"}},
+			{Name: "second", Request: domain.Request{Method: "pkg.A/Get"}},
+		},
+		SelectedService: "pkg.A",
+		SelectedMethod:  "Get",
+	}
+}
+
+func TestWorkspaceHash(t *testing.T) {
+	ws := sampleWorkspace()
+	hash := workspaceHash(ws)
+
+	// Name, save time and list order do not count as changes
+	same := sampleWorkspace()
+	same.Name = "other"
+	same.SavedAt = time.Now()
+	same.Requests[0], same.Requests[1] = same.Requests[1], same.Requests[0]
+	same.Library[0], same.Library[1] = same.Library[1], same.Library[0]
+	assert.Equal(t, hash, workspaceHash(same))
+	assert.Equal(t, "pkg.B/List", same.Requests[0].Name, "hashing must not reorder the caller's slices")
+
+	// Content does
+	edited := sampleWorkspace()
+	edited.Requests[0].Request.Body = `{"id": 2}`
+	assert.NotEqual(t, hash, workspaceHash(edited))
+
+	moved := sampleWorkspace()
+	moved.SelectedMethod = "List"
+	assert.NotEqual(t, hash, workspaceHash(moved))
+}
+
+func TestWorkspaceTracker(t *testing.T) {
+	var tracker workspaceTracker
+	ws := sampleWorkspace()
+	tracker.Reset(ws)
+
+	_, dirty, changed := tracker.Check(ws)
+	assert.False(t, dirty)
+	assert.False(t, changed)
+
+	// An edit is unsaved and due for autosave until autosaved
+	edited := sampleWorkspace()
+	edited.SelectedMethod = "List"
+	hash, dirty, changed := tracker.Check(edited)
+	assert.True(t, dirty)
+	assert.True(t, changed)
+	tracker.MarkAutosaved(hash)
+	_, dirty, changed = tracker.Check(edited)
+	assert.True(t, dirty, "autosaving does not save the workspace")
+	assert.False(t, changed)
+
+	// Undoing the edit is clean again, but differs from the autosave
+	_, dirty, changed = tracker.Check(ws)
+	assert.False(t, dirty)
+	assert.True(t, changed)
+
+	// Saving makes the edit the new baseline
+	tracker.Reset(edited)
+	_, dirty, changed = tracker.Check(edited)
+	assert.False(t, dirty)
+	assert.False(t, changed)
+}
+
+func TestShouldOfferRestore(t *testing.T) {
+	savedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	saved := sampleWorkspace()
+	saved.SavedAt = savedAt
+
+	newer := sampleWorkspace()
+	newer.SelectedMethod = "List"
+	newer.SavedAt = savedAt.Add(time.Minute)
+
+	older := newer
+	older.SavedAt = savedAt.Add(-time.Minute)
+
+	unchanged := sampleWorkspace()
+	unchanged.SavedAt = savedAt.Add(time.Minute)
+
+	tests := []struct {
+		name            string
+		autosave, saved *domain.Workspace
+		want            bool
+	}{
+		{"no autosave", nil, &saved, false},
+		{"no saved workspace", &newer, nil, true},
+		{"newer than saved", &newer, &saved, true},
+		{"older than saved", &older, &saved, false},
+		{"same as saved", &unchanged, &saved, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shouldOfferRestore(tt.autosave, tt.saved))
+		})
+	}
+}
+
+func TestWorkspaceTitle(t *testing.T) {
+	assert.Equal(t, "Grotto - gRPC Client", workspaceTitle("", false))
+	assert.Equal(t, "Grotto - gRPC Client*", workspaceTitle("", true))
+	assert.Equal(t, "Grotto - gRPC Client — dev", workspaceTitle("dev", false))
+	assert.Equal(t, "Grotto - gRPC Client — dev*", workspaceTitle("dev", true))
+}
@@ -1,52 +1,45 @@
 package bidi
 
 import (
-	"fmt"
-	"strings"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	"github.com/shhac/grotto/internal/ui/response"
-	"github.com/shhac/grotto/internal/ui/streamconst"
+	"github.com/shhac/grotto/internal/export"
+	"github.com/shhac/grotto/internal/ui/components"
+	"github.com/shhac/grotto/internal/ui/streambatch"
 )
 
 // BidiStreamPanel provides UI for bidirectional streaming RPCs.
-// It displays sent and received messages in a split view, allowing the user
-// to send multiple messages while simultaneously receiving responses.
+// It shows the conversation as one chronological transcript of sent and
+// received messages, above the editor for the next message to send.
 type BidiStreamPanel struct {
 	widget.BaseWidget
 
 	window fyne.Window
 
-	// Send side (left)
-	messageEntry *widget.Entry      // Current message to send
-	sentList     *widget.List       // List of sent messages
-	sentMessages binding.StringList // Binding for sent messages
-
-	sendBtn      *widget.Button // Send current message
-	closeSendBtn *widget.Button // Close send stream
-	abortBtn     *widget.Button // Abort entire stream (cancel context)
-
-	// Receive side (right)
-	receivedList     *widget.List        // List of received messages
-	receivedMessages binding.UntypedList // Binding for received messages
-	autoScroll       bool
-	autoScrollCheck  *widget.Check
-
-	// Copy buttons
-	copySentBtn     *widget.Button
-	copyReceivedBtn *widget.Button
-
-	// Counters (including evicted messages)
-	totalSent     int
-	totalReceived int
+	// Conversation
+	transcript      *Transcript
+	transcriptList  *widget.List
+	autoScroll      bool
+	autoScrollCheck *widget.Check
+	copyBtn         *widget.Button
+	exportBtn       *widget.Button
+
+	// Send controls
+	messageEntry *widget.Entry       // Current message to send
+	batch        *streambatch.Sender // Timed sequence of messages to send
+	messageTabs  *container.AppTabs  // Next message or batch
+	sendBtn      *widget.Button      // Send current message
+	resendBtn    *widget.Button      // Pick a sent message to send again
+	closeSendBtn *widget.Button      // Close send stream
+	abortBtn     *widget.Button      // Abort entire stream (cancel context)
+	restartBtn   *widget.Button      // Open a new stream once one has ended
 
 	// Status
 	statusLabel *widget.Label
+	headers     *components.StreamHeaders // Response headers, once sent
 
 	// Main container
 	container *fyne.Container
@@ -55,15 +48,15 @@ type BidiStreamPanel struct {
 	onSend      func(json string) // Callback when Send is clicked
 	onCloseSend func()            // Callback when Close Send is clicked
 	onAbort     func()            // Callback when Abort Stream is clicked
+	onRestart   func()            // Callback when Restart Stream is clicked
 }
 
 // NewBidiStreamPanel creates a new bidirectional streaming panel.
 func NewBidiStreamPanel(window fyne.Window) *BidiStreamPanel {
 	p := &BidiStreamPanel{
-		window:           window,
-		sentMessages:     binding.NewStringList(),
-		receivedMessages: binding.NewUntypedList(),
-		autoScroll:       true,
+		window:     window,
+		transcript: NewTranscript(),
+		autoScroll: true,
 	}
 	p.ExtendBaseWidget(p)
 	p.initializeComponents()
@@ -77,39 +70,35 @@ func (p *BidiStreamPanel) initializeComponents() {
 	p.messageEntry.SetPlaceHolder(`{"field": "value"}`)
 	p.messageEntry.Wrapping = fyne.TextWrapWord
 
-	// List of sent messages (syntax highlighted)
-	p.sentList = widget.NewList(
-		func() int {
-			return p.sentMessages.Length()
-		},
+	// Transcript: a direction and time line above each message (syntax
+	// highlighted)
+	p.transcriptList = widget.NewList(
+		p.transcript.Len,
 		func() fyne.CanvasObject {
+			header := widget.NewLabel("")
+			header.TextStyle = fyne.TextStyle{Monospace: true}
 			rt := widget.NewRichText()
 			rt.Wrapping = fyne.TextWrapBreak
-			return rt
+			return container.NewVBox(header, rt)
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			rt := obj.(*widget.RichText)
-			msg, _ := p.sentMessages.GetValue(id)
-			rt.Segments = response.HighlightJSON(msg)
-			rt.Refresh()
-		},
-	)
-
-	// List of received messages (syntax highlighted)
-	p.receivedList = widget.NewListWithData(
-		p.receivedMessages,
-		func() fyne.CanvasObject {
-			rt := widget.NewRichText()
-			rt.Wrapping = fyne.TextWrapBreak
-			return rt
-		},
-		func(item binding.DataItem, obj fyne.CanvasObject) {
-			rt := obj.(*widget.RichText)
-			if strItem, ok := item.(binding.String); ok {
-				val, _ := strItem.Get()
-				rt.Segments = response.HighlightJSON(val)
-				rt.Refresh()
+			if id >= p.transcript.Len() {
+				return
+			}
+			e := p.transcript.Entry(id)
+			row := obj.(*fyne.Container)
+			header := row.Objects[0].(*widget.Label)
+			header.Alignment = fyne.TextAlignLeading
+			header.Importance = widget.LowImportance
+			if e.Direction == export.DirectionSend {
+				// Sent messages sit on the right, as in a chat
+				header.Alignment = fyne.TextAlignTrailing
+				header.Importance = widget.HighImportance
 			}
+			header.SetText(Header(e))
+			rt := row.Objects[1].(*widget.RichText)
+			rt.Segments = components.HighlightJSON(e.JSON)
+			rt.Refresh()
 		},
 	)
 
@@ -118,6 +107,9 @@ func (p *BidiStreamPanel) initializeComponents() {
 		p.handleSend()
 	})
 
+	p.resendBtn = widget.NewButtonWithIcon("Resend", theme.HistoryIcon(), nil)
+	p.resendBtn.OnTapped = p.showResendMenu
+
 	p.closeSendBtn = widget.NewButton("Close Send", func() {
 		p.handleCloseSend()
 	})
@@ -128,107 +120,89 @@ func (p *BidiStreamPanel) initializeComponents() {
 	})
 	p.abortBtn.Importance = widget.DangerImportance
 
-	// Copy buttons
-	p.copySentBtn = widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
-		all, _ := p.sentMessages.Get()
-		if len(all) > 0 {
-			p.window.Clipboard().SetContent(strings.Join(all, "\n"))
+	p.restartBtn = widget.NewButtonWithIcon("Restart Stream", theme.ViewRefreshIcon(), func() {
+		if p.onRestart != nil {
+			p.onRestart()
 		}
 	})
+	p.restartBtn.Importance = widget.HighImportance
+	p.restartBtn.Hide()
 
-	p.copyReceivedBtn = widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
-		all, err := p.receivedMessages.Get()
-		if err != nil || len(all) == 0 {
-			return
+	// Copy the whole conversation
+	p.copyBtn = widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		if p.transcript.Len() > 0 {
+			p.window.Clipboard().SetContent(p.transcript.Text())
 		}
-		var msgs []string
-		for _, item := range all {
-			if s, ok := item.(string); ok {
-				msgs = append(msgs, s)
-			}
+	})
+
+	// Batch mode sends each message as if typed and sent one by one
+	p.batch = streambatch.NewSender()
+	p.batch.SetOnSend(p.sendMessage)
+
+	// Export both directions as NDJSON
+	p.exportBtn = widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), func() {
+		if msgs := p.Snapshot(); len(msgs) > 0 {
+			components.ShowNDJSONExport(p.window, "bidi-stream.ndjson", msgs)
 		}
-		p.window.Clipboard().SetContent(strings.Join(msgs, "\n"))
 	})
 
 	// Auto-scroll toggle
 	p.autoScrollCheck = widget.NewCheck("Auto-scroll", func(checked bool) {
 		p.autoScroll = checked
 		if checked {
-			p.receivedList.ScrollToBottom()
+			p.transcriptList.ScrollToBottom()
 		}
 	})
 	p.autoScrollCheck.SetChecked(true)
 
 	// Status label
 	p.statusLabel = widget.NewLabel("Ready")
+	p.headers = components.NewStreamHeaders()
 
 	// Build layout
 	p.buildLayout()
 }
 
-// buildLayout constructs the split view layout.
+// buildLayout constructs the transcript above the message editor.
 func (p *BidiStreamPanel) buildLayout() {
-	// Left side: Send section
-	sentCountLabel := widget.NewLabel("Sent:")
-	sentCountLabel.TextStyle = fyne.TextStyle{Bold: true}
+	transcriptLabel := widget.NewLabel("Conversation:")
+	transcriptLabel.TextStyle = fyne.TextStyle{Bold: true}
 
-	sentSection := container.NewBorder(
-		container.NewBorder(nil, nil, sentCountLabel, p.copySentBtn),
+	transcriptSection := container.NewBorder(
+		container.NewBorder(nil, nil, transcriptLabel, container.NewHBox(p.autoScrollCheck, p.copyBtn)),
 		nil, nil, nil,
-		p.sentList,
+		components.EditorThemed(p.transcriptList),
 	)
 
-	nextLabel := widget.NewLabel("Next message:")
-	nextLabel.TextStyle = fyne.TextStyle{Bold: true}
-
-	messageSection := container.NewBorder(
-		nextLabel,
-		nil, nil, nil,
-		p.messageEntry,
+	p.messageTabs = container.NewAppTabs(
+		container.NewTabItem("Next message", p.messageEntry),
+		container.NewTabItem("Send batch", p.batch),
 	)
 
 	sendButtons := container.NewHBox(
 		p.sendBtn,
+		p.resendBtn,
 		layout.NewSpacer(),
+		p.restartBtn,
 		p.closeSendBtn,
 		p.abortBtn,
 	)
 
-	leftPanel := container.NewBorder(
-		nil,         // top
-		sendButtons, // bottom (buttons)
-		nil, nil,    // left, right
-		container.NewVSplit(
-			sentSection,    // top half (sent messages)
-			messageSection, // bottom half (next message)
-		),
-	)
-
-	// Right side: Receive section
-	receivedLabel := widget.NewLabel("Received:")
-	receivedLabel.TextStyle = fyne.TextStyle{Bold: true}
-
-	rightPanel := container.NewBorder(
-		container.NewBorder(nil, nil, receivedLabel, container.NewHBox(p.autoScrollCheck, p.copyReceivedBtn)),
-		nil, nil, nil,
-		p.receivedList,
-	)
-
-	// Main split: left (send) and right (receive)
-	mainSplit := container.NewHSplit(
-		leftPanel,
-		rightPanel,
+	split := container.NewVSplit(
+		transcriptSection, // top (conversation)
+		container.NewBorder(nil, sendButtons, nil, nil, p.messageTabs), // bottom (next message)
 	)
-	mainSplit.SetOffset(0.5) // 50/50 split
+	split.SetOffset(0.6)
 
 	// Wrap with status at top
 	p.container = container.NewBorder(
 		container.NewVBox(
-			p.statusLabel,
+			container.NewBorder(nil, nil, nil, p.exportBtn, p.statusLabel),
+			p.headers,
 			widget.NewSeparator(),
 		),
 		nil, nil, nil,
-		mainSplit,
+		split,
 	)
 }
 
@@ -247,7 +221,37 @@ func (p *BidiStreamPanel) SetOnAbort(fn func()) {
 	p.onAbort = fn
 }
 
-// handleSend sends the current message and adds it to the sent list.
+// SetOnRestart sets the callback for when a new stream is requested after
+// the last one ended.
+func (p *BidiStreamPanel) SetOnRestart(fn func()) {
+	p.onRestart = fn
+}
+
+// SetBatchValidator sets the check each message of a batch must pass before
+// the batch starts.
+func (p *BidiStreamPanel) SetBatchValidator(fn func(json string) error) {
+	p.batch.SetValidator(fn)
+}
+
+// SetMessage puts msg in the Next message editor and shows it.
+func (p *BidiStreamPanel) SetMessage(msg string) {
+	p.messageEntry.SetText(msg)
+	p.messageTabs.SelectIndex(0)
+}
+
+// SetBatchMessages fills the Send batch tab with msgs and shows it.
+func (p *BidiStreamPanel) SetBatchMessages(msgs []string) {
+	p.batch.SetMessages(msgs)
+	p.messageTabs.SelectIndex(1)
+}
+
+// StopBatch stops a running batch before its next message, e.g. after a
+// send failed. The send side can still be closed.
+func (p *BidiStreamPanel) StopBatch() {
+	p.batch.Stop()
+}
+
+// handleSend sends the current message and clears the entry.
 func (p *BidiStreamPanel) handleSend() {
 	if p.onSend == nil {
 		return
@@ -258,29 +262,37 @@ func (p *BidiStreamPanel) handleSend() {
 		return // Don't send empty messages
 	}
 
-	// Call the callback
-	p.onSend(msg)
-
-	// Add to sent messages list
-	_ = p.sentMessages.Append(msg)
-	p.totalSent++
-
-	// Evict oldest if over cap
-	if count := p.sentMessages.Length(); count > streamconst.MaxStreamMessages {
-		all, err := p.sentMessages.Get()
-		if err == nil && len(all) > streamconst.MaxStreamMessages {
-			_ = p.sentMessages.Set(all[streamconst.EvictionBatch:])
-		}
-	}
+	p.sendMessage(msg)
 
 	// Clear the entry for next message
 	p.messageEntry.SetText("")
+}
 
-	// Refresh the list
-	p.sentList.Refresh()
+// sendMessage hands msg to the stream. It appears in the transcript once
+// the window reports it sent with AddSent.
+func (p *BidiStreamPanel) sendMessage(msg string) {
+	if p.onSend == nil {
+		return
+	}
+	p.onSend(msg)
+}
 
-	// Update status
-	p.updateStatus()
+// showResendMenu lists the messages sent so far; picking one sends it again.
+func (p *BidiStreamPanel) showResendMenu() {
+	var items []*fyne.MenuItem
+	for _, msg := range p.transcript.History() {
+		items = append(items, fyne.NewMenuItem(HistoryLabel(msg), func() {
+			p.sendMessage(msg)
+		}))
+	}
+	if len(items) == 0 {
+		none := fyne.NewMenuItem("Nothing sent yet", nil)
+		none.Disabled = true
+		items = append(items, none)
+	}
+	menu := fyne.NewMenu("", items...)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(p.resendBtn)
+	widget.ShowPopUpMenuAtPosition(menu, p.window.Canvas(), pos.AddXY(0, p.resendBtn.Size().Height))
 }
 
 // handleCloseSend closes the send side of the stream.
@@ -289,10 +301,12 @@ func (p *BidiStreamPanel) handleCloseSend() {
 		return
 	}
 
+	p.batch.Disable()
 	p.onCloseSend()
 
 	// Disable send controls
 	p.sendBtn.Disable()
+	p.resendBtn.Disable()
 	p.closeSendBtn.Disable()
 	p.messageEntry.Disable()
 
@@ -307,35 +321,37 @@ func (p *BidiStreamPanel) handleAbort() {
 	}
 
 	p.onAbort()
-	p.sendBtn.Disable()
-	p.closeSendBtn.Disable()
-	p.abortBtn.Disable()
-	p.messageEntry.Disable()
-	p.statusLabel.SetText("Stream aborted")
+	p.ShowEnded("Stream aborted")
 }
 
-// AddReceived adds a received message to the list (thread-safe via bindings).
-func (p *BidiStreamPanel) AddReceived(json string) {
-	p.receivedMessages.Append(json)
-	p.totalReceived++
-
-	// Evict oldest if over cap
-	if count := p.receivedMessages.Length(); count > streamconst.MaxStreamMessages {
-		all, err := p.receivedMessages.Get()
-		if err == nil && len(all) > streamconst.MaxStreamMessages {
-			_ = p.receivedMessages.Set(all[streamconst.EvictionBatch:])
-		}
-	}
+// AddSent adds a message the stream accepted to the transcript and the
+// resend history. Call it on the UI thread.
+func (p *BidiStreamPanel) AddSent(json string) {
+	p.transcript.AddSent(json)
+	p.refreshTranscript()
+}
 
-	p.receivedList.Refresh()
+// AddReceived adds a received message to the transcript. Call it on the UI
+// thread.
+func (p *BidiStreamPanel) AddReceived(json string) {
+	p.transcript.AddReceived(json)
+	p.refreshTranscript()
+}
 
-	// Auto-scroll to latest message
+// refreshTranscript shows new messages, scrolling to the latest if enabled,
+// and updates the counts.
+func (p *BidiStreamPanel) refreshTranscript() {
+	p.transcriptList.Refresh()
 	if p.autoScroll {
-		p.receivedList.ScrollToBottom()
+		p.transcriptList.ScrollToBottom()
 	}
+	p.statusLabel.SetText(p.transcript.Summary())
+}
 
-	// Update status
-	p.updateStatus()
+// Snapshot returns the kept sent and received messages in time order, for
+// exporting while the stream is still running.
+func (p *BidiStreamPanel) Snapshot() []export.StreamMessage {
+	return p.transcript.Entries()
 }
 
 // SetStatus updates the status display.
@@ -343,21 +359,10 @@ func (p *BidiStreamPanel) SetStatus(status string) {
 	p.statusLabel.SetText(status)
 }
 
-// updateStatus updates the status with message counts.
-func (p *BidiStreamPanel) updateStatus() {
-	sentVisible := p.sentMessages.Length()
-	recvVisible := p.receivedMessages.Length()
-
-	sentStr := fmt.Sprintf("%d", sentVisible)
-	if p.totalSent > sentVisible {
-		sentStr = fmt.Sprintf("%d of %d", sentVisible, p.totalSent)
-	}
-	recvStr := fmt.Sprintf("%d", recvVisible)
-	if p.totalReceived > recvVisible {
-		recvStr = fmt.Sprintf("%d of %d", recvVisible, p.totalReceived)
-	}
-
-	p.statusLabel.SetText(fmt.Sprintf("Sent: %s | Received: %s", sentStr, recvStr))
+// SetHeaders shows the stream's response headers, which may arrive long
+// before its first message.
+func (p *BidiStreamPanel) SetHeaders(md map[string]string) {
+	p.headers.Set(md)
 }
 
 // Clear resets the panel for a new stream.
@@ -365,27 +370,43 @@ func (p *BidiStreamPanel) Clear() {
 	p.messageEntry.SetText("")
 	p.messageEntry.Enable()
 
-	_ = p.sentMessages.Set([]string{})
-	p.totalSent = 0
-	p.sentList.Refresh()
-
-	_ = p.receivedMessages.Set([]interface{}{})
-	p.totalReceived = 0
-	p.receivedList.Refresh()
+	p.transcript.Clear()
+	p.transcriptList.Refresh()
 
 	p.sendBtn.Enable()
+	p.resendBtn.Enable()
 	p.closeSendBtn.Enable()
 	p.abortBtn.Enable()
+	p.restartBtn.Hide()
+	p.batch.Stop()
+	p.batch.Enable()
 
+	p.headers.Reset()
 	p.statusLabel.SetText("Ready")
 }
 
-// DisableSendControls disables the send controls (when stream errors).
-func (p *BidiStreamPanel) DisableSendControls() {
-	p.sendBtn.Disable()
+// ShowEnded shows that the stream has ended, however it ended, and readies
+// the panel for a new one: sending again opens a new stream, as does
+// Restart Stream. The transcript is kept.
+func (p *BidiStreamPanel) ShowEnded(status string) {
+	p.batch.Stop()
+	p.batch.Enable()
+	p.messageEntry.Enable()
+	p.sendBtn.Enable()
+	p.resendBtn.Enable()
 	p.closeSendBtn.Disable()
 	p.abortBtn.Disable()
-	p.messageEntry.Disable()
+	p.restartBtn.Show()
+	p.statusLabel.SetText(status)
+}
+
+// SetStreamActive shows that a stream is open.
+func (p *BidiStreamPanel) SetStreamActive() {
+	p.headers.Reset()
+	p.closeSendBtn.Enable()
+	p.abortBtn.Enable()
+	p.restartBtn.Hide()
+	p.statusLabel.SetText("Stream active")
 }
 
 // CreateRenderer implements fyne.Widget.
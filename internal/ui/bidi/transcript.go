@@ -0,0 +1,159 @@
+package bidi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shhac/grotto/internal/export"
+	"github.com/shhac/grotto/internal/ui/streamconst"
+)
+
+const (
+	// historySize is how many distinct sent messages can be resent.
+	historySize = 20
+	// historyLabelLen is the longest a message is shown in the resend menu.
+	historyLabelLen = 60
+)
+
+// Transcript is the conversation on a bidi stream: sent and received
+// messages in the order they happened, with their times. Only the newest
+// streamconst.MaxStreamMessages are kept, the oldest dropped in batches.
+// It also keeps the distinct messages sent, newest first, for resending.
+// It is not safe for concurrent use; the panel uses it on the UI thread.
+type Transcript struct {
+	entries []export.StreamMessage
+	history []string
+
+	totalSent     int // Including dropped messages
+	totalReceived int
+	keptSent      int
+	keptReceived  int
+
+	now func() time.Time
+}
+
+// NewTranscript creates an empty transcript.
+func NewTranscript() *Transcript {
+	return &Transcript{now: time.Now}
+}
+
+// AddSent records a message sent on the stream and puts it at the top of
+// the history.
+func (t *Transcript) AddSent(msg string) {
+	t.add(export.DirectionSend, msg)
+
+	t.history = slices.DeleteFunc(t.history, func(h string) bool { return h == msg })
+	t.history = slices.Insert(t.history, 0, msg)
+	if len(t.history) > historySize {
+		t.history = t.history[:historySize]
+	}
+}
+
+// AddReceived records a message received on the stream.
+func (t *Transcript) AddReceived(msg string) {
+	t.add(export.DirectionRecv, msg)
+}
+
+func (t *Transcript) add(direction, msg string) {
+	if len(t.entries) >= streamconst.MaxStreamMessages {
+		for _, e := range t.entries[:streamconst.EvictionBatch] {
+			t.count(e.Direction, -1)
+		}
+		t.entries = slices.Delete(t.entries, 0, streamconst.EvictionBatch)
+	}
+	t.entries = append(t.entries, export.StreamMessage{Direction: direction, Time: t.now(), JSON: msg})
+	t.count(direction, 1)
+	if direction == export.DirectionSend {
+		t.totalSent++
+	} else {
+		t.totalReceived++
+	}
+}
+
+// count adjusts the number of kept messages going in direction by n.
+func (t *Transcript) count(direction string, n int) {
+	if direction == export.DirectionSend {
+		t.keptSent += n
+	} else {
+		t.keptReceived += n
+	}
+}
+
+// Len returns the number of kept messages.
+func (t *Transcript) Len() int {
+	return len(t.entries)
+}
+
+// Entry returns the i-th kept message, oldest first.
+func (t *Transcript) Entry(i int) export.StreamMessage {
+	return t.entries[i]
+}
+
+// Entries returns a copy of the kept messages, oldest first.
+func (t *Transcript) Entries() []export.StreamMessage {
+	return slices.Clone(t.entries)
+}
+
+// History returns the distinct messages sent, most recent first.
+func (t *Transcript) History() []string {
+	return slices.Clone(t.history)
+}
+
+// Summary counts the messages each way, noting when older ones were
+// dropped: "Sent: 3 | Received: 800 of 1200".
+func (t *Transcript) Summary() string {
+	return fmt.Sprintf("Sent: %s | Received: %s",
+		keptOf(t.keptSent, t.totalSent), keptOf(t.keptReceived, t.totalReceived))
+}
+
+func keptOf(kept, total int) string {
+	if total > kept {
+		return fmt.Sprintf("%d of %d", kept, total)
+	}
+	return fmt.Sprintf("%d", kept)
+}
+
+// Text returns the kept messages as text, each under its Header.
+func (t *Transcript) Text() string {
+	parts := make([]string, len(t.entries))
+	for i, e := range t.entries {
+		parts[i] = Header(e) + "\n" + e.JSON
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Clear forgets every message and the history.
+func (t *Transcript) Clear() {
+	t.entries = nil
+	t.history = nil
+	t.totalSent, t.totalReceived = 0, 0
+	t.keptSent, t.keptReceived = 0, 0
+}
+
+// Header is the line shown above a message: its direction, with an arrow
+// pointing away for sent ones and in for received ones, and its time.
+func Header(e export.StreamMessage) string {
+	arrow, label := "←", "Received"
+	if e.Direction == export.DirectionSend {
+		arrow, label = "→", "Sent"
+	}
+	return fmt.Sprintf("%s %s  %s", arrow, label, e.Time.Format("15:04:05.000"))
+}
+
+// HistoryLabel shows msg on one line, compacted and shortened, for the
+// resend menu.
+func HistoryLabel(msg string) string {
+	var buf bytes.Buffer
+	line := strings.Join(strings.Fields(msg), " ")
+	if json.Compact(&buf, []byte(msg)) == nil {
+		line = buf.String()
+	}
+	if runes := []rune(line); len(runes) > historyLabelLen {
+		line = string(runes[:historyLabelLen-1]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,95 @@
+package bidi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/export"
+	"github.com/shhac/grotto/internal/ui/streamconst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTranscript returns a transcript whose clock advances a second per
+// message from 10:00:00.
+func testTranscript() *Transcript {
+	t := NewTranscript()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	t.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return t
+}
+
+func TestTranscript_Interleaves(t *testing.T) {
+	tr := testTranscript()
+	tr.AddSent(`{"n":1}`)
+	tr.AddReceived(`{"ack":1}`)
+	tr.AddReceived(`{"ack":2}`)
+	tr.AddSent(`{"n":2}`)
+
+	require.Equal(t, 4, tr.Len())
+	var order []string
+	for _, e := range tr.Entries() {
+		order = append(order, e.Direction+" "+e.JSON)
+	}
+	assert.Equal(t, []string{
+		export.DirectionSend + ` {"n":1}`,
+		export.DirectionRecv + ` {"ack":1}`,
+		export.DirectionRecv + ` {"ack":2}`,
+		export.DirectionSend + ` {"n":2}`,
+	}, order)
+	assert.True(t, tr.Entry(0).Time.Before(tr.Entry(3).Time))
+
+	assert.Equal(t, "→ Sent  10:00:01.000", Header(tr.Entry(0)))
+	assert.Equal(t, "← Received  10:00:02.000", Header(tr.Entry(1)))
+	assert.Equal(t, "Sent: 2 | Received: 2", tr.Summary())
+	assert.True(t, strings.HasPrefix(tr.Text(), "→ Sent  10:00:01.000\n{\"n\":1}\n← Received  10:00:02.000\n"))
+}
+
+func TestTranscript_History(t *testing.T) {
+	tr := testTranscript()
+	tr.AddSent("a")
+	tr.AddSent("b")
+	tr.AddReceived("ignored")
+	tr.AddSent("a")
+	assert.Equal(t, []string{"a", "b"}, tr.History(), "a resent message moves to the top once")
+
+	for i := range historySize + 5 {
+		tr.AddSent(fmt.Sprintf("m%d", i))
+	}
+	history := tr.History()
+	assert.Len(t, history, historySize)
+	assert.Equal(t, fmt.Sprintf("m%d", historySize+4), history[0])
+
+	tr.Clear()
+	assert.Empty(t, tr.History())
+	assert.Zero(t, tr.Len())
+	assert.Equal(t, "Sent: 0 | Received: 0", tr.Summary())
+}
+
+func TestTranscript_Eviction(t *testing.T) {
+	tr := testTranscript()
+	tr.AddSent("first")
+	for range streamconst.MaxStreamMessages {
+		tr.AddReceived("r")
+	}
+
+	assert.Equal(t, streamconst.MaxStreamMessages-streamconst.EvictionBatch+1, tr.Len())
+	assert.Equal(t, export.DirectionRecv, tr.Entry(0).Direction, "the oldest messages are dropped")
+	assert.Equal(t, fmt.Sprintf("Sent: 0 of 1 | Received: %d of %d",
+		tr.Len(), streamconst.MaxStreamMessages), tr.Summary())
+	assert.Equal(t, []string{"first"}, tr.History(), "dropped messages can still be resent")
+}
+
+func TestHistoryLabel(t *testing.T) {
+	assert.Equal(t, `{"id":"a","n":1}`, HistoryLabel("{\n  \"id\": \"a\",\n  \"n\": 1\n}"))
+	assert.Equal(t, "not json at all", HistoryLabel("not  json\nat all"))
+
+	long := HistoryLabel(fmt.Sprintf(`{"text":%q}`, strings.Repeat("é", 100)))
+	assert.Len(t, []rune(long), historyLabelLen)
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// bidiSession owns the bidi stream the bidi panel talks to. Sends run on
+// the UI thread and the receive loop in a goroutine; both reach the stream
+// only through the session, so a stream that has ended is never used again
+// and a new one can take its place.
+type bidiSession struct {
+	mu       sync.Mutex
+	handle   *grpc.BidiStreamHandle // nil when no stream is open
+	cancel   context.CancelFunc
+	metadata map[string]string // Request metadata of the last stream opened
+}
+
+// open starts a stream for methodDesc with the given request metadata. Any
+// stream still open is ended first.
+func (s *bidiSession) open(invoker *grpc.Invoker, methodDesc protoreflect.MethodDescriptor, metadataMap map[string]string) (*grpc.BidiStreamHandle, error) {
+	s.stop()
+
+	md, err := grpc.BuildMetadata(metadataMap)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	handle, err := invoker.InvokeBidiStream(ctx, methodDesc, md)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.handle, s.cancel, s.metadata = handle, cancel, metadataMap
+	s.mu.Unlock()
+	return handle, nil
+}
+
+// current returns the open stream, or nil if there is none.
+func (s *bidiSession) current() *grpc.BidiStreamHandle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handle
+}
+
+// lastMetadata returns the request metadata the last stream was opened
+// with, for reopening it.
+func (s *bidiSession) lastMetadata() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.metadata
+}
+
+// end cancels handle's stream if it is still the open one, reporting
+// whether it was. A stream replaced or stopped meanwhile is left alone.
+func (s *bidiSession) end(handle *grpc.BidiStreamHandle) bool {
+	s.mu.Lock()
+	if handle == nil || s.handle != handle {
+		s.mu.Unlock()
+		return false
+	}
+	cancel := s.cancel
+	s.handle, s.cancel = nil, nil
+	s.mu.Unlock()
+
+	// Outside the lock, like the window's other cancel funcs
+	cancel()
+	return true
+}
+
+// stop cancels the open stream, if any, reporting whether there was one.
+func (s *bidiSession) stop() bool {
+	return s.end(s.current())
+}
+
+// receive reads handle's messages into onMessage until the stream ends,
+// then ends it in the session. It returns the number of messages received,
+// whether it was still the open stream (so the caller knows whether the
+// panel shows it), and the error that ended the stream, nil when the server
+// finished it.
+func (s *bidiSession) receive(handle *grpc.BidiStreamHandle, onMessage func(json string)) (count int, current bool, err error) {
+	for {
+		msg, recvErr := handle.Recv()
+		if recvErr == io.EOF {
+			break
+		}
+		if recvErr != nil {
+			err = recvErr
+			break
+		}
+		count++
+		onMessage(msg)
+	}
+	return count, s.end(handle), err
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resettingService echoes limit messages on each bidi stream, then ends the
+// stream with UNAVAILABLE, as a server going away would.
+type resettingService struct {
+	pb.UnimplementedTestServiceServer
+	limit int
+}
+
+func (s *resettingService) BidiEcho(stream pb.TestService_BidiEchoServer) error {
+	for range s.limit {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.ItemResponse{Item: req.GetItem(), Ok: true}); err != nil {
+			return err
+		}
+	}
+	return status.Error(codes.Unavailable, "server going away")
+}
+
+func startResettingServer(t *testing.T, limit int) *grpc.Invoker {
+	t.Helper()
+	srv := grpctest.StartServer(t, grpctest.WithService(func(s *gogrpc.Server) {
+		pb.RegisterTestServiceServer(s, &resettingService{limit: limit})
+	}))
+	return grpc.NewInvoker(srv.Conn, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestBidiSession_ServerEndsStream(t *testing.T) {
+	invoker := startResettingServer(t, 2)
+	method := pb.File_grpc_test_proto.Services().ByName("TestService").Methods().ByName("BidiEcho")
+	md := map[string]string{"x-tenant-id": "acme"}
+
+	var s bidiSession
+	handle, err := s.open(invoker, method, md)
+	require.NoError(t, err)
+	require.Same(t, handle, s.current())
+
+	for _, msg := range []string{`{"item":{"id":"1"}}`, `{"item":{"id":"2"}}`} {
+		require.NoError(t, handle.Send(msg))
+	}
+	var received []string
+	count, current, err := s.receive(handle, func(json string) {
+		received = append(received, json)
+	})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 2, count)
+	assert.Len(t, received, 2)
+	assert.True(t, current, "the stream was still open when the server ended it")
+	assert.Nil(t, s.current(), "an ended stream is not reused")
+	assert.False(t, s.stop(), "nothing left to stop")
+
+	// Restart with the same metadata; the new stream starts empty
+	assert.Equal(t, md, s.lastMetadata())
+	restarted, err := s.open(invoker, method, s.lastMetadata())
+	require.NoError(t, err)
+	require.NoError(t, restarted.Send(`{"item":{"id":"3"}}`))
+	msg, err := restarted.Recv()
+	require.NoError(t, err)
+	assert.Contains(t, msg, `"3"`)
+
+	assert.True(t, s.stop())
+	assert.Nil(t, s.current())
+}
+
+func TestBidiSession_StaleHandle(t *testing.T) {
+	invoker := startResettingServer(t, 1)
+	method := pb.File_grpc_test_proto.Services().ByName("TestService").Methods().ByName("BidiEcho")
+
+	var s bidiSession
+	first, err := s.open(invoker, method, nil)
+	require.NoError(t, err)
+	second, err := s.open(invoker, method, nil)
+	require.NoError(t, err)
+
+	// The first stream was cancelled when the second opened; its receive
+	// loop ending must not touch the second
+	_, current, err := s.receive(first, func(string) {})
+	assert.Error(t, err)
+	assert.False(t, current)
+	assert.False(t, s.end(first))
+	assert.Same(t, second, s.current())
+
+	assert.True(t, s.end(second))
+	assert.False(t, s.end(second), "ending twice is harmless")
+	assert.False(t, s.stop())
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/protoconv"
+	"github.com/shhac/grotto/internal/ui/settings"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// decideRequestBody decides what becomes of the request body now that a
+// method taking md is selected, and empties the body when it is to be
+// cleared so the method's form never sees it.
+func (w *MainWindow) decideRequestBody(md protoreflect.MessageDescriptor, cached bool) model.BodyAction {
+	body, _ := w.state.Request.TextData.Get()
+	bodyType, _ := w.state.Request.InputType.Get()
+	carry := model.ParseBodyCarry(w.fyneApp.Preferences().String(settings.PrefBodyCarry))
+
+	action := model.BodySwitch{
+		Body:      body,
+		BodyType:  bodyType,
+		InputType: string(md.FullName()),
+		Template:  body != "" && body == w.lastTemplate,
+		Cached:    cached,
+	}.Decide(carry)
+	if action == model.BodyClear {
+		_ = w.state.Request.TextData.Set("")
+	}
+	return action
+}
+
+// carryRequestBody finishes what decideRequestBody decided, once the
+// request panel shows the method taking md: a kept body is flagged with
+// the type it was written for, and a converted one is mapped onto md.
+func (w *MainWindow) carryRequestBody(action model.BodyAction, md protoreflect.MessageDescriptor) {
+	body, _ := w.state.Request.TextData.Get()
+	bodyType, _ := w.state.Request.InputType.Get()
+
+	switch action {
+	case model.BodyKeep:
+		w.requestPanel.SetBodyNote(keptBodyNote(bodyType))
+		return
+	case model.BodyConvert:
+		if note, ok := w.convertRequestBody(body, protoreflect.FullName(bodyType), md); ok {
+			w.requestPanel.SetBodyNote(note)
+			return
+		}
+		// Could not convert, so the body stays as written
+		w.requestPanel.SetBodyNote(keptBodyNote(bodyType))
+		return
+	}
+	_ = w.state.Request.InputType.Set(string(md.FullName()))
+	w.requestPanel.SetBodyNote("")
+}
+
+// convertRequestBody replaces body, a message of type from, with the
+// fields of it that fit md, returning a note of what was done. It reports
+// false, leaving the body alone, when from is unknown or body is not
+// valid JSON.
+func (w *MainWindow) convertRequestBody(body string, from protoreflect.FullName, md protoreflect.MessageDescriptor) (string, bool) {
+	types := w.typeResolver()
+	if types == nil {
+		return "", false
+	}
+	mt, err := types.FindMessageByName(from)
+	if err != nil {
+		w.logger.Debug("cannot convert request body", slog.String("from", string(from)), slog.Any("error", err))
+		return "", false
+	}
+	converted, dropped, err := protoconv.MapMessageJSON(body, mt.Descriptor(), md)
+	if err != nil {
+		w.logger.Debug("cannot convert request body", slog.String("from", string(from)), slog.Any("error", err))
+		return "", false
+	}
+
+	_ = w.state.Request.TextData.Set(prettyJSON(converted))
+	w.requestPanel.SyncTextToForm()
+	return convertedBodyNote(from, len(dropped)), true
+}
+
+// keptBodyNote notes a body left as written for bodyType ("" if unknown).
+func keptBodyNote(bodyType string) string {
+	if bodyType == "" {
+		return "Body was written for another method"
+	}
+	return "Body was written for " + bodyType
+}
+
+// convertedBodyNote notes a body converted from type from, dropped of the
+// fields that did not fit.
+func convertedBodyNote(from protoreflect.FullName, dropped int) string {
+	switch dropped {
+	case 0:
+		return fmt.Sprintf("Body was converted from %s", from)
+	case 1:
+		return fmt.Sprintf("Body was converted from %s; 1 field did not fit", from)
+	default:
+		return fmt.Sprintf("Body was converted from %s; %d fields did not fit", from, dropped)
+	}
+}
@@ -1,12 +1,19 @@
 package browser
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	fynestorage "fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
 	"github.com/shhac/grotto/internal/model"
 	"github.com/shhac/grotto/internal/storage"
 	"github.com/shhac/grotto/internal/ui/settings"
@@ -20,16 +27,67 @@ type ConnectionBar struct {
 	connectBtn   *widget.Button
 	tlsBtn       *widget.Button
 	tlsToggleBtn *widget.Button
+	sourceBtn    *widget.Button
+	refreshBtn   *widget.Button
+	certBtn      *widget.Button
+	keepAliveChk *widget.Check
 	state        *model.ConnectionUIState
 	window       fyne.Window
 	storage      storage.Repository
 	recentConns  []domain.Connection
+	addressText  string // entry text before the last change
+
+	// Display name of the connection ("" shows just the address)
+	name string
+
+	// Entry text kept while connected
+	connectedText string
 
 	// TLS settings
 	tlsSettings domain.TLSSettings
 
-	onConnect    func(address string, tlsSettings domain.TLSSettings)
-	onDisconnect func()
+	// Transport (native gRPC or gRPC-Web)
+	transport domain.Transport
+
+	// Message size limits in bytes (0 means gRPC's default)
+	maxRecvMsgSize, maxSendMsgSize int
+
+	// Request compressor ("" for none)
+	compression string
+
+	// :authority and user-agent overrides ("" keeps gRPC's)
+	authority, userAgent string
+
+	// Keepalive pings (zero value sends none)
+	keepaliveParams domain.KeepaliveParams
+
+	// Unary call retries (zero value makes a single attempt)
+	retry domain.RetryPolicy
+
+	// HTTP CONNECT or SOCKS5 proxy (zero value connects directly)
+	proxy domain.ProxySettings
+
+	// Default authorization for calls on the connection
+	auth domain.Auth
+
+	// Metadata sent with every call on the connection
+	defaultMetadata map[string]string
+
+	// FileDescriptorSet or .proto import paths used instead of reflection
+	// (both empty means reflection)
+	descriptorSet    string
+	protoImportPaths []string
+
+	// grpc.health.v1 status of the connected server
+	health *HealthIndicator
+
+	onConnect         func(conn domain.Connection)
+	onDisconnect      func()
+	onKeepAliveChange func(enabled bool)
+	onRefreshSchema   func()
+	onCancelConnect   func()
+	onShowCertificate func()
+	onRename          func(name string)
 
 	container *fyne.Container
 }
@@ -43,7 +101,7 @@ func NewConnectionBar(state *model.ConnectionUIState, window fyne.Window, repo s
 	}
 
 	c.addressEntry = widget.NewSelectEntry(nil)
-	c.addressEntry.SetPlaceHolder("localhost:50051")
+	c.addressEntry.SetPlaceHolder("localhost:50051 or unix:///path/to.sock")
 	c.addressEntry.OnSubmitted = func(s string) {
 		c.handleButtonClick()
 	}
@@ -61,17 +119,51 @@ func NewConnectionBar(state *model.ConnectionUIState, window fyne.Window, repo s
 	})
 	c.tlsToggleBtn.Importance = widget.LowImportance
 
-	// TLS settings button with gear icon (advanced settings)
+	// Settings button with gear icon (TLS and transport settings)
 	c.tlsBtn = widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
-		c.showTLSSettings()
+		c.showConnectionSettings()
 	})
 	c.tlsBtn.Importance = widget.LowImportance
 
-	// Layout: [padlock] [address entry] [gear] [connect]
+	// Descriptor source button (reflection, FileDescriptorSet file, or .proto sources)
+	c.sourceBtn = widget.NewButtonWithIcon("", theme.FileIcon(), func() {
+		c.showSourceMenu()
+	})
+	c.updateSourceIcon()
+
+	// Refresh schema: re-fetch descriptors from the server, bypassing the
+	// on-disk schema cache. Only available while connected.
+	c.refreshBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if c.onRefreshSchema != nil {
+			c.onRefreshSchema()
+		}
+	})
+	c.refreshBtn.Importance = widget.LowImportance
+
+	// Server certificate details, for TLS connections once connected
+	c.certBtn = widget.NewButtonWithIcon("", theme.InfoIcon(), func() {
+		if c.onShowCertificate != nil {
+			c.onShowCertificate()
+		}
+	})
+	c.certBtn.Importance = widget.LowImportance
+	c.certBtn.Hide()
+
+	// Keep alive: redial automatically when the connection drops. Unlike
+	// the other settings it can be toggled while connected.
+	c.keepAliveChk = widget.NewCheck("Keep alive", func(enabled bool) {
+		if c.onKeepAliveChange != nil {
+			c.onKeepAliveChange(enabled)
+		}
+	})
+
+	c.health = NewHealthIndicator()
+
+	// Layout: [padlock] [address entry] [health] [certificate] [refresh] [source] [gear] [keep alive] [connect]
 	c.container = container.NewBorder(
 		nil, nil,
 		c.tlsToggleBtn,
-		container.NewHBox(c.tlsBtn, c.connectBtn),
+		container.NewHBox(c.health, c.certBtn, c.refreshBtn, c.sourceBtn, c.tlsBtn, c.keepAliveChk, c.connectBtn),
 		c.addressEntry,
 	)
 
@@ -88,7 +180,7 @@ func NewConnectionBar(state *model.ConnectionUIState, window fyne.Window, repo s
 }
 
 // SetOnConnect sets the callback for when the connect button is clicked while disconnected
-func (c *ConnectionBar) SetOnConnect(fn func(address string, tlsSettings domain.TLSSettings)) {
+func (c *ConnectionBar) SetOnConnect(fn func(conn domain.Connection)) {
 	c.onConnect = fn
 }
 
@@ -97,6 +189,32 @@ func (c *ConnectionBar) SetOnDisconnect(fn func()) {
 	c.onDisconnect = fn
 }
 
+// SetOnKeepAliveChange sets the callback for when the keep alive toggle changes
+func (c *ConnectionBar) SetOnKeepAliveChange(fn func(enabled bool)) {
+	c.onKeepAliveChange = fn
+}
+
+// SetOnRefreshSchema sets the callback for when the refresh schema button is clicked
+func (c *ConnectionBar) SetOnRefreshSchema(fn func()) {
+	c.onRefreshSchema = fn
+}
+
+// SetOnCancelConnect sets the callback for when the connect button is clicked while connecting
+func (c *ConnectionBar) SetOnCancelConnect(fn func()) {
+	c.onCancelConnect = fn
+}
+
+// SetOnShowCertificate sets the callback for when the certificate button is clicked
+func (c *ConnectionBar) SetOnShowCertificate(fn func()) {
+	c.onShowCertificate = fn
+}
+
+// SetOnRename sets the callback for when the connection's display name is
+// changed in the settings dialog
+func (c *ConnectionBar) SetOnRename(fn func(name string)) {
+	c.onRename = fn
+}
+
 // CreateRenderer creates the renderer for this widget
 func (c *ConnectionBar) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(c.container)
@@ -112,12 +230,19 @@ func (c *ConnectionBar) handleButtonClick() {
 	switch state {
 	case "disconnected", "error":
 		// Connect
-		address := c.resolveAddress()
-		if address == "" {
-			address = "localhost:50051" // Default
+		conn := c.GetConnection()
+		if conn.Address == "" {
+			conn.Address = "localhost:50051" // Default
+		}
+		// gRPC-Web addresses are URLs, checked when the client is built
+		if !conn.Transport.IsWeb() {
+			if err := grpc.ValidateAddress(conn.Address); err != nil {
+				dialog.ShowError(err, c.window)
+				return
+			}
 		}
 		if c.onConnect != nil {
-			c.onConnect(address, c.tlsSettings)
+			c.onConnect(conn)
 		}
 	case "connected":
 		// Disconnect
@@ -125,20 +250,134 @@ func (c *ConnectionBar) handleButtonClick() {
 			c.onDisconnect()
 		}
 	case "connecting":
-		// Do nothing while connecting
+		// Cancel
+		if c.onCancelConnect != nil {
+			c.onCancelConnect()
+		}
 	}
 }
 
-// showTLSSettings opens the TLS configuration dialog
-func (c *ConnectionBar) showTLSSettings() {
-	settings.ShowTLSDialog(c.window, c.tlsSettings, func(newSettings domain.TLSSettings) {
-		c.tlsSettings = newSettings
+// showConnectionSettings opens the name, TLS, transport, authority, proxy, auth, metadata, limits, keepalive, and retry configuration dialog.
+// Only the name takes effect while connected.
+func (c *ConnectionBar) showConnectionSettings() {
+	settings.ShowConnectionDialog(c.window, c.GetConnection(), func(updated domain.Connection) {
+		if updated.Name != c.name {
+			c.SetName(updated.Name)
+			if c.onRename != nil {
+				c.onRename(updated.Name)
+			}
+		}
+		c.tlsSettings = updated.TLS
+		c.transport = updated.Transport
+		c.compression = updated.Compression
+		c.authority, c.userAgent = updated.Authority, updated.UserAgent
+		c.proxy = updated.Proxy
+		c.auth = updated.Auth
+		c.defaultMetadata = updated.DefaultMetadata
+		c.maxRecvMsgSize, c.maxSendMsgSize = updated.MaxRecvMsgSize, updated.MaxSendMsgSize
+		c.keepaliveParams = updated.KeepaliveParams
+		c.retry = updated.Retry
 		c.updateTLSIcon()
 	})
 }
 
-// updateTLSIcon syncs the padlock icon with the current TLS enabled state.
+// showSourceMenu offers loading descriptors from a file, compiling .proto
+// sources, or going back to server reflection. The choice applies to the
+// next connection.
+func (c *ConnectionBar) showSourceMenu() {
+	useReflection := fyne.NewMenuItem("Use Server Reflection", func() {
+		c.SetDescriptorSet("")
+		c.SetProtoImportPaths(nil)
+	})
+	useReflection.Checked = c.descriptorSet == "" && len(c.protoImportPaths) == 0
+
+	loadFile := fyne.NewMenuItem("Load Descriptors from File...", func() {
+		c.showDescriptorSetDialog()
+	})
+	if c.descriptorSet != "" {
+		loadFile.Label = "Descriptors: " + filepath.Base(c.descriptorSet)
+		loadFile.Checked = true
+	}
+
+	loadProtos := fyne.NewMenuItem("Load Protos from Directory...", func() {
+		c.showProtoDirDialog(false)
+	})
+	items := []*fyne.MenuItem{useReflection, loadFile, loadProtos}
+	if len(c.protoImportPaths) > 0 {
+		loadProtos.Label = "Protos: " + filepath.Base(c.protoImportPaths[0])
+		if more := len(c.protoImportPaths) - 1; more > 0 {
+			loadProtos.Label += fmt.Sprintf(" (+%d)", more)
+		}
+		loadProtos.Checked = true
+		items = append(items, fyne.NewMenuItem("Add Import Path...", func() {
+			c.showProtoDirDialog(true)
+		}))
+	}
+
+	menu := fyne.NewMenu("", items...)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(c.sourceBtn)
+	widget.ShowPopUpMenuAtPosition(menu, c.window.Canvas(), pos.AddXY(0, c.sourceBtn.Size().Height))
+}
+
+// showDescriptorSetDialog opens a file picker for a binary FileDescriptorSet
+// (protoc --descriptor_set_out).
+func (c *ConnectionBar) showDescriptorSetDialog() {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, c.window)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		c.SetDescriptorSet(reader.URI().Path())
+	}, c.window)
+
+	fd.SetFilter(fynestorage.NewExtensionFileFilter([]string{".pb", ".binpb", ".protoset", ".desc", ".bin"}))
+	fd.Show()
+}
+
+// showProtoDirDialog opens a folder picker for a directory of .proto
+// sources. With add set the folder becomes another import path; otherwise
+// it replaces the current ones.
+func (c *ConnectionBar) showProtoDirDialog(add bool) {
+	dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, c.window)
+			return
+		}
+		if dir == nil {
+			return // User cancelled
+		}
+
+		paths := []string{dir.Path()}
+		if add {
+			paths = append(append([]string{}, c.protoImportPaths...), dir.Path())
+		}
+		c.SetProtoImportPaths(paths)
+	}, c.window)
+}
+
+// updateSourceIcon highlights the source button when local descriptors are in use.
+func (c *ConnectionBar) updateSourceIcon() {
+	if c.descriptorSet != "" || len(c.protoImportPaths) > 0 {
+		c.sourceBtn.Importance = widget.WarningImportance
+	} else {
+		c.sourceBtn.Importance = widget.LowImportance
+	}
+	c.sourceBtn.Refresh()
+}
+
+// updateTLSIcon syncs the padlock icon with the current TLS enabled state,
+// highlighting it while certificate verification is skipped.
 func (c *ConnectionBar) updateTLSIcon() {
+	if c.tlsSettings.Enabled && c.tlsSettings.SkipVerify {
+		c.tlsToggleBtn.Importance = widget.WarningImportance
+	} else {
+		c.tlsToggleBtn.Importance = widget.LowImportance
+	}
 	if c.tlsSettings.Enabled {
 		c.tlsToggleBtn.SetIcon(lockLockedIcon)
 	} else {
@@ -158,35 +397,49 @@ func (c *ConnectionBar) updateButton() {
 		c.connectBtn.SetText("Connect")
 		c.connectBtn.Importance = widget.HighImportance
 		c.connectBtn.Enable()
-		c.addressEntry.OnChanged = c.restoreTLSFromHistory
+		c.addressEntry.OnChanged = c.handleAddressChanged
 		c.addressEntry.Enable()
 		c.tlsToggleBtn.Enable()
+		c.sourceBtn.Enable()
+		c.refreshBtn.Disable()
+		c.certBtn.Hide()
 	case "connecting":
-		c.connectBtn.SetText("Connecting...")
+		c.connectBtn.SetText("Cancel")
 		c.connectBtn.Importance = widget.MediumImportance
-		c.connectBtn.Disable()
+		c.connectBtn.Enable()
 		c.addressEntry.OnChanged = nil
 		c.addressEntry.Disable()
 		c.tlsToggleBtn.Disable()
+		c.sourceBtn.Disable()
+		c.refreshBtn.Disable()
+		c.certBtn.Hide()
 	case "connected":
 		c.connectBtn.SetText("Disconnect")
 		c.connectBtn.Importance = widget.MediumImportance
 		c.connectBtn.Enable()
 		// Keep entry enabled for readable text contrast; prevent edits via OnChanged guard.
-		connectedAddr := c.addressEntry.Text
+		c.SetName(c.name) // shows "Name (address)" and keeps it
 		c.addressEntry.OnChanged = func(s string) {
-			if s != connectedAddr {
-				c.addressEntry.SetText(connectedAddr)
+			if s != c.connectedText {
+				c.addressEntry.SetText(c.connectedText)
 			}
 		}
 		c.tlsToggleBtn.Disable()
+		c.sourceBtn.Disable()
+		c.refreshBtn.Enable()
+		if c.tlsSettings.Enabled {
+			c.certBtn.Show()
+		}
 	case "error":
 		c.connectBtn.SetText("Retry")
 		c.connectBtn.Importance = widget.HighImportance
 		c.connectBtn.Enable()
-		c.addressEntry.OnChanged = c.restoreTLSFromHistory
+		c.addressEntry.OnChanged = c.handleAddressChanged
 		c.addressEntry.Enable()
 		c.tlsToggleBtn.Enable()
+		c.sourceBtn.Enable()
+		c.refreshBtn.Disable()
+		c.certBtn.Hide()
 	}
 }
 
@@ -195,12 +448,195 @@ func (c *ConnectionBar) GetTLSSettings() domain.TLSSettings {
 	return c.tlsSettings
 }
 
+// GetConnection returns the address in the entry field with the settings
+// the next connection will use.
+func (c *ConnectionBar) GetConnection() domain.Connection {
+	return domain.Connection{
+		Name:              c.name,
+		Address:           c.resolveAddress(),
+		TLS:               c.tlsSettings,
+		Transport:         c.transport,
+		DescriptorSetFile: c.descriptorSet,
+		ProtoImportPaths:  c.protoImportPaths,
+		KeepAlive:         c.keepAliveChk.Checked,
+		MaxRecvMsgSize:    c.maxRecvMsgSize,
+		MaxSendMsgSize:    c.maxSendMsgSize,
+		Compression:       c.compression,
+		Authority:         c.authority,
+		UserAgent:         c.userAgent,
+		KeepaliveParams:   c.keepaliveParams,
+		Retry:             c.retry,
+		Proxy:             c.proxy,
+		Auth:              c.auth,
+		DefaultMetadata:   c.defaultMetadata,
+	}
+}
+
 // SetTLSSettings sets the TLS settings and updates the padlock icon.
 func (c *ConnectionBar) SetTLSSettings(s domain.TLSSettings) {
 	c.tlsSettings = s
 	c.updateTLSIcon()
 }
 
+// GetTransport returns the currently selected transport
+func (c *ConnectionBar) GetTransport() domain.Transport {
+	return c.transport
+}
+
+// SetTransport sets the transport used for the next connection.
+func (c *ConnectionBar) SetTransport(t domain.Transport) {
+	c.transport = t
+}
+
+// SetCompression sets the request compressor used for the next connection
+// ("" for none).
+func (c *ConnectionBar) SetCompression(name string) {
+	c.compression = name
+}
+
+// SetAuthority sets the :authority and user-agent the next connection
+// sends ("" keeps gRPC's).
+func (c *ConnectionBar) SetAuthority(authority, userAgent string) {
+	c.authority, c.userAgent = authority, userAgent
+}
+
+// SetKeepaliveParams sets the keepalive pings of the next connection (the
+// zero value sends none).
+func (c *ConnectionBar) SetKeepaliveParams(p domain.KeepaliveParams) {
+	c.keepaliveParams = p
+}
+
+// SetRetryPolicy sets how unary calls on the next connection are retried
+// (the zero value makes a single attempt).
+func (c *ConnectionBar) SetRetryPolicy(p domain.RetryPolicy) {
+	c.retry = p
+}
+
+// SetProxy sets the proxy used for the next connection (the zero value
+// connects directly).
+func (c *ConnectionBar) SetProxy(p domain.ProxySettings) {
+	c.proxy = p
+}
+
+// SetAuth sets the default authorization for the next connection.
+func (c *ConnectionBar) SetAuth(a domain.Auth) {
+	c.auth = a
+}
+
+// SetDefaultMetadata sets the metadata sent with every call on the next
+// connection.
+func (c *ConnectionBar) SetDefaultMetadata(md map[string]string) {
+	c.defaultMetadata = md
+}
+
+// GetMessageLimits returns the maximum receive and send message sizes in
+// bytes (0 means gRPC's default)
+func (c *ConnectionBar) GetMessageLimits() (maxRecv, maxSend int) {
+	return c.maxRecvMsgSize, c.maxSendMsgSize
+}
+
+// SetMessageLimits sets the message size limits used for the next connection.
+func (c *ConnectionBar) SetMessageLimits(maxRecv, maxSend int) {
+	c.maxRecvMsgSize, c.maxSendMsgSize = maxRecv, maxSend
+}
+
+// GetDescriptorSet returns the FileDescriptorSet path, or "" for server reflection
+func (c *ConnectionBar) GetDescriptorSet() string {
+	return c.descriptorSet
+}
+
+// SetDescriptorSet sets the FileDescriptorSet used for the next connection
+// ("" switches back to server reflection). A descriptor set replaces any
+// .proto import paths.
+func (c *ConnectionBar) SetDescriptorSet(path string) {
+	c.descriptorSet = path
+	if path != "" {
+		c.protoImportPaths = nil
+	}
+	c.updateSourceIcon()
+}
+
+// GetProtoImportPaths returns the .proto import paths compiled for the next
+// connection, or nil when another source is used.
+func (c *ConnectionBar) GetProtoImportPaths() []string {
+	return c.protoImportPaths
+}
+
+// SetProtoImportPaths sets the directories of .proto sources compiled for
+// the next connection (nil switches back to server reflection). Import
+// paths replace any descriptor set.
+func (c *ConnectionBar) SetProtoImportPaths(paths []string) {
+	c.protoImportPaths = paths
+	if len(paths) > 0 {
+		c.descriptorSet = ""
+	}
+	c.updateSourceIcon()
+}
+
+// SetConnection populates the name and address, TLS settings, transport, message
+// limits, compression, authority and user-agent, keepalive pings, retry policy, proxy, default auth and metadata, descriptor source, and keep alive toggle from a saved connection.
+func (c *ConnectionBar) SetConnection(conn domain.Connection) {
+	c.SetAddress(conn.Address)
+	c.SetTLSSettings(conn.TLS)
+	c.SetTransport(conn.Transport)
+	c.SetMessageLimits(conn.MaxRecvMsgSize, conn.MaxSendMsgSize)
+	c.SetCompression(conn.Compression)
+	c.SetAuthority(conn.Authority, conn.UserAgent)
+	c.SetKeepaliveParams(conn.KeepaliveParams)
+	c.SetRetryPolicy(conn.Retry)
+	c.SetProxy(conn.Proxy)
+	c.SetAuth(conn.Auth)
+	c.SetDefaultMetadata(conn.DefaultMetadata)
+	c.SetDescriptorSet(conn.DescriptorSetFile)
+	c.SetProtoImportPaths(conn.ProtoImportPaths)
+	c.SetKeepAlive(conn.KeepAlive)
+	c.SetName(conn.Name)
+}
+
+// SetName sets the connection's display name ("" shows just the address),
+// showing it in the entry field as "Name (address)". Unlike the other
+// settings it can be changed while connected.
+func (c *ConnectionBar) SetName(name string) {
+	address := c.resolveAddress()
+	c.name = name
+	display := domain.Connection{Name: name, Address: address}.DisplayName()
+	if address == "" {
+		display = ""
+	}
+
+	// Set the text without restoring settings from history, keeping it
+	// while connected
+	onChanged := c.addressEntry.OnChanged
+	c.addressEntry.OnChanged = nil
+	c.addressEntry.SetText(display)
+	c.addressEntry.OnChanged = onChanged
+	c.addressText = display
+	c.connectedText = display
+}
+
+// GetKeepAlive reports whether the keep alive toggle is on
+func (c *ConnectionBar) GetKeepAlive() bool {
+	return c.keepAliveChk.Checked
+}
+
+// SetKeepAlive sets the keep alive toggle without notifying the callback.
+func (c *ConnectionBar) SetKeepAlive(enabled bool) {
+	onChanged := c.keepAliveChk.OnChanged
+	c.keepAliveChk.OnChanged = nil
+	c.keepAliveChk.SetChecked(enabled)
+	c.keepAliveChk.OnChanged = onChanged
+}
+
+// SetHealth shows the result of the latest server health check.
+func (c *ConnectionBar) SetHealth(r grpc.HealthResult) {
+	c.health.SetResult(r)
+}
+
+// ClearHealth hides the health indicator.
+func (c *ConnectionBar) ClearHealth() {
+	c.health.Clear()
+}
+
 // FocusAddress focuses the address entry field (for keyboard shortcut)
 func (c *ConnectionBar) FocusAddress() {
 	c.window.Canvas().Focus(c.addressEntry)
@@ -216,6 +652,19 @@ func (c *ConnectionBar) SetAddress(address string) {
 	c.addressEntry.SetText(address)
 }
 
+// GetAddress returns the address in the entry field, without the display
+// name of a saved profile.
+func (c *ConnectionBar) GetAddress() string {
+	return c.resolveAddress()
+}
+
+// RestoreAddress sets the address in the entry field along with the TLS,
+// transport, and descriptor settings last used with it.
+func (c *ConnectionBar) RestoreAddress(address string) {
+	c.SetAddress(address)
+	c.restoreTLSFromHistory(address)
+}
+
 // SaveConnection persists the given connection to recent connections and refreshes the dropdown.
 func (c *ConnectionBar) SaveConnection(conn domain.Connection) {
 	if err := c.storage.SaveRecentConnection(conn); err != nil {
@@ -226,38 +675,85 @@ func (c *ConnectionBar) SaveConnection(conn domain.Connection) {
 	})
 }
 
+// clearHistoryOption is the last item of the address dropdown, which
+// forgets all recent connections.
+const clearHistoryOption = "Clear history"
+
 // loadRecentOptions populates the address dropdown from stored recent connections.
 func (c *ConnectionBar) loadRecentOptions() {
 	conns, err := c.storage.GetRecentConnections()
-	if err != nil || len(conns) == 0 {
+	if err != nil {
 		return
 	}
 	c.recentConns = conns
-	options := make([]string, len(conns))
+	if len(conns) == 0 {
+		c.addressEntry.SetOptions(nil)
+		return
+	}
+	options := make([]string, len(conns), len(conns)+1)
 	for i, conn := range conns {
-		options[i] = formatConnectionDisplay(conn)
+		options[i] = conn.DisplayName()
 	}
-	c.addressEntry.SetOptions(options)
+	c.addressEntry.SetOptions(append(options, clearHistoryOption))
 }
 
-// formatConnectionDisplay returns a display string for a connection.
-// If the connection has a name, formats as "Name (address)", otherwise just the address.
-func formatConnectionDisplay(conn domain.Connection) string {
-	if conn.Name != "" {
-		return conn.Name + " (" + conn.Address + ")"
+// handleAddressChanged restores the settings of a recent connection when
+// its address is picked or typed, and clears the history when that item is
+// picked, putting back the address that was there before.
+func (c *ConnectionBar) handleAddressChanged(text string) {
+	if text == clearHistoryOption {
+		if err := c.storage.ClearRecentConnections(); err != nil {
+			dialog.ShowError(err, c.window)
+		}
+		c.loadRecentOptions()
+		c.addressEntry.SetText(c.addressText)
+		return
+	}
+	c.addressText = text
+	restored := c.restoreTLSFromHistory(text)
+	if _, named := c.hasName(text); !restored && !named {
+		// A new address is not known by the name of the last one
+		c.name = ""
 	}
-	return conn.Address
 }
 
-// restoreTLSFromHistory restores TLS settings when an address matches a recent connection.
-func (c *ConnectionBar) restoreTLSFromHistory(addr string) {
+// restoreTLSFromHistory restores the name, TLS settings, transport, message limits, compression, authority and user-agent, keepalive pings, retry policy, proxy, default auth and metadata, descriptor source, and keep alive when an address matches a recent connection,
+// reporting whether one did.
+func (c *ConnectionBar) restoreTLSFromHistory(addr string) bool {
 	for _, conn := range c.recentConns {
-		if conn.Address == addr || formatConnectionDisplay(conn) == addr {
+		if conn.Address == addr || conn.DisplayName() == addr {
+			c.name = conn.Name
 			c.tlsSettings = conn.TLS
+			c.transport = conn.Transport
+			c.SetMessageLimits(conn.MaxRecvMsgSize, conn.MaxSendMsgSize)
+			c.SetCompression(conn.Compression)
+			c.SetAuthority(conn.Authority, conn.UserAgent)
+			c.SetKeepaliveParams(conn.KeepaliveParams)
+			c.SetRetryPolicy(conn.Retry)
+			c.SetProxy(conn.Proxy)
+			c.SetAuth(conn.Auth)
+			c.SetDefaultMetadata(conn.DefaultMetadata)
 			c.updateTLSIcon()
-			return
+			c.SetDescriptorSet(conn.DescriptorSetFile)
+			c.SetProtoImportPaths(conn.ProtoImportPaths)
+			c.SetKeepAlive(conn.KeepAlive)
+			return true
 		}
 	}
+	return false
+}
+
+// hasName reports whether text is shown as "Name (address)" with the
+// current name, and returns the address.
+func (c *ConnectionBar) hasName(text string) (string, bool) {
+	if c.name == "" {
+		return "", false
+	}
+	address, ok := strings.CutPrefix(text, c.name+" (")
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(address, ")")
 }
 
 // resolveAddress extracts the raw address from the entry text.
@@ -266,9 +762,12 @@ func (c *ConnectionBar) resolveAddress() string {
 	text := c.addressEntry.Text
 	// Check if it matches a named profile display format
 	for _, conn := range c.recentConns {
-		if formatConnectionDisplay(conn) == text {
+		if conn.DisplayName() == text {
 			return conn.Address
 		}
 	}
+	if address, ok := c.hasName(text); ok {
+		return address
+	}
 	return text
 }
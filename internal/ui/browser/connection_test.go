@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionBar_RecentAddresses(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	repo := storage.NewMemoryRepository()
+	secure := domain.Connection{Address: "api.example.com:443", TLS: domain.TLSSettings{Enabled: true, CertFile: "/etc/ca.pem"}}
+	plain := domain.Connection{Address: "localhost:50051"}
+	require.NoError(t, repo.SaveRecentConnection(secure))
+	require.NoError(t, repo.SaveRecentConnection(plain))
+
+	w := test.NewWindow(nil)
+	defer w.Close()
+	c := NewConnectionBar(model.NewConnectionUIState(), w, repo)
+
+	// Picking an address restores the TLS settings it was used with
+	c.addressEntry.SetText("api.example.com:443")
+	assert.Equal(t, secure.TLS, c.GetTLSSettings())
+	c.addressEntry.SetText("localhost:50051")
+	assert.Equal(t, plain.TLS, c.GetTLSSettings())
+
+	// A successful connect moves its address to the front
+	c.SaveConnection(secure)
+	conns, err := repo.GetRecentConnections()
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Connection{secure, plain}, conns)
+
+	// The last item clears the history and keeps the typed address
+	c.addressEntry.SetText(clearHistoryOption)
+	assert.Equal(t, "localhost:50051", c.addressEntry.Text)
+	conns, err = repo.GetRecentConnections()
+	require.NoError(t, err)
+	assert.Empty(t, conns)
+	assert.Empty(t, c.recentConns)
+}
+
+func TestConnectionBar_Name(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	repo := storage.NewMemoryRepository()
+	prod := domain.Connection{Name: "prod", Address: "api.example.com:443", TLS: domain.TLSSettings{Enabled: true}}
+	require.NoError(t, repo.SaveRecentConnection(prod))
+
+	w := test.NewWindow(nil)
+	defer w.Close()
+	state := model.NewConnectionUIState()
+	c := NewConnectionBar(state, w, repo)
+	var renamed []string
+	c.SetOnRename(func(name string) { renamed = append(renamed, name) })
+
+	// Picking a named recent connection restores its name
+	c.addressEntry.SetText("prod (api.example.com:443)")
+	assert.Equal(t, "prod", c.GetConnection().Name)
+	assert.Equal(t, "api.example.com:443", c.GetAddress())
+
+	// Typing another address forgets it
+	c.addressEntry.SetText("localhost:50051")
+	assert.Empty(t, c.GetConnection().Name)
+
+	// The address of a named connection is found even once renamed
+	c.addressEntry.SetText("api.example.com:443")
+	require.NoError(t, state.State.Set("connected"))
+	assert.Equal(t, "prod (api.example.com:443)", c.addressEntry.Text, "shown with its name once connected")
+	c.SetName("production")
+	assert.Equal(t, "production (api.example.com:443)", c.addressEntry.Text)
+	assert.Equal(t, "production", c.GetConnection().Name)
+	assert.Equal(t, "api.example.com:443", c.GetAddress())
+	c.addressEntry.SetText("edited")
+	assert.Equal(t, "production (api.example.com:443)", c.addressEntry.Text, "the entry is kept while connected")
+
+	// Clearing the name shows just the address
+	c.SetName("")
+	assert.Equal(t, "api.example.com:443", c.addressEntry.Text)
+	assert.Equal(t, "api.example.com:443", c.GetAddress())
+	assert.Empty(t, renamed, "only renaming in the settings dialog is reported")
+
+	// Saved connections bring their names
+	require.NoError(t, state.State.Set("disconnected"))
+	c.SetConnection(domain.Connection{Name: "local", Address: "localhost:50051"})
+	assert.Equal(t, "local (localhost:50051)", c.addressEntry.Text)
+	assert.Equal(t, "localhost:50051", c.GetConnection().Address)
+}
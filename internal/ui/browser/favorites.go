@@ -0,0 +1,126 @@
+package browser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/components"
+)
+
+// favoritesUID is the Favorites node at the top of the tree. Its children
+// are favoriteUIDPrefix followed by the method's own UID, e.g.
+// "@fav:pkg.Svc:Method". Proto names cannot contain '@', so these never
+// collide with service, method or package UIDs.
+const (
+	favoritesUID      = "@favorites"
+	favoriteUIDPrefix = "@fav:"
+)
+
+// SetFavorites sets the pinned methods of the current server, as full
+// method names ("pkg.Service/Method") in the order they were pinned. Nil
+// clears them.
+func (b *ServiceBrowser) SetFavorites(methods []string) {
+	b.favorites = slices.Clone(methods)
+	b.tree.OpenBranch(favoritesUID)
+	b.tree.Refresh()
+}
+
+// Favorites returns the pinned methods, as full method names.
+func (b *ServiceBrowser) Favorites() []string {
+	return slices.Clone(b.favorites)
+}
+
+// SetOnFavoritesChange sets the callback for when a method is pinned or
+// unpinned, called with every pinned method.
+func (b *ServiceBrowser) SetOnFavoritesChange(fn func(methods []string)) {
+	b.onFavoritesChange = fn
+}
+
+// IsFavorite reports whether a method is pinned.
+func (b *ServiceBrowser) IsFavorite(serviceName, methodName string) bool {
+	return slices.Contains(b.favorites, serviceName+"/"+methodName)
+}
+
+// SetFavorite pins or unpins a method. Pinned methods are listed in the
+// order they were pinned.
+func (b *ServiceBrowser) SetFavorite(serviceName, methodName string, pinned bool) {
+	name := serviceName + "/" + methodName
+	i := slices.Index(b.favorites, name)
+	switch {
+	case pinned && i < 0:
+		b.favorites = append(b.favorites, name)
+	case !pinned && i >= 0:
+		b.favorites = slices.Delete(b.favorites, i, i+1)
+	default:
+		return
+	}
+	b.tree.OpenBranch(favoritesUID)
+	b.tree.Refresh()
+	if b.onFavoritesChange != nil {
+		b.onFavoritesChange(b.Favorites())
+	}
+}
+
+// getFavoriteUIDs returns the UIDs of the pinned methods the server has,
+// filtered if a query is active. Pins of methods the server does not list
+// are kept, but not shown.
+func (b *ServiceBrowser) getFavoriteUIDs() []string {
+	var uids []string
+	for _, name := range b.favorites {
+		serviceName, methodName, ok := strings.Cut(name, "/")
+		if !ok {
+			continue
+		}
+		service := b.findService(serviceName)
+		if service == nil {
+			continue
+		}
+		method := b.findMethod(*service, methodName)
+		if method == nil {
+			continue
+		}
+		if b.filterQuery != "" && !b.serviceNameMatchesFilter(serviceName, service) && !b.methodMatchesFilter(*method) {
+			continue
+		}
+		uids = append(uids, favoriteUIDPrefix+serviceName+":"+methodName)
+	}
+	return uids
+}
+
+// withFavorites puts the Favorites node ahead of the root's children while
+// any pinned method is shown.
+func (b *ServiceBrowser) withFavorites(children []string) []string {
+	if len(b.getFavoriteUIDs()) == 0 {
+		return children
+	}
+	return append([]string{favoritesUID}, children...)
+}
+
+// updateFavoritesNode shows the Favorites node with its method count,
+// like a service.
+func (b *ServiceBrowser) updateFavoritesNode(icon *components.TooltipIcon, label *widget.RichText) {
+	icon.SetResource(starIcon)
+	style := widget.RichTextStyle{TextStyle: fyne.TextStyle{Bold: true}}
+	b.setLabel(label, "Favorites", fmt.Sprintf("  (%d)", len(b.getFavoriteUIDs())), style)
+}
+
+// favoriteMenuItem pins or unpins the method right-clicked.
+func (b *ServiceBrowser) favoriteMenuItem(service domain.Service, method domain.Method) *fyne.MenuItem {
+	if b.IsFavorite(service.FullName, method.Name) {
+		item := fyne.NewMenuItem("Remove from Favorites", func() {
+			b.SetFavorite(service.FullName, method.Name, false)
+		})
+		item.Icon = theme.ContentRemoveIcon()
+		return item
+	}
+	item := fyne.NewMenuItem("Add to Favorites", func() {
+		b.SetFavorite(service.FullName, method.Name, true)
+	})
+	item.Icon = starIcon
+	return item
+}
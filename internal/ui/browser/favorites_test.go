@@ -0,0 +1,127 @@
+package browser
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFavoritesBrowser returns a browser listing two services in different
+// packages.
+func newFavoritesBrowser(t *testing.T) *ServiceBrowser {
+	t.Helper()
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "ListUsers", FullName: "example.UserService.ListUsers"},
+		},
+	})
+	services.Append(domain.Service{
+		Name: "OrderService", FullName: "shop.OrderService",
+		Methods: []domain.Method{
+			{Name: "PlaceOrder", FullName: "shop.OrderService.PlaceOrder"},
+		},
+	})
+	return NewServiceBrowser(services, binding.NewString())
+}
+
+func TestServiceBrowser_PinAndUnpin(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	browser := newFavoritesBrowser(t)
+	var saved [][]string
+	browser.SetOnFavoritesChange(func(methods []string) { saved = append(saved, methods) })
+
+	assert.Equal(t, []string{"example.UserService", "shop.OrderService"}, browser.childUIDs(""),
+		"no Favorites node until something is pinned")
+
+	browser.SetFavorite("shop.OrderService", "PlaceOrder", true)
+	browser.SetFavorite("example.UserService", "GetUser", true)
+	browser.SetFavorite("example.UserService", "GetUser", true) // already pinned
+	assert.Equal(t, []string{favoritesUID, "example.UserService", "shop.OrderService"}, browser.childUIDs(""))
+	assert.Equal(t, []string{"@fav:shop.OrderService:PlaceOrder", "@fav:example.UserService:GetUser"},
+		browser.childUIDs(favoritesUID), "in the order pinned")
+	assert.True(t, browser.isBranch(favoritesUID))
+	assert.False(t, browser.isBranch("@fav:shop.OrderService:PlaceOrder"))
+	assert.True(t, browser.IsFavorite("example.UserService", "GetUser"))
+
+	browser.SetFavorite("shop.OrderService", "PlaceOrder", false)
+	assert.Equal(t, []string{"@fav:example.UserService:GetUser"}, browser.childUIDs(favoritesUID))
+	assert.Equal(t, [][]string{
+		{"shop.OrderService/PlaceOrder"},
+		{"shop.OrderService/PlaceOrder", "example.UserService/GetUser"},
+		{"example.UserService/GetUser"},
+	}, saved, "each change is reported once")
+
+	browser.SetFavorite("example.UserService", "GetUser", false)
+	assert.NotContains(t, browser.childUIDs(""), favoritesUID, "hidden once empty")
+}
+
+func TestServiceBrowser_SetFavorites(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	browser := newFavoritesBrowser(t)
+	var changed bool
+	browser.SetOnFavoritesChange(func([]string) { changed = true })
+
+	// As loaded for a server: pins of methods it no longer has are kept
+	// but not shown
+	browser.SetFavorites([]string{"example.UserService/ListUsers", "gone.Service/Method", "malformed"})
+	assert.False(t, changed, "loading is not a change")
+	assert.Equal(t, []string{"@fav:example.UserService:ListUsers"}, browser.childUIDs(favoritesUID))
+	assert.Equal(t, []string{"example.UserService/ListUsers", "gone.Service/Method", "malformed"}, browser.Favorites())
+
+	// The filter applies to Favorites too
+	browser.setFilter("order")
+	assert.Empty(t, browser.childUIDs(favoritesUID))
+	assert.Equal(t, []string{"shop.OrderService"}, browser.childUIDs(""))
+	browser.setFilter("")
+
+	// Favorites stay first when grouping by package
+	browser.SetGroupByPackage(true)
+	assert.Equal(t, []string{favoritesUID, "#example", "#shop"}, browser.childUIDs(""))
+
+	browser.SetFavorites(nil)
+	assert.Equal(t, []string{"#example", "#shop"}, browser.childUIDs(""))
+}
+
+func TestServiceBrowser_SelectFavorite(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	browser := newFavoritesBrowser(t)
+	browser.SetFavorites([]string{"shop.OrderService/PlaceOrder"})
+	var selected []string
+	browser.SetOnMethodSelect(func(service domain.Service, method domain.Method) {
+		selected = append(selected, service.FullName+"/"+method.Name)
+	})
+
+	// Selecting the pinned copy dispatches just like the method itself
+	browser.onTreeSelected("@fav:shop.OrderService:PlaceOrder")
+	browser.onTreeSelected("shop.OrderService:PlaceOrder")
+	assert.Equal(t, []string{"shop.OrderService/PlaceOrder", "shop.OrderService/PlaceOrder"}, selected)
+
+	// Its row shows the method, noting its service
+	node := browser.create(false)
+	browser.update("@fav:shop.OrderService:PlaceOrder", false, node)
+	label := node.(*treeRow).content.Objects[1].(*widget.RichText)
+	assert.Equal(t, "PlaceOrder  OrderService", label.String())
+
+	// Its menu unpins it
+	browser.SetMethodActions(&recordedActions{})
+	menu := browser.contextMenu("@fav:shop.OrderService:PlaceOrder")
+	require.NotNil(t, menu)
+	last := menu.Items[len(menu.Items)-1]
+	assert.Equal(t, "Remove from Favorites", last.Label)
+	last.Action()
+	assert.Empty(t, browser.Favorites())
+}
@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// Compile-time interface check.
+var _ desktop.Hoverable = (*HealthIndicator)(nil)
+
+const healthDotSize = 10
+
+// HealthIndicator shows the server's grpc.health.v1 status as a colored
+// dot, with the details in a popup on hover. Servers without the health
+// service show "n/a" instead. It is hidden until the first result.
+type HealthIndicator struct {
+	widget.BaseWidget
+
+	result grpc.HealthResult
+	dot    *canvas.Circle
+	na     *widget.Label
+	popup  *widget.PopUp
+}
+
+// NewHealthIndicator creates a hidden health indicator.
+func NewHealthIndicator() *HealthIndicator {
+	h := &HealthIndicator{
+		dot: canvas.NewCircle(theme.Color(theme.ColorNameDisabled)),
+		na:  widget.NewLabel("n/a"),
+	}
+	h.na.Importance = widget.LowImportance
+	h.na.Hide()
+	h.ExtendBaseWidget(h)
+	h.Hide()
+	return h
+}
+
+// SetResult shows the outcome of a health check.
+func (h *HealthIndicator) SetResult(r grpc.HealthResult) {
+	h.result = r
+	if r.Status == grpc.HealthUnavailable && r.Err == nil {
+		h.dot.Hide()
+		h.na.Show()
+	} else {
+		h.na.Hide()
+		h.dot.Show()
+	}
+	h.Show()
+	h.Refresh()
+}
+
+// Clear hides the indicator, e.g. after disconnecting.
+func (h *HealthIndicator) Clear() {
+	h.result = grpc.HealthResult{}
+	h.MouseOut()
+	h.Hide()
+}
+
+// Tooltip returns the text shown on hover.
+func (h *HealthIndicator) Tooltip() string {
+	r := h.result
+	switch {
+	case r.Err != nil:
+		return "Health check failed: " + r.Err.Error()
+	case r.Status == grpc.HealthUnavailable:
+		return "Server does not implement grpc.health.v1.Health"
+	case r.Status == "":
+		return ""
+	}
+	return fmt.Sprintf("Health: %s\nChecked %s", r.Status, r.CheckedAt.Format("15:04:05"))
+}
+
+// dotColor maps the current status to a theme color.
+func (h *HealthIndicator) dotColor() fyne.ThemeColorName {
+	if h.result.Err != nil {
+		return theme.ColorNameWarning
+	}
+	switch h.result.Status {
+	case grpc.HealthServing:
+		return theme.ColorNameSuccess
+	case grpc.HealthNotServing:
+		return theme.ColorNameError
+	case grpc.HealthUnknown, grpc.HealthServiceUnknown:
+		return theme.ColorNameWarning
+	default:
+		return theme.ColorNameDisabled
+	}
+}
+
+// Refresh re-reads the dot color so it follows theme changes.
+func (h *HealthIndicator) Refresh() {
+	h.dot.FillColor = theme.Color(h.dotColor())
+	h.BaseWidget.Refresh()
+	h.dot.Refresh()
+}
+
+// MouseIn shows the status details in a popup.
+func (h *HealthIndicator) MouseIn(_ *desktop.MouseEvent) {
+	text := h.Tooltip()
+	if text == "" {
+		return
+	}
+	c := fyne.CurrentApp().Driver().CanvasForObject(h)
+	if c == nil {
+		return
+	}
+	h.popup = widget.NewPopUp(widget.NewLabel(text), c)
+	h.popup.ShowAtRelativePosition(fyne.NewPos(0, h.Size().Height), h)
+}
+
+// MouseMoved is required by desktop.Hoverable but needs no action.
+func (h *HealthIndicator) MouseMoved(_ *desktop.MouseEvent) {}
+
+// MouseOut hides and discards the popup.
+func (h *HealthIndicator) MouseOut() {
+	if h.popup != nil {
+		h.popup.Hide()
+		h.popup = nil
+	}
+}
+
+// CreateRenderer implements fyne.Widget.
+func (h *HealthIndicator) CreateRenderer() fyne.WidgetRenderer {
+	dot := container.NewCenter(container.NewGridWrap(fyne.NewSquareSize(healthDotSize), h.dot))
+	return widget.NewSimpleRenderer(container.NewStack(dot, h.na))
+}
@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthIndicator(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	h := NewHealthIndicator()
+	w := test.NewWindow(h)
+	defer w.Close()
+	assert.False(t, h.Visible(), "hidden until the first check")
+
+	checked := time.Date(2024, 1, 1, 12, 4, 5, 0, time.UTC)
+	h.SetResult(grpc.HealthResult{Status: grpc.HealthServing, CheckedAt: checked})
+	assert.True(t, h.Visible())
+	assert.True(t, h.dot.Visible())
+	assert.Equal(t, theme.Color(theme.ColorNameSuccess), h.dot.FillColor)
+	assert.Equal(t, "Health: SERVING\nChecked 12:04:05", h.Tooltip())
+
+	h.SetResult(grpc.HealthResult{Status: grpc.HealthNotServing, CheckedAt: checked})
+	assert.Equal(t, theme.Color(theme.ColorNameError), h.dot.FillColor)
+
+	h.SetResult(grpc.HealthResult{Status: grpc.HealthUnknown, Err: errors.New("deadline exceeded")})
+	assert.Equal(t, theme.Color(theme.ColorNameWarning), h.dot.FillColor)
+	assert.Equal(t, "Health check failed: deadline exceeded", h.Tooltip())
+
+	// No health service: "n/a" rather than a dot
+	h.SetResult(grpc.HealthResult{Status: grpc.HealthUnavailable})
+	assert.False(t, h.dot.Visible())
+	assert.True(t, h.na.Visible())
+
+	h.Clear()
+	assert.False(t, h.Visible())
+	assert.Empty(t, h.Tooltip())
+}
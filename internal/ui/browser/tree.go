@@ -6,20 +6,26 @@ import (
 	"strings"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/components"
 )
 
+// packageUIDPrefix starts the UIDs of package nodes when grouping by
+// package, e.g. "#com.example". Proto names cannot contain '#', so these
+// never collide with service UIDs (full names) or method UIDs
+// ("service:method").
+const packageUIDPrefix = "#"
+
 // ServiceBrowser displays services and methods in a tree view
 type ServiceBrowser struct {
 	widget.BaseWidget
 
-	tree        *widget.Tree
+	tree        *navTree
 	services    binding.UntypedList // []domain.Service
 	connState   binding.String      // connection state for loading indicator
 	placeholder *widget.Label       // shown when no services loaded
@@ -32,12 +38,44 @@ type ServiceBrowser struct {
 	displayNames map[string]string // FullName → disambiguated short display name
 
 	// Filter
-	filterEntry *widget.Entry
-	filterQuery string
+	filterEntry  *widget.Entry
+	filterQuery  string
+	filterCount  *widget.Label
+	filterOpened []string // branches opened by the filter, closed again when it changes
+
+	// Where the service list came from (reflection or a descriptor set file)
+	sourceLabel *widget.Label
+
+	// Invocation statistics by full method name ("pkg.Service/Method"),
+	// annotated beside the methods called on the current server
+	methodStats map[string]domain.MethodStatsSummary
+
+	// Nest services under their package segments instead of listing them flat
+	groupByPackage bool
+	groupCheck     *widget.Check
+
+	// Methods pinned on the current server, by full method name
+	// ("pkg.Service/Method"), listed under Favorites in the order pinned
+	favorites []string
 
 	// Callbacks
-	onMethodSelect func(service domain.Service, method domain.Method)
-	onServiceError func(service domain.Service)
+	onMethodSelect    func(service domain.Service, method domain.Method)
+	onServiceError    func(service domain.Service)
+	onDiagnose        func(service domain.Service)
+	onGroupChange     func(grouped bool)
+	onFavoritesChange func(methods []string)
+
+	// Offered by a method's context menu
+	methodActions MethodActions
+}
+
+// MethodActions are the actions offered by a method's context menu in the
+// service browser.
+type MethodActions interface {
+	InvokeEmpty(service domain.Service, method domain.Method)      // Send {} right away
+	CopyMethodName(service domain.Service, method domain.Method)   // Copy "pkg.Service/Method"
+	CopyInputType(service domain.Service, method domain.Method)    // Copy the input message's full name
+	GenerateTemplate(service domain.Service, method domain.Method) // Replace the request with a template
 }
 
 // NewServiceBrowser creates a new service browser widget
@@ -53,7 +91,7 @@ func NewServiceBrowser(services binding.UntypedList, connState binding.String) *
 		b.rebuildIndex()
 	}))
 
-	b.tree = widget.NewTree(
+	b.tree = newNavTree(
 		b.childUIDs,
 		b.isBranch,
 		b.create,
@@ -74,10 +112,22 @@ func NewServiceBrowser(services binding.UntypedList, connState binding.String) *
 	// Filter entry for searching services and methods
 	b.filterEntry = widget.NewEntry()
 	b.filterEntry.SetPlaceHolder("Filter services...")
-	b.filterEntry.OnChanged = func(query string) {
-		b.filterQuery = strings.ToLower(query)
-		b.tree.Refresh()
-	}
+	b.filterEntry.OnChanged = b.setFilter
+	b.filterEntry.OnSubmitted = func(string) { b.FocusTree() } // Enter moves on to the results
+
+	// Flat list (the default) or services nested under their packages
+	b.groupCheck = widget.NewCheck("Group by package", b.SetGroupByPackage)
+
+	// Match count shown beside the filter while a query is active
+	b.filterCount = widget.NewLabel("")
+	b.filterCount.Importance = widget.LowImportance
+	b.filterCount.Hide()
+
+	// Descriptor source indicator shown beneath the tree
+	b.sourceLabel = widget.NewLabel("")
+	b.sourceLabel.Importance = widget.LowImportance
+	b.sourceLabel.Truncation = fyne.TextTruncateEllipsis
+	b.sourceLabel.Hide()
 
 	// Stack container: shows placeholder when empty, tree when populated
 	// Use Border with spacers for vertical centering — NewCenter gives minimum width
@@ -96,11 +146,130 @@ func NewServiceBrowser(services binding.UntypedList, connState binding.String) *
 	return b
 }
 
+// navTree is a widget.Tree that also selects the focused node on Enter, so
+// methods can be picked with the arrow keys alone.
+type navTree struct {
+	widget.Tree
+}
+
+// newNavTree creates a navTree with the same callbacks as widget.NewTree.
+func newNavTree(
+	childUIDs func(widget.TreeNodeID) []widget.TreeNodeID,
+	isBranch func(widget.TreeNodeID) bool,
+	create func(bool) fyne.CanvasObject,
+	update func(widget.TreeNodeID, bool, fyne.CanvasObject),
+) *navTree {
+	t := &navTree{}
+	t.ChildUIDs = childUIDs
+	t.IsBranch = isBranch
+	t.CreateNode = create
+	t.UpdateNode = update
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// TypedKey implements fyne.Focusable, treating Enter like Space, which the
+// tree already uses to select the focused node.
+func (t *navTree) TypedKey(event *fyne.KeyEvent) {
+	switch event.Name {
+	case fyne.KeyReturn, fyne.KeyEnter:
+		t.Tree.TypedKey(&fyne.KeyEvent{Name: fyne.KeySpace})
+	default:
+		t.Tree.TypedKey(event)
+	}
+}
+
+// treeRow is a tree node's icon and label. A secondary tap on it opens the
+// node's context menu.
+type treeRow struct {
+	widget.BaseWidget
+	content *fyne.Container
+	uid     string // The node shown, set by update
+	onMenu  func(uid string, pos fyne.Position)
+}
+
+func newTreeRow(onMenu func(uid string, pos fyne.Position), objects ...fyne.CanvasObject) *treeRow {
+	r := &treeRow{content: container.NewHBox(objects...), onMenu: onMenu}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// TappedSecondary implements fyne.SecondaryTappable.
+func (r *treeRow) TappedSecondary(event *fyne.PointEvent) {
+	r.onMenu(r.uid, event.AbsolutePosition)
+}
+
+// CreateRenderer implements fyne.Widget.
+func (r *treeRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.content)
+}
+
 // SetOnMethodSelect sets callback when a method is selected
 func (b *ServiceBrowser) SetOnMethodSelect(fn func(service domain.Service, method domain.Method)) {
 	b.onMethodSelect = fn
 }
 
+// SetMethodActions sets the actions offered by right-clicking a method.
+// Without them methods have no context menu.
+func (b *ServiceBrowser) SetMethodActions(actions MethodActions) {
+	b.methodActions = actions
+}
+
+// SetSource shows where the service list came from, e.g. "server reflection"
+// or a descriptor set file name. An empty source hides the indicator.
+func (b *ServiceBrowser) SetSource(source string) {
+	if source == "" {
+		b.sourceLabel.SetText("")
+		b.sourceLabel.Hide()
+		return
+	}
+	b.sourceLabel.SetText("Source: " + source)
+	b.sourceLabel.Show()
+}
+
+// SetOnGroupByPackageChange sets the callback for when the Group by package
+// toggle changes.
+func (b *ServiceBrowser) SetOnGroupByPackageChange(fn func(grouped bool)) {
+	b.onGroupChange = fn
+}
+
+// GroupByPackage reports whether services are nested under their packages.
+func (b *ServiceBrowser) GroupByPackage() bool {
+	return b.groupByPackage
+}
+
+// SetGroupByPackage nests services under their proto package segments
+// (com → example → api → UserService), or lists them flat.
+func (b *ServiceBrowser) SetGroupByPackage(grouped bool) {
+	if grouped == b.groupByPackage {
+		return
+	}
+	b.groupByPackage = grouped
+	b.groupCheck.SetChecked(grouped)
+	if b.filterQuery != "" {
+		b.setFilter(b.filterQuery) // open the new mode's branches
+	} else {
+		b.tree.Refresh()
+	}
+	if b.onGroupChange != nil {
+		b.onGroupChange(grouped)
+	}
+}
+
+// SetMethodStats sets the invocation statistics shown beside methods, by
+// full method name ("pkg.Service/Method"); hovering a method's icon shows
+// them in full. Nil clears them.
+func (b *ServiceBrowser) SetMethodStats(stats map[string]domain.MethodStatsSummary) {
+	b.methodStats = stats
+	b.tree.Refresh()
+}
+
+// SetOnDiagnose sets the action offered by the context menu of a service
+// that failed to resolve, in whole or in part.
+func (b *ServiceBrowser) SetOnDiagnose(fn func(service domain.Service)) {
+	b.onDiagnose = fn
+}
+
 // SetOnServiceError sets callback when an error service is selected
 func (b *ServiceBrowser) SetOnServiceError(fn func(service domain.Service)) {
 	b.onServiceError = fn
@@ -114,6 +283,11 @@ func (b *ServiceBrowser) Refresh() {
 // SelectMethod programmatically opens a service branch and selects a method node.
 // This triggers onTreeSelected which calls onMethodSelect.
 func (b *ServiceBrowser) SelectMethod(serviceName, methodName string) {
+	if b.groupByPackage {
+		for _, uid := range packageAncestors(serviceName) {
+			b.tree.OpenBranch(uid)
+		}
+	}
 	b.tree.OpenBranch(serviceName)
 	uid := fmt.Sprintf("%s:%s", serviceName, methodName)
 	b.tree.Select(uid)
@@ -133,20 +307,96 @@ func (b *ServiceBrowser) FocusFilter() {
 	}
 }
 
-// ExpandAll opens all service branches in the tree.
+// ExpandAll opens all service branches in the tree, and package branches
+// when grouping by package.
 func (b *ServiceBrowser) ExpandAll() {
-	for _, uid := range b.serviceUIDs {
+	for _, uid := range b.branchUIDs(b.serviceUIDs) {
 		b.tree.OpenBranch(uid)
 	}
 }
 
-// CollapseAll closes all service branches in the tree.
+// CollapseAll closes all service branches in the tree, and package branches
+// when grouping by package.
 func (b *ServiceBrowser) CollapseAll() {
-	for _, uid := range b.serviceUIDs {
+	for _, uid := range b.branchUIDs(b.serviceUIDs) {
 		b.tree.CloseBranch(uid)
 	}
 }
 
+// branchUIDs returns the given services preceded, when grouping by package,
+// by the package nodes above them.
+func (b *ServiceBrowser) branchUIDs(serviceUIDs []string) []string {
+	if !b.groupByPackage {
+		return serviceUIDs
+	}
+	var uids []string
+	seen := make(map[string]bool)
+	for _, service := range serviceUIDs {
+		for _, uid := range packageAncestors(service) {
+			if !seen[uid] {
+				seen[uid] = true
+				uids = append(uids, uid)
+			}
+		}
+	}
+	return append(uids, serviceUIDs...)
+}
+
+// setFilter applies a filter query: the tree keeps only matching services
+// and methods, branches with matches are opened, and the match count is
+// shown. An empty query restores the full tree and closes the branches the
+// filter opened, leaving ones the user opened alone.
+func (b *ServiceBrowser) setFilter(query string) {
+	b.filterQuery = strings.ToLower(strings.TrimSpace(query))
+
+	for _, uid := range b.filterOpened {
+		b.tree.CloseBranch(uid)
+	}
+	b.filterOpened = nil
+	if b.filterQuery != "" {
+		for _, uid := range b.branchUIDs(b.getServiceUIDs()) {
+			if !b.tree.IsBranchOpen(uid) {
+				b.tree.OpenBranch(uid)
+				b.filterOpened = append(b.filterOpened, uid)
+			}
+		}
+	}
+
+	b.updateFilterCount()
+	b.tree.Refresh()
+}
+
+// updateFilterCount shows how many methods and services match the filter,
+// or hides the count when no filter is active.
+func (b *ServiceBrowser) updateFilterCount() {
+	if b.filterQuery == "" {
+		b.filterCount.Hide()
+		return
+	}
+
+	services, methods := b.filterMatches()
+	switch {
+	case services == 0:
+		b.filterCount.SetText("No matches")
+	case methods == 1:
+		b.filterCount.SetText("1 method")
+	case services == 1:
+		b.filterCount.SetText(fmt.Sprintf("%d methods", methods))
+	default:
+		b.filterCount.SetText(fmt.Sprintf("%d methods in %d services", methods, services))
+	}
+	b.filterCount.Show()
+}
+
+// filterMatches counts the services and methods left by the current filter.
+func (b *ServiceBrowser) filterMatches() (services, methods int) {
+	for _, uid := range b.getServiceUIDs() {
+		services++
+		methods += len(b.getMethodUIDs(uid))
+	}
+	return services, methods
+}
+
 // CreateRenderer creates the renderer for this widget
 func (b *ServiceBrowser) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(b.content)
@@ -154,9 +404,22 @@ func (b *ServiceBrowser) CreateRenderer() fyne.WidgetRenderer {
 
 // childUIDs returns the child UIDs for a given parent UID
 func (b *ServiceBrowser) childUIDs(uid string) []string {
+	if uid == favoritesUID {
+		return b.getFavoriteUIDs()
+	}
+
+	if b.groupByPackage && (uid == "" || strings.HasPrefix(uid, packageUIDPrefix)) {
+		// Root or package - return sub-packages, then services
+		children := b.getPackageChildUIDs(strings.TrimPrefix(uid, packageUIDPrefix))
+		if uid == "" {
+			return b.withFavorites(children)
+		}
+		return children
+	}
+
 	if uid == "" {
-		// Root level - return all services
-		return b.getServiceUIDs()
+		// Root level - return favorites, then all services
+		return b.withFavorites(b.getServiceUIDs())
 	}
 
 	// Check if this is a service (no colon means it's a service name)
@@ -171,8 +434,8 @@ func (b *ServiceBrowser) childUIDs(uid string) []string {
 
 // isBranch returns whether the given UID represents a branch node
 func (b *ServiceBrowser) isBranch(uid string) bool {
-	// Root level services are branches
-	// Methods (containing ":") are leaves
+	// Packages ("#pkg"), Favorites and services are branches
+	// Methods (containing ":"), pinned or not, are leaves
 	return !strings.Contains(uid, ":")
 }
 
@@ -180,76 +443,189 @@ func (b *ServiceBrowser) isBranch(uid string) bool {
 func (b *ServiceBrowser) create(branch bool) fyne.CanvasObject {
 	// Both branches and leaves use same structure for consistency
 	// (Fyne tree widget may have issues with inconsistent structures)
-	icon := canvas.NewImageFromResource(theme.FolderIcon())
-	icon.FillMode = canvas.ImageFillContain
-	icon.SetMinSize(fyne.NewSize(16, 16))
+	// Hovering the icon shows a service's file or a package's full name
+	icon := components.NewTooltipIcon(theme.FolderIcon(), "")
 
-	label := widget.NewLabel("")
+	label := widget.NewRichText()
 
-	return container.NewHBox(icon, label)
+	return newTreeRow(b.showContextMenu, icon, label)
 }
 
 // update updates a tree node widget with the appropriate data
 func (b *ServiceBrowser) update(uid string, branch bool, obj fyne.CanvasObject) {
-	cont := obj.(*fyne.Container)
-	icon := cont.Objects[0].(*canvas.Image)
-	label := cont.Objects[1].(*widget.Label)
+	row := obj.(*treeRow)
+	row.uid = uid
+	cont := row.content
+	icon := cont.Objects[0].(*components.TooltipIcon)
+	label := cont.Objects[1].(*widget.RichText)
+	icon.SetTooltip("")
 
-	if branch {
+	if uid == favoritesUID {
+		b.updateFavoritesNode(icon, label)
+	} else if pkg, ok := strings.CutPrefix(uid, packageUIDPrefix); ok {
+		// Package: show its last segment; the tree shows the rest
+		icon.SetResource(theme.FolderIcon())
+		icon.SetTooltip(pkg)
+		b.setLabel(label, pkg[strings.LastIndex(pkg, ".")+1:], "", widget.RichTextStyle{})
+	} else if branch {
 		service := b.findService(uid)
 
 		displayName := b.displayNames[uid]
+		if b.groupByPackage && service != nil {
+			displayName = service.Name // The package is shown by the tree
+		}
 		if displayName == "" {
 			displayName = uid
 		}
+		if service != nil {
+			icon.SetTooltip(service.File)
+		}
 
 		if service != nil && service.Error != "" {
 			// Error service: show warning icon and indicator
-			icon.Resource = theme.WarningIcon()
-			icon.Refresh()
-			label.SetText(displayName)
-			label.TextStyle = fyne.TextStyle{Italic: true}
-			label.Importance = widget.WarningImportance
+			icon.SetResource(theme.WarningIcon())
+			b.setLabel(label, displayName, "", widget.RichTextStyle{
+				ColorName: theme.ColorNameWarning,
+				TextStyle: fyne.TextStyle{Italic: true},
+			})
 		} else {
 			// Normal service: show short name with method count
-			icon.Resource = theme.FolderIcon()
-			icon.Refresh()
+			icon.SetResource(theme.FolderIcon())
 			methodCount := 0
 			if service != nil {
 				methodCount = len(service.Methods)
 			}
-			label.SetText(fmt.Sprintf("%s  (%d)", displayName, methodCount))
-			label.TextStyle = fyne.TextStyle{Bold: true}
-			label.Importance = widget.MediumImportance
+			style := widget.RichTextStyle{TextStyle: fyne.TextStyle{Bold: true}}
+			suffix := fmt.Sprintf("  (%d)", methodCount)
+			if service != nil && service.Deprecated {
+				style = deprecatedStyle(style)
+				suffix += deprecatedSuffix
+			}
+			b.setLabel(label, displayName, suffix, style)
 		}
 	} else {
-		// Methods: show icon based on method type
-		parts := strings.Split(uid, ":")
+		// Methods: show icon based on method type; pinned copies under
+		// Favorites also note their service
+		methodUID, pinned := strings.CutPrefix(uid, favoriteUIDPrefix)
+		parts := strings.Split(methodUID, ":")
 		if len(parts) == 2 {
 			methodName := parts[1]
 			service := b.findService(parts[0])
 			if service != nil {
 				method := b.findMethod(*service, methodName)
-				if method != nil {
+				if method != nil && method.Error != "" {
+					// Unresolvable types: shown disabled, hover for why
+					icon.SetResource(theme.WarningIcon())
+					icon.SetTooltip(method.Error)
+					b.setLabel(label, method.Name, unresolvedSuffix, widget.RichTextStyle{
+						ColorName: theme.ColorNameDisabled,
+						TextStyle: fyne.TextStyle{Italic: true},
+					})
+				} else if method != nil {
 					// Set icon based on method type
-					icon.Resource = b.getMethodIcon(method)
-					icon.Refresh()
+					icon.SetResource(b.getMethodIcon(method))
 
 					// Format method name with subtle type badge
 					typeBadge := b.getMethodTypeBadge(method)
-					name := method.Name
+					suffix := ""
 					if typeBadge != "" {
-						name += "  " + typeBadge
+						suffix = "  " + typeBadge
+					}
+					style := widget.RichTextStyle{}
+					if method.Deprecated {
+						style = deprecatedStyle(style)
+						suffix += deprecatedSuffix
+					}
+					b.setLabel(label, method.Name, suffix, style)
+					if stats, ok := b.methodStats[parts[0]+"/"+methodName]; ok {
+						icon.SetTooltip(stats.Details())
+						appendNote(label, stats.Annotation())
 					}
-					label.SetText(name)
-					label.TextStyle = fyne.TextStyle{}
-					label.Importance = widget.MediumImportance
+				}
+				if method != nil && pinned {
+					appendNote(label, b.displayNames[parts[0]])
 				}
 			}
 		}
 	}
 }
 
+// unresolvedSuffix follows the names of methods that cannot be called
+// because their input or output type could not be resolved.
+const unresolvedSuffix = "  (unresolved)"
+
+// deprecatedSuffix follows the names of deprecated services and methods.
+const deprecatedSuffix = "  (deprecated)"
+
+// deprecatedStyle dims and italicises style for a deprecated service or
+// method. Fyne text has no strikethrough, so this stands in for one.
+func deprecatedStyle(style widget.RichTextStyle) widget.RichTextStyle {
+	style.ColorName = theme.ColorNameDisabled
+	style.TextStyle.Italic = true
+	return style
+}
+
+// setLabel shows name followed by suffix in style, with the part of name
+// matching the active filter highlighted.
+func (b *ServiceBrowser) setLabel(label *widget.RichText, name, suffix string, style widget.RichTextStyle) {
+	style.Inline = true
+	var segments []widget.RichTextSegment
+	for _, part := range highlightParts(name, b.filterQuery) {
+		partStyle := style
+		if part.match {
+			partStyle.ColorName = theme.ColorNamePrimary
+			partStyle.TextStyle.Bold = true
+		}
+		segments = append(segments, &widget.TextSegment{Text: part.text, Style: partStyle})
+	}
+	if suffix != "" {
+		segments = append(segments, &widget.TextSegment{Text: suffix, Style: style})
+	}
+	label.Segments = segments
+	label.Refresh()
+}
+
+// appendNote adds a dimmed note after a label's text.
+func appendNote(label *widget.RichText, note string) {
+	label.Segments = append(label.Segments, &widget.TextSegment{
+		Text:  "  " + note,
+		Style: widget.RichTextStyle{Inline: true, ColorName: theme.ColorNameDisabled, SizeName: theme.SizeNameCaptionText},
+	})
+	label.Refresh()
+}
+
+// textPart is a run of label text that does or does not match the filter.
+type textPart struct {
+	text  string
+	match bool
+}
+
+// highlightParts splits text around case-insensitive occurrences of query
+// (which must already be lower case).
+func highlightParts(text, query string) []textPart {
+	if query == "" {
+		return []textPart{{text: text}}
+	}
+	var parts []textPart
+	lower := strings.ToLower(text)
+	for {
+		i := strings.Index(lower, query)
+		// Lower-casing can change byte lengths; highlight only when it did not
+		if i < 0 || len(lower) != len(text) {
+			break
+		}
+		if i > 0 {
+			parts = append(parts, textPart{text: text[:i]})
+		}
+		parts = append(parts, textPart{text: text[i : i+len(query)], match: true})
+		text, lower = text[i+len(query):], lower[i+len(query):]
+	}
+	if text != "" || len(parts) == 0 {
+		parts = append(parts, textPart{text: text})
+	}
+	return parts
+}
+
 // getMethodIcon returns the appropriate icon for a method type
 func (b *ServiceBrowser) getMethodIcon(method *domain.Method) fyne.Resource {
 	if method.IsClientStream && method.IsServerStream {
@@ -283,11 +659,89 @@ func (b *ServiceBrowser) getMethodTypeBadge(method *domain.Method) string {
 	}
 }
 
-// onTreeSelected handles tree selection events
+// showContextMenu opens the context menu of the node uid at pos, on the
+// canvas. Only methods and failed services have one.
+func (b *ServiceBrowser) showContextMenu(uid string, pos fyne.Position) {
+	menu := b.contextMenu(uid)
+	if menu == nil {
+		return
+	}
+	if c := fyne.CurrentApp().Driver().CanvasForObject(b.tree); c != nil {
+		widget.ShowPopUpMenuAtPosition(menu, c, pos)
+	}
+}
+
+// contextMenu returns the context menu of the node uid, or nil when it has
+// none. Methods whose types are unresolved can only have their names copied.
+// Pinned methods under Favorites have the same menu as the methods.
+func (b *ServiceBrowser) contextMenu(uid string) *fyne.Menu {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(uid, favoriteUIDPrefix), ":")
+	if !ok {
+		return b.serviceMenu(uid)
+	}
+	if b.methodActions == nil {
+		return nil
+	}
+	service := b.findService(serviceName)
+	if service == nil {
+		return nil
+	}
+	method := b.findMethod(*service, methodName)
+	if method == nil {
+		return nil
+	}
+
+	action := func(fn func(domain.Service, domain.Method)) func() {
+		return func() { fn(*service, *method) }
+	}
+	invoke := fyne.NewMenuItem("Invoke with empty request", action(b.methodActions.InvokeEmpty))
+	template := fyne.NewMenuItem("Generate template into editor", action(b.methodActions.GenerateTemplate))
+	invoke.Disabled = method.Error != ""
+	template.Disabled = method.Error != ""
+	return fyne.NewMenu("",
+		invoke,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Copy full method name", action(b.methodActions.CopyMethodName)),
+		fyne.NewMenuItem("Copy input type name", action(b.methodActions.CopyInputType)),
+		fyne.NewMenuItemSeparator(),
+		template,
+		fyne.NewMenuItemSeparator(),
+		b.favoriteMenuItem(*service, *method),
+	)
+}
+
+// serviceMenu returns the context menu of the service uid: diagnostics,
+// when it failed to resolve in whole or in part.
+func (b *ServiceBrowser) serviceMenu(uid string) *fyne.Menu {
+	service := b.findService(uid)
+	if service == nil || b.onDiagnose == nil || !serviceFailed(*service) {
+		return nil
+	}
+	return fyne.NewMenu("",
+		fyne.NewMenuItem("Diagnostics…", func() { b.onDiagnose(*service) }),
+	)
+}
+
+// serviceFailed reports whether the service or any of its methods could
+// not be resolved.
+func serviceFailed(service domain.Service) bool {
+	if service.Error != "" {
+		return true
+	}
+	for _, m := range service.Methods {
+		if m.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// onTreeSelected handles tree selection events. Selecting a method under
+// Favorites selects it just as under its service.
 func (b *ServiceBrowser) onTreeSelected(uid string) {
 	if strings.Contains(uid, ":") {
 		// Method selection (leaf)
-		parts := strings.Split(uid, ":")
+		parts := strings.Split(strings.TrimPrefix(uid, favoriteUIDPrefix), ":")
 		if len(parts) == 2 {
 			serviceName := parts[0]
 			methodName := parts[1]
@@ -295,13 +749,16 @@ func (b *ServiceBrowser) onTreeSelected(uid string) {
 			service := b.findService(serviceName)
 			if service != nil {
 				method := b.findMethod(*service, methodName)
-				if method != nil && b.onMethodSelect != nil {
+				if method != nil && method.Error != "" {
+					// Nothing can be built for it; the tooltip says why
+					b.tree.UnselectAll()
+				} else if method != nil && b.onMethodSelect != nil {
 					b.onMethodSelect(*service, *method)
 				}
 			}
 		}
 	} else {
-		// Service selection (branch)
+		// Package or service selection (branch)
 		service := b.findService(uid)
 		if service != nil && service.Error != "" && b.onServiceError != nil {
 			// Error service: show error details
@@ -347,7 +804,6 @@ func (b *ServiceBrowser) rebuildIndex() {
 	if b.content != nil {
 		if len(uids) == 0 {
 			b.filterEntry.SetText("")
-			b.filterQuery = ""
 			b.content.Objects = []fyne.CanvasObject{
 				container.NewBorder(nil, nil, nil, nil,
 					container.NewVBox(layout.NewSpacer(), b.placeholder, layout.NewSpacer()),
@@ -355,7 +811,15 @@ func (b *ServiceBrowser) rebuildIndex() {
 			}
 		} else {
 			b.content.Objects = []fyne.CanvasObject{
-				container.NewBorder(b.filterEntry, nil, nil, nil, b.tree),
+				container.NewBorder(
+					container.NewVBox(
+						container.NewBorder(nil, nil, nil, b.filterCount, b.filterEntry),
+						b.groupCheck,
+					),
+					b.sourceLabel, nil, nil, b.tree),
+			}
+			if b.filterQuery != "" {
+				b.setFilter(b.filterQuery)
 			}
 		}
 		b.content.Refresh()
@@ -408,17 +872,19 @@ func (b *ServiceBrowser) getServiceUIDs() []string {
 	return filtered
 }
 
-// getMethodUIDs returns the UIDs of all methods for a given service, filtered if a query is active.
+// getMethodUIDs returns the UIDs of all methods for a given service, filtered
+// if a query is active. A service whose own name matches keeps all its methods.
 func (b *ServiceBrowser) getMethodUIDs(serviceName string) []string {
 	service := b.findService(serviceName)
 	if service == nil {
 		return []string{}
 	}
 
+	all := b.filterQuery == "" || b.serviceNameMatchesFilter(serviceName, service)
 	uids := make([]string, 0, len(service.Methods))
 	for _, method := range service.Methods {
 		uid := fmt.Sprintf("%s:%s", serviceName, method.Name)
-		if b.filterQuery == "" || b.methodMatchesFilter(method) {
+		if all || b.methodMatchesFilter(method) {
 			uids = append(uids, uid)
 		}
 	}
@@ -426,17 +892,71 @@ func (b *ServiceBrowser) getMethodUIDs(serviceName string) []string {
 	return uids
 }
 
+// getPackageChildUIDs returns, for grouping by package, the package nodes
+// directly under pkg ("" for the root) followed by the services in pkg,
+// keeping only those with services left by the filter.
+func (b *ServiceBrowser) getPackageChildUIDs(pkg string) []string {
+	prefix := pkg
+	if prefix != "" {
+		prefix += "."
+	}
+
+	var packages, services []string
+	seen := make(map[string]bool)
+	for _, uid := range b.getServiceUIDs() {
+		servicePkg := packageOf(uid)
+		if servicePkg == pkg {
+			services = append(services, uid)
+			continue
+		}
+		rest, ok := strings.CutPrefix(servicePkg, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.IndexByte(rest, '.'); i >= 0 {
+			rest = rest[:i]
+		}
+		child := packageUIDPrefix + prefix + rest
+		if !seen[child] {
+			seen[child] = true
+			packages = append(packages, child)
+		}
+	}
+	sort.Strings(packages)
+	return append(packages, services...)
+}
+
+// packageOf returns the package of a service full name, e.g. "com.example"
+// for "com.example.UserService" ("" when it has none).
+func packageOf(fullName string) string {
+	if i := strings.LastIndexByte(fullName, '.'); i >= 0 {
+		return fullName[:i]
+	}
+	return ""
+}
+
+// packageAncestors returns the UIDs of the package nodes above a service
+// when grouping by package, outermost first: "#com", "#com.example".
+func packageAncestors(serviceName string) []string {
+	pkg := packageOf(serviceName)
+	if pkg == "" {
+		return nil
+	}
+	var uids []string
+	for i, r := range pkg {
+		if r == '.' {
+			uids = append(uids, packageUIDPrefix+pkg[:i])
+		}
+	}
+	return append(uids, packageUIDPrefix+pkg)
+}
+
 // serviceMatchesFilter returns true if a service or any of its methods match the filter.
 func (b *ServiceBrowser) serviceMatchesFilter(uid string, service *domain.Service) bool {
-	query := b.filterQuery
-	// Check service full name and display name
-	if strings.Contains(strings.ToLower(uid), query) {
+	if b.serviceNameMatchesFilter(uid, service) {
 		return true
 	}
 	if service != nil {
-		if strings.Contains(strings.ToLower(service.Name), query) {
-			return true
-		}
 		// Check if any method matches
 		for _, method := range service.Methods {
 			if b.methodMatchesFilter(method) {
@@ -447,9 +967,18 @@ func (b *ServiceBrowser) serviceMatchesFilter(uid string, service *domain.Servic
 	return false
 }
 
-// methodMatchesFilter returns true if a method name matches the filter.
+// serviceNameMatchesFilter returns true if a service's full or short name matches the filter.
+func (b *ServiceBrowser) serviceNameMatchesFilter(uid string, service *domain.Service) bool {
+	if strings.Contains(strings.ToLower(uid), b.filterQuery) {
+		return true
+	}
+	return service != nil && strings.Contains(strings.ToLower(service.Name), b.filterQuery)
+}
+
+// methodMatchesFilter returns true if a method's name or full name matches the filter.
 func (b *ServiceBrowser) methodMatchesFilter(method domain.Method) bool {
-	return strings.Contains(strings.ToLower(method.Name), b.filterQuery)
+	return strings.Contains(strings.ToLower(method.Name), b.filterQuery) ||
+		strings.Contains(strings.ToLower(method.FullName), b.filterQuery)
 }
 
 // findService finds a service by its full name using the O(1) index
@@ -2,11 +2,17 @@ package browser
 
 import (
 	"testing"
+	"time"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/components"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewServiceBrowser(t *testing.T) {
@@ -142,6 +148,11 @@ func TestServiceBrowser_IsBranch(t *testing.T) {
 			uid:      "example.UserService:GetUser",
 			expected: false,
 		},
+		{
+			name:     "package is branch",
+			uid:      "#com.example",
+			expected: true,
+		},
 		{
 			name:     "empty is branch",
 			uid:      "",
@@ -494,6 +505,115 @@ func TestServiceBrowser_ErrorService(t *testing.T) {
 	assert.True(t, browser.isBranch("example.BrokenService"))
 }
 
+func TestServiceBrowser_DeprecatedLabels(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "FetchUser", FullName: "example.UserService.FetchUser", Deprecated: true},
+		},
+	})
+	services.Append(domain.Service{
+		Name: "LegacyService", FullName: "example.LegacyService", Deprecated: true,
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+
+	labelFor := func(uid string, branch bool) *widget.RichText {
+		node := browser.create(branch)
+		browser.update(uid, branch, node)
+		return node.(*treeRow).content.Objects[1].(*widget.RichText)
+	}
+
+	assert.Equal(t, "GetUser", labelFor("example.UserService:GetUser", false).String())
+
+	deprecated := labelFor("example.UserService:FetchUser", false)
+	assert.Equal(t, "FetchUser  (deprecated)", deprecated.String())
+	assert.True(t, deprecated.Segments[0].(*widget.TextSegment).Style.TextStyle.Italic)
+
+	assert.Equal(t, "UserService  (2)", labelFor("example.UserService", true).String())
+	assert.Equal(t, "LegacyService  (0)  (deprecated)", labelFor("example.LegacyService", true).String())
+}
+
+func TestServiceBrowser_MethodStats(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "ListUsers", FullName: "example.UserService.ListUsers"},
+		},
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+	browser.SetMethodStats(map[string]domain.MethodStatsSummary{
+		"example.UserService/GetUser": {Calls: 12, LastCode: "OK", P50: 45 * time.Millisecond, P95: 90 * time.Millisecond, Samples: 12},
+	})
+
+	rowFor := func(uid string) (*components.TooltipIcon, *widget.RichText) {
+		node := browser.create(false)
+		browser.update(uid, false, node)
+		objects := node.(*treeRow).content.Objects
+		return objects[0].(*components.TooltipIcon), objects[1].(*widget.RichText)
+	}
+
+	icon, label := rowFor("example.UserService:GetUser")
+	assert.Equal(t, "GetUser  12 calls · 45ms", label.String())
+	assert.Equal(t, "12 calls, last OK · p50 45ms, p95 90ms over the last 12", icon.Tooltip())
+
+	icon, label = rowFor("example.UserService:ListUsers")
+	assert.Equal(t, "ListUsers", label.String(), "methods never called have no note")
+	assert.Empty(t, icon.Tooltip())
+
+	browser.SetMethodStats(nil)
+	_, label = rowFor("example.UserService:GetUser")
+	assert.Equal(t, "GetUser", label.String())
+}
+
+func TestServiceBrowser_UnresolvedMethod(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "ListUsers", FullName: "example.UserService.ListUsers",
+				Error: "input type example.ListUsersRequest could not be resolved"},
+		},
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+
+	var selected []string
+	browser.SetOnMethodSelect(func(_ domain.Service, method domain.Method) {
+		selected = append(selected, method.Name)
+	})
+
+	node := browser.create(false)
+	browser.update("example.UserService:ListUsers", false, node)
+	objects := node.(*treeRow).content.Objects
+	icon, label := objects[0].(*components.TooltipIcon), objects[1].(*widget.RichText)
+	assert.Equal(t, "ListUsers  (unresolved)", label.String())
+	assert.Equal(t, "input type example.ListUsersRequest could not be resolved", icon.Tooltip())
+	style := label.Segments[0].(*widget.TextSegment).Style
+	assert.Equal(t, theme.ColorNameDisabled, style.ColorName)
+
+	// A reused row shown for a working method loses the tooltip
+	browser.update("example.UserService:GetUser", false, node)
+	assert.Equal(t, "GetUser", label.String())
+	assert.Empty(t, icon.Tooltip())
+
+	browser.onTreeSelected("example.UserService:ListUsers")
+	browser.onTreeSelected("example.UserService:GetUser")
+	assert.Equal(t, []string{"GetUser"}, selected, "unresolved methods must not open")
+}
+
 func TestServiceBrowser_SortedAlphabetically(t *testing.T) {
 	app := test.NewApp()
 	defer app.Quit()
@@ -584,11 +704,80 @@ func TestServiceBrowser_FilterServices(t *testing.T) {
 	assert.Len(t, methods, 1)
 	assert.Equal(t, "example.UserService:ListUsers", methods[0])
 
+	// A service matched by name keeps all its methods
+	browser.filterQuery = "userservice"
+	assert.Equal(t, []string{"example.UserService:GetUser", "example.UserService:ListUsers"},
+		browser.getMethodUIDs("example.UserService"))
+
+	// Method full names match across the service/method boundary
+	browser.filterQuery = "productservice.get"
+	assert.Equal(t, []string{"example.ProductService"}, browser.getServiceUIDs())
+	assert.Equal(t, []string{"example.ProductService:GetProduct"},
+		browser.getMethodUIDs("example.ProductService"))
+
 	// Clear filter
 	browser.filterQuery = ""
 	assert.Len(t, browser.getServiceUIDs(), 2)
 }
 
+func TestServiceBrowser_SetFilter(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "ListUsers", FullName: "example.UserService.ListUsers"},
+		},
+	})
+	services.Append(domain.Service{
+		Name: "ProductService", FullName: "example.ProductService",
+		Methods: []domain.Method{
+			{Name: "GetProduct", FullName: "example.ProductService.GetProduct"},
+			{Name: "ListProducts", FullName: "example.ProductService.ListProducts"},
+		},
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+	browser.tree.OpenBranch("example.UserService") // opened by the user
+
+	browser.filterEntry.SetText("Get")
+	assert.True(t, browser.tree.IsBranchOpen("example.ProductService"), "matching branches open")
+	assert.True(t, browser.tree.IsBranchOpen("example.UserService"))
+	assert.Equal(t, "2 methods in 2 services", browser.filterCount.Text)
+	assert.True(t, browser.filterCount.Visible())
+
+	browser.filterEntry.SetText("listproducts")
+	assert.Equal(t, "1 method", browser.filterCount.Text)
+
+	browser.filterEntry.SetText("nothing")
+	assert.Equal(t, "No matches", browser.filterCount.Text)
+	assert.Empty(t, browser.childUIDs(""))
+
+	// Clearing restores the full tree and closes only the filter's branches
+	browser.filterEntry.SetText("")
+	assert.Equal(t, []string{"example.ProductService", "example.UserService"}, browser.childUIDs(""))
+	assert.False(t, browser.tree.IsBranchOpen("example.ProductService"))
+	assert.True(t, browser.tree.IsBranchOpen("example.UserService"))
+	assert.False(t, browser.filterCount.Visible())
+}
+
+func TestHighlightParts(t *testing.T) {
+	assert.Equal(t, []textPart{{text: "GetUser"}}, highlightParts("GetUser", ""))
+	assert.Equal(t, []textPart{{text: "GetUser"}}, highlightParts("GetUser", "list"))
+	assert.Equal(t, []textPart{
+		{text: "Get"},
+		{text: "User", match: true},
+	}, highlightParts("GetUser", "user"))
+	assert.Equal(t, []textPart{
+		{text: "Us", match: true},
+		{text: "er"},
+		{text: "us", match: true},
+		{text: "e"},
+	}, highlightParts("Useruse", "us"))
+}
+
 func TestBuildDisplayNames_NoCollision(t *testing.T) {
 	index := map[string]domain.Service{
 		"com.example.api.UserService":    {Name: "UserService", FullName: "com.example.api.UserService"},
@@ -651,3 +840,260 @@ func TestServiceBrowser_OnServiceError(t *testing.T) {
 	assert.Equal(t, "BrokenService", capturedService.Name)
 	assert.Equal(t, "unresolvable type dependency", capturedService.Error)
 }
+
+func TestServiceBrowser_SetSource(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	browser := NewServiceBrowser(binding.NewUntypedList(), binding.NewString())
+	assert.False(t, browser.sourceLabel.Visible(), "source should be hidden initially")
+
+	browser.SetSource("descriptors.pb")
+	assert.True(t, browser.sourceLabel.Visible())
+	assert.Equal(t, "Source: descriptors.pb", browser.sourceLabel.Text)
+
+	browser.SetSource("")
+	assert.False(t, browser.sourceLabel.Visible(), "empty source should hide the indicator")
+}
+
+func TestServiceBrowser_KeyboardNavigation(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	browser := NewServiceBrowser(services, binding.NewString())
+	w := test.NewWindow(browser)
+	defer w.Close()
+	_ = services.Append(domain.Service{
+		Name:     "UserService",
+		FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "ListUsers", FullName: "example.UserService.ListUsers"},
+		},
+	})
+
+	var selected []string
+	browser.SetOnMethodSelect(func(service domain.Service, method domain.Method) {
+		selected = append(selected, method.Name)
+	})
+
+	// Enter in the filter moves focus to the tree
+	browser.filterEntry.OnSubmitted("")
+	assert.Equal(t, browser.tree, w.Canvas().Focused())
+
+	// Right opens the service and focuses its first method
+	browser.tree.TypedKey(&fyne.KeyEvent{Name: fyne.KeyRight})
+	browser.tree.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	assert.Empty(t, selected, "moving focus does not select")
+
+	browser.tree.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	assert.Equal(t, []string{"ListUsers"}, selected)
+
+	browser.tree.TypedKey(&fyne.KeyEvent{Name: fyne.KeyUp})
+	browser.tree.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEnter})
+	assert.Equal(t, []string{"ListUsers", "GetUser"}, selected)
+}
+
+func TestServiceBrowser_GroupByPackage(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "com.example.api.UserService", File: "com/example/api/user.proto",
+		Methods: []domain.Method{{Name: "GetUser", FullName: "com.example.api.UserService.GetUser"}},
+	})
+	services.Append(domain.Service{
+		Name: "AdminService", FullName: "com.example.AdminService", File: "com/example/admin.proto",
+		Methods: []domain.Method{{Name: "Ban", FullName: "com.example.AdminService.Ban"}},
+	})
+	services.Append(domain.Service{
+		Name: "Health", FullName: "grpc.health.v1.Health",
+		Methods: []domain.Method{{Name: "Check", FullName: "grpc.health.v1.Health.Check"}},
+	})
+	services.Append(domain.Service{
+		Name: "Bare", FullName: "Bare",
+		Methods: []domain.Method{{Name: "Ping", FullName: "Bare.Ping"}},
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+
+	// Flat by default
+	assert.False(t, browser.GroupByPackage())
+	assert.Equal(t, []string{"Bare", "com.example.AdminService", "com.example.api.UserService", "grpc.health.v1.Health"},
+		browser.childUIDs(""))
+
+	var toggled []bool
+	browser.SetOnGroupByPackageChange(func(grouped bool) { toggled = append(toggled, grouped) })
+	test.Tap(browser.groupCheck)
+	assert.True(t, browser.GroupByPackage())
+	assert.Equal(t, []bool{true}, toggled)
+
+	// Packages first, then services without a package
+	assert.Equal(t, []string{"#com", "#grpc", "Bare"}, browser.childUIDs(""))
+	assert.Equal(t, []string{"#com.example"}, browser.childUIDs("#com"))
+	assert.Equal(t, []string{"#com.example.api", "com.example.AdminService"}, browser.childUIDs("#com.example"))
+	assert.Equal(t, []string{"com.example.api.UserService"}, browser.childUIDs("#com.example.api"))
+	assert.Equal(t, []string{"com.example.api.UserService:GetUser"}, browser.childUIDs("com.example.api.UserService"))
+	assert.Equal(t, []string{"#grpc.health"}, browser.childUIDs("#grpc"))
+	assert.True(t, browser.isBranch("#com.example.api"))
+
+	nodeFor := func(uid string) (*components.TooltipIcon, *widget.RichText) {
+		node := browser.create(browser.isBranch(uid))
+		browser.update(uid, browser.isBranch(uid), node)
+		objects := node.(*treeRow).content.Objects
+		return objects[0].(*components.TooltipIcon), objects[1].(*widget.RichText)
+	}
+	icon, label := nodeFor("#com.example.api")
+	assert.Equal(t, "api", label.String())
+	assert.Equal(t, "com.example.api", icon.Tooltip())
+	icon, label = nodeFor("com.example.api.UserService")
+	assert.Equal(t, "UserService  (1)", label.String())
+	assert.Equal(t, "com/example/api/user.proto", icon.Tooltip(), "the defining file shows on hover")
+	icon, _ = nodeFor("com.example.api.UserService:GetUser")
+	assert.Empty(t, icon.Tooltip(), "recycled rows drop the tooltip")
+
+	// The filter prunes packages without matches and opens the way down
+	browser.filterEntry.SetText("getuser")
+	assert.Equal(t, []string{"#com"}, browser.childUIDs(""))
+	assert.Equal(t, []string{"#com.example.api"}, browser.childUIDs("#com.example"))
+	for _, uid := range []string{"#com", "#com.example", "#com.example.api", "com.example.api.UserService"} {
+		assert.True(t, browser.tree.IsBranchOpen(uid), uid)
+	}
+	browser.filterEntry.SetText("")
+	assert.False(t, browser.tree.IsBranchOpen("#com"))
+
+	// Selecting a method opens its packages
+	var selected string
+	browser.SetOnMethodSelect(func(_ domain.Service, m domain.Method) { selected = m.FullName })
+	browser.SelectMethod("grpc.health.v1.Health", "Check")
+	assert.Equal(t, "grpc.health.v1.Health.Check", selected)
+	assert.True(t, browser.tree.IsBranchOpen("#grpc.health.v1"))
+
+	browser.CollapseAll()
+	assert.False(t, browser.tree.IsBranchOpen("#grpc"))
+	browser.ExpandAll()
+	assert.True(t, browser.tree.IsBranchOpen("#com.example.api"))
+
+	// Back to flat
+	browser.SetGroupByPackage(false)
+	assert.False(t, browser.groupCheck.Checked)
+	assert.Len(t, browser.childUIDs(""), 4)
+}
+
+func TestPackageAncestors(t *testing.T) {
+	assert.Equal(t, []string{"#com", "#com.example", "#com.example.api"}, packageAncestors("com.example.api.UserService"))
+	assert.Equal(t, []string{"#example"}, packageAncestors("example.UserService"))
+	assert.Nil(t, packageAncestors("Bare"))
+}
+
+// recordedActions records which method actions were called, and for what.
+type recordedActions struct {
+	calls []string
+}
+
+func (r *recordedActions) record(action string, method domain.Method) {
+	r.calls = append(r.calls, action+" "+method.FullName)
+}
+
+func (r *recordedActions) InvokeEmpty(_ domain.Service, m domain.Method)    { r.record("invoke", m) }
+func (r *recordedActions) CopyMethodName(_ domain.Service, m domain.Method) { r.record("copy-name", m) }
+func (r *recordedActions) CopyInputType(_ domain.Service, m domain.Method)  { r.record("copy-input", m) }
+func (r *recordedActions) GenerateTemplate(_ domain.Service, m domain.Method) {
+	r.record("template", m)
+}
+
+func TestServiceBrowser_MethodContextMenu(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{
+			{Name: "GetUser", FullName: "example.UserService.GetUser"},
+			{Name: "ListUsers", FullName: "example.UserService.ListUsers", Error: "unresolved"},
+		},
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+	assert.Nil(t, browser.contextMenu("example.UserService:GetUser"), "no menu without actions")
+
+	actions := &recordedActions{}
+	browser.SetMethodActions(actions)
+	assert.Nil(t, browser.contextMenu("example.UserService"), "services have no menu")
+
+	// Each item dispatches to its action with the method right-clicked
+	menu := browser.contextMenu("example.UserService:GetUser")
+	var labels []string
+	for _, item := range menu.Items {
+		if item.IsSeparator {
+			continue
+		}
+		labels = append(labels, item.Label)
+		assert.False(t, item.Disabled, item.Label)
+		item.Action()
+	}
+	assert.Equal(t, []string{
+		"Invoke with empty request",
+		"Copy full method name",
+		"Copy input type name",
+		"Generate template into editor",
+		"Add to Favorites",
+	}, labels)
+	assert.Equal(t, []string{
+		"invoke example.UserService.GetUser",
+		"copy-name example.UserService.GetUser",
+		"copy-input example.UserService.GetUser",
+		"template example.UserService.GetUser",
+	}, actions.calls)
+
+	// Unresolved methods cannot be invoked or templated
+	for _, item := range browser.contextMenu("example.UserService:ListUsers").Items {
+		switch item.Label {
+		case "Invoke with empty request", "Generate template into editor":
+			assert.True(t, item.Disabled, item.Label)
+		case "Copy full method name", "Copy input type name":
+			assert.False(t, item.Disabled, item.Label)
+		}
+	}
+
+	// A secondary tap on a method row opens its menu on the canvas
+	w := test.NewWindow(browser.tree)
+	defer w.Close()
+	node := browser.create(false)
+	browser.update("example.UserService:GetUser", false, node)
+	node.(*treeRow).TappedSecondary(&fyne.PointEvent{})
+	assert.NotEmpty(t, w.Canvas().Overlays().List(), "context menu shown")
+}
+
+func TestServiceBrowser_DiagnoseMenu(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	services := binding.NewUntypedList()
+	services.Append(domain.Service{Name: "BrokenService", FullName: "example.BrokenService", Error: "unresolvable"})
+	services.Append(domain.Service{
+		Name: "PartialService", FullName: "example.PartialService",
+		Methods: []domain.Method{{Name: "Get", FullName: "example.PartialService.Get", Error: "unresolved"}},
+	})
+	services.Append(domain.Service{
+		Name: "UserService", FullName: "example.UserService",
+		Methods: []domain.Method{{Name: "GetUser", FullName: "example.UserService.GetUser"}},
+	})
+	browser := NewServiceBrowser(services, binding.NewString())
+	assert.Nil(t, browser.contextMenu("example.BrokenService"), "no menu without the action")
+
+	var diagnosed []string
+	browser.SetOnDiagnose(func(service domain.Service) {
+		diagnosed = append(diagnosed, service.FullName)
+	})
+	assert.Nil(t, browser.contextMenu("example.UserService"), "resolved services have no menu")
+	for _, uid := range []string{"example.BrokenService", "example.PartialService"} {
+		menu := browser.contextMenu(uid)
+		require.NotNil(t, menu, uid)
+		require.Len(t, menu.Items, 1)
+		assert.Equal(t, "Diagnostics…", menu.Items[0].Label)
+		menu.Items[0].Action()
+	}
+	assert.Equal(t, []string{"example.BrokenService", "example.PartialService"}, diagnosed)
+}
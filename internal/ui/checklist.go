@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"log/slog"
+
+	"fyne.io/fyne/v2/dialog"
+	"github.com/shhac/grotto/internal/checklist"
+	"github.com/shhac/grotto/internal/domain"
+	uichecklist "github.com/shhac/grotto/internal/ui/checklist"
+)
+
+// showChecklistEditor opens the editor for the workspace startup checklist.
+// Changes are kept in memory and persisted with the next workspace save.
+func (w *MainWindow) showChecklistEditor() {
+	uichecklist.ShowEditorDialog(w.window, w.checklist, func(items []domain.ChecklistItem) {
+		w.checklist = items
+		w.logger.Info("checklist updated", slog.Int("items", len(items)))
+	})
+}
+
+// handleRunChecklist runs the startup checklist against dedicated
+// connections, leaving the current connection untouched. Metadata checks
+// and method calls use the request panel's current metadata.
+func (w *MainWindow) handleRunChecklist() {
+	if len(w.checklist) == 0 {
+		dialog.ShowConfirm("Startup Checklist",
+			"This workspace has no checklist yet. Create one now?",
+			func(create bool) {
+				if create {
+					w.showChecklistEditor()
+				}
+			},
+			w.window,
+		)
+		return
+	}
+
+	runner := checklist.NewRunner(checklist.NewGRPCEnv(w.logger), w.logger)
+	uichecklist.ShowRunDialog(w.window, runner, w.checklist, w.requestPanel.SendMetadata(), w.handleChecklistFix)
+}
+
+// handleChecklistFix jumps to the place where a failed check can be fixed.
+func (w *MainWindow) handleChecklistFix(index int, fix checklist.Fix) {
+	switch fix {
+	case checklist.FixMetadata:
+		w.requestPanel.ShowMetadataTab()
+	case checklist.FixConnection, checklist.FixItem:
+		if index < 0 || index >= len(w.checklist) {
+			return
+		}
+		uichecklist.ShowItemDialog(w.window, w.checklist[index], func(item domain.ChecklistItem) {
+			if index < len(w.checklist) {
+				w.checklist[index] = item
+			}
+		})
+	}
+}
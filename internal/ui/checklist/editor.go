@@ -0,0 +1,133 @@
+package checklist
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/checklist"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// ShowEditorDialog displays the checklist editor. Items are edited on a
+// copy; onSave receives the new list only when the user saves.
+func ShowEditorDialog(window fyne.Window, items []domain.ChecklistItem, onSave func([]domain.ChecklistItem)) {
+	working := append([]domain.ChecklistItem(nil), items...)
+	selected := -1
+
+	placeholder := widget.NewLabel("No checks yet — add a connect check first, then method and metadata checks")
+	placeholder.Alignment = fyne.TextAlignCenter
+	placeholder.Wrapping = fyne.TextWrapWord
+	placeholder.TextStyle = fyne.TextStyle{Italic: true}
+
+	list := widget.NewList(
+		func() int { return len(working) },
+		func() fyne.CanvasObject {
+			kind := widget.NewLabelWithStyle("Metadata", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			desc := widget.NewLabel("template")
+			desc.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, kind, nil, desc)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			ct := o.(*fyne.Container)
+			ct.Objects[0].(*widget.Label).SetText(checklist.Describe(working[id]))
+			ct.Objects[1].(*widget.Label).SetText(working[id].Kind.String())
+		},
+	)
+
+	var editBtn, removeBtn, upBtn, downBtn *widget.Button
+	refresh := func() {
+		list.Refresh()
+		if len(working) == 0 {
+			placeholder.Show()
+		} else {
+			placeholder.Hide()
+		}
+		if selected >= 0 && selected < len(working) {
+			editBtn.Enable()
+			removeBtn.Enable()
+			upBtn.Enable()
+			downBtn.Enable()
+		} else {
+			editBtn.Disable()
+			removeBtn.Disable()
+			upBtn.Disable()
+			downBtn.Disable()
+		}
+	}
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		refresh()
+	}
+
+	move := func(delta int) {
+		to := selected + delta
+		if selected < 0 || to < 0 || to >= len(working) {
+			return
+		}
+		working[selected], working[to] = working[to], working[selected]
+		selected = to
+		list.Select(to)
+		refresh()
+	}
+
+	add := func(kind domain.ChecklistItemKind) {
+		ShowItemDialog(window, domain.ChecklistItem{Kind: kind}, func(item domain.ChecklistItem) {
+			working = append(working, item)
+			selected = len(working) - 1
+			list.Select(selected)
+			refresh()
+		})
+	}
+
+	addBtn := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), nil)
+	addMenu := fyne.NewMenu("",
+		fyne.NewMenuItem("Connect to profile", func() { add(domain.ChecklistConnect) }),
+		fyne.NewMenuItem("Method returns status", func() { add(domain.ChecklistMethod) }),
+		fyne.NewMenuItem("Metadata present", func() { add(domain.ChecklistMetadata) }),
+	)
+	addBtn.OnTapped = func() {
+		pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(addBtn)
+		widget.ShowPopUpMenuAtPosition(addMenu, window.Canvas(), pos.AddXY(0, addBtn.Size().Height))
+	}
+
+	editBtn = widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), func() {
+		if selected < 0 || selected >= len(working) {
+			return
+		}
+		idx := selected
+		ShowItemDialog(window, working[idx], func(item domain.ChecklistItem) {
+			working[idx] = item
+			refresh()
+		})
+	})
+	removeBtn = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if selected < 0 || selected >= len(working) {
+			return
+		}
+		working = append(working[:selected], working[selected+1:]...)
+		selected = -1
+		list.UnselectAll()
+		refresh()
+	})
+	upBtn = widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { move(-1) })
+	downBtn = widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { move(1) })
+	refresh()
+
+	hint := widget.NewLabel("Checks run top to bottom. Method checks use the most recent connect check.")
+	hint.Importance = widget.LowImportance
+	hint.Wrapping = fyne.TextWrapWord
+
+	toolbar := container.NewHBox(addBtn, editBtn, removeBtn, upBtn, downBtn)
+	content := container.NewBorder(toolbar, hint, nil, nil,
+		container.NewStack(list, placeholder))
+
+	dlg := dialog.NewCustomConfirm("Startup Checklist", "Save", "Cancel", content, func(save bool) {
+		if save {
+			onSave(working)
+		}
+	}, window)
+	dlg.Resize(fyne.NewSize(640, 460))
+	dlg.Show()
+}
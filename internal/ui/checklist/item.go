@@ -0,0 +1,210 @@
+package checklist
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/checklist"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/settings"
+	"google.golang.org/grpc/codes"
+)
+
+// statusCodeNames lists the status codes offered for method checks
+var statusCodeNames = func() []string {
+	names := make([]string, 0, 17)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		names = append(names, c.String())
+	}
+	return names
+}()
+
+// ShowItemDialog displays a dialog for editing a single checklist item.
+// onSave receives the edited copy; the original is left untouched.
+func ShowItemDialog(window fyne.Window, item domain.ChecklistItem, onSave func(domain.ChecklistItem)) {
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder("Optional — defaults to a description of the check")
+	labelEntry.SetText(item.Label)
+
+	formItems := []*widget.FormItem{widget.NewFormItem("Label", labelEntry)}
+	var apply func(*domain.ChecklistItem) error
+
+	switch item.Kind {
+	case domain.ChecklistConnect:
+		conn := domain.Connection{}
+		if item.Connection != nil {
+			conn = *item.Connection
+		}
+
+		nameEntry := widget.NewEntry()
+		nameEntry.SetPlaceHolder("Profile name (optional)")
+		nameEntry.SetText(conn.Name)
+
+		addressEntry := widget.NewEntry()
+		addressEntry.SetPlaceHolder("localhost:50051")
+		addressEntry.SetText(conn.Address)
+
+		timeoutEntry := newDurationEntry(conn.Timeout)
+
+		settingsSummary := widget.NewLabel(connectionSummary(conn))
+		settingsBtn := widget.NewButton("Connection Settings...", func() {
+			settings.ShowConnectionDialog(window, conn, func(updated domain.Connection) {
+				conn.TLS = updated.TLS
+				conn.Transport = updated.Transport
+				settingsSummary.SetText(connectionSummary(conn))
+			})
+		})
+
+		formItems = append(formItems,
+			widget.NewFormItem("Profile", nameEntry),
+			widget.NewFormItem("Address", addressEntry),
+			widget.NewFormItem("Timeout", timeoutEntry),
+			widget.NewFormItem("", container.NewBorder(nil, nil, nil, settingsBtn, settingsSummary)),
+		)
+		apply = func(it *domain.ChecklistItem) error {
+			address := strings.TrimSpace(addressEntry.Text)
+			if address == "" {
+				return errors.New("address is required")
+			}
+			timeout, err := parseDuration(timeoutEntry.Text)
+			if err != nil {
+				return err
+			}
+			conn.Name = strings.TrimSpace(nameEntry.Text)
+			conn.Address = address
+			conn.Timeout = timeout
+			it.Connection = &conn
+			return nil
+		}
+
+	case domain.ChecklistMethod:
+		methodEntry := widget.NewEntry()
+		methodEntry.SetPlaceHolder("package.Service/Method")
+		methodEntry.SetText(item.Method)
+
+		expectSelect := widget.NewSelect(statusCodeNames, nil)
+		expect, err := checklist.ExpectedCode(item.ExpectCode)
+		if err != nil {
+			expect = codes.OK
+		}
+		expectSelect.SetSelected(expect.String())
+
+		timeoutEntry := newDurationEntry(item.Timeout)
+		timeoutEntry.SetPlaceHolder("e.g. 2s (default " + checklist.DefaultMethodTimeout.String() + ")")
+
+		bodyEntry := widget.NewMultiLineEntry()
+		bodyEntry.SetPlaceHolder("{}")
+		bodyEntry.SetMinRowsVisible(4)
+		bodyEntry.SetText(item.Body)
+
+		formItems = append(formItems,
+			widget.NewFormItem("Method", methodEntry),
+			widget.NewFormItem("Expect", expectSelect),
+			widget.NewFormItem("Within", timeoutEntry),
+			widget.NewFormItem("Body", bodyEntry),
+		)
+		apply = func(it *domain.ChecklistItem) error {
+			if _, _, err := checklist.SplitMethod(methodEntry.Text); err != nil {
+				return err
+			}
+			timeout, err := parseDuration(timeoutEntry.Text)
+			if err != nil {
+				return err
+			}
+			it.Method = strings.TrimPrefix(strings.TrimSpace(methodEntry.Text), "/")
+			it.ExpectCode = expectSelect.Selected
+			if it.ExpectCode == codes.OK.String() {
+				it.ExpectCode = ""
+			}
+			it.Timeout = timeout
+			it.Body = bodyEntry.Text
+			return nil
+		}
+
+	case domain.ChecklistMetadata:
+		keyEntry := widget.NewEntry()
+		keyEntry.SetPlaceHolder("authorization")
+		keyEntry.SetText(item.MetadataKey)
+
+		jwtCheck := widget.NewCheck("Value is a JWT — fail if its exp claim has passed", nil)
+		jwtCheck.SetChecked(item.CheckJWTExpiry)
+
+		formItems = append(formItems,
+			widget.NewFormItem("Key", keyEntry),
+			widget.NewFormItem("", jwtCheck),
+		)
+		apply = func(it *domain.ChecklistItem) error {
+			key := strings.ToLower(strings.TrimSpace(keyEntry.Text))
+			if key == "" {
+				return errors.New("metadata key is required")
+			}
+			it.MetadataKey = key
+			it.CheckJWTExpiry = jwtCheck.Checked
+			return nil
+		}
+
+	default:
+		dialog.ShowError(errors.New("unsupported checklist item kind: "+string(item.Kind)), window)
+		return
+	}
+
+	form := widget.NewForm(formItems...)
+	dlg := dialog.NewCustomConfirm(item.Kind.String()+" Check", "Save", "Cancel", form, func(save bool) {
+		if !save {
+			return
+		}
+		updated := item
+		updated.Label = strings.TrimSpace(labelEntry.Text)
+		if err := apply(&updated); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		onSave(updated)
+	}, window)
+	dlg.Resize(fyne.NewSize(520, 0))
+	dlg.Show()
+}
+
+// newDurationEntry creates an entry pre-filled with d (blank when zero)
+func newDurationEntry(d time.Duration) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("e.g. 5s")
+	if d > 0 {
+		entry.SetText(d.String())
+	}
+	entry.Validator = func(s string) error {
+		_, err := parseDuration(s)
+		return err
+	}
+	return entry
+}
+
+// parseDuration parses a Go duration string; blank means zero (use the default)
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.New("invalid duration " + s + " (use e.g. 500ms, 2s, 1m)")
+	}
+	return d, nil
+}
+
+// connectionSummary describes a profile's transport and TLS settings
+func connectionSummary(conn domain.Connection) string {
+	tls := "plaintext"
+	if conn.TLS.Enabled {
+		tls = "TLS"
+		if conn.TLS.SkipVerify {
+			tls += " (skip verify)"
+		}
+	}
+	return conn.Transport.String() + ", " + tls
+}
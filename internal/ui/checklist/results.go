@@ -0,0 +1,138 @@
+package checklist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/checklist"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// fixLabels are the button captions for each fix action
+var fixLabels = map[checklist.Fix]string{
+	checklist.FixConnection: "Edit Profile",
+	checklist.FixMetadata:   "Edit Metadata",
+	checklist.FixItem:       "Edit Check",
+}
+
+// ShowRunDialog runs the checklist and displays live results. Failed items
+// offer a button that closes the dialog and calls onFix with the item index
+// and the suggested fix. Closing the dialog cancels any remaining checks.
+func ShowRunDialog(
+	window fyne.Window,
+	runner *checklist.Runner,
+	items []domain.ChecklistItem,
+	md map[string]string,
+	onFix func(index int, fix checklist.Fix),
+) {
+	items = append([]domain.ChecklistItem(nil), items...)
+	results := make([]*checklist.Result, len(items))
+
+	banner := widget.NewLabelWithStyle(
+		fmt.Sprintf("Running 0 of %d checks...", len(items)),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	progress := widget.NewProgressBar()
+	progress.Max = float64(len(items))
+
+	var dlg *dialog.CustomDialog
+
+	list := widget.NewList(
+		func() int { return len(items) },
+		func() fyne.CanvasObject {
+			icon := widget.NewIcon(theme.MoreHorizontalIcon())
+			desc := widget.NewLabel("template")
+			desc.Truncation = fyne.TextTruncateEllipsis
+			detail := widget.NewLabel("detail")
+			detail.Importance = widget.LowImportance
+			detail.Truncation = fyne.TextTruncateEllipsis
+			fixBtn := widget.NewButton("Edit Metadata", nil)
+			fixBtn.Importance = widget.LowImportance
+			return container.NewBorder(nil, nil, icon, fixBtn, container.NewVBox(desc, detail))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			ct := o.(*fyne.Container)
+			text := ct.Objects[0].(*fyne.Container)
+			icon := ct.Objects[1].(*widget.Icon)
+			fixBtn := ct.Objects[2].(*widget.Button)
+			desc := text.Objects[0].(*widget.Label)
+			detail := text.Objects[1].(*widget.Label)
+
+			desc.SetText(checklist.Describe(items[id]))
+
+			res := results[id]
+			if res == nil {
+				icon.SetResource(theme.MoreHorizontalIcon())
+				detail.SetText("Pending")
+				fixBtn.Hide()
+				return
+			}
+
+			detail.SetText(fmt.Sprintf("%s — %s (%s)",
+				res.Status, res.Message, res.Duration.Round(time.Millisecond)))
+			switch res.Status {
+			case checklist.StatusPass:
+				icon.SetResource(theme.ConfirmIcon())
+			case checklist.StatusFail:
+				icon.SetResource(theme.ErrorIcon())
+			default:
+				icon.SetResource(theme.MediaSkipNextIcon())
+			}
+
+			label, ok := fixLabels[res.Fix]
+			if res.Status != checklist.StatusFail || !ok || onFix == nil {
+				fixBtn.Hide()
+				return
+			}
+			index, fix := res.Index, res.Fix
+			fixBtn.SetText(label)
+			fixBtn.OnTapped = func() {
+				dlg.Hide()
+				onFix(index, fix)
+			}
+			fixBtn.Show()
+		},
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(banner, progress, widget.NewSeparator()),
+		nil, nil, nil,
+		list,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dlg = dialog.NewCustom("Startup Checklist", "Close", content, window)
+	dlg.SetOnClosed(cancel)
+	dlg.Resize(fyne.NewSize(700, 460))
+	dlg.Show()
+
+	go func() {
+		done := 0
+		summary := runner.Run(ctx, items, md, func(res checklist.Result) {
+			fyne.Do(func() {
+				results[res.Index] = &res
+				done++
+				progress.SetValue(float64(done))
+				banner.SetText(fmt.Sprintf("Running %d of %d checks...", done, len(items)))
+				list.RefreshItem(res.Index)
+			})
+		})
+
+		fyne.Do(func() {
+			progress.Hide()
+			if summary.OK() {
+				banner.SetText("All checks passed — " + summary.String())
+				banner.Importance = widget.SuccessImportance
+			} else {
+				banner.SetText("Checklist failed — " + summary.String())
+				banner.Importance = widget.DangerImportance
+			}
+			banner.Refresh()
+		})
+	}()
+}
@@ -0,0 +1,161 @@
+package components
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/tokencmd"
+)
+
+// authOptions lists the selectable auth types in display order.
+var authOptions = []domain.AuthType{domain.AuthNone, domain.AuthBearer, domain.AuthBasic, domain.AuthCommand}
+
+// AuthEditor edits an authorization preset: a type selector and the token,
+// username and password, or token command for it. Secrets use password
+// entries, which have a reveal toggle.
+type AuthEditor struct {
+	widget.BaseWidget
+
+	typeSelect *widget.Select
+	token      *widget.Entry
+	username   *widget.Entry
+	password   *widget.Entry
+	command    *widget.Entry
+	ttl        *widget.Entry
+
+	bearerFields  *fyne.Container
+	basicFields   *fyne.Container
+	commandFields *fyne.Container
+	content       *fyne.Container
+
+	onChanged func(domain.Auth)
+	setting   bool // suppresses onChanged while SetAuth fills the fields
+}
+
+// NewAuthEditor creates an editor showing no auth.
+func NewAuthEditor() *AuthEditor {
+	e := &AuthEditor{}
+
+	labels := make([]string, len(authOptions))
+	for i, opt := range authOptions {
+		labels[i] = opt.String()
+	}
+	e.typeSelect = widget.NewSelect(labels, func(string) {
+		e.updateFields()
+		e.changed()
+	})
+
+	e.token = widget.NewPasswordEntry()
+	e.token.SetPlaceHolder("Token (the Bearer prefix is added for you)")
+	e.username = widget.NewEntry()
+	e.username.SetPlaceHolder("Username")
+	e.password = widget.NewPasswordEntry()
+	e.password.SetPlaceHolder("Password")
+	e.command = widget.NewEntry()
+	e.command.SetPlaceHolder("Command printing a token, e.g. gcloud auth print-identity-token")
+	e.ttl = widget.NewEntry()
+	e.ttl.SetPlaceHolder(tokencmd.DefaultTTL.String())
+	e.ttl.Validator = validateTTL
+	for _, entry := range []*widget.Entry{e.token, e.username, e.password, e.command, e.ttl} {
+		entry.OnChanged = func(string) { e.changed() }
+	}
+
+	e.bearerFields = container.NewStack(e.token)
+	e.basicFields = container.NewGridWithColumns(2, e.username, e.password)
+	e.commandFields = container.NewBorder(nil, nil, nil,
+		container.NewHBox(widget.NewLabel("Reuse for"), e.ttl), e.command)
+	e.content = container.NewBorder(nil, nil, e.typeSelect, nil,
+		container.NewStack(e.bearerFields, e.basicFields, e.commandFields))
+
+	e.setting = true
+	e.typeSelect.SetSelected(domain.AuthNone.String())
+	e.setting = false
+
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// SetOnChanged sets the callback for edits to the auth.
+func (e *AuthEditor) SetOnChanged(fn func(domain.Auth)) {
+	e.onChanged = fn
+}
+
+// Auth returns the edited auth. Fields of other types are dropped.
+func (e *AuthEditor) Auth() domain.Auth {
+	var t domain.AuthType
+	for _, opt := range authOptions {
+		if opt.String() == e.typeSelect.Selected {
+			t = opt
+		}
+	}
+	switch t {
+	case domain.AuthBearer:
+		return domain.Auth{Type: t, Token: e.token.Text}
+	case domain.AuthBasic:
+		return domain.Auth{Type: t, Username: e.username.Text, Password: e.password.Text}
+	case domain.AuthCommand:
+		ttl, _ := time.ParseDuration(strings.TrimSpace(e.ttl.Text))
+		return domain.Auth{Type: t, Command: strings.TrimSpace(e.command.Text), TTL: max(ttl, 0)}
+	default:
+		return domain.Auth{}
+	}
+}
+
+// SetAuth fills the editor without calling the change callback.
+func (e *AuthEditor) SetAuth(a domain.Auth) {
+	e.setting = true
+	defer func() { e.setting = false }()
+	e.token.SetText(a.Token)
+	e.username.SetText(a.Username)
+	e.password.SetText(a.Password)
+	e.command.SetText(a.Command)
+	e.ttl.SetText("")
+	if a.TTL > 0 {
+		e.ttl.SetText(a.TTL.String())
+	}
+	e.typeSelect.SetSelected(a.Type.String())
+	e.updateFields()
+}
+
+// updateFields shows the fields for the selected type.
+func (e *AuthEditor) updateFields() {
+	e.bearerFields.Hide()
+	e.basicFields.Hide()
+	e.commandFields.Hide()
+	switch e.typeSelect.Selected {
+	case domain.AuthBearer.String():
+		e.bearerFields.Show()
+	case domain.AuthBasic.String():
+		e.basicFields.Show()
+	case domain.AuthCommand.String():
+		e.commandFields.Show()
+	}
+}
+
+func (e *AuthEditor) changed() {
+	if !e.setting && e.onChanged != nil {
+		e.onChanged(e.Auth())
+	}
+}
+
+// CreateRenderer implements fyne.Widget.
+func (e *AuthEditor) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(e.content)
+}
+
+// validateTTL accepts an empty field or a positive duration such as 15m.
+func validateTTL(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(s); err != nil || d <= 0 {
+		return errors.New("enter a duration such as 15m")
+	}
+	return nil
+}
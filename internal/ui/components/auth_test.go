@@ -0,0 +1,49 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthEditor(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	e := NewAuthEditor()
+	var changes []domain.Auth
+	e.SetOnChanged(func(a domain.Auth) { changes = append(changes, a) })
+
+	assert.Equal(t, domain.Auth{}, e.Auth())
+	assert.True(t, e.bearerFields.Hidden)
+	assert.True(t, e.basicFields.Hidden)
+
+	// SetAuth fills the fields quietly
+	e.SetAuth(domain.Auth{Type: domain.AuthBearer, Token: "abc"})
+	assert.Empty(t, changes)
+	assert.False(t, e.bearerFields.Hidden)
+	assert.True(t, e.token.Password, "token is masked")
+
+	// Switching type shows its fields and drops the other type's
+	e.typeSelect.SetSelected(domain.AuthBasic.String())
+	assert.True(t, e.bearerFields.Hidden)
+	assert.False(t, e.basicFields.Hidden)
+	e.username.SetText("u")
+	e.password.SetText("p")
+	assert.Equal(t, domain.Auth{Type: domain.AuthBasic, Username: "u", Password: "p"}, e.Auth())
+	assert.Equal(t, e.Auth(), changes[len(changes)-1])
+
+	// Token commands take an optional TTL
+	e.SetAuth(domain.Auth{Type: domain.AuthCommand, Command: "print-token", TTL: 15 * time.Minute})
+	assert.False(t, e.commandFields.Hidden)
+	assert.True(t, e.basicFields.Hidden)
+	assert.Equal(t, "15m0s", e.ttl.Text)
+	e.ttl.SetText("")
+	assert.Equal(t, domain.Auth{Type: domain.AuthCommand, Command: "print-token"}, e.Auth())
+	assert.Error(t, e.ttl.Validator("soon"))
+	assert.Error(t, e.ttl.Validator("-1m"))
+	assert.NoError(t, e.ttl.Validator("90s"))
+}
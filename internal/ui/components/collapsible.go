@@ -25,10 +25,11 @@ func NewCollapsibleSection(title string, content fyne.CanvasObject) *TreeSection
 }
 
 // NewCollapsibleSectionWithHint creates a collapsible section with a subdued type hint.
-func NewCollapsibleSectionWithHint(title, hint string, content fyne.CanvasObject) *TreeSection {
+// Any badges are shown after the hint.
+func NewCollapsibleSectionWithHint(title, hint string, content fyne.CanvasObject, badges ...fyne.CanvasObject) *TreeSection {
 	titleLabel := widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 	hintLabel := NewHintLabel(hint)
-	titleRow := container.NewHBox(titleLabel, hintLabel)
+	titleRow := container.NewHBox(append([]fyne.CanvasObject{titleLabel, hintLabel}, badges...)...)
 	return newTreeSection(titleRow, content, false)
 }
 
@@ -0,0 +1,86 @@
+package components
+
+import (
+	"image/color"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+)
+
+// EditorFont is the font of the JSON editors and views: the request
+// editor, the response, streamed messages and the bidi transcript.
+type EditorFont struct {
+	Monospace bool    // Use the bundled monospace font
+	Size      float32 // Text size in points; 0 keeps the theme's
+}
+
+var (
+	editorFontMu sync.RWMutex
+	editorFont   EditorFont
+)
+
+// SetEditorFont sets the font of the editors. Views already shown pick it
+// up when next refreshed, e.g. by setting the app theme again.
+func SetEditorFont(f EditorFont) {
+	editorFontMu.Lock()
+	defer editorFontMu.Unlock()
+	editorFont = f
+}
+
+// CurrentEditorFont returns the font set by SetEditorFont.
+func CurrentEditorFont() EditorFont {
+	editorFontMu.RLock()
+	defer editorFontMu.RUnlock()
+	return editorFont
+}
+
+// EditorTheme is the theme of the editors: the app's current theme, with
+// the text font and size of CurrentEditorFont.
+type EditorTheme struct{}
+
+var _ fyne.Theme = (*EditorTheme)(nil)
+
+func (t *EditorTheme) base() fyne.Theme {
+	if a := fyne.CurrentApp(); a != nil {
+		return a.Settings().Theme()
+	}
+	return theme.DefaultTheme()
+}
+
+// Color returns the base theme's color.
+func (t *EditorTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	return t.base().Color(name, variant)
+}
+
+// Font returns the monospace font for all text when the editor font is
+// monospace, and the base theme's font otherwise.
+func (t *EditorTheme) Font(style fyne.TextStyle) fyne.Resource {
+	if CurrentEditorFont().Monospace && !style.Symbol {
+		style.Monospace = true
+	}
+	return t.base().Font(style)
+}
+
+// Icon returns the base theme's icon.
+func (t *EditorTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return t.base().Icon(name)
+}
+
+// Size returns the editor font's size for text, and the base theme's size
+// for everything else.
+func (t *EditorTheme) Size(name fyne.ThemeSizeName) float32 {
+	if name == theme.SizeNameText {
+		if size := CurrentEditorFont().Size; size > 0 {
+			return size
+		}
+	}
+	return t.base().Size(name)
+}
+
+// EditorThemed shows obj in the editor theme. Objects placed in obj after
+// it is shown need the returned override refreshed to be themed.
+func EditorThemed(obj fyne.CanvasObject) *container.ThemeOverride {
+	return container.NewThemeOverride(obj, &EditorTheme{})
+}
@@ -0,0 +1,58 @@
+package components
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditorTheme(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	t.Cleanup(func() { SetEditorFont(EditorFont{}) })
+	app.Settings().SetTheme(theme.DefaultTheme())
+	base := app.Settings().Theme()
+	th := &EditorTheme{}
+
+	// By default the editors look like the rest of the app
+	SetEditorFont(EditorFont{})
+	assert.Equal(t, base.Font(fyne.TextStyle{}), th.Font(fyne.TextStyle{}))
+	assert.Equal(t, base.Font(fyne.TextStyle{Bold: true}), th.Font(fyne.TextStyle{Bold: true}))
+	assert.Equal(t, base.Size(theme.SizeNameText), th.Size(theme.SizeNameText))
+
+	SetEditorFont(EditorFont{Monospace: true, Size: 17})
+	mono := base.Font(fyne.TextStyle{Monospace: true})
+	assert.Equal(t, mono, th.Font(fyne.TextStyle{}))
+	assert.Equal(t, base.Font(fyne.TextStyle{Monospace: true, Bold: true}), th.Font(fyne.TextStyle{Bold: true}))
+	assert.Equal(t, base.Font(fyne.TextStyle{Symbol: true}), th.Font(fyne.TextStyle{Symbol: true}), "symbols keep their font")
+	assert.Equal(t, float32(17), th.Size(theme.SizeNameText))
+	assert.Equal(t, base.Size(theme.SizeNamePadding), th.Size(theme.SizeNamePadding), "only the text size changes")
+	assert.Equal(t, base.Size(theme.SizeNameHeadingText), th.Size(theme.SizeNameHeadingText))
+
+	// Colors follow the app's theme, even once it changes
+	assert.Equal(t, base.Color(theme.ColorNameForeground, theme.VariantDark), th.Color(theme.ColorNameForeground, theme.VariantDark))
+	app.Settings().SetTheme(test.Theme())
+	assert.Equal(t, test.Theme().Color(theme.ColorNameBackground, theme.VariantLight), th.Color(theme.ColorNameBackground, theme.VariantLight))
+}
+
+func TestEditorThemed(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	t.Cleanup(func() { SetEditorFont(EditorFont{}) })
+
+	SetEditorFont(EditorFont{Size: 20})
+	entry := widget.NewMultiLineEntry()
+	plain := widget.NewMultiLineEntry()
+	w := test.NewWindow(container.NewVBox(EditorThemed(entry), plain))
+	defer w.Close()
+	entry.SetText("{}")
+	plain.SetText("{}")
+
+	// The themed entry's text is bigger than the one outside
+	assert.Greater(t, entry.MinSize().Height, plain.MinSize().Height)
+}
@@ -0,0 +1,199 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// jsonTokenType identifies the kind of JSON token for syntax coloring.
+type jsonTokenType int
+
+const (
+	jsonTokenKey jsonTokenType = iota
+	jsonTokenString
+	jsonTokenNumber
+	jsonTokenBool
+	jsonTokenNull
+	jsonTokenPunct
+	jsonTokenWhitespace
+)
+
+// maxHighlightTokens caps how many tokens are colored; text past the cap is
+// shown uncolored.
+const maxHighlightTokens = 50_000
+
+// jsonToken is a single lexed JSON token: input[start:end].
+type jsonToken struct {
+	typ        jsonTokenType
+	start, end int
+}
+
+// tokenColorName maps token types to Fyne theme color names, so highlighting
+// follows the light and dark theme variants.
+var tokenColorName = map[jsonTokenType]fyne.ThemeColorName{
+	jsonTokenKey:        theme.ColorNamePrimary,
+	jsonTokenString:     theme.ColorNameSuccess,
+	jsonTokenNumber:     theme.ColorNameWarning,
+	jsonTokenBool:       theme.ColorNameError,
+	jsonTokenNull:       theme.ColorNameDisabled,
+	jsonTokenPunct:      theme.ColorNameForeground,
+	jsonTokenWhitespace: theme.ColorNameForeground,
+}
+
+// HighlightJSON converts a JSON string into colored RichText segments: keys,
+// strings, numbers, booleans and null each get a theme color. Adjacent
+// tokens of the same color share a segment. Invalid JSON is colored as far
+// as it lexes and never dropped.
+func HighlightJSON(input string) []widget.RichTextSegment {
+	if input == "" {
+		return nil
+	}
+
+	tokens, rest := tokenizeJSON(input, maxHighlightTokens)
+	segments := make([]widget.RichTextSegment, 0, len(tokens)/2+2)
+
+	for i := 0; i < len(tokens); {
+		colorName := tokenColorName[tokens[i].typ]
+		start, end := tokens[i].start, tokens[i].end
+		for i++; i < len(tokens) && tokenColorName[tokens[i].typ] == colorName; i++ {
+			end = tokens[i].end
+		}
+		segments = append(segments, jsonSegment(input[start:end], colorName))
+	}
+
+	if rest < len(input) {
+		segments = append(segments,
+			jsonSegment(input[rest:], theme.ColorNameForeground),
+			TruncationSegment("\n... (syntax highlighting limited - too many tokens) ..."),
+		)
+	}
+
+	return segments
+}
+
+// TruncationSegment creates a styled indicator for truncated content.
+func TruncationSegment(text string) *widget.TextSegment {
+	return &widget.TextSegment{
+		Style: widget.RichTextStyle{
+			ColorName: theme.ColorNameDisabled,
+			Inline:    true,
+			SizeName:  theme.SizeNameText,
+			TextStyle: fyne.TextStyle{Monospace: true, Italic: true},
+		},
+		Text: text,
+	}
+}
+
+// jsonSegment is a run of monospaced text in one color.
+func jsonSegment(text string, color fyne.ThemeColorName) *widget.TextSegment {
+	return &widget.TextSegment{
+		Style: widget.RichTextStyle{
+			ColorName: color,
+			Inline:    true,
+			SizeName:  theme.SizeNameText,
+			TextStyle: fyne.TextStyle{Monospace: true},
+		},
+		Text: text,
+	}
+}
+
+// tokenizeJSON breaks a JSON string into typed tokens in a single linear
+// pass. At most limit tokens are returned (limit <= 0 means no limit), along
+// with the offset where lexing stopped (len(input) when it finished).
+//
+// Strings may contain escaped quotes and \u escapes; an unterminated string
+// runs to the end of the input. A string followed by a colon is a key.
+func tokenizeJSON(input string, limit int) ([]jsonToken, int) {
+	tokens := make([]jsonToken, 0, min(len(input)/4+1, 4096))
+	lastString := -1 // index of a string token that may turn out to be a key
+	i := 0
+
+	for i < len(input) {
+		if limit > 0 && len(tokens) >= limit {
+			break
+		}
+		ch := input[i]
+		typ := jsonTokenPunct
+		j := i + 1
+
+		switch {
+		case ch == '"':
+			for j < len(input) {
+				c := input[j]
+				if c == '\\' {
+					j += 2
+					continue
+				}
+				j++
+				if c == '"' {
+					break
+				}
+			}
+			j = min(j, len(input))
+			typ = jsonTokenString
+
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			for j < len(input) && isNumberByte(input[j]) {
+				j++
+			}
+			typ = jsonTokenNumber
+
+		case hasWordAt(input, i, "true"):
+			j, typ = i+4, jsonTokenBool
+
+		case hasWordAt(input, i, "false"):
+			j, typ = i+5, jsonTokenBool
+
+		case hasWordAt(input, i, "null"):
+			j, typ = i+4, jsonTokenNull
+
+		case isSpaceByte(ch):
+			for j < len(input) && isSpaceByte(input[j]) {
+				j++
+			}
+			typ = jsonTokenWhitespace
+
+		case isStructuralByte(ch):
+			// Single punctuation character
+
+		default:
+			// Unexpected text: keep the run together as punctuation
+			for j < len(input) && !isStructuralByte(input[j]) && !isSpaceByte(input[j]) && input[j] != '"' {
+				j++
+			}
+		}
+
+		switch {
+		case typ == jsonTokenString:
+			lastString = len(tokens)
+		case typ == jsonTokenPunct && ch == ':' && lastString >= 0:
+			tokens[lastString].typ = jsonTokenKey
+			lastString = -1
+		case typ != jsonTokenWhitespace:
+			lastString = -1
+		}
+
+		tokens = append(tokens, jsonToken{typ: typ, start: i, end: j})
+		i = j
+	}
+
+	return tokens, i
+}
+
+// hasWordAt reports whether word appears in s at i.
+func hasWordAt(s string, i int, word string) bool {
+	return len(s)-i >= len(word) && s[i:i+len(word)] == word
+}
+
+func isNumberByte(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-'
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isStructuralByte(c byte) bool {
+	return c == '{' || c == '}' || c == '[' || c == ']' || c == ':' || c == ','
+}
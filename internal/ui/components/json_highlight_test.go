@@ -0,0 +1,240 @@
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lexed pairs a token's type with its text, for readable assertions.
+type lexed struct {
+	typ  jsonTokenType
+	text string
+}
+
+func lex(input string) []lexed {
+	tokens, rest := tokenizeJSON(input, 0)
+	if rest != len(input) {
+		panic("tokenizer stopped early")
+	}
+	out := make([]lexed, len(tokens))
+	for i, tok := range tokens {
+		out[i] = lexed{tok.typ, input[tok.start:tok.end]}
+	}
+	return out
+}
+
+func TestTokenizeJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []lexed
+	}{
+		{
+			name:  "object",
+			input: `{"a": 1, "b": [true, null, "x"]}`,
+			want: []lexed{
+				{jsonTokenPunct, "{"}, {jsonTokenKey, `"a"`}, {jsonTokenPunct, ":"}, {jsonTokenWhitespace, " "},
+				{jsonTokenNumber, "1"}, {jsonTokenPunct, ","}, {jsonTokenWhitespace, " "},
+				{jsonTokenKey, `"b"`}, {jsonTokenPunct, ":"}, {jsonTokenWhitespace, " "}, {jsonTokenPunct, "["},
+				{jsonTokenBool, "true"}, {jsonTokenPunct, ","}, {jsonTokenWhitespace, " "},
+				{jsonTokenNull, "null"}, {jsonTokenPunct, ","}, {jsonTokenWhitespace, " "},
+				{jsonTokenString, `"x"`}, {jsonTokenPunct, "]"}, {jsonTokenPunct, "}"},
+			},
+		},
+		{
+			name:  "escaped quotes",
+			input: `{"say \"hi\"": "a \"quoted\" word\\"}`,
+			want: []lexed{
+				{jsonTokenPunct, "{"}, {jsonTokenKey, `"say \"hi\""`}, {jsonTokenPunct, ":"}, {jsonTokenWhitespace, " "},
+				{jsonTokenString, `"a \"quoted\" word\\"`}, {jsonTokenPunct, "}"},
+			},
+		},
+		{
+			name:  "unicode escapes and UTF-8",
+			input: `["é😀", "héllo 😀"]`,
+			want: []lexed{
+				{jsonTokenPunct, "["}, {jsonTokenString, `"é😀"`}, {jsonTokenPunct, ","}, {jsonTokenWhitespace, " "},
+				{jsonTokenString, `"héllo 😀"`}, {jsonTokenPunct, "]"},
+			},
+		},
+		{
+			name:  "key before colon across whitespace",
+			input: "{\"k\"\n  : -1.5e+3}",
+			want: []lexed{
+				{jsonTokenPunct, "{"}, {jsonTokenKey, `"k"`}, {jsonTokenWhitespace, "\n  "}, {jsonTokenPunct, ":"},
+				{jsonTokenWhitespace, " "}, {jsonTokenNumber, "-1.5e+3"}, {jsonTokenPunct, "}"},
+			},
+		},
+		{
+			name:  "string value is not a key",
+			input: `["a", "b"]`,
+			want: []lexed{
+				{jsonTokenPunct, "["}, {jsonTokenString, `"a"`}, {jsonTokenPunct, ","}, {jsonTokenWhitespace, " "},
+				{jsonTokenString, `"b"`}, {jsonTokenPunct, "]"},
+			},
+		},
+		{
+			name:  "unterminated string",
+			input: `{"a": "open`,
+			want: []lexed{
+				{jsonTokenPunct, "{"}, {jsonTokenKey, `"a"`}, {jsonTokenPunct, ":"}, {jsonTokenWhitespace, " "},
+				{jsonTokenString, `"open`},
+			},
+		},
+		{
+			name:  "trailing backslash",
+			input: `"abc\`,
+			want:  []lexed{{jsonTokenString, `"abc\`}},
+		},
+		{
+			name:  "invalid text kept together",
+			input: `{bad_word: tru}`,
+			want: []lexed{
+				{jsonTokenPunct, "{"}, {jsonTokenPunct, "bad_word"}, {jsonTokenPunct, ":"}, {jsonTokenWhitespace, " "},
+				{jsonTokenPunct, "tru"}, {jsonTokenPunct, "}"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lex(tt.input))
+		})
+	}
+}
+
+func TestTokenizeJSON_Limit(t *testing.T) {
+	input := `[1, 2, 3]`
+	tokens, rest := tokenizeJSON(input, 3)
+	require.Len(t, tokens, 3)
+	assert.Equal(t, "[1,", input[:rest])
+}
+
+// segmentText concatenates the text of RichText segments.
+func segmentText(segments []widget.RichTextSegment) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteString(s.(*widget.TextSegment).Text)
+	}
+	return b.String()
+}
+
+func TestHighlightJSON(t *testing.T) {
+	assert.Nil(t, HighlightJSON(""))
+
+	input := "{\n  \"name\": \"grotto\",\n  \"n\": 1\n}"
+	segments := HighlightJSON(input)
+	assert.Equal(t, input, segmentText(segments), "all text is kept")
+
+	colors := make(map[string]string)
+	for _, s := range segments {
+		ts := s.(*widget.TextSegment)
+		colors[ts.Text] = string(ts.Style.ColorName)
+		assert.True(t, ts.Style.TextStyle.Monospace)
+	}
+	assert.Equal(t, string(theme.ColorNamePrimary), colors[`"name"`])
+	assert.Equal(t, string(theme.ColorNameSuccess), colors[`"grotto"`])
+	assert.Equal(t, string(theme.ColorNameWarning), colors["1"])
+
+	// Punctuation and whitespace share the foreground color and a segment
+	assert.Equal(t, string(theme.ColorNameForeground), colors["{\n  "])
+}
+
+func TestHighlightJSON_TooManyTokensKeepsText(t *testing.T) {
+	input := "[" + strings.Repeat("1,", maxHighlightTokens) + "1]"
+	segments := HighlightJSON(input)
+
+	last := segments[len(segments)-1].(*widget.TextSegment)
+	assert.Contains(t, last.Text, "syntax highlighting limited")
+	assert.Equal(t, input, segmentText(segments[:len(segments)-1]), "text past the cap is shown uncolored")
+}
+
+func TestJSONView(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	v := NewJSONView()
+	v.SetText(`{"a": 1}`)
+	assert.Equal(t, `{"a": 1}`, v.Text())
+	assert.Equal(t, `{"a": 1}`, segmentText(v.rich.Segments))
+
+	tapped := false
+	v.OnTapped = func() { tapped = true }
+	test.Tap(v)
+	assert.True(t, tapped)
+
+	// Huge documents are cut with a note (the test theme has no
+	// monospace italic font to measure it with)
+	app.Settings().SetTheme(theme.DefaultTheme())
+	big := `"` + strings.Repeat("x", MaxJSONDisplayBytes) + `"`
+	v.TruncationNote = "(cut)"
+	v.SetText(big)
+	assert.Equal(t, big, v.Text())
+	last := v.rich.Segments[len(v.rich.Segments)-1].(*widget.TextSegment)
+	assert.Equal(t, "(cut)", last.Text)
+}
+
+// benchmarkDocument returns a JSON document of about size bytes, pretty
+// printed or on a single line.
+func benchmarkDocument(size int, pretty bool) string {
+	type item struct {
+		ID    int               `json:"id"`
+		Name  string            `json:"name"`
+		Tags  []string          `json:"tags"`
+		Price float64           `json:"price"`
+		OK    bool              `json:"ok"`
+		Extra map[string]string `json:"extra"`
+	}
+	var items []item
+	n := 0
+	for i := 0; n < size; i++ {
+		it := item{
+			ID:    i,
+			Name:  fmt.Sprintf("item \"%d\" é", i),
+			Tags:  []string{"a", "b\\c"},
+			Price: float64(i) * 1.25,
+			OK:    i%2 == 0,
+			Extra: map[string]string{"k": "v"},
+		}
+		items = append(items, it)
+		n += 110
+	}
+	var data []byte
+	if pretty {
+		data, _ = json.MarshalIndent(items, "", "  ")
+	} else {
+		data, _ = json.Marshal(items)
+	}
+	return string(data)
+}
+
+func BenchmarkTokenizeJSON_1MB(b *testing.B) {
+	for _, pretty := range []bool{false, true} {
+		doc := benchmarkDocument(1<<20, pretty)
+		name := "single-line"
+		if pretty {
+			name = "pretty"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				tokenizeJSON(doc, 0)
+			}
+		})
+	}
+}
+
+func BenchmarkHighlightJSON_1MB(b *testing.B) {
+	doc := benchmarkDocument(1<<20, true)
+	b.SetBytes(int64(len(doc)))
+	for b.Loop() {
+		HighlightJSON(doc)
+	}
+}
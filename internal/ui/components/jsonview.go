@@ -0,0 +1,72 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Compile-time interface check.
+var _ fyne.Tappable = (*JSONView)(nil)
+
+// MaxJSONDisplayBytes caps how much text a JSONView renders, to prevent
+// segment explosion on huge documents.
+const MaxJSONDisplayBytes = 1_000_000
+
+// JSONView is a read-only, syntax-highlighted JSON display that scrolls in
+// both directions. Colors are theme color names, so the view follows the
+// light and dark variants.
+type JSONView struct {
+	widget.BaseWidget
+
+	// OnTapped is called when the view is tapped, if set.
+	OnTapped func()
+
+	// TruncationNote is shown after text cut at MaxJSONDisplayBytes.
+	TruncationNote string
+
+	text string
+	rich *widget.RichText
+}
+
+// NewJSONView creates an empty JSON view.
+func NewJSONView() *JSONView {
+	v := &JSONView{
+		TruncationNote: "\n\n... (too large to display in full) ...",
+		rich:           widget.NewRichText(),
+	}
+	v.rich.Wrapping = fyne.TextWrapBreak
+	v.rich.Scroll = fyne.ScrollBoth
+	v.ExtendBaseWidget(v)
+	return v
+}
+
+// SetText highlights and displays text, cut at MaxJSONDisplayBytes.
+func (v *JSONView) SetText(text string) {
+	v.text = text
+	display := text
+	if len(display) > MaxJSONDisplayBytes {
+		display = display[:MaxJSONDisplayBytes]
+	}
+	v.rich.Segments = HighlightJSON(display)
+	if len(text) > MaxJSONDisplayBytes {
+		v.rich.Segments = append(v.rich.Segments, TruncationSegment(v.TruncationNote))
+	}
+	v.rich.Refresh()
+}
+
+// Text returns the full text last passed to SetText.
+func (v *JSONView) Text() string {
+	return v.text
+}
+
+// Tapped calls OnTapped.
+func (v *JSONView) Tapped(*fyne.PointEvent) {
+	if v.OnTapped != nil {
+		v.OnTapped()
+	}
+}
+
+// CreateRenderer creates the renderer for this widget.
+func (v *JSONView) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(v.rich)
+}
@@ -0,0 +1,26 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"github.com/shhac/grotto/internal/export"
+)
+
+// ShowNDJSONExport asks for a file and writes msgs to it as newline-delimited
+// JSON. msgs should be a snapshot: messages arriving while the dialog is open
+// are not included.
+func ShowNDJSONExport(window fyne.Window, fileName string, msgs []export.StreamMessage) {
+	d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err := export.WriteNDJSON(writer, msgs); err != nil {
+			dialog.ShowError(err, window)
+		}
+	}, window)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".ndjson", ".jsonl"}))
+	d.SetFileName(fileName)
+	d.Show()
+}
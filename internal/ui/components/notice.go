@@ -0,0 +1,65 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Notice is a bar announcing something the user may act on, such as a
+// server schema change, without interrupting what they are doing. It stays
+// hidden until Announce is called and hides again when acted on or
+// dismissed.
+type Notice struct {
+	widget.BaseWidget
+
+	label     *widget.Label
+	actionBtn *widget.Button
+	onAction  func()
+}
+
+// NewNotice creates a hidden notice bar.
+func NewNotice() *Notice {
+	n := &Notice{label: widget.NewLabel("")}
+	n.label.Truncation = fyne.TextTruncateEllipsis
+	n.actionBtn = widget.NewButton("", func() {
+		n.Hide()
+		if n.onAction != nil {
+			n.onAction()
+		}
+	})
+	n.actionBtn.Importance = widget.HighImportance
+	n.ExtendBaseWidget(n)
+	n.Hide()
+	return n
+}
+
+// Announce shows message with a button labelled action that calls
+// onAction, replacing whatever the notice showed before.
+func (n *Notice) Announce(message, action string, onAction func()) {
+	n.label.SetText(message)
+	n.actionBtn.SetText(action)
+	n.onAction = onAction
+	n.Show()
+}
+
+// Message returns the text the notice is showing, or "" when hidden.
+func (n *Notice) Message() string {
+	if !n.Visible() {
+		return ""
+	}
+	return n.label.Text
+}
+
+// CreateRenderer implements fyne.Widget.
+func (n *Notice) CreateRenderer() fyne.WidgetRenderer {
+	dismissBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), n.Hide)
+	dismissBtn.Importance = widget.LowImportance
+	bar := container.NewBorder(nil, nil,
+		widget.NewIcon(theme.InfoIcon()),
+		container.NewHBox(n.actionBtn, dismissBtn),
+		n.label,
+	)
+	return widget.NewSimpleRenderer(bar)
+}
@@ -0,0 +1,34 @@
+package components
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotice(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	n := NewNotice()
+	w := test.NewWindow(n)
+	defer w.Close()
+	assert.False(t, n.Visible(), "hidden until announced")
+	assert.Empty(t, n.Message())
+
+	calls := 0
+	n.Announce("Schema changed: 1 method updated", "Refresh", func() { calls++ })
+	assert.True(t, n.Visible())
+	assert.Equal(t, "Schema changed: 1 method updated", n.Message())
+	assert.Equal(t, "Refresh", n.actionBtn.Text)
+
+	test.Tap(n.actionBtn)
+	assert.Equal(t, 1, calls)
+	assert.False(t, n.Visible(), "acting on the notice hides it")
+
+	n.Announce("again", "Refresh", func() { calls++ })
+	n.Hide()
+	assert.Empty(t, n.Message())
+	assert.Equal(t, 1, calls)
+}
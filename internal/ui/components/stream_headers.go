@@ -0,0 +1,73 @@
+package components
+
+import (
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// StreamHeaders shows the response headers of a stream as soon as they
+// arrive, which for a long-lived stream may be well before its first
+// message. It stays hidden until headers are set.
+type StreamHeaders struct {
+	widget.BaseWidget
+
+	label   *widget.Label
+	content *fyne.Container
+}
+
+// NewStreamHeaders creates a hidden headers section.
+func NewStreamHeaders() *StreamHeaders {
+	h := &StreamHeaders{label: widget.NewLabel("")}
+	h.label.Importance = widget.LowImportance
+	h.label.Wrapping = fyne.TextWrapBreak
+	title := widget.NewLabelWithStyle("Headers", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	h.content = container.NewBorder(nil, nil, title, nil, h.label)
+	h.ExtendBaseWidget(h)
+	h.Hide()
+	return h
+}
+
+// Set shows md, one "key: value" line per key in key order. Empty headers
+// still show, so it is clear the server sent none.
+func (h *StreamHeaders) Set(md map[string]string) {
+	h.label.SetText(MetadataText(md))
+	h.Show()
+}
+
+// Reset hides the section for a new stream.
+func (h *StreamHeaders) Reset() {
+	h.label.SetText("")
+	h.Hide()
+}
+
+// Text returns the headers shown.
+func (h *StreamHeaders) Text() string {
+	return h.label.Text
+}
+
+// CreateRenderer implements fyne.Widget.
+func (h *StreamHeaders) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(h.content)
+}
+
+// MetadataText lists md one "key: value" line per key, in key order, or
+// "(none)" when it is empty.
+func MetadataText(md map[string]string) string {
+	if len(md) == 0 {
+		return "(none)"
+	}
+	keys := make([]string, 0, len(md))
+	for key := range md {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + ": " + md[key]
+	}
+	return strings.Join(lines, "\n")
+}
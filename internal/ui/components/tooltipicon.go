@@ -0,0 +1,69 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Compile-time interface check.
+var _ desktop.Hoverable = (*TooltipIcon)(nil)
+
+// TooltipIcon displays an icon that explains itself in a popup on hover.
+type TooltipIcon struct {
+	widget.BaseWidget
+
+	tooltip string
+	icon    *widget.Icon
+	popup   *widget.PopUp
+}
+
+// NewTooltipIcon creates an icon showing res that reveals tooltip on mouse
+// hover.
+func NewTooltipIcon(res fyne.Resource, tooltip string) *TooltipIcon {
+	t := &TooltipIcon{tooltip: tooltip, icon: widget.NewIcon(res)}
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// Tooltip returns the text shown on hover.
+func (t *TooltipIcon) Tooltip() string {
+	return t.tooltip
+}
+
+// SetTooltip changes the text shown on hover. An empty tooltip shows no
+// popup.
+func (t *TooltipIcon) SetTooltip(tooltip string) {
+	t.tooltip = tooltip
+}
+
+// SetResource changes the icon.
+func (t *TooltipIcon) SetResource(res fyne.Resource) {
+	t.icon.SetResource(res)
+}
+
+// MouseIn shows the tooltip popup below the icon.
+func (t *TooltipIcon) MouseIn(_ *desktop.MouseEvent) {
+	c := fyne.CurrentApp().Driver().CanvasForObject(t)
+	if c == nil || t.tooltip == "" {
+		return
+	}
+	t.popup = widget.NewPopUp(widget.NewLabel(t.tooltip), c)
+	t.popup.ShowAtRelativePosition(fyne.NewPos(0, t.Size().Height), t)
+}
+
+// MouseMoved is required by desktop.Hoverable but needs no action.
+func (t *TooltipIcon) MouseMoved(_ *desktop.MouseEvent) {}
+
+// MouseOut hides and discards the tooltip popup.
+func (t *TooltipIcon) MouseOut() {
+	if t.popup != nil {
+		t.popup.Hide()
+		t.popup = nil
+	}
+}
+
+// CreateRenderer implements fyne.Widget.
+func (t *TooltipIcon) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.icon)
+}
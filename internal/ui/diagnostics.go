@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"context"
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// showServiceDiagnostics resolves a failed service again, tracing which
+// files the server returned and what was done to them, and shows the
+// report for attaching to bug reports.
+func (w *MainWindow) showServiceDiagnostics(service domain.Service) {
+	refClient := w.app.ReflectionClient()
+	if refClient == nil {
+		dialog.ShowError(errors.New("not connected"), w.window)
+		return
+	}
+
+	running := dialog.NewCustomWithoutButtons("Diagnostics",
+		container.NewVBox(widget.NewLabel("Resolving "+service.FullName+"…"), widget.NewProgressBarInfinite()), w.window)
+	running.Show()
+	timeout := w.getRequestTimeout()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		report, err := refClient.Diagnose(ctx, service.FullName)
+		fyne.Do(func() {
+			running.Hide()
+			if err != nil {
+				dialog.ShowError(err, w.window)
+				return
+			}
+			w.showDiagnosticsReport(report)
+		})
+	}()
+}
+
+// showDiagnosticsReport shows report as text with a button to copy it.
+func (w *MainWindow) showDiagnosticsReport(report grpc.ResolutionReport) {
+	text := report.String()
+	label := widget.NewLabel(text)
+	label.TextStyle.Monospace = true
+	label.Selectable = true
+	copyBtn := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		w.window.Clipboard().SetContent(text)
+	})
+
+	d := dialog.NewCustom("Diagnostics: "+report.Service, "Close",
+		container.NewBorder(nil, container.NewHBox(copyBtn), nil, nil, container.NewScroll(label)), w.window)
+	d.Resize(fyne.NewSize(700, 500))
+	d.Show()
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	uierrors "github.com/shhac/grotto/internal/ui/errors"
+)
+
+// maxDroppedBodySize caps the JSON file a drop loads as the request body.
+const maxDroppedBodySize = 4 << 20
+
+// dropKind is what a file dropped on the window is used for.
+type dropKind int
+
+const (
+	dropRequestBody   dropKind = iota // a .json file, loaded as the body
+	dropDescriptorSet                 // a binary FileDescriptorSet
+)
+
+// descriptorSetExts are the extensions a dropped descriptor set may have.
+var descriptorSetExts = []string{".binpb", ".pb", ".protoset", ".desc"}
+
+// classifyDrop decides what the dropped files are for, by extension. Only
+// a single file is accepted.
+func classifyDrop(paths []string) (dropKind, error) {
+	if len(paths) != 1 {
+		return 0, errors.New("drop one file at a time")
+	}
+	ext := strings.ToLower(filepath.Ext(paths[0]))
+	switch {
+	case ext == ".json":
+		return dropRequestBody, nil
+	case slices.Contains(descriptorSetExts, ext):
+		return dropDescriptorSet, nil
+	}
+	return 0, fmt.Errorf("%s is neither a JSON request body nor a descriptor set (%s)",
+		filepath.Base(paths[0]), strings.Join(descriptorSetExts, ", "))
+}
+
+// readDroppedBody reads the JSON file at path, refusing files over limit
+// bytes and ones that are not valid JSON.
+func readDroppedBody(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// Read one byte past the limit to tell a file at the limit from one over it
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > limit {
+		return "", fmt.Errorf("%s is larger than %s", filepath.Base(path), formatByteSize(int(limit)))
+	}
+	if !json.Valid(data) {
+		return "", fmt.Errorf("%s is not valid JSON", filepath.Base(path))
+	}
+	return string(data), nil
+}
+
+// handleDrop loads a file dropped on the window: a .json file becomes the
+// request body, and a descriptor set is offered as the schema source.
+func (w *MainWindow) handleDrop(_ fyne.Position, uris []fyne.URI) {
+	var paths []string
+	for _, u := range uris {
+		if u.Scheme() == "file" {
+			paths = append(paths, u.Path())
+		}
+	}
+	kind, err := classifyDrop(paths)
+	if err != nil {
+		dialog.ShowError(err, w.window)
+		return
+	}
+
+	switch kind {
+	case dropRequestBody:
+		w.loadDroppedBody(paths[0])
+	case dropDescriptorSet:
+		w.offerDroppedDescriptorSet(paths[0])
+	}
+}
+
+// loadDroppedBody replaces the request body with the JSON file at path,
+// offering to restore the previous body in a toast.
+func (w *MainWindow) loadDroppedBody(path string) {
+	if w.inBidiMode || !w.requestPanel.BodyTabShowing() {
+		dialog.ShowError(errors.New("drop JSON files onto the Request Body tab of a unary or server-streaming method"), w.window)
+		return
+	}
+	body, err := readDroppedBody(path, maxDroppedBodySize)
+	if err != nil {
+		dialog.ShowError(err, w.window)
+		return
+	}
+
+	prevBody, _ := w.state.Request.TextData.Get()
+	prevType, _ := w.state.Request.InputType.Get()
+	_ = w.state.Request.TextData.Set(body)
+	w.requestPanel.SyncTextToForm()
+	w.logger.Info("loaded request body from dropped file", slog.String("file", path))
+
+	w.toasts.Push(uierrors.Toast{
+		Title:   "Request Body Loaded",
+		Message: filepath.Base(path),
+		Notice:  true,
+		Action:  "Restore Previous",
+		OnAction: func() {
+			_ = w.state.Request.TextData.Set(prevBody)
+			w.requestPanel.SyncTextToForm()
+			_ = w.state.Request.InputType.Set(prevType)
+		},
+	})
+}
+
+// offerDroppedDescriptorSet asks whether to load the schema from the
+// descriptor set at path instead of the current source, reloading the
+// services when connected.
+func (w *MainWindow) offerDroppedDescriptorSet(path string) {
+	current := "server reflection"
+	if set := w.connectionBar.GetDescriptorSet(); set != "" {
+		current = filepath.Base(set)
+	} else if len(w.connectionBar.GetProtoImportPaths()) > 0 {
+		current = "the .proto sources"
+	}
+	msg := fmt.Sprintf("Load services from the descriptor set %s instead of %s?", filepath.Base(path), current)
+	dialog.ShowConfirm("Load Descriptor Set", msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		w.connectionBar.SetDescriptorSet(path)
+		if connected, _ := w.state.Connected.Get(); connected {
+			w.handleRefreshSchema()
+		}
+	}, w.window)
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyDrop(t *testing.T) {
+	tests := []struct {
+		paths   []string
+		want    dropKind
+		wantErr string
+	}{
+		{paths: []string{"/tmp/req.json"}, want: dropRequestBody},
+		{paths: []string{"/tmp/REQ.JSON"}, want: dropRequestBody},
+		{paths: []string{"/tmp/api.binpb"}, want: dropDescriptorSet},
+		{paths: []string{"/tmp/api.pb"}, want: dropDescriptorSet},
+		{paths: []string{"/tmp/api.protoset"}, want: dropDescriptorSet},
+		{paths: []string{"/tmp/api.proto"}, wantErr: "api.proto is neither a JSON request body nor a descriptor set"},
+		{paths: []string{"/tmp/notes"}, wantErr: "notes is neither"},
+		{paths: []string{"/tmp/a.json", "/tmp/b.json"}, wantErr: "drop one file at a time"},
+		{paths: nil, wantErr: "drop one file at a time"},
+	}
+	for _, tt := range tests {
+		got, err := classifyDrop(tt.paths)
+		if tt.wantErr != "" {
+			assert.ErrorContains(t, err, tt.wantErr, tt.paths)
+			continue
+		}
+		require.NoError(t, err, tt.paths)
+		assert.Equal(t, tt.want, got, tt.paths)
+	}
+}
+
+func TestReadDroppedBody(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	body, err := readDroppedBody(write("ok.json", `{"id": "1"}`), 64)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": "1"}`, body)
+
+	// Exactly at the limit is fine, one byte over is not
+	_, err = readDroppedBody(write("limit.json", `"12345678"`), 10)
+	assert.NoError(t, err)
+	_, err = readDroppedBody(write("over.json", `"123456789"`), 10)
+	assert.EqualError(t, err, "over.json is larger than 10 B")
+
+	_, err = readDroppedBody(write("bad.json", `{"id":`), 64)
+	assert.EqualError(t, err, "bad.json is not valid JSON")
+
+	_, err = readDroppedBody(filepath.Join(dir, "missing.json"), 64)
+	assert.Error(t, err)
+}
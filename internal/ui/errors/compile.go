@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// ShowProtoCompileErrors displays the errors found compiling .proto
+// sources, one per line as file:line:column. The onRetry function is called
+// when the user clicks Retry, after fixing the sources.
+func ShowProtoCompileErrors(problems []grpc.ProtoSourceError, window fyne.Window, onRetry func()) {
+	if len(problems) == 0 {
+		return
+	}
+
+	summary := "1 error compiling protos:"
+	if len(problems) > 1 {
+		summary = fmt.Sprintf("%d errors compiling protos:", len(problems))
+	}
+	content := container.NewVBox(widget.NewLabel(summary))
+	for _, p := range problems {
+		lbl := widget.NewLabelWithStyle(p.String(), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		lbl.Wrapping = fyne.TextWrapWord
+		content.Add(lbl)
+	}
+
+	d := dialog.NewCustomConfirm(
+		"Proto Compile Errors",
+		"Retry",
+		"Close",
+		container.NewVScroll(content),
+		func(retry bool) {
+			if retry && onRetry != nil {
+				onRetry()
+			}
+		},
+		window,
+	)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
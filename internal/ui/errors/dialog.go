@@ -1,12 +1,16 @@
 package errors
 
 import (
+	"errors"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
 	apperrors "github.com/shhac/grotto/internal/errors"
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 // ShowError displays a simple error dialog with the error message.
@@ -27,7 +31,7 @@ func ShowGRPCError(err error, window fyne.Window, onRetry func()) {
 	}
 
 	// Classify the error to get UI-friendly metadata
-	uiErr := apperrors.ClassifyGRPCError(err)
+	uiErr := classifyCallError(err)
 	if uiErr == nil {
 		// Fall back to simple error dialog
 		dialog.ShowError(err, window)
@@ -96,3 +100,37 @@ func ShowGRPCError(err error, window fyne.Window, onRetry func()) {
 		d.Show()
 	}
 }
+
+// classifyCallError classifies err for display. A failed call from the
+// invoker is classified by its phase and code; any other error by the
+// status it may carry.
+func classifyCallError(err error) *apperrors.UIError {
+	var invErr *grpc.InvocationError
+	if !errors.As(err, &invErr) {
+		return apperrors.ClassifyGRPCError(err)
+	}
+
+	switch {
+	case invErr.Phase == grpc.PhaseMarshal && invErr.Code == codes.InvalidArgument:
+		// Never sent, and sending it again would fail the same way
+		return &apperrors.UIError{
+			Err:      err,
+			Severity: apperrors.SeverityError,
+			Title:    "Invalid Request",
+			Message:  "The request does not match the method's input type.",
+			Recovery: []string{"Check field values", "See details for specifics"},
+			Actions:  []apperrors.ErrorAction{{Label: "Edit Request"}},
+			Details:  invErr.Message,
+		}
+	case invErr.Phase == grpc.PhaseMarshal:
+		return &apperrors.UIError{
+			Err:      err,
+			Severity: apperrors.SeverityError,
+			Title:    "Unreadable Response",
+			Message:  "The server's response does not match the method's output type.",
+			Recovery: []string{"Refresh the schema", "Check that the server runs the version you expect"},
+			Details:  invErr.Message,
+		}
+	}
+	return apperrors.ClassifyStatus(err, invErr.GRPCStatus())
+}
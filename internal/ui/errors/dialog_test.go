@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"slices"
+	"testing"
+
+	apperrors "github.com/shhac/grotto/internal/errors"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func actionLabels(uiErr *apperrors.UIError) []string {
+	var labels []string
+	for _, a := range uiErr.Actions {
+		labels = append(labels, a.Label)
+	}
+	return labels
+}
+
+func TestClassifyCallError(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   *grpc.InvocationError
+		title string
+		retry bool
+	}{
+		{
+			name:  "request not encoded",
+			err:   &grpc.InvocationError{Phase: grpc.PhaseMarshal, Code: codes.InvalidArgument, Message: "invalid value for int32 field count"},
+			title: "Invalid Request",
+		},
+		{
+			name:  "response not decoded",
+			err:   &grpc.InvocationError{Phase: grpc.PhaseMarshal, Code: codes.Internal, Message: "cannot parse invalid wire-format data"},
+			title: "Unreadable Response",
+		},
+		{
+			name:  "rejected by the server",
+			err:   &grpc.InvocationError{Phase: grpc.PhaseRecv, Code: codes.InvalidArgument, Message: "id is required"},
+			title: "Invalid Request",
+		},
+		{
+			name:  "server unavailable",
+			err:   &grpc.InvocationError{Phase: grpc.PhaseStart, Code: codes.Unavailable, Message: "connection refused"},
+			title: "Cannot Connect to Server",
+			retry: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.err.Err = status.Error(tc.err.Code, tc.err.Message)
+			uiErr := classifyCallError(tc.err)
+			require.NotNil(t, uiErr)
+			assert.Equal(t, tc.title, uiErr.Title)
+			assert.Equal(t, tc.retry, slices.Contains(actionLabels(uiErr), "Retry"), "actions %v", actionLabels(uiErr))
+			assert.Contains(t, uiErr.Details, tc.err.Message)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/shhac/grotto/internal/protoconv"
+)
+
+// ShowRequestProblems displays the problems found while checking a request
+// before it is sent, one per line with its field path, listing fields the
+// message does not have separately. The onSendAnyway function is called if
+// the user chooses to send regardless. When onSendWithoutUnknown is set,
+// the user is also offered to send with the unknown fields dropped.
+func ShowRequestProblems(problems []protoconv.FieldError, window fyne.Window, onSendAnyway, onSendWithoutUnknown func()) {
+	if len(problems) == 0 {
+		return
+	}
+
+	var invalid, unknown []protoconv.FieldError
+	for _, p := range problems {
+		if p.Unknown {
+			unknown = append(unknown, p)
+		} else {
+			invalid = append(invalid, p)
+		}
+	}
+
+	content := container.NewVBox()
+	addProblems := func(summary string, problems []protoconv.FieldError) {
+		content.Add(widget.NewLabel(summary))
+		for _, p := range problems {
+			lbl := widget.NewLabel("• " + p.Error())
+			lbl.Wrapping = fyne.TextWrapWord
+			content.Add(lbl)
+		}
+	}
+	if len(invalid) > 0 {
+		summary := "1 problem found in the request:"
+		if len(invalid) > 1 {
+			summary = fmt.Sprintf("%d problems found in the request:", len(invalid))
+		}
+		addProblems(summary, invalid)
+	}
+	if len(unknown) > 0 {
+		if len(invalid) > 0 {
+			content.Add(widget.NewSeparator())
+		}
+		summary := "1 unknown field:"
+		if len(unknown) > 1 {
+			summary = fmt.Sprintf("%d unknown fields:", len(unknown))
+		}
+		addProblems(summary, unknown)
+	}
+
+	d := dialog.NewCustomWithoutButtons("Check Request", container.NewVScroll(content), window)
+	sendAnyway := widget.NewButton("Send Anyway", func() {
+		d.Hide()
+		if onSendAnyway != nil {
+			onSendAnyway()
+		}
+	})
+	buttons := []fyne.CanvasObject{widget.NewButton("Cancel", d.Hide), sendAnyway}
+	if onSendWithoutUnknown != nil {
+		ignore := widget.NewButton("Send Anyway (Ignore Unknown Fields)", func() {
+			d.Hide()
+			onSendWithoutUnknown()
+		})
+		ignore.Importance = widget.HighImportance
+		buttons = append(buttons, ignore)
+	} else {
+		sendAnyway.Importance = widget.HighImportance
+	}
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(500, 400))
+	d.Show()
+}
@@ -1,6 +1,10 @@
 package errors
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
@@ -16,12 +20,19 @@ import (
 //   - Connecting: view-refresh icon (circular arrows)
 //   - Connected: confirm icon (checkmark)
 //   - Error: error icon (X shape)
+//
+// While connected, the bar also follows the transport link: it shows uptime
+// while the link is ready and a warning while it is down or reconnecting.
 type StatusBar struct {
 	widget.BaseWidget
 
 	state       *model.ConnectionUIState
 	statusLabel *widget.Label
 	indicator   *widget.Icon
+
+	// Ticks the uptime display while the link is ready (protected by tickMu)
+	tickMu     sync.Mutex
+	tickerStop chan struct{}
 }
 
 // NewStatusBar creates a new status bar bound to the given connection state.
@@ -39,6 +50,8 @@ func NewStatusBar(state *model.ConnectionUIState) *StatusBar {
 	// Listen to state changes
 	state.State.AddListener(binding.NewDataListener(s.updateStatus))
 	state.Message.AddListener(binding.NewDataListener(s.updateStatus))
+	state.Link.AddListener(binding.NewDataListener(s.updateStatus))
+	state.LinkSince.AddListener(binding.NewDataListener(s.updateStatus))
 
 	// Set initial state
 	s.updateStatus()
@@ -69,10 +82,26 @@ func (s *StatusBar) updateStatus() {
 		}
 
 	case "connected":
-		s.indicator.SetResource(theme.ConfirmIcon())
 		if message == "" {
-			s.statusLabel.SetText("Connected")
-		} else {
+			message = "Connected"
+		}
+		link, _ := s.state.Link.Get()
+		since, _ := s.state.LinkSince.Get()
+		switch link {
+		case "transient-failure":
+			s.indicator.SetResource(theme.WarningIcon())
+			s.statusLabel.SetText("Connection lost: server unreachable")
+		case "connecting":
+			s.indicator.SetResource(theme.ViewRefreshIcon())
+			s.statusLabel.SetText("Connection lost: reconnecting...")
+		case "idle":
+			s.indicator.SetResource(theme.ConfirmIcon())
+			s.statusLabel.SetText(message + " (idle, reconnects on next request)")
+		case "ready":
+			s.indicator.SetResource(theme.ConfirmIcon())
+			s.statusLabel.SetText(message + " · up " + formatUptime(time.Since(since)))
+		default:
+			s.indicator.SetResource(theme.ConfirmIcon())
 			s.statusLabel.SetText(message)
 		}
 
@@ -90,6 +119,60 @@ func (s *StatusBar) updateStatus() {
 	}
 
 	s.statusLabel.Refresh()
+
+	link, _ := s.state.Link.Get()
+	s.setTicking(stateStr == "connected" && link == "ready")
+}
+
+// setTicking starts or stops the once-a-second refresh of the uptime display.
+func (s *StatusBar) setTicking(on bool) {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+
+	if !on {
+		if s.tickerStop != nil {
+			close(s.tickerStop)
+			s.tickerStop = nil
+		}
+		return
+	}
+	if s.tickerStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.tickerStop = stop
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fyne.Do(s.updateStatus)
+			}
+		}
+	}()
+}
+
+// formatUptime renders a duration at the coarsest useful precision, e.g.
+// "42s", "5m 3s", "2h 5m" or "3d 4h".
+func formatUptime(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Truncate(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
 }
 
 // CreateRenderer implements fyne.Widget.
@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatUptime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{-time.Second, "0s"},
+		{42*time.Second + 600*time.Millisecond, "42s"},
+		{5*time.Minute + 3*time.Second, "5m 3s"},
+		{2*time.Hour + 5*time.Minute + 59*time.Second, "2h 5m"},
+		{76 * time.Hour, "3d 4h"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatUptime(tt.d), tt.d.String())
+	}
+}
+
+func TestStatusBar_Link(t *testing.T) {
+	test.NewApp()
+	state := model.NewConnectionUIState()
+	bar := NewStatusBar(state)
+	defer bar.setTicking(false)
+
+	_ = state.State.Set("connected")
+	_ = state.Message.Set("Connected to localhost:50051")
+	_ = state.LinkSince.Set(time.Now().Add(-90 * time.Second))
+	_ = state.Link.Set("ready")
+	assert.Equal(t, "Connected to localhost:50051 · up 1m 30s", bar.statusLabel.Text)
+
+	_ = state.Link.Set("transient-failure")
+	assert.Equal(t, "Connection lost: server unreachable", bar.statusLabel.Text)
+
+	_ = state.Link.Set("connecting")
+	assert.Equal(t, "Connection lost: reconnecting...", bar.statusLabel.Text)
+
+	_ = state.Link.Set("idle")
+	assert.Equal(t, "Connected to localhost:50051 (idle, reconnects on next request)", bar.statusLabel.Text)
+
+	// Link state is ignored once disconnected.
+	_ = state.State.Set("disconnected")
+	_ = state.Message.Set("")
+	assert.Equal(t, "Disconnected", bar.statusLabel.Text)
+}
@@ -0,0 +1,354 @@
+package errors
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxVisibleToasts is how many toasts are shown at once; later ones
+	// wait for a slot.
+	maxVisibleToasts = 3
+
+	// toastLifetime is how long a toast stays up unless hovered.
+	toastLifetime = 6 * time.Second
+
+	// toastWidth is the fixed width of a toast card.
+	toastWidth = 340
+)
+
+// Toast is a short, non-blocking notification.
+type Toast struct {
+	Title     string
+	Message   string
+	OnDetails func() // Opens the full report; nil hides the Details button
+
+	// Action labels a button that runs OnAction and dismisses the toast,
+	// e.g. "Undo"; "" hides it
+	Action   string
+	OnAction func()
+
+	// Notice marks the toast as information rather than a failure
+	Notice bool
+}
+
+// toastEntry is a toast in the queue.
+type toastEntry struct {
+	id      int
+	toast   Toast
+	shownAt time.Time // When it became visible, or was last unpinned
+	pinned  bool      // Hovered: does not expire
+}
+
+// toastQueue decides which toasts are visible. Up to max are shown in the
+// order they arrived; each expires lifetime after it was shown unless it is
+// pinned, and the next waiting toast takes its slot.
+type toastQueue struct {
+	now      func() time.Time
+	max      int
+	lifetime time.Duration
+
+	nextID  int
+	visible []*toastEntry
+	waiting []*toastEntry
+}
+
+// newToastQueue creates a queue that reads the time from now.
+func newToastQueue(now func() time.Time) *toastQueue {
+	return &toastQueue{now: now, max: maxVisibleToasts, lifetime: toastLifetime}
+}
+
+// push queues t and returns its id.
+func (q *toastQueue) push(t Toast) int {
+	q.nextID++
+	q.waiting = append(q.waiting, &toastEntry{id: q.nextID, toast: t})
+	q.promote()
+	return q.nextID
+}
+
+// promote moves waiting toasts into free visible slots.
+func (q *toastQueue) promote() {
+	for len(q.visible) < q.max && len(q.waiting) > 0 {
+		e := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		e.shownAt = q.now()
+		q.visible = append(q.visible, e)
+	}
+}
+
+// dismiss removes a toast, reporting whether it was queued.
+func (q *toastQueue) dismiss(id int) bool {
+	for i, e := range q.visible {
+		if e.id == id {
+			q.visible = append(q.visible[:i], q.visible[i+1:]...)
+			q.promote()
+			return true
+		}
+	}
+	for i, e := range q.waiting {
+		if e.id == id {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// pin keeps a visible toast up while pinned. Unpinning gives it a full
+// lifetime again, so it does not vanish the moment the mouse leaves.
+func (q *toastQueue) pin(id int, pinned bool) {
+	for _, e := range q.visible {
+		if e.id == id && e.pinned != pinned {
+			e.pinned = pinned
+			if !pinned {
+				e.shownAt = q.now()
+			}
+		}
+	}
+}
+
+// expire removes visible toasts whose lifetime has passed, reporting
+// whether any were removed.
+func (q *toastQueue) expire() bool {
+	now := q.now()
+	kept := q.visible[:0]
+	for _, e := range q.visible {
+		if e.pinned || now.Sub(e.shownAt) < q.lifetime {
+			kept = append(kept, e)
+		}
+	}
+	changed := len(kept) != len(q.visible)
+	q.visible = kept
+	q.promote()
+	return changed
+}
+
+// empty reports whether no toasts are shown or waiting.
+func (q *toastQueue) empty() bool {
+	return len(q.visible) == 0 && len(q.waiting) == 0
+}
+
+// ToastStack shows toasts stacked in the bottom-right corner of the area it
+// covers. It is meant to be layered over the window content in a stack;
+// only the toasts themselves take input.
+type ToastStack struct {
+	widget.BaseWidget
+
+	queue *toastQueue
+	cards map[int]*toastCard
+	box   *fyne.Container
+
+	// Expires toasts while any are queued (protected by tickMu)
+	tickMu     sync.Mutex
+	tickerStop chan struct{}
+}
+
+// NewToastStack creates an empty toast stack.
+func NewToastStack() *ToastStack {
+	s := &ToastStack{
+		queue: newToastQueue(time.Now),
+		cards: make(map[int]*toastCard),
+		box:   container.NewVBox(),
+	}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+// Push queues a toast. Call it on the main thread.
+func (s *ToastStack) Push(t Toast) {
+	s.queue.push(t)
+	s.sync()
+}
+
+// dismiss removes a toast now.
+func (s *ToastStack) dismiss(id int) {
+	if s.queue.dismiss(id) {
+		s.sync()
+	}
+}
+
+// tick expires toasts whose time is up.
+func (s *ToastStack) tick() {
+	if s.queue.expire() {
+		s.sync()
+	}
+}
+
+// sync shows a card for each visible toast, newest at the bottom.
+func (s *ToastStack) sync() {
+	visible := make(map[int]bool, len(s.queue.visible))
+	objects := make([]fyne.CanvasObject, 0, len(s.queue.visible))
+	for _, e := range s.queue.visible {
+		visible[e.id] = true
+		card, ok := s.cards[e.id]
+		if !ok {
+			card = newToastCard(s, e.id, e.toast)
+			s.cards[e.id] = card
+		}
+		objects = append(objects, card)
+	}
+	for id := range s.cards {
+		if !visible[id] {
+			delete(s.cards, id)
+		}
+	}
+	s.box.Objects = objects
+	s.box.Refresh()
+	s.setTicking(!s.queue.empty())
+}
+
+// setTicking starts or stops the periodic expiry check.
+func (s *ToastStack) setTicking(on bool) {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+
+	if !on {
+		if s.tickerStop != nil {
+			close(s.tickerStop)
+			s.tickerStop = nil
+		}
+		return
+	}
+	if s.tickerStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.tickerStop = stop
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fyne.Do(s.tick)
+			}
+		}
+	}()
+}
+
+// CreateRenderer implements fyne.Widget.
+func (s *ToastStack) CreateRenderer() fyne.WidgetRenderer {
+	corner := container.NewHBox(layout.NewSpacer(), s.box)
+	return widget.NewSimpleRenderer(container.NewBorder(nil, container.NewPadded(corner), nil, nil))
+}
+
+// Compile-time interface check.
+var _ desktop.Hoverable = (*toastCard)(nil)
+
+// toastCard renders one toast and pins it while hovered.
+type toastCard struct {
+	widget.BaseWidget
+
+	stack   *ToastStack
+	id      int
+	content fyne.CanvasObject
+}
+
+func newToastCard(stack *ToastStack, id int, t Toast) *toastCard {
+	c := &toastCard{stack: stack, id: id}
+
+	title := widget.NewLabelWithStyle(t.Title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Truncation = fyne.TextTruncateEllipsis
+	message := widget.NewLabel(t.Message)
+	message.Truncation = fyne.TextTruncateEllipsis
+
+	icon, stroke := theme.ErrorIcon(), theme.ColorNameError
+	if t.Notice {
+		icon, stroke = theme.InfoIcon(), theme.ColorNamePrimary
+	}
+
+	closeBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), func() { stack.dismiss(id) })
+	closeBtn.Importance = widget.LowImportance
+	header := container.NewBorder(nil, nil, widget.NewIcon(icon), closeBtn, title)
+
+	body := container.NewVBox(header, message)
+	buttons := container.NewHBox(layout.NewSpacer())
+	if t.Action != "" && t.OnAction != nil {
+		action := widget.NewButton(t.Action, func() {
+			stack.dismiss(id)
+			t.OnAction()
+		})
+		action.Importance = widget.LowImportance
+		buttons.Add(action)
+	}
+	if t.OnDetails != nil {
+		details := widget.NewButton("Details", func() {
+			stack.dismiss(id)
+			t.OnDetails()
+		})
+		details.Importance = widget.LowImportance
+		buttons.Add(details)
+	}
+	if len(buttons.Objects) > 1 {
+		body.Add(buttons)
+	}
+
+	bg := canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground))
+	bg.StrokeColor = theme.Color(stroke)
+	bg.StrokeWidth = 1
+	bg.CornerRadius = theme.InputRadiusSize()
+	c.content = container.NewStack(bg, container.NewPadded(body))
+
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// MouseIn pins the toast so it does not expire while being read.
+func (c *toastCard) MouseIn(_ *desktop.MouseEvent) {
+	c.stack.queue.pin(c.id, true)
+}
+
+// MouseMoved is required by desktop.Hoverable but needs no action.
+func (c *toastCard) MouseMoved(_ *desktop.MouseEvent) {}
+
+// MouseOut unpins the toast, giving it a full lifetime again.
+func (c *toastCard) MouseOut() {
+	c.stack.queue.pin(c.id, false)
+}
+
+// MinSize keeps every toast the same width.
+func (c *toastCard) MinSize() fyne.Size {
+	return fyne.NewSize(toastWidth, c.BaseWidget.MinSize().Height)
+}
+
+// CreateRenderer implements fyne.Widget.
+func (c *toastCard) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.content)
+}
+
+// ShowGRPCToast reports a failed call as a toast naming its status code,
+// for errors that should not interrupt the session. Its Details button opens
+// the full ShowGRPCError dialog, including the Retry button when onRetry is
+// set.
+func ShowGRPCToast(err error, stack *ToastStack, window fyne.Window, onRetry func()) {
+	if err == nil {
+		return
+	}
+
+	title := "Request Failed"
+	if uiErr := classifyCallError(err); uiErr != nil && uiErr.Title != "" {
+		title = uiErr.Title
+	}
+	message := err.Error()
+	if st, ok := status.FromError(err); ok {
+		message = st.Code().String() + ": " + st.Message()
+	}
+
+	stack.Push(Toast{
+		Title:     title,
+		Message:   message,
+		OnDetails: func() { ShowGRPCError(err, window, onRetry) },
+	})
+}
@@ -0,0 +1,165 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClock is a settable time source.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// visibleTitles lists the titles of the visible toasts in order.
+func visibleTitles(q *toastQueue) []string {
+	var titles []string
+	for _, e := range q.visible {
+		titles = append(titles, e.toast.Title)
+	}
+	return titles
+}
+
+func TestToastQueue_MaxVisible(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	q := newToastQueue(clock.now)
+
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		q.push(Toast{Title: title})
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, visibleTitles(q))
+	assert.Len(t, q.waiting, 2)
+
+	// Dismissing one shows the next in line
+	require.True(t, q.dismiss(q.visible[1].id))
+	assert.Equal(t, []string{"a", "c", "d"}, visibleTitles(q))
+
+	// Waiting toasts can be dismissed before they show
+	require.True(t, q.dismiss(q.waiting[0].id))
+	assert.Empty(t, q.waiting)
+	assert.False(t, q.dismiss(999))
+}
+
+func TestToastQueue_Expiry(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	q := newToastQueue(clock.now)
+
+	q.push(Toast{Title: "a"})
+	clock.advance(2 * time.Second)
+	q.push(Toast{Title: "b"})
+	q.push(Toast{Title: "c"})
+	q.push(Toast{Title: "d"}) // waits for a slot
+
+	clock.advance(toastLifetime - 2*time.Second - time.Millisecond)
+	assert.False(t, q.expire(), "nothing is due yet")
+
+	clock.advance(time.Millisecond)
+	assert.True(t, q.expire())
+	assert.Equal(t, []string{"b", "c", "d"}, visibleTitles(q))
+
+	// The promoted toast gets its own full lifetime
+	clock.advance(2 * time.Second)
+	assert.True(t, q.expire())
+	assert.Equal(t, []string{"d"}, visibleTitles(q))
+
+	clock.advance(toastLifetime)
+	assert.True(t, q.expire())
+	assert.True(t, q.empty())
+}
+
+func TestToastQueue_PinWhileHovered(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	q := newToastQueue(clock.now)
+	id := q.push(Toast{Title: "a"})
+
+	q.pin(id, true)
+	clock.advance(time.Minute)
+	assert.False(t, q.expire(), "pinned toasts stay")
+
+	// Unpinning restarts the countdown
+	q.pin(id, false)
+	clock.advance(toastLifetime - time.Second)
+	assert.False(t, q.expire())
+	clock.advance(time.Second)
+	assert.True(t, q.expire())
+	assert.True(t, q.empty())
+}
+
+func TestToastStack(t *testing.T) {
+	test.NewApp()
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	s := NewToastStack()
+	s.queue.now = clock.now
+	w := test.NewWindow(s)
+	defer w.Close()
+
+	details := 0
+	s.Push(Toast{Title: "Failed", Message: "INVALID_ARGUMENT: bad id", OnDetails: func() { details++ }})
+	require.Len(t, s.box.Objects, 1)
+	card := s.box.Objects[0].(*toastCard)
+
+	card.MouseIn(nil)
+	clock.advance(time.Minute)
+	s.tick()
+	assert.Len(t, s.box.Objects, 1, "hovered toast is kept")
+	card.MouseOut()
+	clock.advance(toastLifetime)
+	s.tick()
+	assert.Empty(t, s.box.Objects)
+	assert.Empty(t, s.cards)
+
+	s.Push(Toast{Title: "Again", OnDetails: func() { details++ }})
+	s.queue.visible[0].toast.OnDetails()
+	assert.Equal(t, 1, details)
+	s.dismiss(s.queue.visible[0].id)
+	assert.Empty(t, s.box.Objects)
+	s.setTicking(false)
+}
+
+func TestToastStack_Action(t *testing.T) {
+	test.NewApp()
+	s := NewToastStack()
+	w := test.NewWindow(s)
+	defer w.Close()
+	defer s.setTicking(false)
+
+	undone := 0
+	s.Push(Toast{Title: "Loaded", Notice: true, Action: "Undo", OnAction: func() { undone++ }})
+	require.Len(t, s.box.Objects, 1)
+
+	var undo *widget.Button
+	for _, o := range test.LaidOutObjects(s.box.Objects[0]) {
+		if b, ok := o.(*widget.Button); ok && b.Text == "Undo" {
+			undo = b
+		}
+	}
+	require.NotNil(t, undo)
+	test.Tap(undo)
+	assert.Equal(t, 1, undone)
+	assert.Empty(t, s.box.Objects, "acting dismisses the toast")
+}
+
+func TestShowGRPCToast(t *testing.T) {
+	test.NewApp()
+	s := NewToastStack()
+	w := test.NewWindow(s)
+	defer w.Close()
+	defer s.setTicking(false)
+
+	ShowGRPCToast(status.Error(codes.InvalidArgument, "id must be set"), s, w, nil)
+	ShowGRPCToast(errors.New("stream closed"), s, w, nil)
+	ShowGRPCToast(nil, s, w, nil)
+
+	require.Len(t, s.queue.visible, 2)
+	assert.Equal(t, "InvalidArgument: id must be set", s.queue.visible[0].toast.Message)
+	assert.NotEmpty(t, s.queue.visible[0].toast.Title)
+	assert.Equal(t, "stream closed", s.queue.visible[1].toast.Message)
+}
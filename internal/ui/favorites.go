@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+)
+
+// loadFavorites shows the methods pinned on the current server under
+// Favorites in the service browser. Without a server they are cleared.
+func (w *MainWindow) loadFavorites() {
+	address, _ := w.state.CurrentServer.Get()
+	var favorites []string
+	if address != "" {
+		var err error
+		favorites, err = w.app.Storage().GetFavorites(address)
+		if err != nil {
+			w.logger.Error("failed to load favorites", slog.Any("error", err))
+			return
+		}
+	}
+
+	fyne.Do(func() {
+		w.serviceBrowser.SetFavorites(favorites)
+	})
+}
+
+// saveFavorites remembers the methods pinned on the current server. It
+// saves right away, so quick successive pins are written in order.
+func (w *MainWindow) saveFavorites(methods []string) {
+	address, _ := w.state.CurrentServer.Get()
+	if address == "" {
+		return
+	}
+	if err := w.app.Storage().SetFavorites(address, methods); err != nil {
+		w.logger.Error("failed to save favorites", slog.Any("error", err))
+	}
+}
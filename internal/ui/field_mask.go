@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// showFieldMaskBuilder lists the fields set in the last response, such as a
+// Get method's, and builds a FieldMask from the ones ticked. It is inserted
+// into the selected method's update_mask (or other FieldMask field) when
+// the request has one, and copied otherwise.
+func (w *MainWindow) showFieldMaskBuilder() {
+	held := w.lastResponse
+	if held == nil {
+		dialog.ShowInformation("Build Field Mask",
+			"Send a request first, e.g. to a Get method: the mask is built from the fields set in its response.", w.window)
+		return
+	}
+	paths, err := protoconv.MaskPaths(held.json, held.desc)
+	if err != nil {
+		dialog.ShowError(err, w.window)
+		return
+	}
+	if len(paths) == 0 {
+		dialog.ShowInformation("Build Field Mask",
+			fmt.Sprintf("The last %s response has no fields set.", held.desc.Name()), w.window)
+		return
+	}
+
+	target := w.fieldMaskTarget()
+	selected := make(map[string]bool)
+	maskLabel := widget.NewLabel("")
+	maskLabel.Wrapping = fyne.TextWrapBreak
+	mask := func() string {
+		var picked []string
+		for _, p := range paths {
+			if selected[p.Path] {
+				picked = append(picked, p.Path)
+			}
+		}
+		s, err := protoconv.FieldMaskJSON(picked)
+		if err != nil {
+			return ""
+		}
+		return s
+	}
+	updateMask := func() {
+		if s := mask(); s != "" {
+			maskLabel.SetText(s)
+		} else {
+			maskLabel.SetText("(tick the fields to update)")
+		}
+	}
+	updateMask()
+
+	checks := container.NewVBox()
+	for _, p := range paths {
+		name := p.Path[strings.LastIndex(p.Path, ".")+1:]
+		check := widget.NewCheck(strings.Repeat("    ", p.Depth)+name, func(on bool) {
+			selected[p.Path] = on
+			updateMask()
+		})
+		checks.Add(check)
+	}
+
+	intro := fmt.Sprintf("Fields set in the last %s response:", held.desc.Name())
+	confirm := "Copy"
+	if target != nil {
+		confirm = "Set " + target.JSONName()
+	}
+	content := container.NewBorder(
+		widget.NewLabel(intro),
+		container.NewVBox(widget.NewSeparator(), maskLabel),
+		nil, nil,
+		container.NewVScroll(checks),
+	)
+	d := dialog.NewCustomConfirm("Build Field Mask", confirm, "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		s := mask()
+		if target == nil {
+			w.window.Clipboard().SetContent(s)
+			return
+		}
+		body, _ := w.state.Request.TextData.Get()
+		updated, err := setMember(body, target, strconv.Quote(s))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("could not set %s: %w", target.JSONName(), err), w.window)
+			return
+		}
+		_ = w.state.Request.TextData.Set(prettyJSON(updated))
+		w.requestPanel.SyncTextToForm()
+	}, w.window)
+	d.Resize(fyne.NewSize(450, 500))
+	d.Show()
+}
+
+// fieldMaskTarget returns the FieldMask field of the selected method's
+// input, preferring update_mask, or nil when it has none.
+func (w *MainWindow) fieldMaskTarget() protoreflect.FieldDescriptor {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
+	refClient := w.app.ReflectionClient()
+	if serviceName == "" || methodName == "" || refClient == nil {
+		return nil
+	}
+	methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		w.logger.Error("failed to get method descriptor", slog.Any("error", err))
+		return nil
+	}
+	fields := protoconv.FieldMaskFields(methodDesc.Input())
+	for _, fd := range fields {
+		if fd.Name() == "update_mask" {
+			return fd
+		}
+	}
+	if len(fields) > 0 {
+		return fields[0]
+	}
+	return nil
+}
+
+// setMember sets the top-level member for fd in the JSON object body to
+// value, a JSON value, replacing it under either its JSON or proto name.
+// The rest of the body is kept as written; a new member goes last.
+func setMember(body string, fd protoreflect.FieldDescriptor, value string) (string, error) {
+	member := strconv.Quote(fd.JSONName()) + ": " + value
+	if strings.TrimSpace(body) == "" {
+		return "{" + member + "}", nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return "", errors.New("the request body is not a JSON object")
+	}
+	lastEnd := -1
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		keyEnd := int(dec.InputOffset())
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return "", err
+		}
+		end := int(dec.InputOffset())
+		if name, _ := tok.(string); name == fd.JSONName() || name == string(fd.Name()) {
+			// Replace the key too, so a proto name becomes the JSON name
+			keyStart := strings.LastIndex(body[:keyEnd], `"`+name+`"`)
+			if keyStart < 0 {
+				keyStart = keyEnd
+			}
+			return body[:keyStart] + member + body[end:], nil
+		}
+		lastEnd = end
+	}
+	if _, err := dec.Token(); err != nil {
+		return "", err
+	}
+	closing := int(dec.InputOffset()) - 1
+	if lastEnd < 0 {
+		return body[:closing] + member + body[closing:], nil
+	}
+	return body[:lastEnd] + ", " + member + body[lastEnd:], nil
+}
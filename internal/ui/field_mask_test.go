@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+func TestSetMember(t *testing.T) {
+	fd := (&pb.Item{}).ProtoReflect().Descriptor().Fields().ByName("created_at")
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"empty body", "", `{"createdAt": "x"}`, false},
+		{"empty object", "{ }", `{ "createdAt": "x"}`, false},
+		{"appended last", `{"id": "1", "count": 9007199254740993}`, `{"id": "1", "count": 9007199254740993, "createdAt": "x"}`, false},
+		{"replaced in place", `{"createdAt": "old", "id": "1"}`, `{"createdAt": "x", "id": "1"}`, false},
+		{"proto name replaced", `{"id": "1", "created_at": {"a": [1]}}`, `{"id": "1", "createdAt": "x"}`, false},
+		{"not an object", `[1]`, "", true},
+		{"invalid", `{"id": `, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setMember(tt.body, fd, `"x"`)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// showFindType lets the user look a message or enum up by name, such as
+// one an Any or a log line names by type URL, and opens its definition.
+// Types no service uses are found when their full name is typed.
+func (w *MainWindow) showFindType() {
+	refClient := w.app.ReflectionClient()
+	if refClient == nil {
+		dialog.ShowInformation("Find Type", "Connect to a server to find its types.", w.window)
+		return
+	}
+
+	var results []grpc.TypeInfo
+	status := widget.NewLabel("")
+	list := widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil, widget.NewLabel("kind"), widget.NewLabel("type"))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := obj.(*fyne.Container)
+			result := results[id]
+			row.Objects[0].(*widget.Label).SetText(result.FullName + "  —  " + result.File)
+			row.Objects[1].(*widget.Label).SetText(string(result.Kind))
+		},
+	)
+
+	var d dialog.Dialog
+	list.OnSelected = func(id widget.ListItemID) {
+		list.UnselectAll()
+		if id < len(results) {
+			d.Hide()
+			w.showTypeSchema(results[id])
+		}
+	}
+
+	// Each search runs off the UI thread; only the latest one is shown
+	var latest atomic.Uint64
+	search := func(query string) {
+		seq := latest.Add(1)
+		go func() {
+			found, err := refClient.SearchTypes(query)
+			if err != nil {
+				w.logger.Warn("type search could not fetch every file", slog.Any("error", err))
+			}
+			fyne.Do(func() {
+				if latest.Load() != seq {
+					return
+				}
+				results = found
+				list.Refresh()
+				list.ScrollToTop()
+				switch {
+				case err != nil:
+					status.SetText(fmt.Sprintf("%d types; some files could not be fetched", len(found)))
+				case len(found) == 1:
+					status.SetText("1 type")
+				default:
+					status.SetText(fmt.Sprintf("%d types", len(found)))
+				}
+			})
+		}()
+	}
+
+	query := widget.NewEntry()
+	query.SetPlaceHolder("Type name, full name or type URL")
+	query.OnChanged = search
+
+	d = dialog.NewCustom("Find Type", "Close", container.NewBorder(query, status, nil, nil, list), w.window)
+	d.Resize(fyne.NewSize(650, 450))
+	d.Show()
+	w.window.Canvas().Focus(query)
+	search("")
+}
+
+// showTypeSchema shows a message or enum's definition and the file
+// declaring it, with a button to copy the definition.
+func (w *MainWindow) showTypeSchema(t grpc.TypeInfo) {
+	schema, err := t.Schema()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("print %s: %w", t.FullName, err), w.window)
+		return
+	}
+	label := widget.NewLabel(schema)
+	label.TextStyle.Monospace = true
+	label.Selectable = true
+	copyBtn := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		w.window.Clipboard().SetContent(schema)
+	})
+	file := widget.NewLabel("Declared in " + t.File)
+
+	d := dialog.NewCustom(t.FullName, "Close",
+		container.NewBorder(file, container.NewHBox(copyBtn), nil, nil, container.NewScroll(label)), w.window)
+	d.Resize(fyne.NewSize(650, 500))
+	d.Show()
+}
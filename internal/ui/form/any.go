@@ -0,0 +1,215 @@
+package form
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// AnyWidget edits a google.protobuf.Any: a type-to-filter selector over the
+// message types the form's TypeResolver knows, and a nested form for the
+// payload of the chosen type. An Any loaded with a type that cannot be
+// resolved keeps its encoded payload as is until another type is chosen.
+type AnyWidget struct {
+	widget.BaseWidget
+
+	parent *FormBuilder
+
+	typeEntry  *widget.SelectEntry
+	options    []string // every selectable type name
+	note       *widget.Label
+	payloadBox *fyne.Container
+	content    *fyne.Container
+
+	payload     *lazyForm // nil unless a resolvable type is chosen
+	payloadName protoreflect.FullName
+	typeURL     string                 // URL the value was loaded with
+	raw         map[string]interface{} // unresolvable value, kept as loaded
+}
+
+// newAnyWidget creates an Any editor nested under parent.
+func newAnyWidget(parent *FormBuilder) *AnyWidget {
+	a := &AnyWidget{
+		parent:     parent,
+		note:       widget.NewLabel(""),
+		payloadBox: container.NewStack(),
+	}
+	a.note.Importance = widget.WarningImportance
+	a.note.Wrapping = fyne.TextWrapWord
+	a.note.Hide()
+
+	names := parent.types.MessageNames()
+	a.options = make([]string, len(names))
+	known := make(map[protoreflect.FullName]bool, len(names))
+	for i, name := range names {
+		a.options[i] = string(name)
+		known[name] = true
+	}
+	a.typeEntry = widget.NewSelectEntry(a.options)
+	a.typeEntry.Wrapping = fyne.TextWrapOff
+	a.typeEntry.Scroll = container.ScrollNone
+	a.typeEntry.SetPlaceHolder("Type to filter message types...")
+	a.typeEntry.OnChanged = func(text string) {
+		if text == "" {
+			a.typeEntry.SetOptions(a.options)
+		} else {
+			lower := strings.ToLower(text)
+			filtered := make([]string, 0)
+			for _, opt := range a.options {
+				if strings.Contains(strings.ToLower(opt), lower) {
+					filtered = append(filtered, opt)
+				}
+			}
+			a.typeEntry.SetOptions(filtered)
+		}
+		// Only listed names are resolved while typing, so partial names
+		// never reach the server
+		name := protoreflect.FullName(strings.TrimSpace(text))
+		a.selectType(name, known[name])
+	}
+	a.typeEntry.Validator = func(string) error { return a.Validate() }
+
+	a.content = container.NewVBox(a.typeEntry, a.note, a.payloadBox)
+	a.ExtendBaseWidget(a)
+	return a
+}
+
+// anyFieldWidget wraps an AnyWidget for a singular Any field.
+func (b *FormBuilder) anyFieldWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
+	a := newAnyWidget(b)
+	name := string(fd.Name())
+	return &FieldWidget{
+		Name:       name,
+		Label:      formatFieldLabel(name),
+		Widget:     a,
+		Descriptor: fd,
+		Deprecated: protoconv.IsFieldDeprecated(fd),
+		GetValue:   a.GetValue,
+		SetValue:   a.SetValue,
+		Validate:   a.Validate,
+	}
+}
+
+// CreateRenderer implements fyne.Widget.
+func (a *AnyWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(a.content)
+}
+
+// selectType shows the payload form for name, if resolve is set and it
+// resolves. Choosing a different type drops an unresolvable payload kept
+// from SetValue.
+func (a *AnyWidget) selectType(name protoreflect.FullName, resolve bool) {
+	if name == a.payloadName && (a.payload != nil || a.raw != nil) {
+		return
+	}
+	a.payload = nil
+	a.payloadName = name
+	a.raw = nil
+	a.note.Hide()
+	a.payloadBox.Objects = nil
+
+	if name != "" && resolve {
+		if mt, err := a.parent.types.FindMessageByName(name); err == nil {
+			a.payload = newLazyForm(a.parent, mt.Descriptor())
+			a.payloadBox.Objects = []fyne.CanvasObject{a.payload.content}
+		}
+	}
+	a.payloadBox.Refresh()
+}
+
+// GetValue returns the Any as a type_url and encoded value, or nil when no
+// type is chosen.
+func (a *AnyWidget) GetValue() interface{} {
+	if a.raw != nil {
+		return a.raw
+	}
+	if a.payload == nil {
+		return nil
+	}
+	msg := dynamicpb.NewMessage(a.payload.md)
+	if a.payload.builder != nil {
+		if err := a.payload.builder.populateMessage(msg, a.payload.values()); err != nil {
+			return nil
+		}
+	}
+	value, err := proto.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	url := protoconv.TypeURL(a.payloadName)
+	if strings.HasSuffix(a.typeURL, "/"+string(a.payloadName)) {
+		url = a.typeURL
+	}
+	return map[string]interface{}{"type_url": url, "value": value}
+}
+
+// SetValue loads an Any given as a map of type_url and value, decoding the
+// payload into the nested form when its type resolves. Anything else
+// clears the widget.
+func (a *AnyWidget) SetValue(v interface{}) {
+	m, _ := v.(map[string]interface{})
+	url, _ := m["type_url"].(string)
+	var value []byte
+	switch val := m["value"].(type) {
+	case []byte:
+		value = val
+	case string:
+		value, _, _ = DecodeBase64(val)
+	}
+
+	name := protoreflect.FullName(url)
+	if i := strings.LastIndexByte(url, '/'); i >= 0 {
+		name = protoreflect.FullName(url[i+1:])
+	}
+	a.typeURL = url
+	a.typeEntry.SetText(string(name))
+	a.selectType(name, true)
+	if url == "" {
+		return
+	}
+
+	if a.payload != nil {
+		msg := dynamicpb.NewMessage(a.payload.md)
+		if err := proto.Unmarshal(value, msg); err == nil {
+			values := make(map[string]interface{})
+			msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+				values[string(fd.Name())] = valueToInterface(fd, v)
+				return true
+			})
+			a.payload.clear()
+			a.payload.setValues(values)
+			return
+		}
+	}
+
+	// Not a type the form can edit: keep the payload for sending back
+	a.payload = nil
+	a.payloadBox.Objects = nil
+	a.payloadBox.Refresh()
+	a.raw = map[string]interface{}{"type_url": url, "value": value}
+	a.note.SetText(fmt.Sprintf("%s cannot be decoded; its %d-byte payload is sent unchanged.", name, len(value)))
+	a.note.Show()
+}
+
+// Validate reports a chosen type that cannot be resolved, and problems in
+// the payload form.
+func (a *AnyWidget) Validate() error {
+	name := strings.TrimSpace(a.typeEntry.Text)
+	if name == "" || a.raw != nil {
+		return nil
+	}
+	if a.payload == nil {
+		return fmt.Errorf("unknown message type: %s", name)
+	}
+	if errs := a.payload.fieldErrors(""); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
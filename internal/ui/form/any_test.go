@@ -0,0 +1,132 @@
+package form
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/shhac/grotto/internal/protoconv"
+)
+
+// anyTestFile declares a message with an Any field and a payload type
+// only the server knows:
+//
+//	message Envelope { google.protobuf.Any payload = 1; }
+//	message Widget { string name = 1; int32 size = 2; }
+func anyTestFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("anytest/envelope.proto"),
+		Package:    proto.String("anytest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/any.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Envelope"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name: proto.String("payload"), JsonName: proto.String("payload"), Number: proto.Int32(1), Label: opt,
+					Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Any"),
+				}},
+			},
+			{
+				Name: proto.String("Widget"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+					{Name: proto.String("size"), JsonName: proto.String("size"), Number: proto.Int32(2), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd
+}
+
+// anyTestBuilder builds the Envelope form with the file's types.
+func anyTestBuilder(t *testing.T) (*FormBuilder, *AnyWidget) {
+	t.Helper()
+	fd := anyTestFile(t)
+	b := NewFormBuilder(fd.Messages().ByName("Envelope"))
+	b.SetTypeResolver(protoconv.NewTypeResolver([]protoreflect.FileDescriptor{fd}, nil))
+	b.Build()
+	fw, ok := b.fields["payload"]
+	require.True(t, ok, "Any field should get a widget")
+	return b, fw.Widget.(*AnyWidget)
+}
+
+func TestAnyWidget_PickTypeAndFillPayload(t *testing.T) {
+	test.NewApp()
+	b, a := anyTestBuilder(t)
+
+	assert.Contains(t, a.options, "anytest.Widget", "local types are offered")
+	assert.Contains(t, a.options, "google.protobuf.Timestamp", "compiled-in types are offered")
+
+	a.typeEntry.SetText("anytest.Widget")
+	require.NotNil(t, a.payload, "choosing a type shows its form")
+	a.payload.builder.fields["name"].SetValue("sprocket")
+	a.payload.builder.fields["size"].SetValue(int32(3))
+
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"payload":{"@type":"type.googleapis.com/anytest.Widget","name":"sprocket","size":3}}`, got)
+	assert.NoError(t, b.Validate())
+}
+
+func TestAnyWidget_FromJSONExpandsPayload(t *testing.T) {
+	test.NewApp()
+	b, a := anyTestBuilder(t)
+
+	input := `{"payload":{"@type":"type.googleapis.com/anytest.Widget","name":"gear","size":7}}`
+	require.NoError(t, b.FromJSON(input))
+	assert.Equal(t, "anytest.Widget", a.typeEntry.Text)
+	require.NotNil(t, a.payload)
+	assert.Equal(t, "gear", a.payload.builder.fields["name"].GetValue())
+	assert.Equal(t, int32(7), a.payload.builder.fields["size"].GetValue())
+
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, input, got)
+}
+
+func TestAnyWidget_UnresolvableTypeKeptAsIs(t *testing.T) {
+	test.NewApp()
+	b, a := anyTestBuilder(t)
+
+	input := `{"payload":{"@type":"type.googleapis.com/missing.v1.Thing","value":"CCo="}}`
+	require.NoError(t, b.FromJSON(input))
+	assert.Equal(t, "missing.v1.Thing", a.typeEntry.Text)
+	assert.Nil(t, a.payload)
+	assert.True(t, a.note.Visible(), "the raw payload is explained")
+	assert.NoError(t, b.Validate())
+
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, input, got, "the payload is sent back unchanged")
+
+	// Choosing a known type replaces the raw payload
+	a.typeEntry.SetText("anytest.Widget")
+	assert.NotNil(t, a.payload)
+	assert.False(t, a.note.Visible())
+}
+
+func TestAnyWidget_UnknownTypeInvalid(t *testing.T) {
+	test.NewApp()
+	b, a := anyTestBuilder(t)
+
+	a.typeEntry.SetText("anytest.Wid")
+	assert.Nil(t, a.payload, "partial names are not resolved")
+	assert.EqualError(t, b.Validate(), "payload: unknown message type: anytest.Wid")
+
+	a.typeEntry.SetText("")
+	assert.NoError(t, b.Validate())
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, got)
+}
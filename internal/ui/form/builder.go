@@ -2,25 +2,35 @@ package form
 
 import (
 	"fmt"
+	"sort"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"github.com/shhac/grotto/internal/ui/components"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/dynamicpb"
 )
 
+// DefaultMaxDepth is how many levels of nested messages a form expands
+// before deferring deeper ones behind an "Add <Type>..." button.
+const DefaultMaxDepth = 5
+
 // FormBuilder generates Fyne forms from proto message descriptors
 type FormBuilder struct {
 	md             protoreflect.MessageDescriptor
+	ancestors      []protoreflect.FullName // Enclosing message types, outermost first
+	maxDepth       int
 	fields         map[string]*FieldWidget // Scalar field widgets
 	repeatedFields map[string]*RepeatedFieldWidget
 	mapFields      map[string]*MapFieldWidget
 	nestedFields   map[string]*NestedMessageWidget
 	oneofFields    map[string]*OneofWidget
-	optionalFields map[string]*OptionalFieldWidget // Proto3 optional + single-member oneofs
+	optionalFields map[string]*OptionalFieldWidget // Explicit presence + single-member oneofs
+	types          *protoconv.TypeResolver         // Message types for Any fields
 	container      *fyne.Container
 }
 
@@ -28,6 +38,7 @@ type FormBuilder struct {
 func NewFormBuilder(md protoreflect.MessageDescriptor) *FormBuilder {
 	return &FormBuilder{
 		md:             md,
+		maxDepth:       DefaultMaxDepth,
 		fields:         make(map[string]*FieldWidget),
 		repeatedFields: make(map[string]*RepeatedFieldWidget),
 		mapFields:      make(map[string]*MapFieldWidget),
@@ -37,6 +48,51 @@ func NewFormBuilder(md protoreflect.MessageDescriptor) *FormBuilder {
 	}
 }
 
+// SetMaxDepth sets how many levels of nested messages are expanded when
+// the form is built; deeper messages are added on request. Values below 1
+// are treated as 1. Call it before Build.
+func (b *FormBuilder) SetMaxDepth(depth int) {
+	if depth < 1 {
+		depth = 1
+	}
+	b.maxDepth = depth
+}
+
+// SetTypeResolver sets the message types Any fields can hold, and that
+// JSON conversion packs and expands Any values with. Without one only the
+// types compiled into grotto are known. Call it before Build.
+func (b *FormBuilder) SetTypeResolver(types *protoconv.TypeResolver) {
+	b.types = types
+}
+
+// shouldDefer reports whether a nested md field should be left unexpanded:
+// either md already encloses this form, so expanding it would recurse
+// forever, or the nesting has reached the depth limit.
+func (b *FormBuilder) shouldDefer(md protoreflect.MessageDescriptor) bool {
+	if len(b.ancestors)+1 >= b.maxDepth {
+		return true
+	}
+	name := md.FullName()
+	if name == b.md.FullName() {
+		return true
+	}
+	for _, ancestor := range b.ancestors {
+		if ancestor == name {
+			return true
+		}
+	}
+	return false
+}
+
+// childBuilder creates the builder for a message nested in this form.
+func (b *FormBuilder) childBuilder(md protoreflect.MessageDescriptor) *FormBuilder {
+	child := NewFormBuilder(md)
+	child.ancestors = append(append([]protoreflect.FullName{}, b.ancestors...), b.md.FullName())
+	child.maxDepth = b.maxDepth
+	child.types = b.types
+	return child
+}
+
 // Destroy breaks reference cycles to help GC collect the widget tree.
 // Call this before discarding a FormBuilder to release nested builders,
 // closures, and widget references that Fyne's canvas may otherwise retain.
@@ -61,83 +117,67 @@ func (b *FormBuilder) Destroy() {
 // Build creates the form UI for the message descriptor
 func (b *FormBuilder) Build() fyne.CanvasObject {
 	items := make([]fyne.CanvasObject, 0)
+	for _, row := range b.rows() {
+		if item := row(); item != nil {
+			items = append(items, item)
+		}
+	}
+
+	// If no fields, show placeholder
+	if len(items) == 0 {
+		items = append(items, widget.NewLabel("(empty message)"))
+	}
+
+	// Create scrollable container with all fields
+	b.container = container.NewVBox(items...)
+	return container.NewVScroll(b.container)
+}
+
+// BuildInSteps creates the form UI like Build, but empty, returning with it
+// a step function that adds up to n more rows per call and reports when
+// the form is complete. Spreading the steps over several frames keeps the
+// window responsive while a very large form is built. Until step reports
+// done the form is partial, so its values should not be read or set.
+func (b *FormBuilder) BuildInSteps() (fyne.CanvasObject, func(n int) (done bool)) {
+	rows := b.rows()
+	next := 0
+	b.container = container.NewVBox()
+	step := func(n int) bool {
+		items := b.container.Objects
+		for ; next < len(rows) && n > 0; next++ {
+			if item := rows[next](); item != nil {
+				items = append(items, item)
+				n--
+			}
+		}
+		done := next >= len(rows)
+		if done && len(items) == 0 {
+			items = append(items, widget.NewLabel("(empty message)"))
+		}
+		b.container.Objects = items
+		b.container.Refresh()
+		return done
+	}
+	return container.NewVScroll(b.container), step
+}
+
+// rows returns a function per top-level row of the form, in order, each
+// creating the row's widgets and registering them with the builder. A row
+// function returns nil when its field has no widget.
+func (b *FormBuilder) rows() []func() fyne.CanvasObject {
+	var rows []func() fyne.CanvasObject
 
 	// Iterate through all fields in the message
 	fields := b.md.Fields()
 	for i := 0; i < fields.Len(); i++ {
 		fd := fields.Get(i)
-		fieldName := string(fd.Name())
 
 		// Skip fields that are part of a real oneof (handled separately).
 		// Proto3 optional fields use synthetic oneofs — treat those as normal fields.
 		if fd.ContainingOneof() != nil && !fd.ContainingOneof().IsSynthetic() {
 			continue
 		}
-
-		isOptional := fd.ContainingOneof() != nil && fd.ContainingOneof().IsSynthetic()
-
-		// Handle different field types
-		if fd.IsList() {
-			// Repeated field
-			repeatedWidget := NewRepeatedFieldWidget(fieldName, fd)
-			b.repeatedFields[fieldName] = repeatedWidget
-			items = append(items, repeatedWidget)
-
-		} else if fd.IsMap() {
-			// Map field - create a specialized map widget
-			mapWidget := NewMapFieldWidget(fieldName, fd)
-			b.mapFields[fieldName] = mapWidget
-			items = append(items, mapWidget)
-
-		} else if isOptional {
-			// Proto3 optional field — wrap in presence toggle
-			optWidget := b.createOptionalForField(fd)
-			if optWidget != nil {
-				b.optionalFields[fieldName] = optWidget
-				items = append(items, optWidget)
-			}
-
-		} else if fd.Kind() == protoreflect.MessageKind {
-			// Check if it's a well-known type
-			if isWellKnownType(fd) {
-				// Well-known types are handled by MapFieldToWidget
-				fw := MapFieldToWidget(fd)
-				if fw != nil {
-					b.fields[fieldName] = fw
-					formItem := container.NewBorder(
-						nil, nil,
-						fieldLabel(fw.Label, scalarTypeHint(fd)), nil,
-						fw.Widget,
-					)
-					items = append(items, formItem)
-				}
-			} else {
-				// Nested message - create expandable section
-				nestedWidget := NewNestedMessageWidget(fieldName, fd.Message())
-				b.nestedFields[fieldName] = nestedWidget
-				items = append(items, nestedWidget)
-			}
-
-		} else {
-			// Scalar field - use mapper
-			fw := MapFieldToWidget(fd)
-			if fw != nil {
-				b.fields[fieldName] = fw
-
-				// Strip checkbox text — label is provided by fieldLabel for consistency
-				if check, ok := fw.Widget.(*widget.Check); ok {
-					check.Text = ""
-					check.Refresh()
-				}
-
-				formItem := container.NewBorder(
-					nil, nil,
-					fieldLabel(fw.Label, scalarTypeHint(fd)), nil,
-					fw.Widget,
-				)
-				items = append(items, formItem)
-			}
-		}
+		rows = append(rows, func() fyne.CanvasObject { return b.fieldRow(fd) })
 	}
 
 	// Handle oneofs (skip synthetic oneofs created for proto3 optional fields)
@@ -147,32 +187,110 @@ func (b *FormBuilder) Build() fyne.CanvasObject {
 		if od.IsSynthetic() {
 			continue
 		}
+		rows = append(rows, func() fyne.CanvasObject { return b.oneofRow(od) })
+	}
+	return rows
+}
 
-		if od.Fields().Len() == 1 {
-			// Single-member oneof: use toggle instead of useless dropdown
-			fd := od.Fields().Get(0)
-			fieldName := string(fd.Name())
-			optWidget := b.createOptionalForField(fd)
-			if optWidget != nil {
-				b.optionalFields[fieldName] = optWidget
-				items = append(items, optWidget)
+// fieldRow creates the row for a field outside any real oneof.
+func (b *FormBuilder) fieldRow(fd protoreflect.FieldDescriptor) fyne.CanvasObject {
+	fieldName := string(fd.Name())
+	isOptional := hasPresenceToggle(fd)
+
+	// Handle different field types
+	if fd.IsList() {
+		// Repeated field
+		repeatedWidget := NewRepeatedFieldWidget(fieldName, fd)
+		b.repeatedFields[fieldName] = repeatedWidget
+		return repeatedWidget
+
+	} else if fd.IsMap() {
+		// Map field - create a specialized map widget
+		mapWidget := NewMapFieldWidget(fieldName, fd)
+		b.mapFields[fieldName] = mapWidget
+		return mapWidget
+
+	} else if isOptional {
+		// Explicit presence — wrap in presence toggle
+		optWidget := b.createOptionalForField(fd)
+		if optWidget == nil {
+			return nil
+		}
+		b.optionalFields[fieldName] = optWidget
+		return optWidget
+
+	} else if fd.Kind() == protoreflect.MessageKind {
+		// Check if it's a well-known type
+		if isWellKnownType(fd) {
+			// Well-known types are handled by MapFieldToWidget, except
+			// Any, which needs the builder's types for its payload
+			fw := MapFieldToWidget(fd)
+			if fd.Message().FullName() == protoconv.AnyFullName {
+				fw = b.anyFieldWidget(fd)
 			}
-		} else {
-			oneofName := string(od.Name())
-			oneofWidget := NewOneofWidget(oneofName, od)
-			b.oneofFields[oneofName] = oneofWidget
-			items = append(items, oneofWidget)
+			if fw == nil {
+				return nil
+			}
+			b.fields[fieldName] = fw
+			return container.NewBorder(
+				nil, nil,
+				fieldLabel(fw.Label, scalarTypeHint(fd), fw.Deprecated), nil,
+				fw.Widget,
+			)
 		}
+		// Nested message - create expandable section
+		nestedWidget := newNestedMessageWidget(fieldName, fd.Message(), b, protoconv.IsFieldDeprecated(fd))
+		b.nestedFields[fieldName] = nestedWidget
+		return nestedWidget
 	}
 
-	// If no fields, show placeholder
-	if len(items) == 0 {
-		items = append(items, widget.NewLabel("(empty message)"))
+	// Scalar field - use mapper
+	fw := MapFieldToWidget(fd)
+	if fw == nil {
+		return nil
 	}
+	b.fields[fieldName] = fw
 
-	// Create scrollable container with all fields
-	b.container = container.NewVBox(items...)
-	return container.NewVScroll(b.container)
+	// Strip checkbox text — label is provided by fieldLabel for consistency
+	if check, ok := fw.Widget.(*widget.Check); ok {
+		check.Text = ""
+		check.Refresh()
+	}
+
+	return container.NewBorder(
+		nil, nil,
+		fieldLabel(fw.Label, scalarTypeHint(fd), fw.Deprecated), nil,
+		fw.Widget,
+	)
+}
+
+// hasPresenceToggle reports whether fd, outside any real oneof, gets a
+// toggle so that a value can be sent even when it is zero: proto3 optional
+// fields, and scalars with explicit presence in editions files, where it is
+// the default. Proto2 optional fields keep showing their declared defaults.
+func hasPresenceToggle(fd protoreflect.FieldDescriptor) bool {
+	if od := fd.ContainingOneof(); od != nil {
+		return od.IsSynthetic()
+	}
+	return fd.HasPresence() && fd.Message() == nil && fd.Syntax() == protoreflect.Editions
+}
+
+// oneofRow creates the row for a real oneof.
+func (b *FormBuilder) oneofRow(od protoreflect.OneofDescriptor) fyne.CanvasObject {
+	if od.Fields().Len() == 1 {
+		// Single-member oneof: use toggle instead of useless dropdown
+		fd := od.Fields().Get(0)
+		optWidget := b.createOptionalForField(fd)
+		if optWidget == nil {
+			return nil
+		}
+		b.optionalFields[string(fd.Name())] = optWidget
+		return optWidget
+	}
+	oneofName := string(od.Name())
+	oneofWidget := newOneofWidget(oneofName, od, b)
+	b.oneofFields[oneofName] = oneofWidget
+	return oneofWidget
 }
 
 // BuildContent creates the form UI without wrapping in a scroll container.
@@ -242,7 +360,7 @@ func (b *FormBuilder) GetValues() map[string]interface{} {
 		}
 	}
 
-	// Collect optional field values (proto3 optional + single-member oneofs).
+	// Collect optional field values (explicit presence + single-member oneofs).
 	// When enabled, include the value even if zero — this preserves field presence.
 	for name, ofw := range b.optionalFields {
 		if ofw.IsEnabled() {
@@ -253,54 +371,61 @@ func (b *FormBuilder) GetValues() map[string]interface{} {
 	return values
 }
 
-// SetValues populates form fields from a map
+// SetValues populates form fields from a map keyed by proto field name or
+// JSON name
 func (b *FormBuilder) SetValues(values map[string]interface{}) {
+	msgFields := b.md.Fields()
+
 	// Set scalar field values
 	for name, fw := range b.fields {
-		if val, ok := values[name]; ok {
+		if val, ok := fieldValue(values, msgFields.ByName(protoreflect.Name(name))); ok {
 			fw.SetValue(val)
 		}
 	}
 
 	// Set repeated field values
 	for name, rfw := range b.repeatedFields {
-		if val, ok := values[name]; ok {
+		if val, ok := fieldValue(values, msgFields.ByName(protoreflect.Name(name))); ok {
 			rfw.SetValue(val)
 		}
 	}
 
 	// Set map field values
 	for name, mfw := range b.mapFields {
-		if val, ok := values[name]; ok {
+		if val, ok := fieldValue(values, msgFields.ByName(protoreflect.Name(name))); ok {
 			mfw.SetValue(val)
 		}
 	}
 
 	// Set nested message values
 	for name, nfw := range b.nestedFields {
-		if val, ok := values[name]; ok {
+		if val, ok := fieldValue(values, msgFields.ByName(protoreflect.Name(name))); ok {
 			nfw.SetValue(val)
 		}
 	}
 
-	// Set oneof values
+	// Set oneof values: the first field present in declaration order, or
+	// back to the default when none is
 	for _, ofw := range b.oneofFields {
-		// Check if any oneof field is present in values
 		oneofDesc := ofw.GetDescriptor()
 		fields := oneofDesc.Fields()
+		found := false
 		for i := 0; i < fields.Len(); i++ {
 			fd := fields.Get(i)
-			fieldName := string(fd.Name())
-			if val, ok := values[fieldName]; ok {
-				ofw.SetValue(fieldName, val)
+			if val, ok := fieldValue(values, fd); ok {
+				ofw.SetValue(string(fd.Name()), val)
+				found = true
 				break
 			}
 		}
+		if !found {
+			ofw.Clear()
+		}
 	}
 
 	// Set optional field values — toggle on if present, off if absent
 	for name, ofw := range b.optionalFields {
-		if val, ok := values[name]; ok {
+		if val, ok := fieldValue(values, msgFields.ByName(protoreflect.Name(name))); ok {
 			ofw.SetValue(val)
 		} else {
 			ofw.SetEnabled(false)
@@ -308,6 +433,33 @@ func (b *FormBuilder) SetValues(values map[string]interface{}) {
 	}
 }
 
+// fieldValue returns the value values holds for fd, keyed by its proto name
+// or else its JSON name. A JSON name that is another field's proto name
+// belongs to that field.
+func fieldValue(values map[string]interface{}, fd protoreflect.FieldDescriptor) (interface{}, bool) {
+	if fd == nil {
+		return nil, false
+	}
+	if val, ok := values[string(fd.Name())]; ok {
+		return val, true
+	}
+	jsonName := fd.JSONName()
+	if fd.ContainingMessage().Fields().ByName(protoreflect.Name(jsonName)) != nil {
+		return nil, false
+	}
+	val, ok := values[jsonName]
+	return val, ok
+}
+
+// fieldByKey finds the field of md a values key names: the field with that
+// proto name, else the one with that JSON name.
+func fieldByKey(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(key)); fd != nil {
+		return fd
+	}
+	return md.Fields().ByJSONName(key)
+}
+
 // ToJSON converts form values to JSON string
 func (b *FormBuilder) ToJSON() (string, error) {
 	// Create a dynamic message from the descriptor
@@ -319,12 +471,12 @@ func (b *FormBuilder) ToJSON() (string, error) {
 		return "", fmt.Errorf("failed to populate message: %w", err)
 	}
 
-	// Marshal to JSON using protojson
-	jsonBytes, err := protojson.MarshalOptions{
+	// Marshal to JSON using protojson, expanding Any payloads
+	jsonBytes, err := b.types.Marshal(protojson.MarshalOptions{
 		Multiline:       true,
 		Indent:          "  ",
 		EmitUnpopulated: false,
-	}.Marshal(msg)
+	}, msg)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal to JSON: %w", err)
 	}
@@ -332,13 +484,18 @@ func (b *FormBuilder) ToJSON() (string, error) {
 	return string(jsonBytes), nil
 }
 
-// FromJSON populates form from JSON string
+// FromJSON populates form from JSON string. If the JSON sets more than one
+// field of a oneof, the first is kept and the form is populated, but an
+// *OneofConflictError is returned to warn about the dropped values.
 func (b *FormBuilder) FromJSON(jsonStr string) error {
 	// Create a dynamic message from the descriptor
 	msg := dynamicpb.NewMessage(b.md)
 
-	// Unmarshal JSON into message
-	if err := protojson.Unmarshal([]byte(jsonStr), msg); err != nil {
+	// Unmarshal JSON into message, accepting human-friendly durations and
+	// keeping one field per oneof, which protojson insists on
+	jsonStr, _ = protoconv.NormalizeJSON(jsonStr, b.md)
+	jsonStr, conflicts := resolveOneofConflicts(jsonStr, b.md)
+	if err := b.types.Unmarshal(protojson.UnmarshalOptions{}, []byte(jsonStr), msg); err != nil {
 		return fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 
@@ -348,6 +505,9 @@ func (b *FormBuilder) FromJSON(jsonStr string) error {
 	// Populate form fields
 	b.SetValues(values)
 
+	if len(conflicts) > 0 {
+		return &OneofConflictError{Conflicts: conflicts}
+	}
 	return nil
 }
 
@@ -387,7 +547,7 @@ func (b *FormBuilder) Clear() {
 }
 
 // createOptionalForField creates an OptionalFieldWidget for a field descriptor.
-// Used for proto3 optional fields and single-member oneofs.
+// Used for fields with explicit presence and single-member oneofs.
 func (b *FormBuilder) createOptionalForField(fd protoreflect.FieldDescriptor) *OptionalFieldWidget {
 	fieldName := string(fd.Name())
 	if fd.Kind() == protoreflect.MessageKind {
@@ -397,7 +557,7 @@ func (b *FormBuilder) createOptionalForField(fd protoreflect.FieldDescriptor) *O
 				return NewOptionalScalarWidget(fw)
 			}
 		} else {
-			return NewOptionalNestedWidget(fieldName, fd.Message())
+			return newOptionalNestedWidget(fieldName, fd.Message(), b, protoconv.IsFieldDeprecated(fd))
 		}
 	} else {
 		fw := MapFieldToWidget(fd)
@@ -408,10 +568,11 @@ func (b *FormBuilder) createOptionalForField(fd protoreflect.FieldDescriptor) *O
 	return nil
 }
 
-// populateMessage sets field values from a map to a proto message
+// populateMessage sets field values from a map, keyed by proto field name or
+// JSON name, to a proto message
 func (b *FormBuilder) populateMessage(msg protoreflect.Message, values map[string]interface{}) error {
 	for fieldName, value := range values {
-		fd := b.md.Fields().ByName(protoreflect.Name(fieldName))
+		fd := fieldByKey(b.md, fieldName)
 		if fd == nil {
 			continue // Skip unknown fields
 		}
@@ -423,7 +584,8 @@ func (b *FormBuilder) populateMessage(msg protoreflect.Message, values map[strin
 	return nil
 }
 
-// messageToMap converts a proto message to a map
+// messageToMap converts a proto message to a map keyed by proto field name,
+// which SetValues and populateMessage read back whatever the JSON names
 func (b *FormBuilder) messageToMap(msg protoreflect.Message) map[string]interface{} {
 	values := make(map[string]interface{})
 
@@ -495,44 +657,14 @@ func interfaceToValue(fd protoreflect.FieldDescriptor, v interface{}) (protorefl
 		if b, ok := v.(bool); ok {
 			return protoreflect.ValueOfBool(b), nil
 		}
-	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
-		if i, ok := v.(int32); ok {
-			return protoreflect.ValueOfInt32(i), nil
-		}
-		if i, ok := v.(float64); ok {
-			return protoreflect.ValueOfInt32(int32(i)), nil
-		}
-	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
-		if i, ok := v.(int64); ok {
-			return protoreflect.ValueOfInt64(i), nil
-		}
-		if i, ok := v.(float64); ok {
-			return protoreflect.ValueOfInt64(int64(i)), nil
-		}
-	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
-		if u, ok := v.(uint32); ok {
-			return protoreflect.ValueOfUint32(u), nil
-		}
-		if f, ok := v.(float64); ok {
-			return protoreflect.ValueOfUint32(uint32(f)), nil
-		}
-	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
-		if u, ok := v.(uint64); ok {
-			return protoreflect.ValueOfUint64(u), nil
-		}
-		if f, ok := v.(float64); ok {
-			return protoreflect.ValueOfUint64(uint64(f)), nil
-		}
-	case protoreflect.FloatKind:
-		if f, ok := v.(float32); ok {
-			return protoreflect.ValueOfFloat32(f), nil
-		}
-		if f, ok := v.(float64); ok {
-			return protoreflect.ValueOfFloat32(float32(f)), nil
-		}
-	case protoreflect.DoubleKind:
-		if f, ok := v.(float64); ok {
-			return protoreflect.ValueOfFloat64(f), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		// 64-bit integers may come as strings, as protojson writes them
+		if n, err := numericValue(v, fd); err == nil {
+			return protoreflect.ValueOf(n), nil
 		}
 	case protoreflect.StringKind:
 		if s, ok := v.(string); ok {
@@ -542,6 +674,11 @@ func interfaceToValue(fd protoreflect.FieldDescriptor, v interface{}) (protorefl
 		if b, ok := v.([]byte); ok {
 			return protoreflect.ValueOfBytes(b), nil
 		}
+		if s, ok := v.(string); ok {
+			if b, _, err := DecodeBase64(s); err == nil {
+				return protoreflect.ValueOfBytes(b), nil
+			}
+		}
 	case protoreflect.EnumKind:
 		if i, ok := v.(int32); ok {
 			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i)), nil
@@ -550,11 +687,16 @@ func interfaceToValue(fd protoreflect.FieldDescriptor, v interface{}) (protorefl
 			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(int32(f))), nil
 		}
 	case protoreflect.MessageKind:
+		// Timestamp, Duration, and FieldMask widgets produce string forms;
+		// values arriving as maps of seconds/nanos are set field by field
+		if _, isMap := v.(map[string]interface{}); !isMap && protoconv.IsSupported(fd.Message().FullName()) {
+			return wellKnownToValue(fd.Message(), v)
+		}
 		// Handle nested messages
 		if m, ok := v.(map[string]interface{}); ok {
 			nestedMsg := dynamicpb.NewMessage(fd.Message())
 			for k, val := range m {
-				nestedFd := fd.Message().Fields().ByName(protoreflect.Name(k))
+				nestedFd := fieldByKey(fd.Message(), k)
 				if nestedFd != nil {
 					if err := setFieldValue(nestedMsg, nestedFd, val); err != nil {
 						return protoreflect.Value{}, err
@@ -596,6 +738,11 @@ func valueToInterface(fd protoreflect.FieldDescriptor, v protoreflect.Value) int
 		return result
 	}
 
+	// Singular well-known types are edited as strings
+	if fd.Kind() == protoreflect.MessageKind && protoconv.IsSupported(fd.Message().FullName()) {
+		return wellKnownToInterface(v.Message())
+	}
+
 	// For non-list, non-map fields, use scalar converter
 	return scalarValueToInterface(fd, v)
 }
@@ -628,6 +775,10 @@ func scalarValueToInterface(fd protoreflect.FieldDescriptor, v protoreflect.Valu
 		return int32(v.Enum())
 	case protoreflect.MessageKind:
 		msg := v.Message()
+		// Well-known types are edited as strings in lists and maps too
+		if protoconv.IsSupported(msg.Descriptor().FullName()) {
+			return wellKnownToInterface(msg)
+		}
 		result := make(map[string]interface{})
 		msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
 			result[string(fd.Name())] = valueToInterface(fd, v)
@@ -664,6 +815,8 @@ func isZeroValue(v interface{}) bool {
 		return val == ""
 	case []byte:
 		return len(val) == 0
+	case []string:
+		return len(val) == 0
 	}
 
 	return false
@@ -691,33 +844,76 @@ func getDefaultValue(fd protoreflect.FieldDescriptor) interface{} {
 	case protoreflect.BytesKind:
 		return []byte{}
 	case protoreflect.EnumKind:
-		// Return first enum value
+		// The declared default in proto2, else the first value
+		if ev := enumDefault(fd); ev != nil {
+			return int32(ev.Number())
+		}
 		return int32(0)
 	}
 	return nil
 }
 
-// Validate validates all form fields
+// Validate validates all form fields, returning the first error found
 func (b *FormBuilder) Validate() error {
-	// Validate scalar fields
-	for fieldName, fw := range b.fields {
-		if fw.Validate != nil {
+	if errs := b.FieldErrors(""); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// FieldErrors validates all form fields and returns every failure,
+// qualified with its dotted field path under prefix. Disabled optional
+// fields are skipped since they are omitted from the request.
+func (b *FormBuilder) FieldErrors(prefix string) []protoconv.FieldError {
+	var errs []protoconv.FieldError
+
+	for _, name := range sortedKeys(b.fields) {
+		if fw := b.fields[name]; fw.Validate != nil {
 			if err := fw.Validate(); err != nil {
-				return fmt.Errorf("field %s: %w", fieldName, err)
+				errs = append(errs, protoconv.FieldError{Path: joinPath(prefix, name), Message: err.Error()})
 			}
 		}
 	}
 
-	// Validate nested messages
-	for fieldName, nfw := range b.nestedFields {
-		if builder := nfw.GetBuilder(); builder != nil {
-			if err := builder.Validate(); err != nil {
-				return fmt.Errorf("nested field %s: %w", fieldName, err)
-			}
+	for _, name := range sortedKeys(b.repeatedFields) {
+		errs = append(errs, b.repeatedFields[name].FieldErrors(joinPath(prefix, name))...)
+	}
+
+	for _, name := range sortedKeys(b.mapFields) {
+		errs = append(errs, b.mapFields[name].FieldErrors(joinPath(prefix, name))...)
+	}
+
+	for _, name := range sortedKeys(b.nestedFields) {
+		if builder := b.nestedFields[name].GetBuilder(); builder != nil {
+			errs = append(errs, builder.FieldErrors(joinPath(prefix, name))...)
 		}
 	}
 
-	return nil
+	for _, name := range sortedKeys(b.optionalFields) {
+		if ofw := b.optionalFields[name]; ofw.IsEnabled() {
+			errs = append(errs, ofw.FieldErrors(joinPath(prefix, name))...)
+		}
+	}
+
+	return errs
+}
+
+// joinPath appends a field name to a dotted path prefix.
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // ToMap converts form values to a generic map (useful for JSON serialization)
@@ -737,17 +933,32 @@ func (b *FormBuilder) BuildForm(md protoreflect.MessageDescriptor) fyne.CanvasOb
 	// If a different descriptor is provided, recreate the builder
 	if md != b.md {
 		newBuilder := NewFormBuilder(md)
+		newBuilder.maxDepth = b.maxDepth
+		newBuilder.types = b.types
 		*b = *newBuilder
 	}
 	return b.Build()
 }
 
-// fieldLabel creates a consistent label row with the field name and a subdued type hint.
+// fieldLabel creates a consistent label row with the field name and a subdued type hint,
+// followed by a warning when the field is deprecated.
 // All form fields should use this for consistent labeling.
-func fieldLabel(name, typeHint string) fyne.CanvasObject {
+func fieldLabel(name, typeHint string, deprecated bool) fyne.CanvasObject {
 	nameLabel := widget.NewLabel(name)
 	hint := components.NewHintLabel(typeHint)
-	return container.NewHBox(nameLabel, hint)
+	return container.NewHBox(append([]fyne.CanvasObject{nameLabel, hint}, deprecatedBadges(deprecated)...)...)
+}
+
+// deprecatedTooltip explains the warning shown beside deprecated fields.
+const deprecatedTooltip = "Deprecated: the schema marks this field or its type as deprecated"
+
+// deprecatedBadges returns the warning icon to show after a deprecated
+// field's label, or nothing.
+func deprecatedBadges(deprecated bool) []fyne.CanvasObject {
+	if !deprecated {
+		return nil
+	}
+	return []fyne.CanvasObject{components.NewTooltipIcon(theme.WarningIcon(), deprecatedTooltip)}
 }
 
 // scalarTypeHint returns a human-readable type name for a scalar/message field.
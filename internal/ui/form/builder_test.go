@@ -0,0 +1,226 @@
+package form
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/shhac/grotto/internal/ui/components"
+)
+
+// deprecatedTestMessage builds:
+//
+//	message Legacy { option deprecated = true; string id = 1; }
+//	message Account {
+//	  string name = 1;
+//	  string old_name = 2 [deprecated = true];
+//	  Legacy legacy = 3;
+//	  repeated string aliases = 4 [deprecated = true];
+//	}
+func deprecatedTestMessage(t *testing.T) *FormBuilder {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	deprecated := &descriptorpb.FieldOptions{Deprecated: proto.Bool(true)}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("deprecatedtest/account.proto"),
+		Package: proto.String("deprecatedtest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:    proto.String("Legacy"),
+				Options: &descriptorpb.MessageOptions{Deprecated: proto.Bool(true)},
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), JsonName: proto.String("id"), Number: proto.Int32(1), Label: opt, Type: str},
+				},
+			},
+			{
+				Name: proto.String("Account"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Label: opt, Type: str},
+					{Name: proto.String("old_name"), JsonName: proto.String("oldName"), Number: proto.Int32(2), Label: opt, Type: str, Options: deprecated},
+					{
+						Name: proto.String("legacy"), JsonName: proto.String("legacy"), Number: proto.Int32(3), Label: opt,
+						Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".deprecatedtest.Legacy"),
+					},
+					{
+						Name: proto.String("aliases"), JsonName: proto.String("aliases"), Number: proto.Int32(4),
+						Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: str, Options: deprecated,
+					},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return NewFormBuilder(fd.Messages().ByName("Account"))
+}
+
+// tooltipIcons collects the tooltip icons rendered within obj.
+func tooltipIcons(obj fyne.CanvasObject) []*components.TooltipIcon {
+	switch o := obj.(type) {
+	case *components.TooltipIcon:
+		return []*components.TooltipIcon{o}
+	case *fyne.Container:
+		var icons []*components.TooltipIcon
+		for _, child := range o.Objects {
+			icons = append(icons, tooltipIcons(child)...)
+		}
+		return icons
+	case fyne.Widget:
+		var icons []*components.TooltipIcon
+		for _, child := range test.WidgetRenderer(o).Objects() {
+			icons = append(icons, tooltipIcons(child)...)
+		}
+		return icons
+	}
+	return nil
+}
+
+func TestFormBuilder_DeprecatedFields(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := deprecatedTestMessage(t)
+	form := b.Build()
+
+	assert.False(t, b.fields["name"].Deprecated)
+	assert.True(t, b.fields["old_name"].Deprecated)
+
+	// old_name, the Legacy-typed section and aliases are each flagged
+	icons := tooltipIcons(form)
+	require.Len(t, icons, 3)
+	for _, icon := range icons {
+		assert.Equal(t, deprecatedTooltip, icon.Tooltip())
+	}
+}
+
+// jsonNameTestMessage builds:
+//
+//	message Person {
+//	  string user_id = 1 [json_name = "userId"];
+//	  string external_id = 2 [json_name = "uid"];
+//	}
+//	message Lookup {
+//	  string user_id = 1 [json_name = "userId"];
+//	  string external_id = 2 [json_name = "uid"];
+//	  Person owner = 3 [json_name = "person"];
+//	  repeated string tag_list = 4 [json_name = "tags"];
+//	  oneof target {
+//	    string account_id = 5 [json_name = "acct"];
+//	    Person member = 6 [json_name = "who"];
+//	  }
+//	}
+func jsonNameTestMessage(t *testing.T) *FormBuilder {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("jsonnametest/lookup.proto"),
+		Package: proto.String("jsonnametest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Person"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("user_id"), JsonName: proto.String("userId"), Number: proto.Int32(1), Label: opt, Type: str},
+					{Name: proto.String("external_id"), JsonName: proto.String("uid"), Number: proto.Int32(2), Label: opt, Type: str},
+				},
+			},
+			{
+				Name: proto.String("Lookup"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("user_id"), JsonName: proto.String("userId"), Number: proto.Int32(1), Label: opt, Type: str},
+					{Name: proto.String("external_id"), JsonName: proto.String("uid"), Number: proto.Int32(2), Label: opt, Type: str},
+					{
+						Name: proto.String("owner"), JsonName: proto.String("person"), Number: proto.Int32(3), Label: opt,
+						Type: msg, TypeName: proto.String(".jsonnametest.Person"),
+					},
+					{
+						Name: proto.String("tag_list"), JsonName: proto.String("tags"), Number: proto.Int32(4),
+						Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: str,
+					},
+					{Name: proto.String("account_id"), JsonName: proto.String("acct"), Number: proto.Int32(5), Label: opt, Type: str, OneofIndex: proto.Int32(0)},
+					{
+						Name: proto.String("member"), JsonName: proto.String("who"), Number: proto.Int32(6), Label: opt,
+						Type: msg, TypeName: proto.String(".jsonnametest.Person"), OneofIndex: proto.Int32(0),
+					},
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("target")}},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return NewFormBuilder(fd.Messages().ByName("Lookup"))
+}
+
+func TestFormBuilder_JSONNameRoundTrip(t *testing.T) {
+	test.NewApp()
+
+	inputs := map[string]string{
+		"json names": `{
+			"userId": "u-1", "uid": "x-1",
+			"person": {"userId": "u-2", "uid": "x-2"},
+			"tags": ["a", "b"],
+			"who": {"uid": "x-3"}
+		}`,
+		"proto names": `{
+			"user_id": "u-1", "external_id": "x-1",
+			"owner": {"user_id": "u-2", "external_id": "x-2"},
+			"tag_list": ["a", "b"],
+			"member": {"external_id": "x-3"}
+		}`,
+	}
+	want := `{
+		"userId": "u-1", "uid": "x-1",
+		"person": {"userId": "u-2", "uid": "x-2"},
+		"tags": ["a", "b"],
+		"who": {"uid": "x-3"}
+	}`
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			b := jsonNameTestMessage(t)
+			b.Build()
+			require.NoError(t, b.FromJSON(input))
+			got, err := b.ToJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, want, got)
+
+			// Form → text → form keeps everything too
+			require.NoError(t, b.FromJSON(got))
+			again, err := b.ToJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, want, again)
+		})
+	}
+}
+
+func TestFormBuilder_SetValuesByJSONName(t *testing.T) {
+	test.NewApp()
+	b := jsonNameTestMessage(t)
+	b.Build()
+
+	b.SetValues(map[string]interface{}{
+		"userId": "u-1",
+		"uid":    "x-1",
+		"person": map[string]interface{}{"uid": "x-2"},
+		"tags":   []interface{}{"a"},
+		"acct":   "acct-9",
+	})
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"userId":"u-1","uid":"x-1","person":{"uid":"x-2"},"tags":["a"],"acct":"acct-9"}`, got)
+
+	// GetValues keys by proto name, whatever SetValues was given
+	values := b.GetValues()
+	assert.Equal(t, "u-1", values["user_id"])
+	assert.Equal(t, "acct-9", values["account_id"])
+	assert.Equal(t, map[string]interface{}{"external_id": "x-2"}, values["owner"])
+}
@@ -0,0 +1,299 @@
+package form
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Base64Variant identifies which base64 alphabet a bytes value was written in
+type Base64Variant int
+
+const (
+	// Base64Standard is RFC 4648 standard base64 ('+' and '/'), padded or not
+	Base64Standard Base64Variant = iota
+	// Base64URL is RFC 4648 URL-safe base64 ('-' and '_'), padded or not
+	Base64URL
+)
+
+// String returns a short human-readable name for the variant
+func (v Base64Variant) String() string {
+	if v == Base64URL {
+		return "base64url"
+	}
+	return "base64"
+}
+
+// errInvalidBase64 is returned when a value decodes in neither alphabet
+var errInvalidBase64 = errors.New("not valid base64 or base64url")
+
+// base64URLCaption is shown beneath bytes entries holding URL-safe values
+const base64URLCaption = "base64url detected — will be converted"
+
+// DecodeBase64 decodes s as standard or URL-safe base64, with or without
+// padding, ignoring embedded whitespace (line breaks from copy/paste).
+// Strings valid in both alphabets are treated as standard base64.
+func DecodeBase64(s string) ([]byte, Base64Variant, error) {
+	s = stripWhitespace(s)
+	if s == "" {
+		return []byte{}, Base64Standard, nil
+	}
+
+	if data, err := decodeWith(base64.StdEncoding, s); err == nil {
+		return data, Base64Standard, nil
+	}
+	if data, err := decodeWith(base64.URLEncoding, s); err == nil {
+		return data, Base64URL, nil
+	}
+	return nil, Base64Standard, errInvalidBase64
+}
+
+// NormalizeBase64 converts a standard or URL-safe base64 string to the
+// padded standard form expected in protojson payloads.
+func NormalizeBase64(s string) (string, Base64Variant, error) {
+	data, variant, err := DecodeBase64(s)
+	if err != nil {
+		return "", variant, err
+	}
+	return base64.StdEncoding.EncodeToString(data), variant, nil
+}
+
+// decodeWith decodes s with enc, accepting input with or without padding.
+func decodeWith(enc *base64.Encoding, s string) ([]byte, error) {
+	if strings.HasSuffix(s, "=") || len(s)%4 == 0 {
+		return enc.Strict().DecodeString(s)
+	}
+	return enc.WithPadding(base64.NoPadding).Strict().DecodeString(s)
+}
+
+// stripWhitespace removes spaces, tabs, and line breaks.
+func stripWhitespace(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// NormalizeBytesJSON rewrites every bytes-typed value in a JSON request so
+// it uses padded standard base64, following the message descriptor through
+// nested messages, repeated fields, and maps. It returns the (possibly
+// unchanged) JSON and the paths of fields whose values were base64url.
+// JSON that does not parse is returned as-is so the caller's normal
+// validation can report it.
+func NormalizeBytesJSON(jsonStr string, md protoreflect.MessageDescriptor) (string, []string) {
+	var converted []string
+	jsonStr = protoconv.RewriteJSON(jsonStr, md, NormalizeBytes(&converted))
+	return jsonStr, converted
+}
+
+// NormalizeBytes returns a rewrite for protoconv.RewriteJSON that converts
+// bytes values to padded standard base64, adding the path of each that was
+// base64url to converted (if not nil). Values that are not base64 are left
+// for protojson to report.
+func NormalizeBytes(converted *[]string) protoconv.JSONRewrite {
+	return func(val interface{}, fd protoreflect.FieldDescriptor, path string) (interface{}, bool) {
+		s, ok := val.(string)
+		if !ok || fd.Kind() != protoreflect.BytesKind {
+			return val, false
+		}
+		normalized, variant, err := NormalizeBase64(s)
+		if err != nil || normalized == s {
+			return val, false
+		}
+		if variant == Base64URL && converted != nil {
+			*converted = append(*converted, path)
+		}
+		return normalized, true
+	}
+}
+
+// EncodeBytesFile reads r to the end and returns its contents as padded
+// standard base64, ready for a bytes field.
+func EncodeBytesFile(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// byteLengthHint describes a decoded payload size, e.g. "5 bytes" or
+// "2.0 MiB (2097152 bytes)".
+func byteLengthHint(n int) string {
+	switch {
+	case n == 1:
+		return "1 byte"
+	case n < 1024:
+		return fmt.Sprintf("%d bytes", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KiB (%d bytes)", float64(n)/1024, n)
+	default:
+		return fmt.Sprintf("%.1f MiB (%d bytes)", float64(n)/(1024*1024), n)
+	}
+}
+
+// BytesEntry is a text entry for bytes fields that accepts standard or
+// URL-safe base64, or a file loaded from disk. It shows the decoded length
+// and a caption when the URL-safe variant is detected.
+type BytesEntry struct {
+	widget.BaseWidget
+
+	entry   *widget.Entry
+	loadBtn *widget.Button
+	caption *widget.Label
+	hint    *widget.Label
+	content *fyne.Container
+}
+
+// NewBytesEntry creates a new bytes entry widget
+func NewBytesEntry() *BytesEntry {
+	b := &BytesEntry{
+		entry:   newFormEntry(),
+		caption: widget.NewLabel(base64URLCaption),
+		hint:    widget.NewLabel(""),
+	}
+	b.loadBtn = widget.NewButtonWithIcon("Load from file...", theme.FolderOpenIcon(), b.showFileDialog)
+	b.entry.SetPlaceHolder("Base64 encoded bytes")
+	b.entry.Validator = func(s string) error {
+		_, _, err := DecodeBase64(s)
+		return err
+	}
+
+	b.caption.Importance = widget.LowImportance
+	b.caption.TextStyle = fyne.TextStyle{Italic: true}
+	b.caption.Hide()
+	b.hint.Importance = widget.LowImportance
+	b.hint.Hide()
+
+	b.entry.OnChanged = func(string) {
+		b.updateCaption()
+	}
+
+	b.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, b.loadBtn, b.entry),
+		b.caption,
+		b.hint,
+	)
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+// Text returns the raw text as typed
+func (b *BytesEntry) Text() string {
+	return b.entry.Text
+}
+
+// SetText sets the raw text
+func (b *BytesEntry) SetText(s string) {
+	b.entry.SetText(s)
+	b.updateCaption()
+}
+
+// Bytes decodes the entry text. Invalid input is returned as raw UTF-8 bytes,
+// matching the lenient behaviour of the other form widgets.
+func (b *BytesEntry) Bytes() []byte {
+	data, _, err := DecodeBase64(b.entry.Text)
+	if err != nil {
+		return []byte(b.entry.Text)
+	}
+	return data
+}
+
+// SetBytes displays data as padded standard base64
+func (b *BytesEntry) SetBytes(data []byte) {
+	b.SetText(base64.StdEncoding.EncodeToString(data))
+}
+
+// Validate validates the entry text
+func (b *BytesEntry) Validate() error {
+	return b.entry.Validate()
+}
+
+// LoadFile replaces the entry text with the base64 encoding of r's contents.
+func (b *BytesEntry) LoadFile(r io.Reader) error {
+	encoded, err := EncodeBytesFile(r)
+	if err != nil {
+		return err
+	}
+	b.SetText(encoded)
+	return nil
+}
+
+// showFileDialog opens a file picker and loads the chosen file.
+func (b *BytesEntry) showFileDialog() {
+	win := b.window()
+	if win == nil {
+		return
+	}
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		if err := b.LoadFile(reader); err != nil {
+			dialog.ShowError(fmt.Errorf("read %s: %w", reader.URI().Name(), err), win)
+		}
+	}, win)
+	fd.Show()
+}
+
+// window returns the window showing this entry, falling back to the first
+// open window when the entry is not on screen.
+func (b *BytesEntry) window() fyne.Window {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return nil
+	}
+	windows := app.Driver().AllWindows()
+	canvas := app.Driver().CanvasForObject(b)
+	for _, w := range windows {
+		if w.Canvas() == canvas {
+			return w
+		}
+	}
+	if len(windows) > 0 {
+		return windows[0]
+	}
+	return nil
+}
+
+// updateCaption shows the base64url caption when the text uses that
+// alphabet, and the decoded length whenever the text is valid.
+func (b *BytesEntry) updateCaption() {
+	data, variant, err := DecodeBase64(b.entry.Text)
+	if err == nil && variant == Base64URL {
+		b.caption.Show()
+	} else {
+		b.caption.Hide()
+	}
+
+	if err == nil && len(data) > 0 {
+		b.hint.SetText(byteLengthHint(len(data)))
+		b.hint.Show()
+	} else {
+		b.hint.Hide()
+	}
+}
+
+// CreateRenderer implements fyne.Widget
+func (b *BytesEntry) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(b.content)
+}
@@ -0,0 +1,320 @@
+package form
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"fyne.io/fyne/v2/test"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        string
+		wantVariant Base64Variant
+		wantErr     bool
+	}{
+		{"empty", "", "", Base64Standard, false},
+		{"standard padded", "aGVsbG8=", "hello", Base64Standard, false},
+		{"standard unpadded", "aGVsbG8", "hello", Base64Standard, false},
+		{"standard plus and slash", "+/8=", "\xfb\xff", Base64Standard, false},
+		{"url safe padded", "-_8=", "\xfb\xff", Base64URL, false},
+		{"url safe unpadded", "-_8", "\xfb\xff", Base64URL, false},
+		{"ambiguous treated as standard", "YWJj", "abc", Base64Standard, false},
+		{"whitespace and line breaks", "aGVs\nbG8=\r\n", "hello", Base64Standard, false},
+		{"url safe with spaces", " -_8 ", "\xfb\xff", Base64URL, false},
+		{"mixed alphabets", "+_8=", "", Base64Standard, true},
+		{"invalid characters", "not*base64", "", Base64Standard, true},
+		{"bad padding", "aGVsbG8==", "", Base64Standard, true},
+		{"impossible length", "a", "", Base64Standard, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, variant, err := DecodeBase64(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DecodeBase64(%q) expected error, got %q", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeBase64(%q) unexpected error: %v", tt.input, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DecodeBase64(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if variant != tt.wantVariant {
+				t.Errorf("DecodeBase64(%q) variant = %v, want %v", tt.input, variant, tt.wantVariant)
+			}
+		})
+	}
+}
+
+func TestNormalizeBase64(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"aGVsbG8", "aGVsbG8="},
+		{"-_8", "+/8="},
+		{"-_8=", "+/8="},
+		{"aGVs\nbG8=", "aGVsbG8="},
+		{"+/8=", "+/8="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, _, err := NormalizeBase64(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeBase64(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeBase64(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// bytesTestMessage builds a descriptor with repeated and map bytes fields.
+func bytesTestMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("bytes_test.proto"),
+		Package: proto.String("bytestest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Blob"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{
+					Name:     proto.String("chunks"),
+					JsonName: proto.String("chunks"),
+					Number:   proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(),
+				},
+				{
+					Name:     proto.String("by_key"),
+					JsonName: proto.String("byKey"),
+					Number:   proto.Int32(2),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					TypeName: proto.String(".bytestest.Blob.ByKeyEntry"),
+				},
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("ByKeyEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("key"),
+						JsonName: proto.String("key"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:     proto.String("value"),
+						JsonName: proto.String("value"),
+						Number:   proto.Int32(2),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(),
+					},
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatalf("failed to build test descriptor: %v", err)
+	}
+	return fd.Messages().ByName("Blob")
+}
+
+func TestNormalizeBytesJSON(t *testing.T) {
+	itemRequest := pb.File_grpc_test_proto.Messages().ByName("ItemRequest")
+	itemList := pb.File_grpc_test_proto.Messages().ByName("ItemList")
+	blob := bytesTestMessage(t)
+
+	tests := []struct {
+		name          string
+		desc          protoreflect.MessageDescriptor
+		input         string
+		wantField     func(m map[string]interface{}) interface{}
+		want          interface{}
+		wantConverted []string
+		wantUnchanged bool
+	}{
+		{
+			name:      "nested scalar base64url",
+			desc:      itemRequest,
+			input:     `{"item": {"id": "1", "data": "-_8"}}`,
+			wantField: func(m map[string]interface{}) interface{} { return m["item"].(map[string]interface{})["data"] },
+			want:      "+/8=", wantConverted: []string{"item.data"},
+		},
+		{
+			name:  "repeated message element",
+			desc:  itemList,
+			input: `{"items": [{"data": "aGVsbG8"}, {"data": "-_8="}]}`,
+			wantField: func(m map[string]interface{}) interface{} {
+				return m["items"].([]interface{})[1].(map[string]interface{})["data"]
+			},
+			want: "+/8=", wantConverted: []string{"items[1].data"},
+		},
+		{
+			name:      "repeated bytes",
+			desc:      blob,
+			input:     `{"chunks": ["-_8", "YWJj"]}`,
+			wantField: func(m map[string]interface{}) interface{} { return m["chunks"].([]interface{})[0] },
+			want:      "+/8=", wantConverted: []string{"chunks[0]"},
+		},
+		{
+			name:      "map bytes values by proto name",
+			desc:      blob,
+			input:     `{"by_key": {"a": "-_8"}}`,
+			wantField: func(m map[string]interface{}) interface{} { return m["by_key"].(map[string]interface{})["a"] },
+			want:      "+/8=", wantConverted: []string{"by_key[a]"},
+		},
+		{
+			name:          "already standard is untouched",
+			desc:          itemRequest,
+			input:         `{"item": {"data": "aGVsbG8="}}`,
+			wantUnchanged: true,
+		},
+		{
+			name:          "invalid base64 left for protojson to report",
+			desc:          itemRequest,
+			input:         `{"item": {"data": "not*base64"}}`,
+			wantUnchanged: true,
+		},
+		{
+			name:          "invalid JSON",
+			desc:          itemRequest,
+			input:         `{"item": `,
+			wantUnchanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, converted := NormalizeBytesJSON(tt.input, tt.desc)
+			if tt.wantUnchanged {
+				if got != tt.input {
+					t.Errorf("expected input unchanged, got %s", got)
+				}
+				return
+			}
+
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(got), &m); err != nil {
+				t.Fatalf("normalized JSON does not parse: %v (%s)", err, got)
+			}
+			if v := tt.wantField(m); v != tt.want {
+				t.Errorf("normalized value = %v, want %v (json: %s)", v, tt.want, got)
+			}
+			if len(converted) != len(tt.wantConverted) || (len(converted) > 0 && converted[0] != tt.wantConverted[0]) {
+				t.Errorf("converted = %v, want %v", converted, tt.wantConverted)
+			}
+		})
+	}
+}
+
+func TestNormalizeBytesJSON_PreservesNumbers(t *testing.T) {
+	itemRequest := pb.File_grpc_test_proto.Messages().ByName("ItemRequest")
+	got, _ := NormalizeBytesJSON(`{"item": {"data": "-_8", "count": 9007199254740993}}`, itemRequest)
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(got), &m); err != nil {
+		t.Fatal(err)
+	}
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(m["item"], &item); err != nil {
+		t.Fatal(err)
+	}
+	if string(item["count"]) != "9007199254740993" {
+		t.Errorf("large number was altered: %s", item["count"])
+	}
+}
+
+func TestEncodeBytesFile(t *testing.T) {
+	encoded, err := EncodeBytesFile(strings.NewReader("\xfb\xffhello"))
+	if err != nil {
+		t.Fatalf("EncodeBytesFile: %v", err)
+	}
+	if encoded != "+/9oZWxsbw==" {
+		t.Errorf("EncodeBytesFile = %q, want %q", encoded, "+/9oZWxsbw==")
+	}
+
+	encoded, err = EncodeBytesFile(strings.NewReader(""))
+	if err != nil || encoded != "" {
+		t.Errorf("EncodeBytesFile(empty) = %q, %v; want empty", encoded, err)
+	}
+
+	if _, err := EncodeBytesFile(iotest.ErrReader(io.ErrUnexpectedEOF)); err == nil {
+		t.Error("EncodeBytesFile expected read error")
+	}
+}
+
+func TestByteLengthHint(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "1 byte"},
+		{5, "5 bytes"},
+		{1536, "1.5 KiB (1536 bytes)"},
+		{2 * 1024 * 1024, "2.0 MiB (2097152 bytes)"},
+	}
+	for _, tt := range tests {
+		if got := byteLengthHint(tt.n); got != tt.want {
+			t.Errorf("byteLengthHint(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestBytesEntry_LengthHint(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewBytesEntry()
+	if b.hint.Visible() {
+		t.Error("hint should be hidden for an empty entry")
+	}
+
+	b.SetText("aGVsbG8=")
+	if !b.hint.Visible() || b.hint.Text != "5 bytes" {
+		t.Errorf("hint = %q (visible %v), want %q", b.hint.Text, b.hint.Visible(), "5 bytes")
+	}
+
+	// Typing goes through OnChanged; URL-safe input is counted too
+	b.entry.SetText("-_8")
+	if b.hint.Text != "2 bytes" || !b.caption.Visible() {
+		t.Errorf("hint = %q, caption visible %v; want %q and caption", b.hint.Text, b.caption.Visible(), "2 bytes")
+	}
+
+	b.SetText("not*base64")
+	if b.hint.Visible() {
+		t.Error("hint should be hidden for invalid input")
+	}
+
+	if err := b.LoadFile(strings.NewReader(strings.Repeat("x", 2048))); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if b.hint.Text != "2.0 KiB (2048 bytes)" {
+		t.Errorf("hint after LoadFile = %q", b.hint.Text)
+	}
+	if len(b.Bytes()) != 2048 || b.Validate() != nil {
+		t.Errorf("loaded %d bytes, validate %v", len(b.Bytes()), b.Validate())
+	}
+}
@@ -0,0 +1,282 @@
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// enumDefaultName returns the name an enum field starts at: the declared
+// default of a proto2 field, otherwise the first value.
+func enumDefaultName(fd protoreflect.FieldDescriptor) string {
+	if ev := enumDefault(fd); ev != nil {
+		return string(ev.Name())
+	}
+	return ""
+}
+
+// enumDefault returns the value an enum field starts at, or nil for an
+// enum without values.
+func enumDefault(fd protoreflect.FieldDescriptor) protoreflect.EnumValueDescriptor {
+	if ev := fd.DefaultEnumValue(); ev != nil {
+		// Only set for an explicit proto2 default
+		return ev
+	}
+	if values := fd.Enum().Values(); values.Len() > 0 {
+		return values.Get(0)
+	}
+	return nil
+}
+
+// enumNumberOf returns the number named name, or false if ed has no such
+// name.
+func enumNumberOf(ed protoreflect.EnumDescriptor, name string) (int32, bool) {
+	ev := ed.Values().ByName(protoreflect.Name(name))
+	if ev == nil {
+		return 0, false
+	}
+	return int32(ev.Number()), true
+}
+
+// enumSelection returns the name to show for v, an enum number or name,
+// in a widget currently showing current. A number keeps current when
+// current is one of its aliases, so setting back a value read from the
+// widget changes nothing; otherwise it shows the number's first-declared
+// (canonical) name. It returns false if v names no value of ed.
+func enumSelection(ed protoreflect.EnumDescriptor, current string, v interface{}) (string, bool) {
+	if t, ok := v.(string); ok {
+		if ed.Values().ByName(protoreflect.Name(t)) == nil {
+			return "", false
+		}
+		return t, true
+	}
+	num, ok := enumNumberFrom(v)
+	if !ok {
+		return "", false
+	}
+	if n, ok := enumNumberOf(ed, current); ok && protoreflect.EnumNumber(n) == num {
+		return current, true
+	}
+	ev := ed.Values().ByNumber(num)
+	if ev == nil {
+		return "", false
+	}
+	return string(ev.Name()), true
+}
+
+// enumNumberFrom returns v as an enum number, if it is a number.
+func enumNumberFrom(v interface{}) (protoreflect.EnumNumber, bool) {
+	switch t := v.(type) {
+	case int32:
+		return protoreflect.EnumNumber(t), true
+	case int:
+		return protoreflect.EnumNumber(t), true
+	case float64:
+		// JSON numbers
+		return protoreflect.EnumNumber(t), true
+	case json.Number:
+		n, err := strconv.ParseInt(t.String(), 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		return protoreflect.EnumNumber(n), true
+	case protoreflect.EnumNumber:
+		return t, true
+	}
+	return 0, false
+}
+
+// searchableEnumThreshold is the number of values above which an enum is
+// picked with a type-to-filter entry instead of a plain select.
+const searchableEnumThreshold = 10
+
+// otherEnumOption ends every enum's options. It reveals an entry for a
+// number the descriptor does not declare, e.g. one added to the server's
+// enum since.
+const otherEnumOption = "Other number…"
+
+// enumLabel is how an enum value is listed: its name and number,
+// "STATE_DONE (2)".
+func enumLabel(ev protoreflect.EnumValueDescriptor) string {
+	return fmt.Sprintf("%s (%d)", ev.Name(), ev.Number())
+}
+
+// enumLabelName returns the name an option names. Typed text may leave
+// off the number.
+func enumLabelName(label string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(label), " ")
+	return name
+}
+
+// enumOptions returns the labels of every value of ed in declaration
+// order, followed by otherEnumOption.
+func enumOptions(ed protoreflect.EnumDescriptor) []string {
+	values := ed.Values()
+	options := make([]string, 0, values.Len()+1)
+	for i := range values.Len() {
+		options = append(options, enumLabel(values.Get(i)))
+	}
+	return append(options, otherEnumOption)
+}
+
+// enumSelect picks a value of an enum: from a select listing its values,
+// or a type-to-filter entry for enums of more than searchableEnumThreshold
+// values, or as any int32 typed in after choosing otherEnumOption.
+type enumSelect struct {
+	widget.BaseWidget
+	ed protoreflect.EnumDescriptor
+
+	sel      *widget.Select      // Small enums
+	selEntry *widget.SelectEntry // Large enums
+	other    *widget.Entry       // The number, shown for otherEnumOption
+
+	content *fyne.Container
+}
+
+// newEnumSelect creates an enumSelect for ed showing the value named
+// initial, or nothing when initial is empty.
+func newEnumSelect(ed protoreflect.EnumDescriptor, initial string) *enumSelect {
+	e := &enumSelect{ed: ed}
+
+	e.other = newSignedIntEntry()
+	e.other.SetPlaceHolder("Enum number")
+	e.other.Validator = func(s string) error {
+		if _, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32); err != nil {
+			return fmt.Errorf("enter an enum number")
+		}
+		return nil
+	}
+	e.other.Hide()
+
+	options := enumOptions(ed)
+	var choice fyne.CanvasObject
+	if len(options) > searchableEnumThreshold+1 {
+		// Large enum: use SelectEntry with type-to-filter
+		e.selEntry = widget.NewSelectEntry(options)
+		e.selEntry.Wrapping = fyne.TextWrapOff
+		e.selEntry.Scroll = container.ScrollNone
+		e.selEntry.SetPlaceHolder("Type to filter...")
+		e.selEntry.OnChanged = func(text string) {
+			e.showOther(text == otherEnumOption)
+			if text == "" {
+				e.selEntry.SetOptions(options)
+				return
+			}
+			lower := strings.ToLower(text)
+			filtered := make([]string, 0)
+			for _, opt := range options {
+				if strings.Contains(strings.ToLower(opt), lower) {
+					filtered = append(filtered, opt)
+				}
+			}
+			e.selEntry.SetOptions(filtered)
+		}
+		e.selEntry.Validator = func(s string) error {
+			if s == "" || s == otherEnumOption {
+				return nil
+			}
+			if _, ok := enumNumberOf(ed, enumLabelName(s)); !ok {
+				return fmt.Errorf("unknown enum value: %s", s)
+			}
+			return nil
+		}
+		choice = e.selEntry
+	} else {
+		// Small enum: use plain Select
+		e.sel = widget.NewSelect(options, func(option string) {
+			e.showOther(option == otherEnumOption)
+		})
+		choice = e.sel
+	}
+	if ev := ed.Values().ByName(protoreflect.Name(initial)); ev != nil {
+		e.setChoice(enumLabel(ev))
+	}
+
+	e.content = container.NewVBox(choice, e.other)
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// showOther shows or hides the number entry.
+func (e *enumSelect) showOther(show bool) {
+	if show {
+		e.other.Show()
+	} else {
+		e.other.Hide()
+	}
+}
+
+// choice returns the option chosen, or the text typed.
+func (e *enumSelect) choice() string {
+	if e.sel != nil {
+		return e.sel.Selected
+	}
+	return e.selEntry.Text
+}
+
+// setChoice chooses an option.
+func (e *enumSelect) setChoice(option string) {
+	if e.sel != nil {
+		e.sel.SetSelected(option)
+	} else {
+		e.selEntry.SetText(option)
+	}
+	e.showOther(option == otherEnumOption)
+}
+
+// Name returns the name of the value chosen, or "" when none is or a
+// number was entered.
+func (e *enumSelect) Name() string {
+	if name := enumLabelName(e.choice()); e.ed.Values().ByName(protoreflect.Name(name)) != nil {
+		return name
+	}
+	return ""
+}
+
+// Number returns the number of the value chosen or entered, or false when
+// there is none.
+func (e *enumSelect) Number() (int32, bool) {
+	if e.choice() == otherEnumOption {
+		n, err := strconv.ParseInt(strings.TrimSpace(e.other.Text), 10, 32)
+		return int32(n), err == nil
+	}
+	return enumNumberOf(e.ed, e.Name())
+}
+
+// SetValue shows v, an enum name or number. A number the enum does not
+// declare is shown in the number entry; an unknown name changes nothing.
+func (e *enumSelect) SetValue(v interface{}) {
+	if name, ok := enumSelection(e.ed, e.Name(), v); ok {
+		e.setChoice(enumLabel(e.ed.Values().ByName(protoreflect.Name(name))))
+		return
+	}
+	if num, ok := enumNumberFrom(v); ok {
+		e.setChoice(otherEnumOption)
+		e.other.SetText(strconv.Itoa(int(num)))
+	}
+}
+
+// Validate checks the typed value of a large enum, and the number entered
+// for otherEnumOption.
+func (e *enumSelect) Validate() error {
+	if e.selEntry != nil {
+		if err := e.selEntry.Validate(); err != nil {
+			return err
+		}
+	}
+	if e.choice() == otherEnumOption {
+		return e.other.Validate()
+	}
+	return nil
+}
+
+// CreateRenderer implements fyne.Widget.
+func (e *enumSelect) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(e.content)
+}
@@ -0,0 +1,284 @@
+package form
+
+import (
+	"fmt"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// enumTestMessage builds, in proto2:
+//
+//	enum State {
+//	  option allow_alias = true;
+//	  STATE_UNKNOWN = 0;
+//	  STATE_RUNNING = 1;
+//	  STATE_STARTED = 1; // alias
+//	  STATE_DONE = 2;
+//	}
+//	enum Region { REGION_0 = 0; ... REGION_11 = 11; REGION_ELEVEN = 11; } // aliased, searchable
+//	message Job {
+//	  optional State state = 1 [default = STATE_DONE];
+//	  optional State legacy_state = 2 [default = STATE_STARTED];
+//	  optional State plain = 3;
+//	  repeated State history = 4;
+//	  optional Region region = 5 [default = REGION_ELEVEN];
+//	}
+func enumTestMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	enum := descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum()
+	aliases := &descriptorpb.EnumOptions{AllowAlias: proto.Bool(true)}
+
+	region := &descriptorpb.EnumDescriptorProto{Name: proto.String("Region"), Options: aliases}
+	for i := range 12 {
+		region.Value = append(region.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name: proto.String(fmt.Sprintf("REGION_%d", i)), Number: proto.Int32(int32(i)),
+		})
+	}
+	region.Value = append(region.Value, &descriptorpb.EnumValueDescriptorProto{Name: proto.String("REGION_ELEVEN"), Number: proto.Int32(11)})
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("enumtest/job.proto"),
+		Package: proto.String("enumtest"),
+		Syntax:  proto.String("proto2"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name:    proto.String("State"),
+				Options: aliases,
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("STATE_UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("STATE_RUNNING"), Number: proto.Int32(1)},
+					{Name: proto.String("STATE_STARTED"), Number: proto.Int32(1)},
+					{Name: proto.String("STATE_DONE"), Number: proto.Int32(2)},
+				},
+			},
+			region,
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Job"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name: proto.String("state"), JsonName: proto.String("state"), Number: proto.Int32(1), Label: opt,
+						Type: enum, TypeName: proto.String(".enumtest.State"), DefaultValue: proto.String("STATE_DONE"),
+					},
+					{
+						Name: proto.String("legacy_state"), JsonName: proto.String("legacyState"), Number: proto.Int32(2), Label: opt,
+						Type: enum, TypeName: proto.String(".enumtest.State"), DefaultValue: proto.String("STATE_STARTED"),
+					},
+					{
+						Name: proto.String("plain"), JsonName: proto.String("plain"), Number: proto.Int32(3), Label: opt,
+						Type: enum, TypeName: proto.String(".enumtest.State"),
+					},
+					{
+						Name: proto.String("history"), JsonName: proto.String("history"), Number: proto.Int32(4),
+						Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+						Type:  enum, TypeName: proto.String(".enumtest.State"),
+					},
+					{
+						Name: proto.String("region"), JsonName: proto.String("region"), Number: proto.Int32(5), Label: opt,
+						Type: enum, TypeName: proto.String(".enumtest.Region"), DefaultValue: proto.String("REGION_ELEVEN"),
+					},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd.Messages().ByName("Job")
+}
+
+func TestMapFieldToWidget_EnumDefaults(t *testing.T) {
+	test.NewApp()
+	fields := enumTestMessage(t).Fields()
+
+	cases := []struct {
+		field string
+		name  string
+		num   int32
+	}{
+		{"state", "STATE_DONE", 2},
+		{"legacy_state", "STATE_STARTED", 1}, // A default naming an alias keeps it
+		{"plain", "STATE_UNKNOWN", 0},        // No default: the first value
+	}
+	for _, tc := range cases {
+		t.Run(tc.field, func(t *testing.T) {
+			fw := MapFieldToWidget(fields.ByName(protoreflect.Name(tc.field)))
+			enum, ok := fw.Widget.(*enumSelect)
+			require.True(t, ok)
+			require.NotNil(t, enum.sel)
+			assert.Equal(t, []string{
+				"STATE_UNKNOWN (0)", "STATE_RUNNING (1)", "STATE_STARTED (1)", "STATE_DONE (2)", otherEnumOption,
+			}, enum.sel.Options, "aliases stay selectable")
+			assert.Equal(t, tc.name, enum.Name())
+			assert.Equal(t, tc.num, fw.GetValue())
+		})
+	}
+
+	fw := MapFieldToWidget(fields.ByName("region"))
+	enum, ok := fw.Widget.(*enumSelect)
+	require.True(t, ok)
+	require.NotNil(t, enum.selEntry, "searchable")
+	assert.Equal(t, "REGION_ELEVEN (11)", enum.selEntry.Text)
+	assert.Equal(t, int32(11), fw.GetValue())
+}
+
+func TestMapFieldToWidget_EnumAliases(t *testing.T) {
+	test.NewApp()
+	fw := MapFieldToWidget(enumTestMessage(t).Fields().ByName("plain"))
+	sel := fw.Widget.(*enumSelect)
+
+	// A number shows its canonical name
+	fw.SetValue(int32(1))
+	assert.Equal(t, "STATE_RUNNING", sel.Name())
+
+	// An alias picked by name survives being read back and set again
+	fw.SetValue("STATE_STARTED")
+	assert.Equal(t, "STATE_STARTED", sel.Name())
+	fw.SetValue(fw.GetValue())
+	assert.Equal(t, "STATE_STARTED", sel.Name())
+	fw.SetValue(float64(1))
+	assert.Equal(t, "STATE_STARTED", sel.Name())
+
+	// Another number moves on to its canonical name
+	fw.SetValue(int32(2))
+	assert.Equal(t, "STATE_DONE", sel.Name())
+
+	// Unknown names leave the selection alone
+	fw.SetValue("STATE_BOGUS")
+	assert.Equal(t, "STATE_DONE", sel.Name())
+
+	// The searchable widget behaves the same
+	region := MapFieldToWidget(enumTestMessage(t).Fields().ByName("region"))
+	selEntry := region.Widget.(*enumSelect).selEntry
+	region.SetValue(region.GetValue())
+	assert.Equal(t, "REGION_ELEVEN (11)", selEntry.Text)
+	region.SetValue(int32(3))
+	assert.Equal(t, "REGION_3 (3)", selEntry.Text)
+	region.SetValue(int32(11))
+	assert.Equal(t, "REGION_11 (11)", selEntry.Text)
+	assert.NoError(t, region.Validate())
+
+	// Typing a name without its number is fine
+	selEntry.SetText("REGION_4")
+	assert.Equal(t, int32(4), region.GetValue())
+	assert.NoError(t, region.Validate())
+	selEntry.SetText("REGION_BOGUS")
+	assert.Error(t, region.Validate())
+}
+
+func TestFormBuilder_EnumAliasRoundTrip(t *testing.T) {
+	test.NewApp()
+	b := NewFormBuilder(enumTestMessage(t))
+	b.Build()
+
+	sel := b.fields["plain"].Widget.(*enumSelect)
+	sel.sel.SetSelected("STATE_STARTED (1)")
+
+	// The wire carries only the number, so the text shows the canonical name
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"state":"STATE_DONE","legacyState":"STATE_RUNNING","plain":"STATE_RUNNING","region":"REGION_11"}`, got)
+
+	// Back in the form, the alias picked is kept
+	require.NoError(t, b.FromJSON(got))
+	assert.Equal(t, "STATE_STARTED", sel.Name())
+
+	// Clear returns to the declared defaults
+	b.Clear()
+	assert.Equal(t, "STATE_DONE", b.fields["state"].Widget.(*enumSelect).Name())
+	assert.Equal(t, "STATE_UNKNOWN", sel.Name())
+	assert.Equal(t, int32(1), b.fields["legacy_state"].GetValue())
+}
+
+func TestRepeatedFieldWidget_EnumAliases(t *testing.T) {
+	test.NewApp()
+	fd := enumTestMessage(t).Fields().ByName("history")
+	r := NewRepeatedFieldWidget("history", fd)
+
+	r.SetValue([]interface{}{"STATE_STARTED", float64(1), float64(2)})
+	assert.Equal(t, []interface{}{int32(1), int32(1), int32(2)}, r.GetValue())
+}
+
+func TestGenerateTemplate_EnumDefault(t *testing.T) {
+	out, err := GenerateTemplate(enumTestMessage(t), TemplateOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"state": "STATE_DONE"`)
+	assert.Contains(t, out, `"legacyState": "STATE_STARTED"`)
+	assert.Contains(t, out, `"plain": "STATE_UNKNOWN"`)
+	assert.Contains(t, out, `"region": "REGION_ELEVEN"`)
+}
+
+func TestMapFieldToWidget_EnumOtherNumber(t *testing.T) {
+	test.NewApp()
+	fw := MapFieldToWidget(enumTestMessage(t).Fields().ByName("plain"))
+	enum := fw.Widget.(*enumSelect)
+	assert.False(t, enum.other.Visible())
+
+	// Choosing Other number reveals the entry, which must hold an int32
+	enum.sel.SetSelected(otherEnumOption)
+	assert.True(t, enum.other.Visible())
+	assert.Error(t, fw.Validate(), "no number yet")
+	enum.other.SetText("99999999999")
+	assert.Error(t, fw.Validate(), "out of int32 range")
+	enum.other.SetText("999")
+	assert.NoError(t, fw.Validate())
+	assert.Equal(t, int32(999), fw.GetValue())
+	assert.Empty(t, enum.Name())
+
+	// A declared value hides it again
+	enum.sel.SetSelected("STATE_DONE (2)")
+	assert.False(t, enum.other.Visible())
+	assert.Equal(t, int32(2), fw.GetValue())
+
+	// Undeclared numbers set from JSON are shown in the entry
+	fw.SetValue(float64(-5))
+	assert.Equal(t, otherEnumOption, enum.sel.Selected)
+	assert.Equal(t, "-5", enum.other.Text)
+	assert.Equal(t, int32(-5), fw.GetValue())
+
+	// So is the searchable widget
+	region := MapFieldToWidget(enumTestMessage(t).Fields().ByName("region"))
+	region.SetValue(int32(40))
+	assert.Equal(t, otherEnumOption, region.Widget.(*enumSelect).selEntry.Text)
+	assert.Equal(t, int32(40), region.GetValue())
+	assert.NoError(t, region.Validate())
+}
+
+func TestFormBuilder_EnumUnknownNumberRoundTrip(t *testing.T) {
+	test.NewApp()
+	b := NewFormBuilder(enumTestMessage(t))
+	b.Build()
+
+	// 999 is sent as is, though State declares three numbers
+	plain := b.fields["plain"].Widget.(*enumSelect)
+	plain.sel.SetSelected(otherEnumOption)
+	plain.other.SetText("999")
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"state":"STATE_DONE","legacyState":"STATE_RUNNING","plain":999,"region":"REGION_11"}`, got)
+
+	// And comes back into the form the same
+	b.Clear()
+	require.NoError(t, b.FromJSON(`{"plain":999,"history":["STATE_DONE",999]}`))
+	assert.Equal(t, int32(999), b.fields["plain"].GetValue())
+	assert.Equal(t, "999", plain.other.Text)
+	got, err = b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"state":"STATE_DONE","legacyState":"STATE_RUNNING","plain":999,"history":["STATE_DONE",999],"region":"REGION_11"}`, got)
+}
+
+func TestRepeatedFieldWidget_EnumUnknownNumber(t *testing.T) {
+	test.NewApp()
+	r := NewRepeatedFieldWidget("history", enumTestMessage(t).Fields().ByName("history"))
+
+	r.SetValue([]interface{}{float64(999), "STATE_DONE"})
+	assert.Equal(t, []interface{}{int32(999), int32(2)}, r.GetValue())
+}
@@ -0,0 +1,147 @@
+package form
+
+import (
+	"encoding/json"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Past 2^53 a float64 can no longer hold every integer: this one reads
+// back as 9007199254740992.
+const (
+	beyondFloat = "9007199254740993"
+	maxUint64   = "18446744073709551615"
+)
+
+// int64TestMessage builds:
+//
+//	message Counters {
+//	  int64 signed = 1;
+//	  uint64 unsigned = 2;
+//	  fixed64 fixed = 3;
+//	  repeated sint64 history = 4;
+//	  map<string, uint64> totals = 5;
+//	}
+func int64TestMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	field := func(name, jsonName string, num int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name), JsonName: proto.String(jsonName), Number: proto.Int32(num), Label: opt, Type: typ.Enum(),
+		}
+	}
+	history := field("history", "history", 4, descriptorpb.FieldDescriptorProto_TYPE_SINT64)
+	history.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	totals := field("totals", "totals", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	totals.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	totals.TypeName = proto.String(".int64test.Counters.TotalsEntry")
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("int64test/counters.proto"),
+		Package: proto.String("int64test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Counters"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("signed", "signed", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field("unsigned", "unsigned", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+				field("fixed", "fixed", 3, descriptorpb.FieldDescriptorProto_TYPE_FIXED64),
+				history,
+				totals,
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name:    proto.String("TotalsEntry"),
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("key", "key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("value", "value", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+				},
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd.Messages().ByName("Counters")
+}
+
+func TestFormBuilder_Int64RoundTrip(t *testing.T) {
+	test.NewApp()
+
+	// protojson writes 64-bit integers as strings, but numbers are accepted too
+	inputs := map[string]string{
+		"strings": `{
+			"signed": "` + beyondFloat + `", "unsigned": "` + maxUint64 + `", "fixed": "` + maxUint64 + `",
+			"history": ["-` + beyondFloat + `", "` + beyondFloat + `"],
+			"totals": {"all": "` + maxUint64 + `"}
+		}`,
+		"numbers": `{
+			"signed": ` + beyondFloat + `, "unsigned": ` + maxUint64 + `, "fixed": ` + maxUint64 + `,
+			"history": [-` + beyondFloat + `, ` + beyondFloat + `],
+			"totals": {"all": ` + maxUint64 + `}
+		}`,
+	}
+	want := `{
+		"signed": "` + beyondFloat + `", "unsigned": "` + maxUint64 + `", "fixed": "` + maxUint64 + `",
+		"history": ["-` + beyondFloat + `", "` + beyondFloat + `"],
+		"totals": {"all": "` + maxUint64 + `"}
+	}`
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			b := NewFormBuilder(int64TestMessage(t))
+			b.Build()
+			require.NoError(t, b.FromJSON(input))
+
+			// The entries show every digit
+			assert.Equal(t, beyondFloat, b.fields["signed"].Widget.(*widget.Entry).Text)
+			assert.Equal(t, maxUint64, b.fields["unsigned"].Widget.(*widget.Entry).Text)
+
+			got, err := b.ToJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, want, got)
+		})
+	}
+}
+
+func TestMapFieldToWidget_Int64SetValue(t *testing.T) {
+	test.NewApp()
+	fields := int64TestMessage(t).Fields()
+
+	signed := MapFieldToWidget(fields.ByName("signed"))
+	for _, v := range []interface{}{beyondFloat, json.Number(beyondFloat), int64(9007199254740993)} {
+		signed.SetValue(v)
+		assert.Equal(t, int64(9007199254740993), signed.GetValue(), "%T", v)
+	}
+
+	unsigned := MapFieldToWidget(fields.ByName("unsigned"))
+	for _, v := range []interface{}{maxUint64, json.Number(maxUint64), uint64(18446744073709551615)} {
+		unsigned.SetValue(v)
+		assert.Equal(t, uint64(18446744073709551615), unsigned.GetValue(), "%T", v)
+	}
+
+	// Out of range values leave the entry alone
+	unsigned.SetValue("-1")
+	assert.Equal(t, uint64(18446744073709551615), unsigned.GetValue())
+}
+
+func TestInterfaceToValue_Int64(t *testing.T) {
+	fields := int64TestMessage(t).Fields()
+
+	v, err := interfaceToValue(fields.ByName("signed"), beyondFloat)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), v.Int())
+
+	v, err = interfaceToValue(fields.ByName("unsigned"), json.Number(maxUint64))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), v.Uint())
+
+	_, err = interfaceToValue(fields.ByName("unsigned"), "not a number")
+	assert.Error(t, err)
+}
@@ -1,21 +1,17 @@
 package form
 
 import (
-	"encoding/base64"
 	"fmt"
-	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-// mapSearchableEnumThreshold matches the threshold in mapper.go for consistency.
-const mapSearchableEnumThreshold = 10
-
 // MapFieldWidget displays a map with add/remove key-value pairs
 type MapFieldWidget struct {
 	widget.BaseWidget
@@ -69,7 +65,7 @@ func NewMapFieldWidget(name string, fd protoreflect.FieldDescriptor) *MapFieldWi
 	// Main container with label, list, and add button.
 	// Items grow naturally inside the VBox; the outer form VScroll handles scrolling.
 	m.container = container.NewBorder(
-		fieldLabel(formatFieldLabel(name), mapTypeHint(fd)),
+		fieldLabel(formatFieldLabel(name), mapTypeHint(fd), protoconv.IsFieldDeprecated(fd)),
 		m.addButton,
 		nil,
 		nil,
@@ -197,6 +193,33 @@ func (m *MapFieldWidget) SetValue(v interface{}) {
 	}
 }
 
+// FieldErrors validates well-known type and nested message values, naming
+// each failure by its key under path (e.g. "deadlines[build]").
+func (m *MapFieldWidget) FieldErrors(path string) []protoconv.FieldError {
+	var errs []protoconv.FieldError
+	for _, item := range m.items {
+		border, ok := item.(*fyne.Container)
+		if !ok || len(border.Objects) == 0 {
+			continue
+		}
+		grid, ok := border.Objects[0].(*fyne.Container)
+		if !ok || len(grid.Objects) < 2 {
+			continue
+		}
+		key := m.extractWidgetValue(grid.Objects[0], m.keyDesc)
+		itemPath := fmt.Sprintf("%s[%v]", path, key)
+		switch w := grid.Objects[1].(type) {
+		case *wellKnownItem:
+			if err := w.field.Validate(); err != nil {
+				errs = append(errs, protoconv.FieldError{Path: itemPath, Message: err.Error()})
+			}
+		case *NestedMessageWidget:
+			errs = append(errs, w.GetBuilder().FieldErrors(itemPath)...)
+		}
+	}
+	return errs
+}
+
 // OnAdd sets a callback for when entries are added
 func (m *MapFieldWidget) OnAdd(callback func()) {
 	m.onAdd = callback
@@ -268,52 +291,7 @@ func (m *MapFieldWidget) createValueWidget() fyne.CanvasObject {
 	case protoreflect.BoolKind:
 		return widget.NewCheck("", nil)
 	case protoreflect.EnumKind:
-		options := make([]string, 0)
-		enumValues := m.valueDesc.Enum().Values()
-		for i := 0; i < enumValues.Len(); i++ {
-			options = append(options, string(enumValues.Get(i).Name()))
-		}
-		if len(options) > mapSearchableEnumThreshold {
-			selEntry := widget.NewSelectEntry(options)
-			selEntry.Wrapping = fyne.TextWrapOff
-			selEntry.Scroll = container.ScrollNone
-			selEntry.SetPlaceHolder("Type to filter...")
-			allOptions := options
-			selEntry.OnChanged = func(text string) {
-				if text == "" {
-					selEntry.SetOptions(allOptions)
-					return
-				}
-				lower := strings.ToLower(text)
-				filtered := make([]string, 0)
-				for _, opt := range allOptions {
-					if strings.Contains(strings.ToLower(opt), lower) {
-						filtered = append(filtered, opt)
-					}
-				}
-				selEntry.SetOptions(filtered)
-			}
-			selEntry.Validator = func(s string) error {
-				if s == "" {
-					return nil
-				}
-				for i := 0; i < enumValues.Len(); i++ {
-					if string(enumValues.Get(i).Name()) == s {
-						return nil
-					}
-				}
-				return fmt.Errorf("unknown enum value: %s", s)
-			}
-			if len(options) > 0 {
-				selEntry.SetText(options[0])
-			}
-			return selEntry
-		}
-		sel := widget.NewSelect(options, nil)
-		if len(options) > 0 {
-			sel.SetSelected(options[0])
-		}
-		return sel
+		return newEnumSelect(m.valueDesc.Enum(), enumDefaultName(m.valueDesc))
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
 		entry := newSignedIntEntry()
 		entry.SetPlaceHolder("0")
@@ -379,17 +357,11 @@ func (m *MapFieldWidget) createValueWidget() fyne.CanvasObject {
 		entry.SetPlaceHolder("value")
 		return entry
 	case protoreflect.BytesKind:
-		entry := newFormEntry()
-		entry.SetPlaceHolder("Base64 encoded bytes")
-		entry.Validator = func(s string) error {
-			if s == "" {
-				return nil
-			}
-			_, err := base64.StdEncoding.DecodeString(s)
-			return err
-		}
-		return entry
+		return NewBytesEntry()
 	case protoreflect.MessageKind:
+		if protoconv.IsSupported(m.valueDesc.Message().FullName()) {
+			return newWellKnownItem(m.valueDesc.Message())
+		}
 		nestedWidget := NewNestedMessageWidget(
 			"Value",
 			m.valueDesc.Message(),
@@ -411,26 +383,14 @@ func (m *MapFieldWidget) extractWidgetValue(w fyne.CanvasObject, fd protoreflect
 		if entry, ok := w.(*widget.Entry); ok {
 			return entry.Text
 		}
+	case protoreflect.BytesKind:
+		if be, ok := w.(*BytesEntry); ok {
+			return be.Bytes()
+		}
 	case protoreflect.EnumKind:
-		if sel, ok := w.(*widget.Select); ok {
-			enumValues := fd.Enum().Values()
-			for i := 0; i < enumValues.Len(); i++ {
-				val := enumValues.Get(i)
-				if string(val.Name()) == sel.Selected {
-					return int32(val.Number())
-				}
-			}
-			return int32(0)
-		}
-		if selEntry, ok := w.(*widget.SelectEntry); ok {
-			enumValues := fd.Enum().Values()
-			for i := 0; i < enumValues.Len(); i++ {
-				val := enumValues.Get(i)
-				if string(val.Name()) == selEntry.Text {
-					return int32(val.Number())
-				}
-			}
-			return int32(0)
+		if enum, ok := w.(*enumSelect); ok {
+			num, _ := enum.Number()
+			return num
 		}
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
 		if entry, ok := w.(*widget.Entry); ok {
@@ -490,6 +450,9 @@ func (m *MapFieldWidget) extractWidgetValue(w fyne.CanvasObject, fd protoreflect
 		if nmw, ok := w.(*NestedMessageWidget); ok {
 			return nmw.GetValue()
 		}
+		if wk, ok := w.(*wellKnownItem); ok {
+			return wk.field.GetValue()
+		}
 	}
 
 	return nil
@@ -510,44 +473,36 @@ func (m *MapFieldWidget) setWidgetValue(w fyne.CanvasObject, value interface{},
 				entry.SetText(s)
 			}
 		}
-	case protoreflect.EnumKind:
-		var enumNum int32
-		switch v := value.(type) {
-		case int32:
-			enumNum = v
-		case float64:
-			enumNum = int32(v)
-		}
-		enumValues := fd.Enum().Values()
-		if sel, ok := w.(*widget.Select); ok {
-			for i := 0; i < enumValues.Len(); i++ {
-				val := enumValues.Get(i)
-				if int32(val.Number()) == enumNum {
-					sel.SetSelected(string(val.Name()))
-					break
-				}
-			}
-		} else if selEntry, ok := w.(*widget.SelectEntry); ok {
-			for i := 0; i < enumValues.Len(); i++ {
-				val := enumValues.Get(i)
-				if int32(val.Number()) == enumNum {
-					selEntry.SetText(string(val.Name()))
-					break
-				}
+	case protoreflect.BytesKind:
+		if be, ok := w.(*BytesEntry); ok {
+			switch b := value.(type) {
+			case []byte:
+				be.SetBytes(b)
+			case string:
+				be.SetText(b)
 			}
 		}
+	case protoreflect.EnumKind:
+		if enum, ok := w.(*enumSelect); ok {
+			enum.SetValue(value)
+		}
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
 		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
 		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
 		protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
 		protoreflect.FloatKind, protoreflect.DoubleKind:
 		if entry, ok := w.(*widget.Entry); ok {
-			entry.SetText(fmt.Sprintf("%v", value))
+			if text, ok := numberText(value); ok {
+				entry.SetText(text)
+			}
 		}
 	case protoreflect.MessageKind:
 		if nmw, ok := w.(*NestedMessageWidget); ok {
 			nmw.SetValue(value)
 		}
+		if wk, ok := w.(*wellKnownItem); ok {
+			wk.field.SetValue(value)
+		}
 	}
 }
 
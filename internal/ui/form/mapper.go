@@ -1,15 +1,14 @@
 package form
 
 import (
-	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -19,6 +18,7 @@ type FieldWidget struct {
 	Label      string
 	Widget     fyne.CanvasObject
 	Descriptor protoreflect.FieldDescriptor
+	Deprecated bool // Field or its message type is marked deprecated
 
 	// Value getters/setters
 	GetValue func() interface{}
@@ -46,6 +46,7 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 		Name:       name,
 		Label:      label,
 		Descriptor: fd,
+		Deprecated: protoconv.IsFieldDeprecated(fd),
 	}
 
 	// Map proto type to widget
@@ -62,121 +63,17 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 		fw.Validate = func() error { return nil }
 
 	case protoreflect.EnumKind:
-		// Build enum options
-		enumDesc := fd.Enum()
-		values := enumDesc.Values()
-		options := make([]string, values.Len())
-		for i := 0; i < values.Len(); i++ {
-			val := values.Get(i)
-			options[i] = string(val.Name())
-		}
-
-		const searchableEnumThreshold = 10
-
-		if len(options) > searchableEnumThreshold {
-			// Large enum: use SelectEntry with type-to-filter
-			selEntry := widget.NewSelectEntry(options)
-			selEntry.Wrapping = fyne.TextWrapOff
-			selEntry.Scroll = container.ScrollNone
-			selEntry.SetPlaceHolder("Type to filter...")
-			allOptions := options // capture full list for filtering
-			selEntry.OnChanged = func(text string) {
-				if text == "" {
-					selEntry.SetOptions(allOptions)
-					return
-				}
-				lower := strings.ToLower(text)
-				filtered := make([]string, 0)
-				for _, opt := range allOptions {
-					if strings.Contains(strings.ToLower(opt), lower) {
-						filtered = append(filtered, opt)
-					}
-				}
-				selEntry.SetOptions(filtered)
-			}
-			selEntry.Validator = func(s string) error {
-				if s == "" {
-					return nil
-				}
-				for i := 0; i < values.Len(); i++ {
-					if string(values.Get(i).Name()) == s {
-						return nil
-					}
-				}
-				return fmt.Errorf("unknown enum value: %s", s)
-			}
-			if len(options) > 0 {
-				selEntry.SetText(options[0])
-			}
-			fw.Widget = selEntry
-			fw.GetValue = func() interface{} {
-				for i := 0; i < values.Len(); i++ {
-					val := values.Get(i)
-					if string(val.Name()) == selEntry.Text {
-						return int32(val.Number())
-					}
-				}
-				return int32(0)
-			}
-			fw.SetValue = func(v interface{}) {
-				var enumNum int32
-				switch t := v.(type) {
-				case int32:
-					enumNum = t
-				case int:
-					enumNum = int32(t)
-				default:
-					return
-				}
-				for i := 0; i < values.Len(); i++ {
-					val := values.Get(i)
-					if val.Number() == protoreflect.EnumNumber(enumNum) {
-						selEntry.SetText(string(val.Name()))
-						return
-					}
-				}
-			}
-			fw.Validate = func() error { return selEntry.Validate() }
-		} else {
-			// Small enum: use plain Select
-			sel := widget.NewSelect(options, nil)
-			if len(options) > 0 {
-				sel.SetSelected(options[0]) // Default to first enum value
-			}
-
-			fw.Widget = sel
-			fw.GetValue = func() interface{} {
-				// Return enum number
-				for i := 0; i < values.Len(); i++ {
-					val := values.Get(i)
-					if string(val.Name()) == sel.Selected {
-						return int32(val.Number())
-					}
-				}
-				return int32(0)
-			}
-			fw.SetValue = func(v interface{}) {
-				var enumNum int32
-				switch t := v.(type) {
-				case int32:
-					enumNum = t
-				case int:
-					enumNum = int32(t)
-				default:
-					return
-				}
-
-				// Find enum name by number
-				for i := 0; i < values.Len(); i++ {
-					val := values.Get(i)
-					if val.Number() == protoreflect.EnumNumber(enumNum) {
-						sel.SetSelected(string(val.Name()))
-						return
-					}
-				}
-			}
-			fw.Validate = func() error { return nil }
+		// Values are listed with their numbers, aliases under their own
+		// names, and any other number can be entered
+		enum := newEnumSelect(fd.Enum(), enumDefaultName(fd))
+		fw.Widget = enum
+		fw.GetValue = func() interface{} {
+			// Return enum number
+			num, _ := enum.Number()
+			return num
 		}
+		fw.SetValue = enum.SetValue
+		fw.Validate = enum.Validate
 
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
 		entry := newSignedIntEntry()
@@ -196,8 +93,8 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 			return int32(val)
 		}
 		fw.SetValue = func(v interface{}) {
-			if num, ok := v.(int32); ok {
-				entry.SetText(strconv.FormatInt(int64(num), 10))
+			if num, err := numericValue(v, fd); err == nil {
+				entry.SetText(strconv.FormatInt(int64(num.(int32)), 10))
 			}
 		}
 		fw.Validate = func() error {
@@ -222,8 +119,9 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 			return val
 		}
 		fw.SetValue = func(v interface{}) {
-			if num, ok := v.(int64); ok {
-				entry.SetText(strconv.FormatInt(num, 10))
+			// Strings and json.Numbers keep values past 2^53 exact
+			if num, err := numericValue(v, fd); err == nil {
+				entry.SetText(strconv.FormatInt(num.(int64), 10))
 			}
 		}
 		fw.Validate = func() error {
@@ -248,8 +146,8 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 			return uint32(val)
 		}
 		fw.SetValue = func(v interface{}) {
-			if num, ok := v.(uint32); ok {
-				entry.SetText(strconv.FormatUint(uint64(num), 10))
+			if num, err := numericValue(v, fd); err == nil {
+				entry.SetText(strconv.FormatUint(uint64(num.(uint32)), 10))
 			}
 		}
 		fw.Validate = func() error {
@@ -274,8 +172,8 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 			return val
 		}
 		fw.SetValue = func(v interface{}) {
-			if num, ok := v.(uint64); ok {
-				entry.SetText(strconv.FormatUint(num, 10))
+			if num, err := numericValue(v, fd); err == nil {
+				entry.SetText(strconv.FormatUint(num.(uint64), 10))
 			}
 		}
 		fw.Validate = func() error {
@@ -300,8 +198,8 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 			return float32(val)
 		}
 		fw.SetValue = func(v interface{}) {
-			if num, ok := v.(float32); ok {
-				entry.SetText(strconv.FormatFloat(float64(num), 'g', -1, 32))
+			if num, err := numericValue(v, fd); err == nil {
+				entry.SetText(strconv.FormatFloat(float64(num.(float32)), 'g', -1, 32))
 			}
 		}
 		fw.Validate = func() error {
@@ -326,8 +224,8 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 			return val
 		}
 		fw.SetValue = func(v interface{}) {
-			if num, ok := v.(float64); ok {
-				entry.SetText(strconv.FormatFloat(num, 'g', -1, 64))
+			if num, err := numericValue(v, fd); err == nil {
+				entry.SetText(strconv.FormatFloat(num.(float64), 'g', -1, 64))
 			}
 		}
 		fw.Validate = func() error {
@@ -347,32 +245,18 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 		fw.Validate = func() error { return nil }
 
 	case protoreflect.BytesKind:
-		// Entry with base64 encoding hint
-		entry := newFormEntry()
-		entry.SetPlaceHolder("Base64 encoded bytes")
-		entry.Validator = func(s string) error {
-			if s == "" {
-				return nil
-			}
-			_, err := base64.StdEncoding.DecodeString(s)
-			return err
-		}
+		// Entry accepting standard or URL-safe base64
+		entry := NewBytesEntry()
 		fw.Widget = entry
 		fw.GetValue = func() interface{} {
-			if entry.Text == "" {
-				return []byte{}
-			}
-			// Try to decode base64
-			data, err := base64.StdEncoding.DecodeString(entry.Text)
-			if err != nil {
-				// If not valid base64, treat as raw string
-				return []byte(entry.Text)
-			}
-			return data
+			return entry.Bytes()
 		}
 		fw.SetValue = func(v interface{}) {
-			if b, ok := v.([]byte); ok {
-				entry.SetText(base64.StdEncoding.EncodeToString(b))
+			switch b := v.(type) {
+			case []byte:
+				entry.SetBytes(b)
+			case string:
+				entry.SetText(b)
 			}
 		}
 		fw.Validate = func() error {
@@ -380,103 +264,8 @@ func MapFieldToWidget(fd protoreflect.FieldDescriptor) *FieldWidget {
 		}
 
 	case protoreflect.MessageKind:
-		// Handle well-known types
-		msgType := fd.Message().FullName()
-		switch msgType {
-		case "google.protobuf.Timestamp":
-			entry := newFormEntry()
-			entry.SetPlaceHolder("RFC3339 format (e.g., 2024-01-15T10:30:00Z)")
-			entry.Validator = func(s string) error {
-				if s == "" {
-					return nil
-				}
-				_, err := time.Parse(time.RFC3339, s)
-				if err != nil {
-					return fmt.Errorf("invalid timestamp: use RFC3339 format")
-				}
-				return nil
-			}
-			fw.Widget = entry
-			fw.GetValue = func() interface{} { return entry.Text }
-			fw.SetValue = func(v interface{}) {
-				if s, ok := v.(string); ok {
-					entry.SetText(s)
-				}
-			}
-			fw.Validate = func() error { return entry.Validate() }
-
-		case "google.protobuf.Duration":
-			entry := newFormEntry()
-			entry.SetPlaceHolder("Duration format (e.g., 5m30s)")
-			entry.Validator = func(s string) error {
-				if s == "" {
-					return nil
-				}
-				_, err := time.ParseDuration(s)
-				if err != nil {
-					return fmt.Errorf("invalid duration: use Go duration format (e.g., 5m30s)")
-				}
-				return nil
-			}
-			fw.Widget = entry
-			fw.GetValue = func() interface{} { return entry.Text }
-			fw.SetValue = func(v interface{}) {
-				if s, ok := v.(string); ok {
-					entry.SetText(s)
-				}
-			}
-			fw.Validate = func() error { return entry.Validate() }
-
-		case "google.protobuf.FieldMask":
-			entry := widget.NewMultiLineEntry()
-			entry.SetPlaceHolder("Field paths (one per line or comma-separated)")
-			fw.Widget = entry
-			fw.GetValue = func() interface{} {
-				text := strings.TrimSpace(entry.Text)
-				if text == "" {
-					return []string{}
-				}
-				// Support both newline and comma-separated formats
-				var paths []string
-				if strings.Contains(text, "\n") {
-					// Multi-line format: one path per line
-					lines := strings.Split(text, "\n")
-					for _, line := range lines {
-						line = strings.TrimSpace(line)
-						if line != "" {
-							paths = append(paths, line)
-						}
-					}
-				} else {
-					// Comma-separated format
-					parts := strings.Split(text, ",")
-					for _, part := range parts {
-						part = strings.TrimSpace(part)
-						if part != "" {
-							paths = append(paths, part)
-						}
-					}
-				}
-				return paths
-			}
-			fw.SetValue = func(v interface{}) {
-				// Accept either []string or string
-				switch val := v.(type) {
-				case []string:
-					if len(val) == 0 {
-						entry.SetText("")
-					} else {
-						// Format as one path per line
-						entry.SetText(strings.Join(val, "\n"))
-					}
-				case string:
-					entry.SetText(val)
-				}
-			}
-			fw.Validate = func() error { return nil }
-
-		default:
-			// Unknown message type - should be handled by builder
+		// Well-known types with a string form; other messages are handled by builder
+		if !setupWellKnownField(fw, fd.Message()) {
 			return nil
 		}
 
@@ -1,16 +1,102 @@
 package form
 
 import (
+	"fmt"
 	"image/color"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"github.com/shhac/grotto/internal/ui/components"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// lazyForm holds the form for a nested message. When the message type
+// already encloses it, or the nesting is too deep, the form is not built
+// up front: an "Add <Type>..." button stands in for it and builds one more
+// level when clicked. This keeps recursive schemas from expanding forever.
+type lazyForm struct {
+	parent  *FormBuilder // Enclosing builder, or nil for a standalone form
+	md      protoreflect.MessageDescriptor
+	builder *FormBuilder // nil until built
+	content *fyne.Container
+	onBuilt func()
+}
+
+// newLazyForm creates the form for md nested under parent, building it
+// immediately unless parent says to defer it.
+func newLazyForm(parent *FormBuilder, md protoreflect.MessageDescriptor) *lazyForm {
+	l := &lazyForm{parent: parent, md: md, content: container.NewStack()}
+	if parent != nil && parent.shouldDefer(md) {
+		addBtn := widget.NewButton(fmt.Sprintf("Add %s...", md.Name()), l.build)
+		addBtn.Importance = widget.LowImportance
+		l.content.Objects = []fyne.CanvasObject{container.NewHBox(addBtn)}
+	} else {
+		l.build()
+	}
+	return l
+}
+
+// build creates the nested form, if it does not exist yet.
+func (l *lazyForm) build() {
+	if l.builder != nil {
+		return
+	}
+	if l.parent != nil {
+		l.builder = l.parent.childBuilder(l.md)
+	} else {
+		l.builder = NewFormBuilder(l.md)
+	}
+
+	// Wrap nested content with left padding for visual depth cue.
+	// Since nesting is recursive, each level auto-compounds the indent.
+	leftPad := canvas.NewRectangle(color.Transparent)
+	leftPad.SetMinSize(fyne.NewSize(12, 0))
+	l.content.Objects = []fyne.CanvasObject{
+		container.NewBorder(nil, nil, leftPad, nil, l.builder.BuildContent()),
+	}
+	l.content.Refresh()
+	if l.onBuilt != nil {
+		l.onBuilt()
+	}
+}
+
+// values returns the form values, or an empty map if it was never built.
+func (l *lazyForm) values() map[string]interface{} {
+	if l.builder == nil {
+		return map[string]interface{}{}
+	}
+	return l.builder.GetValues()
+}
+
+// setValues populates the form, building it first if there is anything to set.
+func (l *lazyForm) setValues(values map[string]interface{}) {
+	if l.builder == nil {
+		if len(values) == 0 {
+			return
+		}
+		l.build()
+	}
+	l.builder.SetValues(values)
+}
+
+// clear resets the form if it was built.
+func (l *lazyForm) clear() {
+	if l.builder != nil {
+		l.builder.Clear()
+	}
+}
+
+// fieldErrors validates the form if it was built.
+func (l *lazyForm) fieldErrors(prefix string) []protoconv.FieldError {
+	if l.builder == nil {
+		return nil
+	}
+	return l.builder.FieldErrors(prefix)
+}
+
 // NestedMessageWidget displays a nested message as an expandable section
 type NestedMessageWidget struct {
 	widget.BaseWidget
@@ -18,30 +104,31 @@ type NestedMessageWidget struct {
 	name      string
 	md        protoreflect.MessageDescriptor
 	expanded  bool
-	builder   *FormBuilder // Nested form builder
+	form      *lazyForm // Nested form, built on demand for recursive types
 	container fyne.CanvasObject
 	section   *components.TreeSection
 }
 
 // NewNestedMessageWidget creates an expandable nested message widget
 func NewNestedMessageWidget(name string, md protoreflect.MessageDescriptor) *NestedMessageWidget {
+	return newNestedMessageWidget(name, md, nil, protoconv.IsDeprecated(md))
+}
+
+// newNestedMessageWidget creates a nested message widget under parent,
+// which decides whether the form is built now or on request.
+func newNestedMessageWidget(name string, md protoreflect.MessageDescriptor, parent *FormBuilder, deprecated bool) *NestedMessageWidget {
 	n := &NestedMessageWidget{
 		name: name,
 		md:   md,
 	}
 
-	// Create nested form builder
-	n.builder = NewFormBuilder(md)
-
-	// Wrap nested content with left padding for visual depth cue.
-	// Since nesting is recursive, each level auto-compounds the indent.
-	leftPad := canvas.NewRectangle(color.Transparent)
-	leftPad.SetMinSize(fyne.NewSize(12, 0))
-	indentedContent := container.NewBorder(nil, nil, leftPad, nil, n.builder.BuildContent())
+	n.form = newLazyForm(parent, md)
+	n.form.onBuilt = func() { n.SetExpanded(true) }
 
 	// Create tree-style collapsible section with ▶/▼ disclosure icons and type hint
 	n.section = components.NewCollapsibleSectionWithHint(
-		formatFieldLabel(name), string(md.Name()), indentedContent,
+		formatFieldLabel(name), string(md.Name()), n.form.content,
+		deprecatedBadges(deprecated)...,
 	)
 
 	n.container = n.section
@@ -67,21 +154,14 @@ func (n *NestedMessageWidget) Toggle() {
 
 // GetValue returns the nested message values as a map
 func (n *NestedMessageWidget) GetValue() interface{} {
-	if n.builder == nil {
-		return nil
-	}
-	return n.builder.GetValues()
+	return n.form.values()
 }
 
 // SetValue populates nested fields from a map or protoreflect.Message
 func (n *NestedMessageWidget) SetValue(v interface{}) {
-	if n.builder == nil {
-		return
-	}
-
 	switch val := v.(type) {
 	case map[string]interface{}:
-		n.builder.SetValues(val)
+		n.form.setValues(val)
 	case protoreflect.Message:
 		// Convert protoreflect.Message to map
 		values := make(map[string]interface{})
@@ -89,7 +169,7 @@ func (n *NestedMessageWidget) SetValue(v interface{}) {
 			values[string(fd.Name())] = v.Interface()
 			return true
 		})
-		n.builder.SetValues(values)
+		n.form.setValues(values)
 	}
 }
 
@@ -103,7 +183,8 @@ func (n *NestedMessageWidget) SetExpanded(expanded bool) {
 	}
 }
 
-// GetBuilder returns the nested form builder for advanced access
+// GetBuilder returns the nested form builder for advanced access, or nil
+// if a recursive message has not been expanded yet
 func (n *NestedMessageWidget) GetBuilder() *FormBuilder {
-	return n.builder
+	return n.form.builder
 }
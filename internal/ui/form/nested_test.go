@@ -0,0 +1,210 @@
+package form
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// recursiveFile builds a file with self-referencing and deep messages:
+//
+//	message Node {
+//	  string name = 1;
+//	  Node child = 2;
+//	  repeated Node children = 3;
+//	  optional Node next = 4;
+//	  oneof kind { string leaf = 5; Node branch = 6; }
+//	  Ping ping = 7;
+//	}
+//	message Ping { Pong pong = 1; }
+//	message Pong { Ping ping = 1; }
+//	message Level0 { Level1 next = 1; } ... message Level7 { string name = 1; }
+func recursiveFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+
+	children := field("children", 3, msg, ".recursivetest.Node")
+	children.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	next := field("next", 4, msg, ".recursivetest.Node")
+	next.OneofIndex = proto.Int32(1)
+	next.Proto3Optional = proto.Bool(true)
+	leaf := field("leaf", 5, str, "")
+	leaf.OneofIndex = proto.Int32(0)
+	branch := field("branch", 6, msg, ".recursivetest.Node")
+	branch.OneofIndex = proto.Int32(0)
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("recursive_test.proto"),
+		Package: proto.String("recursivetest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Node"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, str, ""),
+					field("child", 2, msg, ".recursivetest.Node"),
+					children, next, leaf, branch,
+					field("ping", 7, msg, ".recursivetest.Ping"),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("kind")},
+					{Name: proto.String("_next")},
+				},
+			},
+			{Name: proto.String("Ping"), Field: []*descriptorpb.FieldDescriptorProto{field("pong", 1, msg, ".recursivetest.Pong")}},
+			{Name: proto.String("Pong"), Field: []*descriptorpb.FieldDescriptorProto{field("ping", 1, msg, ".recursivetest.Ping")}},
+		},
+	}
+	for i := 0; i < 7; i++ {
+		fdp.MessageType = append(fdp.MessageType, &descriptorpb.DescriptorProto{
+			Name:  proto.String(fmt.Sprintf("Level%d", i)),
+			Field: []*descriptorpb.FieldDescriptorProto{field("next", 1, msg, fmt.Sprintf(".recursivetest.Level%d", i+1))},
+		})
+	}
+	fdp.MessageType = append(fdp.MessageType, &descriptorpb.DescriptorProto{
+		Name:  proto.String("Level7"),
+		Field: []*descriptorpb.FieldDescriptorProto{field("name", 1, str, "")},
+	})
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd
+}
+
+// addButton returns the "Add <Type>..." button standing in for an
+// unexpanded form, or nil if the form is built.
+func addButton(l *lazyForm) *widget.Button {
+	if l.builder != nil {
+		return nil
+	}
+	box := l.content.Objects[0].(*fyne.Container)
+	return box.Objects[0].(*widget.Button)
+}
+
+func TestFormBuilder_RecursiveMessageBuilds(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	file := recursiveFile(t)
+	b := NewFormBuilder(file.Messages().ByName("Node"))
+
+	start := time.Now()
+	b.Build()
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Self-references stop at the first repeat
+	child := b.nestedFields["child"]
+	require.NotNil(t, child)
+	assert.Nil(t, child.GetBuilder())
+	btn := addButton(child.form)
+	require.NotNil(t, btn)
+	assert.Equal(t, "Add Node...", btn.Text)
+
+	// Indirect cycles stop where a type repeats: Node > Ping > Pong > (Ping)
+	ping := b.nestedFields["ping"].GetBuilder()
+	require.NotNil(t, ping)
+	pong := ping.nestedFields["pong"].GetBuilder()
+	require.NotNil(t, pong)
+	assert.Nil(t, pong.nestedFields["ping"].GetBuilder())
+
+	// Clicking expands exactly one more level
+	test.Tap(btn)
+	inner := child.GetBuilder()
+	require.NotNil(t, inner)
+	assert.True(t, child.section.IsExpanded())
+	assert.Nil(t, inner.nestedFields["child"].GetBuilder())
+
+	// Recursive oneof members and optionals are deferred too
+	branch := b.oneofFields["kind"].fields["branch"].widget.(*fyne.Container)
+	branchBtn := branch.Objects[0].(*fyne.Container).Objects[0].(*widget.Button)
+	assert.Equal(t, "Add Node...", branchBtn.Text)
+	next := b.optionalFields["next"]
+	require.NotNil(t, next)
+	next.SetEnabled(true)
+	out, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"leaf": "", "child": {"leaf": ""}, "next": {"leaf": ""}}`, out)
+}
+
+func TestFormBuilder_RecursiveMessageFromJSON(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder(recursiveFile(t).Messages().ByName("Node"))
+	b.Build()
+
+	// The kind oneof always emits its selected member, so leaf is set
+	// wherever branch is not
+	in := `{
+		"name": "root", "leaf": "",
+		"child": {"name": "a", "leaf": "", "child": {"name": "b", "leaf": "", "child": {"name": "c", "leaf": ""}}},
+		"next": {"name": "n", "branch": {"leaf": "deep"}},
+		"children": [{"name": "x", "leaf": "", "child": {"name": "y", "leaf": ""}}]
+	}`
+	require.NoError(t, b.FromJSON(in))
+	out, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, in, out)
+
+	// Clearing empties the expanded levels
+	b.Clear()
+	out, err = b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"leaf": "", "child": {"leaf": "", "child": {"leaf": "", "child": {"leaf": ""}}}}`, out)
+}
+
+func TestFormBuilder_MaxDepth(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	file := recursiveFile(t)
+	depthOf := func(b *FormBuilder) int {
+		depth := 0
+		for {
+			next, ok := b.nestedFields["next"]
+			if !ok || next.GetBuilder() == nil {
+				return depth
+			}
+			b = next.GetBuilder()
+			depth++
+		}
+	}
+
+	b := NewFormBuilder(file.Messages().ByName("Level0"))
+	b.Build()
+	assert.Equal(t, DefaultMaxDepth-1, depthOf(b), "levels below the root")
+
+	b = NewFormBuilder(file.Messages().ByName("Level0"))
+	b.SetMaxDepth(2)
+	b.Build()
+	assert.Equal(t, 1, depthOf(b))
+
+	// Deferred levels still take values from JSON
+	require.NoError(t, b.FromJSON(`{"next": {"next": {"next": {"next": {}}}}}`))
+	assert.Equal(t, 3, depthOf(b))
+}
@@ -1,12 +1,15 @@
 package form
 
 import (
-	"image/color"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -23,6 +26,7 @@ type OneofWidget struct {
 
 	name        string
 	oneof       protoreflect.OneofDescriptor
+	parent      *FormBuilder // Enclosing form, for message members
 	selector    *widget.Select
 	fields      map[string]*oneofMember
 	container   *fyne.Container
@@ -31,9 +35,16 @@ type OneofWidget struct {
 
 // NewOneofWidget creates a new oneof selector widget
 func NewOneofWidget(name string, od protoreflect.OneofDescriptor) *OneofWidget {
+	return newOneofWidget(name, od, nil)
+}
+
+// newOneofWidget creates a oneof selector under parent, which decides
+// whether recursive message members are built now or on request.
+func newOneofWidget(name string, od protoreflect.OneofDescriptor, parent *FormBuilder) *OneofWidget {
 	w := &OneofWidget{
 		name:   name,
 		oneof:  od,
+		parent: parent,
 		fields: make(map[string]*oneofMember),
 	}
 
@@ -43,33 +54,8 @@ func NewOneofWidget(name string, od protoreflect.OneofDescriptor) *OneofWidget {
 		fd := od.Fields().Get(i)
 		fieldName := string(fd.Name())
 		fieldNames = append(fieldNames, fieldName)
-
-		if fd.Kind() == protoreflect.MessageKind && !isWellKnownType(fd) {
-			// Nested message: create a form builder with indented content
-			builder := NewFormBuilder(fd.Message())
-			leftPad := canvas.NewRectangle(color.Transparent)
-			leftPad.SetMinSize(fyne.NewSize(12, 0))
-			indented := container.NewBorder(nil, nil, leftPad, nil, builder.BuildContent())
-
-			w.fields[fieldName] = &oneofMember{
-				widget:   indented,
-				getValue: func() interface{} { return builder.GetValues() },
-				setValue: func(v interface{}) {
-					if m, ok := v.(map[string]interface{}); ok {
-						builder.SetValues(m)
-					}
-				},
-			}
-		} else {
-			// Scalar, enum, or well-known type
-			fieldWidget := MapFieldToWidget(fd)
-			if fieldWidget != nil {
-				w.fields[fieldName] = &oneofMember{
-					widget:   fieldWidget.Widget,
-					getValue: fieldWidget.GetValue,
-					setValue: fieldWidget.SetValue,
-				}
-			}
+		if member := newOneofMember(fd, parent); member != nil {
+			w.fields[fieldName] = member
 		}
 	}
 
@@ -99,17 +85,65 @@ func NewOneofWidget(name string, od protoreflect.OneofDescriptor) *OneofWidget {
 	return w
 }
 
-// onFieldSelected handles field selection changes
+// newOneofMember creates the input for one oneof field, or nil if the field
+// type has no widget.
+func newOneofMember(fd protoreflect.FieldDescriptor, parent *FormBuilder) *oneofMember {
+	if fd.Kind() == protoreflect.MessageKind && !isWellKnownType(fd) {
+		// Nested message: indented form, deferred for recursive types
+		form := newLazyForm(parent, fd.Message())
+		return &oneofMember{
+			widget:   form.content,
+			getValue: func() interface{} { return form.values() },
+			setValue: func(v interface{}) {
+				if m, ok := v.(map[string]interface{}); ok {
+					form.setValues(m)
+				}
+			},
+		}
+	}
+
+	// Scalar, enum, or well-known type
+	fieldWidget := MapFieldToWidget(fd)
+	if fieldWidget == nil {
+		return nil
+	}
+	return &oneofMember{
+		widget:   fieldWidget.Widget,
+		getValue: fieldWidget.GetValue,
+		setValue: fieldWidget.SetValue,
+	}
+}
+
+// resetMember replaces a field's input with a fresh one, discarding
+// whatever was entered so it cannot resurface when the field is reselected.
+func (o *OneofWidget) resetMember(fieldName string) {
+	fd := o.oneof.Fields().ByName(protoreflect.Name(fieldName))
+	if fd == nil {
+		return
+	}
+	if member := newOneofMember(fd, o.parent); member != nil {
+		o.fields[fieldName] = member
+	}
+}
+
+// onFieldSelected handles field selection changes. The previously selected
+// field is reset, since a oneof holds at most one value.
 func (o *OneofWidget) onFieldSelected(fieldName string) {
 	if fieldName == o.activeField {
 		return
 	}
 
+	if o.activeField != "" {
+		o.resetMember(o.activeField)
+	}
 	o.activeField = fieldName
+	o.showActive()
+}
 
-	// Update container to show only the selected field
+// showActive updates the container to show only the selected field.
+func (o *OneofWidget) showActive() {
 	o.container.Objects = []fyne.CanvasObject{}
-	if member, ok := o.fields[fieldName]; ok {
+	if member, ok := o.fields[o.activeField]; ok {
 		o.container.Objects = []fyne.CanvasObject{member.widget}
 	}
 	o.container.Refresh()
@@ -120,7 +154,8 @@ func (o *OneofWidget) GetSelectedField() string {
 	return o.activeField
 }
 
-// GetValue returns the value of the selected field as a map
+// GetValue returns the value of the selected field as a single-entry map.
+// Other fields of the oneof are never included.
 func (o *OneofWidget) GetValue() interface{} {
 	if o.activeField == "" {
 		return nil
@@ -137,23 +172,26 @@ func (o *OneofWidget) GetValue() interface{} {
 	}
 }
 
-// SetValue sets the selected field and its value
+// SetValue selects a field and sets its value, replacing anything entered
+// for this or any other field of the oneof.
 func (o *OneofWidget) SetValue(fieldName string, value interface{}) {
-	member, ok := o.fields[fieldName]
-	if !ok {
+	if _, ok := o.fields[fieldName]; !ok {
 		return
 	}
 
-	// Update selector
+	// Update selector (resets the previously selected field)
 	o.selector.SetSelected(fieldName)
 
-	// Set the field value
-	member.setValue(value)
+	// Start from an empty input so partial values cannot merge with old ones
+	o.resetMember(fieldName)
+	o.activeField = fieldName
+	o.showActive()
+	o.fields[fieldName].setValue(value)
 }
 
 // CreateRenderer implements fyne.Widget
 func (o *OneofWidget) CreateRenderer() fyne.WidgetRenderer {
-	label := fieldLabel(formatFieldLabel(o.name), "oneof")
+	label := fieldLabel(formatFieldLabel(o.name), "oneof", false)
 
 	content := container.NewVBox(
 		container.NewBorder(nil, nil, label, nil, o.selector),
@@ -168,15 +206,140 @@ func (o *OneofWidget) GetDescriptor() protoreflect.OneofDescriptor {
 	return o.oneof
 }
 
-// Clear resets the oneof to its default state
+// Clear resets the oneof to its default state: the first field selected
+// and every field's input emptied.
 func (o *OneofWidget) Clear() {
-	if len(o.fields) > 0 {
-		// Get first field name
-		fields := o.oneof.Fields()
-		if fields.Len() > 0 {
-			firstFieldName := string(fields.Get(0).Name())
-			o.selector.SetSelected(firstFieldName)
-			o.onFieldSelected(firstFieldName)
+	fields := o.oneof.Fields()
+	if fields.Len() == 0 {
+		return
+	}
+	for i := 0; i < fields.Len(); i++ {
+		o.resetMember(string(fields.Get(i).Name()))
+	}
+
+	firstFieldName := string(fields.Get(0).Name())
+	o.selector.SetSelected(firstFieldName)
+	o.activeField = firstFieldName
+	o.showActive()
+}
+
+// OneofConflict is a oneof that JSON input set more than one field of.
+type OneofConflict struct {
+	Path   string   // Dotted path of the oneof, e.g. "item.payload"
+	Fields []string // Fields that were set, in declaration order
+}
+
+// OneofConflictError is returned by FormBuilder.FromJSON when the input
+// set several fields of the same oneof. The form is still populated, with
+// the first field in declaration order kept for each oneof, so the error is
+// a warning that the other values were dropped.
+type OneofConflictError struct {
+	Conflicts []OneofConflict
+}
+
+// Error implements error
+func (e *OneofConflictError) Error() string {
+	msgs := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		msgs[i] = fmt.Sprintf("oneof %s has %s set; keeping %s",
+			c.Path, strings.Join(c.Fields, " and "), c.Fields[0])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// resolveOneofConflicts removes all but the first set field (in declaration
+// order) of every oneof in jsonStr, including nested messages, so protojson
+// accepts it. It returns the input unchanged when there is nothing to drop.
+func resolveOneofConflicts(jsonStr string, md protoreflect.MessageDescriptor) (string, []OneofConflict) {
+	dec := json.NewDecoder(strings.NewReader(jsonStr))
+	dec.UseNumber()
+	var root map[string]interface{}
+	if err := dec.Decode(&root); err != nil {
+		return jsonStr, nil
+	}
+
+	var conflicts []OneofConflict
+	dropOneofConflicts(root, md, "", &conflicts)
+	if len(conflicts) == 0 {
+		return jsonStr, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(root); err != nil {
+		return jsonStr, nil
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), conflicts
+}
+
+// dropOneofConflicts applies resolveOneofConflicts to a decoded JSON object
+// for md, recursing into message fields.
+func dropOneofConflicts(obj map[string]interface{}, md protoreflect.MessageDescriptor, prefix string, conflicts *[]OneofConflict) {
+	oneofs := md.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+		var set, keys []string
+		for j := 0; j < od.Fields().Len(); j++ {
+			fd := od.Fields().Get(j)
+			if key, ok := jsonKey(obj, fd); ok {
+				set = append(set, string(fd.Name()))
+				keys = append(keys, key)
+			}
+		}
+		if len(set) > 1 {
+			for _, key := range keys[1:] {
+				delete(obj, key)
+			}
+			*conflicts = append(*conflicts, OneofConflict{Path: joinPath(prefix, string(od.Name())), Fields: set})
+		}
+	}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		key, ok := jsonKey(obj, fd)
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, string(fd.Name()))
+		switch {
+		case fd.IsMap():
+			entries, _ := obj[key].(map[string]interface{})
+			if fd.MapValue().Kind() != protoreflect.MessageKind {
+				continue
+			}
+			for _, k := range sortedKeys(entries) {
+				if m, ok := entries[k].(map[string]interface{}); ok {
+					dropOneofConflicts(m, fd.MapValue().Message(), path+"["+k+"]", conflicts)
+				}
+			}
+		case fd.Kind() != protoreflect.MessageKind || protoconv.IsSupported(fd.Message().FullName()):
+			continue
+		case fd.IsList():
+			items, _ := obj[key].([]interface{})
+			for n, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					dropOneofConflicts(m, fd.Message(), fmt.Sprintf("%s[%d]", path, n), conflicts)
+				}
+			}
+		default:
+			if m, ok := obj[key].(map[string]interface{}); ok {
+				dropOneofConflicts(m, fd.Message(), path, conflicts)
+			}
+		}
+	}
+}
+
+// jsonKey returns the key obj uses for fd: its JSON name or its proto name.
+func jsonKey(obj map[string]interface{}, fd protoreflect.FieldDescriptor) (string, bool) {
+	for _, key := range []string{fd.JSONName(), string(fd.Name())} {
+		if _, ok := obj[key]; ok {
+			return key, true
 		}
 	}
+	return "", false
 }
@@ -0,0 +1,115 @@
+package form
+
+import (
+	"encoding/json"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+// payloadMembers returns which members of Item's payload oneof are in out.
+func payloadMembers(t *testing.T, out string) map[string]interface{} {
+	t.Helper()
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	members := make(map[string]interface{})
+	for _, name := range []string{"text", "number"} {
+		if v, ok := got[name]; ok {
+			members[name] = v
+		}
+	}
+	return members
+}
+
+func TestFormBuilder_OneofSwitchingEmitsOneMember(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder((&pb.Item{}).ProtoReflect().Descriptor())
+	b.Build()
+	payload := b.oneofFields["payload"]
+	require.NotNil(t, payload)
+
+	for i := 0; i < 3; i++ {
+		payload.selector.SetSelected("text")
+		payload.fields["text"].setValue("hello")
+		out, err := b.ToJSON()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"text": "hello"}, payloadMembers(t, out))
+
+		payload.selector.SetSelected("number")
+		payload.fields["number"].setValue(int64(42))
+		out, err = b.ToJSON()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"number": "42"}, payloadMembers(t, out))
+	}
+
+	// Switching back starts from an empty input
+	payload.selector.SetSelected("text")
+	assert.Equal(t, "", payload.fields["text"].getValue())
+	payload.selector.SetSelected("number")
+	assert.Equal(t, int64(0), payload.fields["number"].getValue())
+}
+
+func TestFormBuilder_FromJSONSelectsOneofMember(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder((&pb.Item{}).ProtoReflect().Descriptor())
+	b.Build()
+	payload := b.oneofFields["payload"]
+
+	require.NoError(t, b.FromJSON(`{"text": "hello"}`))
+	require.NoError(t, b.FromJSON(`{"number": "7"}`))
+	assert.Equal(t, "number", payload.GetSelectedField())
+	out, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"number": "7"}, payloadMembers(t, out))
+
+	// JSON without the oneof resets it
+	require.NoError(t, b.FromJSON(`{"id": "1"}`))
+	assert.Equal(t, "text", payload.GetSelectedField())
+	assert.Equal(t, int64(0), payload.fields["number"].getValue())
+}
+
+func TestFormBuilder_FromJSONOneofConflict(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder((&pb.ItemRequest{}).ProtoReflect().Descriptor())
+	b.Build()
+
+	err := b.FromJSON(`{"item": {"id": "1", "number": "7", "text": "hello"}}`)
+	var conflict *OneofConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []OneofConflict{{Path: "item.payload", Fields: []string{"text", "number"}}}, conflict.Conflicts)
+	assert.Equal(t, "oneof item.payload has text and number set; keeping text", err.Error())
+
+	// The rest of the form is still populated
+	out, err := b.ToJSON()
+	require.NoError(t, err)
+	var got struct {
+		Item map[string]interface{} `json:"item"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	assert.Equal(t, "1", got.Item["id"])
+	assert.Equal(t, "hello", got.Item["text"])
+	assert.NotContains(t, got.Item, "number")
+}
+
+func TestResolveOneofConflicts_Unchanged(t *testing.T) {
+	md := (&pb.ItemList{}).ProtoReflect().Descriptor()
+
+	in := `{"items": [{"text": "a"}, {"number": "1"}]}`
+	out, conflicts := resolveOneofConflicts(in, md)
+	assert.Equal(t, in, out)
+	assert.Empty(t, conflicts)
+
+	out, conflicts = resolveOneofConflicts(`{"items": [{"text": "a"}, {"number": "1", "text": "b"}]}`, md)
+	assert.JSONEq(t, `{"items": [{"text": "a"}, {"text": "b"}]}`, out)
+	assert.Equal(t, []OneofConflict{{Path: "items[1].payload", Fields: []string{"text", "number"}}}, conflicts)
+}
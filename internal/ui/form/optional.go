@@ -1,19 +1,17 @@
 package form
 
 import (
-	"image/color"
-
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"github.com/shhac/grotto/internal/ui/components"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // OptionalFieldWidget wraps a field with a checkbox toggle for field presence.
-// Used for proto3 optional fields and single-member oneofs.
-// When unchecked, the field is omitted from the request entirely.
+// Used for fields with explicit presence, such as proto3 optional fields, and
+// single-member oneofs. When unchecked, the field is omitted from the request entirely.
 // When checked, the field is included even if set to its zero value.
 type OptionalFieldWidget struct {
 	widget.BaseWidget
@@ -26,6 +24,7 @@ type OptionalFieldWidget struct {
 	getInnerValue func() interface{}
 	setInnerValue func(interface{})
 	clearInner    func()
+	innerErrors   func(path string) []protoconv.FieldError
 }
 
 // NewOptionalScalarWidget creates an optional toggle wrapping a scalar FieldWidget.
@@ -41,7 +40,7 @@ func NewOptionalScalarWidget(fw *FieldWidget) *OptionalFieldWidget {
 
 	o.toggle = widget.NewCheck(fw.Label, nil)
 	typeHint := components.NewHintLabel(scalarTypeHint(fw.Descriptor))
-	toggleRow := container.NewHBox(o.toggle, typeHint)
+	toggleRow := container.NewHBox(append([]fyne.CanvasObject{o.toggle, typeHint}, deprecatedBadges(fw.Deprecated)...)...)
 
 	o.content = container.NewStack(fw.Widget)
 	o.content.Hide()
@@ -61,6 +60,15 @@ func NewOptionalScalarWidget(fw *FieldWidget) *OptionalFieldWidget {
 	o.getInnerValue = fw.GetValue
 	o.setInnerValue = fw.SetValue
 	o.clearInner = func() { fw.SetValue(getDefaultValue(fw.Descriptor)) }
+	o.innerErrors = func(path string) []protoconv.FieldError {
+		if fw.Validate == nil {
+			return nil
+		}
+		if err := fw.Validate(); err != nil {
+			return []protoconv.FieldError{{Path: path, Message: err.Error()}}
+		}
+		return nil
+	}
 
 	o.ExtendBaseWidget(o)
 	return o
@@ -69,25 +77,28 @@ func NewOptionalScalarWidget(fw *FieldWidget) *OptionalFieldWidget {
 // NewOptionalNestedWidget creates an optional toggle wrapping a nested message.
 // When toggled on, all sub-fields of the message are shown indented below the toggle.
 func NewOptionalNestedWidget(name string, md protoreflect.MessageDescriptor) *OptionalFieldWidget {
+	return newOptionalNestedWidget(name, md, nil, protoconv.IsDeprecated(md))
+}
+
+// newOptionalNestedWidget creates an optional nested message under parent.
+// A recursive message's form is only built once the toggle is turned on.
+func newOptionalNestedWidget(name string, md protoreflect.MessageDescriptor, parent *FormBuilder, deprecated bool) *OptionalFieldWidget {
 	o := &OptionalFieldWidget{name: name}
 
-	builder := NewFormBuilder(md)
+	form := newLazyForm(parent, md)
 
 	o.toggle = widget.NewCheck(formatFieldLabel(name), nil)
 	typeHint := components.NewHintLabel(string(md.Name()))
 
-	// Indent nested content for visual depth cue
-	leftPad := canvas.NewRectangle(color.Transparent)
-	leftPad.SetMinSize(fyne.NewSize(12, 0))
-	indented := container.NewBorder(nil, nil, leftPad, nil, builder.BuildContent())
-
-	o.content = container.NewVBox(indented)
+	o.content = container.NewVBox(form.content)
 	o.content.Hide()
 
-	o.outer = container.NewVBox(container.NewHBox(o.toggle, typeHint), o.content)
+	toggleRow := container.NewHBox(append([]fyne.CanvasObject{o.toggle, typeHint}, deprecatedBadges(deprecated)...)...)
+	o.outer = container.NewVBox(toggleRow, o.content)
 
 	o.toggle.OnChanged = func(checked bool) {
 		if checked {
+			form.build()
 			o.content.Show()
 		} else {
 			o.content.Hide()
@@ -95,13 +106,14 @@ func NewOptionalNestedWidget(name string, md protoreflect.MessageDescriptor) *Op
 		o.outer.Refresh()
 	}
 
-	o.getInnerValue = func() interface{} { return builder.GetValues() }
+	o.getInnerValue = func() interface{} { return form.values() }
 	o.setInnerValue = func(v interface{}) {
 		if m, ok := v.(map[string]interface{}); ok {
-			builder.SetValues(m)
+			form.setValues(m)
 		}
 	}
-	o.clearInner = func() { builder.Clear() }
+	o.clearInner = form.clear
+	o.innerErrors = form.fieldErrors
 
 	o.ExtendBaseWidget(o)
 	return o
@@ -131,6 +143,11 @@ func (o *OptionalFieldWidget) SetValue(v interface{}) {
 	o.setInnerValue(v)
 }
 
+// FieldErrors validates the inner field, qualifying errors with path.
+func (o *OptionalFieldWidget) FieldErrors(path string) []protoconv.FieldError {
+	return o.innerErrors(path)
+}
+
 // Clear disables the toggle and resets the inner value.
 func (o *OptionalFieldWidget) Clear() {
 	o.toggle.SetChecked(false)
@@ -0,0 +1,175 @@
+package form
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// presenceTestMessage builds:
+//
+//	message Patch {
+//	  optional int32 count = 1;
+//	  optional string note = 2;
+//	  int32 total = 3;
+//	}
+func presenceTestMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	optional := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, oneof int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name), JsonName: proto.String(name), Number: proto.Int32(num), Label: opt, Type: typ.Enum(),
+			OneofIndex: proto.Int32(oneof), Proto3Optional: proto.Bool(true),
+		}
+	}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("presencetest/patch.proto"),
+		Package: proto.String("presencetest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Patch"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				optional("count", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32, 0),
+				optional("note", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, 1),
+				{
+					Name: proto.String("total"), JsonName: proto.String("total"), Number: proto.Int32(3), Label: opt,
+					Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+				},
+			},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{
+				{Name: proto.String("_count")},
+				{Name: proto.String("_note")},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd.Messages().ByName("Patch")
+}
+
+func TestFormBuilder_OptionalZeroRoundTrip(t *testing.T) {
+	test.NewApp()
+
+	tests := []struct {
+		name  string
+		input string
+		count bool
+		note  bool
+	}{
+		{"int32 zero", `{"count": 0}`, true, false},
+		{"empty string", `{"note": ""}`, false, true},
+		{"both", `{"count": 0, "note": ""}`, true, true},
+		{"neither", `{}`, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewFormBuilder(presenceTestMessage(t))
+			b.Build()
+			require.NoError(t, b.FromJSON(tt.input))
+
+			// Present fields are ticked, absent ones are not
+			assert.Equal(t, tt.count, b.optionalFields["count"].IsEnabled())
+			assert.Equal(t, tt.note, b.optionalFields["note"].IsEnabled())
+
+			values := b.GetValues()
+			_, hasCount := values["count"]
+			_, hasNote := values["note"]
+			assert.Equal(t, tt.count, hasCount)
+			assert.Equal(t, tt.note, hasNote)
+
+			got, err := b.ToJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.input, got)
+		})
+	}
+}
+
+func TestFormBuilder_OptionalToggle(t *testing.T) {
+	test.NewApp()
+	b := NewFormBuilder(presenceTestMessage(t))
+	b.Build()
+
+	// A field without presence drops its zero value
+	_, ok := b.fields["total"]
+	require.True(t, ok, "total has no presence toggle")
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, got)
+
+	// Ticking sends the zero value; unticking omits the field again
+	count := b.optionalFields["count"]
+	count.SetEnabled(true)
+	got, err = b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count": 0}`, got)
+
+	count.SetValue(int32(7))
+	count.SetEnabled(false)
+	got, err = b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, got)
+
+	// Setting a value ticks the box
+	b.SetValues(map[string]interface{}{"note": ""})
+	assert.True(t, b.optionalFields["note"].IsEnabled())
+	assert.False(t, count.IsEnabled(), "absent fields are unticked")
+	assert.Equal(t, "", b.optionalFields["note"].content.Objects[0].(*widget.Entry).Text)
+}
+
+// editionsTestMessage builds, in edition 2023, where presence is explicit
+// by default:
+//
+//	message Limits {
+//	  int32 max = 1;
+//	  string label = 2;
+//	  int32 used = 3 [features.field_presence = IMPLICIT];
+//	}
+func editionsTestMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	implicit := &descriptorpb.FieldOptions{Features: &descriptorpb.FeatureSet{
+		FieldPresence: descriptorpb.FeatureSet_IMPLICIT.Enum(),
+	}}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("presencetest/limits.proto"),
+		Package: proto.String("presencetest"),
+		Syntax:  proto.String("editions"),
+		Edition: descriptorpb.Edition_EDITION_2023.Enum(),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Limits"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("max"), JsonName: proto.String("max"), Number: proto.Int32(1), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()},
+				{Name: proto.String("label"), JsonName: proto.String("label"), Number: proto.Int32(2), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("used"), JsonName: proto.String("used"), Number: proto.Int32(3), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Options: implicit},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd.Messages().ByName("Limits")
+}
+
+func TestFormBuilder_EditionsExplicitPresence(t *testing.T) {
+	test.NewApp()
+	b := NewFormBuilder(editionsTestMessage(t))
+	b.Build()
+
+	require.Contains(t, b.optionalFields, "max")
+	require.Contains(t, b.optionalFields, "label")
+	require.Contains(t, b.fields, "used", "implicit presence has no toggle")
+
+	input := `{"max": 0, "label": "", "used": 4}`
+	require.NoError(t, b.FromJSON(input))
+	assert.True(t, b.optionalFields["max"].IsEnabled())
+	assert.True(t, b.optionalFields["label"].IsEnabled())
+
+	got, err := b.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, input, got)
+}
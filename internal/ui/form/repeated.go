@@ -1,15 +1,14 @@
 package form
 
 import (
-	"encoding/base64"
 	"fmt"
-	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -50,7 +49,7 @@ func NewRepeatedFieldWidget(name string, fd protoreflect.FieldDescriptor) *Repea
 	// Main container with label, list, and add button.
 	// Items grow naturally inside the VBox; the outer form VScroll handles scrolling.
 	r.container = container.NewBorder(
-		fieldLabel(formatFieldLabel(name), repeatedTypeHint(fd)),
+		fieldLabel(formatFieldLabel(name), repeatedTypeHint(fd), protoconv.IsFieldDeprecated(fd)),
 		r.addButton,
 		nil,
 		nil,
@@ -73,7 +72,10 @@ func (r *RepeatedFieldWidget) AddItem() {
 	// Create item widget based on field kind
 	var itemWidget fyne.CanvasObject
 
-	if r.fd.Kind() == protoreflect.MessageKind {
+	if r.fd.Kind() == protoreflect.MessageKind && protoconv.IsSupported(r.fd.Message().FullName()) {
+		// Repeated Timestamp, Duration, or FieldMask: same input as a single one
+		itemWidget = newWellKnownItem(r.fd.Message())
+	} else if r.fd.Kind() == protoreflect.MessageKind {
 		// Repeated message: create nested form
 		nestedWidget := NewNestedMessageWidget(
 			fmt.Sprintf("Item %d", itemNum),
@@ -150,39 +152,20 @@ func (r *RepeatedFieldWidget) GetValue() interface{} {
 			// Extract values from widgets
 			if nmw, ok := w.(*NestedMessageWidget); ok {
 				values = append(values, nmw.GetValue())
+			} else if wk, ok := w.(*wellKnownItem); ok {
+				values = append(values, wk.field.GetValue())
+			} else if be, ok := w.(*BytesEntry); ok {
+				values = append(values, be.Bytes())
 			} else if entry, ok := w.(*widget.Entry); ok {
 				// Parse value based on field kind
 				val := r.parseEntryValue(entry.Text)
 				values = append(values, val)
 			} else if check, ok := w.(*widget.Check); ok {
 				values = append(values, check.Checked)
-			} else if sel, ok := w.(*widget.Select); ok {
-				// Convert enum name to number for protobuf
-				if r.fd.Kind() == protoreflect.EnumKind {
-					enumValues := r.fd.Enum().Values()
-					for i := 0; i < enumValues.Len(); i++ {
-						ev := enumValues.Get(i)
-						if string(ev.Name()) == sel.Selected {
-							values = append(values, int32(ev.Number()))
-							break
-						}
-					}
-				} else {
-					values = append(values, sel.Selected)
-				}
-			} else if selEntry, ok := w.(*widget.SelectEntry); ok {
-				// Large enum: SelectEntry with type-to-filter
-				if r.fd.Kind() == protoreflect.EnumKind {
-					enumValues := r.fd.Enum().Values()
-					for i := 0; i < enumValues.Len(); i++ {
-						ev := enumValues.Get(i)
-						if string(ev.Name()) == selEntry.Text {
-							values = append(values, int32(ev.Number()))
-							break
-						}
-					}
-				} else {
-					values = append(values, selEntry.Text)
+			} else if enum, ok := w.(*enumSelect); ok {
+				// Enums are sent as numbers
+				if num, ok := enum.Number(); ok {
+					values = append(values, num)
 				}
 			}
 		}
@@ -197,7 +180,11 @@ func (r *RepeatedFieldWidget) parseEntryValue(text string) interface{} {
 	case protoreflect.StringKind:
 		return text
 	case protoreflect.BytesKind:
-		return text // Keep as string, will be converted later
+		data, _, err := DecodeBase64(text)
+		if err != nil {
+			return []byte(text)
+		}
+		return data
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
 		if val, err := parseScalarValue(text, r.fd); err == nil {
 			return val
@@ -252,44 +239,32 @@ func (r *RepeatedFieldWidget) SetValue(v interface{}) {
 
 					if nmw, ok := wid.(*NestedMessageWidget); ok {
 						nmw.SetValue(item)
+					} else if wk, ok := wid.(*wellKnownItem); ok {
+						wk.field.SetValue(item)
+					} else if be, ok := wid.(*BytesEntry); ok {
+						switch b := item.(type) {
+						case []byte:
+							be.SetBytes(b)
+						case string:
+							be.SetText(b)
+						}
 					} else if entry, ok := wid.(*widget.Entry); ok {
-						// Handle both string and numeric values
-						entry.SetText(fmt.Sprintf("%v", item))
+						// Handle both string and numeric values; numbers are
+						// written out in full, not as 1e+21
+						if s, ok := item.(string); ok {
+							entry.SetText(s)
+						} else if text, ok := numberText(item); ok {
+							entry.SetText(text)
+						} else {
+							entry.SetText(fmt.Sprintf("%v", item))
+						}
 					} else if check, ok := wid.(*widget.Check); ok {
 						if b, ok := item.(bool); ok {
 							check.SetChecked(b)
 						}
-					} else if sel, ok := wid.(*widget.Select); ok {
-						// Handle enum values (could be string name or int value)
-						if str, ok := item.(string); ok {
-							sel.SetSelected(str)
-						} else if num, ok := item.(float64); ok {
-							// JSON numbers come as float64 - convert to enum name
-							enumValues := r.fd.Enum().Values()
-							enumNum := int32(num)
-							for i := 0; i < enumValues.Len(); i++ {
-								ev := enumValues.Get(i)
-								if int32(ev.Number()) == enumNum {
-									sel.SetSelected(string(ev.Name()))
-									break
-								}
-							}
-						}
-					} else if selEntry, ok := wid.(*widget.SelectEntry); ok {
-						// Large enum: SelectEntry
-						if str, ok := item.(string); ok {
-							selEntry.SetText(str)
-						} else if num, ok := item.(float64); ok {
-							enumValues := r.fd.Enum().Values()
-							enumNum := int32(num)
-							for i := 0; i < enumValues.Len(); i++ {
-								ev := enumValues.Get(i)
-								if int32(ev.Number()) == enumNum {
-									selEntry.SetText(string(ev.Name()))
-									break
-								}
-							}
-						}
+					} else if enum, ok := wid.(*enumSelect); ok {
+						// Enum values come as a name or a number
+						enum.SetValue(item)
 					}
 				}
 			}
@@ -297,6 +272,28 @@ func (r *RepeatedFieldWidget) SetValue(v interface{}) {
 	}
 }
 
+// FieldErrors validates well-known type and nested message items, naming
+// each failure by its index under path (e.g. "times[1]").
+func (r *RepeatedFieldWidget) FieldErrors(path string) []protoconv.FieldError {
+	var errs []protoconv.FieldError
+	for i, item := range r.items {
+		border, ok := item.(*fyne.Container)
+		if !ok || len(border.Objects) == 0 {
+			continue
+		}
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch w := border.Objects[0].(type) {
+		case *wellKnownItem:
+			if err := w.field.Validate(); err != nil {
+				errs = append(errs, protoconv.FieldError{Path: itemPath, Message: err.Error()})
+			}
+		case *NestedMessageWidget:
+			errs = append(errs, w.GetBuilder().FieldErrors(itemPath)...)
+		}
+	}
+	return errs
+}
+
 // OnAdd sets a callback for when items are added
 func (r *RepeatedFieldWidget) OnAdd(callback func()) {
 	r.onAdd = callback
@@ -307,61 +304,13 @@ func (r *RepeatedFieldWidget) OnRemove(callback func(index int)) {
 	r.onRemove = callback
 }
 
-// searchableEnumThreshold matches the threshold in mapper.go for consistency.
-const repeatedSearchableEnumThreshold = 10
-
 // createScalarWidget creates an appropriate widget for scalar repeated fields
 func (r *RepeatedFieldWidget) createScalarWidget() fyne.CanvasObject {
 	switch r.fd.Kind() {
 	case protoreflect.BoolKind:
 		return widget.NewCheck("", nil)
 	case protoreflect.EnumKind:
-		options := make([]string, 0)
-		enumValues := r.fd.Enum().Values()
-		for i := 0; i < enumValues.Len(); i++ {
-			options = append(options, string(enumValues.Get(i).Name()))
-		}
-		if len(options) > repeatedSearchableEnumThreshold {
-			selEntry := widget.NewSelectEntry(options)
-			selEntry.Wrapping = fyne.TextWrapOff
-			selEntry.Scroll = container.ScrollNone
-			selEntry.SetPlaceHolder("Type to filter...")
-			allOptions := options
-			selEntry.OnChanged = func(text string) {
-				if text == "" {
-					selEntry.SetOptions(allOptions)
-					return
-				}
-				lower := strings.ToLower(text)
-				filtered := make([]string, 0)
-				for _, opt := range allOptions {
-					if strings.Contains(strings.ToLower(opt), lower) {
-						filtered = append(filtered, opt)
-					}
-				}
-				selEntry.SetOptions(filtered)
-			}
-			selEntry.Validator = func(s string) error {
-				if s == "" {
-					return nil
-				}
-				for i := 0; i < enumValues.Len(); i++ {
-					if string(enumValues.Get(i).Name()) == s {
-						return nil
-					}
-				}
-				return fmt.Errorf("unknown enum value: %s", s)
-			}
-			if len(options) > 0 {
-				selEntry.SetText(options[0])
-			}
-			return selEntry
-		}
-		sel := widget.NewSelect(options, nil)
-		if len(options) > 0 {
-			sel.SetSelected(options[0])
-		}
-		return sel
+		return newEnumSelect(r.fd.Enum(), enumDefaultName(r.fd))
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
 		entry := newSignedIntEntry()
 		entry.SetPlaceHolder("0")
@@ -425,16 +374,7 @@ func (r *RepeatedFieldWidget) createScalarWidget() fyne.CanvasObject {
 	case protoreflect.StringKind:
 		return newFormEntry()
 	case protoreflect.BytesKind:
-		entry := newFormEntry()
-		entry.SetPlaceHolder("Base64 encoded bytes")
-		entry.Validator = func(s string) error {
-			if s == "" {
-				return nil
-			}
-			_, err := base64.StdEncoding.DecodeString(s)
-			return err
-		}
-		return entry
+		return NewBytesEntry()
 	default:
 		return widget.NewLabel("Unsupported type")
 	}
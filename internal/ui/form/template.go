@@ -0,0 +1,187 @@
+package form
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Example values used for well-known types in generated templates
+const (
+	templateTimestamp = "2024-01-01T00:00:00Z"
+	templateDuration  = "60s"
+)
+
+// TemplateOptions controls GenerateTemplate.
+type TemplateOptions struct {
+	// MaxDepth is how many levels of nested messages are filled in; deeper
+	// messages are left as {}. Zero means one level.
+	MaxDepth int
+}
+
+// GenerateTemplate returns a JSON request body for md with every field
+// present: scalars at their zero value, enums at their first value, one
+// element in each repeated field and map, the first field of each oneof,
+// and example values for Timestamp and Duration. A message type that
+// encloses itself is left as {} rather than expanded again.
+func GenerateTemplate(md protoreflect.MessageDescriptor, opts TemplateOptions) (string, error) {
+	if md == nil {
+		return "", errors.New("no message descriptor")
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 1
+	}
+
+	t := &templateGenerator{maxDepth: opts.MaxDepth}
+	out, err := json.MarshalIndent(t.message(md, 0, nil), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// templateGenerator walks a message descriptor building template values.
+type templateGenerator struct {
+	maxDepth int
+}
+
+// message returns the template object for md at the given nesting depth.
+// ancestors are the message types enclosing it.
+func (t *templateGenerator) message(md protoreflect.MessageDescriptor, depth int, ancestors []protoreflect.FullName) templateObject {
+	obj := templateObject{}
+	for _, name := range ancestors {
+		if name == md.FullName() {
+			return obj
+		}
+	}
+	if depth > t.maxDepth {
+		return obj
+	}
+	ancestors = append(ancestors, md.FullName())
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		// Only the first field of a real oneof is emitted
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() && od.Fields().Get(0) != fd {
+			continue
+		}
+
+		var val interface{}
+		switch {
+		case fd.IsMap():
+			val = templateObject{{Name: mapKeyTemplate(fd.MapKey()), Value: t.single(fd.MapValue(), depth, ancestors)}}
+		case fd.IsList():
+			val = []interface{}{t.single(fd, depth, ancestors)}
+		default:
+			val = t.single(fd, depth, ancestors)
+		}
+		obj = append(obj, templateField{Name: fd.JSONName(), Value: val})
+	}
+	return obj
+}
+
+// single returns the template value for one (non-list, non-map) value of fd.
+func (t *templateGenerator) single(fd protoreflect.FieldDescriptor, depth int, ancestors []protoreflect.FullName) interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return false
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return ""
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson writes 64-bit integers as strings
+		return "0"
+	case protoreflect.EnumKind:
+		if name := enumDefaultName(fd); name != "" {
+			return name
+		}
+		return 0
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if val, ok := wellKnownTemplate(fd.Message()); ok {
+			return val
+		}
+		return t.message(fd.Message(), depth+1, ancestors)
+	default:
+		// 32-bit integers, float, double
+		return 0
+	}
+}
+
+// mapKeyTemplate returns the example key for a map key field.
+func mapKeyTemplate(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return "key"
+	case protoreflect.BoolKind:
+		return "false"
+	default:
+		return "0"
+	}
+}
+
+// wellKnownTemplate returns the template value for google.protobuf types
+// with a special JSON form, or false for other messages.
+func wellKnownTemplate(md protoreflect.MessageDescriptor) (interface{}, bool) {
+	switch md.FullName() {
+	case protoconv.TimestampName:
+		return templateTimestamp, true
+	case protoconv.DurationName:
+		return templateDuration, true
+	case protoconv.FieldMaskName:
+		return "", true
+	case "google.protobuf.Struct", "google.protobuf.Empty", "google.protobuf.Any":
+		return templateObject{}, true
+	case "google.protobuf.ListValue":
+		return []interface{}{}, true
+	case "google.protobuf.Value":
+		return nil, true
+	case "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return "", true
+	case "google.protobuf.BoolValue":
+		return false, true
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return "0", true
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return 0, true
+	}
+	return nil, false
+}
+
+// templateField is one member of a templateObject.
+type templateField struct {
+	Name  string
+	Value interface{}
+}
+
+// templateObject is a JSON object that keeps its fields in declaration
+// order, unlike a Go map.
+type templateObject []templateField
+
+// MarshalJSON implements json.Marshaler
+func (o templateObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+package form
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+func TestGenerateTemplate_Item(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	out, err := GenerateTemplate(md, TemplateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "item": {
+    "id": "",
+    "name": "",
+    "color": "COLOR_UNSPECIFIED",
+    "labels": {
+      "key": ""
+    },
+    "tags": [
+      ""
+    ],
+    "createdAt": "2024-01-01T00:00:00Z",
+    "ttl": "60s",
+    "text": "",
+    "nested": {},
+    "count": 0,
+    "active": false,
+    "score": 0,
+    "data": ""
+  }
+}`, out)
+
+	// The template is a valid request
+	require.NoError(t, protojson.Unmarshal([]byte(out), dynamicpb.NewMessage(md)))
+
+	// Deeper templates fill nested messages in further
+	out, err = GenerateTemplate(md, TemplateOptions{MaxDepth: 2})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"nested": {
+      "value": ""
+    }`)
+}
+
+func TestGenerateTemplate_ListsAndMaps(t *testing.T) {
+	md := scheduleMessage(t)
+
+	out, err := GenerateTemplate(md, TemplateOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"times": ["2024-01-01T00:00:00Z"], "timeouts": {"key": "60s"}}`, out)
+	require.NoError(t, protojson.Unmarshal([]byte(out), dynamicpb.NewMessage(md)))
+}
+
+func TestGenerateTemplate_RecursionCutoff(t *testing.T) {
+	file := recursiveFile(t)
+	md := file.Messages().ByName("Node")
+
+	out, err := GenerateTemplate(md, TemplateOptions{MaxDepth: 10})
+	require.NoError(t, err)
+	// Node never expands inside itself; Ping > Pong stops at the second Ping
+	assert.JSONEq(t, `{
+		"name": "",
+		"child": {},
+		"children": [{}],
+		"next": {},
+		"leaf": "",
+		"ping": {"pong": {"ping": {}}}
+	}`, out)
+	require.NoError(t, protojson.Unmarshal([]byte(out), dynamicpb.NewMessage(md)))
+
+	// Depth still applies without recursion
+	out, err = GenerateTemplate(file.Messages().ByName("Level0"), TemplateOptions{MaxDepth: 2})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"next": {"next": {"next": {}}}}`, out)
+}
+
+func TestGenerateTemplate_NoDescriptor(t *testing.T) {
+	_, err := GenerateTemplate(nil, TemplateOptions{})
+	assert.Error(t, err)
+}
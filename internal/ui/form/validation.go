@@ -1,9 +1,11 @@
 package form
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
@@ -140,3 +142,33 @@ func parseScalarValue(s string, fd protoreflect.FieldDescriptor) (interface{}, e
 		return nil, fmt.Errorf("unsupported scalar type: %v", fd.Kind())
 	}
 }
+
+// numericValue converts v, a number as form values and decoded JSON carry
+// it, to the Go type fd's kind is edited as (int32, int64, uint32, uint64,
+// float32 or float64). Strings, the form protojson writes 64-bit integers
+// in, and json.Numbers are parsed digit for digit, so integers past 2^53
+// keep their value; only a float64 has already lost it.
+func numericValue(v interface{}, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	s, ok := numberText(v)
+	if !ok {
+		return nil, fmt.Errorf("not a number: %v", v)
+	}
+	return parseScalarValue(s, fd)
+}
+
+// numberText returns v as decimal text, or false if v is not a number.
+func numberText(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case string:
+		return strings.TrimSpace(n), true
+	case json.Number:
+		return n.String(), true
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32), true
+	case int, int32, int64, uint, uint32, uint64:
+		return fmt.Sprint(n), true
+	}
+	return "", false
+}
@@ -0,0 +1,321 @@
+package form
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DurationEntry is an Entry for google.protobuf.Duration values that
+// rewrites human-friendly input ("5m", "1h30m", "250ms") to the protojson
+// seconds form ("300s") when it loses focus.
+type DurationEntry struct {
+	widget.Entry
+}
+
+// NewDurationEntry creates a single-line duration entry with validation.
+func NewDurationEntry() *DurationEntry {
+	e := &DurationEntry{}
+	e.ExtendBaseWidget(e)
+	e.Wrapping = fyne.TextWrapOff
+	e.Scroll = container.ScrollNone
+	e.SetPlaceHolder("Duration (e.g., 1.5s, 5m, 1h30m)")
+	e.Validator = protoconv.ValidateDuration
+	return e
+}
+
+// FocusLost implements fyne.Focusable, normalizing the entered duration.
+func (e *DurationEntry) FocusLost() {
+	e.Entry.FocusLost()
+	e.Normalize()
+}
+
+// Normalize rewrites the text to the protojson form if it parses, leaving
+// invalid input untouched so the validator can flag it.
+func (e *DurationEntry) Normalize() {
+	if normalized, err := protoconv.NormalizeDuration(e.Text); err == nil && normalized != e.Text {
+		e.SetText(normalized)
+	}
+}
+
+// clockLayouts are the accepted time-of-day forms for TimestampEntry
+var clockLayouts = []string{"15:04:05.999999999", "15:04"}
+
+// TimestampEntry edits a google.protobuf.Timestamp as a date, picked from a
+// calendar or typed, and a UTC time of day, with a button for the current
+// time. Text and SetText use the RFC3339 form protojson expects.
+type TimestampEntry struct {
+	widget.BaseWidget
+
+	date    *widget.DateEntry
+	clock   *widget.Entry
+	now     *widget.Button
+	content fyne.CanvasObject
+}
+
+// NewTimestampEntry creates an empty timestamp entry.
+func NewTimestampEntry() *TimestampEntry {
+	e := &TimestampEntry{
+		date:  widget.NewDateEntry(),
+		clock: newFormEntry(),
+	}
+	e.clock.SetPlaceHolder("HH:MM:SS (UTC)")
+	e.clock.Validator = validateClock
+	e.now = widget.NewButton("Now", func() {
+		e.SetTime(time.Now())
+	})
+	e.content = container.NewBorder(nil, nil, nil, e.now,
+		container.NewGridWithColumns(2, e.date, e.clock))
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// CreateRenderer implements fyne.Widget.
+func (e *TimestampEntry) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(e.content)
+}
+
+// Text returns the timestamp in RFC3339 form, or "" when unset. Input that
+// cannot be combined into a timestamp is returned as typed so validation
+// can report it.
+func (e *TimestampEntry) Text() string {
+	clock := strings.TrimSpace(e.clock.Text)
+	// A full timestamp pasted into the time field wins over the date
+	if _, err := time.Parse(time.RFC3339Nano, clock); err == nil || e.date.Date == nil {
+		return clock
+	}
+
+	d := *e.date.Date
+	t, err := parseClock(clock)
+	if err != nil {
+		return d.Format("2006-01-02") + "T" + clock + "Z"
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).Format(time.RFC3339Nano)
+}
+
+// SetText shows an RFC3339 timestamp, converted to UTC. Unparseable text is
+// kept in the time field, with no date, for validation to flag.
+func (e *TimestampEntry) SetText(s string) {
+	s = strings.TrimSpace(s)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		e.date.SetDate(nil)
+		e.clock.SetText(s)
+		return
+	}
+	e.SetTime(t)
+}
+
+// SetTime shows t in UTC.
+func (e *TimestampEntry) SetTime(t time.Time) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	e.date.SetDate(&day)
+	e.clock.SetText(t.Format(clockLayouts[0]))
+}
+
+// Validate checks the combined value is a valid timestamp.
+func (e *TimestampEntry) Validate() error {
+	return protoconv.ValidateTimestamp(e.Text())
+}
+
+// parseClock parses a time of day in one of clockLayouts. Empty is midnight.
+func parseClock(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, layout := range clockLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// validateClock accepts a time of day or, when pasted, a full timestamp.
+func validateClock(s string) error {
+	s = strings.TrimSpace(s)
+	if _, err := parseClock(s); err == nil {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return nil
+	}
+	return fmt.Errorf("time must be HH:MM or HH:MM:SS in UTC")
+}
+
+// setupWellKnownField fills in fw's widget and accessors for a Timestamp,
+// Duration, or FieldMask value, reporting false for other message types.
+// Values are the protojson string forms (paths for FieldMask).
+func setupWellKnownField(fw *FieldWidget, md protoreflect.MessageDescriptor) bool {
+	switch md.FullName() {
+	case protoconv.TimestampName:
+		entry := NewTimestampEntry()
+		fw.Widget = entry
+		fw.GetValue = func() interface{} { return entry.Text() }
+		fw.SetValue = func(v interface{}) {
+			if s, ok := v.(string); ok {
+				entry.SetText(s)
+			}
+		}
+		fw.Validate = entry.Validate
+
+	case protoconv.DurationName:
+		entry := NewDurationEntry()
+		fw.Widget = entry
+		fw.GetValue = func() interface{} {
+			// Convert on read too, in case the entry still has focus
+			if normalized, err := protoconv.NormalizeDuration(entry.Text); err == nil {
+				return normalized
+			}
+			return entry.Text
+		}
+		fw.SetValue = func(v interface{}) {
+			if s, ok := v.(string); ok {
+				entry.SetText(s)
+			}
+		}
+		fw.Validate = func() error { return entry.Validate() }
+
+	case protoconv.FieldMaskName:
+		entry := widget.NewMultiLineEntry()
+		entry.SetPlaceHolder("Field paths (one per line or comma-separated)")
+		entry.Validator = protoconv.ValidateFieldMask
+		fw.Widget = entry
+		fw.GetValue = func() interface{} {
+			paths, err := protoconv.ParseFieldMask(entry.Text)
+			if err != nil || len(paths) == 0 {
+				return []string{}
+			}
+			return paths
+		}
+		fw.SetValue = func(v interface{}) {
+			// Accept either []string or string
+			switch val := v.(type) {
+			case []string:
+				if len(val) == 0 {
+					entry.SetText("")
+				} else {
+					// Format as one path per line
+					entry.SetText(strings.Join(val, "\n"))
+				}
+			case string:
+				entry.SetText(val)
+			}
+		}
+		fw.Validate = func() error { return entry.Validate() }
+
+	default:
+		return false
+	}
+	return true
+}
+
+// wellKnownItem holds the input for one Timestamp, Duration, or FieldMask
+// element of a repeated field or map value, which find their inputs by
+// widget type.
+type wellKnownItem struct {
+	widget.BaseWidget
+	field *FieldWidget
+}
+
+// newWellKnownItem creates an element input for md, which must satisfy
+// protoconv.IsSupported.
+func newWellKnownItem(md protoreflect.MessageDescriptor) *wellKnownItem {
+	item := &wellKnownItem{field: &FieldWidget{}}
+	setupWellKnownField(item.field, md)
+	item.ExtendBaseWidget(item)
+	return item
+}
+
+// CreateRenderer implements fyne.Widget.
+func (w *wellKnownItem) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.field.Widget)
+}
+
+// wellKnownToValue converts a form value for a Timestamp, Duration, or
+// FieldMask field into a message value.
+func wellKnownToValue(md protoreflect.MessageDescriptor, v interface{}) (protoreflect.Value, error) {
+	msg := dynamicpb.NewMessage(md)
+
+	if md.FullName() == protoconv.FieldMaskName {
+		var paths []string
+		switch val := v.(type) {
+		case []string:
+			paths = val
+		case string:
+			parsed, err := protoconv.ParseFieldMask(val)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			paths = parsed
+		default:
+			return protoreflect.Value{}, fmt.Errorf("unsupported type conversion for %v", v)
+		}
+		list := msg.Mutable(md.Fields().ByName("paths")).List()
+		for _, p := range paths {
+			list.Append(protoreflect.ValueOfString(p))
+		}
+		return protoreflect.ValueOfMessage(msg), nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return protoreflect.Value{}, fmt.Errorf("unsupported type conversion for %v", v)
+	}
+
+	// An empty element of a repeated field or map is the zero value
+	if strings.TrimSpace(s) == "" {
+		return protoreflect.ValueOfMessage(msg), nil
+	}
+
+	var err error
+	if md.FullName() == protoconv.DurationName {
+		s, err = protoconv.NormalizeDuration(s)
+	} else {
+		s, err = protoconv.NormalizeTimestamp(s)
+	}
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	if err := protojson.Unmarshal([]byte(strconv.Quote(s)), msg); err != nil {
+		return protoreflect.Value{}, err
+	}
+	return protoreflect.ValueOfMessage(msg), nil
+}
+
+// wellKnownToInterface converts a Timestamp or Duration message to its
+// protojson string, and a FieldMask to its paths, matching what the form
+// widgets accept in SetValue.
+func wellKnownToInterface(msg protoreflect.Message) interface{} {
+	if msg.Descriptor().FullName() == protoconv.FieldMaskName {
+		list := msg.Get(msg.Descriptor().Fields().ByName("paths")).List()
+		paths := make([]string, list.Len())
+		for i := range paths {
+			paths[i] = list.Get(i).String()
+		}
+		return paths
+	}
+
+	b, err := protojson.Marshal(msg.Interface())
+	if err != nil {
+		return ""
+	}
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,220 @@
+package form
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+// scheduleMessage builds a message with well-known types inside a repeated
+// field and a map:
+//
+//	message Schedule {
+//	  repeated google.protobuf.Timestamp times = 1;
+//	  map<string, google.protobuf.Duration> timeouts = 2;
+//	}
+func scheduleMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("wkttest/schedule.proto"),
+		Package:    proto.String("wkttest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto", "google/protobuf/duration.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Schedule"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{
+					Name:     proto.String("times"),
+					JsonName: proto.String("times"),
+					Number:   proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					TypeName: proto.String(".google.protobuf.Timestamp"),
+				},
+				{
+					Name:     proto.String("timeouts"),
+					JsonName: proto.String("timeouts"),
+					Number:   proto.Int32(2),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					TypeName: proto.String(".wkttest.Schedule.TimeoutsEntry"),
+				},
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("TimeoutsEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("key"),
+						JsonName: proto.String("key"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:     proto.String("value"),
+						JsonName: proto.String("value"),
+						Number:   proto.Int32(2),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".google.protobuf.Duration"),
+					},
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	return fd.Messages().ByName("Schedule")
+}
+
+func TestFormBuilder_WellKnownRoundTrip(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder((&pb.Item{}).ProtoReflect().Descriptor())
+	b.Build()
+
+	require.NoError(t, b.FromJSON(`{"createdAt":"2024-06-15T10:00:00+02:00","ttl":"1h30m"}`))
+
+	out, err := b.ToJSON()
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	assert.Equal(t, "2024-06-15T08:00:00Z", got["createdAt"])
+	assert.Equal(t, "5400s", got["ttl"])
+}
+
+func TestFormBuilder_FieldErrors(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder((&pb.ItemRequest{}).ProtoReflect().Descriptor())
+	b.Build()
+	b.SetValues(map[string]interface{}{
+		"item": map[string]interface{}{
+			"created_at": "yesterday",
+			"ttl":        "30",
+		},
+	})
+
+	errs := b.FieldErrors("")
+	require.Len(t, errs, 2)
+	assert.Equal(t, "item.created_at: "+protoconv.TimestampHint, errs[0].Error())
+	assert.Equal(t, "item.ttl", errs[1].Path)
+	assert.Contains(t, errs[1].Message, "30s")
+
+	assert.Equal(t, errs[0], b.Validate())
+}
+
+func TestDurationEntry_FocusLostNormalizes(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5m", "300s"},
+		{"250ms", "0.250s"},
+		{"1.5s", "1.500s"},
+		{"forever", "forever"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e := NewDurationEntry()
+			e.SetText(tt.input)
+			e.FocusLost()
+			assert.Equal(t, tt.want, e.Text)
+		})
+	}
+}
+
+func TestFormBuilder_WellKnownInListsAndMaps(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	b := NewFormBuilder(scheduleMessage(t))
+	b.Build()
+
+	require.NoError(t, b.FromJSON(`{
+		"times": ["2024-06-15T10:00:00+02:00", "2024-06-16T09:30:00.500Z"],
+		"timeouts": {"build": "1h30m", "test": "90s"}
+	}`))
+	assert.IsType(t, &wellKnownItem{}, b.repeatedFields["times"].items[0].(*fyne.Container).Objects[0])
+
+	out, err := b.ToJSON()
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	assert.Equal(t, []interface{}{"2024-06-15T08:00:00Z", "2024-06-16T09:30:00.500Z"}, got["times"])
+	assert.Equal(t, map[string]interface{}{"build": "5400s", "test": "90s"}, got["timeouts"])
+	assert.Empty(t, b.FieldErrors(""))
+
+	// Invalid elements are reported with their index or key
+	b.SetValues(map[string]interface{}{
+		"times":    []interface{}{"2024-06-15T08:00:00Z", "tomorrow"},
+		"timeouts": map[string]interface{}{"build": "soon"},
+	})
+	errs := b.FieldErrors("")
+	require.Len(t, errs, 2)
+	assert.Equal(t, "times[1]: "+protoconv.TimestampHint, errs[0].Error())
+	assert.Equal(t, "timeouts[build]", errs[1].Path)
+	assert.Equal(t, errs[0], b.Validate())
+}
+
+func TestTimestampEntry(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	e := NewTimestampEntry()
+	assert.Equal(t, "", e.Text())
+	assert.NoError(t, e.Validate(), "empty is unset")
+
+	// Offsets are converted to UTC and split into date and time
+	e.SetText("2024-06-15T10:00:00.25+02:00")
+	assert.Equal(t, "08:00:00.25", e.clock.Text)
+	assert.Equal(t, "2024-06-15T08:00:00.25Z", e.Text())
+
+	// Editing the time keeps the date; HH:MM and blank are accepted
+	e.clock.SetText("23:45")
+	assert.Equal(t, "2024-06-15T23:45:00Z", e.Text())
+	e.clock.SetText("")
+	assert.Equal(t, "2024-06-15T00:00:00Z", e.Text())
+
+	// A full timestamp pasted into the time field is used as is
+	e.clock.SetText("2025-01-02T03:04:05Z")
+	assert.Equal(t, "2025-01-02T03:04:05Z", e.Text())
+
+	e.clock.SetText("25:00")
+	assert.Error(t, e.clock.Validate())
+	assert.EqualError(t, e.Validate(), protoconv.TimestampHint)
+
+	// Unparseable values are kept for validation to flag
+	e.SetText("yesterday")
+	assert.Nil(t, e.date.Date)
+	assert.Equal(t, "yesterday", e.Text())
+	assert.EqualError(t, e.Validate(), protoconv.TimestampHint)
+
+	before := time.Now().UTC().Truncate(time.Second)
+	test.Tap(e.now)
+	got, err := time.Parse(time.RFC3339Nano, e.Text())
+	require.NoError(t, err)
+	assert.WithinDuration(t, before, got, 2*time.Second)
+	assert.NoError(t, e.Validate())
+}
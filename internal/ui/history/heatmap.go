@@ -0,0 +1,193 @@
+package history
+
+import (
+	"image/color"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+const (
+	heatmapMinHeight = float32(36)
+	heatmapColumnGap = float32(1)
+	heatmapEmptyMark = float32(2) // Height of the baseline drawn for empty buckets
+)
+
+// clientErrorCodes are codes caused by the request rather than the server
+var clientErrorCodes = map[string]bool{
+	"InvalidArgument":    true,
+	"NotFound":           true,
+	"AlreadyExists":      true,
+	"PermissionDenied":   true,
+	"Unauthenticated":    true,
+	"FailedPrecondition": true,
+	"OutOfRange":         true,
+}
+
+// StatusHeatmap draws time-bucketed history as columns stacked by status
+// code: green for OK, amber for client errors, red for server errors.
+// Column height is proportional to call count; tapping a column reports
+// its bucket.
+type StatusHeatmap struct {
+	widget.BaseWidget
+
+	buckets  []domain.StatusBucket
+	onTapped func(bucket domain.StatusBucket)
+}
+
+// NewStatusHeatmap creates an empty heatmap
+func NewStatusHeatmap() *StatusHeatmap {
+	h := &StatusHeatmap{}
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+// SetBuckets replaces the displayed buckets (oldest first)
+func (h *StatusHeatmap) SetBuckets(buckets []domain.StatusBucket) {
+	h.buckets = buckets
+	h.Refresh()
+}
+
+// SetOnBucketTapped sets the callback for when a column is tapped
+func (h *StatusHeatmap) SetOnBucketTapped(fn func(bucket domain.StatusBucket)) {
+	h.onTapped = fn
+}
+
+// Tapped implements fyne.Tappable, reporting the bucket under the pointer.
+func (h *StatusHeatmap) Tapped(ev *fyne.PointEvent) {
+	if h.onTapped == nil || len(h.buckets) == 0 {
+		return
+	}
+	width := h.Size().Width / float32(len(h.buckets))
+	i := int(ev.Position.X / width)
+	if i < 0 || i >= len(h.buckets) {
+		return
+	}
+	h.onTapped(h.buckets[i])
+}
+
+// CreateRenderer implements fyne.Widget.
+func (h *StatusHeatmap) CreateRenderer() fyne.WidgetRenderer {
+	r := &heatmapRenderer{heatmap: h}
+	r.rebuild()
+	return r
+}
+
+// heatmapSegment is one status code's share of a column.
+type heatmapSegment struct {
+	rect   *canvas.Rectangle
+	column int
+	count  int // Zero for the empty-bucket baseline
+}
+
+type heatmapRenderer struct {
+	heatmap  *StatusHeatmap
+	segments []heatmapSegment
+	objects  []fyne.CanvasObject
+}
+
+// rebuild creates one rectangle per (bucket, code), OK at the bottom.
+func (r *heatmapRenderer) rebuild() {
+	r.segments = r.segments[:0]
+	r.objects = r.objects[:0]
+	for i, bucket := range r.heatmap.buckets {
+		codes := sortedCodes(bucket.Counts)
+		if len(codes) == 0 {
+			rect := canvas.NewRectangle(theme.Color(theme.ColorNameSeparator))
+			r.segments = append(r.segments, heatmapSegment{rect: rect, column: i})
+			r.objects = append(r.objects, rect)
+			continue
+		}
+		for _, code := range codes {
+			rect := canvas.NewRectangle(codeColor(code))
+			r.segments = append(r.segments, heatmapSegment{rect: rect, column: i, count: bucket.Counts[code]})
+			r.objects = append(r.objects, rect)
+		}
+	}
+}
+
+func (r *heatmapRenderer) Layout(size fyne.Size) {
+	buckets := r.heatmap.buckets
+	if len(buckets) == 0 {
+		return
+	}
+
+	maxTotal := 0
+	for _, b := range buckets {
+		if t := b.Total(); t > maxTotal {
+			maxTotal = t
+		}
+	}
+
+	colWidth := size.Width / float32(len(buckets))
+	barWidth := colWidth - heatmapColumnGap
+	if barWidth < 1 {
+		barWidth = colWidth
+	}
+
+	// Stack segments upward from the bottom edge of each column
+	bottoms := make([]float32, len(buckets))
+	for i := range bottoms {
+		bottoms[i] = size.Height
+	}
+	for _, seg := range r.segments {
+		height := heatmapEmptyMark
+		if seg.count > 0 {
+			height = size.Height * float32(seg.count) / float32(maxTotal)
+		}
+		bottoms[seg.column] -= height
+		seg.rect.Move(fyne.NewPos(float32(seg.column)*colWidth, bottoms[seg.column]))
+		seg.rect.Resize(fyne.NewSize(barWidth, height))
+	}
+}
+
+func (r *heatmapRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(float32(len(r.heatmap.buckets))*2, heatmapMinHeight)
+}
+
+func (r *heatmapRenderer) Refresh() {
+	r.rebuild()
+	r.Layout(r.heatmap.Size())
+	canvas.Refresh(r.heatmap)
+}
+
+func (r *heatmapRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *heatmapRenderer) Destroy() {}
+
+// sortedCodes returns the codes with calls, OK first then alphabetical.
+func sortedCodes(counts map[string]int) []string {
+	codes := make([]string, 0, len(counts))
+	for code, n := range counts {
+		if n > 0 {
+			codes = append(codes, code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if (codes[i] == "OK") != (codes[j] == "OK") {
+			return codes[i] == "OK"
+		}
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// codeColor returns the fill for a status code's segment.
+func codeColor(code string) color.Color {
+	switch {
+	case code == "OK":
+		return theme.Color(theme.ColorNameSuccess)
+	case code == "Canceled":
+		return theme.Color(theme.ColorNameDisabled)
+	case clientErrorCodes[code]:
+		return theme.Color(theme.ColorNameWarning)
+	default:
+		return theme.Color(theme.ColorNameError)
+	}
+}
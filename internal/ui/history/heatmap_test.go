@@ -0,0 +1,118 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticBuckets builds consecutive 5-minute buckets with the given counts.
+func syntheticBuckets(counts ...map[string]int) []domain.StatusBucket {
+	start := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	buckets := make([]domain.StatusBucket, len(counts))
+	for i, c := range counts {
+		s := start.Add(time.Duration(i) * 5 * time.Minute)
+		buckets[i] = domain.StatusBucket{Start: s, End: s.Add(5 * time.Minute), Counts: c}
+	}
+	return buckets
+}
+
+// renderHeatmap lays out a heatmap at size and returns its rectangles.
+func renderHeatmap(t *testing.T, buckets []domain.StatusBucket, size fyne.Size) (*StatusHeatmap, []*canvas.Rectangle) {
+	t.Helper()
+	h := NewStatusHeatmap()
+	h.SetBuckets(buckets)
+	h.Resize(size)
+
+	r := test.WidgetRenderer(h)
+	r.Layout(size)
+	var rects []*canvas.Rectangle
+	for _, obj := range r.Objects() {
+		rect, ok := obj.(*canvas.Rectangle)
+		require.True(t, ok, "heatmap objects should be rectangles")
+		rects = append(rects, rect)
+	}
+	return h, rects
+}
+
+func TestStatusHeatmap_StacksSegmentsByCode(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	buckets := syntheticBuckets(
+		map[string]int{"OK": 2, "Unavailable": 2}, // tallest column: full height
+		map[string]int{"OK": 1, "NotFound": 1},    // half height
+	)
+	_, rects := renderHeatmap(t, buckets, fyne.NewSize(100, 40))
+	require.Len(t, rects, 4)
+
+	// Column 0: OK at the bottom, Unavailable on top, together full height
+	assert.Equal(t, theme.Color(theme.ColorNameSuccess), rects[0].FillColor)
+	assert.Equal(t, theme.Color(theme.ColorNameError), rects[1].FillColor)
+	assert.InDelta(t, 20, rects[0].Size().Height, 0.01)
+	assert.InDelta(t, 20, rects[1].Size().Height, 0.01)
+	assert.InDelta(t, 20, rects[0].Position().Y, 0.01)
+	assert.InDelta(t, 0, rects[1].Position().Y, 0.01)
+
+	// Column 1: client error in amber; half the height of column 0
+	assert.Equal(t, theme.Color(theme.ColorNameWarning), rects[3].FillColor)
+	assert.InDelta(t, 10, rects[2].Size().Height, 0.01)
+	assert.InDelta(t, 10, rects[3].Size().Height, 0.01)
+	assert.InDelta(t, 50, rects[2].Position().X, 0.01)
+	assert.InDelta(t, 20, rects[3].Position().Y, 0.01)
+}
+
+func TestStatusHeatmap_EmptyBucketsShowBaseline(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	buckets := syntheticBuckets(map[string]int{}, map[string]int{"OK": 3}, map[string]int{})
+	_, rects := renderHeatmap(t, buckets, fyne.NewSize(90, 40))
+	require.Len(t, rects, 3)
+
+	assert.Equal(t, theme.Color(theme.ColorNameSeparator), rects[0].FillColor)
+	assert.InDelta(t, heatmapEmptyMark, rects[0].Size().Height, 0.01)
+	assert.InDelta(t, 40-heatmapEmptyMark, rects[0].Position().Y, 0.01)
+	assert.InDelta(t, 40, rects[1].Size().Height, 0.01)
+	assert.InDelta(t, 60, rects[2].Position().X, 0.01)
+}
+
+func TestStatusHeatmap_RefreshRebuildsSegments(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	h, rects := renderHeatmap(t, syntheticBuckets(map[string]int{"OK": 1}), fyne.NewSize(50, 40))
+	require.Len(t, rects, 1)
+
+	h.SetBuckets(syntheticBuckets(
+		map[string]int{"OK": 1, "Internal": 1, "Canceled": 1},
+		map[string]int{"DeadlineExceeded": 4},
+	))
+	objects := test.WidgetRenderer(h).Objects()
+	require.Len(t, objects, 4)
+	assert.Equal(t, theme.Color(theme.ColorNameDisabled), objects[1].(*canvas.Rectangle).FillColor, "Canceled sorts after OK")
+}
+
+func TestStatusHeatmap_TappedReportsBucket(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	buckets := syntheticBuckets(map[string]int{"OK": 1}, map[string]int{}, map[string]int{"Internal": 2})
+	h, _ := renderHeatmap(t, buckets, fyne.NewSize(90, 40))
+
+	var tapped []time.Time
+	h.SetOnBucketTapped(func(b domain.StatusBucket) { tapped = append(tapped, b.Start) })
+
+	h.Tapped(&fyne.PointEvent{Position: fyne.NewPos(5, 20)})
+	h.Tapped(&fyne.PointEvent{Position: fyne.NewPos(75, 39)})
+	h.Tapped(&fyne.PointEvent{Position: fyne.NewPos(95, 10)}) // outside: ignored
+
+	assert.Equal(t, []time.Time{buckets[0].Start, buckets[2].Start}, tapped)
+}
@@ -37,6 +37,18 @@ type HistoryPanel struct {
 	filterQuery  string
 	statusFilter string                // "" (all), "success", or "error"
 	allEntries   []domain.HistoryEntry // full unfiltered entries from storage
+	bucketFilter *domain.StatusBucket  // set when a heatmap column is tapped
+
+	// Status heatmap for the selected method
+	heatmap       *StatusHeatmap
+	heatmapLabel  *widget.Label
+	clearBucket   *widget.Button
+	heatmapWindow heatmapWindow
+	method        string // Full method name the heatmap covers; empty for all
+
+	// Invocation statistics of the selected method on the current server
+	statsLabel  *widget.Label
+	methodStats map[string]domain.MethodStatsSummary // By full method name
 
 	// Empty state
 	placeholder *widget.Label
@@ -49,13 +61,28 @@ type HistoryPanel struct {
 	content *fyne.Container
 }
 
+// heatmapWindow is a selectable time range for the status heatmap
+type heatmapWindow struct {
+	label  string
+	span   time.Duration
+	bucket time.Duration
+}
+
+// heatmapWindows are the ranges offered above the history list
+var heatmapWindows = []heatmapWindow{
+	{label: "Last hour", span: time.Hour, bucket: 5 * time.Minute},
+	{label: "Last day", span: 24 * time.Hour, bucket: time.Hour},
+	{label: "Last week", span: 7 * 24 * time.Hour, bucket: 24 * time.Hour},
+}
+
 // NewHistoryPanel creates a new history panel
 func NewHistoryPanel(storage storage.Repository, logger *slog.Logger, window fyne.Window) *HistoryPanel {
 	p := &HistoryPanel{
-		storage:     storage,
-		logger:      logger,
-		window:      window,
-		historyList: binding.NewUntypedList(),
+		storage:       storage,
+		logger:        logger,
+		window:        window,
+		historyList:   binding.NewUntypedList(),
+		heatmapWindow: heatmapWindows[0],
 	}
 
 	p.ExtendBaseWidget(p)
@@ -107,6 +134,9 @@ func (p *HistoryPanel) buildUI() {
 			methodLabel.TextStyle = fyne.TextStyle{Bold: true}
 			statusLabel := widget.NewLabel("")
 			durationLabel := widget.NewLabel("")
+			connLabel := widget.NewLabel("")
+			connLabel.Importance = widget.LowImportance
+			connLabel.Truncation = fyne.TextTruncateEllipsis
 			replayButton := widget.NewButton("Replay", nil)
 			deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 
@@ -116,7 +146,7 @@ func (p *HistoryPanel) buildUI() {
 				nil, // left
 				container.NewHBox(replayButton, deleteButton), // right
 				container.NewVBox(
-					container.NewHBox(timeLabel, statusLabel, durationLabel),
+					container.NewBorder(nil, nil, container.NewHBox(timeLabel, statusLabel, durationLabel), nil, connLabel),
 					methodLabel,
 				),
 			)
@@ -145,14 +175,17 @@ func (p *HistoryPanel) buildUI() {
 			topRow := centerBox.Objects[0].(*fyne.Container)
 			methodLabel := centerBox.Objects[1].(*widget.Label)
 
-			timeLabel := topRow.Objects[0].(*widget.Label)
-			statusLabel := topRow.Objects[1].(*widget.Label)
-			durationLabel := topRow.Objects[2].(*widget.Label)
+			connLabel := topRow.Objects[0].(*widget.Label)
+			topLeft := topRow.Objects[1].(*fyne.Container)
+			timeLabel := topLeft.Objects[0].(*widget.Label)
+			statusLabel := topLeft.Objects[1].(*widget.Label)
+			durationLabel := topLeft.Objects[2].(*widget.Label)
 
 			// Format display
 			timeLabel.SetText(historyEntry.Timestamp.Format("15:04:05"))
 			methodLabel.SetText(p.formatMethodName(historyEntry.Method))
 			durationLabel.SetText(fmt.Sprintf("%dms", historyEntry.Duration.Milliseconds()))
+			connLabel.SetText(historyEntry.Connection.DisplayName())
 
 			// Status icon
 			if historyEntry.Status == "success" {
@@ -220,7 +253,12 @@ func (p *HistoryPanel) buildUI() {
 		p.filterEntry,
 	)
 
-	header := container.NewVBox(headerRow, filterRow, widget.NewSeparator())
+	p.statsLabel = widget.NewLabel("")
+	p.statsLabel.Importance = widget.LowImportance
+	p.statsLabel.Truncation = fyne.TextTruncateEllipsis
+	p.statsLabel.Hide()
+
+	header := container.NewVBox(headerRow, filterRow, p.buildHeatmap(), p.statsLabel, widget.NewSeparator())
 
 	// Empty state placeholder
 	p.placeholder = widget.NewLabel("No history yet — send a request to get started")
@@ -238,6 +276,117 @@ func (p *HistoryPanel) buildUI() {
 	)
 }
 
+// buildHeatmap creates the status heatmap row: a window selector, the
+// heatmap itself, and a caption that doubles as the bucket filter indicator.
+func (p *HistoryPanel) buildHeatmap() fyne.CanvasObject {
+	p.heatmap = NewStatusHeatmap()
+	p.heatmap.SetOnBucketTapped(func(bucket domain.StatusBucket) {
+		p.mu.Lock()
+		p.bucketFilter = &bucket
+		p.mu.Unlock()
+		p.applyFilter()
+	})
+
+	p.heatmapLabel = widget.NewLabel("")
+	p.heatmapLabel.TextStyle = fyne.TextStyle{Italic: true}
+	p.heatmapLabel.Truncation = fyne.TextTruncateEllipsis
+
+	p.clearBucket = widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		p.mu.Lock()
+		p.bucketFilter = nil
+		p.mu.Unlock()
+		p.applyFilter()
+	})
+	p.clearBucket.Importance = widget.LowImportance
+	p.clearBucket.Hide()
+
+	labels := make([]string, len(heatmapWindows))
+	for i, w := range heatmapWindows {
+		labels[i] = w.label
+	}
+	windowSelect := widget.NewSelect(labels, func(selected string) {
+		for _, w := range heatmapWindows {
+			if w.label == selected {
+				p.mu.Lock()
+				p.heatmapWindow = w
+				p.bucketFilter = nil
+				p.mu.Unlock()
+			}
+		}
+		p.refreshHeatmap()
+		p.applyFilter()
+	})
+	windowSelect.Selected = p.heatmapWindow.label // Refresh loads the initial buckets
+
+	caption := container.NewBorder(nil, nil, nil, container.NewHBox(p.clearBucket, windowSelect), p.heatmapLabel)
+	return container.NewVBox(caption, p.heatmap)
+}
+
+// SetMethod scopes the status heatmap to a full method name
+// ("pkg.Service/Method"); empty covers all methods.
+func (p *HistoryPanel) SetMethod(method string) {
+	p.mu.Lock()
+	p.method = method
+	p.bucketFilter = nil
+	p.mu.Unlock()
+	p.refreshHeatmap()
+	p.applyFilter()
+	fyne.Do(p.updateStatsLabel)
+}
+
+// SetMethodStats sets the invocation statistics of the methods called on
+// the current server, by full method name, and shows the selected
+// method's. Nil clears them.
+func (p *HistoryPanel) SetMethodStats(stats map[string]domain.MethodStatsSummary) {
+	p.mu.Lock()
+	p.methodStats = stats
+	p.mu.Unlock()
+	p.updateStatsLabel()
+}
+
+// updateStatsLabel shows the selected method's statistics, or hides the
+// label when it has none.
+func (p *HistoryPanel) updateStatsLabel() {
+	p.mu.Lock()
+	summary, ok := p.methodStats[p.method]
+	p.mu.Unlock()
+	if !ok || p.method == "" {
+		p.statsLabel.Hide()
+		return
+	}
+	p.statsLabel.SetText(summary.Details())
+	p.statsLabel.Show()
+}
+
+// refreshHeatmap reloads the heatmap buckets from storage.
+func (p *HistoryPanel) refreshHeatmap() {
+	if p.heatmap == nil {
+		return
+	}
+
+	p.mu.Lock()
+	window := p.heatmapWindow
+	method := p.method
+	p.mu.Unlock()
+
+	now := time.Now()
+	buckets, err := p.storage.HistoryStatusBuckets(domain.HistoryStatsQuery{
+		Method:   method,
+		Since:    now.Add(-window.span),
+		Until:    now.Add(time.Nanosecond), // include calls recorded this instant
+		Bucket:   window.bucket,
+		Location: time.Local,
+	})
+	if err != nil {
+		p.logger.Error("failed to load history status buckets", slog.Any("error", err))
+		return
+	}
+
+	fyne.Do(func() {
+		p.heatmap.SetBuckets(buckets)
+	})
+}
+
 // CreateRenderer implements the fyne.Widget interface
 func (p *HistoryPanel) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(p.content)
@@ -257,6 +406,7 @@ func (p *HistoryPanel) Refresh() {
 	p.mu.Lock()
 	p.allEntries = entries
 	p.mu.Unlock()
+	p.refreshHeatmap()
 	p.applyFilter()
 	p.logger.Debug("history refreshed", slog.Int("count", len(entries)))
 }
@@ -266,22 +416,36 @@ func (p *HistoryPanel) applyFilter() {
 	p.mu.Lock()
 	entries := make([]domain.HistoryEntry, len(p.allEntries))
 	copy(entries, p.allEntries)
+	bucket := p.bucketFilter
+	method := p.method
+	window := p.heatmapWindow
 	p.mu.Unlock()
 
 	var filtered []domain.HistoryEntry
 	for _, entry := range entries {
+		// Heatmap bucket filter: only calls to the method within the bucket
+		if bucket != nil {
+			if entry.Timestamp.Before(bucket.Start) || !entry.Timestamp.Before(bucket.End) {
+				continue
+			}
+			if method != "" && entry.Method != method {
+				continue
+			}
+		}
 		// Status filter
 		if p.statusFilter != "" && entry.Status != p.statusFilter {
 			continue
 		}
-		// Text filter: match against method name, request body, error message
+		// Text filter: match against method name, request body, error message, connection
 		if p.filterQuery != "" {
 			method := strings.ToLower(entry.Method)
 			request := strings.ToLower(entry.Request)
 			errMsg := strings.ToLower(entry.Error)
+			conn := strings.ToLower(entry.Connection.DisplayName())
 			if !strings.Contains(method, p.filterQuery) &&
 				!strings.Contains(request, p.filterQuery) &&
-				!strings.Contains(errMsg, p.filterQuery) {
+				!strings.Contains(errMsg, p.filterQuery) &&
+				!strings.Contains(conn, p.filterQuery) {
 				continue
 			}
 		}
@@ -299,7 +463,9 @@ func (p *HistoryPanel) applyFilter() {
 	}
 
 	fyne.Do(func() {
-		if p.filterQuery != "" || p.statusFilter != "" {
+		p.updateHeatmapLabel(bucket, method, window)
+
+		if p.filterQuery != "" || p.statusFilter != "" || bucket != nil {
 			p.statusLabel.SetText(fmt.Sprintf("History (%d of %d)", len(filtered), len(p.allEntries)))
 		} else {
 			p.statusLabel.SetText(fmt.Sprintf("History (%d)", len(p.allEntries)))
@@ -315,6 +481,34 @@ func (p *HistoryPanel) applyFilter() {
 	})
 }
 
+// updateHeatmapLabel describes the heatmap scope, or the active bucket
+// filter with a clear button.
+func (p *HistoryPanel) updateHeatmapLabel(bucket *domain.StatusBucket, method string, window heatmapWindow) {
+	if p.heatmapLabel == nil {
+		return
+	}
+	if bucket != nil {
+		p.heatmapLabel.SetText(fmt.Sprintf("Showing %s · %d calls", formatBucketRange(*bucket, window.bucket), bucket.Total()))
+		p.clearBucket.Show()
+		return
+	}
+	scope := "All methods"
+	if method != "" {
+		scope = p.formatMethodName(method)
+	}
+	p.heatmapLabel.SetText(scope + " by status")
+	p.clearBucket.Hide()
+}
+
+// formatBucketRange formats a bucket's time span in local time.
+func formatBucketRange(bucket domain.StatusBucket, width time.Duration) string {
+	start := bucket.Start.In(time.Local)
+	if width >= 24*time.Hour {
+		return start.Format("Mon 2 Jan")
+	}
+	return start.Format("15:04") + "–" + bucket.End.In(time.Local).Format("15:04")
+}
+
 // SetOnSelect sets the callback when user clicks a history item (load without sending)
 func (p *HistoryPanel) SetOnSelect(fn func(entry domain.HistoryEntry)) {
 	p.onSelect = fn
@@ -366,12 +560,16 @@ func (p *HistoryPanel) formatMethodName(fullMethod string) string {
 	return fmt.Sprintf("%s.%s", serviceName, methodName)
 }
 
-// AddEntry adds a new entry to history and refreshes the display
+// AddEntry adds a new entry to history, counts the call in its method's
+// statistics, and refreshes the display
 func (p *HistoryPanel) AddEntry(entry domain.HistoryEntry) error {
 	if err := p.storage.AddHistoryEntry(entry); err != nil {
 		p.logger.Error("failed to add history entry", slog.Any("error", err))
 		return err
 	}
+	if err := p.storage.RecordMethodCall(entry); err != nil {
+		p.logger.Error("failed to record method call", slog.Any("error", err))
+	}
 
 	p.Refresh()
 	return nil
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/ui/browser"
+	"github.com/shhac/grotto/internal/ui/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secretConnection has a secret in every place a connection keeps one.
+var secretConnection = domain.Connection{
+	Address:         "localhost:50051",
+	Auth:            domain.Auth{Type: domain.AuthBearer, Token: "auth-token"},
+	DefaultMetadata: map[string]string{"authorization": "Bearer default-token", "x-tenant": "acme"},
+	Proxy:           domain.ProxySettings{Type: domain.ProxyHTTP, Host: "proxy", Port: 3128, Username: "pu", Password: "proxy-password"},
+	TLS:             domain.TLSSettings{Enabled: true, ClientCertFile: "client.pem", ClientKeyFile: "client-key.pem"},
+}
+
+func TestHistoryCredentials_OffKeepsNoSecrets(t *testing.T) {
+	w := newTestMainWindow(t)
+	w.serviceBrowser = browser.NewServiceBrowser(w.state.Services, binding.NewString())
+	w.connectionBar = browser.NewConnectionBar(model.NewConnectionUIState(), w.window, w.app.Storage())
+	w.connectionBar.SetConnection(secretConnection)
+
+	requestMetadata := map[string]string{"authorization": "Bearer request-token"}
+	w.recordHistoryEntry("localhost:50051", "pkg.Svc/Get", "{}", requestMetadata, "{}", nil, nil, time.Second, nil)
+	w.historyWrites.Wait()
+	w.recordStreamHistoryEntry("localhost:50051", "pkg.Svc/Watch", "{}", requestMetadata, nil, nil, time.Second,
+		nil, "server_stream", 1)
+
+	entries, err := w.app.Storage().GetHistory(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	saved, err := json.Marshal(entries)
+	require.NoError(t, err)
+	for _, secret := range []string{"auth-token", "default-token", "proxy-password", "client-key.pem", "request-token"} {
+		assert.NotContains(t, string(saved), secret)
+	}
+	for _, e := range entries {
+		assert.Equal(t, "acme", e.Connection.DefaultMetadata["x-tenant"], "other defaults are kept")
+	}
+
+	// Kept when the user opts in
+	w.fyneApp.Preferences().SetBool(settings.PrefHistoryCredentials, true)
+	entry := w.historyCredentials(domain.HistoryEntry{Connection: secretConnection})
+	assert.Equal(t, secretConnection, entry.Connection)
+}
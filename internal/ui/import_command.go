@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/export"
+)
+
+// showImportCommand asks for a grpcurl command, e.g. one pasted from chat,
+// and loads the call it makes.
+func (w *MainWindow) showImportCommand() {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder(`grpcurl -plaintext -H 'x-tenant-id: acme' -d '{"id": "1"}' localhost:50051 pkg.Service/Method`)
+	entry.Wrapping = fyne.TextWrapBreak
+	entry.SetMinRowsVisible(8)
+
+	hint := widget.NewLabel("Its address, TLS flags, headers, method and body fill in the connection and request. " +
+		"Anything that cannot be imported is listed afterwards.")
+	hint.Wrapping = fyne.TextWrapWord
+	hint.Importance = widget.LowImportance
+
+	content := container.NewBorder(widget.NewLabel("Paste a grpcurl command:"), hint, nil, nil, entry)
+	d := dialog.NewCustomConfirm("Import grpcurl Command", "Import", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		req, problems, err := export.ParseGrpcurlCommand(entry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("cannot import command: %w", err), w.window)
+			return
+		}
+		w.importGrpcurlRequest(req, problems)
+	}, w.window)
+	d.Resize(fyne.NewSize(640, 360))
+	d.Show()
+	w.window.Canvas().Focus(entry)
+}
+
+// importGrpcurlRequest connects to the imported request's server, unless
+// already connected to it the same way, then loads the request. A command
+// without -protoset keeps the connection's current schema source.
+func (w *MainWindow) importGrpcurlRequest(req export.GrpcurlRequest, problems []string) {
+	w.logger.Info("importing grpcurl command",
+		slog.String("address", req.Address),
+		slog.String("method", req.Method),
+	)
+
+	conn := w.connectionBar.GetConnection()
+	currentServer, _ := w.state.CurrentServer.Get()
+	needsConnect := currentServer != req.Address || conn.TLS != req.TLS ||
+		conn.Authority != req.Authority || conn.UserAgent != req.UserAgent ||
+		(req.Protoset != "" && conn.DescriptorSetFile != req.Protoset)
+
+	load := func() {
+		fyne.Do(func() {
+			w.loadImportedRequest(req, problems)
+		})
+	}
+	if !needsConnect {
+		load()
+		return
+	}
+
+	conn.Address = req.Address
+	conn.TLS = req.TLS
+	conn.Authority, conn.UserAgent = req.Authority, req.UserAgent
+	if req.Protoset != "" {
+		conn.DescriptorSetFile = req.Protoset
+		conn.ProtoImportPaths = nil
+	}
+	w.connectionBar.SetConnection(conn)
+	w.handleConnect(conn)
+	w.waitForConnection(load, "while importing grpcurl command")
+}
+
+// loadImportedRequest selects the imported method and fills in its
+// metadata and messages, then lists what could not be imported.
+func (w *MainWindow) loadImportedRequest(req export.GrpcurlRequest, problems []string) {
+	w.requestPanel.SetMetadata(req.Metadata)
+
+	serviceName, methodName, _ := strings.Cut(req.Method, "/")
+	refClient := w.app.ReflectionClient()
+	if req.Method == "" || refClient == nil {
+		w.showImportProblems(problems)
+		return
+	}
+	methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		w.logger.Warn("imported method not found", slog.String("method", req.Method), slog.Any("error", err))
+		problems = append(problems, fmt.Sprintf("%s was not found on %s, so its body was not loaded", req.Method, req.Address))
+		w.showImportProblems(problems)
+		return
+	}
+	w.serviceBrowser.SelectMethod(serviceName, methodName)
+
+	msgs := req.Messages
+	if !req.Streaming && req.Body != "" {
+		msgs = []string{req.Body}
+	}
+	switch {
+	case methodDesc.IsStreamingClient() && methodDesc.IsStreamingServer():
+		if len(msgs) == 1 {
+			w.bidiPanel.SetMessage(prettyJSON(msgs[0]))
+		} else if len(msgs) > 1 {
+			w.bidiPanel.SetBatchMessages(msgs)
+		}
+	case methodDesc.IsStreamingClient():
+		if len(msgs) == 1 {
+			w.requestPanel.StreamingInput().SetCurrentMessage(prettyJSON(msgs[0]))
+		} else if len(msgs) > 1 {
+			w.requestPanel.StreamingInput().SetBatchMessages(msgs)
+		}
+	default:
+		body := "{}"
+		if len(msgs) > 0 {
+			body = prettyJSON(msgs[0])
+		}
+		if len(msgs) > 1 {
+			problems = append(problems, fmt.Sprintf("%s takes one request message, so only the first of %d was loaded", methodName, len(msgs)))
+		}
+		_ = w.state.Request.TextData.Set(body)
+		w.requestPanel.SyncTextToForm()
+	}
+
+	w.showImportProblems(problems)
+}
+
+// showImportProblems lists the parts of an imported command that were left
+// out, if any.
+func (w *MainWindow) showImportProblems(problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+	content := container.NewVBox(widget.NewLabel("Some of the command could not be imported:"))
+	for _, p := range problems {
+		lbl := widget.NewLabel("• " + p)
+		lbl.Wrapping = fyne.TextWrapWord
+		content.Add(lbl)
+	}
+	d := dialog.NewCustom("Import grpcurl Command", "OK", container.NewVScroll(content), w.window)
+	d.Resize(fyne.NewSize(500, 350))
+	d.Show()
+}
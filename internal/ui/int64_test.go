@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/shhac/grotto/internal/ui/form"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// counterEchoMethod builds, and serves an echo of:
+//
+//	message Counters { int64 signed = 1; uint64 unsigned = 2; }
+//	service Counter { rpc Echo(Counters) returns (Counters); }
+func counterEchoMethod(t *testing.T) (*grpc.Invoker, protoreflect.MethodDescriptor) {
+	t.Helper()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("int64test/counter.proto"),
+		Package: proto.String("int64test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Counters"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("signed"), JsonName: proto.String("signed"), Number: proto.Int32(1), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()},
+				{Name: proto.String("unsigned"), JsonName: proto.String("unsigned"), Number: proto.Int32(2), Label: opt, Type: descriptorpb.FieldDescriptorProto_TYPE_UINT64.Enum()},
+			},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Counter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name: proto.String("Echo"), InputType: proto.String(".int64test.Counters"), OutputType: proto.String(".int64test.Counters"),
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err, "failed to build test descriptor")
+	method := fd.Services().ByName("Counter").Methods().ByName("Echo")
+
+	srv := grpctest.StartServer(t, grpctest.WithService(func(s *gogrpc.Server) {
+		s.RegisterService(&gogrpc.ServiceDesc{
+			ServiceName: "int64test.Counter",
+			HandlerType: (*any)(nil),
+			Methods: []gogrpc.MethodDesc{{
+				MethodName: "Echo",
+				Handler: func(_ any, _ context.Context, dec func(any) error, _ gogrpc.UnaryServerInterceptor) (any, error) {
+					msg := dynamicpb.NewMessage(method.Input())
+					if err := dec(msg); err != nil {
+						return nil, err
+					}
+					return msg, nil
+				},
+			}},
+		}, struct{}{})
+	}))
+	return grpc.NewInvoker(srv.Conn, slog.New(slog.NewTextHandler(io.Discard, nil))), method
+}
+
+func TestInt64Precision_FormToResponse(t *testing.T) {
+	test.NewApp()
+	invoker, method := counterEchoMethod(t)
+
+	// 2^53 + 1 reads back as 2^53 through a float64
+	const signed, unsigned = "9007199254740993", "18446744073709551615"
+
+	request := form.NewFormBuilder(method.Input())
+	request.Build()
+	require.NoError(t, request.FromJSON(`{"signed": `+signed+`, "unsigned": "`+unsigned+`"}`))
+	body, err := request.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"signed": "`+signed+`", "unsigned": "`+unsigned+`"}`, body)
+
+	resp, _, _, err := invoker.InvokeUnary(context.Background(), method, body, nil)
+	require.NoError(t, err)
+	shown := prettyJSON(resp)
+	assert.Contains(t, shown, `"`+signed+`"`)
+	assert.Contains(t, shown, `"`+unsigned+`"`)
+
+	// Loading the response back into a form keeps every digit
+	response := form.NewFormBuilder(method.Output())
+	response.Build()
+	require.NoError(t, response.FromJSON(shown))
+	again, err := response.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, body, again)
+}
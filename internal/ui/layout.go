@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+)
+
+// Preference keys for window state persistence
+const (
+	prefWindowWidth  = "windowWidth"
+	prefWindowHeight = "windowHeight"
+	prefSplitMain    = "splitMain"
+	prefSplitBrowser = "splitBrowser"
+	prefSplitContent = "splitContent"
+	prefLastAddress  = "lastAddress"
+)
+
+// Bounds applied to saved layouts. Offsets outside the split range would
+// collapse a panel out of reach; sizes below the minimum are unusable.
+const (
+	minSplitOffset  = 0.05
+	maxSplitOffset  = 0.95
+	minWindowWidth  = 400
+	minWindowHeight = 300
+)
+
+// windowLayout is the window size, split positions, and connection
+// address restored at the next launch.
+type windowLayout struct {
+	Width, Height float32
+
+	SplitMain    float64 // service browser / panels
+	SplitBrowser float64 // service tree / workspace and history tabs
+	SplitContent float64 // request / response
+
+	Address string
+}
+
+// defaultWindowLayout is used for anything not saved, or saved corrupt.
+var defaultWindowLayout = windowLayout{
+	Width:        1200,
+	Height:       800,
+	SplitMain:    0.3,
+	SplitBrowser: 0.7,
+	SplitContent: 0.75,
+}
+
+// loadWindowLayout reads the saved layout from prefs, falling back to
+// defaultWindowLayout for missing or corrupt values.
+func loadWindowLayout(prefs fyne.Preferences) windowLayout {
+	d := defaultWindowLayout
+	return windowLayout{
+		Width:        float32(prefs.FloatWithFallback(prefWindowWidth, float64(d.Width))),
+		Height:       float32(prefs.FloatWithFallback(prefWindowHeight, float64(d.Height))),
+		SplitMain:    prefs.FloatWithFallback(prefSplitMain, d.SplitMain),
+		SplitBrowser: prefs.FloatWithFallback(prefSplitBrowser, d.SplitBrowser),
+		SplitContent: prefs.FloatWithFallback(prefSplitContent, d.SplitContent),
+		Address:      prefs.StringWithFallback(prefLastAddress, d.Address),
+	}.sanitized()
+}
+
+// save writes the layout to prefs.
+func (l windowLayout) save(prefs fyne.Preferences) {
+	prefs.SetFloat(prefWindowWidth, float64(l.Width))
+	prefs.SetFloat(prefWindowHeight, float64(l.Height))
+	prefs.SetFloat(prefSplitMain, l.SplitMain)
+	prefs.SetFloat(prefSplitBrowser, l.SplitBrowser)
+	prefs.SetFloat(prefSplitContent, l.SplitContent)
+	prefs.SetString(prefLastAddress, l.Address)
+}
+
+// sanitized returns l with non-finite values replaced by the defaults,
+// split offsets clamped to a range that keeps every panel visible, and
+// the window no smaller than the minimum size.
+func (l windowLayout) sanitized() windowLayout {
+	d := defaultWindowLayout
+	l.Width = clampSize(l.Width, minWindowWidth, d.Width)
+	l.Height = clampSize(l.Height, minWindowHeight, d.Height)
+	l.SplitMain = clampOffset(l.SplitMain, d.SplitMain)
+	l.SplitBrowser = clampOffset(l.SplitBrowser, d.SplitBrowser)
+	l.SplitContent = clampOffset(l.SplitContent, d.SplitContent)
+	return l
+}
+
+// clampOffset limits a split offset to [minSplitOffset, maxSplitOffset],
+// using def when the value is not a number.
+func clampOffset(v, def float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return def
+	}
+	return math.Min(math.Max(v, minSplitOffset), maxSplitOffset)
+}
+
+// clampSize raises a window dimension to lo, using def when the value is
+// not a positive number.
+func clampSize(v, lo, def float32) float32 {
+	f := float64(v)
+	if math.IsNaN(f) || math.IsInf(f, 0) || v <= 0 {
+		return def
+	}
+	return max(v, lo)
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"math"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowLayout_RoundTrip(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	prefs := app.Preferences()
+
+	assert.Equal(t, defaultWindowLayout, loadWindowLayout(prefs), "nothing saved yet")
+
+	saved := windowLayout{
+		Width:        1440,
+		Height:       900,
+		SplitMain:    0.25,
+		SplitBrowser: 0.6,
+		SplitContent: 0.5,
+		Address:      "api.example.com:443",
+	}
+	saved.save(prefs)
+	assert.Equal(t, saved, loadWindowLayout(prefs))
+}
+
+func TestWindowLayout_CorruptValues(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	prefs := app.Preferences()
+
+	prefs.SetString(prefWindowWidth, "wide")
+	prefs.SetFloat(prefWindowHeight, math.NaN())
+	prefs.SetFloat(prefSplitMain, math.Inf(1))
+	prefs.SetBool(prefSplitContent, true)
+
+	got := loadWindowLayout(prefs)
+	d := defaultWindowLayout
+	assert.Equal(t, d.Width, got.Width)
+	assert.Equal(t, d.Height, got.Height)
+	assert.Equal(t, d.SplitMain, got.SplitMain)
+	assert.Equal(t, d.SplitContent, got.SplitContent)
+}
+
+func TestWindowLayout_Sanitized(t *testing.T) {
+	got := windowLayout{
+		Width:        -5,
+		Height:       120,
+		SplitMain:    -0.2,
+		SplitBrowser: 1.7,
+		SplitContent: 0.5,
+	}.sanitized()
+
+	assert.Equal(t, defaultWindowLayout.Width, got.Width, "non-positive size uses the default")
+	assert.Equal(t, float32(minWindowHeight), got.Height)
+	assert.Equal(t, minSplitOffset, got.SplitMain)
+	assert.Equal(t, maxSplitOffset, got.SplitBrowser)
+	assert.Equal(t, 0.5, got.SplitContent)
+}
@@ -0,0 +1,302 @@
+// Package logview shows the application's recent log records in a window of
+// their own.
+package logview
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	fynestorage "fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/shhac/grotto/internal/logging"
+)
+
+// levels are the level filter choices, least severe first.
+var levels = []struct {
+	name  string
+	level slog.Level
+}{
+	{"Debug", slog.LevelDebug},
+	{"Info", slog.LevelInfo},
+	{"Warn", slog.LevelWarn},
+	{"Error", slog.LevelError},
+}
+
+// refreshInterval is how often an open viewer looks for new records.
+const refreshInterval = time.Second
+
+// Viewer is the Log window. It lists the records kept by a
+// logging.RingHandler, filtered by level and text, and picks up new ones
+// while open. Tapping rows selects them for copying.
+type Viewer struct {
+	app    fyne.App
+	logs   *logging.RingHandler
+	window fyne.Window // nil while closed
+	stop   chan struct{}
+
+	shown    []logging.Entry // Entries passing the filters, oldest first
+	seq      uint64          // logs.Seq() when shown was built
+	selected map[uint64]bool // Entry.Seq of selected rows
+	level    slog.Level
+	text     string
+
+	list        *widget.List
+	levelSelect *widget.Select
+	filterEntry *widget.Entry
+	countLabel  *widget.Label
+	copyBtn     *widget.Button
+	follow      *widget.Check // Scroll to new records as they arrive
+}
+
+// NewViewer creates a viewer for the records in logs. The window is created
+// when first shown.
+func NewViewer(app fyne.App, logs *logging.RingHandler) *Viewer {
+	return &Viewer{
+		app:      app,
+		logs:     logs,
+		selected: make(map[uint64]bool),
+		level:    levels[0].level,
+	}
+}
+
+// Show opens the Log window, or brings it to the front if it is open.
+func (v *Viewer) Show() {
+	if v.window != nil {
+		v.window.RequestFocus()
+		return
+	}
+
+	v.window = v.app.NewWindow("Grotto Logs")
+	v.window.SetContent(v.build())
+	v.window.Resize(fyne.NewSize(900, 550))
+	v.window.SetOnClosed(v.closed)
+	v.refresh()
+	v.startRefreshing()
+	v.window.Show()
+}
+
+// build creates the window's widgets.
+func (v *Viewer) build() fyne.CanvasObject {
+	v.list = widget.NewList(
+		func() int { return len(v.shown) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(theme.Color(theme.ColorNameSelection))
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			label.Truncation = fyne.TextTruncateEllipsis
+			return container.NewStack(bg, label)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.shown) {
+				return
+			}
+			e := v.shown[id]
+			row := obj.(*fyne.Container)
+			bg := row.Objects[0].(*canvas.Rectangle)
+			bg.Hidden = !v.selected[e.Seq]
+			label := row.Objects[1].(*widget.Label)
+			label.Importance = levelImportance(e.Level)
+			label.SetText(e.String())
+		},
+	)
+	v.list.OnSelected = func(id widget.ListItemID) {
+		v.list.Unselect(id)
+		v.toggle(id)
+	}
+
+	names := make([]string, len(levels))
+	for i, l := range levels {
+		names[i] = l.name
+	}
+	v.levelSelect = widget.NewSelect(names, func(name string) {
+		for _, l := range levels {
+			if l.name == name {
+				v.level = l.level
+			}
+		}
+		v.refresh()
+	})
+	v.levelSelect.Selected = levels[0].name
+
+	v.filterEntry = widget.NewEntry()
+	v.filterEntry.SetPlaceHolder("Filter messages and attributes...")
+	v.filterEntry.OnChanged = func(text string) {
+		v.text = text
+		v.refresh()
+	}
+
+	v.countLabel = widget.NewLabel("")
+	v.countLabel.Importance = widget.LowImportance
+	v.copyBtn = widget.NewButtonWithIcon("Copy Selected", theme.ContentCopyIcon(), v.copySelected)
+	v.copyBtn.Disable()
+	saveBtn := widget.NewButtonWithIcon("Save Logs...", theme.DocumentSaveIcon(), v.save)
+	v.follow = widget.NewCheck("Follow", func(on bool) {
+		if on {
+			v.list.ScrollToBottom()
+		}
+	})
+	v.follow.Checked = true
+
+	toolbar := container.NewBorder(nil, nil,
+		v.levelSelect,
+		container.NewHBox(v.countLabel, v.follow, v.copyBtn, saveBtn),
+		v.filterEntry,
+	)
+	return container.NewBorder(toolbar, nil, nil, nil, v.list)
+}
+
+// levelImportance colours a row by its level.
+func levelImportance(level slog.Level) widget.Importance {
+	switch {
+	case level >= slog.LevelError:
+		return widget.DangerImportance
+	case level >= slog.LevelWarn:
+		return widget.WarningImportance
+	case level < slog.LevelInfo:
+		return widget.LowImportance
+	}
+	return widget.MediumImportance
+}
+
+// refresh rebuilds the list from the kept records, scrolling to the newest
+// when following.
+func (v *Viewer) refresh() {
+	if v.list == nil {
+		return
+	}
+	v.seq = v.logs.Seq()
+	entries := v.logs.Entries()
+	v.shown = logging.FilterEntries(entries, v.level, v.text)
+
+	// Forget selections that have scrolled out of the buffer or the filter
+	kept := make(map[uint64]bool, len(v.selected))
+	for _, e := range v.shown {
+		if v.selected[e.Seq] {
+			kept[e.Seq] = true
+		}
+	}
+	v.selected = kept
+
+	total := len(entries)
+	if len(v.shown) == total {
+		v.countLabel.SetText(fmt.Sprintf("%d records", total))
+	} else {
+		v.countLabel.SetText(fmt.Sprintf("%d of %d records", len(v.shown), total))
+	}
+	v.updateCopyButton()
+	v.list.Refresh()
+	if v.follow.Checked {
+		v.list.ScrollToBottom()
+	}
+}
+
+// toggle selects or deselects the row at id.
+func (v *Viewer) toggle(id widget.ListItemID) {
+	if id >= len(v.shown) {
+		return
+	}
+	seq := v.shown[id].Seq
+	if v.selected[seq] {
+		delete(v.selected, seq)
+	} else {
+		v.selected[seq] = true
+	}
+	v.updateCopyButton()
+	v.list.RefreshItem(id)
+}
+
+func (v *Viewer) updateCopyButton() {
+	if len(v.selected) == 0 {
+		v.copyBtn.Disable()
+	} else {
+		v.copyBtn.Enable()
+	}
+}
+
+// selectedText returns the selected rows as lines, oldest first.
+func (v *Viewer) selectedText() string {
+	var lines []string
+	for _, e := range v.shown {
+		if v.selected[e.Seq] {
+			lines = append(lines, e.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shownText returns every row passing the filters as lines, oldest first.
+func (v *Viewer) shownText() string {
+	lines := make([]string, len(v.shown))
+	for i, e := range v.shown {
+		lines[i] = e.String()
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (v *Viewer) copySelected() {
+	if text := v.selectedText(); text != "" {
+		v.app.Clipboard().SetContent(text)
+	}
+}
+
+// save writes the rows passing the filters to a file.
+func (v *Viewer) save() {
+	text := v.shownText()
+	d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, v.window)
+			return
+		}
+		if writer == nil {
+			return // User cancelled
+		}
+		defer writer.Close()
+		if _, err := writer.Write([]byte(text)); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save logs: %w", err), v.window)
+		}
+	}, v.window)
+	d.SetFilter(fynestorage.NewExtensionFileFilter([]string{".log", ".txt"}))
+	d.SetFileName("grotto-" + time.Now().Format("20060102-150405") + ".log")
+	d.Show()
+}
+
+// startRefreshing picks up new records while the window is open.
+func (v *Viewer) startRefreshing() {
+	stop := make(chan struct{})
+	v.stop = stop
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fyne.Do(func() {
+					if v.window != nil && v.logs.Seq() != v.seq {
+						v.refresh()
+					}
+				})
+			}
+		}
+	}()
+}
+
+// closed stops refreshing and lets the next Show build a new window.
+func (v *Viewer) closed() {
+	if v.stop != nil {
+		close(v.stop)
+		v.stop = nil
+	}
+	v.window = nil
+	v.list = nil
+	v.selected = make(map[uint64]bool)
+}
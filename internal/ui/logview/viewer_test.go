@@ -0,0 +1,68 @@
+package logview
+
+import (
+	"log/slog"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shhac/grotto/internal/logging"
+)
+
+func TestViewer(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	logs := logging.NewRingHandler(10, slog.LevelDebug)
+	logger := slog.New(logs)
+	logger.Debug("dialing", slog.String("address", "localhost:50051"))
+	logger.Info("connected", slog.String("address", "localhost:50051"))
+	logger.Warn("service resolution failed", slog.String("service", "pkg.Broken"))
+
+	v := NewViewer(app, logs)
+	v.Show()
+	defer v.window.Close()
+	require.Len(t, v.shown, 3)
+	assert.Equal(t, "3 records", v.countLabel.Text)
+
+	v.levelSelect.SetSelected("Info")
+	assert.Equal(t, "2 of 3 records", v.countLabel.Text)
+	v.filterEntry.SetText("BROKEN")
+	require.Len(t, v.shown, 1)
+	assert.Equal(t, "service resolution failed", v.shown[0].Message)
+
+	// Selections survive new records and are copied oldest first
+	v.filterEntry.SetText("")
+	assert.True(t, v.copyBtn.Disabled())
+	v.list.Select(1)
+	v.list.Select(0)
+	assert.False(t, v.copyBtn.Disabled())
+	logger.Error("RPC invocation failed")
+	v.refresh()
+	require.Len(t, v.shown, 3)
+	assert.Len(t, v.selected, 2)
+	assert.Equal(t, v.shown[0].String()+"\n"+v.shown[1].String(), v.selectedText())
+
+	v.copySelected()
+	assert.Equal(t, v.selectedText(), app.Clipboard().Content())
+
+	// Tapping a selected row deselects it
+	v.list.Select(0)
+	assert.Equal(t, v.shown[1].String(), v.selectedText())
+}
+
+func TestViewer_ReopensAfterClose(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	v := NewViewer(app, logging.NewRingHandler(10, slog.LevelDebug))
+	v.Show()
+	v.window.Close()
+	assert.Nil(t, v.window)
+
+	v.Show()
+	assert.NotNil(t, v.window)
+	v.window.Close()
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"fyne.io/fyne/v2/dialog"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/browser"
+	"github.com/shhac/grotto/internal/ui/form"
+)
+
+// methodActions carries out the service browser's method context menu.
+type methodActions struct {
+	w *MainWindow
+}
+
+var _ browser.MethodActions = methodActions{}
+
+// InvokeEmpty selects the method and sends {} with the current metadata.
+// Streaming methods are confirmed first, since they stay open.
+func (a methodActions) InvokeEmpty(service domain.Service, method domain.Method) {
+	w := a.w
+	invoke := func() {
+		w.serviceBrowser.SelectMethod(service.FullName, method.Name)
+		_ = w.state.Request.TextData.Set("{}")
+		w.requestPanel.SyncTextToForm()
+
+		metadata := w.requestPanel.GetMetadata()
+		switch {
+		case method.IsClientStream && method.IsServerStream:
+			w.handleBidiStreamSend("{}", metadata)
+		case method.IsClientStream:
+			w.handleClientStreamFinish(metadata)
+		default:
+			w.handleSendRequest("{}", metadata)
+		}
+	}
+
+	if !method.IsClientStream && !method.IsServerStream {
+		invoke()
+		return
+	}
+	dialog.ShowConfirm("Invoke Streaming Method",
+		fmt.Sprintf("%s is a %s method. Start it with an empty request?", method.Name, method.MethodType()),
+		func(confirmed bool) {
+			if confirmed {
+				invoke()
+			}
+		},
+		w.window,
+	)
+}
+
+// CopyMethodName copies the method's name as grpcurl takes it.
+func (a methodActions) CopyMethodName(service domain.Service, method domain.Method) {
+	a.w.window.Clipboard().SetContent(service.FullName + "/" + method.Name)
+}
+
+// CopyInputType copies the full name of the method's input message.
+func (a methodActions) CopyInputType(_ domain.Service, method domain.Method) {
+	a.w.window.Clipboard().SetContent(method.InputType)
+}
+
+// GenerateTemplate selects the method and replaces the request with a
+// template of its input message, whatever the editor held.
+func (a methodActions) GenerateTemplate(service domain.Service, method domain.Method) {
+	w := a.w
+	w.serviceBrowser.SelectMethod(service.FullName, method.Name)
+
+	refClient := w.app.ReflectionClient()
+	if refClient == nil {
+		return
+	}
+	methodDesc, err := refClient.GetMethodDescriptor(service.FullName, method.Name)
+	if err != nil {
+		w.logger.Error("failed to get method descriptor", slog.Any("error", err))
+		return
+	}
+	template, err := form.GenerateTemplate(methodDesc.Input(), form.TemplateOptions{})
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("generate template: %w", err), w.window)
+		return
+	}
+	w.lastTemplate = template
+	_ = w.state.Request.TextData.Set(template)
+	w.requestPanel.SyncTextToForm()
+}
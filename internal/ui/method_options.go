@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/ui/components"
+)
+
+// showMethodOptions shows the options of the selected method, its service,
+// and its request and response types, such as google.api.http routes.
+// Custom options resolve against the connection's schema; unresolvable
+// ones are listed raw.
+func (w *MainWindow) showMethodOptions() {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
+	refClient := w.app.ReflectionClient()
+	if serviceName == "" || methodName == "" || refClient == nil {
+		dialog.ShowInformation("Method Options", "Select a method to see its options.", w.window)
+		return
+	}
+	methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		w.logger.Error("failed to get method descriptor", slog.Any("error", err))
+		dialog.ShowError(err, w.window)
+		return
+	}
+
+	opts, err := grpc.MethodOptions(methodDesc, w.typeResolver())
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to read options: %w", err), w.window)
+		return
+	}
+	if len(opts) == 0 {
+		dialog.ShowInformation("Method Options",
+			fmt.Sprintf("%s, its service, and its request and response types set no options.", methodName),
+			w.window)
+		return
+	}
+	data, err := json.MarshalIndent(opts, "", "  ")
+	if err != nil {
+		dialog.ShowError(err, w.window)
+		return
+	}
+
+	view := components.NewJSONView()
+	view.SetText(string(data))
+	title := widget.NewLabel(string(methodDesc.FullName()))
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	d := dialog.NewCustom("Method Options", "Close", container.NewBorder(title, nil, nil, nil, view), w.window)
+	d.Resize(fyne.NewSize(600, 450))
+	d.Show()
+}
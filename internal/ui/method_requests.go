@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// maxMethodRequests caps how many methods have their last request
+// remembered; the least recently used are forgotten first.
+const maxMethodRequests = 100
+
+// methodRequests remembers the request last written for each method, by
+// "service/method", so switching back to a method restores it. It keeps at
+// most max methods, forgetting the least recently used.
+type methodRequests struct {
+	mu       sync.Mutex
+	max      int
+	requests map[string]domain.Request
+	order    []string // Methods, least recently used first
+}
+
+func newMethodRequests(max int) *methodRequests {
+	return &methodRequests{max: max, requests: make(map[string]domain.Request)}
+}
+
+// Get returns the request remembered for method, marking it used.
+func (m *methodRequests) Get(method string) (domain.Request, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	req, ok := m.requests[method]
+	if ok {
+		m.touch(method)
+	}
+	return req, ok
+}
+
+// Put remembers req as method's request, forgetting the least recently
+// used method when there are too many.
+func (m *methodRequests) Put(method string, req domain.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	req.Metadata = maps.Clone(req.Metadata)
+	m.requests[method] = req
+	m.touch(method)
+	for len(m.order) > m.max {
+		delete(m.requests, m.order[0])
+		m.order = m.order[1:]
+	}
+}
+
+// Clear forgets every method's request.
+func (m *methodRequests) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.requests)
+	m.order = nil
+}
+
+// Saved returns the remembered requests for a workspace, least recently
+// used first, so putting them back in order keeps their recency.
+func (m *methodRequests) Saved() []domain.SavedRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := make([]domain.SavedRequest, 0, len(m.order))
+	for _, method := range m.order {
+		req := m.requests[method]
+		req.Metadata = maps.Clone(req.Metadata)
+		saved = append(saved, domain.SavedRequest{Name: method, Request: req})
+	}
+	return saved
+}
+
+// touch makes method the most recently used. The caller holds m.mu.
+func (m *methodRequests) touch(method string) {
+	if i := slices.Index(m.order, method); i >= 0 {
+		m.order = slices.Delete(m.order, i, i+1)
+	}
+	m.order = append(m.order, method)
+}
+
+// stashMethodRequest remembers the request in the editor as the one last
+// written for service/method, unless the body is empty or was written for
+// another method.
+func (w *MainWindow) stashMethodRequest(service, method string) {
+	if service == "" || method == "" {
+		return
+	}
+	body, _ := w.state.Request.TextData.Get()
+	if body == "" || !w.requestPanel.BodyFits() {
+		return
+	}
+	key := service + "/" + method
+	w.methodRequests.Put(key, domain.Request{
+		Method:   key,
+		Body:     body,
+		Metadata: w.requestPanel.GetMetadata(),
+	})
+}
+
+// restoreMethodRequest puts a remembered request back in the editor. Its
+// metadata replaces the current metadata only when there was any, so
+// headers added since on another method are not lost to an empty list.
+func (w *MainWindow) restoreMethodRequest(req domain.Request) {
+	_ = w.state.Request.TextData.Set(req.Body)
+	if len(req.Metadata) > 0 {
+		w.requestPanel.SetMetadata(req.Metadata)
+	}
+	w.requestPanel.SyncTextToForm()
+}
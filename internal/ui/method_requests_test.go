@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/shhac/grotto/internal/controller"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/storage"
+	"github.com/shhac/grotto/internal/ui/history"
+	"github.com/shhac/grotto/internal/ui/request"
+	"github.com/shhac/grotto/internal/ui/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestMethodRequests_LRU(t *testing.T) {
+	m := newMethodRequests(3)
+	for _, method := range []string{"a", "b", "c"} {
+		m.Put(method, domain.Request{Body: method})
+	}
+
+	// Using a marks it recent, so b is the one forgotten
+	_, ok := m.Get("a")
+	require.True(t, ok)
+	m.Put("d", domain.Request{Body: "d"})
+	_, ok = m.Get("b")
+	assert.False(t, ok, "least recently used is forgotten")
+
+	// Replacing a method's request keeps one entry for it
+	m.Put("c", domain.Request{Body: "c2"})
+	req, ok := m.Get("c")
+	require.True(t, ok)
+	assert.Equal(t, "c2", req.Body)
+
+	var order []string
+	for _, saved := range m.Saved() {
+		order = append(order, saved.Name)
+	}
+	assert.Equal(t, []string{"a", "d", "c"}, order, "least recently used first")
+
+	// Saved requests put back in order keep their recency
+	restored := newMethodRequests(3)
+	for _, saved := range m.Saved() {
+		restored.Put(saved.Name, saved.Request)
+	}
+	restored.Put("e", domain.Request{})
+	_, ok = restored.Get("a")
+	assert.False(t, ok)
+	_, ok = restored.Get("d")
+	assert.True(t, ok)
+
+	m.Clear()
+	assert.Empty(t, m.Saved())
+}
+
+func TestMethodRequests_Cap(t *testing.T) {
+	m := newMethodRequests(maxMethodRequests)
+	for i := range maxMethodRequests + 20 {
+		m.Put(fmt.Sprintf("svc/M%d", i), domain.Request{})
+	}
+	saved := m.Saved()
+	require.Len(t, saved, maxMethodRequests)
+	assert.Equal(t, "svc/M20", saved[0].Name)
+}
+
+func TestMethodRequests_CopiesMetadata(t *testing.T) {
+	m := newMethodRequests(1)
+	md := map[string]string{"x-a": "1"}
+	m.Put("a", domain.Request{Metadata: md})
+	md["x-a"] = "2"
+	req, _ := m.Get("a")
+	assert.Equal(t, "1", req.Metadata["x-a"])
+}
+
+// windowTestApp is the part of the app a MainWindow needs to select
+// methods, with descriptors in place of a connection.
+type windowTestApp struct {
+	AppController
+	storage   storage.Repository
+	refClient *grpc.ReflectionClient
+	invoker   controller.Invoker // nil leaves calls unable to be made
+}
+
+func (a *windowTestApp) Storage() storage.Repository               { return a.storage }
+func (a *windowTestApp) ReflectionClient() *grpc.ReflectionClient  { return a.refClient }
+func (a *windowTestApp) MethodResolver() controller.MethodResolver { return a.refClient }
+func (a *windowTestApp) MethodInvoker() controller.Invoker         { return a.invoker }
+
+// userServiceFiles describes:
+//
+//	message GetUserRequest { string id = 1; }
+//	message ListUsersRequest { int32 page = 1; }
+//	service UserService {
+//	  rpc GetUser(GetUserRequest) returns (GetUserRequest);
+//	  rpc ListUsers(ListUsersRequest) returns (ListUsersRequest);
+//	}
+func userServiceFiles() []*descriptorpb.FileDescriptorProto {
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	field := func(name string, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name), JsonName: proto.String(name), Number: proto.Int32(1), Label: opt, Type: typ.Enum(),
+		}
+	}
+	method := func(name, input string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name: proto.String(name), InputType: proto.String(".rememberedtest." + input), OutputType: proto.String(".rememberedtest." + input),
+		}
+	}
+	return []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("rememberedtest/user.proto"),
+		Package: proto.String("rememberedtest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("GetUserRequest"), Field: []*descriptorpb.FieldDescriptorProto{field("id", descriptorpb.FieldDescriptorProto_TYPE_STRING)}},
+			{Name: proto.String("ListUsersRequest"), Field: []*descriptorpb.FieldDescriptorProto{field("page", descriptorpb.FieldDescriptorProto_TYPE_INT32)}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("UserService"),
+			Method: []*descriptorpb.MethodDescriptorProto{method("GetUser", "GetUserRequest"), method("ListUsers", "ListUsersRequest")},
+		}},
+	}}
+}
+
+// newTestMainWindow builds the panels a MainWindow uses to select unary
+// methods of userServiceFiles.
+func newTestMainWindow(t *testing.T) *MainWindow {
+	t.Helper()
+	a := test.NewApp()
+	t.Cleanup(a.Quit)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	refClient, err := grpc.NewReflectionClientFromDescriptors(nil, userServiceFiles(), logger)
+	require.NoError(t, err)
+	app := &windowTestApp{storage: storage.NewJSONRepository(t.TempDir(), logger), refClient: refClient}
+
+	window := a.NewWindow("test")
+	state := model.NewApplicationState()
+	// Forms are built as each method is selected, on the test's goroutine
+	requestPanel := request.NewRequestPanel(state.Request, logger)
+	requestPanel.SetFormBuildDelay(0)
+	return &MainWindow{
+		window:         window,
+		fyneApp:        a,
+		state:          state,
+		logger:         logger,
+		app:            app,
+		methodRequests: newMethodRequests(maxMethodRequests),
+		requestPanel:   requestPanel,
+		responsePanel:  response.NewResponsePanel(state.Response, window),
+		historyPanel:   history.NewHistoryPanel(app.storage, logger, window),
+		requests:       controller.NewRequestController(app, logger),
+		connection:     controller.NewConnectionController(),
+	}
+}
+
+func TestHandleMethodSelect_RestoresRememberedRequest(t *testing.T) {
+	w := newTestMainWindow(t)
+	service := domain.Service{Name: "UserService", FullName: "rememberedtest.UserService"}
+	getUser := domain.Method{Name: "GetUser"}
+	listUsers := domain.Method{Name: "ListUsers"}
+	body := func() string {
+		text, _ := w.state.Request.TextData.Get()
+		return text
+	}
+
+	// A method never visited gets its template
+	w.handleMethodSelect(service, getUser)
+	assert.JSONEq(t, `{"id": ""}`, body())
+	_ = w.state.Request.TextData.Set(`{"id": "u-1"}`)
+	w.requestPanel.SetMetadata(map[string]string{"x-tenant": "a"})
+
+	w.handleMethodSelect(service, listUsers)
+	assert.JSONEq(t, `{"page": 0}`, body())
+	_ = w.state.Request.TextData.Set(`{"page": 3}`)
+	w.requestPanel.SetMetadata(map[string]string{"x-tenant": "b"})
+
+	// Each method gets back what was written in it, template or not
+	w.handleMethodSelect(service, getUser)
+	assert.JSONEq(t, `{"id": "u-1"}`, body())
+	assert.Equal(t, map[string]string{"x-tenant": "a"}, w.requestPanel.GetMetadata())
+
+	w.handleMethodSelect(service, listUsers)
+	assert.JSONEq(t, `{"page": 3}`, body())
+	assert.Equal(t, map[string]string{"x-tenant": "b"}, w.requestPanel.GetMetadata())
+
+	// Remembered requests are saved with the workspace and restored from it
+	ws := w.captureWorkspaceState()
+	require.Len(t, ws.Requests, 2)
+	assert.Equal(t, "rememberedtest.UserService/GetUser", ws.Requests[0].Name, "least recently used first")
+	assert.Equal(t, map[string]string{"x-tenant": "b"}, ws.Requests[1].Request.Metadata)
+
+	w.methodRequests.Clear()
+	w.applyWorkspaceState(domain.Workspace{Requests: ws.Requests})
+	assert.Equal(t, ws.Requests, w.methodRequests.Saved())
+}
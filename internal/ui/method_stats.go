@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// loadMethodStats shows the invocation statistics of the methods called on
+// the current server beside them in the service browser, and the selected
+// method's in the history panel. Without a server they are cleared.
+func (w *MainWindow) loadMethodStats() {
+	address, _ := w.state.CurrentServer.Get()
+	var summaries map[string]domain.MethodStatsSummary
+	if address != "" {
+		stats, err := w.app.Storage().GetMethodStats(address)
+		if err != nil {
+			w.logger.Error("failed to load method stats", slog.Any("error", err))
+			return
+		}
+		summaries = make(map[string]domain.MethodStatsSummary, len(stats))
+		for _, s := range stats {
+			summaries[s.Method] = s.Summary()
+		}
+	}
+
+	fyne.Do(func() {
+		w.serviceBrowser.SetMethodStats(summaries)
+		w.historyPanel.SetMethodStats(summaries)
+	})
+}
@@ -0,0 +1,57 @@
+package request
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// initBodyNote creates the banner noting a body carried over from a
+// method with another input type, hidden until SetBodyNote shows it.
+func (p *RequestPanel) initBodyNote() {
+	p.bodyNoteLabel = widget.NewLabel("")
+	p.bodyNoteLabel.Importance = widget.WarningImportance
+	p.bodyNoteLabel.Wrapping = fyne.TextWrapWord
+	// Dismissing accepts the body as written for the current method
+	dismiss := widget.NewButtonWithIcon("", theme.CancelIcon(), p.markBodyType)
+	dismiss.Importance = widget.LowImportance
+	p.bodyNote = container.NewBorder(nil, nil, nil, dismiss, p.bodyNoteLabel)
+	p.bodyNote.Hide()
+}
+
+// SetBodyNote shows note above the request body, e.g. which message type
+// it was written for ("" hides the banner). Loading a body with
+// SyncTextToForm hides it again.
+func (p *RequestPanel) SetBodyNote(note string) {
+	p.bodyNoteLabel.SetText(note)
+	if note == "" {
+		p.bodyNote.Hide()
+	} else {
+		p.bodyNote.Show()
+	}
+}
+
+// BodyNote returns the note shown above the request body, or "" when none is.
+func (p *RequestPanel) BodyNote() string {
+	if !p.bodyNote.Visible() {
+		return ""
+	}
+	return p.bodyNoteLabel.Text
+}
+
+// markBodyType records the body as written for the current method's input
+// type and hides the banner.
+func (p *RequestPanel) markBodyType() {
+	if p.currentDesc != nil {
+		_ = p.state.InputType.Set(string(p.currentDesc.FullName()))
+	}
+	p.SetBodyNote("")
+}
+
+// BodyFits reports whether the body was written for the current method's
+// input type, or its type is not known.
+func (p *RequestPanel) BodyFits() bool {
+	bodyType, _ := p.state.InputType.Get()
+	return bodyType == "" || p.currentDesc == nil || bodyType == string(p.currentDesc.FullName())
+}
@@ -0,0 +1,37 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestPanel_BodyNote(t *testing.T) {
+	p := newTestPanel(t)
+	p.SetMethod("Wide", wideMessage(t, "Wide", 3))
+	p.formLoader.Cancel()
+
+	// A body of unknown type fits whatever the method
+	_ = p.state.TextData.Set(`{"id": 1}`)
+	assert.True(t, p.BodyFits())
+
+	// Kept from a method taking another type
+	_ = p.state.InputType.Set("pkg.OtherRequest")
+	p.SetBodyNote("Body was written for pkg.OtherRequest")
+	assert.False(t, p.BodyFits())
+	assert.Equal(t, "Body was written for pkg.OtherRequest", p.BodyNote())
+
+	// Loading a body takes it as written for the method
+	p.SyncTextToForm()
+	assert.Empty(t, p.BodyNote())
+	bodyType, _ := p.state.InputType.Get()
+	assert.Equal(t, "wide.Wide", bodyType)
+	assert.True(t, p.BodyFits())
+
+	// And so does dismissing the banner
+	_ = p.state.InputType.Set("pkg.OtherRequest")
+	p.SetBodyNote("Body was written for pkg.OtherRequest")
+	p.markBodyType()
+	assert.Empty(t, p.BodyNote())
+	assert.True(t, p.BodyFits())
+}
@@ -0,0 +1,495 @@
+package request
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// snippetTimestamp is the Timestamp value a snippet inserts.
+const snippetTimestamp = "1970-01-01T00:00:00Z"
+
+// cursorPos is what may be written at the cursor in a JSON body.
+type cursorPos int
+
+const (
+	posNone    cursorPos = iota // nothing to suggest, e.g. right after a value
+	posKey                      // a member name, after "{" or ","
+	posValue                    // the value of the member Key, after ":"
+	posElement                  // an array element, after "[" or ","
+)
+
+// pathStep is one step of the path to the cursor: a member name, or an
+// array element when IsIndex is set.
+type pathStep struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// cursorContext is where the cursor sits in a JSON body, which may be
+// unfinished: open braces, a trailing comma, a half-typed name.
+type cursorContext struct {
+	// Path leads from the top-level object to the object or array the
+	// cursor is directly in
+	Path []pathStep
+	Pos  cursorPos
+
+	// Key is the member whose value the cursor is at (posValue)
+	Key string
+
+	// Prefix is what has been typed of the name or value at the cursor,
+	// starting at byte offset Start; Quoted is set when it follows an
+	// opening quote, which Start includes
+	Prefix string
+	Start  int
+	Quoted bool
+
+	// Present holds the member names already written in the object the
+	// cursor is in, before the cursor (posKey)
+	Present map[string]bool
+}
+
+// String renders the path to the cursor, e.g. $.items[0].createdAt.
+func (c cursorContext) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, st := range c.Path {
+		if st.IsIndex {
+			b.WriteString("[" + strconv.Itoa(st.Index) + "]")
+		} else {
+			b.WriteString("." + st.Key)
+		}
+	}
+	if c.Pos == posValue {
+		b.WriteString("." + c.Key)
+	}
+	return b.String()
+}
+
+// frame is an object or array open at the cursor.
+type frame struct {
+	array bool
+	state frameState
+	key   string // object: the member being written
+	index int    // array: the element being written
+	keys  map[string]bool
+}
+
+type frameState int
+
+const (
+	expectKey   frameState = iota // object, after "{" or ","
+	expectColon                   // object, after a member name
+	expectValue                   // after ":" in an object, after "[" or "," in an array
+	afterValue                    // after a value, before "," or the closing bracket
+)
+
+// contextAt works out where offset, a byte offset into text, falls in the
+// JSON body. Only the text before the cursor is read, so whatever follows
+// it, finished or not, does not matter; neither do trailing commas.
+func contextAt(text string, offset int) cursorContext {
+	offset = min(max(offset, 0), len(text))
+	var stack []*frame
+	top := func() *frame {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+	// valueDone marks the value just finished in the enclosing frame
+	valueDone := func() {
+		if f := top(); f != nil {
+			f.state = afterValue
+		}
+	}
+	ctx := func(pos cursorPos) cursorContext {
+		c := cursorContext{Pos: pos, Start: offset}
+		for i, f := range stack {
+			if i == len(stack)-1 {
+				break
+			}
+			if f.array {
+				c.Path = append(c.Path, pathStep{Index: f.index, IsIndex: true})
+			} else {
+				c.Path = append(c.Path, pathStep{Key: f.key})
+			}
+		}
+		if f := top(); f != nil {
+			if pos == posValue {
+				c.Key = f.key
+			}
+			if pos == posKey {
+				c.Present = f.keys
+			}
+		}
+		return c
+	}
+	// partial returns the context for a token cut off by the cursor
+	partial := func(start int, quoted bool) cursorContext {
+		f := top()
+		pos := posNone
+		switch {
+		case f == nil:
+		case f.array && f.state == expectValue:
+			pos = posElement
+		case !f.array && f.state == expectKey:
+			pos = posKey
+		case !f.array && f.state == expectValue:
+			pos = posValue
+		}
+		c := ctx(pos)
+		c.Start, c.Quoted = start, quoted
+		c.Prefix = text[start:offset]
+		if quoted {
+			c.Prefix = c.Prefix[1:]
+		}
+		return c
+	}
+
+	i := 0
+	for i < offset {
+		ch := text[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '{' || ch == '[':
+			f := &frame{array: ch == '[', keys: map[string]bool{}}
+			if f.array {
+				f.state = expectValue
+			}
+			stack = append(stack, f)
+			i++
+		case ch == '}' || ch == ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			valueDone()
+			i++
+		case ch == ',':
+			if f := top(); f != nil {
+				if f.array {
+					if f.state == afterValue {
+						f.index++
+					}
+					f.state = expectValue
+				} else {
+					f.state = expectKey
+				}
+			}
+			i++
+		case ch == ':':
+			if f := top(); f != nil && !f.array {
+				f.state = expectValue
+			}
+			i++
+		case ch == '"':
+			end := closingQuote(text, i)
+			if end < 0 || end >= offset {
+				return partial(i, true)
+			}
+			s, _ := strconv.Unquote(text[i : end+1])
+			if f := top(); f != nil && !f.array && f.state == expectKey {
+				f.key = s
+				f.keys[s] = true
+				f.state = expectColon
+			} else {
+				valueDone()
+			}
+			i = end + 1
+		default:
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\r\n,:{}[]\"", rune(text[end])) {
+				end++
+			}
+			if end >= offset {
+				return partial(i, false)
+			}
+			valueDone()
+			i = end
+		}
+	}
+
+	f := top()
+	switch {
+	case f == nil:
+		return ctx(posNone)
+	case f.array && f.state == expectValue:
+		return ctx(posElement)
+	case !f.array && f.state == expectKey:
+		return ctx(posKey)
+	case !f.array && f.state == expectValue:
+		return ctx(posValue)
+	}
+	return ctx(posNone)
+}
+
+// closingQuote returns the index of the quote closing the string that
+// opens at text[start], or -1 if it is not closed.
+func closingQuote(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// byteOffset converts an offset in runes into text, as the editor
+// reports its cursor, to one in bytes.
+func byteOffset(text string, runes int) int {
+	offset := 0
+	for i := 0; i < runes && offset < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// snippet is a completion offered at the cursor.
+type snippet struct {
+	Label  string // shown in the list
+	Insert string // replaces what was typed from cursorContext.Start
+}
+
+// snippetsAt returns the completions for the cursor context in a body for
+// md: the fields of the message the cursor is in, with a value skeleton
+// for each, or values for the member or element at the cursor. Only those
+// starting with what has been typed are offered.
+func snippetsAt(c cursorContext, md protoreflect.MessageDescriptor) []snippet {
+	if md == nil || c.Pos == posNone {
+		return nil
+	}
+	in, ok := resolveContainer(md, c.Path)
+	if !ok {
+		return nil
+	}
+
+	var snippets []snippet
+	switch c.Pos {
+	case posKey:
+		if in.msg == nil {
+			return nil
+		}
+		fields := in.msg.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			if c.Present[fd.JSONName()] || c.Present[string(fd.Name())] {
+				continue
+			}
+			snippets = append(snippets, snippet{
+				Label:  fd.JSONName() + "  " + fieldTypeName(fd),
+				Insert: strconv.Quote(fd.JSONName()) + ": " + fieldSkeleton(fd),
+			})
+		}
+	case posValue:
+		var fd protoreflect.FieldDescriptor
+		switch {
+		case in.msg != nil:
+			fd = lookupField(in.msg, c.Key)
+			if fd == nil {
+				return nil
+			}
+			if fd.IsList() || fd.IsMap() {
+				return matching([]snippet{{Label: fieldSkeleton(fd), Insert: fieldSkeleton(fd)}}, c)
+			}
+		case in.mapField != nil:
+			fd = in.mapField.MapValue()
+		default:
+			return nil
+		}
+		snippets = valueSnippets(fd)
+	case posElement:
+		if in.list == nil {
+			return nil
+		}
+		snippets = valueSnippets(in.list)
+	}
+	return matching(snippets, c)
+}
+
+// matching keeps the snippets whose insert text starts with what has been
+// typed at the cursor.
+func matching(snippets []snippet, c cursorContext) []snippet {
+	typed := c.Prefix
+	if c.Quoted {
+		typed = `"` + typed
+	}
+	if typed == "" {
+		return snippets
+	}
+	var kept []snippet
+	for _, s := range snippets {
+		if strings.HasPrefix(strings.ToLower(s.Insert), strings.ToLower(typed)) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// target is what the cursor's object or array holds: the fields of a
+// message, the elements of a repeated field, or the entries of a map field.
+type target struct {
+	msg      protoreflect.MessageDescriptor
+	list     protoreflect.FieldDescriptor
+	mapField protoreflect.FieldDescriptor
+}
+
+// resolveContainer follows path from md to the container the cursor is
+// in. It fails when the path leaves the schema, or enters a well-known
+// type whose JSON form is not its fields.
+func resolveContainer(md protoreflect.MessageDescriptor, path []pathStep) (target, bool) {
+	in := target{msg: md}
+	for _, st := range path {
+		var fd protoreflect.FieldDescriptor
+		switch {
+		case in.msg != nil && !st.IsIndex:
+			fd = lookupField(in.msg, st.Key)
+			if fd == nil {
+				return target{}, false
+			}
+			if fd.IsList() {
+				in = target{list: fd}
+				continue
+			}
+			if fd.IsMap() {
+				in = target{mapField: fd}
+				continue
+			}
+		case in.list != nil && st.IsIndex:
+			fd = in.list
+		case in.mapField != nil && !st.IsIndex:
+			fd = in.mapField.MapValue()
+		default:
+			return target{}, false
+		}
+		if fd.Message() == nil || hasSpecialJSON(fd.Message()) {
+			return target{}, false
+		}
+		in = target{msg: fd.Message()}
+	}
+	return in, true
+}
+
+// lookupField finds a field of md by JSON or proto name.
+func lookupField(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByJSONName(key); fd != nil {
+		return fd
+	}
+	return md.Fields().ByName(protoreflect.Name(key))
+}
+
+// hasSpecialJSON reports whether md is a google.protobuf type written in
+// JSON as something other than an object of its fields.
+func hasSpecialJSON(md protoreflect.MessageDescriptor) bool {
+	return md.ParentFile() != nil && md.ParentFile().Package() == "google.protobuf" && md.FullName() != "google.protobuf.Empty"
+}
+
+// fieldSkeleton returns the JSON value inserted for a whole field: [] for
+// repeated fields, {} for maps and messages, otherwise a single value.
+func fieldSkeleton(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case fd.IsList():
+		return "[]"
+	case fd.IsMap():
+		return "{}"
+	}
+	return valueSkeleton(fd)
+}
+
+// valueSkeleton returns the JSON value inserted for one value of fd.
+func valueSkeleton(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "false"
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return `""`
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson writes 64-bit integers as strings
+		return `"0"`
+	case protoreflect.EnumKind:
+		if values := fd.Enum().Values(); values.Len() > 0 {
+			return strconv.Quote(string(values.Get(0).Name()))
+		}
+		return "0"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch fd.Message().FullName() {
+		case protoconv.TimestampName:
+			return strconv.Quote(snippetTimestamp)
+		case protoconv.DurationName:
+			return `"0s"`
+		case protoconv.FieldMaskName:
+			// protojson writes a FieldMask as its comma-separated paths
+			return `""`
+		case "google.protobuf.ListValue":
+			return "[]"
+		case "google.protobuf.Value":
+			return "null"
+		case "google.protobuf.StringValue", "google.protobuf.BytesValue":
+			return `""`
+		case "google.protobuf.BoolValue":
+			return "false"
+		case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+			return `"0"`
+		case "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+			"google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+			return "0"
+		}
+		return "{}"
+	}
+	// 32-bit integers, float, double
+	return "0"
+}
+
+// valueSnippets returns the values offered for one value of fd: each
+// enum value, true and false, or the skeleton for its type.
+func valueSnippets(fd protoreflect.FieldDescriptor) []snippet {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		var snippets []snippet
+		values := fd.Enum().Values()
+		for i := range values.Len() {
+			v := strconv.Quote(string(values.Get(i).Name()))
+			snippets = append(snippets, snippet{Label: v, Insert: v})
+		}
+		return snippets
+	case protoreflect.BoolKind:
+		return []snippet{{Label: "true", Insert: "true"}, {Label: "false", Insert: "false"}}
+	}
+	v := valueSkeleton(fd)
+	label := v
+	if fd.Message() != nil {
+		label = v + "  " + fieldTypeName(fd)
+	}
+	return []snippet{{Label: label, Insert: v}}
+}
+
+// fieldTypeName names a field's type for the suggestion list, e.g.
+// "string", "repeated Item", "map<string, int32>", "Timestamp".
+func fieldTypeName(fd protoreflect.FieldDescriptor) string {
+	single := func(fd protoreflect.FieldDescriptor) string {
+		switch fd.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			return string(fd.Message().Name())
+		case protoreflect.EnumKind:
+			return string(fd.Enum().Name())
+		}
+		return fd.Kind().String()
+	}
+	switch {
+	case fd.IsMap():
+		return "map<" + single(fd.MapKey()) + ", " + single(fd.MapValue()) + ">"
+	case fd.IsList():
+		return "repeated " + single(fd)
+	}
+	return single(fd)
+}
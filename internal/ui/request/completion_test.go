@@ -0,0 +1,142 @@
+package request
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+// updateRequest returns a message with an Item and a FieldMask, like a
+// typical Update method's request.
+func updateRequest(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	itemFile := (&pb.Item{}).ProtoReflect().Descriptor().ParentFile().Path()
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("completion/update.proto"),
+		Package:    proto.String("completion"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{itemFile, "google/protobuf/field_mask.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("UpdateItemRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("item"), Number: proto.Int32(1), JsonName: proto.String("item"),
+					Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".grpctest.Item"),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("update_mask"), Number: proto.Int32(2), JsonName: proto.String("updateMask"),
+					Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.FieldMask"),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("items"), Number: proto.Int32(3), JsonName: proto.String("items"),
+					Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".grpctest.Item"),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	return fd.Messages().Get(0)
+}
+
+func TestContextAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string // | marks the cursor
+		pos     cursorPos
+		path    string
+		prefix  string
+		present []string
+	}{
+		{"empty body", "|", posNone, "$", "", nil},
+		{"empty object", "{|}", posKey, "$", "", nil},
+		{"after member", `{"id": "1", |}`, posKey, "$", "", []string{"id"}},
+		{"trailing comma, unclosed", `{"id": "1",` + "\n  |", posKey, "$", "", []string{"id"}},
+		{"half-typed name", `{"na|`, posKey, "$", "na", nil},
+		{"bare name", `{na|`, posKey, "$", "na", nil},
+		{"value", `{"item": |`, posValue, "$.item", "", nil},
+		{"half-typed value", `{"item": {"color": "RE|"}}`, posValue, "$.item.color", "RE", nil},
+		{"nested key", `{"item": {"name": "x", |`, posKey, "$.item", "", []string{"name"}},
+		{"after value", `{"id": "1" |}`, posNone, "$", "", nil},
+		{"before colon", `{"id" |`, posNone, "$", "", nil},
+		{"array element", `{"items": [{}, |`, posElement, "$.items", "", nil},
+		{"object in array", `{"items": [{}, {"tags": [], |`, posKey, "$.items[1]", "", []string{"tags"}},
+		{"closed object", `{"item": {"id": "1"}, |`, posKey, "$", "", []string{"item"}},
+		{"escaped quote", `{"name": "a\"b", |`, posKey, "$", "", []string{"name"}},
+		{"text after cursor", `{"item": {|}, "items": []}`, posKey, "$.item", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.text, "|")
+			text := strings.Replace(tt.text, "|", "", 1)
+			got := contextAt(text, offset)
+			assert.Equal(t, tt.pos, got.Pos)
+			assert.Equal(t, tt.path, got.String())
+			assert.Equal(t, tt.prefix, got.Prefix)
+			for _, k := range tt.present {
+				assert.True(t, got.Present[k], k)
+			}
+		})
+	}
+}
+
+func TestSnippetsAt(t *testing.T) {
+	md := updateRequest(t)
+	inserts := func(text string) []string {
+		offset := strings.Index(text, "|")
+		var got []string
+		for _, s := range snippetsAt(contextAt(strings.Replace(text, "|", "", 1), offset), md) {
+			got = append(got, s.Insert)
+		}
+		return got
+	}
+
+	assert.Equal(t, []string{`"item": {}`, `"updateMask": ""`, `"items": []`}, inserts(`{|`))
+	assert.Equal(t, []string{`"updateMask": ""`}, inserts(`{"up|`))
+	assert.Equal(t, []string{`"updateMask": ""`, `"items": []`}, inserts(`{"item": {}, |`), "fields already written are left out")
+
+	item := inserts(`{"item": {|`)
+	assert.Contains(t, item, `"createdAt": "1970-01-01T00:00:00Z"`)
+	assert.Contains(t, item, `"ttl": "0s"`)
+	assert.Contains(t, item, `"labels": {}`)
+	assert.Contains(t, item, `"tags": []`)
+	assert.Contains(t, item, `"color": "COLOR_UNSPECIFIED"`)
+	assert.Contains(t, item, `"number": "0"`)
+
+	assert.Equal(t, []string{`"RED"`}, inserts(`{"item": {"color": "R|`))
+	assert.Equal(t, []string{"true", "false"}, inserts(`{"item": {"active": |`))
+	assert.Equal(t, []string{`"1970-01-01T00:00:00Z"`}, inserts(`{"item": {"created_at": |`), "proto names work too")
+	assert.Contains(t, inserts(`{"items": [{}, {|`), `"name": ""`)
+	assert.Equal(t, []string{`""`}, inserts(`{"item": {"labels": {"env": |`))
+
+	assert.Empty(t, inserts(`{"item": {"createdAt": {|`), "no fields inside a well-known type")
+	assert.Empty(t, inserts(`{"unknown": {|`))
+}
+
+func TestSnippetsAt_SkeletonsParse(t *testing.T) {
+	// Every field skeleton makes a body protojson accepts
+	md := updateRequest(t)
+	for _, in := range []string{`{|`, `{"item": {|`} {
+		for _, s := range snippetsAt(contextAt(strings.Replace(in, "|", "", 1), strings.Index(in, "|")), md) {
+			body := strings.Replace(in, "|", s.Insert, 1) + strings.Repeat("}", strings.Count(in, "{"))
+			require.True(t, json.Valid([]byte(body)), body)
+			assert.NoError(t, protojson.Unmarshal([]byte(body), dynamicpb.NewMessage(md)), body)
+		}
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	assert.Equal(t, 0, byteOffset(`{"é": 1}`, 0))
+	assert.Equal(t, 4, byteOffset(`{"é": 1}`, 3))
+	assert.Equal(t, 9, byteOffset(`{"é": 1}`, 20))
+}
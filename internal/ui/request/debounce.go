@@ -0,0 +1,47 @@
+package request
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer runs fn once calls to Trigger have stopped for delay. Each
+// Trigger restarts the wait, so a burst of keystrokes runs fn once. fn runs
+// on a timer goroutine; UI work inside it must go through fyne.Do. A zero
+// delay runs fn at once, on the goroutine calling Trigger.
+type debouncer struct {
+	delay time.Duration
+	fn    func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// newDebouncer creates a debouncer that calls fn after delay of quiet.
+func newDebouncer(delay time.Duration, fn func()) *debouncer {
+	return &debouncer{delay: delay, fn: fn}
+}
+
+// Trigger schedules fn, replacing any call still waiting.
+func (d *debouncer) Trigger() {
+	if d.delay == 0 {
+		d.fn()
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, d.fn)
+}
+
+// Stop cancels a pending call, if any.
+func (d *debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
@@ -0,0 +1,48 @@
+package request
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebouncer_CoalescesBursts(t *testing.T) {
+	var calls atomic.Int32
+	d := newDebouncer(30*time.Millisecond, func() { calls.Add(1) })
+
+	for i := 0; i < 5; i++ {
+		d.Trigger()
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int32(0), calls.Load(), "no call while triggers keep coming")
+
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load(), "one call per burst")
+
+	d.Trigger()
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, 5*time.Millisecond)
+}
+
+func TestDebouncer_Stop(t *testing.T) {
+	var calls atomic.Int32
+	d := newDebouncer(20*time.Millisecond, func() { calls.Add(1) })
+
+	d.Trigger()
+	d.Stop()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), calls.Load())
+
+	d.Stop() // no-op when idle
+}
+
+func TestDebouncer_ZeroDelay(t *testing.T) {
+	calls := 0
+	d := newDebouncer(0, func() { calls++ })
+
+	d.Trigger()
+	d.Trigger()
+	assert.Equal(t, 2, calls, "each trigger runs at once")
+}
@@ -0,0 +1,79 @@
+package request
+
+import (
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// overriddenSuffix marks a connection default replaced by the request's
+// own header.
+const overriddenSuffix = "  (replaced by request)"
+
+// initDefaultMetadata creates the section of the metadata tab listing the
+// connection's default metadata. It is hidden while there is none.
+func (p *RequestPanel) initDefaultMetadata() *fyne.Container {
+	p.defaultsBox = container.NewVBox()
+	hint := widget.NewLabel("Sent with every call on this connection. Set them in Connection Settings → Metadata.")
+	hint.Importance = widget.LowImportance
+	hint.Wrapping = fyne.TextWrapWord
+	p.defaultsSection = container.NewVBox(
+		widget.NewLabel("Connection Defaults"),
+		p.defaultsBox,
+		hint,
+		widget.NewSeparator(),
+	)
+	p.defaultsSection.Hide()
+	return p.defaultsSection
+}
+
+// SetDefaultMetadata shows the metadata the connection sends with every
+// call alongside the request's own.
+func (p *RequestPanel) SetDefaultMetadata(md map[string]string) {
+	p.defaultMetadata = md
+	p.refreshDefaultMetadata()
+}
+
+// EffectiveMetadata returns the metadata a call is sent with once the
+// connection's defaults are merged in, request headers winning.
+func (p *RequestPanel) EffectiveMetadata() map[string]string {
+	return domain.MergeMetadata(p.defaultMetadata, p.SendMetadata())
+}
+
+// refreshDefaultMetadata lists the defaults, dimmed to set them apart from
+// the request's headers, and marks those the request replaces.
+func (p *RequestPanel) refreshDefaultMetadata() {
+	if p.defaultsBox == nil {
+		return
+	}
+	if len(p.defaultMetadata) == 0 {
+		p.defaultsBox.Objects = nil
+		p.defaultsSection.Hide()
+		return
+	}
+
+	keys := make([]string, 0, len(p.defaultMetadata))
+	for k := range p.defaultMetadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sent := p.SendMetadata()
+	rows := make([]fyne.CanvasObject, len(keys))
+	for i, k := range keys {
+		text := k + ": " + p.defaultMetadata[k]
+		if domain.HasMetadataKey(sent, k) {
+			text += overriddenSuffix
+		}
+		label := widget.NewLabelWithStyle(text, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+		label.Importance = widget.LowImportance
+		label.Truncation = fyne.TextTruncateEllipsis
+		rows[i] = label
+	}
+	p.defaultsBox.Objects = rows
+	p.defaultsBox.Refresh()
+	p.defaultsSection.Show()
+}
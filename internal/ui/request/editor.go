@@ -0,0 +1,87 @@
+package request
+
+import (
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// completeShortcut opens the completion list in the text editor.
+var completeShortcut = &desktop.CustomShortcut{KeyName: fyne.KeySpace, Modifier: fyne.KeyModifierControl}
+
+// jsonEditor is the multiline entry of text mode. While an entry has
+// focus, shortcuts go to it rather than the window, so it catches Ctrl+Space
+// itself.
+type jsonEditor struct {
+	widget.Entry
+	onComplete func()
+}
+
+func newJSONEditor() *jsonEditor {
+	e := &jsonEditor{}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapWord
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// TypedShortcut implements fyne.Shortcutable.
+func (e *jsonEditor) TypedShortcut(s fyne.Shortcut) {
+	if cs, ok := s.(*desktop.CustomShortcut); ok && e.onComplete != nil &&
+		cs.KeyName == completeShortcut.KeyName && cs.Modifier == completeShortcut.Modifier {
+		e.onComplete()
+		return
+	}
+	e.Entry.TypedShortcut(s)
+}
+
+// showCompletions lists the fields or values that fit at the cursor, for
+// the selected method's input message, below the cursor.
+func (p *RequestPanel) showCompletions() {
+	if p.currentDesc == nil || p.textEditor.Disabled() {
+		return
+	}
+	text := p.textEditor.Text
+	offset := byteOffset(text, p.textEditor.CursorTextOffset())
+	at := contextAt(text, offset)
+	snippets := snippetsAt(at, p.currentDesc)
+	c := fyne.CurrentApp().Driver().CanvasForObject(p.textEditor)
+	if len(snippets) == 0 || c == nil {
+		return
+	}
+
+	typed := utf8.RuneCountInString(text[at.Start:offset])
+	items := make([]*fyne.MenuItem, len(snippets))
+	for i, s := range snippets {
+		items[i] = fyne.NewMenuItem(s.Label, func() {
+			p.insertSnippet(typed, s.Insert)
+		})
+	}
+
+	// The cursor position ignores the editor's scroll, so keep the list
+	// within the editor
+	pos := p.textEditor.CursorPosition()
+	pos.Y += p.textEditor.Theme().Size(theme.SizeNameText) * 1.5
+	size := p.textEditor.Size()
+	pos.X = min(pos.X, size.Width)
+	pos.Y = min(pos.Y, size.Height)
+	abs := fyne.CurrentApp().Driver().AbsolutePositionForObject(p.textEditor)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), c, abs.Add(pos))
+}
+
+// insertSnippet replaces the typed runes before the cursor with insert,
+// typing it so the editor keeps its cursor and undo history right.
+func (p *RequestPanel) insertSnippet(typed int, insert string) {
+	for range typed {
+		p.textEditor.TypedKey(&fyne.KeyEvent{Name: fyne.KeyBackspace})
+	}
+	for _, r := range insert {
+		p.textEditor.TypedRune(r)
+	}
+	if c := fyne.CurrentApp().Driver().CanvasForObject(p.textEditor); c != nil {
+		c.Focus(p.textEditor)
+	}
+}
@@ -0,0 +1,107 @@
+package request
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/shhac/grotto/internal/ui/form"
+)
+
+// formBuildDelay is how long method selection must settle before a form is
+// built, so skipping through methods with the keyboard builds only the one
+// that is stopped on.
+const formBuildDelay = 50 * time.Millisecond
+
+// formBuildBatch is how many rows of a form are built per frame.
+const formBuildBatch = 25
+
+// formLoader builds request forms a batch of rows at a time, so selecting
+// a method with a very large input message does not freeze the window.
+//
+// Fyne's widgets and caches belong to the UI thread, so the rows are built
+// there: a goroutine hands the thread one batch at a time with
+// fyne.DoAndWait, and the window draws and handles input in between. The
+// form is shown as it grows and handed to onReady once complete.
+//
+// Each Load supersedes the one before: a build still waiting for selection
+// to settle is dropped, and one under way stops at its next batch.
+type formLoader struct {
+	onShow  func(ui fyne.CanvasObject)      // A form has started building
+	onReady func(builder *form.FormBuilder) // That form is complete
+
+	mu      sync.Mutex
+	gen     uint64            // Bumped by every Load and Cancel
+	pending *form.FormBuilder // Waiting to be built, for gen
+
+	building sync.Mutex // Held while a form builds, so one builds at a time
+	debounce *debouncer
+}
+
+// newFormLoader creates a loader that shows each current form through
+// onShow as it starts building and passes it to onReady once complete.
+// Both run on the UI thread.
+func newFormLoader(delay time.Duration, onShow func(ui fyne.CanvasObject), onReady func(builder *form.FormBuilder)) *formLoader {
+	l := &formLoader{onShow: onShow, onReady: onReady}
+	l.debounce = newDebouncer(delay, l.run)
+	return l
+}
+
+// Load schedules builder to be built, superseding any earlier load.
+func (l *formLoader) Load(builder *form.FormBuilder) {
+	l.mu.Lock()
+	l.gen++
+	l.pending = builder
+	l.mu.Unlock()
+	l.debounce.Trigger()
+}
+
+// Cancel supersedes any earlier load without starting another.
+func (l *formLoader) Cancel() {
+	l.debounce.Stop()
+	l.mu.Lock()
+	l.gen++
+	l.pending = nil
+	l.mu.Unlock()
+}
+
+// current reports whether gen is still the latest load.
+func (l *formLoader) current(gen uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return gen == l.gen
+}
+
+// run builds the pending form batch by batch on the UI thread, abandoning
+// it as soon as a later load supersedes it.
+func (l *formLoader) run() {
+	l.building.Lock()
+	defer l.building.Unlock()
+
+	l.mu.Lock()
+	builder, gen := l.pending, l.gen
+	l.pending = nil
+	l.mu.Unlock()
+	if builder == nil {
+		return
+	}
+
+	var step func(n int) bool
+	for done := false; !done; {
+		fyne.DoAndWait(func() {
+			switch {
+			case !l.current(gen):
+				builder.Destroy()
+				done = true
+			case step == nil:
+				var ui fyne.CanvasObject
+				ui, step = builder.BuildInSteps()
+				l.onShow(ui)
+			default:
+				if done = step(formBuildBatch); done {
+					l.onReady(builder)
+				}
+			}
+		})
+	}
+}
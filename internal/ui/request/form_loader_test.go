@@ -0,0 +1,154 @@
+package request
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/shhac/grotto/internal/ui/form"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// wideMessage returns a message with n fields, cycling through strings,
+// numbers, repeated strings and a nested message, like the generated
+// request types that made method selection stall.
+func wideMessage(t *testing.T, name string, n int) protoreflect.MessageDescriptor {
+	t.Helper()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	wide := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	for i := 1; i <= n; i++ {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(fmt.Sprintf("field_%d", i)),
+			Number: proto.Int32(int32(i)),
+			Label:  optional,
+		}
+		switch i % 4 {
+		case 0:
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+		case 1:
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()
+		case 2:
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+			f.Label = repeated
+		case 3:
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			f.TypeName = proto.String(".wide.Inner")
+		}
+		wide.Field = append(wide.Field, f)
+	}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String(name + ".proto"),
+		Package: proto.String("wide"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			wide,
+			{
+				Name: proto.String("Inner"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("a"), Number: proto.Int32(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+					{Name: proto.String("b"), Number: proto.Int32(2), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum()},
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+	return fd.Messages().ByName(protoreflect.Name(name))
+}
+
+func TestRequestPanel_SetMethodBuildsFormInBackground(t *testing.T) {
+	p := newTestPanel(t)
+	md := wideMessage(t, "Wide", 500)
+
+	start := time.Now()
+	p.SetMethod("Wide", md)
+	elapsed := time.Since(start)
+
+	// Returns with a placeholder, before any of the form is built
+	assert.Less(t, elapsed, 100*time.Millisecond)
+	assert.Nil(t, p.formBuilder)
+	require.Len(t, p.formContainer.Objects, 1)
+	assert.Equal(t, []fyne.CanvasObject{p.formBuilding}, p.formContainer.Objects[0].(*fyne.Container).Objects)
+
+	// Text loaded meanwhile fills the form once it is complete. The build
+	// runs here rather than on the loader's goroutine, as the test driver
+	// does not serialize UI work the way the app's main loop does.
+	p.formLoader.debounce.Stop()
+	_ = p.state.TextData.Set(`{"field_1": "7", "field_4": "x"}`)
+	p.jsonValidator.Stop()
+	p.SyncTextToForm()
+	p.formLoader.run()
+
+	require.NotNil(t, p.formBuilder)
+	json, err := p.formBuilder.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"field1": "7", "field4": "x"}`, json)
+}
+
+func TestFormLoader_LatestLoadWins(t *testing.T) {
+	app := test.NewApp()
+	t.Cleanup(app.Quit)
+
+	var shown atomic.Int32
+	ready := make(chan *form.FormBuilder, 4)
+	l := newFormLoader(20*time.Millisecond,
+		func(fyne.CanvasObject) { shown.Add(1) },
+		func(builder *form.FormBuilder) { ready <- builder },
+	)
+
+	// Rapid switching: only the last selection is built and shown
+	var last *form.FormBuilder
+	for i := range 3 {
+		last = form.NewFormBuilder(wideMessage(t, fmt.Sprintf("Switch%d", i), 60))
+		l.Load(last)
+	}
+	assert.Same(t, last, <-ready)
+	assert.Equal(t, int32(1), shown.Load())
+
+	// A load cancelled while another form builds is never built
+	l.building.Lock()
+	l.Load(form.NewFormBuilder(wideMessage(t, "Queued", 10)))
+	time.Sleep(40 * time.Millisecond) // past the delay: waiting to build
+	l.Cancel()
+	l.building.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), shown.Load())
+	select {
+	case builder := <-ready:
+		t.Fatalf("cancelled form completed: %v", builder)
+	default:
+	}
+}
+
+func TestFormBuilder_BuildInStepsMatchesBuild(t *testing.T) {
+	app := test.NewApp()
+	t.Cleanup(app.Quit)
+	md := wideMessage(t, "Steps", 60)
+	values := `{"field1": "7", "field2": ["a", "b"], "field3": {"a": "x"}, "field60": "y"}`
+
+	whole := form.NewFormBuilder(md)
+	whole.Build()
+	require.NoError(t, whole.FromJSON(values))
+	want, err := whole.ToJSON()
+	require.NoError(t, err)
+
+	stepped := form.NewFormBuilder(md)
+	_, step := stepped.BuildInSteps()
+	steps := 1
+	for !step(formBuildBatch) {
+		steps++
+	}
+	assert.Equal(t, 3, steps)
+	require.NoError(t, stepped.FromJSON(values))
+	got, err := stepped.ToJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, want, got)
+}
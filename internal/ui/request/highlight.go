@@ -0,0 +1,63 @@
+package request
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/ui/components"
+)
+
+// initHighlight creates the optional syntax-highlighted view of the text
+// editor. Entries can't color their text, so the toggle swaps the editor
+// for a read-only, colored rendering of the request; tapping the rendering
+// goes back to editing.
+func (p *RequestPanel) initHighlight() {
+	p.highlightView = components.NewJSONView()
+	p.highlightView.OnTapped = func() {
+		p.SetHighlighted(false)
+		p.FocusEditor()
+	}
+
+	p.highlightToggle = widget.NewButtonWithIcon("", theme.ColorPaletteIcon(), func() {
+		p.SetHighlighted(!p.highlighted)
+	})
+	p.highlightToggle.Importance = widget.LowImportance
+
+	p.textStack = container.NewStack(p.textEditor)
+	p.textTheme = components.EditorThemed(p.textStack)
+
+	// Keep the view current when the request changes from outside the
+	// editor (saved requests, history, form sync)
+	p.state.TextData.AddListener(binding.NewDataListener(func() {
+		if p.highlighted {
+			text, _ := p.state.TextData.Get()
+			p.highlightView.SetText(text)
+		}
+	}))
+}
+
+// SetHighlighted switches text mode between the editor and the
+// syntax-highlighted view of the request.
+func (p *RequestPanel) SetHighlighted(on bool) {
+	if p.highlighted == on {
+		return
+	}
+	p.highlighted = on
+	if on {
+		text, _ := p.state.TextData.Get()
+		p.highlightView.SetText(text)
+		p.textStack.Objects = []fyne.CanvasObject{p.highlightView}
+		p.highlightToggle.SetIcon(theme.DocumentCreateIcon())
+	} else {
+		p.textStack.Objects = []fyne.CanvasObject{p.textEditor}
+		p.highlightToggle.SetIcon(theme.ColorPaletteIcon())
+	}
+	p.textTheme.Refresh() // Themes the view swapped in too
+}
+
+// IsHighlighted reports whether text mode shows the highlighted view.
+func (p *RequestPanel) IsHighlighted() bool {
+	return p.highlighted
+}
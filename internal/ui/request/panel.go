@@ -3,19 +3,30 @@ package request
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"sort"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
 	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/protoconv"
 	"github.com/shhac/grotto/internal/ui/components"
 	"github.com/shhac/grotto/internal/ui/form"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// jsonValidationDelay is how long typing must pause before the text editor
+// is validated.
+const jsonValidationDelay = 300 * time.Millisecond
+
 // RequestPanel handles request input.
 //
 // SYNC ARCHITECTURE:
@@ -34,15 +45,31 @@ type RequestPanel struct {
 	methodLabel *widget.Label
 
 	// Text mode
-	textEditor      *widget.Entry // Multiline JSON editor
+	textEditor      *jsonEditor   // Multiline JSON editor
 	jsonStatusLabel *widget.Label // Inline JSON validity indicator
+	jsonValidator   *debouncer    // Runs updateJSONStatus after typing pauses
 	syncErrorLabel  *widget.Label // Shows mode-switch errors
 
+	// Banner noting a body written for another message type
+	bodyNote      *fyne.Container
+	bodyNoteLabel *widget.Label
+
+	// Optional syntax-highlighted view swapped in for the text editor
+	highlighted     bool
+	highlightView   *components.JSONView
+	highlightToggle *widget.Button
+	textStack       *fyne.Container          // holds textEditor or highlightView
+	textTheme       *container.ThemeOverride // textStack in the editor font
+
 	// Form mode
-	formBuilder     *form.FormBuilder              // Form generator
+	formBuilder     *form.FormBuilder              // Form generator, once built
+	formLoader      *formLoader                    // Builds forms a batch at a time
 	formPlaceholder *widget.Label                  // Shown when no method selected
+	formBuilding    *widget.Label                  // Shown while a form builds
 	formContainer   *fyne.Container                // Container for form or placeholder
 	currentDesc     protoreflect.MessageDescriptor // Current message descriptor
+	formMaxDepth    int                            // Nesting depth expanded up front
+	types           *protoconv.TypeResolver        // Message types for Any fields
 
 	// Mode synchronization (prevents freeze bugs)
 	synchronizer *ModeSynchronizer
@@ -55,12 +82,26 @@ type RequestPanel struct {
 	isStreaming    bool                  // Whether current method is client streaming
 
 	// Metadata
-	metadataKeys binding.StringList // Keys for metadata
-	metadataVals binding.StringList // Values for metadata
-	metadataList *widget.List       // Key-value metadata entries
-	keyEntry     *widget.Entry      // New key entry
-	valEntry     *widget.Entry      // New value entry
+	metadataKeys binding.StringList     // Keys for metadata
+	metadataVals binding.StringList     // Values for metadata
+	metadataList *widget.List           // Key-value metadata entries
+	auth         *components.AuthEditor // Authorization preset added at send time
+	keyEntry     *widget.Entry          // New key entry
+	valEntry     *widget.Entry          // New value entry
 	sendBtn      *widget.Button
+	grpcurlBtn   *widget.Button
+
+	// Connection default metadata, shown above the request's headers
+	defaultMetadata map[string]string
+	defaultsBox     *fyne.Container
+	defaultsSection *fyne.Container
+
+	// Saved requests library for the current method
+	savedRequests  []domain.SavedRequest
+	savedSelect    *widget.Select // Picks a saved request to apply
+	saveRequestBtn *widget.Button
+	deleteSavedBtn *widget.Button
+	savedControls  *fyne.Container
 
 	// Top-level tabs (Request Body | Request Metadata)
 	topLevelTabs    *container.AppTabs
@@ -74,9 +115,13 @@ type RequestPanel struct {
 
 	logger *slog.Logger
 
-	onSend       func(json string, metadata map[string]string)
-	onStreamSend func(json string, metadata map[string]string) // Send one message in stream
-	onStreamEnd  func(metadata map[string]string)              // Finish stream and get response
+	onSend         func(json string, metadata map[string]string)
+	onStreamSend   func(json string, metadata map[string]string)                                // Send one message in stream
+	onStreamEnd    func(metadata map[string]string)                                             // Finish stream and get response
+	onSendProblems func(problems []protoconv.FieldError, sendAnyway, sendWithoutUnknown func()) // Pre-send check failed
+	onCopyGrpcurl  func(body string, messages []string, metadata map[string]string)
+	onSaveRequest  func(body string, metadata map[string]string) // Save icon tapped
+	onDeleteSaved  func(name string)                             // Delete icon tapped
 }
 
 // NewRequestPanel creates a new request panel
@@ -86,6 +131,7 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 		metadataKeys: binding.NewStringList(),
 		metadataVals: binding.NewStringList(),
 		logger:       logger,
+		formMaxDepth: form.DefaultMaxDepth,
 	}
 
 	// Create mode synchronizer (handles Text <-> Form sync)
@@ -96,32 +142,20 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 	p.methodLabel.TextStyle = fyne.TextStyle{Bold: true}
 
 	// Multiline JSON editor bound to state.TextData
-	p.textEditor = widget.NewMultiLineEntry()
+	p.textEditor = newJSONEditor()
 	p.textEditor.SetPlaceHolder(`{"field": "value"}`)
-	p.textEditor.Wrapping = fyne.TextWrapWord
 	p.textEditor.Bind(state.TextData)
+	p.textEditor.onComplete = p.showCompletions
 
 	// JSON validity indicator shown below the text editor
 	p.jsonStatusLabel = widget.NewLabel("")
 	p.jsonStatusLabel.Hide()
 
-	// Wire up JSON validation on text changes
-	state.TextData.AddListener(binding.NewDataListener(func() {
-		text, _ := state.TextData.Get()
-		if text == "" {
-			p.jsonStatusLabel.Hide()
-			return
-		}
-		if json.Valid([]byte(text)) {
-			p.jsonStatusLabel.SetText("Valid JSON")
-			p.jsonStatusLabel.Importance = widget.SuccessImportance
-		} else {
-			p.jsonStatusLabel.SetText("Invalid JSON")
-			p.jsonStatusLabel.Importance = widget.DangerImportance
-		}
-		p.jsonStatusLabel.Show()
-		p.jsonStatusLabel.Refresh()
-	}))
+	// Validate JSON once typing pauses; Send stays enabled regardless
+	p.jsonValidator = newDebouncer(jsonValidationDelay, func() {
+		fyne.Do(p.updateJSONStatus)
+	})
+	state.TextData.AddListener(binding.NewDataListener(p.jsonValidator.Trigger))
 
 	// Sync error label (shown when text→form sync fails)
 	p.syncErrorLabel = widget.NewLabel("")
@@ -133,9 +167,16 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 	p.formPlaceholder = widget.NewLabel("Select a method to see the form")
 	p.formPlaceholder.Alignment = fyne.TextAlignCenter
 	p.formContainer = container.NewMax(container.NewCenter(p.formPlaceholder))
+	p.formBuilding = widget.NewLabel("Building form…")
+	p.formBuilding.Alignment = fyne.TextAlignCenter
+	p.formLoader = newFormLoader(formBuildDelay, p.showForm, p.formReady)
+
+	p.initHighlight()
+	p.initBodyNote()
 
 	// Create mode tabs with text editor (+ status bar) and form container (+ sync error)
-	textContainer := container.NewBorder(nil, p.jsonStatusLabel, nil, nil, p.textEditor)
+	textStatusRow := container.NewBorder(nil, nil, nil, p.highlightToggle, p.jsonStatusLabel)
+	textContainer := container.NewBorder(nil, textStatusRow, nil, nil, p.textTheme)
 	formWithError := container.NewBorder(p.syncErrorLabel, nil, nil, nil, p.formContainer)
 	p.modeTabs = components.NewModeTabs(
 		textContainer,
@@ -149,7 +190,12 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 
 	// Show/hide sync error when text→form fails
 	p.synchronizer.SetOnSyncError(func(err error) {
-		if err != nil {
+		var conflict *form.OneofConflictError
+		if errors.As(err, &conflict) {
+			// The form was populated, minus the extra oneof fields
+			p.syncErrorLabel.SetText("Form populated, but " + conflict.Error())
+			p.syncErrorLabel.Show()
+		} else if err != nil {
 			p.syncErrorLabel.SetText("Could not populate form: " + err.Error())
 			p.syncErrorLabel.Show()
 		} else {
@@ -169,36 +215,64 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 		}
 	}))
 
-	// Metadata list showing key-value pairs with delete buttons
+	// Metadata list of editable key-value rows with delete buttons
 	p.metadataList = widget.NewList(
 		func() int {
 			return p.metadataKeys.Length()
 		},
 		func() fyne.CanvasObject {
-			// Template row: key label, equals, value label, delete button
+			// Template row: key entry, value entry, delete button
+			keyEntry := widget.NewEntry()
+			keyEntry.SetPlaceHolder("Header name")
+			valEntry := widget.NewEntry()
+			valEntry.SetPlaceHolder("Header value")
 			return container.NewBorder(
 				nil, nil, nil,
 				widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
-				container.NewHBox(
-					widget.NewLabel(""),
-					widget.NewLabel("="),
-					widget.NewLabel(""),
-				),
+				container.NewGridWithColumns(2, keyEntry, valEntry),
 			)
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
 			border := obj.(*fyne.Container)
 			deleteBtn := border.Objects[1].(*widget.Button)
-			hbox := border.Objects[0].(*fyne.Container)
-			keyLabel := hbox.Objects[0].(*widget.Label)
-			valLabel := hbox.Objects[2].(*widget.Label)
+			grid := border.Objects[0].(*fyne.Container)
+			keyEntry := grid.Objects[0].(*widget.Entry)
+			valEntry := grid.Objects[1].(*widget.Entry)
 
 			// Get key and value from bindings
 			key, _ := p.metadataKeys.GetValue(id)
 			val, _ := p.metadataVals.GetValue(id)
 
-			keyLabel.SetText(key)
-			valLabel.SetText(val)
+			// Rows are recycled: detach handlers before loading this row's text
+			keyEntry.OnChanged = nil
+			keyEntry.Validator = nil
+			valEntry.OnChanged = nil
+			keyEntry.SetText(key)
+			valEntry.SetText(val)
+
+			// A key renamed to one an earlier row already has is flagged;
+			// that earlier row is the one sent
+			keyEntry.Validator = func(s string) error {
+				if p.duplicateMetadataKey(id, s) {
+					return errors.New(strings.TrimSpace(s) + " is already set above")
+				}
+				return nil
+			}
+			_ = keyEntry.Validate()
+
+			// Write edits straight back to the bindings, re-checking the
+			// other rows for keys that now clash or no longer do
+			keyEntry.OnChanged = func(s string) {
+				_ = p.metadataKeys.SetValue(id, s)
+				for i := 0; i < p.metadataKeys.Length(); i++ {
+					if i != id {
+						p.metadataList.RefreshItem(i)
+					}
+				}
+			}
+			valEntry.OnChanged = func(s string) {
+				_ = p.metadataVals.SetValue(id, s)
+			}
 
 			// Wire delete button
 			deleteBtn.OnTapped = func() {
@@ -213,6 +287,16 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 
 	p.valEntry = widget.NewEntry()
 	p.valEntry.SetPlaceHolder("Header value")
+	p.valEntry.Validator = func(s string) error {
+		if grpc.IsBinaryHeader(p.keyEntry.Text) {
+			_, err := grpc.DecodeBinaryHeader(s)
+			return err
+		}
+		return nil
+	}
+	p.keyEntry.OnChanged = func(string) {
+		_ = p.valEntry.Validate()
+	}
 
 	// Send button (disabled until a method is selected)
 	p.sendBtn = widget.NewButton("Send", func() {
@@ -221,6 +305,16 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 	p.sendBtn.Importance = widget.HighImportance
 	p.sendBtn.Disable()
 
+	// Copy as grpcurl (disabled along with Send)
+	p.grpcurlBtn = widget.NewButtonWithIcon("grpcurl", theme.ContentCopyIcon(), func() {
+		p.handleCopyGrpcurl()
+	})
+	p.grpcurlBtn.Importance = widget.LowImportance
+	p.grpcurlBtn.Disable()
+
+	// Saved requests dropdown with save and delete icons
+	p.initSavedRequests()
+
 	// Streaming input widget
 	p.streamingInput = NewStreamingInputWidget()
 	p.streamingInput.SetOnSend(func(json string) {
@@ -229,6 +323,12 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 	p.streamingInput.SetOnFinish(func() {
 		p.handleStreamFinish()
 	})
+	p.streamingInput.SetBatchValidator(func(json string) error {
+		if problems := checkRequestJSON(json, p.currentDesc, p.types); len(problems) > 0 {
+			return problems[0]
+		}
+		return nil
+	})
 
 	p.initializeComponents()
 	p.ExtendBaseWidget(p)
@@ -239,6 +339,21 @@ func NewRequestPanel(state *model.RequestState, logger *slog.Logger) *RequestPan
 // pattern as ResponsePanel and BidiStreamPanel. This avoids recreating
 // widgets inside CreateRenderer, which Fyne may call more than once.
 func (p *RequestPanel) initializeComponents() {
+	// Auth preset, composed into the authorization header at send time
+	p.auth = components.NewAuthEditor()
+	authHint := widget.NewLabel("Sent as the authorization header, replacing one in the list below.")
+	authHint.Importance = widget.LowImportance
+	authHint.Wrapping = fyne.TextWrapWord
+	authSection := container.NewVBox(
+		widget.NewLabel("Auth"),
+		p.auth,
+		authHint,
+		widget.NewSeparator(),
+		p.initDefaultMetadata(),
+	)
+	p.auth.SetOnChanged(func(domain.Auth) { p.refreshDefaultMetadata() })
+	p.metadataKeys.AddListener(binding.NewDataListener(p.refreshDefaultMetadata))
+
 	// Metadata section UI
 	addMetadataBtn := widget.NewButton("+ Add Header", func() {
 		p.addMetadata()
@@ -254,7 +369,7 @@ func (p *RequestPanel) initializeComponents() {
 	)
 
 	p.metadataContent = container.NewBorder(
-		nil,
+		authSection,
 		metadataEntry,
 		nil, nil,
 		p.metadataList,
@@ -268,13 +383,15 @@ func (p *RequestPanel) initializeComponents() {
 	p.metadataTab = container.NewTabItem("Request Metadata", p.metadataContent)
 	p.topLevelTabs = container.NewAppTabs(p.bodyTab, p.metadataTab)
 
-	// Header row: method label on left, send button on right
-	headerRow := container.NewBorder(nil, nil, nil, p.sendBtn, p.methodLabel)
+	// Header row: method label on left, saved requests and send button on right
+	headerRow := container.NewBorder(nil, nil, nil,
+		container.NewHBox(p.savedControls, p.grpcurlBtn, p.sendBtn), p.methodLabel)
 
 	// Full layout
 	p.content = container.NewBorder(
 		container.NewVBox(
 			headerRow,
+			p.bodyNote,
 			widget.NewSeparator(),
 		),
 		nil,
@@ -283,13 +400,17 @@ func (p *RequestPanel) initializeComponents() {
 	)
 }
 
-// SetSendEnabled enables or disables the Send button
+// SetSendEnabled enables or disables the Send, Copy as grpcurl and saved
+// request buttons
 func (p *RequestPanel) SetSendEnabled(enabled bool) {
 	if enabled {
 		p.sendBtn.Enable()
+		p.grpcurlBtn.Enable()
 	} else {
 		p.sendBtn.Disable()
+		p.grpcurlBtn.Disable()
 	}
+	p.setSavedEnabled(enabled)
 }
 
 // SetEnabled enables or disables all interactive elements in the request panel.
@@ -299,12 +420,15 @@ func (p *RequestPanel) SetEnabled(enabled bool) {
 		p.keyEntry.Enable()
 		p.valEntry.Enable()
 		p.sendBtn.Enable()
+		p.grpcurlBtn.Enable()
 	} else {
 		p.textEditor.Disable()
 		p.keyEntry.Disable()
 		p.valEntry.Disable()
 		p.sendBtn.Disable()
+		p.grpcurlBtn.Disable()
 	}
+	p.setSavedEnabled(enabled)
 }
 
 // SetOnSend sets the callback for when Send is clicked (unary/server streaming)
@@ -312,6 +436,22 @@ func (p *RequestPanel) SetOnSend(fn func(json string, metadata map[string]string
 	p.onSend = fn
 }
 
+// SetOnSendProblems sets the callback invoked instead of sending when the
+// pre-send check finds problems. Calling sendAnyway sends the request as-is.
+// When some problems are unknown fields, sendWithoutUnknown sends the
+// request with those fields dropped; otherwise it is nil.
+func (p *RequestPanel) SetOnSendProblems(fn func(problems []protoconv.FieldError, sendAnyway, sendWithoutUnknown func())) {
+	p.onSendProblems = fn
+}
+
+// SetOnCopyGrpcurl sets the callback for Copy as grpcurl. It receives the
+// normalized request body, the stream's messages when the method is client
+// streaming (nil otherwise), and the request metadata with the connection's
+// defaults merged in.
+func (p *RequestPanel) SetOnCopyGrpcurl(fn func(body string, messages []string, metadata map[string]string)) {
+	p.onCopyGrpcurl = fn
+}
+
 // SetOnStreamSend sets the callback for sending a message in client streaming
 func (p *RequestPanel) SetOnStreamSend(fn func(json string, metadata map[string]string)) {
 	p.onStreamSend = fn
@@ -334,23 +474,69 @@ func (p *RequestPanel) SetClientStreaming(streaming bool) {
 		p.streamingInput.Clear()
 		p.bodyTabContent.Objects = []fyne.CanvasObject{p.streamingInput}
 		p.sendBtn.Hide()
+		p.savedControls.Hide()
 	} else {
 		p.bodyTabContent.Objects = []fyne.CanvasObject{p.modeTabs}
 		p.sendBtn.Show()
+		p.savedControls.Show()
 	}
 	p.bodyTabContent.Refresh()
 }
 
-// SetMethod updates the panel for a selected method
+// updateJSONStatus validates the text editor against the current method
+// and shows the outcome under the editor.
+func (p *RequestPanel) updateJSONStatus() {
+	text, _ := p.state.TextData.Get()
+	if strings.TrimSpace(text) == "" {
+		p.jsonStatusLabel.Hide()
+		return
+	}
+
+	status := validateRequestText(text, p.currentDesc, p.types)
+	p.jsonStatusLabel.SetText(status.Message)
+	switch status.Severity {
+	case jsonInvalid:
+		p.jsonStatusLabel.Importance = widget.DangerImportance
+	case jsonWarning:
+		p.jsonStatusLabel.Importance = widget.WarningImportance
+	default:
+		p.jsonStatusLabel.Importance = widget.SuccessImportance
+	}
+	p.jsonStatusLabel.Show()
+	p.jsonStatusLabel.Refresh()
+}
+
+// SetFormBuildDelay sets how long method selection must settle before
+// its form is built. A zero delay builds the form before SetMethod
+// returns, which keeps tests off the loader's goroutine.
+func (p *RequestPanel) SetFormBuildDelay(delay time.Duration) {
+	p.formLoader.Cancel()
+	p.formLoader = newFormLoader(delay, p.showForm, p.formReady)
+}
+
+// SetFormMaxDepth sets how many levels of nested messages the form expands
+// before deferring deeper ones. It applies from the next method selected.
+func (p *RequestPanel) SetFormMaxDepth(depth int) {
+	p.formMaxDepth = depth
+}
+
+// SetTypeResolver sets the message types Any fields can be filled with and
+// that request JSON is checked against. It applies from the next method
+// selected.
+func (p *RequestPanel) SetTypeResolver(types *protoconv.TypeResolver) {
+	p.types = types
+}
+
+// SetMethod updates the panel for a selected method. The form is built in
+// the background, a batch of rows per frame; until it is complete the
+// request is edited as text, which the form picks up once it is. The body
+// is left as it is; whether it carries over is up to the caller.
 func (p *RequestPanel) SetMethod(methodName string, inputDesc protoreflect.MessageDescriptor) {
 	if methodName == "" {
 		p.methodLabel.SetText("No method selected")
 		p.currentDesc = nil
-		if p.formBuilder != nil {
-			p.formBuilder.Destroy()
-		}
-		p.formBuilder = nil
-		p.synchronizer.SetFormBuilder(nil)
+		p.formLoader.Cancel()
+		p.dropForm()
 		p.formContainer.Objects = []fyne.CanvasObject{container.NewCenter(p.formPlaceholder)}
 		p.formContainer.Refresh()
 	} else {
@@ -359,35 +545,64 @@ func (p *RequestPanel) SetMethod(methodName string, inputDesc protoreflect.Messa
 
 		// Build form for this method
 		if inputDesc != nil {
-			if p.formBuilder != nil {
-				p.formBuilder.Destroy()
-			}
-			p.formBuilder = form.NewFormBuilder(inputDesc)
-			p.synchronizer.SetFormBuilder(p.formBuilder)
-			formUI := p.formBuilder.Build()
-			p.formContainer.Objects = []fyne.CanvasObject{formUI}
+			p.dropForm()
+			builder := form.NewFormBuilder(inputDesc)
+			builder.SetMaxDepth(p.formMaxDepth)
+			builder.SetTypeResolver(p.types)
+			p.formLoader.Load(builder)
+			p.formContainer.Objects = []fyne.CanvasObject{container.NewCenter(p.formBuilding)}
 			p.formContainer.Refresh()
-
-			// Clear text data when switching methods - old JSON won't match new schema
-			// This prevents crashes from trying to sync incompatible data
-			_ = p.state.TextData.Set("")
+		} else {
+			// Keep the current form, but don't let an older selection's land
+			p.formLoader.Cancel()
 		}
 	}
 	p.Refresh()
 }
 
-// addMetadata adds a new metadata header
+// dropForm discards the current form, if any.
+func (p *RequestPanel) dropForm() {
+	if p.formBuilder != nil {
+		p.formBuilder.Destroy()
+	}
+	p.formBuilder = nil
+	p.synchronizer.SetFormBuilder(nil)
+}
+
+// showForm swaps in a form formLoader has started building.
+func (p *RequestPanel) showForm(ui fyne.CanvasObject) {
+	p.formContainer.Objects = []fyne.CanvasObject{ui}
+	p.formContainer.Refresh()
+}
+
+// formReady takes over a form formLoader has finished and fills it from
+// the text editor, which has been the request while the form was building.
+func (p *RequestPanel) formReady(builder *form.FormBuilder) {
+	p.formBuilder = builder
+	p.synchronizer.SetFormBuilder(builder)
+	p.synchronizer.SyncTextToFormNow()
+}
+
+// addMetadata adds a new metadata header, replacing the value of an
+// existing header with the same name (metadata keys are case-insensitive).
 func (p *RequestPanel) addMetadata() {
-	key := p.keyEntry.Text
+	key := strings.TrimSpace(p.keyEntry.Text)
 	val := p.valEntry.Text
 
 	if key == "" {
 		return // Don't add empty keys
 	}
+	if p.valEntry.Validate() != nil {
+		return // Keep invalid -bin values in the entry for correction
+	}
 
-	// Add to bindings
-	_ = p.metadataKeys.Append(key)
-	_ = p.metadataVals.Append(val)
+	if index := p.findMetadataKey(key); index >= 0 {
+		_ = p.metadataKeys.SetValue(index, key)
+		_ = p.metadataVals.SetValue(index, val)
+	} else {
+		_ = p.metadataKeys.Append(key)
+		_ = p.metadataVals.Append(val)
+	}
 
 	// Clear entry fields
 	p.keyEntry.SetText("")
@@ -396,6 +611,28 @@ func (p *RequestPanel) addMetadata() {
 	p.metadataList.Refresh()
 }
 
+// findMetadataKey returns the index of the header named key, or -1.
+func (p *RequestPanel) findMetadataKey(key string) int {
+	keys, _ := p.metadataKeys.Get()
+	for i, k := range keys {
+		if strings.EqualFold(strings.TrimSpace(k), key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// duplicateMetadataKey reports whether a row before index already has
+// the header named key (metadata keys are case-insensitive).
+func (p *RequestPanel) duplicateMetadataKey(index int, key string) bool {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return false
+	}
+	first := p.findMetadataKey(key)
+	return first >= 0 && first < index
+}
+
 // deleteMetadata removes a metadata entry by index.
 func (p *RequestPanel) deleteMetadata(index int) {
 	keys, _ := p.metadataKeys.Get()
@@ -405,8 +642,8 @@ func (p *RequestPanel) deleteMetadata(index int) {
 		return
 	}
 
-	newKeys := append(keys[:index], keys[index+1:]...)
-	newVals := append(vals[:index], vals[index+1:]...)
+	newKeys := append(append([]string{}, keys[:index]...), keys[index+1:]...)
+	newVals := append(append([]string{}, vals[:index]...), vals[index+1:]...)
 
 	_ = p.metadataKeys.Set(newKeys)
 	_ = p.metadataVals.Set(newVals)
@@ -414,7 +651,8 @@ func (p *RequestPanel) deleteMetadata(index int) {
 	p.metadataList.Refresh()
 }
 
-// handleSend collects data and invokes the onSend callback (unary/server streaming)
+// handleSend collects data and invokes the onSend callback (unary/server streaming).
+// If the request fails the pre-send check, onSendProblems is asked first.
 func (p *RequestPanel) handleSend() {
 	if p.onSend == nil {
 		return
@@ -426,19 +664,84 @@ func (p *RequestPanel) handleSend() {
 		p.synchronizer.SyncFormToTextNow()
 	}
 
-	// Get JSON text from state
+	if p.onSendProblems != nil {
+		if problems := p.checkRequest(currentMode); len(problems) > 0 {
+			var sendWithoutUnknown func()
+			if hasUnknownFields(problems) {
+				sendWithoutUnknown = p.sendWithoutUnknown
+			}
+			p.onSendProblems(problems, p.send, sendWithoutUnknown)
+			return
+		}
+	}
+	p.send()
+}
+
+// checkRequest returns the problems that would make the current request
+// fail, starting with malformed -bin metadata values. In form mode, invalid fields are reported instead of the JSON,
+// which may be stale when the form could not be converted.
+func (p *RequestPanel) checkRequest(mode string) []protoconv.FieldError {
+	problems := checkMetadata(p.GetMetadata())
+	if mode == "form" && p.formBuilder != nil {
+		if fieldProblems := p.formBuilder.FieldErrors(""); len(fieldProblems) > 0 {
+			return append(problems, fieldProblems...)
+		}
+	}
+	jsonText, _ := p.state.TextData.Get()
+	return append(problems, checkRequestJSON(jsonText, p.currentDesc, p.types)...)
+}
+
+// send normalizes and pretty-prints the request JSON and invokes onSend.
+func (p *RequestPanel) send() {
+	// Get JSON text from state, applying base64url and duration conversions
+	jsonText, _ := p.state.TextData.Get()
+	p.sendJSON(normalizeRequestJSON(jsonText, p.currentDesc))
+}
+
+// sendWithoutUnknown sends the request like send, first dropping the fields
+// its message does not have. If the request cannot be parsed even then, it
+// is sent as-is so the failure is reported by the call.
+func (p *RequestPanel) sendWithoutUnknown() {
 	jsonText, _ := p.state.TextData.Get()
+	jsonText = normalizeRequestJSON(jsonText, p.currentDesc)
+	if p.currentDesc != nil {
+		if known, err := discardUnknownFields(jsonText, p.currentDesc, p.types); err == nil {
+			jsonText = known
+		}
+	}
+	p.sendJSON(jsonText)
+}
 
+// sendJSON pretty-prints a normalized request body and invokes onSend.
+func (p *RequestPanel) sendJSON(jsonText string) {
 	// Pretty-print JSON
 	var buf bytes.Buffer
 	if err := json.Indent(&buf, []byte(jsonText), "", "  "); err == nil {
 		jsonText = buf.String()
 	}
 
-	// Build metadata map
-	metadata := p.GetMetadata()
+	p.onSend(jsonText, p.SendMetadata())
+}
 
-	p.onSend(jsonText, metadata)
+// handleCopyGrpcurl collects the request as it would be sent and invokes
+// onCopyGrpcurl.
+func (p *RequestPanel) handleCopyGrpcurl() {
+	if p.onCopyGrpcurl == nil {
+		return
+	}
+
+	var messages []string
+	if p.isStreaming {
+		messages = []string{}
+		for _, msg := range p.streamingInput.Messages() {
+			messages = append(messages, normalizeRequestJSON(msg, p.currentDesc))
+		}
+	} else if mode, _ := p.state.Mode.Get(); mode == "form" && p.formBuilder != nil {
+		p.synchronizer.SyncFormToTextNow()
+	}
+
+	body, _ := p.state.TextData.Get()
+	p.onCopyGrpcurl(normalizeRequestJSON(body, p.currentDesc), messages, p.EffectiveMetadata())
 }
 
 // handleStreamSend sends a single message in a client stream
@@ -447,16 +750,16 @@ func (p *RequestPanel) handleStreamSend(jsonText string) {
 		return
 	}
 
+	// Normalize base64url bytes values and human-friendly durations
+	jsonText = normalizeRequestJSON(jsonText, p.currentDesc)
+
 	// Pretty-print JSON
 	var buf bytes.Buffer
 	if err := json.Indent(&buf, []byte(jsonText), "", "  "); err == nil {
 		jsonText = buf.String()
 	}
 
-	// Build metadata map
-	metadata := p.GetMetadata()
-
-	p.onStreamSend(jsonText, metadata)
+	p.onStreamSend(jsonText, p.SendMetadata())
 }
 
 // handleStreamFinish finishes the client stream and requests the response
@@ -465,44 +768,77 @@ func (p *RequestPanel) handleStreamFinish() {
 		return
 	}
 
-	// Build metadata map
-	metadata := p.GetMetadata()
-
-	p.onStreamEnd(metadata)
+	p.onStreamEnd(p.SendMetadata())
 }
 
-// GetMetadata builds the metadata map from the UI
+// GetMetadata builds the metadata map from the UI. Rows whose key was
+// edited down to blank are skipped, as are rows renamed to a key an
+// earlier row has.
 func (p *RequestPanel) GetMetadata() map[string]string {
 	metadata := make(map[string]string)
 	length := p.metadataKeys.Length()
 	for i := 0; i < length; i++ {
 		key, _ := p.metadataKeys.GetValue(i)
 		val, _ := p.metadataVals.GetValue(i)
-		metadata[key] = val
+		if key = strings.TrimSpace(key); key != "" && !p.duplicateMetadataKey(i, key) {
+			metadata[key] = val
+		}
 	}
 	return metadata
 }
 
+// SendMetadata returns the metadata a call is sent with: the listed
+// entries plus the authorization header composed from the auth preset.
+func (p *RequestPanel) SendMetadata() map[string]string {
+	return p.auth.Auth().Apply(p.GetMetadata())
+}
+
+// Auth returns the auth preset applied to calls.
+func (p *RequestPanel) Auth() domain.Auth {
+	return p.auth.Auth()
+}
+
+// SetAuth sets the auth preset applied to calls, e.g. to the connection's
+// default.
+func (p *RequestPanel) SetAuth(a domain.Auth) {
+	p.auth.SetAuth(a)
+	p.refreshDefaultMetadata()
+}
+
 // SetMetadata replaces the metadata entries displayed in the UI.
 func (p *RequestPanel) SetMetadata(metadata map[string]string) {
 	keys := make([]string, 0, len(metadata))
-	vals := make([]string, 0, len(metadata))
-	for k, v := range metadata {
+	for k := range metadata {
 		keys = append(keys, k)
-		vals = append(vals, v)
+	}
+	sort.Strings(keys)
+	vals := make([]string, 0, len(metadata))
+	for _, k := range keys {
+		vals = append(vals, metadata[k])
 	}
 	_ = p.metadataKeys.Set(keys)
 	_ = p.metadataVals.Set(vals)
 	p.metadataList.Refresh()
 }
 
-// SyncTextToForm populates the form from current TextData (for history load)
+// SyncTextToForm populates the form from current TextData (for history
+// load), taking it as a body written for the current method.
 func (p *RequestPanel) SyncTextToForm() {
+	p.markBodyType()
 	p.synchronizer.SyncTextToFormNow()
 }
 
-// TriggerSend programmatically triggers the send action (for keyboard shortcut)
+// TriggerSend programmatically triggers the send action (for keyboard
+// shortcut). For client-streaming methods it sends the current stream
+// message instead. Nothing happens while sending is disabled.
 func (p *RequestPanel) TriggerSend() {
+	if p.isStreaming {
+		p.streamingInput.TriggerSend()
+		return
+	}
+	if p.sendBtn.Disabled() {
+		return
+	}
 	p.handleSend()
 }
 
@@ -516,13 +852,30 @@ func (p *RequestPanel) SwitchToFormMode() {
 	p.modeTabs.SetMode("form")
 }
 
-// FocusEditor moves keyboard focus to the text editor widget.
+// FocusEditor moves keyboard focus to the text editor widget, leaving the
+// highlighted view if it is showing.
 func (p *RequestPanel) FocusEditor() {
+	p.SetHighlighted(false)
 	if c := fyne.CurrentApp().Driver().CanvasForObject(p.textEditor); c != nil {
 		c.Focus(p.textEditor)
 	}
 }
 
+// ShowMetadataTab selects the Request Metadata tab and focuses the header
+// name entry, so the user can add or fix a header.
+func (p *RequestPanel) ShowMetadataTab() {
+	p.topLevelTabs.Select(p.metadataTab)
+	if c := fyne.CurrentApp().Driver().CanvasForObject(p.keyEntry); c != nil {
+		c.Focus(p.keyEntry)
+	}
+}
+
+// BodyTabShowing reports whether the Request Body tab is selected with the
+// body editor in it, rather than the client-streaming input.
+func (p *RequestPanel) BodyTabShowing() bool {
+	return p.topLevelTabs.Selected() == p.bodyTab && !p.isStreaming
+}
+
 // CreateRenderer returns the widget renderer.
 func (p *RequestPanel) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(p.content)
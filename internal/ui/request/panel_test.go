@@ -0,0 +1,364 @@
+package request
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/logging"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPanel creates a request panel shown in a test window.
+func newTestPanel(t *testing.T) *RequestPanel {
+	t.Helper()
+	app := test.NewApp()
+	t.Cleanup(app.Quit)
+
+	p := NewRequestPanel(model.NewRequestState(), logging.NewNopLogger())
+	w := test.NewWindow(p)
+	t.Cleanup(w.Close)
+	return p
+}
+
+// addHeader types a header into the entry fields and taps Add.
+func addHeader(p *RequestPanel, key, val string) {
+	p.keyEntry.SetText(key)
+	p.valEntry.SetText(val)
+	p.addMetadata()
+}
+
+// metadataRow renders list row id and returns its key entry, value entry,
+// and delete button.
+func metadataRow(p *RequestPanel, id widget.ListItemID) (*widget.Entry, *widget.Entry, *widget.Button) {
+	row := p.metadataList.CreateItem()
+	p.metadataList.UpdateItem(id, row)
+	border := row.(*fyne.Container)
+	grid := border.Objects[0].(*fyne.Container)
+	return grid.Objects[0].(*widget.Entry), grid.Objects[1].(*widget.Entry), border.Objects[1].(*widget.Button)
+}
+
+func TestRequestPanel_DeleteMiddleHeader(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "authorization", "Bearer abc")
+	addHeader(p, "x-trace-id", "123")
+	addHeader(p, "x-tenant", "acme")
+
+	_, _, deleteBtn := metadataRow(p, 1)
+	test.Tap(deleteBtn)
+
+	keys, _ := p.metadataKeys.Get()
+	vals, _ := p.metadataVals.Get()
+	assert.Equal(t, []string{"authorization", "x-tenant"}, keys)
+	assert.Equal(t, []string{"Bearer abc", "acme"}, vals)
+	assert.Equal(t, map[string]string{
+		"authorization": "Bearer abc",
+		"x-tenant":      "acme",
+	}, p.GetMetadata())
+}
+
+func TestRequestPanel_EditHeaderInPlace(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "authorizaton", "Bearer abc")
+	addHeader(p, "x-tenant", "acme")
+
+	keyEntry, valEntry, _ := metadataRow(p, 0)
+	keyEntry.SetText("authorization")
+	valEntry.SetText("Bearer xyz")
+
+	keys, _ := p.metadataKeys.Get()
+	vals, _ := p.metadataVals.Get()
+	assert.Equal(t, []string{"authorization", "x-tenant"}, keys)
+	assert.Equal(t, []string{"Bearer xyz", "acme"}, vals)
+	assert.Equal(t, map[string]string{
+		"authorization": "Bearer xyz",
+		"x-tenant":      "acme",
+	}, p.GetMetadata())
+}
+
+func TestRequestPanel_RenamingHeaderToExistingKey(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "x-tenant", "acme")
+	addHeader(p, "x-trace-id", "123")
+
+	keyEntry, _, _ := metadataRow(p, 1)
+	keyEntry.SetText("X-Tenant")
+	assert.Error(t, keyEntry.Validate(), "clashing row is flagged")
+	first, _, _ := metadataRow(p, 0)
+	assert.NoError(t, first.Validate())
+	assert.Equal(t, map[string]string{"x-tenant": "acme"}, p.GetMetadata(),
+		"the earlier row is sent, not the renamed one")
+
+	keyEntry.SetText("x-tenant-id")
+	assert.NoError(t, keyEntry.Validate())
+	assert.Equal(t, map[string]string{"x-tenant": "acme", "x-tenant-id": "123"}, p.GetMetadata())
+}
+
+func TestRequestPanel_ReaddingHeaderReplacesValue(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "x-tenant", "acme")
+	addHeader(p, "x-trace-id", "123")
+	addHeader(p, "X-Tenant", "globex")
+
+	keys, _ := p.metadataKeys.Get()
+	vals, _ := p.metadataVals.Get()
+	require.Len(t, keys, 2)
+	assert.Equal(t, []string{"X-Tenant", "x-trace-id"}, keys)
+	assert.Equal(t, []string{"globex", "123"}, vals)
+	assert.Empty(t, p.keyEntry.Text)
+	assert.Empty(t, p.valEntry.Text)
+}
+
+func TestRequestPanel_RecycledRowDoesNotWriteBack(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "a", "1")
+	addHeader(p, "b", "2")
+
+	// Loading row 1 into a template that previously showed row 0 must not
+	// overwrite row 0 with row 1's text
+	row := p.metadataList.CreateItem()
+	p.metadataList.UpdateItem(0, row)
+	p.metadataList.UpdateItem(1, row)
+
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, p.GetMetadata())
+}
+
+func TestRequestPanel_BlankedKeyOmitted(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "x-tenant", "acme")
+	keyEntry, _, _ := metadataRow(p, 0)
+	keyEntry.SetText("")
+
+	assert.Empty(t, p.GetMetadata())
+}
+
+func TestRequestPanel_InvalidBinaryHeaderNotAdded(t *testing.T) {
+	p := newTestPanel(t)
+
+	addHeader(p, "trace-bin", "not base64!")
+	assert.Empty(t, p.GetMetadata())
+	assert.Equal(t, "not base64!", p.valEntry.Text, "invalid value stays for correction")
+
+	addHeader(p, "trace-bin", "AAEC")
+	assert.Equal(t, map[string]string{"trace-bin": "AAEC"}, p.GetMetadata())
+}
+
+func TestRequestPanel_CheckRequestReportsBinaryHeader(t *testing.T) {
+	p := newTestPanel(t)
+	p.SetMetadata(map[string]string{"trace-bin": "%%%", "x-plain": "%%%"})
+
+	problems := p.checkRequest("text")
+	require.Len(t, problems, 1)
+	assert.Equal(t, "metadata trace-bin", problems[0].Path)
+}
+
+func TestRequestPanel_TriggerSend(t *testing.T) {
+	p := newTestPanel(t)
+	var sent, streamed []string
+	p.SetOnSend(func(json string, _ map[string]string) { sent = append(sent, json) })
+	p.SetOnStreamSend(func(json string, _ map[string]string) { streamed = append(streamed, json) })
+
+	// Nothing is sent before a method enables the button
+	_ = p.state.TextData.Set(`{"id": "1"}`)
+	p.TriggerSend()
+	assert.Empty(t, sent)
+
+	p.SetSendEnabled(true)
+	p.TriggerSend()
+	assert.Len(t, sent, 1)
+
+	// Disabled while a request is in flight
+	p.SetSendEnabled(false)
+	p.TriggerSend()
+	assert.Len(t, sent, 1)
+	p.SetSendEnabled(true)
+
+	// Client streaming sends the current stream message instead
+	p.SetClientStreaming(true)
+	p.StreamingInput().SetCurrentMessage(`{"id": "2"}`)
+	p.TriggerSend()
+	assert.Len(t, sent, 1)
+	require.Len(t, streamed, 1)
+	assert.JSONEq(t, `{"id": "2"}`, streamed[0])
+
+	// Nothing more once the stream is finished
+	p.StreamingInput().handleFinish()
+	p.StreamingInput().SetCurrentMessage(`{"id": "3"}`)
+	p.TriggerSend()
+	assert.Len(t, streamed, 1)
+}
+
+func TestRequestPanel_AuthPreset(t *testing.T) {
+	p := newTestPanel(t)
+	var gotMetadata map[string]string
+	p.SetOnSend(func(_ string, metadata map[string]string) { gotMetadata = metadata })
+	_ = p.state.TextData.Set(`{}`)
+	p.SetSendEnabled(true)
+
+	addHeader(p, "x-trace", "abc")
+	addHeader(p, "Authorization", "Bearer stale")
+	p.SetAuth(domain.Auth{Type: domain.AuthBearer, Token: "fresh"})
+	p.TriggerSend()
+
+	assert.Equal(t, map[string]string{"x-trace": "abc", "authorization": "Bearer fresh"}, gotMetadata)
+	assert.Equal(t, "Bearer stale", p.GetMetadata()["Authorization"], "visible list unchanged")
+
+	p.SetAuth(domain.Auth{Type: domain.AuthBasic, Username: "u", Password: "p"})
+	p.TriggerSend()
+	assert.Equal(t, "Basic dTpw", gotMetadata["authorization"])
+
+	p.SetAuth(domain.Auth{})
+	p.TriggerSend()
+	assert.Equal(t, "Bearer stale", gotMetadata["Authorization"], "no preset leaves the list alone")
+}
+
+func TestRequestPanel_CopyGrpcurl(t *testing.T) {
+	p := newTestPanel(t)
+	var gotBody string
+	var gotMessages []string
+	var gotMetadata map[string]string
+	p.SetOnCopyGrpcurl(func(body string, messages []string, metadata map[string]string) {
+		gotBody, gotMessages, gotMetadata = body, messages, metadata
+	})
+
+	_ = p.state.TextData.Set(`{"id": "1"}`)
+	addHeader(p, "x-trace", "abc")
+	p.SetSendEnabled(true)
+	test.Tap(p.grpcurlBtn)
+
+	assert.Equal(t, `{"id": "1"}`, gotBody)
+	assert.Nil(t, gotMessages, "not streaming")
+	assert.Equal(t, map[string]string{"x-trace": "abc"}, gotMetadata)
+
+	// Client streaming: sent messages plus the one being written
+	p.SetClientStreaming(true)
+	p.SetOnStreamSend(func(string, map[string]string) {})
+	p.StreamingInput().SetCurrentMessage(`{"id": "2"}`)
+	p.StreamingInput().TriggerSend()
+	p.StreamingInput().SetCurrentMessage(`{"id": "3"}`)
+	test.Tap(p.grpcurlBtn)
+	require.Len(t, gotMessages, 2)
+	assert.JSONEq(t, `{"id": "2"}`, gotMessages[0])
+	assert.JSONEq(t, `{"id": "3"}`, gotMessages[1])
+}
+
+func TestRequestPanel_SavedRequests(t *testing.T) {
+	const method = "users.UserService/CreateUser"
+	p := newTestPanel(t)
+	repo := storage.NewMemoryRepository()
+
+	// Saving stores the current body and metadata under a name
+	p.SetOnSaveRequest(func(body string, metadata map[string]string) {
+		require.NoError(t, repo.SaveRequest(domain.SavedRequest{
+			Name:    "missing email",
+			Request: domain.Request{Method: method, Body: body, Metadata: metadata},
+		}))
+	})
+	var deleted string
+	p.SetOnDeleteSavedRequest(func(name string) { deleted = name })
+
+	assert.True(t, p.saveRequestBtn.Disabled(), "disabled until a method is selected")
+	p.SetSendEnabled(true)
+
+	_ = p.state.TextData.Set(`{"name": "Ada"}`)
+	addHeader(p, "authorization", "Bearer abc")
+	test.Tap(p.saveRequestBtn)
+
+	// Reload the library, as on method selection, after editing the request
+	saved, err := repo.GetSavedRequests(method)
+	require.NoError(t, err)
+	p.SetSavedRequests(saved)
+	assert.Equal(t, []string{"missing email"}, p.savedSelect.Options)
+	assert.True(t, p.HasSavedRequest("missing email"))
+	assert.False(t, p.HasSavedRequest("happy path"))
+	assert.True(t, p.deleteSavedBtn.Disabled(), "nothing selected")
+
+	_ = p.state.TextData.Set(`{}`)
+	p.SetMetadata(nil)
+
+	// Selecting applies body and metadata
+	p.savedSelect.SetSelected("missing email")
+	body, _ := p.state.TextData.Get()
+	assert.Equal(t, `{"name": "Ada"}`, body)
+	assert.Equal(t, map[string]string{"authorization": "Bearer abc"}, p.GetMetadata())
+	assert.Equal(t, "missing email", p.SelectedSavedRequest())
+
+	require.False(t, p.deleteSavedBtn.Disabled())
+	test.Tap(p.deleteSavedBtn)
+	assert.Equal(t, "missing email", deleted)
+
+	// A new method's library starts unselected
+	p.SetSavedRequests(nil)
+	assert.Empty(t, p.SelectedSavedRequest())
+	assert.True(t, p.deleteSavedBtn.Disabled())
+}
+
+func TestRequestPanel_Highlight(t *testing.T) {
+	p := newTestPanel(t)
+	_ = p.state.TextData.Set(`{"name": "Ada"}`)
+	assert.False(t, p.IsHighlighted())
+	assert.Equal(t, []fyne.CanvasObject{p.textEditor}, p.textStack.Objects)
+
+	test.Tap(p.highlightToggle)
+	require.True(t, p.IsHighlighted())
+	assert.Equal(t, []fyne.CanvasObject{p.highlightView}, p.textStack.Objects)
+	assert.Equal(t, `{"name": "Ada"}`, p.highlightView.Text())
+
+	// Changes from outside the editor show up in the view
+	_ = p.state.TextData.Set(`{"name": "Grace"}`)
+	assert.Equal(t, `{"name": "Grace"}`, p.highlightView.Text())
+
+	// Tapping the view goes back to editing
+	test.Tap(p.highlightView)
+	assert.False(t, p.IsHighlighted())
+	assert.Equal(t, []fyne.CanvasObject{p.textEditor}, p.textStack.Objects)
+	assert.Equal(t, `{"name": "Grace"}`, p.textEditor.Text)
+}
+
+func TestRequestPanel_DefaultMetadata(t *testing.T) {
+	p := newTestPanel(t)
+	assert.True(t, p.defaultsSection.Hidden, "hidden without defaults")
+
+	addHeader(p, "X-Tenant", "beta")
+	p.SetDefaultMetadata(map[string]string{"x-tenant": "acme", "x-api-key": "k", "authorization": "Bearer d"})
+	require.False(t, p.defaultsSection.Hidden)
+
+	rowText := func() []string {
+		var texts []string
+		for _, obj := range p.defaultsBox.Objects {
+			texts = append(texts, obj.(*widget.Label).Text)
+		}
+		return texts
+	}
+	assert.Equal(t, []string{
+		"authorization: Bearer d",
+		"x-api-key: k",
+		"x-tenant: acme" + overriddenSuffix,
+	}, rowText())
+
+	// The auth preset replaces the default authorization too
+	p.SetAuth(domain.Auth{Type: domain.AuthBearer, Token: "mine"})
+	assert.Equal(t, "authorization: Bearer d"+overriddenSuffix, rowText()[0])
+
+	assert.Equal(t, map[string]string{
+		"X-Tenant":      "beta",
+		"x-api-key":     "k",
+		"authorization": "Bearer mine",
+	}, p.EffectiveMetadata())
+	assert.Equal(t, map[string]string{"X-Tenant": "beta", "authorization": "Bearer mine"}, p.SendMetadata(),
+		"defaults are merged by the invoker, not sent from the panel")
+
+	p.SetDefaultMetadata(nil)
+	assert.True(t, p.defaultsSection.Hidden)
+}
@@ -0,0 +1,136 @@
+package request
+
+import (
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// initSavedRequests creates the saved requests dropdown and its save and
+// delete buttons, all disabled until a method is selected.
+func (p *RequestPanel) initSavedRequests() {
+	p.savedSelect = widget.NewSelect(nil, func(name string) {
+		p.applySavedRequest(name)
+	})
+	p.savedSelect.PlaceHolder = "Saved requests"
+	p.savedSelect.Disable()
+
+	p.saveRequestBtn = widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		p.handleSaveRequest()
+	})
+	p.saveRequestBtn.Importance = widget.LowImportance
+	p.saveRequestBtn.Disable()
+
+	p.deleteSavedBtn = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if name := p.savedSelect.Selected; name != "" && p.onDeleteSaved != nil {
+			p.onDeleteSaved(name)
+		}
+	})
+	p.deleteSavedBtn.Importance = widget.LowImportance
+	p.deleteSavedBtn.Disable()
+
+	p.savedControls = container.NewHBox(p.savedSelect, p.saveRequestBtn, p.deleteSavedBtn)
+}
+
+// SetOnSaveRequest sets the callback for the save icon. It receives the
+// request body and metadata; naming and storing the request is up to the
+// caller.
+func (p *RequestPanel) SetOnSaveRequest(fn func(body string, metadata map[string]string)) {
+	p.onSaveRequest = fn
+}
+
+// SetOnDeleteSavedRequest sets the callback for the delete icon. It receives
+// the name of the selected saved request.
+func (p *RequestPanel) SetOnDeleteSavedRequest(fn func(name string)) {
+	p.onDeleteSaved = fn
+}
+
+// SetSavedRequests replaces the saved requests offered in the dropdown and
+// clears its selection.
+func (p *RequestPanel) SetSavedRequests(requests []domain.SavedRequest) {
+	p.savedRequests = requests
+	names := make([]string, len(requests))
+	for i, req := range requests {
+		names[i] = req.Name
+	}
+	p.savedSelect.Options = names
+	p.savedSelect.ClearSelected()
+	p.deleteSavedBtn.Disable()
+	p.savedSelect.Refresh()
+}
+
+// SelectSavedRequest selects the named saved request and applies it to the
+// body and metadata.
+func (p *RequestPanel) SelectSavedRequest(name string) {
+	p.savedSelect.SetSelected(name)
+}
+
+// SelectedSavedRequest returns the name of the selected saved request, or ""
+// when none is selected.
+func (p *RequestPanel) SelectedSavedRequest() string {
+	return p.savedSelect.Selected
+}
+
+// HasSavedRequest reports whether a saved request with the name is offered
+// for the current method.
+func (p *RequestPanel) HasSavedRequest(name string) bool {
+	_, ok := p.findSavedRequest(name)
+	return ok
+}
+
+// findSavedRequest returns the saved request with the given name.
+func (p *RequestPanel) findSavedRequest(name string) (domain.SavedRequest, bool) {
+	for _, req := range p.savedRequests {
+		if req.Name == name {
+			return req, true
+		}
+	}
+	return domain.SavedRequest{}, false
+}
+
+// applySavedRequest fills the body and metadata from the named request.
+func (p *RequestPanel) applySavedRequest(name string) {
+	req, ok := p.findSavedRequest(name)
+	if !ok {
+		p.deleteSavedBtn.Disable()
+		return
+	}
+	if !p.saveRequestBtn.Disabled() {
+		p.deleteSavedBtn.Enable()
+	}
+
+	_ = p.state.TextData.Set(req.Request.Body)
+	p.SetMetadata(req.Request.Metadata)
+	p.SyncTextToForm()
+}
+
+// handleSaveRequest collects the body and metadata and invokes onSaveRequest.
+func (p *RequestPanel) handleSaveRequest() {
+	if p.onSaveRequest == nil {
+		return
+	}
+
+	if mode, _ := p.state.Mode.Get(); mode == "form" && p.formBuilder != nil {
+		p.synchronizer.SyncFormToTextNow()
+	}
+
+	body, _ := p.state.TextData.Get()
+	p.onSaveRequest(body, p.GetMetadata())
+}
+
+// setSavedEnabled enables or disables the saved request controls. Delete
+// also needs a selection.
+func (p *RequestPanel) setSavedEnabled(enabled bool) {
+	if enabled {
+		p.savedSelect.Enable()
+		p.saveRequestBtn.Enable()
+		if p.savedSelect.Selected != "" {
+			p.deleteSavedBtn.Enable()
+		}
+	} else {
+		p.savedSelect.Disable()
+		p.saveRequestBtn.Disable()
+		p.deleteSavedBtn.Disable()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/ui/streambatch"
 	"github.com/shhac/grotto/internal/ui/streamconst"
 )
 
@@ -16,9 +17,11 @@ import (
 type StreamingInputWidget struct {
 	widget.BaseWidget
 
-	messageEntry *widget.Entry      // Current message to send (multiline JSON editor)
-	sentList     *widget.List       // List of sent messages
-	sentMessages binding.StringList // Binding for sent messages
+	messageEntry *widget.Entry       // Current message to send (multiline JSON editor)
+	batch        *streambatch.Sender // Timed sequence of messages to send
+	messageTabs  *container.AppTabs  // Next message or batch
+	sentList     *widget.List        // List of sent messages
+	sentMessages binding.StringList  // Binding for sent messages
 
 	sendBtn   *widget.Button // Send current message
 	finishBtn *widget.Button // Close stream and get response
@@ -79,6 +82,10 @@ func NewStreamingInputWidget() *StreamingInputWidget {
 	})
 	w.abortBtn.Importance = widget.DangerImportance
 
+	// Batch mode sends each message as if typed and sent one by one
+	w.batch = streambatch.NewSender()
+	w.batch.SetOnSend(w.sendMessage)
+
 	w.ExtendBaseWidget(w)
 	return w
 }
@@ -98,7 +105,36 @@ func (w *StreamingInputWidget) SetOnAbort(fn func()) {
 	w.onAbort = fn
 }
 
-// handleSend sends the current message and adds it to the sent list.
+// TriggerSend sends the current message as if Send Message were clicked,
+// unless sending has been disabled.
+func (w *StreamingInputWidget) TriggerSend() {
+	if w.sendBtn.Disabled() {
+		return
+	}
+	w.handleSend()
+}
+
+// SetBatchValidator sets the check each message of a batch must pass before
+// the batch starts.
+func (w *StreamingInputWidget) SetBatchValidator(fn func(json string) error) {
+	w.batch.SetValidator(fn)
+}
+
+// SetBatchMessages fills the Send batch tab with msgs and shows it.
+func (w *StreamingInputWidget) SetBatchMessages(msgs []string) {
+	w.batch.SetMessages(msgs)
+	if w.messageTabs != nil { // Built with the renderer
+		w.messageTabs.SelectIndex(1)
+	}
+}
+
+// StopBatch stops a running batch before its next message, e.g. after a
+// send failed. The stream can still be closed.
+func (w *StreamingInputWidget) StopBatch() {
+	w.batch.Stop()
+}
+
+// handleSend sends the current message and clears the entry.
 func (w *StreamingInputWidget) handleSend() {
 	if w.onSend == nil {
 		return
@@ -109,6 +145,18 @@ func (w *StreamingInputWidget) handleSend() {
 		return // Don't send empty messages
 	}
 
+	w.sendMessage(msg)
+
+	// Clear the entry for next message
+	w.messageEntry.SetText("")
+}
+
+// sendMessage sends msg and adds it to the sent list.
+func (w *StreamingInputWidget) sendMessage(msg string) {
+	if w.onSend == nil {
+		return
+	}
+
 	// Call the callback
 	w.onSend(msg)
 
@@ -124,9 +172,6 @@ func (w *StreamingInputWidget) handleSend() {
 		}
 	}
 
-	// Clear the entry for next message
-	w.messageEntry.SetText("")
-
 	// Refresh the list
 	w.sentList.Refresh()
 	w.updateStatus()
@@ -138,6 +183,7 @@ func (w *StreamingInputWidget) handleFinish() {
 		return
 	}
 
+	w.batch.Disable()
 	w.onFinish()
 	w.sendBtn.Disable()
 	w.finishBtn.Disable()
@@ -154,6 +200,8 @@ func (w *StreamingInputWidget) Clear() {
 	w.sentList.Refresh()
 	w.sendBtn.Enable()
 	w.finishBtn.Enable()
+	w.batch.Stop()
+	w.batch.Enable()
 	w.statusLabel.SetText("Ready")
 }
 
@@ -162,6 +210,17 @@ func (w *StreamingInputWidget) GetCurrentMessage() string {
 	return w.messageEntry.Text
 }
 
+// Messages returns the messages sent so far followed by the current one,
+// if any: everything a replay of the stream would send.
+func (w *StreamingInputWidget) Messages() []string {
+	msgs, _ := w.sentMessages.Get()
+	msgs = append([]string(nil), msgs...)
+	if w.messageEntry.Text != "" {
+		msgs = append(msgs, w.messageEntry.Text)
+	}
+	return msgs
+}
+
 // SetCurrentMessage sets the current message text.
 func (w *StreamingInputWidget) SetCurrentMessage(text string) {
 	w.messageEntry.SetText(text)
@@ -174,6 +233,7 @@ func (w *StreamingInputWidget) SetStatus(status string) {
 
 // DisableSendControls disables all send controls.
 func (w *StreamingInputWidget) DisableSendControls() {
+	w.batch.Disable()
 	w.sendBtn.Disable()
 	w.finishBtn.Disable()
 	w.messageEntry.Disable()
@@ -201,14 +261,10 @@ func (w *StreamingInputWidget) CreateRenderer() fyne.WidgetRenderer {
 		w.sentList,
 	)
 
-	// Next message section
-	nextLabel := widget.NewLabel("Next message:")
-	nextLabel.TextStyle = fyne.TextStyle{Bold: true}
-
-	messageSection := container.NewBorder(
-		nextLabel,
-		nil, nil, nil,
-		w.messageEntry,
+	// Next message section, or a batch of them
+	w.messageTabs = container.NewAppTabs(
+		container.NewTabItem("Next message", w.messageEntry),
+		container.NewTabItem("Send batch", w.batch),
 	)
 
 	// Buttons at bottom - send/finish on left, abort on right
@@ -225,8 +281,8 @@ func (w *StreamingInputWidget) CreateRenderer() fyne.WidgetRenderer {
 		buttonBox, // bottom (buttons)
 		nil, nil,  // left, right
 		container.NewVSplit(
-			sentSection,    // top half (sent messages)
-			messageSection, // bottom half (next message)
+			sentSection,   // top half (sent messages)
+			w.messageTabs, // bottom half (next message)
 		),
 	)
 
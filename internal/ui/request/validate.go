@@ -0,0 +1,222 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/protoconv"
+	"github.com/shhac/grotto/internal/ui/form"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// checkRequestJSON dry-runs a JSON request body against the message
+// descriptor and returns everything that would make the send fail:
+// malformed JSON, well-known type values in the wrong syntax, and any other
+// protojson rejection. Base64url bytes and human-friendly durations are
+// not problems since they are converted at send time. Any values are
+// checked against types, which may be nil.
+func checkRequestJSON(text string, md protoreflect.MessageDescriptor, types *protoconv.TypeResolver) []protoconv.FieldError {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		return []protoconv.FieldError{{Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	if md == nil {
+		return nil
+	}
+
+	normalized, problems, _ := normalizeRequest(text, md)
+	if len(problems) > 0 {
+		return problems
+	}
+
+	// Unknown top-level fields are listed on their own and left out of the
+	// parse, so they do not hide the problems that follow them []protoconv.FieldError
+	unknown := protoconv.UnknownFields(normalized, md)
+	for _, name := range unknown {
+		problems = append(problems, protoconv.FieldError{
+			Path:    name,
+			Message: "not a field of " + string(md.FullName()),
+			Unknown: true,
+		})
+	}
+	known := withoutKeys(normalized, unknown)
+	if err := types.Unmarshal(protojson.UnmarshalOptions{}, []byte(known), dynamicpb.NewMessage(md)); err != nil {
+		problems = append(problems, protoconv.ExplainJSONError(err.Error(), known, md))
+	}
+	return problems
+}
+
+// withoutKeys returns the JSON object text with the top-level keys
+// removed, or text unchanged when there are none.
+func withoutKeys(text string, keys []string) string {
+	if len(keys) == 0 {
+		return text
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return text
+	}
+	for _, key := range keys {
+		delete(obj, key)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return text
+	}
+	return string(data)
+}
+
+// hasUnknownFields reports whether any of problems names a field the
+// message does not have.
+func hasUnknownFields(problems []protoconv.FieldError) bool {
+	for _, p := range problems {
+		if p.Unknown {
+			return true
+		}
+	}
+	return false
+}
+
+// discardUnknownFields re-encodes a JSON request body as an md message,
+// dropping the fields md does not have at any depth. Any values are
+// resolved with types, which may be nil.
+func discardUnknownFields(text string, md protoreflect.MessageDescriptor, types *protoconv.TypeResolver) (string, error) {
+	msg := dynamicpb.NewMessage(md)
+	if err := types.Unmarshal(protojson.UnmarshalOptions{DiscardUnknown: true}, []byte(text), msg); err != nil {
+		return "", err
+	}
+	data, err := types.Marshal(protojson.MarshalOptions{}, msg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// jsonSeverity ranks the inline validation shown under the text editor.
+type jsonSeverity int
+
+const (
+	jsonValid   jsonSeverity = iota
+	jsonWarning              // Well-formed, but names fields the message does not have
+	jsonInvalid
+)
+
+// jsonStatus is the inline validation result for the text editor.
+type jsonStatus struct {
+	Severity jsonSeverity
+	Message  string
+}
+
+// validateRequestText checks the text editor's contents as the user types.
+// Unlike checkRequestJSON it locates problems by line and column where it
+// can, and downgrades unknown fields to a warning, since the user may be
+// sending unusual JSON on purpose.
+func validateRequestText(text string, md protoreflect.MessageDescriptor, types *protoconv.TypeResolver) jsonStatus {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		msg := err.Error()
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := offsetPosition(text, syntaxErr.Offset)
+			msg = atPosition(line, col, msg)
+		}
+		return jsonStatus{Severity: jsonInvalid, Message: "Invalid JSON: " + msg}
+	}
+	if md == nil {
+		return jsonStatus{Message: "Valid JSON"}
+	}
+
+	normalized, problems, base64URL := normalizeRequest(text, md)
+	if len(problems) > 0 {
+		return jsonStatus{Severity: jsonInvalid, Message: problems[0].Error()}
+	}
+
+	if err := types.Unmarshal(protojson.UnmarshalOptions{}, []byte(normalized), dynamicpb.NewMessage(md)); err != nil {
+		msg, line, col := protoconv.SplitJSONError(err.Error())
+		// Positions refer to the normalized text, which only matches what
+		// the user typed when nothing needed converting
+		if line > 0 && normalized == text {
+			msg = atPosition(line, col, msg)
+		}
+		severity := jsonInvalid
+		if strings.HasPrefix(msg, "unknown field") || strings.Contains(msg, ": unknown field") {
+			severity = jsonWarning
+		}
+		return jsonStatus{Severity: severity, Message: fmt.Sprintf("%s: %s", md.FullName(), msg)}
+	}
+
+	status := "Valid JSON"
+	if len(base64URL) > 0 {
+		status += " — base64url detected in " + strings.Join(base64URL, ", ") + " — will be converted"
+	}
+	return jsonStatus{Message: status}
+}
+
+// offsetPosition converts an encoding/json error offset, the number of
+// bytes read when the error was found, to a 1-based line and column.
+func offsetPosition(text string, offset int64) (int, int) {
+	idx := int(offset) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx > len(text) {
+		idx = len(text)
+	}
+	before := text[:idx]
+	line := strings.Count(before, "\n") + 1
+	col := idx - strings.LastIndex(before, "\n")
+	return line, col
+}
+
+// atPosition prefixes msg with a line and column.
+func atPosition(line, col int, msg string) string {
+	return fmt.Sprintf("line %d, column %d: %s", line, col, msg)
+}
+
+// normalizeRequestJSON applies the send-time conversions: base64url bytes
+// to standard base64 and human-friendly durations to protojson seconds.
+func normalizeRequestJSON(text string, md protoreflect.MessageDescriptor) string {
+	text, _, _ = normalizeRequest(text, md)
+	return text
+}
+
+// normalizeRequest applies the send-time conversions in one pass over the
+// JSON, returning the converted text, the well-known type values in the
+// wrong syntax, and the paths of base64url bytes values.
+func normalizeRequest(text string, md protoreflect.MessageDescriptor) (string, []protoconv.FieldError, []string) {
+	var problems []protoconv.FieldError
+	var base64URL []string
+	text = protoconv.RewriteJSON(text, md, form.NormalizeBytes(&base64URL), protoconv.NormalizeWellKnown(&problems))
+	return text, problems, base64URL
+}
+
+// checkMetadata reports binary (-bin) headers whose values are not base64.
+// Keys are checked in sorted order so the report is stable.
+func checkMetadata(headers map[string]string) []protoconv.FieldError {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []protoconv.FieldError
+	for _, key := range keys {
+		if !grpc.IsBinaryHeader(key) {
+			continue
+		}
+		if _, err := grpc.DecodeBinaryHeader(headers[key]); err != nil {
+			problems = append(problems, protoconv.FieldError{Path: "metadata " + key, Message: err.Error()})
+		}
+	}
+	return problems
+}
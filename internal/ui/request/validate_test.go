@@ -0,0 +1,138 @@
+package request
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+)
+
+func TestCheckRequestJSON(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	tests := []struct {
+		name     string
+		text     string
+		wantErrs []string
+	}{
+		{"empty", "", nil},
+		{"valid", `{"item":{"name":"x","createdAt":"2024-06-15T08:00:00Z"}}`, nil},
+		{"human duration accepted", `{"item":{"ttl":"5m"}}`, nil},
+		{"base64url bytes accepted", `{"item":{"data":"-_8"}}`, nil},
+		{"bad timestamp", `{"item":{"createdAt":"2024-06-15"}}`, []string{"item.createdAt: " + protoconv.TimestampHint}},
+		{"bad duration", `{"item":{"ttl":"soon"}}`, []string{"item.ttl: " + protoconv.DurationHint}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range checkRequestJSON(tt.text, md, nil) {
+				got = append(got, p.Error())
+			}
+			assert.Equal(t, tt.wantErrs, got)
+		})
+	}
+}
+
+func TestCheckRequestJSON_OtherProblems(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	problems := checkRequestJSON(`{"item":`, md, nil)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0].Message, "invalid JSON")
+
+	problems = checkRequestJSON(`{"item":{"count":"many"}}`, md, nil)
+	require.Len(t, problems, 1)
+	assert.Equal(t, `item.count: expected int32, got "many"`, problems[0].Error())
+	assert.False(t, hasUnknownFields(problems))
+}
+
+func TestCheckRequestJSON_UnknownFields(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	// Unknown top-level fields are listed first, without hiding later problems
+	problems := checkRequestJSON(`{"zzz":1,"item":{"count":"many"},"extra":true}`, md, nil)
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Error())
+	}
+	assert.Equal(t, []string{
+		"extra: not a field of grpctest.ItemRequest",
+		"zzz: not a field of grpctest.ItemRequest",
+		`item.count: expected int32, got "many"`,
+	}, got)
+	assert.True(t, problems[0].Unknown)
+	assert.False(t, problems[2].Unknown)
+
+	problems = checkRequestJSON(`{"item":{"nmae":"x"}}`, md, nil)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "item.nmae: not a field of grpctest.Item", problems[0].Error())
+	assert.True(t, hasUnknownFields(problems))
+}
+
+func TestDiscardUnknownFields(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	got, err := discardUnknownFields(`{"extra":1,"item":{"name":"x","nmae":"y"}}`, md, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"item":{"name":"x"}}`, got)
+
+	_, err = discardUnknownFields(`{"extra":1,"item":{"count":"many"}}`, md, nil)
+	assert.Error(t, err)
+}
+
+func TestCheckMetadata(t *testing.T) {
+	problems := checkMetadata(map[string]string{
+		"b-bin":         "AAEC", // padded-free standard base64
+		"a-bin":         "AAE",  // raw standard base64
+		"z-bin":         "@@",   // not base64
+		"authorization": "@@",   // not binary, anything goes
+	})
+	require.Len(t, problems, 1)
+	assert.Equal(t, "metadata z-bin", problems[0].Path)
+}
+
+func TestOffsetPosition(t *testing.T) {
+	text := "{\n  \"a\": x\n}"
+	line, col := offsetPosition(text, int64(strings.Index(text, "x")+1))
+	assert.Equal(t, 2, line)
+	assert.Equal(t, 8, col)
+
+	line, col = offsetPosition(text, 0)
+	assert.Equal(t, 1, line)
+	assert.Equal(t, 1, col)
+}
+
+func TestValidateRequestText(t *testing.T) {
+	md := (&pb.ItemRequest{}).ProtoReflect().Descriptor()
+
+	tests := []struct {
+		name         string
+		text         string
+		wantSeverity jsonSeverity
+		wantMsg      string
+	}{
+		{"valid", `{"item": {"name": "x"}}`, jsonValid, "Valid JSON"},
+		{"no method", `{"anything": 1}`, jsonValid, "Valid JSON"},
+		{"syntax error", "{\n  \"item\": x\n}", jsonInvalid, "Invalid JSON: line 2, column 11: invalid character 'x' looking for beginning of value"},
+		{"unknown field", "{\n  \"item\": {\"nmae\": \"x\"}\n}", jsonWarning, `grpctest.ItemRequest: line 2, column 12: unknown field "nmae"`},
+		{"wrong type", `{"item": {"count": "many"}}`, jsonInvalid, ""},
+		{"converted text has no position", `{"item": {"ttl": "5m", "nmae": 1}}`, jsonWarning, `grpctest.ItemRequest: unknown field "nmae"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc := md
+			if tt.name == "no method" {
+				desc = nil
+			}
+			got := validateRequestText(tt.text, desc, nil)
+			assert.Equal(t, tt.wantSeverity, got.Severity)
+			if tt.wantMsg != "" {
+				assert.Equal(t, tt.wantMsg, got.Message)
+			}
+		})
+	}
+}
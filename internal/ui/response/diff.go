@@ -0,0 +1,58 @@
+package response
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/diff"
+)
+
+// diffContextLines is how many unchanged lines surround each change.
+const diffContextLines = 3
+
+// diffOpColor maps diff line kinds to theme colors.
+var diffOpColor = map[diff.Op]fyne.ThemeColorName{
+	diff.Equal:  theme.ColorNameForeground,
+	diff.Delete: theme.ColorNameError,
+	diff.Insert: theme.ColorNameSuccess,
+}
+
+// diffOpPrefix marks each line as in a unified diff.
+var diffOpPrefix = map[diff.Op]string{
+	diff.Equal:  " ",
+	diff.Delete: "-",
+	diff.Insert: "+",
+}
+
+// DiffSegments renders a unified diff of the pinned and current responses
+// as colored RichText segments: removed lines red, added lines green.
+func DiffSegments(pinned, current string) []widget.RichTextSegment {
+	lines := diff.JSON(pinned, current)
+	if !diff.Changed(lines) {
+		return []widget.RichTextSegment{diffSegment("No differences from the pinned response", theme.ColorNameDisabled)}
+	}
+
+	var segments []widget.RichTextSegment
+	for _, h := range diff.Hunks(lines, diffContextLines) {
+		segments = append(segments, diffSegment(h.Header(), theme.ColorNamePrimary))
+		for _, l := range h.Lines {
+			segments = append(segments, diffSegment(diffOpPrefix[l.Op]+l.Text, diffOpColor[l.Op]))
+		}
+	}
+	return segments
+}
+
+// diffSegment is one monospaced line of the diff.
+func diffSegment(text string, color fyne.ThemeColorName) *widget.TextSegment {
+	return &widget.TextSegment{
+		Style: widget.RichTextStyle{
+			ColorName: color,
+			Inline:    true,
+			SizeName:  theme.SizeNameText,
+			TextStyle: fyne.TextStyle{Monospace: true},
+		},
+		Text: strings.TrimRight(text, "\r") + "\n",
+	}
+}
@@ -0,0 +1,226 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// treePageSize is how many array elements the tree loads at a time; longer
+// arrays end in a "show more" node.
+const treePageSize = 200
+
+// moreNodePrefix starts the ID of an array's "show more" node. JSON paths
+// always start with "$", so the IDs can't collide.
+const moreNodePrefix = "more:"
+
+// JSONNodeKind is the JSON type of a tree node.
+type JSONNodeKind int
+
+const (
+	JSONObject JSONNodeKind = iota
+	JSONArray
+	JSONString
+	JSONNumber
+	JSONBool
+	JSONNull
+	// JSONMore stands in for the elements of an array that aren't loaded yet.
+	JSONMore
+)
+
+// JSONNode is one value in a JSONTree.
+type JSONNode struct {
+	// ID is the node's JSON path, e.g. $.items[3].id, and its tree node ID.
+	ID string
+	// Key is the member name or "[i]" index the node is shown under.
+	Key  string
+	Kind JSONNodeKind
+	// Value is the JSON text of a scalar, or the "show more" label.
+	Value string
+	// Len is the number of members or elements of an object or array, or the
+	// number of elements still hidden behind a "show more" node.
+	Len int
+
+	raw      any
+	children []string // loaded child IDs; nil until first asked for
+	loaded   int      // array elements loaded so far
+}
+
+// IsBranch reports whether the node has children.
+func (n *JSONNode) IsBranch() bool {
+	return n.Kind == JSONObject || n.Kind == JSONArray
+}
+
+// JSONTree is a lazily expanded tree model of a JSON document, shaped for
+// widget.Tree: object members sorted by key and array elements are branches,
+// scalars are leaves. Children are built on first access, and arrays load
+// treePageSize elements at a time.
+type JSONTree struct {
+	nodes map[string]*JSONNode
+	root  *JSONNode
+}
+
+// NewJSONTree parses text into a tree. Numbers keep their original text.
+func NewJSONTree(text string) (*JSONTree, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid JSON: unexpected data after the top-level value")
+	}
+
+	t := &JSONTree{nodes: make(map[string]*JSONNode)}
+	t.root = t.add("$", "$", v)
+	return t, nil
+}
+
+// Node returns the node with the given ID, or nil.
+func (t *JSONTree) Node(id string) *JSONNode {
+	return t.nodes[id]
+}
+
+// IsBranch reports whether id has children. The empty ID is the tree root.
+func (t *JSONTree) IsBranch(id string) bool {
+	if id == "" {
+		return true
+	}
+	n := t.nodes[id]
+	return n != nil && n.IsBranch()
+}
+
+// ChildIDs returns the loaded children of id. The empty ID is the tree root:
+// its children are the members of a top-level object or array, or the
+// top-level value itself when it is a scalar.
+func (t *JSONTree) ChildIDs(id string) []string {
+	if id == "" {
+		if !t.root.IsBranch() {
+			return []string{t.root.ID}
+		}
+		id = t.root.ID
+	}
+	n := t.nodes[id]
+	if n == nil || !n.IsBranch() {
+		return nil
+	}
+	if n.children == nil {
+		t.load(n)
+	}
+	return n.children
+}
+
+// ShowMore loads the next page of the array behind the "show more" node id.
+// It reports whether id was a "show more" node.
+func (t *JSONTree) ShowMore(id string) bool {
+	more := t.nodes[id]
+	if more == nil || more.Kind != JSONMore {
+		return false
+	}
+	parent := t.nodes[strings.TrimPrefix(id, moreNodePrefix)]
+	// Drop the old "show more" node; load adds a new one if needed
+	parent.children = parent.children[:len(parent.children)-1]
+	delete(t.nodes, id)
+	t.load(parent)
+	return true
+}
+
+// load builds the children of n: every member of an object, or the next page
+// of an array's elements.
+func (t *JSONTree) load(n *JSONNode) {
+	switch v := n.raw.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		n.children = make([]string, 0, len(keys))
+		for _, k := range keys {
+			child := t.add(n.ID+memberPath(k), k, v[k])
+			n.children = append(n.children, child.ID)
+		}
+
+	case []any:
+		end := min(n.loaded+treePageSize, len(v))
+		if n.children == nil {
+			n.children = make([]string, 0, end-n.loaded+1)
+		}
+		for i := n.loaded; i < end; i++ {
+			child := t.add(n.ID+"["+strconv.Itoa(i)+"]", "["+strconv.Itoa(i)+"]", v[i])
+			n.children = append(n.children, child.ID)
+		}
+		n.loaded = end
+
+		if remaining := len(v) - end; remaining > 0 {
+			more := &JSONNode{
+				ID:    moreNodePrefix + n.ID,
+				Kind:  JSONMore,
+				Value: fmt.Sprintf("show %d more (%d remaining)", min(remaining, treePageSize), remaining),
+				Len:   remaining,
+			}
+			t.nodes[more.ID] = more
+			n.children = append(n.children, more.ID)
+		}
+	}
+}
+
+// add registers a node for value v at path id.
+func (t *JSONTree) add(id, key string, v any) *JSONNode {
+	n := &JSONNode{ID: id, Key: key, raw: v}
+	switch v := v.(type) {
+	case map[string]any:
+		n.Kind, n.Len = JSONObject, len(v)
+	case []any:
+		n.Kind, n.Len = JSONArray, len(v)
+	case string:
+		n.Kind, n.Value = JSONString, quoteJSON(v)
+	case json.Number:
+		n.Kind, n.Value = JSONNumber, v.String()
+	case bool:
+		n.Kind, n.Value = JSONBool, strconv.FormatBool(v)
+	case nil:
+		n.Kind, n.Value = JSONNull, "null"
+	}
+	t.nodes[id] = n
+	return n
+}
+
+// memberPath returns the path step for object member key: .key for plain
+// identifiers, ["key"] otherwise.
+func memberPath(key string) string {
+	if isPathIdentifier(key) {
+		return "." + key
+	}
+	return "[" + quoteJSON(key) + "]"
+}
+
+func isPathIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteJSON returns s as a JSON string literal, leaving <, > and & as-is.
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
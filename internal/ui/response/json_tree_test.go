@@ -0,0 +1,119 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTree_NestedArrays(t *testing.T) {
+	tree, err := NewJSONTree(`{"matrix": [[1, 2], [], [[true]]], "name": "m"}`)
+	require.NoError(t, err)
+
+	// Top-level members are the roots, sorted by key
+	assert.Equal(t, []string{"$.matrix", "$.name"}, tree.ChildIDs(""))
+
+	matrix := tree.Node("$.matrix")
+	require.NotNil(t, matrix)
+	assert.Equal(t, JSONArray, matrix.Kind)
+	assert.Equal(t, 3, matrix.Len)
+	assert.Equal(t, []string{"$.matrix[0]", "$.matrix[1]", "$.matrix[2]"}, tree.ChildIDs("$.matrix"))
+
+	assert.Equal(t, []string{"$.matrix[0][0]", "$.matrix[0][1]"}, tree.ChildIDs("$.matrix[0]"))
+	assert.Equal(t, "[1]", tree.Node("$.matrix[0][1]").Key)
+	assert.Equal(t, "2", tree.Node("$.matrix[0][1]").Value)
+
+	// An empty array is still a branch, just without children
+	assert.True(t, tree.IsBranch("$.matrix[1]"))
+	assert.Empty(t, tree.ChildIDs("$.matrix[1]"))
+
+	tree.ChildIDs("$.matrix[2]")
+	deep := tree.Node("$.matrix[2][0][0]")
+	assert.Nil(t, deep, "children are built on first access")
+	tree.ChildIDs("$.matrix[2][0]")
+	deep = tree.Node("$.matrix[2][0][0]")
+	require.NotNil(t, deep)
+	assert.Equal(t, JSONBool, deep.Kind)
+	assert.Equal(t, "true", deep.Value)
+	assert.False(t, tree.IsBranch(deep.ID))
+}
+
+func TestJSONTree_NullAndScalars(t *testing.T) {
+	tree, err := NewJSONTree(`{"a": null, "b": "x<y", "c": 12345678901234567890, "d": {}}`)
+	require.NoError(t, err)
+	require.Len(t, tree.ChildIDs(""), 4)
+
+	null := tree.Node("$.a")
+	assert.Equal(t, JSONNull, null.Kind)
+	assert.Equal(t, "null", null.Value)
+	assert.False(t, null.IsBranch())
+
+	assert.Equal(t, `"x<y"`, tree.Node("$.b").Value)
+	assert.Equal(t, "12345678901234567890", tree.Node("$.c").Value, "numbers keep their text")
+	assert.Equal(t, JSONObject, tree.Node("$.d").Kind)
+
+	// A scalar document is a single leaf
+	tree, err = NewJSONTree(`null`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"$"}, tree.ChildIDs(""))
+	assert.Equal(t, JSONNull, tree.Node("$").Kind)
+}
+
+func TestJSONTree_Paths(t *testing.T) {
+	tree, err := NewJSONTree(`{"items": [{"id": 1}], "with space": {"a.b": 1, "_ok1": 2, "1st": 3}}`)
+	require.NoError(t, err)
+
+	tree.ChildIDs("")
+	tree.ChildIDs("$.items")
+	assert.Equal(t, []string{"$.items[0].id"}, tree.ChildIDs("$.items[0]"))
+	assert.Equal(t,
+		[]string{`$["with space"]["1st"]`, `$["with space"]._ok1`, `$["with space"]["a.b"]`},
+		tree.ChildIDs(`$["with space"]`))
+}
+
+func TestJSONTree_ShowMore(t *testing.T) {
+	elems := make([]string, 450)
+	for i := range elems {
+		elems[i] = fmt.Sprint(i)
+	}
+	tree, err := NewJSONTree(`{"items": [` + strings.Join(elems, ",") + `]}`)
+	require.NoError(t, err)
+	tree.ChildIDs("")
+
+	children := tree.ChildIDs("$.items")
+	require.Len(t, children, treePageSize+1)
+	assert.Equal(t, "$.items[199]", children[treePageSize-1])
+
+	more := tree.Node(children[treePageSize])
+	require.NotNil(t, more)
+	assert.Equal(t, JSONMore, more.Kind)
+	assert.Equal(t, 250, more.Len)
+	assert.Equal(t, "show 200 more (250 remaining)", more.Value)
+	assert.False(t, tree.IsBranch(more.ID))
+	assert.Nil(t, tree.Node("$.items[200]"), "the next page isn't built yet")
+
+	require.True(t, tree.ShowMore(more.ID))
+	children = tree.ChildIDs("$.items")
+	require.Len(t, children, 2*treePageSize+1)
+	assert.Equal(t, "$.items[200]", children[treePageSize])
+	assert.Equal(t, "show 50 more (50 remaining)", tree.Node(children[2*treePageSize]).Value)
+
+	require.True(t, tree.ShowMore(children[2*treePageSize]))
+	children = tree.ChildIDs("$.items")
+	require.Len(t, children, 450)
+	assert.Equal(t, "$.items[449]", children[449])
+	assert.Equal(t, "449", tree.Node("$.items[449]").Value)
+
+	assert.False(t, tree.ShowMore("$.items"), "only show-more nodes expand")
+	assert.False(t, tree.ShowMore(more.ID), "a used show-more node is gone")
+}
+
+func TestJSONTree_Invalid(t *testing.T) {
+	for _, text := range []string{"", "{", `{"a": 1} {"b": 2}`, "not json"} {
+		_, err := NewJSONTree(text)
+		assert.Error(t, err, "%q", text)
+	}
+}
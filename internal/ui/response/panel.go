@@ -3,6 +3,9 @@ package response
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,20 +14,20 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/jsonpath"
 	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/ui/components"
 )
 
-const maxDisplayBytes = 1_000_000 // 1 MB — cap response display to prevent segment explosion
-
 // ResponsePanel displays response data with reactive binding to state.
 type ResponsePanel struct {
 	widget.BaseWidget
 
 	window         fyne.Window
 	state          *model.ResponseState
-	richText       *widget.RichText
+	jsonView       *components.JSONView
 	placeholder    *widget.Label
-	jsonScroll     *fyne.Container // stack of richText + placeholder
+	jsonScroll     *fyne.Container // stack of jsonView + placeholder
 	errorLabel     *widget.Label
 	durationLabel  *widget.Label
 	sizeLabel      *widget.Label
@@ -33,11 +36,42 @@ type ResponsePanel struct {
 	copyCompactBtn *widget.Button
 	saveBtn        *widget.Button
 
+	// Loads the response into the request editor
+	useBtn         *widget.Button
+	onUseAsRequest func(json string)
+
+	// Pinned response compared against later responses
+	pinBtn   *widget.Button
+	pinned   *string // nil when nothing is pinned
+	diffText *widget.RichText
+	diffTab  *container.TabItem
+
+	// Collapsible tree of the response JSON, rebuilt when its tab is shown
+	tree        *widget.Tree
+	treeModel   *JSONTree // nil when the response is empty or not JSON
+	treeTab     *container.TabItem
+	treeMessage *widget.Label
+	treeHint    *widget.Label
+	treeText    string
+	treeStale   bool
+
+	// Paths of the enum values in unknownEnumsText, a response, that the
+	// method's descriptor does not declare; marked in the tree
+	unknownEnums     map[string]bool
+	unknownEnumsText string
+
+	// Path filter narrowing the shown response and stream messages
+	filterEntry *widget.Entry
+	filterNote  *widget.Label
+	filter      *jsonpath.Path // nil shows everything
+	shownText   string         // response text after filtering
+
 	// Select mode: toggle between colored RichText and selectable Entry
 	selectMode   bool
 	selectEntry  *ReadOnlyEntry
 	selectToggle *widget.Button
-	displayStack *fyne.Container // swaps between jsonScroll and selectEntry
+	displayStack *fyne.Container          // swaps between jsonScroll and selectEntry
+	displayTheme *container.ThemeOverride // displayStack in the editor font
 
 	// Response metadata display
 	metadataKeys binding.StringList
@@ -48,6 +82,10 @@ type ResponsePanel struct {
 	trailerList  *widget.List
 	responseTabs *container.AppTabs
 
+	// Where the time of the last call went, collapsed by default
+	timingText    *widget.Label
+	timingSection *components.TreeSection
+
 	// Streaming widget
 	streamingWidget *StreamingMessagesWidget
 	isStreaming     bool
@@ -78,14 +116,20 @@ func NewResponsePanel(state *model.ResponseState, window fyne.Window) *ResponseP
 // initializeComponents creates all UI components.
 func (p *ResponsePanel) initializeComponents() {
 	// Response text display (syntax-highlighted JSON)
-	p.richText = widget.NewRichText()
-	p.richText.Wrapping = fyne.TextWrapBreak
-	p.richText.Scroll = fyne.ScrollBoth
+	p.jsonView = components.NewJSONView()
+	p.jsonView.TruncationNote = "\n\n... (response too large for display - use copy button for full text) ..."
 
 	// Placeholder shown when no response
 	p.placeholder = widget.NewLabel("Send a request to see the response")
 	p.placeholder.Alignment = fyne.TextAlignCenter
-	p.jsonScroll = container.NewStack(p.richText, p.placeholder)
+	p.jsonScroll = container.NewStack(p.jsonView, p.placeholder)
+
+	// Path filter above the response and stream messages
+	p.filterEntry = widget.NewEntry()
+	p.filterEntry.SetPlaceHolder("Filter by path, e.g. items[*].id")
+	p.filterEntry.OnChanged = p.setFilter
+	p.filterNote = widget.NewLabel("")
+	p.filterNote.Hide()
 
 	// Duration and size labels
 	p.durationLabel = widget.NewLabel("")
@@ -122,6 +166,31 @@ func (p *ResponsePanel) initializeComponents() {
 	})
 	p.saveBtn.Hide()
 
+	// Use as request button (hidden until there's a response)
+	p.useBtn = widget.NewButtonWithIcon("Use as Request", theme.ContentRedoIcon(), func() {
+		text, _ := p.state.TextData.Get()
+		if text != "" && p.onUseAsRequest != nil {
+			p.onUseAsRequest(text)
+		}
+	})
+	p.useBtn.Hide()
+
+	// Pin button (hidden until there's a response)
+	p.pinBtn = widget.NewButton("Pin", func() {
+		if p.pinned != nil {
+			p.ClearPin()
+		} else {
+			p.PinResponse()
+		}
+	})
+	p.pinBtn.Hide()
+
+	// Diff of the current response against the pinned one
+	p.diffText = widget.NewRichText()
+	p.diffText.Wrapping = fyne.TextWrapOff
+	p.diffText.Scroll = fyne.ScrollBoth
+	p.diffTab = container.NewTabItem("Diff", p.diffText)
+
 	// Select mode: read-only Entry for text selection (full contrast, no edits)
 	p.selectEntry = NewReadOnlyMultiLineEntry()
 
@@ -133,6 +202,7 @@ func (p *ResponsePanel) initializeComponents() {
 
 	// Display stack: swaps between colored RichText and selectable Entry
 	p.displayStack = container.NewStack(p.jsonScroll)
+	p.displayTheme = components.EditorThemed(p.displayStack)
 
 	// Loading bar (infinite progress)
 	p.loadingBar = widget.NewProgressBarInfinite()
@@ -192,6 +262,14 @@ func (p *ResponsePanel) initializeComponents() {
 		},
 	)
 
+	// Timing breakdown (hidden until a call reports its timing)
+	p.timingText = widget.NewLabel("")
+	p.timingText.TextStyle = fyne.TextStyle{Monospace: true}
+	timingScroll := container.NewVScroll(p.timingText)
+	timingScroll.SetMinSize(fyne.NewSize(0, 120))
+	p.timingSection = components.NewCollapsibleSection("Timing", timingScroll)
+	p.timingSection.Hide()
+
 	// Streaming widget
 	p.streamingWidget = NewStreamingMessagesWidget(p.window)
 
@@ -201,11 +279,11 @@ func (p *ResponsePanel) initializeComponents() {
 		nil,
 		container.NewVBox(
 			widget.NewSeparator(),
-			container.NewBorder(nil, nil, container.NewHBox(p.durationLabel, p.sizeLabel), container.NewHBox(p.selectToggle, p.copyBtn, p.copyCompactBtn, p.saveBtn)),
+			container.NewBorder(nil, nil, container.NewHBox(p.durationLabel, p.sizeLabel), container.NewHBox(p.useBtn, p.pinBtn, p.selectToggle, p.copyBtn, p.copyCompactBtn, p.saveBtn)),
 		),
 		nil,
 		nil,
-		p.displayStack,
+		p.displayTheme,
 	)
 
 	// Metadata tab: headers and trailers
@@ -220,11 +298,19 @@ func (p *ResponsePanel) initializeComponents() {
 	)
 	metadataTabContent.SetOffset(0.5)
 
+	p.treeTab = container.NewTabItem("Tree", p.initTree())
+
 	// Create tabbed interface
 	p.responseTabs = container.NewAppTabs(
 		container.NewTabItem("Response", responseTabContent),
+		p.treeTab,
 		container.NewTabItem("Metadata", metadataTabContent),
 	)
+	p.responseTabs.OnSelected = func(item *container.TabItem) {
+		if item == p.treeTab && p.treeStale {
+			p.rebuildTree()
+		}
+	}
 
 	// Create content containers (wrap tabs in a container)
 	p.responseContent = container.NewMax(p.responseTabs)
@@ -248,41 +334,36 @@ func (p *ResponsePanel) setupBindings() {
 	// Listen to text data changes and re-highlight
 	p.state.TextData.AddListener(binding.NewDataListener(func() {
 		text, _ := p.state.TextData.Get()
+		p.setTreeText(text)
+		p.showText(text)
 		if text == "" {
-			p.richText.Segments = nil
-			p.richText.Refresh()
 			p.placeholder.Show()
 			p.copyBtn.Hide()
 			p.copyCompactBtn.Hide()
 			p.saveBtn.Hide()
+			p.useBtn.Hide()
+			p.pinBtn.Hide()
 			p.selectToggle.Hide()
 			// Exit select mode when response is cleared
 			if p.selectMode {
 				p.selectMode = false
 				p.selectToggle.SetIcon(theme.DocumentIcon())
 				p.displayStack.Objects = []fyne.CanvasObject{p.jsonScroll}
-				p.displayStack.Refresh()
+				p.displayTheme.Refresh()
 			}
 		} else {
 			p.placeholder.Hide()
 			p.copyBtn.Show()
 			p.copyCompactBtn.Show()
 			p.saveBtn.Show()
-			p.selectToggle.Show()
-			displayText := text
-			if len(displayText) > maxDisplayBytes {
-				displayText = displayText[:maxDisplayBytes]
-			}
-			p.richText.Segments = HighlightJSON(displayText)
-			if len(text) > maxDisplayBytes {
-				p.richText.Segments = append(p.richText.Segments, truncationSegment(
-					"\n\n... (response too large for display - use copy button for full text) ...",
-				))
+			if p.onUseAsRequest != nil {
+				p.useBtn.Show()
 			}
-			p.richText.Refresh()
-			// Keep select entry in sync
-			if p.selectMode {
-				p.selectEntry.SetText(text)
+			p.pinBtn.Show()
+			p.selectToggle.Show()
+			if p.pinned != nil && text != *p.pinned {
+				p.updateDiff(text)
+				p.responseTabs.Select(p.diffTab)
 			}
 		}
 	}))
@@ -368,8 +449,7 @@ func (p *ResponsePanel) toggleSelectMode() {
 	p.selectMode = !p.selectMode
 	if p.selectMode {
 		// Switch to selectable plain text
-		text, _ := p.state.TextData.Get()
-		p.selectEntry.SetText(text)
+		p.selectEntry.SetText(p.shownText)
 		p.displayStack.Objects = []fyne.CanvasObject{p.selectEntry}
 		p.selectToggle.SetIcon(theme.ColorPaletteIcon())
 	} else {
@@ -377,7 +457,92 @@ func (p *ResponsePanel) toggleSelectMode() {
 		p.displayStack.Objects = []fyne.CanvasObject{p.jsonScroll}
 		p.selectToggle.SetIcon(theme.DocumentIcon())
 	}
-	p.displayStack.Refresh()
+	p.displayTheme.Refresh() // Themes the view swapped in too
+}
+
+// setFilter narrows the response, and each stream message, to the
+// fragments the path expression expr selects. An empty expression shows
+// everything; an invalid one is reported and shows everything too.
+func (p *ResponsePanel) setFilter(expr string) {
+	p.filter = nil
+	p.setFilterNote("", widget.LowImportance)
+	if strings.TrimSpace(expr) != "" {
+		path, err := jsonpath.Parse(expr)
+		if err != nil {
+			p.setFilterNote("Invalid path: "+err.Error(), widget.DangerImportance)
+		} else {
+			p.filter = &path
+		}
+	}
+
+	text, _ := p.state.TextData.Get()
+	p.showText(text)
+	if p.filter != nil {
+		p.streamingWidget.SetFilter(p.filterMessage)
+	} else {
+		p.streamingWidget.SetFilter(nil)
+	}
+}
+
+// showText shows the response text, filtered when a filter is set, and
+// notes how many fragments matched.
+func (p *ResponsePanel) showText(text string) {
+	shown := text
+	if p.filter != nil && text != "" {
+		out, n, err := p.filter.Filter(text)
+		switch {
+		case err != nil:
+			p.setFilterNote("Response is not JSON", widget.WarningImportance)
+		case n == 0:
+			shown = ""
+			p.setFilterNote("No match", widget.WarningImportance)
+		default:
+			shown = out
+			p.setFilterNote(matchCount(n), widget.LowImportance)
+		}
+	} else if p.filter != nil {
+		p.setFilterNote("", widget.LowImportance)
+	}
+
+	p.shownText = shown
+	p.jsonView.SetText(shown)
+	if p.selectMode {
+		p.selectEntry.SetText(shown)
+	}
+}
+
+// filterMessage applies the filter to one stream message.
+func (p *ResponsePanel) filterMessage(msg string) string {
+	if p.filter == nil {
+		return msg
+	}
+	out, n, err := p.filter.Filter(msg)
+	if err != nil {
+		return msg
+	}
+	if n == 0 {
+		return "(no match)"
+	}
+	return out
+}
+
+// setFilterNote shows note next to the filter entry ("" hides it).
+func (p *ResponsePanel) setFilterNote(note string, importance widget.Importance) {
+	p.filterNote.Importance = importance
+	p.filterNote.SetText(note)
+	if note == "" {
+		p.filterNote.Hide()
+	} else {
+		p.filterNote.Show()
+	}
+}
+
+// matchCount describes how many fragments a filter matched.
+func matchCount(n int) string {
+	if n == 1 {
+		return "1 match"
+	}
+	return fmt.Sprintf("%d matches", n)
 }
 
 // exportResponseToFile saves the response text to a user-chosen file.
@@ -399,6 +564,63 @@ func (p *ResponsePanel) exportResponseToFile() {
 	d.Show()
 }
 
+// SetOnUseAsRequest sets the callback for the Use as Request button, which
+// receives the response JSON. The button is only shown once this is set.
+func (p *ResponsePanel) SetOnUseAsRequest(fn func(json string)) {
+	p.onUseAsRequest = fn
+}
+
+// PinResponse keeps the current response so the next ones are shown as a
+// diff against it.
+func (p *ResponsePanel) PinResponse() {
+	text, _ := p.state.TextData.Get()
+	if text == "" {
+		return
+	}
+	p.pinned = &text
+	p.pinBtn.SetText("Unpin")
+	p.updateDiff(text)
+	if !p.hasDiffTab() {
+		p.responseTabs.Append(p.diffTab)
+	}
+}
+
+// ClearPin forgets the pinned response and removes the diff, e.g. when
+// another method is selected.
+func (p *ResponsePanel) ClearPin() {
+	p.pinned = nil
+	p.pinBtn.SetText("Pin")
+	p.diffText.Segments = nil
+	p.diffText.Refresh()
+	if p.hasDiffTab() {
+		p.responseTabs.Remove(p.diffTab)
+	}
+}
+
+// Pinned returns the pinned response, if any.
+func (p *ResponsePanel) Pinned() (string, bool) {
+	if p.pinned == nil {
+		return "", false
+	}
+	return *p.pinned, true
+}
+
+// updateDiff renders current against the pinned response.
+func (p *ResponsePanel) updateDiff(current string) {
+	p.diffText.Segments = DiffSegments(*p.pinned, current)
+	p.diffText.Refresh()
+}
+
+// hasDiffTab reports whether the diff tab is showing.
+func (p *ResponsePanel) hasDiffTab() bool {
+	for _, item := range p.responseTabs.Items {
+		if item == p.diffTab {
+			return true
+		}
+	}
+	return false
+}
+
 // StreamingWidget returns the streaming widget for external control.
 func (p *ResponsePanel) StreamingWidget() *StreamingMessagesWidget {
 	return p.streamingWidget
@@ -410,10 +632,10 @@ func (p *ResponsePanel) SetResponseMetadata(md map[string]string) {
 	_ = p.metadataKeys.Set([]string{})
 	_ = p.metadataVals.Set([]string{})
 
-	// Add new metadata (convert map to lists)
-	for key, val := range md {
+	// Add new metadata (convert map to lists, sorted by key)
+	for _, key := range sortedKeys(md) {
 		_ = p.metadataKeys.Append(key)
-		_ = p.metadataVals.Append(val)
+		_ = p.metadataVals.Append(md[key])
 	}
 
 	p.metadataList.Refresh()
@@ -427,14 +649,24 @@ func (p *ResponsePanel) SetResponseTrailers(md map[string]string) {
 	_ = p.trailerKeys.Set([]string{})
 	_ = p.trailerVals.Set([]string{})
 
-	for key, val := range md {
+	for _, key := range sortedKeys(md) {
 		_ = p.trailerKeys.Append(key)
-		_ = p.trailerVals.Append(val)
+		_ = p.trailerVals.Append(md[key])
 	}
 
 	p.trailerList.Refresh()
 }
 
+// sortedKeys returns the keys of md in sorted order.
+func sortedKeys(md map[string]string) []string {
+	keys := make([]string, 0, len(md))
+	for key := range md {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // ClearResponse clears all response data (for keyboard shortcut)
 func (p *ResponsePanel) ClearResponse() {
 	_ = p.state.TextData.Set("")
@@ -442,6 +674,7 @@ func (p *ResponsePanel) ClearResponse() {
 	_ = p.state.Duration.Set("")
 	_ = p.state.Size.Set("")
 	p.ClearResponseMetadata()
+	p.SetTiming("")
 
 	// If in streaming mode, also clear streaming widget
 	if p.isStreaming {
@@ -459,12 +692,31 @@ func (p *ResponsePanel) ClearResponseMetadata() {
 	p.trailerList.Refresh()
 }
 
+// SetTiming shows where the time of the last call went in the Timing
+// section; "" hides the section.
+func (p *ResponsePanel) SetTiming(text string) {
+	p.timingText.SetText(text)
+	if text == "" {
+		p.timingSection.Hide()
+	} else {
+		p.timingSection.Show()
+	}
+}
+
+// Timing returns the text of the Timing section, "" when hidden.
+func (p *ResponsePanel) Timing() string {
+	if !p.timingSection.Visible() {
+		return ""
+	}
+	return p.timingText.Text
+}
+
 // CreateRenderer implements fyne.Widget.
 func (p *ResponsePanel) CreateRenderer() fyne.WidgetRenderer {
-	// Main layout with loading bar at bottom
+	// Main layout with the timing section and loading bar at bottom
 	content := container.NewBorder(
-		nil,
-		p.loadingBar,
+		container.NewBorder(nil, nil, nil, p.filterNote, p.filterEntry),
+		container.NewVBox(p.timingSection, p.loadingBar),
 		nil,
 		nil,
 		p.contentContainer,
@@ -0,0 +1,179 @@
+package response
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPanel(t *testing.T) *ResponsePanel {
+	t.Helper()
+	app := test.NewApp()
+	t.Cleanup(app.Quit)
+
+	w := test.NewWindow(nil)
+	t.Cleanup(w.Close)
+	p := NewResponsePanel(model.NewResponseState(), w)
+	w.SetContent(p)
+	return p
+}
+
+// diffText joins the rendered diff segments.
+func diffText(p *ResponsePanel) string {
+	var s string
+	for _, seg := range p.diffText.Segments {
+		s += seg.(*widget.TextSegment).Text
+	}
+	return s
+}
+
+func TestResponsePanel_PinAndDiff(t *testing.T) {
+	p := newTestPanel(t)
+
+	// Nothing to pin yet
+	p.PinResponse()
+	_, ok := p.Pinned()
+	assert.False(t, ok)
+
+	_ = p.state.TextData.Set(`{"id": "1", "count": 2}`)
+	test.Tap(p.pinBtn)
+	pinned, ok := p.Pinned()
+	require.True(t, ok)
+	assert.Equal(t, `{"id": "1", "count": 2}`, pinned)
+	assert.Equal(t, "Unpin", p.pinBtn.Text)
+	assert.Len(t, p.responseTabs.Items, 4)
+
+	// The next response is diffed against the pin, ignoring key order
+	_ = p.state.TextData.Set(`{"count": 3, "id": "1"}`)
+	assert.Equal(t, p.diffTab, p.responseTabs.Selected())
+	assert.Equal(t, "@@ -1,4 +1,4 @@\n {\n-  \"count\": 2,\n+  \"count\": 3,\n   \"id\": \"1\"\n }\n", diffText(p))
+
+	_ = p.state.TextData.Set(`{"count": 2, "id": "1"}`)
+	assert.Contains(t, diffText(p), "No differences")
+
+	test.Tap(p.pinBtn)
+	_, ok = p.Pinned()
+	assert.False(t, ok)
+	assert.Equal(t, "Pin", p.pinBtn.Text)
+	assert.Len(t, p.responseTabs.Items, 3)
+}
+
+func TestResponsePanel_Tree(t *testing.T) {
+	p := newTestPanel(t)
+	_ = p.state.TextData.Set(`{"items": [{"id": "a"}], "next": null}`)
+
+	// Built when the tab is first shown
+	assert.Nil(t, p.treeModel)
+	p.responseTabs.Select(p.treeTab)
+	require.NotNil(t, p.treeModel)
+	assert.True(t, p.tree.Visible())
+
+	// Expand down to the leaf, as the tree widget does when rendering
+	p.treeModel.ChildIDs("")
+	p.treeModel.ChildIDs("$.items")
+	p.treeModel.ChildIDs("$.items[0]")
+	p.tree.Select("$.items[0].id")
+	assert.Equal(t, "Copied $.items[0].id", p.treeHint.Text)
+
+	// Later responses rebuild it while it is showing
+	_ = p.state.TextData.Set(`not json`)
+	assert.Nil(t, p.treeModel)
+	assert.False(t, p.tree.Visible())
+	assert.Equal(t, "Response is not valid JSON", p.treeMessage.Text)
+
+	_ = p.state.TextData.Set(`[1, 2]`)
+	require.NotNil(t, p.treeModel)
+	assert.Equal(t, []string{"$[0]", "$[1]"}, p.treeModel.ChildIDs(""))
+}
+
+func TestResponsePanel_UnknownEnums(t *testing.T) {
+	p := newTestPanel(t)
+	text := `{"status": 999, "items": [{"status": "STATUS_OK"}]}`
+	_ = p.state.TextData.Set(text)
+	p.SetUnknownEnums(text, []string{"$.status"})
+	p.responseTabs.Select(p.treeTab)
+	p.treeModel.ChildIDs("")
+
+	// nodeText renders a tree node as the tree does
+	nodeText := func(id string) string {
+		var s string
+		for _, seg := range treeNodeSegments(p.treeModel.Node(id), p.isUnknownEnum(id)) {
+			s += seg.(*widget.TextSegment).Text
+		}
+		return s
+	}
+	assert.Equal(t, "status: 999 (unknown)", nodeText("$.status"))
+	assert.Equal(t, "items: [1]", nodeText("$.items"))
+
+	// The marks belong to that response only
+	_ = p.state.TextData.Set(`{"status": 999}`)
+	p.treeModel.ChildIDs("")
+	assert.Equal(t, "status: 999", nodeText("$.status"))
+}
+
+func TestResponsePanel_UseAsRequest(t *testing.T) {
+	p := newTestPanel(t)
+	_ = p.state.TextData.Set(`{"id": "1"}`)
+	assert.False(t, p.useBtn.Visible(), "hidden without a callback")
+
+	var used string
+	p.SetOnUseAsRequest(func(json string) { used = json })
+	_ = p.state.TextData.Set(`{"id": "2"}`)
+	require.True(t, p.useBtn.Visible())
+	test.Tap(p.useBtn)
+	assert.Equal(t, `{"id": "2"}`, used)
+
+	_ = p.state.TextData.Set("")
+	assert.False(t, p.useBtn.Visible())
+}
+
+func TestResponsePanel_Timing(t *testing.T) {
+	p := newTestPanel(t)
+	assert.Empty(t, p.Timing(), "hidden until a call reports its timing")
+
+	p.SetTiming("Total 52ms")
+	assert.Equal(t, "Total 52ms", p.Timing())
+	assert.True(t, p.timingSection.Visible())
+
+	p.ClearResponse()
+	assert.Empty(t, p.Timing())
+	assert.False(t, p.timingSection.Visible())
+}
+
+func TestResponsePanel_Filter(t *testing.T) {
+	p := newTestPanel(t)
+	_ = p.state.TextData.Set(`{"items": [{"id": "a"}, {"id": "b"}], "next": "c"}`)
+
+	p.filterEntry.SetText("items[*].id")
+	assert.Equal(t, "{\n  \"$.items[0].id\": \"a\",\n  \"$.items[1].id\": \"b\"\n}", p.shownText)
+	assert.Equal(t, "2 matches", p.filterNote.Text)
+
+	// Applies to later responses too
+	_ = p.state.TextData.Set(`{"items": [{"id": "z"}]}`)
+	assert.Equal(t, "{\n  \"$.items[0].id\": \"z\"\n}", p.shownText)
+	assert.Equal(t, "1 match", p.filterNote.Text)
+
+	p.filterEntry.SetText("next")
+	assert.Empty(t, p.shownText)
+	assert.Equal(t, "No match", p.filterNote.Text)
+
+	p.filterEntry.SetText("items[")
+	assert.Equal(t, `{"items": [{"id": "z"}]}`, p.shownText, "an invalid path shows everything")
+	assert.Contains(t, p.filterNote.Text, "Invalid path")
+	assert.Nil(t, p.streamingWidget.filter)
+
+	// Stream messages are filtered one by one
+	p.filterEntry.SetText("id")
+	require.NotNil(t, p.streamingWidget.filter)
+	assert.Equal(t, `"a"`, p.streamingWidget.filter(`{"id": "a"}`))
+	assert.Equal(t, "(no match)", p.streamingWidget.filter(`{"name": "a"}`))
+
+	p.filterEntry.SetText("")
+	assert.Equal(t, `{"items": [{"id": "z"}]}`, p.shownText, "clearing restores the full body")
+	assert.False(t, p.filterNote.Visible())
+	assert.Nil(t, p.streamingWidget.filter)
+}
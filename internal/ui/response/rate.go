@@ -0,0 +1,78 @@
+package response
+
+import "time"
+
+// rateWindow is how far back the streaming message rate looks.
+const rateWindow = 5 * time.Second
+
+// rateBuckets is how many slices rateWindow is counted in.
+const rateBuckets = 10
+
+// rateMeter reports a moving average event rate over a time window. Events
+// are counted in fixed-width buckets kept in a ring, so memory stays constant
+// however fast events arrive.
+type rateMeter struct {
+	bucket time.Duration
+	counts []int
+	slots  []int64   // bucket number each ring slot is counting
+	first  time.Time // first event since the last reset
+}
+
+// newRateMeter creates a meter averaging over window, counted in n buckets.
+func newRateMeter(window time.Duration, n int) *rateMeter {
+	m := &rateMeter{
+		bucket: window / time.Duration(n),
+		counts: make([]int, n),
+		slots:  make([]int64, n),
+	}
+	m.Reset()
+	return m
+}
+
+// Add records an event at now.
+func (m *rateMeter) Add(now time.Time) {
+	if m.first.IsZero() {
+		m.first = now
+	}
+	b := m.bucketOf(now)
+	i := int(b % int64(len(m.slots)))
+	if m.slots[i] != b {
+		m.slots[i] = b
+		m.counts[i] = 0
+	}
+	m.counts[i]++
+}
+
+// Rate returns events per second over the window ending at now. Before a
+// full window has passed since the first event, the average is over the time
+// so far, but never less than a second.
+func (m *rateMeter) Rate(now time.Time) float64 {
+	if m.first.IsZero() {
+		return 0
+	}
+	cur := m.bucketOf(now)
+	oldest := cur - int64(len(m.slots))
+	total := 0
+	for i, b := range m.slots {
+		if b > oldest && b <= cur {
+			total += m.counts[i]
+		}
+	}
+
+	span := min(now.Sub(m.first), m.bucket*time.Duration(len(m.slots)))
+	span = max(span, time.Second)
+	return float64(total) / span.Seconds()
+}
+
+// Reset forgets all events.
+func (m *rateMeter) Reset() {
+	for i := range m.slots {
+		m.slots[i] = -1
+		m.counts[i] = 0
+	}
+	m.first = time.Time{}
+}
+
+func (m *rateMeter) bucketOf(t time.Time) int64 {
+	return t.UnixNano() / int64(m.bucket)
+}
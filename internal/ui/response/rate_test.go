@@ -0,0 +1,50 @@
+package response
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateMeter(t *testing.T) {
+	base := time.Unix(1000, 0)
+	m := newRateMeter(5*time.Second, 10)
+	assert.Zero(t, m.Rate(base))
+
+	// 10 msg/s for 5 seconds
+	for i := range 50 {
+		m.Add(base.Add(time.Duration(i) * 100 * time.Millisecond))
+	}
+	assert.InDelta(t, 10, m.Rate(base.Add(4900*time.Millisecond)), 0.5)
+
+	// Two seconds of silence: only the last 2.5 seconds of messages are
+	// still in the window
+	assert.InDelta(t, 5, m.Rate(base.Add(7*time.Second)), 0.1)
+
+	// The rate decays to zero once the window has passed
+	assert.Zero(t, m.Rate(base.Add(20*time.Second)))
+
+	// A later burst replaces the stale buckets
+	later := base.Add(time.Minute)
+	for range 30 {
+		m.Add(later)
+	}
+	assert.InDelta(t, 6, m.Rate(later.Add(5*time.Second-time.Millisecond)), 0.1)
+}
+
+func TestRateMeter_StartOfStream(t *testing.T) {
+	base := time.Unix(1000, 0)
+	m := newRateMeter(5*time.Second, 10)
+
+	// Averaged over the time so far, not the whole window
+	for i := range 20 {
+		m.Add(base.Add(time.Duration(i) * 100 * time.Millisecond))
+	}
+	assert.InDelta(t, 10, m.Rate(base.Add(2*time.Second)), 0.1)
+
+	// But never over less than a second
+	m.Reset()
+	m.Add(base)
+	assert.InDelta(t, 1, m.Rate(base), 0.001)
+}
@@ -1,33 +1,72 @@
 package response
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/export"
+	"github.com/shhac/grotto/internal/ui/components"
 	"github.com/shhac/grotto/internal/ui/streamconst"
 )
 
-// StreamingMessagesWidget displays streaming RPC messages as they arrive.
+// maxPreviewBytes caps how much of a message a list row highlights; the
+// row is a single line, so the rest would be cut off anyway.
+const maxPreviewBytes = 300
+
+// listRefreshInterval is the least time between list refreshes: each lays
+// out every visible row again, so a fast stream's messages are shown in
+// batches.
+const listRefreshInterval = 50 * time.Millisecond
+
+// StreamingMessagesWidget displays streaming RPC messages as they arrive,
+// one line per message. Rows are formatted only while visible, and tapping
+// one shows the whole message pretty-printed.
 type StreamingMessagesWidget struct {
 	widget.BaseWidget
 
 	window        fyne.Window
-	messages      binding.UntypedList // []string (JSON messages)
+	raw           []string                   // JSON messages as received
+	messages      binding.ExternalStringList // bound to raw, reloaded by refreshList
+	records       []export.StreamMessage     // kept messages with their receive times
 	messageList   *widget.List
 	autoScroll    bool
-	totalReceived int // total messages received (including evicted)
+	lastRefresh   time.Time
+	refreshQueued bool // a refreshList is due after listRefreshInterval
+	totalReceived int  // total messages received (including evicted)
+	maxMessages   int  // messages kept; older ones are dropped
+
+	// Rewrites messages for display, e.g. to a path's fragments; nil shows
+	// them as received
+	filter func(string) string
+
+	// Message rate over the last rateWindow
+	rate      *rateMeter
+	now       func() time.Time
+	afterFunc func(time.Duration, func()) // runs a func on the main thread later
+	tickerMu  sync.Mutex
+	stopTicks chan struct{} // closes to stop live counter updates
 
 	// Status section
-	statusLabel     *widget.Label
-	stopBtn         *widget.Button
-	copyAllBtn      *widget.Button
-	autoScrollCheck *widget.Check
-	statusBox       *fyne.Container
+	statusLabel   *widget.Label
+	counterLabel  *widget.Label
+	stopBtn       *widget.Button
+	copyAllBtn    *widget.Button
+	exportBtn     *widget.Button
+	autoScrollBtn *widget.Button
+	headers       *components.StreamHeaders
+	statusBox     *fyne.Container
 
 	// Main container
 	container *fyne.Container
@@ -39,10 +78,18 @@ type StreamingMessagesWidget struct {
 // NewStreamingMessagesWidget creates a new streaming messages widget.
 func NewStreamingMessagesWidget(window fyne.Window) *StreamingMessagesWidget {
 	w := &StreamingMessagesWidget{
-		window:     window,
-		messages:   binding.NewUntypedList(),
-		autoScroll: true,
+		window:      window,
+		autoScroll:  true,
+		maxMessages: streamconst.MaxStreamMessages,
+		rate:        newRateMeter(rateWindow, rateBuckets),
+		now:         time.Now,
+		afterFunc: func(d time.Duration, f func()) {
+			time.AfterFunc(d, func() { fyne.Do(f) })
+		},
 	}
+	// An external list only notifies the rows whose message changed, where
+	// a plain one notifies every row on each append
+	w.messages = binding.BindStringList(&w.raw)
 	w.ExtendBaseWidget(w)
 	w.initializeComponents()
 	return w
@@ -53,6 +100,10 @@ func (w *StreamingMessagesWidget) initializeComponents() {
 	// Status label
 	w.statusLabel = widget.NewLabel("Ready")
 
+	// Live message count and rate
+	w.counterLabel = widget.NewLabel("")
+	w.counterLabel.Importance = widget.LowImportance
+
 	// Stop button (styled as danger to make it prominent)
 	w.stopBtn = widget.NewButton("Abort Stream", func() {
 		if w.onStop != nil {
@@ -64,54 +115,54 @@ func (w *StreamingMessagesWidget) initializeComponents() {
 
 	// Copy all button
 	w.copyAllBtn = widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
-		all, err := w.messages.Get()
-		if err != nil || len(all) == 0 {
-			return
-		}
-		var msgs []string
-		for _, item := range all {
-			if s, ok := item.(string); ok {
-				msgs = append(msgs, s)
-			}
+		if len(w.raw) > 0 {
+			w.window.Clipboard().SetContent(strings.Join(w.raw, "\n"))
 		}
-		w.window.Clipboard().SetContent(strings.Join(msgs, "\n"))
 	})
 
-	// Auto-scroll toggle
-	w.autoScrollCheck = widget.NewCheck("Auto-scroll", func(checked bool) {
-		w.autoScroll = checked
-		if checked && w.messageList != nil {
-			w.messageList.ScrollToBottom()
+	// Export received messages as NDJSON
+	w.exportBtn = widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), func() {
+		if len(w.records) > 0 {
+			components.ShowNDJSONExport(w.window, "stream.ndjson", w.Snapshot())
 		}
 	})
-	w.autoScrollCheck.SetChecked(true)
+
+	// Auto-scroll pause/resume toggle
+	w.autoScrollBtn = widget.NewButtonWithIcon("Pause scroll", theme.MediaPauseIcon(), func() {
+		w.SetAutoScroll(!w.autoScroll)
+	})
+
+	// Response headers, shown once the server sends them
+	w.headers = components.NewStreamHeaders()
 
 	// Status box (label + controls)
 	w.statusBox = container.NewBorder(
 		nil,
 		nil,
 		nil,
-		container.NewHBox(w.autoScrollCheck, w.copyAllBtn, w.stopBtn),
-		w.statusLabel,
+		container.NewHBox(w.autoScrollBtn, w.copyAllBtn, w.exportBtn, w.stopBtn),
+		container.NewVBox(w.statusLabel, w.counterLabel, w.headers),
 	)
 
-	// Message list with syntax-highlighted JSON
+	// Message list, one syntax-highlighted line per message
 	w.messageList = widget.NewListWithData(
 		w.messages,
 		func() fyne.CanvasObject {
-			rt := widget.NewRichText()
-			rt.Wrapping = fyne.TextWrapBreak
-			return rt
+			return widget.NewRichText()
 		},
 		func(item binding.DataItem, obj fyne.CanvasObject) {
 			rt := obj.(*widget.RichText)
 			if strItem, ok := item.(binding.String); ok {
 				val, _ := strItem.Get()
-				rt.Segments = HighlightJSON(val)
+				rt.Segments = components.HighlightJSON(previewJSON(w.show(val)))
 				rt.Refresh()
 			}
 		},
 	)
+	w.messageList.OnSelected = func(id widget.ListItemID) {
+		w.messageList.Unselect(id)
+		w.showMessage(id)
+	}
 
 	// Header for streaming section
 	header := widget.NewLabel("Streaming Messages")
@@ -128,50 +179,229 @@ func (w *StreamingMessagesWidget) initializeComponents() {
 		nil,
 		nil,
 		nil,
-		w.messageList,
+		components.EditorThemed(w.messageList),
 	)
 }
 
 // AddMessage appends a message to the list (thread-safe).
 // This should be called from a goroutine using fyne.Do() wrapper.
+//
+// At most the configured number of messages are kept: the oldest are dropped
+// in batches before the new one is appended, so the list never holds more.
 func (w *StreamingMessagesWidget) AddMessage(jsonStr string) {
-	w.messages.Append(jsonStr)
+	if len(w.raw) >= w.maxMessages {
+		drop := len(w.raw) - w.maxMessages + evictionBatch(w.maxMessages)
+		w.raw = slices.Clone(w.raw[min(drop, len(w.raw)):]) // Lets the dropped ones go
+		w.records = slices.Delete(w.records, 0, min(drop, len(w.records)))
+	}
+	now := w.now()
+	w.raw = append(w.raw, jsonStr)
+	w.records = append(w.records, export.StreamMessage{Direction: export.DirectionRecv, Time: now, JSON: jsonStr})
 	w.totalReceived++
+	w.rate.Add(now)
 
-	// Evict oldest messages if over cap
-	count := w.messages.Length()
-	if count > streamconst.MaxStreamMessages {
-		all, err := w.messages.Get()
-		if err == nil && len(all) > streamconst.MaxStreamMessages {
-			_ = w.messages.Set(all[streamconst.EvictionBatch:])
-			count = w.messages.Length()
-		}
+	if w.statusLabel.Text != "Streaming..." {
+		w.statusLabel.SetText("Streaming...")
 	}
+	w.queueRefresh()
+}
 
-	// Update status
-	if w.totalReceived > count {
-		w.statusLabel.SetText(fmt.Sprintf("Streaming... (showing %d of %d messages)", count, w.totalReceived))
-	} else {
-		w.statusLabel.SetText(fmt.Sprintf("Streaming... (%d messages)", count))
+// queueRefresh refreshes the list now, or once listRefreshInterval has
+// passed since the last refresh.
+func (w *StreamingMessagesWidget) queueRefresh() {
+	if w.refreshQueued {
+		return
+	}
+	wait := listRefreshInterval - w.now().Sub(w.lastRefresh)
+	if wait <= 0 {
+		w.refreshList()
+		return
 	}
+	w.refreshQueued = true
+	w.afterFunc(wait, w.refreshList)
+}
 
-	// Auto-scroll to latest message if enabled
+// refreshList shows the messages added since the last refresh and the
+// counter, scrolling to the latest if auto-scroll is on.
+func (w *StreamingMessagesWidget) refreshList() {
+	w.refreshQueued = false
+	w.lastRefresh = w.now()
+	_ = w.messages.Reload()
+	w.updateCounter()
 	if w.autoScroll {
 		w.messageList.ScrollToBottom()
 	}
 }
 
+// show applies the filter, if any, to a message.
+func (w *StreamingMessagesWidget) show(msg string) string {
+	if w.filter != nil {
+		return w.filter(msg)
+	}
+	return msg
+}
+
+// showMessage shows the kept message id in full, pretty-printed.
+func (w *StreamingMessagesWidget) showMessage(id widget.ListItemID) {
+	if id < 0 || id >= len(w.raw) {
+		return
+	}
+	msg := w.raw[id]
+	text := indentJSON(w.show(msg))
+	view := components.NewJSONView()
+	view.SetText(text)
+	copyBtn := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		w.window.Clipboard().SetContent(text)
+	})
+
+	// Number messages from the start of the stream, dropped ones included
+	number := w.totalReceived - len(w.raw) + id + 1
+	d := dialog.NewCustom(fmt.Sprintf("Message %d", number), "Close",
+		container.NewBorder(nil, container.NewHBox(copyBtn), nil, nil, components.EditorThemed(view)), w.window)
+	d.Resize(fyne.NewSize(600, 500))
+	d.Show()
+}
+
+// previewJSON returns msg on one line for a list row: compacted when it is
+// JSON, and cut after maxPreviewBytes.
+func previewJSON(msg string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(msg)); err == nil {
+		msg = buf.String()
+	} else {
+		msg = strings.Join(strings.Fields(msg), " ")
+	}
+	if len(msg) > maxPreviewBytes {
+		cut := maxPreviewBytes
+		for cut > 0 && !utf8.RuneStart(msg[cut]) {
+			cut--
+		}
+		msg = msg[:cut] + "…"
+	}
+	return msg
+}
+
+// indentJSON pretty-prints msg, or returns it as is when it isn't JSON.
+func indentJSON(msg string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(msg), "", "  "); err == nil {
+		return buf.String()
+	}
+	return msg
+}
+
+// SetFilter sets how messages are shown, e.g. narrowed to the fragments
+// a path selects, for those kept and those still to come (nil shows them as
+// received). Copying and exporting use the messages as received.
+func (w *StreamingMessagesWidget) SetFilter(filter func(string) string) {
+	w.filter = filter
+	w.messageList.Refresh()
+}
+
+// Snapshot returns a copy of the kept messages with their receive times, for
+// exporting while the stream is still running.
+func (w *StreamingMessagesWidget) Snapshot() []export.StreamMessage {
+	return slices.Clone(w.records)
+}
+
+// SetMaxMessages sets how many messages are kept (default
+// streamconst.MaxStreamMessages). Values below 1 are ignored. Messages over a
+// lowered cap are dropped with the next message.
+func (w *StreamingMessagesWidget) SetMaxMessages(n int) {
+	if n > 0 {
+		w.maxMessages = n
+	}
+}
+
+// SetAutoScroll pauses or resumes scrolling to each new message.
+func (w *StreamingMessagesWidget) SetAutoScroll(on bool) {
+	w.autoScroll = on
+	if on {
+		w.autoScrollBtn.SetText("Pause scroll")
+		w.autoScrollBtn.SetIcon(theme.MediaPauseIcon())
+		w.messageList.ScrollToBottom()
+	} else {
+		w.autoScrollBtn.SetText("Resume scroll")
+		w.autoScrollBtn.SetIcon(theme.MediaPlayIcon())
+	}
+}
+
+// updateCounter shows the message count, the recent rate and how many of the
+// oldest messages were dropped.
+func (w *StreamingMessagesWidget) updateCounter() {
+	w.counterLabel.SetText(streamCounterText(w.totalReceived, len(w.raw), w.rate.Rate(w.now())))
+}
+
+// streamCounterText formats the live counter, e.g.
+// "1200 messages · 40.0 msg/s · oldest 200 dropped".
+func streamCounterText(total, kept int, rate float64) string {
+	text := fmt.Sprintf("%d messages · %.1f msg/s", total, rate)
+	if dropped := total - kept; dropped > 0 {
+		text += fmt.Sprintf(" · oldest %d dropped", dropped)
+	}
+	return text
+}
+
+// evictionBatch is how many messages beyond the overflow are dropped at once,
+// so a full list isn't rewritten for every new message.
+func evictionBatch(maxMessages int) int {
+	return max(1, min(streamconst.EvictionBatch, maxMessages/5))
+}
+
+// startTicker refreshes the counter every second until stopTicker, so the rate
+// decays while no messages arrive.
+func (w *StreamingMessagesWidget) startTicker() {
+	w.stopTicker()
+	stop := make(chan struct{})
+	w.tickerMu.Lock()
+	w.stopTicks = stop
+	w.tickerMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fyne.Do(w.updateCounter)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopTicker stops the live counter updates, if running.
+func (w *StreamingMessagesWidget) stopTicker() {
+	w.tickerMu.Lock()
+	defer w.tickerMu.Unlock()
+	if w.stopTicks != nil {
+		close(w.stopTicks)
+		w.stopTicks = nil
+	}
+}
+
 // SetStatus updates the status label with a custom message.
 func (w *StreamingMessagesWidget) SetStatus(status string) {
 	w.statusLabel.SetText(status)
 }
 
-// Clear removes all messages from the list.
+// SetHeaders shows the stream's response headers, which may arrive long
+// before its first message.
+func (w *StreamingMessagesWidget) SetHeaders(md map[string]string) {
+	w.headers.Set(md)
+}
+
+// Clear removes all messages and headers from the list.
 func (w *StreamingMessagesWidget) Clear() {
-	_ = w.messages.Set([]interface{}{})
+	w.raw = nil
+	w.records = nil
 	w.totalReceived = 0
-	w.messageList.Refresh()
+	w.rate.Reset()
+	w.refreshList()
 	w.statusLabel.SetText("Ready")
+	w.counterLabel.SetText("")
+	w.headers.Reset()
 }
 
 // SetOnStop sets the callback for the stop button.
@@ -179,14 +409,18 @@ func (w *StreamingMessagesWidget) SetOnStop(fn func()) {
 	w.onStop = fn
 }
 
-// EnableStopButton enables the stop button (call when streaming starts).
+// EnableStopButton enables the stop button and starts live counter updates
+// (call when streaming starts).
 func (w *StreamingMessagesWidget) EnableStopButton() {
 	w.stopBtn.Enable()
+	w.startTicker()
 }
 
-// DisableStopButton disables the stop button (call when streaming completes).
+// DisableStopButton disables the stop button and stops live counter updates
+// (call when streaming completes).
 func (w *StreamingMessagesWidget) DisableStopButton() {
 	w.stopBtn.Disable()
+	w.stopTicker()
 }
 
 // CreateRenderer implements fyne.Widget.
@@ -0,0 +1,317 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/export"
+	"github.com/shhac/grotto/internal/ui/components"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStreamingWidget(t *testing.T) *StreamingMessagesWidget {
+	t.Helper()
+	app := test.NewApp()
+	t.Cleanup(app.Quit)
+
+	w := test.NewWindow(nil)
+	t.Cleanup(w.Close)
+	s := NewStreamingMessagesWidget(w)
+	// A throttled refresh is left to the test, which flushes it with
+	// refreshList: a real timer would refresh the list from another
+	// goroutine while the test changes it
+	s.afterFunc = func(time.Duration, func()) {}
+	w.SetContent(s)
+	return s
+}
+
+func TestStreamingMessages_RetentionNeverExceedsCap(t *testing.T) {
+	for _, maxMessages := range []int{1, 3, 10, 50} {
+		t.Run(fmt.Sprint(maxMessages), func(t *testing.T) {
+			s := newTestStreamingWidget(t)
+			s.SetMaxMessages(maxMessages)
+
+			total := 3*maxMessages + 7
+			for i := range total {
+				s.AddMessage(fmt.Sprintf(`{"n": %d}`, i))
+				require.LessOrEqual(t, len(s.raw), maxMessages, "after message %d", i)
+			}
+			s.refreshList()
+
+			// The newest message is always kept, the oldest are dropped
+			all, err := s.messages.Get()
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf(`{"n": %d}`, total-1), all[len(all)-1])
+			assert.Equal(t, total, s.totalReceived)
+
+			// The export snapshot drops the same messages
+			snap := s.Snapshot()
+			require.Len(t, snap, len(all))
+			assert.Equal(t, all[0], snap[0].JSON)
+			assert.Equal(t, all[len(all)-1], snap[len(snap)-1].JSON)
+			assert.Contains(t, s.counterLabel.Text, fmt.Sprintf("oldest %d dropped", total-len(all)))
+		})
+	}
+}
+
+func TestStreamingMessages_LoweredCap(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	for i := range 50 {
+		s.AddMessage(fmt.Sprint(i))
+	}
+	s.SetMaxMessages(10)
+	s.AddMessage("50")
+	assert.LessOrEqual(t, len(s.raw), 10)
+
+	s.SetMaxMessages(0)
+	assert.Equal(t, 10, s.maxMessages, "a cap below 1 is ignored")
+}
+
+func TestStreamingMessages_Counter(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	for range 10 {
+		s.AddMessage(`{}`)
+		now = now.Add(200 * time.Millisecond)
+	}
+	assert.Equal(t, "10 messages · 5.6 msg/s", s.counterLabel.Text)
+
+	s.Clear()
+	assert.Empty(t, s.counterLabel.Text)
+	assert.Zero(t, s.messages.Length())
+}
+
+func TestStreamingMessages_Snapshot(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	s.AddMessage(`{"n": 1}`)
+	snap := s.Snapshot()
+
+	// Later messages don't change an earlier snapshot
+	now = now.Add(time.Second)
+	s.AddMessage(`{"n": 2}`)
+	require.Len(t, snap, 1)
+	assert.Equal(t, export.StreamMessage{Direction: export.DirectionRecv, Time: time.Unix(1000, 0), JSON: `{"n": 1}`}, snap[0])
+	assert.Len(t, s.Snapshot(), 2)
+
+	s.Clear()
+	assert.Empty(t, s.Snapshot())
+}
+
+func TestStreamingMessages_AutoScrollToggle(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	assert.True(t, s.autoScroll)
+
+	test.Tap(s.autoScrollBtn)
+	assert.False(t, s.autoScroll)
+	assert.Equal(t, "Resume scroll", s.autoScrollBtn.Text)
+
+	test.Tap(s.autoScrollBtn)
+	assert.True(t, s.autoScroll)
+	assert.Equal(t, "Pause scroll", s.autoScrollBtn.Text)
+}
+
+func TestStreamingMessages_HeadersBeforeMessages(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	assert.False(t, s.headers.Visible(), "hidden until the server sends headers")
+
+	// Headers show while no message has arrived yet
+	s.SetHeaders(map[string]string{"session-id": "s-42", "content-type": "application/grpc"})
+	assert.True(t, s.headers.Visible())
+	assert.Equal(t, "content-type: application/grpc\nsession-id: s-42", s.headers.Text())
+	assert.Zero(t, s.messages.Length())
+
+	s.Clear()
+	assert.False(t, s.headers.Visible(), "a new stream starts without headers")
+}
+
+func TestStreamCounterText(t *testing.T) {
+	assert.Equal(t, "3 messages · 1.5 msg/s", streamCounterText(3, 3, 1.5))
+	assert.Equal(t, "1200 messages · 40.0 msg/s · oldest 200 dropped", streamCounterText(1200, 1000, 40))
+}
+
+// fakeClock stands in for a stream widget's clock and timer: the time moves
+// only when advanced, running a queued func once it is due.
+type fakeClock struct {
+	now  time.Time
+	due  time.Time
+	next func()
+}
+
+func newFakeClock(s *StreamingMessagesWidget) *fakeClock {
+	c := &fakeClock{now: time.Unix(1000, 0)}
+	s.now = func() time.Time { return c.now }
+	s.afterFunc = func(d time.Duration, f func()) {
+		c.due, c.next = c.now.Add(d), f
+	}
+	return c
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+	if c.next != nil && !c.now.Before(c.due) {
+		f := c.next
+		c.next = nil
+		f()
+	}
+}
+
+func TestStreamingMessages_RefreshesInBatches(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	clock := newFakeClock(s)
+
+	// The first message shows at once, the next wait for the interval
+	for range 5 {
+		s.AddMessage(`{}`)
+		clock.advance(5 * time.Millisecond)
+	}
+	assert.Equal(t, 1, s.messages.Length())
+	assert.Len(t, s.raw, 5)
+	assert.Contains(t, s.counterLabel.Text, "1 messages")
+
+	clock.advance(listRefreshInterval)
+	assert.Equal(t, 5, s.messages.Length())
+	assert.Contains(t, s.counterLabel.Text, "5 messages")
+}
+
+// richTexts collects the rich texts rendered within obj.
+func richTexts(obj fyne.CanvasObject) []*widget.RichText {
+	switch o := obj.(type) {
+	case *widget.RichText:
+		return []*widget.RichText{o}
+	case *fyne.Container:
+		var texts []*widget.RichText
+		for _, child := range o.Objects {
+			texts = append(texts, richTexts(child)...)
+		}
+		return texts
+	case fyne.Widget:
+		var texts []*widget.RichText
+		for _, child := range test.WidgetRenderer(o).Objects() {
+			texts = append(texts, richTexts(child)...)
+		}
+		return texts
+	}
+	return nil
+}
+
+func TestStreamingMessages_ScrollUnderLoad(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	clock := newFakeClock(s)
+	s.SetMaxMessages(5000)
+
+	// Scroll around while 10k messages arrive at 1000 per second
+	for i := range 10_000 {
+		s.AddMessage(benchMessage(i))
+		clock.advance(time.Millisecond)
+		switch {
+		case i == 3000:
+			s.SetAutoScroll(false)
+		case i > 3000 && i < 7000 && i%250 == 0:
+			s.messageList.ScrollTo(i % s.messages.Length())
+		case i == 7000:
+			s.SetAutoScroll(true)
+		}
+		// Only the visible rows are ever built
+		if i%500 == 0 {
+			require.Less(t, len(richTexts(s.messageList)), 50, "after message %d", i)
+		}
+	}
+	clock.advance(listRefreshInterval)
+
+	assert.LessOrEqual(t, s.messages.Length(), 5000)
+	assert.Contains(t, s.counterLabel.Text, "10000 messages")
+
+	// Scrolled to the bottom, the newest message is on a single line
+	rows := richTexts(s.messageList)
+	var shown []string
+	for _, rt := range rows {
+		shown = append(shown, rt.String())
+	}
+	assert.Contains(t, shown, benchMessage(9999))
+}
+
+func TestStreamingMessages_ShowMessage(t *testing.T) {
+	s := newTestStreamingWidget(t)
+	s.AddMessage(`{"a": 1}`)
+	s.AddMessage(`{"b": {"c": [1, 2]}}`)
+	s.refreshList()
+
+	s.messageList.Select(1)
+	overlay := s.window.Canvas().Overlays().Top()
+	require.NotNil(t, overlay, "the message is shown in a dialog")
+	var view *components.JSONView
+	var find func(obj fyne.CanvasObject)
+	find = func(obj fyne.CanvasObject) {
+		switch o := obj.(type) {
+		case *components.JSONView:
+			view = o
+		case *fyne.Container:
+			for _, child := range o.Objects {
+				find(child)
+			}
+		case fyne.Widget:
+			for _, child := range test.WidgetRenderer(o).Objects() {
+				find(child)
+			}
+		}
+	}
+	find(overlay)
+	require.NotNil(t, view)
+	assert.Equal(t, "{\n  \"b\": {\n    \"c\": [\n      1,\n      2\n    ]\n  }\n}", view.Text())
+}
+
+func TestPreviewJSON(t *testing.T) {
+	assert.Equal(t, `{"a":1,"b":[1,2]}`, previewJSON("{\n  \"a\": 1,\n  \"b\": [1, 2]\n}"))
+	assert.Equal(t, "(no match)", previewJSON("(no match)"))
+	assert.Equal(t, `"x" "y"`, previewJSON("\"x\"\n\"y\""), "filter fragments on one line")
+
+	long := `{"s":"` + strings.Repeat("é", maxPreviewBytes) + `"}`
+	got := previewJSON(long)
+	assert.True(t, strings.HasSuffix(got, "…"))
+	assert.LessOrEqual(t, len(got), maxPreviewBytes+len("…"))
+	assert.True(t, utf8.ValidString(got), "not cut inside a character")
+}
+
+// benchMessage is a typical stream message as protojson writes it.
+func benchMessage(i int) string {
+	return fmt.Sprintf(`{"id":"evt-%d","kind":"UPDATE","payload":{"user":{"name":"Ada","email":"ada@example.com"},"tags":["a","b","c"],"score":%d.5},"createdAt":"2026-03-01T12:00:00Z"}`, i, i)
+}
+
+// BenchmarkStreamingMessages_Append10k appends 10k messages arriving at 1000
+// per second. Before rows were refreshed in batches, bound to an external
+// list and kept to one line, 200 appends took minutes.
+func BenchmarkStreamingMessages_Append10k(b *testing.B) {
+	app := test.NewApp()
+	defer app.Quit()
+	w := test.NewWindow(nil)
+	defer w.Close()
+	w.Resize(fyne.NewSize(800, 600))
+
+	msgs := make([]string, 10_000)
+	for i := range msgs {
+		msgs[i] = benchMessage(i)
+	}
+	b.ResetTimer()
+	for range b.N {
+		s := NewStreamingMessagesWidget(w)
+		clock := newFakeClock(s)
+		s.SetMaxMessages(len(msgs))
+		w.SetContent(s)
+		for _, m := range msgs {
+			s.AddMessage(m)
+			clock.advance(time.Millisecond)
+		}
+	}
+}
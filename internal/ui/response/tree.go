@@ -0,0 +1,183 @@
+package response
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const treeHintText = "Click a value to copy its JSON path"
+
+// treeKindColorName colors tree values like the raw JSON highlighting does.
+var treeKindColorName = map[JSONNodeKind]fyne.ThemeColorName{
+	JSONObject: theme.ColorNameDisabled,
+	JSONArray:  theme.ColorNameDisabled,
+	JSONString: theme.ColorNameSuccess,
+	JSONNumber: theme.ColorNameWarning,
+	JSONBool:   theme.ColorNameError,
+	JSONNull:   theme.ColorNameDisabled,
+	JSONMore:   theme.ColorNamePrimary,
+}
+
+// initTree creates the Tree tab's widgets and returns its content.
+func (p *ResponsePanel) initTree() fyne.CanvasObject {
+	p.tree = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			if p.treeModel == nil {
+				return nil
+			}
+			return p.treeModel.ChildIDs(id)
+		},
+		func(id widget.TreeNodeID) bool {
+			return p.treeModel != nil && p.treeModel.IsBranch(id)
+		},
+		func(bool) fyne.CanvasObject {
+			return widget.NewRichText(&widget.TextSegment{Text: "key: value"})
+		},
+		func(id widget.TreeNodeID, _ bool, obj fyne.CanvasObject) {
+			rich := obj.(*widget.RichText)
+			rich.Segments = nil
+			if p.treeModel != nil {
+				if n := p.treeModel.Node(id); n != nil {
+					rich.Segments = treeNodeSegments(n, p.isUnknownEnum(n.ID))
+				}
+			}
+			rich.Refresh()
+		},
+	)
+	p.tree.OnSelected = p.treeNodeSelected
+
+	p.treeMessage = widget.NewLabel("")
+	p.treeMessage.Alignment = fyne.TextAlignCenter
+	p.treeMessage.Hide()
+
+	p.treeHint = widget.NewLabel(treeHintText)
+	p.treeHint.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, p.treeHint, nil, nil, container.NewStack(p.tree, p.treeMessage))
+}
+
+// setTreeText marks the tree out of date with the response text. It is only
+// rebuilt while the Tree tab is showing, so large responses aren't parsed
+// twice when nobody looks at them.
+func (p *ResponsePanel) setTreeText(text string) {
+	p.treeText = text
+	p.treeStale = true
+	if p.responseTabs != nil && p.responseTabs.Selected() == p.treeTab {
+		p.rebuildTree()
+	}
+}
+
+// rebuildTree parses the response text into a new tree model. Branches open
+// at the same paths stay open.
+func (p *ResponsePanel) rebuildTree() {
+	p.treeStale = false
+	p.treeHint.SetText(treeHintText)
+	p.tree.UnselectAll()
+
+	model, err := NewJSONTree(p.treeText)
+	switch {
+	case p.treeText == "":
+		p.treeModel = nil
+		p.treeMessage.SetText("Send a request to see the response")
+	case err != nil:
+		p.treeModel = nil
+		p.treeMessage.SetText("Response is not valid JSON")
+	default:
+		p.treeModel = model
+	}
+
+	if p.treeModel == nil {
+		p.tree.Hide()
+		p.treeMessage.Show()
+	} else {
+		p.treeMessage.Hide()
+		p.tree.Show()
+	}
+	p.tree.Refresh()
+}
+
+// treeNodeSelected copies a leaf's JSON path, toggles a branch or loads the
+// next page of an array.
+func (p *ResponsePanel) treeNodeSelected(id widget.TreeNodeID) {
+	defer p.tree.Unselect(id)
+	if p.treeModel == nil {
+		return
+	}
+	n := p.treeModel.Node(id)
+	switch {
+	case n == nil:
+	case n.Kind == JSONMore:
+		p.treeModel.ShowMore(id)
+		p.tree.Refresh()
+	case n.IsBranch():
+		p.tree.ToggleBranch(id)
+	default:
+		p.window.Clipboard().SetContent(n.ID)
+		p.treeHint.SetText("Copied " + n.ID)
+	}
+}
+
+// SetUnknownEnums marks the values at paths in the response text, as enum
+// numbers its descriptor does not declare. The marks are dropped once the
+// response changes.
+func (p *ResponsePanel) SetUnknownEnums(text string, paths []string) {
+	p.unknownEnums = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		p.unknownEnums[path] = true
+	}
+	p.unknownEnumsText = text
+	p.tree.Refresh()
+}
+
+// isUnknownEnum reports whether the tree node id is an undeclared enum
+// number.
+func (p *ResponsePanel) isUnknownEnum(id string) bool {
+	return p.unknownEnumsText == p.treeText && p.unknownEnums[id]
+}
+
+// treeNodeSegments renders a node as its key followed by a type-colored
+// value, or a summary of its size for objects and arrays. Undeclared enum
+// numbers are followed by "(unknown)".
+func treeNodeSegments(n *JSONNode, unknownEnum bool) []widget.RichTextSegment {
+	style := widget.RichTextStyle{
+		ColorName: treeKindColorName[n.Kind],
+		Inline:    true,
+		SizeName:  theme.SizeNameText,
+		TextStyle: fyne.TextStyle{Monospace: true},
+	}
+
+	var value string
+	switch n.Kind {
+	case JSONObject:
+		value = fmt.Sprintf("{%d}", n.Len)
+	case JSONArray:
+		value = fmt.Sprintf("[%d]", n.Len)
+	case JSONMore:
+		style.TextStyle = fyne.TextStyle{Italic: true}
+		return []widget.RichTextSegment{&widget.TextSegment{Style: style, Text: "… " + n.Value}}
+	default:
+		value = n.Value
+	}
+
+	keyStyle := widget.RichTextStyle{
+		ColorName: theme.ColorNamePrimary,
+		Inline:    true,
+		SizeName:  theme.SizeNameText,
+		TextStyle: fyne.TextStyle{Monospace: true},
+	}
+	segments := []widget.RichTextSegment{
+		&widget.TextSegment{Style: keyStyle, Text: n.Key + ": "},
+		&widget.TextSegment{Style: style, Text: value},
+	}
+	if unknownEnum {
+		noteStyle := style
+		noteStyle.ColorName = theme.ColorNameDisabled
+		noteStyle.TextStyle.Italic = true
+		segments = append(segments, &widget.TextSegment{Style: noteStyle, Text: " (unknown)"})
+	}
+	return segments
+}
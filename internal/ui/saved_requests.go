@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// selectedMethodFullName returns the selected method as "pkg.Service/Method",
+// or "" when no method is selected.
+func (w *MainWindow) selectedMethodFullName() string {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
+	if serviceName == "" || methodName == "" {
+		return ""
+	}
+	return serviceName + "/" + methodName
+}
+
+// loadSavedRequests fills the request panel's saved requests dropdown with
+// the library entries for method.
+func (w *MainWindow) loadSavedRequests(method string) {
+	saved, err := w.app.Storage().GetSavedRequests(method)
+	if err != nil {
+		w.logger.Warn("failed to load saved requests",
+			slog.String("method", method),
+			slog.Any("error", err))
+		saved = nil
+	}
+	w.requestPanel.SetSavedRequests(saved)
+}
+
+// handleSaveRequest asks for a name and stores the request in the library
+// of the selected method. The selected saved request's name is suggested;
+// reusing an existing name overwrites it after confirmation.
+func (w *MainWindow) handleSaveRequest(body string, metadata map[string]string) {
+	method := w.selectedMethodFullName()
+	if method == "" {
+		dialog.ShowError(fmt.Errorf("no method selected"), w.window)
+		return
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g. create user – happy path")
+	nameEntry.SetText(w.requestPanel.SelectedSavedRequest())
+	nameEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("name cannot be empty")
+		}
+		return nil
+	}
+
+	dialog.ShowForm("Save Request", "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", nameEntry)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			name := strings.TrimSpace(nameEntry.Text)
+			save := func() {
+				w.storeSavedRequest(domain.SavedRequest{
+					Name:    name,
+					Request: domain.Request{Method: method, Body: body, Metadata: metadata},
+				})
+			}
+			if !w.requestPanel.HasSavedRequest(name) {
+				save()
+				return
+			}
+			dialog.ShowConfirm("Overwrite Saved Request",
+				fmt.Sprintf("A saved request named %q already exists for %s. Overwrite it?", name, method),
+				func(confirmed bool) {
+					if confirmed {
+						save()
+					}
+				},
+				w.window,
+			)
+		},
+		w.window,
+	)
+}
+
+// storeSavedRequest writes req to storage, reloads the dropdown and selects
+// the stored request.
+func (w *MainWindow) storeSavedRequest(req domain.SavedRequest) {
+	if err := w.app.Storage().SaveRequest(req); err != nil {
+		w.logger.Error("failed to save request", slog.Any("error", err))
+		dialog.ShowError(fmt.Errorf("failed to save request: %w", err), w.window)
+		return
+	}
+	w.loadSavedRequests(req.Request.Method)
+	w.requestPanel.SelectSavedRequest(req.Name)
+}
+
+// handleDeleteSavedRequest removes a request from the selected method's
+// library after confirmation.
+func (w *MainWindow) handleDeleteSavedRequest(name string) {
+	method := w.selectedMethodFullName()
+	if method == "" {
+		return
+	}
+
+	dialog.ShowConfirm("Delete Saved Request",
+		fmt.Sprintf("Delete the saved request %q?", name),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := w.app.Storage().DeleteSavedRequest(method, name); err != nil {
+				w.logger.Error("failed to delete saved request", slog.Any("error", err))
+				dialog.ShowError(fmt.Errorf("failed to delete saved request: %w", err), w.window)
+				return
+			}
+			w.loadSavedRequests(method)
+		},
+		w.window,
+	)
+}
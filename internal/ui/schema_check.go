@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"log/slog"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/ui/settings"
+)
+
+// startSchemaWatcher starts checking the connected server's services in
+// the background, announcing changes so the schema can be refreshed.
+// Descriptor sets and .proto sources are local and not watched.
+func (w *MainWindow) startSchemaWatcher() {
+	w.stopSchemaWatcher()
+
+	if w.connectionBar.GetDescriptorSet() != "" || len(w.connectionBar.GetProtoImportPaths()) > 0 {
+		return
+	}
+	seconds := w.fyneApp.Preferences().IntWithFallback(settings.PrefSchemaCheckInterval, settings.DefaultSchemaCheckInterval)
+	conn := w.app.ConnManager().Channel()
+	if seconds <= 0 || conn == nil {
+		return
+	}
+
+	watcher := grpc.NewSchemaWatcher(conn, time.Duration(seconds)*time.Second, w.logger)
+	w.schemaMu.Lock()
+	w.schemaWatcher = watcher
+	w.schemaMu.Unlock()
+	watcher.Start(w.handleSchemaListing)
+}
+
+// stopSchemaWatcher stops background schema checks and withdraws any
+// pending schema change notice.
+func (w *MainWindow) stopSchemaWatcher() {
+	w.schemaMu.Lock()
+	watcher := w.schemaWatcher
+	w.schemaWatcher = nil
+	w.schemaMu.Unlock()
+
+	if watcher != nil {
+		watcher.Stop()
+	}
+	fyne.Do(w.schemaNotice.Hide)
+}
+
+// handleSchemaListing compares a background listing of the server's
+// services with the ones shown and offers a refresh when they differ.
+func (w *MainWindow) handleSchemaListing(services []domain.Service) {
+	diff := domain.DiffServices(w.currentServices(), services)
+	if diff.IsEmpty() {
+		return
+	}
+	w.logger.Info("server schema changed", slog.String("changes", diff.Summary()))
+	fyne.Do(func() {
+		w.schemaNotice.Announce("Schema changed: "+diff.Summary()+" — Refresh?", "Refresh", w.handleRefreshSchema)
+	})
+}
+
+// currentServices returns the services shown in the service browser.
+func (w *MainWindow) currentServices() []domain.Service {
+	items, _ := w.state.Services.Get()
+	services := make([]domain.Service, 0, len(items))
+	for _, item := range items {
+		if svc, ok := item.(domain.Service); ok {
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
+// reconcileSelection keeps the selected method across a schema refresh
+// described by diff. A method whose types changed is selected again so its
+// request form is rebuilt, keeping the request body and metadata; a method
+// that is gone is deselected. Must be called on the main thread.
+func (w *MainWindow) reconcileSelection(diff domain.SchemaDiff, services []domain.Service) {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
+
+	switch diff.Selection(serviceName, methodName) {
+	case domain.SelectionChanged:
+		for _, svc := range services {
+			if svc.FullName != serviceName {
+				continue
+			}
+			for _, m := range svc.Methods {
+				if m.Name != methodName {
+					continue
+				}
+				body, _ := w.state.Request.TextData.Get()
+				metadata := w.requestPanel.GetMetadata()
+				w.handleMethodSelect(svc, m)
+				if body != "" {
+					_ = w.state.Request.TextData.Set(body)
+					w.requestPanel.SyncTextToForm()
+				}
+				if len(metadata) > 0 {
+					w.requestPanel.SetMetadata(metadata)
+				}
+				return
+			}
+		}
+	case domain.SelectionRemoved:
+		w.clearSelection()
+	}
+}
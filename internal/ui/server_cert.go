@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showServerCertificates shows the certificate chain the server presented
+// in the connection's TLS handshake, flagging certificates that have
+// expired or expire soon.
+func (w *MainWindow) showServerCertificates() {
+	certs, ok := w.app.ConnManager().ServerCertificates()
+	if !ok {
+		dialog.ShowError(errors.New("no TLS handshake has completed on this connection yet"), w.window)
+		return
+	}
+
+	summary := fmt.Sprintf("%s, %s", certs.Version, certs.CipherSuite)
+	if certs.ServerName != "" {
+		summary += "\nServer name: " + certs.ServerName
+	}
+	if !certs.Verified {
+		summary += "\nNot verified: certificate verification is turned off"
+	}
+	content := container.NewVBox(widget.NewLabel(summary))
+
+	for i, cert := range certs.Chain {
+		content.Add(widget.NewSeparator())
+		title := "Server certificate"
+		if i > 0 {
+			title = fmt.Sprintf("Issuer certificate %d", i)
+		}
+		if cert.IsCA {
+			title += " (CA)"
+		}
+		content.Add(widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+
+		if cert.Expired || cert.ExpiresSoon {
+			warning := widget.NewLabel("This certificate " + cert.Expiry)
+			warning.Importance = widget.WarningImportance
+			if cert.Expired {
+				warning.Importance = widget.DangerImportance
+			}
+			content.Add(warning)
+		}
+
+		form := widget.NewForm()
+		for _, f := range cert.Fields() {
+			value := widget.NewLabel(f.Value)
+			value.Wrapping = fyne.TextWrapBreak
+			value.Selectable = true
+			if f.Name == "SHA-256" {
+				value.TextStyle.Monospace = true
+			}
+			form.Append(f.Name, value)
+		}
+		content.Add(form)
+	}
+
+	d := dialog.NewCustom("Server Certificate", "Close", container.NewVScroll(content), w.window)
+	d.Resize(fyne.NewSize(700, 550))
+	d.Show()
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// serviceFeedInterval is the least time between two updates of the
+// service list while a listing is in progress, so a listing resolving many
+// services quickly redraws the tree about ten times a second rather than
+// once per service.
+const serviceFeedInterval = 100 * time.Millisecond
+
+// serviceFeed fills the bound service list as a listing reports services,
+// sorted by name, batching what arrives within serviceFeedInterval into
+// one update. Its first update replaces whatever the list held.
+type serviceFeed struct {
+	list     binding.UntypedList
+	onUpdate func() // Called after each update, e.g. to refresh the tree
+	interval time.Duration
+
+	// mu is held while the list is updated, so nothing is written after
+	// stop returns
+	mu       sync.Mutex
+	services []domain.Service // Everything reported, sorted by name
+	timer    *time.Timer      // Pending update, nil if none
+	updated  bool
+	stopped  bool
+}
+
+// newServiceFeed creates a feed into list. onUpdate may be nil.
+func newServiceFeed(list binding.UntypedList, onUpdate func()) *serviceFeed {
+	return &serviceFeed{list: list, onUpdate: onUpdate, interval: serviceFeedInterval}
+}
+
+// add inserts a service in order and schedules an update. It is safe to
+// call from any goroutine, and does nothing once the feed is stopped.
+func (f *serviceFeed) add(svc domain.Service) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stopped {
+		return
+	}
+	i, _ := slices.BinarySearchFunc(f.services, svc.FullName, func(s domain.Service, name string) int {
+		return strings.Compare(s.FullName, name)
+	})
+	f.services = slices.Insert(f.services, i, svc)
+	if f.timer == nil {
+		f.timer = time.AfterFunc(f.interval, f.update)
+	}
+}
+
+// update writes the services reported so far to the list.
+func (f *serviceFeed) update() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.timer = nil
+	if f.stopped {
+		return
+	}
+	_ = f.list.Set(serviceItems(f.services))
+	f.updated = true
+	if f.onUpdate != nil {
+		f.onUpdate()
+	}
+}
+
+// stop ends the feed, dropping any pending update since the listing's
+// result replaces it, and reports whether the list was updated at all.
+func (f *serviceFeed) stop() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	return f.updated
+}
+
+// serviceItems returns services sorted by name, as the items of the bound
+// service list.
+func serviceItems(services []domain.Service) []interface{} {
+	sorted := slices.SortedStableFunc(slices.Values(services), func(a, b domain.Service) int {
+		return strings.Compare(a.FullName, b.FullName)
+	})
+	items := make([]interface{}, len(sorted))
+	for i, svc := range sorted {
+		items[i] = svc
+	}
+	return items
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedNames returns the names of the services in the bound list.
+func feedNames(t *testing.T, list binding.UntypedList) []string {
+	t.Helper()
+	items, err := list.Get()
+	require.NoError(t, err)
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.(domain.Service).FullName
+	}
+	return names
+}
+
+func TestServiceFeed(t *testing.T) {
+	list := binding.NewUntypedList()
+	require.NoError(t, list.Set([]interface{}{domain.Service{FullName: "old.Service"}}))
+	var updates atomic.Int32
+	feed := newServiceFeed(list, func() { updates.Add(1) })
+	feed.interval = 20 * time.Millisecond
+
+	// A burst is one update, in order, replacing the old server's services
+	feed.add(domain.Service{FullName: "b.Service"})
+	feed.add(domain.Service{FullName: "c.Service", Error: "failed to resolve"})
+	feed.add(domain.Service{FullName: "a.Service"})
+	assert.Equal(t, []string{"old.Service"}, feedNames(t, list), "nothing before the interval")
+	require.Eventually(t, func() bool { return updates.Load() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"a.Service", "b.Service", "c.Service"}, feedNames(t, list))
+
+	feed.add(domain.Service{FullName: "ab.Service"})
+	require.Eventually(t, func() bool { return updates.Load() == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"a.Service", "ab.Service", "b.Service", "c.Service"}, feedNames(t, list))
+
+	// Pending updates are dropped once stopped
+	feed.add(domain.Service{FullName: "z.Service"})
+	assert.True(t, feed.stop(), "the list was updated")
+	feed.add(domain.Service{FullName: "y.Service"})
+	time.Sleep(3 * feed.interval)
+	assert.Equal(t, int32(2), updates.Load())
+	assert.NotContains(t, feedNames(t, list), "z.Service")
+}
+
+func TestServiceFeed_StoppedBeforeUpdate(t *testing.T) {
+	list := binding.NewUntypedList()
+	feed := newServiceFeed(list, nil)
+	feed.add(domain.Service{FullName: "a.Service"})
+	assert.False(t, feed.stop(), "a listing faster than the interval never touches the list")
+	assert.Empty(t, feedNames(t, list))
+}
+
+func TestServiceItems(t *testing.T) {
+	services := []domain.Service{{FullName: "b.Service"}, {FullName: "a.Service"}}
+	items := serviceItems(services)
+	assert.Equal(t, []interface{}{services[1], services[0]}, items)
+	assert.Equal(t, "b.Service", services[0].FullName, "the caller's slice is left alone")
+}
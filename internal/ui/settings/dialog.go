@@ -1,19 +1,88 @@
 package settings
 
 import (
+	"strings"
+
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
 	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/ui/components"
 )
 
-// ShowTLSDialog displays a dialog for configuring TLS settings
-func ShowTLSDialog(window fyne.Window, currentSettings domain.TLSSettings, onSave func(domain.TLSSettings)) {
+// ShowConnectionDialog displays a dialog for configuring connection settings
+// (display name, TLS, transport, compression, authority and user-agent, proxy, default auth and metadata, message size limits, keepalive, and retries). Only those fields of the
+// connection are edited; other fields are passed through unchanged.
+func ShowConnectionDialog(window fyne.Window, current domain.Connection, onSave func(domain.Connection)) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Optional, e.g. staging")
+	nameEntry.SetText(current.Name)
+	nameForm := widget.NewForm(widget.NewFormItem("Name", nameEntry))
+
 	tlsWidget := NewTLSConfig(window)
-	tlsWidget.SetConfig(currentSettings)
+	tlsWidget.SetConfig(current.TLS)
+
+	transportWidget := NewTransportConfig()
+	transportWidget.SetTransport(current.Transport)
+	transportWidget.SetCompression(current.Compression)
+	transportWidget.SetAuthority(current.Authority, current.UserAgent)
+
+	proxyWidget := NewProxyConfig()
+	proxyWidget.SetProxy(current.Proxy)
+
+	authEditor := components.NewAuthEditor()
+	authEditor.SetAuth(current.Auth)
+	authNote := widget.NewLabel("Calls on this connection start with this auth. " +
+		"Change it per call in the request's metadata tab. Saved with the connection in plain text.")
+	authNote.Wrapping = fyne.TextWrapWord
+	authNote.Importance = widget.LowImportance
+	authTab := container.NewVBox(
+		widget.NewLabel("Default Auth"),
+		widget.NewSeparator(),
+		authEditor,
+		authNote,
+	)
+
+	metadataWidget := NewMetadataConfig()
+	metadataWidget.SetMetadata(current.DefaultMetadata)
+
+	limitsWidget := NewLimitsConfig()
+	limitsWidget.SetLimits(current.MaxRecvMsgSize, current.MaxSendMsgSize)
+
+	keepaliveWidget := NewKeepaliveConfig()
+	keepaliveWidget.SetParams(current.KeepaliveParams)
+
+	retryWidget := NewRetryConfig()
+	retryWidget.SetPolicy(current.Retry)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("TLS", tlsWidget.container),
+		container.NewTabItem("Transport", transportWidget.container),
+		container.NewTabItem("Proxy", proxyWidget.container),
+		container.NewTabItem("Auth", authTab),
+		container.NewTabItem("Metadata", metadataWidget.container),
+		container.NewTabItem("Limits", limitsWidget.container),
+		container.NewTabItem("Keepalive", keepaliveWidget.container),
+		container.NewTabItem("Retry", retryWidget.container),
+	)
 
-	dlg := dialog.NewCustomConfirm("TLS Configuration", "Save", "Cancel", tlsWidget.container, func(save bool) {
+	content := container.NewBorder(nameForm, nil, nil, nil, tabs)
+	dlg := dialog.NewCustomConfirm("Connection Settings", "Save", "Cancel", content, func(save bool) {
 		if save {
-			onSave(tlsWidget.GetConfig())
+			updated := current
+			updated.Name = strings.TrimSpace(nameEntry.Text)
+			updated.TLS = tlsWidget.GetConfig()
+			updated.Transport = transportWidget.GetTransport()
+			updated.Compression = transportWidget.GetCompression()
+			updated.Authority, updated.UserAgent = transportWidget.GetAuthority()
+			updated.Proxy = proxyWidget.GetProxy()
+			updated.Auth = authEditor.Auth()
+			updated.DefaultMetadata = metadataWidget.GetMetadata()
+			updated.MaxRecvMsgSize, updated.MaxSendMsgSize = limitsWidget.GetLimits()
+			updated.KeepaliveParams = keepaliveWidget.GetParams()
+			updated.Retry = retryWidget.GetPolicy()
+			onSave(updated)
 		}
 	}, window)
 	dlg.Resize(fyne.NewSize(600, 500))
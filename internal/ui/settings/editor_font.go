@@ -0,0 +1,94 @@
+package settings
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/ui/components"
+)
+
+// editorFontSizes are the editor text size choices, smallest first. Medium
+// is the theme's own size.
+var editorFontSizes = []struct {
+	name string
+	size float32
+}{
+	{"Small", 12},
+	{"Medium", 0},
+	{"Large", 16},
+	{"Extra Large", 19},
+}
+
+// LoadEditorFont reads the saved editor font.
+func LoadEditorFont(prefs fyne.Preferences) components.EditorFont {
+	return components.EditorFont{
+		Monospace: prefs.BoolWithFallback(PrefEditorMonospace, false),
+		Size:      float32(prefs.FloatWithFallback(PrefEditorFontSize, 0)),
+	}
+}
+
+// SaveEditorFont saves the editor font.
+func SaveEditorFont(prefs fyne.Preferences, f components.EditorFont) {
+	prefs.SetBool(PrefEditorMonospace, f.Monospace)
+	prefs.SetFloat(PrefEditorFontSize, float64(f.Size))
+}
+
+// EditorFontControls choose the editor font: monospace or not, and a text
+// size.
+type EditorFontControls struct {
+	Monospace *widget.Check
+	Size      *widget.Select
+}
+
+// NewEditorFontControls creates controls showing f. onChange, if set, is
+// called with the font chosen whenever either control changes.
+func NewEditorFontControls(f components.EditorFont, onChange func(components.EditorFont)) *EditorFontControls {
+	c := &EditorFontControls{
+		Monospace: widget.NewCheck("Monospace", nil),
+		Size:      widget.NewSelect(editorFontSizeNames(), nil),
+	}
+	c.Monospace.SetChecked(f.Monospace)
+	c.Size.SetSelected(editorFontSizeName(f.Size))
+	if onChange != nil {
+		c.Monospace.OnChanged = func(bool) { onChange(c.Font()) }
+		c.Size.OnChanged = func(string) { onChange(c.Font()) }
+	}
+	return c
+}
+
+// Font returns the font chosen.
+func (c *EditorFontControls) Font() components.EditorFont {
+	f := components.EditorFont{Monospace: c.Monospace.Checked}
+	for _, s := range editorFontSizes {
+		if s.name == c.Size.Selected {
+			f.Size = s.size
+		}
+	}
+	return f
+}
+
+// FormItems returns the controls as labelled form rows.
+func (c *EditorFontControls) FormItems() []*widget.FormItem {
+	return []*widget.FormItem{
+		widget.NewFormItem("Editor Font", c.Monospace),
+		widget.NewFormItem("Editor Text Size", c.Size),
+	}
+}
+
+func editorFontSizeNames() []string {
+	names := make([]string, len(editorFontSizes))
+	for i, s := range editorFontSizes {
+		names[i] = s.name
+	}
+	return names
+}
+
+// editorFontSizeName names size, or Medium for a size that is not one of
+// the choices.
+func editorFontSizeName(size float32) string {
+	for _, s := range editorFontSizes {
+		if s.size == size {
+			return s.name
+		}
+	}
+	return "Medium"
+}
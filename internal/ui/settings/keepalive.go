@@ -0,0 +1,139 @@
+package settings
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// KeepaliveConfig is a widget for setting a connection's keepalive pings,
+// entered in seconds. An empty interval sends no pings.
+type KeepaliveConfig struct {
+	widget.BaseWidget
+
+	time                *widget.Entry
+	timeout             *widget.Entry
+	permitWithoutStream *widget.Check
+	warning             *widget.Label
+
+	// UI container
+	container *fyne.Container
+}
+
+// NewKeepaliveConfig creates a new keepalive widget
+func NewKeepaliveConfig() *KeepaliveConfig {
+	k := &KeepaliveConfig{}
+
+	k.time = widget.NewEntry()
+	k.time.SetPlaceHolder("Off (default)")
+	k.time.Validator = validateSeconds
+	k.time.OnChanged = func(string) { k.updateWarning() }
+
+	k.timeout = widget.NewEntry()
+	k.timeout.SetPlaceHolder("20 (default)")
+	k.timeout.Validator = validateSeconds
+
+	k.permitWithoutStream = widget.NewCheck("Ping when no call is in progress", nil)
+
+	note := widget.NewLabel("Pings keep an idle connection open through NATs and load balancers " +
+		"that drop quiet connections. Servers limit how often they accept pings (by default once " +
+		"every 5 minutes, and never without a call in progress) and close connections that ping " +
+		"more often with GOAWAY too_many_pings, failing the calls in progress. " +
+		"Match the server's policy. Native gRPC only.")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.LowImportance
+
+	k.warning = widget.NewLabel("gRPC pings at most every 10 seconds, and few servers accept " +
+		"pings that often. Expect the connection to be closed.")
+	k.warning.Wrapping = fyne.TextWrapWord
+	k.warning.Importance = widget.WarningImportance
+	k.warning.Hide()
+
+	k.container = container.NewVBox(
+		widget.NewLabel("Keepalive"),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Ping interval (s)", k.time),
+			widget.NewFormItem("Ping timeout (s)", k.timeout),
+		),
+		k.permitWithoutStream,
+		k.warning,
+		note,
+	)
+
+	k.ExtendBaseWidget(k)
+	return k
+}
+
+// GetParams returns the entered keepalive parameters (zero durations for
+// empty or invalid fields)
+func (k *KeepaliveConfig) GetParams() domain.KeepaliveParams {
+	return domain.KeepaliveParams{
+		Time:                parseSeconds(k.time.Text),
+		Timeout:             parseSeconds(k.timeout.Text),
+		PermitWithoutStream: k.permitWithoutStream.Checked,
+	}
+}
+
+// SetParams fills the fields from p (zero durations leave a field empty)
+func (k *KeepaliveConfig) SetParams(p domain.KeepaliveParams) {
+	k.time.SetText(formatSeconds(p.Time))
+	k.timeout.SetText(formatSeconds(p.Timeout))
+	k.permitWithoutStream.SetChecked(p.PermitWithoutStream)
+	k.updateWarning()
+}
+
+// CreateRenderer implements the fyne.Widget interface
+func (k *KeepaliveConfig) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(k.container)
+}
+
+// updateWarning shows the warning while the interval is under the
+// minimum gRPC allows.
+func (k *KeepaliveConfig) updateWarning() {
+	if d := parseSeconds(k.time.Text); d > 0 && d < domain.MinKeepaliveTime {
+		k.warning.Show()
+	} else {
+		k.warning.Hide()
+	}
+}
+
+// validateSeconds accepts an empty field or a positive number of seconds.
+func validateSeconds(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil || secs <= 0 {
+		return errors.New("enter a positive number of seconds")
+	}
+	return nil
+}
+
+// parseSeconds converts a number of seconds to a duration, returning 0 for
+// an empty or invalid value.
+func parseSeconds(s string) time.Duration {
+	if validateSeconds(s) != nil {
+		return 0
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// formatSeconds formats d in seconds, or "" for 0.
+func formatSeconds(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
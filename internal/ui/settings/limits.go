@@ -0,0 +1,112 @@
+package settings
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const bytesPerMB = 1 << 20
+
+// LimitsConfig is a widget for setting the maximum message sizes of a
+// connection, entered in megabytes. Empty fields keep gRPC's defaults.
+type LimitsConfig struct {
+	widget.BaseWidget
+
+	maxRecv *widget.Entry
+	maxSend *widget.Entry
+
+	// UI container
+	container *fyne.Container
+}
+
+// NewLimitsConfig creates a new message size limits widget
+func NewLimitsConfig() *LimitsConfig {
+	l := &LimitsConfig{}
+
+	l.maxRecv = widget.NewEntry()
+	l.maxRecv.SetPlaceHolder("4 (default)")
+	l.maxRecv.Validator = validateMegabytes
+
+	l.maxSend = widget.NewEntry()
+	l.maxSend.SetPlaceHolder("Unlimited (default)")
+	l.maxSend.Validator = validateMegabytes
+
+	note := widget.NewLabel("Messages over the limit fail with RESOURCE_EXHAUSTED. " +
+		"The server enforces its own limits too. Native gRPC only.")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.LowImportance
+
+	l.container = container.NewVBox(
+		widget.NewLabel("Message Size Limits"),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Max receive (MB)", l.maxRecv),
+			widget.NewFormItem("Max send (MB)", l.maxSend),
+		),
+		note,
+	)
+
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+// GetLimits returns the entered limits in bytes (0 for default or invalid)
+func (l *LimitsConfig) GetLimits() (maxRecv, maxSend int) {
+	return parseMegabytes(l.maxRecv.Text), parseMegabytes(l.maxSend.Text)
+}
+
+// SetLimits fills the fields from limits in bytes (0 leaves a field empty)
+func (l *LimitsConfig) SetLimits(maxRecv, maxSend int) {
+	l.maxRecv.SetText(formatMegabytes(maxRecv))
+	l.maxSend.SetText(formatMegabytes(maxSend))
+}
+
+// CreateRenderer implements the fyne.Widget interface
+func (l *LimitsConfig) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(l.container)
+}
+
+// validateMegabytes accepts an empty field or a positive size in MB.
+func validateMegabytes(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	mb, err := strconv.ParseFloat(s, 64)
+	if err != nil || mb <= 0 {
+		return errors.New("enter a positive number of megabytes")
+	}
+	if mb*bytesPerMB > float64(maxInt32) {
+		return errors.New("must be under 2048 MB")
+	}
+	return nil
+}
+
+// maxInt32 is the largest message gRPC can frame.
+const maxInt32 = 1<<31 - 1
+
+// parseMegabytes converts a size in MB to bytes, returning 0 for an empty
+// or invalid value.
+func parseMegabytes(s string) int {
+	if validateMegabytes(s) != nil {
+		return 0
+	}
+	mb, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return int(mb * bytesPerMB)
+}
+
+// formatMegabytes formats a size in bytes as MB, or "" for 0.
+func formatMegabytes(bytes int) string {
+	if bytes <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(bytes)/bytesPerMB, 'f', -1, 64)
+}
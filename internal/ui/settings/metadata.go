@@ -0,0 +1,113 @@
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// MetadataConfig is a widget for the metadata sent with every call on a
+// connection, entered one "name: value" header per line.
+type MetadataConfig struct {
+	widget.BaseWidget
+
+	entry *widget.Entry
+
+	// UI container
+	container *fyne.Container
+}
+
+// NewMetadataConfig creates a new default metadata widget
+func NewMetadataConfig() *MetadataConfig {
+	m := &MetadataConfig{}
+
+	m.entry = widget.NewMultiLineEntry()
+	m.entry.SetPlaceHolder("x-tenant-id: acme\nx-api-key: ...")
+	m.entry.SetMinRowsVisible(8)
+	m.entry.Validator = func(s string) error {
+		_, err := parseMetadataLines(s)
+		return err
+	}
+
+	note := widget.NewLabel("Sent with every call on this connection. A header of the same name " +
+		"in the request's metadata tab replaces the default. Values of -bin headers are base64. " +
+		"Saved with the connection in plain text.")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.LowImportance
+
+	m.container = container.NewVBox(
+		widget.NewLabel("Default Metadata"),
+		widget.NewSeparator(),
+		m.entry,
+		note,
+	)
+
+	m.ExtendBaseWidget(m)
+	return m
+}
+
+// GetMetadata returns the entered headers, skipping lines that are not
+// valid (nil when there are none)
+func (m *MetadataConfig) GetMetadata() map[string]string {
+	md, _ := parseMetadataLines(m.entry.Text)
+	return md
+}
+
+// SetMetadata fills the entry with one line per header, sorted by name
+func (m *MetadataConfig) SetMetadata(md map[string]string) {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = k + ": " + md[k]
+	}
+	m.entry.SetText(strings.Join(lines, "\n"))
+}
+
+// CreateRenderer implements the fyne.Widget interface
+func (m *MetadataConfig) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(m.container)
+}
+
+// parseMetadataLines reads "name: value" lines, ignoring blank ones. It
+// returns the valid headers along with the first problem found.
+func parseMetadataLines(text string) (map[string]string, error) {
+	var (
+		md       map[string]string
+		firstErr error
+	)
+	for n, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("line %d: expected name: value", n+1)
+			}
+			continue
+		}
+		if grpc.IsBinaryHeader(key) {
+			if _, err := grpc.DecodeBinaryHeader(value); err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("line %d: %w", n+1, err)
+				}
+				continue
+			}
+		}
+		if md == nil {
+			md = make(map[string]string)
+		}
+		md[key] = value
+	}
+	return md, firstErr
+}
@@ -1,23 +1,102 @@
 package settings
 
 import (
+	"log/slog"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/logging"
+	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/ui/components"
+	"github.com/shhac/grotto/internal/ui/form"
+	"github.com/shhac/grotto/internal/ui/streamconst"
 )
 
 // Preference keys (must match the constants used elsewhere in the app).
 const (
 	PrefRequestTimeout = "requestTimeout"
 	PrefTheme          = "appTheme"
+	PrefFormMaxDepth   = "formMaxDepth"
+	PrefHealthInterval = "healthCheckInterval"
+	// PrefSchemaCheckInterval is how often, in seconds, the server's
+	// services are listed again to notice schema changes.
+	PrefSchemaCheckInterval = "schemaCheckInterval"
+	// PrefAutosaveInterval is how often, in seconds, the workspace is
+	// saved to the autosave slot when it has changed.
+	PrefAutosaveInterval = "autosaveInterval"
+	PrefStreamMessages   = "streamMessageCap"
+	// PrefBodyCarry is what happens to a request body when a method taking
+	// another message type is selected ("clear", "keep" or "convert").
+	PrefBodyCarry = "bodyOnMethodSwitch"
+	// PrefHistoryCredentials keeps authorization credentials in history
+	// entries instead of redacting them.
+	PrefHistoryCredentials = "historyIncludeCredentials"
+	// PrefGroupByPackage nests services under their packages in the
+	// service browser.
+	PrefGroupByPackage = "browserGroupByPackage"
+	// PrefLogLevel is the log level name ("debug", "info", "warn", "error").
+	PrefLogLevel = "logLevel"
+	// PrefLogFile is an extra log file written alongside the default one.
+	PrefLogFile = "logFile"
+	// PrefAudit records every call to the audit file.
+	PrefAudit = "auditEnabled"
+	// PrefAuditPayloads records the messages of audited calls too.
+	PrefAuditPayloads = "auditPayloads"
+	// PrefEditorMonospace shows the JSON editors and views in monospace.
+	PrefEditorMonospace = "editorMonospace"
+	// PrefEditorFontSize is the editor text size in points (0 is the
+	// theme's).
+	PrefEditorFontSize = "editorFontSize"
 )
 
+// DefaultHealthInterval is the health check interval in seconds when none is saved.
+const DefaultHealthInterval = 10
+
+// DefaultSchemaCheckInterval is the schema check interval in seconds when none is saved.
+const DefaultSchemaCheckInterval = 300
+
+// DefaultAutosaveInterval is the workspace autosave interval in seconds when none is saved.
+const DefaultAutosaveInterval = 30
+
+// logLevels are the log level choices, most verbose first.
+var logLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// LoadLogSettings reads the saved log level and extra log file.
+func LoadLogSettings(prefs fyne.Preferences) logging.Settings {
+	return logging.Settings{
+		Level:    logging.ParseLevel(prefs.StringWithFallback(PrefLogLevel, "info")),
+		FilePath: prefs.String(PrefLogFile),
+	}
+}
+
+// LoadAuditSettings reads whether calls are audited, and with their messages.
+func LoadAuditSettings(prefs fyne.Preferences) (enabled, payloads bool) {
+	return prefs.BoolWithFallback(PrefAudit, false), prefs.BoolWithFallback(PrefAuditPayloads, false)
+}
+
 // PreferencesCallbacks provides hooks for the preferences dialog to apply changes.
 type PreferencesCallbacks struct {
-	OnThemeChange func(mode string) // Called with "system", "dark", or "light"
+	OnThemeChange               func(mode string) // Called with "system", "dark", or "light"
+	OnFormMaxDepthChange        func(depth int)   // Called with the saved nesting depth
+	OnHealthIntervalChange      func(seconds int) // Called with the saved interval (0 is off)
+	OnSchemaCheckIntervalChange func(seconds int) // Called with the saved interval (0 is off)
+	OnStreamMessagesChange      func(n int)       // Called with the saved streamed message cap
+	// OnEditorFontChange is called with the saved editor font
+	OnEditorFontChange func(f components.EditorFont)
+	// OnLogSettingsChange is called with the saved log level and file
+	OnLogSettingsChange func(settings logging.Settings)
+	// LogSettings is the logging in effect, shown in the Logging tab; the
+	// saved settings are shown if it is nil
+	LogSettings func() logging.Settings
+	// OnAuditChange is called with the saved audit choices
+	OnAuditChange func(enabled, payloads bool)
+	// AuditPath is the audit file shown in the Logging tab
+	AuditPath string
 }
 
 // ShowPreferencesDialog displays the unified preferences dialog with General and Appearance tabs.
@@ -30,11 +109,72 @@ func ShowPreferencesDialog(a fyne.App, window fyne.Window, callbacks Preferences
 	timeoutEntry := widget.NewEntry()
 	timeoutEntry.SetText(strconv.FormatFloat(currentTimeout, 'f', -1, 64))
 
+	currentDepth := prefs.IntWithFallback(PrefFormMaxDepth, form.DefaultMaxDepth)
+	depthEntry := widget.NewEntry()
+	depthEntry.SetText(strconv.Itoa(currentDepth))
+
+	currentHealth := prefs.IntWithFallback(PrefHealthInterval, DefaultHealthInterval)
+	healthEntry := widget.NewEntry()
+	healthEntry.SetText(strconv.Itoa(currentHealth))
+
+	currentSchemaCheck := prefs.IntWithFallback(PrefSchemaCheckInterval, DefaultSchemaCheckInterval)
+	schemaCheckEntry := widget.NewEntry()
+	schemaCheckEntry.SetText(strconv.Itoa(currentSchemaCheck))
+
+	currentAutosave := prefs.IntWithFallback(PrefAutosaveInterval, DefaultAutosaveInterval)
+	autosaveEntry := widget.NewEntry()
+	autosaveEntry.SetText(strconv.Itoa(currentAutosave))
+
+	currentStreamMessages := prefs.IntWithFallback(PrefStreamMessages, streamconst.MaxStreamMessages)
+	streamMessagesEntry := widget.NewEntry()
+	streamMessagesEntry.SetText(strconv.Itoa(currentStreamMessages))
+
+	bodyCarryLabels := map[model.BodyCarry]string{
+		model.BodyCarryClear:   "Start from the template",
+		model.BodyCarryKeep:    "Keep it as written",
+		model.BodyCarryConvert: "Keep the fields that fit",
+	}
+	bodyCarrySelect := widget.NewSelect([]string{
+		bodyCarryLabels[model.BodyCarryClear],
+		bodyCarryLabels[model.BodyCarryKeep],
+		bodyCarryLabels[model.BodyCarryConvert],
+	}, nil)
+	bodyCarrySelect.SetSelected(bodyCarryLabels[model.ParseBodyCarry(prefs.String(PrefBodyCarry))])
+
+	historyCredentialsCheck := widget.NewCheck("Save credentials in history", nil)
+	historyCredentialsCheck.SetChecked(prefs.BoolWithFallback(PrefHistoryCredentials, false))
+
 	generalTab := container.NewTabItem("General", container.NewVBox(
 		widget.NewForm(
 			widget.NewFormItem("Request Timeout (seconds)", timeoutEntry),
 		),
 		widget.NewLabel("Timeout for unary RPC requests. Streaming RPCs are not affected."),
+		widget.NewForm(
+			widget.NewFormItem("Form Nesting Depth", depthEntry),
+		),
+		widget.NewLabel("Nested messages deeper than this are added on request."),
+		widget.NewForm(
+			widget.NewFormItem("Body on Method Switch", bodyCarrySelect),
+		),
+		widget.NewLabel("What happens to the request body when the next method takes a different message type."),
+		widget.NewForm(
+			widget.NewFormItem("Health Check Interval (seconds)", healthEntry),
+		),
+		widget.NewLabel("How often the server's grpc.health.v1 status is checked. 0 turns checks off."),
+		widget.NewForm(
+			widget.NewFormItem("Schema Check Interval (seconds)", schemaCheckEntry),
+		),
+		widget.NewLabel("How often the server is asked for its services to notice schema changes. 0 turns checks off."),
+		widget.NewForm(
+			widget.NewFormItem("Autosave Interval (seconds)", autosaveEntry),
+		),
+		widget.NewLabel("How often unsaved workspace changes are kept for restoring after a crash. 0 turns autosave off."),
+		widget.NewForm(
+			widget.NewFormItem("Streamed Messages Kept", streamMessagesEntry),
+		),
+		widget.NewLabel("Older server-stream messages are dropped past this many."),
+		historyCredentialsCheck,
+		widget.NewLabel("Otherwise authorization headers are saved to history as [redacted]."),
 	))
 
 	// --- Appearance tab ---
@@ -54,15 +194,83 @@ func ShowPreferencesDialog(a fyne.App, window fyne.Window, callbacks Preferences
 		themeSelector.SetSelected("System Default")
 	}
 
+	editorFont := NewEditorFontControls(LoadEditorFont(prefs), nil)
+
 	appearanceTab := container.NewTabItem("Appearance", container.NewVBox(
+		widget.NewForm(append(
+			[]*widget.FormItem{widget.NewFormItem("Theme", themeSelector)},
+			editorFont.FormItems()...,
+		)...),
+		widget.NewLabel("For the request editor, response, streamed messages and bidi conversation."),
+	))
+
+	// --- Logging tab ---
+
+	currentLog := LoadLogSettings(prefs)
+	if callbacks.LogSettings != nil {
+		currentLog = callbacks.LogSettings()
+	}
+	levelNames := make([]string, len(logLevels))
+	for i, l := range logLevels {
+		levelNames[i] = logging.LevelName(l)
+	}
+	logLevelSelect := widget.NewSelect(levelNames, nil)
+	logLevelSelect.SetSelected(logging.LevelName(currentLog.Level))
+
+	logFileEntry := widget.NewEntry()
+	logFileEntry.SetPlaceHolder("None")
+	logFileEntry.SetText(currentLog.FilePath)
+	logFileBrowse := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			writer.Close()
+			logFileEntry.SetText(writer.URI().Path())
+		}, window)
+		d.SetFileName("grotto.log")
+		d.Show()
+	})
+
+	auditEnabled, auditPayloads := LoadAuditSettings(prefs)
+	auditPayloadsCheck := widget.NewCheck("Include request and response messages", nil)
+	auditPayloadsCheck.SetChecked(auditPayloads)
+	auditCheck := widget.NewCheck("Audit every call", func(on bool) {
+		if on {
+			auditPayloadsCheck.Enable()
+		} else {
+			auditPayloadsCheck.Disable()
+		}
+	})
+	auditCheck.SetChecked(auditEnabled)
+	if !auditEnabled {
+		auditPayloadsCheck.Disable()
+	}
+	auditHintText := "Each call's server, method, sizes, status and duration are appended to a JSON lines audit file. Applies from the next connection."
+	if callbacks.AuditPath != "" {
+		auditHintText += "\nFile: " + callbacks.AuditPath
+	}
+	auditHint := widget.NewLabel(auditHintText)
+	auditHint.Wrapping = fyne.TextWrapWord
+
+	loggingTab := container.NewTabItem("Logging", container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Log Level", logLevelSelect),
+		),
+		widget.NewLabel("Debug includes the detail of reflection and schema resolution. Applies immediately."),
 		widget.NewForm(
-			widget.NewFormItem("Theme", themeSelector),
+			widget.NewFormItem("Also Log To", container.NewBorder(nil, nil, nil, logFileBrowse, logFileEntry)),
 		),
+		widget.NewLabel("Records are appended to this file as JSON, besides the usual log file. Leave empty for none."),
+		widget.NewSeparator(),
+		auditCheck,
+		auditPayloadsCheck,
+		auditHint,
 	))
 
 	// --- Build dialog ---
 
-	tabs := container.NewAppTabs(generalTab, appearanceTab)
+	tabs := container.NewAppTabs(generalTab, appearanceTab, loggingTab)
 
 	dlg := dialog.NewCustomConfirm("Preferences", "Save", "Cancel", tabs, func(save bool) {
 		if !save {
@@ -74,6 +282,53 @@ func ShowPreferencesDialog(a fyne.App, window fyne.Window, callbacks Preferences
 			prefs.SetFloat(PrefRequestTimeout, val)
 		}
 
+		// Save form nesting depth
+		if val, err := strconv.Atoi(depthEntry.Text); err == nil && val > 0 {
+			prefs.SetInt(PrefFormMaxDepth, val)
+			if callbacks.OnFormMaxDepthChange != nil {
+				callbacks.OnFormMaxDepthChange(val)
+			}
+		}
+
+		// Save health check interval
+		if val, err := strconv.Atoi(healthEntry.Text); err == nil && val >= 0 {
+			prefs.SetInt(PrefHealthInterval, val)
+			if callbacks.OnHealthIntervalChange != nil {
+				callbacks.OnHealthIntervalChange(val)
+			}
+		}
+
+		// Save schema check interval
+		if val, err := strconv.Atoi(schemaCheckEntry.Text); err == nil && val >= 0 {
+			prefs.SetInt(PrefSchemaCheckInterval, val)
+			if callbacks.OnSchemaCheckIntervalChange != nil {
+				callbacks.OnSchemaCheckIntervalChange(val)
+			}
+		}
+
+		// Save autosave interval
+		if val, err := strconv.Atoi(autosaveEntry.Text); err == nil && val >= 0 {
+			prefs.SetInt(PrefAutosaveInterval, val)
+		}
+
+		// Save streamed message cap
+		if val, err := strconv.Atoi(streamMessagesEntry.Text); err == nil && val > 0 {
+			prefs.SetInt(PrefStreamMessages, val)
+			if callbacks.OnStreamMessagesChange != nil {
+				callbacks.OnStreamMessagesChange(val)
+			}
+		}
+
+		// Save what happens to the body on method switch
+		for carry, label := range bodyCarryLabels {
+			if label == bodyCarrySelect.Selected {
+				prefs.SetString(PrefBodyCarry, string(carry))
+			}
+		}
+
+		// Save history credentials choice
+		prefs.SetBool(PrefHistoryCredentials, historyCredentialsCheck.Checked)
+
 		// Save and apply theme
 		var mode string
 		switch themeSelector.Selected {
@@ -88,8 +343,32 @@ func ShowPreferencesDialog(a fyne.App, window fyne.Window, callbacks Preferences
 		if callbacks.OnThemeChange != nil {
 			callbacks.OnThemeChange(mode)
 		}
+
+		// Save and apply the editor font
+		SaveEditorFont(prefs, editorFont.Font())
+		if callbacks.OnEditorFontChange != nil {
+			callbacks.OnEditorFontChange(editorFont.Font())
+		}
+
+		// Save and apply logging
+		logSettings := logging.Settings{
+			Level:    logging.ParseLevel(logLevelSelect.Selected),
+			FilePath: strings.TrimSpace(logFileEntry.Text),
+		}
+		prefs.SetString(PrefLogLevel, logging.LevelName(logSettings.Level))
+		prefs.SetString(PrefLogFile, logSettings.FilePath)
+		if callbacks.OnLogSettingsChange != nil {
+			callbacks.OnLogSettingsChange(logSettings)
+		}
+
+		// Save and apply audit choices
+		prefs.SetBool(PrefAudit, auditCheck.Checked)
+		prefs.SetBool(PrefAuditPayloads, auditPayloadsCheck.Checked)
+		if callbacks.OnAuditChange != nil {
+			callbacks.OnAuditChange(auditCheck.Checked, auditPayloadsCheck.Checked)
+		}
 	}, window)
 
-	dlg.Resize(fyne.NewSize(500, 350))
+	dlg.Resize(fyne.NewSize(500, 540))
 	dlg.Show()
 }
@@ -0,0 +1,146 @@
+package settings
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+)
+
+// proxyOptions lists the selectable proxy types in display order
+var proxyOptions = []domain.ProxyType{
+	domain.ProxyNone,
+	domain.ProxyHTTP,
+	domain.ProxySOCKS5,
+}
+
+// ProxyConfig is a widget for routing a connection through an HTTP CONNECT
+// or SOCKS5 proxy
+type ProxyConfig struct {
+	widget.BaseWidget
+
+	proxyType *widget.Select
+	host      *widget.Entry
+	port      *widget.Entry
+	username  *widget.Entry
+	password  *widget.Entry
+
+	// UI container
+	container *fyne.Container
+}
+
+// NewProxyConfig creates a new proxy settings widget
+func NewProxyConfig() *ProxyConfig {
+	p := &ProxyConfig{}
+
+	labels := make([]string, len(proxyOptions))
+	for i, opt := range proxyOptions {
+		labels[i] = opt.String()
+	}
+	p.proxyType = widget.NewSelect(labels, func(string) {
+		p.updateFieldStates()
+	})
+
+	p.host = widget.NewEntry()
+	p.host.SetPlaceHolder("proxy.example.com")
+
+	p.port = widget.NewEntry()
+	p.port.SetPlaceHolder("1080")
+	p.port.Validator = validateProxyPort
+
+	p.username = widget.NewEntry()
+	p.username.SetPlaceHolder("Optional")
+
+	p.password = widget.NewPasswordEntry()
+	p.password.SetPlaceHolder("Optional")
+
+	note := widget.NewLabel("The proxy resolves the server address, so names only known inside its network work. " +
+		"Credentials are saved with the connection in plain text.")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.LowImportance
+
+	p.container = container.NewVBox(
+		widget.NewLabel("Proxy"),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Type", p.proxyType),
+			widget.NewFormItem("Host", p.host),
+			widget.NewFormItem("Port", p.port),
+			widget.NewFormItem("Username", p.username),
+			widget.NewFormItem("Password", p.password),
+		),
+		note,
+	)
+
+	p.proxyType.SetSelected(domain.ProxyNone.String())
+
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+// GetProxy returns the entered proxy settings. A type of None clears the
+// other fields.
+func (p *ProxyConfig) GetProxy() domain.ProxySettings {
+	var proxyType domain.ProxyType
+	for _, opt := range proxyOptions {
+		if opt.String() == p.proxyType.Selected {
+			proxyType = opt
+		}
+	}
+	if proxyType == domain.ProxyNone {
+		return domain.ProxySettings{}
+	}
+	port, _ := strconv.Atoi(strings.TrimSpace(p.port.Text))
+	return domain.ProxySettings{
+		Type:     proxyType,
+		Host:     strings.TrimSpace(p.host.Text),
+		Port:     port,
+		Username: p.username.Text,
+		Password: p.password.Text,
+	}
+}
+
+// SetProxy fills the fields from saved proxy settings
+func (p *ProxyConfig) SetProxy(s domain.ProxySettings) {
+	p.host.SetText(s.Host)
+	p.port.SetText("")
+	if s.Port != 0 {
+		p.port.SetText(strconv.Itoa(s.Port))
+	}
+	p.username.SetText(s.Username)
+	p.password.SetText(s.Password)
+	p.proxyType.SetSelected(s.Type.String())
+}
+
+// updateFieldStates enables the fields only when a proxy is selected
+func (p *ProxyConfig) updateFieldStates() {
+	fields := []*widget.Entry{p.host, p.port, p.username, p.password}
+	for _, f := range fields {
+		if p.proxyType.Selected == domain.ProxyNone.String() {
+			f.Disable()
+		} else {
+			f.Enable()
+		}
+	}
+}
+
+// CreateRenderer implements the fyne.Widget interface
+func (p *ProxyConfig) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(p.container)
+}
+
+// validateProxyPort accepts an empty field or a TCP port number.
+func validateProxyPort(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err != nil || n < 1 || n > 65535 {
+		return errors.New("enter a port from 1 to 65535")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// maxRetryAttempts bounds the attempts that can be entered, so a typo
+// cannot hammer a struggling server.
+const maxRetryAttempts = 10
+
+// RetryConfig is a widget for setting how a connection's unary calls are
+// retried. Backoffs are entered in seconds and codes as a comma-separated
+// list; an empty attempts field makes a single attempt.
+type RetryConfig struct {
+	widget.BaseWidget
+
+	attempts       *widget.Entry
+	initialBackoff *widget.Entry
+	maxBackoff     *widget.Entry
+	codes          *widget.Entry
+
+	// UI container
+	container *fyne.Container
+}
+
+// NewRetryConfig creates a new retry policy widget
+func NewRetryConfig() *RetryConfig {
+	r := &RetryConfig{}
+
+	r.attempts = widget.NewEntry()
+	r.attempts.SetPlaceHolder("1 (no retries)")
+	r.attempts.Validator = validateAttempts
+
+	r.initialBackoff = widget.NewEntry()
+	r.initialBackoff.SetPlaceHolder(formatSeconds(grpc.DefaultRetryBackoff) + " (default)")
+	r.initialBackoff.Validator = validateSeconds
+
+	r.maxBackoff = widget.NewEntry()
+	r.maxBackoff.SetPlaceHolder("Uncapped (default)")
+	r.maxBackoff.Validator = validateSeconds
+
+	r.codes = widget.NewEntry()
+	r.codes.SetPlaceHolder(strings.Join(domain.DefaultRetryableCodes, ", ") + " (default)")
+	r.codes.Validator = validateCodes
+
+	note := widget.NewLabel("Unary calls failing with one of the codes are sent again, " +
+		"waiting the backoff before the first retry and twice as long before each one after it. " +
+		"Retries stop at the call's timeout. Streaming calls are never retried. " +
+		"Only retry methods that are safe to repeat.")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.LowImportance
+
+	r.container = container.NewVBox(
+		widget.NewLabel("Retries"),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Max attempts", r.attempts),
+			widget.NewFormItem("Initial backoff (s)", r.initialBackoff),
+			widget.NewFormItem("Max backoff (s)", r.maxBackoff),
+			widget.NewFormItem("Retryable codes", r.codes),
+		),
+		note,
+	)
+
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// GetPolicy returns the entered retry policy (zero values for empty or
+// invalid fields)
+func (r *RetryConfig) GetPolicy() domain.RetryPolicy {
+	p := domain.RetryPolicy{
+		InitialBackoff: parseSeconds(r.initialBackoff.Text),
+		MaxBackoff:     parseSeconds(r.maxBackoff.Text),
+	}
+	if validateAttempts(r.attempts.Text) == nil {
+		p.MaxAttempts, _ = strconv.Atoi(strings.TrimSpace(r.attempts.Text))
+	}
+	if validateCodes(r.codes.Text) == nil {
+		p.RetryableCodes = splitCodes(r.codes.Text)
+	}
+	return p
+}
+
+// SetPolicy fills the fields from p (zero values leave a field empty)
+func (r *RetryConfig) SetPolicy(p domain.RetryPolicy) {
+	attempts := ""
+	if p.MaxAttempts > 0 {
+		attempts = strconv.Itoa(p.MaxAttempts)
+	}
+	r.attempts.SetText(attempts)
+	r.initialBackoff.SetText(formatSeconds(p.InitialBackoff))
+	r.maxBackoff.SetText(formatSeconds(p.MaxBackoff))
+	r.codes.SetText(strings.Join(p.RetryableCodes, ", "))
+}
+
+// CreateRenderer implements the fyne.Widget interface
+func (r *RetryConfig) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.container)
+}
+
+// validateAttempts accepts an empty field or a whole number of attempts up
+// to maxRetryAttempts.
+func validateAttempts(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > maxRetryAttempts {
+		return fmt.Errorf("enter a number of attempts from 1 to %d", maxRetryAttempts)
+	}
+	return nil
+}
+
+// validateCodes accepts an empty field or status code names such as
+// UNAVAILABLE, separated by commas.
+func validateCodes(s string) error {
+	for _, name := range splitCodes(s) {
+		if _, err := grpc.ParseCode(name); err != nil {
+			return errors.New("unknown status code " + name)
+		}
+	}
+	return nil
+}
+
+// splitCodes splits a comma-separated list of code names, upper-casing
+// them and dropping empty entries.
+func splitCodes(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -1,6 +1,8 @@
 package settings
 
 import (
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -16,6 +18,8 @@ type TLSConfig struct {
 	// TLS settings
 	enableTLS     *widget.Check
 	skipVerify    *widget.Check
+	skipWarning   *widget.Label // Shown while verification is skipped
+	serverName    *widget.Entry
 	certFile      *widget.Entry
 	certFileBtn   *widget.Button
 	clientCert    *widget.Entry
@@ -39,11 +43,23 @@ func NewTLSConfig(window fyne.Window) *TLSConfig {
 		t.updateFieldStates()
 	})
 
-	t.skipVerify = widget.NewCheck("Skip certificate verification (insecure)", nil)
+	t.skipVerify = widget.NewCheck("Skip certificate verification (insecure)", func(bool) {
+		t.updateSkipWarning()
+	})
+	t.skipWarning = widget.NewLabelWithStyle(
+		"⚠ The server's identity will not be checked. Anyone between you and the server can read and change this connection's traffic, credentials included. Use only for development servers.",
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	t.skipWarning.Importance = widget.DangerImportance
+	t.skipWarning.Wrapping = fyne.TextWrapWord
+	t.skipWarning.Hide()
+
+	// Server name (SNI) override
+	t.serverName = widget.NewEntry()
+	t.serverName.SetPlaceHolder("Server name, if not the address's host (optional)")
 
 	// CA Certificate
 	t.certFile = widget.NewEntry()
-	t.certFile.SetPlaceHolder("Path to CA certificate (optional)")
+	t.certFile.SetPlaceHolder("Path to CA certificate bundle, PEM (optional)")
 	t.certFileBtn = widget.NewButton("Browse", func() {
 		t.showFileDialog("Select CA Certificate", t.certFile)
 	})
@@ -89,6 +105,9 @@ func (t *TLSConfig) buildLayout() {
 		widget.NewSeparator(),
 		t.enableTLS,
 		t.skipVerify,
+		t.skipWarning,
+		widget.NewLabel("Server Name Override:"),
+		t.serverName,
 		widget.NewLabel("CA Certificate:"),
 		caCertRow,
 		widget.NewLabel("Client Certificate (mTLS):"),
@@ -125,6 +144,7 @@ func (t *TLSConfig) updateFieldStates() {
 
 	if enabled {
 		t.skipVerify.Enable()
+		t.serverName.Enable()
 		t.certFile.Enable()
 		t.certFileBtn.Enable()
 		t.clientCert.Enable()
@@ -133,6 +153,7 @@ func (t *TLSConfig) updateFieldStates() {
 		t.clientKeyBtn.Enable()
 	} else {
 		t.skipVerify.Disable()
+		t.serverName.Disable()
 		t.certFile.Disable()
 		t.certFileBtn.Disable()
 		t.clientCert.Disable()
@@ -140,6 +161,17 @@ func (t *TLSConfig) updateFieldStates() {
 		t.clientKey.Disable()
 		t.clientKeyBtn.Disable()
 	}
+	t.updateSkipWarning()
+}
+
+// updateSkipWarning shows the warning while TLS is on with verification
+// skipped.
+func (t *TLSConfig) updateSkipWarning() {
+	if t.enableTLS.Checked && t.skipVerify.Checked {
+		t.skipWarning.Show()
+	} else {
+		t.skipWarning.Hide()
+	}
 }
 
 // GetConfig returns the current TLS settings
@@ -150,6 +182,8 @@ func (t *TLSConfig) GetConfig() domain.TLSSettings {
 		CertFile:       t.certFile.Text,
 		ClientCertFile: t.clientCert.Text,
 		ClientKeyFile:  t.clientKey.Text,
+
+		ServerNameOverride: strings.TrimSpace(t.serverName.Text),
 	}
 }
 
@@ -157,6 +191,7 @@ func (t *TLSConfig) GetConfig() domain.TLSSettings {
 func (t *TLSConfig) SetConfig(cfg domain.TLSSettings) {
 	t.enableTLS.SetChecked(cfg.Enabled)
 	t.skipVerify.SetChecked(cfg.SkipVerify)
+	t.serverName.SetText(cfg.ServerNameOverride)
 	t.certFile.SetText(cfg.CertFile)
 	t.clientCert.SetText(cfg.ClientCertFile)
 	t.clientKey.SetText(cfg.ClientKeyFile)
@@ -0,0 +1,148 @@
+package settings
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// transportOptions lists the selectable transports in display order
+var transportOptions = []domain.Transport{
+	domain.TransportGRPC,
+	domain.TransportGRPCWeb,
+	domain.TransportGRPCWebText,
+}
+
+// compressionNone labels the empty compressor in the selector
+const compressionNone = "None"
+
+// compressionOptions lists the selectable request compressors
+var compressionOptions = []string{compressionNone, "gzip"}
+
+// TransportConfig is a widget for choosing between native gRPC and gRPC-Web,
+// the request compression, and the :authority and user-agent sent
+type TransportConfig struct {
+	widget.BaseWidget
+
+	radio       *widget.RadioGroup
+	compression *widget.Select
+	authority   *widget.Entry
+	userAgent   *widget.Entry
+
+	// UI container
+	container *fyne.Container
+}
+
+// NewTransportConfig creates a new transport selection widget
+func NewTransportConfig() *TransportConfig {
+	t := &TransportConfig{}
+
+	labels := make([]string, len(transportOptions))
+	for i, opt := range transportOptions {
+		labels[i] = opt.String()
+	}
+	t.radio = widget.NewRadioGroup(labels, nil)
+	t.radio.Required = true
+	t.radio.SetSelected(domain.TransportGRPC.String())
+
+	note := widget.NewLabel("gRPC-Web is for servers behind a gRPC-Web proxy (e.g. Envoy's grpc_web filter). " +
+		"It supports unary and server-streaming methods only, and most proxies do not expose reflection.")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.LowImportance
+
+	t.compression = widget.NewSelect(compressionOptions, nil)
+	t.compression.SetSelected(compressionNone)
+
+	compressionNote := widget.NewLabel("Compresses requests. Servers usually answer in kind. Native gRPC only.")
+	compressionNote.Wrapping = fyne.TextWrapWord
+	compressionNote.Importance = widget.LowImportance
+
+	t.authority = widget.NewEntry()
+	t.authority.SetPlaceHolder("The address (default)")
+	t.authority.Validator = grpc.ValidateAuthority
+
+	t.userAgent = widget.NewEntry()
+	t.userAgent.SetPlaceHolder("grpc-go (default)")
+
+	authorityNote := widget.NewLabel("For gateways that route by :authority, e.g. to dial an IP as tenant-a.example.com:443. " +
+		"TLS certificates are checked against the authority. Native gRPC only.")
+	authorityNote.Wrapping = fyne.TextWrapWord
+	authorityNote.Importance = widget.LowImportance
+
+	t.container = container.NewVBox(
+		widget.NewLabel("Transport"),
+		widget.NewSeparator(),
+		t.radio,
+		note,
+		widget.NewLabel("Compression"),
+		widget.NewSeparator(),
+		t.compression,
+		compressionNote,
+		widget.NewLabel("Authority"),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Authority", t.authority),
+			widget.NewFormItem("User-Agent", t.userAgent),
+		),
+		authorityNote,
+	)
+
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// GetTransport returns the selected transport
+func (t *TransportConfig) GetTransport() domain.Transport {
+	for _, opt := range transportOptions {
+		if opt.String() == t.radio.Selected {
+			return opt
+		}
+	}
+	return domain.TransportGRPC
+}
+
+// SetTransport selects the given transport
+func (t *TransportConfig) SetTransport(transport domain.Transport) {
+	t.radio.SetSelected(transport.String())
+}
+
+// GetCompression returns the selected compressor, or "" for none
+func (t *TransportConfig) GetCompression() string {
+	if t.compression.Selected == compressionNone {
+		return ""
+	}
+	return t.compression.Selected
+}
+
+// SetCompression selects the given compressor ("" for none)
+func (t *TransportConfig) SetCompression(name string) {
+	if name == "" {
+		name = compressionNone
+	}
+	t.compression.SetSelected(name)
+}
+
+// GetAuthority returns the entered :authority and user-agent ("" for
+// gRPC's own); an authority that does not validate is left out.
+func (t *TransportConfig) GetAuthority() (authority, userAgent string) {
+	authority = strings.TrimSpace(t.authority.Text)
+	if grpc.ValidateAuthority(authority) != nil {
+		authority = ""
+	}
+	return authority, strings.TrimSpace(t.userAgent.Text)
+}
+
+// SetAuthority fills in the :authority and user-agent ("" for gRPC's own)
+func (t *TransportConfig) SetAuthority(authority, userAgent string) {
+	t.authority.SetText(authority)
+	t.userAgent.SetText(userAgent)
+}
+
+// CreateRenderer implements the fyne.Widget interface
+func (t *TransportConfig) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.container)
+}
@@ -3,132 +3,188 @@ package ui
 import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/shhac/grotto/internal/controller"
 )
 
+// modalOpen reports whether a dialog or pop-up is showing over c.
+func modalOpen(c fyne.Canvas) bool {
+	return c.Overlays().Top() != nil
+}
+
+// unlessModal wraps fn so it does nothing while a dialog is open over c.
+func unlessModal(c fyne.Canvas, fn func()) func() {
+	return func() {
+		if !modalOpen(c) {
+			fn()
+		}
+	}
+}
+
+// addShortcut registers a canvas shortcut that is ignored while a dialog
+// is open, so keys meant for the dialog cannot act on the window behind it.
+func addShortcut(c fyne.Canvas, shortcut fyne.Shortcut, fn func()) {
+	guarded := unlessModal(c, fn)
+	c.AddShortcut(shortcut, func(fyne.Shortcut) { guarded() })
+}
+
+// sendShortcut is Ctrl+Enter on Windows and Linux, Cmd+Enter on macOS.
+var sendShortcut = &desktop.CustomShortcut{
+	KeyName:  fyne.KeyReturn,
+	Modifier: fyne.KeyModifierShortcutDefault,
+}
+
+// findTypeShortcut is Ctrl+T on every platform.
+var findTypeShortcut = &desktop.CustomShortcut{
+	KeyName:  fyne.KeyT,
+	Modifier: fyne.KeyModifierControl,
+}
+
+// handleSendShortcut sends the request, or the next stream message when
+// the method is client streaming.
+func (w *MainWindow) handleSendShortcut() {
+	w.logger.Debug("keyboard shortcut: send request")
+	w.requestPanel.TriggerSend()
+}
+
 // setupKeyboardShortcuts configures all keyboard shortcuts for the main window
 func (w *MainWindow) setupKeyboardShortcuts() {
 	canvas := w.window.Canvas()
 
-	// Cmd+Enter: Send request
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	// Ctrl+Enter (Cmd+Enter on macOS): Send request
+	addShortcut(canvas, sendShortcut, w.handleSendShortcut)
+
+	// Cmd+Enter: Send request, kept for Windows and Linux users used to it
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyReturn,
 		Modifier: fyne.KeyModifierSuper, // Cmd on macOS, Win on Windows
-	}, func(shortcut fyne.Shortcut) {
-		w.logger.Debug("keyboard shortcut: send request")
-		w.requestPanel.TriggerSend()
+	}, w.handleSendShortcut)
+
+	// Ctrl+K: Search services
+	addShortcut(canvas, &desktop.CustomShortcut{
+		KeyName:  fyne.KeyK,
+		Modifier: fyne.KeyModifierControl,
+	}, func() {
+		w.logger.Debug("keyboard shortcut: search services")
+		w.serviceBrowser.FocusFilter()
+	})
+
+	// Ctrl+T: Find a type by name
+	addShortcut(canvas, findTypeShortcut, func() {
+		w.logger.Debug("keyboard shortcut: find type")
+		w.showFindType()
 	})
 
 	// Cmd+S: Save workspace
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyS,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: save workspace")
 		w.workspacePanel.TriggerSave()
 	})
 
 	// Cmd+O: Load workspace
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyO,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: load workspace")
 		w.workspacePanel.TriggerLoad()
 	})
 
 	// Cmd+K: Focus address bar
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyK,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: focus address bar")
 		w.connectionBar.FocusAddress()
 	})
 
 	// Cmd+L: Clear response
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyL,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: clear response")
 		w.responsePanel.ClearResponse()
 	})
 
 	// Cmd+1: Switch to Text mode
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.Key1,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: switch to text mode")
 		w.requestPanel.SwitchToTextMode()
 	})
 
 	// Cmd+2: Switch to Form mode
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.Key2,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: switch to form mode")
 		w.requestPanel.SwitchToFormMode()
 	})
 
 	// Cmd+B: Focus service browser
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyB,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: focus service browser")
 		w.serviceBrowser.FocusTree()
 	})
 
 	// Cmd+P: Focus service filter
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyP,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: focus service filter")
 		w.serviceBrowser.FocusFilter()
 	})
 
 	// Cmd+Shift+E: Expand all services
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyE,
 		Modifier: fyne.KeyModifierSuper | fyne.KeyModifierShift,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: expand all services")
 		w.serviceBrowser.ExpandAll()
 	})
 
 	// Cmd+Shift+W: Collapse all services
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyW,
 		Modifier: fyne.KeyModifierSuper | fyne.KeyModifierShift,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: collapse all services")
 		w.serviceBrowser.CollapseAll()
 	})
 
 	// Cmd+Shift+C: Toggle connect/disconnect
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyC,
 		Modifier: fyne.KeyModifierSuper | fyne.KeyModifierShift,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: toggle connection")
 		w.toggleConnection()
 	})
 
 	// Cmd+,: Open preferences
-	canvas.AddShortcut(&desktop.CustomShortcut{
+	addShortcut(canvas, &desktop.CustomShortcut{
 		KeyName:  fyne.KeyComma,
 		Modifier: fyne.KeyModifierSuper,
-	}, func(shortcut fyne.Shortcut) {
+	}, func() {
 		w.logger.Debug("keyboard shortcut: open preferences")
 		w.showPreferences()
 	})
 
 	// Escape: Cancel current operation (for streaming)
 	canvas.SetOnTypedKey(func(key *fyne.KeyEvent) {
-		if key.Name == fyne.KeyEscape {
+		if key.Name == fyne.KeyEscape && !modalOpen(canvas) {
 			w.logger.Debug("keyboard shortcut: escape (cancel operation)")
 			w.handleCancelOperation()
 		}
@@ -137,51 +193,34 @@ func (w *MainWindow) setupKeyboardShortcuts() {
 	w.logger.Info("keyboard shortcuts configured")
 }
 
-// handleCancelOperation cancels any active streaming operation.
+// handleCancelOperation cancels any token command still running for a
+// request, and any active streaming operation.
 // Priority order: bidi > server stream > client stream > unary.
 func (w *MainWindow) handleCancelOperation() {
-	w.streamMu.Lock()
-	bidiCancel := w.bidiCancelFunc
-	serverCancel := w.serverStreamCancel
-	clientHandle := w.clientStreamHandle
-	unaryCancel := w.unaryCancel
-
-	// Cancel in priority order
-	switch {
-	case bidiCancel != nil:
-		w.bidiCancelFunc = nil
-		w.bidiStreamHandle = nil
-		w.streamMu.Unlock()
-		bidiCancel()
-		w.bidiPanel.SetStatus("Cancelled by user (Escape)")
-		w.bidiPanel.DisableSendControls()
+	w.tokenFetches.stop()
+	if w.bidi.stop() {
+		w.bidiPanel.ShowEnded("Cancelled by user (Escape)")
 		w.logger.Info("bidi stream cancelled by user")
+		return
+	}
 
-	case serverCancel != nil:
-		w.serverStreamCancel = nil
-		w.streamMu.Unlock()
-		serverCancel()
+	// Cancel in priority order
+	switch w.requests.Cancel() {
+	case controller.OpServerStream:
 		streamWidget := w.responsePanel.StreamingWidget()
 		streamWidget.DisableStopButton()
 		streamWidget.SetStatus("Cancelled by user (Escape)")
 		w.logger.Info("server stream cancelled by user")
 
-	case clientHandle != nil:
-		w.clientStreamHandle = nil
-		w.streamMu.Unlock()
-		go clientHandle.CloseAndReceive()
+	case controller.OpClientStream:
 		w.requestPanel.StreamingInput().DisableSendControls()
 		w.requestPanel.StreamingInput().SetStatus("Cancelled by user (Escape)")
 		w.logger.Info("client stream cancelled by user")
 
-	case unaryCancel != nil:
-		w.unaryCancel = nil
-		w.streamMu.Unlock()
-		unaryCancel()
+	case controller.OpUnary:
 		w.logger.Info("unary request cancelled by user")
 
 	default:
-		w.streamMu.Unlock()
 		w.logger.Debug("no active operation to cancel")
 	}
 }
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddShortcut_IgnoredWhileModalOpen(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	w := test.NewWindow(widget.NewLabel("main"))
+	defer w.Close()
+	c := w.Canvas()
+
+	fired := 0
+	addShortcut(c, sendShortcut, func() { fired++ })
+	press := func() { c.(fyne.Shortcutable).TypedShortcut(sendShortcut) }
+
+	press()
+	assert.Equal(t, 1, fired)
+
+	popup := widget.NewModalPopUp(widget.NewLabel("dialog"), c)
+	popup.Show()
+	press()
+	assert.Equal(t, 1, fired, "shortcut fired behind a dialog")
+
+	popup.Hide()
+	press()
+	assert.Equal(t, 2, fired)
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// formatByteSize returns a human-readable byte count (e.g., "1.2 KB", "3.4 MB").
+func formatByteSize(bytes int) string {
+	const (
+		kb = 1024
+		mb = kb * 1024
+	)
+	switch {
+	case bytes >= mb:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/float64(mb))
+	case bytes >= kb:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/float64(kb))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+// formatMessageSizes describes the encoded request and response sizes
+// shown next to the call duration, noting the response compression taken
+// from the grpc-encoding header, if any.
+func formatMessageSizes(request, response int, headers metadata.MD) string {
+	s := fmt.Sprintf("Request: %s · Response: %s", formatByteSize(request), formatByteSize(response))
+	if enc := headers.Get(grpc.EncodingHeader); len(enc) > 0 {
+		s += " (" + enc[0] + " compressed)"
+	}
+	return s
+}
+
+// encodedSize returns the protobuf-encoded size of a JSON message, which is
+// what counts against the connection's message size limits. The JSON has
+// already been through the invoker, so failures are only logged.
+func (w *MainWindow) encodedSize(desc protoreflect.MessageDescriptor, jsonMsg string) int {
+	n, err := grpc.EncodedSize(desc, jsonMsg, w.typeResolver())
+	if err != nil {
+		w.logger.Debug("failed to measure message size", slog.Any("error", err))
+	}
+	return n
+}
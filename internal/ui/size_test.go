@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestFormatByteSize(t *testing.T) {
+	tests := []struct {
+		bytes int
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{1 << 20, "1.0 MB"},
+		{5*(1<<20) + 1<<19, "5.5 MB"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatByteSize(tt.bytes), "%d bytes", tt.bytes)
+	}
+}
+
+func TestFormatMessageSizes(t *testing.T) {
+	assert.Equal(t, "Request: 12 B · Response: 2.0 KB", formatMessageSizes(12, 2048, nil))
+	assert.Equal(t, "Request: 12 B · Response: 2.0 KB (gzip compressed)",
+		formatMessageSizes(12, 2048, metadata.Pairs("grpc-encoding", "gzip")))
+}
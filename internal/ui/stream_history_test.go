@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/shhac/grotto/internal/controller"
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/shhac/grotto/internal/ui/browser"
+	pb "github.com/shhac/grotto/testdata/grpctest/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// stallingService sends one item on each server stream, then holds it open
+// until the client goes away.
+type stallingService struct {
+	pb.UnimplementedTestServiceServer
+	started chan struct{}
+}
+
+func (s *stallingService) StreamItems(req *pb.ItemRequest, stream pb.TestService_StreamItemsServer) error {
+	if err := stream.Send(&pb.ItemResponse{Item: req.GetItem(), Ok: true}); err != nil {
+		return err
+	}
+	s.started <- struct{}{}
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func TestRecordStreamHistoryEntry_Status(t *testing.T) {
+	w := newTestMainWindow(t)
+	w.serviceBrowser = browser.NewServiceBrowser(w.state.Services, binding.NewString())
+
+	w.recordStreamHistoryEntry("localhost:50051", "pkg.Svc/Watch", "{}", nil, nil, nil, time.Second,
+		grpcstatus.Error(codes.PermissionDenied, "no"), "server_stream", 3)
+	w.recordStreamHistoryEntry("localhost:50051", "pkg.Svc/Chat", "", nil, nil, nil, time.Second,
+		grpcstatus.Error(codes.Unavailable, "gone"), "bidi_stream", 2)
+	w.recordStreamHistoryEntry("localhost:50051", "pkg.Svc/Tail", "{}", nil, nil, nil, time.Second,
+		nil, "server_stream", 1)
+	w.recordStreamHistoryEntry("localhost:50051", "pkg.Svc/Echo", "", nil, nil, nil, time.Second,
+		nil, "bidi_stream", 2)
+	w.recordHistoryEntry("localhost:50051", "pkg.Svc/Get", "{}", nil, "", nil, nil, time.Second,
+		grpcstatus.Error(codes.Unavailable, "gone"))
+	w.historyWrites.Wait()
+
+	entries, err := w.app.Storage().GetHistory(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	type outcome struct{ Status, Code string }
+	got := map[string]outcome{}
+	for _, e := range entries {
+		got[e.Method] = outcome{e.Status, e.Code}
+	}
+	// Every kind of call records the same status for the same outcome
+	assert.Equal(t, map[string]outcome{
+		"pkg.Svc/Watch": {"error", "PermissionDenied"},
+		"pkg.Svc/Chat":  {"error", "Unavailable"},
+		"pkg.Svc/Tail":  {"success", "OK"},
+		"pkg.Svc/Echo":  {"success", "OK"},
+		"pkg.Svc/Get":   {"error", "Unavailable"},
+	}, got)
+}
+
+func TestCancelAndWaitStreams_RecordsCancelledStream(t *testing.T) {
+	svc := &stallingService{started: make(chan struct{}, 1)}
+	srv := grpctest.StartServer(t, grpctest.WithService(func(s *gogrpc.Server) {
+		pb.RegisterTestServiceServer(s, svc)
+	}))
+	w := newTestMainWindow(t)
+	w.serviceBrowser = browser.NewServiceBrowser(w.state.Services, binding.NewString())
+	w.app.(*windowTestApp).invoker = controller.FromGRPC(grpc.NewInvoker(srv.Conn, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	_ = w.state.CurrentServer.Set("localhost:50051")
+
+	w.handleServerStreamRequest(controller.Call{
+		Service: "grpctest.TestService",
+		Method:  "StreamItems",
+		Desc:    pb.File_grpc_test_proto.Services().ByName("TestService").Methods().ByName("StreamItems"),
+		Body:    "{}",
+	})
+	<-svc.started
+
+	// As on close: once streams are cancelled and waited for, flushing
+	// history writes saves the cancelled stream's entry
+	w.cancelAndWaitStreams()
+	w.historyWrites.Wait()
+
+	entries, err := w.app.Storage().GetHistory(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "grpctest.TestService/StreamItems", entries[0].Method)
+	assert.Equal(t, "Canceled", entries[0].Code)
+}
@@ -0,0 +1,93 @@
+// Package streambatch sends a JSON array of messages on a client or bidi
+// stream, one element at a time with a delay between them.
+package streambatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Clock waits between messages. Tests substitute a fake.
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock waits in real time.
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Parse splits text, a JSON array of messages, into its elements, each
+// indented on its own. check, if set, is run on every element; the first
+// failure is returned with the element's position.
+func Parse(text string, check func(msg string) error) ([]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("enter a JSON array of messages")
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal([]byte(text), &elems); err != nil {
+		return nil, fmt.Errorf("not a JSON array of messages: %w", err)
+	}
+	if len(elems) == 0 {
+		return nil, errors.New("the array has no messages")
+	}
+
+	msgs := make([]string, len(elems))
+	for i, elem := range elems {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, elem, "", "  "); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		msgs[i] = buf.String()
+		if check != nil {
+			if err := check(msgs[i]); err != nil {
+				return nil, fmt.Errorf("message %d: %w", i+1, err)
+			}
+		}
+	}
+	return msgs, nil
+}
+
+// Format writes msgs as the JSON array Parse reads, indented. Messages that
+// are not valid JSON are kept as they are.
+func Format(msgs []string) string {
+	elems := make([]json.RawMessage, len(msgs))
+	for i, msg := range msgs {
+		elems[i] = json.RawMessage(msg)
+	}
+	if b, err := json.MarshalIndent(elems, "", "  "); err == nil {
+		return string(b)
+	}
+	return "[\n" + strings.Join(msgs, ",\n") + "\n]"
+}
+
+// Run sends msgs in order, waiting delay on clock between consecutive
+// messages, and calls progress with the number sent after each one. It
+// stops between messages when ctx is cancelled, returning ctx.Err(), or at
+// the first send error. It returns nil once every message is sent.
+func Run(ctx context.Context, msgs []string, delay time.Duration, clock Clock, send func(msg string) error, progress func(sent int)) error {
+	for i, msg := range msgs {
+		if i > 0 && delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(delay):
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := send(msg); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package streambatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock records the waits asked for. Each wait fires at once unless the
+// clock is held, in which case it fires on release.
+type fakeClock struct {
+	mu      sync.Mutex
+	waits   []time.Duration
+	hold    bool
+	release chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{release: make(chan time.Time, 1)}
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	if c.hold {
+		return c.release
+	}
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+// Waits returns the waits asked for so far.
+func (c *fakeClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+func TestParse(t *testing.T) {
+	msgs, err := Parse(`[{"id": "a"}, {}, {"nested": {"x": [1, 2]}}]`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"{\n  \"id\": \"a\"\n}",
+		"{}",
+		"{\n  \"nested\": {\n    \"x\": [\n      1,\n      2\n    ]\n  }\n}",
+	}, msgs)
+
+	for _, text := range []string{"", "  ", `{"id": "a"}`, `[{"id": }]`, `[]`} {
+		_, err := Parse(text, nil)
+		assert.Error(t, err, "%q", text)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	msgs := []string{`{"id": "a"}`, "{}"}
+	text := Format(msgs)
+	assert.Equal(t, "[\n  {\n    \"id\": \"a\"\n  },\n  {}\n]", text)
+	parsed, err := Parse(text, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"{\n  \"id\": \"a\"\n}", "{}"}, parsed)
+
+	assert.Equal(t, "[\n{oops},\n{}\n]", Format([]string{"{oops}", "{}"}))
+}
+
+func TestParse_Check(t *testing.T) {
+	var checked []string
+	check := func(msg string) error {
+		checked = append(checked, msg)
+		if msg == `"bad"` {
+			return errors.New("not a message")
+		}
+		return nil
+	}
+
+	_, err := Parse(`[{}, "bad", {}]`, check)
+	assert.EqualError(t, err, "message 2: not a message")
+	assert.Equal(t, []string{"{}", `"bad"`}, checked, "checking stops at the first failure")
+}
+
+func TestRun(t *testing.T) {
+	clock := newFakeClock()
+	var sent []string
+	var progress []int
+
+	err := Run(context.Background(), []string{"a", "b", "c"}, 250*time.Millisecond, clock,
+		func(msg string) error { sent = append(sent, msg); return nil },
+		func(n int) { progress = append(progress, n) },
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, sent)
+	assert.Equal(t, []int{1, 2, 3}, progress)
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, clock.waits, "no wait before the first message")
+}
+
+func TestRun_NoDelay(t *testing.T) {
+	clock := newFakeClock()
+	n := 0
+	err := Run(context.Background(), []string{"a", "b"}, 0, clock, func(string) error { n++; return nil }, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Empty(t, clock.waits)
+}
+
+func TestRun_CancelBetweenMessages(t *testing.T) {
+	clock := newFakeClock()
+	clock.hold = true
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sent := make(chan string, 3)
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, []string{"a", "b", "c"}, time.Second, clock,
+			func(msg string) error { sent <- msg; return nil }, nil)
+	}()
+
+	assert.Equal(t, "a", <-sent)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, sent, "nothing is sent after cancelling")
+}
+
+func TestRun_SendError(t *testing.T) {
+	var sent []string
+	err := Run(context.Background(), []string{"a", "b", "c"}, time.Second, newFakeClock(),
+		func(msg string) error {
+			if msg == "b" {
+				return errors.New("stream closed")
+			}
+			sent = append(sent, msg)
+			return nil
+		}, nil)
+	assert.EqualError(t, err, "message 2: stream closed")
+	assert.Equal(t, []string{"a"}, sent)
+}
+
+func TestSender(t *testing.T) {
+	app := test.NewApp()
+	t.Cleanup(app.Quit)
+
+	s := NewSender()
+	clock := newFakeClock()
+	s.clock = clock
+	var mu sync.Mutex
+	var sent []string
+	s.SetOnSend(func(json string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, json)
+	})
+	sentSoFar := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), sent...)
+	}
+	s.SetValidator(func(json string) error {
+		if json == "{}" {
+			return errors.New("empty")
+		}
+		return nil
+	})
+
+	// Invalid batches don't start
+	s.entry.SetText(`[{"a": 1}, {}]`)
+	test.Tap(s.startBtn)
+	assert.False(t, s.Running())
+	assert.Equal(t, "message 2: empty", s.progress.Text)
+
+	s.delayEntry.SetText("-5")
+	s.entry.SetText(`[{"a": 1}, {"a": 2}]`)
+	test.Tap(s.startBtn)
+	assert.False(t, s.Running())
+	assert.Contains(t, s.progress.Text, "delay must be")
+
+	s.delayEntry.SetText("40")
+	test.Tap(s.startBtn)
+	require.Eventually(t, func() bool { return !s.Running() }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"{\n  \"a\": 1\n}", "{\n  \"a\": 2\n}"}, sentSoFar())
+	assert.Equal(t, "Sent 2 of 2", s.progress.Text)
+	assert.Equal(t, []time.Duration{40 * time.Millisecond}, clock.Waits())
+	assert.False(t, s.startBtn.Disabled())
+
+	// A stopped batch keeps what was sent
+	clock.hold = true
+	test.Tap(s.startBtn)
+	require.Eventually(t, func() bool { return len(clock.Waits()) == 2 }, time.Second, time.Millisecond)
+	test.Tap(s.stopBtn)
+	require.Eventually(t, func() bool { return !s.Running() }, time.Second, time.Millisecond)
+	assert.Len(t, sentSoFar(), 3)
+	assert.Equal(t, "Stopped after 1 of 2", s.progress.Text)
+
+	// Disabled once the stream is closed
+	s.Disable()
+	test.Tap(s.startBtn)
+	assert.False(t, s.Running())
+	s.Enable()
+	assert.False(t, s.startBtn.Disabled())
+}
@@ -0,0 +1,207 @@
+package streambatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultDelay is the delay between messages shown for a new Sender.
+const defaultDelay = 100 * time.Millisecond
+
+// Sender is the "Send batch" mode of the streaming panels: an editor for a
+// JSON array of messages, the delay between them, Start and Stop, and the
+// send progress.
+type Sender struct {
+	widget.BaseWidget
+
+	entry      *widget.Entry
+	delayEntry *widget.Entry
+	startBtn   *widget.Button
+	stopBtn    *widget.Button
+	progress   *widget.Label
+	content    fyne.CanvasObject
+
+	clock    Clock
+	onSend   func(json string)
+	validate func(json string) error
+
+	disabled bool // set while the stream can't take messages
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // nil when no batch is running
+}
+
+// NewSender creates a batch sender.
+func NewSender() *Sender {
+	s := &Sender{clock: realClock{}}
+
+	s.entry = widget.NewMultiLineEntry()
+	s.entry.SetPlaceHolder(`[{"field": "first"}, {"field": "second"}]`)
+	s.entry.Wrapping = fyne.TextWrapWord
+
+	s.delayEntry = widget.NewEntry()
+	s.delayEntry.SetText(strconv.FormatInt(defaultDelay.Milliseconds(), 10))
+
+	s.startBtn = widget.NewButton("Start", s.Start)
+	s.startBtn.Importance = widget.HighImportance
+	s.stopBtn = widget.NewButton("Stop", s.Stop)
+	s.stopBtn.Disable()
+
+	s.progress = widget.NewLabel("")
+	s.progress.Wrapping = fyne.TextWrapWord
+
+	controls := container.NewBorder(nil, nil,
+		widget.NewLabel("Delay (ms):"),
+		container.NewHBox(s.startBtn, s.stopBtn),
+		s.delayEntry,
+	)
+	s.content = container.NewBorder(nil, container.NewVBox(controls, s.progress), nil, nil, s.entry)
+
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+// SetOnSend sets the callback that sends one message. It is called on the
+// UI thread, like a click on the panel's Send button.
+func (s *Sender) SetOnSend(fn func(json string)) {
+	s.onSend = fn
+}
+
+// SetValidator sets the check every message must pass before a batch
+// starts, typically against the method's input descriptor.
+func (s *Sender) SetValidator(fn func(json string) error) {
+	s.validate = fn
+}
+
+// SetMessages fills the editor with msgs, replacing what was there.
+func (s *Sender) SetMessages(msgs []string) {
+	s.entry.SetText(Format(msgs))
+}
+
+// Start validates the messages and delay and starts sending them.
+func (s *Sender) Start() {
+	if s.Running() || s.disabled || s.onSend == nil {
+		return
+	}
+
+	delay, err := parseDelay(s.delayEntry.Text)
+	if err != nil {
+		s.progress.SetText(err.Error())
+		return
+	}
+	msgs, err := Parse(s.entry.Text, s.validate)
+	if err != nil {
+		s.progress.SetText(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	s.startBtn.Disable()
+	s.stopBtn.Enable()
+	s.entry.Disable()
+	s.delayEntry.Disable()
+	total := len(msgs)
+	s.progress.SetText(fmt.Sprintf("Sending 0 of %d", total))
+
+	go func() {
+		sent := 0
+		err := Run(ctx, msgs, delay, s.clock,
+			func(msg string) error {
+				fyne.DoAndWait(func() { s.onSend(msg) })
+				return nil
+			},
+			func(n int) {
+				sent = n
+				fyne.Do(func() { s.progress.SetText(fmt.Sprintf("Sending %d of %d", n, total)) })
+			},
+		)
+		cancel()
+		fyne.Do(func() { s.finish(sent, total, err) })
+	}()
+}
+
+// Stop stops a running batch before its next message. The stream stays
+// open.
+func (s *Sender) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Disable stops a running batch and disables Start, e.g. once the send side
+// of the stream is closed.
+func (s *Sender) Disable() {
+	s.Stop()
+	s.disabled = true
+	s.startBtn.Disable()
+}
+
+// Enable allows batches to be started again.
+func (s *Sender) Enable() {
+	s.disabled = false
+	if !s.Running() {
+		s.startBtn.Enable()
+	}
+}
+
+// Running reports whether a batch is being sent.
+func (s *Sender) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancel != nil
+}
+
+// finish resets the controls after a batch ends.
+func (s *Sender) finish(sent, total int, err error) {
+	defer func() {
+		s.mu.Lock()
+		s.cancel = nil
+		s.mu.Unlock()
+	}()
+
+	if !s.disabled {
+		s.startBtn.Enable()
+	}
+	s.stopBtn.Disable()
+	s.entry.Enable()
+	s.delayEntry.Enable()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		s.progress.SetText(fmt.Sprintf("Stopped after %d of %d", sent, total))
+	case err != nil:
+		s.progress.SetText(fmt.Sprintf("Stopped after %d of %d: %v", sent, total, err))
+	default:
+		s.progress.SetText(fmt.Sprintf("Sent %d of %d", sent, total))
+	}
+}
+
+// parseDelay reads a delay in whole milliseconds.
+func parseDelay(text string) (time.Duration, error) {
+	ms, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("delay must be a whole number of milliseconds, got %q", text)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// CreateRenderer implements fyne.Widget.
+func (s *Sender) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(s.content)
+}
@@ -4,8 +4,11 @@ import (
 	"image/color"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/ui/components"
+	"github.com/shhac/grotto/internal/ui/settings"
 )
 
 // ThemePreferenceKey is the key used to store theme preference
@@ -41,12 +44,22 @@ func ApplyTheme(a fyne.App, mode string) {
 	}
 }
 
-// LoadThemePreference loads and applies the saved theme preference
+// LoadThemePreference loads and applies the saved theme and editor font
+// preferences
 func LoadThemePreference(a fyne.App) {
+	components.SetEditorFont(settings.LoadEditorFont(a.Preferences()))
 	mode := a.Preferences().StringWithFallback(ThemePreferenceKey, "system")
 	ApplyTheme(a, mode)
 }
 
+// ApplyEditorFont sets the font of the JSON editors and views, restyling
+// those already open
+func ApplyEditorFont(a fyne.App, f components.EditorFont) {
+	components.SetEditorFont(f)
+	// Setting the theme again refreshes every window with the new font
+	a.Settings().SetTheme(a.Settings().Theme())
+}
+
 // SaveThemePreference saves and applies the theme preference
 func SaveThemePreference(a fyne.App, mode string) {
 	a.Preferences().SetString(ThemePreferenceKey, mode)
@@ -83,3 +96,32 @@ func CreateThemeSelector(a fyne.App) *widget.Select {
 	}
 	return selector
 }
+
+// CreateAppearanceButton creates a button showing a popover to choose the
+// theme and editor font, which are applied and saved as they change
+func CreateAppearanceButton(a fyne.App) *widget.Button {
+	var btn *widget.Button
+	btn = widget.NewButtonWithIcon("", theme.ColorPaletteIcon(), func() {
+		c := fyne.CurrentApp().Driver().CanvasForObject(btn)
+		if c == nil {
+			return
+		}
+		editorFont := settings.NewEditorFontControls(components.CurrentEditorFont(), func(f components.EditorFont) {
+			settings.SaveEditorFont(a.Preferences(), f)
+			ApplyEditorFont(a, f)
+		})
+		content := widget.NewForm(append(
+			[]*widget.FormItem{widget.NewFormItem("Theme", CreateThemeSelector(a))},
+			editorFont.FormItems()...,
+		)...)
+		popup := widget.NewPopUp(container.NewPadded(content), c)
+
+		// Open above the button, which sits in the bottom bar, kept inside
+		// the window
+		size := popup.MinSize()
+		pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(btn)
+		pos = pos.AddXY(btn.Size().Width-size.Width, -size.Height)
+		popup.ShowAtPosition(fyne.NewPos(max(pos.X, 0), max(pos.Y, 0)))
+	})
+	return btn
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/ui/components"
+	"github.com/shhac/grotto/internal/ui/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formWidgets returns the widgets of the form in the top overlay of w, by
+// label.
+func formWidgets(t *testing.T, w fyne.Window) map[string]fyne.CanvasObject {
+	t.Helper()
+	popup, ok := w.Canvas().Overlays().Top().(*widget.PopUp)
+	require.True(t, ok, "a popover is shown")
+	var form *widget.Form
+	var find func(obj fyne.CanvasObject)
+	find = func(obj fyne.CanvasObject) {
+		switch o := obj.(type) {
+		case *widget.Form:
+			form = o
+		case *fyne.Container:
+			for _, child := range o.Objects {
+				find(child)
+			}
+		}
+	}
+	find(popup.Content)
+	require.NotNil(t, form)
+	widgets := make(map[string]fyne.CanvasObject)
+	for _, item := range form.Items {
+		widgets[item.Text] = item.Widget
+	}
+	return widgets
+}
+
+func TestAppearanceButton(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	t.Cleanup(func() { components.SetEditorFont(components.EditorFont{}) })
+
+	app.Preferences().SetBool(settings.PrefEditorMonospace, true)
+	LoadThemePreference(app)
+	assert.True(t, components.CurrentEditorFont().Monospace, "the saved font applies at start")
+
+	btn := CreateAppearanceButton(app)
+	w := test.NewWindow(btn)
+	defer w.Close()
+	test.Tap(btn)
+
+	controls := formWidgets(t, w)
+	require.Contains(t, controls, "Theme")
+	mono := controls["Editor Font"].(*widget.Check)
+	size := controls["Editor Text Size"].(*widget.Select)
+	assert.True(t, mono.Checked)
+	assert.Equal(t, "Medium", size.Selected)
+
+	// Changes apply and are saved at once
+	size.SetSelected("Large")
+	assert.Equal(t, components.EditorFont{Monospace: true, Size: 16}, components.CurrentEditorFont())
+	mono.SetChecked(false)
+	assert.Equal(t, components.EditorFont{Size: 16}, components.CurrentEditorFont())
+	assert.Equal(t, components.EditorFont{Size: 16}, settings.LoadEditorFont(app.Preferences()))
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shhac/grotto/internal/grpc"
+)
+
+// formatTiming describes where the time of a call went, for the response
+// panel's Timing section: when headers and the first message arrived, the
+// total, and what was sent and received. With messages set (streaming
+// calls) every message is listed with its time since the call started.
+func formatTiming(t grpc.CallTiming, messages bool) string {
+	var b strings.Builder
+	var phases []string
+	if t.FirstHeader > 0 {
+		phases = append(phases, "Headers after "+roundDuration(t.FirstHeader))
+	}
+	if t.FirstMessage > 0 {
+		phases = append(phases, "First message after "+roundDuration(t.FirstMessage))
+	}
+	if t.Done() {
+		phases = append(phases, "Total "+roundDuration(t.Total))
+	} else {
+		phases = append(phases, "In progress")
+	}
+	b.WriteString(strings.Join(phases, " · "))
+	fmt.Fprintf(&b, "\nSent %s (%s) · Received %s (%s)",
+		countMessages(t.SentMessages), formatByteSize(t.SentBytes),
+		countMessages(t.ReceivedMessages), formatByteSize(t.ReceivedBytes))
+
+	if messages && len(t.Messages) > 0 {
+		b.WriteString("\n")
+		for _, m := range t.Messages {
+			arrow := "←"
+			if m.Sent {
+				arrow = "→"
+			}
+			fmt.Fprintf(&b, "\n+%-10s %s %s", roundDuration(m.At), arrow, formatByteSize(m.Bytes))
+		}
+		if n := t.SentMessages + t.ReceivedMessages - len(t.Messages); n > 0 {
+			fmt.Fprintf(&b, "\n… and %s more", countMessages(n))
+		}
+	}
+	return b.String()
+}
+
+// roundDuration formats d to a precision that suits its size: microseconds
+// below a millisecond, else tenths of a millisecond below a second, else
+// milliseconds.
+func roundDuration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return d.Round(100 * time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
+
+// countMessages returns "1 message" or "n messages".
+func countMessages(n int) string {
+	if n == 1 {
+		return "1 message"
+	}
+	return fmt.Sprintf("%d messages", n)
+}
+
+// callTimingText formats the timing rec recorded, or returns "" when the
+// connection reported none (gRPC-Web connections have no stats handler).
+func callTimingText(rec *grpc.TimingRecorder, messages bool) string {
+	t, ok := rec.Timing()
+	if !ok {
+		return ""
+	}
+	return formatTiming(t, messages)
+}
+
+// formatAttempts describes the attempts a unary call took under the
+// connection's retry policy, e.g. "succeeded on attempt 2/3", or "" when
+// the first attempt settled it.
+func formatAttempts(a grpc.CallAttempts, failed bool) string {
+	if !a.Retried() {
+		return ""
+	}
+	outcome := "succeeded"
+	if failed {
+		outcome = "failed"
+	}
+	return fmt.Sprintf("%s on attempt %d/%d", outcome, a.Made, a.Max)
+}
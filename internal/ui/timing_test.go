@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTiming(t *testing.T) {
+	unary := grpc.CallTiming{
+		FirstHeader:      40*time.Millisecond + 123*time.Microsecond,
+		FirstMessage:     50 * time.Millisecond,
+		Total:            52 * time.Millisecond,
+		SentMessages:     1,
+		ReceivedMessages: 1,
+		SentBytes:        25,
+		ReceivedBytes:    2048,
+		Messages: []grpc.MessageTiming{
+			{At: 800 * time.Microsecond, Sent: true, Bytes: 25},
+			{At: 50 * time.Millisecond, Bytes: 2048},
+		},
+	}
+	assert.Equal(t, "Headers after 40.1ms · First message after 50ms · Total 52ms\n"+
+		"Sent 1 message (25 B) · Received 1 message (2.0 KB)",
+		formatTiming(unary, false))
+
+	assert.Equal(t, "Headers after 40.1ms · First message after 50ms · Total 52ms\n"+
+		"Sent 1 message (25 B) · Received 1 message (2.0 KB)\n\n"+
+		"+800µs      → 25 B\n"+
+		"+50ms       ← 2.0 KB",
+		formatTiming(unary, true))
+
+	stream := grpc.CallTiming{
+		FirstHeader:      2 * time.Second,
+		ReceivedMessages: 3,
+		ReceivedBytes:    30,
+		Messages:         []grpc.MessageTiming{{At: 2500 * time.Millisecond, Bytes: 10}},
+	}
+	assert.Equal(t, "Headers after 2s · In progress\n"+
+		"Sent 0 messages (0 B) · Received 3 messages (30 B)\n\n"+
+		"+2.5s       ← 10 B\n"+
+		"… and 2 messages more",
+		formatTiming(stream, true))
+}
+
+func TestFormatAttempts(t *testing.T) {
+	assert.Equal(t, "", formatAttempts(grpc.CallAttempts{}, false))
+	assert.Equal(t, "", formatAttempts(grpc.CallAttempts{Made: 1, Max: 3}, true), "not retried")
+	assert.Equal(t, "succeeded on attempt 2/3", formatAttempts(grpc.CallAttempts{Made: 2, Max: 3}, false))
+	assert.Equal(t, "failed on attempt 3/3", formatAttempts(grpc.CallAttempts{Made: 3, Max: 3}, true))
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/shhac/grotto/internal/domain"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// tokenFetches cancels the token commands run for requests, when the user
+// cancels the request or the window closes.
+type tokenFetches struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// context returns the context token commands are run under until stop.
+func (f *tokenFetches) context() context.Context {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ctx == nil {
+		f.ctx, f.cancel = context.WithCancel(context.Background())
+	}
+	return f.ctx
+}
+
+// stop kills the token commands still running; commands run after it get
+// a new context.
+func (f *tokenFetches) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cancel != nil {
+		f.cancel()
+	}
+	f.ctx, f.cancel = nil, nil
+}
+
+// withAuthToken calls next on the UI thread with metadata ready to send.
+// When the request panel's auth is a token command, the command is run
+// (or its cached token reused) off the UI thread first and the token is
+// sent as a bearer authorization header. A failing command blocks the
+// request and shows its stderr; a cancelled one drops it quietly.
+func (w *MainWindow) withAuthToken(metadata map[string]string, next func(map[string]string)) {
+	auth := w.requestPanel.Auth()
+	if auth.Type != domain.AuthCommand {
+		next(metadata)
+		return
+	}
+
+	ctx := w.tokenFetches.context()
+	go func() {
+		token, err := w.tokens.Token(ctx, auth.Command, auth.TTL)
+		fyne.Do(func() {
+			if errors.Is(err, context.Canceled) {
+				w.logger.Info("token command cancelled")
+				return
+			}
+			if err != nil {
+				w.logger.Error("token command failed", slog.Any("error", err))
+				dialog.ShowError(err, w.window)
+				return
+			}
+			next(domain.Auth{Type: domain.AuthBearer, Token: token}.Apply(metadata))
+		})
+	}()
+}
+
+// forgetRejectedToken drops cached command tokens when the server rejects a
+// call as unauthenticated, so the next request fetches a fresh one.
+func (w *MainWindow) forgetRejectedToken(err error) {
+	if grpcstatus.Code(err) == codes.Unauthenticated {
+		w.tokens.Invalidate()
+	}
+}
@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenFetches_Stop(t *testing.T) {
+	var f tokenFetches
+	f.stop() // nothing running yet
+
+	first := f.context()
+	assert.Same(t, first, f.context(), "fetches share a context until stopped")
+	assert.NoError(t, first.Err())
+
+	f.stop()
+	assert.ErrorIs(t, first.Err(), context.Canceled)
+
+	next := f.context()
+	assert.NoError(t, next.Err(), "fetches after a stop run again")
+}
@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"log/slog"
+
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// unknownEnumPaths returns the paths of the enum numbers in a response
+// that desc, its type, does not declare, so the response panel can mark
+// them. The JSON has already been through the invoker, so failures are
+// only logged.
+func (w *MainWindow) unknownEnumPaths(desc protoreflect.MessageDescriptor, jsonMsg string) []string {
+	paths, err := protoconv.UnknownEnumPaths(desc, jsonMsg, w.typeResolver())
+	if err != nil {
+		w.logger.Debug("failed to find unknown enum values", slog.Any("error", err))
+	}
+	return paths
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/protoconv"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// heldResponse is a response waiting, after Use as Request, for the method
+// it will be sent to.
+type heldResponse struct {
+	json string
+	desc protoreflect.MessageDescriptor // Response message type
+}
+
+// handleUseAsRequest loads a response into the request editor. When the
+// selected method takes its own response type as input the response is
+// loaded straight away; otherwise it is held until the next method is
+// selected and mapped onto that method's input type.
+func (w *MainWindow) handleUseAsRequest(respJSON string) {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
+	refClient := w.app.ReflectionClient()
+	if serviceName == "" || methodName == "" || refClient == nil {
+		return
+	}
+	methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		w.logger.Error("failed to get method descriptor", slog.Any("error", err))
+		return
+	}
+
+	held := &heldResponse{json: respJSON, desc: methodDesc.Output()}
+	if methodDesc.Input().FullName() == held.desc.FullName() {
+		w.applyHeldResponse(held, methodDesc.Input())
+		return
+	}
+	w.nextRequest = held
+	dialog.ShowInformation("Use as Request",
+		fmt.Sprintf("Select the method to send this %s to. Its fields are copied into the method's request where they fit.", held.desc.Name()),
+		w.window)
+}
+
+// applyHeldResponse fills the request editor with a held response mapped
+// onto md, listing the fields that could not be carried over.
+func (w *MainWindow) applyHeldResponse(held *heldResponse, md protoreflect.MessageDescriptor) {
+	body, dropped, err := protoconv.MapMessageJSON(held.json, held.desc, md)
+	if err != nil {
+		w.logger.Warn("failed to use response as request", slog.Any("error", err))
+		return
+	}
+	_ = w.state.Request.TextData.Set(prettyJSON(body))
+	w.requestPanel.SyncTextToForm()
+	if len(dropped) > 0 {
+		w.showDroppedFields(held.desc, md, dropped)
+	}
+}
+
+// showDroppedFields lists the fields of a response left out when it was
+// mapped onto a request of another type.
+func (w *MainWindow) showDroppedFields(from, to protoreflect.MessageDescriptor, dropped []protoconv.FieldError) {
+	summary := fmt.Sprintf("%d fields of %s did not fit %s and were left out:", len(dropped), from.Name(), to.Name())
+	if len(dropped) == 1 {
+		summary = fmt.Sprintf("1 field of %s did not fit %s and was left out:", from.Name(), to.Name())
+	}
+	content := container.NewVBox(widget.NewLabel(summary))
+	for _, d := range dropped {
+		lbl := widget.NewLabel("• " + d.Error())
+		lbl.Wrapping = fyne.TextWrapWord
+		content.Add(lbl)
+	}
+
+	d := dialog.NewCustom("Use as Request", "OK", container.NewVScroll(content), w.window)
+	d.Resize(fyne.NewSize(500, 350))
+	d.Show()
+}
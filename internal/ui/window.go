@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -17,43 +18,56 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/controller"
 	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/export"
 	"github.com/shhac/grotto/internal/grpc"
+	"github.com/shhac/grotto/internal/logging"
 	"github.com/shhac/grotto/internal/model"
+	"github.com/shhac/grotto/internal/protoconv"
 	"github.com/shhac/grotto/internal/storage"
+	"github.com/shhac/grotto/internal/tokencmd"
 	"github.com/shhac/grotto/internal/ui/bidi"
 	"github.com/shhac/grotto/internal/ui/browser"
+	"github.com/shhac/grotto/internal/ui/components"
 	uierrors "github.com/shhac/grotto/internal/ui/errors"
+	"github.com/shhac/grotto/internal/ui/form"
 	"github.com/shhac/grotto/internal/ui/history"
+	"github.com/shhac/grotto/internal/ui/logview"
 	"github.com/shhac/grotto/internal/ui/request"
 	"github.com/shhac/grotto/internal/ui/response"
 	"github.com/shhac/grotto/internal/ui/settings"
+	"github.com/shhac/grotto/internal/ui/streamconst"
 	"github.com/shhac/grotto/internal/ui/workspace"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-// AppController defines the interface for app-level operations needed by the UI
+// AppController defines the interface for app-level operations needed by the UI.
+// Calls go through the Session's interfaces; ReflectionClient and Invoker
+// serve the features the controllers do not handle yet.
 type AppController interface {
+	controller.Session
 	State() *model.ApplicationState
 	Logger() *slog.Logger
-	InitializeReflectionClient() error
+	Logs() *logging.RingHandler
+	LogSettings() logging.Settings
+	SetLogSettings(settings logging.Settings) error
+	SetAudit(enabled, payloads bool)
+	AuditPath() string
+	InitializeReflectionClient(ctx context.Context) error
+	InitializeDescriptorSetClient(ctx context.Context, path string) error
+	InitializeProtoSourceClient(ctx context.Context, importPaths []string) error
 	CleanupReflectionClient()
+	Shutdown(cancelStreams, flush func(), timeout time.Duration) error
 	ConnManager() *grpc.ConnectionManager
 	ReflectionClient() *grpc.ReflectionClient
 	Invoker() *grpc.Invoker
 	Storage() storage.Repository
 }
 
-// Preference keys for window state persistence
-const (
-	prefWindowWidth  = "windowWidth"
-	prefWindowHeight = "windowHeight"
-	prefSplitMain    = "splitMain"
-	prefSplitBrowser = "splitBrowser"
-	prefSplitContent = "splitContent"
-)
-
 // MainWindow manages the main application window and its layout.
 type MainWindow struct {
 	window  fyne.Window
@@ -74,26 +88,60 @@ type MainWindow struct {
 	statusBar      *uierrors.StatusBar
 	workspacePanel *workspace.WorkspacePanel
 	historyPanel   *history.HistoryPanel
-	themeSelector  *widget.Select
-
-	// Streaming state (protected by streamMu)
-	streamMu           sync.Mutex
-	clientStreamHandle *grpc.ClientStreamHandle
-	clientStreamCancel context.CancelFunc
-	bidiStreamHandle   *grpc.BidiStreamHandle
-	bidiCancelFunc     context.CancelFunc
-	serverStreamCancel context.CancelFunc
-	unaryCancel        context.CancelFunc
-	connectCancel      context.CancelFunc
+	appearanceBtn  *widget.Button
+	logViewer      *logview.Viewer
+
+	// Calls in progress and the connection attempt; each has its own lock
+	requests   *controller.RequestController
+	connection *controller.ConnectionController
+	bidi       bidiSession
+
+	// Background health checks for the current connection
+	healthMu      sync.Mutex
+	healthMonitor *grpc.HealthMonitor
+
+	// Background schema checks, announcing changes in schemaNotice
+	schemaMu      sync.Mutex
+	schemaWatcher *grpc.SchemaWatcher
+	schemaNotice  *components.Notice
+
+	// Failures of single calls, reported without interrupting
+	toasts *uierrors.ToastStack
 
 	// Layout state
+	layout       windowLayout     // saved on close; split offsets survive panel rebuilds
 	inBidiMode   bool             // avoid unnecessary rebuilds
 	contentSplit *container.Split // request/response vertical split (stored for offset changes)
 	mainSplit    *container.Split // left/right horizontal split (stored for state persistence)
 	browserSplit *container.Split // browser/tabs vertical split (stored for state persistence)
 
-	// Per-method request cache: "service/method" → last JSON text
-	methodRequestCache map[string]string
+	// Request last written for each method, restored when it is selected
+	methodRequests *methodRequests
+	lastTemplate   string        // Body last filled in by applyRequestTemplate
+	nextRequest    *heldResponse // Response to load into the next selected method's request
+	lastResponse   *heldResponse // Last unary response, for building field masks from
+
+	// Startup checklist for the current workspace
+	checklist []domain.ChecklistItem
+
+	// Unsaved change tracking and autosave (main thread only)
+	workspaceName string           // Workspace last loaded or saved
+	tracker       workspaceTracker // Hashes of the saved and autosaved state
+	lastAutosave  time.Time
+	trackingStop  chan struct{}
+
+	// Cached tokens from auth token commands, and the commands still
+	// running for requests
+	tokens       *tokencmd.Runner
+	tokenFetches tokenFetches
+
+	// History entries still being saved, waited for on close
+	historyWrites sync.WaitGroup
+	closing       bool // Shutting down after the window was closed (main thread only)
+
+	// Calls and bidi receivers that record history when they end, waited
+	// for on close once cancelled
+	calls sync.WaitGroup
 }
 
 // NewMainWindow creates a new main window with the application layout.
@@ -102,31 +150,40 @@ type MainWindow struct {
 //   - Right side: Request Panel (top), Response Panel (middle), Status Bar (bottom)
 func NewMainWindow(fyneApp fyne.App, app AppController) *MainWindow {
 	// Create the window
-	window := fyneApp.NewWindow("Grotto - gRPC Client")
+	window := fyneApp.NewWindow(windowTitle)
 
 	// Create connection state
 	connState := model.NewConnectionUIState()
 
 	mw := &MainWindow{
-		window:             window,
-		fyneApp:            fyneApp,
-		state:              app.State(),
-		logger:             app.Logger(),
-		app:                app,
-		connState:          connState,
-		methodRequestCache: make(map[string]string),
+		window:         window,
+		fyneApp:        fyneApp,
+		state:          app.State(),
+		logger:         app.Logger(),
+		app:            app,
+		connState:      connState,
+		layout:         loadWindowLayout(fyneApp.Preferences()),
+		methodRequests: newMethodRequests(maxMethodRequests),
+		tokens:         tokencmd.NewRunner(),
+		requests:       controller.NewRequestController(app, app.Logger()),
+		connection:     controller.NewConnectionController(),
 	}
 
 	// Create real UI components
 	mw.connectionBar = browser.NewConnectionBar(connState, window, app.Storage())
 	mw.serviceBrowser = browser.NewServiceBrowser(mw.state.Services, connState.State)
 	mw.requestPanel = request.NewRequestPanel(mw.state.Request, mw.logger)
+	mw.requestPanel.SetFormMaxDepth(fyneApp.Preferences().IntWithFallback(settings.PrefFormMaxDepth, form.DefaultMaxDepth))
 	mw.responsePanel = response.NewResponsePanel(mw.state.Response, window)
+	mw.responsePanel.StreamingWidget().SetMaxMessages(fyneApp.Preferences().IntWithFallback(settings.PrefStreamMessages, streamconst.MaxStreamMessages))
 	mw.bidiPanel = bidi.NewBidiStreamPanel(window)
 	mw.statusBar = uierrors.NewStatusBar(connState)
+	mw.schemaNotice = components.NewNotice()
+	mw.toasts = uierrors.NewToastStack()
 	mw.workspacePanel = workspace.NewWorkspacePanel(app.Storage(), app.Logger(), window)
 	mw.historyPanel = history.NewHistoryPanel(app.Storage(), app.Logger(), window)
-	mw.themeSelector = CreateThemeSelector(fyneApp)
+	mw.appearanceBtn = CreateAppearanceButton(fyneApp)
+	mw.logViewer = logview.NewViewer(fyneApp, app.Logs())
 
 	// Wire up callbacks
 	mw.wireCallbacks()
@@ -140,44 +197,102 @@ func NewMainWindow(fyneApp fyne.App, app AppController) *MainWindow {
 	// Set up keyboard shortcuts
 	mw.setupKeyboardShortcuts()
 
-	// Cancel all streams on window close and persist window state
-	window.SetCloseIntercept(func() {
-		mw.saveWindowState()
-		mw.cancelAllStreams()
-		window.Close()
-	})
+	// Shut down cleanly on window close, persisting window state
+	window.SetCloseIntercept(mw.handleClose)
+
+	// Load JSON bodies and descriptor sets dropped on the window
+	window.SetOnDropped(mw.handleDrop)
 
 	// Restore saved window size or use defaults
 	mw.restoreWindowState()
 
+	// Track unsaved workspace changes, offering any left by a crash
+	mw.startWorkspaceTracking()
+	mw.offerAutosaveRestore()
+
 	return mw
 }
 
-// saveWindowState persists window size and splitter offsets to Fyne Preferences.
+// shutdownTimeout bounds each step of shutting down that may block, so a
+// server that never answers cannot keep the window from closing.
+const shutdownTimeout = 2 * time.Second
+
+// handleClose closes the window once the app has shut down, asking first
+// when a stream is still open.
+func (w *MainWindow) handleClose() {
+	if w.closing {
+		return
+	}
+	if !w.hasOpenStream() {
+		w.shutdownAndClose()
+		return
+	}
+	dialog.ShowConfirm("Quit Grotto", "A stream is still open. Quitting cancels it.\n\nQuit anyway?", func(quit bool) {
+		if quit {
+			w.shutdownAndClose()
+		}
+	}, w.window)
+}
+
+// shutdownAndClose saves the window state, then shuts the app down off the
+// main thread, so a slow server cannot freeze the window, and closes the
+// window when done.
+func (w *MainWindow) shutdownAndClose() {
+	w.closing = true
+	w.saveWindowState()
+	go func() {
+		if err := w.app.Shutdown(w.cancelAndWaitStreams, w.flushWrites, shutdownTimeout); err != nil {
+			w.logger.Warn("shutdown incomplete", slog.Any("error", err))
+		}
+		fyne.Do(w.window.Close)
+	}()
+}
+
+// flushWrites autosaves any unsaved workspace change and waits for history
+// entries still being saved. Must not be called on the main thread.
+func (w *MainWindow) flushWrites() {
+	fyne.DoAndWait(w.stopWorkspaceTracking)
+	w.historyWrites.Wait()
+}
+
+// hasOpenStream reports whether a server, client or bidi stream is open.
+func (w *MainWindow) hasOpenStream() bool {
+	return w.requests.StreamOpen() || w.bidi.current() != nil
+}
+
+// saveWindowState persists window size, splitter offsets, and the
+// connection address to Fyne Preferences.
 func (w *MainWindow) saveWindowState() {
-	prefs := w.fyneApp.Preferences()
+	w.captureLayout()
 	size := w.window.Canvas().Size()
-	prefs.SetFloat(prefWindowWidth, float64(size.Width))
-	prefs.SetFloat(prefWindowHeight, float64(size.Height))
+	w.layout.Width, w.layout.Height = size.Width, size.Height
+	w.layout.Address = w.connectionBar.GetAddress()
+	w.layout.sanitized().save(w.fyneApp.Preferences())
+}
+
+// restoreWindowState restores the window size and last connection address
+// loaded into w.layout. Split offsets are applied as the splits are built.
+func (w *MainWindow) restoreWindowState() {
+	w.window.Resize(fyne.NewSize(w.layout.Width, w.layout.Height))
+	if w.layout.Address != "" {
+		w.connectionBar.RestoreAddress(w.layout.Address)
+	}
+}
+
+// captureLayout records the offsets of the splits on screen. The request /
+// response split is off screen in bidi mode, so its last offset is kept.
+func (w *MainWindow) captureLayout() {
 	if w.mainSplit != nil {
-		prefs.SetFloat(prefSplitMain, w.mainSplit.Offset)
+		w.layout.SplitMain = w.mainSplit.Offset
 	}
 	if w.browserSplit != nil {
-		prefs.SetFloat(prefSplitBrowser, w.browserSplit.Offset)
+		w.layout.SplitBrowser = w.browserSplit.Offset
 	}
-	if w.contentSplit != nil {
-		prefs.SetFloat(prefSplitContent, w.contentSplit.Offset)
+	if w.contentSplit != nil && !w.inBidiMode {
+		w.layout.SplitContent = w.contentSplit.Offset
 	}
 }
 
-// restoreWindowState restores window size from Fyne Preferences or uses defaults.
-func (w *MainWindow) restoreWindowState() {
-	prefs := w.fyneApp.Preferences()
-	width := float32(prefs.FloatWithFallback(prefWindowWidth, 1200))
-	height := float32(prefs.FloatWithFallback(prefWindowHeight, 800))
-	w.window.Resize(fyne.NewSize(width, height))
-}
-
 // getRequestTimeout returns the configured request timeout from preferences.
 func (w *MainWindow) getRequestTimeout() time.Duration {
 	seconds := w.fyneApp.Preferences().FloatWithFallback(settings.PrefRequestTimeout, 30)
@@ -187,38 +302,92 @@ func (w *MainWindow) getRequestTimeout() time.Duration {
 // wireCallbacks sets up all the event handlers and connects components
 func (w *MainWindow) wireCallbacks() {
 	// Connection flow
-	w.connectionBar.SetOnConnect(func(address string, tlsSettings domain.TLSSettings) {
-		w.handleConnect(address, tlsSettings)
+	w.connectionBar.SetOnConnect(func(conn domain.Connection) {
+		w.handleConnect(conn)
 	})
 
 	w.connectionBar.SetOnDisconnect(func() {
 		w.handleDisconnect()
 	})
 
+	// Keep alive applies to the live connection as well as the next one
+	w.connectionBar.SetOnKeepAliveChange(func(enabled bool) {
+		w.app.ConnManager().SetAutoReconnect(enabled)
+	})
+
+	w.connectionBar.SetOnRefreshSchema(w.handleRefreshSchema)
+	w.connectionBar.SetOnCancelConnect(w.handleCancelConnect)
+	w.connectionBar.SetOnShowCertificate(w.showServerCertificates)
+	w.connectionBar.SetOnRename(w.handleRename)
+
+	// Link state of the underlying transport (lost, reconnecting, ready)
+	w.app.ConnManager().SetLinkCallback(w.handleLinkChange)
+
 	// Method selection
 	w.serviceBrowser.SetOnMethodSelect(func(service domain.Service, method domain.Method) {
 		w.handleMethodSelect(service, method)
 	})
 
+	// Method context menu
+	w.serviceBrowser.SetMethodActions(methodActions{w: w})
+
+	// Methods pinned under Favorites, remembered per server
+	w.serviceBrowser.SetOnFavoritesChange(w.saveFavorites)
+
 	// Error service selection — show reflection error in response panel
 	w.serviceBrowser.SetOnServiceError(func(service domain.Service) {
 		_ = w.state.Response.Error.Set(
 			fmt.Sprintf("Service %s failed reflection:\n%s", service.FullName, service.Error))
 	})
+	w.serviceBrowser.SetOnDiagnose(w.showServiceDiagnostics)
+
+	// Service tree layout, remembered across launches
+	w.serviceBrowser.SetGroupByPackage(w.fyneApp.Preferences().Bool(settings.PrefGroupByPackage))
+	w.serviceBrowser.SetOnGroupByPackageChange(func(grouped bool) {
+		w.fyneApp.Preferences().SetBool(settings.PrefGroupByPackage, grouped)
+	})
 
 	// Send request (unary/server streaming)
 	w.requestPanel.SetOnSend(func(jsonStr string, metadata map[string]string) {
-		w.handleSendRequest(jsonStr, metadata)
+		w.withAuthToken(metadata, func(metadata map[string]string) {
+			w.handleSendRequest(jsonStr, metadata)
+		})
+	})
+
+	// Pre-send check: list problems and let the user send anyway
+	w.requestPanel.SetOnSendProblems(func(problems []protoconv.FieldError, sendAnyway, sendWithoutUnknown func()) {
+		uierrors.ShowRequestProblems(problems, w.window, sendAnyway, sendWithoutUnknown)
+	})
+
+	// Load a response into the request editor, mapped onto the input type
+	// of the next method when it differs
+	w.responsePanel.SetOnUseAsRequest(w.handleUseAsRequest)
+
+	// Copy the request as an equivalent grpcurl command
+	w.requestPanel.SetOnCopyGrpcurl(func(body string, messages []string, metadata map[string]string) {
+		w.copyAsGrpcurl(body, messages, metadata)
+	})
+
+	// Saved requests library for the selected method
+	w.requestPanel.SetOnSaveRequest(func(body string, metadata map[string]string) {
+		w.handleSaveRequest(body, metadata)
+	})
+	w.requestPanel.SetOnDeleteSavedRequest(func(name string) {
+		w.handleDeleteSavedRequest(name)
 	})
 
 	// Client streaming: send message
 	w.requestPanel.SetOnStreamSend(func(jsonStr string, metadata map[string]string) {
-		w.handleClientStreamSend(jsonStr, metadata)
+		w.withAuthToken(metadata, func(metadata map[string]string) {
+			w.handleClientStreamSend(jsonStr, metadata)
+		})
 	})
 
 	// Client streaming: finish and get response
 	w.requestPanel.SetOnStreamEnd(func(metadata map[string]string) {
-		w.handleClientStreamFinish(metadata)
+		w.withAuthToken(metadata, func(metadata map[string]string) {
+			w.handleClientStreamFinish(metadata)
+		})
 	})
 
 	// Client streaming: abort
@@ -235,8 +404,11 @@ func (w *MainWindow) wireCallbacks() {
 
 	w.workspacePanel.SetOnLoad(func(workspace domain.Workspace) {
 		w.applyWorkspaceState(workspace)
+		w.markWorkspaceSaved(workspace)
 	})
 
+	w.workspacePanel.SetOnSaved(w.markWorkspaceSaved)
+
 	// History: click to load (without sending), or replay (connect + load + send)
 	w.historyPanel.SetOnSelect(func(entry domain.HistoryEntry) {
 		w.handleHistoryEntry(entry, false)
@@ -246,24 +418,37 @@ func (w *MainWindow) wireCallbacks() {
 	})
 }
 
-// formatByteSize returns a human-readable byte count (e.g., "1.2 KB", "3.4 MB").
-func formatByteSize(bytes int) string {
-	const (
-		kb = 1024
-		mb = kb * 1024
-	)
-	switch {
-	case bytes >= mb:
-		return fmt.Sprintf("%.1f MB", float64(bytes)/float64(mb))
-	case bytes >= kb:
-		return fmt.Sprintf("%.1f KB", float64(bytes)/float64(kb))
-	default:
-		return fmt.Sprintf("%d B", bytes)
+// copyAsGrpcurl puts the grpcurl command reproducing the current request on
+// the clipboard. messages is nil unless the method is client streaming.
+func (w *MainWindow) copyAsGrpcurl(body string, messages []string, metadata map[string]string) {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
+	if serviceName == "" || methodName == "" {
+		return
 	}
+
+	conn := w.connectionBar.GetConnection()
+	if address, _ := w.state.CurrentServer.Get(); address != "" {
+		conn.Address = address
+	}
+
+	w.window.Clipboard().SetContent(export.GrpcurlCommand(export.GrpcurlRequest{
+		Address:   conn.Address,
+		TLS:       conn.TLS,
+		Protoset:  conn.DescriptorSetFile,
+		Authority: conn.Authority,
+		UserAgent: conn.UserAgent,
+		Method:    serviceName + "/" + methodName,
+		Metadata:  metadata,
+		Body:      body,
+		Streaming: messages != nil,
+		Messages:  messages,
+	}))
 }
 
 // prettyJSON returns the pretty-printed form of a JSON string, or the
-// original string if it cannot be indented.
+// original string if it cannot be indented. The text is re-indented, never
+// decoded, so a 64-bit integer sent as a number keeps every digit.
 func prettyJSON(s string) string {
 	var buf bytes.Buffer
 	if err := json.Indent(&buf, []byte(s), "", "  "); err == nil {
@@ -277,17 +462,62 @@ func convertMetadataToMap(md metadata.MD) map[string]string {
 	result := make(map[string]string)
 	for key, values := range md {
 		if len(values) > 0 {
-			result[key] = values[0]
+			result[key] = grpc.FormatMetadataValue(key, values[0])
 			for i := 1; i < len(values); i++ {
-				result[key] += ", " + values[i]
+				result[key] += ", " + grpc.FormatMetadataValue(key, values[i])
 			}
 		}
 	}
 	return result
 }
 
-// handleConnect establishes a connection and lists services
-func (w *MainWindow) handleConnect(address string, tlsSettings domain.TLSSettings) {
+// handleConnect establishes a connection and lists services, first asking
+// before replacing an active connection to the same address.
+func (w *MainWindow) handleConnect(cfg domain.Connection) {
+	if active, ok := w.activeConnection(); ok && active.Address == cfg.Address {
+		dialog.ShowConfirm("Already Connected",
+			"There is already an active connection to "+active.DisplayName()+".\n\n"+
+				"Connecting again replaces it and cancels any calls in progress. Connect anyway?",
+			func(ok bool) {
+				if ok {
+					w.connect(cfg)
+				}
+			}, w.window)
+		return
+	}
+	w.connect(cfg)
+}
+
+// activeConnection returns the settings of the current connection, with
+// its display name, and whether there is one.
+func (w *MainWindow) activeConnection() (domain.Connection, bool) {
+	state, _ := w.connState.State.Get()
+	address, _ := w.state.CurrentServer.Get()
+	if state != "connected" || address == "" {
+		return domain.Connection{}, false
+	}
+	conn := w.connectionBar.GetConnection()
+	conn.Address = address
+	return conn, true
+}
+
+// handleRename shows a connection's new display name in the status bar,
+// and saves it with the recent connection.
+func (w *MainWindow) handleRename(name string) {
+	conn, ok := w.activeConnection()
+	if !ok {
+		return
+	}
+	w.logger.Info("connection renamed", slog.String("address", conn.Address), slog.String("name", name))
+	_ = w.connState.Message.Set("Connected to " + conn.DisplayName())
+	go w.connectionBar.SaveConnection(conn)
+}
+
+// connect establishes a connection and lists services
+func (w *MainWindow) connect(cfg domain.Connection) {
+	address := cfg.Address
+	displayName := cfg.DisplayName()
+
 	// Capture currently selected method before connecting — used to restore
 	// the request panel if the new server has a matching service/method.
 	prevService, _ := w.state.SelectedService.Get()
@@ -299,46 +529,90 @@ func (w *MainWindow) handleConnect(address string, tlsSettings domain.TLSSetting
 	w.requestPanel.SetEnabled(false)
 
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), w.getRequestTimeout())
+		ctx, cancel := w.connection.Begin(w.getRequestTimeout())
 		defer cancel()
-		w.streamMu.Lock()
-		w.connectCancel = cancel
-		w.streamMu.Unlock()
 
 		// Update UI state (bindings are thread-safe)
 		_ = w.connState.State.Set("connecting")
-		_ = w.connState.Message.Set("Connecting to " + address)
-
-		// Connect
-		cfg := domain.Connection{
-			Address: address,
-			TLS:     tlsSettings,
+		_ = w.connState.Message.Set("Connecting to " + displayName)
+		_ = w.connState.Link.Set("") // native connections report their own
+		w.stopHealthMonitor()
+		w.stopSchemaWatcher()
+
+		// A cancelled connect is abandoned quietly; anything else, including
+		// the timeout, is reported
+		fail := func(msg string, err error) {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				w.abortConnect(address)
+				return
+			}
+			w.failConnect(cfg, msg, err)
 		}
 
+		// Connect
 		if err := w.app.ConnManager().Connect(ctx, cfg); err != nil {
-			w.failConnect(address, tlsSettings, "Failed to connect", err)
+			fail("Failed to connect", err)
 			return
 		}
 
-		// Initialize reflection client
-		if err := w.app.InitializeReflectionClient(); err != nil {
-			w.failConnect(address, tlsSettings, "Failed to initialize reflection", err)
+		// Initialize the descriptor source: a FileDescriptorSet or .proto
+		// sources when configured (for servers with reflection disabled),
+		// else reflection
+		if cfg.DescriptorSetFile != "" {
+			_ = w.connState.Message.Set("Loading " + filepath.Base(cfg.DescriptorSetFile))
+			if err := w.app.InitializeDescriptorSetClient(ctx, cfg.DescriptorSetFile); err != nil {
+				fail("Failed to load descriptor set", err)
+				return
+			}
+		} else if len(cfg.ProtoImportPaths) > 0 {
+			_ = w.connState.Message.Set("Compiling protos in " + filepath.Base(cfg.ProtoImportPaths[0]))
+			if err := w.app.InitializeProtoSourceClient(ctx, cfg.ProtoImportPaths); err != nil {
+				fail("Failed to compile protos", err)
+				return
+			}
+		} else if err := w.app.InitializeReflectionClient(ctx); err != nil {
+			fail("Failed to initialize reflection", err)
 			return
 		}
-
-		// List services
-		services, err := w.app.ReflectionClient().ListServices(ctx)
+		w.configureInvoker(cfg)
+
+		// List services. gRPC-Web proxies rarely expose reflection (it needs
+		// bidi streaming), so a listing failure there leaves the connection
+		// usable with an empty service list instead of failing the connect.
+		// Services fill the tree as they resolve, so a slow server shows
+		// what it has while the rest load.
+		_ = w.connState.Message.Set("Listing services on " + displayName)
+		w.app.ReflectionClient().SetOnProgress(func(resolved, total int) {
+			_ = w.connState.Message.Set(fmt.Sprintf("Listing services (%d/%d resolved)", resolved, total))
+		})
+		feed := newServiceFeed(w.state.Services, func() {
+			fyne.Do(w.serviceBrowser.Refresh)
+		})
+		var reflectionErr error
+		services, err := w.app.ReflectionClient().ListServicesFunc(ctx, feed.add)
+		if feed.stop() && err != nil {
+			_ = w.state.Services.Set([]interface{}{})
+		}
 		if err != nil {
-			w.failConnect(address, tlsSettings, "Failed to list services", err)
-			return
+			if errors.Is(ctx.Err(), context.Canceled) {
+				w.abortConnect(address)
+				return
+			}
+			if !cfg.Transport.IsWeb() {
+				w.failConnect(cfg, "Failed to list services", err)
+				return
+			}
+			w.logger.Warn("reflection unavailable over gRPC-Web",
+				slog.String("address", address),
+				slog.Any("error", err),
+			)
+			reflectionErr = err
+			services = nil
 		}
+		w.applyTypeResolver()
 
 		// Update state with services (bindings are thread-safe)
-		servicesInterface := make([]interface{}, len(services))
-		for i, svc := range services {
-			servicesInterface[i] = svc
-		}
-		_ = w.state.Services.Set(servicesInterface)
+		_ = w.state.Services.Set(serviceItems(services))
 
 		// Update connection state (bindings are thread-safe)
 		_ = w.state.CurrentServer.Set(address)
@@ -352,10 +626,27 @@ func (w *MainWindow) handleConnect(address string, tlsSettings domain.TLSSetting
 				errorCount++
 			}
 		}
-		statusMsg := "Connected to " + address
-		if errorCount > 0 {
+		statusMsg := "Connected to " + displayName
+		if cfg.Transport.IsWeb() {
+			statusMsg += " via " + cfg.Transport.String()
+		}
+		source := "server reflection"
+		if rc := w.app.ReflectionClient(); rc != nil && rc.FromSchemaCache() {
+			source = "server reflection (cached)"
+		}
+		if cfg.DescriptorSetFile != "" {
+			source = filepath.Base(cfg.DescriptorSetFile)
+			statusMsg += " (descriptors from " + source + ")"
+		} else if len(cfg.ProtoImportPaths) > 0 {
+			source = "protos in " + filepath.Base(cfg.ProtoImportPaths[0])
+			statusMsg += " (" + source + ")"
+		}
+		if reflectionErr != nil {
+			source = "server reflection (unavailable)"
+			statusMsg += " (reflection unavailable)"
+		} else if errorCount > 0 {
 			statusMsg = fmt.Sprintf("Connected to %s (%d services, %d with errors)",
-				address, len(services), errorCount)
+				displayName, len(services), errorCount)
 		}
 		_ = w.connState.Message.Set(statusMsg)
 
@@ -367,10 +658,18 @@ func (w *MainWindow) handleConnect(address string, tlsSettings domain.TLSSetting
 		// Save to recent connections
 		w.connectionBar.SaveConnection(cfg)
 
+		w.startHealthMonitor()
+		w.startSchemaWatcher()
+		w.loadMethodStats()
+		w.loadFavorites()
+
 		// Refresh the service browser and reconcile request panel (must be on main thread)
 		fyne.Do(func() {
+			w.serviceBrowser.SetSource(source)
 			w.serviceBrowser.Refresh()
 			w.requestPanel.SetEnabled(true)
+			w.requestPanel.SetAuth(cfg.Auth)
+			w.requestPanel.SetDefaultMetadata(cfg.DefaultMetadata)
 
 			// Check if the previously selected method exists on the new server
 			if prevService != "" && prevMethod != "" && w.hasMethod(services, prevService, prevMethod) {
@@ -386,16 +685,7 @@ func (w *MainWindow) handleConnect(address string, tlsSettings domain.TLSSetting
 				}
 			} else if prevService != "" || prevMethod != "" {
 				// No match — clear the stale request panel
-				w.requestPanel.SetMethod("", nil)
-				w.requestPanel.SetMetadata(nil)
-				w.requestPanel.SetSendEnabled(false)
-				_ = w.state.SelectedService.Set("")
-				_ = w.state.SelectedMethod.Set("")
-				_ = w.state.Response.TextData.Set("")
-				_ = w.state.Response.Error.Set("")
-				_ = w.state.Response.Duration.Set("")
-				_ = w.state.Response.Size.Set("")
-				w.responsePanel.ClearResponseMetadata()
+				w.clearSelection()
 			}
 
 			w.serviceBrowser.FocusTree()
@@ -403,20 +693,186 @@ func (w *MainWindow) handleConnect(address string, tlsSettings domain.TLSSetting
 	}()
 }
 
+// handleLinkChange mirrors transport link transitions into the connection
+// UI state. When a lost link comes back, the server may have restarted with
+// different services, so the service list is fetched again.
+func (w *MainWindow) handleLinkChange(status grpc.LinkStatus) {
+	_ = w.connState.LinkSince.Set(status.Since)
+	_ = w.connState.Link.Set(linkStateName(status.State))
+
+	if status.Reconnected {
+		go w.refreshServices()
+	}
+}
+
+// linkStateName converts a connectivity state to the kebab-case name used
+// by ConnectionUIState.Link, e.g. TRANSIENT_FAILURE to "transient-failure".
+func linkStateName(state connectivity.State) string {
+	return strings.ReplaceAll(strings.ToLower(state.String()), "_", "-")
+}
+
+// refreshServices re-lists services over reflection after a reconnect.
+// Descriptor sets and .proto sources are local and unaffected by the
+// server, so they are left alone, as is the current list when the server
+// cannot be listed.
+func (w *MainWindow) refreshServices() {
+	if w.connectionBar.GetDescriptorSet() != "" || len(w.connectionBar.GetProtoImportPaths()) > 0 {
+		return
+	}
+	count, diff, err := w.reloadServices()
+	if err != nil {
+		w.logger.Warn("failed to refresh services after reconnect", slog.Any("error", err))
+		return
+	}
+	w.logger.Info("services refreshed after reconnect",
+		slog.Int("service_count", count),
+		slog.String("changes", diff.Summary()))
+}
+
+// handleRefreshSchema re-fetches the schema of the connected server,
+// dropping its schema cache entry first so reflection runs again.
+// Descriptor sets and .proto sources are reloaded from disk. The selected
+// method stays selected if the server still has it.
+func (w *MainWindow) handleRefreshSchema() {
+	go func() {
+		if rc := w.app.ReflectionClient(); rc != nil {
+			if err := rc.InvalidateSchemaCache(); err != nil {
+				w.logger.Warn("failed to invalidate schema cache", slog.Any("error", err))
+			}
+		}
+
+		conn := w.connectionBar.GetConnection()
+		conn.Address = w.app.ConnManager().Address()
+		count, diff, err := w.reloadServices()
+		if err != nil {
+			w.logger.Warn("failed to refresh schema", slog.Any("error", err))
+			_ = w.connState.Message.Set("Failed to refresh schema: " + err.Error())
+			return
+		}
+		w.logger.Info("schema refreshed",
+			slog.Int("service_count", count),
+			slog.String("changes", diff.Summary()))
+		_ = w.connState.Message.Set(fmt.Sprintf("Connected to %s (schema refreshed, %d services, %s)", conn.DisplayName(), count, diff.Summary()))
+	}()
+}
+
+// reloadServices re-creates the descriptor source for the current
+// connection and lists its services into the service browser, returning
+// how many there are and how they differ from the ones shown before. The
+// current list is kept when listing fails.
+func (w *MainWindow) reloadServices() (int, domain.SchemaDiff, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.getRequestTimeout())
+	defer cancel()
+
+	cfg := w.connectionBar.GetConnection()
+	var err error
+	switch {
+	case cfg.DescriptorSetFile != "":
+		err = w.app.InitializeDescriptorSetClient(ctx, cfg.DescriptorSetFile)
+	case len(cfg.ProtoImportPaths) > 0:
+		err = w.app.InitializeProtoSourceClient(ctx, cfg.ProtoImportPaths)
+	default:
+		err = w.app.InitializeReflectionClient(ctx)
+	}
+	if err != nil {
+		return 0, domain.SchemaDiff{}, err
+	}
+	w.configureInvoker(cfg)
+
+	services, err := w.app.ReflectionClient().ListServices(ctx)
+	if err != nil {
+		return 0, domain.SchemaDiff{}, err
+	}
+	w.applyTypeResolver()
+	diff := domain.DiffServices(w.currentServices(), services)
+
+	_ = w.state.Services.Set(serviceItems(services))
+
+	fyne.Do(func() {
+		w.schemaNotice.Hide()
+		w.serviceBrowser.Refresh()
+		w.reconcileSelection(diff, services)
+	})
+	return len(services), diff, nil
+}
+
+// applyTypeResolver hands the message types of the listed schema to the
+// invoker and request panel, which pack and expand google.protobuf.Any
+// values with them.
+func (w *MainWindow) applyTypeResolver() {
+	types := w.app.ReflectionClient().TypeResolver()
+	w.app.Invoker().SetTypeResolver(types)
+	fyne.Do(func() {
+		w.requestPanel.SetTypeResolver(types)
+	})
+}
+
+// typeResolver returns the message types of the current connection, or nil
+// when there is none.
+func (w *MainWindow) typeResolver() *protoconv.TypeResolver {
+	if inv := w.app.Invoker(); inv != nil {
+		return inv.TypeResolver()
+	}
+	return nil
+}
+
 // failConnect handles a connection-phase error by logging, updating UI state,
 // and showing a gRPC error dialog with a retry option.
-func (w *MainWindow) failConnect(address string, tls domain.TLSSettings, msg string, err error) {
+func (w *MainWindow) failConnect(cfg domain.Connection, msg string, err error) {
 	w.logger.Error(msg, slog.Any("error", err))
 	_ = w.connState.State.Set("error")
 	_ = w.connState.Message.Set(msg + ": " + err.Error())
 	fyne.Do(func() {
 		w.requestPanel.SetEnabled(true)
-		uierrors.ShowGRPCError(err, w.window, func() {
-			w.handleConnect(address, tls)
-		})
+		retry := func() { w.handleConnect(cfg) }
+		var compileErr *grpc.ProtoCompileError
+		if errors.As(err, &compileErr) {
+			uierrors.ShowProtoCompileErrors(compileErr.Errors, w.window, retry)
+			return
+		}
+		uierrors.ShowGRPCError(err, w.window, retry)
 	})
 }
 
+// handleCancelConnect abandons the connection attempt in progress. The
+// connect goroutine sees its context cancelled and cleans up.
+func (w *MainWindow) handleCancelConnect() {
+	if w.connection.Cancel() {
+		_ = w.connState.Message.Set("Cancelling...")
+	}
+}
+
+// abortConnect tears down whatever a cancelled connection attempt set up,
+// leaving the app disconnected without reporting an error.
+func (w *MainWindow) abortConnect(address string) {
+	w.logger.Info("connection cancelled", slog.String("address", address))
+	w.app.CleanupReflectionClient()
+	if err := w.app.ConnManager().Disconnect(); err != nil {
+		w.logger.Warn("failed to close cancelled connection", slog.Any("error", err))
+	}
+	_ = w.connState.State.Set("disconnected")
+	_ = w.connState.Message.Set("Connection cancelled")
+	fyne.Do(func() {
+		w.requestPanel.SetEnabled(true)
+	})
+}
+
+// clearSelection deselects the method whose request panel and response
+// no longer apply, e.g. because the server no longer has it. Must be
+// called on the main thread.
+func (w *MainWindow) clearSelection() {
+	w.requestPanel.SetMethod("", nil)
+	w.requestPanel.SetMetadata(nil)
+	w.requestPanel.SetSendEnabled(false)
+	_ = w.state.SelectedService.Set("")
+	_ = w.state.SelectedMethod.Set("")
+	_ = w.state.Response.TextData.Set("")
+	_ = w.state.Response.Error.Set("")
+	_ = w.state.Response.Duration.Set("")
+	_ = w.state.Response.Size.Set("")
+	w.responsePanel.ClearResponseMetadata()
+}
+
 // hasMethod returns true if the given service/method pair exists in the services list.
 func (w *MainWindow) hasMethod(services []domain.Service, serviceName, methodName string) bool {
 	for _, svc := range services {
@@ -432,45 +888,22 @@ func (w *MainWindow) hasMethod(services []domain.Service, serviceName, methodNam
 	return false
 }
 
-// cancelAllStreams cancels all active stream operations and clears their handles.
-// Cancel funcs are called outside the lock to avoid potential deadlocks.
+// cancelAllStreams cancels the connection attempt, every call in
+// progress, and token commands run for calls not yet made.
 func (w *MainWindow) cancelAllStreams() {
-	w.streamMu.Lock()
-	connCancel := w.connectCancel
-	w.connectCancel = nil
-	unaryCancel := w.unaryCancel
-	w.unaryCancel = nil
-	serverCancel := w.serverStreamCancel
-	w.serverStreamCancel = nil
-	bidiCancel := w.bidiCancelFunc
-	w.bidiCancelFunc = nil
-	w.bidiStreamHandle = nil
-	clientCancel := w.clientStreamCancel
-	w.clientStreamCancel = nil
-	clientHandle := w.clientStreamHandle
-	w.clientStreamHandle = nil
-	w.streamMu.Unlock()
+	w.tokenFetches.stop()
+	w.connection.Cancel()
+	w.requests.CancelAll()
+	w.bidi.stop()
+}
 
-	// Call cancel funcs outside the lock
-	if connCancel != nil {
-		connCancel()
-	}
-	if unaryCancel != nil {
-		unaryCancel()
-	}
-	if serverCancel != nil {
-		serverCancel()
-	}
-	if bidiCancel != nil {
-		bidiCancel()
-	}
-	if clientCancel != nil {
-		clientCancel()
-	}
-	if clientHandle != nil {
-		// CloseAndReceive blocks, so run in goroutine
-		go clientHandle.CloseAndReceive()
-	}
+// cancelAndWaitStreams cancels every call in progress and waits until
+// each has ended and queued its history entry, so flushWrites saves it.
+// Must not be called on the main thread.
+func (w *MainWindow) cancelAndWaitStreams() {
+	w.cancelAllStreams()
+	w.requests.Wait()
+	w.calls.Wait()
 }
 
 // handleDisconnect closes the connection
@@ -482,6 +915,9 @@ func (w *MainWindow) handleDisconnect() {
 	}
 
 	go func() {
+		w.stopHealthMonitor()
+		w.stopSchemaWatcher()
+
 		// Clean up reflection client
 		w.app.CleanupReflectionClient()
 
@@ -501,16 +937,22 @@ func (w *MainWindow) handleDisconnect() {
 		_ = w.state.SelectedService.Set("")
 		_ = w.state.SelectedMethod.Set("")
 		w.requestPanel.SetSendEnabled(false)
-		w.methodRequestCache = make(map[string]string)
+		w.methodRequests.Clear()
+		w.nextRequest = nil
+		w.lastResponse = nil
 
 		// Update connection state to reflect disconnection
+		_ = w.connState.Link.Set("")
 		_ = w.connState.State.Set("disconnected")
 		_ = w.connState.Message.Set("Disconnected")
 
 		// Refresh the service browser to clear the tree (must be on main thread)
 		fyne.Do(func() {
+			w.serviceBrowser.SetSource("")
 			w.serviceBrowser.Refresh()
 		})
+		w.loadMethodStats()
+		w.loadFavorites()
 
 		w.logger.Info("disconnected")
 	}()
@@ -526,19 +968,20 @@ func (w *MainWindow) handleMethodSelect(service domain.Service, method domain.Me
 		slog.String("method", method.Name),
 	)
 
-	// Cache the current method's request JSON before switching
+	// Remember the current method's request before switching
 	prevService, _ := w.state.SelectedService.Get()
 	prevMethod, _ := w.state.SelectedMethod.Get()
-	if prevService != "" && prevMethod != "" {
-		currentJSON, _ := w.state.Request.TextData.Get()
-		if currentJSON != "" {
-			w.methodRequestCache[prevService+"/"+prevMethod] = currentJSON
-		}
+	w.stashMethodRequest(prevService, prevMethod)
+
+	// A pinned response only makes sense against the same method
+	if prevService != service.FullName || prevMethod != method.Name {
+		w.responsePanel.ClearPin()
 	}
 
 	// Update state
 	_ = w.state.SelectedService.Set(service.FullName)
 	_ = w.state.SelectedMethod.Set(method.Name)
+	w.historyPanel.SetMethod(service.FullName + "/" + method.Name)
 
 	// Get method descriptor
 	refClient := w.app.ReflectionClient()
@@ -573,31 +1016,44 @@ func (w *MainWindow) handleMethodSelect(service domain.Service, method domain.Me
 		w.bidiPanel.SetOnCloseSend(func() {
 			w.handleBidiStreamClose()
 		})
+		w.bidiPanel.SetBatchValidator(func(json string) error {
+			return grpc.CheckJSON(protoDesc, json, w.typeResolver())
+		})
 		w.bidiPanel.SetOnAbort(func() {
-			w.streamMu.Lock()
-			bidiCancel := w.bidiCancelFunc
-			w.bidiCancelFunc = nil
-			w.bidiStreamHandle = nil
-			w.streamMu.Unlock()
-			if bidiCancel != nil {
-				bidiCancel()
-			}
+			w.bidi.stop()
+		})
+		w.bidiPanel.SetOnRestart(func() {
+			w.handleBidiStreamRestart()
 		})
 		w.bidiPanel.SetStatus("Ready to start bidirectional stream")
 	} else {
 		// For other method types, use normal request/response panels
 		w.switchToNormalPanel()
 
+		// Decide whether the body carries over before the form is built
+		cacheKey := service.FullName + "/" + method.Name
+		remembered, hasRemembered := w.methodRequests.Get(cacheKey)
+		bodyAction := w.decideRequestBody(protoDesc, hasRemembered)
+
 		// Update request panel with method descriptor
 		w.requestPanel.SetMethod(method.Name, protoDesc)
 		w.requestPanel.SetSendEnabled(true)
 
-		// Restore cached request JSON for this method (if any)
-		cacheKey := service.FullName + "/" + method.Name
-		if cached, ok := w.methodRequestCache[cacheKey]; ok {
-			_ = w.state.Request.TextData.Set(cached)
-			w.requestPanel.SyncTextToForm()
+		// Restore the request last written for this method, if any; it
+		// wins over the template applied below
+		if bodyAction == model.BodyRestore {
+			w.restoreMethodRequest(remembered)
+		} else {
+			w.carryRequestBody(bodyAction, protoDesc)
 		}
+		if held := w.nextRequest; held != nil {
+			w.nextRequest = nil
+			w.applyHeldResponse(held, protoDesc)
+		}
+		w.applyRequestTemplate(protoDesc)
+
+		// Offer this method's saved requests
+		w.loadSavedRequests(cacheKey)
 
 		// Set client streaming mode based on method type
 		w.requestPanel.SetClientStreaming(method.IsClientStream)
@@ -634,139 +1090,115 @@ func (w *MainWindow) handleSendRequest(jsonStr string, metadataMap map[string]st
 	}
 
 	// Get method descriptor
-	refClient := w.app.ReflectionClient()
-	if refClient == nil {
-		_ = w.state.Response.Error.Set("Reflection client not initialized")
+	methodDesc, err := w.requests.Resolve(serviceName, methodName)
+	if err != nil {
+		var resolveErr *controller.ResolveError
+		if errors.As(err, &resolveErr) {
+			_ = w.state.Response.Error.Set("Failed to get method descriptor: " + err.Error())
+		} else {
+			_ = w.state.Response.Error.Set(err.Error())
+		}
 		return
 	}
 
-	methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
-	if err != nil {
-		w.logger.Error("failed to get method descriptor", slog.Any("error", err))
-		_ = w.state.Response.Error.Set("Failed to get method descriptor: " + err.Error())
-		return
+	call := controller.Call{
+		Service:  serviceName,
+		Method:   methodName,
+		Desc:     methodDesc,
+		Body:     jsonStr,
+		Metadata: metadataMap,
 	}
 
 	// Check if this is a server streaming RPC
 	if methodDesc.IsStreamingServer() {
-		w.handleServerStreamRequest(jsonStr, metadataMap, methodDesc)
+		w.handleServerStreamRequest(call)
 	} else {
-		w.handleUnaryRequest(jsonStr, metadataMap, methodDesc)
+		w.handleUnaryRequest(call)
 	}
 }
 
 // handleUnaryRequest handles unary RPC invocations
-func (w *MainWindow) handleUnaryRequest(jsonStr string, metadataMap map[string]string, methodDesc protoreflect.MethodDescriptor) {
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), w.getRequestTimeout())
-		defer cancel()
-		w.streamMu.Lock()
-		w.unaryCancel = cancel
-		w.streamMu.Unlock()
-
-		serviceName, _ := w.state.SelectedService.Get()
-		methodName, _ := w.state.SelectedMethod.Get()
-
-		w.logger.Debug("sending unary request",
-			slog.String("service", serviceName),
-			slog.String("method", methodName),
-		)
-
+func (w *MainWindow) handleUnaryRequest(call controller.Call) {
+	w.calls.Go(func() {
 		// Set loading state and switch to normal response mode
 		_ = w.state.Response.Loading.Set(true)
 		_ = w.state.Response.Error.Set("")
 		fyne.Do(func() {
 			w.responsePanel.SetStreaming(false)
+			w.responsePanel.SetTiming("")
 		})
 
-		startTime := time.Now()
-
-		// Convert metadata map to grpc metadata
-		md := metadata.New(metadataMap)
-
-		// Invoke RPC
-		invoker := w.app.Invoker()
-		if invoker == nil {
-			_ = w.state.Response.Loading.Set(false)
-			_ = w.state.Response.Error.Set("Invoker not initialized")
+		result, err := w.requests.Unary(call, w.getRequestTimeout())
+		_ = w.state.Response.Loading.Set(false)
+		if err != nil {
+			_ = w.state.Response.Error.Set(err.Error())
 			return
 		}
-
-		respJSON, respHeaders, respTrailers, err := invoker.InvokeUnary(ctx, methodDesc, jsonStr, md)
-
-		duration := time.Since(startTime)
-		_ = w.state.Response.Loading.Set(false)
+		timingText := callTimingText(result.Timing, false)
 
 		// Record history entry
 		currentServer, _ := w.state.CurrentServer.Get()
-		w.recordHistoryEntry(currentServer, serviceName+"/"+methodName, jsonStr, metadataMap, respJSON, respHeaders, duration, err)
+		w.recordHistoryEntry(currentServer, call.Name(), call.Body, call.Metadata, result.Response, result.Headers, result.Trailers, result.Duration, result.Err)
 
-		if err != nil {
-			w.logger.Error("RPC invocation failed", slog.Any("error", err))
+		// Convert metadata to maps for display
+		respMetadataMap := convertMetadataToMap(result.Headers)
+		respTrailersMap := convertMetadataToMap(result.Trailers)
+
+		if err := result.Err; err != nil {
+			w.forgetRejectedToken(err)
 
-			// Show rich gRPC error dialog with retry option (must be on main thread)
+			// Report the failure in a toast whose details offer a retry (must be on main thread).
+			// Failed calls keep their headers and trailers, which often carry
+			// request IDs and error details.
 			fyne.Do(func() {
-				uierrors.ShowGRPCError(err, w.window, func() {
+				uierrors.ShowGRPCToast(err, w.toasts, w.window, func() {
 					// Retry callback - send the request again
-					w.handleSendRequest(jsonStr, metadataMap)
+					w.handleSendRequest(call.Body, call.Metadata)
 				})
-				w.responsePanel.ClearResponseMetadata()
+				w.responsePanel.SetResponseMetadata(respMetadataMap)
+				w.responsePanel.SetResponseTrailers(respTrailersMap)
+				w.responsePanel.SetTiming(timingText)
 				w.expandResponsePanel()
 			})
 
 			// Also set error in response panel for inline visibility
-			_ = w.state.Response.Error.Set(err.Error())
+			errText := err.Error()
+			if attempts := formatAttempts(result.Attempts, true); attempts != "" {
+				errText += " (" + attempts + ")"
+			}
+			_ = w.state.Response.Error.Set(errText)
 			return
 		}
 
-		respJSON = prettyJSON(respJSON)
-
-		// Convert metadata to maps for display
-		respMetadataMap := convertMetadataToMap(respHeaders)
-		respTrailersMap := convertMetadataToMap(respTrailers)
+		sizes := formatMessageSizes(w.encodedSize(call.Desc.Input(), call.Body), w.encodedSize(call.Desc.Output(), result.Response), result.Headers)
+		respJSON := prettyJSON(result.Response)
+		unknownEnums := w.unknownEnumPaths(call.Desc.Output(), result.Response)
 
 		// Update response (bindings are thread-safe, but widget methods need main thread)
 		_ = w.state.Response.TextData.Set(respJSON)
-		_ = w.state.Response.Duration.Set(fmt.Sprintf("Duration: %v", duration.Round(time.Millisecond)))
-		_ = w.state.Response.Size.Set(formatByteSize(len(respJSON)))
+		duration := fmt.Sprintf("Duration: %v", result.Duration.Round(time.Millisecond))
+		if attempts := formatAttempts(result.Attempts, false); attempts != "" {
+			duration += " · " + attempts
+		}
+		_ = w.state.Response.Duration.Set(duration)
+		_ = w.state.Response.Size.Set(sizes)
 		_ = w.state.Response.Error.Set("")
 
 		fyne.Do(func() {
 			w.responsePanel.SetResponseMetadata(respMetadataMap)
 			w.responsePanel.SetResponseTrailers(respTrailersMap)
+			w.responsePanel.SetTiming(timingText)
+			w.responsePanel.SetUnknownEnums(respJSON, unknownEnums)
 			w.expandResponsePanel()
+			w.lastResponse = &heldResponse{json: respJSON, desc: call.Desc.Output()}
 		})
-
-		w.logger.Info("RPC completed successfully",
-			slog.String("method", methodName),
-			slog.Duration("duration", duration),
-		)
-	}()
+	})
 }
 
 // handleServerStreamRequest handles server streaming RPC invocations
-func (w *MainWindow) handleServerStreamRequest(jsonStr string, metadataMap map[string]string, methodDesc protoreflect.MethodDescriptor) {
+func (w *MainWindow) handleServerStreamRequest(call controller.Call) {
 	// Cancel any existing server stream before starting a new one
-	w.streamMu.Lock()
-	prevCancel := w.serverStreamCancel
-	w.serverStreamCancel = nil
-	w.streamMu.Unlock()
-	if prevCancel != nil {
-		prevCancel()
-	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-	w.streamMu.Lock()
-	w.serverStreamCancel = cancel
-	w.streamMu.Unlock()
-
-	serviceName, _ := w.state.SelectedService.Get()
-	methodName, _ := w.state.SelectedMethod.Get()
-
-	w.logger.Debug("sending server stream request",
-		slog.String("service", serviceName),
-		slog.String("method", methodName),
-	)
+	w.requests.StopServerStream()
 
 	// Switch to streaming mode and prepare UI
 	w.responsePanel.SetStreaming(true)
@@ -774,125 +1206,64 @@ func (w *MainWindow) handleServerStreamRequest(jsonStr string, metadataMap map[s
 	streamWidget := w.responsePanel.StreamingWidget()
 	streamWidget.Clear()
 	streamWidget.SetStatus("Starting stream...")
+	w.responsePanel.SetTiming("")
 	streamWidget.EnableStopButton()
 
 	// Set stop button handler
 	streamWidget.SetOnStop(func() {
 		w.logger.Info("user requested stream stop")
-		cancel()
-		w.streamMu.Lock()
-		w.serverStreamCancel = nil
-		w.streamMu.Unlock()
-		streamWidget.DisableStopButton()
-		streamWidget.SetStatus("Stopped by user")
-	})
-
-	// Convert metadata map to grpc metadata
-	md := metadata.New(metadataMap)
-
-	// Invoke server streaming RPC
-	invoker := w.app.Invoker()
-	if invoker == nil {
-		streamWidget.SetStatus("Error: Invoker not initialized")
-		streamWidget.DisableStopButton()
-		return
-	}
-
-	startTime := time.Now()
-	msgChan, errChan, headerChan, trailerChan := invoker.InvokeServerStream(ctx, methodDesc, jsonStr, md)
-
-	// Process messages in a goroutine
-	go func() {
-		defer cancel() // ensure context is cleaned up on all exit paths
-		messageCount := 0
-
-		for {
-			select {
-			case jsonMsg, ok := <-msgChan:
-				if !ok {
-					// Channel closed
-					return
-				}
-
-				messageCount++
-				jsonMsg = prettyJSON(jsonMsg)
-
-				// Add message to UI (must be on main thread)
-				fyne.Do(func() {
-					streamWidget.AddMessage(jsonMsg)
-				})
-
-			case err, ok := <-errChan:
-				if !ok {
-					// Channel closed
-					return
-				}
-
-				duration := time.Since(startTime)
-
-				// Read trailers (sent before error by invoker)
-				select {
-				case trailers := <-trailerChan:
-					trailersMap := convertMetadataToMap(trailers)
-					fyne.Do(func() {
-						w.responsePanel.SetResponseTrailers(trailersMap)
-					})
-				default:
-				}
-
-				// Record history for server streaming
-				currentServer, _ := w.state.CurrentServer.Get()
-				streamStatus := "success"
-				streamErr := ""
-				if err != io.EOF {
-					streamStatus = "error"
-					streamErr = err.Error()
-				}
-				go w.recordStreamHistoryEntry(currentServer, serviceName+"/"+methodName, jsonStr, metadataMap, duration, streamStatus, streamErr, "server_stream", messageCount)
+		w.requests.StopServerStream()
+		streamWidget.DisableStopButton()
+		streamWidget.SetStatus("Stopped by user")
+	})
 
-				// Set duration on the response panel so it's visible in the Response tab
-				durationStr := duration.Round(time.Millisecond).String()
-				fyne.Do(func() {
-					_ = w.state.Response.Duration.Set("Duration: " + durationStr)
-				})
+	err := w.requests.StartServerStream(call, controller.StreamEvents{
+		// Headers are shown on the stream as well as the Headers tab; they
+		// may come long before the first message
+		OnHeaders: func(hdr metadata.MD) {
+			hdrsMap := convertMetadataToMap(hdr)
+			fyne.Do(func() {
+				w.responsePanel.SetResponseMetadata(hdrsMap)
+				streamWidget.SetHeaders(hdrsMap)
+			})
+		},
+		// Messages are pretty-printed only when opened
+		OnMessage: func(jsonMsg string) {
+			fyne.Do(func() {
+				streamWidget.AddMessage(jsonMsg)
+			})
+		},
+		OnDone: func(result controller.StreamResult) {
+			trailersMap := convertMetadataToMap(result.Trailers)
+			fyne.Do(func() {
+				w.responsePanel.SetResponseTrailers(trailersMap)
+			})
 
-				// Check if this is normal stream completion (io.EOF) or an error
-				if err == io.EOF {
-					w.logger.Info("server stream completed successfully",
-						slog.String("method", methodName),
-						slog.Int("message_count", messageCount),
-						slog.Duration("duration", duration),
-					)
-
-					fyne.Do(func() {
-						streamWidget.SetStatus(fmt.Sprintf("Complete (%d messages in %v)", messageCount, duration.Round(time.Millisecond)))
-						streamWidget.DisableStopButton()
-					})
+			// Set duration on the response panel so it's visible in the Response tab
+			durationStr := result.Duration.Round(time.Millisecond).String()
+			timingText := callTimingText(result.Timing, true)
+			fyne.Do(func() {
+				_ = w.state.Response.Duration.Set("Duration: " + durationStr)
+				w.responsePanel.SetTiming(timingText)
+				if result.Err != nil {
+					streamWidget.SetStatus(fmt.Sprintf("Error: %s (received %d messages)", result.Err.Error(), result.Messages))
 				} else {
-					w.logger.Error("server stream error",
-						slog.String("method", methodName),
-						slog.Int("message_count", messageCount),
-						slog.Any("error", err),
-					)
-
-					fyne.Do(func() {
-						streamWidget.SetStatus(fmt.Sprintf("Error: %s (received %d messages)", err.Error(), messageCount))
-						streamWidget.DisableStopButton()
-					})
+					streamWidget.SetStatus(fmt.Sprintf("Complete (%d messages in %v)", result.Messages, result.Duration.Round(time.Millisecond)))
 				}
+				streamWidget.DisableStopButton()
+			})
 
-				return
-
-			case hdr, ok := <-headerChan:
-				if ok {
-					hdrsMap := convertMetadataToMap(hdr)
-					fyne.Do(func() {
-						w.responsePanel.SetResponseMetadata(hdrsMap)
-					})
-				}
-			}
-		}
-	}()
+			// Record history for server streaming
+			currentServer, _ := w.state.CurrentServer.Get()
+			w.historyWrites.Go(func() {
+				w.recordStreamHistoryEntry(currentServer, call.Name(), call.Body, call.Metadata, result.Headers, result.Trailers, result.Duration, result.Err, "server_stream", result.Messages)
+			})
+		},
+	})
+	if err != nil {
+		streamWidget.SetStatus("Error: " + err.Error())
+		streamWidget.DisableStopButton()
+	}
 }
 
 // SetContent builds and sets the main window layout.
@@ -918,8 +1289,7 @@ func (w *MainWindow) buildLeftPanel() *fyne.Container {
 		w.serviceBrowser,
 		leftTabs,
 	)
-	savedBrowser := w.fyneApp.Preferences().FloatWithFallback(prefSplitBrowser, 0.7)
-	w.browserSplit.SetOffset(savedBrowser)
+	w.browserSplit.SetOffset(w.layout.SplitBrowser)
 	return container.NewBorder(
 		nil,
 		nil, nil, nil,
@@ -928,13 +1298,14 @@ func (w *MainWindow) buildLeftPanel() *fyne.Container {
 }
 
 func (w *MainWindow) SetContent() {
+	w.captureLayout()
 	leftPanel := w.buildLeftPanel()
 
-	// Bottom bar: status on left, theme selector on right
+	// Bottom bar: status on left, appearance settings on right
 	bottomBar := container.NewBorder(
 		nil, nil, // top, bottom
-		w.statusBar,    // left (status)
-		w.themeSelector, // right (theme selector)
+		w.statusBar,     // left (status)
+		w.appearanceBtn, // right (appearance settings)
 	)
 
 	// Right side: vertical split with request, response, and bottom bar
@@ -942,8 +1313,7 @@ func (w *MainWindow) SetContent() {
 		w.requestPanel,  // top (gets most space initially)
 		w.responsePanel, // bottom (minimized until first response)
 	)
-	savedContent := w.fyneApp.Preferences().FloatWithFallback(prefSplitContent, 0.75)
-	w.contentSplit.SetOffset(savedContent) // default: 75% request, 25% response
+	w.contentSplit.SetOffset(w.layout.SplitContent) // default: 75% request, 25% response
 	rightPanel := container.NewBorder(
 		nil,       // top
 		bottomBar, // bottom (status bar + theme selector)
@@ -959,11 +1329,19 @@ func (w *MainWindow) SetContent() {
 	)
 
 	// Restore saved split position or use default (30% for browser, 70% for panels)
-	savedMain := w.fyneApp.Preferences().FloatWithFallback(prefSplitMain, 0.3)
-	w.mainSplit.SetOffset(savedMain)
+	w.mainSplit.SetOffset(w.layout.SplitMain)
 
 	// Connection bar spans full window width above the split
-	w.window.SetContent(container.NewBorder(w.connectionBar, nil, nil, nil, w.mainSplit))
+	w.window.SetContent(w.frame())
+}
+
+// frame places the connection bar above mainSplit and layers toasts over
+// the whole window.
+func (w *MainWindow) frame() fyne.CanvasObject {
+	return container.NewStack(
+		container.NewBorder(container.NewVBox(w.connectionBar, w.schemaNotice), nil, nil, nil, w.mainSplit),
+		w.toasts,
+	)
 }
 
 // Window returns the underlying Fyne window.
@@ -991,114 +1369,44 @@ func (w *MainWindow) handleClientStreamSend(jsonStr string, metadataMap map[stri
 		return
 	}
 
-	// If we don't have an active stream, start one
-	w.streamMu.Lock()
-	needsNewStream := w.clientStreamHandle == nil
-	w.streamMu.Unlock()
-	if needsNewStream {
-		// Get method descriptor
-		refClient := w.app.ReflectionClient()
-		if refClient == nil {
-			dialog.ShowError(fmt.Errorf("reflection client not initialized"), w.window)
-			return
-		}
-
-		methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
-		if err != nil {
-			w.logger.Error("failed to get method descriptor", slog.Any("error", err))
-			uierrors.ShowGRPCError(err, w.window, nil)
-			return
-		}
-
-		// Verify this is a client streaming method
-		if !methodDesc.IsStreamingClient() {
-			dialog.ShowError(fmt.Errorf("method %s is not a client streaming RPC", methodName), w.window)
-			return
-		}
-
-		// Convert metadata map to grpc metadata
-		md := metadata.New(metadataMap)
-
-		// Start the client stream
-		invoker := w.app.Invoker()
-		if invoker == nil {
-			dialog.ShowError(fmt.Errorf("invoker not initialized"), w.window)
-			return
-		}
-
-		ctx, cancel := context.WithCancel(context.Background())
-		handle, err := invoker.InvokeClientStream(ctx, methodDesc, md)
-		if err != nil {
-			cancel()
-			w.logger.Error("failed to start client stream", slog.Any("error", err))
-			uierrors.ShowGRPCError(err, w.window, func() {
-				// Retry callback - attempt to start stream again
-				w.handleClientStreamSend(jsonStr, metadataMap)
-			})
-			return
-		}
-
-		w.streamMu.Lock()
-		w.clientStreamHandle = handle
-		w.clientStreamCancel = cancel
-		w.streamMu.Unlock()
-		w.logger.Info("client stream started",
-			slog.String("service", serviceName),
-			slog.String("method", methodName),
-		)
-	}
-
-	// Send message on the stream
-	w.streamMu.Lock()
-	csHandle := w.clientStreamHandle
-	w.streamMu.Unlock()
-	if csHandle == nil {
-		w.logger.Error("client stream handle unexpectedly nil")
-		return
-	}
-	if err := csHandle.Send(jsonStr); err != nil {
-		w.logger.Error("failed to send client stream message", slog.Any("error", err))
-		uierrors.ShowGRPCError(err, w.window, func() {
-			// Retry callback - attempt to send the message again
+	err := w.requests.SendClientStream(controller.Call{
+		Service:  serviceName,
+		Method:   methodName,
+		Body:     jsonStr,
+		Metadata: metadataMap,
+	})
+	var callErr *controller.CallError
+	var resolveErr *controller.ResolveError
+	switch {
+	case err == nil:
+	case errors.As(err, &callErr):
+		// Failing to start the stream or to send on it ends the batch
+		// being sent, offering to retry
+		w.requestPanel.StreamingInput().StopBatch()
+		uierrors.ShowGRPCToast(callErr.Err, w.toasts, w.window, func() {
 			w.handleClientStreamSend(jsonStr, metadataMap)
 		})
-		// Clean up handle and cancel context on error
-		w.streamMu.Lock()
-		w.clientStreamHandle = nil
-		sendErrCancel := w.clientStreamCancel
-		w.clientStreamCancel = nil
-		w.streamMu.Unlock()
-		if sendErrCancel != nil {
-			sendErrCancel()
-		}
-		return
+	case errors.As(err, &resolveErr):
+		uierrors.ShowGRPCError(resolveErr.Err, w.window, nil)
+	default:
+		dialog.ShowError(err, w.window)
 	}
-
-	w.logger.Debug("client stream message sent",
-		slog.String("method", methodName),
-	)
 }
 
 // handleClientStreamFinish closes the client stream and receives the final response.
 // This is called when the user clicks "Finish & Get Response" in the streaming input widget.
 func (w *MainWindow) handleClientStreamFinish(metadataMap map[string]string) {
-	w.streamMu.Lock()
-	hasStream := w.clientStreamHandle != nil
-	w.streamMu.Unlock()
-	if !hasStream {
+	if !w.requests.ClientStreamOpen() {
 		// No active stream - start one if we haven't sent any messages yet
 		// This allows "Finish & Get Response" to work even without sending messages
 		w.handleClientStreamSend("{}", metadataMap)
-		w.streamMu.Lock()
-		hasStream = w.clientStreamHandle != nil
-		w.streamMu.Unlock()
-		if !hasStream {
+		if !w.requests.ClientStreamOpen() {
 			// Failed to start stream
 			return
 		}
 	}
 
-	go func() {
+	w.calls.Go(func() {
 		serviceName, _ := w.state.SelectedService.Get()
 		methodName, _ := w.state.SelectedMethod.Get()
 
@@ -1111,45 +1419,26 @@ func (w *MainWindow) handleClientStreamFinish(metadataMap map[string]string) {
 		_ = w.state.Response.Loading.Set(true)
 		_ = w.state.Response.Error.Set("")
 
-		startTime := time.Now()
-
 		// Close stream and receive response
-		w.streamMu.Lock()
-		csHandle := w.clientStreamHandle
-		w.streamMu.Unlock()
-		if csHandle == nil {
-			_ = w.state.Response.Loading.Set(false)
-			_ = w.state.Response.Error.Set("Client stream was cancelled")
-			return
-		}
-		respJSON, err := csHandle.CloseAndReceive()
-
-		// Capture trailers (available after stream ends)
-		csTrailers := csHandle.Trailer()
-
-		duration := time.Since(startTime)
+		result, err := w.requests.FinishClientStream()
 		_ = w.state.Response.Loading.Set(false)
-
-		// Clean up handle and cancel func
-		w.streamMu.Lock()
-		w.clientStreamHandle = nil
-		csCancel := w.clientStreamCancel
-		w.clientStreamCancel = nil
-		w.streamMu.Unlock()
-		if csCancel != nil {
-			csCancel()
+		if err != nil {
+			_ = w.state.Response.Error.Set(err.Error())
+			return
 		}
+		timingText := callTimingText(result.Timing, true)
 
 		// Record history
 		currentServer, _ := w.state.CurrentServer.Get()
-		w.recordHistoryEntry(currentServer, serviceName+"/"+methodName, "", metadataMap, respJSON, nil, duration, err)
+		w.recordHistoryEntry(currentServer, serviceName+"/"+methodName, "", metadataMap, result.Response, result.Headers, result.Trailers, result.Duration, result.Err)
 
-		if err != nil {
-			w.logger.Error("client stream failed", slog.Any("error", err))
-
-			// Show rich gRPC error dialog (must be on main thread)
+		if err := result.Err; err != nil {
+			// Report the failure in a toast (must be on main thread)
 			fyne.Do(func() {
-				uierrors.ShowGRPCError(err, w.window, nil)
+				uierrors.ShowGRPCToast(err, w.toasts, w.window, nil)
+				w.responsePanel.SetResponseMetadata(convertMetadataToMap(result.Headers))
+				w.responsePanel.SetResponseTrailers(convertMetadataToMap(result.Trailers))
+				w.responsePanel.SetTiming(timingText)
 			})
 
 			// Also set error in response panel for inline visibility
@@ -1157,30 +1446,23 @@ func (w *MainWindow) handleClientStreamFinish(metadataMap map[string]string) {
 			return
 		}
 
-		// Capture headers
-		if csHeaders, hdErr := csHandle.Header(); hdErr == nil {
-			fyne.Do(func() {
-				w.responsePanel.SetResponseMetadata(convertMetadataToMap(csHeaders))
-			})
-		}
-
-		respJSON = prettyJSON(respJSON)
+		sizes := formatMessageSizes(result.SentSize, w.encodedSize(result.Method.Output(), result.Response), result.Headers)
+		respJSON := prettyJSON(result.Response)
+		unknownEnums := w.unknownEnumPaths(result.Method.Output(), result.Response)
 
 		// Update response
 		_ = w.state.Response.TextData.Set(respJSON)
-		_ = w.state.Response.Duration.Set(fmt.Sprintf("Duration: %v", duration.Round(time.Millisecond)))
-		_ = w.state.Response.Size.Set(formatByteSize(len(respJSON)))
+		_ = w.state.Response.Duration.Set(fmt.Sprintf("Duration: %v", result.Duration.Round(time.Millisecond)))
+		_ = w.state.Response.Size.Set(sizes)
 		_ = w.state.Response.Error.Set("")
 		fyne.Do(func() {
-			w.responsePanel.SetResponseTrailers(convertMetadataToMap(csTrailers))
+			w.responsePanel.SetResponseMetadata(convertMetadataToMap(result.Headers))
+			w.responsePanel.SetResponseTrailers(convertMetadataToMap(result.Trailers))
+			w.responsePanel.SetTiming(timingText)
+			w.responsePanel.SetUnknownEnums(respJSON, unknownEnums)
 			w.expandResponsePanel()
 		})
-
-		w.logger.Info("client stream completed successfully",
-			slog.String("method", methodName),
-			slog.Duration("duration", duration),
-		)
-	}()
+	})
 }
 
 // captureWorkspaceState captures the current UI state into a Workspace
@@ -1192,11 +1474,9 @@ func (w *MainWindow) captureWorkspaceState() domain.Workspace {
 
 	// Capture current connection settings
 	if address, _ := w.state.CurrentServer.Get(); address != "" {
-		tlsSettings := w.connectionBar.GetTLSSettings()
-		workspace.CurrentConnection = &domain.Connection{
-			Address: address,
-			TLS:     tlsSettings,
-		}
+		conn := w.connectionBar.GetConnection()
+		conn.Address = address
+		workspace.CurrentConnection = &conn
 	}
 
 	// Capture current request
@@ -1213,27 +1493,24 @@ func (w *MainWindow) captureWorkspaceState() domain.Workspace {
 		}
 	}
 
+	// Capture the startup checklist
+	workspace.Checklist = append([]domain.ChecklistItem(nil), w.checklist...)
+
 	// Capture selected service/method
 	workspace.SelectedService, _ = w.state.SelectedService.Get()
 	workspace.SelectedMethod, _ = w.state.SelectedMethod.Get()
 
-	// Snapshot the current method's request into the cache before saving
-	if workspace.SelectedService != "" && workspace.SelectedMethod != "" {
-		if currentJSON, _ := w.state.Request.TextData.Get(); currentJSON != "" {
-			w.methodRequestCache[workspace.SelectedService+"/"+workspace.SelectedMethod] = currentJSON
-		}
-	}
+	// Capture the request last written for each method, the current one
+	// included
+	w.stashMethodRequest(workspace.SelectedService, workspace.SelectedMethod)
+	workspace.Requests = append(workspace.Requests, w.methodRequests.Saved()...)
 
-	// Capture per-method request templates from cache
-	for method, jsonStr := range w.methodRequestCache {
-		workspace.Requests = append(workspace.Requests, domain.SavedRequest{
-			Name: method,
-			Request: domain.Request{
-				Method: method,
-				Body:   jsonStr,
-			},
-		})
+	// Capture the saved requests library
+	library, err := w.app.Storage().GetSavedRequests("")
+	if err != nil {
+		w.logger.Warn("failed to capture saved requests", slog.Any("error", err))
 	}
+	workspace.Library = library
 
 	return workspace
 }
@@ -1242,11 +1519,24 @@ func (w *MainWindow) captureWorkspaceState() domain.Workspace {
 func (w *MainWindow) applyWorkspaceState(workspace domain.Workspace) {
 	w.logger.Info("applying workspace state", slog.String("workspace", workspace.Name))
 
-	// Restore per-method request templates into cache
+	// Restore the request last written for each method, keeping their
+	// order of use
 	for _, saved := range workspace.Requests {
-		w.methodRequestCache[saved.Name] = saved.Request.Body
+		w.methodRequests.Put(saved.Name, saved.Request)
+	}
+
+	// Merge the workspace's saved requests into the library
+	for _, saved := range workspace.Library {
+		if err := w.app.Storage().SaveRequest(saved); err != nil {
+			w.logger.Warn("failed to import saved request",
+				slog.String("name", saved.Name),
+				slog.Any("error", err))
+		}
 	}
 
+	// Replace the checklist — it belongs to the workspace
+	w.checklist = append([]domain.ChecklistItem(nil), workspace.Checklist...)
+
 	// afterConnect selects the saved service/method and restores request state.
 	afterConnect := func() {
 		if workspace.SelectedService != "" && workspace.SelectedMethod != "" {
@@ -1272,8 +1562,7 @@ func (w *MainWindow) applyWorkspaceState(workspace domain.Workspace) {
 	// Auto-connect if workspace has a saved connection
 	if workspace.CurrentConnection != nil {
 		conn := workspace.CurrentConnection
-		w.connectionBar.SetAddress(conn.Address)
-		w.connectionBar.SetTLSSettings(conn.TLS)
+		w.connectionBar.SetConnection(*conn)
 
 		// Check if already connected to this server
 		currentServer, _ := w.state.CurrentServer.Get()
@@ -1282,7 +1571,7 @@ func (w *MainWindow) applyWorkspaceState(workspace domain.Workspace) {
 			afterConnect()
 		} else {
 			// Need to connect first
-			w.handleConnect(conn.Address, conn.TLS)
+			w.handleConnect(*conn)
 			w.waitForConnection(afterConnect, "while loading workspace")
 		}
 	} else {
@@ -1301,13 +1590,14 @@ func (w *MainWindow) switchToBidiPanel() {
 	}
 
 	// Update the window content to show bidi panel instead of request/response panels
+	w.captureLayout()
 	leftPanel := w.buildLeftPanel()
 
-	// Bottom bar: status on left, theme selector on right
+	// Bottom bar: status on left, appearance settings on right
 	bottomBar := container.NewBorder(
 		nil, nil, // top, bottom
 		w.statusBar,     // left (status)
-		w.themeSelector, // right (theme selector)
+		w.appearanceBtn, // right (appearance settings)
 	)
 
 	rightPanel := container.NewBorder(
@@ -1317,13 +1607,10 @@ func (w *MainWindow) switchToBidiPanel() {
 		w.bidiPanel,
 	)
 
-	// Preserve browser split offset across mode switches
-	savedOffset := w.browserSplit.Offset
-
-	mainSplit := container.NewHSplit(leftPanel, rightPanel)
-	mainSplit.SetOffset(0.3)
-	w.browserSplit.SetOffset(savedOffset)
-	w.window.SetContent(container.NewBorder(w.connectionBar, nil, nil, nil, mainSplit))
+	// The new splits keep the offsets captured above
+	w.mainSplit = container.NewHSplit(leftPanel, rightPanel)
+	w.mainSplit.SetOffset(w.layout.SplitMain)
+	w.window.SetContent(w.frame())
 	w.inBidiMode = true
 }
 
@@ -1342,159 +1629,143 @@ func (w *MainWindow) switchToNormalPanel() {
 	w.inBidiMode = false
 }
 
-// handleBidiStreamSend sends a message on a bidirectional stream
+// handleBidiStreamSend sends a message on a bidirectional stream, opening
+// one first if none is open.
 func (w *MainWindow) handleBidiStreamSend(jsonStr string, metadataMap map[string]string) {
-	serviceName, _ := w.state.SelectedService.Get()
-	methodName, _ := w.state.SelectedMethod.Get()
-
-	if serviceName == "" || methodName == "" {
-		dialog.ShowError(fmt.Errorf("no method selected"), w.window)
-		return
-	}
-
-	// If no active stream, start one
-	w.streamMu.Lock()
-	needsNewBidiStream := w.bidiStreamHandle == nil
-	w.streamMu.Unlock()
-	if needsNewBidiStream {
-		refClient := w.app.ReflectionClient()
-		if refClient == nil {
-			dialog.ShowError(fmt.Errorf("reflection client not initialized"), w.window)
-			return
-		}
-
-		methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
-		if err != nil {
-			w.logger.Error("failed to get method descriptor", slog.Any("error", err))
-			uierrors.ShowGRPCError(err, w.window, nil)
+	handle := w.bidi.current()
+	if handle == nil {
+		handle = w.openBidiStream(metadataMap, func() {
+			// Retry callback - attempt to start stream and send again
+			w.handleBidiStreamSend(jsonStr, metadataMap)
+		})
+		if handle == nil {
 			return
 		}
+	}
 
-		// Verify this is a bidi streaming method
-		if !methodDesc.IsStreamingClient() || !methodDesc.IsStreamingServer() {
-			dialog.ShowError(fmt.Errorf("method %s is not a bidirectional streaming RPC", methodName), w.window)
-			return
+	methodName, _ := w.state.SelectedMethod.Get()
+	if err := handle.Send(jsonStr); err != nil {
+		w.logger.Error("failed to send bidi stream message", slog.Any("error", err))
+		// The receive loop reports how the stream ended unless we do here
+		if w.bidi.end(handle) {
+			w.bidiPanel.ShowEnded(fmt.Sprintf("Send error: %s", err.Error()))
 		}
+		return
+	}
 
-		// Convert metadata map to grpc metadata
-		md := metadata.New(metadataMap)
+	w.bidiPanel.AddSent(jsonStr)
+	w.logger.Debug("bidi stream message sent", slog.String("method", methodName))
+}
 
-		// Start the bidi stream
-		invoker := w.app.Invoker()
-		if invoker == nil {
-			dialog.ShowError(fmt.Errorf("invoker not initialized"), w.window)
-			return
-		}
+// handleBidiStreamRestart opens a new stream after the last one ended, with
+// the same metadata. Nothing is sent on it until the user sends.
+func (w *MainWindow) handleBidiStreamRestart() {
+	metadataMap := w.bidi.lastMetadata()
+	if w.openBidiStream(metadataMap, w.handleBidiStreamRestart) != nil {
+		w.logger.Info("bidi stream restarted")
+	}
+}
 
-		ctx, cancel := context.WithCancel(context.Background())
-		w.streamMu.Lock()
-		w.bidiCancelFunc = cancel
-		w.streamMu.Unlock()
+// openBidiStream opens a bidi stream for the selected method and starts
+// receiving on it. On failure it reports the error, offering retry, and
+// returns nil.
+func (w *MainWindow) openBidiStream(metadataMap map[string]string, retry func()) *grpc.BidiStreamHandle {
+	serviceName, _ := w.state.SelectedService.Get()
+	methodName, _ := w.state.SelectedMethod.Get()
 
-		handle, err := invoker.InvokeBidiStream(ctx, methodDesc, md)
-		if err != nil {
-			w.logger.Error("failed to start bidi stream", slog.Any("error", err))
-			uierrors.ShowGRPCError(err, w.window, func() {
-				// Retry callback - attempt to start stream again
-				w.handleBidiStreamSend(jsonStr, metadataMap)
-			})
-			w.streamMu.Lock()
-			w.bidiCancelFunc = nil
-			w.streamMu.Unlock()
-			return
-		}
+	if serviceName == "" || methodName == "" {
+		dialog.ShowError(fmt.Errorf("no method selected"), w.window)
+		return nil
+	}
 
-		w.streamMu.Lock()
-		w.bidiStreamHandle = handle
-		w.streamMu.Unlock()
-		w.logger.Info("bidi stream started",
-			slog.String("service", serviceName),
-			slog.String("method", methodName),
-		)
+	refClient := w.app.ReflectionClient()
+	if refClient == nil {
+		dialog.ShowError(fmt.Errorf("reflection client not initialized"), w.window)
+		return nil
+	}
 
-		// Start receive goroutine
-		go w.receiveBidiMessages()
+	methodDesc, err := refClient.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		w.logger.Error("failed to get method descriptor", slog.Any("error", err))
+		uierrors.ShowGRPCError(err, w.window, nil)
+		return nil
+	}
 
-		w.bidiPanel.SetStatus("Stream active")
+	// Verify this is a bidi streaming method
+	if !methodDesc.IsStreamingClient() || !methodDesc.IsStreamingServer() {
+		dialog.ShowError(fmt.Errorf("method %s is not a bidirectional streaming RPC", methodName), w.window)
+		return nil
 	}
 
-	// Send message on the stream
-	w.streamMu.Lock()
-	bidiHandle := w.bidiStreamHandle
-	w.streamMu.Unlock()
-	if bidiHandle == nil {
-		w.logger.Error("bidi stream handle unexpectedly nil")
-		return
+	invoker := w.app.Invoker()
+	if invoker == nil {
+		dialog.ShowError(fmt.Errorf("invoker not initialized"), w.window)
+		return nil
 	}
-	if err := bidiHandle.Send(jsonStr); err != nil {
-		w.logger.Error("failed to send bidi stream message", slog.Any("error", err))
-		w.bidiPanel.SetStatus(fmt.Sprintf("Send error: %s", err.Error()))
-		w.bidiPanel.DisableSendControls()
-		// Clean up handle on error
-		w.streamMu.Lock()
-		bidiCancel := w.bidiCancelFunc
-		w.bidiStreamHandle = nil
-		w.bidiCancelFunc = nil
-		w.streamMu.Unlock()
-		if bidiCancel != nil {
-			bidiCancel()
-		}
-		return
+
+	handle, err := w.bidi.open(invoker, methodDesc, metadataMap)
+	if err != nil {
+		w.bidiPanel.StopBatch()
+		w.logger.Error("failed to start bidi stream", slog.Any("error", err))
+		uierrors.ShowGRPCToast(err, w.toasts, w.window, retry)
+		return nil
 	}
 
-	w.logger.Debug("bidi stream message sent", slog.String("method", methodName))
+	w.logger.Info("bidi stream started",
+		slog.String("service", serviceName),
+		slog.String("method", methodName),
+	)
+
+	w.calls.Go(func() { w.receiveBidiMessages(handle) })
+	go w.showBidiHeaders(handle)
+
+	w.bidiPanel.SetStreamActive()
+	return handle
+}
+
+// showBidiHeaders shows the bidi stream's response headers as soon as the
+// server sends them, while the stream is still the current one.
+func (w *MainWindow) showBidiHeaders(handle *grpc.BidiStreamHandle) {
+	for hdr := range handle.Headers() {
+		hdrsMap := convertMetadataToMap(hdr)
+		fyne.Do(func() {
+			if w.bidi.current() == handle {
+				w.bidiPanel.SetHeaders(hdrsMap)
+				w.responsePanel.SetResponseMetadata(hdrsMap)
+			}
+		})
+	}
 }
 
-// receiveBidiMessages receives messages from the bidi stream in a background goroutine
-func (w *MainWindow) receiveBidiMessages() {
+// receiveBidiMessages receives messages from the bidi stream in a background
+// goroutine. However the stream ends (the server finishing, a GOAWAY or
+// reset, or a cancel), the panel is readied for a new stream, unless the
+// stream was already stopped or replaced.
+func (w *MainWindow) receiveBidiMessages(handle *grpc.BidiStreamHandle) {
 	currentServer, _ := w.state.CurrentServer.Get()
 	serviceName, _ := w.state.SelectedService.Get()
 	methodName, _ := w.state.SelectedMethod.Get()
 
-	w.streamMu.Lock()
-	handle := w.bidiStreamHandle
-	w.streamMu.Unlock()
-	if handle == nil {
-		w.logger.Warn("bidi stream handle nil at receive start")
-		return
-	}
-
 	startTime := time.Now()
-	messageCount := 0
-	var streamErr error
-
-	for {
-		jsonMsg, err := handle.Recv()
-
-		if err == io.EOF {
-			w.logger.Info("bidi stream receive completed",
-				slog.String("method", methodName),
-				slog.Int("message_count", messageCount),
-			)
-			break
-		}
-
-		if err != nil {
-			streamErr = err
-			w.logger.Error("bidi stream receive error",
-				slog.String("method", methodName),
-				slog.Int("message_count", messageCount),
-				slog.Any("error", err),
-			)
-			break
-		}
-
-		messageCount++
+	messageCount, current, streamErr := w.bidi.receive(handle, func(jsonMsg string) {
 		jsonMsg = prettyJSON(jsonMsg)
 
 		// Add message to UI (must be on main thread)
 		fyne.Do(func() {
 			w.bidiPanel.AddReceived(jsonMsg)
 		})
+	})
 
-		w.logger.Debug("received bidi stream message",
+	if streamErr != nil {
+		w.logger.Error("bidi stream receive error",
 			slog.String("method", methodName),
-			slog.Int("message_num", messageCount),
+			slog.Int("message_count", messageCount),
+			slog.Any("error", streamErr),
+		)
+	} else {
+		w.logger.Info("bidi stream receive completed",
+			slog.String("method", methodName),
+			slog.Int("message_count", messageCount),
 		)
 	}
 
@@ -1502,18 +1773,19 @@ func (w *MainWindow) receiveBidiMessages() {
 	durationStr := duration.Round(time.Millisecond).String()
 
 	// Capture trailers and headers
-	trailers := handle.Trailer()
+	trailers := handle.Trailers()
 	headers, _ := handle.Header()
 
 	// Update UI with final status, headers, and trailers
 	fyne.Do(func() {
 		_ = w.state.Response.Duration.Set("Duration: " + durationStr)
 
-		if streamErr != nil {
-			w.bidiPanel.SetStatus(fmt.Sprintf("Receive error: %s", streamErr.Error()))
-			w.bidiPanel.DisableSendControls()
-		} else {
-			w.bidiPanel.SetStatus(fmt.Sprintf("Receive complete (%d messages in %s)", messageCount, durationStr))
+		if current {
+			if streamErr != nil {
+				w.bidiPanel.ShowEnded(fmt.Sprintf("Receive error: %s", streamErr.Error()))
+			} else {
+				w.bidiPanel.ShowEnded(fmt.Sprintf("Receive complete (%d messages in %s)", messageCount, durationStr))
+			}
 		}
 
 		// Display headers and trailers on the response panel
@@ -1526,20 +1798,14 @@ func (w *MainWindow) receiveBidiMessages() {
 	})
 
 	// Record history
-	status := "OK"
-	errorMsg := ""
-	if streamErr != nil {
-		status = "ERROR"
-		errorMsg = streamErr.Error()
-	}
-	w.recordStreamHistoryEntry(currentServer, serviceName+"/"+methodName, "", nil, duration, status, errorMsg, "bidi_stream", messageCount)
+	w.historyWrites.Go(func() {
+		w.recordStreamHistoryEntry(currentServer, serviceName+"/"+methodName, "", nil, headers, trailers, duration, streamErr, "bidi_stream", messageCount)
+	})
 }
 
 // handleBidiStreamClose closes the send side of the bidi stream
 func (w *MainWindow) handleBidiStreamClose() {
-	w.streamMu.Lock()
-	bidiHandle := w.bidiStreamHandle
-	w.streamMu.Unlock()
+	bidiHandle := w.bidi.current()
 	if bidiHandle == nil {
 		w.logger.Warn("no active bidi stream to close")
 		return
@@ -1564,26 +1830,27 @@ func (w *MainWindow) handleBidiStreamClose() {
 	w.bidiPanel.SetStatus("Send closed (still receiving)")
 }
 
+// historyStatus returns the status ("success" or "error"), error message
+// and status code name recorded in history for a call that ended with err,
+// the same for every kind of call.
+func historyStatus(err error) (status, errorMsg, code string) {
+	code = grpcstatus.Code(err).String()
+	if err != nil {
+		return "error", err.Error(), code
+	}
+	return "success", "", code
+}
+
 // recordHistoryEntry saves a request/response to history
-func (w *MainWindow) recordHistoryEntry(address, method, requestJSON string, requestMetadata map[string]string, responseJSON string, responseMetadata metadata.MD, duration time.Duration, err error) {
+func (w *MainWindow) recordHistoryEntry(address, method, requestJSON string, requestMetadata map[string]string, responseJSON string, responseHeaders, responseTrailers metadata.MD, duration time.Duration, err error) {
 	// Get current connection settings
-	currentConn := domain.Connection{
-		Address: address,
-	}
+	currentConn := domain.Connection{}
 	if w.connectionBar != nil {
-		currentConn.TLS = w.connectionBar.GetTLSSettings()
+		currentConn = w.connectionBar.GetConnection()
 	}
+	currentConn.Address = address
 
-	// Convert response metadata to map
-	respMeta := convertMetadataToMap(responseMetadata)
-
-	// Determine status
-	status := "success"
-	errorMsg := ""
-	if err != nil {
-		status = "error"
-		errorMsg = err.Error()
-	}
+	status, errorMsg, code := historyStatus(err)
 
 	// Create history entry
 	entry := domain.HistoryEntry{
@@ -1595,29 +1862,36 @@ func (w *MainWindow) recordHistoryEntry(address, method, requestJSON string, req
 		Response:   responseJSON,
 		Duration:   duration,
 		Status:     status,
+		Code:       code,
 		Error:      errorMsg,
 		Metadata: domain.Metadata{
 			Request:  requestMetadata,
-			Response: respMeta,
+			Response: convertMetadataToMap(responseHeaders),
+			Trailers: convertMetadataToMap(responseTrailers),
 		},
 	}
 
+	entry = w.historyCredentials(entry)
+
 	// Save to history (non-blocking)
-	go func() {
+	w.historyWrites.Go(func() {
 		if err := w.historyPanel.AddEntry(entry); err != nil {
 			w.logger.Error("failed to save history entry", slog.Any("error", err))
+			return
 		}
-	}()
+		w.loadMethodStats()
+	})
 }
 
-// recordStreamHistoryEntry saves a streaming RPC summary to history.
-func (w *MainWindow) recordStreamHistoryEntry(address, method, requestJSON string, requestMetadata map[string]string, duration time.Duration, status, errorMsg, streamType string, messageCount int) {
-	currentConn := domain.Connection{
-		Address: address,
-	}
+// recordStreamHistoryEntry saves a streaming RPC summary to history. Run
+// it with historyWrites.Go so closing waits for it.
+func (w *MainWindow) recordStreamHistoryEntry(address, method, requestJSON string, requestMetadata map[string]string, responseHeaders, responseTrailers metadata.MD, duration time.Duration, err error, streamType string, messageCount int) {
+	status, errorMsg, code := historyStatus(err)
+	currentConn := domain.Connection{}
 	if w.connectionBar != nil {
-		currentConn.TLS = w.connectionBar.GetTLSSettings()
+		currentConn = w.connectionBar.GetConnection()
 	}
+	currentConn.Address = address
 
 	entry := domain.HistoryEntry{
 		ID:           history.GenerateEntryID(),
@@ -1628,17 +1902,32 @@ func (w *MainWindow) recordStreamHistoryEntry(address, method, requestJSON strin
 		Response:     fmt.Sprintf("(%d messages)", messageCount),
 		Duration:     duration,
 		Status:       status,
+		Code:         code,
 		Error:        errorMsg,
 		StreamType:   streamType,
 		MessageCount: messageCount,
 		Metadata: domain.Metadata{
-			Request: requestMetadata,
+			Request:  requestMetadata,
+			Response: convertMetadataToMap(responseHeaders),
+			Trailers: convertMetadataToMap(responseTrailers),
 		},
 	}
+	entry = w.historyCredentials(entry)
 
 	if err := w.historyPanel.AddEntry(entry); err != nil {
 		w.logger.Error("failed to save stream history entry", slog.Any("error", err))
+		return
+	}
+	w.loadMethodStats()
+}
+
+// historyCredentials redacts the entry's credentials unless the user chose
+// to keep them in history.
+func (w *MainWindow) historyCredentials(entry domain.HistoryEntry) domain.HistoryEntry {
+	if w.fyneApp.Preferences().BoolWithFallback(settings.PrefHistoryCredentials, false) {
+		return entry
 	}
+	return entry.RedactCredentials()
 }
 
 // handleHistoryEntry loads a history entry into the UI. When replay is true
@@ -1676,7 +1965,8 @@ func (w *MainWindow) handleHistoryEntry(entry domain.HistoryEntry, replay bool)
 
 		fyne.Do(func() {
 			_ = w.state.Request.TextData.Set(entry.Request)
-			w.requestPanel.SetMetadata(entry.Metadata.Request)
+			// Redacted credentials are dropped so the auth preset applies
+			w.requestPanel.SetMetadata(domain.WithoutRedacted(entry.Metadata.Request))
 			w.requestPanel.SyncTextToForm()
 
 			w.logger.Info("history entry loaded into request panel")
@@ -1692,10 +1982,12 @@ func (w *MainWindow) handleHistoryEntry(entry domain.HistoryEntry, replay bool)
 	needsConnect := currentServer != entry.Connection.Address
 
 	if needsConnect {
+		if entry.Connection.Auth.IsRedacted() {
+			entry.Connection.Auth = w.connectionBar.GetConnection().Auth
+		}
 		w.logger.Info("connecting to historical server", slog.String("address", entry.Connection.Address))
-		w.connectionBar.SetAddress(entry.Connection.Address)
-		w.connectionBar.SetTLSSettings(entry.Connection.TLS)
-		w.handleConnect(entry.Connection.Address, entry.Connection.TLS)
+		w.connectionBar.SetConnection(entry.Connection)
+		w.handleConnect(entry.Connection)
 		w.waitForConnection(afterConnect, "while "+action+" history entry")
 	} else {
 		afterConnect()
@@ -1784,6 +2076,22 @@ func (w *MainWindow) setupMainMenu() {
 	fileMenu := fyne.NewMenu("File",
 		saveItem,
 		loadItem,
+		fyne.NewMenuItem("Export Workspace...", func() {
+			w.workspacePanel.TriggerExport()
+		}),
+		fyne.NewMenuItem("Import Workspace...", func() {
+			w.workspacePanel.TriggerImport()
+		}),
+		fyne.NewMenuItem("Import grpcurl Command...", func() {
+			w.showImportCommand()
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Run Checklist", func() {
+			w.handleRunChecklist()
+		}),
+		fyne.NewMenuItem("Edit Checklist...", func() {
+			w.showChecklistEditor()
+		}),
 		fyne.NewMenuItemSeparator(),
 		connectItem,
 		fyne.NewMenuItemSeparator(),
@@ -1803,11 +2111,20 @@ func (w *MainWindow) setupMainMenu() {
 		Modifier: fyne.KeyModifierSuper,
 	}
 
+	// Main menu shortcuts reach the window even while a text entry has
+	// focus, which canvas shortcuts do not
+	sendItem := fyne.NewMenuItem("Send Request", unlessModal(w.window.Canvas(), w.handleSendShortcut))
+	sendItem.Shortcut = sendShortcut
+
 	editMenu := fyne.NewMenu("Edit",
+		sendItem,
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Clear Request", func() {
 			w.handleClearRequest()
 		}),
 		clearResponseItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Build Field Mask...", w.showFieldMaskBuilder),
 	)
 
 	// View menu - mode switching
@@ -1859,6 +2176,9 @@ func (w *MainWindow) setupMainMenu() {
 		Modifier: fyne.KeyModifierSuper | fyne.KeyModifierShift,
 	}
 
+	findTypeItem := fyne.NewMenuItem("Find Type...", w.showFindType)
+	findTypeItem.Shortcut = findTypeShortcut
+
 	viewMenu := fyne.NewMenu("View",
 		textModeItem,
 		formModeItem,
@@ -1867,6 +2187,9 @@ func (w *MainWindow) setupMainMenu() {
 		filterServicesItem,
 		expandAllItem,
 		collapseAllItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Method Options...", w.showMethodOptions),
+		findTypeItem,
 	)
 
 	// Help menu - shortcuts reference and about dialog
@@ -1874,6 +2197,7 @@ func (w *MainWindow) setupMainMenu() {
 		fyne.NewMenuItem("Keyboard Shortcuts", func() {
 			ShowShortcutDialog(w.window)
 		}),
+		fyne.NewMenuItem("Show Logs", w.logViewer.Show),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("About Grotto", func() {
 			ShowAboutDialog(w.window)
@@ -1891,15 +2215,109 @@ func (w *MainWindow) setupMainMenu() {
 	w.window.SetMainMenu(mainMenu)
 }
 
+// configureInvoker applies the connection's request compression, default
+// metadata and retry policy to the invoker just created for it.
+func (w *MainWindow) configureInvoker(cfg domain.Connection) {
+	invoker := w.app.Invoker()
+	if !cfg.Transport.IsWeb() {
+		invoker.SetCompressor(cfg.Compression)
+	}
+	defaults, err := grpc.BuildMetadata(cfg.DefaultMetadata)
+	if err != nil {
+		w.logger.Warn("ignoring invalid default metadata", slog.Any("error", err))
+		defaults = nil
+	}
+	invoker.SetDefaultMetadata(defaults)
+	invoker.SetRetryPolicy(cfg.Retry)
+}
+
 // showPreferences opens the unified Preferences dialog.
 func (w *MainWindow) showPreferences() {
 	settings.ShowPreferencesDialog(w.fyneApp, w.window, settings.PreferencesCallbacks{
 		OnThemeChange: func(mode string) {
 			ApplyTheme(w.fyneApp, mode)
 		},
+		OnEditorFontChange: func(f components.EditorFont) {
+			ApplyEditorFont(w.fyneApp, f)
+		},
+		OnFormMaxDepthChange:   w.requestPanel.SetFormMaxDepth,
+		OnStreamMessagesChange: w.responsePanel.StreamingWidget().SetMaxMessages,
+		OnHealthIntervalChange: func(int) {
+			if connected, _ := w.state.Connected.Get(); connected {
+				go w.startHealthMonitor()
+			}
+		},
+		OnSchemaCheckIntervalChange: func(int) {
+			if connected, _ := w.state.Connected.Get(); connected {
+				go w.startSchemaWatcher()
+			}
+		},
+		LogSettings: w.app.LogSettings,
+		OnLogSettingsChange: func(s logging.Settings) {
+			if err := w.app.SetLogSettings(s); err != nil {
+				dialog.ShowError(err, w.window)
+			}
+		},
+		AuditPath:     w.app.AuditPath(),
+		OnAuditChange: w.app.SetAudit,
+	})
+}
+
+// startHealthMonitor starts polling grpc.health.v1.Health on the current
+// connection at the configured interval, replacing any earlier monitor.
+// An interval of 0 turns health checks off.
+func (w *MainWindow) startHealthMonitor() {
+	w.stopHealthMonitor()
+
+	seconds := w.fyneApp.Preferences().IntWithFallback(settings.PrefHealthInterval, settings.DefaultHealthInterval)
+	invoker := w.app.Invoker()
+	if seconds <= 0 || invoker == nil {
+		return
+	}
+
+	monitor := grpc.NewHealthMonitor(invoker, time.Duration(seconds)*time.Second, w.logger)
+	w.healthMu.Lock()
+	w.healthMonitor = monitor
+	w.healthMu.Unlock()
+	monitor.Start(func(r grpc.HealthResult) {
+		fyne.Do(func() {
+			w.connectionBar.SetHealth(r)
+		})
 	})
 }
 
+// stopHealthMonitor stops health checks and hides the indicator.
+func (w *MainWindow) stopHealthMonitor() {
+	w.healthMu.Lock()
+	monitor := w.healthMonitor
+	w.healthMonitor = nil
+	w.healthMu.Unlock()
+
+	if monitor != nil {
+		monitor.Stop()
+	}
+	fyne.Do(w.connectionBar.ClearHealth)
+}
+
+// applyRequestTemplate pre-fills the request body with a template of md,
+// unless the editor holds something the user wrote: only an empty editor
+// or one still showing the previous template is replaced.
+func (w *MainWindow) applyRequestTemplate(md protoreflect.MessageDescriptor) {
+	current, _ := w.state.Request.TextData.Get()
+	if current != "" && current != w.lastTemplate {
+		return
+	}
+
+	template, err := form.GenerateTemplate(md, form.TemplateOptions{})
+	if err != nil {
+		w.logger.Warn("failed to generate request template", slog.Any("error", err))
+		return
+	}
+	w.lastTemplate = template
+	_ = w.state.Request.TextData.Set(template)
+	w.requestPanel.SyncTextToForm()
+}
+
 // handleClearHistory shows a confirmation dialog and clears history if confirmed
 func (w *MainWindow) handleClearHistory() {
 	dialog.ShowConfirm("Clear History",
@@ -1921,6 +2339,8 @@ func (w *MainWindow) handleClearHistory() {
 // handleClearRequest clears the request panel
 func (w *MainWindow) handleClearRequest() {
 	_ = w.state.Request.TextData.Set("")
+	_ = w.state.Request.InputType.Set("")
+	w.requestPanel.SetBodyNote("")
 	_ = w.state.Request.Metadata.Set([]string{})
 	w.logger.Debug("request panel cleared")
 }
@@ -2,6 +2,7 @@ package workspace
 
 import (
 	"log/slog"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -23,6 +24,7 @@ type WorkspacePanel struct {
 
 	// UI components
 	workspaceList binding.StringList
+	connections   map[string]string // Workspace name to its connection's display name
 	listWidget    *widget.List
 	nameEntry     *widget.Entry
 	clearBtn      *widget.Button
@@ -33,8 +35,9 @@ type WorkspacePanel struct {
 	placeholder *widget.Label
 
 	// Callbacks
-	onLoad func(workspace domain.Workspace)
-	onSave func() domain.Workspace
+	onLoad  func(workspace domain.Workspace)
+	onSave  func() domain.Workspace
+	onSaved func(workspace domain.Workspace)
 
 	// Content container
 	content *fyne.Container
@@ -65,18 +68,24 @@ func (p *WorkspacePanel) buildUI() {
 		func() fyne.CanvasObject {
 			label := widget.NewLabel("template")
 			label.Truncation = fyne.TextTruncateEllipsis
+			connLabel := widget.NewLabel("")
+			connLabel.Importance = widget.LowImportance
+			connLabel.Truncation = fyne.TextTruncateEllipsis
 			deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 			deleteBtn.Importance = widget.LowImportance
-			return container.NewBorder(nil, nil, nil, deleteBtn, label)
+			return container.NewBorder(nil, nil, nil, deleteBtn, container.NewGridWithColumns(2, label, connLabel))
 		},
 		func(i binding.DataItem, o fyne.CanvasObject) {
 			ct := o.(*fyne.Container)
-			label := ct.Objects[0].(*widget.Label)
+			labels := ct.Objects[0].(*fyne.Container)
+			label := labels.Objects[0].(*widget.Label)
+			connLabel := labels.Objects[1].(*widget.Label)
 			deleteBtn := ct.Objects[1].(*widget.Button)
 
 			strItem := i.(binding.String)
 			val, _ := strItem.Get()
 			label.SetText(val)
+			connLabel.SetText(p.connections[val])
 
 			deleteBtn.OnTapped = func() {
 				p.handleDeleteWorkspace(val)
@@ -142,7 +151,8 @@ func (p *WorkspacePanel) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(p.content)
 }
 
-// RefreshList reloads workspace list from storage
+// RefreshList reloads workspace list from storage, with the connection
+// each workspace opens
 func (p *WorkspacePanel) RefreshList() {
 	workspaces, err := p.storage.ListWorkspaces()
 	if err != nil {
@@ -150,6 +160,17 @@ func (p *WorkspacePanel) RefreshList() {
 		return
 	}
 
+	// Each workspace is listed with the connection it opens
+	connections := make(map[string]string, len(workspaces))
+	for _, name := range workspaces {
+		ws, err := p.storage.LoadWorkspace(name)
+		if err != nil || ws.CurrentConnection == nil {
+			continue
+		}
+		connections[name] = ws.CurrentConnection.DisplayName()
+	}
+	p.connections = connections
+
 	if err := p.workspaceList.Set(workspaces); err != nil {
 		p.logger.Error("failed to update workspace list", slog.Any("error", err))
 	}
@@ -171,6 +192,16 @@ func (p *WorkspacePanel) SetOnSave(fn func() domain.Workspace) {
 	p.onSave = fn
 }
 
+// SetOnSaved sets callback when a workspace has been saved
+func (p *WorkspacePanel) SetOnSaved(fn func(workspace domain.Workspace)) {
+	p.onSaved = fn
+}
+
+// SetWorkspaceName fills in the workspace name that Save and Load use
+func (p *WorkspacePanel) SetWorkspaceName(name string) {
+	p.nameEntry.SetText(name)
+}
+
 // TriggerSave programmatically triggers save (for keyboard shortcut)
 func (p *WorkspacePanel) TriggerSave() {
 	p.handleSave()
@@ -197,6 +228,7 @@ func (p *WorkspacePanel) handleSave() {
 	// Get current state from callback
 	workspace := p.onSave()
 	workspace.Name = name
+	workspace.SavedAt = time.Now()
 
 	doSave := func() {
 		if err := p.storage.SaveWorkspace(workspace); err != nil {
@@ -209,6 +241,9 @@ func (p *WorkspacePanel) handleSave() {
 
 		p.logger.Info("workspace saved", slog.String("name", name))
 		p.RefreshList()
+		if p.onSaved != nil {
+			p.onSaved(workspace)
+		}
 	}
 
 	// Check if workspace already exists and prompt for overwrite
@@ -0,0 +1,159 @@
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	fynestorage "fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/storage"
+)
+
+// TriggerExport writes the selected workspace to a file chosen by the user,
+// without credentials unless they choose to include them.
+func (p *WorkspacePanel) TriggerExport() {
+	name := p.nameEntry.Text
+	if name == "" {
+		ShowErrorDialog(p.window, "Please select a workspace to export")
+		return
+	}
+	workspace, err := p.storage.LoadWorkspace(name)
+	if err != nil {
+		p.logger.Error("failed to load workspace",
+			slog.String("name", name),
+			slog.Any("error", err))
+		ShowErrorDialog(p.window, "Failed to load workspace: "+err.Error())
+		return
+	}
+
+	secrets := widget.NewCheck("Include secrets (tokens, passwords, client key paths)", nil)
+	note := widget.NewLabel("Workspace files are meant to be shared. Secrets are left out unless you include them.")
+	note.Wrapping = fyne.TextWrapWord
+	dialog.ShowCustomConfirm("Export Workspace '"+name+"'", "Export...", "Cancel",
+		container.NewVBox(note, secrets),
+		func(confirmed bool) {
+			if confirmed {
+				p.exportToFile(*workspace, secrets.Checked)
+			}
+		}, p.window)
+}
+
+// exportToFile asks where to save workspace and writes it there.
+func (p *WorkspacePanel) exportToFile(workspace domain.Workspace, includeSecrets bool) {
+	data, err := storage.ExportWorkspace(workspace, includeSecrets)
+	if err != nil {
+		ShowErrorDialog(p.window, "Failed to export workspace: "+err.Error())
+		return
+	}
+
+	d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, p.window)
+			return
+		}
+		if writer == nil {
+			return // User cancelled
+		}
+		defer writer.Close()
+		if _, err := writer.Write(data); err != nil {
+			ShowErrorDialog(p.window, "Failed to export workspace: "+err.Error())
+			return
+		}
+		p.logger.Info("workspace exported",
+			slog.String("name", workspace.Name),
+			slog.Bool("secrets", includeSecrets))
+	}, p.window)
+	d.SetFilter(fynestorage.NewExtensionFileFilter([]string{".json"}))
+	d.SetFileName(workspace.Name + ".grotto.json")
+	d.Show()
+}
+
+// TriggerImport reads a workspace file chosen by the user and saves it. When
+// a workspace of the same name exists the user chooses whether to merge the
+// file into it or replace it.
+func (p *WorkspacePanel) TriggerImport() {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, p.window)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			ShowErrorDialog(p.window, "Failed to read workspace file: "+err.Error())
+			return
+		}
+		workspace, err := storage.ImportWorkspace(data)
+		if err != nil {
+			ShowErrorDialog(p.window, "Failed to import workspace: "+err.Error())
+			return
+		}
+		p.importWorkspace(*workspace)
+	}, p.window)
+	fd.SetFilter(fynestorage.NewExtensionFileFilter([]string{".json"}))
+	fd.Show()
+}
+
+// importWorkspace saves an imported workspace, asking first how to combine
+// it with an existing workspace of the same name.
+func (p *WorkspacePanel) importWorkspace(imported domain.Workspace) {
+	name := imported.Name
+	existing, err := p.storage.LoadWorkspace(name)
+	if err != nil {
+		// Nothing to collide with
+		p.saveImported(imported, "Imported workspace '"+name+"'.")
+		return
+	}
+
+	merged, replaced := existing.Merge(imported)
+	message := "A workspace named '" + name + "' already exists.\n\n" +
+		"Merge adds the imported connections and requests to it; Replace discards it."
+	if len(replaced) > 0 {
+		message += fmt.Sprintf("\n\nMerging replaces %d entries of the same name:\n• %s", len(replaced), strings.Join(replaced, "\n• "))
+	}
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons("Import Workspace", container.NewVScroll(label), p.window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton("Cancel", func() { d.Hide() }),
+		widget.NewButton("Merge", func() {
+			d.Hide()
+			p.saveImported(merged, "Merged the imported workspace into '"+name+"'.")
+		}),
+		widget.NewButton("Replace", func() {
+			d.Hide()
+			p.saveImported(imported, "Replaced workspace '"+name+"'.")
+		}),
+	})
+	d.Resize(fyne.NewSize(500, 300))
+	d.Show()
+}
+
+// saveImported stores an imported workspace, selects it in the list, and
+// confirms with message.
+func (p *WorkspacePanel) saveImported(workspace domain.Workspace, message string) {
+	if err := p.storage.SaveWorkspace(workspace); err != nil {
+		p.logger.Error("failed to save workspace",
+			slog.String("name", workspace.Name),
+			slog.Any("error", err))
+		ShowErrorDialog(p.window, "Failed to save workspace: "+err.Error())
+		return
+	}
+
+	p.logger.Info("workspace imported", slog.String("name", workspace.Name))
+	p.RefreshList()
+	p.nameEntry.SetText(workspace.Name)
+	ShowInfoDialog(p.window, "Import Workspace", message+" Load it to use it.")
+}
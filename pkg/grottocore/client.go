@@ -0,0 +1,266 @@
+package grottocore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shhac/grotto/internal/domain"
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Options configure how Connect reaches a server and resolves its schema.
+// The zero value connects over TLS, verifying the server against the
+// system roots, and uses server reflection.
+type Options struct {
+	// Plaintext connects without TLS
+	Plaintext bool
+	// InsecureSkipVerify skips verifying the server's certificate
+	InsecureSkipVerify bool
+	// CAFile is a PEM bundle trusted instead of the system roots
+	CAFile string
+	// CertFile and KeyFile are a client certificate and key, for mTLS
+	CertFile string
+	KeyFile  string
+	// ServerName is checked against the server's certificate and sent for
+	// SNI, when it differs from the address's host
+	ServerName string
+
+	// Web connects over gRPC-Web instead of native gRPC
+	Web bool
+
+	// DescriptorSetFile is a binary FileDescriptorSet, as written by
+	// protoc --descriptor_set_out, used instead of server reflection
+	DescriptorSetFile string
+	// ProtoImportPaths are directories of .proto sources compiled instead
+	// of using server reflection. Cannot be combined with
+	// DescriptorSetFile.
+	ProtoImportPaths []string
+
+	// WaitForReady makes Connect dial at once and wait until the server is
+	// reachable, instead of on the first call. Ignored over gRPC-Web.
+	WaitForReady bool
+
+	// Logger receives the engine's logs (nil discards them)
+	Logger *slog.Logger
+}
+
+// connection returns the connection profile the options describe.
+func (o Options) connection(addr string) domain.Connection {
+	conn := domain.Connection{
+		Address:           addr,
+		DescriptorSetFile: o.DescriptorSetFile,
+		ProtoImportPaths:  o.ProtoImportPaths,
+	}
+	if o.Web {
+		conn.Transport = domain.TransportGRPCWeb
+	}
+	if !o.Plaintext {
+		conn.TLS = domain.TLSSettings{
+			Enabled:            true,
+			SkipVerify:         o.InsecureSkipVerify,
+			CertFile:           o.CAFile,
+			ClientCertFile:     o.CertFile,
+			ClientKeyFile:      o.KeyFile,
+			ServerNameOverride: o.ServerName,
+		}
+	}
+	return conn
+}
+
+// Client is a connection to a server and the schema resolved for it. It
+// is safe for concurrent use.
+type Client struct {
+	conn       *grpc.ConnectionManager
+	reflection *grpc.ReflectionClient
+	invoker    *grpc.Invoker
+
+	// typesFor holds the services whose files the invoker resolves Any
+	// and extension types from (guarded by mu)
+	mu       sync.Mutex
+	typesFor map[protoreflect.FullName]bool
+}
+
+// Connect connects to addr, host:port or unix:///path, and sets up its
+// schema source. ctx bounds connecting and, for .proto sources, compiling
+// them; it does not bound the Client's later use. Close the Client when
+// done.
+func Connect(ctx context.Context, addr string, opts Options) (*Client, error) {
+	if opts.DescriptorSetFile != "" && len(opts.ProtoImportPaths) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "a descriptor set and .proto sources cannot be combined")
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	cfg := opts.connection(addr)
+	cm := grpc.NewConnectionManager(logger)
+	if err := cm.Connect(ctx, cfg); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if opts.WaitForReady {
+		if err := cm.WaitReady(ctx); err != nil {
+			_ = cm.Disconnect()
+			return nil, status.Errorf(codes.Unavailable, "failed to connect to %s: %v", addr, err)
+		}
+	}
+
+	channel := cm.Channel()
+	reflection, err := grpc.NewSchemaSource(ctx, channel, cfg, logger)
+	if err != nil {
+		_ = cm.Disconnect()
+		return nil, err
+	}
+	return &Client{
+		conn:       cm,
+		reflection: reflection,
+		invoker:    grpc.NewInvoker(channel, logger),
+		typesFor:   make(map[protoreflect.FullName]bool),
+	}, nil
+}
+
+// Close closes the schema source and the connection. Calls in progress
+// fail.
+func (c *Client) Close() error {
+	c.reflection.Close()
+	return c.conn.Disconnect()
+}
+
+// Service is a service the server exposes.
+type Service struct {
+	FullName   string // e.g. "example.v1.UserService"
+	File       string // Path of the .proto file declaring it
+	Deprecated bool
+	// Error is why the service could not be resolved; its Methods may be
+	// empty then
+	Error   string
+	Methods []Method
+}
+
+// Method is a method of a Service.
+type Method struct {
+	Name            string // e.g. "GetUser"
+	FullName        string // e.g. "example.v1.UserService.GetUser"
+	InputType       string // Full name of the request message
+	OutputType      string // Full name of the response message
+	ClientStreaming bool
+	ServerStreaming bool
+	Deprecated      bool // Itself or through its service
+	// Error is why the method cannot be called, such as a request type
+	// that could not be resolved
+	Error string
+}
+
+// ListServices lists the services the schema source declares, sorted by
+// name. Services that cannot be resolved are listed with their Error
+// rather than failing the listing. Cancelling ctx abandons the listing;
+// over server reflection it also closes the Client's reflection stream.
+func (c *Client) ListServices(ctx context.Context) ([]Service, error) {
+	services, err := c.reflection.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]Service, 0, len(services))
+	for _, svc := range services {
+		s := Service{
+			FullName:   svc.FullName,
+			File:       svc.File,
+			Deprecated: svc.Deprecated,
+			Error:      svc.Error,
+			Methods:    make([]Method, 0, len(svc.Methods)),
+		}
+		for _, m := range svc.Methods {
+			s.Methods = append(s.Methods, Method{
+				Name:            m.Name,
+				FullName:        m.FullName,
+				InputType:       m.InputType,
+				OutputType:      m.OutputType,
+				ClientStreaming: m.IsClientStream,
+				ServerStreaming: m.IsServerStream,
+				Deprecated:      m.Deprecated,
+				Error:           m.Error,
+			})
+		}
+		listed = append(listed, s)
+	}
+	slices.SortFunc(listed, func(a, b Service) int { return cmp.Compare(a.FullName, b.FullName) })
+	c.useTypes(listed...)
+	return listed, nil
+}
+
+// Describe returns the descriptor of a service, method, message or enum
+// by its full name, e.g. "example.v1.UserService.GetUser"; a method may
+// also be named "example.v1.UserService/GetUser". Over server reflection,
+// symbols not yet loaded are asked for. Symbols that cannot be found fail
+// with codes.NotFound.
+func (c *Client) Describe(symbol string) (protoreflect.Descriptor, error) {
+	name := strings.Replace(strings.TrimPrefix(strings.TrimSpace(symbol), "/"), "/", ".", 1)
+	d, err := c.reflection.FindDescriptor(name)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return d, nil
+}
+
+// Method returns the descriptor of a method to call, named
+// "package.Service/Method". Malformed names fail with
+// codes.InvalidArgument and methods that cannot be found or called with
+// codes.NotFound, unless the server failed otherwise.
+func (c *Client) Method(name string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, err := SplitMethod(name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	md, err := c.reflection.GetMethodDescriptor(serviceName, methodName)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	c.useTypes(Service{FullName: serviceName})
+	return md, nil
+}
+
+// useTypes has the invoker resolve Any and extension types from the files
+// of services too, once each, as the app does after listing.
+func (c *Client) useTypes(services ...Service) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	added := false
+	for _, svc := range services {
+		name := protoreflect.FullName(svc.FullName)
+		if svc.Error == "" && !c.typesFor[name] {
+			c.typesFor[name] = true
+			added = true
+		}
+	}
+	if added {
+		c.invoker.SetTypeResolver(c.reflection.TypeResolver())
+	}
+}
+
+// notFound gives err codes.NotFound unless it already has a status.
+func notFound(err error) error {
+	if status.Code(err) == codes.Unknown {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+// SplitMethod splits "package.Service/Method", with an optional leading
+// slash, into service and method names.
+func SplitMethod(fullName string) (service, method string, err error) {
+	name := strings.TrimPrefix(strings.TrimSpace(fullName), "/")
+	service, method, ok := strings.Cut(name, "/")
+	if !ok || service == "" || method == "" {
+		return "", "", fmt.Errorf("method %q must be in the form package.Service/Method", fullName)
+	}
+	return service, method, nil
+}
@@ -0,0 +1,27 @@
+// Package grottocore is Grotto's gRPC engine without the GUI: connecting
+// to a server, resolving its schema and calling its methods with JSON
+// messages, from any Go program.
+//
+// Schemas come from server reflection, a FileDescriptorSet or .proto
+// sources, with the same fix-ups the app applies to descriptors that do
+// not build as served: missing imports, map entries without the Entry
+// suffix, well-known types declared in the wrong files and the like. A
+// service that cannot be fully resolved still lists its methods, each with
+// the reason it cannot be called if it cannot.
+//
+// Requests and responses are protobuf JSON, as the app shows them. Types
+// packed in google.protobuf.Any fields and extensions are resolved from
+// the files of the services used, asking the server for more over
+// reflection.
+//
+// Errors from calls carry the call's status, so status.Code and
+// status.FromError see through them. Requests that are not valid JSON for
+// the method fail with codes.InvalidArgument before anything is sent.
+//
+// # Stability
+//
+// The functions and types in this package are kept compatible across
+// releases; Grotto's own command-line tools are built on them. Fields may
+// be added to Options, Service and Method. Everything under internal/ may
+// change at any time.
+package grottocore
@@ -0,0 +1,129 @@
+package grottocore_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/shhac/grotto/pkg/grottocore"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func Example() {
+	ctx := context.Background()
+	client, err := grottocore.Connect(ctx, "localhost:50051", grottocore.Options{Plaintext: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	resp, _, _, err := client.InvokeUnary(ctx, "example.v1.UserService/GetUser",
+		`{"id": "42"}`, metadata.Pairs("authorization", "Bearer token"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp)
+}
+
+func ExampleClient_ListServices() {
+	ctx := context.Background()
+	client, err := grottocore.Connect(ctx, "api.example.com:443", grottocore.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	services, err := client.ListServices(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, svc := range services {
+		if svc.Error != "" {
+			fmt.Printf("%s: %s\n", svc.FullName, svc.Error)
+			continue
+		}
+		for _, m := range svc.Methods {
+			fmt.Printf("%s/%s(%s) returns (%s)\n", svc.FullName, m.Name, m.InputType, m.OutputType)
+		}
+	}
+}
+
+func ExampleClient_Describe() {
+	client, err := grottocore.Connect(context.Background(), "localhost:50051", grottocore.Options{
+		Plaintext:         true,
+		DescriptorSetFile: "api.protoset",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	d, err := client.Describe("example.v1.User")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if md, ok := d.(protoreflect.MessageDescriptor); ok {
+		fields := md.Fields()
+		for i := range fields.Len() {
+			fmt.Println(fields.Get(i).JSONName(), fields.Get(i).Kind())
+		}
+	}
+}
+
+func ExampleClient_InvokeServerStream() {
+	ctx := context.Background()
+	client, err := grottocore.Connect(ctx, "localhost:50051", grottocore.Options{Plaintext: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	stream, err := client.InvokeServerStream(ctx, "example.v1.UserService/WatchUsers", `{}`, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(msg)
+	}
+}
+
+func ExampleClient_InvokeBidiStream() {
+	ctx := context.Background()
+	client, err := grottocore.Connect(ctx, "localhost:50051", grottocore.Options{Plaintext: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	stream, err := client.InvokeBidiStream(ctx, "example.v1.ChatService/Chat", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		for _, text := range []string{"hello", "bye"} {
+			if err := stream.Send(fmt.Sprintf(`{"text": %q}`, text)); err != nil {
+				return // Recv reports why
+			}
+		}
+		_ = stream.CloseSend()
+	}()
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(msg)
+	}
+}
@@ -0,0 +1,177 @@
+package grottocore_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shhac/grotto/internal/testutil/grpctest"
+	"github.com/shhac/grotto/pkg/grottocore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// connect connects to addr over plaintext, closing the client when the
+// test ends.
+func connect(t *testing.T, addr string) *grottocore.Client {
+	t.Helper()
+	client, err := grottocore.Connect(context.Background(), addr, grottocore.Options{Plaintext: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestClient_ListServices(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+	client := connect(t, srv.Addr)
+
+	services, err := client.ListServices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	svc := services[0]
+	assert.Equal(t, "grpctest.TestService", svc.FullName)
+	assert.Empty(t, svc.Error)
+
+	methods := make(map[string]grottocore.Method)
+	for _, m := range svc.Methods {
+		methods[m.Name] = m
+	}
+	require.Contains(t, methods, "BidiEcho")
+	assert.Equal(t, grottocore.Method{
+		Name:            "BidiEcho",
+		FullName:        "grpctest.TestService.BidiEcho",
+		InputType:       "grpctest.ItemRequest",
+		OutputType:      "grpctest.ItemResponse",
+		ClientStreaming: true,
+		ServerStreaming: true,
+	}, methods["BidiEcho"])
+}
+
+func TestClient_NonCanonical(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithReflectionFiles(grpctest.NonCanonicalFiles()...))
+	client := connect(t, srv.Addr)
+
+	services, err := client.ListServices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "custom.event.v1.EventService", services[0].FullName)
+	assert.Empty(t, services[0].Error, "resolved with fix-ups")
+
+	d, err := client.Describe("custom.event.v1.EventService/GetEvent")
+	require.NoError(t, err)
+	md, ok := d.(protoreflect.MethodDescriptor)
+	require.True(t, ok, "%T", d)
+
+	d, err = client.Describe(".custom.event.v1.Event")
+	require.NoError(t, err)
+	assert.Same(t, md.Output(), d)
+
+	_, err = client.Describe("custom.event.v1.Nope")
+	assert.Equal(t, codes.NotFound, status.Code(err), "%v", err)
+}
+
+func TestClient_InvokeUnary(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(), grpctest.WithEchoMetadata("-echo"))
+	client := connect(t, srv.Addr)
+
+	// No listing needed first
+	resp, header, _, err := client.InvokeUnary(context.Background(), "/grpctest.TestService/UnaryEcho",
+		`{"item": {"id": "1", "number": "9007199254740993"}}`, metadata.Pairs("x-tenant-echo", "acme"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"item": {"id": "1", "number": "9007199254740993"}, "ok": true}`, resp)
+	assert.Equal(t, []string{"acme"}, header.Get("x-tenant-echo"), "request metadata is sent")
+}
+
+func TestClient_Streams(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService())
+	client := connect(t, srv.Addr)
+	ctx := context.Background()
+
+	t.Run("server", func(t *testing.T) {
+		stream, err := client.InvokeServerStream(ctx, "grpctest.TestService/StreamItems", `{"item": {"id": "s"}}`, nil)
+		require.NoError(t, err)
+		assert.NotNil(t, stream.Header())
+		var msgs []string
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			msgs = append(msgs, msg)
+		}
+		assert.Len(t, msgs, 3)
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err, "the end is reported again")
+	})
+
+	t.Run("client", func(t *testing.T) {
+		stream, err := client.InvokeClientStream(ctx, "grpctest.TestService/CollectItems", nil)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(`{"item": {"id": "a"}}`))
+		require.NoError(t, stream.Send(`{"item": {"id": "b"}}`))
+		resp, err := stream.CloseAndReceive()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"items": [{"id": "a"}, {"id": "b"}], "count": 2}`, resp)
+	})
+
+	t.Run("bidi", func(t *testing.T) {
+		stream, err := client.InvokeBidiStream(ctx, "grpctest.TestService/BidiEcho", nil)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(`{"item": {"id": "1"}}`))
+		msg, err := stream.Recv()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"item": {"id": "1"}, "ok": true}`, msg)
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestClient_Errors(t *testing.T) {
+	srv := grpctest.StartServer(t, grpctest.WithTestService(),
+		grpctest.WithStatus("/grpctest.TestService/UnaryEcho", codes.PermissionDenied))
+	client := connect(t, srv.Addr)
+	ctx := context.Background()
+
+	_, _, _, err := client.InvokeUnary(ctx, "grpctest.TestService/UnaryEcho", `{}`, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "the server's status")
+
+	cases := map[string]error{}
+	_, _, _, cases["malformed"] = client.InvokeUnary(ctx, "UnaryEcho", `{}`, nil)
+	_, _, _, cases["bad JSON"] = client.InvokeUnary(ctx, "grpctest.TestService/UnaryEcho", `{"bogus": 1}`, nil)
+	_, _, _, cases["wrong kind"] = client.InvokeUnary(ctx, "grpctest.TestService/StreamItems", `{}`, nil)
+	_, cases["wrong stream kind"] = client.InvokeClientStream(ctx, "grpctest.TestService/BidiEcho", nil)
+	for name, err := range cases {
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%s: %v", name, err)
+	}
+
+	_, _, _, err = client.InvokeUnary(ctx, "grpctest.TestService/Nope", `{}`, nil)
+	assert.Equal(t, codes.NotFound, status.Code(err), "%v", err)
+}
+
+func TestConnect_Errors(t *testing.T) {
+	_, err := grottocore.Connect(context.Background(), "localhost:1", grottocore.Options{
+		Plaintext:         true,
+		DescriptorSetFile: "a.protoset",
+		ProtoImportPaths:  []string{"protos"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", err)
+
+	// Grab a free port and release it so nothing answers
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = grottocore.Connect(ctx, addr, grottocore.Options{Plaintext: true, WaitForReady: true})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "%v", err)
+}
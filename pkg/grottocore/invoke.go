@@ -0,0 +1,201 @@
+package grottocore
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/shhac/grotto/internal/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// InvokeUnary calls a unary method, named "package.Service/Method", with a
+// JSON request and returns the JSON response along with the response
+// headers and trailers. md is sent as request metadata; keys ending in
+// -bin take base64 values.
+func (c *Client) InvokeUnary(ctx context.Context, method, jsonRequest string, md metadata.MD) (jsonResponse string, header, trailer metadata.MD, err error) {
+	methodDesc, err := c.methodOfKind(method, false, false)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return c.invoker.InvokeUnary(ctx, methodDesc, jsonRequest, md)
+}
+
+// InvokeServerStream starts a server streaming call with a JSON request.
+// Read the responses with Recv until it returns an error; cancel ctx to
+// stop reading early.
+func (c *Client) InvokeServerStream(ctx context.Context, method, jsonRequest string, md metadata.MD) (*ServerStream, error) {
+	methodDesc, err := c.methodOfKind(method, false, true)
+	if err != nil {
+		return nil, err
+	}
+	msgs, errs, headers, trailers := c.invoker.InvokeServerStream(ctx, methodDesc, jsonRequest, md)
+	return &ServerStream{msgs: msgs, errs: errs, headers: headers, trailers: trailers}, nil
+}
+
+// InvokeClientStream starts a client streaming call. Send the requests,
+// then call CloseAndReceive for the response.
+func (c *Client) InvokeClientStream(ctx context.Context, method string, md metadata.MD) (*ClientStream, error) {
+	methodDesc, err := c.methodOfKind(method, true, false)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := c.invoker.InvokeClientStream(ctx, methodDesc, md)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientStream{handle: handle}, nil
+}
+
+// InvokeBidiStream starts a bidirectional streaming call. Requests may be
+// sent while responses are received, from another goroutine; call
+// CloseSend after the last request and Recv until it returns an error.
+func (c *Client) InvokeBidiStream(ctx context.Context, method string, md metadata.MD) (*BidiStream, error) {
+	methodDesc, err := c.methodOfKind(method, true, true)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := c.invoker.InvokeBidiStream(ctx, methodDesc, md)
+	if err != nil {
+		return nil, err
+	}
+	return &BidiStream{handle: handle}, nil
+}
+
+// methodOfKind resolves method and checks it streams as the call made.
+func (c *Client) methodOfKind(method string, clientStreaming, serverStreaming bool) (protoreflect.MethodDescriptor, error) {
+	md, err := c.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	if md.IsStreamingClient() != clientStreaming || md.IsStreamingServer() != serverStreaming {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is a %s method", md.FullName(), kindOf(md))
+	}
+	return md, nil
+}
+
+// kindOf names the kind of call a method takes.
+func kindOf(md protoreflect.MethodDescriptor) string {
+	switch {
+	case md.IsStreamingClient() && md.IsStreamingServer():
+		return "bidirectional streaming"
+	case md.IsStreamingClient():
+		return "client streaming"
+	case md.IsStreamingServer():
+		return "server streaming"
+	}
+	return "unary"
+}
+
+// ServerStream is a server streaming call in progress. Recv is not safe
+// to call from more than one goroutine at a time.
+type ServerStream struct {
+	msgs     <-chan string
+	errs     <-chan error
+	headers  <-chan metadata.MD
+	trailers <-chan metadata.MD
+
+	headerOnce sync.Once
+	header     metadata.MD
+
+	trailer metadata.MD
+	err     error // How the stream ended, once Recv has seen it
+}
+
+// Recv returns the next response message as JSON. Once the stream has
+// ended it returns io.EOF if the call succeeded, or why it failed.
+func (s *ServerStream) Recv() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	if msg, ok := <-s.msgs; ok {
+		return msg, nil
+	}
+	s.trailer = <-s.trailers
+	if s.err = <-s.errs; s.err == nil {
+		s.err = io.EOF
+	}
+	return "", s.err
+}
+
+// Header returns the response headers, waiting for the server to send
+// them. It returns nil if the stream ended without any.
+func (s *ServerStream) Header() metadata.MD {
+	s.headerOnce.Do(func() { s.header = <-s.headers })
+	return s.header
+}
+
+// Trailers returns the response trailers. They are only available once
+// Recv has returned an error; before that the result is empty.
+func (s *ServerStream) Trailers() metadata.MD {
+	return s.trailer
+}
+
+// ClientStream is a client streaming call in progress.
+type ClientStream struct {
+	handle *grpc.ClientStreamHandle
+}
+
+// Send sends a JSON request. io.EOF means the server ended the call
+// early; CloseAndReceive returns why.
+func (s *ClientStream) Send(jsonRequest string) error {
+	return s.handle.Send(jsonRequest)
+}
+
+// CloseAndReceive closes the send side of the stream and returns the JSON
+// response.
+func (s *ClientStream) CloseAndReceive() (string, error) {
+	return s.handle.CloseAndReceive()
+}
+
+// Header returns the response headers, waiting for the server to send
+// them.
+func (s *ClientStream) Header() (metadata.MD, error) {
+	return s.handle.Header()
+}
+
+// Trailers returns the response trailers. They are only available once
+// the stream has finished; before that the result is empty.
+func (s *ClientStream) Trailers() metadata.MD {
+	return s.handle.Trailers()
+}
+
+// BidiStream is a bidirectional streaming call in progress. Send and Recv
+// may be called from different goroutines, but neither from more than one
+// at a time.
+type BidiStream struct {
+	handle *grpc.BidiStreamHandle
+}
+
+// Send sends a JSON request. io.EOF means the server ended the call;
+// Recv returns why.
+func (s *BidiStream) Send(jsonRequest string) error {
+	return s.handle.Send(jsonRequest)
+}
+
+// Recv returns the next response message as JSON, or io.EOF once the
+// server has ended the call successfully.
+func (s *BidiStream) Recv() (string, error) {
+	return s.handle.Recv()
+}
+
+// CloseSend tells the server no more requests follow. Responses can
+// still be received.
+func (s *BidiStream) CloseSend() error {
+	return s.handle.CloseSend()
+}
+
+// Header returns the response headers, waiting for the server to send
+// them.
+func (s *BidiStream) Header() (metadata.MD, error) {
+	return s.handle.Header()
+}
+
+// Trailers returns the response trailers. They are only available once
+// Recv has returned an error; before that the result is empty.
+func (s *BidiStream) Trailers() metadata.MD {
+	return s.handle.Trailers()
+}
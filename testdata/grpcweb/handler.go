@@ -0,0 +1,174 @@
+// Package grpcweb provides a minimal in-process gRPC-Web to gRPC bridge for
+// tests. It plays the role Envoy's grpc_web filter plays in production:
+// requests framed as application/grpc-web(+proto) or application/grpc-web-text
+// are forwarded to a native gRPC connection and the responses are re-framed,
+// with trailers encoded in the body.
+package grpcweb
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// rawCodec passes already-serialized message bytes through unchanged.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unexpected type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+// skipHeaders are HTTP request headers that are not forwarded as metadata.
+var skipHeaders = map[string]bool{
+	"content-type":    true,
+	"content-length":  true,
+	"accept":          true,
+	"accept-encoding": true,
+	"user-agent":      true,
+	"x-grpc-web":      true,
+	"x-user-agent":    true,
+	"grpc-timeout":    true,
+	"connection":      true,
+}
+
+// NewHandler returns an http.Handler that bridges gRPC-Web requests to conn.
+// Only unary and server-streaming methods are supported, as in gRPC-Web itself.
+func NewHandler(conn *grpc.ClientConn) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+		text := strings.HasPrefix(contentType, "application/grpc-web-text")
+		if !text && !strings.HasPrefix(contentType, "application/grpc-web") {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if text {
+			if body, err = base64.StdEncoding.DecodeString(string(body)); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+			http.Error(w, "malformed grpc-web frame", http.StatusBadRequest)
+			return
+		}
+		payload := body[5:]
+
+		md := metadata.MD{}
+		for key, values := range r.Header {
+			key = strings.ToLower(key)
+			if skipHeaders[key] {
+				continue
+			}
+			for _, v := range values {
+				if strings.HasSuffix(key, "-bin") {
+					decoded, err := base64.StdEncoding.DecodeString(v)
+					if err != nil {
+						decoded, _ = base64.RawStdEncoding.DecodeString(v)
+					}
+					v = string(decoded)
+				}
+				md.Append(key, v)
+			}
+		}
+		ctx := metadata.NewOutgoingContext(r.Context(), md)
+
+		stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, r.URL.Path, grpc.ForceCodec(rawCodec{}))
+		if err == nil {
+			err = stream.SendMsg(&payload)
+		}
+		if err == nil {
+			err = stream.CloseSend()
+		}
+
+		responseType := "application/grpc-web+proto"
+		if text {
+			responseType = "application/grpc-web-text"
+		}
+		w.Header().Set("Content-Type", responseType)
+
+		writeFrame := func(flag byte, data []byte) {
+			frame := make([]byte, 5+len(data))
+			frame[0] = flag
+			binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+			copy(frame[5:], data)
+			if text {
+				// Encode each frame separately, as streaming proxies do.
+				frame = []byte(base64.StdEncoding.EncodeToString(frame))
+			}
+			_, _ = w.Write(frame)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+
+		if err == nil {
+			var hdr metadata.MD
+			if hdr, err = stream.Header(); err == nil {
+				for key, values := range hdr {
+					for _, v := range values {
+						if strings.HasSuffix(key, "-bin") {
+							v = base64.StdEncoding.EncodeToString([]byte(v))
+						}
+						w.Header().Add(key, v)
+					}
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+
+		for err == nil {
+			var msg []byte
+			if err = stream.RecvMsg(&msg); err == nil {
+				writeFrame(0x00, msg)
+			}
+		}
+
+		st := status.Convert(err)
+		if err == io.EOF {
+			st = status.New(codes.OK, "")
+		}
+		var trailer strings.Builder
+		fmt.Fprintf(&trailer, "grpc-status: %d\r\n", st.Code())
+		if st.Message() != "" {
+			fmt.Fprintf(&trailer, "grpc-message: %s\r\n", url.PathEscape(st.Message()))
+		}
+		if stream != nil {
+			for key, values := range stream.Trailer() {
+				for _, v := range values {
+					fmt.Fprintf(&trailer, "%s: %s\r\n", key, v)
+				}
+			}
+		}
+		writeFrame(0x80, []byte(trailer.String()))
+	})
+}
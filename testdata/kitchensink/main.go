@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"strings"
 	"time"
 
 	pb "github.com/shhac/grotto/testdata/kitchensink/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -124,13 +128,58 @@ func (s *kitchenSinkServer) ListTasks(ctx context.Context, req *pb.ListTasksRequ
 	}, nil
 }
 
+// binaryHeaders returns the incoming -bin headers, which gRPC has already
+// decoded to raw bytes.
+func binaryHeaders(ctx context.Context) metadata.MD {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	echoed := metadata.MD{}
+	for key, values := range incoming {
+		if strings.HasSuffix(key, "-bin") {
+			echoed[key] = values
+		}
+	}
+	return echoed
+}
+
+// echoBinaryUnary echoes -bin request headers back as response headers.
+func echoBinaryUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md := binaryHeaders(ctx); md.Len() > 0 {
+		if err := grpc.SetHeader(ctx, md); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// echoBinaryStream echoes -bin request headers back as response headers.
+func echoBinaryStream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if md := binaryHeaders(ss.Context()); md.Len() > 0 {
+		if err := ss.SetHeader(md); err != nil {
+			return err
+		}
+	}
+	return handler(srv, ss)
+}
+
 func main() {
-	lis, err := net.Listen("tcp", "localhost:50052")
+	unixSocket := flag.String("unix", "", "listen on this unix socket path instead of localhost:50052")
+	flag.Parse()
+
+	network, addr := "tcp", "localhost:50052"
+	if *unixSocket != "" {
+		// Remove a socket left behind by a previous run
+		_ = os.Remove(*unixSocket)
+		network, addr = "unix", *unixSocket
+	}
+	lis, err := net.Listen(network, addr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(echoBinaryUnary),
+		grpc.StreamInterceptor(echoBinaryStream),
+	)
 
 	// Register kitchen sink service
 	pb.RegisterKitchenSinkServer(s, newKitchenSinkServer())
@@ -143,7 +192,7 @@ func main() {
 	// Enable reflection for grpcurl/grpcui
 	reflection.Register(s)
 
-	log.Printf("Kitchen Sink gRPC test server listening on localhost:50052")
+	log.Printf("Kitchen Sink gRPC test server listening on %s %s", network, addr)
 	log.Printf("Services: kitchensink.KitchenSink, grpc.health.v1.Health")
 	log.Printf("Reflection enabled")
 	log.Println("\nExample usage:")
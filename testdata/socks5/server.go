@@ -0,0 +1,284 @@
+// Package socks5 provides a minimal in-process SOCKS5 proxy for tests. It
+// speaks just enough of RFC 1928 and RFC 1929 to tunnel TCP: the CONNECT
+// command, with no authentication or a required username and password.
+package socks5
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	version = 0x05
+
+	methodNoAuth       = 0x00
+	methodUserPass     = 0x02
+	methodNoAcceptable = 0xff
+
+	cmdConnect = 0x01
+
+	atypIPv4   = 0x01
+	atypDomain = 0x03
+	atypIPv6   = 0x04
+
+	replySucceeded          = 0x00
+	replyHostUnreachable    = 0x04
+	replyCommandUnsupported = 0x07
+	replyAddressUnsupported = 0x08
+)
+
+// Server is a running SOCKS5 proxy.
+type Server struct {
+	// Addr is the host:port the proxy listens on.
+	Addr string
+
+	username, password string
+
+	lis      net.Listener
+	connects atomic.Int64
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// Start starts a proxy on a random local port. When username is set,
+// clients must authenticate with it and password.
+func Start(username, password string) (*Server, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	s := &Server{
+		Addr:     lis.Addr().String(),
+		username: username,
+		password: password,
+		lis:      lis,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Port returns the port the proxy listens on.
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.Addr)
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// Connects returns how many tunnels the proxy has opened.
+func (s *Server) Connects() int {
+	return int(s.connects.Load())
+}
+
+// Close stops the proxy and closes every open tunnel.
+func (s *Server) Close() error {
+	err := s.lis.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.lis.Accept()
+		if err != nil {
+			return
+		}
+		s.track(conn, true)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.track(conn, false)
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) track(c net.Conn, add bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		s.conns[c] = struct{}{}
+	} else {
+		delete(s.conns, c)
+	}
+}
+
+// handle runs one client: method negotiation, authentication, the CONNECT
+// request, then relaying until either side closes.
+func (s *Server) handle(conn net.Conn) {
+	if err := s.negotiate(conn); err != nil {
+		return
+	}
+
+	target, err := readRequest(conn)
+	if err != nil {
+		var rep replyError
+		if errors.As(err, &rep) {
+			_ = writeReply(conn, byte(rep))
+		}
+		return
+	}
+
+	upstream, err := net.DialTimeout("tcp", target, 5*time.Second)
+	if err != nil {
+		_ = writeReply(conn, replyHostUnreachable)
+		return
+	}
+	s.track(upstream, true)
+	defer s.track(upstream, false)
+	defer upstream.Close()
+
+	if err := writeReply(conn, replySucceeded); err != nil {
+		return
+	}
+	s.connects.Add(1)
+
+	done := make(chan struct{}, 2)
+	relay := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// Unblock the other direction
+		_ = dst.Close()
+		_ = src.Close()
+		done <- struct{}{}
+	}
+	go relay(upstream, conn)
+	go relay(conn, upstream)
+	<-done
+	<-done
+}
+
+// negotiate picks an authentication method and, for username/password,
+// checks the client's credentials.
+func (s *Server) negotiate(conn net.Conn) error {
+	var head [2]byte
+	if _, err := io.ReadFull(conn, head[:]); err != nil {
+		return err
+	}
+	if head[0] != version {
+		return fmt.Errorf("unsupported SOCKS version %d", head[0])
+	}
+	methods := make([]byte, head[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+
+	want := byte(methodNoAuth)
+	if s.username != "" {
+		want = methodUserPass
+	}
+	offered := false
+	for _, m := range methods {
+		offered = offered || m == want
+	}
+	if !offered {
+		_, _ = conn.Write([]byte{version, methodNoAcceptable})
+		return errors.New("no acceptable authentication method")
+	}
+	if _, err := conn.Write([]byte{version, want}); err != nil {
+		return err
+	}
+	if want == methodNoAuth {
+		return nil
+	}
+
+	// RFC 1929: VER ULEN UNAME PLEN PASSWD
+	var ver [1]byte
+	if _, err := io.ReadFull(conn, ver[:]); err != nil {
+		return err
+	}
+	user, err := readShortString(conn)
+	if err != nil {
+		return err
+	}
+	pass, err := readShortString(conn)
+	if err != nil {
+		return err
+	}
+	if user != s.username || pass != s.password {
+		_, _ = conn.Write([]byte{0x01, 0x01})
+		return errors.New("authentication failed")
+	}
+	_, err = conn.Write([]byte{0x01, 0x00})
+	return err
+}
+
+// replyError is a request the proxy refuses with the given reply code.
+type replyError byte
+
+func (e replyError) Error() string {
+	return fmt.Sprintf("SOCKS reply %d", byte(e))
+}
+
+// readRequest reads a CONNECT request and returns its host:port.
+func readRequest(conn net.Conn) (string, error) {
+	var head [4]byte // VER CMD RSV ATYP
+	if _, err := io.ReadFull(conn, head[:]); err != nil {
+		return "", err
+	}
+	if head[1] != cmdConnect {
+		return "", replyError(replyCommandUnsupported)
+	}
+
+	var host string
+	switch head[3] {
+	case atypIPv4, atypIPv6:
+		ip := make(net.IP, 4)
+		if head[3] == atypIPv6 {
+			ip = make(net.IP, 16)
+		}
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case atypDomain:
+		name, err := readShortString(conn)
+		if err != nil {
+			return "", err
+		}
+		host = name
+	default:
+		return "", replyError(replyAddressUnsupported)
+	}
+
+	var port [2]byte
+	if _, err := io.ReadFull(conn, port[:]); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))), nil
+}
+
+// writeReply answers a request with rep and an unspecified bound address.
+func writeReply(conn net.Conn, rep byte) error {
+	_, err := conn.Write([]byte{version, rep, 0x00, atypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// readShortString reads a string prefixed with its one-byte length.
+func readShortString(r io.Reader) (string, error) {
+	var n [1]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return "", err
+	}
+	b := make([]byte, n[0])
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
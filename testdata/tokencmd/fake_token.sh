@@ -0,0 +1,32 @@
+#!/bin/sh
+# Fake token command for the internal/tokencmd tests.
+#
+#   fake_token.sh ok [token]   print a token (default "fake-token")
+#   fake_token.sh fail         print to stderr and exit 3
+#   fake_token.sh slow         sleep longer than any test timeout
+#   fake_token.sh count FILE   append a line to FILE, then print "token-N"
+#                              where N is the number of lines
+#   fake_token.sh empty        print nothing
+case "$1" in
+ok)
+	echo "${2:-fake-token}"
+	;;
+fail)
+	echo "ERROR: not logged in, run the login command first" >&2
+	exit 3
+	;;
+slow)
+	sleep 30
+	echo "too-late"
+	;;
+count)
+	echo x >>"$2"
+	echo "token-$(wc -l <"$2" | tr -d ' ')"
+	;;
+empty)
+	;;
+*)
+	echo "unknown mode: $1" >&2
+	exit 2
+	;;
+esac